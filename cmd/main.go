@@ -34,20 +34,124 @@
 package main
 
 import (
-	_ "github.com/guttosm/pack-service/docs" // swagger docs
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
 
 	"github.com/guttosm/pack-service/config"
 	"github.com/guttosm/pack-service/internal/app"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/spreadsheet"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
 	cfg := config.Load()
 
-	router := app.InitializeApp(cfg)
-	server := app.NewServer(router, cfg.Server.Port)
+	if len(os.Args) > 1 && os.Args[1] == "support-bundle" {
+		runSupportBundle(cfg, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "calc" {
+		if err := runCalc(cfg, os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "calc:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	migrateOnly := flag.Bool("migrate-only", false, "apply the pending database migrations and exit")
+	flag.Parse()
+	if *migrateOnly {
+		app.InitializeLogger()
+		if err := app.RunMigrations(cfg.Database); err != nil {
+			log.Fatal().Err(err).Msg("Failed to migrate the database")
+		}
+		return
+	}
+
+	tlsCerts, stopTLSReload, err := app.InitializeTLS(cfg.Server.TLS)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load TLS certificates")
+	}
+
+	application := app.InitializeApplication(cfg)
+	opts := []app.ServerOption{
+		app.WithGRPCServer(application.GRPCServer, cfg.Server.GRPCPort),
+		app.WithTLS(tlsCerts),
+		app.WithHTTP2(cfg.Server.HTTP2),
+		app.WithShutdownHook(stopTLSReload),
+	}
+	for _, hook := range application.ShutdownHooks {
+		opts = append(opts, app.WithShutdownHook(hook))
+	}
+	server := app.NewServer(application.Router, cfg.Server.Port, opts...)
 
 	if err := server.Run(); err != nil {
 		log.Fatal().Err(err).Msg("Server error")
 	}
 }
+
+// runSupportBundle implements the "support-bundle" subcommand.
+func runSupportBundle(cfg config.Config, args []string) {
+	fs := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: support-bundle-<timestamp>.zip)")
+	_ = fs.Parse(args)
+
+	app.InitializeLogger()
+
+	path, err := app.WriteSupportBundle(cfg, *output)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to write support bundle")
+	}
+	fmt.Println(path)
+}
+
+// runCalc implements the "calc" subcommand. With --items it calculates one
+// order; without, it calculates the orders read from stdin. Results go to
+// stdout, so the HTTP server is never started.
+func runCalc(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("calc", flag.ExitOnError)
+	items := fs.Int("items", 0, "items ordered; without it, orders are read from stdin")
+	sizes := fs.String("sizes", "", "pack sizes separated by commas (default: the configured pack sizes)")
+	format := fs.String("format", app.CalcFormatJSON, "input and output format: json or csv")
+	explain := fs.Bool("explain", false, "explain why the result was chosen (json, single order only)")
+	_ = fs.Parse(args)
+
+	calculator := app.NewOfflineCalculator(cfg)
+	if *items == 0 {
+		failed, err := app.CalculateBatchOffline(calculator, os.Stdin, os.Stdout, *format)
+		if err == nil && failed > 0 {
+			fmt.Fprintf(os.Stderr, "calc: failed orders: %d\n", failed)
+		}
+		return err
+	}
+
+	packSizes, err := app.ParsePackSizes(*sizes)
+	if err != nil {
+		return err
+	}
+	result, err := app.CalculateOffline(calculator, dto.CalculatePacksRequest{ItemsOrdered: *items, PackSizes: packSizes, Explain: *explain})
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case app.CalcFormatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case app.CalcFormatCSV:
+		table := spreadsheet.NewCSVWriter(os.Stdout)
+		if err := table.Write(dto.BatchItemColumns); err != nil {
+			return err
+		}
+		if err := table.Write(dto.BatchItemResult{Result: &result}.Row(*items)); err != nil {
+			return err
+		}
+		return table.Close()
+	default:
+		return fmt.Errorf("%w: %q", app.ErrUnknownCalcFormat, *format)
+	}
+}
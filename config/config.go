@@ -2,104 +2,854 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// Config holds the complete application configuration.
+// Config holds the complete application configuration. Fields tagged
+// `secret:"true"` hold secrets, and those tagged `secret:"url"` URLs that
+// may carry credentials; configuration shared outside the service, such as
+// in support bundles, must have them redacted.
 type Config struct {
-	Server   ServerConfig
-	Cache    CacheConfig
-	Auth     AuthConfig
-	Database DatabaseConfig
+	// Environment names the deployment, e.g. "production", "development" or "test".
+	Environment string
+	Server      ServerConfig
+	Cache       CacheConfig
+	Auth        AuthConfig
+	Database    DatabaseConfig
+	Alerting    AlertingConfig
+	Seed        SeedConfig
+	Batch       BatchConfig
+	Heavy       HeavyConfig
+	Jobs        JobsConfig
+	Tracing     TracingConfig
+	Metrics     MetricsConfig
+	EdgeCache   EdgeCacheConfig
+	GeoFence    GeoFenceConfig
+	IPFilter    IPFilterConfig
+	I18n        I18nConfig
+	// PackSizeRules are the rules stored pack size configurations follow.
+	PackSizeRules PackSizeRulesConfig
+	// Presentation holds the rules laying out calculated results.
+	Presentation PresentationConfig
+	// Deterministic makes generated IDs, tokens and timestamps repeat
+	// across runs, for contract and integration tests.
+	Deterministic DeterministicConfig
+	Log           LogConfig
+	// Reload controls reloading settings without a restart.
+	Reload ReloadConfig
+}
+
+// IsDevelopment reports whether the service runs in a development or test environment.
+func (c Config) IsDevelopment() bool {
+	switch strings.ToLower(c.Environment) {
+	case "development", "dev", "local", "test":
+		return true
+	default:
+		return false
+	}
 }
 
 // ServerConfig holds HTTP server configuration.
 type ServerConfig struct {
-	Port          string
-	RateLimit     int
-	RateWindow    time.Duration
-	CORSOrigins   []string
-	SwaggerUser   string
-	SwaggerPass   string
+	Port        string
+	RateLimit   int
+	RateWindow  time.Duration
+	CORSOrigins []string
+	// TrustedProxies are the IPs and CIDR ranges of the proxies trusted to
+	// set X-Forwarded-For; the client IP of requests from other peers is the
+	// peer's.
+	TrustedProxies []string
+	// SwaggerEnabled serves Swagger UI under /docs.
+	SwaggerEnabled bool
+	// SwaggerUser and SwaggerPass protect /docs with basic auth outside
+	// development environments.
+	SwaggerUser string
+	SwaggerPass string `secret:"true"`
+	// GRPCEnabled serves the gRPC API on GRPCPort alongside HTTP.
+	GRPCEnabled bool
+	GRPCPort    string
+	// DisplayTimezone is the IANA timezone reports show timestamps in.
+	// Timestamps are always stored in UTC; empty means UTC.
+	DisplayTimezone string
+	// MaxCompute caps the compute time a calculate request may ask for with
+	// max_compute_ms; zero leaves it uncapped.
+	MaxCompute time.Duration
+	// MaxBodyBytes caps the request body of the calculate and auth
+	// endpoints; zero leaves it unlimited.
+	MaxBodyBytes int
+	// ProblemDetails sends every error response as RFC 7807 problem
+	// details; otherwise only clients accepting application/problem+json
+	// get them.
+	ProblemDetails bool
+	// DecimalPrecision is the number of decimal places, up to 6, decimal
+	// quantity calculations are exact to.
+	DecimalPrecision int
+	// MaxItemsOrdered is the largest order calculated; larger ones are
+	// refused with 422 since the calculation's memory grows with the order.
+	MaxItemsOrdered int
+	// BoundedSearchThreshold is the order size from which the calculator
+	// searches in memory bounded by the largest pack size; zero disables it.
+	BoundedSearchThreshold int
+	// ExactSearchMaxTotals caps the table of totals, 16 bytes each, searched
+	// for large orders the bounded search cannot solve exactly; larger ones
+	// get an approximate result. Zero removes the cap.
+	ExactSearchMaxTotals int
+	// PageCursorKey is the base64 AES key (16, 24 or 32 bytes) encrypting
+	// listing page cursors. Instances behind one load balancer must share
+	// it; empty uses a random key per instance.
+	PageCursorKey string `secret:"true"`
+	// PageCursorTTL is how long a page cursor stays valid.
+	PageCursorTTL time.Duration
+	// RequestValidation checks JSON request bodies against the OpenAPI
+	// document: "off" (default), "report" logs the violations, "enforce"
+	// rejects them with 400.
+	RequestValidation string
+	// HTTP2 serves HTTP/2 alongside HTTP/1.1: negotiated over TLS, and with
+	// prior knowledge (h2c) over plain HTTP.
+	HTTP2 bool
+	// TLS serves HTTP and gRPC over TLS when a certificate is configured.
+	TLS TLSConfig
+}
+
+// TLSConfig holds the TLS settings of the HTTP and gRPC servers.
+type TLSConfig struct {
+	// CertFile and KeyFile are the PEM certificate chain and private key;
+	// empty serves plain HTTP.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is a PEM bundle of the CAs client certificates must be
+	// issued by (mTLS); empty does not ask for client certificates.
+	ClientCAFile string
+	// ClientAuth is "require" (default), rejecting clients without a
+	// certificate, or "optional", verifying the certificates clients send.
+	ClientAuth string
+	// ReloadInterval is how often the files are checked for changes,
+	// loading new certificates without a restart; zero never reloads.
+	ReloadInterval time.Duration
+}
+
+// Enabled reports whether TLS is configured.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
 }
 
 // CacheConfig holds cache configuration.
 type CacheConfig struct {
+	// Backend selects the result cache: "memory" (default) or "redis".
+	Backend   string
 	Size      int
 	TTL       time.Duration
 	PackSizes []int
+	Redis     RedisConfig
+	// Compression is "none", "snappy" or "zstd"; values smaller than
+	// CompressionThreshold bytes are stored uncompressed.
+	Compression          string
+	CompressionThreshold int
+	// SnapshotPath is where the in-memory cache's hot entries are saved on
+	// shutdown and restored from on startup; empty disables snapshots.
+	SnapshotPath string
+	// SnapshotMaxAge discards snapshots older than this on startup.
+	SnapshotMaxAge time.Duration
+	// SnapshotMaxEntries caps the entries saved; zero saves up to Size.
+	SnapshotMaxEntries int
+	// Eviction is the in-memory cache's eviction policy: "lru" (default) or
+	// "lfu", which keeps frequently requested quantities through bursts of
+	// one-off ones.
+	Eviction string
+	// ResponseTTL is how long the responses of the pack size and admin
+	// listing endpoints are cached in memory; zero disables response caching.
+	ResponseTTL time.Duration
+	// ResponseMaxEntries caps the cached responses.
+	ResponseMaxEntries int
+	// WarmupQuantities are order quantities whose results are calculated
+	// ahead of requests, on startup and after the pack sizes change.
+	WarmupQuantities []int
+	// WarmupTop adds the most requested quantities of the calculation
+	// history over WarmupWindow to the warm-up; zero disables it.
+	WarmupTop    int
+	WarmupWindow time.Duration
+	// WarmupInterval repeats the warm-up, so the warmed results outlive the
+	// cache TTL and follow shifts in demand; zero warms only on startup and
+	// pack size changes.
+	WarmupInterval time.Duration
+}
+
+// RedisConfig holds Redis connection settings for the redis cache backend.
+type RedisConfig struct {
+	Addr             string
+	Password         string `secret:"true"`
+	DB               int
+	PoolSize         int
+	MinIdleConns     int
+	DialTimeout      time.Duration
+	OperationTimeout time.Duration
+	KeyPrefix        string
 }
 
 // AuthConfig holds authentication configuration.
 type AuthConfig struct {
 	Enabled          bool
-	APIKeys          map[string]bool
-	JWTSecretKey     string
-	JWTRefreshSecret string
+	APIKeys          map[string]bool `secret:"true"`
+	JWTSecretKey     string          `secret:"true"`
+	JWTRefreshSecret string          `secret:"true"`
 	AccessTokenTTL   time.Duration
 	RefreshTokenTTL  time.Duration
+	// DPoPRequired rejects tokens that are not bound to a client key with a DPoP proof.
+	DPoPRequired    bool
+	DPoPProofMaxAge time.Duration
+	// BlacklistCache caches token blacklist lookups: "none" (default) or
+	// "redis", using the REDIS_* connection settings.
+	BlacklistCache string
+	// BlacklistCacheTTL is how long lookups answered by MongoDB are cached.
+	BlacklistCacheTTL time.Duration
+	// TokenRevocation selects how logout revokes an access token: "blacklist"
+	// (default) stores it and checks every request against the blacklist,
+	// "version" skips the blacklist and lets the token expire on its own.
+	TokenRevocation string
+	// TokenVersionCacheTTL is how long a user's token version is cached in
+	// memory when no change stream reports updates.
+	TokenVersionCacheTTL time.Duration
+	// DegradedMode keeps serving requests with access tokens while the auth
+	// repositories' circuit breaker is open: tokens are checked by signature
+	// and expiry only, and permissions with the roles last read.
+	DegradedMode bool
+	// LockoutThreshold locks an account after this many consecutive failed
+	// logins; zero disables account lockout.
+	LockoutThreshold int
+	// LockoutIPThreshold locks a client IP out after this many consecutive
+	// failed logins across accounts; zero disables IP lockout.
+	LockoutIPThreshold int
+	// LockoutDuration is how long a lockout lasts, and how long failures
+	// count towards one.
+	LockoutDuration time.Duration
+	// LegacyTokenCutoff is when refresh tokens stored in plaintext, from
+	// before they were hashed, stop being accepted and are deleted. Zero
+	// keeps accepting them, and the startup migration hashes them instead.
+	LegacyTokenCutoff time.Time
+	// SecretKeyCutoff is when tokens without kid, signed with JWTSecretKey
+	// or JWTRefreshSecret rather than a signing key, stop being accepted.
+	// Zero keeps accepting them.
+	SecretKeyCutoff time.Time
+	// LegacyTokenBatchSize is how many plaintext refresh tokens the startup
+	// migration handles per batch; zero disables the migration.
+	LegacyTokenBatchSize int
+	// StaleAccountDays is how many days without a login make an account
+	// stale in the stale account report.
+	StaleAccountDays int
+	// StaleAccountReportInterval is how often the stale account report is
+	// sent; zero disables it.
+	StaleAccountReportInterval time.Duration
+	// StaleAccountDeactivateDays deactivates accounts without a login for
+	// this many days when the report runs; zero disables deactivation.
+	StaleAccountDeactivateDays int
+	// DeletedUserRetention is how long a deactivated user is kept before
+	// it can be purged.
+	DeletedUserRetention time.Duration
+	// LoginAnomalyDetection audits and alerts on suspicious logins.
+	LoginAnomalyDetection bool
+	// LoginAnomalyFailures is how many failed logins before a successful
+	// one make it suspicious; zero disables the check.
+	LoginAnomalyFailures int
+	// LoginAnomalyWindow is how long failed logins are counted, and how
+	// recent a login from another country makes a new one suspicious.
+	LoginAnomalyWindow time.Duration
+	// TokenCleanupInterval is how often expired refresh and blacklist tokens
+	// are deleted; zero leaves them to the TTL index alone.
+	TokenCleanupInterval time.Duration
+	// RequiredRouteGroups lists route groups, such as "pack-sizes", that
+	// require an API key even when auth is disabled. Ignored with JWT auth,
+	// which always protects every group.
+	RequiredRouteGroups []string
+	// JWKSURL also accepts service tokens, RS256 access tokens signed by a
+	// key of the JSON Web Key Set at this URL, from other services. Empty
+	// accepts only tokens issued here.
+	JWKSURL string
+	// JWKSRefreshInterval is how often the key set is fetched again.
+	JWKSRefreshInterval time.Duration
+	// ServiceTokenIssuer and ServiceTokenAudience are the iss and aud
+	// claims service tokens must carry; empty accepts any.
+	ServiceTokenIssuer   string
+	ServiceTokenAudience string
+	// ServiceTokenRoles are the role IDs granted to service tokens.
+	ServiceTokenRoles []string
+	// SigningKeyRefreshInterval is how often the JWT signing keys are
+	// reloaded from MongoDB, and how long a new key waits before it signs.
+	SigningKeyRefreshInterval time.Duration
 }
 
 // DatabaseConfig holds MongoDB configuration.
 type DatabaseConfig struct {
-	URI          string
+	URI          string `secret:"url"`
 	DatabaseName string
 	LogsTTL      time.Duration
 	Enabled      bool
+	// LogsRetentionByLevel keeps log entries of a level, such as "error",
+	// for their own duration instead of LogsTTL.
+	LogsRetentionByLevel map[string]time.Duration
+	// LogsMaxEntries caps the stored log entries, deleting the oldest; zero
+	// keeps any number.
+	LogsMaxEntries int
+	// LogsPurgeInterval is how often LogsRetentionByLevel and LogsMaxEntries
+	// are applied; zero disables them.
+	LogsPurgeInterval time.Duration
+	// LogsBufferSize is how many log entries are queued for writing in
+	// batches; zero writes each entry as it is logged.
+	LogsBufferSize int
+	// LogsBatchSize is how many queued log entries are written at once.
+	LogsBatchSize int
+	// LogsFlushInterval is how often queued log entries are written when
+	// there is no full batch.
+	LogsFlushInterval time.Duration
+	// LogsOverflowPolicy is what happens to a log entry when the queue is
+	// full: drop_newest, drop_oldest or block.
+	LogsOverflowPolicy string
 	// CircuitBreaker configuration
 	CircuitBreakerFailureThreshold int
 	CircuitBreakerSuccessThreshold int
 	CircuitBreakerTimeout          time.Duration
+	// AuthCircuitBreakerFailureThreshold, AuthCircuitBreakerSuccessThreshold
+	// and AuthCircuitBreakerTimeout configure the circuit breaker of the
+	// user, role, permission and token repositories. They default to the
+	// CircuitBreaker values.
+	AuthCircuitBreakerFailureThreshold int
+	AuthCircuitBreakerSuccessThreshold int
+	AuthCircuitBreakerTimeout          time.Duration
+	// ClientUsageFlushInterval is how often client version stats are written.
+	ClientUsageFlushInterval time.Duration
+	// CalculationHistoryFlushInterval is how often buffered calculations are written.
+	CalculationHistoryFlushInterval time.Duration
+	// CalculationRollupInterval is how often the daily calculation rollups
+	// are recomputed; zero disables the rollup job.
+	CalculationRollupInterval time.Duration
+	// CalculationRollupLookbackDays is how many days, today included, each
+	// rollup run recomputes.
+	CalculationRollupLookbackDays int
+	// HedgedReadDelay is how long the token blacklist and user-by-ID lookups
+	// wait for MongoDB before also asking a secondary; zero disables hedging.
+	HedgedReadDelay time.Duration
+	// PackSizesPollInterval is how often the active pack sizes are read
+	// while the change stream reporting their changes is down.
+	PackSizesPollInterval time.Duration
+}
+
+// AlertingConfig holds operational alerting configuration.
+type AlertingConfig struct {
+	Enabled bool
+	// BreakerTripThreshold alerts when a breaker opens this many times within BreakerTripWindow.
+	BreakerTripThreshold int
+	BreakerTripWindow    time.Duration
+	// Cooldown is the minimum time between alerts for the same breaker.
+	Cooldown   time.Duration
+	WebhookURL string `secret:"url"`
+	SMTPAddr   string
+	SMTPUser   string
+	SMTPPass   string `secret:"true"`
+	EmailFrom  string
+	EmailTo    []string
+	// WebhookProbeInterval is how often the webhook is probed. Delivery is
+	// paused after WebhookFailureThreshold consecutive failures, retrying with
+	// a doubling pause up to WebhookMaxPause.
+	WebhookProbeInterval    time.Duration
+	WebhookProbeTimeout     time.Duration
+	WebhookFailureThreshold int
+	WebhookMaxPause         time.Duration
+}
+
+// BatchConfig holds the worker pool settings for batch calculations.
+type BatchConfig struct {
+	// Workers is the number of batch items calculated at once across all
+	// requests; zero uses the CPU count.
+	Workers int
+	// MaxConcurrencyPerRequest caps the workers one batch may use; zero uses half the workers.
+	MaxConcurrencyPerRequest int
+	// QueueTimeout is how long an item waits for a worker before the rest of its batch is rejected.
+	QueueTimeout time.Duration
+	// StreamJobTTL is how long a submitted stream job waits for its client to open the stream.
+	StreamJobTTL time.Duration
+	// MaxStreamJobs caps the stream jobs waiting at once.
+	MaxStreamJobs int
+}
+
+// HeavyConfig caps the CPU-heavy requests, batch and streamed calculations,
+// running at once, apart from normal traffic, so a few of them cannot slow
+// down every single calculation.
+type HeavyConfig struct {
+	// MaxConcurrent is the number of heavy requests served at once across
+	// all callers; zero leaves them uncapped.
+	MaxConcurrent int
+	// MaxQueued caps the heavy requests waiting for a turn; more are
+	// rejected with 503 right away. Zero leaves the queue unbounded.
+	MaxQueued int
+	// QueueTimeout is how long a heavy request waits for a turn before it
+	// is rejected with 503.
+	QueueTimeout time.Duration
+}
+
+// I18nConfig holds the settings of user-facing messages.
+type I18nConfig struct {
+	// OverrideRefreshInterval is how often the messages tenants replaced are
+	// reloaded, picking up changes made through other instances; zero
+	// disables reloading.
+	OverrideRefreshInterval time.Duration
+	// CatalogDir holds message catalogs, such as fr.json or pt-br.yaml,
+	// registered at startup to add locales or replace shipped messages.
+	CatalogDir string
+}
+
+// PresentationConfig holds the settings of result presentation rules.
+type PresentationConfig struct {
+	// RulesFile is the YAML file of the default and per-tenant rule sets;
+	// empty presents results as calculated.
+	RulesFile string
+}
+
+// JobsConfig holds the settings of asynchronous calculation jobs, which
+// require the database.
+type JobsConfig struct {
+	// Workers is the number of jobs this instance runs at once.
+	Workers int
+	// Retention is how long finished jobs and their results are kept.
+	Retention time.Duration
+	// Timeout bounds one run of a job.
+	Timeout time.Duration
+	// MaxUnfinished caps the queued and running jobs across all instances.
+	MaxUnfinished int
+	// PollInterval is how often idle workers look for jobs queued by other instances.
+	PollInterval time.Duration
 }
 
-// Load creates a Config from environment variables.
+// TracingConfig holds OpenTelemetry tracing configuration. Other OTLP
+// exporter settings, such as OTEL_EXPORTER_OTLP_HEADERS, are read by the
+// exporter itself.
+type TracingConfig struct {
+	Enabled bool
+	// Endpoint is the OTLP/HTTP collector URL spans are exported to.
+	Endpoint string
+	// ServiceName identifies the service in traces.
+	ServiceName string
+	// SampleRatio is the fraction of traces started here that are sampled;
+	// requests that arrive with a sampled parent are always traced.
+	SampleRatio float64
+}
+
+// MetricsConfig limits the label cardinality of Prometheus metrics.
+type MetricsConfig struct {
+	// ClientLabels is "raw", "hash" or "bucket": how client identifiers such
+	// as API key fingerprints appear in labels.
+	ClientLabels string
+	// ClientLabelBuckets is the number of buckets in "bucket" mode.
+	ClientLabelBuckets int
+	// MaxLabelValues caps the distinct values of unbounded labels; zero
+	// means no cap.
+	MaxLabelValues int
+	// MaxPackSets caps the distinct pack size sets labelled; zero means no cap.
+	MaxPackSets int
+}
+
+// EdgeCacheConfig holds the caching of calculate responses by an API gateway
+// or CDN.
+type EdgeCacheConfig struct {
+	// MaxAge is how long shared caches may serve an anonymous calculate
+	// response; zero disables edge caching.
+	MaxAge time.Duration
+	// PurgeURL is POSTed to purge a surrogate key when the pack sizes
+	// change, with "{key}" replaced by the key; empty disables purging.
+	PurgeURL string `secret:"url"`
+	// PurgeToken is sent as a bearer token with purge requests.
+	PurgeToken string `secret:"true"`
+}
+
+// PackSizeRulesConfig holds the rules pack sizes must follow to be saved
+// through the API.
+type PackSizeRulesConfig struct {
+	// MaxSizes is the most sizes a configuration may have.
+	MaxSizes int
+	// MinSize and MaxSize bound each pack size.
+	MinSize int
+	MaxSize int
+	// CommonDivisorWarning warns when every size is a multiple of a number
+	// at least this large; zero disables the warning.
+	CommonDivisorWarning int
+}
+
+// GeoFenceConfig holds the CIDR ranges and countries requests may come
+// from. Admins can change the rules at runtime.
+type GeoFenceConfig struct {
+	// AllowedCIDRs are client IP ranges allowed; with AllowedCountries empty
+	// too, every request is allowed.
+	AllowedCIDRs []string
+	// AllowedCountries are ISO 3166-1 alpha-2 codes of allowed countries.
+	AllowedCountries []string
+	// CountryHeader is the header the edge sets to the client's country.
+	CountryHeader string
+	// TrustedProxies are the CIDR ranges of the edge proxies trusted to set
+	// CountryHeader and X-Forwarded-For; the headers of other peers are
+	// ignored.
+	TrustedProxies []string
+}
+
+// IPFilterConfig holds the IP ranges requests are allowed or denied from.
+// Admins can add and delete rules at runtime; those are stored in MongoDB.
+type IPFilterConfig struct {
+	// AllowCIDRs are client IP ranges allowed; once any allow rule exists,
+	// requests from other IPs are rejected.
+	AllowCIDRs []string
+	// DenyCIDRs are client IP ranges rejected.
+	DenyCIDRs []string
+	// RefreshInterval is how often the stored rules are reloaded, picking up
+	// changes made through other instances; zero disables reloading.
+	RefreshInterval time.Duration
+}
+
+// SeedConfig holds development seed data configuration.
+type SeedConfig struct {
+	// Dir is a directory of YAML fixtures loaded at startup; ignored outside development.
+	Dir string
+}
+
+// DeterministicConfig holds test-mode configuration.
+type DeterministicConfig struct {
+	// Enabled seeds generated IDs and token nonces and freezes the clock;
+	// ignored outside development and test environments.
+	Enabled bool
+	// Seed selects the sequence of generated IDs.
+	Seed int
+	// Clock is the instant the clock is frozen at; zero uses
+	// timeutil.DefaultFrozenTime.
+	Clock time.Time
+}
+
+// LogConfig holds logging configuration.
+type LogConfig struct {
+	// Level is "debug", "info" (default), "warn" or "error".
+	Level string
+	// SuccessSampleRate is the fraction of requests answered below 400 that
+	// are logged, above 0 and up to 1 (default). Failed requests are always
+	// logged.
+	SuccessSampleRate float64
+	// RedactFields names the log entry fields masked before entries are
+	// stored, such as "password"; a name also covers fields ending in
+	// "_<name>".
+	RedactFields []string
+}
+
+// ReloadConfig holds configuration reload settings.
+type ReloadConfig struct {
+	// File is a file of KEY=VALUE lines overriding the environment, read on
+	// startup and on every reload; empty reads the environment alone.
+	File string
+	// WatchInterval is how often File is checked for changes, reloading it
+	// when modified; zero reloads on SIGHUP and admin request only.
+	WatchInterval time.Duration
+}
+
+// fileValues holds the settings of the config file while Load reads them;
+// loadMu serializes Load.
+var (
+	loadMu     sync.Mutex
+	fileValues map[string]string
+)
+
+// Load creates a Config from environment variables, overridden by the
+// settings of CONFIG_FILE when set. A config file that cannot be read is
+// ignored, as LoadFile reports.
 func Load() Config {
+	cfg, _ := LoadFile(os.Getenv("CONFIG_FILE"))
+	return cfg
+}
+
+// LoadFile creates a Config from environment variables, overridden by the
+// KEY=VALUE lines of the file at path; an empty path reads the environment
+// alone. Blank lines and lines starting with # are skipped, and values may
+// be quoted. When the file cannot be read or has an invalid line, it returns
+// the Config of the environment alone with the error.
+func LoadFile(path string) (Config, error) {
+	values, err := readConfigFile(path)
+
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	fileValues = values
+	defer func() { fileValues = nil }()
+
+	cfg := load()
+	cfg.Reload.File = path
+	return cfg, err
+}
+
+// readConfigFile parses the KEY=VALUE lines of the file at path.
+func readConfigFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	values := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("config file %s line %d: expected KEY=VALUE", path, i+1)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// load creates a Config from the environment and fileValues.
+func load() Config {
 	return Config{
+		Environment: getEnv("APP_ENV", "production"),
 		Server: ServerConfig{
-			Port:        getEnv("PORT", "8080"),
-			RateLimit:   getEnvInt("RATE_LIMIT", 100),
-			RateWindow:  getEnvDuration("RATE_WINDOW", time.Minute),
-			CORSOrigins: parseCORSOrigins(os.Getenv("CORS_ORIGINS")),
-			SwaggerUser: getEnv("SWAGGER_USER", ""),
-			SwaggerPass: getEnv("SWAGGER_PASS", ""),
+			Port:                   getEnv("PORT", "8080"),
+			RateLimit:              getEnvInt("RATE_LIMIT", 100),
+			RateWindow:             getEnvDuration("RATE_WINDOW", time.Minute),
+			CORSOrigins:            parseCORSOrigins(lookupEnv("CORS_ORIGINS")),
+			TrustedProxies:         parseStringSlice(lookupEnv("TRUSTED_PROXIES")),
+			SwaggerEnabled:         getEnvBool("SWAGGER_ENABLED", false),
+			SwaggerUser:            getEnv("SWAGGER_USER", ""),
+			SwaggerPass:            getEnv("SWAGGER_PASS", ""),
+			GRPCEnabled:            getEnvBool("GRPC_ENABLED", false),
+			GRPCPort:               getEnv("GRPC_PORT", "9090"),
+			DisplayTimezone:        getEnv("DISPLAY_TIMEZONE", ""),
+			MaxCompute:             getEnvDuration("MAX_COMPUTE_TIME", time.Second),
+			MaxBodyBytes:           getEnvInt("MAX_REQUEST_BODY_BYTES", 64<<10),
+			HTTP2:                  getEnvBool("HTTP2_ENABLED", true),
+			ProblemDetails:         getEnvBool("PROBLEM_DETAILS_ERRORS", false),
+			DecimalPrecision:       getEnvInt("DECIMAL_PRECISION", 3),
+			MaxItemsOrdered:        getEnvInt("MAX_ITEMS_ORDERED", 10_000_000),
+			BoundedSearchThreshold: getEnvInt("BOUNDED_SEARCH_THRESHOLD", 1_000_000),
+			ExactSearchMaxTotals:   getEnvInt("EXACT_SEARCH_MAX_TOTALS", 1<<24),
+			PageCursorKey:          getEnv("PAGE_CURSOR_KEY", ""),
+			PageCursorTTL:          getEnvDuration("PAGE_CURSOR_TTL", time.Hour),
+			RequestValidation:      strings.ToLower(getEnv("REQUEST_VALIDATION", "off")),
+			TLS: TLSConfig{
+				CertFile:       getEnv("TLS_CERT_FILE", ""),
+				KeyFile:        getEnv("TLS_KEY_FILE", ""),
+				ClientCAFile:   getEnv("TLS_CLIENT_CA_FILE", ""),
+				ClientAuth:     strings.ToLower(getEnv("TLS_CLIENT_AUTH", "require")),
+				ReloadInterval: getEnvDuration("TLS_RELOAD_INTERVAL", time.Minute),
+			},
 		},
 		Cache: CacheConfig{
+			Backend:   strings.ToLower(getEnv("CACHE_BACKEND", "memory")),
 			Size:      getEnvInt("CACHE_SIZE", 1000),
 			TTL:       getEnvDuration("CACHE_TTL", 5*time.Minute),
-			PackSizes: parseIntSlice(os.Getenv("PACK_SIZES")),
+			PackSizes: parseIntSlice(lookupEnv("PACK_SIZES")),
+			Compression:          strings.ToLower(getEnv("CACHE_COMPRESSION", "none")),
+			CompressionThreshold: getEnvInt("CACHE_COMPRESSION_THRESHOLD", 1024),
+			SnapshotPath:         getEnv("CACHE_SNAPSHOT_PATH", ""),
+			SnapshotMaxAge:       getEnvDuration("CACHE_SNAPSHOT_MAX_AGE", time.Hour),
+			SnapshotMaxEntries:   getEnvInt("CACHE_SNAPSHOT_MAX_ENTRIES", 0),
+			Eviction:             strings.ToLower(getEnv("CACHE_EVICTION", "lru")),
+			ResponseTTL:          getEnvDuration("RESPONSE_CACHE_TTL", 0),
+			ResponseMaxEntries:   getEnvInt("RESPONSE_CACHE_MAX_ENTRIES", 1000),
+			WarmupQuantities:     parseIntSlice(lookupEnv("CACHE_WARMUP_QUANTITIES")),
+			WarmupTop:            getEnvInt("CACHE_WARMUP_TOP", 0),
+			WarmupWindow:         getEnvDuration("CACHE_WARMUP_WINDOW", 7*24*time.Hour),
+			WarmupInterval:       getEnvDuration("CACHE_WARMUP_INTERVAL", 0),
+			Redis: RedisConfig{
+				Addr:             getEnv("REDIS_ADDR", "localhost:6379"),
+				Password:         getEnv("REDIS_PASSWORD", ""),
+				DB:               getEnvInt("REDIS_DB", 0),
+				PoolSize:         getEnvInt("REDIS_POOL_SIZE", 0),
+				MinIdleConns:     getEnvInt("REDIS_MIN_IDLE_CONNS", 0),
+				DialTimeout:      getEnvDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+				OperationTimeout: getEnvDuration("REDIS_OPERATION_TIMEOUT", 100*time.Millisecond),
+				KeyPrefix:        getEnv("REDIS_KEY_PREFIX", "pack-service:calc"),
+			},
 		},
 		Auth: AuthConfig{
 			Enabled:          getEnvBool("AUTH_ENABLED", false),
-			APIKeys:          parseAPIKeys(os.Getenv("API_KEYS")),
+			APIKeys:          parseAPIKeys(lookupEnv("API_KEYS")),
 			JWTSecretKey:     getEnv("JWT_SECRET_KEY", "your-secret-key-change-in-production"),
 			JWTRefreshSecret: getEnv("JWT_REFRESH_SECRET_KEY", "your-refresh-secret-key-change-in-production"),
 			AccessTokenTTL:   getEnvDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
 			RefreshTokenTTL:  getEnvDuration("JWT_REFRESH_TOKEN_TTL", 7*24*time.Hour),
+			DPoPRequired:     getEnvBool("AUTH_DPOP_REQUIRED", false),
+			DPoPProofMaxAge:  getEnvDuration("AUTH_DPOP_PROOF_MAX_AGE", time.Minute),
+			BlacklistCache:    strings.ToLower(getEnv("AUTH_BLACKLIST_CACHE", "none")),
+			BlacklistCacheTTL: getEnvDuration("AUTH_BLACKLIST_CACHE_TTL", 30*time.Second),
+			TokenRevocation:      strings.ToLower(getEnv("AUTH_TOKEN_REVOCATION", "blacklist")),
+			TokenVersionCacheTTL: getEnvDuration("AUTH_TOKEN_VERSION_CACHE_TTL", time.Minute),
+			DegradedMode:         getEnvBool("AUTH_DEGRADED_MODE", true),
+			LockoutThreshold:   getEnvInt("AUTH_LOCKOUT_THRESHOLD", 5),
+			LockoutIPThreshold: getEnvInt("AUTH_LOCKOUT_IP_THRESHOLD", 20),
+			LockoutDuration:    getEnvDuration("AUTH_LOCKOUT_DURATION", 15*time.Minute),
+			LegacyTokenCutoff:    getEnvTime("AUTH_LEGACY_TOKEN_CUTOFF"),
+			LegacyTokenBatchSize: getEnvInt("AUTH_LEGACY_TOKEN_BATCH_SIZE", 500),
+			SecretKeyCutoff:      getEnvTime("AUTH_SECRET_KEY_CUTOFF"),
+			StaleAccountDays:           getEnvInt("AUTH_STALE_ACCOUNT_DAYS", 90),
+			StaleAccountReportInterval: getEnvDuration("AUTH_STALE_ACCOUNT_REPORT_INTERVAL", 24*time.Hour),
+			StaleAccountDeactivateDays: getEnvInt("AUTH_STALE_ACCOUNT_DEACTIVATE_DAYS", 0),
+			DeletedUserRetention:       getEnvDuration("AUTH_DELETED_USER_RETENTION", 30*24*time.Hour),
+			LoginAnomalyDetection:      getEnvBool("AUTH_LOGIN_ANOMALY_DETECTION", true),
+			LoginAnomalyFailures:       getEnvInt("AUTH_LOGIN_ANOMALY_FAILURES", 5),
+			LoginAnomalyWindow:         getEnvDuration("AUTH_LOGIN_ANOMALY_WINDOW", time.Hour),
+			TokenCleanupInterval:       getEnvDuration("AUTH_TOKEN_CLEANUP_INTERVAL", time.Hour),
+			RequiredRouteGroups:        parseStringSlice(lookupEnv("AUTH_REQUIRED_ROUTE_GROUPS")),
+			JWKSURL:                    getEnv("AUTH_JWKS_URL", ""),
+			JWKSRefreshInterval:        getEnvDuration("AUTH_JWKS_REFRESH_INTERVAL", time.Hour),
+			ServiceTokenIssuer:         getEnv("AUTH_SERVICE_TOKEN_ISSUER", ""),
+			ServiceTokenAudience:       getEnv("AUTH_SERVICE_TOKEN_AUDIENCE", ""),
+			ServiceTokenRoles:          parseStringSlice(lookupEnv("AUTH_SERVICE_TOKEN_ROLES")),
+			SigningKeyRefreshInterval:  getEnvDuration("AUTH_SIGNING_KEY_REFRESH_INTERVAL", time.Minute),
 		},
 		Database: DatabaseConfig{
 			URI:                            getEnv("MONGODB_URI", "mongodb://localhost:27017"),
 			DatabaseName:                   getEnv("MONGODB_DATABASE", "pack_service"),
 			LogsTTL:                        getEnvDuration("MONGODB_LOGS_TTL", 30*24*time.Hour),
+			LogsRetentionByLevel:            parseDurationMap(lookupEnv("MONGODB_LOGS_RETENTION_BY_LEVEL")),
+			LogsMaxEntries:                  getEnvInt("MONGODB_LOGS_MAX_ENTRIES", 0),
+			LogsPurgeInterval:               getEnvDuration("MONGODB_LOGS_PURGE_INTERVAL", time.Hour),
+			LogsBufferSize:                  getEnvInt("MONGODB_LOGS_BUFFER_SIZE", 10000),
+			LogsBatchSize:                   getEnvInt("MONGODB_LOGS_BATCH_SIZE", 500),
+			LogsFlushInterval:               getEnvDuration("MONGODB_LOGS_FLUSH_INTERVAL", time.Second),
+			LogsOverflowPolicy:              getEnv("MONGODB_LOGS_OVERFLOW_POLICY", "drop_newest"),
 			Enabled:                        getEnvBool("MONGODB_ENABLED", false),
 			CircuitBreakerFailureThreshold: getEnvInt("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5),
 			CircuitBreakerSuccessThreshold: getEnvInt("CIRCUIT_BREAKER_SUCCESS_THRESHOLD", 2),
 			CircuitBreakerTimeout:          getEnvDuration("CIRCUIT_BREAKER_TIMEOUT", 30*time.Second),
+			ClientUsageFlushInterval:       getEnvDuration("CLIENT_USAGE_FLUSH_INTERVAL", 30*time.Second),
+			CalculationHistoryFlushInterval: getEnvDuration("CALCULATION_HISTORY_FLUSH_INTERVAL", 5*time.Second),
+			CalculationRollupInterval:       getEnvDuration("CALCULATION_ROLLUP_INTERVAL", time.Hour),
+			CalculationRollupLookbackDays:   getEnvInt("CALCULATION_ROLLUP_LOOKBACK_DAYS", 2),
+			HedgedReadDelay:                 getEnvDuration("MONGODB_HEDGED_READ_DELAY", 0),
+			PackSizesPollInterval:           getEnvDuration("PACK_SIZES_POLL_INTERVAL", 30*time.Second),
+
+			AuthCircuitBreakerFailureThreshold: getEnvInt("AUTH_CIRCUIT_BREAKER_FAILURE_THRESHOLD", getEnvInt("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5)),
+			AuthCircuitBreakerSuccessThreshold: getEnvInt("AUTH_CIRCUIT_BREAKER_SUCCESS_THRESHOLD", getEnvInt("CIRCUIT_BREAKER_SUCCESS_THRESHOLD", 2)),
+			AuthCircuitBreakerTimeout:          getEnvDuration("AUTH_CIRCUIT_BREAKER_TIMEOUT", getEnvDuration("CIRCUIT_BREAKER_TIMEOUT", 30*time.Second)),
+		},
+		Alerting: AlertingConfig{
+			Enabled:              getEnvBool("ALERTING_ENABLED", false),
+			BreakerTripThreshold: getEnvInt("ALERT_BREAKER_TRIP_THRESHOLD", 3),
+			BreakerTripWindow:    getEnvDuration("ALERT_BREAKER_TRIP_WINDOW", 5*time.Minute),
+			Cooldown:             getEnvDuration("ALERT_COOLDOWN", 15*time.Minute),
+			WebhookURL:           getEnv("ALERT_WEBHOOK_URL", ""),
+			SMTPAddr:             getEnv("ALERT_SMTP_ADDR", ""),
+			SMTPUser:             getEnv("ALERT_SMTP_USER", ""),
+			SMTPPass:             getEnv("ALERT_SMTP_PASS", ""),
+			EmailFrom:            getEnv("ALERT_EMAIL_FROM", ""),
+			EmailTo:              parseStringSlice(lookupEnv("ALERT_EMAIL_TO")),
+
+			WebhookProbeInterval:    getEnvDuration("ALERT_WEBHOOK_PROBE_INTERVAL", time.Minute),
+			WebhookProbeTimeout:     getEnvDuration("ALERT_WEBHOOK_PROBE_TIMEOUT", 5*time.Second),
+			WebhookFailureThreshold: getEnvInt("ALERT_WEBHOOK_FAILURE_THRESHOLD", 3),
+			WebhookMaxPause:         getEnvDuration("ALERT_WEBHOOK_MAX_PAUSE", time.Hour),
+		},
+		Seed: SeedConfig{
+			Dir: getEnv("SEED_DIR", ""),
+		},
+		Batch: BatchConfig{
+			Workers:                  getEnvInt("BATCH_WORKERS", 0),
+			MaxConcurrencyPerRequest: getEnvInt("BATCH_MAX_CONCURRENCY_PER_REQUEST", 0),
+			QueueTimeout:             getEnvDuration("BATCH_QUEUE_TIMEOUT", 5*time.Second),
+			StreamJobTTL:             getEnvDuration("BATCH_STREAM_JOB_TTL", time.Minute),
+			MaxStreamJobs:            getEnvInt("BATCH_MAX_STREAM_JOBS", 100),
+		},
+		Heavy: HeavyConfig{
+			MaxConcurrent: getEnvInt("HEAVY_MAX_CONCURRENT", 2),
+			MaxQueued:     getEnvInt("HEAVY_MAX_QUEUED", 8),
+			QueueTimeout:  getEnvDuration("HEAVY_QUEUE_TIMEOUT", 5*time.Second),
+		},
+		Presentation: PresentationConfig{
+			RulesFile: getEnv("PRESENTATION_RULES_FILE", ""),
+		},
+		I18n: I18nConfig{
+			OverrideRefreshInterval: getEnvDuration("I18N_OVERRIDE_REFRESH_INTERVAL", time.Minute),
+			CatalogDir:              getEnv("I18N_CATALOG_DIR", ""),
+		},
+		Jobs: JobsConfig{
+			Workers:       getEnvInt("JOBS_WORKERS", 2),
+			Retention:     getEnvDuration("JOBS_RETENTION", 24*time.Hour),
+			Timeout:       getEnvDuration("JOBS_TIMEOUT", 10*time.Minute),
+			MaxUnfinished: getEnvInt("JOBS_MAX_UNFINISHED", 1000),
+			PollInterval:  getEnvDuration("JOBS_POLL_INTERVAL", time.Second),
+		},
+		Tracing: TracingConfig{
+			Enabled:     getEnvBool("TRACING_ENABLED", false),
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "pack-service"),
+			SampleRatio: getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1),
+		},
+		Metrics: MetricsConfig{
+			ClientLabels:       getEnv("METRICS_CLIENT_LABELS", "raw"),
+			ClientLabelBuckets: getEnvInt("METRICS_CLIENT_LABEL_BUCKETS", 64),
+			MaxLabelValues:     getEnvInt("METRICS_MAX_LABEL_VALUES", 500),
+			MaxPackSets:        getEnvInt("METRICS_MAX_PACK_SETS", 50),
+		},
+		EdgeCache: EdgeCacheConfig{
+			MaxAge:     getEnvDuration("EDGE_CACHE_MAX_AGE", 0),
+			PurgeURL:   getEnv("EDGE_CACHE_PURGE_URL", ""),
+			PurgeToken: getEnv("EDGE_CACHE_PURGE_TOKEN", ""),
+		},
+		GeoFence: GeoFenceConfig{
+			AllowedCIDRs:     parseStringSlice(lookupEnv("GEOFENCE_ALLOWED_CIDRS")),
+			AllowedCountries: parseStringSlice(lookupEnv("GEOFENCE_ALLOWED_COUNTRIES")),
+			CountryHeader:    getEnv("GEOFENCE_COUNTRY_HEADER", "CF-IPCountry"),
+			TrustedProxies:   parseStringSlice(lookupEnv("GEOFENCE_TRUSTED_PROXIES")),
+		},
+		IPFilter: IPFilterConfig{
+			AllowCIDRs:      parseStringSlice(lookupEnv("IP_ALLOW_CIDRS")),
+			DenyCIDRs:       parseStringSlice(lookupEnv("IP_DENY_CIDRS")),
+			RefreshInterval: getEnvDuration("IP_RULES_REFRESH_INTERVAL", 30*time.Second),
+		},
+		PackSizeRules: PackSizeRulesConfig{
+			MaxSizes:             getEnvInt("PACK_SIZE_RULES_MAX_SIZES", 100),
+			MinSize:              getEnvInt("PACK_SIZE_RULES_MIN_SIZE", 1),
+			MaxSize:              getEnvInt("PACK_SIZE_RULES_MAX_SIZE", 10_000_000),
+			CommonDivisorWarning: getEnvInt("PACK_SIZE_RULES_COMMON_DIVISOR_WARNING", 0),
+		},
+		Deterministic: DeterministicConfig{
+			Enabled: getEnvBool("DETERMINISTIC_MODE", false),
+			Seed:    getEnvInt("DETERMINISTIC_SEED", 1),
+			Clock:   getEnvTime("DETERMINISTIC_CLOCK"),
+		},
+		Log: LogConfig{
+			Level:             strings.ToLower(getEnv("LOG_LEVEL", "info")),
+			SuccessSampleRate: getEnvFloat("LOG_SUCCESS_SAMPLE_RATE", 1),
+			RedactFields:      parseStringSlice(getEnv("LOG_REDACT_FIELDS", "password,secret,token,authorization,api_key")),
+		},
+		Reload: ReloadConfig{
+			WatchInterval: getEnvDuration("CONFIG_WATCH_INTERVAL", 0),
 		},
 	}
 }
 
+// lookupEnv returns the value of the config file setting key, or else of the
+// environment variable.
+func lookupEnv(key string) string {
+	if v, ok := fileValues[key]; ok {
+		return v
+	}
+	return os.Getenv(key)
+}
+
 func getEnv(key, defaultValue string) string {
-	if v := os.Getenv(key); v != "" {
+	if v := lookupEnv(key); v != "" {
 		return v
 	}
 	return defaultValue
 }
 
 func getEnvInt(key string, defaultValue int) int {
-	if v := os.Getenv(key); v != "" {
+	if v := lookupEnv(key); v != "" {
 		if i, err := strconv.Atoi(v); err == nil {
 			return i
 		}
@@ -108,7 +858,7 @@ func getEnvInt(key string, defaultValue int) int {
 }
 
 func getEnvBool(key string, defaultValue bool) bool {
-	if v := os.Getenv(key); v != "" {
+	if v := lookupEnv(key); v != "" {
 		if b, err := strconv.ParseBool(v); err == nil {
 			return b
 		}
@@ -117,7 +867,7 @@ func getEnvBool(key string, defaultValue bool) bool {
 }
 
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if v := os.Getenv(key); v != "" {
+	if v := lookupEnv(key); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			return d
 		}
@@ -125,6 +875,31 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if v := lookupEnv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+// getEnvTime parses an RFC 3339 time or a date (UTC midnight), returning
+// the zero time when the variable is unset or invalid.
+func getEnvTime(key string) time.Time {
+	v := lookupEnv(key)
+	if v == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
 func parseIntSlice(s string) []int {
 	if s == "" {
 		return nil
@@ -139,6 +914,40 @@ func parseIntSlice(s string) []int {
 	return result
 }
 
+func parseStringSlice(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if v := strings.TrimSpace(p); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// parseDurationMap parses comma-separated key=duration pairs, such as
+// "error=2160h,warn=720h", lowercasing the keys. Invalid pairs are skipped.
+func parseDurationMap(s string) map[string]time.Duration {
+	if s == "" {
+		return nil
+	}
+	result := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || key == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(strings.TrimSpace(value)); err == nil && d > 0 {
+			result[key] = d
+		}
+	}
+	return result
+}
+
 func parseAPIKeys(s string) map[string]bool {
 	if s == "" {
 		return nil
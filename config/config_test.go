@@ -2,10 +2,13 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoad(t *testing.T) {
@@ -17,9 +20,21 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, "8080", cfg.Server.Port)
 		assert.Equal(t, 100, cfg.Server.RateLimit)
 		assert.Equal(t, time.Minute, cfg.Server.RateWindow)
+		assert.False(t, cfg.Server.SwaggerEnabled)
 		assert.Equal(t, 1000, cfg.Cache.Size)
 		assert.Equal(t, 5*time.Minute, cfg.Cache.TTL)
+		assert.Equal(t, "memory", cfg.Cache.Backend)
+		assert.Equal(t, "none", cfg.Cache.Compression)
+		assert.Equal(t, 1024, cfg.Cache.CompressionThreshold)
 		assert.False(t, cfg.Auth.Enabled)
+		assert.Equal(t, "production", cfg.Environment)
+		assert.False(t, cfg.IsDevelopment())
+		assert.Empty(t, cfg.Seed.Dir)
+		assert.Equal(t, "info", cfg.Log.Level)
+		assert.Equal(t, 1.0, cfg.Log.SuccessSampleRate)
+		assert.Equal(t, []string{"password", "secret", "token", "authorization", "api_key"}, cfg.Log.RedactFields)
+		assert.Empty(t, cfg.Reload.File)
+		assert.Zero(t, cfg.Reload.WatchInterval)
 	})
 
 	t.Run("loads values from environment", func(t *testing.T) {
@@ -32,6 +47,7 @@ func TestLoad(t *testing.T) {
 		_ = os.Setenv("PACK_SIZES", "100,200,300")
 		_ = os.Setenv("AUTH_ENABLED", "true")
 		_ = os.Setenv("API_KEYS", "key1,key2")
+		_ = os.Setenv("SWAGGER_ENABLED", "true")
 		defer os.Clearenv()
 
 		cfg := Load()
@@ -45,6 +61,7 @@ func TestLoad(t *testing.T) {
 		assert.True(t, cfg.Auth.Enabled)
 		assert.True(t, cfg.Auth.APIKeys["key1"])
 		assert.True(t, cfg.Auth.APIKeys["key2"])
+		assert.True(t, cfg.Server.SwaggerEnabled)
 	})
 
 	t.Run("handles invalid values gracefully", func(t *testing.T) {
@@ -108,4 +125,870 @@ func TestLoad(t *testing.T) {
 
 		assert.Nil(t, cfg.Auth.APIKeys)
 	})
+
+	t.Run("loads alerting configuration", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("ALERTING_ENABLED", "true")
+		_ = os.Setenv("ALERT_BREAKER_TRIP_THRESHOLD", "5")
+		_ = os.Setenv("ALERT_BREAKER_TRIP_WINDOW", "10m")
+		_ = os.Setenv("ALERT_WEBHOOK_URL", "https://hooks.example.com/alerts")
+		_ = os.Setenv("ALERT_EMAIL_TO", " oncall@example.com , ops@example.com ")
+		_ = os.Setenv("ALERT_WEBHOOK_FAILURE_THRESHOLD", "5")
+		defer os.Clearenv()
+
+		cfg := Load()
+
+		assert.True(t, cfg.Alerting.Enabled)
+		assert.Equal(t, 5, cfg.Alerting.BreakerTripThreshold)
+		assert.Equal(t, 10*time.Minute, cfg.Alerting.BreakerTripWindow)
+		assert.Equal(t, 15*time.Minute, cfg.Alerting.Cooldown)
+		assert.Equal(t, "https://hooks.example.com/alerts", cfg.Alerting.WebhookURL)
+		assert.Equal(t, []string{"oncall@example.com", "ops@example.com"}, cfg.Alerting.EmailTo)
+		assert.Equal(t, time.Minute, cfg.Alerting.WebhookProbeInterval)
+		assert.Equal(t, 5, cfg.Alerting.WebhookFailureThreshold)
+		assert.Equal(t, time.Hour, cfg.Alerting.WebhookMaxPause)
+	})
+
+	t.Run("loads seed configuration", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("APP_ENV", "Development")
+		_ = os.Setenv("SEED_DIR", "scripts/seed")
+		defer os.Clearenv()
+
+		cfg := Load()
+
+		assert.True(t, cfg.IsDevelopment())
+		assert.Equal(t, "scripts/seed", cfg.Seed.Dir)
+	})
+
+	t.Run("loads token blacklist cache configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, "none", cfg.Auth.BlacklistCache)
+		assert.Equal(t, 30*time.Second, cfg.Auth.BlacklistCacheTTL)
+
+		_ = os.Setenv("AUTH_BLACKLIST_CACHE", "Redis")
+		_ = os.Setenv("AUTH_BLACKLIST_CACHE_TTL", "1m")
+
+		cfg = Load()
+		assert.Equal(t, "redis", cfg.Auth.BlacklistCache)
+		assert.Equal(t, time.Minute, cfg.Auth.BlacklistCacheTTL)
+	})
+
+	t.Run("loads token revocation configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, "blacklist", cfg.Auth.TokenRevocation)
+		assert.Equal(t, time.Minute, cfg.Auth.TokenVersionCacheTTL)
+
+		_ = os.Setenv("AUTH_TOKEN_REVOCATION", "Version")
+		_ = os.Setenv("AUTH_TOKEN_VERSION_CACHE_TTL", "10s")
+
+		cfg = Load()
+		assert.Equal(t, "version", cfg.Auth.TokenRevocation)
+		assert.Equal(t, 10*time.Second, cfg.Auth.TokenVersionCacheTTL)
+	})
+
+	t.Run("loads degraded mode", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.True(t, Load().Auth.DegradedMode)
+
+		_ = os.Setenv("AUTH_DEGRADED_MODE", "false")
+		assert.False(t, Load().Auth.DegradedMode)
+	})
+
+	t.Run("loads login lockout configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, 5, cfg.Auth.LockoutThreshold)
+		assert.Equal(t, 20, cfg.Auth.LockoutIPThreshold)
+		assert.Equal(t, 15*time.Minute, cfg.Auth.LockoutDuration)
+
+		_ = os.Setenv("AUTH_LOCKOUT_THRESHOLD", "0")
+		_ = os.Setenv("AUTH_LOCKOUT_IP_THRESHOLD", "50")
+		_ = os.Setenv("AUTH_LOCKOUT_DURATION", "1h")
+
+		cfg = Load()
+		assert.Zero(t, cfg.Auth.LockoutThreshold)
+		assert.Equal(t, 50, cfg.Auth.LockoutIPThreshold)
+		assert.Equal(t, time.Hour, cfg.Auth.LockoutDuration)
+	})
+
+	t.Run("loads legacy token migration configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.True(t, cfg.Auth.LegacyTokenCutoff.IsZero())
+		assert.Equal(t, 500, cfg.Auth.LegacyTokenBatchSize)
+		assert.True(t, cfg.Auth.SecretKeyCutoff.IsZero())
+
+		_ = os.Setenv("AUTH_LEGACY_TOKEN_CUTOFF", "2026-12-01")
+		_ = os.Setenv("AUTH_LEGACY_TOKEN_BATCH_SIZE", "0")
+		_ = os.Setenv("AUTH_SECRET_KEY_CUTOFF", "2027-01-15")
+
+		cfg = Load()
+		assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), cfg.Auth.LegacyTokenCutoff)
+		assert.Equal(t, time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC), cfg.Auth.SecretKeyCutoff)
+		assert.Zero(t, cfg.Auth.LegacyTokenBatchSize)
+
+		_ = os.Setenv("AUTH_LEGACY_TOKEN_CUTOFF", "2026-12-01T09:30:00+01:00")
+		cfg = Load()
+		assert.True(t, time.Date(2026, 12, 1, 8, 30, 0, 0, time.UTC).Equal(cfg.Auth.LegacyTokenCutoff))
+
+		_ = os.Setenv("AUTH_LEGACY_TOKEN_CUTOFF", "soon")
+		cfg = Load()
+		assert.True(t, cfg.Auth.LegacyTokenCutoff.IsZero())
+	})
+
+	t.Run("loads stale account report configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, 90, cfg.Auth.StaleAccountDays)
+		assert.Equal(t, 24*time.Hour, cfg.Auth.StaleAccountReportInterval)
+		assert.Zero(t, cfg.Auth.StaleAccountDeactivateDays)
+
+		_ = os.Setenv("AUTH_STALE_ACCOUNT_DAYS", "30")
+		_ = os.Setenv("AUTH_STALE_ACCOUNT_REPORT_INTERVAL", "0")
+		_ = os.Setenv("AUTH_STALE_ACCOUNT_DEACTIVATE_DAYS", "180")
+
+		cfg = Load()
+		assert.Equal(t, 30, cfg.Auth.StaleAccountDays)
+		assert.Zero(t, cfg.Auth.StaleAccountReportInterval)
+		assert.Equal(t, 180, cfg.Auth.StaleAccountDeactivateDays)
+	})
+
+	t.Run("loads login anomaly detection configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.True(t, cfg.Auth.LoginAnomalyDetection)
+		assert.Equal(t, 5, cfg.Auth.LoginAnomalyFailures)
+		assert.Equal(t, time.Hour, cfg.Auth.LoginAnomalyWindow)
+
+		_ = os.Setenv("AUTH_LOGIN_ANOMALY_DETECTION", "false")
+		_ = os.Setenv("AUTH_LOGIN_ANOMALY_FAILURES", "0")
+		_ = os.Setenv("AUTH_LOGIN_ANOMALY_WINDOW", "6h")
+
+		cfg = Load()
+		assert.False(t, cfg.Auth.LoginAnomalyDetection)
+		assert.Zero(t, cfg.Auth.LoginAnomalyFailures)
+		assert.Equal(t, 6*time.Hour, cfg.Auth.LoginAnomalyWindow)
+	})
+
+	t.Run("loads deleted user retention", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Equal(t, 30*24*time.Hour, Load().Auth.DeletedUserRetention)
+
+		_ = os.Setenv("AUTH_DELETED_USER_RETENTION", "0")
+		assert.Zero(t, Load().Auth.DeletedUserRetention)
+	})
+
+	t.Run("loads token cleanup interval", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, time.Hour, cfg.Auth.TokenCleanupInterval)
+
+		_ = os.Setenv("AUTH_TOKEN_CLEANUP_INTERVAL", "0")
+		cfg = Load()
+		assert.Zero(t, cfg.Auth.TokenCleanupInterval)
+	})
+
+	t.Run("loads required route groups", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Empty(t, Load().Auth.RequiredRouteGroups)
+
+		_ = os.Setenv("AUTH_REQUIRED_ROUTE_GROUPS", "pack-sizes, presets")
+		assert.Equal(t, []string{"pack-sizes", "presets"}, Load().Auth.RequiredRouteGroups)
+	})
+
+	t.Run("loads service token configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.Auth.JWKSURL)
+		assert.Equal(t, time.Hour, cfg.Auth.JWKSRefreshInterval)
+		assert.Empty(t, cfg.Auth.ServiceTokenRoles)
+
+		_ = os.Setenv("AUTH_JWKS_URL", "https://platform.internal/.well-known/jwks.json")
+		_ = os.Setenv("AUTH_JWKS_REFRESH_INTERVAL", "10m")
+		_ = os.Setenv("AUTH_SERVICE_TOKEN_ISSUER", "https://platform.internal")
+		_ = os.Setenv("AUTH_SERVICE_TOKEN_AUDIENCE", "pack-service")
+		_ = os.Setenv("AUTH_SERVICE_TOKEN_ROLES", "507f1f77bcf86cd799439011")
+
+		cfg = Load()
+		assert.Equal(t, "https://platform.internal/.well-known/jwks.json", cfg.Auth.JWKSURL)
+		assert.Equal(t, 10*time.Minute, cfg.Auth.JWKSRefreshInterval)
+		assert.Equal(t, "https://platform.internal", cfg.Auth.ServiceTokenIssuer)
+		assert.Equal(t, "pack-service", cfg.Auth.ServiceTokenAudience)
+		assert.Equal(t, []string{"507f1f77bcf86cd799439011"}, cfg.Auth.ServiceTokenRoles)
+	})
+
+	t.Run("loads signing key refresh interval", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Equal(t, time.Minute, Load().Auth.SigningKeyRefreshInterval)
+		_ = os.Setenv("AUTH_SIGNING_KEY_REFRESH_INTERVAL", "30s")
+		assert.Equal(t, 30*time.Second, Load().Auth.SigningKeyRefreshInterval)
+	})
+
+	t.Run("loads batch configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Zero(t, cfg.Batch.Workers)
+		assert.Zero(t, cfg.Batch.MaxConcurrencyPerRequest)
+		assert.Equal(t, 5*time.Second, cfg.Batch.QueueTimeout)
+		assert.Equal(t, time.Minute, cfg.Batch.StreamJobTTL)
+		assert.Equal(t, 100, cfg.Batch.MaxStreamJobs)
+
+		_ = os.Setenv("BATCH_WORKERS", "8")
+		_ = os.Setenv("BATCH_MAX_CONCURRENCY_PER_REQUEST", "2")
+		_ = os.Setenv("BATCH_QUEUE_TIMEOUT", "500ms")
+		_ = os.Setenv("BATCH_STREAM_JOB_TTL", "30s")
+		_ = os.Setenv("BATCH_MAX_STREAM_JOBS", "5")
+
+		cfg = Load()
+		assert.Equal(t, 8, cfg.Batch.Workers)
+		assert.Equal(t, 2, cfg.Batch.MaxConcurrencyPerRequest)
+		assert.Equal(t, 500*time.Millisecond, cfg.Batch.QueueTimeout)
+		assert.Equal(t, 30*time.Second, cfg.Batch.StreamJobTTL)
+		assert.Equal(t, 5, cfg.Batch.MaxStreamJobs)
+	})
+
+	t.Run("loads heavy request configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, 2, cfg.Heavy.MaxConcurrent)
+		assert.Equal(t, 8, cfg.Heavy.MaxQueued)
+		assert.Equal(t, 5*time.Second, cfg.Heavy.QueueTimeout)
+
+		_ = os.Setenv("HEAVY_MAX_CONCURRENT", "0")
+		_ = os.Setenv("HEAVY_MAX_QUEUED", "3")
+		_ = os.Setenv("HEAVY_QUEUE_TIMEOUT", "250ms")
+
+		cfg = Load()
+		assert.Zero(t, cfg.Heavy.MaxConcurrent)
+		assert.Equal(t, 3, cfg.Heavy.MaxQueued)
+		assert.Equal(t, 250*time.Millisecond, cfg.Heavy.QueueTimeout)
+	})
+
+	t.Run("loads i18n configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, time.Minute, cfg.I18n.OverrideRefreshInterval)
+
+		assert.Empty(t, cfg.I18n.CatalogDir)
+
+		_ = os.Setenv("I18N_OVERRIDE_REFRESH_INTERVAL", "0")
+		_ = os.Setenv("I18N_CATALOG_DIR", "/etc/pack-service/locales")
+		cfg = Load()
+		assert.Zero(t, cfg.I18n.OverrideRefreshInterval)
+		assert.Equal(t, "/etc/pack-service/locales", cfg.I18n.CatalogDir)
+	})
+
+	t.Run("loads presentation configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Empty(t, Load().Presentation.RulesFile)
+
+		_ = os.Setenv("PRESENTATION_RULES_FILE", "/etc/pack-service/presentation.yaml")
+		assert.Equal(t, "/etc/pack-service/presentation.yaml", Load().Presentation.RulesFile)
+	})
+
+	t.Run("loads calculation job configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, 2, cfg.Jobs.Workers)
+		assert.Equal(t, 24*time.Hour, cfg.Jobs.Retention)
+		assert.Equal(t, 10*time.Minute, cfg.Jobs.Timeout)
+		assert.Equal(t, 1000, cfg.Jobs.MaxUnfinished)
+		assert.Equal(t, time.Second, cfg.Jobs.PollInterval)
+
+		_ = os.Setenv("JOBS_WORKERS", "4")
+		_ = os.Setenv("JOBS_RETENTION", "1h")
+		_ = os.Setenv("JOBS_TIMEOUT", "2m")
+		_ = os.Setenv("JOBS_MAX_UNFINISHED", "50")
+		_ = os.Setenv("JOBS_POLL_INTERVAL", "5s")
+
+		cfg = Load()
+		assert.Equal(t, 4, cfg.Jobs.Workers)
+		assert.Equal(t, time.Hour, cfg.Jobs.Retention)
+		assert.Equal(t, 2*time.Minute, cfg.Jobs.Timeout)
+		assert.Equal(t, 50, cfg.Jobs.MaxUnfinished)
+		assert.Equal(t, 5*time.Second, cfg.Jobs.PollInterval)
+	})
+
+	t.Run("loads tracing configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.False(t, cfg.Tracing.Enabled)
+		assert.Equal(t, "http://localhost:4318", cfg.Tracing.Endpoint)
+		assert.Equal(t, "pack-service", cfg.Tracing.ServiceName)
+		assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+
+		_ = os.Setenv("TRACING_ENABLED", "true")
+		_ = os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector:4318")
+		_ = os.Setenv("OTEL_SERVICE_NAME", "packs-eu")
+		_ = os.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+
+		cfg = Load()
+		assert.True(t, cfg.Tracing.Enabled)
+		assert.Equal(t, "https://collector:4318", cfg.Tracing.Endpoint)
+		assert.Equal(t, "packs-eu", cfg.Tracing.ServiceName)
+		assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	})
+
+	t.Run("loads metrics configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, "raw", cfg.Metrics.ClientLabels)
+		assert.Equal(t, 500, cfg.Metrics.MaxLabelValues)
+		assert.Equal(t, 50, cfg.Metrics.MaxPackSets)
+
+		_ = os.Setenv("METRICS_CLIENT_LABELS", "bucket")
+		_ = os.Setenv("METRICS_CLIENT_LABEL_BUCKETS", "16")
+		_ = os.Setenv("METRICS_MAX_LABEL_VALUES", "0")
+
+		cfg = Load()
+		assert.Equal(t, "bucket", cfg.Metrics.ClientLabels)
+		assert.Equal(t, 16, cfg.Metrics.ClientLabelBuckets)
+		assert.Equal(t, 0, cfg.Metrics.MaxLabelValues)
+	})
+
+	t.Run("loads edge cache configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Zero(t, cfg.EdgeCache.MaxAge)
+		assert.Empty(t, cfg.EdgeCache.PurgeURL)
+
+		_ = os.Setenv("EDGE_CACHE_MAX_AGE", "10m")
+		_ = os.Setenv("EDGE_CACHE_PURGE_URL", "https://cdn.example.com/purge/{key}")
+		_ = os.Setenv("EDGE_CACHE_PURGE_TOKEN", "secret")
+
+		cfg = Load()
+		assert.Equal(t, 10*time.Minute, cfg.EdgeCache.MaxAge)
+		assert.Equal(t, "https://cdn.example.com/purge/{key}", cfg.EdgeCache.PurgeURL)
+		assert.Equal(t, "secret", cfg.EdgeCache.PurgeToken)
+	})
+
+	t.Run("loads geofence configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.GeoFence.AllowedCIDRs)
+		assert.Empty(t, cfg.GeoFence.AllowedCountries)
+		assert.Equal(t, "CF-IPCountry", cfg.GeoFence.CountryHeader)
+		assert.Empty(t, cfg.GeoFence.TrustedProxies)
+
+		_ = os.Setenv("GEOFENCE_ALLOWED_CIDRS", "10.0.0.0/8, 192.168.1.0/24")
+		_ = os.Setenv("GEOFENCE_ALLOWED_COUNTRIES", "NL,DE")
+		_ = os.Setenv("GEOFENCE_COUNTRY_HEADER", "X-Country-Code")
+		_ = os.Setenv("GEOFENCE_TRUSTED_PROXIES", "173.245.48.0/20")
+
+		cfg = Load()
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.0/24"}, cfg.GeoFence.AllowedCIDRs)
+		assert.Equal(t, []string{"NL", "DE"}, cfg.GeoFence.AllowedCountries)
+		assert.Equal(t, "X-Country-Code", cfg.GeoFence.CountryHeader)
+		assert.Equal(t, []string{"173.245.48.0/20"}, cfg.GeoFence.TrustedProxies)
+	})
+
+	t.Run("loads IP filter configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.IPFilter.AllowCIDRs)
+		assert.Empty(t, cfg.IPFilter.DenyCIDRs)
+		assert.Equal(t, 30*time.Second, cfg.IPFilter.RefreshInterval)
+
+		_ = os.Setenv("IP_ALLOW_CIDRS", "10.0.0.0/8")
+		_ = os.Setenv("IP_DENY_CIDRS", "203.0.113.0/24, 2001:db8::/32")
+		_ = os.Setenv("IP_RULES_REFRESH_INTERVAL", "5s")
+
+		cfg = Load()
+		assert.Equal(t, []string{"10.0.0.0/8"}, cfg.IPFilter.AllowCIDRs)
+		assert.Equal(t, []string{"203.0.113.0/24", "2001:db8::/32"}, cfg.IPFilter.DenyCIDRs)
+		assert.Equal(t, 5*time.Second, cfg.IPFilter.RefreshInterval)
+	})
+
+	t.Run("loads deterministic mode configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.False(t, cfg.Deterministic.Enabled)
+		assert.Equal(t, 1, cfg.Deterministic.Seed)
+		assert.True(t, cfg.Deterministic.Clock.IsZero())
+
+		_ = os.Setenv("DETERMINISTIC_MODE", "true")
+		_ = os.Setenv("DETERMINISTIC_SEED", "42")
+		_ = os.Setenv("DETERMINISTIC_CLOCK", "2031-06-01T12:00:00Z")
+
+		cfg = Load()
+		assert.True(t, cfg.Deterministic.Enabled)
+		assert.Equal(t, 42, cfg.Deterministic.Seed)
+		assert.Equal(t, time.Date(2031, 6, 1, 12, 0, 0, 0, time.UTC), cfg.Deterministic.Clock)
+	})
+
+	t.Run("loads calculation history flush interval", func(t *testing.T) {
+		os.Clearenv()
+		assert.Equal(t, 5*time.Second, Load().Database.CalculationHistoryFlushInterval)
+
+		_ = os.Setenv("CALCULATION_HISTORY_FLUSH_INTERVAL", "1s")
+		assert.Equal(t, time.Second, Load().Database.CalculationHistoryFlushInterval)
+	})
+
+	t.Run("loads log retention configuration", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		assert.Nil(t, cfg.Database.LogsRetentionByLevel)
+		assert.Zero(t, cfg.Database.LogsMaxEntries)
+		assert.Equal(t, time.Hour, cfg.Database.LogsPurgeInterval)
+
+		_ = os.Setenv("MONGODB_LOGS_RETENTION_BY_LEVEL", "ERROR=2160h, debug=24h,warn,info=soon")
+		_ = os.Setenv("MONGODB_LOGS_MAX_ENTRIES", "1000000")
+		cfg = Load()
+		assert.Equal(t, map[string]time.Duration{"error": 2160 * time.Hour, "debug": 24 * time.Hour}, cfg.Database.LogsRetentionByLevel)
+		assert.Equal(t, 1000000, cfg.Database.LogsMaxEntries)
+	})
+
+	t.Run("loads log buffer configuration", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		assert.Equal(t, 10000, cfg.Database.LogsBufferSize)
+		assert.Equal(t, 500, cfg.Database.LogsBatchSize)
+		assert.Equal(t, time.Second, cfg.Database.LogsFlushInterval)
+		assert.Equal(t, "drop_newest", cfg.Database.LogsOverflowPolicy)
+
+		_ = os.Setenv("MONGODB_LOGS_BUFFER_SIZE", "0")
+		_ = os.Setenv("MONGODB_LOGS_BATCH_SIZE", "100")
+		_ = os.Setenv("MONGODB_LOGS_FLUSH_INTERVAL", "250ms")
+		_ = os.Setenv("MONGODB_LOGS_OVERFLOW_POLICY", "block")
+		cfg = Load()
+		assert.Zero(t, cfg.Database.LogsBufferSize)
+		assert.Equal(t, 100, cfg.Database.LogsBatchSize)
+		assert.Equal(t, 250*time.Millisecond, cfg.Database.LogsFlushInterval)
+		assert.Equal(t, "block", cfg.Database.LogsOverflowPolicy)
+	})
+
+	t.Run("loads pack size rules", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		assert.Equal(t, PackSizeRulesConfig{MaxSizes: 100, MinSize: 1, MaxSize: 10_000_000}, cfg.PackSizeRules)
+
+		_ = os.Setenv("PACK_SIZE_RULES_MAX_SIZES", "10")
+		_ = os.Setenv("PACK_SIZE_RULES_MIN_SIZE", "5")
+		_ = os.Setenv("PACK_SIZE_RULES_MAX_SIZE", "50000")
+		_ = os.Setenv("PACK_SIZE_RULES_COMMON_DIVISOR_WARNING", "100")
+		cfg = Load()
+		assert.Equal(t, PackSizeRulesConfig{MaxSizes: 10, MinSize: 5, MaxSize: 50000, CommonDivisorWarning: 100}, cfg.PackSizeRules)
+	})
+
+	t.Run("loads calculation rollup configuration", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		assert.Equal(t, time.Hour, cfg.Database.CalculationRollupInterval)
+		assert.Equal(t, 2, cfg.Database.CalculationRollupLookbackDays)
+
+		_ = os.Setenv("CALCULATION_ROLLUP_INTERVAL", "0")
+		_ = os.Setenv("CALCULATION_ROLLUP_LOOKBACK_DAYS", "400")
+		cfg = Load()
+		assert.Zero(t, cfg.Database.CalculationRollupInterval)
+		assert.Equal(t, 400, cfg.Database.CalculationRollupLookbackDays)
+	})
+
+	t.Run("loads hedged read delay", func(t *testing.T) {
+		os.Clearenv()
+		assert.Zero(t, Load().Database.HedgedReadDelay)
+
+		_ = os.Setenv("MONGODB_HEDGED_READ_DELAY", "20ms")
+		assert.Equal(t, 20*time.Millisecond, Load().Database.HedgedReadDelay)
+	})
+
+	t.Run("loads pack sizes poll interval", func(t *testing.T) {
+		os.Clearenv()
+		assert.Equal(t, 30*time.Second, Load().Database.PackSizesPollInterval)
+
+		_ = os.Setenv("PACK_SIZES_POLL_INTERVAL", "5s")
+		assert.Equal(t, 5*time.Second, Load().Database.PackSizesPollInterval)
+	})
+
+	t.Run("loads auth circuit breaker configuration", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("CIRCUIT_BREAKER_FAILURE_THRESHOLD", "8")
+		_ = os.Setenv("CIRCUIT_BREAKER_TIMEOUT", "1m")
+		cfg := Load().Database
+		assert.Equal(t, 8, cfg.AuthCircuitBreakerFailureThreshold)
+		assert.Equal(t, 2, cfg.AuthCircuitBreakerSuccessThreshold)
+		assert.Equal(t, time.Minute, cfg.AuthCircuitBreakerTimeout)
+
+		_ = os.Setenv("AUTH_CIRCUIT_BREAKER_FAILURE_THRESHOLD", "3")
+		_ = os.Setenv("AUTH_CIRCUIT_BREAKER_SUCCESS_THRESHOLD", "1")
+		_ = os.Setenv("AUTH_CIRCUIT_BREAKER_TIMEOUT", "10s")
+		cfg = Load().Database
+		assert.Equal(t, 3, cfg.AuthCircuitBreakerFailureThreshold)
+		assert.Equal(t, 1, cfg.AuthCircuitBreakerSuccessThreshold)
+		assert.Equal(t, 10*time.Second, cfg.AuthCircuitBreakerTimeout)
+	})
+
+	t.Run("loads redis cache configuration", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("CACHE_BACKEND", "Redis")
+		_ = os.Setenv("REDIS_ADDR", "redis:6379")
+		_ = os.Setenv("REDIS_DB", "2")
+		_ = os.Setenv("REDIS_POOL_SIZE", "20")
+		_ = os.Setenv("REDIS_OPERATION_TIMEOUT", "50ms")
+		_ = os.Setenv("CACHE_COMPRESSION", "ZSTD")
+		_ = os.Setenv("CACHE_COMPRESSION_THRESHOLD", "512")
+		defer os.Clearenv()
+
+		cfg := Load()
+
+		assert.Equal(t, "redis", cfg.Cache.Backend)
+		assert.Equal(t, "redis:6379", cfg.Cache.Redis.Addr)
+		assert.Equal(t, 2, cfg.Cache.Redis.DB)
+		assert.Equal(t, 20, cfg.Cache.Redis.PoolSize)
+		assert.Equal(t, 50*time.Millisecond, cfg.Cache.Redis.OperationTimeout)
+		assert.Equal(t, "pack-service:calc", cfg.Cache.Redis.KeyPrefix)
+		assert.Equal(t, "zstd", cfg.Cache.Compression)
+		assert.Equal(t, 512, cfg.Cache.CompressionThreshold)
+	})
+
+	t.Run("loads cache snapshot configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.Cache.SnapshotPath)
+		assert.Equal(t, time.Hour, cfg.Cache.SnapshotMaxAge)
+		assert.Zero(t, cfg.Cache.SnapshotMaxEntries)
+		assert.Equal(t, "lru", cfg.Cache.Eviction)
+
+		_ = os.Setenv("CACHE_SNAPSHOT_PATH", "/var/lib/pack-service/cache.json")
+		_ = os.Setenv("CACHE_SNAPSHOT_MAX_AGE", "10m")
+		_ = os.Setenv("CACHE_SNAPSHOT_MAX_ENTRIES", "200")
+		_ = os.Setenv("CACHE_EVICTION", "LFU")
+
+		cfg = Load()
+		assert.Equal(t, "/var/lib/pack-service/cache.json", cfg.Cache.SnapshotPath)
+		assert.Equal(t, 10*time.Minute, cfg.Cache.SnapshotMaxAge)
+		assert.Equal(t, 200, cfg.Cache.SnapshotMaxEntries)
+		assert.Equal(t, "lfu", cfg.Cache.Eviction)
+	})
+
+	t.Run("loads cache warm-up configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.Cache.WarmupQuantities)
+		assert.Zero(t, cfg.Cache.WarmupTop)
+		assert.Equal(t, 7*24*time.Hour, cfg.Cache.WarmupWindow)
+		assert.Zero(t, cfg.Cache.WarmupInterval)
+
+		_ = os.Setenv("CACHE_WARMUP_QUANTITIES", "250, 1000,12001")
+		_ = os.Setenv("CACHE_WARMUP_TOP", "500")
+		_ = os.Setenv("CACHE_WARMUP_WINDOW", "24h")
+		_ = os.Setenv("CACHE_WARMUP_INTERVAL", "4m")
+
+		cfg = Load()
+		assert.Equal(t, []int{250, 1000, 12001}, cfg.Cache.WarmupQuantities)
+		assert.Equal(t, 500, cfg.Cache.WarmupTop)
+		assert.Equal(t, 24*time.Hour, cfg.Cache.WarmupWindow)
+		assert.Equal(t, 4*time.Minute, cfg.Cache.WarmupInterval)
+	})
+
+	t.Run("loads request validation mode", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Equal(t, "off", Load().Server.RequestValidation)
+
+		_ = os.Setenv("REQUEST_VALIDATION", "Enforce")
+		assert.Equal(t, "enforce", Load().Server.RequestValidation)
+	})
+
+	t.Run("loads response cache configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Zero(t, cfg.Cache.ResponseTTL)
+		assert.Equal(t, 1000, cfg.Cache.ResponseMaxEntries)
+
+		_ = os.Setenv("RESPONSE_CACHE_TTL", "15s")
+		_ = os.Setenv("RESPONSE_CACHE_MAX_ENTRIES", "200")
+
+		cfg = Load()
+		assert.Equal(t, 15*time.Second, cfg.Cache.ResponseTTL)
+		assert.Equal(t, 200, cfg.Cache.ResponseMaxEntries)
+	})
+
+	t.Run("loads gRPC configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.False(t, cfg.Server.GRPCEnabled)
+		assert.Equal(t, "9090", cfg.Server.GRPCPort)
+
+		_ = os.Setenv("GRPC_ENABLED", "true")
+		_ = os.Setenv("GRPC_PORT", "50051")
+
+		cfg = Load()
+		assert.True(t, cfg.Server.GRPCEnabled)
+		assert.Equal(t, "50051", cfg.Server.GRPCPort)
+	})
+
+	t.Run("loads display timezone", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.Server.DisplayTimezone)
+
+		_ = os.Setenv("DISPLAY_TIMEZONE", "Europe/Lisbon")
+
+		cfg = Load()
+		assert.Equal(t, "Europe/Lisbon", cfg.Server.DisplayTimezone)
+	})
+
+	t.Run("loads trusted proxies", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.Server.TrustedProxies)
+
+		_ = os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1")
+
+		cfg = Load()
+		assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.1"}, cfg.Server.TrustedProxies)
+	})
+
+	t.Run("loads page cursor configuration", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Empty(t, cfg.Server.PageCursorKey)
+		assert.Equal(t, time.Hour, cfg.Server.PageCursorTTL)
+
+		_ = os.Setenv("PAGE_CURSOR_KEY", "MDEyMzQ1Njc4OWFiY2RlZg==")
+		_ = os.Setenv("PAGE_CURSOR_TTL", "15m")
+
+		cfg = Load()
+		assert.Equal(t, "MDEyMzQ1Njc4OWFiY2RlZg==", cfg.Server.PageCursorKey)
+		assert.Equal(t, 15*time.Minute, cfg.Server.PageCursorTTL)
+	})
+
+	t.Run("loads max compute time", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, time.Second, cfg.Server.MaxCompute)
+
+		_ = os.Setenv("MAX_COMPUTE_TIME", "250ms")
+
+		cfg = Load()
+		assert.Equal(t, 250*time.Millisecond, cfg.Server.MaxCompute)
+	})
+
+	t.Run("loads max request body size", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.Equal(t, 64<<10, cfg.Server.MaxBodyBytes)
+
+		_ = os.Setenv("MAX_REQUEST_BODY_BYTES", "1024")
+
+		cfg = Load()
+		assert.Equal(t, 1024, cfg.Server.MaxBodyBytes)
+	})
+
+	t.Run("loads log sampling and redaction", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		_ = os.Setenv("LOG_SUCCESS_SAMPLE_RATE", "0.05")
+		_ = os.Setenv("LOG_REDACT_FIELDS", "password, email")
+
+		cfg := Load()
+		assert.Equal(t, 0.05, cfg.Log.SuccessSampleRate)
+		assert.Equal(t, []string{"password", "email"}, cfg.Log.RedactFields)
+	})
+
+	t.Run("loads TLS settings", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		cfg := Load()
+		assert.False(t, cfg.Server.TLS.Enabled())
+		assert.True(t, cfg.Server.HTTP2)
+		assert.Equal(t, "require", cfg.Server.TLS.ClientAuth)
+		assert.Equal(t, time.Minute, cfg.Server.TLS.ReloadInterval)
+
+		_ = os.Setenv("TLS_CERT_FILE", "/etc/tls/tls.crt")
+		_ = os.Setenv("TLS_KEY_FILE", "/etc/tls/tls.key")
+		_ = os.Setenv("TLS_CLIENT_CA_FILE", "/etc/tls/ca.crt")
+		_ = os.Setenv("TLS_CLIENT_AUTH", "Optional")
+		_ = os.Setenv("TLS_RELOAD_INTERVAL", "0")
+		_ = os.Setenv("HTTP2_ENABLED", "false")
+
+		cfg = Load()
+		assert.True(t, cfg.Server.TLS.Enabled())
+		assert.Equal(t, TLSConfig{
+			CertFile:     "/etc/tls/tls.crt",
+			KeyFile:      "/etc/tls/tls.key",
+			ClientCAFile: "/etc/tls/ca.crt",
+			ClientAuth:   "optional",
+		}, cfg.Server.TLS)
+		assert.False(t, cfg.Server.HTTP2)
+	})
+
+	t.Run("loads problem details errors", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.False(t, Load().Server.ProblemDetails)
+
+		_ = os.Setenv("PROBLEM_DETAILS_ERRORS", "true")
+		assert.True(t, Load().Server.ProblemDetails)
+	})
+
+	t.Run("loads decimal precision", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Equal(t, 3, Load().Server.DecimalPrecision)
+
+		_ = os.Setenv("DECIMAL_PRECISION", "2")
+		assert.Equal(t, 2, Load().Server.DecimalPrecision)
+	})
+
+	t.Run("loads max items ordered", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Equal(t, 10_000_000, Load().Server.MaxItemsOrdered)
+
+		_ = os.Setenv("MAX_ITEMS_ORDERED", "500000")
+		assert.Equal(t, 500_000, Load().Server.MaxItemsOrdered)
+	})
+
+	t.Run("loads bounded search threshold", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Equal(t, 1_000_000, Load().Server.BoundedSearchThreshold)
+
+		_ = os.Setenv("BOUNDED_SEARCH_THRESHOLD", "0")
+		assert.Equal(t, 0, Load().Server.BoundedSearchThreshold)
+	})
+
+	t.Run("loads exact search table cap", func(t *testing.T) {
+		os.Clearenv()
+		defer os.Clearenv()
+
+		assert.Equal(t, 1<<24, Load().Server.ExactSearchMaxTotals)
+
+		_ = os.Setenv("EXACT_SEARCH_MAX_TOTALS", "0")
+		assert.Equal(t, 0, Load().Server.ExactSearchMaxTotals)
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pack-service.env")
+
+	t.Run("file overrides the environment", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("RATE_LIMIT", "50")
+		_ = os.Setenv("CACHE_SIZE", "500")
+		_ = os.Setenv("CONFIG_WATCH_INTERVAL", "30s")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+			"# Limits",
+			"RATE_LIMIT = 75",
+			"",
+			`API_KEYS="key1, key2"`,
+			"LOG_LEVEL='DEBUG'",
+		}, "\n")), 0o600))
+
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, 75, cfg.Server.RateLimit)
+		assert.Equal(t, 500, cfg.Cache.Size)
+		assert.Equal(t, map[string]bool{"key1": true, "key2": true}, cfg.Auth.APIKeys)
+		assert.Equal(t, "debug", cfg.Log.Level)
+		assert.Equal(t, path, cfg.Reload.File)
+		assert.Equal(t, 30*time.Second, cfg.Reload.WatchInterval)
+
+		// The file's values do not leak into later loads
+		assert.Equal(t, 50, Load().Server.RateLimit)
+	})
+
+	t.Run("Load reads CONFIG_FILE", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("CONFIG_FILE", path)
+		require.NoError(t, os.WriteFile(path, []byte("RATE_LIMIT=75\n"), 0o600))
+
+		cfg := Load()
+		assert.Equal(t, 75, cfg.Server.RateLimit)
+		assert.Equal(t, path, cfg.Reload.File)
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("RATE_LIMIT", "50")
+		require.NoError(t, os.WriteFile(path, []byte("RATE_LIMIT=75\nnot a setting\n"), 0o600))
+
+		cfg, err := LoadFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+		assert.Equal(t, 50, cfg.Server.RateLimit)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		os.Clearenv()
+
+		cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.env"))
+		require.Error(t, err)
+		assert.Equal(t, 100, cfg.Server.RateLimit)
+	})
 }
@@ -0,0 +1,157 @@
+// Package alerting turns operational signals into throttled notifications.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/rs/zerolog/log"
+)
+
+// BreakerTripPolicyConfig controls when circuit breaker trips raise an alert.
+type BreakerTripPolicyConfig struct {
+	// Threshold is the number of trips within Window that raises an alert.
+	Threshold int
+	// Window is the sliding window trips are counted in.
+	Window time.Duration
+	// Cooldown is the minimum time between two alerts for the same breaker.
+	// Alerts that would fire during the cooldown are suppressed and counted.
+	Cooldown time.Duration
+	// SendTimeout bounds how long delivering a single alert may take.
+	SendTimeout time.Duration
+}
+
+// DefaultBreakerTripPolicyConfig returns a policy alerting on 3 trips in 5 minutes,
+// at most once every 15 minutes per breaker.
+func DefaultBreakerTripPolicyConfig() BreakerTripPolicyConfig {
+	return BreakerTripPolicyConfig{
+		Threshold:   3,
+		Window:      5 * time.Minute,
+		Cooldown:    15 * time.Minute,
+		SendTimeout: 10 * time.Second,
+	}
+}
+
+// BreakerTripPolicy notifies when a circuit breaker opens repeatedly.
+// A single trip is normal during a blip; a breaker flapping open and closed
+// usually means the dependency is unhealthy and someone should look at it.
+type BreakerTripPolicy struct {
+	notifier notify.Notifier
+	config   BreakerTripPolicyConfig
+	now      func() time.Time
+
+	mu         sync.Mutex
+	trips      map[string][]time.Time
+	lastAlert  map[string]time.Time
+	suppressed map[string]int
+	wg         sync.WaitGroup
+}
+
+// NewBreakerTripPolicy creates a policy delivering alerts through notifier.
+// Zero values in cfg fall back to DefaultBreakerTripPolicyConfig.
+func NewBreakerTripPolicy(notifier notify.Notifier, cfg BreakerTripPolicyConfig) *BreakerTripPolicy {
+	defaults := DefaultBreakerTripPolicyConfig()
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaults.Threshold
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaults.Cooldown
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = defaults.SendTimeout
+	}
+
+	return &BreakerTripPolicy{
+		notifier:   notifier,
+		config:     cfg,
+		now:        time.Now,
+		trips:      make(map[string][]time.Time),
+		lastAlert:  make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// Watch subscribes the policy to state changes of cb.
+func (p *BreakerTripPolicy) Watch(cb *circuitbreaker.CircuitBreaker) {
+	cb.OnStateChange(p.OnStateChange)
+}
+
+// OnStateChange records a trip whenever a breaker opens and sends an alert
+// asynchronously once the policy fires. It never blocks the caller on delivery.
+func (p *BreakerTripPolicy) OnStateChange(name string, _, to circuitbreaker.State) {
+	if to != circuitbreaker.StateOpen {
+		return
+	}
+
+	n, fire := p.recordTrip(name)
+	if !fire {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.SendTimeout)
+		defer cancel()
+		if err := p.notifier.Notify(ctx, n); err != nil {
+			log.Error().Err(err).Str("circuit_breaker", name).Msg("Failed to send circuit breaker alert")
+		}
+	}()
+}
+
+// Wait blocks until all in-flight alerts have been delivered.
+func (p *BreakerTripPolicy) Wait() {
+	p.wg.Wait()
+}
+
+// recordTrip registers a trip for name and reports whether an alert should fire.
+func (p *BreakerTripPolicy) recordTrip(name string) (notify.Notification, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	cutoff := now.Add(-p.config.Window)
+
+	trips := p.trips[name][:0]
+	for _, t := range p.trips[name] {
+		if t.After(cutoff) {
+			trips = append(trips, t)
+		}
+	}
+	trips = append(trips, now)
+	p.trips[name] = trips
+
+	if len(trips) < p.config.Threshold {
+		return notify.Notification{}, false
+	}
+
+	if last, ok := p.lastAlert[name]; ok && now.Sub(last) < p.config.Cooldown {
+		p.suppressed[name]++
+		return notify.Notification{}, false
+	}
+
+	suppressed := p.suppressed[name]
+	p.lastAlert[name] = now
+	p.suppressed[name] = 0
+
+	return notify.Notification{
+		Title:    fmt.Sprintf("Circuit breaker %s is flapping", name),
+		Message:  fmt.Sprintf("Circuit breaker %s opened %d times in the last %s.", name, len(trips), p.config.Window),
+		Severity: notify.SeverityCritical,
+		Source:   "circuit_breaker",
+		Fields: map[string]interface{}{
+			"circuit_breaker":   name,
+			"trips":             len(trips),
+			"window":            p.config.Window.String(),
+			"suppressed_alerts": suppressed,
+		},
+		Timestamp: now,
+	}, true
+}
@@ -0,0 +1,142 @@
+//go:build !integration
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeClock is a manually advanced clock for deterministic window tests.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestPolicy(t *testing.T, cfg BreakerTripPolicyConfig) (*BreakerTripPolicy, *mocks.MockNotifier, *fakeClock) {
+	notifier := mocks.NewMockNotifier(t)
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	policy := NewBreakerTripPolicy(notifier, cfg)
+	policy.now = clock.now
+	return policy, notifier, clock
+}
+
+func trip(p *BreakerTripPolicy, name string) {
+	p.OnStateChange(name, circuitbreaker.StateClosed, circuitbreaker.StateOpen)
+}
+
+func TestNewBreakerTripPolicy_Defaults(t *testing.T) {
+	policy := NewBreakerTripPolicy(notify.NewLogNotifier(), BreakerTripPolicyConfig{})
+
+	assert.Equal(t, DefaultBreakerTripPolicyConfig(), policy.config)
+}
+
+func TestBreakerTripPolicy_BelowThreshold(t *testing.T) {
+	policy, _, _ := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 3, Window: time.Minute})
+
+	trip(policy, "db")
+	trip(policy, "db")
+	policy.Wait()
+	// No Notify expectation: the mock fails the test if it is called
+}
+
+func TestBreakerTripPolicy_IgnoresNonOpenTransitions(t *testing.T) {
+	policy, _, _ := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 1, Window: time.Minute})
+
+	policy.OnStateChange("db", circuitbreaker.StateOpen, circuitbreaker.StateHalfOpen)
+	policy.OnStateChange("db", circuitbreaker.StateHalfOpen, circuitbreaker.StateClosed)
+	policy.Wait()
+}
+
+func TestBreakerTripPolicy_FiresAtThreshold(t *testing.T) {
+	policy, notifier, _ := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 3, Window: time.Minute, Cooldown: time.Hour})
+
+	notifier.EXPECT().Notify(mock.Anything, mock.MatchedBy(func(n notify.Notification) bool {
+		return n.Severity == notify.SeverityCritical &&
+			n.Fields["circuit_breaker"] == "db" &&
+			n.Fields["trips"] == 3
+	})).Return(nil).Once()
+
+	trip(policy, "db")
+	trip(policy, "db")
+	trip(policy, "db")
+	policy.Wait()
+}
+
+func TestBreakerTripPolicy_WindowExpiresOldTrips(t *testing.T) {
+	policy, _, clock := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 2, Window: time.Minute})
+
+	trip(policy, "db")
+	clock.advance(2 * time.Minute)
+	trip(policy, "db")
+	policy.Wait()
+}
+
+func TestBreakerTripPolicy_CooldownSuppressesDuplicates(t *testing.T) {
+	policy, notifier, clock := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 1, Window: time.Minute, Cooldown: 10 * time.Minute})
+
+	notifier.EXPECT().Notify(mock.Anything, mock.MatchedBy(func(n notify.Notification) bool {
+		return n.Fields["suppressed_alerts"] == 0
+	})).Return(nil).Once()
+
+	trip(policy, "db")
+	policy.Wait()
+
+	// Within the cooldown: suppressed
+	clock.advance(time.Minute)
+	trip(policy, "db")
+	clock.advance(time.Minute)
+	trip(policy, "db")
+	policy.Wait()
+
+	// After the cooldown the next alert reports what was suppressed
+	notifier.EXPECT().Notify(mock.Anything, mock.MatchedBy(func(n notify.Notification) bool {
+		return n.Fields["suppressed_alerts"] == 2
+	})).Return(nil).Once()
+
+	clock.advance(10 * time.Minute)
+	trip(policy, "db")
+	policy.Wait()
+}
+
+func TestBreakerTripPolicy_TracksBreakersIndependently(t *testing.T) {
+	policy, notifier, _ := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 2, Window: time.Minute, Cooldown: time.Hour})
+
+	notifier.EXPECT().Notify(mock.Anything, mock.MatchedBy(func(n notify.Notification) bool {
+		return n.Fields["circuit_breaker"] == "logs"
+	})).Return(nil).Once()
+
+	trip(policy, "packs")
+	trip(policy, "logs")
+	trip(policy, "logs")
+	policy.Wait()
+}
+
+func TestBreakerTripPolicy_NotifierErrorDoesNotPanic(t *testing.T) {
+	policy, notifier, _ := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 1, Window: time.Minute})
+
+	notifier.EXPECT().Notify(mock.Anything, mock.Anything).Return(assert.AnError).Once()
+
+	trip(policy, "db")
+	policy.Wait()
+}
+
+func TestBreakerTripPolicy_Watch(t *testing.T) {
+	policy, notifier, _ := newTestPolicy(t, BreakerTripPolicyConfig{Threshold: 1, Window: time.Minute})
+
+	notifier.EXPECT().Notify(mock.Anything, mock.Anything).Return(nil).Once()
+
+	cb := circuitbreaker.New(circuitbreaker.Config{FailureThreshold: 1, SuccessThreshold: 1, Timeout: time.Minute, Name: "db"})
+	policy.Watch(cb)
+
+	_ = cb.Execute(t.Context(), func() error { return assert.AnError })
+	policy.Wait()
+}
@@ -0,0 +1,95 @@
+// Package app provides alerting initialization.
+package app
+
+import (
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/alerting"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/rs/zerolog/log"
+)
+
+// InitializeAlerting attaches the circuit breaker trip policy to the database breakers.
+// Returns nil if alerting is disabled or there are no breakers to watch.
+// monitor may be nil.
+func InitializeAlerting(cfg config.AlertingConfig, dbComponents *DatabaseComponents, monitor *notify.WebhookMonitor) *alerting.BreakerTripPolicy {
+	if !cfg.Enabled || dbComponents == nil {
+		return nil
+	}
+
+	policyCfg := alerting.DefaultBreakerTripPolicyConfig()
+	policyCfg.Threshold = cfg.BreakerTripThreshold
+	policyCfg.Window = cfg.BreakerTripWindow
+	policyCfg.Cooldown = cfg.Cooldown
+
+	policy := alerting.NewBreakerTripPolicy(buildNotifier(cfg, policyCfg, monitor), policyCfg)
+	if dbComponents.PackSizesCircuitBreaker != nil {
+		policy.Watch(dbComponents.PackSizesCircuitBreaker)
+	}
+	if dbComponents.LogsCircuitBreaker != nil {
+		policy.Watch(dbComponents.LogsCircuitBreaker)
+	}
+	if dbComponents.AuthCircuitBreaker != nil {
+		policy.Watch(dbComponents.AuthCircuitBreaker)
+	}
+
+	log.Info().
+		Int("threshold", policyCfg.Threshold).
+		Dur("window", policyCfg.Window).
+		Dur("cooldown", policyCfg.Cooldown).
+		Msg("Circuit breaker alerting enabled")
+
+	return policy
+}
+
+// InitializeWebhookMonitor starts probing the alert webhook. Returns nil if
+// alerting is disabled or no webhook is configured.
+func InitializeWebhookMonitor(cfg config.AlertingConfig) *notify.WebhookMonitor {
+	if !cfg.Enabled || cfg.WebhookURL == "" {
+		return nil
+	}
+
+	monitor := notify.NewWebhookMonitor(notify.WebhookMonitorConfig{
+		Interval:         cfg.WebhookProbeInterval,
+		Timeout:          cfg.WebhookProbeTimeout,
+		FailureThreshold: cfg.WebhookFailureThreshold,
+		MaxPause:         cfg.WebhookMaxPause,
+	})
+	monitor.Watch(alertWebhookName, cfg.WebhookURL)
+	monitor.Start()
+
+	log.Info().
+		Dur("interval", cfg.WebhookProbeInterval).
+		Int("failure_threshold", cfg.WebhookFailureThreshold).
+		Msg("Alert webhook health checks enabled")
+
+	return monitor
+}
+
+// alertWebhookName names the alert webhook in health reports.
+const alertWebhookName = "alerts"
+
+// buildNotifier combines the log notifier with any configured external
+// channels. Delivery to the webhook is paused while monitor reports it
+// failing; monitor may be nil.
+func buildNotifier(cfg config.AlertingConfig, policyCfg alerting.BreakerTripPolicyConfig, monitor *notify.WebhookMonitor) notify.Notifier {
+	notifiers := []notify.Notifier{notify.NewLogNotifier()}
+
+	if cfg.WebhookURL != "" {
+		var webhook notify.Notifier = notify.NewWebhookNotifier(cfg.WebhookURL, policyCfg.SendTimeout)
+		if monitor != nil {
+			webhook = monitor.Guard(alertWebhookName, cfg.WebhookURL, webhook)
+		}
+		notifiers = append(notifiers, webhook)
+	}
+	if cfg.SMTPAddr != "" && cfg.EmailFrom != "" && len(cfg.EmailTo) > 0 {
+		notifiers = append(notifiers, notify.NewEmailNotifier(notify.EmailConfig{
+			Addr:     cfg.SMTPAddr,
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPass,
+			From:     cfg.EmailFrom,
+			To:       cfg.EmailTo,
+		}))
+	}
+
+	return notify.NewMultiNotifier(notifiers...)
+}
@@ -0,0 +1,77 @@
+//go:build !integration
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/alerting"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeAlerting(t *testing.T) {
+	enabled := config.AlertingConfig{
+		Enabled:              true,
+		BreakerTripThreshold: 2,
+		BreakerTripWindow:    time.Minute,
+		Cooldown:             time.Hour,
+	}
+
+	t.Run("disabled returns nil", func(t *testing.T) {
+		assert.Nil(t, InitializeAlerting(config.AlertingConfig{}, &DatabaseComponents{}, nil))
+	})
+
+	t.Run("no database returns nil", func(t *testing.T) {
+		assert.Nil(t, InitializeAlerting(enabled, nil, nil))
+	})
+
+	t.Run("watches database circuit breakers", func(t *testing.T) {
+		components := &DatabaseComponents{
+			PackSizesCircuitBreaker: circuitbreaker.New(circuitbreaker.DefaultConfig()),
+			LogsCircuitBreaker:      circuitbreaker.New(circuitbreaker.DefaultConfig()),
+		}
+
+		policy := InitializeAlerting(enabled, components, nil)
+		assert.NotNil(t, policy)
+	})
+}
+
+func TestBuildNotifier(t *testing.T) {
+	cfg := config.AlertingConfig{
+		WebhookURL: "https://hooks.example.com/alerts",
+		SMTPAddr:   "smtp.example.com:587",
+		EmailFrom:  "alerts@example.com",
+		EmailTo:    []string{"oncall@example.com"},
+	}
+
+	notifier := buildNotifier(cfg, alerting.DefaultBreakerTripPolicyConfig(), nil)
+	assert.IsType(t, &notify.MultiNotifier{}, notifier)
+}
+
+func TestInitializeWebhookMonitor(t *testing.T) {
+	assert.Nil(t, InitializeWebhookMonitor(config.AlertingConfig{Enabled: true}))
+	assert.Nil(t, InitializeWebhookMonitor(config.AlertingConfig{WebhookURL: "https://hooks.example.com/alerts"}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	monitor := InitializeWebhookMonitor(config.AlertingConfig{
+		Enabled:              true,
+		WebhookURL:           server.URL + "/alerts",
+		WebhookProbeInterval: time.Hour,
+	})
+	require.NotNil(t, monitor)
+	defer monitor.Stop()
+
+	require.Eventually(t, func() bool {
+		health := monitor.Health()
+		return len(health) == 1 && health[0].Status == notify.WebhookHealthy
+	}, time.Second, time.Millisecond)
+}
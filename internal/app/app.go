@@ -2,20 +2,58 @@
 package app
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
 	"github.com/guttosm/pack-service/config"
 	"github.com/guttosm/pack-service/internal/http"
+	"github.com/guttosm/pack-service/internal/logger"
 	"github.com/guttosm/pack-service/internal/service"
 )
 
-// InitializeApp creates and wires all application dependencies.
-// This is the main orchestration function that initializes all components.
+// Application holds the servers built by InitializeApplication.
+type Application struct {
+	Router *gin.Engine
+	// GRPCServer is nil unless GRPC_ENABLED is set.
+	GRPCServer *grpc.Server
+	// ShutdownHooks run once the servers have stopped taking requests.
+	ShutdownHooks []func(context.Context)
+}
+
+// InitializeApp creates and wires all application dependencies and returns
+// the HTTP router.
 func InitializeApp(cfg config.Config) *gin.Engine {
+	return InitializeApplication(cfg).Router
+}
+
+// InitializeApplication creates and wires all application dependencies.
+// This is the main orchestration function that initializes all components.
+func InitializeApplication(cfg config.Config) *Application {
 	// Initialize logger first (needed by other components)
 	InitializeLogger()
+	// CONFIG_FILE may set a different level than the environment
+	logger.SetLevel(cfg.Log.Level)
+
+	// Seed IDs and freeze the clock before anything generates one
+	InitializeDeterministicMode(cfg)
+
+	// Messages in added locales are available before anything translates one
+	InitializeMessageCatalogs(cfg.I18n)
+
+	// Trace requests before anything that could send spans is created
+	stopTracing := InitializeTracing(cfg.Tracing)
+
+	// Bound metric labels before anything records one
+	InitializeMetrics(cfg.Metrics)
 
 	// Initialize business services
-	serviceComponents := InitializeServices(cfg.Cache)
+	serviceComponents := InitializeServices(cfg.Cache,
+		service.WithMaxItemsOrdered(cfg.Server.MaxItemsOrdered),
+		service.WithBoundedSearchThreshold(cfg.Server.BoundedSearchThreshold),
+		service.WithMaxTableTotals(cfg.Server.ExactSearchMaxTotals),
+	)
 
 	// Initialize database components (MongoDB repositories and services)
 	defaultPackSizes := cfg.Cache.PackSizes
@@ -23,9 +61,120 @@ func InitializeApp(cfg config.Config) *gin.Engine {
 		defaultPackSizes = service.DefaultPackSizes
 	}
 	dbComponents := InitializeDatabase(cfg.Database, defaultPackSizes)
+	InitializeTokenBlacklistCache(cfg, dbComponents)
+
+	// Load development fixtures (no-op unless SEED_DIR is set in a dev environment)
+	envAPIKeys := cfg.Auth.APIKeys
+	cfg.Auth.APIKeys = InitializeSeedData(cfg, dbComponents)
+
+	// Probe the alert webhook and pause delivery while it is down
+	var shutdownHooks []func(context.Context)
+	webhookMonitor := InitializeWebhookMonitor(cfg.Alerting)
+	if webhookMonitor != nil {
+		shutdownHooks = append(shutdownHooks, func(context.Context) { webhookMonitor.Stop() })
+	}
+
+	// Alert on flapping database circuit breakers
+	InitializeAlerting(cfg.Alerting, dbComponents, webhookMonitor)
+
+	// Warm the result cache from the last shutdown's snapshot
+	if saveSnapshot := InitializeCacheSnapshot(cfg.Cache, serviceComponents.Calculator, dbComponents); saveSnapshot != nil {
+		shutdownHooks = append(shutdownHooks, saveSnapshot)
+	}
+
+	// Hash refresh tokens stored before hashing was introduced
+	if stopMigration := InitializeLegacyTokenMigration(cfg.Auth, dbComponents); stopMigration != nil {
+		shutdownHooks = append(shutdownHooks, stopMigration)
+	}
 
 	// Initialize router components (handlers and configuration)
 	routerComponents := InitializeRouter(serviceComponents.Calculator, dbComponents, cfg)
+	routerComponents.Config.WebhookMonitor = webhookMonitor
+	if stopTokenVersions := routerComponents.StopTokenVersions; stopTokenVersions != nil {
+		shutdownHooks = append(shutdownHooks, func(context.Context) { stopTokenVersions() })
+	}
+
+	// Apply rate limits, API keys, cache size and log level changes without a restart
+	shutdownHooks = append(shutdownHooks, InitializeConfigReload(cfg, envAPIKeys, serviceComponents.Calculator, &routerComponents.Config))
+
+	// Delete expired tokens on schedule and on demand
+	tokenCleanup, stopTokenCleanup := InitializeTokenCleanup(cfg.Auth, dbComponents)
+	routerComponents.Config.TokenCleanup = tokenCleanup
+	if stopTokenCleanup != nil {
+		shutdownHooks = append(shutdownHooks, stopTokenCleanup)
+	}
+
+	// Apply the messages tenants replaced
+	messageOverrides, stopOverrides := InitializeMessageOverrides(cfg.I18n, dbComponents)
+	if messageOverrides != nil {
+		routerComponents.Config.MessageOverrides = messageOverrides
+	}
+	if stopOverrides != nil {
+		shutdownHooks = append(shutdownHooks, stopOverrides)
+	}
 
-	return http.NewRouter(routerComponents.Handler, routerComponents.HealthHandler, routerComponents.Config)
+	// Block abusive IP ranges, with rules admins can change at runtime
+	ipRules, stopIPRules := InitializeIPRules(cfg.IPFilter, dbComponents)
+	if ipRules != nil {
+		routerComponents.Config.IPRules = ipRules
+	}
+	if stopIPRules != nil {
+		shutdownHooks = append(shutdownHooks, stopIPRules)
+	}
+
+	// Audit and alert on logins from new locations or after many failures
+	routerComponents.Config.AuthService = InitializeLoginAnomalyDetection(cfg, routerComponents.Config.AuthService,
+		routerComponents.Config.LoggingService, dbComponents, webhookMonitor)
+
+	// Report accounts without recent logins for access reviews
+	if stopReport := InitializeStaleAccountReport(cfg, routerComponents.Config.UserService, webhookMonitor); stopReport != nil {
+		shutdownHooks = append(shutdownHooks, stopReport)
+	}
+
+	// Keep the analytics rollups of the calculation history up to date
+	if stopRollups := InitializeCalculationRollups(cfg.Database, dbComponents); stopRollups != nil {
+		shutdownHooks = append(shutdownHooks, stopRollups)
+	}
+
+	// Keep errors longer than other logs, and cap the stored logs
+	if stopLogRetention := InitializeLogRetention(cfg.Database, dbComponents); stopLogRetention != nil {
+		shutdownHooks = append(shutdownHooks, stopLogRetention)
+	}
+
+	// Pick up JWT signing keys added or retired through other instances
+	if signingKeys := routerComponents.Config.SigningKeys; signingKeys != nil && cfg.Auth.SigningKeyRefreshInterval > 0 {
+		signingKeys.Start(cfg.Auth.SigningKeyRefreshInterval)
+		shutdownHooks = append(shutdownHooks, func(context.Context) { signingKeys.Stop() })
+	}
+
+	// Calculate common order quantities before they are requested
+	if stopWarmup := InitializeCacheWarmup(cfg.Cache, serviceComponents.Calculator, dbComponents, routerComponents.Config.ActivePackSizes); stopWarmup != nil {
+		shutdownHooks = append(shutdownHooks, stopWarmup)
+	}
+
+	// Stop reloading the active pack sizes, which the pack routes started
+	if view := routerComponents.Config.ActivePackSizes; view != nil {
+		shutdownHooks = append(shutdownHooks, func(context.Context) { view.Stop() })
+	}
+
+	// Let running calculation jobs finish, or re-queue them
+	if jobs := routerComponents.Config.CalculationJobs; jobs != nil {
+		shutdownHooks = append(shutdownHooks, jobs.Shutdown)
+	}
+
+	// Write the queued logs once nothing else logs
+	if stopLogBuffer := LogBufferShutdown(dbComponents); stopLogBuffer != nil {
+		shutdownHooks = append(shutdownHooks, stopLogBuffer)
+	}
+
+	// Flush spans last, once nothing else can end one
+	if stopTracing != nil {
+		shutdownHooks = append(shutdownHooks, stopTracing)
+	}
+
+	return &Application{
+		Router:        http.NewRouter(routerComponents.Handler, routerComponents.HealthHandler, routerComponents.Config),
+		GRPCServer:    InitializeGRPC(cfg, serviceComponents.Calculator, routerComponents),
+		ShutdownHooks: shutdownHooks,
+	}
 }
@@ -85,3 +85,21 @@ func TestInitializeApp(t *testing.T) {
 		})
 	}
 }
+
+func TestInitializeGRPC(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		application := InitializeApplication(config.Config{Server: config.ServerConfig{Port: "8080"}})
+		assert.NotNil(t, application.Router)
+		assert.Nil(t, application.GRPCServer)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		application := InitializeApplication(config.Config{
+			Server: config.ServerConfig{Port: "8080", GRPCEnabled: true, GRPCPort: "9090"},
+			Auth:   config.AuthConfig{Enabled: true, APIKeys: map[string]bool{"test-key": true}},
+		})
+		assert.NotNil(t, application.Router)
+		assert.NotNil(t, application.GRPCServer)
+		assert.Contains(t, application.GRPCServer.GetServiceInfo(), "pack.v1.PackService")
+	})
+}
@@ -5,11 +5,33 @@ import (
 	"context"
 	"time"
 
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/alerting"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/notify"
 	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 )
 
+// defaultPermissions returns the permissions every deployment starts with.
+// The first two entries are granted to the "user" role; "admin" gets all of them.
+func defaultPermissions() []*model.Permission {
+	return []*model.Permission{
+		{Name: "packs:read", Description: "Read pack calculations", Resource: "packs", Action: "read", Active: true},
+		{Name: "packs:write", Description: "Create pack calculations", Resource: "packs", Action: "write", Active: true},
+		{Name: "users:read", Description: "Read users", Resource: "users", Action: "read", Active: true},
+		{Name: "users:write", Description: "Create/update users", Resource: "users", Action: "write", Active: true},
+		{Name: "users:delete", Description: "Delete users", Resource: "users", Action: "delete", Active: true},
+		{Name: "roles:read", Description: "Read roles", Resource: "roles", Action: "read", Active: true},
+		{Name: "roles:write", Description: "Create/update roles", Resource: "roles", Action: "write", Active: true},
+		{Name: "system:read", Description: "Read diagnostics and support bundles", Resource: "system", Action: "read", Active: true},
+		{Name: "logs:read", Description: "Query request and audit logs", Resource: "logs", Action: "read", Active: true},
+		{Name: "system:write", Description: "Operate the service, such as resetting circuit breakers", Resource: "system", Action: "write", Active: true},
+	}
+}
+
 // initializeDefaultRolesAndPermissions creates default roles and permissions if they don't exist.
 func initializeDefaultRolesAndPermissions(
 	roleRepo repository.RoleRepositoryInterface,
@@ -19,15 +41,7 @@ func initializeDefaultRolesAndPermissions(
 	defer cancel()
 
 	// Create default permissions
-	permissions := []*model.Permission{
-		{Name: "packs:read", Description: "Read pack calculations", Resource: "packs", Action: "read", Active: true},
-		{Name: "packs:write", Description: "Create pack calculations", Resource: "packs", Action: "write", Active: true},
-		{Name: "users:read", Description: "Read users", Resource: "users", Action: "read", Active: true},
-		{Name: "users:write", Description: "Create/update users", Resource: "users", Action: "write", Active: true},
-		{Name: "users:delete", Description: "Delete users", Resource: "users", Action: "delete", Active: true},
-		{Name: "roles:read", Description: "Read roles", Resource: "roles", Action: "read", Active: true},
-		{Name: "roles:write", Description: "Create/update roles", Resource: "roles", Action: "write", Active: true},
-	}
+	permissions := defaultPermissions()
 
 	permissionIDs := make([]string, 0, len(permissions))
 	for _, perm := range permissions {
@@ -68,8 +82,172 @@ func initializeDefaultRolesAndPermissions(
 			} else {
 				log.Info().Str("role", role.Name).Msg("Created default role")
 			}
+			continue
+		}
+
+		// Permissions added in later releases must reach existing admin roles too
+		if role.Name == "admin" {
+			syncRolePermissions(ctx, roleRepo, existing, permissionIDs)
 		}
 	}
 
 	return nil
 }
+
+// syncRolePermissions grants role any of permissionIDs it is missing.
+func syncRolePermissions(ctx context.Context, roleRepo repository.RoleRepositoryInterface, role *model.Role, permissionIDs []string) {
+	granted := make(map[string]bool, len(role.Permissions))
+	for _, id := range role.Permissions {
+		granted[id] = true
+	}
+
+	missing := 0
+	for _, id := range permissionIDs {
+		if !granted[id] {
+			role.Permissions = append(role.Permissions, id)
+			missing++
+		}
+	}
+	if missing == 0 {
+		return
+	}
+
+	if err := roleRepo.Update(ctx, role); err != nil {
+		log.Warn().Err(err).Str("role", role.Name).Msg("Failed to grant new permissions to role")
+		return
+	}
+	log.Info().Str("role", role.Name).Int("granted", missing).Msg("Granted new default permissions to role")
+}
+
+// InitializeTokenBlacklistCache puts a Redis cache in front of the token
+// repository's blacklist lookups when AUTH_BLACKLIST_CACHE is "redis".
+func InitializeTokenBlacklistCache(cfg config.Config, dbComponents *DatabaseComponents) {
+	if dbComponents == nil || dbComponents.TokenRepo == nil {
+		return
+	}
+
+	switch cfg.Auth.BlacklistCache {
+	case "", "none":
+		return
+	case "redis":
+	default:
+		log.Warn().Str("backend", cfg.Auth.BlacklistCache).Msg("Unknown AUTH_BLACKLIST_CACHE, token blacklist cache disabled")
+		return
+	}
+
+	redisCfg := cfg.Cache.Redis
+	client := redis.NewClient(&redis.Options{
+		Addr:         redisCfg.Addr,
+		Password:     redisCfg.Password,
+		DB:           redisCfg.DB,
+		PoolSize:     redisCfg.PoolSize,
+		MinIdleConns: redisCfg.MinIdleConns,
+		DialTimeout:  redisCfg.DialTimeout,
+	})
+	blacklistCache := repository.NewRedisTokenBlacklistCache(client, repository.DefaultBlacklistKeyPrefix, redisCfg.OperationTimeout)
+	dbComponents.TokenRepo = repository.NewTokenRepositoryWithBlacklistCache(dbComponents.TokenRepo, blacklistCache, cfg.Auth.BlacklistCacheTTL)
+	log.Info().Str("addr", redisCfg.Addr).Msg("Using Redis token blacklist cache")
+}
+
+// InitializeLegacyTokenMigration starts migrating refresh tokens stored in
+// plaintext to hashed storage in the background. The returned hook stops
+// the migration at shutdown; it is nil when there is nothing to run.
+func InitializeLegacyTokenMigration(cfg config.AuthConfig, dbComponents *DatabaseComponents) func(context.Context) {
+	if dbComponents == nil || dbComponents.TokenRepo == nil || cfg.LegacyTokenBatchSize <= 0 {
+		return nil
+	}
+
+	migration := service.NewLegacyTokenMigration(dbComponents.TokenRepo, service.LegacyTokenMigrationConfig{
+		BatchSize: cfg.LegacyTokenBatchSize,
+		Cutoff:    cfg.LegacyTokenCutoff,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := migration.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("Plaintext refresh token migration stopped; it resumes on the next start")
+		}
+	}()
+
+	return func(shutdownCtx context.Context) {
+		cancel()
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+		}
+	}
+}
+
+// InitializeTokenCleanup creates the expired token cleanup behind the admin
+// trigger and, unless cfg.TokenCleanupInterval is zero, starts running it on
+// schedule. The returned hook stops it at shutdown; both are nil without a
+// token repository.
+func InitializeTokenCleanup(cfg config.AuthConfig, dbComponents *DatabaseComponents) (*service.TokenCleanupJob, func(context.Context)) {
+	if dbComponents == nil || dbComponents.TokenRepo == nil {
+		return nil, nil
+	}
+
+	job := service.NewTokenCleanupJob(dbComponents.TokenRepo, cfg.TokenCleanupInterval)
+	if cfg.TokenCleanupInterval <= 0 {
+		return job, nil
+	}
+	job.Start()
+	log.Info().Dur("interval", cfg.TokenCleanupInterval).Msg("Expired token cleanup enabled")
+
+	return job, func(context.Context) { job.Stop() }
+}
+
+// InitializeStaleAccountReport starts the periodic stale account report,
+// sent to the alerting channels when alerting is enabled and logged
+// otherwise. The returned hook stops it at shutdown; it is nil when the
+// report is disabled or there are no users to report on. monitor may be nil.
+func InitializeStaleAccountReport(cfg config.Config, users service.UserService, monitor *notify.WebhookMonitor) func(context.Context) {
+	if users == nil || cfg.Auth.StaleAccountReportInterval <= 0 {
+		return nil
+	}
+
+	var notifier notify.Notifier = notify.NewLogNotifier()
+	if cfg.Alerting.Enabled {
+		notifier = buildNotifier(cfg.Alerting, alerting.DefaultBreakerTripPolicyConfig(), monitor)
+	}
+	reporter := service.NewStaleAccountReporter(users, notifier, service.StaleAccountReportConfig{
+		Interval:       cfg.Auth.StaleAccountReportInterval,
+		InactiveDays:   cfg.Auth.StaleAccountDays,
+		DeactivateDays: cfg.Auth.StaleAccountDeactivateDays,
+	})
+	reporter.Start()
+
+	log.Info().
+		Dur("interval", cfg.Auth.StaleAccountReportInterval).
+		Int("inactive_days", cfg.Auth.StaleAccountDays).
+		Int("deactivate_days", cfg.Auth.StaleAccountDeactivateDays).
+		Msg("Stale account report enabled")
+
+	return func(context.Context) { reporter.Stop() }
+}
+
+// InitializeLoginAnomalyDetection returns auth with suspicious logins
+// audited and alerted on, or auth unchanged when detection is disabled or
+// failed logins cannot be counted.
+func InitializeLoginAnomalyDetection(cfg config.Config, auth service.AuthService, logging service.LoggingService, dbComponents *DatabaseComponents, monitor *notify.WebhookMonitor) service.AuthService {
+	if auth == nil || !cfg.Auth.LoginAnomalyDetection || dbComponents == nil || dbComponents.LoginAttemptRepo == nil {
+		return auth
+	}
+
+	var notifier notify.Notifier = notify.NewLogNotifier()
+	if cfg.Alerting.Enabled {
+		notifier = buildNotifier(cfg.Alerting, alerting.DefaultBreakerTripPolicyConfig(), monitor)
+	}
+	detector := service.NewLoginAnomalyDetector(dbComponents.LoginAttemptRepo, logging, notifier, service.LoginAnomalyConfig{
+		FailureThreshold: cfg.Auth.LoginAnomalyFailures,
+		Window:           cfg.Auth.LoginAnomalyWindow,
+	})
+
+	log.Info().
+		Int("failure_threshold", cfg.Auth.LoginAnomalyFailures).
+		Dur("window", cfg.Auth.LoginAnomalyWindow).
+		Msg("Login anomaly detection enabled")
+
+	return service.NewAnomalyAuthService(auth, detector)
+}
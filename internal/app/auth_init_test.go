@@ -3,11 +3,15 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/guttosm/pack-service/config"
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,10 +26,8 @@ func TestInitializeDefaultRolesAndPermissions(t *testing.T) {
 		{
 			name: "successful initialization",
 			setupMocks: func(roleRepo *mocks.MockRoleRepositoryInterface, permRepo *mocks.MockPermissionRepositoryInterface) {
-				permResources := []string{"packs", "packs", "users", "users", "users", "roles", "roles"}
-				permActions := []string{"read", "write", "read", "write", "delete", "read", "write"}
-				for i := 0; i < 7; i++ {
-					permRepo.On("FindByResourceAndAction", mock.Anything, permResources[i], permActions[i]).Return(nil, nil).Once()
+				for _, perm := range defaultPermissions() {
+					permRepo.On("FindByResourceAndAction", mock.Anything, perm.Resource, perm.Action).Return(nil, nil).Once()
 					permRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Permission")).Return(nil).Once()
 				}
 				roleRepo.On("FindByName", mock.Anything, "user").Return(nil, nil).Once()
@@ -42,15 +44,13 @@ func TestInitializeDefaultRolesAndPermissions(t *testing.T) {
 		{
 			name: "permissions already exist",
 			setupMocks: func(roleRepo *mocks.MockRoleRepositoryInterface, permRepo *mocks.MockPermissionRepositoryInterface) {
-				permResources := []string{"packs", "packs", "users", "users", "users", "roles", "roles"}
-				permActions := []string{"read", "write", "read", "write", "delete", "read", "write"}
-				for i := 0; i < 7; i++ {
+				for _, perm := range defaultPermissions() {
 					existingPerm := &model.Permission{
 						ID:       primitive.NewObjectID(),
-						Resource: permResources[i],
-						Action:   permActions[i],
+						Resource: perm.Resource,
+						Action:   perm.Action,
 					}
-					permRepo.On("FindByResourceAndAction", mock.Anything, permResources[i], permActions[i]).Return(existingPerm, nil).Once()
+					permRepo.On("FindByResourceAndAction", mock.Anything, perm.Resource, perm.Action).Return(existingPerm, nil).Once()
 				}
 				roleRepo.On("FindByName", mock.Anything, "user").Return(nil, nil).Once()
 				roleRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
@@ -62,10 +62,8 @@ func TestInitializeDefaultRolesAndPermissions(t *testing.T) {
 		{
 			name: "roles already exist",
 			setupMocks: func(roleRepo *mocks.MockRoleRepositoryInterface, permRepo *mocks.MockPermissionRepositoryInterface) {
-				permResources := []string{"packs", "packs", "users", "users", "users", "roles", "roles"}
-				permActions := []string{"read", "write", "read", "write", "delete", "read", "write"}
-				for i := 0; i < 7; i++ {
-					permRepo.On("FindByResourceAndAction", mock.Anything, permResources[i], permActions[i]).Return(nil, nil).Once()
+				for _, perm := range defaultPermissions() {
+					permRepo.On("FindByResourceAndAction", mock.Anything, perm.Resource, perm.Action).Return(nil, nil).Once()
 					permRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
 				}
 				existingUserRole := &model.Role{
@@ -78,6 +76,10 @@ func TestInitializeDefaultRolesAndPermissions(t *testing.T) {
 				}
 				roleRepo.On("FindByName", mock.Anything, "user").Return(existingUserRole, nil).Once()
 				roleRepo.On("FindByName", mock.Anything, "admin").Return(existingAdminRole, nil).Once()
+				// The existing admin role is granted every default permission
+				roleRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *model.Role) bool {
+					return r.Name == "admin" && len(r.Permissions) == len(defaultPermissions())
+				})).Return(nil).Once()
 			},
 			wantError: false,
 		},
@@ -86,7 +88,7 @@ func TestInitializeDefaultRolesAndPermissions(t *testing.T) {
 			setupMocks: func(roleRepo *mocks.MockRoleRepositoryInterface, permRepo *mocks.MockPermissionRepositoryInterface) {
 				permRepo.On("FindByResourceAndAction", mock.Anything, "packs", "read").Return(nil, nil).Once()
 				permRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error")).Once()
-				for i := 1; i < 7; i++ {
+				for i := 1; i < len(defaultPermissions()); i++ {
 					permRepo.On("FindByResourceAndAction", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 					permRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
 				}
@@ -98,10 +100,8 @@ func TestInitializeDefaultRolesAndPermissions(t *testing.T) {
 		{
 			name: "role creation error",
 			setupMocks: func(roleRepo *mocks.MockRoleRepositoryInterface, permRepo *mocks.MockPermissionRepositoryInterface) {
-				permResources := []string{"packs", "packs", "users", "users", "users", "roles", "roles"}
-				permActions := []string{"read", "write", "read", "write", "delete", "read", "write"}
-				for i := 0; i < 7; i++ {
-					permRepo.On("FindByResourceAndAction", mock.Anything, permResources[i], permActions[i]).Return(nil, nil).Once()
+				for _, perm := range defaultPermissions() {
+					permRepo.On("FindByResourceAndAction", mock.Anything, perm.Resource, perm.Action).Return(nil, nil).Once()
 					permRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
 				}
 				roleRepo.On("FindByName", mock.Anything, "user").Return(nil, nil).Once()
@@ -131,3 +131,115 @@ func TestInitializeDefaultRolesAndPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncRolePermissions(t *testing.T) {
+	t.Run("no missing permissions", func(t *testing.T) {
+		roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+		role := &model.Role{Name: "admin", Permissions: []string{"a", "b"}}
+
+		syncRolePermissions(context.Background(), roleRepo, role, []string{"a", "b"})
+
+		assert.Equal(t, []string{"a", "b"}, role.Permissions)
+	})
+
+	t.Run("grants missing permissions", func(t *testing.T) {
+		roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+		role := &model.Role{Name: "admin", Permissions: []string{"a"}}
+		roleRepo.On("Update", mock.Anything, role).Return(nil).Once()
+
+		syncRolePermissions(context.Background(), roleRepo, role, []string{"a", "b", "c"})
+
+		assert.Equal(t, []string{"a", "b", "c"}, role.Permissions)
+	})
+
+	t.Run("update error is tolerated", func(t *testing.T) {
+		roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+		role := &model.Role{Name: "admin"}
+		roleRepo.On("Update", mock.Anything, role).Return(errors.New("database error")).Once()
+
+		syncRolePermissions(context.Background(), roleRepo, role, []string{"a"})
+	})
+}
+
+func TestInitializeTokenBlacklistCache(t *testing.T) {
+	InitializeTokenBlacklistCache(config.Config{Auth: config.AuthConfig{BlacklistCache: "redis"}}, nil)
+
+	tests := []struct {
+		backend string
+		wrapped bool
+	}{
+		{"none", false},
+		{"", false},
+		{"memcached", false},
+		{"redis", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+			components := &DatabaseComponents{TokenRepo: tokenRepo}
+
+			InitializeTokenBlacklistCache(config.Config{Auth: config.AuthConfig{BlacklistCache: tt.backend}}, components)
+
+			_, wrapped := components.TokenRepo.(*repository.TokenRepositoryWithBlacklistCache)
+			assert.Equal(t, tt.wrapped, wrapped)
+		})
+	}
+}
+
+func TestInitializeLegacyTokenMigration(t *testing.T) {
+	assert.Nil(t, InitializeLegacyTokenMigration(config.AuthConfig{LegacyTokenBatchSize: 100}, nil))
+
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	components := &DatabaseComponents{TokenRepo: tokenRepo}
+	assert.Nil(t, InitializeLegacyTokenMigration(config.AuthConfig{}, components))
+
+	tokenRepo.EXPECT().CountLegacyRefreshTokens(mock.Anything).Return(0, nil)
+	stop := InitializeLegacyTokenMigration(config.AuthConfig{LegacyTokenBatchSize: 100}, components)
+	assert.NotNil(t, stop)
+	stop(context.Background())
+}
+
+func TestInitializeTokenCleanup(t *testing.T) {
+	job, stop := InitializeTokenCleanup(config.AuthConfig{TokenCleanupInterval: time.Hour}, nil)
+	assert.Nil(t, job)
+	assert.Nil(t, stop)
+
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	components := &DatabaseComponents{TokenRepo: tokenRepo}
+	job, stop = InitializeTokenCleanup(config.AuthConfig{}, components)
+	assert.NotNil(t, job, "the admin trigger works without a schedule")
+	assert.Nil(t, stop)
+
+	tokenRepo.EXPECT().CleanupExpired(mock.Anything).Return(int64(0), nil).Maybe()
+	job, stop = InitializeTokenCleanup(config.AuthConfig{TokenCleanupInterval: time.Hour}, components)
+	assert.NotNil(t, job)
+	assert.NotNil(t, stop)
+	stop(context.Background())
+}
+
+func TestInitializeStaleAccountReport(t *testing.T) {
+	cfg := config.Config{Auth: config.AuthConfig{StaleAccountReportInterval: time.Hour}}
+	assert.Nil(t, InitializeStaleAccountReport(cfg, nil, nil))
+
+	users := mocks.NewMockUserService(t)
+	assert.Nil(t, InitializeStaleAccountReport(config.Config{}, users, nil))
+
+	stop := InitializeStaleAccountReport(cfg, users, nil)
+	assert.NotNil(t, stop)
+	stop(context.Background())
+}
+
+func TestInitializeLoginAnomalyDetection(t *testing.T) {
+	cfg := config.Config{Auth: config.AuthConfig{LoginAnomalyDetection: true, LoginAnomalyFailures: 5, LoginAnomalyWindow: time.Hour}}
+	auth := mocks.NewMockAuthService(t)
+	dbComponents := &DatabaseComponents{LoginAttemptRepo: mocks.NewMockLoginAttemptRepositoryInterface(t)}
+
+	assert.Nil(t, InitializeLoginAnomalyDetection(cfg, nil, nil, dbComponents, nil))
+	assert.Same(t, auth, InitializeLoginAnomalyDetection(config.Config{}, auth, nil, dbComponents, nil))
+	assert.Same(t, auth, InitializeLoginAnomalyDetection(cfg, auth, nil, &DatabaseComponents{}, nil))
+
+	wrapped := InitializeLoginAnomalyDetection(cfg, auth, nil, dbComponents, nil)
+	assert.NotNil(t, wrapped)
+	assert.NotSame(t, auth, wrapped)
+}
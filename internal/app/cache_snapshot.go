@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// cacheSnapshotTimeout bounds the pack sizes version lookup made around a snapshot.
+const cacheSnapshotTimeout = 2 * time.Second
+
+// cacheSnapshotter is implemented by calculators whose result cache can be snapshotted.
+type cacheSnapshotter interface {
+	SaveCacheSnapshot(path string, maxEntries, configVersion int) (int, error)
+	RestoreCacheSnapshot(path string, maxAge time.Duration, configVersion int) (int, error)
+}
+
+// InitializeCacheSnapshot warms the result cache from the snapshot at
+// cfg.SnapshotPath and returns a shutdown hook that saves a new one.
+// Returns nil if snapshots are disabled or the cache does not support them.
+func InitializeCacheSnapshot(cfg config.CacheConfig, calculator service.PackCalculator, dbComponents *DatabaseComponents) func(context.Context) {
+	if cfg.SnapshotPath == "" {
+		return nil
+	}
+	snapshotter, ok := calculator.(cacheSnapshotter)
+	if !ok {
+		return nil
+	}
+
+	restored, err := snapshotter.RestoreCacheSnapshot(cfg.SnapshotPath, cfg.SnapshotMaxAge, packSizesVersion(context.Background(), dbComponents))
+	switch {
+	case errors.Is(err, service.ErrCacheSnapshotUnsupported):
+		log.Warn().Str("backend", cfg.Backend).Msg("CACHE_SNAPSHOT_PATH is set but the result cache does not support snapshots")
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		log.Info().Str("path", cfg.SnapshotPath).Msg("No cache snapshot found, starting with an empty cache")
+	case err != nil:
+		log.Warn().Err(err).Str("path", cfg.SnapshotPath).Msg("Discarded cache snapshot")
+	default:
+		log.Info().Int("entries", restored).Str("path", cfg.SnapshotPath).Msg("Restored result cache from snapshot")
+	}
+
+	maxEntries := cfg.SnapshotMaxEntries
+	if maxEntries <= 0 {
+		maxEntries = cfg.Size
+	}
+	return func(ctx context.Context) {
+		saved, err := snapshotter.SaveCacheSnapshot(cfg.SnapshotPath, maxEntries, packSizesVersion(ctx, dbComponents))
+		if err != nil {
+			log.Error().Err(err).Str("path", cfg.SnapshotPath).Msg("Failed to save cache snapshot")
+			return
+		}
+		log.Info().Int("entries", saved).Str("path", cfg.SnapshotPath).Msg("Saved cache snapshot")
+	}
+}
+
+// packSizesVersion returns the active pack sizes configuration version, or
+// zero without a database or when it cannot be read.
+func packSizesVersion(ctx context.Context, dbComponents *DatabaseComponents) int {
+	if dbComponents == nil || dbComponents.PackSizesRepo == nil {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, cacheSnapshotTimeout)
+	defer cancel()
+
+	active, err := dbComponents.PackSizesRepo.GetActive(ctx)
+	if err != nil || active == nil {
+		return 0
+	}
+	return active.Version
+}
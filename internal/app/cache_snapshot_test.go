@@ -0,0 +1,49 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestInitializeCacheSnapshot(t *testing.T) {
+	cfg := config.CacheConfig{
+		Backend:        "memory",
+		Size:           100,
+		TTL:            time.Minute,
+		SnapshotPath:   filepath.Join(t.TempDir(), "cache.json"),
+		SnapshotMaxAge: time.Hour,
+	}
+
+	t.Run("disabled without a path", func(t *testing.T) {
+		assert.Nil(t, InitializeCacheSnapshot(config.CacheConfig{Size: 100}, InitializeServices(config.CacheConfig{Size: 100}).Calculator, nil))
+	})
+
+	t.Run("disabled without a snapshot capable cache", func(t *testing.T) {
+		assert.Nil(t, InitializeCacheSnapshot(cfg, service.NewPackCalculatorService(), nil))
+	})
+
+	t.Run("saves on shutdown and restores on startup", func(t *testing.T) {
+		calculator := InitializeServices(cfg).Calculator
+		save := InitializeCacheSnapshot(cfg, calculator, nil)
+		require.NotNil(t, save)
+		calculator.Calculate(251)
+		save(context.Background())
+		assert.FileExists(t, cfg.SnapshotPath)
+
+		restarted := InitializeServices(cfg).Calculator.(*service.PackCalculatorService)
+		require.NotNil(t, InitializeCacheSnapshot(cfg, restarted, nil))
+		metrics, ok := restarted.CacheMetrics()
+		require.True(t, ok)
+		assert.Equal(t, 1, metrics.Size)
+	})
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// InitializeCacheWarmup starts warming the result cache with the configured
+// order quantities and the most requested ones in the calculation history,
+// again whenever packSizes changes. The returned hook stops it at shutdown;
+// it is nil when no quantities are configured.
+func InitializeCacheWarmup(cfg config.CacheConfig, calculator service.PackCalculator, dbComponents *DatabaseComponents, packSizes *service.ActivePackSizes) func(context.Context) {
+	if len(cfg.WarmupQuantities) == 0 && cfg.WarmupTop <= 0 {
+		return nil
+	}
+
+	var history repository.CalculationRepositoryInterface
+	if dbComponents != nil && dbComponents.CalculationRepo != nil {
+		history = dbComponents.CalculationRepo
+	} else if cfg.WarmupTop > 0 {
+		log.Warn().Msg("No calculation history, warming only the configured quantities")
+	}
+
+	warmer := service.NewCacheWarmer(calculator, history, packSizes, service.CacheWarmupConfig{
+		Quantities: cfg.WarmupQuantities,
+		Top:        cfg.WarmupTop,
+		Window:     cfg.WarmupWindow,
+		Interval:   cfg.WarmupInterval,
+	})
+	warmer.Start()
+
+	log.Info().
+		Ints("quantities", cfg.WarmupQuantities).
+		Int("top", cfg.WarmupTop).
+		Dur("interval", cfg.WarmupInterval).
+		Msg("Cache warm-up enabled")
+
+	return func(context.Context) { warmer.Stop() }
+}
@@ -0,0 +1,35 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestInitializeCacheWarmup(t *testing.T) {
+	calculator := service.NewPackCalculatorService(service.WithCache(10, time.Minute))
+	assert.Nil(t, InitializeCacheWarmup(config.CacheConfig{}, calculator, nil, nil))
+
+	calculationRepo := mocks.NewMockCalculationRepositoryInterface(t)
+	read := make(chan struct{})
+	calculationRepo.EXPECT().TopQuantities(mock.Anything, mock.Anything, 5).
+		RunAndReturn(func(context.Context, time.Time, int) ([]int, error) {
+			close(read)
+			return []int{251}, nil
+		}).Once()
+
+	cfg := config.CacheConfig{WarmupQuantities: []int{12001}, WarmupTop: 5}
+	stop := InitializeCacheWarmup(cfg, calculator, &DatabaseComponents{CalculationRepo: calculationRepo}, nil)
+	assert.NotNil(t, stop)
+	<-read
+	stop(context.Background())
+}
@@ -0,0 +1,243 @@
+package app
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/spreadsheet"
+)
+
+// Formats of offline batch calculations.
+const (
+	// CalcFormatJSON reads calculate requests, one JSON object after the
+	// other or a batch request body, and writes one result per line.
+	CalcFormatJSON = "json"
+	// CalcFormatCSV reads rows of items_ordered and optional pack sizes,
+	// separated by semicolons, and writes the batch spreadsheet columns.
+	CalcFormatCSV = "csv"
+)
+
+// ErrUnknownCalcFormat is returned for a batch format other than json or csv.
+var ErrUnknownCalcFormat = errors.New("unknown format, use json or csv")
+
+// NewOfflineCalculator returns a calculator with the configured pack sizes
+// and order limits, without the result cache, for calculations outside the
+// server. Pack sizes stored in MongoDB are not read.
+func NewOfflineCalculator(cfg config.Config) service.PackCalculator {
+	opts := []service.Option{
+		service.WithMaxItemsOrdered(cfg.Server.MaxItemsOrdered),
+		service.WithBoundedSearchThreshold(cfg.Server.BoundedSearchThreshold),
+		service.WithMaxTableTotals(cfg.Server.ExactSearchMaxTotals),
+	}
+	if len(cfg.Cache.PackSizes) > 0 {
+		opts = append(opts, service.WithPackSizes(cfg.Cache.PackSizes))
+	}
+	return service.NewPackCalculatorService(opts...)
+}
+
+// CalculateOffline validates and calculates req the way the calculate
+// endpoint does, without hooks, presets or presentation rules.
+func CalculateOffline(calculator service.PackCalculator, req dto.CalculatePacksRequest) (model.PackResult, error) {
+	if req.Preset != "" {
+		return model.PackResult{}, errors.New("preset: not available offline")
+	}
+	if err := req.Validate(); err != nil {
+		return model.PackResult{}, err
+	}
+	if err := req.CheckItemsOrdered(service.MaxItemsOrdered(calculator)); err != nil {
+		return model.PackResult{}, err
+	}
+
+	var sizes []int
+	for _, size := range req.PackSizes {
+		if size > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+
+	var result model.PackResult
+	var err error
+	constraints := req.Constraints()
+	switch {
+	case req.MaxComputeMs != nil:
+		result, err = calculator.CalculateWithin(req.ItemsOrdered, sizes, constraints, time.Duration(*req.MaxComputeMs)*time.Millisecond)
+	case !constraints.IsZero():
+		result, err = calculator.CalculateWithConstraints(req.ItemsOrdered, sizes, constraints)
+	case len(sizes) > 0:
+		result = calculator.CalculateWithPackSizes(req.ItemsOrdered, sizes)
+	default:
+		result = calculator.Calculate(req.ItemsOrdered)
+	}
+	if err != nil {
+		return result, err
+	}
+	if req.Explain {
+		result.Explanation = calculator.Explain(result, sizes, constraints)
+	}
+	result.Metadata = req.Metadata
+	return result, nil
+}
+
+// CalculateBatchOffline reads orders in format from r and writes one result
+// per order to w, in input order, as each is calculated. Orders that fail
+// are written as errors; it returns how many failed. Unreadable input stops
+// the batch with an error.
+func CalculateBatchOffline(calculator service.PackCalculator, r io.Reader, w io.Writer, format string) (failed int, err error) {
+	var (
+		read   func(emit func(dto.CalculatePacksRequest) error) error
+		write  func(itemsOrdered int, result dto.BatchItemResult) error
+		finish func() error
+	)
+
+	switch format {
+	case CalcFormatJSON:
+		read = func(emit func(dto.CalculatePacksRequest) error) error { return readJSONOrders(r, emit) }
+		buf := bufio.NewWriter(w)
+		enc := json.NewEncoder(buf)
+		write = func(_ int, result dto.BatchItemResult) error { return enc.Encode(result) }
+		finish = buf.Flush
+	case CalcFormatCSV:
+		read = func(emit func(dto.CalculatePacksRequest) error) error { return readCSVOrders(r, emit) }
+		table := spreadsheet.NewCSVWriter(w)
+		if err := table.Write(dto.BatchItemColumns); err != nil {
+			return 0, err
+		}
+		write = func(itemsOrdered int, result dto.BatchItemResult) error { return table.Write(result.Row(itemsOrdered)) }
+		finish = table.Close
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCalcFormat, format)
+	}
+
+	index := 0
+	err = read(func(req dto.CalculatePacksRequest) error {
+		item := dto.BatchItemResult{Index: index}
+		index++
+		result, err := CalculateOffline(calculator, req)
+		if err != nil {
+			failed++
+			item.Error = offlineError(err)
+		} else {
+			item.Result = &result
+		}
+		return write(req.ItemsOrdered, item)
+	})
+	if finishErr := finish(); err == nil {
+		err = finishErr
+	}
+	return failed, err
+}
+
+// offlineError returns the batch item error of a failed calculation.
+func offlineError(err error) *dto.BatchItemError {
+	code := dto.ErrCodeInvalidRequest
+	var tooLarge *dto.ItemsOrderedTooLargeError
+	if domainErr, ok := domainerr.From(err); ok {
+		code = domainErr.Code
+	} else if errors.As(err, &tooLarge) {
+		code = dto.ErrCodeUnprocessable
+	}
+	return &dto.BatchItemError{Code: code, Message: err.Error()}
+}
+
+// readJSONOrders passes each calculate request of r to emit. r holds JSON
+// objects one after the other, such as one per line, each a calculate
+// request or a batch request body with items.
+func readJSONOrders(r io.Reader, emit func(dto.CalculatePacksRequest) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var order struct {
+			dto.CalculatePacksRequest
+			Items []dto.CalculatePacksRequest `json:"items"`
+		}
+		if err := dec.Decode(&order); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("invalid JSON input: %w", err)
+		}
+
+		if order.Items == nil {
+			if err := emit(order.CalculatePacksRequest); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, item := range order.Items {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readCSVOrders passes each row of r to emit as a calculate request. Rows
+// are items_ordered and optionally the pack sizes, separated by semicolons;
+// a first row that is not a number is a header and skipped, and lines
+// starting with # are comments.
+func readCSVOrders(r io.Reader, emit func(dto.CalculatePacksRequest) error) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid CSV input: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		items, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil && first {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("invalid CSV input: line %d: items_ordered %q is not a number", line, record[0])
+		}
+
+		req := dto.CalculatePacksRequest{ItemsOrdered: items}
+		if len(record) > 1 {
+			if req.PackSizes, err = parseSizes(record[1], ";"); err != nil {
+				return fmt.Errorf("invalid CSV input: line %d: %w", line, err)
+			}
+		}
+		if err := emit(req); err != nil {
+			return err
+		}
+	}
+}
+
+// ParsePackSizes parses pack sizes separated by commas, such as 250,500,1000.
+func ParsePackSizes(s string) ([]int, error) {
+	return parseSizes(s, ",")
+}
+
+func parseSizes(s, sep string) ([]int, error) {
+	var sizes []int
+	for _, part := range strings.Split(s, sep) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		size, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("pack size %q is not a number", part)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
@@ -0,0 +1,118 @@
+//go:build !integration
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestNewOfflineCalculator(t *testing.T) {
+	calculator := NewOfflineCalculator(config.Config{
+		Server: config.ServerConfig{MaxItemsOrdered: 1000},
+		Cache:  config.CacheConfig{PackSizes: []int{23, 31, 53}},
+	})
+
+	assert.Equal(t, 263, calculator.Calculate(263).TotalItems)
+	assert.Equal(t, 1000, service.MaxItemsOrdered(calculator))
+}
+
+func TestCalculateOffline(t *testing.T) {
+	calculator := NewOfflineCalculator(config.Config{})
+
+	result, err := CalculateOffline(calculator, dto.CalculatePacksRequest{ItemsOrdered: 263, PackSizes: []int{23, 31, 53}, Explain: true})
+	require.NoError(t, err)
+	assert.Equal(t, 263, result.TotalItems)
+	assert.NotNil(t, result.Explanation)
+
+	_, err = CalculateOffline(calculator, dto.CalculatePacksRequest{ItemsOrdered: 0})
+	assert.Error(t, err)
+	_, err = CalculateOffline(calculator, dto.CalculatePacksRequest{ItemsOrdered: 10, Preset: "warehouse-a"})
+	assert.Error(t, err, "presets are stored in MongoDB")
+}
+
+func TestCalculateBatchOffline_JSON(t *testing.T) {
+	input := `{"items_ordered": 251}
+{"items": [{"items_ordered": 0}, {"items_ordered": 5, "pack_sizes": [3], "max_packs": 1}]}
+{"items_ordered": 12001, "pack_sizes": [250, 500, 1000]}`
+
+	var out bytes.Buffer
+	failed, err := CalculateBatchOffline(NewOfflineCalculator(config.Config{}), strings.NewReader(input), &out, CalcFormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, 2, failed)
+
+	var results []dto.BatchItemResult
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var result dto.BatchItemResult
+		require.NoError(t, dec.Decode(&result))
+		results = append(results, result)
+	}
+	require.Len(t, results, 4)
+	assert.Equal(t, 500, results[0].Result.TotalItems)
+	assert.Equal(t, dto.ErrCodeInvalidRequest, results[1].Error.Code)
+	assert.Equal(t, dto.ErrCodeUnprocessable, results[2].Error.Code)
+	assert.Equal(t, 3, results[3].Index)
+	assert.Equal(t, 12250, results[3].Result.TotalItems)
+}
+
+func TestCalculateBatchOffline_CSV(t *testing.T) {
+	input := "items_ordered,pack_sizes\n# a comment\n251\n263, 23;31;53\n0\n"
+
+	var out bytes.Buffer
+	failed, err := CalculateBatchOffline(NewOfflineCalculator(config.Config{}), strings.NewReader(input), &out, CalcFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, `index,items_ordered,total_items,overage_items,pack_count,packs,approximate,error_code,error_message
+0,251,500,249,1,1x500,false,,
+1,263,263,0,9,"7x31, 2x23",false,,
+2,0,,,,,,invalid_request,items_ordered: must be a positive integer
+`, out.String())
+}
+
+func TestCalculateBatchOffline_InvalidInput(t *testing.T) {
+	calculator := NewOfflineCalculator(config.Config{})
+
+	tests := []struct {
+		name    string
+		input   string
+		format  string
+		wantErr string
+	}{
+		{name: "malformed JSON", input: `{"items_ordered": 251}{`, format: CalcFormatJSON, wantErr: "invalid JSON input"},
+		{name: "row not a number", input: "251\nmany\n", format: CalcFormatCSV, wantErr: `line 2: items_ordered "many" is not a number`},
+		{name: "pack size not a number", input: "251,250;big\n", format: CalcFormatCSV, wantErr: `line 1: pack size "big" is not a number`},
+		{name: "unknown format", format: "xml", wantErr: ErrUnknownCalcFormat.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			_, err := CalculateBatchOffline(calculator, strings.NewReader(tt.input), &out, tt.format)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParsePackSizes(t *testing.T) {
+	sizes, err := ParsePackSizes("250, 500,,1000")
+	require.NoError(t, err)
+	assert.Equal(t, []int{250, 500, 1000}, sizes)
+
+	sizes, err = ParsePackSizes("")
+	require.NoError(t, err)
+	assert.Empty(t, sizes)
+
+	_, err = ParsePackSizes("250,half")
+	assert.Error(t, err)
+}
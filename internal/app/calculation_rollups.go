@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// InitializeCalculationRollups starts the job maintaining the daily
+// calculation rollups behind the analytics API. The returned hook stops it
+// at shutdown; it is nil without a calculation history or when the job is
+// disabled.
+func InitializeCalculationRollups(cfg config.DatabaseConfig, dbComponents *DatabaseComponents) func(context.Context) {
+	if dbComponents == nil || dbComponents.CalculationRepo == nil || cfg.CalculationRollupInterval <= 0 {
+		return nil
+	}
+
+	job := service.NewCalculationRollupJob(dbComponents.CalculationRepo, service.CalculationRollupConfig{
+		Interval:     cfg.CalculationRollupInterval,
+		LookbackDays: cfg.CalculationRollupLookbackDays,
+	})
+	job.Start()
+
+	log.Info().
+		Dur("interval", cfg.CalculationRollupInterval).
+		Int("lookback_days", cfg.CalculationRollupLookbackDays).
+		Msg("Calculation rollups enabled")
+
+	return func(context.Context) { job.Stop() }
+}
@@ -0,0 +1,35 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/mocks"
+)
+
+func TestInitializeCalculationRollups(t *testing.T) {
+	cfg := config.DatabaseConfig{CalculationRollupInterval: time.Hour, CalculationRollupLookbackDays: 1}
+	assert.Nil(t, InitializeCalculationRollups(cfg, nil))
+
+	calculationRepo := mocks.NewMockCalculationRepositoryInterface(t)
+	components := &DatabaseComponents{CalculationRepo: calculationRepo}
+	assert.Nil(t, InitializeCalculationRollups(config.DatabaseConfig{}, components))
+
+	ran := make(chan struct{})
+	calculationRepo.EXPECT().RollUp(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(context.Context, time.Time, time.Time, time.Time) error {
+			close(ran)
+			return nil
+		}).Once()
+	stop := InitializeCalculationRollups(cfg, components)
+	assert.NotNil(t, stop)
+	<-ran
+	stop(context.Background())
+}
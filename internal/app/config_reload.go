@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/http"
+	"github.com/guttosm/pack-service/internal/logger"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// cacheResizer is implemented by calculators whose in-memory result cache
+// can be resized at runtime.
+type cacheResizer interface {
+	ResizeCache(capacity int, ttl time.Duration) bool
+}
+
+// InitializeConfigReload reloads the settings that can change without a
+// restart on SIGHUP, on admin request and, with cfg.Reload.WatchInterval,
+// whenever cfg.Reload.File changes. envAPIKeys are the API keys configured
+// before the seed fixtures were merged into cfg.Auth.APIKeys; the fixture
+// keys are kept across reloads. The returned hook stops watching at shutdown.
+func InitializeConfigReload(cfg config.Config, envAPIKeys map[string]bool, calculator service.PackCalculator, routerCfg *http.RouterConfig) func(context.Context) {
+	reloader := service.NewConfigReloader(cfg, func() (config.Config, error) {
+		return config.LoadFile(cfg.Reload.File)
+	}, reloadableSettings(seededAPIKeys(cfg.Auth.APIKeys, envAPIKeys), calculator, routerCfg), routerCfg.LoggingService)
+	routerCfg.ConfigReloader = reloader
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	ctx, cancel := context.WithCancel(context.Background())
+	go reloader.Watch(ctx, signals, cfg.Reload.File, cfg.Reload.WatchInterval)
+
+	log.Info().
+		Str("file", cfg.Reload.File).
+		Dur("watch_interval", cfg.Reload.WatchInterval).
+		Msg("Configuration reloads enabled")
+	return func(context.Context) {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// reloadableSettings returns the settings applied on reload. Rate limits
+// apply to the limiters created at startup, so rate limiting cannot be turned
+// on or off, and only an in-memory result cache can be resized.
+func reloadableSettings(seeded map[string]bool, calculator service.PackCalculator, routerCfg *http.RouterConfig) []service.ReloadableSetting {
+	setRateLimit := func(cfg config.Config) {
+		if cfg.Server.RateLimit <= 0 {
+			log.Warn().Int("rate_limit", cfg.Server.RateLimit).Msg("Rate limiting cannot be disabled without a restart, keeping the previous limit")
+			return
+		}
+		routerCfg.RateLimiters.SetLimit(cfg.Server.RateLimit, cfg.Server.RateWindow)
+	}
+	resizeCache := func(cfg config.Config) {
+		resizer, ok := calculator.(cacheResizer)
+		if !ok || !resizer.ResizeCache(cfg.Cache.Size, cfg.Cache.TTL) {
+			log.Warn().
+				Int("cache_size", cfg.Cache.Size).
+				Msg("Result cache cannot be resized without a restart, keeping the previous size")
+		}
+	}
+	apiKeys := func(cfg config.Config) map[string]bool {
+		merged := make(map[string]bool, len(cfg.Auth.APIKeys)+len(seeded))
+		for key, enabled := range cfg.Auth.APIKeys {
+			merged[key] = enabled
+		}
+		for key := range seeded {
+			merged[key] = true
+		}
+		return merged
+	}
+
+	return []service.ReloadableSetting{
+		{
+			Name:  "RATE_LIMIT",
+			Value: func(cfg config.Config) string { return strconv.Itoa(cfg.Server.RateLimit) },
+			Apply: setRateLimit,
+		},
+		{
+			Name:  "RATE_WINDOW",
+			Value: func(cfg config.Config) string { return cfg.Server.RateWindow.String() },
+			Apply: setRateLimit,
+		},
+		{
+			Name:  "API_KEYS",
+			Value: func(cfg config.Config) string { return apiKeyFingerprints(apiKeys(cfg)) },
+			Apply: func(cfg config.Config) {
+				if routerCfg.APIKeySet != nil {
+					routerCfg.APIKeySet.Replace(apiKeys(cfg))
+				}
+			},
+		},
+		{
+			Name:  "CACHE_SIZE",
+			Value: func(cfg config.Config) string { return strconv.Itoa(cfg.Cache.Size) },
+			Apply: resizeCache,
+		},
+		{
+			Name:  "CACHE_TTL",
+			Value: func(cfg config.Config) string { return cfg.Cache.TTL.String() },
+			Apply: resizeCache,
+		},
+		{
+			Name:  "LOG_LEVEL",
+			Value: func(cfg config.Config) string { return cfg.Log.Level },
+			Apply: func(cfg config.Config) { logger.SetLevel(cfg.Log.Level) },
+		},
+	}
+}
+
+// seededAPIKeys returns the keys of apiKeys that envAPIKeys does not
+// configure, which came from the seed fixtures.
+func seededAPIKeys(apiKeys, envAPIKeys map[string]bool) map[string]bool {
+	seeded := make(map[string]bool)
+	for key, enabled := range apiKeys {
+		if enabled && !envAPIKeys[key] {
+			seeded[key] = true
+		}
+	}
+	return seeded
+}
+
+// apiKeyFingerprints lists the fingerprints of the enabled keys, sorted, so
+// reloads report which keys changed without revealing them.
+func apiKeyFingerprints(keys map[string]bool) string {
+	var fingerprints []string
+	for key, enabled := range keys {
+		if enabled {
+			fingerprints = append(fingerprints, middleware.APIKeyFingerprint(key))
+		}
+	}
+	slices.Sort(fingerprints)
+	return strings.Join(fingerprints, ",")
+}
@@ -0,0 +1,92 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/http"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestInitializeConfigReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pack-service.env")
+	require.NoError(t, os.WriteFile(path, []byte("API_KEYS=env-key\nCACHE_SIZE=100\n"), 0o600))
+
+	cfg, err := config.LoadFile(path)
+	require.NoError(t, err)
+	envAPIKeys := cfg.Auth.APIKeys
+	cfg.Auth.APIKeys = map[string]bool{"env-key": true, "seed-key": true}
+
+	calculator := service.NewPackCalculatorService(service.WithCache(cfg.Cache.Size, cfg.Cache.TTL))
+	routerCfg := &http.RouterConfig{
+		APIKeySet:    middleware.NewAPIKeySet(cfg.Auth.APIKeys),
+		RateLimiters: middleware.NewRateLimiterSet(),
+	}
+	stop := InitializeConfigReload(cfg, envAPIKeys, calculator, routerCfg)
+	defer stop(context.Background())
+	require.NotNil(t, routerCfg.ConfigReloader)
+
+	result, err := routerCfg.ConfigReloader.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes, "seed keys are not reported as removed")
+
+	require.NoError(t, os.WriteFile(path, []byte("API_KEYS=new-key\nCACHE_SIZE=200\n"), 0o600))
+	result, err = routerCfg.ConfigReloader.Reload()
+	require.NoError(t, err)
+
+	settings := make([]string, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		settings = append(settings, change.Setting)
+		assert.NotContains(t, change.Old+change.New, "-key", "keys are reported by fingerprint")
+	}
+	assert.ElementsMatch(t, []string{"API_KEYS", "CACHE_SIZE"}, settings)
+
+	assert.True(t, routerCfg.APIKeySet.Valid("new-key"))
+	assert.True(t, routerCfg.APIKeySet.Valid("seed-key"))
+	assert.False(t, routerCfg.APIKeySet.Valid("env-key"))
+
+	metrics, ok := calculator.CacheMetrics()
+	require.True(t, ok)
+	assert.Equal(t, 200, metrics.Capacity)
+}
+
+func TestReloadableSettings_KeepRateLimitingEnabled(t *testing.T) {
+	limiter := middleware.NewRateLimiter(10, time.Minute)
+	defer limiter.Stop()
+	routerCfg := &http.RouterConfig{RateLimiters: middleware.NewRateLimiterSet()}
+	routerCfg.RateLimiters.Add(limiter)
+
+	var current config.Config
+	current.Server.RateLimit = 10
+	current.Server.RateWindow = time.Minute
+	next := current
+	next.Server.RateLimit = 0
+	reloader := service.NewConfigReloader(current, func() (config.Config, error) {
+		return next, nil
+	}, reloadableSettings(nil, nil, routerCfg), nil)
+
+	result, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []dto.ConfigChange{{Setting: "RATE_LIMIT", Old: "10", New: "0"}}, result.Changes)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", limiter.RateLimit(), func(c *gin.Context) { c.Status(nethttp.StatusNoContent) })
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/", nil))
+	assert.Equal(t, nethttp.StatusNoContent, w.Code)
+}
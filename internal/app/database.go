@@ -3,6 +3,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/guttosm/pack-service/config"
@@ -14,14 +15,26 @@ import (
 
 // DatabaseComponents holds database-related components.
 type DatabaseComponents struct {
-	PackSizesRepo            repository.PackSizesRepositoryInterface
-	LoggingService           service.LoggingService
-	PackSizesCircuitBreaker  *circuitbreaker.CircuitBreaker
-	LogsCircuitBreaker       *circuitbreaker.CircuitBreaker
-	UserRepo                 repository.UserRepositoryInterface
-	RoleRepo                 repository.RoleRepositoryInterface
-	PermissionRepo           repository.PermissionRepositoryInterface
-	TokenRepo                repository.TokenRepositoryInterface
+	DB                         *repository.MongoDB
+	PackSizesRepo              repository.PackSizesRepositoryInterface
+	LoggingService             service.LoggingService
+	LogBuffer                  *service.BufferedLoggingService
+	PackSizesCircuitBreaker    *circuitbreaker.CircuitBreaker
+	LogsCircuitBreaker         *circuitbreaker.CircuitBreaker
+	UserRepo                   repository.UserRepositoryInterface
+	RoleRepo                   repository.RoleRepositoryInterface
+	PermissionRepo             repository.PermissionRepositoryInterface
+	TokenRepo                  repository.TokenRepositoryInterface
+	PresetRepo                 repository.PresetRepositoryInterface
+	ClientUsageRepo            repository.ClientUsageRepositoryInterface
+	CalculationRepo            repository.CalculationRepositoryInterface
+	CalculationsCircuitBreaker *circuitbreaker.CircuitBreaker
+	LoginAttemptRepo           repository.LoginAttemptRepositoryInterface
+	CalculationJobRepo         repository.CalculationJobRepositoryInterface
+	MessageOverrideRepo        repository.MessageOverrideRepositoryInterface
+	SigningKeyRepo             repository.SigningKeyRepositoryInterface
+	IPRuleRepo                 repository.IPRuleRepositoryInterface
+	AuthCircuitBreaker         *circuitbreaker.CircuitBreaker
 }
 
 // InitializeDatabase initializes MongoDB connection and creates required repositories and services.
@@ -39,8 +52,8 @@ func InitializeDatabase(cfg config.DatabaseConfig, defaultPackSizes []int) *Data
 
 	log.Info().Msg("Connected to MongoDB")
 
-	// Set TTL for logs
-	ttlDays := int(cfg.LogsTTL.Hours() / 24)
+	// Set TTL for logs, past which no level keeps them
+	ttlDays := int(logRetention(cfg).Longest().Hours() / 24)
 	if err := db.SetLogsTTL(context.Background(), ttlDays); err != nil {
 		log.Warn().Err(err).Msg("Failed to set logs TTL index (may already exist)")
 	}
@@ -60,19 +73,44 @@ func InitializeDatabase(cfg config.DatabaseConfig, defaultPackSizes []int) *Data
 		Name:            "mongodb-logs",
 	})
 
+	calculationsCB := circuitbreaker.New(circuitbreaker.Config{
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		SuccessThreshold: cfg.CircuitBreakerSuccessThreshold,
+		Timeout:          cfg.CircuitBreakerTimeout,
+		Name:             "mongodb-calculations",
+	})
+
+	// Shared by the auth repositories, so logins and token checks fail fast
+	// together while MongoDB is down
+	authCB := circuitbreaker.New(circuitbreaker.Config{
+		FailureThreshold: cfg.AuthCircuitBreakerFailureThreshold,
+		SuccessThreshold: cfg.AuthCircuitBreakerSuccessThreshold,
+		Timeout:          cfg.AuthCircuitBreakerTimeout,
+		Name:             "mongodb-auth",
+	})
+
 	// Initialize repositories
 	logsRepo := repository.NewLogsRepository(db)
 	logsRepoWithCB := repository.NewLogsRepositoryWithCircuitBreaker(logsRepo, logsCB)
 	loggingService := service.NewLoggingService(logsRepoWithCB)
+	logBuffer := newLogBuffer(cfg, loggingService)
+	if logBuffer != nil {
+		loggingService = logBuffer
+	}
 
 	packSizesRepo := repository.NewPackSizesRepository(db)
 	packSizesRepoWithCB := repository.NewPackSizesRepositoryWithCircuitBreaker(packSizesRepo, packSizesCB)
 
 	// Initialize auth repositories
-	userRepo := repository.NewUserRepository(db.Database)
-	roleRepo := repository.NewRoleRepository(db.Database)
-	permissionRepo := repository.NewPermissionRepository(db.Database)
-	tokenRepo := repository.NewTokenRepository(db.Database)
+	hedgedReads := repository.WithHedgedReads(cfg.HedgedReadDelay)
+	userRepo := repository.NewUserRepositoryWithCircuitBreaker(repository.NewUserRepository(db.Database, hedgedReads), authCB)
+	roleRepo := repository.NewRoleRepositoryWithCircuitBreaker(repository.NewRoleRepository(db.Database), authCB)
+	permissionRepo := repository.NewPermissionRepositoryWithCircuitBreaker(repository.NewPermissionRepository(db.Database), authCB)
+	tokenRepo := repository.NewTokenRepositoryWithCircuitBreaker(repository.NewTokenRepository(db.Database, hedgedReads), authCB)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db.Database)
+	presetRepo := repository.NewPresetRepository(db.Database)
+	clientUsageRepo := repository.NewClientUsageRepository(db.Database)
+	calculationRepo := repository.NewCalculationRepositoryWithCircuitBreaker(repository.NewCalculationRepository(db.Database), calculationsCB)
 
 	// Initialize default pack sizes if none exist
 	if err := initializeDefaultPackSizes(packSizesRepoWithCB, defaultPackSizes); err != nil {
@@ -85,14 +123,26 @@ func InitializeDatabase(cfg config.DatabaseConfig, defaultPackSizes []int) *Data
 	}
 
 	return &DatabaseComponents{
-		PackSizesRepo:          packSizesRepoWithCB,
-		LoggingService:         loggingService,
-		PackSizesCircuitBreaker: packSizesCB,
-		LogsCircuitBreaker:     logsCB,
-		UserRepo:               userRepo,
-		RoleRepo:               roleRepo,
-		PermissionRepo:         permissionRepo,
-		TokenRepo:              tokenRepo,
+		DB:                         db,
+		PackSizesRepo:              packSizesRepoWithCB,
+		LoggingService:             loggingService,
+		LogBuffer:                  logBuffer,
+		PackSizesCircuitBreaker:    packSizesCB,
+		LogsCircuitBreaker:         logsCB,
+		UserRepo:                   userRepo,
+		RoleRepo:                   roleRepo,
+		PermissionRepo:             permissionRepo,
+		TokenRepo:                  tokenRepo,
+		PresetRepo:                 presetRepo,
+		ClientUsageRepo:            clientUsageRepo,
+		CalculationRepo:            calculationRepo,
+		CalculationsCircuitBreaker: calculationsCB,
+		LoginAttemptRepo:           loginAttemptRepo,
+		CalculationJobRepo:         repository.NewCalculationJobRepository(db.Database),
+		MessageOverrideRepo:        repository.NewMessageOverrideRepository(db.Database),
+		SigningKeyRepo:             repository.NewSigningKeyRepository(db.Database),
+		IPRuleRepo:                 repository.NewIPRuleRepository(db.Database),
+		AuthCircuitBreaker:         authCB,
 	}
 }
 
@@ -111,7 +161,7 @@ func initializeDefaultPackSizes(repo repository.PackSizesRepositoryInterface, de
 		if len(defaultSizes) == 0 {
 			defaultSizes = service.DefaultPackSizes
 		}
-		_, err := repo.Create(ctx, defaultSizes, "system")
+		_, err := repo.Create(ctx, defaultSizes, nil, "system")
 		if err != nil {
 			return err
 		}
@@ -120,3 +170,27 @@ func initializeDefaultPackSizes(repo repository.PackSizesRepositoryInterface, de
 
 	return nil
 }
+
+// RunMigrations connects to MongoDB, which applies the pending migrations,
+// and reports the resulting schema version. It backs the --migrate-only flag,
+// letting a deployment migrate the database before starting new replicas.
+func RunMigrations(cfg config.DatabaseConfig) error {
+	if !cfg.Enabled {
+		return errors.New("database is disabled (MONGODB_ENABLED=false)")
+	}
+
+	db, err := repository.NewMongoDB(cfg.URI, cfg.DatabaseName)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer func() { _ = db.Close(ctx) }()
+
+	version, err := db.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info().Int("version", version).Msg("Database migrations are up to date")
+	return nil
+}
@@ -30,7 +30,7 @@ func TestInitializeDefaultPackSizes(t *testing.T) {
 					Sizes:  []int{5000, 2000, 1000},
 					Active: true,
 				}
-				m.On("Create", mock.Anything, []int{5000, 2000, 1000}, "system").Return(config, nil).Once()
+				m.On("Create", mock.Anything, []int{5000, 2000, 1000}, mock.Anything, "system").Return(config, nil).Once()
 			},
 			wantError: false,
 		},
@@ -57,7 +57,7 @@ func TestInitializeDefaultPackSizes(t *testing.T) {
 					Sizes:  []int{5000, 2000, 1000, 500, 250},
 					Active: true,
 				}
-				m.On("Create", mock.Anything, mock.Anything, "system").Return(config, nil).Once()
+				m.On("Create", mock.Anything, mock.Anything, mock.Anything, "system").Return(config, nil).Once()
 			},
 			wantError: false,
 		},
@@ -74,7 +74,7 @@ func TestInitializeDefaultPackSizes(t *testing.T) {
 			defaultSizes: []int{5000, 2000, 1000},
 			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
 				m.On("GetActive", mock.Anything).Return(nil, nil).Once()
-				m.On("Create", mock.Anything, mock.Anything, "system").Return(nil, errors.New("database error")).Once()
+				m.On("Create", mock.Anything, mock.Anything, mock.Anything, "system").Return(nil, errors.New("database error")).Once()
 			},
 			wantError: true,
 		},
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// InitializeDeterministicMode seeds generated IDs and token nonces and
+// freezes the clock when cfg.Deterministic is enabled, so contract and
+// integration tests get the same responses on every run. It reports whether
+// the mode is on.
+//
+// Frozen tokens never expire and anyone knowing the seed can predict IDs, so
+// the mode is only turned on in a development or test environment.
+func InitializeDeterministicMode(cfg config.Config) bool {
+	if !cfg.Deterministic.Enabled {
+		return false
+	}
+	if !cfg.IsDevelopment() {
+		log.Warn().Str("environment", cfg.Environment).Msg("Ignoring DETERMINISTIC_MODE outside development and test environments")
+		return false
+	}
+
+	clock := cfg.Deterministic.Clock
+	if clock.IsZero() {
+		clock = timeutil.DefaultFrozenTime
+	}
+	timeutil.Freeze(clock)
+	idgen.Seed(uint64(cfg.Deterministic.Seed))
+
+	log.Warn().
+		Int("seed", cfg.Deterministic.Seed).
+		Time("clock", clock).
+		Msg("Deterministic mode enabled: IDs are predictable and the clock is frozen")
+	return true
+}
@@ -0,0 +1,43 @@
+//go:build !integration
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+func TestInitializeDeterministicMode(t *testing.T) {
+	defer idgen.Reset()
+	defer timeutil.Freeze(time.Time{})
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.False(t, InitializeDeterministicMode(config.Config{Environment: "test"}))
+		assert.WithinDuration(t, time.Now(), timeutil.Now(), time.Minute)
+	})
+
+	t.Run("ignored in production", func(t *testing.T) {
+		cfg := config.Config{Environment: "production", Deterministic: config.DeterministicConfig{Enabled: true}}
+		assert.False(t, InitializeDeterministicMode(cfg))
+		assert.WithinDuration(t, time.Now(), timeutil.Now(), time.Minute)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		cfg := config.Config{Environment: "test", Deterministic: config.DeterministicConfig{Enabled: true, Seed: 3}}
+		assert.True(t, InitializeDeterministicMode(cfg))
+		assert.Equal(t, timeutil.DefaultFrozenTime, timeutil.Now())
+		first := []string{idgen.NewObjectID().Hex(), idgen.NewUUID()}
+
+		cfg.Deterministic.Clock = time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)
+		assert.True(t, InitializeDeterministicMode(cfg))
+		assert.Equal(t, cfg.Deterministic.Clock, timeutil.Now())
+		second := []string{idgen.NewObjectID().Hex(), idgen.NewUUID()}
+		assert.Equal(t, first[1], second[1], "the sequence restarts")
+	})
+}
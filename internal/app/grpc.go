@@ -0,0 +1,35 @@
+// Package app provides gRPC server initialization.
+package app
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/guttosm/pack-service/config"
+	packgrpc "github.com/guttosm/pack-service/internal/grpc"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// InitializeGRPC creates the gRPC server when GRPC_ENABLED is set. It shares
+// the calculator, services and authentication settings of the HTTP router.
+// Returns nil when gRPC is disabled.
+func InitializeGRPC(cfg config.Config, calculator service.PackCalculator, routerComponents *RouterComponents) *grpc.Server {
+	if !cfg.Server.GRPCEnabled {
+		return nil
+	}
+
+	routerCfg := routerComponents.Config
+	return packgrpc.NewServer(packgrpc.Config{
+		Calculator:         calculator,
+		PackSizesService:   routerCfg.PackSizesService,
+		LoggingService:     routerCfg.LoggingService,
+		EnableAuth:         routerCfg.EnableAuth,
+		APIKeys:            routerCfg.APIKeys,
+		APIKeySet:          routerCfg.APIKeySet,
+		AuthService:        routerCfg.AuthService,
+		RoleService:        routerCfg.RoleService,
+		PermissionService:  routerCfg.PermissionService,
+		RequireDPoP:        routerCfg.RequireDPoP,
+		CalculationHistory: routerCfg.CalculationHistory,
+		PackSizeRules:      routerCfg.PackSizeRules,
+	})
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+// InitializeMessageCatalogs registers the catalogs in cfg.CatalogDir with
+// the translator, adding locales or replacing shipped messages. A catalog
+// that cannot be loaded is logged, leaving the shipped messages in use.
+func InitializeMessageCatalogs(cfg config.I18nConfig) {
+	if cfg.CatalogDir == "" {
+		return
+	}
+	translator := i18n.GetTranslator()
+	if err := translator.LoadCatalogs(os.DirFS(cfg.CatalogDir)); err != nil {
+		log.Error().Err(err).Str("dir", cfg.CatalogDir).Msg("Failed to load message catalogs")
+		return
+	}
+	log.Info().Str("dir", cfg.CatalogDir).Strs("locales", translator.Locales()).Msg("Message catalogs loaded")
+}
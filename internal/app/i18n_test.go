@@ -0,0 +1,27 @@
+//go:build !integration
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+func TestInitializeMessageCatalogs(t *testing.T) {
+	InitializeMessageCatalogs(config.I18nConfig{})
+	assert.NotContains(t, i18n.GetTranslator().Locales(), "eo")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eo.json"), []byte(`{"error.not_found": "Ne trovita"}`), 0o600))
+
+	InitializeMessageCatalogs(config.I18nConfig{CatalogDir: dir})
+	assert.Contains(t, i18n.GetTranslator().Locales(), "eo")
+	assert.Equal(t, "Ne trovita", i18n.GetTranslator().Translate(i18n.ErrKeyNotFound, "eo"))
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// InitializeIPRules creates the IP allow and deny rules from the
+// configuration and the database, and keeps reloading the stored ones every
+// cfg.RefreshInterval. The returned hook stops reloading at shutdown; both
+// are nil when no rule is configured and there is no database to store any.
+//
+// Configured ranges that do not parse are dropped. If no allow range is left
+// of those configured, only loopback clients are allowed, so a typo does not
+// open the service to everyone.
+func InitializeIPRules(cfg config.IPFilterConfig, dbComponents *DatabaseComponents) (*service.IPRuleService, func(context.Context)) {
+	var repo repository.IPRuleRepositoryInterface
+	if dbComponents != nil {
+		repo = dbComponents.IPRuleRepo
+	}
+	if repo == nil && len(cfg.AllowCIDRs) == 0 && len(cfg.DenyCIDRs) == 0 {
+		return nil, nil
+	}
+
+	allow := validIPRuleCIDRs(cfg.AllowCIDRs, "IP_ALLOW_CIDRS")
+	deny := validIPRuleCIDRs(cfg.DenyCIDRs, "IP_DENY_CIDRS")
+	if len(cfg.AllowCIDRs) > 0 && len(allow) == 0 {
+		log.Error().Msg("No valid IP allow range configured, allowing loopback clients only")
+		allow = []string{"127.0.0.0/8", "::1/128"}
+	}
+	rules, err := service.NewIPRuleService(repo, allow, deny)
+	if err != nil {
+		log.Error().Err(err).Msg("IP rules disabled")
+		return nil, nil
+	}
+	if len(allow) > 0 || len(deny) > 0 {
+		log.Info().Strs("allow_cidrs", allow).Strs("deny_cidrs", deny).Msg("IP rules configured")
+	}
+	if repo == nil {
+		return rules, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rules.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to load IP rules")
+	}
+
+	if cfg.RefreshInterval <= 0 {
+		return rules, nil
+	}
+	rules.Start(cfg.RefreshInterval)
+	return rules, func(context.Context) { rules.Stop() }
+}
+
+// validIPRuleCIDRs returns the cidrs that parse, logging the others as
+// entries of the env variable.
+func validIPRuleCIDRs(cidrs []string, env string) []string {
+	var valid []string
+	for _, cidr := range cidrs {
+		if _, err := service.NewIPRuleService(nil, []string{cidr}, nil); err != nil {
+			log.Error().Err(err).Msg("Ignoring invalid " + env + " entry")
+			continue
+		}
+		valid = append(valid, cidr)
+	}
+	return valid
+}
@@ -0,0 +1,62 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+)
+
+func TestInitializeIPRules(t *testing.T) {
+	t.Run("disabled without rules or database", func(t *testing.T) {
+		rules, stop := InitializeIPRules(config.IPFilterConfig{RefreshInterval: time.Minute}, nil)
+		assert.Nil(t, rules)
+		assert.Nil(t, stop)
+	})
+
+	t.Run("configured rules only", func(t *testing.T) {
+		rules, stop := InitializeIPRules(config.IPFilterConfig{DenyCIDRs: []string{"203.0.113.0/24", "not-a-range"}}, nil)
+		require.NotNil(t, rules)
+		assert.Nil(t, stop)
+
+		allowed, _ := rules.Check("203.0.113.7")
+		assert.False(t, allowed)
+		allowed, _ = rules.Check("198.51.100.7")
+		assert.True(t, allowed, "invalid entries are dropped")
+	})
+
+	t.Run("invalid allow ranges allow loopback only", func(t *testing.T) {
+		rules, _ := InitializeIPRules(config.IPFilterConfig{AllowCIDRs: []string{"10.0.0.0/33"}}, nil)
+		require.NotNil(t, rules)
+
+		allowed, _ := rules.Check("127.0.0.1")
+		assert.True(t, allowed)
+		allowed, _ = rules.Check("198.51.100.7")
+		assert.False(t, allowed)
+	})
+
+	t.Run("stored rules are loaded at startup", func(t *testing.T) {
+		repo := mocks.NewMockIPRuleRepositoryInterface(t)
+		repo.EXPECT().List(mock.Anything).Return([]*model.IPRule{
+			{ID: primitive.NewObjectID(), CIDR: "192.0.2.0/24", Action: model.IPRuleDeny},
+		}, nil).Once()
+
+		rules, stop := InitializeIPRules(config.IPFilterConfig{RefreshInterval: time.Hour}, &DatabaseComponents{IPRuleRepo: repo})
+		require.NotNil(t, rules)
+		require.NotNil(t, stop)
+		stop(context.Background())
+
+		allowed, _ := rules.Check("192.0.2.1")
+		assert.False(t, allowed)
+	})
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// newLogBuffer returns a logging service queuing the entries logged through
+// inner and writing them in batches, or nil when cfg disables the buffer.
+func newLogBuffer(cfg config.DatabaseConfig, inner service.LoggingService) *service.BufferedLoggingService {
+	if cfg.LogsBufferSize <= 0 {
+		return nil
+	}
+
+	policy, err := service.ParseLogOverflowPolicy(cfg.LogsOverflowPolicy)
+	if err != nil {
+		log.Warn().Err(err).Msg("Using the drop_newest log overflow policy")
+		policy = service.LogOverflowDropNewest
+	}
+
+	return service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{
+		BufferSize:     cfg.LogsBufferSize,
+		BatchSize:      cfg.LogsBatchSize,
+		FlushInterval:  cfg.LogsFlushInterval,
+		OverflowPolicy: policy,
+	})
+}
+
+// LogBufferShutdown returns the hook writing the queued log entries at
+// shutdown; it is nil when logs are not buffered.
+func LogBufferShutdown(dbComponents *DatabaseComponents) func(context.Context) {
+	if dbComponents == nil || dbComponents.LogBuffer == nil {
+		return nil
+	}
+
+	buffer := dbComponents.LogBuffer
+	return func(ctx context.Context) {
+		if err := buffer.Close(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to write buffered logs before shutdown")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+)
+
+func TestNewLogBuffer(t *testing.T) {
+	loggingService := mocks.NewMockLoggingService(t)
+	assert.Nil(t, newLogBuffer(config.DatabaseConfig{}, loggingService), "a zero buffer size writes directly")
+
+	buffer := newLogBuffer(config.DatabaseConfig{LogsBufferSize: 10, LogsOverflowPolicy: "unknown"}, loggingService)
+	require.NotNil(t, buffer, "an unknown policy falls back to the default")
+	loggingService.EXPECT().CreateLogs(mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(t, buffer.CreateLog(context.Background(), &model.LogEntry{Message: "request"}))
+
+	stop := LogBufferShutdown(&DatabaseComponents{LogBuffer: buffer})
+	require.NotNil(t, stop)
+	stop(context.Background())
+}
+
+func TestLogBufferShutdown(t *testing.T) {
+	assert.Nil(t, LogBufferShutdown(nil))
+	assert.Nil(t, LogBufferShutdown(&DatabaseComponents{}))
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// logRetention returns the log retention cfg configures.
+func logRetention(cfg config.DatabaseConfig) model.LogRetention {
+	return model.LogRetention{
+		MaxAge:      cfg.LogsTTL,
+		LevelMaxAge: cfg.LogsRetentionByLevel,
+		MaxEntries:  int64(cfg.LogsMaxEntries),
+	}
+}
+
+// InitializeLogRetention starts the purge applying the per-level log
+// retention and the cap on stored entries. Entries older than every
+// retention are also deleted by the logs TTL index, so the purge is only
+// started when one of those is configured. The returned hook stops it at
+// shutdown; it is nil when the purge is not started.
+func InitializeLogRetention(cfg config.DatabaseConfig, dbComponents *DatabaseComponents) func(context.Context) {
+	if dbComponents == nil || dbComponents.LoggingService == nil || cfg.LogsPurgeInterval <= 0 {
+		return nil
+	}
+	if len(cfg.LogsRetentionByLevel) == 0 && cfg.LogsMaxEntries <= 0 {
+		return nil
+	}
+
+	job := service.NewLogRetentionJob(dbComponents.LoggingService, logRetention(cfg), cfg.LogsPurgeInterval)
+	job.Start()
+
+	log.Info().
+		Dur("interval", cfg.LogsPurgeInterval).
+		Int("max_entries", cfg.LogsMaxEntries).
+		Int("level_retentions", len(cfg.LogsRetentionByLevel)).
+		Msg("Log retention purge enabled")
+
+	return func(context.Context) { job.Stop() }
+}
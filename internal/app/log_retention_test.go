@@ -0,0 +1,43 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+)
+
+func TestInitializeLogRetention(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		LogsTTL:              30 * 24 * time.Hour,
+		LogsRetentionByLevel: map[string]time.Duration{"error": 90 * 24 * time.Hour},
+		LogsPurgeInterval:    time.Hour,
+	}
+	assert.Nil(t, InitializeLogRetention(cfg, nil))
+
+	loggingService := mocks.NewMockLoggingService(t)
+	components := &DatabaseComponents{LoggingService: loggingService}
+	assert.Nil(t, InitializeLogRetention(config.DatabaseConfig{LogsTTL: time.Hour, LogsPurgeInterval: time.Hour}, components),
+		"the TTL index alone applies a single retention")
+
+	ran := make(chan struct{})
+	loggingService.EXPECT().PurgeLogs(mock.Anything, model.LogRetention{
+		MaxAge:      cfg.LogsTTL,
+		LevelMaxAge: cfg.LogsRetentionByLevel,
+	}).RunAndReturn(func(context.Context, model.LogRetention) (int64, error) {
+		close(ran)
+		return 0, nil
+	}).Once()
+	stop := InitializeLogRetention(cfg, components)
+	assert.NotNil(t, stop)
+	<-ran
+	stop(context.Background())
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// InitializeMessageOverrides loads the messages tenants replaced into the
+// translator and keeps reloading them every cfg.OverrideRefreshInterval.
+// The returned hook stops reloading at shutdown; both are nil without a
+// database.
+func InitializeMessageOverrides(cfg config.I18nConfig, dbComponents *DatabaseComponents) (service.MessageOverrideService, func(context.Context)) {
+	if dbComponents == nil || dbComponents.MessageOverrideRepo == nil {
+		return nil, nil
+	}
+
+	overrides := service.NewMessageOverrideService(dbComponents.MessageOverrideRepo, i18n.GetTranslator())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := overrides.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to load message overrides")
+	}
+
+	if cfg.OverrideRefreshInterval <= 0 {
+		return overrides, nil
+	}
+	overrides.Start(cfg.OverrideRefreshInterval)
+	return overrides, func(context.Context) { overrides.Stop() }
+}
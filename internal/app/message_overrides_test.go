@@ -0,0 +1,36 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/mocks"
+)
+
+func TestInitializeMessageOverrides(t *testing.T) {
+	overrides, stop := InitializeMessageOverrides(config.I18nConfig{OverrideRefreshInterval: time.Minute}, nil)
+	assert.Nil(t, overrides)
+	assert.Nil(t, stop)
+
+	defer i18n.GetTranslator().SetOverrides(nil)
+	repo := mocks.NewMockMessageOverrideRepositoryInterface(t)
+	repo.EXPECT().List(mock.Anything).Return([]*model.MessageOverride{
+		{Tenant: "acme", Locale: "en", Key: i18n.ErrKeyForbidden, Message: "Acme says no"},
+	}, nil).Once()
+
+	overrides, stop = InitializeMessageOverrides(config.I18nConfig{OverrideRefreshInterval: time.Hour}, &DatabaseComponents{MessageOverrideRepo: repo})
+	require.NotNil(t, overrides)
+	require.NotNil(t, stop)
+	stop(context.Background())
+	assert.Equal(t, "Acme says no", i18n.GetTranslator().TranslateFor("acme", i18n.ErrKeyForbidden, "en"), "overrides are loaded at startup")
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// InitializeMetrics applies the label cardinality limits. It must run before
+// the first request is served.
+func InitializeMetrics(cfg config.MetricsConfig) {
+	mode := strings.ToLower(cfg.ClientLabels)
+	switch mode {
+	case metrics.ClientLabelsRaw, metrics.ClientLabelsHash, metrics.ClientLabelsBucket:
+	default:
+		log.Warn().Str("mode", cfg.ClientLabels).Msg("Unknown METRICS_CLIENT_LABELS, using raw")
+		mode = metrics.ClientLabelsRaw
+	}
+
+	metrics.ConfigureCardinality(metrics.CardinalityConfig{
+		ClientLabels:       mode,
+		ClientLabelBuckets: cfg.ClientLabelBuckets,
+		MaxLabelValues:     cfg.MaxLabelValues,
+		MaxPackSets:        cfg.MaxPackSets,
+	})
+}
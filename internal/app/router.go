@@ -2,10 +2,31 @@
 package app
 
 import (
+	"context"
+	"encoding/base64"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+
 	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/docs"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/deprecation"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/edgecache"
+	"github.com/guttosm/pack-service/internal/hooks"
 	"github.com/guttosm/pack-service/internal/http"
+	"github.com/guttosm/pack-service/internal/jwks"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/presentation"
 	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"github.com/guttosm/pack-service/internal/workerpool"
 )
 
 // RouterComponents holds router-related components.
@@ -13,6 +34,9 @@ type RouterComponents struct {
 	Handler       *http.Handler
 	HealthHandler *http.HealthHandler
 	Config        http.RouterConfig
+	// StopTokenVersions stops watching the token version changes; it is nil
+	// when nothing watches them.
+	StopTokenVersions context.CancelFunc
 }
 
 // InitializeRouter initializes HTTP handlers and router configuration.
@@ -25,37 +49,48 @@ func InitializeRouter(
 	var loggingService service.LoggingService
 	if dbComponents != nil {
 		packSizesRepo = dbComponents.PackSizesRepo
-		loggingService = dbComponents.LoggingService
+		// Sensitive fields are masked before any writer stores them
+		loggingService = service.NewRedactingLoggingService(dbComponents.LoggingService, service.NewLogRedactor(cfg.Log.RedactFields))
 	}
 
-	// Initialize pack sizes service
+	// Initialize pack sizes service; activating a configuration drops the
+	// results calculated with the previous one
 	var packSizesService service.PackSizesService
 	if packSizesRepo != nil {
-		packSizesService = service.NewPackSizesService(packSizesRepo)
+		packSizesService = service.NewPackSizesService(packSizesRepo, service.WithCalculatorInvalidation(calculator))
 	}
 
 	handler := http.NewHandler(calculator, packSizesService)
 	healthHandler := http.NewHealthHandler()
 
-	// Register circuit breakers for health monitoring
-	if dbComponents != nil {
-		if dbComponents.PackSizesCircuitBreaker != nil {
-			healthHandler.RegisterCircuitBreaker("mongodb_pack_sizes", dbComponents.PackSizesCircuitBreaker)
-		}
-		if dbComponents.LogsCircuitBreaker != nil {
-			healthHandler.RegisterCircuitBreaker("mongodb_logs", dbComponents.LogsCircuitBreaker)
-		}
-	}
+	registerHealthChecks(healthHandler, calculator, dbComponents, cfg.Cache)
+	drainer := middleware.NewDrainer()
+	healthHandler.SetDrainer(drainer)
 
 	// Initialize authentication service
 	var authService service.AuthService
+	var signingKeys *service.SigningKeyRing
+	var stopTokenVersions context.CancelFunc
 	if dbComponents != nil && dbComponents.UserRepo != nil {
+		signingKeys = newSigningKeyRing(cfg.Auth, dbComponents.SigningKeyRepo)
+		var tokenVersions *service.TokenVersionCache
+		tokenVersions, stopTokenVersions = newTokenVersionCache(cfg.Auth, dbComponents.UserRepo)
 		authService = service.NewAuthService(
 			dbComponents.UserRepo,
 			dbComponents.RoleRepo,
 			dbComponents.TokenRepo,
 			cfg.Auth,
+			service.WithTokenVersions(tokenVersions),
+			serviceTokens(cfg.Auth),
+			service.WithSigningKeys(signingKeys),
 		)
+		if dbComponents.LoginAttemptRepo != nil {
+			authService = service.NewLockoutAuthService(authService, service.NewLoginLockout(dbComponents.LoginAttemptRepo, service.LockoutConfig{
+				Threshold:   cfg.Auth.LockoutThreshold,
+				IPThreshold: cfg.Auth.LockoutIPThreshold,
+				Duration:    cfg.Auth.LockoutDuration,
+			}))
+		}
 	}
 
 	// Initialize permission service
@@ -67,28 +102,444 @@ func InitializeRouter(
 	// Initialize role service
 	var roleService service.RoleService
 	if dbComponents != nil && dbComponents.RoleRepo != nil {
-		roleService = service.NewRoleService(dbComponents.RoleRepo)
+		var roleOpts []service.RoleServiceOption
+		if cfg.Auth.DegradedMode {
+			roleOpts = append(roleOpts, service.WithLastKnownRoles())
+		}
+		roleService = service.NewRoleService(dbComponents.RoleRepo, roleOpts...)
+	}
+
+	// Initialize user administration
+	var userService service.UserService
+	if authService != nil {
+		userService = service.NewUserService(dbComponents.UserRepo, dbComponents.RoleRepo, authService,
+			service.WithStaleAccountDays(cfg.Auth.StaleAccountDays),
+			service.WithPermissionRepository(dbComponents.PermissionRepo),
+			service.WithTokenRepository(dbComponents.TokenRepo),
+			service.WithDeletedUserRetention(cfg.Auth.DeletedUserRetention))
 	}
 
+	// Initialize preset service
+	var presetService service.PresetService
+	if dbComponents != nil && dbComponents.PresetRepo != nil {
+		presetService = service.NewPresetService(dbComponents.PresetRepo)
+	}
+
+	// Initialize client usage stats
+	var clientUsageService service.ClientUsageService
+	if dbComponents != nil && dbComponents.ClientUsageRepo != nil {
+		clientUsageService = service.NewClientUsageService(dbComponents.ClientUsageRepo, cfg.Database.ClientUsageFlushInterval)
+	}
+
+	// Initialize calculation history
+	var calculationHistory service.CalculationHistoryService
+	if dbComponents != nil && dbComponents.CalculationRepo != nil {
+		calculationHistory = service.NewCalculationHistoryService(dbComponents.CalculationRepo, cfg.Database.CalculationHistoryFlushInterval)
+	}
+
+	// Initialize asynchronous calculation jobs
+	var calculationJobs service.CalculationJobService
+	if dbComponents != nil && dbComponents.CalculationJobRepo != nil {
+		calculationJobs = service.NewCalculationJobService(dbComponents.CalculationJobRepo, service.CalculationJobConfig{
+			Workers:      cfg.Jobs.Workers,
+			Retention:    cfg.Jobs.Retention,
+			Timeout:      cfg.Jobs.Timeout,
+			MaxQueued:    cfg.Jobs.MaxUnfinished,
+			PollInterval: cfg.Jobs.PollInterval,
+		})
+	}
+
+	// Initialize staged pack size migrations
+	var packSizesMigrator *service.PackSizesMigrator
+	if packSizesService != nil {
+		packSizesMigrator = service.NewPackSizesMigrator(packSizesService, calculator, calculationHistory)
+	}
+
+	calculationHooks := hooks.Default()
+	if names := calculationHooks.Names(); len(names) > 0 {
+		log.Info().Strs("hooks", names).Msg("Calculation hooks registered")
+	}
+
+	// Development environments serve the docs without auth. Elsewhere the
+	// admin spec is only served behind basic auth.
+	swaggerUser, swaggerPass := cfg.Server.SwaggerUser, cfg.Server.SwaggerPass
+	if cfg.IsDevelopment() {
+		swaggerUser, swaggerPass = "", ""
+	}
+
+	// The geofence bypass shares the verifier so DPoP proofs are checked once
+	dpopVerifier := dpop.NewVerifier(dpop.Config{MaxAge: cfg.Auth.DPoPProofMaxAge})
+
 	routerCfg := http.RouterConfig{
-		RateLimit:         cfg.Server.RateLimit,
-		RateWindow:        cfg.Server.RateWindow,
-		EnableAuth:        cfg.Auth.Enabled,
-		APIKeys:           cfg.Auth.APIKeys,
-		EnableIdempotency: true,
-		CORSOrigins:       cfg.Server.CORSOrigins,
-		SwaggerUser:       cfg.Server.SwaggerUser,
-		SwaggerPass:       cfg.Server.SwaggerPass,
-		LoggingService:    loggingService,
-		PackSizesService:  packSizesService,
-		AuthService:       authService,
-		RoleService:       roleService,
-		PermissionService: permissionService,
+		RateLimit:           cfg.Server.RateLimit,
+		RateWindow:          cfg.Server.RateWindow,
+		EnableAuth:          cfg.Auth.Enabled,
+		APIKeys:             cfg.Auth.APIKeys,
+		APIKeySet:           middleware.NewAPIKeySet(cfg.Auth.APIKeys),
+		RateLimiters:        middleware.NewRateLimiterSet(),
+		EnableIdempotency:   true,
+		CORSOrigins:         cfg.Server.CORSOrigins,
+		TrustedProxies:      trustedProxies(cfg.Server.TrustedProxies),
+		SwaggerEnabled:      cfg.Server.SwaggerEnabled,
+		SwaggerUser:         swaggerUser,
+		SwaggerPass:         swaggerPass,
+		SwaggerAdminSpec:    cfg.IsDevelopment() || swaggerUser != "" && swaggerPass != "",
+		LoggingService:      loggingService,
+		RequestLogging:      middleware.RequestLoggerConfig{SuccessSampleRate: cfg.Log.SuccessSampleRate},
+		PackSizesService:    packSizesService,
+		AuthService:         authService,
+		RoleService:         roleService,
+		PermissionService:   permissionService,
+		PresetService:       presetService,
+		UserService:         userService,
+		DPoPVerifier:        dpopVerifier,
+		RequireDPoP:         cfg.Auth.DPoPRequired,
+		SupportBundle:       NewSupportBundleGenerator(cfg, calculator, dbComponents),
+		Deprecations:        deprecation.NewTracker(),
+		ClientUsage:         clientUsageService,
+		MetricsGatherer:     prometheus.DefaultGatherer,
+		CalculationHistory:  calculationHistory,
+		DisplayLocation:     displayLocation(cfg.Server.DisplayTimezone),
+		Cursors:             pageCursors(cfg.Server),
+		MaxCompute:          cfg.Server.MaxCompute,
+		MaxBodyBytes:        int64(cfg.Server.MaxBodyBytes),
+		ProblemDetails:      cfg.Server.ProblemDetails,
+		DecimalPrecision:    cfg.Server.DecimalPrecision,
+		StreamJobs:          service.NewStreamJobRunner(cfg.Batch.StreamJobTTL, cfg.Batch.MaxStreamJobs),
+		CalculationJobs:     calculationJobs,
+		SigningKeys:         signingKeys,
+		RequiredRouteGroups: cfg.Auth.RequiredRouteGroups,
+		Hooks:               calculationHooks,
+		Presentation:        presentationRules(cfg.Presentation),
+		PackSizesMigrator:   packSizesMigrator,
+		ActivePackSizes:     activePackSizes(packSizesService, packSizesRepo, cfg.Database),
+		CircuitBreakers:     circuitBreakerRegistry(dbComponents),
+		Drainer:             drainer,
+		GeoFence:            geoFence(cfg.GeoFence, authService, roleService, permissionService, middleware.WithDPoP(dpopVerifier, cfg.Auth.DPoPRequired)),
+		CountryHeader:       cfg.GeoFence.CountryHeader,
+		EdgeCache:           edgeCachePolicy(cfg.EdgeCache),
+		ResponseCache:       responseCache(cfg.Cache),
+		RequestValidator:    requestValidator(cfg.Server.RequestValidation),
+		PackSizeRules: service.PackSizeRules{
+			MaxSizes:             cfg.PackSizeRules.MaxSizes,
+			MinSize:              cfg.PackSizeRules.MinSize,
+			MaxSize:              cfg.PackSizeRules.MaxSize,
+			CommonDivisorWarning: cfg.PackSizeRules.CommonDivisorWarning,
+		},
+		BatchPool: workerpool.New(workerpool.Config{
+			Name:         "batch_calculate",
+			Workers:      cfg.Batch.Workers,
+			MaxPerCaller: cfg.Batch.MaxConcurrencyPerRequest,
+			QueueTimeout: cfg.Batch.QueueTimeout,
+		}),
+		HeavyRequests: heavyRequestPool(cfg.Heavy),
 	}
 
 	return &RouterComponents{
-		Handler:       handler,
-		HealthHandler: healthHandler,
-		Config:        routerCfg,
+		Handler:           handler,
+		HealthHandler:     healthHandler,
+		Config:            routerCfg,
+		StopTokenVersions: stopTokenVersions,
+	}
+}
+
+// registerHealthChecks registers the readiness checks. MongoDB and the pack
+// sizes it serves are critical; the logs and calculation history databases,
+// the auth repositories and the Redis cache only degrade the service, since
+// some requests succeed without them.
+func registerHealthChecks(health *http.HealthHandler, calculator service.PackCalculator, dbComponents *DatabaseComponents, cacheCfg config.CacheConfig) {
+	if dbComponents != nil {
+		if dbComponents.DB != nil {
+			health.RegisterChecker("mongodb", http.HealthCheckFunc(dbComponents.DB.HealthCheck), true)
+		}
+		if dbComponents.PackSizesCircuitBreaker != nil {
+			health.RegisterCircuitBreaker("mongodb_pack_sizes", dbComponents.PackSizesCircuitBreaker)
+		}
+		if dbComponents.LogsCircuitBreaker != nil {
+			health.RegisterOptionalCircuitBreaker("mongodb_logs", dbComponents.LogsCircuitBreaker)
+		}
+		if dbComponents.CalculationsCircuitBreaker != nil {
+			health.RegisterOptionalCircuitBreaker("mongodb_calculations", dbComponents.CalculationsCircuitBreaker)
+		}
+		if dbComponents.AuthCircuitBreaker != nil {
+			health.RegisterOptionalCircuitBreaker("mongodb_auth", dbComponents.AuthCircuitBreaker)
+		}
+	}
+
+	if pinger, ok := calculator.(interface{ PingCache(context.Context) error }); ok && cacheCfg.Backend == "redis" {
+		health.RegisterChecker("cache", http.HealthCheckFunc(pinger.PingCache), false)
+	}
+}
+
+// circuitBreakerRegistry registers the database circuit breakers for the
+// admin routes and the circuit_breaker_state gauge. It returns nil without a
+// database.
+func circuitBreakerRegistry(dbComponents *DatabaseComponents) *circuitbreaker.Registry {
+	if dbComponents == nil {
+		return nil
+	}
+	registry := circuitbreaker.NewRegistry()
+	for name, cb := range map[string]*circuitbreaker.CircuitBreaker{
+		"mongodb_pack_sizes":   dbComponents.PackSizesCircuitBreaker,
+		"mongodb_logs":         dbComponents.LogsCircuitBreaker,
+		"mongodb_calculations": dbComponents.CalculationsCircuitBreaker,
+		"mongodb_auth":         dbComponents.AuthCircuitBreaker,
+	} {
+		if cb != nil {
+			registry.Register(name, cb)
+		}
+	}
+	return registry
+}
+
+// edgeCachePolicy lets a gateway or CDN cache anonymous calculate responses,
+// purging them through the configured URL when the pack sizes change. It
+// returns nil when edge caching is disabled.
+func edgeCachePolicy(cfg config.EdgeCacheConfig) *edgecache.Policy {
+	edgeCfg := edgecache.Config{MaxAge: cfg.MaxAge}
+	if cfg.PurgeURL != "" {
+		edgeCfg.Purger = edgecache.NewHTTPPurger(cfg.PurgeURL, cfg.PurgeToken)
+	}
+	policy := edgecache.New(edgeCfg)
+	if policy != nil {
+		log.Info().Dur("max_age", cfg.MaxAge).Bool("purge", cfg.PurgeURL != "").Msg("Edge caching of calculate responses enabled")
+	}
+	return policy
+}
+
+// responseCache caches the responses of the endpoints dashboards poll. It
+// returns nil when response caching is disabled.
+func responseCache(cfg config.CacheConfig) *middleware.ResponseCache {
+	cache := middleware.NewResponseCache(cfg.ResponseTTL, cfg.ResponseMaxEntries)
+	if cache != nil {
+		log.Info().Dur("ttl", cfg.ResponseTTL).Int("max_entries", cfg.ResponseMaxEntries).Msg("Response caching enabled")
+	}
+	return cache
+}
+
+// requestValidator checks request bodies against the generated Swagger
+// document. An unknown mode is logged and leaves requests unchecked.
+func requestValidator(mode string) *middleware.RequestValidator {
+	validator, err := middleware.NewRequestValidator([]byte(docs.SwaggerInfo.ReadDoc()), mode)
+	if err != nil {
+		log.Error().Err(err).Str("mode", mode).Msg("Invalid REQUEST_VALIDATION, request bodies are not checked against the OpenAPI document")
+		return nil
+	}
+	if validator != nil {
+		log.Info().Str("mode", mode).Msg("OpenAPI request validation enabled")
+	}
+	return validator
+}
+
+// heavyRequestPool creates the pool capping the CPU-heavy requests served at
+// once. It returns nil when they are uncapped.
+func heavyRequestPool(cfg config.HeavyConfig) *workerpool.Pool {
+	if cfg.MaxConcurrent <= 0 {
+		return nil
+	}
+	return workerpool.New(workerpool.Config{
+		Name:         "heavy_requests",
+		Workers:      cfg.MaxConcurrent,
+		QueueTimeout: cfg.QueueTimeout,
+		MaxQueued:    cfg.MaxQueued,
+	})
+}
+
+// geoFence creates the geofence from the configured rules, letting users with
+// the system:write permission bypass it when JWT auth is available; jwtOpts
+// are the options of the JWTAuth middleware their tokens are validated with.
+// It returns nil when no rule is configured and admins cannot set any, and
+// drops rules and trusted proxies that do not parse; if no rule is left,
+// only loopback clients and admins are allowed, so a typo does not open the
+// service to every country.
+func geoFence(cfg config.GeoFenceConfig, authService service.AuthService, roleService service.RoleService, permissionService service.PermissionService, jwtOpts ...middleware.JWTAuthOption) *middleware.GeoFence {
+	canBypass := authService != nil && roleService != nil && permissionService != nil
+	configured := middleware.GeoFenceRules{AllowedCIDRs: cfg.AllowedCIDRs, AllowedCountries: cfg.AllowedCountries}
+	if !configured.Enabled() && !canBypass {
+		return nil
+	}
+
+	opts := []middleware.GeoFenceOption{middleware.WithCountryHeader(cfg.CountryHeader)}
+	if canBypass {
+		opts = append(opts, middleware.WithGeoFenceBypass(middleware.GlobalAdminBypass(authService, roleService, permissionService, jwtOpts...)))
+	}
+	for _, cidr := range cfg.TrustedProxies {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			log.Error().Err(err).Msg("Ignoring invalid GEOFENCE_TRUSTED_PROXIES entry")
+			continue
+		}
+		opts = append(opts, middleware.WithTrustedProxies(prefix.Masked()))
+	}
+	if len(cfg.AllowedCountries) > 0 && len(cfg.TrustedProxies) == 0 {
+		log.Warn().Msg("GEOFENCE_ALLOWED_COUNTRIES set without GEOFENCE_TRUSTED_PROXIES: the country of requests is unknown, only GEOFENCE_ALLOWED_CIDRS can allow them")
+	}
+
+	var rules middleware.GeoFenceRules
+	for _, cidr := range cfg.AllowedCIDRs {
+		if _, err := middleware.NewGeoFence(middleware.GeoFenceRules{AllowedCIDRs: []string{cidr}}); err != nil {
+			log.Error().Err(err).Msg("Ignoring invalid GEOFENCE_ALLOWED_CIDRS entry")
+			continue
+		}
+		rules.AllowedCIDRs = append(rules.AllowedCIDRs, cidr)
+	}
+	for _, country := range cfg.AllowedCountries {
+		if _, err := middleware.NewGeoFence(middleware.GeoFenceRules{AllowedCountries: []string{country}}); err != nil {
+			log.Error().Err(err).Msg("Ignoring invalid GEOFENCE_ALLOWED_COUNTRIES entry")
+			continue
+		}
+		rules.AllowedCountries = append(rules.AllowedCountries, country)
+	}
+	if configured.Enabled() && !rules.Enabled() {
+		log.Error().Msg("No valid geofence rule configured, allowing loopback clients only")
+		rules.AllowedCIDRs = []string{"127.0.0.0/8", "::1/128"}
+	}
+
+	fence, err := middleware.NewGeoFence(rules, opts...)
+	if err != nil {
+		log.Error().Err(err).Msg("Geofence disabled")
+		return nil
+	}
+	if rules.Enabled() {
+		log.Info().Strs("allowed_cidrs", rules.AllowedCIDRs).Strs("allowed_countries", rules.AllowedCountries).Msg("Geofence enabled")
+	}
+	return fence
+}
+
+// newTokenVersionCache creates the cache of users' token versions, kept
+// current by a change stream when the repository supports one. The returned
+// function stops the change stream; it is nil without one.
+func newTokenVersionCache(authCfg config.AuthConfig, userRepo repository.UserRepositoryInterface) (*service.TokenVersionCache, context.CancelFunc) {
+	switch authCfg.TokenRevocation {
+	case "", service.TokenRevocationBlacklist, service.TokenRevocationVersion:
+	default:
+		log.Warn().Str("mode", authCfg.TokenRevocation).Msg("Unknown AUTH_TOKEN_REVOCATION, using blacklist")
+	}
+
+	versions := service.NewTokenVersionCache(userRepo, authCfg.TokenVersionCacheTTL)
+	watcher, ok := userRepo.(repository.TokenVersionWatcher)
+	if !ok {
+		return versions, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	versions.Watch(ctx, watcher)
+	return versions, cancel
+}
+
+// newSigningKeyRing loads the JWT signing keys stored in repo, or returns nil
+// without one.
+func newSigningKeyRing(authCfg config.AuthConfig, repo repository.SigningKeyRepositoryInterface) *service.SigningKeyRing {
+	if repo == nil {
+		return nil
+	}
+
+	keys := service.NewSigningKeyRing(repo, authCfg.SigningKeyRefreshInterval)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := keys.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to load JWT signing keys")
+	}
+	return keys
+}
+
+// serviceTokens accepts the service tokens of the issuer publishing its
+// keys at cfg.JWKSURL, if any.
+func serviceTokens(cfg config.AuthConfig) service.TokenServiceOption {
+	var keys *jwks.KeySet
+	if cfg.JWKSURL != "" {
+		keys = jwks.New(jwks.Config{URL: cfg.JWKSURL, RefreshInterval: cfg.JWKSRefreshInterval})
+	}
+	return service.WithServiceTokens(keys, service.ServiceTokenConfig{
+		Issuer:   cfg.ServiceTokenIssuer,
+		Audience: cfg.ServiceTokenAudience,
+		Roles:    cfg.ServiceTokenRoles,
+	})
+}
+
+// activePackSizes creates the in-memory view of the active pack sizes, kept
+// current by a change stream when repo supports one and by polling
+// otherwise, and loads it. It returns nil without a database.
+func activePackSizes(packSizesService service.PackSizesService, repo repository.PackSizesRepositoryInterface, cfg config.DatabaseConfig) *service.ActivePackSizes {
+	if packSizesService == nil {
+		return nil
+	}
+
+	view := service.NewActivePackSizes(packSizesService, packSizesWatcher(repo), cfg.PackSizesPollInterval)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := view.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to load the active pack sizes, using the defaults until they load")
+	}
+	return view
+}
+
+// packSizesWatcher returns repo as a PackSizesWatcher when it supports change
+// streams, or nil.
+func packSizesWatcher(repo repository.PackSizesRepositoryInterface) repository.PackSizesWatcher {
+	if watcher, ok := repo.(repository.PackSizesWatcher); ok {
+		return watcher
+	}
+	return nil
+}
+
+// displayLocation resolves DISPLAY_TIMEZONE, falling back to UTC when it is
+// not a valid timezone.
+func displayLocation(name string) *time.Location {
+	loc, err := timeutil.LoadLocation(name)
+	if err != nil {
+		log.Warn().Err(err).Str("timezone", name).Msg("Invalid DISPLAY_TIMEZONE, reports use UTC")
+		return time.UTC
+	}
+	return loc
+}
+
+// presentationRules loads the presentation rules of cfg.RulesFile. Invalid
+// rules are logged and results are presented as calculated.
+func presentationRules(cfg config.PresentationConfig) *presentation.Engine {
+	if cfg.RulesFile == "" {
+		return nil
+	}
+	rules, err := presentation.LoadFile(cfg.RulesFile)
+	if err == nil {
+		var engine *presentation.Engine
+		if engine, err = presentation.NewEngine(rules); err == nil {
+			log.Info().Str("file", cfg.RulesFile).Int("tenants", len(rules.Tenants)).Msg("Presentation rules loaded")
+			return engine
+		}
+	}
+	log.Error().Err(err).Str("file", cfg.RulesFile).Msg("Invalid PRESENTATION_RULES_FILE, results are presented as calculated")
+	return nil
+}
+
+// trustedProxies returns the valid IPs and CIDR ranges of TRUSTED_PROXIES,
+// logging the others.
+func trustedProxies(entries []string) []string {
+	var proxies []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if _, err := netip.ParsePrefix(entry); err != nil {
+			if _, err := netip.ParseAddr(entry); err != nil {
+				log.Error().Str("entry", entry).Msg("Ignoring invalid TRUSTED_PROXIES entry")
+				continue
+			}
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies
+}
+
+// pageCursors creates the codec of listing page cursors. Without a valid
+// PAGE_CURSOR_KEY the key is random, so a cursor only works on the instance
+// that issued it; clients sent elsewhere get invalid_cursor and restart.
+func pageCursors(cfg config.ServerConfig) *pagination.Codec {
+	key, err := base64.StdEncoding.DecodeString(cfg.PageCursorKey)
+	if err == nil {
+		var codec *pagination.Codec
+		if codec, err = pagination.NewCodec(key, cfg.PageCursorTTL); err == nil {
+			return codec
+		}
 	}
+	log.Error().Err(err).Msg("Invalid PAGE_CURSOR_KEY, page cursors use a random key")
+	codec, _ := pagination.NewCodec(nil, cfg.PageCursorTTL)
+	return codec
 }
@@ -3,6 +3,9 @@
 package app
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,8 +13,19 @@ import (
 	"github.com/guttosm/pack-service/config"
 	"github.com/guttosm/pack-service/internal/mocks"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// noActivePackSizesRepo returns a pack sizes repository without an active
+// configuration, which the router reads at startup.
+func noActivePackSizesRepo() *mocks.MockPackSizesRepositoryInterface {
+	repo := new(mocks.MockPackSizesRepositoryInterface)
+	repo.On("GetActive", mock.Anything).Return(nil, nil).Maybe()
+	return repo
+}
+
 func TestInitializeRouter(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -36,6 +50,8 @@ func TestInitializeRouter(t *testing.T) {
 				assert.False(t, components.Config.EnableAuth)
 				assert.True(t, components.Config.EnableIdempotency)
 				assert.Equal(t, 100, components.Config.RateLimit)
+				assert.NotNil(t, components.Config.BatchPool)
+				assert.Nil(t, components.Config.CalculationHistory)
 			},
 		},
 		{
@@ -61,10 +77,11 @@ func TestInitializeRouter(t *testing.T) {
 			name:       "creates router with database components",
 			calculator: service.NewPackCalculatorService(),
 			dbComponents: &DatabaseComponents{
-				PackSizesRepo:            new(mocks.MockPackSizesRepositoryInterface),
+				PackSizesRepo:            noActivePackSizesRepo(),
 				LoggingService:           mocks.NewMockLoggingService(t),
 				PackSizesCircuitBreaker:  nil,
 				LogsCircuitBreaker:       nil,
+				CalculationRepo:          new(mocks.MockCalculationRepositoryInterface),
 			},
 			cfg: config.Config{
 				Server: config.ServerConfig{
@@ -76,13 +93,14 @@ func TestInitializeRouter(t *testing.T) {
 				assert.NotNil(t, components)
 				assert.NotNil(t, components.Config.PackSizesService)
 				assert.NotNil(t, components.Config.LoggingService)
+				assert.NotNil(t, components.Config.CalculationHistory)
 			},
 		},
 		{
 			name:       "creates router with circuit breakers registered",
 			calculator: service.NewPackCalculatorService(),
 			dbComponents: &DatabaseComponents{
-				PackSizesRepo:            new(mocks.MockPackSizesRepositoryInterface),
+				PackSizesRepo:            noActivePackSizesRepo(),
 				LoggingService:           mocks.NewMockLoggingService(t),
 				PackSizesCircuitBreaker:  nil, // Using nil since circuit breaker is tested in integration tests
 				LogsCircuitBreaker:       nil,
@@ -122,7 +140,7 @@ func TestInitializeRouter(t *testing.T) {
 				UserRepo:      mocks.NewMockUserRepositoryInterface(t),
 				RoleRepo:      mocks.NewMockRoleRepositoryInterface(t),
 				TokenRepo:     mocks.NewMockTokenRepositoryInterface(t),
-				PackSizesRepo: new(mocks.MockPackSizesRepositoryInterface),
+				PackSizesRepo: noActivePackSizesRepo(),
 			},
 			cfg: config.Config{
 				Server: config.ServerConfig{
@@ -140,7 +158,7 @@ func TestInitializeRouter(t *testing.T) {
 			calculator: service.NewPackCalculatorService(),
 			dbComponents: &DatabaseComponents{
 				UserRepo:      nil,
-				PackSizesRepo: new(mocks.MockPackSizesRepositoryInterface),
+				PackSizesRepo: noActivePackSizesRepo(),
 			},
 			cfg: config.Config{
 				Server: config.ServerConfig{
@@ -164,3 +182,111 @@ func TestInitializeRouter(t *testing.T) {
 		})
 	}
 }
+
+// watchingUserRepo is a user repository with a token version change stream
+// that runs until its context is done.
+type watchingUserRepo struct {
+	*mocks.MockUserRepositoryInterface
+	stopped chan struct{}
+}
+
+func (r *watchingUserRepo) WatchTokenVersions(ctx context.Context, _ func(primitive.ObjectID, int)) error {
+	<-ctx.Done()
+	close(r.stopped)
+	return ctx.Err()
+}
+
+func TestNewTokenVersionCache(t *testing.T) {
+	t.Run("stops watching when cancelled", func(t *testing.T) {
+		repo := &watchingUserRepo{MockUserRepositoryInterface: new(mocks.MockUserRepositoryInterface), stopped: make(chan struct{})}
+
+		versions, stop := newTokenVersionCache(config.AuthConfig{}, repo)
+		require.NotNil(t, versions)
+		require.NotNil(t, stop)
+
+		stop()
+		select {
+		case <-repo.stopped:
+		case <-time.After(time.Second):
+			t.Fatal("change stream still running after stop")
+		}
+	})
+
+	t.Run("nothing to stop without a change stream", func(t *testing.T) {
+		versions, stop := newTokenVersionCache(config.AuthConfig{}, new(mocks.MockUserRepositoryInterface))
+
+		assert.NotNil(t, versions)
+		assert.Nil(t, stop)
+	})
+}
+
+func TestDisplayLocation(t *testing.T) {
+	assert.Equal(t, time.UTC, displayLocation(""))
+	assert.Equal(t, "Asia/Tokyo", displayLocation("Asia/Tokyo").String())
+	assert.Equal(t, time.UTC, displayLocation("Not/AZone"))
+}
+
+func TestPageCursors(t *testing.T) {
+	key := config.ServerConfig{PageCursorKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", PageCursorTTL: time.Minute}
+	cursor := pageCursors(key).Encode("logs?", 50)
+
+	offset, err := pageCursors(key).Decode("logs?", cursor)
+	require.NoError(t, err, "instances sharing the key accept each other's cursors")
+	assert.Equal(t, 50, offset)
+
+	for _, cfg := range []config.ServerConfig{{}, {PageCursorKey: "not base64!"}, {PageCursorKey: "c2hvcnQ="}} {
+		codec := pageCursors(cfg)
+		require.NotNil(t, codec, "%+v", cfg)
+		_, err := codec.Decode("logs?", cursor)
+		assert.Error(t, err, "a random key does not accept other instances' cursors")
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"},
+		trustedProxies([]string{"10.0.0.0/8", " 192.0.2.1", "not-an-ip", "2001:db8::/32", "10.0.0.0/33"}))
+	assert.Empty(t, trustedProxies(nil))
+}
+
+func TestPresentationRules(t *testing.T) {
+	assert.Nil(t, presentationRules(config.PresentationConfig{}))
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("default:\n  order: largest_first\n"), 0o600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("default:\n  order: alphabetical\n"), 0o600))
+
+	assert.NotNil(t, presentationRules(config.PresentationConfig{RulesFile: valid}))
+	assert.Nil(t, presentationRules(config.PresentationConfig{RulesFile: invalid}))
+	assert.Nil(t, presentationRules(config.PresentationConfig{RulesFile: filepath.Join(dir, "missing.yaml")}))
+}
+
+func TestGeoFence(t *testing.T) {
+	t.Run("disabled without rules or admins", func(t *testing.T) {
+		assert.Nil(t, geoFence(config.GeoFenceConfig{}, nil, nil, nil))
+	})
+
+	t.Run("available to admins without rules", func(t *testing.T) {
+		fence := geoFence(config.GeoFenceConfig{}, new(mocks.MockAuthService), new(mocks.MockRoleService), new(mocks.MockPermissionService))
+		if assert.NotNil(t, fence) {
+			assert.True(t, fence.Allowed("", "203.0.113.7", "US"))
+		}
+	})
+
+	t.Run("drops invalid rules", func(t *testing.T) {
+		fence := geoFence(config.GeoFenceConfig{AllowedCIDRs: []string{"10.0.0.0/8", "10.0.0.0/99"}, AllowedCountries: []string{"NLD"}}, nil, nil, nil)
+		if assert.NotNil(t, fence) {
+			assert.Equal(t, []string{"10.0.0.0/8"}, fence.Rules().AllowedCIDRs)
+			assert.Empty(t, fence.Rules().AllowedCountries)
+		}
+	})
+
+	t.Run("fails closed when no rule is valid", func(t *testing.T) {
+		fence := geoFence(config.GeoFenceConfig{AllowedCountries: []string{"Netherlands"}}, nil, nil, nil)
+		if assert.NotNil(t, fence) {
+			assert.False(t, fence.Allowed("", "203.0.113.7", "NL"))
+			assert.True(t, fence.Allowed("", "127.0.0.1", ""))
+		}
+	})
+}
@@ -0,0 +1,70 @@
+// Package app provides development seed data initialization.
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/seed"
+	"github.com/rs/zerolog/log"
+)
+
+// InitializeSeedData loads the fixtures in cfg.Seed.Dir and applies them to the
+// database. It returns the configured API keys merged with the fixture keys.
+//
+// Fixtures contain plain-text passwords and keys, so they are only applied when
+// cfg.Environment is a development or test environment.
+func InitializeSeedData(cfg config.Config, dbComponents *DatabaseComponents) map[string]bool {
+	apiKeys := cfg.Auth.APIKeys
+	if cfg.Seed.Dir == "" {
+		return apiKeys
+	}
+	if !cfg.IsDevelopment() {
+		log.Warn().Str("environment", cfg.Environment).Msg("Ignoring SEED_DIR outside development and test environments")
+		return apiKeys
+	}
+
+	fixtures, err := seed.Load(cfg.Seed.Dir)
+	if err != nil {
+		log.Error().Err(err).Str("dir", cfg.Seed.Dir).Msg("Failed to load seed fixtures")
+		return apiKeys
+	}
+
+	if len(fixtures.APIKeys) > 0 {
+		merged := make(map[string]bool, len(apiKeys)+len(fixtures.APIKeys))
+		for key, enabled := range apiKeys {
+			merged[key] = enabled
+		}
+		for _, key := range fixtures.APIKeys {
+			merged[key] = true
+		}
+		apiKeys = merged
+	}
+
+	if dbComponents == nil {
+		log.Warn().Msg("Database disabled - only seed API keys were loaded")
+		return apiKeys
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	seeder := seed.NewSeeder(dbComponents.RoleRepo, dbComponents.PermissionRepo, dbComponents.UserRepo, dbComponents.PackSizesRepo)
+	result, err := seeder.Apply(ctx, fixtures)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to apply seed fixtures")
+		return apiKeys
+	}
+
+	log.Info().
+		Str("dir", cfg.Seed.Dir).
+		Int("roles_created", result.RolesCreated).
+		Int("roles_updated", result.RolesUpdated).
+		Int("users_created", result.UsersCreated).
+		Int("pack_size_configs_created", result.PackSizesCreated).
+		Int("api_keys", len(fixtures.APIKeys)).
+		Msg("Applied seed fixtures")
+
+	return apiKeys
+}
@@ -0,0 +1,55 @@
+//go:build !integration
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeSeedData(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keys.yaml"), []byte("api_keys: [seed-key]\n"), 0o600))
+
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		wantKeys map[string]bool
+	}{
+		{
+			name:     "no seed directory",
+			cfg:      config.Config{Environment: "development", Auth: config.AuthConfig{APIKeys: map[string]bool{"env-key": true}}},
+			wantKeys: map[string]bool{"env-key": true},
+		},
+		{
+			name:     "ignored in production",
+			cfg:      config.Config{Environment: "production", Seed: config.SeedConfig{Dir: dir}},
+			wantKeys: nil,
+		},
+		{
+			name: "merges fixture api keys",
+			cfg: config.Config{
+				Environment: "development",
+				Auth:        config.AuthConfig{APIKeys: map[string]bool{"env-key": true}},
+				Seed:        config.SeedConfig{Dir: dir},
+			},
+			wantKeys: map[string]bool{"env-key": true, "seed-key": true},
+		},
+		{
+			name:     "unreadable fixtures keep configured keys",
+			cfg:      config.Config{Environment: "test", Seed: config.SeedConfig{Dir: filepath.Join(dir, "missing")}},
+			wantKeys: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantKeys, InitializeSeedData(tt.cfg, nil))
+		})
+	}
+}
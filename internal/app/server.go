@@ -2,7 +2,9 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,17 +12,64 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
 )
 
-// Server wraps http.Server with graceful shutdown capabilities.
+// Server wraps http.Server, and optionally a gRPC server, with graceful
+// shutdown capabilities.
 type Server struct {
 	httpServer      *http.Server
+	grpcServer      *grpc.Server
+	grpcAddr        string
+	tlsCerts        *TLSCertificates
+	http2           bool
 	shutdownTimeout time.Duration
+	shutdownHooks   []func(context.Context)
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithGRPCServer serves grpcServer on port alongside HTTP. A nil server is ignored.
+func WithGRPCServer(grpcServer *grpc.Server, port string) ServerOption {
+	return func(s *Server) {
+		if grpcServer != nil {
+			s.grpcServer = grpcServer
+			s.grpcAddr = ":" + port
+		}
+	}
+}
+
+// WithTLS serves HTTP and gRPC over TLS with certs. A nil certs is ignored.
+func WithTLS(certs *TLSCertificates) ServerOption {
+	return func(s *Server) {
+		if certs != nil {
+			s.tlsCerts = certs
+		}
+	}
+}
+
+// WithHTTP2 sets whether HTTP/2 is served alongside HTTP/1.1: negotiated
+// with ALPN over TLS, and with prior knowledge (h2c) over plain HTTP.
+func WithHTTP2(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.http2 = enabled
+	}
+}
+
+// WithShutdownHook runs hook during graceful shutdown, once the servers have
+// stopped taking requests. Hooks share the shutdown timeout. A nil hook is ignored.
+func WithShutdownHook(hook func(context.Context)) ServerOption {
+	return func(s *Server) {
+		if hook != nil {
+			s.shutdownHooks = append(s.shutdownHooks, hook)
+		}
+	}
 }
 
 // NewServer creates a new Server instance with optimized settings.
-func NewServer(handler http.Handler, port string) *Server {
-	return &Server{
+func NewServer(handler http.Handler, port string, opts ...ServerOption) *Server {
+	s := &Server{
 		httpServer: &http.Server{
 			Addr:           ":" + port,
 			Handler:        handler,
@@ -30,25 +79,69 @@ func NewServer(handler http.Handler, port string) *Server {
 			MaxHeaderBytes: 1 << 20, // 1MB
 		},
 		shutdownTimeout: 10 * time.Second,
+		http2:           true,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(s.http2)
+	protocols.SetUnencryptedHTTP2(s.http2 && s.tlsCerts == nil)
+	s.httpServer.Protocols = protocols
+	if s.tlsCerts != nil {
+		nextProtos := []string{"http/1.1"}
+		if s.http2 {
+			nextProtos = []string{"h2", "http/1.1"}
+		}
+		s.httpServer.TLSConfig = s.tlsCerts.Config(nextProtos...)
+	}
+	return s
 }
 
 // Run starts the server and blocks until shutdown signal is received.
 func (s *Server) Run() error {
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	if s.tlsCerts != nil {
+		listener = tls.NewListener(listener, s.httpServer.TLSConfig)
+	}
 	go func() {
-		log.Info().Str("addr", s.httpServer.Addr).Msg("Server starting")
-		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Info().Str("addr", s.httpServer.Addr).Bool("tls", s.tlsCerts != nil).Msg("Server starting")
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errChan <- err
 		}
 	}()
 
+	if s.grpcServer != nil {
+		listener, err := net.Listen("tcp", s.grpcAddr)
+		if err != nil {
+			_ = s.Shutdown()
+			return err
+		}
+		if s.tlsCerts != nil {
+			// gRPC runs over HTTP/2 whatever HTTP2_ENABLED says
+			listener = tls.NewListener(listener, s.tlsCerts.Config("h2"))
+		}
+		go func() {
+			log.Info().Str("addr", s.grpcAddr).Msg("gRPC server starting")
+			if err := s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				errChan <- err
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	select {
 	case err := <-errChan:
+		_ = s.Shutdown()
 		return err
 	case sig := <-quit:
 		log.Info().Str("signal", sig.String()).Msg("Received signal, initiating graceful shutdown")
@@ -62,7 +155,15 @@ func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 
-	if err := s.httpServer.Shutdown(ctx); err != nil {
+	if s.grpcServer != nil {
+		s.stopGRPC(ctx)
+	}
+
+	err := s.httpServer.Shutdown(ctx)
+	for _, hook := range s.shutdownHooks {
+		hook(ctx)
+	}
+	if err != nil {
 		log.Error().Err(err).Msg("Server forced to shutdown")
 		return err
 	}
@@ -70,3 +171,19 @@ func (s *Server) Shutdown() error {
 	log.Info().Msg("Server stopped gracefully")
 	return nil
 }
+
+// stopGRPC lets in-flight RPCs finish, then forces the server to stop once ctx expires.
+func (s *Server) stopGRPC(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Warn().Msg("gRPC server forced to stop")
+		s.grpcServer.Stop()
+	}
+}
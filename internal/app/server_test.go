@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 func TestNewServer(t *testing.T) {
@@ -128,3 +129,47 @@ func TestServer_Run_GracefulShutdown(t *testing.T) {
 	}
 }
 
+func TestServer_RunWithGRPC(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	grpcServer := grpc.NewServer()
+
+	server := NewServer(handler, "0", WithGRPCServer(grpcServer, "0"))
+	require.Same(t, grpcServer, server.grpcServer)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Run()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	proc, _ := os.FindProcess(os.Getpid())
+	_ = proc.Signal(syscall.SIGTERM)
+
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not shutdown in time")
+	}
+}
+
+func TestWithGRPCServer_IgnoresNil(t *testing.T) {
+	server := NewServer(http.NotFoundHandler(), "8080", WithGRPCServer(nil, "9090"))
+	assert.Nil(t, server.grpcServer)
+}
+
+func TestWithShutdownHook(t *testing.T) {
+	var calls int
+	server := NewServer(http.NotFoundHandler(), "0",
+		WithShutdownHook(func(ctx context.Context) {
+			assert.NoError(t, ctx.Err())
+			calls++
+		}),
+		WithShutdownHook(nil))
+
+	require.NoError(t, server.Shutdown())
+	assert.Equal(t, 1, calls)
+}
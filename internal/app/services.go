@@ -2,8 +2,11 @@
 package app
 
 import (
+	"github.com/rs/zerolog/log"
+
 	"github.com/guttosm/pack-service/config"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/service/cache"
 )
 
 // ServiceComponents holds service-related components.
@@ -11,19 +14,51 @@ type ServiceComponents struct {
 	Calculator service.PackCalculator
 }
 
-// InitializeServices initializes business logic services.
-func InitializeServices(cfg config.CacheConfig) *ServiceComponents {
+// InitializeServices initializes business logic services. opts are applied
+// to the calculator after the cache configuration.
+func InitializeServices(cfg config.CacheConfig, calculatorOpts ...service.Option) *ServiceComponents {
 	var opts []service.Option
 
 	if len(cfg.PackSizes) > 0 {
 		opts = append(opts, service.WithPackSizes(cfg.PackSizes))
 	}
 
-	if cfg.Size > 0 {
+	switch {
+	case cfg.Backend == "redis":
+		opts = append(opts, service.WithRedisCache(cache.RedisConfig{
+			Addr:             cfg.Redis.Addr,
+			Password:         cfg.Redis.Password,
+			DB:               cfg.Redis.DB,
+			PoolSize:         cfg.Redis.PoolSize,
+			MinIdleConns:     cfg.Redis.MinIdleConns,
+			DialTimeout:      cfg.Redis.DialTimeout,
+			OperationTimeout: cfg.Redis.OperationTimeout,
+			KeyPrefix:        cfg.Redis.KeyPrefix,
+			TTL:              cfg.TTL,
+		}))
+		log.Info().Str("addr", cfg.Redis.Addr).Msg("Using Redis result cache")
+	case cfg.Size > 0:
+		if cfg.Backend != "" && cfg.Backend != "memory" {
+			log.Warn().Str("backend", cfg.Backend).Msg("Unknown CACHE_BACKEND, using in-memory cache")
+		}
 		opts = append(opts, service.WithCache(cfg.Size, cfg.TTL))
+		switch cfg.Eviction {
+		case service.CacheEvictionLFU:
+			opts = append(opts, service.WithCacheEviction(service.CacheEvictionLFU))
+		case "", service.CacheEvictionLRU:
+		default:
+			log.Warn().Str("eviction", cfg.Eviction).Msg("Unknown CACHE_EVICTION, using LRU")
+		}
+	}
+
+	compressor, err := cache.NewCompressor(cfg.Compression, cfg.CompressionThreshold)
+	if err != nil {
+		log.Warn().Err(err).Msg("Cache compression disabled")
+	} else if compressor != nil {
+		opts = append(opts, service.WithCompression(compressor))
 	}
 
-	calculator := service.NewPackCalculatorService(opts...)
+	calculator := service.NewPackCalculatorService(append(opts, calculatorOpts...)...)
 
 	return &ServiceComponents{
 		Calculator: calculator,
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/service"
 )
 
 func TestInitializeServices(t *testing.T) {
@@ -50,6 +51,18 @@ func TestInitializeServices(t *testing.T) {
 				assert.NotNil(t, components.Calculator)
 			},
 		},
+		{
+			name: "creates service with lfu cache eviction",
+			cfg: config.CacheConfig{
+				Size:     1000,
+				TTL:      5 * time.Minute,
+				Eviction: "lfu",
+			},
+			validate: func(t *testing.T, components *ServiceComponents) {
+				assert.NotNil(t, components)
+				assert.Equal(t, 251, components.Calculator.Calculate(251).OrderedItems)
+			},
+		},
 		{
 			name: "creates service with cache and custom pack sizes",
 			cfg: config.CacheConfig{
@@ -62,6 +75,18 @@ func TestInitializeServices(t *testing.T) {
 				assert.NotNil(t, components.Calculator)
 			},
 		},
+		{
+			name: "creates service with redis cache backend",
+			cfg: config.CacheConfig{
+				Backend: "redis",
+				TTL:     time.Minute,
+				Redis:   config.RedisConfig{Addr: "localhost:0"},
+			},
+			validate: func(t *testing.T, components *ServiceComponents) {
+				assert.NotNil(t, components)
+				assert.NotNil(t, components.Calculator)
+			},
+		},
 		{
 			name: "creates service with zero cache size disables cache",
 			cfg: config.CacheConfig{
@@ -99,3 +124,10 @@ func TestServiceComponents_Calculator(t *testing.T) {
 	assert.Greater(t, result.TotalItems, result.OrderedItems)
 	assert.NotEmpty(t, result.Packs)
 }
+
+func TestInitializeServices_CalculatorOptions(t *testing.T) {
+	components := InitializeServices(config.CacheConfig{Size: 100, TTL: time.Minute}, service.WithMaxItemsOrdered(1000))
+
+	assert.Equal(t, 1000, service.MaxItemsOrdered(components.Calculator))
+	assert.Empty(t, components.Calculator.Calculate(1001).Packs)
+}
@@ -0,0 +1,97 @@
+// Package app provides support bundle wiring.
+package app
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/support"
+	"github.com/rs/zerolog/log"
+)
+
+// NewSupportBundleGenerator creates a support bundle generator over the running
+// application's components. dbComponents may be nil when the database is disabled.
+func NewSupportBundleGenerator(
+	cfg config.Config,
+	calculator service.PackCalculator,
+	dbComponents *DatabaseComponents,
+) *support.Generator {
+	sources := support.Sources{
+		Config:          cfg,
+		CircuitBreakers: make(map[string]*circuitbreaker.CircuitBreaker),
+	}
+
+	if provider, ok := calculator.(support.CacheMetricsProvider); ok {
+		sources.Cache = provider
+	}
+
+	if dbComponents != nil {
+		sources.LoggingService = dbComponents.LoggingService
+		if dbComponents.PackSizesCircuitBreaker != nil {
+			sources.CircuitBreakers["mongodb_pack_sizes"] = dbComponents.PackSizesCircuitBreaker
+		}
+		if dbComponents.LogsCircuitBreaker != nil {
+			sources.CircuitBreakers["mongodb_logs"] = dbComponents.LogsCircuitBreaker
+		}
+		if dbComponents.AuthCircuitBreaker != nil {
+			sources.CircuitBreakers["mongodb_auth"] = dbComponents.AuthCircuitBreaker
+		}
+	}
+
+	return support.NewGenerator(sources)
+}
+
+// WriteSupportBundle generates a support bundle from the command line and writes it
+// to output, or to a timestamped file in the working directory when output is empty.
+// It returns the path written.
+//
+// The CLI runs in its own process, so circuit breaker states and cache metrics
+// describe that process; use the admin endpoint for a live server's state.
+func WriteSupportBundle(cfg config.Config, output string) (string, error) {
+	var loggingService service.LoggingService
+	if cfg.Database.Enabled {
+		db, err := repository.NewMongoDB(cfg.Database.URI, cfg.Database.DatabaseName)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to connect to MongoDB - bundle will not include recent errors")
+		} else {
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = db.Close(ctx)
+			}()
+			loggingService = service.NewLoggingService(repository.NewLogsRepository(db))
+		}
+	}
+
+	generator := support.NewGenerator(support.Sources{
+		Config:         cfg,
+		LoggingService: loggingService,
+	})
+
+	if output == "" {
+		output = generator.Filename()
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := generator.Write(ctx, f); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
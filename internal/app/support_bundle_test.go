@@ -0,0 +1,49 @@
+//go:build !integration
+
+package app
+
+import (
+	"archive/zip"
+	"path/filepath"
+	"testing"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSupportBundleGenerator(t *testing.T) {
+	t.Run("without database", func(t *testing.T) {
+		generator := NewSupportBundleGenerator(config.Config{}, service.NewPackCalculatorService(), nil)
+		assert.NotNil(t, generator)
+	})
+
+	t.Run("with database components", func(t *testing.T) {
+		dbComponents := &DatabaseComponents{
+			PackSizesCircuitBreaker: circuitbreaker.New(circuitbreaker.DefaultConfig()),
+			LogsCircuitBreaker:      circuitbreaker.New(circuitbreaker.DefaultConfig()),
+		}
+		generator := NewSupportBundleGenerator(config.Config{}, service.NewPackCalculatorService(), dbComponents)
+		assert.NotNil(t, generator)
+	})
+}
+
+func TestWriteSupportBundle(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "bundle.zip")
+
+	path, err := WriteSupportBundle(config.Config{}, output)
+	require.NoError(t, err)
+	assert.Equal(t, output, path)
+
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+	assert.NotEmpty(t, zr.File)
+}
+
+func TestWriteSupportBundle_InvalidOutput(t *testing.T) {
+	_, err := WriteSupportBundle(config.Config{}, filepath.Join(t.TempDir(), "missing", "bundle.zip"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+)
+
+// TLS client authentication modes.
+const (
+	tlsClientAuthRequire  = "require"
+	tlsClientAuthOptional = "optional"
+)
+
+// TLSCertificates holds the server certificate and the client CAs read from
+// the configured files, and builds the TLS configurations of the listeners
+// from them. Reload swaps in new files without dropping open connections.
+type TLSCertificates struct {
+	certFile, keyFile, clientCAFile string
+	clientAuth                      tls.ClientAuthType
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  []time.Time
+}
+
+// InitializeTLS loads the certificates configured in cfg, or returns nil when
+// TLS is not configured. With cfg.ReloadInterval the files are checked for
+// changes in the background; the returned hook stops checking at shutdown.
+func InitializeTLS(cfg config.TLSConfig) (*TLSCertificates, func(context.Context), error) {
+	if !cfg.Enabled() {
+		return nil, nil, nil
+	}
+	certs, err := NewTLSCertificates(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Info().
+		Str("cert_file", cfg.CertFile).
+		Str("client_ca_file", cfg.ClientCAFile).
+		Str("client_auth", certs.clientAuth.String()).
+		Msg("TLS enabled")
+	if cfg.ReloadInterval <= 0 {
+		return certs, nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		certs.watch(ctx, cfg.ReloadInterval)
+	}()
+	return certs, func(context.Context) {
+		cancel()
+		<-done
+	}, nil
+}
+
+// NewTLSCertificates loads the certificate and client CAs configured in cfg.
+func NewTLSCertificates(cfg config.TLSConfig) (*TLSCertificates, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("TLS needs both TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	certs := &TLSCertificates{
+		certFile:     cfg.CertFile,
+		keyFile:      cfg.KeyFile,
+		clientCAFile: cfg.ClientCAFile,
+		clientAuth:   tls.NoClientCert,
+	}
+	if cfg.ClientCAFile != "" {
+		switch cfg.ClientAuth {
+		case "", tlsClientAuthRequire:
+			certs.clientAuth = tls.RequireAndVerifyClientCert
+		case tlsClientAuthOptional:
+			certs.clientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("unknown TLS client auth %q, use require or optional", cfg.ClientAuth)
+		}
+	}
+
+	if err := certs.Reload(); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// Reload reads the certificate and client CAs again. On error the previous
+// ones are kept.
+func (c *TLSCertificates) Reload() error {
+	modTimes := c.fileModTimes()
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if c.clientCAFile != "" {
+		pem, err := os.ReadFile(c.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("read TLS client CAs: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in TLS client CA file %s", c.clientCAFile)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	c.clientCAs = clientCAs
+	c.modTimes = modTimes
+	return nil
+}
+
+// Config returns a TLS configuration serving the current certificate and
+// verifying clients against the current client CAs on every handshake, and
+// offering nextProtos for ALPN.
+func (c *TLSCertificates) Config(nextProtos ...string) *tls.Config {
+	handshake := func() *tls.Config {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			NextProtos:   nextProtos,
+			Certificates: []tls.Certificate{*c.cert},
+			ClientAuth:   c.clientAuth,
+			ClientCAs:    c.clientCAs,
+		}
+	}
+
+	cfg := handshake()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return handshake(), nil
+	}
+	return cfg
+}
+
+// watch reloads the files every interval when any of them changed, until ctx
+// is done. Files that fail to load are retried once they change again.
+func (c *TLSCertificates) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.mu.RLock()
+	seen := c.modTimes
+	c.mu.RUnlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTimes := c.fileModTimes()
+			if slices.EqualFunc(modTimes, seen, time.Time.Equal) {
+				continue
+			}
+			seen = modTimes
+			if err := c.Reload(); err != nil {
+				log.Error().Err(err).Msg("Failed to reload TLS certificates, keeping the previous ones")
+				continue
+			}
+			log.Info().Str("cert_file", c.certFile).Msg("TLS certificates reloaded")
+		}
+	}
+}
+
+// fileModTimes returns the modification times of the files, zero for those
+// missing or not configured.
+func (c *TLSCertificates) fileModTimes() []time.Time {
+	files := []string{c.certFile, c.keyFile, c.clientCAFile}
+	modTimes := make([]time.Time, len(files))
+	for i, file := range files {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+	return modTimes
+}
@@ -0,0 +1,276 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+)
+
+// testCert is a certificate and its key, issued by parent or self-signed.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write stores the certificate and key as PEM files in dir.
+func (c *testCert) write(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// serveTLS accepts TLS connections with cfg, completing their handshakes,
+// and returns the listener address.
+func serveTLS(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = conn.(*tls.Conn).Handshake()
+				_ = conn.Close()
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// peerSerial dials addr and returns the serial number of the server certificate.
+func peerSerial(t *testing.T, addr string, clientCfg *tls.Config) (*big.Int, error) {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, clientCfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	// Client certificate errors surface on the first read with TLS 1.3
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber, nil
+}
+
+func TestInitializeTLS_Disabled(t *testing.T) {
+	certs, stop, err := InitializeTLS(config.TLSConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, certs)
+	assert.Nil(t, stop)
+}
+
+func TestNewTLSCertificates_Errors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := newTestCert(t, "server", nil, false).write(t, dir, "server")
+
+	tests := []struct {
+		name string
+		cfg  config.TLSConfig
+	}{
+		{name: "missing key", cfg: config.TLSConfig{CertFile: certFile}},
+		{name: "unreadable certificate", cfg: config.TLSConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: keyFile}},
+		{name: "unknown client auth", cfg: config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile, ClientAuth: "sometimes"}},
+		{name: "empty client CA file", cfg: config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTLSCertificates(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTLSCertificates_ClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil, true)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "server", ca, false).write(t, dir, "server")
+	client := newTestCert(t, "client", ca, false).tlsCertificate()
+	stranger := newTestCert(t, "stranger", nil, false).tlsCertificate()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	dial := func(addr string, certs ...tls.Certificate) error {
+		_, err := peerSerial(t, addr, &tls.Config{
+			RootCAs:    roots,
+			ServerName: "localhost",
+			// Send the certificate even when the server does not accept its issuer
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if len(certs) == 0 {
+					return &tls.Certificate{}, nil
+				}
+				return &certs[0], nil
+			},
+		})
+		return err
+	}
+
+	t.Run("require", func(t *testing.T) {
+		certs, err := NewTLSCertificates(config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+		require.NoError(t, err)
+		addr := serveTLS(t, certs.Config())
+
+		assert.NoError(t, dial(addr, client))
+		assert.Error(t, dial(addr))
+		assert.Error(t, dial(addr, stranger))
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		certs, err := NewTLSCertificates(config.TLSConfig{
+			CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: "optional",
+		})
+		require.NoError(t, err)
+		addr := serveTLS(t, certs.Config())
+
+		assert.NoError(t, dial(addr, client))
+		assert.NoError(t, dial(addr))
+		assert.Error(t, dial(addr, stranger))
+	})
+}
+
+func TestInitializeTLS_ReloadsChangedCertificates(t *testing.T) {
+	dir := t.TempDir()
+	first := newTestCert(t, "first", nil, false)
+	certFile, keyFile := first.write(t, dir, "server")
+
+	certs, stop, err := InitializeTLS(config.TLSConfig{
+		CertFile: certFile, KeyFile: keyFile, ReloadInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, stop)
+	defer stop(context.Background())
+	addr := serveTLS(t, certs.Config())
+	clientCfg := &tls.Config{InsecureSkipVerify: true} //nolint:gosec // self-signed test certificates
+
+	serial, err := peerSerial(t, addr, clientCfg)
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.SerialNumber, serial)
+
+	// A broken file keeps the previous certificate
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0o600))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	time.Sleep(50 * time.Millisecond)
+	serial, err = peerSerial(t, addr, clientCfg)
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.SerialNumber, serial)
+
+	second := newTestCert(t, "second", nil, false)
+	second.write(t, dir, "server")
+	later = later.Add(time.Second)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	assert.Eventually(t, func() bool {
+		serial, err := peerSerial(t, addr, clientCfg)
+		return err == nil && serial.Cmp(second.cert.SerialNumber) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := newTestCert(t, "server", nil, false).write(t, dir, "server")
+	certs, err := NewTLSCertificates(config.TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	for _, tt := range []struct {
+		http2 bool
+		proto string
+	}{
+		{http2: true, proto: "HTTP/2.0"},
+		{http2: false, proto: "HTTP/1.1"},
+	} {
+		t.Run(tt.proto, func(t *testing.T) {
+			server := NewServer(handler, "0", WithTLS(certs), WithHTTP2(tt.http2))
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go func() { _ = server.httpServer.Serve(tls.NewListener(listener, server.httpServer.TLSConfig)) }()
+			defer func() { _ = server.httpServer.Close() }()
+
+			transport := &http.Transport{
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // self-signed test certificate
+				ForceAttemptHTTP2: true,
+			}
+			defer transport.CloseIdleConnections()
+			resp, err := (&http.Client{Transport: transport}).Get("https://" + listener.Addr().String())
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, tt.proto, resp.Proto)
+		})
+	}
+}
+
+func TestWithTLS_IgnoresNil(t *testing.T) {
+	server := NewServer(http.NotFoundHandler(), "8080", WithTLS(nil))
+	assert.Nil(t, server.tlsCerts)
+	assert.Nil(t, server.httpServer.TLSConfig)
+	assert.True(t, server.httpServer.Protocols.UnencryptedHTTP2())
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/tracing"
+)
+
+// InitializeTracing starts exporting spans to cfg.Endpoint and returns a
+// shutdown hook that flushes the spans still buffered. Returns nil if
+// tracing is disabled or the exporter cannot be created.
+func InitializeTracing(cfg config.TracingConfig) func(context.Context) {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	shutdown, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to initialize tracing, continuing without it")
+		return nil
+	}
+	log.Info().Str("endpoint", cfg.Endpoint).Float64("sample_ratio", cfg.SampleRatio).Msg("Tracing enabled")
+
+	return func(ctx context.Context) {
+		if err := shutdown(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to flush spans")
+		}
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !integration
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/guttosm/pack-service/config"
+)
+
+func TestInitializeTracing(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, InitializeTracing(config.TracingConfig{Endpoint: "http://localhost:4318"}))
+	})
+
+	t.Run("returns a hook that flushes spans", func(t *testing.T) {
+		provider := otel.GetTracerProvider()
+		t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+		stop := InitializeTracing(config.TracingConfig{Enabled: true, Endpoint: "http://localhost:4318", SampleRatio: 1})
+		require.NotNil(t, stop)
+		stop(context.Background())
+	})
+}
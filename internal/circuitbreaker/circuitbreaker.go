@@ -4,6 +4,7 @@ package circuitbreaker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -17,6 +18,23 @@ var (
 	ErrInvalidState = errors.New("invalid circuit breaker state")
 )
 
+// OpenError is an ErrCircuitOpen telling when the circuit lets a trial call
+// through, for callers that answer with a Retry-After.
+type OpenError struct {
+	// Name is the name of the open circuit breaker.
+	Name string
+	// RetryAfter is how long until the circuit half-opens.
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrCircuitOpen, e.Name)
+}
+
+func (e *OpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
 // State represents the state of the circuit breaker.
 type State int
 
@@ -65,6 +83,9 @@ func DefaultConfig() Config {
 	}
 }
 
+// StateChangeFunc is called after a circuit breaker transitions between states.
+type StateChangeFunc func(name string, from, to State)
+
 // CircuitBreaker implements the circuit breaker pattern.
 type CircuitBreaker struct {
 	config          Config
@@ -72,6 +93,8 @@ type CircuitBreaker struct {
 	failureCount    int
 	successCount    int
 	lastFailureTime time.Time
+	lastTransition  time.Time
+	listeners       []StateChangeFunc
 	mu              sync.RWMutex
 }
 
@@ -83,6 +106,15 @@ func New(config Config) *CircuitBreaker {
 	}
 }
 
+// OnStateChange registers fn to be called after every state transition.
+// Listeners run on the caller's goroutine outside the breaker's lock, so they
+// must return quickly.
+func (cb *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.listeners = append(cb.listeners, fn)
+}
+
 // Execute executes a function with circuit breaker protection.
 // Returns ErrCircuitOpen if the circuit is open.
 func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
@@ -92,29 +124,49 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 		if time.Since(cb.lastFailureTime) >= cb.config.Timeout {
 			cb.state = StateHalfOpen
 			cb.successCount = 0
+			cb.lastTransition = time.Now()
 			log.Info().
 				Str("circuit_breaker", cb.config.Name).
 				Msg("Circuit breaker transitioning to half-open")
+			listeners := cb.listeners
+			cb.mu.Unlock()
+			cb.notify(listeners, StateOpen, StateHalfOpen)
 		} else {
 			cb.mu.Unlock()
 			return ErrCircuitOpen
 		}
+	} else {
+		cb.mu.Unlock()
 	}
-	cb.mu.Unlock()
 
 	// Execute the function
 	err := fn()
 
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
+	from := cb.state
 	if err != nil {
 		cb.onFailure()
-		return err
+	} else {
+		cb.onSuccess()
+	}
+	to := cb.state
+	if from != to {
+		cb.lastTransition = time.Now()
+	}
+	listeners := cb.listeners
+	cb.mu.Unlock()
+
+	if from != to {
+		cb.notify(listeners, from, to)
 	}
+	return err
+}
 
-	cb.onSuccess()
-	return nil
+// notify invokes the registered state change listeners.
+func (cb *CircuitBreaker) notify(listeners []StateChangeFunc, from, to State) {
+	for _, fn := range listeners {
+		fn(cb.config.Name, from, to)
+	}
 }
 
 // onFailure handles a failure.
@@ -168,6 +220,50 @@ func (cb *CircuitBreaker) State() State {
 	return cb.state
 }
 
+// Name returns the name the circuit breaker was configured with.
+func (cb *CircuitBreaker) Name() string {
+	return cb.config.Name
+}
+
+// Reset closes the circuit and clears its counts, for an operator who knows
+// the dependency recovered and does not want to wait for the timeout.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	from := cb.state
+	cb.state = StateClosed
+	cb.failureCount = 0
+	cb.successCount = 0
+	if from != StateClosed {
+		cb.lastTransition = time.Now()
+	}
+	listeners := cb.listeners
+	cb.mu.Unlock()
+
+	if from != StateClosed {
+		log.Info().
+			Str("circuit_breaker", cb.config.Name).
+			Str("from", from.String()).
+			Msg("Circuit breaker reset")
+		cb.notify(listeners, from, StateClosed)
+	}
+}
+
+// RetryAfter returns how long until an open circuit half-opens, or zero when
+// it is not open.
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	if cb.state != StateOpen {
+		return 0
+	}
+	return max(cb.config.Timeout-time.Since(cb.lastFailureTime), 0)
+}
+
+// OpenError returns an *OpenError for the circuit, as it stands now.
+func (cb *CircuitBreaker) OpenError() *OpenError {
+	return &OpenError{Name: cb.config.Name, RetryAfter: cb.RetryAfter()}
+}
+
 // IsOpen returns true if the circuit breaker is open.
 func (cb *CircuitBreaker) IsOpen() bool {
 	cb.mu.RLock()
@@ -182,6 +278,8 @@ type Stats struct {
 	SuccessCount  int
 	LastFailure   time.Time
 	IsHealthy     bool
+	// LastTransition is when the state last changed; zero if it never has.
+	LastTransition time.Time
 }
 
 // GetStats returns current circuit breaker statistics.
@@ -195,5 +293,6 @@ func (cb *CircuitBreaker) GetStats() Stats {
 		SuccessCount:  cb.successCount,
 		LastFailure:   cb.lastFailureTime,
 		IsHealthy:     cb.state == StateClosed,
+		LastTransition: cb.lastTransition,
 	}
 }
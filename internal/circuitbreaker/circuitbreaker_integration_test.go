@@ -41,7 +41,7 @@ func TestCircuitBreakerWithMongoDB_Integration(t *testing.T) {
 		wrappedRepo := repository.NewPackSizesRepositoryWithCircuitBreaker(repo, cb)
 
 		// Successful operations
-		_, err = wrappedRepo.Create(ctx, []int{100, 200}, "test")
+		_, err = wrappedRepo.Create(ctx, []int{100, 200}, nil, "test")
 		require.NoError(t, err)
 
 		active, err := wrappedRepo.GetActive(ctx)
@@ -114,6 +114,46 @@ func TestCircuitBreaker_HalfOpen_Failure(t *testing.T) {
 	assert.Equal(t, StateOpen, cb.State())
 }
 
+func TestCircuitBreaker_OnStateChange(t *testing.T) {
+	cb := New(Config{
+		FailureThreshold: 1,
+		SuccessThreshold: 1,
+		Timeout:          20 * time.Millisecond,
+		Name:             "test",
+	})
+
+	type transition struct {
+		name     string
+		from, to State
+	}
+	var transitions []transition
+	cb.OnStateChange(func(name string, from, to State) {
+		transitions = append(transitions, transition{name, from, to})
+	})
+
+	// Closed -> Open
+	_ = cb.Execute(context.Background(), func() error {
+		return errors.New("error")
+	})
+	// Rejected while open: no transition
+	_ = cb.Execute(context.Background(), func() error {
+		return nil
+	})
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Open -> HalfOpen -> Closed
+	_ = cb.Execute(context.Background(), func() error {
+		return nil
+	})
+
+	assert.Equal(t, []transition{
+		{"test", StateClosed, StateOpen},
+		{"test", StateOpen, StateHalfOpen},
+		{"test", StateHalfOpen, StateClosed},
+	}, transitions)
+}
+
 func TestCircuitBreaker_GetStats(t *testing.T) {
 	cb := New(DefaultConfig())
 
@@ -131,6 +171,39 @@ func TestCircuitBreaker_GetStats(t *testing.T) {
 	assert.Equal(t, 1, stats.FailureCount)
 }
 
+func TestCircuitBreaker_Reset(t *testing.T) {
+	cb := New(Config{
+		FailureThreshold: 1,
+		SuccessThreshold: 1,
+		Timeout:          time.Hour,
+		Name:             "test",
+	})
+	var transitions []State
+	cb.OnStateChange(func(_ string, _, to State) {
+		transitions = append(transitions, to)
+	})
+
+	assert.True(t, cb.GetStats().LastTransition.IsZero())
+	_ = cb.Execute(context.Background(), func() error {
+		return errors.New("error")
+	})
+	opened := cb.GetStats().LastTransition
+	assert.False(t, opened.IsZero())
+
+	cb.Reset()
+
+	stats := cb.GetStats()
+	assert.Equal(t, "closed", stats.State)
+	assert.Zero(t, stats.FailureCount)
+	assert.False(t, stats.LastTransition.Before(opened))
+	assert.Equal(t, []State{StateOpen, StateClosed}, transitions)
+	assert.NoError(t, cb.Execute(context.Background(), func() error { return nil }))
+
+	// Resetting a closed breaker is not a transition
+	cb.Reset()
+	assert.Len(t, transitions, 2)
+}
+
 func TestCircuitBreaker_IsOpen(t *testing.T) {
 	cb := New(Config{
 		FailureThreshold: 1,
@@ -148,6 +221,32 @@ func TestCircuitBreaker_IsOpen(t *testing.T) {
 	assert.True(t, cb.IsOpen())
 }
 
+func TestCircuitBreaker_RetryAfter(t *testing.T) {
+	cb := New(Config{
+		FailureThreshold: 1,
+		SuccessThreshold: 1,
+		Timeout:          time.Hour,
+		Name:             "test",
+	})
+
+	assert.Zero(t, cb.RetryAfter())
+
+	_ = cb.Execute(context.Background(), func() error {
+		return errors.New("error")
+	})
+
+	wait := cb.RetryAfter()
+	assert.Greater(t, wait, 59*time.Minute)
+	assert.LessOrEqual(t, wait, time.Hour)
+
+	var err error = cb.OpenError()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var openErr *OpenError
+	assert.ErrorAs(t, err, &openErr)
+	assert.Equal(t, "test", openErr.Name)
+	assert.LessOrEqual(t, openErr.RetryAfter, wait)
+}
+
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 	assert.Equal(t, 5, config.FailureThreshold)
@@ -0,0 +1,53 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// Registry names the circuit breakers of a process so operators can inspect
+// and reset them, and exports their state as the circuit_breaker_state gauge.
+type Registry struct {
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*CircuitBreaker)}
+}
+
+// Register adds cb under name. Registering a name again replaces the breaker
+// listed, but the gauge keeps following both.
+func (r *Registry) Register(name string, cb *CircuitBreaker) {
+	r.mu.Lock()
+	r.breakers[name] = cb
+	r.mu.Unlock()
+
+	metrics.SetCircuitBreakerState(name, int(cb.State()))
+	cb.OnStateChange(func(_ string, _, to State) {
+		metrics.SetCircuitBreakerState(name, int(to))
+	})
+}
+
+// Get returns the breaker registered under name.
+func (r *Registry) Get(name string) (*CircuitBreaker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cb, ok := r.breakers[name]
+	return cb, ok
+}
+
+// Names returns the registered names, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.breakers))
+	for name := range r.breakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,39 @@
+//go:build !integration
+
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	logs := New(Config{FailureThreshold: 1, SuccessThreshold: 1, Timeout: time.Hour, Name: "logs"})
+	registry.Register("registry_test_logs", logs)
+	registry.Register("registry_test_calculations", New(DefaultConfig()))
+
+	assert.Equal(t, []string{"registry_test_calculations", "registry_test_logs"}, registry.Names())
+	got, ok := registry.Get("registry_test_logs")
+	require.True(t, ok)
+	assert.Same(t, logs, got)
+	_, ok = registry.Get("missing")
+	assert.False(t, ok)
+
+	gauge := metrics.CircuitBreakerState.WithLabelValues("registry_test_logs")
+	assert.Equal(t, float64(StateClosed), testutil.ToFloat64(gauge))
+
+	_ = logs.Execute(context.Background(), func() error { return errors.New("error") })
+	assert.Equal(t, float64(StateOpen), testutil.ToFloat64(gauge))
+
+	logs.Reset()
+	assert.Equal(t, float64(StateClosed), testutil.ToFloat64(gauge))
+}
@@ -0,0 +1,83 @@
+// Package correlation carries the IDs that tie a request to the logs,
+// errors and database operations it causes, here and in the services that
+// called it, so a trace spanning several services can be stitched together.
+package correlation
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxIDLength is the longest correlation ID accepted from a caller.
+const maxIDLength = 128
+
+// IDs identify a request across services.
+type IDs struct {
+	// RequestID identifies the request in this service.
+	RequestID string
+	// CorrelationID is shared by every request of one operation, across
+	// services. It is the caller's, or the RequestID when it sent none.
+	CorrelationID string
+	// TraceID is the W3C trace the request belongs to, continued from the
+	// caller's traceparent header; empty when it has none.
+	TraceID string
+}
+
+type idsKey struct{}
+
+// ContextWithIDs returns a copy of ctx carrying the request and
+// correlation IDs of ids. The trace ID is read from the span in the
+// context instead.
+func ContextWithIDs(ctx context.Context, ids IDs) context.Context {
+	ids.TraceID = ""
+	return context.WithValue(ctx, idsKey{}, ids)
+}
+
+// FromContext returns the IDs of the request ctx belongs to. They are empty
+// outside a request.
+func FromContext(ctx context.Context) IDs {
+	ids, _ := ctx.Value(idsKey{}).(IDs)
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		ids.TraceID = sc.TraceID().String()
+	}
+	return ids
+}
+
+// String formats the non-empty IDs as space-separated key=value pairs, or
+// returns "" when all are empty.
+func (ids IDs) String() string {
+	var b strings.Builder
+	for _, field := range [...]struct{ key, value string }{
+		{"request_id", ids.RequestID},
+		{"correlation_id", ids.CorrelationID},
+		{"trace_id", ids.TraceID},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(field.key)
+		b.WriteByte('=')
+		b.WriteString(field.value)
+	}
+	return b.String()
+}
+
+// ValidID reports whether id, received from a caller, can be used as a
+// correlation ID: at most 128 printable ASCII characters without spaces, so
+// it cannot forge log fields.
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
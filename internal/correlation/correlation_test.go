@@ -0,0 +1,45 @@
+//go:build !integration
+
+package correlation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, IDs{}, FromContext(context.Background()))
+
+	ctx := ContextWithIDs(context.Background(), IDs{RequestID: "req-1", CorrelationID: "corr-1", TraceID: "ignored"})
+	assert.Equal(t, IDs{RequestID: "req-1", CorrelationID: "corr-1"}, FromContext(ctx))
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	assert.NoError(t, err)
+	ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", FromContext(ctx).TraceID)
+}
+
+func TestIDs_String(t *testing.T) {
+	assert.Empty(t, IDs{}.String())
+	assert.Equal(t, "request_id=req-1", IDs{RequestID: "req-1"}.String())
+	assert.Equal(t, "request_id=req-1 correlation_id=corr-1 trace_id=abc",
+		IDs{RequestID: "req-1", CorrelationID: "corr-1", TraceID: "abc"}.String())
+	assert.Equal(t, "correlation_id=corr-1", IDs{CorrelationID: "corr-1"}.String())
+}
+
+func TestValidID(t *testing.T) {
+	assert.True(t, ValidID("order-42_checkout.v2"))
+	assert.True(t, ValidID(strings.Repeat("a", 128)))
+
+	assert.False(t, ValidID(""))
+	assert.False(t, ValidID(strings.Repeat("a", 129)))
+	assert.False(t, ValidID("two words"))
+	assert.False(t, ValidID("line\nbreak"))
+	assert.False(t, ValidID("café"))
+}
@@ -0,0 +1,172 @@
+// Package deprecation finds deprecated API fields in requests and responses
+// and tracks which clients still use them.
+//
+// Fields are marked with a struct tag next to their json tag:
+//
+//	CreatedBy string `json:"created_by" deprecated:"since=2026-10-15;sunset=2027-04-15;use=the authenticated user"`
+//
+// All keys are optional; an empty tag (`deprecated:""`) marks the field without details.
+package deprecation
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagName is the struct tag that marks a field deprecated.
+const TagName = "deprecated"
+
+// WarningCode is the response warning code for deprecated field usage.
+const WarningCode = "deprecated_field"
+
+// Direction tells whether a deprecated field was sent by or returned to a client.
+type Direction string
+
+const (
+	// Sent means the client included the field in a request.
+	Sent Direction = "sent"
+	// Returned means the field was populated in a response to the client.
+	Returned Direction = "returned"
+)
+
+// Field describes a deprecated field.
+type Field struct {
+	// Name is the JSON name of the field.
+	Name string `json:"name"`
+	// Since is when the field was deprecated.
+	Since string `json:"since,omitempty"`
+	// Sunset is when the field is planned to be removed.
+	Sunset string `json:"sunset,omitempty"`
+	// Replacement tells clients what to use instead.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Message returns a human-readable warning for the field.
+func (f Field) Message(direction Direction) string {
+	var b strings.Builder
+	if direction == Returned {
+		b.WriteString("response field ")
+	}
+	b.WriteString(f.Name)
+	b.WriteString(" is deprecated")
+	if f.Since != "" {
+		b.WriteString(" since ")
+		b.WriteString(f.Since)
+	}
+	if f.Sunset != "" {
+		b.WriteString(" and will be removed on ")
+		b.WriteString(f.Sunset)
+	}
+	if f.Replacement != "" {
+		b.WriteString("; use ")
+		b.WriteString(f.Replacement)
+		b.WriteString(" instead")
+	}
+	return b.String()
+}
+
+// taggedField is a deprecated field and its index in the struct.
+type taggedField struct {
+	index int
+	field Field
+}
+
+// fieldCache holds the deprecated fields of each struct type seen so far.
+var fieldCache sync.Map // map[reflect.Type][]taggedField
+
+// Fields returns the deprecated fields declared on v's struct type.
+func Fields(v any) []Field {
+	tagged := fieldsOf(structType(v))
+	fields := make([]Field, len(tagged))
+	for i, t := range tagged {
+		fields[i] = t.field
+	}
+	return fields
+}
+
+// Used returns the deprecated fields of v that hold a non-zero value. v must be
+// a struct or a pointer to one; anything else has no deprecated fields.
+func Used(v any) []Field {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var used []Field
+	for _, t := range fieldsOf(rv.Type()) {
+		if !rv.Field(t.index).IsZero() {
+			used = append(used, t.field)
+		}
+	}
+	return used
+}
+
+// structType returns the struct type behind v, or nil.
+func structType(v any) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// fieldsOf parses and caches the deprecated fields of a struct type.
+func fieldsOf(t reflect.Type) []taggedField {
+	if t == nil {
+		return nil
+	}
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]taggedField)
+	}
+
+	var tagged []taggedField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(TagName)
+		if !ok || !sf.IsExported() {
+			continue
+		}
+		field := parseTag(tag)
+		field.Name = jsonName(sf)
+		tagged = append(tagged, taggedField{index: i, field: field})
+	}
+
+	fieldCache.Store(t, tagged)
+	return tagged
+}
+
+// parseTag parses "since=...;sunset=...;use=...". Unknown keys are ignored.
+func parseTag(tag string) Field {
+	var field Field
+	for _, part := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "since":
+			field.Since = value
+		case "sunset":
+			field.Sunset = value
+		case "use":
+			field.Replacement = value
+		}
+	}
+	return field
+}
+
+// jsonName returns the name a field is serialised under.
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
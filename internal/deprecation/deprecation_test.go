@@ -0,0 +1,58 @@
+//go:build !integration
+
+package deprecation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	Current string `json:"current"`
+	Old     string `json:"old,omitempty" deprecated:"since=2026-01-01;sunset=2026-07-01;use=current"`
+	Legacy  []int  `deprecated:""`
+	hidden  string `deprecated:""`
+}
+
+func TestFields(t *testing.T) {
+	assert.Equal(t, []Field{
+		{Name: "old", Since: "2026-01-01", Sunset: "2026-07-01", Replacement: "current"},
+		{Name: "Legacy"},
+	}, Fields(sample{}))
+	assert.Equal(t, Fields(sample{}), Fields(&sample{}))
+	assert.Empty(t, Fields(42))
+	assert.Empty(t, Fields(nil))
+}
+
+func TestUsed(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{name: "nothing deprecated set", value: sample{Current: "x"}, want: nil},
+		{name: "deprecated string set", value: &sample{Old: "x"}, want: []string{"old"}},
+		{name: "both set", value: sample{Old: "x", Legacy: []int{1}}, want: []string{"old", "Legacy"}},
+		{name: "nil pointer", value: (*sample)(nil), want: nil},
+		{name: "not a struct", value: map[string]string{"old": "x"}, want: nil},
+		{name: "nil", value: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, f := range Used(tt.value) {
+				names = append(names, f.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestField_Message(t *testing.T) {
+	f := Field{Name: "old", Since: "2026-01-01", Sunset: "2026-07-01", Replacement: "current"}
+	assert.Equal(t, "old is deprecated since 2026-01-01 and will be removed on 2026-07-01; use current instead", f.Message(Sent))
+	assert.Equal(t, "response field old is deprecated since 2026-01-01 and will be removed on 2026-07-01; use current instead", f.Message(Returned))
+	assert.Equal(t, "old is deprecated", Field{Name: "old"}.Message(Sent))
+}
@@ -0,0 +1,168 @@
+package deprecation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// maxTrackedUsages bounds the memory used by a Tracker.
+const maxTrackedUsages = 10000
+
+// Usage counts how often one client used one deprecated field on one endpoint.
+type Usage struct {
+	Client    string    `json:"client"`
+	Endpoint  string    `json:"endpoint"`
+	Direction Direction `json:"direction"`
+	Field     Field     `json:"field"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Declared is a deprecated field known to an endpoint, used or not.
+type Declared struct {
+	Endpoint  string    `json:"endpoint"`
+	Direction Direction `json:"direction"`
+	Field     Field     `json:"field"`
+}
+
+// Report lists the declared deprecated fields and who still uses them.
+// A declared field without usage is a candidate for removal.
+type Report struct {
+	Fields []Declared `json:"fields"`
+	Usage  []Usage    `json:"usage"`
+	// Since is when tracking started; usage before it is unknown.
+	Since time.Time `json:"since"`
+}
+
+// usageKey identifies a Usage entry.
+type usageKey struct {
+	client    string
+	endpoint  string
+	direction Direction
+	field     string
+}
+
+// Tracker records deprecated field usage in memory. Counts are per instance
+// and reset on restart; metrics provide the fleet-wide view.
+type Tracker struct {
+	mu       sync.Mutex
+	declared map[usageKey]Declared
+	usage    map[usageKey]*Usage
+	started  time.Time
+	now      func() time.Time
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		declared: make(map[usageKey]Declared),
+		usage:    make(map[usageKey]*Usage),
+		started:  timeutil.Now(),
+		now:      timeutil.Now,
+	}
+}
+
+// Declare registers the deprecated fields of v's type for an endpoint so they
+// appear in reports even before anyone uses them.
+func (t *Tracker) Declare(endpoint string, direction Direction, v any) {
+	fields := Fields(v)
+	if len(fields) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range fields {
+		t.declared[usageKey{endpoint: endpoint, direction: direction, field: f.Name}] = Declared{
+			Endpoint:  endpoint,
+			Direction: direction,
+			Field:     f,
+		}
+	}
+}
+
+// Record counts one use of each field by client on endpoint.
+func (t *Tracker) Record(client, endpoint string, direction Direction, fields []Field) {
+	if len(fields) == 0 {
+		return
+	}
+
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, f := range fields {
+		t.declared[usageKey{endpoint: endpoint, direction: direction, field: f.Name}] = Declared{
+			Endpoint:  endpoint,
+			Direction: direction,
+			Field:     f,
+		}
+
+		key := usageKey{client: client, endpoint: endpoint, direction: direction, field: f.Name}
+		u, ok := t.usage[key]
+		if !ok {
+			if len(t.usage) >= maxTrackedUsages {
+				// Metrics still count it; the report just stops growing
+				continue
+			}
+			u = &Usage{
+				Client:    client,
+				Endpoint:  endpoint,
+				Direction: direction,
+				Field:     f,
+				FirstSeen: now,
+			}
+			t.usage[key] = u
+		}
+		u.Count++
+		u.LastSeen = now
+	}
+}
+
+// Report returns a snapshot of declared fields and usage, sorted by endpoint,
+// field and then client.
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := Report{
+		Fields: make([]Declared, 0, len(t.declared)),
+		Usage:  make([]Usage, 0, len(t.usage)),
+		Since:  t.started,
+	}
+	for _, d := range t.declared {
+		report.Fields = append(report.Fields, d)
+	}
+	for _, u := range t.usage {
+		report.Usage = append(report.Usage, *u)
+	}
+
+	sort.Slice(report.Fields, func(i, j int) bool {
+		a, b := report.Fields[i], report.Fields[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Field.Name != b.Field.Name {
+			return a.Field.Name < b.Field.Name
+		}
+		return a.Direction < b.Direction
+	})
+	sort.Slice(report.Usage, func(i, j int) bool {
+		a, b := report.Usage[i], report.Usage[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Field.Name != b.Field.Name {
+			return a.Field.Name < b.Field.Name
+		}
+		if a.Client != b.Client {
+			return a.Client < b.Client
+		}
+		return a.Direction < b.Direction
+	})
+	return report
+}
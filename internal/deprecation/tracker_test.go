@@ -0,0 +1,52 @@
+//go:build !integration
+
+package deprecation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Report(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	clock := start
+	tracker.now = func() time.Time { return clock }
+
+	tracker.Declare("PUT /api/pack-sizes", Sent, sample{})
+
+	old := Field{Name: "old", Since: "2026-01-01", Sunset: "2026-07-01", Replacement: "current"}
+	tracker.Record("api_key:bbbb", "PUT /api/pack-sizes", Sent, []Field{old})
+	clock = start.Add(time.Minute)
+	tracker.Record("api_key:bbbb", "PUT /api/pack-sizes", Sent, []Field{old})
+	tracker.Record("api_key:aaaa", "PUT /api/pack-sizes", Sent, []Field{old})
+	tracker.Record("api_key:aaaa", "PUT /api/pack-sizes", Sent, nil)
+
+	report := tracker.Report()
+
+	require.Len(t, report.Fields, 2)
+	assert.Equal(t, "Legacy", report.Fields[0].Field.Name)
+	assert.Equal(t, "old", report.Fields[1].Field.Name)
+
+	require.Len(t, report.Usage, 2)
+	assert.Equal(t, "api_key:aaaa", report.Usage[0].Client)
+	assert.Equal(t, int64(1), report.Usage[0].Count)
+	assert.Equal(t, "api_key:bbbb", report.Usage[1].Client)
+	assert.Equal(t, int64(2), report.Usage[1].Count)
+	assert.Equal(t, start, report.Usage[1].FirstSeen)
+	assert.Equal(t, start.Add(time.Minute), report.Usage[1].LastSeen)
+}
+
+func TestTracker_BoundedUsage(t *testing.T) {
+	tracker := NewTracker()
+	field := []Field{{Name: "old"}}
+
+	for i := 0; i < maxTrackedUsages+5; i++ {
+		tracker.Record(time.Duration(i).String(), "GET /x", Sent, field)
+	}
+
+	assert.Len(t, tracker.Report().Usage, maxTrackedUsages)
+}
@@ -0,0 +1,115 @@
+// Package domainerr defines the errors the service layer reports to clients.
+// Each carries a stable machine-readable code, the HTTP status it is answered
+// with and the i18n key of its message, and the codes form the catalogue
+// served to client developers.
+package domainerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+// Error is an error with a stable code clients can branch on. Errors are
+// compared by identity, so errors.Is matches an Error however it is wrapped.
+type Error struct {
+	// Code is sent as the error field of the response. It never changes
+	// once released.
+	Code string
+	// Status is the HTTP status the error is answered with.
+	Status int
+	// MessageKey is the i18n key of the message sent to clients. When empty,
+	// the message of the catalogued error with the same code is sent,
+	// untranslated; the details wrapped around the error are only logged.
+	MessageKey string
+	// Message describes the error in English.
+	Message string
+}
+
+// Error returns the English message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	catalogueMu sync.RWMutex
+	catalogue   = map[string]*Error{}
+)
+
+// New creates an error and adds its code to the catalogue. It panics when
+// the code is already there, as a code must mean one thing.
+func New(code string, status int, messageKey, message string) *Error {
+	e := &Error{Code: code, Status: status, MessageKey: messageKey, Message: message}
+
+	catalogueMu.Lock()
+	defer catalogueMu.Unlock()
+	if _, ok := catalogue[code]; ok {
+		panic(fmt.Sprintf("domainerr: code %q registered twice", code))
+	}
+	catalogue[code] = e
+	return e
+}
+
+// Derive returns a distinct error with the code and status of e and its own
+// message, for errors clients handle the same way as e. It is not added to
+// the catalogue.
+func (e *Error) Derive(messageKey, message string) *Error {
+	return &Error{Code: e.Code, Status: e.Status, MessageKey: messageKey, Message: message}
+}
+
+// From returns the Error err wraps, if any.
+func From(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Lookup returns the error created with New for code, if any.
+func Lookup(code string) (*Error, bool) {
+	catalogueMu.RLock()
+	defer catalogueMu.RUnlock()
+
+	e, ok := catalogue[code]
+	return e, ok
+}
+
+// Catalogue returns the errors created with New, ordered by code.
+func Catalogue() []*Error {
+	catalogueMu.RLock()
+	defer catalogueMu.RUnlock()
+
+	errs := make([]*Error, 0, len(catalogue))
+	for _, e := range catalogue {
+		errs = append(errs, e)
+	}
+	slices.SortFunc(errs, func(a, b *Error) int { return strings.Compare(a.Code, b.Code) })
+	return errs
+}
+
+// Errors answered by the handlers and middleware themselves, whose codes
+// depend only on the status. More specific errors derive from them when
+// clients already rely on the code.
+var (
+	InvalidRequest      = New(dto.ErrCodeInvalidRequest, http.StatusBadRequest, i18n.ErrKeyInvalidRequest, "invalid request")
+	InvalidCursor       = New(dto.ErrCodeInvalidCursor, http.StatusBadRequest, i18n.ErrKeyInvalidCursor, "invalid or expired page cursor")
+	Unauthorized        = New(dto.ErrCodeUnauthorized, http.StatusUnauthorized, i18n.ErrKeyUnauthorized, "missing or invalid authentication")
+	Forbidden           = New(dto.ErrCodeForbidden, http.StatusForbidden, i18n.ErrKeyForbidden, "insufficient permissions")
+	NotFound            = New(dto.ErrCodeNotFound, http.StatusNotFound, i18n.ErrKeyNotFound, "resource not found")
+	Conflict            = New(dto.ErrCodeConflict, http.StatusConflict, i18n.ErrKeyConflict, "conflict with the current state")
+	PreconditionFailed  = New(dto.ErrCodePreconditionFailed, http.StatusPreconditionFailed, "", "the resource changed since it was read")
+	PayloadTooLarge     = New(dto.ErrCodePayloadTooLarge, http.StatusRequestEntityTooLarge, i18n.ErrKeyPayloadTooLarge, "request body too large")
+	Unprocessable       = New(dto.ErrCodeUnprocessable, http.StatusUnprocessableEntity, "", "valid request that cannot be fulfilled")
+	RateLimitExceeded   = New(dto.ErrCodeRateLimit, http.StatusTooManyRequests, i18n.ErrKeyRateLimitExceeded, "rate limit exceeded")
+	Internal            = New(dto.ErrCodeInternal, http.StatusInternalServerError, i18n.ErrKeyInternalError, "internal server error")
+	ServiceUnavailable  = New(dto.ErrCodeServiceUnavailable, http.StatusServiceUnavailable, i18n.ErrKeyServiceBusy, "service too busy, try again later")
+	DatabaseUnavailable = New(dto.ErrCodeDatabaseUnavailable, http.StatusServiceUnavailable, i18n.ErrKeyDatabaseUnavailable, "database unavailable, try again later")
+	Timeout             = New(dto.ErrCodeTimeout, http.StatusGatewayTimeout, i18n.ErrKeyTimeout, "request timed out")
+)
@@ -0,0 +1,63 @@
+package domainerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	err := New("test_widget_not_found", http.StatusNotFound, "error.not_found", "widget not found")
+
+	assert.Equal(t, "widget not found", err.Error())
+	assert.Contains(t, Catalogue(), err)
+	assert.Panics(t, func() { New("test_widget_not_found", http.StatusGone, "", "widget gone") })
+}
+
+func TestDerive(t *testing.T) {
+	err := Unprocessable.Derive("", "widget too heavy")
+
+	assert.Equal(t, Unprocessable.Code, err.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.Status)
+	assert.Equal(t, "widget too heavy", err.Error())
+	assert.NotErrorIs(t, err, Unprocessable)
+	assert.NotContains(t, Catalogue(), err)
+}
+
+func TestFrom(t *testing.T) {
+	wrapped := fmt.Errorf("%w: name is required", Conflict)
+
+	err, ok := From(wrapped)
+	require.True(t, ok)
+	assert.Same(t, Conflict, err)
+	assert.ErrorIs(t, wrapped, Conflict)
+
+	_, ok = From(errors.New("plain"))
+	assert.False(t, ok)
+	_, ok = From(nil)
+	assert.False(t, ok)
+}
+
+func TestCatalogue(t *testing.T) {
+	errs := Catalogue()
+
+	require.NotEmpty(t, errs)
+	for i := 1; i < len(errs); i++ {
+		assert.Less(t, errs[i-1].Code, errs[i].Code)
+	}
+	assert.Contains(t, errs, InvalidRequest)
+	assert.Contains(t, errs, DatabaseUnavailable)
+}
+
+func TestLookup(t *testing.T) {
+	err, ok := Lookup(Unprocessable.Code)
+	require.True(t, ok)
+	assert.Same(t, Unprocessable, err)
+
+	_, ok = Lookup("test_unknown_code")
+	assert.False(t, ok)
+}
@@ -2,7 +2,11 @@
 package dto
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
 )
 
 // LoginRequest represents the JSON request body for the login endpoint.
@@ -40,6 +44,8 @@ type LoginResponse struct {
 	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 	// RefreshToken is the JWT refresh token.
 	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// TokenType is "DPoP" when the tokens are bound to the client's DPoP key, "Bearer" otherwise.
+	TokenType string `json:"token_type" example:"Bearer"`
 	// User contains the authenticated user information.
 	User UserResponse `json:"user"`
 } // @name LoginResponse
@@ -75,6 +81,30 @@ type Claims struct {
 	Email  string             `json:"email"`
 	Name   string             `json:"name"`
 	Roles  []string           `json:"roles"`
+	// Confirmation binds the token to a client key (RFC 9449); nil for bearer tokens.
+	Confirmation *Confirmation `json:"cnf,omitempty"`
+	// TokenVersion is the user's token version when the token was issued.
+	TokenVersion int `json:"tv,omitempty"`
+	// Service is the subject of a service token, one signed by an external
+	// issuer for another service rather than issued here to a user.
+	Service string `json:"-"`
+}
+
+// IsService reports whether the claims are those of a service token, which
+// has no UserID.
+func (c *Claims) IsService() bool {
+	return c.Service != ""
+}
+
+// Confirmation holds the proof-of-possession key a token is bound to.
+type Confirmation struct {
+	// JKT is the SHA-256 JWK thumbprint of the client's DPoP key.
+	JKT string `json:"jkt"`
+}
+
+// IsBound reports whether the token requires a DPoP proof.
+func (c *Claims) IsBound() bool {
+	return c.Confirmation != nil && c.Confirmation.JKT != ""
 }
 
 // UserResponse represents user information in API responses.
@@ -83,8 +113,101 @@ type UserResponse struct {
 	Email string `json:"email" example:"user@example.com"`
 	// Name is the user's full name.
 	Name string `json:"name,omitempty" example:"John Doe"`
+	// PreviousLogin is the login before this one, returned at login so the
+	// user can spot access they did not make.
+	PreviousLogin *model.LoginRecord `json:"previous_login,omitempty"`
 } // @name UserResponse
 
+// UserPage is one page of an admin user listing.
+type UserPage struct {
+	// Users is the page of users, newest first.
+	Users []*model.User `json:"users"`
+	// Total is the number of users matching the filter.
+	Total int64 `json:"total" example:"42"`
+	// Limit is the page size used.
+	Limit int `json:"limit" example:"50"`
+	// Offset is the number of users skipped.
+	Offset int `json:"offset" example:"0"`
+	// NextCursor requests the next page as the cursor parameter; empty on
+	// the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"AZx3q0v1c2Vyc..."`
+} // @name UserPage
+
+// StaleUserPage is one page of the stale account report: active users
+// without a login since LastLoginBefore.
+type StaleUserPage struct {
+	UserPage
+	// InactiveDays is how many days without a login make an account stale.
+	InactiveDays int `json:"inactive_days" example:"90"`
+	// LastLoginBefore is the cutoff: the users listed last logged in before
+	// it, or never did and were created before it.
+	LastLoginBefore time.Time `json:"last_login_before" example:"2026-01-28T10:00:00Z"`
+} // @name StaleUserPage
+
+// EffectivePermissions lists what a user's roles allow, and why.
+// @Description Permissions granted to a user, with the roles granting each
+type EffectivePermissions struct {
+	UserID string `json:"user_id" example:"507f1f77bcf86cd799439011"`
+	Email  string `json:"email" example:"user@example.com"`
+	// Active is false for deactivated users, who cannot log in whatever
+	// they are granted.
+	Active bool `json:"active" example:"true"`
+	// Roles are the roles assigned to the user. Access tokens carry the
+	// roles of when they were issued, so a change reaches the user at their
+	// next token refresh.
+	Roles []EffectiveRole `json:"roles"`
+	// Permissions are granted by at least one role, sorted by resource and
+	// action.
+	Permissions []EffectivePermission `json:"permissions"`
+	// MissingRoleIDs are roles assigned to the user that no longer exist;
+	// they grant nothing.
+	MissingRoleIDs []string `json:"missing_role_ids,omitempty"`
+	// MissingPermissionIDs are permissions listed by the user's roles that
+	// no longer exist; they grant nothing.
+	MissingPermissionIDs []string `json:"missing_permission_ids,omitempty"`
+} // @name EffectivePermissions
+
+// EffectiveRole is a role assigned to a user.
+type EffectiveRole struct {
+	ID   string `json:"id" example:"507f1f77bcf86cd799439012"`
+	Name string `json:"name" example:"admin"`
+	// Active is false for deactivated roles. They can no longer be
+	// assigned, but still grant their permissions to users who have them.
+	Active bool `json:"active" example:"true"`
+} // @name EffectiveRole
+
+// EffectivePermission is a permission granted to a user.
+type EffectivePermission struct {
+	ID       string `json:"id" example:"507f1f77bcf86cd799439013"`
+	Name     string `json:"name" example:"packs:write"`
+	Resource string `json:"resource" example:"packs"`
+	Action   string `json:"action" example:"write"`
+	// GrantedBy names the user's roles granting the permission.
+	GrantedBy []string `json:"granted_by" example:"admin"`
+} // @name EffectivePermission
+
+// ImportUsersResult reports a completed bulk user import.
+type ImportUsersResult struct {
+	// Imported is the number of users created.
+	Imported int `json:"imported" example:"2"`
+	// Users lists the users created, in the order they were given.
+	Users []ImportedUser `json:"users"`
+} // @name ImportUsersResult
+
+// ImportedUser is a user created by a bulk import.
+type ImportedUser struct {
+	// Row is the position of the user in the import, counting from 1
+	// without the CSV header.
+	Row int `json:"row" example:"1"`
+	// ID is the new user's ID.
+	ID string `json:"id" example:"507f1f77bcf86cd799439011"`
+	// Email is the new user's email address.
+	Email string `json:"email" example:"user@example.com"`
+	// TemporaryPassword is the generated password of an invited user. It is
+	// not stored in plain text and cannot be retrieved again.
+	TemporaryPassword string `json:"temporary_password,omitempty" example:"q3Vx8kT2mZpL0aRw"`
+} // @name ImportedUser
+
 // Validate performs custom validation on the login request.
 func (r *LoginRequest) Validate() error {
 	if r.Email == "" {
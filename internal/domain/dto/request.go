@@ -4,24 +4,230 @@
 // providing validation and serialization for API communication.
 package dto
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/fixedpoint"
+)
+
 // CalculatePacksRequest represents the JSON request body for the pack calculation endpoint.
 //
 // The ItemsOrdered field is required and must be a positive integer.
 // PackSizes is optional - if not provided, uses server-configured pack sizes.
+// Preset is optional and mutually exclusive with PackSizes.
+// MaxPacks, MaxOverageItems and MaxOveragePercent optionally constrain the result.
+// Metadata is optional and echoed back, stored and audited unchanged.
+// MaxComputeMs optionally trades optimality for latency.
+// Optimize and PackCosts optionally choose the cheapest combination.
 // Validation is performed using gin's binding tags.
 //
 // @Description Request to calculate optimal pack combination for an order
 // @Example {"items_ordered": 251}
 // @Example {"items_ordered": 251, "pack_sizes": [23, 31, 53]}
+// @Example {"items_ordered": 251, "preset": "warehouse-a"}
+// @Example {"items_ordered": 12001, "max_packs": 3, "max_overage_percent": 25}
+// @Example {"items_ordered": 251, "metadata": {"order_id": "A-1001", "channel": "web"}}
+// @Example {"items_ordered": 500000, "pack_sizes": [23, 31, 53], "max_compute_ms": 50}
+// @Example {"items_ordered": 251, "pack_sizes": [250, 500], "optimize": "min_cost", "pack_costs": {"250": 3, "500": 4.5}}
 type CalculatePacksRequest struct {
 	// ItemsOrdered is the number of items the customer wants to order.
-	// Must be greater than 0.
+	// Must be greater than 0 and at most the server's maximum
+	// (DefaultMaxItemsOrdered unless configured otherwise).
 	ItemsOrdered int `json:"items_ordered" binding:"required,gt=0" example:"251" minimum:"1"`
 	// PackSizes is an optional list of pack sizes to use for calculation.
-	// If not provided, uses server-configured pack sizes.
-	PackSizes []int `json:"pack_sizes" example:"23,31,53"`
+	// If not provided, uses server-configured pack sizes. At most
+	// MaxPackSizes sizes, each at most MaxPackSize.
+	PackSizes []int `json:"pack_sizes" example:"23,31,53" maxItems:"100"`
+	// Preset is the name of a saved preset whose pack sizes to use.
+	// Cannot be combined with PackSizes.
+	Preset string `json:"preset,omitempty" example:"warehouse-a"`
+	// MaxPacks optionally limits the total number of packs. Must be at least 1.
+	MaxPacks *int `json:"max_packs,omitempty" example:"3" minimum:"1"`
+	// MaxOverageItems optionally limits how many items may be shipped beyond the order.
+	MaxOverageItems *int `json:"max_overage_items,omitempty" example:"100" minimum:"0"`
+	// MaxOveragePercent optionally limits the overage as a percentage of the order.
+	MaxOveragePercent *float64 `json:"max_overage_percent,omitempty" example:"10" minimum:"0"`
+	// Metadata is an optional set of caller references, such as an order ID or
+	// sales channel, returned with the result and kept with the calculation.
+	// At most MaxMetadataKeys keys and MaxMetadataBytes in total.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// MaxComputeMs optionally limits how long the calculation may take, capped
+	// by the server. When it runs out the result is a fast greedy combination
+	// marked approximate instead of the optimal one. Must be at least 1.
+	MaxComputeMs *int `json:"max_compute_ms,omitempty" example:"50" minimum:"1"`
+	// Explain asks for the result's explanation: the alternative
+	// combinations it was preferred to and why.
+	Explain bool `json:"explain,omitempty" example:"false"`
+	// Optimize is the optimization mode: min_items (the default) ships the
+	// fewest items, then uses the fewest packs; min_cost uses the cheapest
+	// combination by the pack costs. min_cost cannot be combined with
+	// MaxPacks.
+	Optimize string `json:"optimize,omitempty" enums:"min_items,min_cost" example:"min_cost"`
+	// PackCosts is the cost of one pack of each size, keyed by size. When
+	// empty, the costs of the stored pack sizes are used with them. Results
+	// are priced when every size used has a cost; min_cost needs a cost for
+	// every size. Costs are rounded to 4 decimal places.
+	PackCosts map[int]float64 `json:"pack_costs,omitempty"`
 } // @name CalculatePacksRequest
 
+// Limits on the metadata of a calculate request.
+const (
+	// MaxMetadataKeys is the most keys metadata may have.
+	MaxMetadataKeys = 16
+	// MaxMetadataKeyLength is the longest allowed metadata key.
+	MaxMetadataKeyLength = 64
+	// MaxMetadataBytes caps the combined length of metadata keys and values.
+	MaxMetadataBytes = 1024
+)
+
+// Limits on the size of a calculate request. The calculation allocates memory
+// in proportion to items_ordered plus a pack size, so both are bounded.
+const (
+	// DefaultMaxItemsOrdered is the largest order calculated unless the
+	// server is configured otherwise.
+	DefaultMaxItemsOrdered = 10_000_000
+	// MaxPackSize is the largest pack size a request may list.
+	MaxPackSize = 10_000_000
+	// MaxPackSizes is the most pack sizes a request may list.
+	MaxPackSizes = 100
+	// MaxPackCost is the largest cost of one pack.
+	MaxPackCost = 1_000_000
+)
+
+// Constraints returns the result constraints set on the request.
+func (r *CalculatePacksRequest) Constraints() model.PackConstraints {
+	return model.PackConstraints{
+		MaxPacks:          r.MaxPacks,
+		MaxOverageItems:   r.MaxOverageItems,
+		MaxOveragePercent: r.MaxOveragePercent,
+		Optimize:          r.Optimize,
+		PackCosts:         r.PackCosts,
+	}
+}
+
+// ApplyPreset fills the constraints, optimization strategy and pack costs
+// the request leaves unset from those saved with preset. Validate the
+// request again afterwards: the combination may not be valid.
+func (r *CalculatePacksRequest) ApplyPreset(preset *model.Preset) {
+	if preset.Constraints == nil {
+		return
+	}
+	r.applySettings(PresetSettings{
+		MaxPacks:          preset.Constraints.MaxPacks,
+		MaxOverageItems:   preset.Constraints.MaxOverageItems,
+		MaxOveragePercent: preset.Constraints.MaxOveragePercent,
+		Optimize:          preset.Constraints.Optimize,
+		PackCosts:         preset.Constraints.PackCosts,
+	})
+}
+
+// applySettings sets the fields of s the request leaves unset.
+func (r *CalculatePacksRequest) applySettings(s PresetSettings) {
+	if r.MaxPacks == nil {
+		r.MaxPacks = s.MaxPacks
+	}
+	if r.MaxOverageItems == nil {
+		r.MaxOverageItems = s.MaxOverageItems
+	}
+	if r.MaxOveragePercent == nil {
+		r.MaxOveragePercent = s.MaxOveragePercent
+	}
+	if r.Optimize == "" {
+		r.Optimize = s.Optimize
+	}
+	if len(r.PackCosts) == 0 {
+		r.PackCosts = s.PackCosts
+	}
+}
+
+// MaxBatchItems caps the number of calculations in one batch request.
+const MaxBatchItems = 10000
+
+// BatchCalculateRequest represents the JSON request body for the batch calculation endpoint.
+//
+// Each item is validated and calculated on its own: an invalid item yields an
+// error record in the results instead of failing the whole batch.
+//
+// @Description Request to calculate several orders at once
+// @Example {"items": [{"items_ordered": 251}, {"items_ordered": 12001, "pack_sizes": [250, 500]}]}
+type BatchCalculateRequest struct {
+	// Items are the orders to calculate, at most MaxBatchItems.
+	Items []CalculatePacksRequest `json:"items" binding:"required,min=1"`
+} // @name BatchCalculateRequest
+
+// Decimal is a non-negative decimal number kept as written, such as "2.5",
+// so it is never rounded through floating point. It is read from a JSON
+// number or string and written as a JSON number.
+type Decimal string
+
+// UnmarshalJSON reads a JSON number or string.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*d = Decimal(text)
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*d = Decimal(number)
+	return nil
+}
+
+// MarshalJSON writes the decimal as a JSON number, or as a string when it is
+// not a valid number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if jsonNumber.MatchString(string(d)) {
+		return []byte(d), nil
+	}
+	return json.Marshal(string(d))
+}
+
+// jsonNumber matches a JSON number literal.
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// CalculateQuantityRequest represents the JSON request body for the decimal
+// quantity calculation endpoint, for products packed by weight or volume.
+//
+// Quantity and PackSizes are decimals, such as 12.5 (kg) and bags of 2.5 and
+// 5, with at most the server's decimal precision of decimal places. They are
+// calculated exactly, in fixed-point units of that precision.
+//
+// @Description Request to calculate the packs for an order of a decimal quantity
+// @Example {"quantity": 12.5, "pack_sizes": [2.5, 5]}
+// @Example {"quantity": "7.25", "pack_sizes": ["0.75", "1.5"], "max_packs": 8}
+type CalculateQuantityRequest struct {
+	// Quantity is the ordered quantity, greater than 0.
+	Quantity Decimal `json:"quantity" binding:"required" swaggertype:"number" example:"12.5"`
+	// PackSizes are the quantities packs hold, each greater than 0. At most
+	// MaxPackSizes sizes.
+	PackSizes []Decimal `json:"pack_sizes" binding:"required,min=1" swaggertype:"array,number" example:"2.5,5" maxItems:"100"`
+	// MaxPacks optionally limits the total number of packs. Must be at least 1.
+	MaxPacks *int `json:"max_packs,omitempty" example:"3" minimum:"1"`
+	// MaxOveragePercent optionally limits the quantity shipped beyond the
+	// order, as a percentage of the order.
+	MaxOveragePercent *float64 `json:"max_overage_percent,omitempty" example:"10" minimum:"0"`
+} // @name CalculateQuantityRequest
+
+// Constraints returns the result constraints set on the request.
+func (r *CalculateQuantityRequest) Constraints() model.PackConstraints {
+	return model.PackConstraints{
+		MaxPacks:          r.MaxPacks,
+		MaxOveragePercent: r.MaxOveragePercent,
+	}
+}
+
 // ValidationError represents a field validation error.
 type ValidationError struct {
 	Field   string
@@ -34,6 +240,64 @@ var (
 		Field:   "items_ordered",
 		Message: "must be a positive integer",
 	}
+
+	// ErrInvalidPackSizes is returned when pack_sizes has too many sizes or
+	// a size that is too large.
+	ErrInvalidPackSizes = &ValidationError{
+		Field:   "pack_sizes",
+		Message: "must have at most 100 sizes, each at most 10000000",
+	}
+
+	// ErrPresetWithPackSizes is returned when both preset and pack_sizes are set.
+	ErrPresetWithPackSizes = &ValidationError{
+		Field:   "preset",
+		Message: "cannot be combined with pack_sizes",
+	}
+
+	// ErrInvalidMaxPacks is returned when max_packs is less than 1.
+	ErrInvalidMaxPacks = &ValidationError{
+		Field:   "max_packs",
+		Message: "must be a positive integer",
+	}
+
+	// ErrInvalidMaxOverageItems is returned when max_overage_items is negative.
+	ErrInvalidMaxOverageItems = &ValidationError{
+		Field:   "max_overage_items",
+		Message: "must not be negative",
+	}
+
+	// ErrInvalidMaxOveragePercent is returned when max_overage_percent is negative.
+	ErrInvalidMaxOveragePercent = &ValidationError{
+		Field:   "max_overage_percent",
+		Message: "must not be negative",
+	}
+
+	// ErrInvalidMaxComputeMs is returned when max_compute_ms is less than 1.
+	ErrInvalidMaxComputeMs = &ValidationError{
+		Field:   "max_compute_ms",
+		Message: "must be a positive integer",
+	}
+
+	// ErrInvalidMetadata is returned when metadata exceeds its limits or has
+	// a key that cannot be stored.
+	ErrInvalidMetadata = &ValidationError{
+		Field:   "metadata",
+		Message: "must have at most 16 keys of 1-64 characters, not starting with '$' or containing '.', and at most 1024 bytes in total",
+	}
+
+	// ErrInvalidOptimize is returned when optimize is not a known mode, or
+	// is min_cost together with max_packs.
+	ErrInvalidOptimize = &ValidationError{
+		Field:   "optimize",
+		Message: "must be min_items or min_cost, and min_cost cannot be combined with max_packs",
+	}
+
+	// ErrInvalidPackCosts is returned when pack_costs has too many sizes, a
+	// size that is not a valid pack size, or a cost out of range.
+	ErrInvalidPackCosts = &ValidationError{
+		Field:   "pack_costs",
+		Message: "must have at most 100 sizes, each from 1 to 10000000, with costs from 0 to 1000000",
+	}
 )
 
 // Validate performs custom validation on the request.
@@ -42,9 +306,165 @@ func (r *CalculatePacksRequest) Validate() error {
 	if r.ItemsOrdered <= 0 {
 		return ErrInvalidItemsOrdered
 	}
+	if !validPackSizes(r.PackSizes) {
+		return ErrInvalidPackSizes
+	}
+	if r.Preset != "" && len(r.PackSizes) > 0 {
+		return ErrPresetWithPackSizes
+	}
+	if r.MaxPacks != nil && *r.MaxPacks < 1 {
+		return ErrInvalidMaxPacks
+	}
+	if r.MaxOverageItems != nil && *r.MaxOverageItems < 0 {
+		return ErrInvalidMaxOverageItems
+	}
+	if r.MaxOveragePercent != nil && *r.MaxOveragePercent < 0 {
+		return ErrInvalidMaxOveragePercent
+	}
+	if r.MaxComputeMs != nil && *r.MaxComputeMs < 1 {
+		return ErrInvalidMaxComputeMs
+	}
+	if !validMetadata(r.Metadata) {
+		return ErrInvalidMetadata
+	}
+	switch r.Optimize {
+	case "", model.OptimizeMinItems:
+	case model.OptimizeMinCost:
+		if r.MaxPacks != nil {
+			return ErrInvalidOptimize
+		}
+	default:
+		return ErrInvalidOptimize
+	}
+	if !ValidPackCosts(r.PackCosts) {
+		return ErrInvalidPackCosts
+	}
+	return nil
+}
+
+var (
+	// ErrInvalidQuantity is returned when quantity is not a positive decimal
+	// of the server's precision.
+	ErrInvalidQuantity = &ValidationError{
+		Field:   "quantity",
+		Message: "must be a positive number with no more decimal places than the precision",
+	}
+
+	// ErrInvalidPackQuantities is returned when pack_sizes of a quantity
+	// request is empty, too long, or has a size that is not a positive
+	// decimal of the server's precision.
+	ErrInvalidPackQuantities = &ValidationError{
+		Field:   "pack_sizes",
+		Message: "must have 1 to 100 positive numbers with no more decimal places than the precision",
+	}
+)
+
+// Validate performs custom validation on the request. Decimals are checked
+// against the server's precision by Units.
+func (r *CalculateQuantityRequest) Validate() error {
+	if r.Quantity == "" {
+		return ErrInvalidQuantity
+	}
+	if len(r.PackSizes) == 0 || len(r.PackSizes) > MaxPackSizes {
+		return ErrInvalidPackQuantities
+	}
+	if r.MaxPacks != nil && *r.MaxPacks < 1 {
+		return ErrInvalidMaxPacks
+	}
+	if r.MaxOveragePercent != nil && *r.MaxOveragePercent < 0 {
+		return ErrInvalidMaxOveragePercent
+	}
+	return nil
+}
+
+// Units returns the quantity and pack sizes as fixed-point units of
+// precision decimal places, such as 2500 for 2.5 at precision 3.
+func (r *CalculateQuantityRequest) Units(precision int) (int, []int, error) {
+	quantity, err := fixedpoint.Parse(string(r.Quantity), precision)
+	if err != nil || quantity <= 0 || quantity > math.MaxInt {
+		return 0, nil, ErrInvalidQuantity
+	}
+	sizes := make([]int, len(r.PackSizes))
+	for i, size := range r.PackSizes {
+		units, err := fixedpoint.Parse(string(size), precision)
+		if err != nil || units <= 0 || units > math.MaxInt {
+			return 0, nil, ErrInvalidPackQuantities
+		}
+		sizes[i] = int(units)
+	}
+	return int(quantity), sizes, nil
+}
+
+// ItemsOrderedTooLargeError is returned when items_ordered is above the
+// server's maximum. The request is well-formed, but the order is too large to
+// calculate.
+type ItemsOrderedTooLargeError struct {
+	// Max is the largest order allowed.
+	Max int
+}
+
+// Error returns the error message for ItemsOrderedTooLargeError.
+func (e *ItemsOrderedTooLargeError) Error() string {
+	return fmt.Sprintf("items_ordered: must be at most %d", e.Max)
+}
+
+// CheckItemsOrdered returns an *ItemsOrderedTooLargeError when the order is
+// above maxItems. A maxItems of zero means DefaultMaxItemsOrdered.
+func (r *CalculatePacksRequest) CheckItemsOrdered(maxItems int) error {
+	if maxItems <= 0 {
+		maxItems = DefaultMaxItemsOrdered
+	}
+	if r.ItemsOrdered > maxItems {
+		return &ItemsOrderedTooLargeError{Max: maxItems}
+	}
 	return nil
 }
 
+// validPackSizes checks pack sizes against their limits. Non-positive sizes
+// are ignored by the calculation, so only their count is limited.
+func validPackSizes(sizes []int) bool {
+	if len(sizes) > MaxPackSizes {
+		return false
+	}
+	for _, size := range sizes {
+		if size > MaxPackSize {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidPackCosts reports whether costs are within the limits of pack costs:
+// at most MaxPackSizes sizes, each a valid pack size, with costs from 0 to
+// MaxPackCost.
+func ValidPackCosts(costs map[int]float64) bool {
+	if len(costs) > MaxPackSizes {
+		return false
+	}
+	for size, cost := range costs {
+		if size < 1 || size > MaxPackSize || !(cost >= 0 && cost <= MaxPackCost) {
+			return false
+		}
+	}
+	return true
+}
+
+// validMetadata checks metadata against its limits. Keys are stored as
+// MongoDB field names, so they cannot start with '$' or contain '.'.
+func validMetadata(metadata map[string]string) bool {
+	if len(metadata) > MaxMetadataKeys {
+		return false
+	}
+	size := 0
+	for key, value := range metadata {
+		if key == "" || len(key) > MaxMetadataKeyLength || key[0] == '$' || strings.Contains(key, ".") {
+			return false
+		}
+		size += len(key) + len(value)
+	}
+	return size <= MaxMetadataBytes
+}
+
 // Error returns the error message for ValidationError.
 func (e *ValidationError) Error() string {
 	return e.Field + ": " + e.Message
@@ -54,6 +474,317 @@ func (e *ValidationError) Error() string {
 type UpdatePackSizesRequest struct {
 	// Sizes is the list of pack sizes to use.
 	Sizes []int `json:"sizes" binding:"required,min=1"`
+	// Costs is the optional cost of one pack of each size, keyed by size,
+	// used by calculations optimizing for cost.
+	Costs map[int]float64 `json:"costs,omitempty"`
 	// CreatedBy is the identifier of who created this configuration.
-	CreatedBy string `json:"created_by,omitempty"`
+	// Deprecated: authenticated requests record the caller automatically.
+	CreatedBy string `json:"created_by,omitempty" deprecated:"since=2026-10-15;use=an authenticated request, which records the caller"`
 } // @name UpdatePackSizesRequest
+
+// ValidCosts reports whether r.Costs is within the limits of pack costs and
+// only has costs of r.Sizes.
+func (r *UpdatePackSizesRequest) ValidCosts() bool {
+	if !ValidPackCosts(r.Costs) {
+		return false
+	}
+	for size := range r.Costs {
+		if !slices.Contains(r.Sizes, size) {
+			return false
+		}
+	}
+	return true
+}
+
+// StartPackSizesMigrationRequest represents the JSON request body for staging
+// a pack size configuration.
+type StartPackSizesMigrationRequest struct {
+	// Sizes are the staged pack sizes.
+	Sizes []int `json:"sizes" binding:"required,min=1,dive,gt=0" example:"250,500,750,1000"`
+	// ShadowMinutes is how long live traffic is compared before the
+	// migration can be promoted. Defaults to 15.
+	ShadowMinutes int `json:"shadow_minutes,omitempty" binding:"omitempty,min=1,max=1440" example:"30"`
+} // @name StartPackSizesMigrationRequest
+
+// MaxSampleOrders caps the sample orders of a pack size validation.
+const MaxSampleOrders = 100
+
+// ValidatePackSizesRequest represents the JSON request body for checking pack
+// sizes before saving them.
+type ValidatePackSizesRequest struct {
+	// Sizes are the pack sizes to check.
+	Sizes []int `json:"sizes" binding:"required,min=1" example:"250,500,750,1000"`
+	// SampleOrders are order quantities calculated with both the active and
+	// the proposed sizes, at most MaxSampleOrders.
+	SampleOrders []int `json:"sample_orders,omitempty" binding:"omitempty,max=100,dive,gt=0" example:"251,751,12001"`
+} // @name ValidatePackSizesRequest
+
+// SimulatePackSizesRequest represents the JSON request body for comparing
+// two pack size configurations over a set of orders.
+//
+// @Description Two pack size sets and the orders to compare them on
+// @Example {"sizes_a": [250, 500, 1000], "sizes_b": [250, 500, 800], "orders": [251, 751, 12001]}
+// @Example {"sizes_a": [250, 500, 1000], "sizes_b": [250, 500, 800], "distribution": [{"items_ordered": 751, "count": 120}]}
+type SimulatePackSizesRequest struct {
+	SizesA []int `json:"sizes_a" binding:"required,min=1" example:"250,500,1000"`
+	SizesB []int `json:"sizes_b" binding:"required,min=1" example:"250,500,800"`
+	// Orders are order quantities, each counted once.
+	Orders []int `json:"orders,omitempty" binding:"omitempty,dive,gt=0" example:"251,751,12001"`
+	// Distribution are order quantities with how often they occur. Orders
+	// and Distribution together list at most MaxBatchItems quantities.
+	Distribution []SimulationOrder `json:"distribution,omitempty" binding:"omitempty,dive"`
+} // @name SimulatePackSizesRequest
+
+// MaxSimulationOrderCount caps the count of one quantity of a simulation
+// distribution, so the weighted totals cannot overflow.
+const MaxSimulationOrderCount = 1_000_000
+
+// SimulationOrder is an order quantity and how often it occurs.
+type SimulationOrder struct {
+	ItemsOrdered int   `json:"items_ordered" binding:"required,gt=0" example:"751"`
+	Count        int64 `json:"count" binding:"required,gt=0,max=1000000" example:"120"`
+} // @name SimulationOrder
+
+// ErrInvalidSimulationOrders is returned when a simulation has no orders or
+// more than MaxBatchItems quantities.
+var ErrInvalidSimulationOrders = &ValidationError{
+	Field:   "orders",
+	Message: "orders and distribution must list between 1 and 10000 quantities",
+}
+
+// SimulationOrders returns the orders and the distribution of the request as
+// one distribution, adding up the counts of repeated quantities.
+func (r *SimulatePackSizesRequest) SimulationOrders() ([]SimulationOrder, error) {
+	total := len(r.Orders) + len(r.Distribution)
+	if total == 0 || total > MaxBatchItems {
+		return nil, ErrInvalidSimulationOrders
+	}
+
+	orders := make([]SimulationOrder, 0, total)
+	index := make(map[int]int, total)
+	add := func(items int, count int64) {
+		if i, ok := index[items]; ok {
+			orders[i].Count += count
+			return
+		}
+		index[items] = len(orders)
+		orders = append(orders, SimulationOrder{ItemsOrdered: items, Count: count})
+	}
+	for _, items := range r.Orders {
+		add(items, 1)
+	}
+	for _, order := range r.Distribution {
+		add(order.ItemsOrdered, order.Count)
+	}
+	return orders, nil
+}
+
+// PresetRequest represents the JSON request body for creating a calculation preset.
+type PresetRequest struct {
+	// Name identifies the preset in calculate requests. Unique per user.
+	Name string `json:"name" binding:"required" example:"warehouse-a"`
+	// Description is an optional human-readable note.
+	Description string `json:"description,omitempty" example:"Pack sizes for warehouse A"`
+	// PackSizes is the list of pack sizes the preset calculates with.
+	PackSizes []int `json:"pack_sizes" binding:"required,min=1" example:"23,31,53"`
+	PresetSettings
+} // @name PresetRequest
+
+// UpdatePresetRequest represents the JSON request body for updating a calculation preset.
+type UpdatePresetRequest struct {
+	// Description is an optional human-readable note.
+	Description string `json:"description,omitempty" example:"Pack sizes for warehouse A"`
+	// PackSizes is the list of pack sizes the preset calculates with.
+	PackSizes []int `json:"pack_sizes" binding:"required,min=1" example:"23,31,53"`
+	PresetSettings
+} // @name UpdatePresetRequest
+
+// PresetSettings are the optional constraints, optimization strategy and
+// pack costs of a preset, with the meaning and limits they have on a
+// calculate request.
+type PresetSettings struct {
+	MaxPacks          *int            `json:"max_packs,omitempty" example:"3"`
+	MaxOverageItems   *int            `json:"max_overage_items,omitempty" example:"100"`
+	MaxOveragePercent *float64        `json:"max_overage_percent,omitempty" example:"25"`
+	Optimize          string          `json:"optimize,omitempty" enums:"min_items,min_cost" example:"min_items"`
+	PackCosts         map[int]float64 `json:"pack_costs,omitempty"`
+}
+
+// Validate checks the settings against the limits of a calculate request.
+func (s *PresetSettings) Validate() error {
+	req := CalculatePacksRequest{ItemsOrdered: 1}
+	req.applySettings(*s)
+	return req.Validate()
+}
+
+// Constraints returns the settings as stored with the preset, or nil when
+// none is set.
+func (s *PresetSettings) Constraints() *model.PackConstraints {
+	constraints := model.PackConstraints{
+		MaxPacks:          s.MaxPacks,
+		MaxOverageItems:   s.MaxOverageItems,
+		MaxOveragePercent: s.MaxOveragePercent,
+		Optimize:          s.Optimize,
+		PackCosts:         s.PackCosts,
+	}
+	if constraints.IsZero() && constraints.Optimize == "" {
+		return nil
+	}
+	return &constraints
+}
+
+// MessageOverrideRequest represents the JSON request body for replacing a
+// catalog message for a tenant.
+type MessageOverrideRequest struct {
+	// Message replaces the catalog message. It must keep its placeholders, such as %d, in order.
+	Message string `json:"message" binding:"required" example:"Your Acme plan allows 100 requests a minute, please slow down"`
+} // @name MessageOverrideRequest
+
+// IPRuleRequest represents the JSON request body for adding an IP rule.
+type IPRuleRequest struct {
+	// CIDR is the range the rule applies to; a single IP address is taken as a one-address range.
+	CIDR string `json:"cidr" binding:"required" example:"203.0.113.0/24"`
+	// Action is "allow" or "deny".
+	Action string `json:"action" binding:"required,oneof=allow deny" example:"deny"`
+	// Reason is an optional note on why the rule was added.
+	Reason string `json:"reason,omitempty" example:"credential stuffing"`
+	// ExpiresAt is when the rule stops applying; omit it to keep the rule until deleted.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-02T15:04:05Z"`
+} // @name IPRuleRequest
+
+// UpdateUserRequest represents the JSON request body for an admin updating a user.
+// Omitted fields are left unchanged.
+type UpdateUserRequest struct {
+	// Email is the user's new email address.
+	Email *string `json:"email,omitempty" example:"user@example.com"`
+	// Username is the user's new unique username (3-30 characters).
+	Username *string `json:"username,omitempty" example:"johndoe"`
+	// Name is the user's new full name.
+	Name *string `json:"name,omitempty" example:"John Doe"`
+	// Roles replaces the user's roles, given by role ID or name.
+	Roles *[]string `json:"roles,omitempty" example:"user,admin"`
+} // @name UpdateUserRequest
+
+// UpdateProfileRequest represents the JSON request body for users updating
+// their own account. Omitted fields are left unchanged.
+type UpdateProfileRequest struct {
+	// Name is the user's new full name.
+	Name *string `json:"name,omitempty" example:"John Doe"`
+	// Username is the user's new unique username (3-30 characters).
+	Username *string `json:"username,omitempty" example:"johndoe"`
+	// CurrentPassword is the user's password, required to set NewPassword.
+	CurrentPassword string `json:"current_password,omitempty" example:"password123"`
+	// NewPassword is the user's new password (minimum 6 characters).
+	NewPassword *string `json:"new_password,omitempty" example:"new-password456"`
+} // @name UpdateProfileRequest
+
+// ImportUsersRequest represents the JSON request body of a bulk user import.
+type ImportUsersRequest struct {
+	Users []ImportUser `json:"users" binding:"required"`
+} // @name ImportUsersRequest
+
+// ImportUser is one user of a bulk import.
+type ImportUser struct {
+	// Email is the user's email address.
+	Email string `json:"email" example:"user@example.com"`
+	// Username is the user's unique username (3-30 characters, optional).
+	Username string `json:"username,omitempty" example:"johndoe"`
+	// Name is the user's full name.
+	Name string `json:"name,omitempty" example:"John Doe"`
+	// Roles are the user's roles, given by ID or name; "user" when empty.
+	Roles []string `json:"roles,omitempty" example:"user"`
+	// Password is the initial password (minimum 6 characters). Required
+	// unless Invite is set.
+	Password string `json:"password,omitempty" example:"password123"`
+	// Invite creates the user with a generated temporary password, returned
+	// once in the import result for the admin to pass on.
+	Invite bool `json:"invite,omitempty" example:"false"`
+} // @name ImportUser
+
+// RouteAuthRequest represents the JSON request body for overriding whether a
+// route group requires authentication.
+type RouteAuthRequest struct {
+	// Required makes the group require an API key (true) or open it (false).
+	Required *bool `json:"required" binding:"required" example:"true"`
+} // @name RouteAuthRequest
+
+// UserFilter narrows an admin user listing. Zero fields match every user.
+type UserFilter struct {
+	// Active keeps only active (true) or deactivated (false) users when set.
+	Active *bool
+	// Role keeps users holding the role, given by ID or name.
+	Role string
+	// Search matches a case-insensitive substring of the email, username or name.
+	Search string
+}
+
+// CalculationFilter narrows a calculation history listing. Zero fields match
+// every calculation; the ranges are inclusive.
+type CalculationFilter struct {
+	// UserID keeps calculations requested by this user.
+	UserID string
+	// From and To bound when the calculation was made.
+	From *time.Time
+	To   *time.Time
+	// MinItems and MaxItems bound the number of items ordered.
+	MinItems *int
+	MaxItems *int
+	// PackSet keeps calculations made with this pack set, as named by
+	// model.CalculationRollup.PackSet.
+	PackSet string
+}
+
+// Validate checks that the ranges are not inverted.
+func (f *CalculationFilter) Validate() error {
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return &ValidationError{Field: "from", Message: "must not be after to"}
+	}
+	if f.MinItems != nil && f.MaxItems != nil && *f.MinItems > *f.MaxItems {
+		return &ValidationError{Field: "min_items", Message: "must not be greater than max_items"}
+	}
+	return nil
+}
+
+// Rollup report granularities.
+const (
+	GranularityDay   = "day"
+	GranularityMonth = "month"
+)
+
+// MaxRollupRangeDays bounds the days one rollup report covers.
+const MaxRollupRangeDays = 731
+
+// RollupFilter selects the calculation rollups of an analytics report.
+type RollupFilter struct {
+	// From and To are UTC days, both inclusive.
+	From time.Time
+	To   time.Time
+	// UserID keeps the rollups of this user.
+	UserID string
+	// PackSet keeps the rollups of this pack set.
+	PackSet string
+	// Granularity is GranularityDay (the default) or GranularityMonth.
+	Granularity string
+}
+
+// Validate checks the range and granularity.
+func (f *RollupFilter) Validate() error {
+	if f.From.IsZero() {
+		return &ValidationError{Field: "from", Message: "is required"}
+	}
+	if f.To.IsZero() {
+		return &ValidationError{Field: "to", Message: "is required"}
+	}
+	if f.From.After(f.To) {
+		return &ValidationError{Field: "from", Message: "must not be after to"}
+	}
+	if f.To.Sub(f.From) >= MaxRollupRangeDays*24*time.Hour {
+		return &ValidationError{Field: "to", Message: fmt.Sprintf("must be less than %d days after from", MaxRollupRangeDays)}
+	}
+	switch f.Granularity {
+	case "", GranularityDay, GranularityMonth:
+	default:
+		return &ValidationError{Field: "granularity", Message: "must be day or month"}
+	}
+	return nil
+}
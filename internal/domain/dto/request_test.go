@@ -1,9 +1,16 @@
 package dto
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
 )
 
 func TestCalculatePacksRequest_Validate(t *testing.T) {
@@ -47,6 +54,153 @@ func TestCalculatePacksRequest_Validate(t *testing.T) {
 	}
 }
 
+func TestCalculatePacksRequest_Validate_Limits(t *testing.T) {
+	tooMany := make([]int, MaxPackSizes+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+
+	assert.NoError(t, (&CalculatePacksRequest{ItemsOrdered: 2 * DefaultMaxItemsOrdered, PackSizes: tooMany[:MaxPackSizes]}).Validate(),
+		"the maximum order is checked separately")
+	assert.Equal(t, ErrInvalidPackSizes, (&CalculatePacksRequest{ItemsOrdered: 100, PackSizes: tooMany}).Validate())
+	assert.Equal(t, ErrInvalidPackSizes, (&CalculatePacksRequest{ItemsOrdered: 100, PackSizes: []int{23, MaxPackSize + 1}}).Validate())
+}
+
+func TestCalculatePacksRequest_CheckItemsOrdered(t *testing.T) {
+	assert.NoError(t, (&CalculatePacksRequest{ItemsOrdered: 1000}).CheckItemsOrdered(1000))
+	assert.NoError(t, (&CalculatePacksRequest{ItemsOrdered: DefaultMaxItemsOrdered}).CheckItemsOrdered(0))
+
+	err := (&CalculatePacksRequest{ItemsOrdered: 1001}).CheckItemsOrdered(1000)
+	assert.Equal(t, &ItemsOrderedTooLargeError{Max: 1000}, err)
+	assert.EqualError(t, err, "items_ordered: must be at most 1000")
+	assert.Equal(t, &ItemsOrderedTooLargeError{Max: DefaultMaxItemsOrdered},
+		(&CalculatePacksRequest{ItemsOrdered: DefaultMaxItemsOrdered + 1}).CheckItemsOrdered(0))
+}
+
+func TestCalculatePacksRequest_Validate_Preset(t *testing.T) {
+	assert.NoError(t, (&CalculatePacksRequest{ItemsOrdered: 100, Preset: "warehouse-a"}).Validate())
+	assert.Equal(t, ErrPresetWithPackSizes, (&CalculatePacksRequest{
+		ItemsOrdered: 100,
+		Preset:       "warehouse-a",
+		PackSizes:    []int{23},
+	}).Validate())
+}
+
+func TestCalculatePacksRequest_Validate_Constraints(t *testing.T) {
+	zero, one, negative := 0, 1, -1
+	zeroPercent, negativePercent := 0.0, -0.5
+
+	valid := CalculatePacksRequest{ItemsOrdered: 100, MaxPacks: &one, MaxOverageItems: &zero, MaxOveragePercent: &zeroPercent}
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, &one, valid.Constraints().MaxPacks)
+
+	assert.Equal(t, ErrInvalidMaxPacks, (&CalculatePacksRequest{ItemsOrdered: 100, MaxPacks: &zero}).Validate())
+	assert.Equal(t, ErrInvalidMaxOverageItems, (&CalculatePacksRequest{ItemsOrdered: 100, MaxOverageItems: &negative}).Validate())
+	assert.Equal(t, ErrInvalidMaxOveragePercent, (&CalculatePacksRequest{ItemsOrdered: 100, MaxOveragePercent: &negativePercent}).Validate())
+	assert.True(t, (&CalculatePacksRequest{ItemsOrdered: 100}).Constraints().IsZero())
+}
+
+func TestCalculatePacksRequest_Validate_MaxComputeMs(t *testing.T) {
+	one, zero := 1, 0
+
+	assert.NoError(t, (&CalculatePacksRequest{ItemsOrdered: 100, MaxComputeMs: &one}).Validate())
+	assert.Equal(t, ErrInvalidMaxComputeMs, (&CalculatePacksRequest{ItemsOrdered: 100, MaxComputeMs: &zero}).Validate())
+}
+
+func TestCalculatePacksRequest_Validate_Optimize(t *testing.T) {
+	one := 1
+	costs := map[int]float64{250: 3, 500: 4.5}
+
+	valid := CalculatePacksRequest{ItemsOrdered: 100, Optimize: model.OptimizeMinCost, PackCosts: costs}
+	assert.NoError(t, valid.Validate())
+	assert.True(t, valid.Constraints().MinCost())
+	assert.Equal(t, costs, valid.Constraints().PackCosts)
+	assert.NoError(t, (&CalculatePacksRequest{ItemsOrdered: 100, Optimize: model.OptimizeMinItems, MaxPacks: &one}).Validate())
+
+	assert.Equal(t, ErrInvalidOptimize, (&CalculatePacksRequest{ItemsOrdered: 100, Optimize: "max_items"}).Validate())
+	assert.Equal(t, ErrInvalidOptimize, (&CalculatePacksRequest{ItemsOrdered: 100, Optimize: model.OptimizeMinCost, MaxPacks: &one}).Validate())
+	assert.Equal(t, ErrInvalidPackCosts, (&CalculatePacksRequest{ItemsOrdered: 100, PackCosts: map[int]float64{0: 1}}).Validate())
+	assert.Equal(t, ErrInvalidPackCosts, (&CalculatePacksRequest{ItemsOrdered: 100, PackCosts: map[int]float64{250: -1}}).Validate())
+	assert.Equal(t, ErrInvalidPackCosts, (&CalculatePacksRequest{ItemsOrdered: 100, PackCosts: map[int]float64{250: MaxPackCost + 1}}).Validate())
+}
+
+func TestUpdatePackSizesRequest_ValidCosts(t *testing.T) {
+	assert.True(t, (&UpdatePackSizesRequest{Sizes: []int{250, 500}}).ValidCosts())
+	assert.True(t, (&UpdatePackSizesRequest{Sizes: []int{250, 500}, Costs: map[int]float64{250: 3}}).ValidCosts())
+	assert.False(t, (&UpdatePackSizesRequest{Sizes: []int{250, 500}, Costs: map[int]float64{1000: 3}}).ValidCosts())
+	assert.False(t, (&UpdatePackSizesRequest{Sizes: []int{250, 500}, Costs: map[int]float64{250: -3}}).ValidCosts())
+}
+
+func TestCalculatePacksRequest_Validate_Metadata(t *testing.T) {
+	tooMany := make(map[string]string, MaxMetadataKeys+1)
+	for i := range MaxMetadataKeys + 1 {
+		tooMany[fmt.Sprintf("key%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "order context", metadata: map[string]string{"order_id": "A-1001", "channel": "web", "customer_ref": ""}},
+		{name: "too many keys", metadata: tooMany, wantErr: true},
+		{name: "empty key", metadata: map[string]string{"": "x"}, wantErr: true},
+		{name: "long key", metadata: map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "x"}, wantErr: true},
+		{name: "operator key", metadata: map[string]string{"$set": "x"}, wantErr: true},
+		{name: "dotted key", metadata: map[string]string{"order.id": "x"}, wantErr: true},
+		{name: "too large", metadata: map[string]string{"note": strings.Repeat("x", MaxMetadataBytes)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&CalculatePacksRequest{ItemsOrdered: 100, Metadata: tt.metadata}).Validate()
+			if tt.wantErr {
+				assert.Equal(t, ErrInvalidMetadata, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var req CalculateQuantityRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"quantity": 12.50, "pack_sizes": ["2.5", 5]}`), &req))
+	assert.Equal(t, Decimal("12.50"), req.Quantity, "numbers are kept as written")
+	assert.Equal(t, []Decimal{"2.5", "5"}, req.PackSizes)
+	assert.Error(t, json.Unmarshal([]byte(`{"quantity": true}`), &req))
+
+	data, err := json.Marshal(QuantityPack{Size: "2.5", Quantity: 2})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"size": 2.5, "quantity": 2}`, string(data))
+	data, err = json.Marshal(Decimal("2,5"))
+	require.NoError(t, err)
+	assert.Equal(t, `"2,5"`, string(data), "invalid numbers are written as strings")
+}
+
+func TestCalculateQuantityRequest_Units(t *testing.T) {
+	zero := 0
+
+	req := CalculateQuantityRequest{Quantity: "12.5", PackSizes: []Decimal{"2.5", "0.125"}}
+	require.NoError(t, req.Validate())
+	quantity, sizes, err := req.Units(3)
+	require.NoError(t, err)
+	assert.Equal(t, 12500, quantity)
+	assert.Equal(t, []int{2500, 125}, sizes)
+
+	_, _, err = req.Units(2)
+	assert.Equal(t, ErrInvalidPackQuantities, err, "0.125 has too many decimal places")
+	_, _, err = (&CalculateQuantityRequest{Quantity: "0", PackSizes: []Decimal{"1"}}).Units(3)
+	assert.Equal(t, ErrInvalidQuantity, err)
+	_, _, err = (&CalculateQuantityRequest{Quantity: "1", PackSizes: []Decimal{"0.000"}}).Units(3)
+	assert.Equal(t, ErrInvalidPackQuantities, err)
+
+	assert.Equal(t, ErrInvalidPackQuantities, (&CalculateQuantityRequest{Quantity: "1"}).Validate())
+	assert.Equal(t, ErrInvalidPackQuantities, (&CalculateQuantityRequest{Quantity: "1", PackSizes: make([]Decimal, MaxPackSizes+1)}).Validate())
+	assert.Equal(t, ErrInvalidMaxPacks, (&CalculateQuantityRequest{Quantity: "1", PackSizes: []Decimal{"1"}, MaxPacks: &zero}).Validate())
+}
+
 func TestValidationError_Error(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -77,3 +231,77 @@ func TestValidationError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculationFilter_Validate(t *testing.T) {
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+	minItems, maxItems := 10, 5
+
+	assert.NoError(t, (&CalculationFilter{}).Validate())
+	assert.NoError(t, (&CalculationFilter{From: &to, To: &from, MinItems: &maxItems, MaxItems: &minItems}).Validate())
+	assert.Error(t, (&CalculationFilter{From: &from, To: &to}).Validate())
+	assert.Error(t, (&CalculationFilter{MinItems: &minItems, MaxItems: &maxItems}).Validate())
+}
+
+func TestRollupFilter_Validate(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := from.AddDate(0, 0, MaxRollupRangeDays-1)
+
+	assert.NoError(t, (&RollupFilter{From: from, To: from}).Validate())
+	assert.NoError(t, (&RollupFilter{From: from, To: lastDay, Granularity: GranularityMonth}).Validate())
+	assert.Error(t, (&RollupFilter{To: from}).Validate())
+	assert.Error(t, (&RollupFilter{From: from}).Validate())
+	assert.Error(t, (&RollupFilter{From: from, To: from.AddDate(0, 0, -1)}).Validate())
+	assert.Error(t, (&RollupFilter{From: from, To: lastDay.AddDate(0, 0, 1)}).Validate())
+	assert.Error(t, (&RollupFilter{From: from, To: from, Granularity: "week"}).Validate())
+}
+
+func TestSimulatePackSizesRequest_SimulationOrders(t *testing.T) {
+	req := SimulatePackSizesRequest{
+		Orders:       []int{251, 751, 251},
+		Distribution: []SimulationOrder{{ItemsOrdered: 12001, Count: 10}, {ItemsOrdered: 751, Count: 4}},
+	}
+	orders, err := req.SimulationOrders()
+	require.NoError(t, err)
+	assert.Equal(t, []SimulationOrder{
+		{ItemsOrdered: 251, Count: 2},
+		{ItemsOrdered: 751, Count: 5},
+		{ItemsOrdered: 12001, Count: 10},
+	}, orders)
+
+	_, err = (&SimulatePackSizesRequest{}).SimulationOrders()
+	assert.Equal(t, ErrInvalidSimulationOrders, err)
+
+	_, err = (&SimulatePackSizesRequest{Orders: make([]int, MaxBatchItems+1)}).SimulationOrders()
+	assert.Equal(t, ErrInvalidSimulationOrders, err)
+}
+
+func TestCalculatePacksRequest_ApplyPreset(t *testing.T) {
+	storedOverage, requestOverage := 250, 10
+	preset := &model.Preset{Constraints: &model.PackConstraints{
+		MaxOverageItems: &storedOverage,
+		Optimize:        model.OptimizeMinCost,
+		PackCosts:       map[int]float64{250: 1},
+	}}
+
+	req := CalculatePacksRequest{ItemsOrdered: 251, MaxOverageItems: &requestOverage}
+	req.ApplyPreset(preset)
+
+	assert.Equal(t, 10, *req.MaxOverageItems)
+	assert.Equal(t, model.OptimizeMinCost, req.Optimize)
+	assert.Equal(t, map[int]float64{250: 1}, req.PackCosts)
+
+	unchanged := CalculatePacksRequest{ItemsOrdered: 251}
+	unchanged.ApplyPreset(&model.Preset{})
+	assert.Equal(t, CalculatePacksRequest{ItemsOrdered: 251}, unchanged)
+}
+
+func TestPresetSettings(t *testing.T) {
+	maxPacks := 0
+	assert.Error(t, (&PresetSettings{MaxPacks: &maxPacks}).Validate())
+	assert.Error(t, (&PresetSettings{Optimize: "fastest"}).Validate())
+	assert.NoError(t, (&PresetSettings{Optimize: model.OptimizeMinCost}).Validate())
+
+	assert.Nil(t, (&PresetSettings{}).Constraints())
+	assert.Equal(t, &model.PackConstraints{Optimize: model.OptimizeMinItems}, (&PresetSettings{Optimize: model.OptimizeMinItems}).Constraints())
+}
@@ -2,7 +2,13 @@ package dto
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/guttosm/pack-service/internal/correlation"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 const (
@@ -22,6 +28,23 @@ const (
 	ErrCodeConflict = "conflict"
 	// ErrCodeTimeout indicates a request timeout.
 	ErrCodeTimeout = "timeout"
+	// ErrCodeUnprocessable indicates a valid request that cannot be fulfilled.
+	ErrCodeUnprocessable = "unprocessable"
+	// ErrCodePayloadTooLarge indicates the request body exceeds the size limit.
+	ErrCodePayloadTooLarge = "payload_too_large"
+	// ErrCodeServiceUnavailable indicates the service is temporarily overloaded.
+	ErrCodeServiceUnavailable = "service_unavailable"
+	// ErrCodeDatabaseUnavailable indicates the request needs the database,
+	// which is down for now; the client retries after the Retry-After.
+	ErrCodeDatabaseUnavailable = "database_unavailable"
+	// ErrCodeAccountLocked indicates logins are locked out after too many failed attempts.
+	ErrCodeAccountLocked = "account_locked"
+	// ErrCodeInvalidCursor indicates a page cursor that is invalid or
+	// expired; the client restarts the listing from the first page.
+	ErrCodeInvalidCursor = "invalid_cursor"
+	// ErrCodePreconditionFailed indicates the resource changed since the
+	// version named by If-Match.
+	ErrCodePreconditionFailed = "precondition_failed"
 )
 
 // SuccessResponse wraps successful API responses with metadata.
@@ -34,8 +57,320 @@ type SuccessResponse struct {
 	RequestID string       `json:"request_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
 	// Timestamp is when the response was generated
 	Timestamp time.Time    `json:"timestamp" example:"2025-01-28T10:00:00Z"`
+	// Warnings lists accepted inputs that look like client mistakes (optional)
+	Warnings  []Warning    `json:"warnings,omitempty"`
 } // @name SuccessResponse
 
+// Warning describes a suspicious request input that did not prevent the request from succeeding.
+// @Description Non-fatal warning about a request input
+type Warning struct {
+	Code    string `json:"code" example:"items_ordered_scaled_pack_size"`
+	Field   string `json:"field,omitempty" example:"items_ordered"`
+	Message string `json:"message" example:"items_ordered is exactly 1000 times the pack size 250; check that items are not sent in the wrong unit"`
+} // @name Warning
+
+// BatchItemResult is the outcome of one item of a batch calculation. Exactly
+// one of Result and Error is set. Streamed batches write one per line.
+// @Description Result or error of one batch item
+type BatchItemResult struct {
+	// Index is the position of the item in the request.
+	Index int `json:"index" example:"0"`
+	// Result is the pack calculation result when the item succeeded.
+	Result *model.PackResult `json:"result,omitempty"`
+	// Error describes why the item failed.
+	Error *BatchItemError `json:"error,omitempty"`
+} // @name BatchItemResult
+
+// BatchItemError describes a failed batch item.
+// @Description Error of one batch item
+type BatchItemError struct {
+	Code    string `json:"code" example:"invalid_request"`
+	Message string `json:"message" example:"items_ordered: must be a positive integer"`
+} // @name BatchItemError
+
+// BatchItemColumns are the spreadsheet columns of batch item results, one
+// row per item, as written by BatchItemResult.Row.
+var BatchItemColumns = []any{
+	"index", "items_ordered", "total_items", "overage_items", "pack_count", "packs",
+	"approximate", "error_code", "error_message",
+}
+
+// Row returns the spreadsheet row of the result of an order of itemsOrdered
+// items, in the order of BatchItemColumns. Packs reads like "2x500, 1x250".
+func (r BatchItemResult) Row(itemsOrdered int) []any {
+	row := []any{r.Index, itemsOrdered, nil, nil, nil, nil, nil, nil, nil}
+	if r.Error != nil {
+		row[7], row[8] = r.Error.Code, r.Error.Message
+		return row
+	}
+	if res := r.Result; res != nil {
+		row[2], row[3], row[4], row[5], row[6] = res.TotalItems, res.TotalItems-res.OrderedItems, res.PackCount(), formatPacks(res.Packs), res.Approximate
+	}
+	return row
+}
+
+// formatPacks writes packs as quantity x size, in the calculator's order.
+func formatPacks(packs []model.Pack) string {
+	parts := make([]string, len(packs))
+	for i, p := range packs {
+		parts[i] = strconv.Itoa(p.Quantity) + "x" + strconv.Itoa(p.Size)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// BatchCalculateResponse is the non-streamed response of a batch calculation.
+// @Description Batch calculation results in request order
+type BatchCalculateResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded" example:"2"`
+	Failed    int               `json:"failed" example:"0"`
+} // @name BatchCalculateResponse
+
+// QuantityResult is the result of a decimal quantity calculation, in the
+// units of the request.
+// @Description Packs for an order of a decimal quantity
+type QuantityResult struct {
+	// OrderedQuantity is the quantity ordered.
+	OrderedQuantity Decimal `json:"ordered_quantity" swaggertype:"number" example:"12.5"`
+	// TotalQuantity is the quantity shipped.
+	TotalQuantity Decimal `json:"total_quantity" swaggertype:"number" example:"12.5"`
+	// Packs are the packs shipped.
+	Packs []QuantityPack `json:"packs"`
+	// Precision is the number of decimal places quantities are calculated with.
+	Precision int `json:"precision" example:"3"`
+} // @name QuantityResult
+
+// QuantityPack is the number of packs of one size in a QuantityResult.
+// @Description Packs of one decimal size
+type QuantityPack struct {
+	// Size is the quantity a pack holds.
+	Size Decimal `json:"size" swaggertype:"number" example:"5"`
+	// Quantity is the number of packs of this size.
+	Quantity int `json:"quantity" example:"2"`
+} // @name QuantityPack
+
+// StreamJobResponse is returned when a bulk calculation is submitted for streaming.
+// @Description Submitted stream job; open stream_url within expires_at to receive results
+type StreamJobResponse struct {
+	JobID     string    `json:"job_id" example:"0b5f3c52-8f0e-4f51-9a55-7a0c8d1c2e3f"`
+	Items     int       `json:"items" example:"25000"`
+	StreamURL string    `json:"stream_url" example:"/api/calculate/stream?job=0b5f3c52-8f0e-4f51-9a55-7a0c8d1c2e3f"`
+	ExpiresAt time.Time `json:"expires_at" example:"2026-01-28T10:01:00Z"`
+} // @name StreamJobResponse
+
+// JobProgress reports how many items of a streamed job are done. It is the
+// data of the progress and done events.
+// @Description Progress of a streamed job
+type JobProgress struct {
+	Completed int `json:"completed" example:"1200"`
+	Total     int `json:"total" example:"25000"`
+	Succeeded int `json:"succeeded" example:"1198"`
+	Failed    int `json:"failed" example:"2"`
+} // @name JobProgress
+
+// CalculationJobResponse describes an asynchronous calculation job. Results
+// are set once the job has completed, in request order.
+// @Description Asynchronous calculation job and, once completed, its results
+type CalculationJobResponse struct {
+	JobID       string            `json:"job_id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Status      string            `json:"status" example:"completed" enums:"queued,running,completed,failed"`
+	Items       int               `json:"items" example:"25000"`
+	Succeeded   int               `json:"succeeded" example:"24998"`
+	Failed      int               `json:"failed" example:"2"`
+	Results     []BatchItemResult `json:"results,omitempty"`
+	Error       string            `json:"error,omitempty" example:"job timed out"`
+	CreatedAt   time.Time         `json:"created_at" example:"2026-01-28T10:00:00Z"`
+	StartedAt   *time.Time        `json:"started_at,omitempty" example:"2026-01-28T10:00:01Z"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty" example:"2026-01-28T10:00:09Z"`
+	// ExpiresAt is when a finished job and its results are deleted.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-29T10:00:09Z"`
+} // @name CalculationJobResponse
+
+// PackSizesMigration describes a staged pack size configuration and how its
+// results compare with the active one's.
+// @Description Staged pack size migration and its divergence statistics
+type PackSizesMigration struct {
+	ID     string `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Status string `json:"status" example:"shadowing" enums:"shadowing,ready,promoting,promoted,cancelled"`
+	Sizes  []int  `json:"sizes" example:"250,500,750,1000"`
+	// BaselineVersion and BaselineSizes are the active configuration the
+	// staged one is compared with and replaces.
+	BaselineVersion int       `json:"baseline_version" example:"4"`
+	BaselineSizes   []int     `json:"baseline_sizes" example:"250,500,1000"`
+	StartedBy       string    `json:"started_by,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	ShadowEndsAt    time.Time `json:"shadow_ends_at"`
+	// Replay compares recent stored calculations; Shadow compares live
+	// traffic since the migration started.
+	Replay MigrationDivergence `json:"replay"`
+	Shadow MigrationDivergence `json:"shadow"`
+	// PromotedVersion is the version the staged sizes were stored as.
+	PromotedVersion int        `json:"promoted_version,omitempty" example:"5"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+} // @name PackSizesMigration
+
+// MigrationDivergence counts how often the staged pack sizes would have
+// produced a different result than the active ones.
+// @Description Comparison of staged and active pack size results
+type MigrationDivergence struct {
+	Compared int64 `json:"compared" example:"1200"`
+	Diverged int64 `json:"diverged" example:"84"`
+	// DivergenceRate is Diverged / Compared.
+	DivergenceRate float64 `json:"divergence_rate" example:"0.07"`
+	// Unsatisfiable counts orders whose constraints the staged sizes cannot meet.
+	Unsatisfiable int64 `json:"unsatisfiable" example:"0"`
+	// Skipped counts live orders not compared because the shadow workers were busy.
+	Skipped int64 `json:"skipped,omitempty" example:"3"`
+	// ItemsDelta and PacksDelta are the staged minus the active totals over
+	// the compared orders: positive means more items or packs shipped.
+	ItemsDelta int64 `json:"items_delta" example:"-1500"`
+	PacksDelta int64 `json:"packs_delta" example:"12"`
+	// Samples are the first diverging orders.
+	Samples []MigrationSample `json:"samples,omitempty"`
+} // @name MigrationDivergence
+
+// MigrationSample is one order the staged pack sizes would fill differently.
+// @Description Order filled differently by the staged pack sizes
+type MigrationSample struct {
+	ItemsOrdered int               `json:"items_ordered" example:"751"`
+	Current      *model.PackResult `json:"current"`
+	// Staged is nil when the staged sizes cannot meet the order's constraints.
+	Staged *model.PackResult `json:"staged,omitempty"`
+} // @name MigrationSample
+
+// PackSizeViolation is a pack size rule a configuration breaks.
+// @Description Pack size rule broken by a configuration
+type PackSizeViolation struct {
+	Rule string `json:"rule" example:"unique_sizes" enums:"max_sizes,min_size,max_size,unique_sizes,common_divisor"`
+	// Severity is error for violations that keep the sizes from being saved.
+	Severity string `json:"severity" example:"error" enums:"error,warning"`
+	Message  string `json:"message" example:"pack sizes must be unique"`
+	// Sizes are the offending sizes, for rules about single sizes.
+	Sizes []int `json:"sizes,omitempty" example:"500"`
+} // @name PackSizeViolation
+
+// PackSizesValidation is the outcome of a dry run of a pack size change.
+// @Description Pack size rule violations and the impact on sample orders
+type PackSizesValidation struct {
+	// Valid is true when no violation is an error, so the sizes can be saved.
+	Valid      bool                `json:"valid" example:"true"`
+	Sizes      []int               `json:"sizes" example:"250,500,750,1000"`
+	Violations []PackSizeViolation `json:"violations"`
+	// ActiveVersion is the configuration the sample orders are compared
+	// with; zero when none is stored and the configured sizes are used.
+	ActiveVersion int   `json:"active_version,omitempty" example:"4"`
+	ActiveSizes   []int `json:"active_sizes,omitempty" example:"250,500,1000"`
+	// Impact compares the sample orders, when the sizes are valid.
+	Impact []PackSizeImpact `json:"impact,omitempty"`
+} // @name PackSizesValidation
+
+// PackSizeImpact compares how the active and the proposed pack sizes fill
+// one order.
+// @Description Sample order filled with the active and the proposed pack sizes
+type PackSizeImpact struct {
+	ItemsOrdered int             `json:"items_ordered" example:"751"`
+	Current      PackSizeOutcome `json:"current"`
+	Proposed     PackSizeOutcome `json:"proposed"`
+	// Changed is true when the proposed sizes use different packs.
+	Changed bool `json:"changed" example:"true"`
+	// TotalItemsChange and PackCountChange are the proposed minus the
+	// current values: positive means more items or packs shipped.
+	TotalItemsChange int `json:"total_items_change" example:"-250"`
+	PackCountChange  int `json:"pack_count_change" example:"0"`
+} // @name PackSizeImpact
+
+// PackSizeOutcome summarizes how one order is filled.
+// @Description Items and packs shipped for an order
+type PackSizeOutcome struct {
+	TotalItems   int          `json:"total_items" example:"1000"`
+	OverageItems int          `json:"overage_items" example:"249"`
+	PackCount    int          `json:"pack_count" example:"2"`
+	Packs        []model.Pack `json:"packs"`
+} // @name PackSizeOutcome
+
+// PackSizesSimulation compares two pack size configurations over the same
+// orders.
+// @Description Comparison of two pack size sets over a set of orders
+type PackSizesSimulation struct {
+	A SimulationStats `json:"a"`
+	B SimulationStats `json:"b"`
+	// DifferingOrders counts the orders filled with different packs.
+	DifferingOrders int64 `json:"differing_orders" example:"120"`
+	// BetterWithA and BetterWithB count the orders the set ships less
+	// overage for, or as much in fewer packs.
+	BetterWithA int64 `json:"better_with_a" example:"0"`
+	BetterWithB int64 `json:"better_with_b" example:"120"`
+} // @name PackSizesSimulation
+
+// SimulationStats sums up how one pack size configuration fills the orders
+// of a simulation. Orders are weighted by their count.
+// @Description Totals and worst cases of one pack size set
+type SimulationStats struct {
+	Sizes        []int `json:"sizes" example:"250,500,800"`
+	Orders       int64 `json:"orders" example:"121"`
+	ItemsOrdered int64 `json:"items_ordered" example:"90371"`
+	ItemsShipped int64 `json:"items_shipped" example:"96500"`
+	OverageItems int64 `json:"overage_items" example:"6129"`
+	// OveragePercent is OverageItems as a percentage of ItemsOrdered.
+	OveragePercent      float64 `json:"overage_percent" example:"6.78"`
+	AverageOverageItems float64 `json:"average_overage_items" example:"50.65"`
+	Packs               int64   `json:"packs" example:"121"`
+	AveragePacks        float64 `json:"average_packs" example:"1"`
+	MaxOverageItems     int     `json:"max_overage_items" example:"249"`
+	MaxPacks            int     `json:"max_packs" example:"2"`
+	// Approximate counts orders too large to search exactly.
+	Approximate int64 `json:"approximate,omitempty" example:"0"`
+	// WorstCases are the orders with the most overage, then the most packs.
+	WorstCases []SimulationCase `json:"worst_cases"`
+} // @name SimulationStats
+
+// SimulationCase is how one order of a simulation is filled.
+// @Description Order of a simulation and the packs filling it
+type SimulationCase struct {
+	ItemsOrdered int          `json:"items_ordered" example:"251"`
+	OverageItems int          `json:"overage_items" example:"249"`
+	PackCount    int          `json:"pack_count" example:"1"`
+	Packs        []model.Pack `json:"packs"`
+	Approximate  bool         `json:"approximate,omitempty" example:"false"`
+} // @name SimulationCase
+
+// CalculationPage is one page of the calculation history.
+// @Description Calculation history page, newest first
+type CalculationPage struct {
+	Calculations []*model.Calculation `json:"calculations"`
+	// Total is the number of calculations matching the filter.
+	Total  int64 `json:"total" example:"1250"`
+	Limit  int   `json:"limit" example:"50"`
+	Offset int   `json:"offset" example:"0"`
+	// NextCursor requests the next page as the cursor parameter; empty on
+	// the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"AZx3q0v1c2Vyc..."`
+} // @name CalculationPage
+
+// CalculationRollupReport aggregates the calculation history by day or month,
+// user and pack set.
+// @Description Calculation analytics report, by period, user and pack set
+type CalculationRollupReport struct {
+	From        time.Time `json:"from" example:"2026-01-01T00:00:00Z"`
+	To          time.Time `json:"to" example:"2026-03-31T00:00:00Z"`
+	Granularity string    `json:"granularity" example:"day"`
+	// Rollups are sorted by period, user and pack set.
+	Rollups []*model.CalculationRollup `json:"rollups"`
+} // @name CalculationRollupReport
+
+// LogPage is one page of stored log entries.
+// @Description Log entries page, newest first
+type LogPage struct {
+	Logs []model.LogEntry `json:"logs"`
+	// Total is the number of entries matching the filter.
+	Total  int64 `json:"total" example:"1250"`
+	Limit  int   `json:"limit" example:"50"`
+	Offset int   `json:"offset" example:"0"`
+	// NextCursor requests the next page as the cursor parameter; empty on
+	// the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"AZx3q0v1c2Vyc..."`
+} // @name LogPage
+
 // ErrorResponse represents a standardized error response for the API.
 // @Description Standardized error response
 type ErrorResponse struct {
@@ -45,8 +380,11 @@ type ErrorResponse struct {
 	// Example: {"field": "error message"}
 	Details   map[string]string `json:"details,omitempty"`
 	RequestID string            `json:"request_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Timestamp time.Time         `json:"timestamp" example:"2025-01-28T10:00:00Z"`
-	TraceID   string            `json:"trace_id,omitempty" example:"trace-123"`
+	// CorrelationID is the caller's X-Correlation-ID, or the request ID.
+	CorrelationID string    `json:"correlation_id,omitempty" example:"checkout-7f3a"`
+	Timestamp     time.Time `json:"timestamp" example:"2025-01-28T10:00:00Z"`
+	// TraceID is the W3C trace ID of the request, from its traceparent header.
+	TraceID string `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
 } // @name ErrorResponse
 
 // NewError creates a new ErrorResponse with the given code and message.
@@ -54,7 +392,7 @@ func NewError(code, message string) ErrorResponse {
 	return ErrorResponse{
 		Error:     code,
 		Message:   message,
-		Timestamp: time.Now(),
+		Timestamp: timeutil.Now(),
 	}
 }
 
@@ -64,6 +402,63 @@ func (e ErrorResponse) WithRequestID(requestID string) ErrorResponse {
 	return e
 }
 
+// WithIDs adds the request, correlation and trace IDs to the error response.
+func (e ErrorResponse) WithIDs(ids correlation.IDs) ErrorResponse {
+	e.RequestID = ids.RequestID
+	e.CorrelationID = ids.CorrelationID
+	e.TraceID = ids.TraceID
+	return e
+}
+
+// ProblemContentType is the media type of ProblemDetails responses.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an error response in the RFC 7807 format, for clients
+// that standardize on it. Besides the RFC members it carries the fields of
+// ErrorResponse as extension members.
+// @Description RFC 7807 problem details error response
+type ProblemDetails struct {
+	// Type is "about:blank": the status and code identify the problem.
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Bad Request"`
+	Status   int    `json:"status" example:"400"`
+	Detail   string `json:"detail,omitempty" example:"items_ordered: must be a positive integer"`
+	Instance string `json:"instance,omitempty" example:"/api/calculate"`
+	// Code is the error code an ErrorResponse holds as error.
+	Code          string            `json:"code" example:"invalid_request"`
+	Details       map[string]string `json:"details,omitempty"`
+	RequestID     string            `json:"request_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CorrelationID string            `json:"correlation_id,omitempty" example:"checkout-7f3a"`
+	Timestamp     time.Time         `json:"timestamp" example:"2025-01-28T10:00:00Z"`
+	TraceID       string            `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+} // @name ProblemDetails
+
+// Problem returns the error as problem details for a response with the
+// given status to a request for instance.
+func (e ErrorResponse) Problem(status int, instance string) ProblemDetails {
+	return ProblemDetails{
+		Type:          "about:blank",
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        e.Message,
+		Instance:      instance,
+		Code:          e.Error,
+		Details:       e.Details,
+		RequestID:     e.RequestID,
+		CorrelationID: e.CorrelationID,
+		Timestamp:     e.Timestamp,
+		TraceID:       e.TraceID,
+	}
+}
+
+// ErrorCode describes an error code of the API, for client developers.
+// @Description Error code, the HTTP status it comes with and its message
+type ErrorCode struct {
+	Code    string `json:"code" example:"user_not_found"`
+	Status  int    `json:"status" example:"404"`
+	Message string `json:"message" example:"User not found"`
+} // @name ErrorCode
+
 // ErrCodeFromStatus returns the appropriate error code for an HTTP status.
 func ErrCodeFromStatus(status int) string {
 	switch status {
@@ -77,11 +472,91 @@ func ErrCodeFromStatus(status int) string {
 		return ErrCodeNotFound
 	case http.StatusConflict:
 		return ErrCodeConflict
+	case http.StatusUnprocessableEntity:
+		return ErrCodeUnprocessable
+	case http.StatusRequestEntityTooLarge:
+		return ErrCodePayloadTooLarge
 	case http.StatusTooManyRequests:
 		return ErrCodeRateLimit
+	case http.StatusLocked:
+		return ErrCodeAccountLocked
+	case http.StatusPreconditionFailed:
+		return ErrCodePreconditionFailed
 	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
 		return ErrCodeTimeout
 	default:
 		return ErrCodeInternal
 	}
 }
+
+// CircuitBreakerStatus is the state of one registered circuit breaker.
+// @Description Circuit breaker state and counts
+type CircuitBreakerStatus struct {
+	Name  string `json:"name" example:"mongodb_pack_sizes"`
+	State string `json:"state" example:"open" enums:"closed,open,half-open"`
+	// FailureCount is the consecutive failures recorded; SuccessCount the
+	// consecutive successes while half-open.
+	FailureCount int        `json:"failure_count" example:"5"`
+	SuccessCount int        `json:"success_count" example:"0"`
+	LastFailure  *time.Time `json:"last_failure,omitempty" example:"2026-01-28T10:00:00Z"`
+	// LastTransition is omitted until the breaker first changes state.
+	LastTransition *time.Time `json:"last_transition,omitempty" example:"2026-01-28T10:00:00Z"`
+} // @name CircuitBreakerStatus
+
+// DrainStatus reports whether the instance is draining.
+// @Description Draining state of the instance
+type DrainStatus struct {
+	Draining bool `json:"draining" example:"true"`
+	// Since is when draining started; omitted when not draining.
+	Since *time.Time `json:"since,omitempty" example:"2026-01-28T10:00:00Z"`
+} // @name DrainStatus
+
+// TokenCleanupResult reports an expired token cleanup.
+// @Description Expired tokens deleted by a cleanup
+type TokenCleanupResult struct {
+	Deleted int64 `json:"deleted" example:"42"`
+} // @name TokenCleanupResult
+
+// ConfigChange is a setting a config reload changed.
+// @Description Setting changed by a config reload
+type ConfigChange struct {
+	Setting string `json:"setting" example:"RATE_LIMIT"`
+	// Old and New are the setting's values; API keys are listed by
+	// fingerprint.
+	Old string `json:"old" example:"100"`
+	New string `json:"new" example:"200"`
+} // @name ConfigChange
+
+// ConfigReloadResult reports a config reload.
+// @Description Settings changed by a config reload
+type ConfigReloadResult struct {
+	// Changes is empty when no reloadable setting changed.
+	Changes    []ConfigChange `json:"changes"`
+	ReloadedAt time.Time      `json:"reloaded_at" example:"2026-01-28T10:00:00Z"`
+} // @name ConfigReloadResult
+
+// CacheShardStats reports one shard of the in-memory result cache.
+// @Description Result cache shard counts
+type CacheShardStats struct {
+	Hits      int64 `json:"hits" example:"1200"`
+	Misses    int64 `json:"misses" example:"300"`
+	Evictions int64 `json:"evictions" example:"12"`
+	Size      int   `json:"size" example:"250"`
+	Capacity  int   `json:"capacity" example:"256"`
+} // @name CacheShardStats
+
+// CacheStats reports the calculation result cache.
+// @Description Result cache counts, totalled over its shards
+type CacheStats struct {
+	// Enabled is false when results are not cached; the counts are then zero.
+	Enabled   bool    `json:"enabled" example:"true"`
+	Hits      int64   `json:"hits" example:"4800"`
+	Misses    int64   `json:"misses" example:"1200"`
+	HitRate   float64 `json:"hit_rate" example:"0.8"`
+	Evictions int64   `json:"evictions" example:"48"`
+	// Size and Capacity are zero, and Shards omitted, for the Redis cache,
+	// which reports only this instance's hits and misses.
+	Size     int               `json:"size" example:"1000"`
+	Capacity int               `json:"capacity" example:"1024"`
+	Shards   []CacheShardStats `json:"shards,omitempty"`
+} // @name CacheStats
@@ -5,6 +5,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/internal/correlation"
+	"github.com/guttosm/pack-service/internal/domain/model"
 )
 
 func TestErrorResponse_WithRequestID(t *testing.T) {
@@ -39,6 +42,23 @@ func TestErrorResponse_WithRequestID(t *testing.T) {
 	}
 }
 
+func TestErrorResponse_WithIDs(t *testing.T) {
+	err := NewError(ErrCodeInternal, "test error").WithIDs(correlation.IDs{
+		RequestID:     "req-1",
+		CorrelationID: "order-42",
+		TraceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+	})
+
+	assert.Equal(t, "req-1", err.RequestID)
+	assert.Equal(t, "order-42", err.CorrelationID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", err.TraceID)
+
+	problem := err.Problem(500, "/api/calculate")
+	assert.Equal(t, "req-1", problem.RequestID)
+	assert.Equal(t, "order-42", problem.CorrelationID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", problem.TraceID)
+}
+
 func TestErrCodeFromStatus(t *testing.T) {
 	tests := []struct {
 		status     int
@@ -49,6 +69,10 @@ func TestErrCodeFromStatus(t *testing.T) {
 		{403, ErrCodeForbidden},
 		{404, ErrCodeNotFound},
 		{409, ErrCodeConflict},
+		{412, ErrCodePreconditionFailed},
+		{422, ErrCodeUnprocessable},
+		{423, ErrCodeAccountLocked},
+		{413, ErrCodePayloadTooLarge},
 		{429, ErrCodeRateLimit},
 		{500, ErrCodeInternal},
 		{502, ErrCodeInternal},
@@ -92,3 +116,16 @@ func TestNewError(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchItemResult_Row(t *testing.T) {
+	result := BatchItemResult{Index: 2, Result: &model.PackResult{
+		OrderedItems: 12001,
+		TotalItems:   12250,
+		Packs:        []model.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+	}}
+	assert.Equal(t, []any{2, 12001, 12250, 249, 4, "2x5000, 1x2000, 1x250", false, nil, nil}, result.Row(12001))
+
+	failed := BatchItemResult{Index: 3, Error: &BatchItemError{Code: ErrCodeInvalidRequest, Message: "bad"}}
+	assert.Equal(t, []any{3, 0, nil, nil, nil, nil, nil, ErrCodeInvalidRequest, "bad"}, failed.Row(0))
+	assert.Len(t, result.Row(12001), len(BatchItemColumns))
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Calculation sources.
+const (
+	CalculationSourceHTTP  = "http"
+	CalculationSourceBatch = "batch"
+	CalculationSourceGRPC  = "grpc"
+	CalculationSourceJob   = "job"
+)
+
+// Calculation is one stored pack calculation: its input, result and who
+// requested it. The history is an audit trail and the raw data for demand
+// analysis.
+type Calculation struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	RequestID string             `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	// UserID is the authenticated user, empty for API key and anonymous callers.
+	UserID string `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	// Source is how the calculation was requested: http, batch, grpc or job.
+	Source       string `bson:"source" json:"source"`
+	ItemsOrdered int    `bson:"items_ordered" json:"items_ordered"`
+	// PackSizes are the sizes calculated with; empty when the built-in
+	// defaults were used.
+	PackSizes []int  `bson:"pack_sizes,omitempty" json:"pack_sizes,omitempty"`
+	Preset    string `bson:"preset,omitempty" json:"preset,omitempty"`
+	// PackSizesVersion is the stored pack sizes configuration used, zero when
+	// the request supplied its own sizes.
+	PackSizesVersion int              `bson:"pack_sizes_version,omitempty" json:"pack_sizes_version,omitempty"`
+	Constraints      *PackConstraints `bson:"constraints,omitempty" json:"constraints,omitempty"`
+	TotalItems       int              `bson:"total_items" json:"total_items"`
+	Packs            []Pack           `bson:"packs" json:"packs"`
+	// TotalCost is the cost of the packs, when the pack sizes had costs.
+	TotalCost *float64 `bson:"total_cost,omitempty" json:"total_cost,omitempty"`
+	// Metadata is the caller's order context sent with the request.
+	Metadata map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	// Approximate is true when the compute time limit ran out before the
+	// optimal combination was found.
+	Approximate bool `bson:"approximate,omitempty" json:"approximate,omitempty"`
+	// LatencyMicros is how long the calculation itself took.
+	LatencyMicros int64 `bson:"latency_us" json:"latency_us"`
+}
+
+// PackSetDefault is the pack set of calculations made with the built-in
+// default pack sizes.
+const PackSetDefault = "default"
+
+// CalculationRollup pre-aggregates one UTC day of calculations by one user
+// with one pack set, so analytics over months read a few documents per day
+// instead of every calculation.
+type CalculationRollup struct {
+	// Day is the UTC midnight starting the day, or the first day of the month
+	// in monthly reports.
+	Day time.Time `bson:"day" json:"day"`
+	// UserID is the requesting user, empty for API key and anonymous callers.
+	UserID string `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	// PackSet lists the sorted pack sizes calculated with, comma separated,
+	// or is PackSetDefault when the built-in defaults were used.
+	PackSet      string `bson:"pack_set" json:"pack_set"`
+	Calculations int64  `bson:"calculations" json:"calculations"`
+	// ItemsOrdered and TotalItems sum the calculations' orders and results;
+	// their difference is the overage shipped.
+	ItemsOrdered int64 `bson:"items_ordered" json:"items_ordered"`
+	TotalItems   int64 `bson:"total_items" json:"total_items"`
+	// Packs is the number of packs shipped.
+	Packs int64 `bson:"packs" json:"packs"`
+	// Approximate counts the results cut short by the compute time limit.
+	Approximate     int64 `bson:"approximate" json:"approximate"`
+	MinItemsOrdered int   `bson:"min_items_ordered" json:"min_items_ordered"`
+	MaxItemsOrdered int   `bson:"max_items_ordered" json:"max_items_ordered"`
+	// LatencyMicros sums the calculations' latencies.
+	LatencyMicros int64 `bson:"latency_us" json:"latency_us"`
+	// UpdatedAt is when the rollup job last recomputed the day.
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Calculation job statuses.
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// CalculationJob is a batch calculation run in the background. Workers on
+// any instance claim queued jobs; a claim is a lease, so the job of an
+// instance that dies mid-run is picked up again once the lease ends.
+type CalculationJob struct {
+	// ID is random, so jobs of callers without an owner cannot be guessed.
+	ID string `bson:"_id" json:"id"`
+	// Owner is the submitting user's ID, empty for API key and anonymous callers.
+	Owner string `bson:"owner,omitempty" json:"owner,omitempty"`
+	// RequestID is the ID of the request that submitted the job.
+	RequestID string `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	// Tenant is the tenant whose message overrides item errors use.
+	Tenant string `bson:"tenant,omitempty" json:"-"`
+	// Locale is the language item errors are reported in.
+	Locale string `bson:"locale,omitempty" json:"locale,omitempty"`
+	Status string `bson:"status" json:"status"`
+	Items  int    `bson:"items" json:"items"`
+	// Request holds the submitted items as JSON.
+	Request []byte `bson:"request" json:"-"`
+	// Results holds the item results as JSON once the job has completed.
+	Results   []byte `bson:"results,omitempty" json:"-"`
+	Succeeded int    `bson:"succeeded" json:"succeeded"`
+	Failed    int    `bson:"failed" json:"failed"`
+	// Error says why a failed job could not run.
+	Error     string     `bson:"error,omitempty" json:"error,omitempty"`
+	Attempts  int        `bson:"attempts" json:"attempts"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	StartedAt *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	// LeaseUntil is when a running job may be claimed by another worker.
+	LeaseUntil  *time.Time `bson:"lease_until,omitempty" json:"-"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	// ExpiresAt is when a finished job is deleted.
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+// Finished reports whether the job has completed or failed.
+func (j *CalculationJob) Finished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed
+}
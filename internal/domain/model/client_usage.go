@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClientUsage counts the requests one client made with one user agent and
+// client version. It lets operators see which integrators still run outdated
+// SDKs before shipping a breaking change.
+type ClientUsage struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	// Client identifies the caller: "api_key:<fingerprint>" or "user:<id>".
+	Client    string `bson:"client" json:"client"`
+	UserAgent string `bson:"user_agent" json:"user_agent"`
+	// ClientName and ClientVersion come from the X-Client-Version header or,
+	// failing that, the first product token of the user agent.
+	ClientName    string    `bson:"client_name,omitempty" json:"client_name,omitempty"`
+	ClientVersion string    `bson:"client_version" json:"client_version"`
+	RequestCount  int64     `bson:"request_count" json:"request_count"`
+	FirstSeen     time.Time `bson:"first_seen" json:"first_seen"`
+	LastSeen      time.Time `bson:"last_seen" json:"last_seen"`
+}
@@ -0,0 +1,58 @@
+package model
+
+// Alternative kinds: how an alternative combination was found.
+const (
+	// AlternativeFewerItems is the fewest-pack combination of a total below
+	// the result's, ruled out by the constraints.
+	AlternativeFewerItems = "fewer_items"
+	// AlternativeMoreItems is the fewest-pack combination of a total above
+	// the result's.
+	AlternativeMoreItems = "more_items"
+	// AlternativeSplitPack is the result with one pack replaced by smaller
+	// packs of the same total.
+	AlternativeSplitPack = "split_pack"
+	// AlternativeGreedy fills the order with the largest packs first.
+	AlternativeGreedy = "greedy"
+)
+
+// Reasons an alternative was not chosen.
+const (
+	// ReasonMoreItems means the alternative ships more items.
+	ReasonMoreItems = "more_items"
+	// ReasonMorePacks means the alternative ships as many items in more packs.
+	ReasonMorePacks = "more_packs"
+	// ReasonExceedsMaxPacks means the alternative uses more packs than max_packs.
+	ReasonExceedsMaxPacks = "exceeds_max_packs"
+	// ReasonExceedsMaxOverage means the alternative ships more items than
+	// max_overage_items or max_overage_percent allow.
+	ReasonExceedsMaxOverage = "exceeds_max_overage"
+)
+
+// Explanation tells why a result was chosen over the combinations it was
+// compared with.
+//
+// @Description Why the result was chosen, and the combinations it was preferred to
+type Explanation struct {
+	// Summary states the choice in words, for support staff
+	Summary string `json:"summary" example:"500 items is the smallest total covering the order of 251 (249 over), and no combination ships it in fewer than 1 pack"`
+	// Alternatives are the combinations the result was preferred to, best first
+	Alternatives []Alternative `json:"alternatives"`
+}
+
+// Alternative is a combination that was considered but not chosen.
+//
+// @Description Combination considered instead of the result
+type Alternative struct {
+	// Kind tells how the alternative was found, e.g. AlternativeSplitPack
+	Kind string `json:"kind" example:"split_pack" enums:"fewer_items,more_items,split_pack,greedy"`
+	// TotalItems is the number of items the alternative ships
+	TotalItems int `json:"total_items" example:"500"`
+	// OverageItems is how many items it ships beyond the order
+	OverageItems int `json:"overage_items" example:"249"`
+	// PackCount is the number of packs it uses
+	PackCount int `json:"pack_count" example:"2"`
+	// Packs is the combination
+	Packs []Pack `json:"packs"`
+	// Reason tells why the result was chosen instead, e.g. ReasonMorePacks
+	Reason string `json:"reason" example:"more_packs" enums:"more_items,more_packs,exceeds_max_packs,exceeds_max_overage"`
+}
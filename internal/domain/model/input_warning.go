@@ -0,0 +1,26 @@
+package model
+
+// Input warning codes. Warnings flag calculate inputs that are valid but look
+// like client bugs; they never cause a request to be rejected.
+const (
+	// WarningScaledPackSize flags an order equal to a pack size times 1000,
+	// which usually means the client sent the wrong unit.
+	WarningScaledPackSize = "items_ordered_scaled_pack_size"
+	// WarningRepeatedLargeOrder flags the same large order sent repeatedly
+	// in a short window, which usually means a client retry loop.
+	WarningRepeatedLargeOrder = "repeated_large_order"
+	// WarningIgnoredPackSizes flags non-positive pack sizes that were dropped.
+	WarningIgnoredPackSizes = "ignored_pack_sizes"
+	// WarningDuplicatePackSizes flags pack sizes listed more than once.
+	WarningDuplicatePackSizes = "duplicate_pack_sizes"
+)
+
+// InputWarning describes a suspicious but accepted request input.
+type InputWarning struct {
+	// Code identifies the kind of warning, e.g. WarningScaledPackSize
+	Code string `json:"code"`
+	// Field is the request field the warning is about
+	Field string `json:"field"`
+	// Message explains the warning to the integrator
+	Message string `json:"message"`
+}
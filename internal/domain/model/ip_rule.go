@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IP rule actions.
+const (
+	// IPRuleAllow lets requests from the range through. Once any allow rule
+	// exists, requests matching none are rejected.
+	IPRuleAllow = "allow"
+	// IPRuleDeny rejects requests from the range.
+	IPRuleDeny = "deny"
+)
+
+// IPRule allows or denies requests from a CIDR range. Rules added through
+// the admin API are stored in MongoDB; rules from the configuration are not
+// stored and cannot be deleted at runtime.
+type IPRule struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CIDR   string             `bson:"cidr" json:"cidr" example:"203.0.113.0/24"`
+	Action string             `bson:"action" json:"action" example:"deny"`
+	Reason string             `bson:"reason,omitempty" json:"reason,omitempty" example:"credential stuffing"`
+	// CreatedBy is the ID of the admin who added the rule.
+	CreatedBy string    `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	// ExpiresAt is when the rule stops applying and is deleted; nil keeps it.
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	// Configured marks the rules from the configuration. It is not stored.
+	Configured bool `bson:"-" json:"configured"`
+}
+
+// Expired reports whether the rule stopped applying at now.
+func (r *IPRule) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
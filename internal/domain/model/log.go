@@ -16,6 +16,9 @@ type LogEntry struct {
 	Level      string                      `bson:"level" json:"level"`
 	Message    string                      `bson:"message" json:"message"`
 	RequestID  string                      `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	// CorrelationID is the caller's X-Correlation-ID, or the request ID
+	CorrelationID string                   `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
+	TraceID    string                      `bson:"trace_id,omitempty" json:"trace_id,omitempty"`
 	Method     string                      `bson:"method,omitempty" json:"method,omitempty"`
 	Path       string                      `bson:"path,omitempty" json:"path,omitempty"`
 	StatusCode int                         `bson:"status_code,omitempty" json:"status_code,omitempty"`
@@ -30,6 +33,22 @@ type LogEntry struct {
 	Fields     map[string]interface{}      `bson:"fields,omitempty" json:"fields,omitempty"`
 }
 
+// Audit action types and field names shared between writers and queries.
+const (
+	// ActionCalculate is the action type of pack calculation audit entries.
+	ActionCalculate = "calculate"
+	// ActionCalculateBatch is the action type of batch calculation audit entries.
+	ActionCalculateBatch = "calculate_batch"
+	// ActionCalculateJob is the action type of calculation job submissions.
+	ActionCalculateJob = "calculate_job"
+	// ActionReloadConfig is the action type of config reload audit entries.
+	ActionReloadConfig = "reload_config"
+	// ActionLoginAnomaly is the action type of suspicious login audit entries.
+	ActionLoginAnomaly = "login_anomaly"
+	// FieldPackSizesVersion holds the pack size config version a calculation used.
+	FieldPackSizesVersion = "pack_sizes_version"
+)
+
 // WithField adds a field to the log entry's Fields map.
 // If Fields is nil, it will be initialized.
 func (e *LogEntry) WithField(key string, value interface{}) *LogEntry {
@@ -58,8 +77,47 @@ type LogQueryOptions struct {
 	Level     string
 	Method    string
 	Path      string
+	// CorrelationID matches the entries of every request of one operation.
+	CorrelationID string
+	// User matches the user ID or email of audit entries.
+	User string
+	// ActionType matches the action of audit entries.
+	ActionType string
+	// AuditOnly keeps only audit entries, which have an action type.
+	AuditOnly bool
 	StartTime *time.Time
 	EndTime   *time.Time
 	Limit     int
 	Skip      int
 }
+
+// ConfigVersionImpact summarises the calculations performed with one pack size
+// configuration version.
+type ConfigVersionImpact struct {
+	Version      int       `json:"version"`
+	Calculations int64     `json:"calculations"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// LogRetention configures which log entries are kept.
+type LogRetention struct {
+	// MaxAge is how long entries are kept unless their level has its own
+	// retention; zero keeps them.
+	MaxAge time.Duration
+	// LevelMaxAge is how long entries of a level, such as "error", are kept,
+	// overriding MaxAge.
+	LevelMaxAge map[string]time.Duration
+	// MaxEntries caps the stored entries, deleting the oldest; zero keeps
+	// any number.
+	MaxEntries int64
+}
+
+// Longest returns the longest age retention keeps entries of any level.
+func (r LogRetention) Longest() time.Duration {
+	longest := r.MaxAge
+	for _, age := range r.LevelMaxAge {
+		longest = max(longest, age)
+	}
+	return longest
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -121,3 +122,11 @@ func TestLogEntry_WithFields(t *testing.T) {
 		})
 	}
 }
+
+func TestLogRetention_Longest(t *testing.T) {
+	assert.Zero(t, LogRetention{}.Longest())
+	assert.Equal(t, 90*24*time.Hour, LogRetention{
+		MaxAge:      30 * 24 * time.Hour,
+		LevelMaxAge: map[string]time.Duration{"error": 90 * 24 * time.Hour, "debug": time.Hour},
+	}.Longest())
+}
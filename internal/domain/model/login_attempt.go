@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// LoginAttempt counts the consecutive failed logins for an account or a
+// client IP, and how long it is locked out for after too many.
+type LoginAttempt struct {
+	// Key identifies what is tracked, e.g. "account:jane@example.com" or "ip:203.0.113.7".
+	Key         string    `bson:"_id" json:"key"`
+	Failures    int       `bson:"failures" json:"failures"`
+	LockedUntil time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	// ExpiresAt is when the document is forgotten: failures are counted
+	// within a window, and locks last until LockedUntil.
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// IsLocked reports whether the attempt is locked out at now.
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a != nil && now.Before(a.LockedUntil)
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// MessageOverride replaces the wording of one message of the i18n catalog,
+// in one locale, for one tenant.
+type MessageOverride struct {
+	// Tenant is the API key fingerprint of the tenant the override is for.
+	Tenant string `bson:"tenant" json:"tenant"`
+	Locale string `bson:"locale" json:"locale"`
+	// Key is the catalog key of the message, e.g. "error.rate_limit_exceeded".
+	Key     string `bson:"key" json:"key"`
+	Message string `bson:"message" json:"message"`
+	// UpdatedBy is the ID of the admin who last set the override.
+	UpdatedBy string    `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -1,6 +1,8 @@
 // Package model defines the core domain entities for the pack service.
 package model
 
+import "math"
+
 // Pack represents a single pack configuration in an order fulfillment.
 //
 // @Description Pack size and quantity used in the order
@@ -29,6 +31,36 @@ type PackResult struct {
 	TotalItems int `json:"total_items" example:"500"`
 	// Packs is the list of packs used to fulfill the order
 	Packs []Pack `json:"packs"`
+	// TotalCost is the cost of all the packs, when the pack sizes have costs
+	TotalCost *float64 `json:"total_cost,omitempty" example:"4.5"`
+	// Metadata echoes the metadata sent with the request
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Approximate is true when the result is a fast greedy combination that
+	// may not be optimal: the compute time limit ran out, or the order was
+	// too large to search exactly in bounded memory
+	Approximate bool `json:"approximate,omitempty"`
+	// GreedyDiffers is true when filling the order with the largest packs
+	// first would have shipped more items or packs than this result, or
+	// broken its constraints
+	GreedyDiffers bool `json:"greedy_differs,omitempty"`
+	// Explanation tells why this combination was chosen, when the request
+	// asked for it. It is never cached or stored.
+	Explanation *Explanation `json:"explanation,omitempty" bson:"-"`
+	// Presentation lays the result out by the deployment's presentation
+	// rules, when it has some. It is never cached or stored.
+	Presentation *Presentation `json:"presentation,omitempty" bson:"-"`
+	// CacheHit is true when the result came from the result cache. It is
+	// only traced, never returned or stored.
+	CacheHit bool `json:"-" bson:"-"`
+}
+
+// PackCount returns the total number of packs in the result.
+func (r PackResult) PackCount() int {
+	count := 0
+	for _, pack := range r.Packs {
+		count += pack.Quantity
+	}
+	return count
 }
 
 // Empty returns an empty PackResult for the given order amount.
@@ -39,3 +71,60 @@ func Empty(orderedItems int) PackResult {
 		Packs:        []Pack{},
 	}
 }
+
+// Optimization modes, which tell the best of the acceptable pack
+// combinations for an order.
+const (
+	// OptimizeMinItems ships the fewest items, then uses the fewest packs.
+	// It is the default.
+	OptimizeMinItems = "min_items"
+	// OptimizeMinCost uses the cheapest combination by the pack costs, then
+	// ships the fewest items, then uses the fewest packs.
+	OptimizeMinCost = "min_cost"
+)
+
+// PackConstraints limits which pack combinations are acceptable for an
+// order and chooses the best of them. Nil fields are unconstrained.
+type PackConstraints struct {
+	// MaxPacks is the most packs the result may contain in total.
+	MaxPacks *int `bson:"max_packs,omitempty" json:"max_packs,omitempty"`
+	// MaxOverageItems is the most items the result may ship beyond the order.
+	MaxOverageItems *int `bson:"max_overage_items,omitempty" json:"max_overage_items,omitempty"`
+	// MaxOveragePercent is the most items the result may ship beyond the
+	// order, as a percentage of the order.
+	MaxOveragePercent *float64 `bson:"max_overage_percent,omitempty" json:"max_overage_percent,omitempty"`
+	// Optimize is the optimization mode; empty means OptimizeMinItems.
+	Optimize string `bson:"optimize,omitempty" json:"optimize,omitempty"`
+	// PackCosts is the cost of one pack of each size. OptimizeMinCost needs
+	// a cost for every size; with other modes the costs are only totalled.
+	PackCosts map[int]float64 `bson:"pack_costs,omitempty" json:"pack_costs,omitempty"`
+}
+
+// IsZero reports whether no constraint is set, no pack has a cost and the
+// optimization mode is the default.
+func (c PackConstraints) IsZero() bool {
+	return c.MaxPacks == nil && c.MaxOverageItems == nil && c.MaxOveragePercent == nil &&
+		!c.MinCost() && len(c.PackCosts) == 0
+}
+
+// MinCost reports whether the optimization mode is OptimizeMinCost.
+func (c PackConstraints) MinCost() bool {
+	return c.Optimize == OptimizeMinCost
+}
+
+// MaxTotalItems returns the most items a result for orderedItems may ship
+// under the overage constraints, and false when overage is unconstrained.
+func (c PackConstraints) MaxTotalItems(orderedItems int) (int, bool) {
+	if c.MaxOverageItems == nil && c.MaxOveragePercent == nil {
+		return 0, false
+	}
+	overage := math.MaxInt32
+	if c.MaxOverageItems != nil {
+		overage = min(overage, *c.MaxOverageItems)
+	}
+	if c.MaxOveragePercent != nil {
+		byPercent := float64(orderedItems) * *c.MaxOveragePercent / 100
+		overage = min(overage, int(min(byPercent, math.MaxInt32)))
+	}
+	return orderedItems + overage, true
+}
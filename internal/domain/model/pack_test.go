@@ -1,6 +1,7 @@
 package model
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,6 +37,13 @@ func TestPack_TotalItems(t *testing.T) {
 	}
 }
 
+func TestPackResult_PackCount(t *testing.T) {
+	result := PackResult{Packs: []Pack{{Size: 5000, Quantity: 2}, {Size: 250, Quantity: 1}}}
+
+	assert.Equal(t, 3, result.PackCount())
+	assert.Equal(t, 0, Empty(0).PackCount())
+}
+
 func TestEmpty(t *testing.T) {
 	result := Empty(100)
 
@@ -60,3 +68,29 @@ func TestPackResult_JSON(t *testing.T) {
 	assert.Equal(t, 500, result.Packs[0].Size)
 	assert.Equal(t, 1, result.Packs[0].Quantity)
 }
+
+func TestPackConstraints_MaxTotalItems(t *testing.T) {
+	items, percent := 10, 5.0
+
+	tests := []struct {
+		name        string
+		constraints PackConstraints
+		expected    int
+		ok          bool
+	}{
+		{"unconstrained", PackConstraints{}, 0, false},
+		{"max packs only", PackConstraints{MaxPacks: &items}, 0, false},
+		{"overage items", PackConstraints{MaxOverageItems: &items}, 1010, true},
+		{"overage percent", PackConstraints{MaxOveragePercent: &percent}, 1050, true},
+		{"tighter of both", PackConstraints{MaxOverageItems: &items, MaxOveragePercent: &percent}, 1010, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, ok := tt.constraints.MaxTotalItems(1000)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, total)
+			assert.Equal(t, reflect.DeepEqual(tt.constraints, PackConstraints{}), tt.constraints.IsZero())
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package model
+
+// Presentation is a result laid out by the deployment's presentation rules
+// for display. The packs of the result are unchanged.
+//
+// @Description Result laid out for display by the deployment's presentation rules
+type Presentation struct {
+	// Lines are the pack lines to display, in order
+	Lines []PresentationLine `json:"lines"`
+	// Pallets is the number of pallets the shipment fills, when the rules
+	// set the items a pallet holds
+	Pallets *float64 `json:"pallets,omitempty" example:"1.25"`
+}
+
+// PresentationLine is a line of a Presentation: the packs of one size, or
+// small lines merged together.
+//
+// @Description Displayed pack line
+type PresentationLine struct {
+	// Size is the pack size, zero on a merged line
+	Size int `json:"size,omitempty" example:"500"`
+	// Quantity is the number of packs on the line
+	Quantity int `json:"quantity" example:"2"`
+	// Items is the number of items the line ships
+	Items int `json:"items" example:"1000"`
+	// Merged lists the packs of a merged line
+	Merged []Pack `json:"merged,omitempty"`
+}
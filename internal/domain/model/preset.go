@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Preset is a named, saved calculation configuration that calculate
+// requests can reference instead of sending pack sizes and constraints
+// inline.
+type Preset struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	// Owner is the ID of the user the preset belongs to. It is empty for
+	// presets created while authentication is disabled, which are shared.
+	Owner       string    `bson:"owner" json:"-"`
+	Name        string    `bson:"name" json:"name"`
+	Description string    `bson:"description,omitempty" json:"description,omitempty"`
+	PackSizes   []int     `bson:"pack_sizes" json:"pack_sizes"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+
+	// Constraints are the result constraints, optimization strategy and
+	// pack costs calculate requests using the preset get by default.
+	Constraints *PackConstraints `bson:"constraints,omitempty" json:"constraints,omitempty"`
+}
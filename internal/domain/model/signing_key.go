@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// SigningKey is a pair of secrets the service signs JWTs with: one for
+// access tokens and one for refresh tokens. Tokens name the key that signed
+// them in their kid header. Secrets are never returned by the API.
+type SigningKey struct {
+	KID           string `bson:"kid" json:"kid"`
+	Secret        []byte `bson:"secret" json:"-"`
+	RefreshSecret []byte `bson:"refresh_secret" json:"-"`
+	// CreatedBy is the ID of the admin who added the key.
+	CreatedBy string    `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	// RetiredAt is when the key was retired; tokens it signed are rejected
+	// from then on.
+	RetiredAt *time.Time `bson:"retired_at,omitempty" json:"retired_at,omitempty"`
+	// Signing tells whether new tokens are signed with the key. It is not
+	// stored.
+	Signing bool `bson:"-" json:"signing"`
+}
+
+// Active reports whether the key is not retired.
+func (k *SigningKey) Active() bool {
+	return k.RetiredAt == nil
+}
@@ -2,6 +2,8 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,6 +20,50 @@ type User struct {
 	Active    bool               `bson:"active" json:"active"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	// DeletedAt is when the user was soft deleted, nil while active. Users
+	// deactivated before it was recorded have none.
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	// TokenVersion is embedded in issued tokens and incremented to revoke
+	// all of them at once.
+	TokenVersion int `bson:"token_version,omitempty" json:"-"`
+	// LastLoginAt is when the user last logged in, nil if they have not
+	// since logins were recorded.
+	LastLoginAt *time.Time `bson:"last_login_at,omitempty" json:"last_login_at,omitempty"`
+	// LastLoginIP is the client IP of the last login.
+	LastLoginIP string `bson:"last_login_ip,omitempty" json:"last_login_ip,omitempty"`
+	// RecentLogins holds the last MaxRecentLogins logins, most recent first.
+	RecentLogins []LoginRecord `bson:"recent_logins,omitempty" json:"recent_logins,omitempty"`
+}
+
+// MaxRecentLogins is how many logins User.RecentLogins keeps.
+const MaxRecentLogins = 10
+
+// LoginRecord is one successful login.
+type LoginRecord struct {
+	At time.Time `bson:"at" json:"at"`
+	IP string    `bson:"ip,omitempty" json:"ip,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code the edge resolved the IP to.
+	Country string `bson:"country,omitempty" json:"country,omitempty"`
+}
+
+// AddLogin records login on the user the way the repository stores it.
+func (u *User) AddLogin(login LoginRecord) {
+	u.LastLoginAt = &login.At
+	u.LastLoginIP = login.IP
+	u.RecentLogins = append([]LoginRecord{login}, u.RecentLogins...)
+	if len(u.RecentLogins) > MaxRecentLogins {
+		u.RecentLogins = u.RecentLogins[:MaxRecentLogins]
+	}
+}
+
+// PreviousLogin returns the login before the most recent one, nil if there
+// is none. Shown at login, it lets users spot access they did not make.
+func (u *User) PreviousLogin() *LoginRecord {
+	if len(u.RecentLogins) < 2 {
+		return nil
+	}
+	previous := u.RecentLogins[1]
+	return &previous
 }
 
 // Role represents a role in the system.
@@ -43,12 +89,24 @@ type Permission struct {
 	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
+// HashedTokenPrefix starts the Token of a stored refresh token, which holds
+// a SHA-256 hash of the token instead of the token itself. Refresh tokens
+// stored before hashing was introduced have no prefix.
+const HashedTokenPrefix = "sha256:"
+
+// HashToken returns the value stored for a hashed token.
+func HashToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return HashedTokenPrefix + hex.EncodeToString(sum[:])
+}
+
 // Token represents a refresh token or blacklisted token.
 type Token struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Token     string             `bson:"token" json:"token"`
+	Token     string             `bson:"token" json:"token"` // SHA-256 for refresh tokens (see HashToken)
 	Type      string             `bson:"type" json:"type"` // "refresh" or "blacklist"
+	JKT       string             `bson:"jkt,omitempty" json:"jkt,omitempty"` // DPoP key thumbprint the token is bound to
 	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 }
@@ -1,7 +1,9 @@
 package model
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -105,3 +107,29 @@ func TestUser_HasPermission(t *testing.T) {
 		})
 	}
 }
+
+func TestHashToken(t *testing.T) {
+	hashed := HashToken("refresh-token")
+
+	assert.True(t, strings.HasPrefix(hashed, HashedTokenPrefix))
+	assert.Len(t, hashed, len(HashedTokenPrefix)+64)
+	assert.Equal(t, hashed, HashToken("refresh-token"))
+	assert.NotEqual(t, hashed, HashToken("other-token"))
+}
+
+func TestUser_AddLogin(t *testing.T) {
+	user := &User{}
+	assert.Nil(t, user.PreviousLogin())
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := range MaxRecentLogins + 2 {
+		user.AddLogin(LoginRecord{At: start.Add(time.Duration(i) * time.Hour), IP: "10.0.0.1"})
+	}
+
+	last := start.Add(time.Duration(MaxRecentLogins+1) * time.Hour)
+	assert.Equal(t, last, *user.LastLoginAt)
+	assert.Equal(t, "10.0.0.1", user.LastLoginIP)
+	assert.Len(t, user.RecentLogins, MaxRecentLogins)
+	assert.Equal(t, last, user.RecentLogins[0].At)
+	assert.Equal(t, last.Add(-time.Hour), user.PreviousLogin().At)
+}
@@ -0,0 +1,221 @@
+// Package dpop verifies DPoP proofs (RFC 9449) used to bind access tokens to a client key.
+//
+// A client generates a key pair and sends a short-lived proof JWT, signed with the
+// private key and carrying the public key in its header, in the DPoP request header.
+// Tokens issued against a proof carry the key's thumbprint, and every request made
+// with such a token must include a fresh proof signed by the same key, so a stolen
+// token cannot be replayed without the key.
+package dpop
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// HeaderName is the request header carrying the proof JWT.
+const HeaderName = "DPoP"
+
+// proofType is the required typ header of a proof JWT.
+const proofType = "dpop+jwt"
+
+var (
+	// ErrMissingProof is returned when a request has no DPoP proof.
+	ErrMissingProof = errors.New("dpop proof is required")
+	// ErrInvalidProof is returned when a proof is malformed, badly signed or does not match the request.
+	ErrInvalidProof = errors.New("invalid dpop proof")
+	// ErrReplayedProof is returned when a proof's jti has already been used.
+	ErrReplayedProof = errors.New("dpop proof has already been used")
+	// ErrReplayCacheFull is returned when too many proofs are still within their
+	// lifetime to remember another one.
+	ErrReplayCacheFull = errors.New("too many dpop proofs in flight")
+)
+
+// supportedAlgorithms lists the asymmetric algorithms accepted for proofs.
+var supportedAlgorithms = []string{"ES256", "ES384", "RS256", "PS256", "EdDSA"}
+
+// Proof is a verified DPoP proof.
+type Proof struct {
+	// Thumbprint is the RFC 7638 SHA-256 thumbprint of the proof's public key.
+	Thumbprint string
+	// ID is the proof's unique identifier (jti).
+	ID string
+	// IssuedAt is when the client created the proof.
+	IssuedAt time.Time
+}
+
+type proofClaims struct {
+	HTM string `json:"htm"`
+	HTU string `json:"htu"`
+	ATH string `json:"ath,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Config holds proof verification settings.
+type Config struct {
+	// MaxAge is how long after its iat a proof is accepted.
+	MaxAge time.Duration
+	// ClockSkew tolerates proofs issued slightly in the future.
+	ClockSkew time.Duration
+	// ReplayCacheSize bounds the number of remembered proof identifiers. Once
+	// that many proofs are within their lifetime, new ones are rejected, so it
+	// should exceed the peak proof rate times MaxAge plus ClockSkew.
+	ReplayCacheSize int
+}
+
+// DefaultConfig returns the default verification settings.
+func DefaultConfig() Config {
+	return Config{
+		MaxAge:          time.Minute,
+		ClockSkew:       10 * time.Second,
+		ReplayCacheSize: 100000,
+	}
+}
+
+// Verifier checks DPoP proofs and rejects replays within the proof lifetime.
+type Verifier struct {
+	cfg    Config
+	replay *replayCache
+	now    func() time.Time
+}
+
+// NewVerifier creates a Verifier. Zero config values fall back to DefaultConfig.
+func NewVerifier(cfg Config) *Verifier {
+	defaults := DefaultConfig()
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaults.MaxAge
+	}
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = defaults.ClockSkew
+	}
+	if cfg.ReplayCacheSize <= 0 {
+		cfg.ReplayCacheSize = defaults.ReplayCacheSize
+	}
+
+	return &Verifier{
+		cfg:    cfg,
+		replay: newReplayCache(cfg.ReplayCacheSize),
+		now:    time.Now,
+	}
+}
+
+// Verify checks a proof against the request method and URL. When accessToken is
+// non-empty the proof must also carry its hash (ath), as required when presenting
+// a bound token; token endpoints pass an empty accessToken.
+func (v *Verifier) Verify(proof, method, requestURL, accessToken string) (*Proof, error) {
+	if proof == "" {
+		return nil, ErrMissingProof
+	}
+
+	var thumbprint string
+	claims := &proofClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(supportedAlgorithms), jwt.WithoutClaimsValidation())
+	_, err := parser.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != proofType {
+			return nil, fmt.Errorf("unexpected typ %q", typ)
+		}
+		jwk, ok := token.Header["jwk"].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("missing jwk header")
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			return nil, err
+		}
+		if !key.supports(token.Method.Alg()) {
+			return nil, fmt.Errorf("key type does not match alg %q", token.Method.Alg())
+		}
+		thumbprint = key.thumbprint
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	if claims.ID == "" {
+		return nil, fmt.Errorf("%w: missing jti", ErrInvalidProof)
+	}
+	if claims.HTM != method {
+		return nil, fmt.Errorf("%w: htm does not match request method", ErrInvalidProof)
+	}
+	if !sameURL(claims.HTU, requestURL) {
+		return nil, fmt.Errorf("%w: htu does not match request URL", ErrInvalidProof)
+	}
+	if accessToken != "" && claims.ATH != TokenHash(accessToken) {
+		return nil, fmt.Errorf("%w: ath does not match access token", ErrInvalidProof)
+	}
+
+	if claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: missing iat", ErrInvalidProof)
+	}
+	now := v.now()
+	issuedAt := claims.IssuedAt.Time
+	if issuedAt.After(now.Add(v.cfg.ClockSkew)) || now.Sub(issuedAt) > v.cfg.MaxAge {
+		return nil, fmt.Errorf("%w: iat outside the accepted window", ErrInvalidProof)
+	}
+
+	// Remember the proof until it can no longer pass the iat check
+	if err := v.replay.add(thumbprint+":"+claims.ID, issuedAt.Add(v.cfg.MaxAge+v.cfg.ClockSkew), now); err != nil {
+		return nil, err
+	}
+
+	return &Proof{Thumbprint: thumbprint, ID: claims.ID, IssuedAt: issuedAt}, nil
+}
+
+// TokenHash returns the ath value for an access token: the base64url-encoded
+// SHA-256 hash of its ASCII representation.
+func TokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// RequestURL returns the htu a proof for r must carry: the request URL without
+// query or fragment. The scheme honours X-Forwarded-Proto for TLS-terminating proxies.
+func RequestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// sameURL compares two URLs as RFC 9449 requires: scheme and host case-insensitively,
+// path exactly, and ignoring query and fragment.
+func sameURL(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil || ua.Host == "" {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil || ub.Host == "" {
+		return false
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) &&
+		strings.EqualFold(ua.Host, ub.Host) &&
+		ua.EscapedPath() == ub.EscapedPath()
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the thumbprint tokens should be bound to.
+func NewContext(ctx context.Context, thumbprint string) context.Context {
+	return context.WithValue(ctx, contextKey{}, thumbprint)
+}
+
+// ThumbprintFromContext returns the thumbprint stored by NewContext, or "" when
+// the request did not present a proof.
+func ThumbprintFromContext(ctx context.Context) string {
+	thumbprint, _ := ctx.Value(contextKey{}).(string)
+	return thumbprint
+}
@@ -0,0 +1,340 @@
+//go:build !integration
+
+package dpop
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testURL = "https://api.example.com/api/calculate"
+
+// testKey is a client key pair used to sign proofs.
+type testKey struct {
+	private interface{}
+	method  jwt.SigningMethod
+	jwk     map[string]interface{}
+}
+
+func newECKey(t *testing.T) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	point, err := key.PublicKey.Bytes()
+	require.NoError(t, err)
+	return testKey{
+		private: key,
+		method:  jwt.SigningMethodES256,
+		jwk: map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(point[1:33]),
+			"y":   base64.RawURLEncoding.EncodeToString(point[33:]),
+		},
+	}
+}
+
+func newEd25519Key(t *testing.T) testKey {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testKey{
+		private: private,
+		method:  jwt.SigningMethodEdDSA,
+		jwk: map[string]interface{}{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(public),
+		},
+	}
+}
+
+func (k testKey) proof(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = k.jwk
+	signed, err := token.SignedString(k.private)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(jti string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"jti": jti,
+		"htm": "POST",
+		"htu": testURL,
+		"iat": time.Now().Unix(),
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	for _, key := range []testKey{newECKey(t), newEd25519Key(t)} {
+		t.Run(key.method.Alg(), func(t *testing.T) {
+			v := NewVerifier(DefaultConfig())
+
+			proof, err := v.Verify(key.proof(t, validClaims("proof-1")), "POST", testURL, "")
+
+			require.NoError(t, err)
+			want, err := Thumbprint(key.jwk)
+			require.NoError(t, err)
+			assert.Equal(t, want, proof.Thumbprint)
+			assert.Equal(t, "proof-1", proof.ID)
+		})
+	}
+}
+
+func TestVerifier_Verify_AccessTokenHash(t *testing.T) {
+	key := newECKey(t)
+	v := NewVerifier(DefaultConfig())
+
+	claims := validClaims("proof-1")
+	claims["ath"] = TokenHash("access-token")
+	_, err := v.Verify(key.proof(t, claims), "POST", testURL, "access-token")
+	assert.NoError(t, err)
+
+	claims = validClaims("proof-2")
+	claims["ath"] = TokenHash("another-token")
+	_, err = v.Verify(key.proof(t, claims), "POST", testURL, "access-token")
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	_, err = v.Verify(key.proof(t, validClaims("proof-3")), "POST", testURL, "access-token")
+	assert.ErrorIs(t, err, ErrInvalidProof, "ath is required when presenting a token")
+}
+
+func TestVerifier_Verify_Rejects(t *testing.T) {
+	key := newECKey(t)
+
+	tests := []struct {
+		name    string
+		proof   func() string
+		method  string
+		url     string
+		wantErr error
+	}{
+		{
+			name:    "missing proof",
+			proof:   func() string { return "" },
+			wantErr: ErrMissingProof,
+		},
+		{
+			name:    "malformed proof",
+			proof:   func() string { return "not-a-jwt" },
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "wrong method",
+			proof: func() string {
+				return key.proof(t, validClaims("a"))
+			},
+			method:  "GET",
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "wrong url",
+			proof: func() string {
+				return key.proof(t, validClaims("a"))
+			},
+			url:     "https://api.example.com/api/presets",
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "expired",
+			proof: func() string {
+				claims := validClaims("a")
+				claims["iat"] = time.Now().Add(-2 * time.Minute).Unix()
+				return key.proof(t, claims)
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "issued in the future",
+			proof: func() string {
+				claims := validClaims("a")
+				claims["iat"] = time.Now().Add(time.Minute).Unix()
+				return key.proof(t, claims)
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "missing jti",
+			proof: func() string {
+				claims := validClaims("")
+				delete(claims, "jti")
+				return key.proof(t, claims)
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "wrong typ",
+			proof: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodES256, validClaims("a"))
+				token.Header["typ"] = "JWT"
+				token.Header["jwk"] = key.jwk
+				signed, _ := token.SignedString(key.private)
+				return signed
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "symmetric algorithm",
+			proof: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims("a"))
+				token.Header["typ"] = "dpop+jwt"
+				token.Header["jwk"] = key.jwk
+				signed, _ := token.SignedString([]byte("secret"))
+				return signed
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "signed by a different key",
+			proof: func() string {
+				other := newECKey(t)
+				other.jwk = key.jwk
+				return other.proof(t, validClaims("a"))
+			},
+			wantErr: ErrInvalidProof,
+		},
+		{
+			name: "private key in header",
+			proof: func() string {
+				leaky := key
+				leaky.jwk = map[string]interface{}{"d": "secret"}
+				for k, v := range key.jwk {
+					leaky.jwk[k] = v
+				}
+				return leaky.proof(t, validClaims("a"))
+			},
+			wantErr: ErrInvalidProof,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, url := tt.method, tt.url
+			if method == "" {
+				method = "POST"
+			}
+			if url == "" {
+				url = testURL
+			}
+
+			_, err := NewVerifier(DefaultConfig()).Verify(tt.proof(), method, url, "")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifier_Verify_RejectsReplay(t *testing.T) {
+	key := newECKey(t)
+	v := NewVerifier(DefaultConfig())
+	proof := key.proof(t, validClaims("proof-1"))
+
+	_, err := v.Verify(proof, "POST", testURL, "")
+	require.NoError(t, err)
+
+	_, err = v.Verify(proof, "POST", testURL, "")
+	assert.ErrorIs(t, err, ErrReplayedProof)
+
+	// The same jti from another key is a different proof
+	_, err = v.Verify(newECKey(t).proof(t, validClaims("proof-1")), "POST", testURL, "")
+	assert.NoError(t, err)
+}
+
+func TestVerifier_Verify_IgnoresQueryAndCase(t *testing.T) {
+	key := newECKey(t)
+	claims := validClaims("proof-1")
+	claims["htu"] = "HTTPS://API.example.com/api/calculate"
+
+	_, err := NewVerifier(DefaultConfig()).Verify(key.proof(t, claims), "POST", testURL+"?lang=en", "")
+
+	assert.NoError(t, err)
+}
+
+func TestThumbprint_RFC7638Example(t *testing.T) {
+	jwk := map[string]interface{}{
+		"kty": "RSA",
+		"n": "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiF" +
+			"V4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0" +
+			"zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csF" +
+			"Cur-kEgU8awapJzKnqDKgw",
+		"e":   "AQAB",
+		"alg": "RS256",
+		"kid": "2011-04-29",
+	}
+
+	thumbprint, err := Thumbprint(jwk)
+
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+}
+
+func TestVerifier_Verify_FloodCannotEvictProof(t *testing.T) {
+	key := newECKey(t)
+	v := NewVerifier(Config{ReplayCacheSize: 3})
+	proof := key.proof(t, validClaims("proof-1"))
+
+	_, err := v.Verify(proof, "POST", testURL, "")
+	require.NoError(t, err)
+
+	// Once the cache is full of live proofs, new ones are refused
+	for i := 0; i < 10; i++ {
+		_, err = v.Verify(key.proof(t, validClaims(fmt.Sprintf("flood-%d", i))), "POST", testURL, "")
+		if i < 2 {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrReplayCacheFull)
+		}
+	}
+
+	_, err = v.Verify(proof, "POST", testURL, "")
+	assert.ErrorIs(t, err, ErrReplayedProof)
+}
+
+func TestReplayCache_Bounded(t *testing.T) {
+	cache := newReplayCache(2)
+	now := time.Now()
+
+	assert.NoError(t, cache.add("a", now.Add(time.Minute), now))
+	assert.NoError(t, cache.add("b", now.Add(time.Minute), now))
+	assert.ErrorIs(t, cache.add("c", now.Add(time.Minute), now), ErrReplayCacheFull)
+	assert.ErrorIs(t, cache.add("a", now.Add(time.Minute), now), ErrReplayedProof)
+	assert.Equal(t, 2, cache.len())
+
+	// Expired entries are dropped before checking for a replay
+	assert.NoError(t, cache.add("b", now.Add(3*time.Minute), now.Add(2*time.Minute)))
+	assert.Equal(t, 1, cache.len())
+}
+
+func TestRequestURL(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://api.example.com/api/calculate?x=1", nil)
+	assert.Equal(t, "http://api.example.com/api/calculate", RequestURL(req))
+
+	req.TLS = &tls.ConnectionState{}
+	assert.Equal(t, "https://api.example.com/api/calculate", RequestURL(req))
+
+	req.TLS = nil
+	req.Header.Set("X-Forwarded-Proto", "https, http")
+	assert.Equal(t, "https://api.example.com/api/calculate", RequestURL(req))
+}
+
+func TestThumbprintContext(t *testing.T) {
+	assert.Empty(t, ThumbprintFromContext(context.Background()))
+	assert.Equal(t, "jkt", ThumbprintFromContext(NewContext(context.Background(), "jkt")))
+}
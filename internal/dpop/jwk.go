@@ -0,0 +1,199 @@
+package dpop
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// minRSABits is the smallest RSA modulus accepted for proof keys.
+const minRSABits = 2048
+
+type publicJWK struct {
+	public     interface{}
+	thumbprint string
+}
+
+// supports reports whether alg is the JWA algorithm for this key's type and curve.
+func (k *publicJWK) supports(alg string) bool {
+	switch key := k.public.(type) {
+	case *ecdsa.PublicKey:
+		return (alg == "ES256" && key.Curve == elliptic.P256()) ||
+			(alg == "ES384" && key.Curve == elliptic.P384())
+	case *rsa.PublicKey:
+		return alg == "RS256" || alg == "PS256"
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	default:
+		return false
+	}
+}
+
+// parseJWK converts a public JWK from a proof header into a verification key and
+// computes its thumbprint. Keys containing private material are rejected.
+func parseJWK(jwk map[string]interface{}) (*publicJWK, error) {
+	if _, ok := jwk["d"]; ok {
+		return nil, errors.New("jwk must not contain a private key")
+	}
+
+	member := func(name string) (string, error) {
+		value, _ := jwk[name].(string)
+		if value == "" {
+			return "", fmt.Errorf("jwk is missing %q", name)
+		}
+		return value, nil
+	}
+
+	kty, err := member("kty")
+	if err != nil {
+		return nil, err
+	}
+
+	// Thumbprint members are the required members only, per RFC 7638 section 3.2
+	members := map[string]string{"kty": kty}
+	var public interface{}
+
+	switch kty {
+	case "EC":
+		crv, err := member("crv")
+		if err != nil {
+			return nil, err
+		}
+		x, err := member("x")
+		if err != nil {
+			return nil, err
+		}
+		y, err := member("y")
+		if err != nil {
+			return nil, err
+		}
+		public, err = ecPublicKey(crv, x, y)
+		if err != nil {
+			return nil, err
+		}
+		members["crv"], members["x"], members["y"] = crv, x, y
+
+	case "RSA":
+		n, err := member("n")
+		if err != nil {
+			return nil, err
+		}
+		e, err := member("e")
+		if err != nil {
+			return nil, err
+		}
+		public, err = rsaPublicKey(n, e)
+		if err != nil {
+			return nil, err
+		}
+		members["n"], members["e"] = n, e
+
+	case "OKP":
+		crv, err := member("crv")
+		if err != nil {
+			return nil, err
+		}
+		x, err := member("x")
+		if err != nil {
+			return nil, err
+		}
+		if crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", crv)
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(x)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		public = ed25519.PublicKey(raw)
+		members["crv"], members["x"] = crv, x
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", kty)
+	}
+
+	thumbprint, err := thumbprintOf(members)
+	if err != nil {
+		return nil, err
+	}
+	return &publicJWK{public: public, thumbprint: thumbprint}, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of a public JWK.
+func Thumbprint(jwk map[string]interface{}) (string, error) {
+	key, err := parseJWK(jwk)
+	if err != nil {
+		return "", err
+	}
+	return key.thumbprint, nil
+}
+
+// thumbprintOf hashes the canonical JSON of the required members. encoding/json
+// sorts map keys and emits no whitespace, which is the canonical form.
+func thumbprintOf(members map[string]string) (string, error) {
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func ecPublicKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", crv)
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil || len(xb) != size {
+		return nil, errors.New("invalid EC x coordinate")
+	}
+	yb, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil || len(yb) != size {
+		return nil, errors.New("invalid EC y coordinate")
+	}
+
+	// ParseUncompressedPublicKey rejects points that are not on the curve
+	point := append(append([]byte{0x04}, xb...), yb...)
+	key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EC public key: %w", err)
+	}
+	return key, nil
+}
+
+func rsaPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, errors.New("invalid RSA modulus")
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil || len(eb) == 0 || len(eb) > 4 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+
+	modulus := new(big.Int).SetBytes(nb)
+	if modulus.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSABits)
+	}
+
+	exponent := int(new(big.Int).SetBytes(eb).Int64())
+	if exponent < 3 || exponent%2 == 0 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+
+	return &rsa.PublicKey{N: modulus, E: exponent}, nil
+}
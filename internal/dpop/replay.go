@@ -0,0 +1,73 @@
+package dpop
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// replayCache remembers proof identifiers until they expire. It is bounded:
+// when full of live entries it refuses new ones, since forgetting an identifier
+// before it expires would let its proof be replayed.
+type replayCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // oldest expiry at the front
+}
+
+type replayEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+func newReplayCache(capacity int) *replayCache {
+	return &replayCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// add records key until expiresAt. It returns ErrReplayedProof if key is
+// already recorded and has not expired, and ErrReplayCacheFull if there is no
+// room left for it.
+func (r *replayCache) add(key string, expiresAt, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.evictExpired(now)
+
+	if _, seen := r.entries[key]; seen {
+		return ErrReplayedProof
+	}
+	if r.order.Len() >= r.capacity {
+		return ErrReplayCacheFull
+	}
+
+	// Proofs share one lifetime, so appending keeps the list ordered by expiry
+	// apart from clock skew, which only delays eviction of a few entries.
+	r.entries[key] = r.order.PushBack(&replayEntry{key: key, expiresAt: expiresAt})
+	return nil
+}
+
+func (r *replayCache) evictExpired(now time.Time) {
+	for front := r.order.Front(); front != nil; front = r.order.Front() {
+		if front.Value.(*replayEntry).expiresAt.After(now) {
+			return
+		}
+		r.remove(front)
+	}
+}
+
+func (r *replayCache) remove(elem *list.Element) {
+	r.order.Remove(elem)
+	delete(r.entries, elem.Value.(*replayEntry).key)
+}
+
+// len returns the number of remembered identifiers.
+func (r *replayCache) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.order.Len()
+}
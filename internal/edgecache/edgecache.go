@@ -0,0 +1,212 @@
+// Package edgecache lets an API gateway or CDN in front of the service cache
+// anonymous calculate responses.
+//
+// Cacheable responses carry a shared-cache lifetime (s-maxage), a
+// deterministic key identifying equivalent requests, and surrogate keys
+// naming what the response depends on. When the pack sizes change, the
+// responses tagged with SurrogateKeyPackSizes are purged at the edge.
+package edgecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/httpclient"
+	"github.com/rs/zerolog/log"
+)
+
+// Response headers set on calculate responses.
+const (
+	// CacheKeyHeader carries the key of the normalized request.
+	CacheKeyHeader = "X-Cache-Key"
+	// SurrogateKeyHeader lists the surrogate keys of a cacheable response.
+	SurrogateKeyHeader = "Surrogate-Key"
+	// SurrogateControlHeader carries the edge lifetime for CDNs that prefer
+	// it over Cache-Control.
+	SurrogateControlHeader = "Surrogate-Control"
+)
+
+// Surrogate keys.
+const (
+	// SurrogateKeyCalculate tags every cacheable calculate response.
+	SurrogateKeyCalculate = "calculate"
+	// SurrogateKeyPackSizes tags responses calculated with the configured
+	// pack sizes, which change when the configuration does.
+	SurrogateKeyPackSizes = "pack-sizes"
+)
+
+// defaultPurgeTimeout bounds a purge request.
+const defaultPurgeTimeout = 5 * time.Second
+
+// Purger removes the responses tagged with a surrogate key from the edge.
+type Purger interface {
+	Purge(ctx context.Context, surrogateKey string) error
+}
+
+// Config configures a Policy.
+type Config struct {
+	// MaxAge is how long shared caches may serve a response.
+	MaxAge time.Duration
+	// Purger purges the edge when the pack sizes change; nil leaves
+	// responses to expire.
+	Purger Purger
+}
+
+// Policy sets the edge caching headers of calculate responses and purges
+// them when the pack sizes change.
+type Policy struct {
+	maxAge time.Duration
+	purger Purger
+}
+
+// New creates a Policy, or returns nil when cfg.MaxAge is not positive.
+func New(cfg Config) *Policy {
+	if cfg.MaxAge <= 0 {
+		return nil
+	}
+	return &Policy{maxAge: cfg.MaxAge, purger: cfg.Purger}
+}
+
+// Cacheable marks a response cacheable by shared caches for key, tagged with
+// the given surrogate keys. Browsers do not cache it.
+func (p *Policy) Cacheable(h http.Header, key string, surrogateKeys ...string) {
+	seconds := strconv.Itoa(int(p.maxAge.Seconds()))
+	h.Set("Cache-Control", "public, max-age=0, s-maxage="+seconds)
+	h.Set(SurrogateControlHeader, "max-age="+seconds)
+	h.Set(SurrogateKeyHeader, strings.Join(surrogateKeys, " "))
+	h.Set(CacheKeyHeader, key)
+	h.Add("Vary", "Accept-Language")
+}
+
+// Private marks a response that no shared cache may store.
+func (p *Policy) Private(h http.Header) {
+	h.Set("Cache-Control", "private, no-store")
+}
+
+// PurgePackSizes purges the responses calculated with the configured pack
+// sizes in the background, so the caller does not wait on the CDN.
+func (p *Policy) PurgePackSizes() {
+	if p.purger == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultPurgeTimeout)
+		defer cancel()
+		if err := p.purger.Purge(ctx, SurrogateKeyPackSizes); err != nil {
+			log.Warn().Err(err).Str("surrogate_key", SurrogateKeyPackSizes).Msg("Failed to purge edge cache")
+			return
+		}
+		log.Info().Str("surrogate_key", SurrogateKeyPackSizes).Msg("Edge cache purged")
+	}()
+}
+
+// Key returns the cache key of req: the hex SHA-256 of its normalized form.
+// Requests that calculate the same result get the same key whatever their
+// field order or formatting, the order and duplicates of pack_sizes, and
+// their max_compute_ms, which does not change an exact result. The
+// optimization mode is part of the key only when it is not the default.
+func Key(req *dto.CalculatePacksRequest) string {
+	sizes := make([]int, 0, len(req.PackSizes))
+	for _, size := range req.PackSizes {
+		if size > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	slices.Sort(sizes)
+	sizes = slices.Compact(sizes)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "items_ordered=%d;pack_sizes=", req.ItemsOrdered)
+	for i, size := range sizes {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(size))
+	}
+	fmt.Fprintf(&b, ";preset=%s;max_packs=%s;max_overage_items=%s;max_overage_percent=%s",
+		req.Preset, optional(req.MaxPacks), optional(req.MaxOverageItems), optional(req.MaxOveragePercent))
+	// Added only when set, so existing keys stay valid
+	if req.Explain {
+		b.WriteString(";explain=true")
+	}
+	if req.Optimize != "" && req.Optimize != model.OptimizeMinItems {
+		b.WriteString(";optimize=" + req.Optimize)
+	}
+	if len(req.PackCosts) > 0 {
+		b.WriteString(";pack_costs=")
+		for i, size := range slices.Sorted(maps.Keys(req.PackCosts)) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%d:%s", size, strconv.FormatFloat(req.PackCosts[size], 'f', -1, 64))
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// optional formats v, or "" when it is nil.
+func optional[T int | float64](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(*v)
+}
+
+// HTTPPurger purges surrogate keys by POSTing to a CDN purge URL.
+type HTTPPurger struct {
+	url    string
+	token  string
+	client *httpclient.Client
+}
+
+// NewHTTPPurger creates a purger for urlTemplate, in which "{key}" is
+// replaced by the surrogate key. A non-empty token is sent as a bearer token.
+// Purging twice is harmless, so failed purges are retried.
+func NewHTTPPurger(urlTemplate, token string) *HTTPPurger {
+	return &HTTPPurger{
+		url:   urlTemplate,
+		token: token,
+		client: httpclient.New(httpclient.Config{
+			Name:               "edge_purge",
+			Timeout:            defaultPurgeTimeout,
+			RetryNonIdempotent: true,
+		}),
+	}
+}
+
+// Purge purges surrogateKey. Any non-2xx response is an error.
+func (p *HTTPPurger) Purge(ctx context.Context, surrogateKey string) error {
+	url := strings.ReplaceAll(p.url, "{key}", surrogateKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("purge returned status %d", resp.StatusCode)
+	}
+	return nil
+}
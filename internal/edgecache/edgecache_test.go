@@ -0,0 +1,89 @@
+//go:build !integration
+
+package edgecache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestKey(t *testing.T) {
+	one, three := 1, 3
+
+	base := Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int{23, 31, 53}})
+	assert.Len(t, base, 64)
+	assert.Equal(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int{53, 23, 31, 23, 0}}),
+		"pack size order, duplicates and ignored sizes do not matter")
+	assert.Equal(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int{23, 31, 53}, MaxComputeMs: &one}))
+
+	assert.NotEqual(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 252, PackSizes: []int{23, 31, 53}}))
+	assert.NotEqual(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 251}))
+	assert.NotEqual(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int{23, 31, 53}, MaxPacks: &three}))
+	assert.NotEqual(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int{23, 31, 53}, Explain: true}))
+	assert.Equal(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int{23, 31, 53}, Optimize: model.OptimizeMinItems}))
+	assert.NotEqual(t, base, Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int{23, 31, 53}, Optimize: model.OptimizeMinCost}))
+	assert.NotEqual(t,
+		Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackCosts: map[int]float64{23: 1, 31: 1.5}}),
+		Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, PackCosts: map[int]float64{23: 1, 31: 2}}))
+	assert.NotEqual(t,
+		Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, MaxPacks: &three}),
+		Key(&dto.CalculatePacksRequest{ItemsOrdered: 251, MaxOverageItems: &three}))
+}
+
+func TestPolicy(t *testing.T) {
+	assert.Nil(t, New(Config{}), "disabled without a max age")
+
+	policy := New(Config{MaxAge: 10 * time.Minute})
+	require.NotNil(t, policy)
+
+	h := http.Header{}
+	policy.Cacheable(h, "abc", SurrogateKeyCalculate, SurrogateKeyPackSizes)
+	assert.Equal(t, "public, max-age=0, s-maxage=600", h.Get("Cache-Control"))
+	assert.Equal(t, "max-age=600", h.Get(SurrogateControlHeader))
+	assert.Equal(t, "calculate pack-sizes", h.Get(SurrogateKeyHeader))
+	assert.Equal(t, "abc", h.Get(CacheKeyHeader))
+	assert.Equal(t, "Accept-Language", h.Get("Vary"))
+
+	h = http.Header{}
+	policy.Private(h)
+	assert.Equal(t, "private, no-store", h.Get("Cache-Control"))
+}
+
+func TestHTTPPurger(t *testing.T) {
+	purged := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		purged <- r.URL.Path
+	}))
+	defer server.Close()
+
+	policy := New(Config{MaxAge: time.Minute, Purger: NewHTTPPurger(server.URL+"/purge/{key}", "secret")})
+	policy.PurgePackSizes()
+
+	select {
+	case path := <-purged:
+		assert.Equal(t, "/purge/pack-sizes", path)
+	case <-time.After(time.Second):
+		t.Fatal("purge was not sent")
+	}
+}
+
+func TestHTTPPurger_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewHTTPPurger(server.URL+"/{key}", "").Purge(context.Background(), SurrogateKeyPackSizes)
+	assert.EqualError(t, err, "purge returned status 403")
+}
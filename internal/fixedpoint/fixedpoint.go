@@ -0,0 +1,91 @@
+// Package fixedpoint converts decimal quantities, such as 2.5 (kg), to and
+// from integers counting units of a fixed precision, 2500 at three decimal
+// places, so they are added and compared exactly. Decimals are read from and
+// written to their text, never through floating point.
+package fixedpoint
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPrecision is the number of decimal places used unless configured
+	// otherwise: grams of a quantity in kilograms, millilitres of litres.
+	DefaultPrecision = 3
+	// MaxPrecision is the most decimal places supported.
+	MaxPrecision = 6
+)
+
+var (
+	// ErrInvalid is returned for text that is not a non-negative decimal
+	// number.
+	ErrInvalid = errors.New("invalid decimal")
+	// ErrTooPrecise is returned for a decimal with more decimal places than
+	// the precision.
+	ErrTooPrecise = errors.New("decimal has too many decimal places")
+	// ErrOutOfRange is returned for a decimal too large to count in units.
+	ErrOutOfRange = errors.New("decimal out of range")
+)
+
+// Parse returns the number of units of precision decimal places in s, such
+// as 2500 for "2.5" at precision 3. s is a plain decimal number: digits,
+// optionally followed by a point and more digits. Trailing zeros past the
+// precision are accepted.
+func Parse(s string, precision int) (int64, error) {
+	if precision < 0 || precision > MaxPrecision {
+		return 0, fmt.Errorf("%w: precision %d", ErrInvalid, precision)
+	}
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || (hasPoint && (frac == "" || !isDigits(frac))) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > precision {
+		return 0, fmt.Errorf("%w: %q, at most %d", ErrTooPrecise, s, precision)
+	}
+	digits := strings.TrimLeft(whole+frac+strings.Repeat("0", precision-len(frac)), "0")
+	if digits == "" {
+		return 0, nil
+	}
+	units, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrOutOfRange, s)
+	}
+	return units, nil
+}
+
+// isDigits reports whether s holds only ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Format returns units of precision decimal places as a decimal, without
+// trailing zeros: "2.5" for 2500 at precision 3.
+func Format(units int64, precision int) string {
+	sign := ""
+	magnitude := uint64(units)
+	if units < 0 {
+		sign, magnitude = "-", -magnitude
+	}
+	text := strconv.FormatUint(magnitude, 10)
+	if precision <= 0 {
+		return sign + text
+	}
+	if len(text) <= precision {
+		text = strings.Repeat("0", precision-len(text)+1) + text
+	}
+	whole, frac := text[:len(text)-precision], strings.TrimRight(text[len(text)-precision:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
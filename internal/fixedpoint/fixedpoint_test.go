@@ -0,0 +1,77 @@
+package fixedpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input     string
+		precision int
+		want      int64
+		wantErr   error
+	}{
+		{input: "2.5", precision: 3, want: 2500},
+		{input: "12", precision: 3, want: 12000},
+		{input: "0.001", precision: 3, want: 1},
+		{input: "007.250", precision: 3, want: 7250},
+		{input: "1.50000", precision: 1, want: 15},
+		{input: "0", precision: 3, want: 0},
+		{input: "42", precision: 0, want: 42},
+		{input: "0.0001", precision: 3, wantErr: ErrTooPrecise},
+		{input: "2.55", precision: 1, wantErr: ErrTooPrecise},
+		{input: "", precision: 3, wantErr: ErrInvalid},
+		{input: ".5", precision: 3, wantErr: ErrInvalid},
+		{input: "5.", precision: 3, wantErr: ErrInvalid},
+		{input: "-1", precision: 3, wantErr: ErrInvalid},
+		{input: "1e3", precision: 3, wantErr: ErrInvalid},
+		{input: "1.2.3", precision: 3, wantErr: ErrInvalid},
+		{input: "1", precision: MaxPrecision + 1, wantErr: ErrInvalid},
+		{input: "99999999999999999", precision: 3, wantErr: ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, tt.precision)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		units     int64
+		precision int
+		want      string
+	}{
+		{units: 2500, precision: 3, want: "2.5"},
+		{units: 12000, precision: 3, want: "12"},
+		{units: 1, precision: 3, want: "0.001"},
+		{units: 0, precision: 3, want: "0"},
+		{units: 7250, precision: 3, want: "7.25"},
+		{units: 42, precision: 0, want: "42"},
+		{units: -1500, precision: 3, want: "-1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, Format(tt.units, tt.precision))
+		})
+	}
+}
+
+func TestParseFormat_RoundTrip(t *testing.T) {
+	for _, input := range []string{"0.125", "2.5", "1000", "0.000001"} {
+		units, err := Parse(input, MaxPrecision)
+		require.NoError(t, err)
+		assert.Equal(t, input, Format(units, MaxPrecision))
+	}
+}
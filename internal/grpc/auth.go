@@ -0,0 +1,175 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/grpc/packv1"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// Metadata keys read by the authenticator, matching the HTTP headers.
+const (
+	authorizationMetadataKey = "authorization"
+	apiKeyMetadataKey        = "x-api-key"
+)
+
+// healthMethodPrefix identifies the health service, which is always public
+// so load balancers and orchestrators can probe it.
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// caller identifies the authenticated client of a call.
+type caller struct {
+	userID   string
+	email    string
+	apiKeyID string
+}
+
+type callerKey struct{}
+
+// callerFromContext returns the caller stored by the authenticator. It is
+// empty when authentication is disabled.
+func callerFromContext(ctx context.Context) caller {
+	c, _ := ctx.Value(callerKey{}).(caller)
+	return c
+}
+
+// authenticator applies the HTTP API's authentication to gRPC calls: JWT
+// with the packs:read / packs:write permissions when an AuthService is
+// configured, otherwise API keys.
+type authenticator struct {
+	authService service.AuthService
+	roleService service.RoleService
+	apiKeys     *middleware.APIKeySet
+	requireDPoP bool
+	// permissions maps full method names to the permission ID they require.
+	// Methods without an entry only require authentication.
+	permissions map[string]string
+}
+
+func newAuthenticator(cfg Config) *authenticator {
+	a := &authenticator{
+		authService: cfg.AuthService,
+		roleService: cfg.RoleService,
+		requireDPoP: cfg.RequireDPoP,
+	}
+	if cfg.AuthService == nil && cfg.EnableAuth && len(cfg.APIKeys) > 0 {
+		a.apiKeys = cfg.APIKeySet
+		if a.apiKeys == nil {
+			a.apiKeys = middleware.NewAPIKeySet(cfg.APIKeys)
+		}
+	}
+
+	if cfg.AuthService != nil && cfg.PermissionService != nil && cfg.RoleService != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		readPermID := cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "packs", "read")
+		writePermID := cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "packs", "write")
+		a.permissions = make(map[string]string)
+		for method, permID := range map[string]string{
+			packv1.PackService_CalculatePacks_FullMethodName:     readPermID,
+			packv1.PackService_GetActivePackSizes_FullMethodName: readPermID,
+			packv1.PackService_UpdatePackSizes_FullMethodName:    writePermID,
+		} {
+			if permID != "" {
+				a.permissions[method] = permID
+			}
+		}
+	}
+	return a
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+		return handler(ctx, req)
+	}
+
+	var err error
+	switch {
+	case a.authService != nil:
+		ctx, err = a.authenticateJWT(ctx, info.FullMethod)
+	case a.apiKeys != nil:
+		ctx, err = a.authenticateAPIKey(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) authenticateAPIKey(ctx context.Context) (context.Context, error) {
+	key := metadataValue(ctx, apiKeyMetadataKey)
+	if key == "" {
+		return ctx, status.Error(codes.Unauthenticated, "API key is required")
+	}
+	if !a.apiKeys.Valid(key) {
+		return ctx, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return context.WithValue(ctx, callerKey{}, caller{apiKeyID: middleware.APIKeyFingerprint(key)}), nil
+}
+
+// authenticateJWT validates the bearer token and checks the method's
+// permission. DPoP-bound tokens are rejected: a proof signs the HTTP method
+// and URL, which gRPC calls do not have.
+func (a *authenticator) authenticateJWT(ctx context.Context, method string) (context.Context, error) {
+	token, isDPoP, ok := middleware.AccessTokenFromHeader(metadataValue(ctx, authorizationMetadataKey))
+	if !ok || token == "" {
+		return ctx, status.Error(codes.Unauthenticated, "bearer token is required")
+	}
+	if isDPoP || a.requireDPoP {
+		return ctx, status.Error(codes.Unauthenticated, "DPoP-bound tokens are not supported over gRPC")
+	}
+
+	claims, err := a.authService.ValidateToken(ctx, token)
+	if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		return ctx, status.Error(codes.Unavailable, "authentication is temporarily unavailable")
+	}
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	if claims.IsBound() {
+		return ctx, status.Error(codes.Unauthenticated, "DPoP-bound tokens are not supported over gRPC")
+	}
+
+	if permID, required := a.permissions[method]; required && !a.hasPermission(ctx, claims.Roles, permID) {
+		return ctx, status.Error(codes.PermissionDenied, "insufficient permissions")
+	}
+
+	// Service tokens have no user
+	authenticated := caller{email: claims.Email}
+	if !claims.IsService() {
+		authenticated.userID = claims.UserID.Hex()
+	}
+	return context.WithValue(ctx, callerKey{}, authenticated), nil
+}
+
+// hasPermission reports whether any of the roles grants permID, like
+// middleware.RequireAuthorization.
+func (a *authenticator) hasPermission(ctx context.Context, roleIDs []string, permID string) bool {
+	for _, roleIDStr := range roleIDs {
+		roleID, err := primitive.ObjectIDFromHex(roleIDStr)
+		if err != nil {
+			continue
+		}
+		role, err := a.roleService.FindByID(ctx, roleID)
+		if err != nil || role == nil {
+			continue
+		}
+		for _, p := range role.Permissions {
+			if p == permID {
+				return true
+			}
+		}
+	}
+	return false
+}
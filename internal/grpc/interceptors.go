@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/guttosm/pack-service/internal/correlation"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/logger"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+const (
+	// requestIDMetadataKey carries the request ID, like the X-Request-ID HTTP header.
+	requestIDMetadataKey = "x-request-id"
+	// correlationIDMetadataKey carries the correlation ID, like the
+	// X-Correlation-ID HTTP header.
+	correlationIDMetadataKey = "x-correlation-id"
+)
+
+type requestIDKey struct{}
+
+// requestIDFromContext returns the request ID set by requestInterceptor.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// recoveryInterceptor turns panics into Internal errors, like middleware.Recovery.
+func recoveryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log := logger.Logger()
+			log.Error().
+				Str("request_id", requestIDFromContext(ctx)).
+				Str("method", info.FullMethod).
+				Interface("panic", r).
+				Msg("PANIC recovered")
+			err = status.Error(codes.Internal, "an unexpected error occurred")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// requestInterceptor assigns a request ID and a correlation ID, echoes them
+// in the response header and logs and measures each call.
+func requestInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	requestID, correlationID := "", ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDMetadataKey); len(ids) > 0 {
+			requestID = ids[0]
+		}
+		if ids := md.Get(correlationIDMetadataKey); len(ids) > 0 {
+			correlationID = ids[0]
+		}
+	}
+	if requestID == "" {
+		requestID = idgen.NewUUID()
+	}
+	if !correlation.ValidID(correlationID) {
+		correlationID = requestID
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	ctx = correlation.ContextWithIDs(ctx, correlation.IDs{RequestID: requestID, CorrelationID: correlationID})
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID, correlationIDMetadataKey, correlationID))
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	latency := time.Since(start)
+	metrics.RecordGRPCRequest(info.FullMethod, code.String(), latency)
+
+	log := logger.Logger().With().
+		Str("request_id", requestID).
+		Str("correlation_id", correlationID).
+		Str("method", info.FullMethod).
+		Str("code", code.String()).
+		Int64("duration_ms", latency.Milliseconds()).
+		Logger()
+	switch code {
+	case codes.OK:
+		log.Info().Msg("gRPC request")
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		log.Error().Err(err).Msg("gRPC request")
+	default:
+		log.Warn().Err(err).Msg("gRPC request")
+	}
+	return resp, err
+}
+
+// metadataValue returns the first value of key in the incoming metadata.
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
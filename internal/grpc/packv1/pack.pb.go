@@ -0,0 +1,434 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: packv1/pack.proto
+
+package packv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CalculatePacksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of items to ship. Must be greater than 0.
+	ItemsOrdered int64 `protobuf:"varint,1,opt,name=items_ordered,json=itemsOrdered,proto3" json:"items_ordered,omitempty"`
+	// Pack sizes to use instead of the active configuration. Non-positive
+	// values are ignored.
+	PackSizes     []int64 `protobuf:"varint,2,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculatePacksRequest) Reset() {
+	*x = CalculatePacksRequest{}
+	mi := &file_packv1_pack_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculatePacksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculatePacksRequest) ProtoMessage() {}
+
+func (x *CalculatePacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packv1_pack_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculatePacksRequest.ProtoReflect.Descriptor instead.
+func (*CalculatePacksRequest) Descriptor() ([]byte, []int) {
+	return file_packv1_pack_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CalculatePacksRequest) GetItemsOrdered() int64 {
+	if x != nil {
+		return x.ItemsOrdered
+	}
+	return 0
+}
+
+func (x *CalculatePacksRequest) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type Pack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Quantity      int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pack) Reset() {
+	*x = Pack{}
+	mi := &file_packv1_pack_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pack) ProtoMessage() {}
+
+func (x *Pack) ProtoReflect() protoreflect.Message {
+	mi := &file_packv1_pack_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pack.ProtoReflect.Descriptor instead.
+func (*Pack) Descriptor() ([]byte, []int) {
+	return file_packv1_pack_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Pack) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Pack) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type CalculatePacksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderedItems  int64                  `protobuf:"varint,1,opt,name=ordered_items,json=orderedItems,proto3" json:"ordered_items,omitempty"`
+	TotalItems    int64                  `protobuf:"varint,2,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	Packs         []*Pack                `protobuf:"bytes,3,rep,name=packs,proto3" json:"packs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculatePacksResponse) Reset() {
+	*x = CalculatePacksResponse{}
+	mi := &file_packv1_pack_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculatePacksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculatePacksResponse) ProtoMessage() {}
+
+func (x *CalculatePacksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packv1_pack_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculatePacksResponse.ProtoReflect.Descriptor instead.
+func (*CalculatePacksResponse) Descriptor() ([]byte, []int) {
+	return file_packv1_pack_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CalculatePacksResponse) GetOrderedItems() int64 {
+	if x != nil {
+		return x.OrderedItems
+	}
+	return 0
+}
+
+func (x *CalculatePacksResponse) GetTotalItems() int64 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *CalculatePacksResponse) GetPacks() []*Pack {
+	if x != nil {
+		return x.Packs
+	}
+	return nil
+}
+
+type GetActivePackSizesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActivePackSizesRequest) Reset() {
+	*x = GetActivePackSizesRequest{}
+	mi := &file_packv1_pack_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActivePackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActivePackSizesRequest) ProtoMessage() {}
+
+func (x *GetActivePackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packv1_pack_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActivePackSizesRequest.ProtoReflect.Descriptor instead.
+func (*GetActivePackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packv1_pack_proto_rawDescGZIP(), []int{3}
+}
+
+type UpdatePackSizesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Pack sizes of the new configuration.
+	Sizes         []int64 `protobuf:"varint,1,rep,packed,name=sizes,proto3" json:"sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePackSizesRequest) Reset() {
+	*x = UpdatePackSizesRequest{}
+	mi := &file_packv1_pack_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePackSizesRequest) ProtoMessage() {}
+
+func (x *UpdatePackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packv1_pack_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePackSizesRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packv1_pack_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdatePackSizesRequest) GetSizes() []int64 {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+type PackSizes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sizes         []int64                `protobuf:"varint,1,rep,packed,name=sizes,proto3" json:"sizes,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackSizes) Reset() {
+	*x = PackSizes{}
+	mi := &file_packv1_pack_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackSizes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackSizes) ProtoMessage() {}
+
+func (x *PackSizes) ProtoReflect() protoreflect.Message {
+	mi := &file_packv1_pack_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackSizes.ProtoReflect.Descriptor instead.
+func (*PackSizes) Descriptor() ([]byte, []int) {
+	return file_packv1_pack_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PackSizes) GetSizes() []int64 {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+func (x *PackSizes) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PackSizes) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *PackSizes) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_packv1_pack_proto protoreflect.FileDescriptor
+
+const file_packv1_pack_proto_rawDesc = "" +
+	"\n" +
+	"\x11packv1/pack.proto\x12\apack.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"[\n" +
+	"\x15CalculatePacksRequest\x12#\n" +
+	"\ritems_ordered\x18\x01 \x01(\x03R\fitemsOrdered\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x02 \x03(\x03R\tpackSizes\"6\n" +
+	"\x04Pack\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x03R\bquantity\"\x83\x01\n" +
+	"\x16CalculatePacksResponse\x12#\n" +
+	"\rordered_items\x18\x01 \x01(\x03R\forderedItems\x12\x1f\n" +
+	"\vtotal_items\x18\x02 \x01(\x03R\n" +
+	"totalItems\x12#\n" +
+	"\x05packs\x18\x03 \x03(\v2\r.pack.v1.PackR\x05packs\"\x1b\n" +
+	"\x19GetActivePackSizesRequest\".\n" +
+	"\x16UpdatePackSizesRequest\x12\x14\n" +
+	"\x05sizes\x18\x01 \x03(\x03R\x05sizes\"\xb1\x01\n" +
+	"\tPackSizes\x12\x14\n" +
+	"\x05sizes\x18\x01 \x03(\x03R\x05sizes\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2\xf6\x01\n" +
+	"\vPackService\x12Q\n" +
+	"\x0eCalculatePacks\x12\x1e.pack.v1.CalculatePacksRequest\x1a\x1f.pack.v1.CalculatePacksResponse\x12L\n" +
+	"\x12GetActivePackSizes\x12\".pack.v1.GetActivePackSizesRequest\x1a\x12.pack.v1.PackSizes\x12F\n" +
+	"\x0fUpdatePackSizes\x12\x1f.pack.v1.UpdatePackSizesRequest\x1a\x12.pack.v1.PackSizesB=Z;github.com/guttosm/pack-service/internal/grpc/packv1;packv1b\x06proto3"
+
+var (
+	file_packv1_pack_proto_rawDescOnce sync.Once
+	file_packv1_pack_proto_rawDescData []byte
+)
+
+func file_packv1_pack_proto_rawDescGZIP() []byte {
+	file_packv1_pack_proto_rawDescOnce.Do(func() {
+		file_packv1_pack_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_packv1_pack_proto_rawDesc), len(file_packv1_pack_proto_rawDesc)))
+	})
+	return file_packv1_pack_proto_rawDescData
+}
+
+var file_packv1_pack_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_packv1_pack_proto_goTypes = []any{
+	(*CalculatePacksRequest)(nil),     // 0: pack.v1.CalculatePacksRequest
+	(*Pack)(nil),                      // 1: pack.v1.Pack
+	(*CalculatePacksResponse)(nil),    // 2: pack.v1.CalculatePacksResponse
+	(*GetActivePackSizesRequest)(nil), // 3: pack.v1.GetActivePackSizesRequest
+	(*UpdatePackSizesRequest)(nil),    // 4: pack.v1.UpdatePackSizesRequest
+	(*PackSizes)(nil),                 // 5: pack.v1.PackSizes
+	(*timestamppb.Timestamp)(nil),     // 6: google.protobuf.Timestamp
+}
+var file_packv1_pack_proto_depIdxs = []int32{
+	1, // 0: pack.v1.CalculatePacksResponse.packs:type_name -> pack.v1.Pack
+	6, // 1: pack.v1.PackSizes.created_at:type_name -> google.protobuf.Timestamp
+	6, // 2: pack.v1.PackSizes.updated_at:type_name -> google.protobuf.Timestamp
+	0, // 3: pack.v1.PackService.CalculatePacks:input_type -> pack.v1.CalculatePacksRequest
+	3, // 4: pack.v1.PackService.GetActivePackSizes:input_type -> pack.v1.GetActivePackSizesRequest
+	4, // 5: pack.v1.PackService.UpdatePackSizes:input_type -> pack.v1.UpdatePackSizesRequest
+	2, // 6: pack.v1.PackService.CalculatePacks:output_type -> pack.v1.CalculatePacksResponse
+	5, // 7: pack.v1.PackService.GetActivePackSizes:output_type -> pack.v1.PackSizes
+	5, // 8: pack.v1.PackService.UpdatePackSizes:output_type -> pack.v1.PackSizes
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_packv1_pack_proto_init() }
+func file_packv1_pack_proto_init() {
+	if File_packv1_pack_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_packv1_pack_proto_rawDesc), len(file_packv1_pack_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_packv1_pack_proto_goTypes,
+		DependencyIndexes: file_packv1_pack_proto_depIdxs,
+		MessageInfos:      file_packv1_pack_proto_msgTypes,
+	}.Build()
+	File_packv1_pack_proto = out.File
+	file_packv1_pack_proto_goTypes = nil
+	file_packv1_pack_proto_depIdxs = nil
+}
@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             v5.29.3
+// source: packv1/pack.proto
+
+package packv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PackService_CalculatePacks_FullMethodName     = "/pack.v1.PackService/CalculatePacks"
+	PackService_GetActivePackSizes_FullMethodName = "/pack.v1.PackService/GetActivePackSizes"
+	PackService_UpdatePackSizes_FullMethodName    = "/pack.v1.PackService/UpdatePackSizes"
+)
+
+// PackServiceClient is the client API for PackService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PackService exposes pack calculation and pack-size management over gRPC.
+// It mirrors the /api/calculate and /api/pack-sizes HTTP endpoints.
+type PackServiceClient interface {
+	// CalculatePacks returns the optimal pack combination for an order.
+	CalculatePacks(ctx context.Context, in *CalculatePacksRequest, opts ...grpc.CallOption) (*CalculatePacksResponse, error)
+	// GetActivePackSizes returns the active pack size configuration.
+	GetActivePackSizes(ctx context.Context, in *GetActivePackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error)
+	// UpdatePackSizes activates a new pack size configuration.
+	UpdatePackSizes(ctx context.Context, in *UpdatePackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error)
+}
+
+type packServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPackServiceClient(cc grpc.ClientConnInterface) PackServiceClient {
+	return &packServiceClient{cc}
+}
+
+func (c *packServiceClient) CalculatePacks(ctx context.Context, in *CalculatePacksRequest, opts ...grpc.CallOption) (*CalculatePacksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CalculatePacksResponse)
+	err := c.cc.Invoke(ctx, PackService_CalculatePacks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packServiceClient) GetActivePackSizes(ctx context.Context, in *GetActivePackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackSizes)
+	err := c.cc.Invoke(ctx, PackService_GetActivePackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packServiceClient) UpdatePackSizes(ctx context.Context, in *UpdatePackSizesRequest, opts ...grpc.CallOption) (*PackSizes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackSizes)
+	err := c.cc.Invoke(ctx, PackService_UpdatePackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PackServiceServer is the server API for PackService service.
+// All implementations must embed UnimplementedPackServiceServer
+// for forward compatibility.
+//
+// PackService exposes pack calculation and pack-size management over gRPC.
+// It mirrors the /api/calculate and /api/pack-sizes HTTP endpoints.
+type PackServiceServer interface {
+	// CalculatePacks returns the optimal pack combination for an order.
+	CalculatePacks(context.Context, *CalculatePacksRequest) (*CalculatePacksResponse, error)
+	// GetActivePackSizes returns the active pack size configuration.
+	GetActivePackSizes(context.Context, *GetActivePackSizesRequest) (*PackSizes, error)
+	// UpdatePackSizes activates a new pack size configuration.
+	UpdatePackSizes(context.Context, *UpdatePackSizesRequest) (*PackSizes, error)
+	mustEmbedUnimplementedPackServiceServer()
+}
+
+// UnimplementedPackServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPackServiceServer struct{}
+
+func (UnimplementedPackServiceServer) CalculatePacks(context.Context, *CalculatePacksRequest) (*CalculatePacksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CalculatePacks not implemented")
+}
+func (UnimplementedPackServiceServer) GetActivePackSizes(context.Context, *GetActivePackSizesRequest) (*PackSizes, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetActivePackSizes not implemented")
+}
+func (UnimplementedPackServiceServer) UpdatePackSizes(context.Context, *UpdatePackSizesRequest) (*PackSizes, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdatePackSizes not implemented")
+}
+func (UnimplementedPackServiceServer) mustEmbedUnimplementedPackServiceServer() {}
+func (UnimplementedPackServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafePackServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PackServiceServer will
+// result in compilation errors.
+type UnsafePackServiceServer interface {
+	mustEmbedUnimplementedPackServiceServer()
+}
+
+func RegisterPackServiceServer(s grpc.ServiceRegistrar, srv PackServiceServer) {
+	// If the following call panics, it indicates UnimplementedPackServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PackService_ServiceDesc, srv)
+}
+
+func _PackService_CalculatePacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculatePacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackServiceServer).CalculatePacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackService_CalculatePacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackServiceServer).CalculatePacks(ctx, req.(*CalculatePacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackService_GetActivePackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActivePackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackServiceServer).GetActivePackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackService_GetActivePackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackServiceServer).GetActivePackSizes(ctx, req.(*GetActivePackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackService_UpdatePackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackServiceServer).UpdatePackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackService_UpdatePackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackServiceServer).UpdatePackSizes(ctx, req.(*UpdatePackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PackService_ServiceDesc is the grpc.ServiceDesc for PackService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PackService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pack.v1.PackService",
+	HandlerType: (*PackServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CalculatePacks",
+			Handler:    _PackService_CalculatePacks_Handler,
+		},
+		{
+			MethodName: "GetActivePackSizes",
+			Handler:    _PackService_GetActivePackSizes_Handler,
+		},
+		{
+			MethodName: "UpdatePackSizes",
+			Handler:    _PackService_UpdatePackSizes_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "packv1/pack.proto",
+}
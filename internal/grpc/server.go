@@ -0,0 +1,326 @@
+// Package grpc exposes pack calculation and pack-size management over gRPC,
+// alongside the HTTP API. Service definitions live in packv1/pack.proto;
+// regenerate the Go code with "make proto".
+package grpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/grpc/packv1"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// packSizesTimeout bounds the active pack sizes lookup when the caller set no
+// earlier deadline, as the HTTP handler does.
+const packSizesTimeout = 2 * time.Second
+
+// Config holds the dependencies of the gRPC server.
+type Config struct {
+	Calculator       service.PackCalculator
+	PackSizesService service.PackSizesService
+	LoggingService   service.LoggingService
+	// Authentication mirrors the HTTP API: JWT when AuthService is set,
+	// otherwise API keys when EnableAuth is set and keys are configured.
+	EnableAuth bool
+	APIKeys    map[string]bool
+	// APIKeySet holds the API keys calls are checked against when set, so
+	// reloads replacing the HTTP API's keys apply to gRPC too.
+	APIKeySet         *middleware.APIKeySet
+	AuthService       service.AuthService
+	RoleService       service.RoleService
+	PermissionService service.PermissionService
+	// RequireDPoP rejects all JWTs: gRPC requests cannot carry DPoP proofs.
+	RequireDPoP bool
+	// CalculationHistory stores calculations alongside the HTTP ones when set.
+	CalculationHistory service.CalculationHistoryService
+	// PackSizeRules are checked before pack sizes are saved, as over HTTP.
+	PackSizeRules service.PackSizeRules
+}
+
+// NewServer creates a gRPC server with the pack service and the standard
+// health service registered.
+func NewServer(cfg Config, opts ...grpc.ServerOption) *grpc.Server {
+	auth := newAuthenticator(cfg)
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		recoveryInterceptor,
+		requestInterceptor,
+		auth.unaryInterceptor,
+	))
+
+	packServer := NewPackServer(cfg.Calculator, cfg.PackSizesService, cfg.LoggingService)
+	packServer.history = cfg.CalculationHistory
+	packServer.packSizeRules = cfg.PackSizeRules
+
+	server := grpc.NewServer(opts...)
+	packv1.RegisterPackServiceServer(server, packServer)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	return server
+}
+
+// PackServer implements packv1.PackServiceServer.
+type PackServer struct {
+	packv1.UnimplementedPackServiceServer
+	calculator       service.PackCalculator
+	packSizesService service.PackSizesService
+	loggingService   service.LoggingService
+	history          service.CalculationHistoryService
+	packSizeRules    service.PackSizeRules
+}
+
+// NewPackServer creates a new PackServer. packSizesService and loggingService may be nil.
+func NewPackServer(calculator service.PackCalculator, packSizesService service.PackSizesService, loggingService service.LoggingService) *PackServer {
+	return &PackServer{
+		calculator:       calculator,
+		packSizesService: packSizesService,
+		loggingService:   loggingService,
+	}
+}
+
+// CalculatePacks returns the optimal pack combination for an order.
+func (s *PackServer) CalculatePacks(ctx context.Context, req *packv1.CalculatePacksRequest) (*packv1.CalculatePacksResponse, error) {
+	if req.GetItemsOrdered() <= 0 {
+		metrics.RecordPackCalculation(0, "validation_error")
+		return nil, status.Error(codes.InvalidArgument, dto.ErrInvalidItemsOrdered.Error())
+	}
+	if maxItems := service.MaxItemsOrdered(s.calculator); req.GetItemsOrdered() > int64(maxItems) {
+		metrics.RecordPackCalculation(0, "validation_error")
+		return nil, status.Error(codes.InvalidArgument, (&dto.ItemsOrderedTooLargeError{Max: maxItems}).Error())
+	}
+	if !validPackSizes(req.GetPackSizes()) {
+		metrics.RecordPackCalculation(0, "validation_error")
+		return nil, status.Error(codes.InvalidArgument, dto.ErrInvalidPackSizes.Error())
+	}
+	itemsOrdered := int(req.GetItemsOrdered())
+
+	customSizes := make([]int, 0, len(req.GetPackSizes()))
+	for _, size := range req.GetPackSizes() {
+		if size > 0 {
+			customSizes = append(customSizes, int(size))
+		}
+	}
+
+	var configSizes []int
+	configVersion := 0
+	if len(customSizes) == 0 {
+		var err error
+		configSizes, configVersion, err = s.activePackSizes(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fields := map[string]interface{}{
+		"items_ordered":    itemsOrdered,
+		"has_custom_sizes": len(customSizes) > 0,
+	}
+	if configVersion > 0 {
+		fields[model.FieldPackSizesVersion] = configVersion
+	}
+	s.audit(ctx, model.ActionCalculate, "Pack calculation requested", fields)
+
+	start := time.Now()
+	var result model.PackResult
+	switch {
+	case len(customSizes) > 0:
+		result = s.calculator.CalculateWithPackSizes(itemsOrdered, customSizes)
+	case len(configSizes) > 0:
+		result = s.calculator.CalculateWithPackSizes(itemsOrdered, configSizes)
+	default:
+		result = s.calculator.Calculate(itemsOrdered)
+	}
+	latency := time.Since(start)
+	sizes := customSizes
+	if len(sizes) == 0 {
+		sizes = configSizes
+	}
+	metrics.RecordPackCalculation(latency, "success")
+	metrics.RecordPackResult(itemsOrdered, result.PackCount(), latency)
+	metrics.RecordPackSetCalculation(sizes)
+	metrics.RecordGreedyComparison(result.GreedyDiffers)
+	if s.history != nil {
+		s.history.Record(&model.Calculation{
+			RequestID:        requestIDFromContext(ctx),
+			UserID:           callerFromContext(ctx).userID,
+			Source:           model.CalculationSourceGRPC,
+			ItemsOrdered:     itemsOrdered,
+			PackSizes:        sizes,
+			PackSizesVersion: configVersion,
+			TotalItems:       result.TotalItems,
+			Packs:            result.Packs,
+			LatencyMicros:    latency.Microseconds(),
+		})
+	}
+
+	resp := &packv1.CalculatePacksResponse{
+		OrderedItems: int64(result.OrderedItems),
+		TotalItems:   int64(result.TotalItems),
+		Packs:        make([]*packv1.Pack, len(result.Packs)),
+	}
+	for i, p := range result.Packs {
+		resp.Packs[i] = &packv1.Pack{Size: int64(p.Size), Quantity: int64(p.Quantity)}
+	}
+	return resp, nil
+}
+
+// activePackSizes returns the configured pack sizes, or nil to use the
+// calculator defaults. Lookup failures fall back to the defaults like the
+// HTTP API, except when the caller's deadline expired or it cancelled.
+func (s *PackServer) activePackSizes(ctx context.Context) ([]int, int, error) {
+	if s.packSizesService == nil {
+		return nil, 0, nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, packSizesTimeout)
+	defer cancel()
+
+	config, err := s.packSizesService.GetActive(lookupCtx)
+	if ctx.Err() != nil {
+		return nil, 0, status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil || config == nil || len(config.Sizes) == 0 {
+		return nil, 0, nil
+	}
+	return config.Sizes, config.Version, nil
+}
+
+// GetActivePackSizes returns the active pack size configuration.
+func (s *PackServer) GetActivePackSizes(ctx context.Context, _ *packv1.GetActivePackSizesRequest) (*packv1.PackSizes, error) {
+	if s.packSizesService == nil {
+		return nil, status.Error(codes.Unimplemented, "pack size management requires a database")
+	}
+
+	config, err := s.packSizesService.GetActive(ctx)
+	if err != nil {
+		return nil, serviceError(ctx, err)
+	}
+	if config == nil {
+		return nil, status.Error(codes.NotFound, "no active pack sizes")
+	}
+	return packSizesToProto(config), nil
+}
+
+// UpdatePackSizes activates a new pack size configuration.
+func (s *PackServer) UpdatePackSizes(ctx context.Context, req *packv1.UpdatePackSizesRequest) (*packv1.PackSizes, error) {
+	if s.packSizesService == nil {
+		return nil, status.Error(codes.Unimplemented, "pack size management requires a database")
+	}
+	if len(req.GetSizes()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "sizes must not be empty")
+	}
+
+	sizes := make([]int, len(req.GetSizes()))
+	for i, size := range req.GetSizes() {
+		sizes[i] = int(size)
+	}
+	for _, violation := range s.packSizeRules.Check(sizes) {
+		if violation.Severity == service.PackSizeRuleError {
+			return nil, status.Error(codes.InvalidArgument, violation.Rule+": "+violation.Message)
+		}
+	}
+
+	config, err := s.packSizesService.Create(ctx, sizes, nil, callerFromContext(ctx).userID)
+	if err != nil {
+		return nil, serviceError(ctx, err)
+	}
+
+	if s.calculator != nil {
+		s.calculator.InvalidateCache()
+	}
+	s.audit(ctx, "update_pack_sizes", "Pack sizes configuration updated", map[string]interface{}{
+		"pack_sizes": sizes,
+		"version":    config.Version,
+	})
+
+	return packSizesToProto(config), nil
+}
+
+func packSizesToProto(config *repository.PackSizeConfig) *packv1.PackSizes {
+	sizes := make([]int64, len(config.Sizes))
+	for i, size := range config.Sizes {
+		sizes[i] = int64(size)
+	}
+	return &packv1.PackSizes{
+		Sizes:     sizes,
+		Version:   int32(config.Version),
+		CreatedAt: timestamppb.New(config.CreatedAt),
+		UpdatedAt: timestamppb.New(config.UpdatedAt),
+	}
+}
+
+// validPackSizes applies the HTTP API's limits on requested pack sizes.
+func validPackSizes(sizes []int64) bool {
+	if len(sizes) > dto.MaxPackSizes {
+		return false
+	}
+	for _, size := range sizes {
+		if size > dto.MaxPackSize {
+			return false
+		}
+	}
+	return true
+}
+
+// serviceError maps a service error to a gRPC status. Errors caused by the
+// caller's deadline or cancellation keep that meaning.
+func serviceError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// audit records an audit log entry asynchronously, like middleware.AuditLog.
+func (s *PackServer) audit(ctx context.Context, action, message string, fields map[string]interface{}) {
+	if s.loggingService == nil {
+		return
+	}
+
+	method, _ := grpc.Method(ctx)
+	caller := callerFromContext(ctx)
+	entry := &model.LogEntry{
+		Timestamp:  timeutil.Now(),
+		Level:      "info",
+		Message:    message,
+		RequestID:  requestIDFromContext(ctx),
+		Method:     "GRPC",
+		Path:       method,
+		UserID:     caller.userID,
+		UserEmail:  caller.email,
+		ActionType: action,
+		Fields:     fields,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(entry.IP); err == nil {
+			entry.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			entry.UserAgent = ua[0]
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.loggingService.CreateLog(ctx, entry)
+	}()
+}
@@ -0,0 +1,349 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/grpc/packv1"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// startServer serves cfg over an in-memory connection and returns a client.
+func startServer(t *testing.T, cfg Config) packv1.PackServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(cfg)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return packv1.NewPackServiceClient(conn)
+}
+
+func TestPackServer_CalculatePacks(t *testing.T) {
+	result := model.PackResult{OrderedItems: 251, TotalItems: 500, Packs: []model.Pack{{Size: 500, Quantity: 1}}}
+
+	tests := []struct {
+		name         string
+		req          *packv1.CalculatePacksRequest
+		setup        func(*mocks.MockPackCalculator, *mocks.MockPackSizesService)
+		expectedCode codes.Code
+	}{
+		{
+			name: "uses active pack sizes",
+			req:  &packv1.CalculatePacksRequest{ItemsOrdered: 251},
+			setup: func(calc *mocks.MockPackCalculator, sizes *mocks.MockPackSizesService) {
+				sizes.EXPECT().GetActive(mock.Anything).Return(&repository.PackSizeConfig{Sizes: []int{250, 500}, Version: 3}, nil)
+				calc.EXPECT().CalculateWithPackSizes(251, []int{250, 500}).Return(result)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "uses custom pack sizes, ignoring non-positive ones",
+			req:  &packv1.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int64{500, 0, -1}},
+			setup: func(calc *mocks.MockPackCalculator, _ *mocks.MockPackSizesService) {
+				calc.EXPECT().CalculateWithPackSizes(251, []int{500}).Return(result)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "falls back to defaults when the lookup fails",
+			req:  &packv1.CalculatePacksRequest{ItemsOrdered: 251},
+			setup: func(calc *mocks.MockPackCalculator, sizes *mocks.MockPackSizesService) {
+				sizes.EXPECT().GetActive(mock.Anything).Return(nil, errors.New("db down"))
+				calc.EXPECT().Calculate(251).Return(result)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "rejects non-positive items",
+			req:          &packv1.CalculatePacksRequest{ItemsOrdered: 0},
+			setup:        func(*mocks.MockPackCalculator, *mocks.MockPackSizesService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "rejects orders above the maximum",
+			req:          &packv1.CalculatePacksRequest{ItemsOrdered: dto.DefaultMaxItemsOrdered + 1},
+			setup:        func(*mocks.MockPackCalculator, *mocks.MockPackSizesService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "rejects oversized pack sizes",
+			req:          &packv1.CalculatePacksRequest{ItemsOrdered: 251, PackSizes: []int64{dto.MaxPackSize + 1}},
+			setup:        func(*mocks.MockPackCalculator, *mocks.MockPackSizesService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := mocks.NewMockPackCalculator(t)
+			sizes := mocks.NewMockPackSizesService(t)
+			tt.setup(calc, sizes)
+			client := startServer(t, Config{Calculator: calc, PackSizesService: sizes})
+
+			resp, err := client.CalculatePacks(context.Background(), tt.req)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				require.NotNil(t, resp)
+				assert.Equal(t, int64(500), resp.GetTotalItems())
+				require.Len(t, resp.GetPacks(), 1)
+				assert.Equal(t, int64(500), resp.GetPacks()[0].GetSize())
+			}
+		})
+	}
+}
+
+func TestPackServer_CalculatePacks_DeadlineExceeded(t *testing.T) {
+	calc := mocks.NewMockPackCalculator(t)
+	sizes := mocks.NewMockPackSizesService(t)
+	sizes.EXPECT().GetActive(mock.Anything).RunAndReturn(func(ctx context.Context) (*repository.PackSizeConfig, error) {
+		// The caller's deadline reaches the database call
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	client := startServer(t, Config{Calculator: calc, PackSizesService: sizes})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.CalculatePacks(ctx, &packv1.CalculatePacksRequest{ItemsOrdered: 251})
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestPackServer_PackSizes(t *testing.T) {
+	now := time.Now()
+	config := &repository.PackSizeConfig{Sizes: []int{250, 500}, Version: 4, CreatedAt: now, UpdatedAt: now}
+
+	t.Run("get active", func(t *testing.T) {
+		sizes := mocks.NewMockPackSizesService(t)
+		sizes.EXPECT().GetActive(mock.Anything).Return(config, nil)
+		client := startServer(t, Config{PackSizesService: sizes})
+
+		resp, err := client.GetActivePackSizes(context.Background(), &packv1.GetActivePackSizesRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{250, 500}, resp.GetSizes())
+		assert.Equal(t, int32(4), resp.GetVersion())
+		assert.True(t, resp.GetCreatedAt().AsTime().Equal(now))
+	})
+
+	t.Run("get active without configuration", func(t *testing.T) {
+		sizes := mocks.NewMockPackSizesService(t)
+		sizes.EXPECT().GetActive(mock.Anything).Return(nil, nil)
+		client := startServer(t, Config{PackSizesService: sizes})
+
+		_, err := client.GetActivePackSizes(context.Background(), &packv1.GetActivePackSizesRequest{})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("update invalidates the calculator cache", func(t *testing.T) {
+		calc := mocks.NewMockPackCalculator(t)
+		calc.EXPECT().InvalidateCache().Once()
+		sizes := mocks.NewMockPackSizesService(t)
+		sizes.EXPECT().Create(mock.Anything, []int{250, 500}, mock.Anything, "").Return(config, nil)
+		client := startServer(t, Config{Calculator: calc, PackSizesService: sizes})
+
+		resp, err := client.UpdatePackSizes(context.Background(), &packv1.UpdatePackSizesRequest{Sizes: []int64{250, 500}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), resp.GetVersion())
+	})
+
+	t.Run("update rejects empty sizes", func(t *testing.T) {
+		client := startServer(t, Config{PackSizesService: mocks.NewMockPackSizesService(t)})
+
+		_, err := client.UpdatePackSizes(context.Background(), &packv1.UpdatePackSizesRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("update rejects duplicate sizes", func(t *testing.T) {
+		client := startServer(t, Config{PackSizesService: mocks.NewMockPackSizesService(t)})
+
+		_, err := client.UpdatePackSizes(context.Background(), &packv1.UpdatePackSizesRequest{Sizes: []int64{250, 250}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), service.RuleUniqueSizes)
+	})
+
+	t.Run("unimplemented without database", func(t *testing.T) {
+		client := startServer(t, Config{})
+
+		_, err := client.GetActivePackSizes(context.Background(), &packv1.GetActivePackSizesRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+func TestAuthenticator_APIKeys(t *testing.T) {
+	calc := mocks.NewMockPackCalculator(t)
+	calc.EXPECT().Calculate(251).Return(model.PackResult{OrderedItems: 251}).Once()
+	client := startServer(t, Config{Calculator: calc, EnableAuth: true, APIKeys: map[string]bool{"key-1": true}})
+	req := &packv1.CalculatePacksRequest{ItemsOrdered: 251}
+
+	_, err := client.CalculatePacks(context.Background(), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadataKey, "wrong")
+	_, err = client.CalculatePacks(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadataKey, "key-1")
+	_, err = client.CalculatePacks(ctx, req)
+	assert.NoError(t, err)
+}
+
+func TestAuthenticator_APIKeySetReplace(t *testing.T) {
+	calc := mocks.NewMockPackCalculator(t)
+	calc.EXPECT().Calculate(251).Return(model.PackResult{OrderedItems: 251}).Once()
+	keys := map[string]bool{"key-1": true}
+	apiKeys := middleware.NewAPIKeySet(keys)
+	client := startServer(t, Config{Calculator: calc, EnableAuth: true, APIKeys: keys, APIKeySet: apiKeys})
+	req := &packv1.CalculatePacksRequest{ItemsOrdered: 251}
+
+	apiKeys.Replace(map[string]bool{"key-2": true})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadataKey, "key-1")
+	_, err := client.CalculatePacks(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadataKey, "key-2")
+	_, err = client.CalculatePacks(ctx, req)
+	assert.NoError(t, err)
+}
+
+func TestAuthenticator_JWT(t *testing.T) {
+	roleID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	authService := mocks.NewMockAuthService(t)
+	authService.EXPECT().ValidateToken(mock.Anything, "reader").Return(&dto.Claims{UserID: userID, Roles: []string{roleID.Hex()}}, nil).Maybe()
+	authService.EXPECT().ValidateToken(mock.Anything, "bound").Return(&dto.Claims{UserID: userID, Confirmation: &dto.Confirmation{JKT: "thumbprint"}}, nil).Maybe()
+	authService.EXPECT().ValidateToken(mock.Anything, "expired").Return(nil, errors.New("token expired")).Maybe()
+	authService.EXPECT().ValidateToken(mock.Anything, "unchecked").Return(nil, &circuitbreaker.OpenError{Name: "mongodb-auth"}).Maybe()
+
+	permService := mocks.NewMockPermissionService(t)
+	permService.EXPECT().GetPermissionIDByResourceAndAction(mock.Anything, "packs", "read").Return("perm-read")
+	permService.EXPECT().GetPermissionIDByResourceAndAction(mock.Anything, "packs", "write").Return("perm-write")
+
+	roleService := mocks.NewMockRoleService(t)
+	roleService.EXPECT().FindByID(mock.Anything, roleID).Return(&model.Role{ID: roleID, Permissions: []string{"perm-read"}}, nil).Maybe()
+
+	calc := mocks.NewMockPackCalculator(t)
+	calc.EXPECT().Calculate(251).Return(model.PackResult{OrderedItems: 251}).Maybe()
+
+	client := startServer(t, Config{
+		Calculator:        calc,
+		AuthService:       authService,
+		RoleService:       roleService,
+		PermissionService: permService,
+	})
+
+	withToken := func(scheme, token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), authorizationMetadataKey, scheme+" "+token)
+	}
+	calculate := &packv1.CalculatePacksRequest{ItemsOrdered: 251}
+
+	_, err := client.CalculatePacks(context.Background(), calculate)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "missing token")
+
+	_, err = client.CalculatePacks(withToken("Bearer", "expired"), calculate)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "invalid token")
+
+	_, err = client.CalculatePacks(withToken("Bearer", "unchecked"), calculate)
+	assert.Equal(t, codes.Unavailable, status.Code(err), "auth circuit open")
+
+	_, err = client.CalculatePacks(withToken("Bearer", "bound"), calculate)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "DPoP-bound token")
+
+	_, err = client.CalculatePacks(withToken("Bearer", "reader"), calculate)
+	assert.NoError(t, err)
+
+	_, err = client.UpdatePackSizes(withToken("Bearer", "reader"), &packv1.UpdatePackSizesRequest{Sizes: []int64{250}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "packs:write required")
+}
+
+func TestServer_HealthIsPublic(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(Config{EnableAuth: true, APIKeys: map[string]bool{"key-1": true}})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestRequestInterceptor_EchoesRequestID(t *testing.T) {
+	calc := mocks.NewMockPackCalculator(t)
+	calc.EXPECT().Calculate(1).Return(model.PackResult{OrderedItems: 1})
+	client := startServer(t, Config{Calculator: calc})
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestIDMetadataKey, "req-123")
+	_, err := client.CalculatePacks(ctx, &packv1.CalculatePacksRequest{ItemsOrdered: 1}, grpc.Header(&header))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-123"}, header.Get(requestIDMetadataKey))
+}
+
+func TestRequestInterceptor_CorrelationID(t *testing.T) {
+	calc := mocks.NewMockPackCalculator(t)
+	calc.EXPECT().Calculate(1).Return(model.PackResult{OrderedItems: 1})
+	client := startServer(t, Config{Calculator: calc})
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestIDMetadataKey, "req-123")
+	_, err := client.CalculatePacks(ctx, &packv1.CalculatePacksRequest{ItemsOrdered: 1}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-123"}, header.Get(correlationIDMetadataKey), "defaults to the request ID")
+
+	ctx = metadata.AppendToOutgoingContext(ctx, correlationIDMetadataKey, "checkout-7f3a")
+	_, err = client.CalculatePacks(ctx, &packv1.CalculatePacksRequest{ItemsOrdered: 1}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"checkout-7f3a"}, header.Get(correlationIDMetadataKey))
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: packv1.PackService_CalculatePacks_FullMethodName}
+	_, err := recoveryInterceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
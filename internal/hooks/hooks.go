@@ -0,0 +1,187 @@
+// Package hooks lets deployments customise the calculate pipeline without
+// forking the handlers. A hook is a Go value implementing one or more of
+// RequestValidator, ClaimEnricher and ResultProcessor, registered at build
+// time from an init function:
+//
+//	func init() {
+//		hooks.Register("acme-rounding", acmeRounding{})
+//	}
+//
+// The request and result types are internal, so hook packages live in this
+// module, e.g. in plugins/acme, and are compiled in by a build-tagged file
+// in cmd that blank-imports them. Hooks run in registration order.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// RequestValidator rejects calculate requests the deployment does not
+// accept. It runs after the built-in validation, for single and batch
+// calculations alike. The message of the returned error is sent to the
+// client, so it must not leak internals.
+type RequestValidator interface {
+	ValidateRequest(ctx context.Context, req *dto.CalculatePacksRequest) error
+}
+
+// ClaimEnricher adds attributes to an authenticated request, such as a
+// customer tier looked up from the user's claims. The attributes are
+// available to later hooks through AttributesFromContext. Returning an
+// error rejects the request.
+type ClaimEnricher interface {
+	EnrichClaims(ctx context.Context, claims *dto.Claims) (Attributes, error)
+}
+
+// ResultProcessor adjusts a calculated result before it is recorded and
+// returned, e.g. to apply rounding rules. Returning an error fails the
+// calculation.
+type ResultProcessor interface {
+	ProcessResult(ctx context.Context, req *dto.CalculatePacksRequest, result *model.PackResult) error
+}
+
+// Attributes are the values claim enrichers add to a request.
+type Attributes map[string]interface{}
+
+type attributesKey struct{}
+
+// ContextWithAttributes returns a copy of ctx carrying attrs.
+func ContextWithAttributes(ctx context.Context, attrs Attributes) context.Context {
+	return context.WithValue(ctx, attributesKey{}, attrs)
+}
+
+// AttributesFromContext returns the attributes claim enrichers added to the
+// request in ctx, or nil when there are none.
+func AttributesFromContext(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(attributesKey{}).(Attributes)
+	return attrs
+}
+
+// Registry holds hooks in registration order. A nil Registry has no hooks.
+type Registry struct {
+	names      []string
+	validators []RequestValidator
+	enrichers  []ClaimEnricher
+	processors []ResultProcessor
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds hook under name. It panics if the name is taken or hook
+// implements none of the hook interfaces, since both are programming errors.
+func (r *Registry) Register(name string, hook interface{}) {
+	for _, existing := range r.names {
+		if existing == name {
+			panic(fmt.Sprintf("hooks: %q registered twice", name))
+		}
+	}
+
+	validator, isValidator := hook.(RequestValidator)
+	enricher, isEnricher := hook.(ClaimEnricher)
+	processor, isProcessor := hook.(ResultProcessor)
+	if !isValidator && !isEnricher && !isProcessor {
+		panic(fmt.Sprintf("hooks: %q (%T) implements no hook interface", name, hook))
+	}
+
+	r.names = append(r.names, name)
+	if isValidator {
+		r.validators = append(r.validators, validator)
+	}
+	if isEnricher {
+		r.enrichers = append(r.enrichers, enricher)
+	}
+	if isProcessor {
+		r.processors = append(r.processors, processor)
+	}
+}
+
+// Names returns the names of the registered hooks, sorted.
+func (r *Registry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := append([]string(nil), r.names...)
+	sort.Strings(names)
+	return names
+}
+
+// HasClaimEnrichers reports whether any claim enricher is registered.
+func (r *Registry) HasClaimEnrichers() bool {
+	return r != nil && len(r.enrichers) > 0
+}
+
+// ValidateRequest runs the request validators, stopping at the first error.
+func (r *Registry) ValidateRequest(ctx context.Context, req *dto.CalculatePacksRequest) error {
+	if r == nil {
+		return nil
+	}
+	for _, validator := range r.validators {
+		if err := validator.ValidateRequest(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnrichClaims runs the claim enrichers and merges their attributes; later
+// enrichers win on conflicts. It stops at the first error.
+func (r *Registry) EnrichClaims(ctx context.Context, claims *dto.Claims) (Attributes, error) {
+	if r == nil {
+		return nil, nil
+	}
+	var merged Attributes
+	for _, enricher := range r.enrichers {
+		attrs, err := enricher.EnrichClaims(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range attrs {
+			if merged == nil {
+				merged = make(Attributes, len(attrs))
+			}
+			merged[key] = value
+		}
+	}
+	return merged, nil
+}
+
+// ProcessResult runs the result processors, stopping at the first error.
+func (r *Registry) ProcessResult(ctx context.Context, req *dto.CalculatePacksRequest, result *model.PackResult) error {
+	if r == nil {
+		return nil
+	}
+	for _, processor := range r.processors {
+		if err := processor.ProcessResult(ctx, req, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	defaultMu       sync.Mutex
+	defaultRegistry = NewRegistry()
+)
+
+// Register adds hook to the registry returned by Default. Call it from an
+// init function.
+func Register(name string, hook interface{}) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRegistry.Register(name, hook)
+}
+
+// Default returns the hooks added with Register.
+func Default() *Registry {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultRegistry
+}
@@ -0,0 +1,105 @@
+//go:build !integration
+
+package hooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maxItems int
+
+func (m maxItems) ValidateRequest(_ context.Context, req *dto.CalculatePacksRequest) error {
+	if req.ItemsOrdered > int(m) {
+		return errors.New("too many items")
+	}
+	return nil
+}
+
+type attributes Attributes
+
+func (a attributes) EnrichClaims(context.Context, *dto.Claims) (Attributes, error) {
+	return Attributes(a), nil
+}
+
+// recorder appends its name to calls when it processes a result.
+type recorder struct {
+	name  string
+	calls *[]string
+}
+
+func (r recorder) ProcessResult(_ context.Context, _ *dto.CalculatePacksRequest, result *model.PackResult) error {
+	*r.calls = append(*r.calls, r.name)
+	result.TotalItems++
+	return nil
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("max-items", maxItems(10))
+	registry.Register("tier", attributes{"tier": "gold"})
+
+	assert.Equal(t, []string{"max-items", "tier"}, registry.Names())
+	assert.True(t, registry.HasClaimEnrichers())
+
+	assert.PanicsWithValue(t, `hooks: "tier" registered twice`, func() {
+		registry.Register("tier", attributes{})
+	})
+	assert.Panics(t, func() { registry.Register("nothing", struct{}{}) })
+}
+
+func TestRegistry_NilHasNoHooks(t *testing.T) {
+	var registry *Registry
+	result := model.PackResult{TotalItems: 250}
+
+	assert.Nil(t, registry.Names())
+	assert.False(t, registry.HasClaimEnrichers())
+	assert.NoError(t, registry.ValidateRequest(context.Background(), &dto.CalculatePacksRequest{}))
+	assert.NoError(t, registry.ProcessResult(context.Background(), &dto.CalculatePacksRequest{}, &result))
+	assert.Equal(t, 250, result.TotalItems)
+
+	attrs, err := registry.EnrichClaims(context.Background(), &dto.Claims{})
+	assert.NoError(t, err)
+	assert.Nil(t, attrs)
+}
+
+func TestRegistry_ValidateRequest(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("max-items", maxItems(10))
+
+	assert.NoError(t, registry.ValidateRequest(context.Background(), &dto.CalculatePacksRequest{ItemsOrdered: 10}))
+	assert.EqualError(t, registry.ValidateRequest(context.Background(), &dto.CalculatePacksRequest{ItemsOrdered: 11}), "too many items")
+}
+
+func TestRegistry_EnrichClaims(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("tier", attributes{"tier": "silver", "region": "eu"})
+	registry.Register("upgrade", attributes{"tier": "gold"})
+
+	attrs, err := registry.EnrichClaims(context.Background(), &dto.Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, Attributes{"tier": "gold", "region": "eu"}, attrs)
+
+	ctx := ContextWithAttributes(context.Background(), attrs)
+	assert.Equal(t, attrs, AttributesFromContext(ctx))
+	assert.Nil(t, AttributesFromContext(context.Background()))
+}
+
+func TestRegistry_ProcessResult(t *testing.T) {
+	var calls []string
+	registry := NewRegistry()
+	registry.Register("first", recorder{name: "first", calls: &calls})
+	registry.Register("second", recorder{name: "second", calls: &calls})
+
+	result := model.PackResult{TotalItems: 250}
+	require.NoError(t, registry.ProcessResult(context.Background(), &dto.CalculatePacksRequest{}, &result))
+
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Equal(t, 252, result.TotalItems)
+}
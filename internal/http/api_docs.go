@@ -0,0 +1,189 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/docs"
+	"github.com/rs/zerolog/log"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+	"golang.org/x/net/webdav"
+)
+
+// Names the spec variants are registered with swag under.
+const (
+	publicSpecName = "pack-service-public"
+	adminSpecName  = "pack-service-admin"
+)
+
+// adminTag is the tag of the admin operations, left out of the public spec.
+const adminTag = "Admin"
+
+func init() {
+	swag.Register(publicSpecName, &specVariant{public: true})
+	swag.Register(adminSpecName, &specVariant{})
+}
+
+// specVariant is the generated OpenAPI document as served under /docs. The
+// public variant leaves out the admin operations and the definitions only
+// they use; the admin variant is the whole document.
+type specVariant struct {
+	public bool
+	once   sync.Once
+	doc    string
+}
+
+// ReadDoc implements swag.Swagger.
+func (s *specVariant) ReadDoc() string {
+	s.once.Do(func() {
+		s.doc = docs.SwaggerInfo.ReadDoc()
+		if !s.public {
+			return
+		}
+		public, err := PublicSpec([]byte(s.doc))
+		if err != nil {
+			// Never fall back to the whole document, which lists the admin operations
+			log.Error().Err(err).Msg("Failed to build public API spec")
+			s.doc = "{}"
+			return
+		}
+		s.doc = string(public)
+	})
+	return s.doc
+}
+
+// PublicSpec returns the Swagger 2.0 document spec without the operations
+// under /api/admin or tagged Admin, and without the tags and definitions
+// only those operations use.
+func PublicSpec(spec []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, err
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		operations, _ := item.(map[string]interface{})
+		if path == "/api/admin" || strings.HasPrefix(path, "/api/admin/") {
+			delete(paths, path)
+			continue
+		}
+		for method, op := range operations {
+			if isAdminOperation(op) {
+				delete(operations, method)
+			}
+		}
+		if len(operations) == 0 {
+			delete(paths, path)
+		}
+	}
+
+	if tags, ok := doc["tags"].([]interface{}); ok {
+		doc["tags"] = slices.DeleteFunc(tags, func(tag interface{}) bool {
+			t, _ := tag.(map[string]interface{})
+			return t["name"] == adminTag
+		})
+	}
+
+	if definitions, ok := doc["definitions"].(map[string]interface{}); ok {
+		used := map[string]bool{}
+		var pending []string
+		collectRefs(paths, func(name string) { pending = append(pending, name) })
+		for len(pending) > 0 {
+			name := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if used[name] {
+				continue
+			}
+			used[name] = true
+			collectRefs(definitions[name], func(name string) { pending = append(pending, name) })
+		}
+		for name := range definitions {
+			if !used[name] {
+				delete(definitions, name)
+			}
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "    ")
+}
+
+// isAdminOperation reports whether the operation op is tagged Admin.
+func isAdminOperation(op interface{}) bool {
+	operation, _ := op.(map[string]interface{})
+	tags, _ := operation["tags"].([]interface{})
+	return slices.Contains(tags, interface{}(adminTag))
+}
+
+// collectRefs calls found with the name of every definition v refers to.
+func collectRefs(v interface{}, found func(name string)) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				if name, ok := strings.CutPrefix(ref, "#/definitions/"); ok {
+					found(name)
+				}
+				continue
+			}
+			collectRefs(value, found)
+		}
+	case []interface{}:
+		for _, value := range v {
+			collectRefs(value, found)
+		}
+	}
+}
+
+// registerDocsRoutes serves Swagger UI with the public spec under /docs and,
+// when cfg.SwaggerAdminSpec is set, with the whole spec under /docs/admin.
+// Both are behind basic auth when cfg.SwaggerUser and cfg.SwaggerPass are
+// set. The old /swagger paths redirect to /docs.
+func registerDocsRoutes(router *gin.Engine, cfg *RouterConfig) {
+	if !cfg.SwaggerEnabled {
+		return
+	}
+
+	var auth []gin.HandlerFunc
+	if cfg.SwaggerUser != "" && cfg.SwaggerPass != "" {
+		auth = append(auth, gin.BasicAuth(gin.Accounts{cfg.SwaggerUser: cfg.SwaggerPass}))
+	}
+
+	// Each UI needs its own file handler, as gin-swagger sets the prefix of
+	// the one it is given to the path it is served under
+	public := ginSwagger.WrapHandler(swaggerFilesHandler(), ginSwagger.InstanceName(publicSpecName))
+	var admin gin.HandlerFunc
+	if cfg.SwaggerAdminSpec {
+		admin = ginSwagger.WrapHandler(swaggerFilesHandler(), ginSwagger.InstanceName(adminSpecName))
+	}
+
+	router.GET("/docs/*any", append(auth, func(c *gin.Context) {
+		path := c.Param("any")
+		switch {
+		case path == "/" || path == "/admin" || path == "/admin/":
+			c.Redirect(http.StatusMovedPermanently, strings.TrimSuffix("/docs"+path, "/")+"/index.html")
+		case strings.HasPrefix(path, "/admin/"):
+			if admin == nil {
+				c.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+				return
+			}
+			admin(c)
+		default:
+			public(c)
+		}
+	})...)
+	router.GET("/swagger/*any", func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/docs/index.html")
+	})
+}
+
+// swaggerFilesHandler returns a handler serving the Swagger UI files.
+func swaggerFilesHandler() *webdav.Handler {
+	return &webdav.Handler{FileSystem: swaggerFiles.FS, LockSystem: webdav.NewMemLS()}
+}
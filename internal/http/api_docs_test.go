@@ -0,0 +1,120 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/docs"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestPublicSpec(t *testing.T) {
+	spec := []byte(`{
+		"swagger": "2.0",
+		"tags": [{"name": "Admin"}, {"name": "Packs"}],
+		"paths": {
+			"/api/admin/users": {"get": {"tags": ["Admin"], "responses": {"200": {"schema": {"$ref": "#/definitions/dto.UserList"}}}}},
+			"/api/calculate": {"post": {"tags": ["Packs"], "responses": {"200": {"schema": {"$ref": "#/definitions/dto.PackResponse"}}}}},
+			"/api/pack-sizes": {
+				"get": {"tags": ["Pack Sizes"], "responses": {"200": {"description": "OK"}}},
+				"put": {"tags": ["Admin"], "responses": {"200": {"description": "OK"}}}
+			},
+			"/api/reindex": {"post": {"tags": ["Admin"], "responses": {"200": {"description": "OK"}}}}
+		},
+		"definitions": {
+			"dto.PackResponse": {"properties": {"packs": {"items": {"$ref": "#/definitions/dto.Pack"}}}},
+			"dto.Pack": {"type": "object"},
+			"dto.UserList": {"type": "object"}
+		}
+	}`)
+
+	public, err := PublicSpec(spec)
+	require.NoError(t, err)
+
+	var doc struct {
+		Tags        []map[string]string                   `json:"tags"`
+		Paths       map[string]map[string]json.RawMessage `json:"paths"`
+		Definitions map[string]json.RawMessage            `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(public, &doc))
+	assert.Equal(t, []map[string]string{{"name": "Packs"}}, doc.Tags)
+	assert.ElementsMatch(t, []string{"/api/calculate", "/api/pack-sizes"}, slices.Collect(maps.Keys(doc.Paths)))
+	assert.ElementsMatch(t, []string{"get"}, slices.Collect(maps.Keys(doc.Paths["/api/pack-sizes"])))
+	assert.ElementsMatch(t, []string{"dto.PackResponse", "dto.Pack"}, slices.Collect(maps.Keys(doc.Definitions)))
+
+	_, err = PublicSpec([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestRouter_Docs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(cfg RouterConfig) *gin.Engine {
+		return NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+	}
+	get := func(router *gin.Engine, path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("serves the public and admin specs", func(t *testing.T) {
+		router := newRouter(RouterConfig{SwaggerEnabled: true, SwaggerAdminSpec: true})
+
+		assert.Equal(t, http.StatusOK, get(router, "/docs/index.html").Code)
+		assert.Equal(t, http.StatusOK, get(router, "/docs/admin/index.html").Code)
+
+		w := get(router, "/docs/doc.json")
+		require.Equal(t, http.StatusOK, w.Code)
+		public, err := PublicSpec([]byte(docs.SwaggerInfo.ReadDoc()))
+		require.NoError(t, err)
+		assert.JSONEq(t, string(public), w.Body.String())
+
+		w = get(router, "/docs/admin/doc.json")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, docs.SwaggerInfo.ReadDoc(), w.Body.String())
+
+		w = get(router, "/swagger/index.html")
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "/docs/index.html", w.Header().Get("Location"))
+		w = get(router, "/docs/admin/")
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "/docs/admin/index.html", w.Header().Get("Location"))
+	})
+
+	t.Run("hides the admin spec unless enabled", func(t *testing.T) {
+		router := newRouter(RouterConfig{SwaggerEnabled: true})
+
+		assert.Equal(t, http.StatusOK, get(router, "/docs/doc.json").Code)
+		assert.Equal(t, http.StatusNotFound, get(router, "/docs/admin/doc.json").Code)
+	})
+
+	t.Run("requires basic auth when credentials are set", func(t *testing.T) {
+		router := newRouter(RouterConfig{SwaggerEnabled: true, SwaggerUser: "docs", SwaggerPass: "secret", SwaggerAdminSpec: true})
+
+		assert.Equal(t, http.StatusUnauthorized, get(router, "/docs/doc.json").Code)
+		assert.Equal(t, http.StatusUnauthorized, get(router, "/docs/admin/doc.json").Code)
+		// docs:secret
+		assert.Equal(t, http.StatusOK, get(router, "/docs/admin/doc.json", "Authorization", "Basic ZG9jczpzZWNyZXQ=").Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := newRouter(RouterConfig{})
+
+		assert.Equal(t, http.StatusNotFound, get(router, "/docs/index.html").Code)
+		assert.Equal(t, http.StatusNotFound, get(router, "/swagger/index.html").Code)
+	})
+}
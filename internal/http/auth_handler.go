@@ -1,27 +1,119 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/dpop"
 	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
 	"github.com/guttosm/pack-service/internal/middleware"
 	"github.com/guttosm/pack-service/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // AuthHandler provides HTTP handlers for authentication routes.
 type AuthHandler struct {
-	authService service.AuthService
+	authService   service.AuthService
+	dpopVerifier  *dpop.Verifier
+	requireDPoP   bool
+	countryHeader string
+}
+
+// AuthHandlerOption configures an AuthHandler.
+type AuthHandlerOption func(*AuthHandler)
+
+// WithDPoP binds issued tokens to the client key of a DPoP proof sent with
+// login, register and refresh requests. When required is true, those requests
+// are rejected without a proof.
+func WithDPoP(verifier *dpop.Verifier, required bool) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.dpopVerifier = verifier
+		h.requireDPoP = required
+	}
+}
+
+// WithCountryHeader reads the client country of logins from header, set by
+// the edge proxy or CDN, for login anomaly detection. An empty header leaves
+// the country unknown.
+func WithCountryHeader(header string) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.countryHeader = header
+	}
 }
 
 // NewAuthHandler creates a new authentication handler.
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
-	return &AuthHandler{
+func NewAuthHandler(authService service.AuthService, opts ...AuthHandlerOption) *AuthHandler {
+	h := &AuthHandler{
 		authService: authService,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// proofContext verifies the request's DPoP proof, if any, and returns a context
+// that binds the tokens issued for it to the proof's key. It writes an error
+// response and returns false when the proof is missing but required, or invalid.
+func (h *AuthHandler) proofContext(c *gin.Context, builder *ResponseBuilder) (context.Context, bool) {
+	ctx := c.Request.Context()
+
+	proof := c.GetHeader(dpop.HeaderName)
+	if proof == "" && !h.requireDPoP {
+		return ctx, true
+	}
+	if h.dpopVerifier == nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyDPoPUnsupported, errors.New("DPoP proofs are not supported"))
+		return nil, false
+	}
+
+	verified, err := h.dpopVerifier.Verify(proof, c.Request.Method, dpop.RequestURL(c.Request), "")
+	if err != nil {
+		c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidDPoPProof, err)
+		return nil, false
+	}
+	return dpop.NewContext(ctx, verified.Thumbprint), true
+}
+
+// tokenType returns the token_type reported for tokens issued in ctx.
+func tokenType(ctx context.Context) string {
+	if dpop.ThumbprintFromContext(ctx) != "" {
+		return "DPoP"
+	}
+	return "Bearer"
+}
+
+// authValidationMessageKey returns the translation key for a login or
+// registration request binding or validation error.
+func authValidationMessageKey(err error) string {
+	var field string
+	var validationErr *dto.ValidationError
+	var bindingErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErr):
+		field = validationErr.Field
+	case errors.As(err, &bindingErrs) && len(bindingErrs) > 0:
+		field = strings.ToLower(bindingErrs[0].Field())
+	default:
+		return i18n.ErrKeyInvalidRequestBody
+	}
+	switch field {
+	case "email":
+		return i18n.ErrKeyValidationEmail
+	case "username":
+		return i18n.ErrKeyValidationUsername
+	case "password":
+		return i18n.ErrKeyValidationPassword
+	default:
+		return i18n.ErrKeyInvalidRequest
+	}
 }
 
 // Login handles POST /api/auth/login requests.
@@ -35,38 +127,47 @@ func NewAuthHandler(authService service.AuthService) *AuthHandler {
 // @Success      200 {object} dto.LoginResponse "Successful login"
 // @Failure      400 {object} dto.ErrorResponse "Bad request - invalid input"
 // @Failure      401 {object} dto.ErrorResponse "Unauthorized - invalid credentials"
+// @Failure      423 {object} dto.ErrorResponse "Locked - too many failed logins, retry after the Retry-After header"
 // @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Failure      503 {object} dto.ErrorResponse "Service unavailable - retry after the Retry-After header"
 // @Router       /api/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	builder := NewResponseBuilder(c)
-	locale := i18n.GetLocale(c)
 
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		builder.Error(http.StatusBadRequest, authValidationMessageKey(err), err)
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		if validationErr, ok := err.(*dto.ValidationError); ok {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyValidationItemsOrdered, locale)
-			// Override with specific validation message
-			switch validationErr.Field {
-			case "email":
-				message = "email: email is required"
-			case "password":
-				message = "password: password must be at least 6 characters"
-			}
-			builder.Error(http.StatusBadRequest, dto.ErrCodeInvalidRequest, errors.New(message))
-		} else {
-			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
-		}
+		builder.Error(http.StatusBadRequest, authValidationMessageKey(err), err)
 		return
 	}
 
-	tokenPair, user, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	ctx, ok := h.proofContext(c, builder)
+	if !ok {
+		return
+	}
+
+	// X-Forwarded-For only counts from the trusted proxies, so clients cannot
+	// choose the IP that failed logins are counted against
+	ctx = service.ContextWithClientIP(ctx, c.ClientIP())
+	if h.countryHeader != "" {
+		ctx = service.ContextWithClientCountry(ctx, c.GetHeader(h.countryHeader))
+	}
+	tokenPair, user, err := h.authService.Login(ctx, req.Email, req.Password)
 	if err != nil {
-		if err == service.ErrInvalidCredentials {
+		var lockedErr *service.AccountLockedError
+		if builder.Unavailable(err) {
+			metrics.RecordLogin("unavailable")
+		} else if errors.As(err, &lockedErr) {
+			metrics.RecordLogin("locked")
+			h.auditLockout(c, req.Email, lockedErr)
+			c.Header("Retry-After", retryAfterSeconds(lockedErr.RetryAfter))
+			builder.ErrorFrom(err)
+		} else if err == service.ErrInvalidCredentials {
+			metrics.RecordLogin("invalid_credentials")
 			if loggingService, exists := c.Get("logging_service"); exists {
 				if ls, ok := loggingService.(service.LoggingService); ok {
 					middleware.AuditLogError(ls, c, "login_failed", "Failed login attempt", err, map[string]interface{}{
@@ -74,9 +175,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 					})
 				}
 			}
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyInvalidCredentials, locale)
-			builder.Error(http.StatusUnauthorized, dto.ErrCodeUnauthorized, errors.New(message))
+			builder.ErrorFrom(err)
 		} else {
+			metrics.RecordLogin("error")
 			// Log the actual error for debugging
 			if loggingService, exists := c.Get("logging_service"); exists {
 				if ls, ok := loggingService.(service.LoggingService); ok {
@@ -91,6 +192,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	metrics.RecordLogin("success")
 	c.Set("user_id", user.ID)
 	c.Set("user_email", user.Email)
 
@@ -103,16 +205,41 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	response := dto.LoginResponse{
-		Token: tokenPair.AccessToken,
+		Token:        tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
+		TokenType:    tokenType(ctx),
 		User: dto.UserResponse{
-			Email: user.Email,
-			Name:  user.Name,
+			Email:         user.Email,
+			Name:          user.Name,
+			PreviousLogin: user.PreviousLogin(),
 		},
 	}
 	builder.SuccessOK(response)
 }
 
+// auditLockout records a login rejected by a lockout, or one that caused it.
+func (h *AuthHandler) auditLockout(c *gin.Context, email string, lockedErr *service.AccountLockedError) {
+	loggingService, exists := c.Get("logging_service")
+	if !exists {
+		return
+	}
+	ls, ok := loggingService.(service.LoggingService)
+	if !ok {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"email":               email,
+		"scope":               lockedErr.Scope,
+		"retry_after_seconds": int(lockedErr.RetryAfter.Seconds()),
+	}
+	if lockedErr.NewlyLocked {
+		middleware.AuditLogError(ls, c, "account_locked", "Locked out after repeated failed logins", lockedErr, fields)
+		return
+	}
+	middleware.AuditLogError(ls, c, "login_locked", "Login rejected while locked out", lockedErr, fields)
+}
+
 // Register handles POST /api/auth/register requests.
 //
 // @Summary      Register new user
@@ -125,30 +252,33 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Failure      400 {object} dto.ErrorResponse "Bad request - invalid input"
 // @Failure      409 {object} dto.ErrorResponse "Conflict - user already exists"
 // @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Failure      503 {object} dto.ErrorResponse "Service unavailable - retry after the Retry-After header"
 // @Router       /api/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	builder := NewResponseBuilder(c)
-	locale := i18n.GetLocale(c)
 
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		builder.Error(http.StatusBadRequest, authValidationMessageKey(err), err)
 		return
 	}
 
-		if err := req.Validate(); err != nil {
-		if validationErr, ok := err.(*dto.ValidationError); ok {
-			message := "validation error: " + validationErr.Message
-			builder.Error(http.StatusBadRequest, dto.ErrCodeInvalidRequest, errors.New(message))
-		} else {
-			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
-		}
+	if err := req.Validate(); err != nil {
+		builder.Error(http.StatusBadRequest, authValidationMessageKey(err), err)
 		return
 	}
 
-	tokenPair, user, err := h.authService.Register(c.Request.Context(), req.Email, req.Username, req.Password, req.Name)
+	ctx, ok := h.proofContext(c, builder)
+	if !ok {
+		return
+	}
+
+	tokenPair, user, err := h.authService.Register(ctx, req.Email, req.Username, req.Password, req.Name)
 	if err != nil {
-		if err == service.ErrUserExists {
+		if builder.Unavailable(err) {
+			return
+		}
+		if errors.Is(err, service.ErrUserExists) {
 			if loggingService, exists := c.Get("logging_service"); exists {
 				if ls, ok := loggingService.(service.LoggingService); ok {
 					middleware.AuditLogError(ls, c, "register_failed", "Failed registration attempt - user already exists", err, map[string]interface{}{
@@ -156,8 +286,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 					})
 				}
 			}
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyConflict, locale)
-			builder.Error(http.StatusConflict, dto.ErrCodeConflict, errors.New(message))
+			builder.ErrorFrom(err)
 		} else {
 			builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
 		}
@@ -177,8 +306,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	response := dto.LoginResponse{
-		Token: tokenPair.AccessToken,
+		Token:        tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
+		TokenType:    tokenType(ctx),
 		User: dto.UserResponse{
 			Email: user.Email,
 			Name:  user.Name,
@@ -199,32 +329,42 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Failure      400 {object} dto.ErrorResponse "Bad request - missing refresh token"
 // @Failure      401 {object} dto.ErrorResponse "Unauthorized - invalid refresh token"
 // @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Failure      503 {object} dto.ErrorResponse "Service unavailable - retry after the Retry-After header"
 // @Router       /api/auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	builder := NewResponseBuilder(c)
-	locale := i18n.GetLocale(c)
 
 	// Extract refresh token from X-Refresh-Token header
 	refreshToken := c.GetHeader("X-Refresh-Token")
 	if refreshToken == "" {
-		builder.Error(http.StatusBadRequest, dto.ErrCodeInvalidRequest, errors.New("X-Refresh-Token header is required"))
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyRefreshTokenRequired, nil)
 		return
 	}
 
-	tokenPair, err := h.authService.RefreshToken(c.Request.Context(), refreshToken)
+	ctx, ok := h.proofContext(c, builder)
+	if !ok {
+		return
+	}
+
+	tokenPair, err := h.authService.RefreshToken(ctx, refreshToken)
 	if err != nil {
-		if err == service.ErrInvalidToken {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyInvalidToken, locale)
-			builder.Error(http.StatusUnauthorized, dto.ErrCodeUnauthorized, errors.New(message))
+		if builder.Unavailable(err) {
+			metrics.RecordTokenRefresh("unavailable")
+		} else if err == service.ErrInvalidToken {
+			metrics.RecordTokenRefresh("invalid_token")
+			builder.ErrorFrom(err)
 		} else {
+			metrics.RecordTokenRefresh("error")
 			builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
 		}
 		return
 	}
+	metrics.RecordTokenRefresh("success")
 
 	response := dto.LoginResponse{
-		Token: tokenPair.AccessToken,
+		Token:        tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
+		TokenType:    tokenType(ctx),
 	}
 	builder.SuccessOK(response)
 }
@@ -243,6 +383,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 // @Failure      400 {object} dto.ErrorResponse "Bad request - missing refresh token"
 // @Failure      401 {object} dto.ErrorResponse "Unauthorized"
 // @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Failure      503 {object} dto.ErrorResponse "Service unavailable - retry after the Retry-After header"
 // @Router       /api/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	builder := NewResponseBuilder(c)
@@ -250,32 +391,34 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	// Extract access token from Authorization header (already validated by JWTAuth middleware)
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		builder.Error(http.StatusUnauthorized, dto.ErrCodeUnauthorized, errors.New("authorization header required"))
+		builder.Error(http.StatusUnauthorized, i18n.ErrKeyTokenRequired, nil)
 		return
 	}
 
-	// Extract token from "Bearer <token>"
-	const bearerPrefix = "Bearer "
-	if !strings.HasPrefix(authHeader, bearerPrefix) {
-		builder.Error(http.StatusUnauthorized, dto.ErrCodeUnauthorized, errors.New("invalid authorization header format"))
+	// Extract token from "Bearer <token>" or "DPoP <token>"
+	accessToken, _, ok := middleware.AccessTokenFromHeader(authHeader)
+	if !ok {
+		builder.Error(http.StatusUnauthorized, i18n.ErrKeyInvalidAuthorizationHeader, nil)
 		return
 	}
 
-	accessToken := strings.TrimPrefix(authHeader, bearerPrefix)
 	if accessToken == "" {
-		builder.Error(http.StatusUnauthorized, dto.ErrCodeUnauthorized, errors.New("access token required"))
+		builder.Error(http.StatusUnauthorized, i18n.ErrKeyTokenRequired, nil)
 		return
 	}
 
 	// Extract refresh token from X-Refresh-Token header
 	refreshToken := c.GetHeader("X-Refresh-Token")
 	if refreshToken == "" {
-		builder.Error(http.StatusBadRequest, dto.ErrCodeInvalidRequest, errors.New("X-Refresh-Token header is required"))
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyRefreshTokenRequired, nil)
 		return
 	}
 
 	err := h.authService.Logout(c.Request.Context(), accessToken, refreshToken)
 	if err != nil {
+		if builder.Unavailable(err) {
+			return
+		}
 		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
 		return
 	}
@@ -286,5 +429,45 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		}
 	}
 
-	builder.SuccessOK(map[string]string{"message": "Logged out successfully"})
+	builder.SuccessOK(map[string]string{"message": i18n.Message(c, i18n.SuccessKeyLoggedOut)})
+}
+
+// LogoutAll handles POST /api/auth/logout-all requests.
+//
+// @Summary      Logout from all sessions
+// @Description  Revokes every access and refresh token issued to the authenticated user, including the one used for this request, by incrementing the user's token version.
+// @Tags         Auth
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization header string true "Bearer token" default(Bearer )
+// @Success      200 {object} dto.SuccessResponse "All sessions revoked"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Failure      503 {object} dto.ErrorResponse "Service unavailable - retry after the Retry-After header"
+// @Router       /api/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	userID, ok := c.Get("user_id")
+	id, isID := userID.(primitive.ObjectID)
+	if !ok || !isID || id.IsZero() {
+		builder.Error(http.StatusUnauthorized, i18n.ErrKeyUnauthorized, nil)
+		return
+	}
+
+	if err := h.authService.RevokeUserTokens(c.Request.Context(), id); err != nil {
+		if builder.Unavailable(err) {
+			return
+		}
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, "logout_all", "User revoked all sessions", nil)
+		}
+	}
+
+	builder.SuccessOK(map[string]string{"message": "Logged out from all sessions"})
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -291,6 +292,32 @@ func TestAuthHandler_Register_Integration(t *testing.T) {
 	})
 }
 
+// TestAuthHandler_Deterministic_Integration does not run in parallel:
+// deterministic mode is process-wide.
+func TestAuthHandler_Deterministic_Integration(t *testing.T) {
+	register := func(dbName string) []byte {
+		testutil.Deterministic(t, 7)
+		router := setupAuthIntegrationRouter(dbName)
+
+		body, _ := json.Marshal(dto.RegisterRequest{
+			Email:    "fixture@example.com",
+			Username: "fixture",
+			Password: "password123",
+			Name:     "Fixture",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/register", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		return w.Body.Bytes()
+	}
+
+	first := register(sanitizeDBNameForHTTP(t.Name() + "_first"))
+	second := register(sanitizeDBNameForHTTP(t.Name() + "_second"))
+	assert.JSONEq(t, string(first), string(second), "the same seed gives the same user IDs, tokens and timestamps")
+}
+
 func TestAuthHandler_RefreshToken_Integration(t *testing.T) {
 	t.Parallel()
 
@@ -322,9 +349,6 @@ func TestAuthHandler_RefreshToken_Integration(t *testing.T) {
 		err = json.Unmarshal(dataBytes, &loginResponse)
 		require.NoError(t, err)
 
-		// Wait for at least 1 second to ensure JWT timestamps differ
-		time.Sleep(time.Second)
-
 		// Refresh token is passed in X-Refresh-Token header, not body
 		req = httptest.NewRequest(http.MethodPost, "/api/auth/refresh", nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -2,17 +2,26 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/testutil"
 	"github.com/guttosm/pack-service/internal/mocks"
 	"github.com/guttosm/pack-service/internal/service"
 )
@@ -54,6 +63,30 @@ func TestAuthHandler_Login(t *testing.T) {
 				assert.NotNil(t, response.Data)
 			},
 		},
+		{
+			name: "successful login shows the previous login",
+			requestBody: dto.LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			setupMocks: func(mockAuth *mocks.MockAuthService, mockLogging *mocks.MockLoggingService) {
+				user := &model.User{
+					ID:    primitive.NewObjectID(),
+					Email: "test@example.com",
+					RecentLogins: []model.LoginRecord{
+						{At: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), IP: "203.0.113.7"},
+						{At: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), IP: "198.51.100.2"},
+					},
+				}
+				mockAuth.On("Login", mock.Anything, "test@example.com", "password123").
+					Return(&dto.TokenPair{AccessToken: "access-token", RefreshToken: "refresh-token"}, user, nil)
+				mockLogging.On("CreateLog", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			validateResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), `"previous_login":{"at":"2026-03-01T09:00:00Z","ip":"198.51.100.2"}`)
+			},
+		},
 		{
 			name: "invalid credentials",
 			requestBody: dto.LoginRequest{
@@ -72,6 +105,48 @@ func TestAuthHandler_Login(t *testing.T) {
 				assert.NotEmpty(t, response.Error)
 			},
 		},
+		{
+			name: "account locked",
+			requestBody: dto.LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			setupMocks: func(mockAuth *mocks.MockAuthService, mockLogging *mocks.MockLoggingService) {
+				lockedErr := &service.AccountLockedError{Scope: service.LockoutScopeAccount, RetryAfter: 90*time.Second + time.Millisecond}
+				mockAuth.On("Login", mock.MatchedBy(func(ctx context.Context) bool {
+					return service.ClientIPFromContext(ctx) == "192.0.2.1"
+				}), "test@example.com", "password123").Return(nil, nil, lockedErr)
+				mockLogging.On("CreateLog", mock.Anything, mock.MatchedBy(func(e *model.LogEntry) bool {
+					return e.ActionType == "login_locked" && e.Fields["scope"] == service.LockoutScopeAccount
+				})).Return(nil).Maybe()
+			},
+			expectedStatus: http.StatusLocked,
+			validateResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, "91", w.Header().Get("Retry-After"))
+				var response dto.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, dto.ErrCodeAccountLocked, response.Error)
+			},
+		},
+		{
+			name: "auth circuit open",
+			requestBody: dto.LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			setupMocks: func(mockAuth *mocks.MockAuthService, mockLogging *mocks.MockLoggingService) {
+				openErr := &circuitbreaker.OpenError{Name: "mongodb-auth", RetryAfter: 12500 * time.Millisecond}
+				mockAuth.On("Login", mock.Anything, "test@example.com", "password123").
+					Return(nil, nil, fmt.Errorf("failed to find user by email: %w", openErr))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			validateResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, "13", w.Header().Get("Retry-After"))
+				var response dto.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, dto.ErrCodeDatabaseUnavailable, response.Error)
+			},
+		},
 		{
 			name: "invalid request body",
 			requestBody: map[string]interface{}{
@@ -140,6 +215,125 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_TranslatesValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/login", NewAuthHandler(new(mocks.MockAuthService)).Login)
+
+	body, _ := json.Marshal(dto.LoginRequest{Email: "test@example.com", Password: "short"})
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "fr-FR, pt-BR;q=0.8, en;q=0.5")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.ErrCodeInvalidRequest, response.Error)
+	assert.Equal(t, "password: deve ter pelo menos 6 caracteres", response.Message)
+}
+
+func TestAuthHandler_Login_CountryHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockAuthService := new(mocks.MockAuthService)
+	mockAuthService.On("Login", mock.MatchedBy(func(ctx context.Context) bool {
+		return service.ClientCountryFromContext(ctx) == "PT"
+	}), "test@example.com", "password123").
+		Return(&dto.TokenPair{AccessToken: "access-token", RefreshToken: "refresh-token"}, &model.User{Email: "test@example.com"}, nil)
+
+	router := gin.New()
+	router.POST("/login", NewAuthHandler(mockAuthService, WithCountryHeader("CF-IPCountry")).Login)
+
+	body, _ := json.Marshal(dto.LoginRequest{Email: "test@example.com", Password: "password123"})
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("CF-IPCountry", "pt")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestAuthHandler_Login_DPoP(t *testing.T) {
+	const loginURL = "http://example.com/login"
+	key := testutil.NewDPoPKey(t)
+
+	tests := []struct {
+		name              string
+		proof             func() string
+		required          bool
+		expectedStatus    int
+		expectedTokenType string
+		expectedJKT       string
+	}{
+		{
+			name:              "valid proof binds tokens to the key",
+			proof:             func() string { return key.Proof(http.MethodPost, loginURL, "") },
+			expectedStatus:    http.StatusOK,
+			expectedTokenType: "DPoP",
+			expectedJKT:       key.Thumbprint(),
+		},
+		{
+			name:              "no proof issues bearer tokens",
+			proof:             func() string { return "" },
+			expectedStatus:    http.StatusOK,
+			expectedTokenType: "Bearer",
+		},
+		{
+			name:           "no proof when required",
+			proof:          func() string { return "" },
+			required:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "proof for another endpoint",
+			proof:          func() string { return key.Proof(http.MethodPost, "http://example.com/register", "") },
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockAuthService := new(mocks.MockAuthService)
+			mockAuthService.On("Login", mock.MatchedBy(func(ctx context.Context) bool {
+				return dpop.ThumbprintFromContext(ctx) == tt.expectedJKT
+			}), "test@example.com", "password123").
+				Return(&dto.TokenPair{AccessToken: "access-token", RefreshToken: "refresh-token"}, &model.User{Email: "test@example.com"}, nil).
+				Maybe()
+
+			handler := NewAuthHandler(mockAuthService, WithDPoP(dpop.NewVerifier(dpop.DefaultConfig()), tt.required))
+			router := gin.New()
+			router.POST("/login", handler.Login)
+
+			body, _ := json.Marshal(dto.LoginRequest{Email: "test@example.com", Password: "password123"})
+			req := httptest.NewRequest(http.MethodPost, loginURL, bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			if proof := tt.proof(); proof != "" {
+				req.Header.Set(dpop.HeaderName, proof)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data dto.LoginResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedTokenType, response.Data.TokenType)
+			} else {
+				mockAuthService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func TestAuthHandler_Register(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -247,6 +441,14 @@ func TestAuthHandler_RefreshToken(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:               "auth circuit open",
+			refreshTokenHeader: "valid-refresh-token",
+			setupMocks: func(mockAuth *mocks.MockAuthService) {
+				mockAuth.On("RefreshToken", mock.Anything, "valid-refresh-token").Return(nil, &circuitbreaker.OpenError{Name: "mongodb-auth"})
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
 		{
 			name: "invalid refresh token",
 			refreshTokenHeader: "invalid-token",
@@ -284,6 +486,32 @@ func TestAuthHandler_RefreshToken(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_RecordsOutcomes(t *testing.T) {
+	mockAuthService := mocks.NewMockAuthService(t)
+	mockAuthService.On("Login", mock.Anything, "test@example.com", "wrongpassword").Return(nil, nil, service.ErrInvalidCredentials)
+	mockAuthService.On("RefreshToken", mock.Anything, "invalid-token").Return(nil, service.ErrInvalidToken)
+
+	handler := NewAuthHandler(mockAuthService)
+	router := gin.New()
+	router.POST("/login", handler.Login)
+	router.POST("/refresh", handler.RefreshToken)
+
+	logins := promtestutil.ToFloat64(metrics.LoginsTotal.WithLabelValues("invalid_credentials"))
+	refreshes := promtestutil.ToFloat64(metrics.TokenRefreshesTotal.WithLabelValues("invalid_token"))
+
+	body, _ := json.Marshal(dto.LoginRequest{Email: "test@example.com", Password: "wrongpassword"})
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.Header.Set("X-Refresh-Token", "invalid-token")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, logins+1, promtestutil.ToFloat64(metrics.LoginsTotal.WithLabelValues("invalid_credentials")))
+	assert.Equal(t, refreshes+1, promtestutil.ToFloat64(metrics.TokenRefreshesTotal.WithLabelValues("invalid_token")))
+}
+
 func TestAuthHandler_Logout(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -302,6 +530,16 @@ func TestAuthHandler_Logout(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:               "successful logout with dpop token",
+			authHeader:         "DPoP access-token",
+			refreshTokenHeader: "refresh-token",
+			setupMocks: func(mockAuth *mocks.MockAuthService, mockLogging *mocks.MockLoggingService) {
+				mockAuth.On("Logout", mock.Anything, "access-token", "refresh-token").Return(nil)
+				mockLogging.On("CreateLog", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:               "missing authorization header",
 			authHeader:         "",
@@ -379,3 +617,96 @@ func TestAuthHandler_Logout(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthHandler_LogoutAll(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name           string
+		userID         interface{}
+		setupMocks     func(*mocks.MockAuthService, *mocks.MockLoggingService)
+		expectedStatus int
+	}{
+		{
+			name:   "revokes all sessions",
+			userID: userID,
+			setupMocks: func(mockAuth *mocks.MockAuthService, mockLogging *mocks.MockLoggingService) {
+				mockAuth.On("RevokeUserTokens", mock.Anything, userID).Return(nil)
+				mockLogging.On("CreateLog", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unauthenticated",
+			setupMocks:     func(mockAuth *mocks.MockAuthService, mockLogging *mocks.MockLoggingService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "revocation error",
+			userID: userID,
+			setupMocks: func(mockAuth *mocks.MockAuthService, mockLogging *mocks.MockLoggingService) {
+				mockAuth.On("RevokeUserTokens", mock.Anything, userID).Return(assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockAuthService := new(mocks.MockAuthService)
+			mockLoggingService := new(mocks.MockLoggingService)
+
+			tt.setupMocks(mockAuthService, mockLoggingService)
+
+			router.Use(func(c *gin.Context) {
+				if tt.userID != nil {
+					c.Set("user_id", tt.userID)
+				}
+				c.Set("logging_service", mockLoggingService)
+				c.Next()
+			})
+			router.POST("/logout-all", NewAuthHandler(mockAuthService).LogoutAll)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/logout-all", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_Login_ClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockAuthService := new(mocks.MockAuthService)
+	cfg := DefaultRouterConfig()
+	cfg.AuthService = mockAuthService
+	cfg.TrustedProxies = []string{"10.0.0.0/8"}
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	login := func(peer, forwardedFor, wantIP string) {
+		mockAuthService.On("Login", mock.MatchedBy(func(ctx context.Context) bool {
+			return service.ClientIPFromContext(ctx) == wantIP
+		}), "test@example.com", "password123").Return(nil, nil, service.ErrInvalidCredentials).Once()
+
+		body, _ := json.Marshal(dto.LoginRequest{Email: "test@example.com", Password: "password123"})
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = peer + ":40000"
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+
+	// A spoofed header neither escapes the IP lockout nor locks out its victim
+	login("192.0.2.1", "198.51.100.7", "192.0.2.1")
+	// Behind a trusted proxy, the client is the last hop before it
+	login("10.1.2.3", "198.51.100.7, 192.0.2.1", "192.0.2.1")
+
+	mockAuthService.AssertExpectations(t)
+}
@@ -0,0 +1,353 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/workerpool"
+)
+
+// MIMENDJSON is the content type of newline-delimited JSON streams.
+const MIMENDJSON = "application/x-ndjson"
+
+// streamWriteTimeout is how long a streamed response may take to write each
+// line. It is renewed after every flush, so long batches are not cut off by
+// the server's WriteTimeout while a stalled client still is.
+const streamWriteTimeout = 15 * time.Second
+
+// batchCalculator resolves pack sizes once per batch and calculates its
+// items, concurrently when the handler has a batch pool.
+type batchCalculator struct {
+	h   *Handler
+	ctx context.Context
+	// owner is the caller whose presets items can reference.
+	owner string
+	// tenant and locale select the messages of item errors.
+	tenant        string
+	locale        string
+	configSizes   []int
+	configCosts   map[int]float64
+	configVersion int
+	// record is the history record template shared by the batch's items.
+	record model.Calculation
+
+	mu         sync.Mutex // guards presets and presetErrs
+	presets    map[string]*model.Preset
+	presetErrs map[string]error
+}
+
+// CalculateBatch handles POST /api/calculate/batch requests.
+//
+// @Summary      Calculate packs for several orders
+// @Description  Calculates up to 10000 orders in one request. Items are validated and calculated independently; a failed item yields an error record instead of failing the batch. With Accept: application/x-ndjson, results are streamed one JSON object per line, in request order, as each item completes. With Accept: text/csv or the XLSX content type, results are streamed as a spreadsheet with one row per item, in request order. Items share a bounded worker pool with other batches; when it stays saturated past the queue timeout, the remaining items fail with service_unavailable.
+// @Tags         Packs
+// @Accept       json
+// @Produce      json
+// @Produce      application/x-ndjson
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        Idempotency-Key header string false "Idempotency key for request deduplication"
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.BatchCalculateRequest true "Orders"
+// @Success      200 {object} dto.SuccessResponse{data=dto.BatchCalculateResponse} "Batch results (one dto.BatchItemResult per line when streamed)"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid body or too many items"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - insufficient permissions"
+// @Failure      429 {object} dto.ErrorResponse "Too many requests - rate limit exceeded"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/calculate/batch [post]
+func (h *Handler) CalculateBatch(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.BatchCalculateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if len(req.Items) > dto.MaxBatchItems {
+		builder.ErrorWithMessage(http.StatusBadRequest,
+			fmt.Sprintf(i18n.Message(c, i18n.ErrKeyValidationBatchItems), dto.MaxBatchItems), nil)
+		return
+	}
+
+	batch := h.newBatchCalculator(c, req.Items)
+	if format := tableFormat(c, gin.MIMEJSON, MIMENDJSON, MIMECSV, MIMEXLSX); format != "" {
+		succeeded, failed := batch.table(c, format, req.Items)
+		h.auditBatch(c, len(req.Items), succeeded, failed, batch.configVersion, true)
+		return
+	}
+	if c.NegotiateFormat(gin.MIMEJSON, MIMENDJSON) == MIMENDJSON {
+		succeeded, failed := batch.stream(c, req.Items)
+		h.auditBatch(c, len(req.Items), succeeded, failed, batch.configVersion, true)
+		return
+	}
+
+	resp := dto.BatchCalculateResponse{Results: make([]dto.BatchItemResult, len(req.Items))}
+	batch.run(req.Items, func(result dto.BatchItemResult) bool {
+		resp.Results[result.Index] = result
+		if result.Error != nil {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+		return true
+	})
+	h.auditBatch(c, len(req.Items), resp.Succeeded, resp.Failed, batch.configVersion, false)
+	builder.SuccessOK(resp)
+}
+
+// newBatchCalculator prepares a batch of items for the request in c.
+func (h *Handler) newBatchCalculator(c *gin.Context, items []dto.CalculatePacksRequest) *batchCalculator {
+	return h.prepareBatch(c.Request.Context(), presetOwner(c), i18n.GetTenant(c), i18n.GetLocale(c),
+		newCalculationRecord(c, model.CalculationSourceBatch), items)
+}
+
+// prepareBatch prepares a batch of items calculated for owner, loading the
+// configured pack sizes when an item needs them.
+func (h *Handler) prepareBatch(ctx context.Context, owner, tenant, locale string, record model.Calculation, items []dto.CalculatePacksRequest) *batchCalculator {
+	batch := &batchCalculator{
+		h:          h,
+		ctx:        ctx,
+		owner:      owner,
+		tenant:     tenant,
+		locale:     locale,
+		record:     record,
+		presets:    make(map[string]*model.Preset),
+		presetErrs: make(map[string]error),
+	}
+	for _, item := range items {
+		if item.Preset == "" && len(item.PackSizes) == 0 {
+			batch.configSizes, batch.configCosts, batch.configVersion = h.getPackSizes(ctx)
+			break
+		}
+	}
+	return batch
+}
+
+// stream writes one result per line, flushing each so clients can process
+// results while the rest of the batch is calculated. It stops early when
+// the client goes away.
+func (b *batchCalculator) stream(c *gin.Context, items []dto.CalculatePacksRequest) (succeeded, failed int) {
+	w := c.Writer
+	w.Header().Set("Content-Type", MIMENDJSON)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	ctx := b.ctx
+
+	b.run(items, func(result dto.BatchItemResult) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if result.Error != nil {
+			failed++
+		} else {
+			succeeded++
+		}
+
+		// Best effort: wrappers that do not expose the connection keep the server deadline
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err := enc.Encode(result); err != nil {
+			return false
+		}
+		w.Flush()
+		return true
+	})
+	return succeeded, failed
+}
+
+// run calculates items and passes the results to emit in request order,
+// stopping when emit returns false. With a batch pool, items run on shared
+// workers capped per request; items that get no worker in time fail with
+// service_unavailable.
+func (b *batchCalculator) run(items []dto.CalculatePacksRequest, emit func(dto.BatchItemResult) bool) {
+	if b.h.batchPool == nil {
+		for i, item := range items {
+			if !emit(b.calculate(i, item)) {
+				return
+			}
+		}
+		return
+	}
+
+	workerpool.Ordered(b.ctx, b.h.batchPool, len(items),
+		func(i int) dto.BatchItemResult {
+			return b.calculate(i, items[i])
+		},
+		func(i int, err error) dto.BatchItemResult {
+			switch {
+			case errors.Is(err, workerpool.ErrSaturated):
+				return b.failureWithCode(i, dto.ErrCodeServiceUnavailable, i18n.ErrKeyServiceBusy)
+			case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+				return b.failure(i, http.StatusGatewayTimeout, i18n.ErrKeyTimeout)
+			default:
+				return b.failure(i, http.StatusInternalServerError, i18n.ErrKeyInternalError)
+			}
+		},
+		func(_ int, result dto.BatchItemResult) bool {
+			return emit(result)
+		})
+}
+
+// calculate validates and calculates one batch item.
+func (b *batchCalculator) calculate(index int, req dto.CalculatePacksRequest) dto.BatchItemResult {
+	if err := req.Validate(); err != nil {
+		metrics.RecordPackCalculation(0, "validation_error")
+		return b.failure(index, http.StatusBadRequest, validationMessageKey(err))
+	}
+	if err := req.CheckItemsOrdered(service.MaxItemsOrdered(b.h.calculator)); err != nil {
+		metrics.RecordPackCalculation(0, "validation_error")
+		return b.orderTooLarge(index)
+	}
+	if err := b.h.hooks.ValidateRequest(b.ctx, &req); err != nil {
+		metrics.RecordPackCalculation(0, "validation_error")
+		return dto.BatchItemResult{
+			Index: index,
+			Error: &dto.BatchItemError{Code: dto.ErrCodeFromStatus(http.StatusBadRequest), Message: err.Error()},
+		}
+	}
+
+	var sizes []int
+	configVersion := 0
+	switch {
+	case req.Preset != "":
+		preset, err := b.preset(req.Preset)
+		if errors.Is(err, service.ErrPresetNotFound) {
+			return b.failureWithCode(index, service.ErrPresetNotFound.Code, i18n.ErrKeyPresetNotFound)
+		}
+		if err != nil {
+			return b.failure(index, http.StatusInternalServerError, i18n.ErrKeyInternalError)
+		}
+		sizes = preset.PackSizes
+		req.ApplyPreset(preset)
+		if err := req.Validate(); err != nil {
+			metrics.RecordPackCalculation(0, "validation_error")
+			return b.failure(index, http.StatusBadRequest, validationMessageKey(err))
+		}
+	case len(req.PackSizes) > 0:
+		sizes = make([]int, 0, len(req.PackSizes))
+		for _, size := range req.PackSizes {
+			if size > 0 {
+				sizes = append(sizes, size)
+			}
+		}
+	default:
+		sizes, configVersion = b.configSizes, b.configVersion
+		if len(req.PackCosts) == 0 {
+			req.PackCosts = b.configCosts
+		}
+	}
+
+	start := time.Now()
+	result, err := b.h.calculate(b.ctx, b.tenant, &req, sizes)
+	if errors.Is(err, service.ErrConstraintsUnsatisfiable) {
+		metrics.RecordPackCalculation(time.Since(start), "unsatisfiable")
+		return b.failure(index, http.StatusUnprocessableEntity, i18n.ErrKeyConstraintsUnsatisfiable)
+	}
+	if errors.Is(err, service.ErrOrderTooLarge) {
+		metrics.RecordPackCalculation(time.Since(start), "validation_error")
+		return b.orderTooLarge(index)
+	}
+	if errors.Is(err, service.ErrMissingPackCost) {
+		metrics.RecordPackCalculation(time.Since(start), "validation_error")
+		return b.failureWithCode(index, service.ErrMissingPackCost.Code, i18n.ErrKeyMissingPackCost)
+	}
+	if err != nil {
+		return b.failure(index, http.StatusInternalServerError, i18n.ErrKeyInternalError)
+	}
+	duration := time.Since(start)
+	recordPackCalculation(duration, sizes, result)
+	result.Metadata = req.Metadata
+	b.h.recordCalculation(b.record, &req, sizes, configVersion, result, duration)
+	b.h.shadowCalculation(&req, configVersion, result)
+
+	return dto.BatchItemResult{Index: index, Result: &result}
+}
+
+// preset loads a preset once per batch, remembering failures too.
+func (b *batchCalculator) preset(name string) (*model.Preset, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if preset, ok := b.presets[name]; ok {
+		return preset, nil
+	}
+	if err, ok := b.presetErrs[name]; ok {
+		return nil, err
+	}
+	if b.h.presetService == nil {
+		return nil, service.ErrPresetNotFound
+	}
+
+	preset, err := b.h.presetService.Get(b.ctx, b.owner, name)
+	if err != nil {
+		b.presetErrs[name] = err
+		return nil, err
+	}
+	b.presets[name] = preset
+	return preset, nil
+}
+
+func (b *batchCalculator) failure(index, status int, messageKey string) dto.BatchItemResult {
+	return b.failureWithCode(index, dto.ErrCodeFromStatus(status), messageKey)
+}
+
+func (b *batchCalculator) failureWithCode(index int, code, messageKey string) dto.BatchItemResult {
+	return dto.BatchItemResult{
+		Index: index,
+		Error: &dto.BatchItemError{
+			Code:    code,
+			Message: i18n.GetTranslator().TranslateFor(b.tenant, messageKey, b.locale),
+		},
+	}
+}
+
+// orderTooLarge fails an item whose order is above the calculator's maximum.
+func (b *batchCalculator) orderTooLarge(index int) dto.BatchItemResult {
+	return dto.BatchItemResult{
+		Index: index,
+		Error: &dto.BatchItemError{
+			Code:    dto.ErrCodeUnprocessable,
+			Message: orderTooLargeMessage(b.tenant, b.locale, service.MaxItemsOrdered(b.h.calculator)),
+		},
+	}
+}
+
+// auditBatch records one audit entry for the whole batch.
+func (h *Handler) auditBatch(c *gin.Context, items, succeeded, failed, configVersion int, streamed bool) {
+	loggingService, exists := c.Get("logging_service")
+	if !exists {
+		return
+	}
+	ls, ok := loggingService.(service.LoggingService)
+	if !ok {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"batch_items": items,
+		"succeeded":   succeeded,
+		"failed":      failed,
+		"streamed":    streamed,
+	}
+	if configVersion > 0 {
+		fields[model.FieldPackSizesVersion] = configVersion
+	}
+	middleware.AuditLog(ls, c, model.ActionCalculateBatch, "Batch pack calculation requested", fields)
+}
@@ -0,0 +1,306 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/workerpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const mixedBatch = `{"items": [
+	{"items_ordered": 251},
+	{"items_ordered": 0},
+	{"items_ordered": 12001, "pack_sizes": [250, 500]},
+	{"items_ordered": 10, "preset": "warehouse-a", "pack_sizes": [5]}
+]}`
+
+func postBatch(router *gin.Engine, body, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCalculateBatch_JSON(t *testing.T) {
+	router := setupRouter()
+
+	w := postBatch(router, mixedBatch, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Results, 4)
+	assert.Equal(t, 2, resp.Data.Succeeded)
+	assert.Equal(t, 2, resp.Data.Failed)
+
+	results := resp.Data.Results
+	assert.Equal(t, 500, results[0].Result.TotalItems)
+	assert.Equal(t, dto.ErrCodeInvalidRequest, results[1].Error.Code)
+	assert.Nil(t, results[1].Result)
+	assert.Equal(t, 12250, results[2].Result.TotalItems)
+	assert.Equal(t, "preset: cannot be combined with pack_sizes", results[3].Error.Message)
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+	}
+}
+
+func TestCalculateBatch_NDJSON(t *testing.T) {
+	router := setupRouter()
+
+	w := postBatch(router, mixedBatch, MIMENDJSON)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMENDJSON, w.Header().Get("Content-Type"))
+	assert.True(t, w.Flushed, "each line is flushed as it completes")
+
+	var results []dto.BatchItemResult
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var r dto.BatchItemResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r), "line %q", scanner.Text())
+		results = append(results, r)
+	}
+	require.Len(t, results, 4)
+	assert.NotNil(t, results[0].Result)
+	assert.NotNil(t, results[1].Error)
+	assert.NotNil(t, results[2].Result)
+	assert.NotNil(t, results[3].Error)
+}
+
+func TestCalculateBatch_InvalidRequests(t *testing.T) {
+	router := setupRouter()
+
+	tooMany := `{"items": [` + strings.TrimSuffix(strings.Repeat(`{"items_ordered": 1},`, dto.MaxBatchItems+1), ",") + `]}`
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed body", `{`},
+		{"missing items", `{}`},
+		{"empty items", `{"items": []}`},
+		{"too many items", tooMany},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Whole-request errors are plain JSON errors even when streaming was requested
+			w := postBatch(router, tt.body, MIMENDJSON)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		})
+	}
+}
+
+func TestCalculateBatch_ResolvesSharedInputsOnce(t *testing.T) {
+	t.Run("sequential", func(t *testing.T) {
+		testResolvesSharedInputsOnce(t)
+	})
+	t.Run("worker pool", func(t *testing.T) {
+		testResolvesSharedInputsOnce(t, WithBatchPool(workerpool.New(workerpool.Config{Name: "test_batch", Workers: 4})))
+	})
+}
+
+func testResolvesSharedInputsOnce(t *testing.T, opts ...HandlerOption) {
+	mockPresets := mocks.NewMockPresetService(t)
+	mockPresets.EXPECT().Get(mock.Anything, "", "warehouse-a").
+		Return(&model.Preset{Name: "warehouse-a", PackSizes: []int{23, 31, 53}}, nil).Once()
+	mockPresets.EXPECT().Get(mock.Anything, "", "missing").Return(nil, service.ErrPresetNotFound).Once()
+
+	mockPackSizes := mocks.NewMockPackSizesService(t)
+	mockPackSizes.EXPECT().GetActive(mock.Anything).Return(nil, fmt.Errorf("db down")).Once()
+
+	router := gin.New()
+	handler := NewHandler(service.NewPackCalculatorService(), mockPackSizes, append(opts, WithPresetService(mockPresets))...)
+	router.POST("/api/calculate/batch", handler.CalculateBatch)
+
+	w := postBatch(router, `{"items": [
+		{"items_ordered": 263, "preset": "warehouse-a"},
+		{"items_ordered": 100, "preset": "warehouse-a"},
+		{"items_ordered": 5, "preset": "missing"},
+		{"items_ordered": 5, "preset": "missing"},
+		{"items_ordered": 251},
+		{"items_ordered": 501}
+	]}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 4, resp.Data.Succeeded)
+	assert.Equal(t, "preset_not_found", resp.Data.Results[2].Error.Code)
+	assert.Equal(t, 263, resp.Data.Results[0].Result.TotalItems)
+	// Falls back to the default pack sizes when the active config is unavailable
+	assert.Equal(t, 500, resp.Data.Results[4].Result.TotalItems)
+}
+
+func TestCalculateBatch_ConfiguredPackCosts(t *testing.T) {
+	mockPackSizes := mocks.NewMockPackSizesService(t)
+	mockPackSizes.EXPECT().GetActive(mock.Anything).Return(&repository.PackSizeConfig{
+		Sizes: []int{250, 500}, Costs: map[int]float64{250: 1, 500: 5}, Version: 4,
+	}, nil).Once()
+
+	router := gin.New()
+	handler := NewHandler(service.NewPackCalculatorService(), mockPackSizes)
+	router.POST("/api/calculate/batch", handler.CalculateBatch)
+
+	w := postBatch(router, `{"items": [
+		{"items_ordered": 251, "optimize": "min_cost"},
+		{"items_ordered": 251},
+		{"items_ordered": 251, "pack_sizes": [250, 750], "optimize": "min_cost"}
+	]}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []model.Pack{{Size: 250, Quantity: 2}}, resp.Data.Results[0].Result.Packs)
+	assert.Equal(t, 2.0, *resp.Data.Results[0].Result.TotalCost)
+	// The stored costs price the fewest items result too
+	assert.Equal(t, []model.Pack{{Size: 500, Quantity: 1}}, resp.Data.Results[1].Result.Packs)
+	assert.Equal(t, 5.0, *resp.Data.Results[1].Result.TotalCost)
+	// Custom sizes do not use the stored costs
+	assert.Equal(t, service.ErrMissingPackCost.Code, resp.Data.Results[2].Error.Code)
+}
+
+func TestCalculateBatch_WorkerPool(t *testing.T) {
+	cfg := DefaultRouterConfig()
+	cfg.BatchPool = workerpool.New(workerpool.Config{Name: "test_batch", Workers: 4, MaxPerCaller: 2})
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	items := make([]string, 200)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"items_ordered": %d}`, i+1)
+	}
+	body := `{"items": [` + strings.Join(items, ",") + `]}`
+
+	w := postBatch(router, body, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 200, resp.Data.Succeeded)
+
+	w = postBatch(router, body, MIMENDJSON)
+	require.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 200)
+	for i, line := range lines {
+		var r dto.BatchItemResult
+		require.NoError(t, json.Unmarshal([]byte(line), &r))
+		assert.Equal(t, i, r.Index, "results are streamed in request order")
+		assert.Equal(t, resp.Data.Results[i].Result.TotalItems, r.Result.TotalItems)
+	}
+}
+
+func TestCalculateBatch_SaturatedPool(t *testing.T) {
+	pool := workerpool.New(workerpool.Config{Name: "test_batch_saturated", Workers: 1, QueueTimeout: 10 * time.Millisecond})
+	handler := NewHandler(service.NewPackCalculatorService(), nil, WithBatchPool(pool))
+	router := gin.New()
+	router.POST("/api/calculate/batch", handler.CalculateBatch)
+
+	// Keep the only worker busy
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		workerpool.Ordered(context.Background(), pool, 1,
+			func(int) bool {
+				close(started)
+				<-release
+				return true
+			},
+			func(int, error) bool { return false },
+			func(int, bool) bool { return true })
+	}()
+	<-started
+	defer func() {
+		close(release)
+		<-done
+	}()
+
+	w := postBatch(router, `{"items": [{"items_ordered": 1}, {"items_ordered": 2}, {"items_ordered": 3}]}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 3, resp.Data.Failed)
+	for _, r := range resp.Data.Results {
+		require.NotNil(t, r.Error)
+		assert.Equal(t, dto.ErrCodeServiceUnavailable, r.Error.Code)
+	}
+}
+
+func TestCalculateBatch_Constraints(t *testing.T) {
+	router := setupRouter()
+
+	w := postBatch(router, `{"items": [
+		{"items_ordered": 12001, "max_packs": 3},
+		{"items_ordered": 251, "max_overage_items": 10},
+		{"items_ordered": 251, "max_packs": 0},
+		{"items_ordered": 10000001}
+	]}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	results := resp.Data.Results
+	require.Len(t, results, 4)
+	assert.Equal(t, 15000, results[0].Result.TotalItems)
+	assert.Equal(t, dto.ErrCodeUnprocessable, results[1].Error.Code)
+	assert.Equal(t, dto.ErrCodeInvalidRequest, results[2].Error.Code)
+	assert.Equal(t, "max_packs: must be a positive integer", results[2].Error.Message)
+	assert.Equal(t, dto.ErrCodeUnprocessable, results[3].Error.Code)
+	assert.Equal(t, "items_ordered: must be at most 10000000", results[3].Error.Message)
+}
+
+func TestCalculateBatch_Metadata(t *testing.T) {
+	router := setupRouter()
+
+	w := postBatch(router, `{"items": [
+		{"items_ordered": 251, "metadata": {"order_id": "A-1"}},
+		{"items_ordered": 500},
+		{"items_ordered": 251, "metadata": {"$where": "x"}}
+	]}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	results := resp.Data.Results
+	require.Len(t, results, 3)
+	assert.Equal(t, map[string]string{"order_id": "A-1"}, results[0].Result.Metadata)
+	assert.Nil(t, results[1].Result.Metadata)
+	assert.Equal(t, dto.ErrCodeInvalidRequest, results[2].Error.Code)
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/service/cache"
+)
+
+// CacheManager is implemented by calculators whose result cache can be
+// inspected and invalidated.
+type CacheManager interface {
+	CacheMetrics() (cache.Metrics, bool)
+	CacheShardMetrics() []cache.Metrics
+	InvalidateCache()
+	InvalidateCachedResult(itemsOrdered int, packSizes []int)
+}
+
+// CacheHandler reports and invalidates the calculation result cache.
+type CacheHandler struct {
+	cache     CacheManager
+	packSizes *service.ActivePackSizes
+}
+
+// NewCacheHandler creates a new CacheHandler instance. packSizes, when not
+// nil, provides the active pack sizes whose results DELETE invalidates by
+// default.
+func NewCacheHandler(cache CacheManager, packSizes *service.ActivePackSizes) *CacheHandler {
+	return &CacheHandler{cache: cache, packSizes: packSizes}
+}
+
+// GetStats handles GET /api/admin/cache/stats requests.
+//
+// @Summary      Result cache statistics
+// @Description  Reports the hits, misses, evictions and size of this instance's calculation result cache, in total and per shard of the in-memory cache.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=dto.CacheStats} "Result cache statistics"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Security     BearerAuth
+// @Router       /api/admin/cache/stats [get]
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	metrics, ok := h.cache.CacheMetrics()
+	if !ok {
+		NewResponseBuilder(c).SuccessOK(dto.CacheStats{})
+		return
+	}
+
+	stats := dto.CacheStats{
+		Enabled:   true,
+		Hits:      metrics.Hits,
+		Misses:    metrics.Misses,
+		Evictions: metrics.Evictions,
+		Size:      metrics.Size,
+		Capacity:  metrics.Capacity,
+	}
+	if lookups := metrics.Hits + metrics.Misses; lookups > 0 {
+		stats.HitRate = float64(metrics.Hits) / float64(lookups)
+	}
+	for _, shard := range h.cache.CacheShardMetrics() {
+		stats.Shards = append(stats.Shards, dto.CacheShardStats{
+			Hits:      shard.Hits,
+			Misses:    shard.Misses,
+			Evictions: shard.Evictions,
+			Size:      shard.Size,
+			Capacity:  shard.Capacity,
+		})
+	}
+	NewResponseBuilder(c).SuccessOK(stats)
+}
+
+// Clear handles POST /api/admin/cache/clear requests.
+//
+// @Summary      Clear the result cache
+// @Description  Removes every cached calculation result. With the Redis cache, the results of all replicas are removed.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      204 "Result cache cleared"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Security     BearerAuth
+// @Router       /api/admin/cache/clear [post]
+func (h *CacheHandler) Clear(c *gin.Context) {
+	h.cache.InvalidateCache()
+
+	log.Warn().Msg("Result cache cleared")
+	h.audit(c, "clear_cache", "Result cache cleared", nil)
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteEntry handles DELETE /api/admin/cache/:key requests.
+//
+// @Summary      Invalidate a cached result
+// @Description  Removes the cached result of one order quantity, so the next request calculates it again. Without pack_sizes, the results with the active and the configured pack sizes are removed.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        key path int true "Order quantity" example(251)
+// @Param        pack_sizes query string false "Comma-separated pack sizes the result was calculated with" example(250,500,1000)
+// @Success      204 "Cached result removed"
+// @Failure      400 {object} dto.ErrorResponse "Invalid order quantity or pack sizes"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Security     BearerAuth
+// @Router       /api/admin/cache/{key} [delete]
+func (h *CacheHandler) DeleteEntry(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	items, err := parseInt(c.Param("key"))
+	if err != nil || items <= 0 {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest,
+			&dto.ValidationError{Field: "key", Message: "must be a positive order quantity"})
+		return
+	}
+	packSizes, err := parsePackSizesQuery(c.Query("pack_sizes"))
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+
+	if len(packSizes) > 0 {
+		h.cache.InvalidateCachedResult(items, packSizes)
+	} else {
+		h.cache.InvalidateCachedResult(items, nil)
+		if h.packSizes != nil {
+			if config := h.packSizes.Current(); config != nil {
+				h.cache.InvalidateCachedResult(items, config.Sizes)
+			}
+		}
+	}
+
+	h.audit(c, "invalidate_cache_entry", "Cached result invalidated", map[string]interface{}{
+		"items_ordered": items,
+		"pack_sizes":    packSizes,
+	})
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CacheHandler) audit(c *gin.Context, action, message string, fields map[string]interface{}) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, action, message, fields)
+		}
+	}
+}
+
+// parsePackSizesQuery parses a comma-separated list of pack sizes; an empty
+// value is no pack sizes.
+func parsePackSizesQuery(value string) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	sizes := make([]int, 0, len(parts))
+	for _, part := range parts {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || size <= 0 {
+			return nil, &dto.ValidationError{Field: "pack_sizes", Message: fmt.Sprintf("%q is not a positive pack size", part)}
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
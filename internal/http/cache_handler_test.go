@@ -0,0 +1,128 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func newCacheRouter(calculator CacheManager, packSizes *service.ActivePackSizes) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewCacheHandler(calculator, packSizes)
+	router := gin.New()
+	router.GET("/admin/cache/stats", handler.GetStats)
+	router.POST("/admin/cache/clear", handler.Clear)
+	router.DELETE("/admin/cache/:key", handler.DeleteEntry)
+	return router
+}
+
+func TestCacheHandler_GetStats(t *testing.T) {
+	t.Run("reports the cache per shard", func(t *testing.T) {
+		sharded := service.NewShardedCache(8, time.Minute, 2)
+		defer sharded.Stop()
+		calculator := service.NewPackCalculatorService(service.WithCacheInterface(sharded))
+		calculator.Calculate(251)
+		calculator.Calculate(251)
+		calculator.Calculate(500)
+
+		w := httptest.NewRecorder()
+		newCacheRouter(calculator, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data dto.CacheStats `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Data.Enabled)
+		assert.Equal(t, int64(1), resp.Data.Hits)
+		assert.Equal(t, int64(2), resp.Data.Misses)
+		assert.InDelta(t, 1.0/3, resp.Data.HitRate, 0.001)
+		assert.Equal(t, 2, resp.Data.Size)
+		assert.Equal(t, 8, resp.Data.Capacity)
+		require.Len(t, resp.Data.Shards, 2)
+		assert.Equal(t, 2, resp.Data.Shards[0].Size+resp.Data.Shards[1].Size)
+		assert.Equal(t, 4, resp.Data.Shards[0].Capacity)
+	})
+
+	t.Run("without a cache", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newCacheRouter(service.NewPackCalculatorService(), nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.JSONEq(t, `{"enabled":false,"hits":0,"misses":0,"hit_rate":0,"evictions":0,"size":0,"capacity":0}`, string(resp.Data))
+	})
+}
+
+func TestCacheHandler_Clear(t *testing.T) {
+	calculator := service.NewPackCalculatorService(service.WithCache(10, time.Minute))
+	calculator.Calculate(251)
+
+	w := httptest.NewRecorder()
+	newCacheRouter(calculator, nil).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/cache/clear", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.False(t, calculator.Calculate(251).CacheHit)
+}
+
+func TestCacheHandler_DeleteEntry(t *testing.T) {
+	activeSizes := []int{23, 31, 53}
+	view := service.NewActivePackSizes(nil, nil, 0)
+	view.Set(&repository.PackSizeConfig{Sizes: activeSizes, Version: 1})
+
+	prime := func() *service.PackCalculatorService {
+		calculator := service.NewPackCalculatorService(service.WithCache(10, time.Minute))
+		calculator.Calculate(251)
+		calculator.Calculate(500)
+		calculator.CalculateWithPackSizes(251, activeSizes)
+		calculator.CalculateWithPackSizes(251, []int{5, 10})
+		return calculator
+	}
+
+	t.Run("removes the results with the active and configured pack sizes", func(t *testing.T) {
+		calculator := prime()
+
+		w := httptest.NewRecorder()
+		newCacheRouter(calculator, view).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/cache/251", nil))
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.False(t, calculator.Calculate(251).CacheHit)
+		assert.False(t, calculator.CalculateWithPackSizes(251, activeSizes).CacheHit)
+		assert.True(t, calculator.CalculateWithPackSizes(251, []int{5, 10}).CacheHit)
+		assert.True(t, calculator.Calculate(500).CacheHit)
+	})
+
+	t.Run("removes the result with the given pack sizes", func(t *testing.T) {
+		calculator := prime()
+
+		w := httptest.NewRecorder()
+		newCacheRouter(calculator, view).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/cache/251?pack_sizes=10,5", nil))
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.False(t, calculator.CalculateWithPackSizes(251, []int{5, 10}).CacheHit)
+		assert.True(t, calculator.Calculate(251).CacheHit)
+	})
+
+	for _, target := range []string{"/admin/cache/abc", "/admin/cache/0", "/admin/cache/251?pack_sizes=10,x"} {
+		t.Run("rejects "+target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newCacheRouter(prime(), view).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, target, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// CalculationHandler serves the calculation history.
+type CalculationHandler struct {
+	history  service.CalculationHistoryService
+	location *time.Location
+	cursors  *pagination.Codec
+}
+
+// NewCalculationHandler creates a new CalculationHandler instance. Listed
+// timestamps are shown in location, or in UTC when it is nil. Pages link to
+// the next one with cursors encoded by cursors; nil leaves listings to
+// offsets.
+func NewCalculationHandler(history service.CalculationHistoryService, location *time.Location, cursors *pagination.Codec) *CalculationHandler {
+	return &CalculationHandler{history: history, location: location, cursors: cursors}
+}
+
+// ListCalculations handles GET /api/calculations requests.
+//
+// @Summary      Calculation history
+// @Description  Returns a page of stored pack calculations, newest first, with their input, result, pack sizes version, requesting user and latency. Timestamps are in the configured display timezone (UTC by default). Calculations are written asynchronously, so the most recent ones may take a few seconds to appear. Requires the system:read permission when auth is enabled.
+// @Tags         Packs
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        limit query int false "Page size (default 50, max 500)"
+// @Param        offset query int false "Number of calculations to skip"
+// @Param        cursor query string false "next_cursor of the previous page, instead of offset"
+// @Param        user_id query string false "Only calculations requested by this user"
+// @Param        from query string false "Only calculations at or after this time (RFC 3339)"
+// @Param        to query string false "Only calculations at or before this time (RFC 3339)"
+// @Param        min_items query int false "Only orders of at least this many items"
+// @Param        max_items query int false "Only orders of at most this many items"
+// @Param        pack_set query string false "Only calculations made with this pack set, such as 23,31,53 or default"
+// @Success      200 {object} dto.SuccessResponse{data=dto.CalculationPage} "Calculation history page"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid query parameter, or invalid_cursor to restart from the first page"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/calculations [get]
+func (h *CalculationHandler) ListCalculations(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	filter, limit, err := parseCalculationQuery(c)
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+	offset, err := pageOffset(c, h.cursors, "calculations")
+	if err != nil {
+		writeQueryError(builder, err)
+		return
+	}
+
+	page, err := h.history.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	for _, calc := range page.Calculations {
+		calc.CreatedAt = timeutil.In(calc.CreatedAt, h.location)
+	}
+	page.NextCursor = nextCursor(c, h.cursors, "calculations", page.Offset, len(page.Calculations), page.Total)
+
+	builder.SuccessOK(page)
+}
+
+// CalculationRollups handles GET /api/analytics/calculations requests.
+//
+// @Summary      Calculation analytics
+// @Description  Returns the calculation history aggregated by UTC day or month, requesting user and pack set: the number of calculations, items ordered and shipped, packs, approximate results, the order size range and the summed latency. Reports read pre-aggregated daily rollups, maintained by a background job, so they stay fast over months of history and trail the raw history by up to the job interval. Drill down into a day with GET /api/analytics/calculations/{day}. Requires the system:read permission when auth is enabled.
+// @Tags         Packs
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        from query string true "First UTC day (YYYY-MM-DD)"
+// @Param        to query string true "Last UTC day (YYYY-MM-DD), less than 731 days after from"
+// @Param        granularity query string false "day (default) or month"
+// @Param        user_id query string false "Only rollups of this user"
+// @Param        pack_set query string false "Only rollups of this pack set, such as 23,31,53 or default"
+// @Success      200 {object} dto.SuccessResponse{data=dto.CalculationRollupReport} "Calculation analytics report"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid query parameter"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/analytics/calculations [get]
+func (h *CalculationHandler) CalculationRollups(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	filter, err := parseRollupQuery(c)
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+
+	report, err := h.history.Rollups(c.Request.Context(), filter)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	builder.SuccessOK(report)
+}
+
+// CalculationDrilldown handles GET /api/analytics/calculations/:day requests.
+//
+// @Summary      Calculation analytics drill-down
+// @Description  Returns a page of the raw calculations behind a day of the analytics report, newest first, optionally only those of one user or pack set. Requires the system:read permission when auth is enabled.
+// @Tags         Packs
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        day path string true "UTC day (YYYY-MM-DD)"
+// @Param        user_id query string false "Only calculations requested by this user"
+// @Param        pack_set query string false "Only calculations made with this pack set, such as 23,31,53 or default"
+// @Param        limit query int false "Page size (default 50, max 500)"
+// @Param        offset query int false "Number of calculations to skip"
+// @Param        cursor query string false "next_cursor of the previous page, instead of offset"
+// @Success      200 {object} dto.SuccessResponse{data=dto.CalculationPage} "Calculation history page"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid day or query parameter, or invalid_cursor to restart from the first page"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/analytics/calculations/{day} [get]
+func (h *CalculationHandler) CalculationDrilldown(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	day, err := parseDay("day", c.Param("day"))
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+	listing := "calculations/" + day.Format(time.DateOnly)
+	offset, err := pageOffset(c, h.cursors, listing)
+	if err != nil {
+		writeQueryError(builder, err)
+		return
+	}
+
+	end := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	filter := dto.CalculationFilter{
+		UserID:  c.Query("user_id"),
+		From:    &day,
+		To:      &end,
+		PackSet: c.Query("pack_set"),
+	}
+	page, err := h.history.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	for _, calc := range page.Calculations {
+		calc.CreatedAt = timeutil.In(calc.CreatedAt, h.location)
+	}
+	page.NextCursor = nextCursor(c, h.cursors, listing, page.Offset, len(page.Calculations), page.Total)
+
+	builder.SuccessOK(page)
+}
+
+// parseRollupQuery reads the analytics report filter from the query string.
+func parseRollupQuery(c *gin.Context) (filter dto.RollupFilter, err error) {
+	if value := c.Query("from"); value != "" {
+		if filter.From, err = parseDay("from", value); err != nil {
+			return filter, err
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if filter.To, err = parseDay("to", value); err != nil {
+			return filter, err
+		}
+	}
+	filter.UserID = c.Query("user_id")
+	filter.PackSet = c.Query("pack_set")
+	filter.Granularity = c.Query("granularity")
+
+	return filter, filter.Validate()
+}
+
+// parseDay parses a UTC day in the YYYY-MM-DD form.
+func parseDay(name, value string) (time.Time, error) {
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, &dto.ValidationError{Field: name, Message: "must be a date (YYYY-MM-DD)"}
+	}
+	return day, nil
+}
+
+// parseCalculationQuery reads the listing filter and page size from the
+// query string.
+func parseCalculationQuery(c *gin.Context) (filter dto.CalculationFilter, limit int, err error) {
+	if limit, err = queryInt(c, "limit"); err != nil {
+		return filter, 0, err
+	}
+
+	filter.UserID = c.Query("user_id")
+	if filter.From, err = queryTime(c, "from"); err != nil {
+		return filter, 0, err
+	}
+	if filter.To, err = queryTime(c, "to"); err != nil {
+		return filter, 0, err
+	}
+	if filter.MinItems, err = queryOptionalInt(c, "min_items"); err != nil {
+		return filter, 0, err
+	}
+	if filter.MaxItems, err = queryOptionalInt(c, "max_items"); err != nil {
+		return filter, 0, err
+	}
+	filter.PackSet = c.Query("pack_set")
+
+	return filter, limit, filter.Validate()
+}
+
+// queryTime parses an optional RFC 3339 query parameter.
+func queryTime(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &dto.ValidationError{Field: name, Message: "must be an RFC 3339 timestamp"}
+	}
+	return &t, nil
+}
+
+// queryOptionalInt parses an optional integer query parameter.
+func queryOptionalInt(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := parseInt(value)
+	if err != nil {
+		return nil, &dto.ValidationError{Field: name, Message: "must be an integer"}
+	}
+	return &n, nil
+}
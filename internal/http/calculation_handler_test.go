@@ -0,0 +1,447 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/service"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculationHandler_ListCalculations(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	minItems, maxItems := 100, 1000
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockCalculationHistoryService)
+		expectedStatus int
+	}{
+		{
+			name:  "lists with defaults",
+			query: "",
+			setupMock: func(m *mocks.MockCalculationHistoryService) {
+				m.EXPECT().List(mock.Anything, dto.CalculationFilter{}, 0, 0).
+					Return(&dto.CalculationPage{Calculations: []*model.Calculation{{ItemsOrdered: 251}}, Total: 1, Limit: 50}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "applies filters",
+			query: "?user_id=u1&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&min_items=100&max_items=1000&limit=10&offset=20",
+			setupMock: func(m *mocks.MockCalculationHistoryService) {
+				m.EXPECT().List(mock.Anything, dto.CalculationFilter{
+					UserID: "u1", From: &from, To: &to, MinItems: &minItems, MaxItems: &maxItems,
+				}, 10, 20).Return(&dto.CalculationPage{Calculations: []*model.Calculation{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{name: "invalid from", query: "?from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "invalid min_items", query: "?min_items=many", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=x", expectedStatus: http.StatusBadRequest},
+		{name: "inverted items range", query: "?min_items=10&max_items=5", expectedStatus: http.StatusBadRequest},
+		{
+			name:  "repository error",
+			query: "",
+			setupMock: func(m *mocks.MockCalculationHistoryService) {
+				m.EXPECT().List(mock.Anything, mock.Anything, 0, 0).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHistory := mocks.NewMockCalculationHistoryService(t)
+			if tt.setupMock != nil {
+				tt.setupMock(mockHistory)
+			}
+
+			router := gin.New()
+			router.GET("/api/calculations", NewCalculationHandler(mockHistory, nil, nil).ListCalculations)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/calculations"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestCalculationHandler_ListCalculations_DisplayTimezone(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+
+	mockHistory := mocks.NewMockCalculationHistoryService(t)
+	mockHistory.EXPECT().List(mock.Anything, dto.CalculationFilter{}, 0, 0).Return(&dto.CalculationPage{
+		Calculations: []*model.Calculation{{CreatedAt: time.Date(2026, 1, 28, 10, 0, 0, 0, time.UTC)}},
+	}, nil)
+
+	router := gin.New()
+	router.GET("/api/calculations", NewCalculationHandler(mockHistory, saoPaulo, nil).ListCalculations)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/calculations", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"created_at":"2026-01-28T07:00:00-03:00"`)
+}
+
+func TestCalculationHandler_ListCalculations_Cursor(t *testing.T) {
+	cursors, err := pagination.NewCodec(nil, time.Minute)
+	require.NoError(t, err)
+
+	mockHistory := mocks.NewMockCalculationHistoryService(t)
+	router := gin.New()
+	router.GET("/api/calculations", NewCalculationHandler(mockHistory, nil, cursors).ListCalculations)
+	get := func(query string) (*httptest.ResponseRecorder, dto.CalculationPage) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/calculations"+query, nil))
+		var resp struct {
+			Data dto.CalculationPage `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.Data
+	}
+	filter := dto.CalculationFilter{UserID: "u1"}
+	page := func(offset, n int) *dto.CalculationPage {
+		calculations := make([]*model.Calculation, n)
+		for i := range calculations {
+			calculations[i] = &model.Calculation{ItemsOrdered: 251}
+		}
+		return &dto.CalculationPage{Calculations: calculations, Total: 5, Limit: 2, Offset: offset}
+	}
+
+	mockHistory.EXPECT().List(mock.Anything, filter, 2, 0).Return(page(0, 2), nil).Once()
+	w, first := get("?user_id=u1&limit=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, first.NextCursor)
+
+	mockHistory.EXPECT().List(mock.Anything, filter, 2, 2).Return(page(2, 2), nil).Once()
+	w, second := get("?user_id=u1&limit=2&cursor=" + first.NextCursor)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, second.NextCursor)
+
+	mockHistory.EXPECT().List(mock.Anything, filter, 2, 4).Return(page(4, 1), nil).Once()
+	w, last := get("?user_id=u1&limit=2&cursor=" + second.NextCursor)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, last.NextCursor, "no cursor after the last page")
+
+	for name, query := range map[string]string{
+		"cursor for another filter": "?user_id=u2&cursor=" + first.NextCursor,
+		"forged cursor":             "?user_id=u1&cursor=b2Zmc2V0PTEwMA",
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			w, _ := get(query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), `"error":"`+dto.ErrCodeInvalidCursor+`"`)
+		})
+	}
+
+	t.Run("rejects a cursor with an offset", func(t *testing.T) {
+		w, _ := get("?user_id=u1&offset=2&cursor=" + first.NextCursor)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"error":"`+dto.ErrCodeInvalidRequest+`"`)
+	})
+}
+
+func TestCalculationHandler_CalculationRollups(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockCalculationHistoryService)
+		expectedStatus int
+	}{
+		{
+			name:  "monthly report",
+			query: "?from=2026-01-01&to=2026-03-31&granularity=month&user_id=u1&pack_set=23,31,53",
+			setupMock: func(m *mocks.MockCalculationHistoryService) {
+				m.EXPECT().Rollups(mock.Anything, dto.RollupFilter{
+					From: from, To: to, UserID: "u1", PackSet: "23,31,53", Granularity: dto.GranularityMonth,
+				}).Return(&dto.CalculationRollupReport{
+					From: from, To: to, Granularity: dto.GranularityMonth,
+					Rollups: []*model.CalculationRollup{{Day: from, UserID: "u1", PackSet: "23,31,53", Calculations: 42}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{name: "missing range", query: "", expectedStatus: http.StatusBadRequest},
+		{name: "invalid day", query: "?from=2026-01-01T00:00:00Z&to=2026-03-31", expectedStatus: http.StatusBadRequest},
+		{name: "range too long", query: "?from=2024-01-01&to=2026-03-31", expectedStatus: http.StatusBadRequest},
+		{name: "invalid granularity", query: "?from=2026-01-01&to=2026-03-31&granularity=week", expectedStatus: http.StatusBadRequest},
+		{
+			name:  "repository error",
+			query: "?from=2026-01-01&to=2026-03-31",
+			setupMock: func(m *mocks.MockCalculationHistoryService) {
+				m.EXPECT().Rollups(mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHistory := mocks.NewMockCalculationHistoryService(t)
+			if tt.setupMock != nil {
+				tt.setupMock(mockHistory)
+			}
+
+			router := gin.New()
+			router.GET("/api/analytics/calculations", NewCalculationHandler(mockHistory, nil, nil).CalculationRollups)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/calculations"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"calculations":42`)
+			}
+		})
+	}
+}
+
+func TestCalculationHandler_CalculationDrilldown(t *testing.T) {
+	day := time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC)
+	end := day.Add(24*time.Hour - time.Nanosecond)
+
+	t.Run("lists the day's calculations", func(t *testing.T) {
+		mockHistory := mocks.NewMockCalculationHistoryService(t)
+		mockHistory.EXPECT().List(mock.Anything, dto.CalculationFilter{
+			UserID: "u1", From: &day, To: &end, PackSet: "default",
+		}, 10, 20).Return(&dto.CalculationPage{Calculations: []*model.Calculation{{ItemsOrdered: 251}}, Total: 21}, nil)
+
+		router := gin.New()
+		router.GET("/api/analytics/calculations/:day", NewCalculationHandler(mockHistory, nil, nil).CalculationDrilldown)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/calculations/2026-01-28?user_id=u1&pack_set=default&limit=10&offset=20", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"items_ordered":251`)
+	})
+
+	t.Run("invalid day", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/api/analytics/calculations/:day", NewCalculationHandler(mocks.NewMockCalculationHistoryService(t), nil, nil).CalculationDrilldown)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/calculations/yesterday", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCalculatePacks_RecordsHistory(t *testing.T) {
+	mockHistory := mocks.NewMockCalculationHistoryService(t)
+	mockHistory.EXPECT().Record(mock.MatchedBy(func(calc *model.Calculation) bool {
+		return calc.Source == model.CalculationSourceHTTP &&
+			calc.RequestID != "" &&
+			calc.ItemsOrdered == 251 &&
+			assert.ObjectsAreEqual([]int{250, 500}, calc.PackSizes) &&
+			calc.TotalItems == 500 &&
+			calc.Constraints != nil && *calc.Constraints.MaxPacks == 2
+	})).Once()
+
+	cfg := DefaultRouterConfig()
+	cfg.CalculationHistory = mockHistory
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251, "pack_sizes": [250, 500], "max_packs": 2}`))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Rejected requests are not recorded
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 0}`))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The listing is registered alongside
+	mockHistory.EXPECT().List(mock.Anything, dto.CalculationFilter{}, 0, 0).Return(&dto.CalculationPage{}, nil).Once()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/calculations", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCalculateBatch_RecordsHistory(t *testing.T) {
+	mockHistory := mocks.NewMockCalculationHistoryService(t)
+	var recorded []*model.Calculation
+	mockHistory.EXPECT().Record(mock.Anything).Run(func(calc *model.Calculation) {
+		recorded = append(recorded, calc)
+	}).Times(2)
+
+	router := gin.New()
+	handler := NewHandler(service.NewPackCalculatorService(), nil, WithCalculationHistory(mockHistory))
+	router.POST("/api/calculate/batch", handler.CalculateBatch)
+
+	w := postBatch(router, mixedBatch, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, recorded, 2)
+	for _, calc := range recorded {
+		assert.Equal(t, model.CalculationSourceBatch, calc.Source)
+	}
+	assert.Equal(t, 251, recorded[0].ItemsOrdered)
+	assert.Equal(t, 12250, recorded[1].TotalItems)
+}
+
+func TestCalculatePacks_Metadata(t *testing.T) {
+	metadata := map[string]string{"order_id": "A-1001", "channel": "web"}
+
+	mockHistory := mocks.NewMockCalculationHistoryService(t)
+	mockHistory.EXPECT().Record(mock.MatchedBy(func(calc *model.Calculation) bool {
+		return assert.ObjectsAreEqual(metadata, calc.Metadata)
+	})).Once()
+
+	audited := make(chan map[string]interface{}, 1)
+	mockLogging := mocks.NewMockLoggingService(t)
+	mockLogging.On("CreateLog", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		audited <- args.Get(1).(*model.LogEntry).Fields
+	}).Return(nil).Once()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("logging_service", mockLogging)
+		c.Next()
+	})
+	handler := NewHandler(service.NewPackCalculatorService(), nil, WithCalculationHistory(mockHistory))
+	router.POST("/api/calculate", handler.CalculatePacks)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251, "metadata": {"order_id": "A-1001", "channel": "web"}}`))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data model.PackResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, metadata, resp.Data.Metadata)
+
+	select {
+	case fields := <-audited:
+		assert.Equal(t, metadata, fields["metadata"])
+	case <-time.After(time.Second):
+		t.Fatal("calculation was not audited")
+	}
+
+	// Oversized metadata is rejected before calculating
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251, "metadata": {"order.id": "A-1001"}}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "metadata")
+}
+
+func TestCalculatePacks_MaxCompute(t *testing.T) {
+	approximate := model.PackResult{OrderedItems: 500000, TotalItems: 500003, Packs: []model.Pack{{Size: 53, Quantity: 9434}}, Approximate: true}
+
+	mockCalc := mocks.NewMockPackCalculator(t)
+	mockCalc.EXPECT().CalculateWithin(500000, []int{23, 31, 53}, model.PackConstraints{}, 50*time.Millisecond).
+		Return(approximate, nil).Once()
+	// Budgets above the server limit are capped
+	mockCalc.EXPECT().CalculateWithin(251, []int(nil), model.PackConstraints{}, 100*time.Millisecond).
+		Return(model.PackResult{OrderedItems: 251, TotalItems: 500, Packs: []model.Pack{{Size: 500, Quantity: 1}}}, nil).Once()
+
+	mockHistory := mocks.NewMockCalculationHistoryService(t)
+	mockHistory.EXPECT().Record(mock.MatchedBy(func(calc *model.Calculation) bool {
+		return calc.ItemsOrdered == 500000 && calc.Approximate
+	})).Once()
+	mockHistory.EXPECT().Record(mock.MatchedBy(func(calc *model.Calculation) bool {
+		return calc.ItemsOrdered == 251 && !calc.Approximate
+	})).Once()
+
+	router := gin.New()
+	handler := NewHandler(mockCalc, nil, WithCalculationHistory(mockHistory), WithMaxCompute(100*time.Millisecond))
+	router.POST("/api/calculate", handler.CalculatePacks)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 500000, "pack_sizes": [23, 31, 53], "max_compute_ms": 50}`))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, true, resp.Data["approximate"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251, "max_compute_ms": 5000}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "approximate")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251, "max_compute_ms": 0}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "max_compute_ms")
+}
+
+func TestCalculatePacks_GreedyComparison(t *testing.T) {
+	router := gin.New()
+	router.POST("/api/calculate", NewHandler(service.NewPackCalculatorService(), nil).CalculatePacks)
+	differs := promtestutil.ToFloat64(metrics.GreedyComparisonsTotal.WithLabelValues("differs"))
+	match := promtestutil.ToFloat64(metrics.GreedyComparisonsTotal.WithLabelValues("match"))
+
+	// Greedy would ship two 250 packs
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"greedy_differs":true`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 12001}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "greedy_differs")
+
+	assert.Equal(t, differs+1, promtestutil.ToFloat64(metrics.GreedyComparisonsTotal.WithLabelValues("differs")))
+	assert.Equal(t, match+1, promtestutil.ToFloat64(metrics.GreedyComparisonsTotal.WithLabelValues("match")))
+}
+
+func TestCalculatePacks_Explain(t *testing.T) {
+	router := gin.New()
+	router.POST("/api/calculate", NewHandler(service.NewPackCalculatorService(), nil).CalculatePacks)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251, "explain": true}`))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data model.PackResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Data.Explanation)
+	assert.Contains(t, resp.Data.Explanation.Summary, "500 items is the smallest total")
+	require.NotEmpty(t, resp.Data.Explanation.Alternatives)
+	assert.Equal(t, model.Alternative{
+		Kind: model.AlternativeSplitPack, TotalItems: 500, OverageItems: 249, PackCount: 2,
+		Packs: []model.Pack{{Size: 250, Quantity: 2}}, Reason: model.ReasonMorePacks,
+	}, resp.Data.Explanation.Alternatives[0])
+
+	// Without the flag the response is unchanged
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, postCalculate(`{"items_ordered": 251}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "explanation")
+}
+
+func postCalculate(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
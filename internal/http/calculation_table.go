@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/spreadsheet"
+	"github.com/rs/zerolog/log"
+)
+
+// MIMEXLSX is the content type of Excel workbooks.
+const MIMEXLSX = spreadsheet.MIMEXLSX
+
+// calculationTableFlushRows is how many streamed batch rows are written
+// between flushes.
+const calculationTableFlushRows = 100
+
+// tableFormat returns the spreadsheet format the request accepts, or "" when
+// it prefers JSON or NDJSON, which stay the default.
+func tableFormat(c *gin.Context, formats ...string) string {
+	switch format := c.NegotiateFormat(formats...); format {
+	case MIMECSV, MIMEXLSX:
+		return format
+	default:
+		return ""
+	}
+}
+
+// startTable writes the headers of a spreadsheet download named name and
+// returns the writer of its rows, the column names already written.
+func startTable(c *gin.Context, format, name string) (spreadsheet.Writer, error) {
+	extension := "csv"
+	if format == MIMEXLSX {
+		extension = "xlsx"
+	}
+
+	w := c.Writer
+	w.Header().Set("Content-Type", format)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.`+extension+`"`)
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	table, err := spreadsheet.New(w, format, "Results")
+	if err != nil {
+		return nil, err
+	}
+	return table, table.Write(dto.BatchItemColumns)
+}
+
+// writeCalculationTable responds with the result of a single calculation as
+// a one-row spreadsheet.
+func writeCalculationTable(c *gin.Context, format string, result model.PackResult) {
+	table, err := startTable(c, format, "calculation")
+	if err == nil {
+		err = table.Write(dto.BatchItemResult{Result: &result}.Row(result.OrderedItems))
+	}
+	if err == nil {
+		err = table.Close()
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to write calculation spreadsheet")
+	}
+}
+
+// table writes one row per item as each completes, in request order, and
+// flushes regularly so clients can start reading before the batch ends. It
+// stops early when the client goes away.
+func (b *batchCalculator) table(c *gin.Context, format string, items []dto.CalculatePacksRequest) (succeeded, failed int) {
+	table, err := startTable(c, format, "calculations")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to start batch spreadsheet")
+		return 0, 0
+	}
+
+	rc := http.NewResponseController(c.Writer)
+	rows := 0
+	b.run(items, func(result dto.BatchItemResult) bool {
+		if b.ctx.Err() != nil {
+			return false
+		}
+		if result.Error != nil {
+			failed++
+		} else {
+			succeeded++
+		}
+
+		if err = table.Write(result.Row(items[result.Index].ItemsOrdered)); err != nil {
+			return false
+		}
+		rows++
+		if rows%calculationTableFlushRows == 0 {
+			if err = table.Flush(); err != nil {
+				return false
+			}
+			// Best effort: wrappers that do not expose the connection keep the server deadline
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			c.Writer.Flush()
+		}
+		return true
+	})
+	if err == nil {
+		err = table.Close()
+	}
+	if err != nil {
+		log.Warn().Err(err).Int("rows", rows).Msg("Batch spreadsheet stopped early")
+	}
+	return succeeded, failed
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+)
+
+func TestCalculatePacks_CSV(t *testing.T) {
+	router := setupRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader(`{"items_ordered": 751}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", MIMECSV)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMECSV, w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="calculation.csv"`, w.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"index", "items_ordered", "total_items", "overage_items", "pack_count", "packs", "approximate", "error_code", "error_message"},
+		{"0", "751", "1000", "249", "1", "1x1000", "false", "", ""},
+	}, records)
+}
+
+func TestCalculatePacks_SpreadsheetErrorsStayJSON(t *testing.T) {
+	router := setupRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader(`{"items_ordered": 0}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", MIMECSV)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
+
+func TestCalculateBatch_CSV(t *testing.T) {
+	router := setupRouter()
+
+	w := postBatch(router, mixedBatch, MIMECSV)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMECSV, w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="calculations.csv"`, w.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5, "a header and one row per item")
+	assert.Equal(t, []string{"0", "251", "500", "249", "1", "1x500", "false", "", ""}, records[1])
+	assert.Equal(t, "1", records[2][0])
+	assert.Equal(t, dto.ErrCodeInvalidRequest, records[2][7])
+	assert.NotEmpty(t, records[2][8])
+	assert.Equal(t, []string{"2", "12001", "12250", "249", "25", "24x500, 1x250", "false", "", ""}, records[3])
+	assert.Equal(t, "preset: cannot be combined with pack_sizes", records[4][8])
+}
+
+func TestCalculateBatch_XLSX(t *testing.T) {
+	router := setupRouter()
+
+	w := postBatch(router, mixedBatch, MIMEXLSX)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMEXLSX, w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="calculations.xlsx"`, w.Header().Get("Content-Disposition"))
+
+	z, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range z.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "xl/worksheets/sheet1.xml")
+}
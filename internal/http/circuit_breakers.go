@@ -0,0 +1,106 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// CircuitBreakerHandler lists and resets the registered circuit breakers.
+type CircuitBreakerHandler struct {
+	registry *circuitbreaker.Registry
+}
+
+// NewCircuitBreakerHandler creates a new CircuitBreakerHandler instance.
+func NewCircuitBreakerHandler(registry *circuitbreaker.Registry) *CircuitBreakerHandler {
+	return &CircuitBreakerHandler{registry: registry}
+}
+
+// ListBreakers handles GET /api/admin/circuit-breakers requests.
+//
+// @Summary      Circuit breakers
+// @Description  Lists every registered circuit breaker, sorted by name, with its state, consecutive failure and success counts, last failure and last state change.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=[]dto.CircuitBreakerStatus} "Circuit breakers"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Security     BearerAuth
+// @Router       /api/admin/circuit-breakers [get]
+func (h *CircuitBreakerHandler) ListBreakers(c *gin.Context) {
+	names := h.registry.Names()
+	breakers := make([]dto.CircuitBreakerStatus, 0, len(names))
+	for _, name := range names {
+		if cb, ok := h.registry.Get(name); ok {
+			breakers = append(breakers, breakerStatus(name, cb))
+		}
+	}
+	NewResponseBuilder(c).SuccessOK(breakers)
+}
+
+// ResetBreaker handles POST /api/admin/circuit-breakers/:name/reset requests.
+//
+// @Summary      Reset a circuit breaker
+// @Description  Closes the circuit breaker and clears its counts without waiting for its timeout. Use it once the dependency is known to have recovered; if it has not, the breaker opens again after its failure threshold.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        name path string true "Circuit breaker name" example(mongodb_pack_sizes)
+// @Success      200 {object} dto.SuccessResponse{data=dto.CircuitBreakerStatus} "Circuit breaker after the reset"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      404 {object} dto.ErrorResponse "No circuit breaker with this name"
+// @Security     BearerAuth
+// @Router       /api/admin/circuit-breakers/{name}/reset [post]
+func (h *CircuitBreakerHandler) ResetBreaker(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+	name := c.Param("name")
+	cb, ok := h.registry.Get(name)
+	if !ok {
+		builder.Error(http.StatusNotFound, i18n.ErrKeyNotFound, fmt.Errorf("circuit breaker %q not found", name))
+		return
+	}
+
+	previous := cb.State()
+	cb.Reset()
+
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, "reset_circuit_breaker", "Circuit breaker reset", map[string]interface{}{
+				"circuit_breaker": name,
+				"previous_state":  previous.String(),
+			})
+		}
+	}
+	builder.SuccessOK(breakerStatus(name, cb))
+}
+
+// breakerStatus describes cb, registered under name.
+func breakerStatus(name string, cb *circuitbreaker.CircuitBreaker) dto.CircuitBreakerStatus {
+	stats := cb.GetStats()
+	return dto.CircuitBreakerStatus{
+		Name:           name,
+		State:          stats.State,
+		FailureCount:   stats.FailureCount,
+		SuccessCount:   stats.SuccessCount,
+		LastFailure:    optionalTime(stats.LastFailure),
+		LastTransition: optionalTime(stats.LastTransition),
+	}
+}
+
+// optionalTime returns t in UTC, or nil when it is zero.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
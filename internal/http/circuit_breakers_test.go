@@ -0,0 +1,67 @@
+//go:build !integration
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+)
+
+func TestCircuitBreakerHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logs := circuitbreaker.New(circuitbreaker.Config{FailureThreshold: 1, SuccessThreshold: 1, Timeout: time.Hour, Name: "mongodb-logs"})
+	_ = logs.Execute(context.Background(), func() error { return errors.New("connection refused") })
+
+	registry := circuitbreaker.NewRegistry()
+	registry.Register("handler_test_pack_sizes", circuitbreaker.New(circuitbreaker.DefaultConfig()))
+	registry.Register("handler_test_logs", logs)
+
+	handler := NewCircuitBreakerHandler(registry)
+	router := gin.New()
+	router.GET("/admin/circuit-breakers", handler.ListBreakers)
+	router.POST("/admin/circuit-breakers/:name/reset", handler.ResetBreaker)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/circuit-breakers", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []dto.CircuitBreakerStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, "handler_test_logs", list.Data[0].Name)
+	assert.Equal(t, "open", list.Data[0].State)
+	assert.Equal(t, 1, list.Data[0].FailureCount)
+	assert.NotNil(t, list.Data[0].LastFailure)
+	assert.NotNil(t, list.Data[0].LastTransition)
+	assert.Equal(t, "closed", list.Data[1].State)
+	assert.Nil(t, list.Data[1].LastTransition)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/circuit-breakers/handler_test_logs/reset", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var reset struct {
+		Data dto.CircuitBreakerStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reset))
+	assert.Equal(t, "closed", reset.Data.State)
+	assert.Zero(t, reset.Data.FailureCount)
+	assert.False(t, logs.IsOpen())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/circuit-breakers/missing/reset", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// ClientUsageHandler serves client SDK version and user agent statistics.
+type ClientUsageHandler struct {
+	clientUsage service.ClientUsageService
+	location    *time.Location
+}
+
+// NewClientUsageHandler creates a new ClientUsageHandler instance. First and
+// last use are shown in location, or in UTC when it is nil.
+func NewClientUsageHandler(clientUsage service.ClientUsageService, location *time.Location) *ClientUsageHandler {
+	return &ClientUsageHandler{clientUsage: clientUsage, location: location}
+}
+
+// ListClients handles GET /api/admin/clients requests.
+//
+// @Summary      Client versions and user agents
+// @Description  Lists the user agents and client versions seen per API key fingerprint or user, with request counts and first/last use, most recently seen first, with timestamps in the configured display timezone (UTC by default). Use it to find integrators on outdated clients before a breaking change.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        client query string false "Only this client, e.g. api_key:0a1b2c3d or user:<id>"
+// @Success      200 {object} dto.SuccessResponse "Client usage"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/clients [get]
+func (h *ClientUsageHandler) ListClients(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	usages, err := h.clientUsage.List(c.Request.Context(), c.Query("client"))
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	for _, usage := range usages {
+		usage.FirstSeen = timeutil.In(usage.FirstSeen, h.location)
+		usage.LastSeen = timeutil.In(usage.LastSeen, h.location)
+	}
+
+	builder.SuccessOK(usages)
+}
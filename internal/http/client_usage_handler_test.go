@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientUsageHandler_ListClients(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockClientUsageService)
+		expectedStatus int
+	}{
+		{
+			name:  "lists all clients",
+			query: "",
+			setupMock: func(m *mocks.MockClientUsageService) {
+				m.EXPECT().List(mock.Anything, "").Return([]*model.ClientUsage{
+					{Client: "api_key:0a1b2c3d", ClientName: "pack-sdk-go", ClientVersion: "1.4.2", RequestCount: 7},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "filters by client",
+			query: "?client=api_key:0a1b2c3d",
+			setupMock: func(m *mocks.MockClientUsageService) {
+				m.EXPECT().List(mock.Anything, "api_key:0a1b2c3d").Return([]*model.ClientUsage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "repository error",
+			query: "",
+			setupMock: func(m *mocks.MockClientUsageService) {
+				m.EXPECT().List(mock.Anything, "").Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsage := mocks.NewMockClientUsageService(t)
+			tt.setupMock(mockUsage)
+
+			router := gin.New()
+			router.GET("/api/admin/clients", NewClientUsageHandler(mockUsage, nil).ListClients)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/clients"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRouter_ClientUsageWithAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsage := mocks.NewMockClientUsageService(t)
+	fingerprint := middleware.APIKeyFingerprint("key-1")
+	mockUsage.EXPECT().Record("api_key:"+fingerprint, "pack-sdk-go/1.4.2", "").Once()
+	mockUsage.EXPECT().List(mock.Anything, "").Return([]*model.ClientUsage{
+		{Client: "api_key:" + fingerprint, ClientName: "pack-sdk-go", ClientVersion: "1.4.2", RequestCount: 1},
+	}, nil).Once()
+
+	cfg := DefaultRouterConfig()
+	cfg.EnableAuth = true
+	cfg.APIKeys = map[string]bool{"key-1": true}
+	cfg.ClientUsage = mockUsage
+	router := NewRouter(NewHandler(nil, nil), NewHealthHandler(), cfg)
+
+	// Rejected requests are not attributed to a client
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/clients", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/clients", nil)
+	req.Header.Set(middleware.APIKeyHeader, "key-1")
+	req.Header.Set("User-Agent", "pack-sdk-go/1.4.2")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data []model.ClientUsage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "1.4.2", body.Data[0].ClientVersion)
+}
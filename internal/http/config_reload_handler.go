@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// ConfigReloadHandler lets admins reload the configuration without
+// restarting the instance.
+type ConfigReloadHandler struct {
+	reloader *service.ConfigReloader
+}
+
+// NewConfigReloadHandler creates a new ConfigReloadHandler instance.
+func NewConfigReloadHandler(reloader *service.ConfigReloader) *ConfigReloadHandler {
+	return &ConfigReloadHandler{reloader: reloader}
+}
+
+// Reload handles POST /api/admin/config/reload requests.
+//
+// @Summary      Reload configuration
+// @Description  Reads the environment and CONFIG_FILE again, as SIGHUP does, and applies the settings that changed among the reloadable ones: RATE_LIMIT, RATE_WINDOW, API_KEYS, CACHE_SIZE, CACHE_TTL and LOG_LEVEL. Other settings apply after a restart. Only the instance that receives the request reloads.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=dto.ConfigReloadResult} "Configuration reloaded"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      422 {object} dto.ErrorResponse "The config file cannot be read or has an invalid line; nothing was applied"
+// @Security     BearerAuth
+// @Router       /api/admin/config/reload [post]
+func (h *ConfigReloadHandler) Reload(c *gin.Context) {
+	result, err := h.reloader.Reload()
+	if err != nil {
+		NewResponseBuilder(c).ErrorWithMessage(http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	}
+
+	log.Info().Interface("changes", result.Changes).Msg("Configuration reloaded")
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, model.ActionReloadConfig, "Configuration reloaded", map[string]interface{}{
+				"trigger": "api",
+				"changes": result.Changes,
+			})
+		}
+	}
+	NewResponseBuilder(c).SuccessOK(result)
+}
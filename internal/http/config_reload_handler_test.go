@@ -0,0 +1,61 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestConfigReloadHandler_Reload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var current config.Config
+	current.Server.RateLimit = 100
+	next := current
+	next.Server.RateLimit = 150
+	var loadErr error
+	applied := 0
+	reloader := service.NewConfigReloader(current, func() (config.Config, error) {
+		return next, loadErr
+	}, []service.ReloadableSetting{{
+		Name:  "RATE_LIMIT",
+		Value: func(cfg config.Config) string { return strconv.Itoa(cfg.Server.RateLimit) },
+		Apply: func(config.Config) { applied++ },
+	}}, nil)
+
+	router := gin.New()
+	router.POST("/api/admin/config/reload", NewConfigReloadHandler(reloader).Reload)
+	reload := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/config/reload", nil))
+		return w
+	}
+
+	w := reload()
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data dto.ConfigReloadResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []dto.ConfigChange{{Setting: "RATE_LIMIT", Old: "100", New: "150"}}, resp.Data.Changes)
+	assert.Equal(t, 1, applied)
+
+	loadErr = errors.New("config file /etc/pack-service.env line 3: expected KEY=VALUE")
+	w = reload()
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "line 3")
+	assert.Equal(t, 1, applied)
+}
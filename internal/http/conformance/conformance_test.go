@@ -0,0 +1,177 @@
+//go:build !integration
+
+package conformance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+)
+
+// scenario is a request every chain must answer the same way.
+type scenario struct {
+	name   string
+	method string
+	path   string
+	body   string
+	locale string
+	// unauthenticated sends the request without credentials; the scenario
+	// only runs against chains that require them.
+	unauthenticated bool
+
+	wantStatus int
+	// wantMessageKey is the message key of an error response; missing auth
+	// uses the chain's own key.
+	wantMessageKey string
+	// wantAudit is the action type of the audit record the request must write.
+	wantAudit string
+}
+
+func scenarios() []scenario {
+	return []scenario{
+		{
+			name:       "success",
+			method:     http.MethodPost,
+			path:       "/api/calculate",
+			body:       `{"items_ordered": 251}`,
+			wantStatus: http.StatusOK,
+			wantAudit:  model.ActionCalculate,
+		},
+		{
+			name:       "probe",
+			method:     http.MethodGet,
+			path:       "/api/probe/ok",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:           "panic",
+			method:         http.MethodGet,
+			path:           "/api/probe/panic",
+			wantStatus:     http.StatusInternalServerError,
+			wantMessageKey: i18n.ErrKeyInternalError,
+		},
+		{
+			name:           "panic in portuguese",
+			method:         http.MethodGet,
+			path:           "/api/probe/panic",
+			locale:         "pt-BR",
+			wantStatus:     http.StatusInternalServerError,
+			wantMessageKey: i18n.ErrKeyInternalError,
+		},
+		{
+			name:           "timeout",
+			method:         http.MethodGet,
+			path:           "/api/probe/slow",
+			locale:         "nl",
+			wantStatus:     http.StatusGatewayTimeout,
+			wantMessageKey: i18n.ErrKeyTimeout,
+		},
+		{
+			name:           "oversized body",
+			method:         http.MethodPost,
+			path:           "/api/calculate",
+			body:           `{"items_ordered": 251, "metadata": {"note": "` + strings.Repeat("x", 1<<20) + `"}}`,
+			wantStatus:     http.StatusBadRequest,
+			wantMessageKey: i18n.ErrKeyValidationMetadata,
+		},
+		{
+			name:           "malformed body in dutch",
+			method:         http.MethodPost,
+			path:           "/api/calculate",
+			body:           `{"items_ordered": "many"}`,
+			locale:         "nl",
+			wantStatus:     http.StatusBadRequest,
+			wantMessageKey: i18n.ErrKeyInvalidRequestBody,
+		},
+		{
+			name:            "missing auth",
+			method:          http.MethodGet,
+			path:            "/api/probe/ok",
+			locale:          "pt",
+			unauthenticated: true,
+			wantStatus:      http.StatusUnauthorized,
+		},
+	}
+}
+
+// TestMiddlewareConformance runs every scenario against every registered
+// chain. Besides the status and message, it checks what must hold whatever
+// middleware is added: the request ID is assigned before anything that logs
+// or responds, so the header, error body, request log and audit record all
+// carry the same one, and authenticated users reach the records too.
+func TestMiddlewareConformance(t *testing.T) {
+	for _, ch := range chains() {
+		for _, sc := range scenarios() {
+			if sc.unauthenticated && ch.authorize == nil {
+				continue
+			}
+
+			t.Run(ch.name+"/"+sc.name, func(t *testing.T) {
+				logs := &recordingLogger{}
+				cfg := ch.config(t)
+				cfg.LoggingService = logs
+				engine := newProbeEngine(cfg)
+
+				requestID := "conformance-" + ch.name + "-" + strings.ReplaceAll(sc.name, " ", "-")
+				req := httptest.NewRequest(sc.method, sc.path, strings.NewReader(sc.body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set(middleware.RequestIDHeader, requestID)
+				if sc.locale != "" {
+					req.Header.Set(i18n.AcceptLanguageHeader, sc.locale)
+				}
+				for name, value := range ch.headers {
+					req.Header.Set(name, value)
+				}
+				if ch.authorize != nil && !sc.unauthenticated {
+					ch.authorize(req)
+				}
+
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+
+				require.Equal(t, sc.wantStatus, w.Code, w.Body.String())
+				assert.Equal(t, requestID, w.Header().Get(middleware.RequestIDHeader))
+
+				if sc.wantStatus >= http.StatusBadRequest {
+					messageKey := sc.wantMessageKey
+					if sc.unauthenticated {
+						messageKey = ch.missingAuthKey
+					}
+
+					var resp dto.ErrorResponse
+					require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+					assert.Equal(t, dto.ErrCodeFromStatus(sc.wantStatus), resp.Error)
+					assert.Equal(t, i18n.GetTranslator().Translate(messageKey, localeOf(req)), resp.Message)
+					assert.Equal(t, requestID, resp.RequestID)
+				}
+
+				authenticated := ch.name == "jwt" && !sc.unauthenticated
+				entry, ok := logs.find(requestID, func(e model.LogEntry) bool { return e.Message == "HTTP request" })
+				require.True(t, ok, "request log record missing")
+				assert.Equal(t, sc.wantStatus, entry.StatusCode)
+				assert.Equal(t, sc.path, entry.Path)
+				if authenticated {
+					assert.Equal(t, testUserID.Hex(), entry.UserID)
+				}
+
+				if sc.wantAudit != "" {
+					audit, ok := logs.find(requestID, func(e model.LogEntry) bool { return e.ActionType == sc.wantAudit })
+					require.True(t, ok, "audit record missing")
+					if authenticated {
+						assert.Equal(t, testUserID.Hex(), audit.UserID)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package conformance holds the middleware conformance suite. It mounts probe
+// routes behind every middleware chain the router registers and runs the same
+// scenarios against each one, so adding or reordering a middleware cannot
+// silently change status codes, headers or audit records.
+package conformance
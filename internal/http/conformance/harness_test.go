@@ -0,0 +1,201 @@
+//go:build !integration
+
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	httpapi "github.com/guttosm/pack-service/internal/http"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+const (
+	testAPIKey = "conformance-key"
+	testToken  = "conformance-token"
+
+	// probeTimeout is the deadline of the slow probe, which sleeps for slowProbeDelay.
+	probeTimeout   = 20 * time.Millisecond
+	slowProbeDelay = 200 * time.Millisecond
+)
+
+var testUserID = primitive.NewObjectID()
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// chain is a middleware chain registered by the router, with what its
+// requests need to get through it.
+type chain struct {
+	name string
+	// config returns the router configuration that registers the chain.
+	config func(t *testing.T) httpapi.RouterConfig
+	// headers are sent with every request, e.g. to engage the chain's middleware.
+	headers map[string]string
+	// authorize adds credentials to a request; nil for chains without auth.
+	authorize func(*http.Request)
+	// missingAuthKey is the message key of the 401 returned without credentials.
+	missingAuthKey string
+}
+
+// chains returns every middleware chain the router can register.
+func chains() []chain {
+	return []chain{
+		{
+			name: "public",
+			config: func(*testing.T) httpapi.RouterConfig {
+				return httpapi.DefaultRouterConfig()
+			},
+		},
+		{
+			name: "idempotency",
+			config: func(*testing.T) httpapi.RouterConfig {
+				cfg := httpapi.DefaultRouterConfig()
+				cfg.EnableIdempotency = true
+				return cfg
+			},
+			headers: map[string]string{middleware.IdempotencyKeyHeader: "conformance"},
+		},
+		{
+			name: "api_key",
+			config: func(*testing.T) httpapi.RouterConfig {
+				cfg := httpapi.DefaultRouterConfig()
+				cfg.EnableAuth = true
+				cfg.APIKeys = map[string]bool{testAPIKey: true}
+				return cfg
+			},
+			authorize: func(req *http.Request) {
+				req.Header.Set(middleware.APIKeyHeader, testAPIKey)
+			},
+			missingAuthKey: i18n.ErrKeyAPIKeyRequired,
+		},
+		{
+			name: "jwt",
+			config: func(t *testing.T) httpapi.RouterConfig {
+				auth := mocks.NewMockAuthService(t)
+				auth.EXPECT().ValidateToken(mock.Anything, testToken).
+					Return(&dto.Claims{UserID: testUserID, Email: "jane@example.com", Roles: []string{"user"}}, nil).
+					Maybe()
+
+				cfg := httpapi.DefaultRouterConfig()
+				cfg.EnableAuth = true
+				cfg.AuthService = auth
+				return cfg
+			},
+			authorize: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+testToken)
+			},
+			missingAuthKey: i18n.ErrKeyTokenRequired,
+		},
+	}
+}
+
+// newProbeEngine mounts the probe routes behind the middleware chain the
+// router registers for cfg: global, then the /api group, then JWT auth.
+func newProbeEngine(cfg httpapi.RouterConfig) *gin.Engine {
+	mw := httpapi.NewMiddlewareChain(cfg)
+
+	engine := gin.New()
+	engine.Use(mw.Global...)
+	api := engine.Group("/api", mw.API...)
+	if len(mw.Protected) > 0 {
+		api = api.Group("", mw.Protected...)
+	}
+
+	handler := httpapi.NewHandler(service.NewPackCalculatorService(), nil)
+	api.POST("/calculate", handler.CalculatePacks)
+	api.GET("/probe/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	api.GET("/probe/panic", func(*gin.Context) {
+		panic("conformance probe")
+	})
+	api.GET("/probe/slow", middleware.TimeoutWithDuration(probeTimeout), func(*gin.Context) {
+		time.Sleep(slowProbeDelay)
+	})
+	return engine
+}
+
+// localeOf returns the locale the middleware resolves for req.
+func localeOf(req *http.Request) string {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+	return i18n.GetLocale(c)
+}
+
+// recordingLogger is a LoggingService that keeps every entry written to it.
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []model.LogEntry
+}
+
+func (l *recordingLogger) CreateLog(_ context.Context, entry *model.LogEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, *entry)
+	return nil
+}
+
+func (l *recordingLogger) CreateLogs(ctx context.Context, entries []*model.LogEntry) error {
+	for _, entry := range entries {
+		_ = l.CreateLog(ctx, entry)
+	}
+	return nil
+}
+
+func (l *recordingLogger) QueryLogs(context.Context, model.LogQueryOptions) ([]model.LogEntry, error) {
+	return nil, nil
+}
+
+func (l *recordingLogger) CountLogs(context.Context, model.LogQueryOptions) (int64, error) {
+	return 0, nil
+}
+
+func (l *recordingLogger) ExportLogs(context.Context, model.LogQueryOptions, func(model.LogEntry) error) error {
+	return nil
+}
+
+func (l *recordingLogger) CalculationsSinceConfigVersion(context.Context, int) ([]model.ConfigVersionImpact, error) {
+	return nil, nil
+}
+
+func (l *recordingLogger) PurgeLogs(context.Context, model.LogRetention) (int64, error) {
+	return 0, nil
+}
+
+// find returns the first entry for requestID matching match. Entries are
+// written asynchronously, so it waits briefly for one to arrive.
+func (l *recordingLogger) find(requestID string, match func(model.LogEntry) bool) (model.LogEntry, bool) {
+	deadline := time.Now().Add(time.Second)
+	for {
+		l.mu.Lock()
+		for _, entry := range l.entries {
+			if entry.RequestID == requestID && match(entry) {
+				l.mu.Unlock()
+				return entry, true
+			}
+		}
+		l.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			return model.LogEntry{}, false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+var _ service.LoggingService = (*recordingLogger)(nil)
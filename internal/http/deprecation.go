@@ -0,0 +1,94 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/deprecation"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/middleware"
+)
+
+// deprecationClient identifies the caller in deprecation reports. The second
+// value is the metric label: users share one label to bound cardinality,
+// while API keys are few enough to be labelled individually, hashed or
+// bucketed as configured.
+func deprecationClient(c *gin.Context) (client, label string) {
+	if keyID := c.GetString(middleware.APIKeyIDContextKey); keyID != "" {
+		return "api_key:" + keyID, "api_key:" + metrics.ClientLabel(keyID)
+	}
+	if userID := userIDFromContext(c); userID != "" {
+		return "user:" + userID, "user"
+	}
+	return "ip:" + c.ClientIP(), "anonymous"
+}
+
+// deprecationEndpoint names the route for reports, e.g. "PUT /api/pack-sizes".
+func deprecationEndpoint(c *gin.Context) string {
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	return c.Request.Method + " " + path
+}
+
+// deprecationWarnings returns a warning for every deprecated field set in the
+// request or response, counts it in metrics and records it in tracker when
+// one is configured. Either value may be nil.
+func deprecationWarnings(c *gin.Context, tracker *deprecation.Tracker, request, response any) []dto.Warning {
+	sent := deprecation.Used(request)
+	returned := deprecation.Used(response)
+	if len(sent) == 0 && len(returned) == 0 {
+		return nil
+	}
+
+	client, label := deprecationClient(c)
+	endpoint := deprecationEndpoint(c)
+
+	warnings := make([]dto.Warning, 0, len(sent)+len(returned))
+	for _, usage := range []struct {
+		direction deprecation.Direction
+		fields    []deprecation.Field
+	}{{deprecation.Sent, sent}, {deprecation.Returned, returned}} {
+		if len(usage.fields) == 0 {
+			continue
+		}
+		if tracker != nil {
+			tracker.Record(client, endpoint, usage.direction, usage.fields)
+		}
+		for _, f := range usage.fields {
+			metrics.RecordDeprecatedFieldUsage(endpoint, f.Name, string(usage.direction), label)
+			warnings = append(warnings, dto.Warning{
+				Code:    deprecation.WarningCode,
+				Field:   f.Name,
+				Message: f.Message(usage.direction),
+			})
+		}
+	}
+	return warnings
+}
+
+// DeprecationHandler serves the deprecated field usage report.
+type DeprecationHandler struct {
+	tracker *deprecation.Tracker
+}
+
+// NewDeprecationHandler creates a new DeprecationHandler instance.
+func NewDeprecationHandler(tracker *deprecation.Tracker) *DeprecationHandler {
+	return &DeprecationHandler{tracker: tracker}
+}
+
+// GetReport handles GET /api/admin/deprecations requests.
+//
+// @Summary      Deprecated field usage
+// @Description  Lists deprecated API fields and which clients (API key fingerprint, user or IP) still send or receive them, with counts and first/last use. Counts are per instance since its start.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse "Deprecation report"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Security     BearerAuth
+// @Router       /api/admin/deprecations [get]
+func (h *DeprecationHandler) GetReport(c *gin.Context) {
+	NewResponseBuilder(c).SuccessOK(h.tracker.Report())
+}
@@ -0,0 +1,110 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/deprecation"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/middleware"
+)
+
+type deprecatedResponse struct {
+	Total int `json:"total" deprecated:"sunset=2027-01-01;use=total_items"`
+}
+
+func TestDeprecationClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name       string
+		setup      func(*gin.Context)
+		wantClient string
+		wantLabel  string
+	}{
+		{
+			name:       "api key",
+			setup:      func(c *gin.Context) { c.Set(middleware.APIKeyIDContextKey, "ab12cd34") },
+			wantClient: "api_key:ab12cd34",
+			wantLabel:  "api_key:ab12cd34",
+		},
+		{
+			name:       "user",
+			setup:      func(c *gin.Context) { c.Set("user_id", userID) },
+			wantClient: "user:" + userID.Hex(),
+			wantLabel:  "user",
+		},
+		{
+			name:       "anonymous",
+			setup:      func(c *gin.Context) {},
+			wantClient: "ip:192.0.2.1",
+			wantLabel:  "anonymous",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			tt.setup(c)
+
+			client, label := deprecationClient(c)
+			assert.Equal(t, tt.wantClient, client)
+			assert.Equal(t, tt.wantLabel, label)
+		})
+	}
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPut, "/api/pack-sizes", nil)
+	c.Set(middleware.APIKeyIDContextKey, "ab12cd34")
+	tracker := deprecation.NewTracker()
+
+	warnings := deprecationWarnings(c, tracker, &dto.UpdatePackSizesRequest{CreatedBy: "ops"}, deprecatedResponse{Total: 1})
+
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "created_by", warnings[0].Field)
+	assert.Equal(t, "total", warnings[1].Field)
+	assert.Contains(t, warnings[1].Message, "response field total")
+
+	usage := tracker.Report().Usage
+	require.Len(t, usage, 2)
+	assert.Equal(t, "api_key:ab12cd34", usage[0].Client)
+	assert.Equal(t, "PUT /api/pack-sizes", usage[0].Endpoint)
+
+	assert.Nil(t, deprecationWarnings(c, tracker, &dto.UpdatePackSizesRequest{Sizes: []int{1}}, nil))
+	assert.Nil(t, deprecationWarnings(c, nil, &dto.CalculatePacksRequest{ItemsOrdered: 1}, nil))
+}
+
+func TestDeprecationHandler_GetReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := deprecation.NewTracker()
+	tracker.Declare("PUT /api/pack-sizes", deprecation.Sent, dto.UpdatePackSizesRequest{})
+
+	router := gin.New()
+	router.GET("/admin/deprecations", NewDeprecationHandler(tracker).GetReport)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/deprecations", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data deprecation.Report `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Fields, 1)
+	assert.Equal(t, "created_by", resp.Data.Fields[0].Field.Name)
+	assert.Empty(t, resp.Data.Usage)
+}
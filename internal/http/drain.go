@@ -0,0 +1,65 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// drainPath is the drain endpoint, which keeps answering while draining.
+const drainPath = "/api/admin/drain"
+
+// DrainHandler lets deployment tooling drain the instance before terminating it.
+type DrainHandler struct {
+	drainer *middleware.Drainer
+}
+
+// NewDrainHandler creates a new DrainHandler instance.
+func NewDrainHandler(drainer *middleware.Drainer) *DrainHandler {
+	return &DrainHandler{drainer: drainer}
+}
+
+// Drain handles POST /api/admin/drain requests.
+//
+// @Summary      Drain the instance
+// @Description  Takes this instance out of the load balancer ahead of termination: /readyz returns 503, requests already running finish, and new requests other than health checks are rejected with 503 and "Connection: close". Draining again is a no-op. It applies to the instance that receives the request only.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=dto.DrainStatus} "Instance draining"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Security     BearerAuth
+// @Router       /api/admin/drain [post]
+func (h *DrainHandler) Drain(c *gin.Context) {
+	since := h.drainer.Drain()
+	h.audit(c, "drain_instance", "Instance draining")
+	NewResponseBuilder(c).SuccessOK(dto.DrainStatus{Draining: true, Since: &since})
+}
+
+// Resume handles DELETE /api/admin/drain requests.
+//
+// @Summary      Stop draining the instance
+// @Description  Takes requests again, for a deployment that was called off. /readyz recovers on its next check.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=dto.DrainStatus} "Instance taking requests"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Security     BearerAuth
+// @Router       /api/admin/drain [delete]
+func (h *DrainHandler) Resume(c *gin.Context) {
+	h.drainer.Resume()
+	h.audit(c, "resume_instance", "Instance resumed taking requests")
+	NewResponseBuilder(c).SuccessOK(dto.DrainStatus{Draining: false})
+}
+
+func (h *DrainHandler) audit(c *gin.Context, action, message string) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, action, message, nil)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestDrain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	drainer := middleware.NewDrainer()
+	healthHandler := NewHealthHandler()
+	healthHandler.SetDrainer(drainer)
+	cfg := DefaultRouterConfig()
+	cfg.Drainer = drainer
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), healthHandler, cfg)
+
+	// Admin routes need JWT auth; mount the handler directly
+	drainHandler := NewDrainHandler(drainer)
+	router.POST(drainPath, drainHandler.Drain)
+	router.DELETE(drainPath, drainHandler.Resume)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/readyz", "").Code)
+
+	w := do(http.MethodPost, drainPath, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data dto.DrainStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.Draining)
+	require.NotNil(t, resp.Data.Since)
+
+	w = do(http.MethodGet, "/readyz", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var readiness ReadinessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+	assert.Equal(t, ReadinessDraining, readiness.Status)
+	assert.NotNil(t, readiness.DrainingSince)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/healthz", "").Code)
+	w = do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, drainPath, "").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/readyz", "").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`).Code)
+}
@@ -0,0 +1,74 @@
+//go:build !integration
+
+package http
+
+import (
+	"bytes"
+	"cmp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/internal/edgecache"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestCalculatePacks_EdgeCacheHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewHandler(service.NewPackCalculatorService(), nil, WithEdgeCache(edgecache.New(edgecache.Config{MaxAge: time.Minute})))
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if tenant := c.GetHeader("X-Test-Tenant"); tenant != "" {
+			c.Set(i18n.TenantContextKey, tenant)
+		}
+	})
+	router.POST("/api/calculate", handler.CalculatePacks)
+
+	tests := []struct {
+		name          string
+		body          string
+		query         string
+		header        string
+		value         string
+		wantCache     string
+		wantSurrogate string
+	}{
+		{name: "configured sizes", body: `{"items_ordered": 251}`, wantCache: "public, max-age=0, s-maxage=60", wantSurrogate: "calculate pack-sizes"},
+		{name: "custom sizes", body: `{"items_ordered": 251, "pack_sizes": [23, 31, 53]}`, wantCache: "public, max-age=0, s-maxage=60", wantSurrogate: "calculate"},
+		{name: "authenticated", body: `{"items_ordered": 251}`, header: "Authorization", wantCache: "private, no-store"},
+		{name: "api key", body: `{"items_ordered": 251}`, header: "X-API-Key", wantCache: "private, no-store"},
+		{name: "idempotency key", body: `{"items_ordered": 251}`, header: "Idempotency-Key", wantCache: "private, no-store"},
+		{name: "metadata", body: `{"items_ordered": 251, "metadata": {"order_id": "A-1001"}}`, wantCache: "private, no-store"},
+		{name: "spreadsheet", body: `{"items_ordered": 251}`, header: "Accept", value: MIMECSV, wantCache: "private, no-store"},
+		{name: "api key query parameter", body: `{"items_ordered": 251}`, query: "?api_key=value", wantCache: "private, no-store"},
+		{name: "tenant", body: `{"items_ordered": 251}`, header: "X-Test-Tenant", wantCache: "private, no-store"},
+		{name: "input warnings", body: `{"items_ordered": 250000, "pack_sizes": [250, 500]}`, wantCache: "private, no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate"+tt.query, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set(tt.header, cmp.Or(tt.value, "value"))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantCache, w.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.wantSurrogate, w.Header().Get(edgecache.SurrogateKeyHeader))
+			if tt.wantSurrogate != "" {
+				assert.Len(t, w.Header().Get(edgecache.CacheKeyHeader), 64)
+			} else {
+				assert.Empty(t, w.Header().Get(edgecache.CacheKeyHeader))
+			}
+		})
+	}
+}
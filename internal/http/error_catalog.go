@@ -0,0 +1,37 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+// ErrorCatalogHandler serves the error codes of the API.
+type ErrorCatalogHandler struct{}
+
+// NewErrorCatalogHandler creates a new ErrorCatalogHandler instance.
+func NewErrorCatalogHandler() *ErrorCatalogHandler {
+	return &ErrorCatalogHandler{}
+}
+
+// ListErrors handles GET /api/errors requests.
+//
+// @Summary      List error codes
+// @Description  Returns every code the error field of a response can hold, with its HTTP status and message in the language of the request. Codes never change once released, so clients can branch on them. Errors with a detailed message describe the request, so their message here is the English summary.
+// @Tags         Errors
+// @Produce      json
+// @Success      200 {object} dto.SuccessResponse{data=[]dto.ErrorCode} "Error codes, ordered by code"
+// @Router       /api/errors [get]
+func (h *ErrorCatalogHandler) ListErrors(c *gin.Context) {
+	catalogue := domainerr.Catalogue()
+	codes := make([]dto.ErrorCode, len(catalogue))
+	for i, e := range catalogue {
+		message := e.Message
+		if e.MessageKey != "" {
+			message = i18n.Message(c, e.MessageKey)
+		}
+		codes[i] = dto.ErrorCode{Code: e.Code, Status: e.Status, Message: message}
+	}
+	NewResponseBuilder(c).SuccessOK(codes)
+}
@@ -0,0 +1,52 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestErrorCatalogHandler_ListErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), DefaultRouterConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/errors", nil)
+	req.Header.Set("Accept-Language", "pt-BR")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []dto.ErrorCode `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	codes := make(map[string]dto.ErrorCode, len(resp.Data))
+	for _, code := range resp.Data {
+		codes[code.Code] = code
+	}
+	assert.Equal(t, dto.ErrorCode{
+		Code:    "user_not_found",
+		Status:  http.StatusNotFound,
+		Message: i18n.GetTranslator().Translate(i18n.ErrKeyUserNotFound, "pt"),
+	}, codes["user_not_found"], "translated")
+	assert.Equal(t, dto.ErrorCode{
+		Code:    "migration_stale",
+		Status:  http.StatusConflict,
+		Message: service.ErrMigrationStale.Error(),
+	}, codes["migration_stale"], "described by the service")
+	assert.Contains(t, codes, dto.ErrCodeAccountLocked)
+	assert.Contains(t, codes, dto.ErrCodeDatabaseUnavailable)
+	assert.Contains(t, codes, dto.ErrCodeUnprocessable)
+}
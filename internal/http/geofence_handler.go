@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// GeoFenceHandler serves and changes the geofence rules.
+type GeoFenceHandler struct {
+	fence *middleware.GeoFence
+}
+
+// NewGeoFenceHandler creates a new GeoFenceHandler instance.
+func NewGeoFenceHandler(fence *middleware.GeoFence) *GeoFenceHandler {
+	return &GeoFenceHandler{fence: fence}
+}
+
+// GetGeoFence handles GET /api/admin/geofence requests.
+//
+// @Summary      Get geofence rules
+// @Description  Returns the CIDR ranges and countries requests may come from. With both lists empty, requests are allowed from anywhere.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=middleware.GeoFenceRules} "Geofence rules"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Security     BearerAuth
+// @Router       /api/admin/geofence [get]
+func (h *GeoFenceHandler) GetGeoFence(c *gin.Context) {
+	NewResponseBuilder(c).SuccessOK(h.fence.Rules())
+}
+
+// SetGeoFence handles PUT /api/admin/geofence requests.
+//
+// @Summary      Replace geofence rules
+// @Description  Replaces the CIDR ranges and countries requests may come from, for tenants without rules of their own; empty lists lift the restriction. Requests from elsewhere get 403, except health checks, metrics and users with the system:write permission, whose bypass is audited. The country is read from the header set by a trusted edge proxy. Rules apply to the instance that receives the request until it restarts.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        request body middleware.GeoFenceRules true "Geofence rules"
+// @Success      200 {object} dto.SuccessResponse{data=middleware.GeoFenceRules} "Geofence rules"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid CIDR range or country code"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Security     BearerAuth
+// @Router       /api/admin/geofence [put]
+func (h *GeoFenceHandler) SetGeoFence(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var rules middleware.GeoFenceRules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if err := h.fence.SetRules(rules); err != nil {
+		if errors.Is(err, middleware.ErrInvalidGeoFenceRule) {
+			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+			return
+		}
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	rules = h.fence.Rules()
+	log.Warn().Strs("allowed_cidrs", rules.AllowedCIDRs).Strs("allowed_countries", rules.AllowedCountries).Msg("Geofence rules replaced")
+	h.audit(c, "update_geofence", "Geofence rules replaced", map[string]interface{}{
+		"allowed_cidrs":     rules.AllowedCIDRs,
+		"allowed_countries": rules.AllowedCountries,
+	})
+	builder.SuccessOK(rules)
+}
+
+// ListTenantGeoFences handles GET /api/admin/geofence/tenants requests.
+//
+// @Summary      List tenant geofence rules
+// @Description  Returns the geofence rules of the tenants that have their own, by tenant. Tenants are API keys, identified by their fingerprint.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=map[string]middleware.GeoFenceRules} "Geofence rules by tenant"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Security     BearerAuth
+// @Router       /api/admin/geofence/tenants [get]
+func (h *GeoFenceHandler) ListTenantGeoFences(c *gin.Context) {
+	NewResponseBuilder(c).SuccessOK(h.fence.TenantRules())
+}
+
+// SetTenantGeoFence handles PUT /api/admin/geofence/tenants/:tenant requests.
+//
+// @Summary      Replace a tenant's geofence rules
+// @Description  Replaces the CIDR ranges and countries the requests authenticated with the tenant's API key may come from. They apply instead of the rules of other clients; empty lists lift the restriction for the tenant. Rules apply to the instance that receives the request until it restarts.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        tenant path string true "API key fingerprint of the tenant"
+// @Param        request body middleware.GeoFenceRules true "Geofence rules"
+// @Success      200 {object} dto.SuccessResponse{data=middleware.GeoFenceRules} "Geofence rules"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid CIDR range or country code"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Security     BearerAuth
+// @Router       /api/admin/geofence/tenants/{tenant} [put]
+func (h *GeoFenceHandler) SetTenantGeoFence(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+	tenant := c.Param("tenant")
+
+	var rules middleware.GeoFenceRules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if err := h.fence.SetTenantRules(tenant, rules); err != nil {
+		if errors.Is(err, middleware.ErrInvalidGeoFenceRule) {
+			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+			return
+		}
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	rules = h.fence.TenantRules()[tenant]
+	log.Warn().Str("tenant", tenant).Strs("allowed_cidrs", rules.AllowedCIDRs).Strs("allowed_countries", rules.AllowedCountries).Msg("Tenant geofence rules replaced")
+	h.audit(c, "update_tenant_geofence", "Tenant geofence rules replaced", map[string]interface{}{
+		"tenant":            tenant,
+		"allowed_cidrs":     rules.AllowedCIDRs,
+		"allowed_countries": rules.AllowedCountries,
+	})
+	builder.SuccessOK(rules)
+}
+
+// DeleteTenantGeoFence handles DELETE /api/admin/geofence/tenants/:tenant requests.
+//
+// @Summary      Remove a tenant's geofence rules
+// @Description  Removes the tenant's own geofence rules, so the rules of other clients apply to it again.
+// @Tags         Admin
+// @Param        Authorization header string true "Bearer token"
+// @Param        tenant path string true "API key fingerprint of the tenant"
+// @Success      204 "Rules removed"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      404 {object} dto.ErrorResponse "The tenant has no rules of its own"
+// @Security     BearerAuth
+// @Router       /api/admin/geofence/tenants/{tenant} [delete]
+func (h *GeoFenceHandler) DeleteTenantGeoFence(c *gin.Context) {
+	tenant := c.Param("tenant")
+	if !h.fence.DeleteTenantRules(tenant) {
+		NewResponseBuilder(c).Error(http.StatusNotFound, i18n.ErrKeyNotFound, nil)
+		return
+	}
+
+	log.Warn().Str("tenant", tenant).Msg("Tenant geofence rules removed")
+	h.audit(c, "delete_tenant_geofence", "Tenant geofence rules removed", map[string]interface{}{"tenant": tenant})
+	c.Status(http.StatusNoContent)
+}
+
+func (h *GeoFenceHandler) audit(c *gin.Context, action, message string, fields map[string]interface{}) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, action, message, fields)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestGeoFenceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fence, err := middleware.NewGeoFence(middleware.GeoFenceRules{}, middleware.WithTrustedProxies(netip.MustParsePrefix("192.0.2.0/24")))
+	require.NoError(t, err)
+	cfg := DefaultRouterConfig()
+	cfg.GeoFence = fence
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	// Admin routes need JWT auth; mount the handler directly
+	geoFenceHandler := NewGeoFenceHandler(fence)
+	router.GET("/api/admin/geofence", geoFenceHandler.GetGeoFence)
+	router.PUT("/api/admin/geofence", geoFenceHandler.SetGeoFence)
+	router.GET("/api/admin/geofence/tenants", geoFenceHandler.ListTenantGeoFences)
+	router.PUT("/api/admin/geofence/tenants/:tenant", geoFenceHandler.SetTenantGeoFence)
+	router.DELETE("/api/admin/geofence/tenants/:tenant", geoFenceHandler.DeleteTenantGeoFence)
+
+	do := func(method, path, body, country string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.DefaultCountryHeader, country)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	rules := func(w *httptest.ResponseRecorder) middleware.GeoFenceRules {
+		var resp struct {
+			Data middleware.GeoFenceRules `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	w := do(http.MethodGet, "/api/admin/geofence", "", "US")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, rules(w).AllowedCountries)
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		w := do(http.MethodPut, "/api/admin/geofence", `{"allowed_countries":["Netherlands"]}`, "NL")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, fence.Rules().AllowedCountries)
+	})
+
+	t.Run("replaces the rules and enforces them", func(t *testing.T) {
+		w := do(http.MethodPut, "/api/admin/geofence", `{"allowed_cidrs":["10.0.0.0/8"],"allowed_countries":["nl"]}`, "NL")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, middleware.GeoFenceRules{AllowedCIDRs: []string{"10.0.0.0/8"}, AllowedCountries: []string{"NL"}}, rules(w))
+
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`, "NL").Code)
+		assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`, "US").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/healthz", "", "US").Code)
+	})
+
+	t.Run("empty rules lift the restriction", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(http.MethodPut, "/api/admin/geofence", `{"allowed_cidrs":[],"allowed_countries":[]}`, "NL").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`, "US").Code)
+	})
+
+	t.Run("manages tenant rules", func(t *testing.T) {
+		w := do(http.MethodPut, "/api/admin/geofence/tenants/1a2b3c4d", `{"allowed_countries":["be"]}`, "NL")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"BE"}, rules(w).AllowedCountries)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/api/admin/geofence/tenants/1a2b3c4d", `{"allowed_cidrs":["10.0.0.1"]}`, "NL").Code)
+
+		w = do(http.MethodGet, "/api/admin/geofence/tenants", "", "NL")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data map[string]middleware.GeoFenceRules `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"BE"}, resp.Data["1a2b3c4d"].AllowedCountries)
+
+		assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/admin/geofence/tenants/1a2b3c4d", "", "NL").Code)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/admin/geofence/tenants/1a2b3c4d", "", "NL").Code)
+		assert.Empty(t, fence.TenantRules())
+	})
+}
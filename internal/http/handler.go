@@ -2,28 +2,49 @@ package http
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/deprecation"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/edgecache"
+	"github.com/guttosm/pack-service/internal/fixedpoint"
+	"github.com/guttosm/pack-service/internal/hooks"
 	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/metrics"
 	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/presentation"
+	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/tracing"
+	"github.com/guttosm/pack-service/internal/workerpool"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // packSizesCache provides thread-safe caching of pack sizes.
 type packSizesCache struct {
-	sizes     atomic.Value // holds []int
+	entry     atomic.Value // holds packSizesEntry
 	expiresAt atomic.Value // holds time.Time
 	mu        sync.Mutex
 	ttl       time.Duration
 }
 
+// packSizesEntry pairs cached sizes and their costs with the config version
+// they came from, so they are always read together.
+type packSizesEntry struct {
+	sizes   []int
+	costs   map[int]float64
+	version int
+}
+
 // newPackSizesCache creates a new pack sizes cache with the given TTL.
 func newPackSizesCache(ttl time.Duration) *packSizesCache {
 	c := &packSizesCache{ttl: ttl}
@@ -33,20 +54,25 @@ func newPackSizesCache(ttl time.Duration) *packSizesCache {
 
 // get returns cached pack sizes if valid, or nil if cache is expired/empty.
 func (c *packSizesCache) get() []int {
+	sizes, _, _ := c.current()
+	return sizes
+}
+
+// current returns cached pack sizes, their costs and their config version if
+// valid.
+func (c *packSizesCache) current() ([]int, map[int]float64, int) {
 	if exp := c.expiresAt.Load(); exp != nil {
 		if expiresAt, ok := exp.(time.Time); ok && time.Now().Before(expiresAt) {
-			if sizes := c.sizes.Load(); sizes != nil {
-				if s, ok := sizes.([]int); ok {
-					return s
-				}
+			if entry, ok := c.entry.Load().(packSizesEntry); ok && entry.sizes != nil {
+				return entry.sizes, entry.costs, entry.version
 			}
 		}
 	}
-	return nil
+	return nil, nil, 0
 }
 
-// set stores pack sizes in the cache with TTL.
-func (c *packSizesCache) set(sizes []int) {
+// set stores pack sizes and their costs in the cache with TTL.
+func (c *packSizesCache) set(sizes []int, costs map[int]float64, version int) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -57,7 +83,16 @@ func (c *packSizesCache) set(sizes []int) {
 		}
 	}
 
-	c.sizes.Store(sizes)
+	c.entry.Store(packSizesEntry{sizes: sizes, costs: costs, version: version})
+	c.expiresAt.Store(time.Now().Add(c.ttl))
+}
+
+// prime stores pack sizes unconditionally, replacing any cached value.
+// Used after a write so readers never observe the previous configuration.
+func (c *packSizesCache) prime(sizes []int, costs map[int]float64, version int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entry.Store(packSizesEntry{sizes: sizes, costs: costs, version: version})
 	c.expiresAt.Store(time.Now().Add(c.ttl))
 }
 
@@ -73,6 +108,25 @@ type Handler struct {
 	calculator       service.PackCalculator
 	packSizesService service.PackSizesService
 	packSizesCache   *packSizesCache
+	inputWarnings    service.InputWarningDetector
+	presetService    service.PresetService
+	deprecations     *deprecation.Tracker
+	batchPool        *workerpool.Pool
+	heavyRequests    *workerpool.Pool
+	history          service.CalculationHistoryService
+	maxCompute       time.Duration
+	streamJobs       *service.StreamJobRunner
+	calculationJobs  service.CalculationJobService
+	hooks            *hooks.Registry
+	presentation     *presentation.Engine
+	migrator         *service.PackSizesMigrator
+	packSizesWatcher repository.PackSizesWatcher
+	activePackSizes  *service.ActivePackSizes
+	edgeCache        *edgecache.Policy
+	packSizeRules    service.PackSizeRules
+	responseCache    *middleware.ResponseCache
+	// decimalPrecision is the number of decimal places of quantity requests
+	decimalPrecision int
 }
 
 // HandlerOption configures a Handler.
@@ -85,12 +139,156 @@ func WithPackSizesCacheTTL(ttl time.Duration) HandlerOption {
 	}
 }
 
+// WithInputWarningDetector sets the detector used to flag suspicious calculate inputs.
+func WithInputWarningDetector(detector service.InputWarningDetector) HandlerOption {
+	return func(h *Handler) {
+		h.inputWarnings = detector
+	}
+}
+
+// WithPresetService enables resolving named presets in calculate requests.
+func WithPresetService(presetService service.PresetService) HandlerOption {
+	return func(h *Handler) {
+		h.presetService = presetService
+	}
+}
+
+// WithDeprecationTracker records deprecated field usage for the admin report.
+func WithDeprecationTracker(tracker *deprecation.Tracker) HandlerOption {
+	return func(h *Handler) {
+		h.deprecations = tracker
+	}
+}
+
+// WithBatchPool calculates batch items on pool. Without one, batches are
+// calculated one item at a time on the request goroutine.
+func WithBatchPool(pool *workerpool.Pool) HandlerOption {
+	return func(h *Handler) {
+		h.batchPool = pool
+	}
+}
+
+// WithHeavyRequests serves the CPU-heavy routes, batch and streamed
+// calculations, on workers of pool, apart from normal traffic, so a burst of
+// them cannot slow down single calculations. Without one they run uncapped.
+func WithHeavyRequests(pool *workerpool.Pool) HandlerOption {
+	return func(h *Handler) {
+		h.heavyRequests = pool
+	}
+}
+
+// WithMaxCompute caps the compute time a request may ask for with
+// max_compute_ms. Zero leaves it uncapped.
+func WithMaxCompute(limit time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.maxCompute = limit
+	}
+}
+
+// WithStreamJobs enables streaming bulk calculations as server-sent events.
+func WithStreamJobs(runner *service.StreamJobRunner) HandlerOption {
+	return func(h *Handler) {
+		h.streamJobs = runner
+	}
+}
+
+// WithCalculationJobs enables queuing batch calculations as background jobs.
+func WithCalculationJobs(jobs service.CalculationJobService) HandlerOption {
+	return func(h *Handler) {
+		h.calculationJobs = jobs
+	}
+}
+
+// WithCalculationHistory stores every successful calculation in history.
+func WithCalculationHistory(history service.CalculationHistoryService) HandlerOption {
+	return func(h *Handler) {
+		h.history = history
+	}
+}
+
+// WithHooks runs the request validators and result processors of registry
+// around every calculation.
+func WithHooks(registry *hooks.Registry) HandlerOption {
+	return func(h *Handler) {
+		h.hooks = registry
+	}
+}
+
+// WithPresentation lays out calculated results by the rules of engine.
+func WithPresentation(engine *presentation.Engine) HandlerOption {
+	return func(h *Handler) {
+		h.presentation = engine
+	}
+}
+
+// WithPackSizesMigrator enables staged pack size migrations, comparing the
+// calculations made with the stored pack sizes against the staged ones.
+func WithPackSizesMigrator(migrator *service.PackSizesMigrator) HandlerOption {
+	return func(h *Handler) {
+		h.migrator = migrator
+	}
+}
+
+// WithPackSizesWatcher drops the cached pack sizes and calculation results
+// whenever watcher reports a change, including ones made by other replicas.
+func WithPackSizesWatcher(watcher repository.PackSizesWatcher) HandlerOption {
+	return func(h *Handler) {
+		h.packSizesWatcher = watcher
+	}
+}
+
+// WithActivePackSizes reads the pack sizes from view, kept current in the
+// background, so calculations never wait on the database. It takes the
+// place of the pack sizes cache and of WithPackSizesWatcher.
+func WithActivePackSizes(view *service.ActivePackSizes) HandlerOption {
+	return func(h *Handler) {
+		h.activePackSizes = view
+	}
+}
+
+// WithEdgeCache lets an API gateway or CDN cache anonymous calculate
+// responses under policy, and purges them when the pack sizes change.
+func WithEdgeCache(policy *edgecache.Policy) HandlerOption {
+	return func(h *Handler) {
+		h.edgeCache = policy
+	}
+}
+
+// WithPackSizeRules sets the rules pack sizes must follow to be saved or
+// staged. Without them, service.DefaultPackSizeRules apply.
+func WithPackSizeRules(rules service.PackSizeRules) HandlerOption {
+	return func(h *Handler) {
+		h.packSizeRules = rules
+	}
+}
+
+// WithResponseCache caches the pack size responses in cache, and drops them
+// whenever the pack sizes change.
+func WithResponseCache(cache *middleware.ResponseCache) HandlerOption {
+	return func(h *Handler) {
+		h.responseCache = cache
+	}
+}
+
+// WithDecimalPrecision sets the number of decimal places, at most
+// fixedpoint.MaxPrecision, quantity requests are calculated with. Zero or
+// less keeps fixedpoint.DefaultPrecision.
+func WithDecimalPrecision(precision int) HandlerOption {
+	return func(h *Handler) {
+		if precision > 0 {
+			h.decimalPrecision = min(precision, fixedpoint.MaxPrecision)
+		}
+	}
+}
+
 // NewHandler creates a new Handler instance.
 func NewHandler(calculator service.PackCalculator, packSizesService service.PackSizesService, opts ...HandlerOption) *Handler {
 	h := &Handler{
 		calculator:       calculator,
 		packSizesService: packSizesService,
 		packSizesCache:   newPackSizesCache(30 * time.Second), // Default 30s cache
+		inputWarnings:    service.NewInputWarningDetector(),
+		decimalPrecision: fixedpoint.DefaultPrecision,
 	}
 
 	for _, opt := range opts {
@@ -100,16 +298,25 @@ func NewHandler(calculator service.PackCalculator, packSizesService service.Pack
 	return h
 }
 
-// getPackSizes retrieves pack sizes from cache or database.
-func (h *Handler) getPackSizes(ctx context.Context) []int {
+// getPackSizes retrieves pack sizes, their costs and their config version
+// from cache or database. The version is zero when no stored configuration is
+// available.
+func (h *Handler) getPackSizes(ctx context.Context) ([]int, map[int]float64, int) {
+	if h.activePackSizes != nil {
+		if config := h.activePackSizes.Current(); config != nil {
+			return config.Sizes, config.Costs, config.Version
+		}
+		return nil, nil, 0
+	}
+
 	// Check cache first
-	if sizes := h.packSizesCache.get(); sizes != nil {
-		return sizes
+	if sizes, costs, version := h.packSizesCache.current(); sizes != nil {
+		return sizes, costs, version
 	}
 
 	// Cache miss - fetch from database
 	if h.packSizesService == nil {
-		return nil
+		return nil, nil, 0
 	}
 
 	// Use a timeout for database fetch
@@ -118,12 +325,12 @@ func (h *Handler) getPackSizes(ctx context.Context) []int {
 
 	config, err := h.packSizesService.GetActive(ctx)
 	if err != nil || config == nil || len(config.Sizes) == 0 {
-		return nil
+		return nil, nil, 0
 	}
 
 	// Cache the result
-	h.packSizesCache.set(config.Sizes)
-	return config.Sizes
+	h.packSizesCache.set(config.Sizes, config.Costs, config.Version)
+	return config.Sizes, config.Costs, config.Version
 }
 
 // InvalidatePackSizesCache invalidates the pack sizes cache.
@@ -132,13 +339,30 @@ func (h *Handler) InvalidatePackSizesCache() {
 	h.packSizesCache.invalidate()
 }
 
+// invalidatePackSizes drops the cached pack sizes and the calculation results
+// made with them.
+func (h *Handler) invalidatePackSizes() {
+	h.packSizesCache.invalidate()
+	h.calculator.InvalidateCache()
+	h.responseCache.Invalidate(responseCachePackSizes)
+}
+
+// PrimePackSizesCache replaces the cached pack sizes and their costs with the
+// given value. Call this with the result of a successful write to get
+// read-your-writes.
+func (h *Handler) PrimePackSizesCache(sizes []int, costs map[int]float64, version int) {
+	h.packSizesCache.prime(sizes, costs, version)
+}
+
 // CalculatePacks handles POST /api/calculate requests.
 //
 // @Summary      Calculate packs for order
-// @Description  Calculates the optimal number of packs needed to fulfill an order. The service uses dynamic programming to find the combination that minimizes total items while using the fewest number of packs. Supports idempotency via Idempotency-Key header.
+// @Description  Calculates the optimal number of packs needed to fulfill an order. The service uses dynamic programming to find the combination that minimizes total items while using the fewest number of packs. Supports idempotency via Idempotency-Key header. Inputs that look like client mistakes are calculated anyway and reported in the response warnings. Optional max_packs, max_overage_items and max_overage_percent restrict the acceptable combinations; when none qualifies the response is 422. Optional max_compute_ms limits the calculation time, capped by the server; when it runs out the result is a fast greedy combination marked approximate. Optional explain adds the alternative combinations considered and why the result was chosen. With Accept: text/csv or the XLSX content type, the result is downloaded as a one-row spreadsheet, without warnings, explanation or presentation; errors stay JSON.
 // @Tags         Packs
 // @Accept       json
 // @Produce      json
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param        Idempotency-Key header string false "Idempotency key for request deduplication"
 // @Param        request body dto.CalculatePacksRequest true "Order information"
 // @Success      200 {object} dto.SuccessResponse "Successful calculation"
@@ -147,6 +371,7 @@ func (h *Handler) InvalidatePackSizesCache() {
 // @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure      403 {object} dto.ErrorResponse "Forbidden - insufficient permissions"
 // @Failure      404 {object} dto.ErrorResponse "Not found - resource not found"
+// @Failure      422 {object} dto.ErrorResponse "No pack combination satisfies max_packs / max_overage_items / max_overage_percent"
 // @Failure      429 {object} dto.ErrorResponse "Too many requests - rate limit exceeded"
 // @Failure      500 {object} dto.ErrorResponse "Internal server error"
 // @Failure      502 {object} dto.ErrorResponse "Bad gateway"
@@ -163,54 +388,383 @@ func (h *Handler) CalculatePacks(c *gin.Context) {
 	}
 
 	if err := req.Validate(); err != nil {
-		if _, ok := err.(*dto.ValidationError); ok {
+		switch {
+		case isValidationError(err):
 			metrics.RecordPackCalculation(0, "validation_error")
-			builder.Error(http.StatusBadRequest, i18n.ErrKeyValidationItemsOrdered, err)
-		} else {
+			builder.Error(http.StatusBadRequest, validationMessageKey(err), err)
+		default:
 			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
 		}
 		return
 	}
+	if err := req.CheckItemsOrdered(service.MaxItemsOrdered(h.calculator)); err != nil {
+		metrics.RecordPackCalculation(0, "validation_error")
+		h.orderTooLarge(builder, c)
+		return
+	}
+	if err := h.hooks.ValidateRequest(c.Request.Context(), &req); err != nil {
+		metrics.RecordPackCalculation(0, "validation_error")
+		builder.ErrorWithMessage(http.StatusBadRequest, err.Error(), err)
+		return
+	}
 
-	start := time.Now()
-	var result model.PackResult
+	// The edge cache key covers the request as sent, without the stored costs
+	edgeReq := req
+
+	// Resolve the pack sizes up front so the audit entry records which
+	// configuration version the result was computed with.
+	var customSizes, configSizes []int
+	configVersion := 0
+	if req.Preset != "" {
+		preset, ok := h.resolvePreset(c, builder, req.Preset)
+		if !ok {
+			return
+		}
+		customSizes = preset.PackSizes
+		req.ApplyPreset(preset)
+		if err := req.Validate(); err != nil {
+			metrics.RecordPackCalculation(0, "validation_error")
+			builder.Error(http.StatusBadRequest, validationMessageKey(err), err)
+			return
+		}
+	} else if len(req.PackSizes) > 0 {
+		customSizes = make([]int, 0, len(req.PackSizes))
+		for _, size := range req.PackSizes {
+			if size > 0 {
+				customSizes = append(customSizes, size)
+			}
+		}
+	} else {
+		var configCosts map[int]float64
+		configSizes, configCosts, configVersion = h.getPackSizes(c.Request.Context())
+		if len(req.PackCosts) == 0 {
+			req.PackCosts = configCosts
+		}
+	}
+
+	effectiveSizes := customSizes
+	if len(effectiveSizes) == 0 {
+		effectiveSizes = configSizes
+	}
+	warnings := h.inspectInput(c, req, effectiveSizes)
 
 	// Audit log (async)
 	if loggingService, exists := c.Get("logging_service"); exists {
 		if ls, ok := loggingService.(service.LoggingService); ok {
-			middleware.AuditLog(ls, c, "calculate", "Pack calculation requested", map[string]interface{}{
+			fields := map[string]interface{}{
 				"items_ordered":    req.ItemsOrdered,
 				"has_custom_sizes": len(req.PackSizes) > 0,
-			})
+			}
+			if len(configSizes) > 0 && configVersion > 0 {
+				fields[model.FieldPackSizesVersion] = configVersion
+			}
+			if req.Preset != "" {
+				fields["preset"] = req.Preset
+			}
+			if req.MaxPacks != nil {
+				fields["max_packs"] = *req.MaxPacks
+			}
+			if req.MaxOverageItems != nil {
+				fields["max_overage_items"] = *req.MaxOverageItems
+			}
+			if req.MaxOveragePercent != nil {
+				fields["max_overage_percent"] = *req.MaxOveragePercent
+			}
+			if req.MaxComputeMs != nil {
+				fields["max_compute_ms"] = *req.MaxComputeMs
+			}
+			if req.Optimize != "" {
+				fields["optimize"] = req.Optimize
+			}
+			if len(req.Metadata) > 0 {
+				fields["metadata"] = req.Metadata
+			}
+			if len(warnings) > 0 {
+				codes := make([]string, len(warnings))
+				for i, w := range warnings {
+					codes[i] = w.Code
+				}
+				fields["input_warnings"] = codes
+			}
+			middleware.AuditLog(ls, c, model.ActionCalculate, "Pack calculation requested", fields)
 		}
 	}
 
-	if len(req.PackSizes) > 0 {
-		// Use custom pack sizes from request
-		validPackSizes := make([]int, 0, len(req.PackSizes))
-		for _, size := range req.PackSizes {
-			if size > 0 {
-				validPackSizes = append(validPackSizes, size)
-			}
-		}
-		if len(validPackSizes) > 0 {
-			result = h.calculator.CalculateWithPackSizes(req.ItemsOrdered, validPackSizes)
-		} else {
-			result = h.calculator.Calculate(req.ItemsOrdered)
-		}
-	} else {
-		// Use cached pack sizes from database or defaults
-		packSizes := h.getPackSizes(c.Request.Context())
+	start := time.Now()
+	result, err := h.calculate(c.Request.Context(), i18n.GetTenant(c), &req, effectiveSizes)
+	duration := time.Since(start)
+
+	if errors.Is(err, service.ErrConstraintsUnsatisfiable) {
+		metrics.RecordPackCalculation(duration, "unsatisfiable")
+		builder.ErrorFrom(err)
+		return
+	}
+	if errors.Is(err, service.ErrOrderTooLarge) {
+		metrics.RecordPackCalculation(duration, "validation_error")
+		h.orderTooLarge(builder, c)
+		return
+	}
+	if errors.Is(err, service.ErrMissingPackCost) {
+		metrics.RecordPackCalculation(duration, "validation_error")
+		builder.ErrorFrom(err)
+		return
+	}
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	recordPackCalculation(duration, effectiveSizes, result)
+	result.Metadata = req.Metadata
+	h.recordCalculation(newCalculationRecord(c, model.CalculationSourceHTTP), &req, effectiveSizes, configVersion, result, duration)
+	h.shadowCalculation(&req, configVersion, result)
+	warnings = append(warnings, deprecationWarnings(c, h.deprecations, &req, &result)...)
+	h.setEdgeCacheHeaders(c, &edgeReq, result, warnings, len(customSizes) == 0)
+	if format := tableFormat(c, gin.MIMEJSON, MIMECSV, MIMEXLSX); format != "" {
+		writeCalculationTable(c, format, result)
+		return
+	}
+	builder.SuccessWithWarnings(http.StatusOK, result, warnings)
+}
+
+// setEdgeCacheHeaders lets shared caches store the response when the same
+// request from anyone else gets the same answer: the caller is anonymous and
+// no tenant's messages or presentation rules apply, the result is exact and
+// carries no warnings, the request has no preset, metadata or idempotency
+// key, and it asks for JSON. Every other response is marked private.
+// Responses calculated with the configured pack sizes are tagged to be purged
+// when those change.
+func (h *Handler) setEdgeCacheHeaders(c *gin.Context, req *dto.CalculatePacksRequest, result model.PackResult, warnings []dto.Warning, configuredSizes bool) {
+	if h.edgeCache == nil {
+		return
+	}
+	if !isAnonymous(c) || i18n.GetTenant(c) != "" || result.Approximate || len(warnings) > 0 ||
+		req.Preset != "" || len(req.Metadata) > 0 ||
+		c.GetHeader("Idempotency-Key") != "" || tableFormat(c, gin.MIMEJSON, MIMECSV, MIMEXLSX) != "" {
+		h.edgeCache.Private(c.Writer.Header())
+		return
+	}
+
+	surrogateKeys := []string{edgecache.SurrogateKeyCalculate}
+	if configuredSizes {
+		surrogateKeys = append(surrogateKeys, edgecache.SurrogateKeyPackSizes)
+	}
+	h.edgeCache.Cacheable(c.Writer.Header(), edgecache.Key(req), surrogateKeys...)
+}
+
+// isAnonymous reports whether the request carries no credentials, in a
+// header or in the API key query parameter.
+func isAnonymous(c *gin.Context) bool {
+	if _, exists := c.Get("user_id"); exists {
+		return false
+	}
+	return c.GetHeader("Authorization") == "" && c.GetHeader(middleware.APIKeyHeader) == "" &&
+		c.Query(middleware.APIKeyQuery) == ""
+}
+
+// calculate runs req with sizes, the calculator's own when empty, in a
+// span of the trace in ctx, then applies the result processor hooks and the
+// presentation rules of tenant.
+func (h *Handler) calculate(ctx context.Context, tenant string, req *dto.CalculatePacksRequest, sizes []int) (model.PackResult, error) {
+	_, span := tracing.Tracer().Start(ctx, "pack.calculate", trace.WithAttributes(tracing.ItemsOrdered.Int(req.ItemsOrdered)))
+	defer span.End()
+
+	result, err := h.runCalculation(req, sizes)
+	span.SetAttributes(tracing.CacheHit.Bool(result.CacheHit))
+	if err == nil && req.Explain {
+		result.Explanation = h.calculator.Explain(result, sizes, req.Constraints())
+	}
+	if err == nil {
+		err = h.hooks.ProcessResult(ctx, req, &result)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return result, err
+	}
+	result.Presentation = h.presentation.Present(tenant, result)
+	return result, nil
+}
+
+// runCalculation picks the calculator method req needs.
+func (h *Handler) runCalculation(req *dto.CalculatePacksRequest, sizes []int) (model.PackResult, error) {
+	constraints := req.Constraints()
+	switch {
+	case req.MaxComputeMs != nil:
+		return h.calculator.CalculateWithin(req.ItemsOrdered, sizes, constraints, h.computeBudget(*req.MaxComputeMs))
+	case !constraints.IsZero():
+		return h.calculator.CalculateWithConstraints(req.ItemsOrdered, sizes, constraints)
+	case len(sizes) > 0:
+		return h.calculator.CalculateWithPackSizes(req.ItemsOrdered, sizes), nil
+	default:
+		return h.calculator.Calculate(req.ItemsOrdered), nil
+	}
+}
+
+// computeBudget converts max_compute_ms to a duration capped by maxCompute.
+func (h *Handler) computeBudget(maxComputeMs int) time.Duration {
+	budget := time.Duration(maxComputeMs) * time.Millisecond
+	if h.maxCompute > 0 && budget > h.maxCompute {
+		return h.maxCompute
+	}
+	return budget
+}
+
+// recordPackCalculation records the metrics of a result calculated with
+// sizes, empty for the calculator's own. Only exact results are compared
+// with greedy; approximate ones are greedy.
+func recordPackCalculation(duration time.Duration, sizes []int, result model.PackResult) {
+	metrics.RecordPackCalculation(duration, calculationStatus(result))
+	metrics.RecordPackResult(result.OrderedItems, result.PackCount(), duration)
+	metrics.RecordPackSetCalculation(sizes)
+	if !result.Approximate {
+		metrics.RecordGreedyComparison(result.GreedyDiffers)
+	}
+}
+
+// calculationStatus is the pack_calculations_total status of a result.
+func calculationStatus(result model.PackResult) string {
+	if result.Approximate {
+		return "approximate"
+	}
+	return "success"
+}
+
+// newCalculationRecord starts a calculation history record for a request.
+func newCalculationRecord(c *gin.Context, source string) model.Calculation {
+	return model.Calculation{
+		RequestID: middleware.GetRequestID(c),
+		UserID:    userIDFromContext(c),
+		Source:    source,
+	}
+}
+
+// recordCalculation completes record with the input and result of a
+// calculation and adds it to the history, which writes it asynchronously.
+func (h *Handler) recordCalculation(record model.Calculation, req *dto.CalculatePacksRequest, sizes []int, configVersion int, result model.PackResult, latency time.Duration) {
+	if h.history == nil {
+		return
+	}
+
+	record.ItemsOrdered = req.ItemsOrdered
+	record.PackSizes = sizes
+	record.Preset = req.Preset
+	record.PackSizesVersion = configVersion
+	if constraints := req.Constraints(); !constraints.IsZero() {
+		record.Constraints = &constraints
+	}
+	record.TotalItems = result.TotalItems
+	record.Packs = result.Packs
+	record.TotalCost = result.TotalCost
+	record.Metadata = req.Metadata
+	record.Approximate = result.Approximate
+	record.LatencyMicros = latency.Microseconds()
+	h.history.Record(&record)
+}
+
+// shadowCalculation hands a result calculated with the stored pack sizes to
+// the migrator. Approximate results are not compared, nor are results
+// optimized for cost, as the staged sizes have no costs.
+func (h *Handler) shadowCalculation(req *dto.CalculatePacksRequest, configVersion int, result model.PackResult) {
+	if configVersion == 0 || result.Approximate || req.Constraints().MinCost() {
+		return
+	}
+	h.migrator.Observe(req.ItemsOrdered, req.Constraints(), configVersion, result)
+}
+
+// resolvePreset loads the caller's preset by name. On failure it writes the
+// error response and returns false.
+func (h *Handler) resolvePreset(c *gin.Context, builder *ResponseBuilder, name string) (*model.Preset, bool) {
+	if h.presetService == nil {
+		builder.Error(http.StatusNotFound, i18n.ErrKeyPresetNotFound, nil)
+		return nil, false
+	}
+
+	preset, err := h.presetService.Get(c.Request.Context(), presetOwner(c), name)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return nil, false
+	}
+	return preset, true
+}
+
+// orderTooLarge responds 422 to an order above the calculator's maximum,
+// stating the maximum in the message and details.
+func (h *Handler) orderTooLarge(builder *ResponseBuilder, c *gin.Context) {
+	maxItems := service.MaxItemsOrdered(h.calculator)
+	err := &dto.ItemsOrderedTooLargeError{Max: maxItems}
+	builder.ErrorWithDetails(http.StatusUnprocessableEntity, orderTooLargeMessage(i18n.GetTenant(c), i18n.GetLocale(c), maxItems),
+		map[string]string{"max_items_ordered": strconv.Itoa(maxItems)}, err)
+}
+
+// orderTooLargeMessage translates the message for an order above maxItems,
+// as tenant replaced it when it did.
+func orderTooLargeMessage(tenant, locale string, maxItems int) string {
+	return fmt.Sprintf(i18n.GetTranslator().TranslateFor(tenant, i18n.ErrKeyValidationItemsOrderedMax, locale), maxItems)
+}
+
+// validationMessageKey returns the translation key for a calculate request
+// validation error.
+func validationMessageKey(err error) string {
+	switch err {
+	case dto.ErrInvalidPackSizes:
+		return i18n.ErrKeyValidationPackSizes
+	case dto.ErrPresetWithPackSizes:
+		return i18n.ErrKeyValidationPresetWithPackSizes
+	case dto.ErrInvalidMaxPacks:
+		return i18n.ErrKeyValidationMaxPacks
+	case dto.ErrInvalidMaxOverageItems, dto.ErrInvalidMaxOveragePercent:
+		return i18n.ErrKeyValidationMaxOverage
+	case dto.ErrInvalidMetadata:
+		return i18n.ErrKeyValidationMetadata
+	case dto.ErrInvalidMaxComputeMs:
+		return i18n.ErrKeyValidationMaxCompute
+	case dto.ErrInvalidOptimize:
+		return i18n.ErrKeyValidationOptimize
+	case dto.ErrInvalidPackCosts:
+		return i18n.ErrKeyValidationPackCosts
+	default:
+		return i18n.ErrKeyValidationItemsOrdered
+	}
+}
+
+// isValidationError reports whether err is a request validation error.
+func isValidationError(err error) bool {
+	_, ok := err.(*dto.ValidationError)
+	return ok
+}
 
-		if len(packSizes) > 0 {
-			result = h.calculator.CalculateWithPackSizes(req.ItemsOrdered, packSizes)
-		} else {
-			result = h.calculator.Calculate(req.ItemsOrdered)
+// userIDFromContext returns the hex ID of the user authenticated by the JWT
+// middleware, or "" when the request is unauthenticated.
+func userIDFromContext(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(primitive.ObjectID); ok && !id.IsZero() {
+			return id.Hex()
 		}
 	}
+	return ""
+}
 
-	duration := time.Since(start)
+// inspectInput flags suspicious but valid calculate inputs. Warnings are
+// returned to the client and counted, never used to reject the request.
+func (h *Handler) inspectInput(c *gin.Context, req dto.CalculatePacksRequest, effectiveSizes []int) []dto.Warning {
+	if h.inputWarnings == nil {
+		return nil
+	}
 
-	metrics.RecordPackCalculation(duration, "success")
-	builder.SuccessOK(result)
+	clientID := userIDFromContext(c)
+	if clientID == "" {
+		clientID = c.ClientIP()
+	}
+
+	found := h.inputWarnings.Inspect(clientID, req.ItemsOrdered, req.PackSizes, effectiveSizes)
+	if len(found) == 0 {
+		return nil
+	}
+
+	warnings := make([]dto.Warning, len(found))
+	for i, w := range found {
+		metrics.RecordInputWarning(w.Code)
+		warnings[i] = dto.Warning{Code: w.Code, Field: w.Field, Message: w.Message}
+	}
+	return warnings
 }
@@ -72,7 +72,7 @@ func TestPackSizesCache_SetAndGet(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			cache := newPackSizesCache(tt.ttl)
 
-			cache.set(tt.sizes)
+			cache.set(tt.sizes, nil, 0)
 
 			if tt.waitTime > 0 {
 				time.Sleep(tt.waitTime)
@@ -94,7 +94,7 @@ func TestPackSizesCache_Invalidate(t *testing.T) {
 
 	// Set some values
 	sizes := []int{250, 500, 1000}
-	cache.set(sizes)
+	cache.set(sizes, nil, 0)
 
 	// Should be cached
 	assert.Equal(t, sizes, cache.get())
@@ -111,30 +111,48 @@ func TestPackSizesCache_SetDoesNotOverwriteValid(t *testing.T) {
 
 	// Set first values
 	firstSizes := []int{100, 200}
-	cache.set(firstSizes)
+	cache.set(firstSizes, nil, 0)
 
 	// Try to set different values (should not overwrite since cache is still valid)
 	secondSizes := []int{500, 1000}
-	cache.set(secondSizes)
+	cache.set(secondSizes, nil, 0)
 
 	// Should still have first values
 	result := cache.get()
 	assert.Equal(t, firstSizes, result)
 }
 
+func TestPackSizesCache_PrimeOverwritesValid(t *testing.T) {
+	cache := newPackSizesCache(time.Minute)
+	cache.set([]int{100, 200}, nil, 1)
+
+	// Prime replaces the value even though the cache is still valid
+	cache.prime([]int{500, 1000}, map[int]float64{500: 4.5}, 2)
+	sizes, costs, version := cache.current()
+	assert.Equal(t, []int{500, 1000}, sizes)
+	assert.Equal(t, map[int]float64{500: 4.5}, costs)
+	assert.Equal(t, 2, version)
+
+	// A stale reader finishing after the prime must not overwrite it
+	cache.set([]int{100, 200}, nil, 1)
+	sizes, _, version = cache.current()
+	assert.Equal(t, []int{500, 1000}, sizes)
+	assert.Equal(t, 2, version)
+}
+
 func TestPackSizesCache_SetAfterExpiration(t *testing.T) {
 	cache := newPackSizesCache(50 * time.Millisecond)
 
 	// Set first values
 	firstSizes := []int{100, 200}
-	cache.set(firstSizes)
+	cache.set(firstSizes, nil, 0)
 
 	// Wait for expiration
 	time.Sleep(100 * time.Millisecond)
 
 	// Set new values
 	secondSizes := []int{500, 1000}
-	cache.set(secondSizes)
+	cache.set(secondSizes, nil, 0)
 
 	// Should have second values
 	result := cache.get()
@@ -171,7 +189,7 @@ func TestHandler_InvalidatePackSizesCache(t *testing.T) {
 	handler := NewHandler(nil, nil)
 
 	// Set some values in cache
-	handler.packSizesCache.set([]int{100, 200, 500})
+	handler.packSizesCache.set([]int{100, 200, 500}, nil, 0)
 
 	// Verify cache is set
 	assert.NotNil(t, handler.packSizesCache.get())
@@ -190,7 +208,7 @@ func TestPackSizesCache_ConcurrentAccess(t *testing.T) {
 	// Concurrent sets
 	go func() {
 		for i := 0; i < 100; i++ {
-			cache.set([]int{i, i * 2})
+			cache.set([]int{i, i * 2}, nil, 0)
 		}
 		done <- true
 	}()
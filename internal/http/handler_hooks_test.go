@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/hooks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minimumOrder rejects orders below its item count.
+type minimumOrder int
+
+func (m minimumOrder) ValidateRequest(_ context.Context, req *dto.CalculatePacksRequest) error {
+	if req.ItemsOrdered < int(m) {
+		return errors.New("orders must be at least 100 items")
+	}
+	return nil
+}
+
+// roundTotal rounds the shipped item count up to a multiple, or fails.
+type roundTotal struct {
+	to  int
+	err error
+}
+
+func (r roundTotal) ProcessResult(_ context.Context, _ *dto.CalculatePacksRequest, result *model.PackResult) error {
+	if r.err != nil {
+		return r.err
+	}
+	result.TotalItems = (result.TotalItems + r.to - 1) / r.to * r.to
+	return nil
+}
+
+func setupRouterWithHooks(register func(*hooks.Registry)) *gin.Engine {
+	registry := hooks.NewRegistry()
+	register(registry)
+	cfg := DefaultRouterConfig()
+	cfg.Hooks = registry
+	return NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+}
+
+func postHookedCalculate(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCalculatePacks_Hooks(t *testing.T) {
+	tests := []struct {
+		name           string
+		register       func(*hooks.Registry)
+		body           string
+		expectedStatus int
+		expectedTotal  int
+		expectedError  string
+	}{
+		{
+			name:           "validator rejects request",
+			register:       func(r *hooks.Registry) { r.Register("minimum", minimumOrder(100)) },
+			body:           `{"items_ordered": 50}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "orders must be at least 100 items",
+		},
+		{
+			name:           "validator accepts request",
+			register:       func(r *hooks.Registry) { r.Register("minimum", minimumOrder(100)) },
+			body:           `{"items_ordered": 251}`,
+			expectedStatus: http.StatusOK,
+			expectedTotal:  500,
+		},
+		{
+			name:           "processor adjusts result",
+			register:       func(r *hooks.Registry) { r.Register("round", roundTotal{to: 1000}) },
+			body:           `{"items_ordered": 251}`,
+			expectedStatus: http.StatusOK,
+			expectedTotal:  1000,
+		},
+		{
+			name:           "processor error fails calculation",
+			register:       func(r *hooks.Registry) { r.Register("round", roundTotal{err: errors.New("rounding service down")}) },
+			body:           `{"items_ordered": 251}`,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postHookedCalculate(setupRouterWithHooks(tt.register), tt.body)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				var resp dto.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedError, resp.Message)
+			}
+			if tt.expectedTotal > 0 {
+				var resp struct {
+					Data model.PackResult `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedTotal, resp.Data.TotalItems)
+			}
+		})
+	}
+}
+
+func TestCalculateBatch_Hooks(t *testing.T) {
+	router := setupRouterWithHooks(func(r *hooks.Registry) {
+		r.Register("minimum", minimumOrder(100))
+		r.Register("round", roundTotal{to: 1000})
+	})
+
+	w := postBatch(router, `{"items": [{"items_ordered": 50}, {"items_ordered": 251}]}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Results, 2)
+	assert.Equal(t, dto.ErrCodeInvalidRequest, resp.Data.Results[0].Error.Code)
+	assert.Equal(t, "orders must be at least 100 items", resp.Data.Results[0].Error.Message)
+	assert.Equal(t, 1000, resp.Data.Results[1].Result.TotalItems)
+}
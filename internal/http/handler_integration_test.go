@@ -320,7 +320,7 @@ func TestHandler_CalculatePacks_WithMongoDB_Integration(t *testing.T) {
 
 	t.Run("calculate with pack sizes from MongoDB", func(t *testing.T) {
 		repo := repository.NewPackSizesRepository(db)
-		_, createErr := repo.Create(ctx, []int{100, 200, 500}, "test")
+		_, createErr := repo.Create(ctx, []int{100, 200, 500}, nil, "test")
 		require.NoError(t, createErr)
 
 		body := []byte(`{"items_ordered": 150}`)
@@ -373,7 +373,7 @@ func TestHandler_CalculatePacks_WithMongoDB_Integration(t *testing.T) {
 
 	t.Run("calculate with custom pack sizes overrides MongoDB", func(t *testing.T) {
 		repo := repository.NewPackSizesRepository(db)
-		_, createErr := repo.Create(ctx, []int{100, 200}, "test")
+		_, createErr := repo.Create(ctx, []int{100, 200}, nil, "test")
 		require.NoError(t, createErr)
 
 		body := []byte(`{"items_ordered": 150, "pack_sizes": [50, 100, 200]}`)
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/presentation"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculatePacks_Presentation(t *testing.T) {
+	engine, err := presentation.NewEngine(presentation.Config{
+		Default: presentation.Rules{Order: presentation.OrderLargestFirst},
+		Tenants: map[string]presentation.Rules{
+			middleware.APIKeyFingerprint("acme-key"): {PalletItems: 1000, PalletRounding: presentation.RoundUp},
+		},
+	})
+	require.NoError(t, err)
+	cfg := DefaultRouterConfig()
+	cfg.Presentation = engine
+	cfg.APIKeys = map[string]bool{"acme-key": true}
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	calculate := func(apiKey string) model.PackResult {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader(`{"items_ordered": 12001}`))
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set(middleware.APIKeyHeader, apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Data model.PackResult `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	result := calculate("")
+	require.NotNil(t, result.Presentation)
+	assert.Equal(t, 5000, result.Presentation.Lines[0].Size, "the default rules put the largest size first")
+	assert.Nil(t, result.Presentation.Pallets)
+
+	result = calculate("acme-key")
+	require.NotNil(t, result.Presentation)
+	require.NotNil(t, result.Presentation.Pallets)
+	assert.Equal(t, float64((result.TotalItems+999)/1000), *result.Presentation.Pallets, "the tenant's rules replace the default ones")
+
+	w := postBatch(router, `{"items": [{"items_ordered": 12001}]}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var batch struct {
+		Data dto.BatchCalculateResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+	require.NotNil(t, batch.Data.Results[0].Result.Presentation, "batch items are presented too")
+}
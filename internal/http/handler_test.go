@@ -3,16 +3,25 @@ package http
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/mocks"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func init() {
@@ -127,6 +136,96 @@ func TestCalculatePacks(t *testing.T) {
 				assert.NoError(t, err)
 			},
 		},
+		{
+			name:           "max packs constraint",
+			body:           `{"items_ordered": 12001, "max_packs": 3}`,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp struct {
+					Data model.PackResult `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, 15000, resp.Data.TotalItems)
+				assert.Equal(t, []model.Pack{{Size: 5000, Quantity: 3}}, resp.Data.Packs)
+			},
+		},
+		{
+			name:           "unsatisfiable constraints",
+			body:           `{"items_ordered": 251, "max_overage_percent": 10}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp dto.ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, dto.ErrCodeUnprocessable, resp.Error)
+				assert.Equal(t, "No pack combination satisfies the requested constraints", resp.Message)
+			},
+		},
+		{
+			name:           "invalid max packs",
+			body:           `{"items_ordered": 251, "max_packs": 0}`,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "max_packs: must be a positive integer")
+			},
+		},
+		{
+			name:           "items above the maximum",
+			body:           `{"items_ordered": 2000000000}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp dto.ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, dto.ErrCodeUnprocessable, resp.Error)
+				assert.Equal(t, "items_ordered: must be at most 10000000", resp.Message)
+				assert.Equal(t, map[string]string{"max_items_ordered": "10000000"}, resp.Details)
+			},
+		},
+		{
+			name:           "pack size above the maximum",
+			body:           `{"items_ordered": 251, "pack_sizes": [23, 2000000000], "max_packs": 1}`,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "pack_sizes: at most 100 sizes, each at most 10000000")
+			},
+		},
+		{
+			name:           "negative overage",
+			body:           `{"items_ordered": 251, "max_overage_items": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "cheapest combination",
+			body:           `{"items_ordered": 251, "pack_sizes": [250, 500], "optimize": "min_cost", "pack_costs": {"250": 1, "500": 5}}`,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp struct {
+					Data model.PackResult `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, []model.Pack{{Size: 250, Quantity: 2}}, resp.Data.Packs)
+				if assert.NotNil(t, resp.Data.TotalCost) {
+					assert.Equal(t, 2.0, *resp.Data.TotalCost)
+				}
+			},
+		},
+		{
+			name:           "cheapest combination without every cost",
+			body:           `{"items_ordered": 251, "pack_sizes": [250, 500], "optimize": "min_cost", "pack_costs": {"250": 1}}`,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp dto.ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "Optimizing for cost needs a cost for every pack size", resp.Message)
+			},
+		},
+		{
+			name:           "unknown optimization mode",
+			body:           `{"items_ordered": 251, "optimize": "max_items"}`,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "optimize: must be min_items or min_cost")
+			},
+		},
 		{
 			name:           "request with zero pack sizes",
 			body:           `{"items_ordered": 100, "pack_sizes": [0, 0]}`,
@@ -286,6 +385,195 @@ func TestCalculatePacks_WithInvalidPackSizes(t *testing.T) {
 	}
 }
 
+func TestCalculatePacks_InputWarnings(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		setupMock     func(*mocks.MockPackCalculator)
+		expectedCodes []string
+	}{
+		{
+			name: "no warnings",
+			body: `{"items_ordered": 251, "pack_sizes": [250, 500]}`,
+			setupMock: func(mockCalc *mocks.MockPackCalculator) {
+				mockCalc.EXPECT().CalculateWithPackSizes(251, []int{250, 500}).Return(model.PackResult{OrderedItems: 251})
+			},
+			expectedCodes: nil,
+		},
+		{
+			name: "quantity is a pack size times 1000",
+			body: `{"items_ordered": 250000, "pack_sizes": [250, 500]}`,
+			setupMock: func(mockCalc *mocks.MockPackCalculator) {
+				mockCalc.EXPECT().CalculateWithPackSizes(250000, []int{250, 500}).Return(model.PackResult{OrderedItems: 250000})
+			},
+			expectedCodes: []string{model.WarningScaledPackSize},
+		},
+		{
+			name: "ignored pack sizes",
+			body: `{"items_ordered": 100, "pack_sizes": [0, -1]}`,
+			setupMock: func(mockCalc *mocks.MockPackCalculator) {
+				mockCalc.EXPECT().Calculate(100).Return(model.PackResult{OrderedItems: 100})
+			},
+			expectedCodes: []string{model.WarningIgnoredPackSizes},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockCalc := setupRouterWithMock(t)
+			tt.setupMock(mockCalc)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			// Warnings never reject the request
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var resp dto.SuccessResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			var codes []string
+			for _, warning := range resp.Warnings {
+				codes = append(codes, warning.Code)
+			}
+			assert.Equal(t, tt.expectedCodes, codes)
+		})
+	}
+}
+
+func TestCalculatePacks_NoInputWarningDetector(t *testing.T) {
+	mockCalc := mocks.NewMockPackCalculator(t)
+	mockCalc.EXPECT().Calculate(100).Return(model.PackResult{OrderedItems: 100})
+	handler := NewHandler(mockCalc, nil, WithInputWarningDetector(nil))
+	router := gin.New()
+	router.POST("/api/calculate", handler.CalculatePacks)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"items_ordered": 100, "pack_sizes": [0]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "warnings")
+}
+
+func TestCalculatePacks_WithPreset(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMocks     func(*mocks.MockPackCalculator, *mocks.MockPresetService)
+		expectedStatus int
+	}{
+		{
+			name: "uses preset pack sizes",
+			body: `{"items_ordered": 100, "preset": "warehouse-a"}`,
+			setupMocks: func(mockCalc *mocks.MockPackCalculator, mockPresets *mocks.MockPresetService) {
+				mockPresets.EXPECT().Get(mock.Anything, "", "warehouse-a").
+					Return(&model.Preset{Name: "warehouse-a", PackSizes: []int{23, 31, 53}}, nil)
+				mockCalc.EXPECT().CalculateWithPackSizes(100, []int{23, 31, 53}).Return(model.PackResult{OrderedItems: 100})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "uses preset constraints",
+			body: `{"items_ordered": 251, "preset": "warehouse-a", "max_overage_items": 10}`,
+			setupMocks: func(mockCalc *mocks.MockPackCalculator, mockPresets *mocks.MockPresetService) {
+				mockPresets.EXPECT().Get(mock.Anything, "", "warehouse-a").
+					Return(&model.Preset{Name: "warehouse-a", PackSizes: []int{250, 500}, Constraints: &model.PackConstraints{
+						MaxOverageItems: intPtr(250),
+						Optimize:        model.OptimizeMinCost,
+						PackCosts:       map[int]float64{250: 1, 500: 5},
+					}}, nil)
+				mockCalc.EXPECT().CalculateWithConstraints(251, []int{250, 500}, mock.MatchedBy(func(c model.PackConstraints) bool {
+					return *c.MaxOverageItems == 10 && c.Optimize == model.OptimizeMinCost && c.PackCosts[500] == 5
+				})).Return(model.PackResult{OrderedItems: 251}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "unknown preset",
+			body: `{"items_ordered": 100, "preset": "missing"}`,
+			setupMocks: func(_ *mocks.MockPackCalculator, mockPresets *mocks.MockPresetService) {
+				mockPresets.EXPECT().Get(mock.Anything, "", "missing").Return(nil, service.ErrPresetNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "preset lookup failure",
+			body: `{"items_ordered": 100, "preset": "warehouse-a"}`,
+			setupMocks: func(_ *mocks.MockPackCalculator, mockPresets *mocks.MockPresetService) {
+				mockPresets.EXPECT().Get(mock.Anything, "", "warehouse-a").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "preset combined with pack sizes",
+			body:           `{"items_ordered": 100, "preset": "warehouse-a", "pack_sizes": [23]}`,
+			setupMocks:     func(*mocks.MockPackCalculator, *mocks.MockPresetService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCalc := mocks.NewMockPackCalculator(t)
+			mockPresets := mocks.NewMockPresetService(t)
+			tt.setupMocks(mockCalc, mockPresets)
+
+			handler := NewHandler(mockCalc, nil, WithPresetService(mockPresets))
+			router := gin.New()
+			router.POST("/api/calculate", handler.CalculatePacks)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestCalculatePacks_PresetWithoutPresetService(t *testing.T) {
+	router, _ := setupRouterWithMock(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"items_ordered": 100, "preset": "warehouse-a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUserIDFromContext(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"unauthenticated", nil, ""},
+		{"jwt object id", id, id.Hex()},
+		{"zero object id", primitive.NilObjectID, ""},
+		{"unexpected type", "user-1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.value != nil {
+				c.Set("user_id", tt.value)
+			}
+			assert.Equal(t, tt.want, userIDFromContext(c))
+		})
+	}
+}
+
 func TestHealthEndpoints(t *testing.T) {
 	router := setupRouter()
 
@@ -334,3 +622,35 @@ func BenchmarkHandler(b *testing.B) {
 		router.ServeHTTP(w, req)
 	}
 }
+
+func TestCalculatePacks_Tracing(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	handler := NewHandler(service.NewPackCalculatorService(service.WithCache(10, time.Minute)), nil)
+	router := NewRouter(handler, NewHealthHandler(), DefaultRouterConfig())
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"items_ordered": 251}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	var calculations []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "pack.calculate" {
+			calculations = append(calculations, span)
+		}
+	}
+	require.Len(t, calculations, 2)
+	for i, cacheHit := range []bool{false, true} {
+		span := calculations[i]
+		assert.Contains(t, span.Attributes(), tracing.ItemsOrdered.Int(251))
+		assert.Contains(t, span.Attributes(), tracing.CacheHit.Bool(cacheHit))
+		// The calculation is a child of the request's span
+		assert.True(t, span.Parent().IsValid())
+	}
+}
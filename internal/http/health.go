@@ -1,34 +1,119 @@
 package http
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/middleware"
 )
 
+// Readiness statuses.
+const (
+	// ReadinessOK means every dependency is healthy.
+	ReadinessOK = "ok"
+	// ReadinessDegraded means only optional dependencies are failing; the
+	// service still accepts traffic.
+	ReadinessDegraded = "degraded"
+	// ReadinessUnavailable means a critical dependency is failing.
+	ReadinessUnavailable = "unavailable"
+	// ReadinessDraining means the instance was told to drain before
+	// termination, whatever its dependencies' health.
+	ReadinessDraining = "draining"
+)
+
+// defaultHealthCheckTimeout bounds each readiness check.
+const defaultHealthCheckTimeout = 2 * time.Second
+
 // HealthChecker defines the interface for health check operations.
 type HealthChecker interface {
-	Check() error
+	Check(ctx context.Context) error
+}
+
+// HealthCheckFunc adapts a function to HealthChecker.
+type HealthCheckFunc func(ctx context.Context) error
+
+// Check calls f.
+func (f HealthCheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// dependencyCheck is a registered readiness check.
+type dependencyCheck struct {
+	name     string
+	checker  HealthChecker
+	critical bool
+}
+
+// CheckResult is the outcome of one readiness check.
+type CheckResult struct {
+	Status     string `json:"status"`
+	Critical   bool   `json:"critical"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// ReadinessResponse is the body of the readiness probe.
+type ReadinessResponse struct {
+	Status   string                 `json:"status"`
+	Degraded bool                   `json:"degraded"`
+	Checks   map[string]CheckResult `json:"checks"`
+	// DrainingSince is when the instance started draining.
+	DrainingSince *time.Time `json:"draining_since,omitempty"`
 }
 
 // HealthHandler handles health check endpoints.
 type HealthHandler struct {
-	checkers        map[string]HealthChecker
-	circuitBreakers map[string]*circuitbreaker.CircuitBreaker
+	mu      sync.RWMutex
+	checks  []dependencyCheck
+	timeout time.Duration
+	drainer *middleware.Drainer
 }
 
 // NewHealthHandler creates a new HealthHandler.
 func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{
-		checkers:        make(map[string]HealthChecker),
-		circuitBreakers: make(map[string]*circuitbreaker.CircuitBreaker),
-	}
+	return &HealthHandler{timeout: defaultHealthCheckTimeout}
+}
+
+// RegisterChecker registers a dependency check. A failing critical check makes
+// the service unavailable; a failing optional one only marks it degraded.
+func (h *HealthHandler) RegisterChecker(name string, checker HealthChecker, critical bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, dependencyCheck{name: name, checker: checker, critical: critical})
 }
 
-// RegisterCircuitBreaker registers a circuit breaker for health monitoring.
+// SetDrainer makes readiness fail while drainer is draining.
+func (h *HealthHandler) SetDrainer(drainer *middleware.Drainer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drainer = drainer
+}
+
+// RegisterCircuitBreaker registers a critical circuit breaker for health
+// monitoring, reported as "<name>_circuit".
 func (h *HealthHandler) RegisterCircuitBreaker(name string, cb *circuitbreaker.CircuitBreaker) {
-	h.circuitBreakers[name] = cb
+	h.RegisterChecker(name+"_circuit", circuitBreakerCheck(cb), true)
+}
+
+// RegisterOptionalCircuitBreaker registers a circuit breaker whose open state
+// only marks the service degraded.
+func (h *HealthHandler) RegisterOptionalCircuitBreaker(name string, cb *circuitbreaker.CircuitBreaker) {
+	h.RegisterChecker(name+"_circuit", circuitBreakerCheck(cb), false)
+}
+
+// circuitBreakerCheck fails while cb is open or half-open.
+func circuitBreakerCheck(cb *circuitbreaker.CircuitBreaker) HealthCheckFunc {
+	return func(context.Context) error {
+		if stats := cb.GetStats(); !stats.IsHealthy {
+			return fmt.Errorf("circuit breaker is %s", stats.State)
+		}
+		return nil
+	}
 }
 
 // Register registers health endpoints on the router.
@@ -53,43 +138,89 @@ func (h *HealthHandler) Liveness(c *gin.Context) {
 
 // Readiness handles the readiness probe endpoint.
 // @Summary     Readiness probe
-// @Description Returns OK if all dependencies are healthy and the service is ready to accept traffic. Used by load balancers and orchestration platforms.
+// @Description Checks every registered dependency concurrently. Returns 503 when a critical dependency (such as MongoDB) is down or the instance is draining, and 200 with status "degraded" when only optional ones (such as the logs database or the Redis cache) fail. Used by load balancers and orchestration platforms.
 // @Tags        Health
 // @Produce     json
-// @Success     200 {object} map[string]interface{} "Service is ready"
-// @Failure     503 {object} map[string]interface{} "Service is not ready"
-// @ExampleResponse 200 {"status": "ok", "checks": {"service": "ok"}}
-// @ExampleResponse 503 {"status": "degraded", "checks": {"database": "connection failed"}}
+// @Success     200 {object} ReadinessResponse "Service is ready"
+// @Failure     503 {object} ReadinessResponse "Service is not ready"
+// @ExampleResponse 200 {"status": "degraded", "degraded": true, "checks": {"mongodb": {"status": "ok", "critical": true, "duration_ms": 1}, "mongodb_logs_circuit": {"status": "failing", "critical": false, "error": "circuit breaker is open", "duration_ms": 0}}}
+// @ExampleResponse 503 {"status": "unavailable", "degraded": false, "checks": {"mongodb": {"status": "failing", "critical": true, "error": "server selection error", "duration_ms": 2000}}}
 // @Router      /readyz [get]
 func (h *HealthHandler) Readiness(c *gin.Context) {
+	resp := h.check(c.Request.Context())
 	status := http.StatusOK
-	checks := make(map[string]interface{})
-
-	// Check registered health checkers
-	for name, checker := range h.checkers {
-		if err := checker.Check(); err != nil {
-			checks[name] = err.Error()
-			status = http.StatusServiceUnavailable
-		} else {
-			checks[name] = "ok"
-		}
+	if resp.Status == ReadinessUnavailable || resp.Status == ReadinessDraining {
+		status = http.StatusServiceUnavailable
 	}
+	c.JSON(status, resp)
+}
 
-	// Check circuit breakers
-	for name, cb := range h.circuitBreakers {
-		stats := cb.GetStats()
-		checks[name+"_circuit"] = stats.State
-		if !stats.IsHealthy {
-			status = http.StatusServiceUnavailable
-		}
+// check runs every registered check concurrently, each bounded by the
+// handler's timeout.
+func (h *HealthHandler) check(ctx context.Context) ReadinessResponse {
+	h.mu.RLock()
+	checks := h.checks
+	drainer := h.drainer
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, check)
+		}()
 	}
+	wg.Wait()
 
+	resp := ReadinessResponse{Status: ReadinessOK, Checks: make(map[string]CheckResult, len(checks))}
+	for i, check := range checks {
+		resp.Checks[check.name] = results[i]
+		if results[i].Status == "ok" {
+			continue
+		}
+		if check.critical {
+			resp.Status = ReadinessUnavailable
+		} else if resp.Status == ReadinessOK {
+			resp.Status = ReadinessDegraded
+		}
+	}
+	resp.Degraded = resp.Status == ReadinessDegraded
 	if len(checks) == 0 {
-		checks["service"] = "ok"
+		resp.Checks["service"] = CheckResult{Status: "ok", Critical: true}
+	}
+	if drainer != nil {
+		if draining, since := drainer.Draining(); draining {
+			resp.Status = ReadinessDraining
+			resp.Degraded = false
+			resp.DrainingSince = &since
+		}
 	}
+	return resp
+}
+
+// run runs one check. A check that ignores its context still fails once
+// the timeout passes; it is left to finish in the background.
+func (h *HealthHandler) run(ctx context.Context, check dependencyCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
 
-	c.JSON(status, gin.H{
-		"status": map[bool]string{true: "ok", false: "degraded"}[status == http.StatusOK],
-		"checks": checks,
-	})
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check.checker.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: "ok", Critical: check.critical, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = "failing"
+		result.Error = err.Error()
+	}
+	return result
 }
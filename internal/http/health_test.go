@@ -1,12 +1,17 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/guttosm/pack-service/internal/circuitbreaker"
 )
 
@@ -88,3 +93,103 @@ func TestHealthHandler_RegisterCircuitBreaker(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthHandler_ReadinessDependencies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	healthy := HealthCheckFunc(func(context.Context) error { return nil })
+	down := HealthCheckFunc(func(context.Context) error { return errors.New("connection refused") })
+	openBreaker := func() *circuitbreaker.CircuitBreaker {
+		cb := circuitbreaker.New(circuitbreaker.Config{FailureThreshold: 1, SuccessThreshold: 1, Timeout: time.Minute})
+		_ = cb.Execute(context.Background(), func() error { return errors.New("boom") })
+		return cb
+	}
+
+	tests := []struct {
+		name           string
+		setup          func(h *HealthHandler)
+		expectedStatus int
+		expectedBody   string
+		degraded       bool
+		failing        string
+	}{
+		{
+			name: "all dependencies healthy",
+			setup: func(h *HealthHandler) {
+				h.RegisterChecker("mongodb", healthy, true)
+				h.RegisterChecker("cache", healthy, false)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   ReadinessOK,
+		},
+		{
+			name: "critical dependency down",
+			setup: func(h *HealthHandler) {
+				h.RegisterChecker("mongodb", down, true)
+				h.RegisterChecker("cache", down, false)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   ReadinessUnavailable,
+			failing:        "mongodb",
+		},
+		{
+			name: "optional dependency down",
+			setup: func(h *HealthHandler) {
+				h.RegisterChecker("mongodb", healthy, true)
+				h.RegisterOptionalCircuitBreaker("mongodb_logs", openBreaker())
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   ReadinessDegraded,
+			degraded:       true,
+			failing:        "mongodb_logs_circuit",
+		},
+		{
+			name: "critical circuit open",
+			setup: func(h *HealthHandler) {
+				h.RegisterCircuitBreaker("mongodb_pack_sizes", openBreaker())
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   ReadinessUnavailable,
+			failing:        "mongodb_pack_sizes_circuit",
+		},
+		{
+			name: "check times out",
+			setup: func(h *HealthHandler) {
+				h.timeout = 10 * time.Millisecond
+				h.RegisterChecker("mongodb", HealthCheckFunc(func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}), true)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   ReadinessUnavailable,
+			failing:        "mongodb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			handler := NewHealthHandler()
+			tt.setup(handler)
+			handler.Register(router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var resp ReadinessResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedBody, resp.Status)
+			assert.Equal(t, tt.degraded, resp.Degraded)
+			for name, check := range resp.Checks {
+				if name == tt.failing {
+					assert.Equal(t, "failing", check.Status)
+					assert.NotEmpty(t, check.Error)
+				} else if tt.failing != "" && check.Critical {
+					assert.Equal(t, "ok", check.Status, name)
+				}
+			}
+		})
+	}
+}
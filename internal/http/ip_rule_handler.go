@@ -0,0 +1,140 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// IPRuleHandler serves and changes the IP allow and deny rules.
+type IPRuleHandler struct {
+	rules *service.IPRuleService
+}
+
+// NewIPRuleHandler creates a new IPRuleHandler instance.
+func NewIPRuleHandler(rules *service.IPRuleService) *IPRuleHandler {
+	return &IPRuleHandler{rules: rules}
+}
+
+// ListIPRules handles GET /api/admin/ip-rules requests.
+//
+// @Summary      List IP rules
+// @Description  Returns the IP allow and deny rules: those from the configuration, marked configured, followed by those added at runtime, newest first
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=[]model.IPRule} "IP rules"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/ip-rules [get]
+func (h *IPRuleHandler) ListIPRules(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	rules, err := h.rules.List(c.Request.Context())
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	builder.SuccessOK(rules)
+}
+
+// CreateIPRule handles POST /api/admin/ip-rules requests.
+//
+// @Summary      Add IP rule
+// @Description  Allows or denies requests from a CIDR range, for instance to block an abusive range during an incident. The most specific matching rule decides, deny winning between ranges of the same size; once an allow rule exists, requests matching no rule are rejected. Blocked requests get 403, except health checks and metrics, and are audited. The rule applies at once on the instance that receives the request and on the others at their next reload.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        request body dto.IPRuleRequest true "IP rule"
+// @Success      201 {object} dto.SuccessResponse{data=model.IPRule} "Created IP rule"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid CIDR range, action or expiry"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      409 {object} dto.ErrorResponse "A rule for this range already exists"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/ip-rules [post]
+func (h *IPRuleHandler) CreateIPRule(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.IPRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+
+	rule := &model.IPRule{
+		CIDR:      req.CIDR,
+		Action:    req.Action,
+		Reason:    req.Reason,
+		CreatedBy: userIDFromContext(c),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := h.rules.Add(c.Request.Context(), rule); err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	log.Warn().Str("cidr", rule.CIDR).Str("action", rule.Action).Str("reason", rule.Reason).Msg("IP rule added")
+	h.audit(c, "add_ip_rule", "IP rule added", rule)
+	builder.SuccessCreated(rule)
+}
+
+// DeleteIPRule handles DELETE /api/admin/ip-rules/:id requests.
+//
+// @Summary      Delete IP rule
+// @Description  Deletes an IP rule added at runtime. Rules from the configuration cannot be deleted.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "IP rule ID"
+// @Success      204 "IP rule deleted"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      404 {object} dto.ErrorResponse "IP rule not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/ip-rules/{id} [delete]
+func (h *IPRuleHandler) DeleteIPRule(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	id := c.Param("id")
+	if err := h.rules.Delete(c.Request.Context(), id); err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	log.Warn().Str("rule_id", id).Msg("IP rule deleted")
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, "delete_ip_rule", "IP rule deleted", map[string]interface{}{"rule_id": id})
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *IPRuleHandler) audit(c *gin.Context, action, message string, rule *model.IPRule) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			fields := map[string]interface{}{
+				"rule_id": rule.ID.Hex(),
+				"cidr":    rule.CIDR,
+				"action":  rule.Action,
+				"reason":  rule.Reason,
+			}
+			if rule.ExpiresAt != nil {
+				fields["expires_at"] = rule.ExpiresAt
+			}
+			middleware.AuditLog(ls, c, action, message, fields)
+		}
+	}
+}
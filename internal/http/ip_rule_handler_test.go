@@ -0,0 +1,131 @@
+//go:build !integration
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestIPRuleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := mocks.NewMockIPRuleRepositoryInterface(t)
+	rules, err := service.NewIPRuleService(repo, nil, []string{"192.0.2.0/24"})
+	require.NoError(t, err)
+	cfg := DefaultRouterConfig()
+	cfg.IPRules = rules
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	// Admin routes need JWT auth; mount the handler directly
+	ipRuleHandler := NewIPRuleHandler(rules)
+	router.GET("/api/admin/ip-rules", ipRuleHandler.ListIPRules)
+	router.POST("/api/admin/ip-rules", ipRuleHandler.CreateIPRule)
+	router.DELETE("/api/admin/ip-rules/:id", ipRuleHandler.DeleteIPRule)
+
+	do := func(method, path, body, ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":40000"
+		router.ServeHTTP(w, req)
+		return w
+	}
+	const admin = "198.51.100.1"
+
+	t.Run("configured rules are enforced and listed", func(t *testing.T) {
+		repo.EXPECT().List(mock.Anything).Return(nil, nil).Once()
+
+		assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`, "192.0.2.7").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/healthz", "", "192.0.2.7").Code)
+
+		w := do(http.MethodGet, "/api/admin/ip-rules", "", admin)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []model.IPRule `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "192.0.2.0/24", resp.Data[0].CIDR)
+		assert.True(t, resp.Data[0].Configured)
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/admin/ip-rules", `{"cidr":"203.0.113.0/24","action":"block"}`, admin).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/admin/ip-rules", `{"cidr":"203.0.113.0/33","action":"deny"}`, admin).Code)
+	})
+
+	t.Run("adds a rule and enforces it at once", func(t *testing.T) {
+		stored := &model.IPRule{ID: primitive.NewObjectID(), CIDR: "203.0.113.0/24", Action: model.IPRuleDeny}
+		repo.EXPECT().Create(mock.Anything, mock.MatchedBy(func(r *model.IPRule) bool {
+			return r.CIDR == "203.0.113.0/24" && r.Reason == "credential stuffing"
+		})).Run(func(_ context.Context, r *model.IPRule) { r.ID = stored.ID }).Return(nil).Once()
+		repo.EXPECT().List(mock.Anything).Return([]*model.IPRule{stored}, nil).Once()
+
+		w := do(http.MethodPost, "/api/admin/ip-rules", `{"cidr":"203.0.113.9/24","action":"deny","reason":"credential stuffing"}`, admin)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), stored.ID.Hex())
+		assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`, "203.0.113.7").Code)
+	})
+
+	t.Run("duplicate range", func(t *testing.T) {
+		repo.EXPECT().Create(mock.Anything, mock.Anything).Return(repository.ErrIPRuleExists).Once()
+		assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/api/admin/ip-rules", `{"cidr":"10.0.0.0/8","action":"deny"}`, admin).Code)
+	})
+
+	t.Run("deletes a rule", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		repo.EXPECT().Delete(mock.Anything, id).Return(true, nil).Once()
+		repo.EXPECT().List(mock.Anything).Return(nil, nil).Once()
+		assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/admin/ip-rules/"+id.Hex(), "", admin).Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/calculate", `{"items_ordered": 251}`, "203.0.113.7").Code)
+
+		missing := primitive.NewObjectID()
+		repo.EXPECT().Delete(mock.Anything, missing).Return(false, nil).Once()
+		assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/admin/ip-rules/"+missing.Hex(), "", admin).Code)
+	})
+}
+
+func TestIPFilter_TrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rules, err := service.NewIPRuleService(nil, nil, []string{"192.0.2.0/24"})
+	require.NoError(t, err)
+	cfg := DefaultRouterConfig()
+	cfg.IPRules = rules
+	cfg.TrustedProxies = []string{"10.0.0.0/8"}
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	do := func(peer, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader(`{"items_ordered": 251}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = peer + ":40000"
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A denied client cannot pass itself off as another by sending the header
+	assert.Equal(t, http.StatusForbidden, do("192.0.2.7", "198.51.100.1"))
+	// An allowed client cannot get another blocked either
+	assert.Equal(t, http.StatusOK, do("198.51.100.1", "192.0.2.7"))
+	// Behind a trusted proxy, the client is the last hop before it
+	assert.Equal(t, http.StatusForbidden, do("10.1.2.3", "198.51.100.1, 192.0.2.7"))
+	assert.Equal(t, http.StatusOK, do("10.1.2.3", "192.0.2.7, 198.51.100.1"))
+}
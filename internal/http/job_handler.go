@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// SubmitCalculationJob handles POST /api/jobs/calculate requests.
+//
+// @Summary      Queue a batch calculation
+// @Description  Queues up to 10000 orders to be calculated in the background and returns the job. Poll GET /api/jobs/{id} until its status is completed or failed. Results are kept for the configured retention, then the job is deleted.
+// @Tags         Packs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.BatchCalculateRequest true "Orders"
+// @Success      202 {object} dto.SuccessResponse{data=dto.CalculationJobResponse} "Job queued"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid body or too many items"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - insufficient permissions"
+// @Failure      429 {object} dto.ErrorResponse "Too many requests - rate limit exceeded"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Failure      503 {object} dto.ErrorResponse "Too many unfinished jobs"
+// @Security     BearerAuth
+// @Router       /api/jobs/calculate [post]
+func (h *Handler) SubmitCalculationJob(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.BatchCalculateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if len(req.Items) > dto.MaxBatchItems {
+		builder.ErrorWithMessage(http.StatusBadRequest,
+			fmt.Sprintf(i18n.Message(c, i18n.ErrKeyValidationBatchItems), dto.MaxBatchItems), nil)
+		return
+	}
+
+	job, err := h.calculationJobs.Submit(c.Request.Context(), &model.CalculationJob{
+		Owner:     userIDFromContext(c),
+		RequestID: middleware.GetRequestID(c),
+		Tenant:    i18n.GetTenant(c),
+		Locale:    i18n.GetLocale(c),
+	}, req.Items)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.auditJob(c, job)
+	builder.SuccessAccepted(job)
+}
+
+// GetCalculationJob handles GET /api/jobs/{id} requests.
+//
+// @Summary      Get a calculation job
+// @Description  Returns a job queued with POST /api/jobs/calculate: its status, counts and, once completed, the results in request order. Only the caller that queued the job can read it.
+// @Tags         Packs
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        id path string true "Job ID"
+// @Success      200 {object} dto.SuccessResponse{data=dto.CalculationJobResponse} "Job"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - insufficient permissions"
+// @Failure      404 {object} dto.ErrorResponse "Job not found or expired"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/jobs/{id} [get]
+func (h *Handler) GetCalculationJob(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	job, err := h.calculationJobs.Get(c.Request.Context(), c.Param("id"), userIDFromContext(c))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+	builder.SuccessOK(job)
+}
+
+// processCalculationJob calculates a job's items like a batch submitted by
+// the job's owner, on the batch pool when there is one.
+func (h *Handler) processCalculationJob(ctx context.Context, job *model.CalculationJob, items []dto.CalculatePacksRequest) []dto.BatchItemResult {
+	record := model.Calculation{
+		RequestID: job.RequestID,
+		UserID:    job.Owner,
+		Source:    model.CalculationSourceJob,
+	}
+	batch := h.prepareBatch(ctx, job.Owner, job.Tenant, job.Locale, record, items)
+
+	results := make([]dto.BatchItemResult, 0, len(items))
+	batch.run(items, func(result dto.BatchItemResult) bool {
+		results = append(results, result)
+		return ctx.Err() == nil
+	})
+	return results
+}
+
+// auditJob records the submission of a calculation job.
+func (h *Handler) auditJob(c *gin.Context, job *dto.CalculationJobResponse) {
+	loggingService, exists := c.Get("logging_service")
+	if !exists {
+		return
+	}
+	ls, ok := loggingService.(service.LoggingService)
+	if !ok {
+		return
+	}
+
+	middleware.AuditLog(ls, c, model.ActionCalculateJob, "Calculation job queued", map[string]interface{}{
+		"job_id":      job.JobID,
+		"batch_items": job.Items,
+	})
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJobsRouter(t *testing.T) (*gin.Engine, *mocks.MockCalculationJobRepositoryInterface) {
+	repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+	// The workers start with the router; there is nothing for them to claim
+	repo.EXPECT().ClaimNext(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	jobs := service.NewCalculationJobService(repo, service.CalculationJobConfig{PollInterval: time.Hour})
+	t.Cleanup(func() { jobs.Shutdown(context.Background()) })
+
+	cfg := DefaultRouterConfig()
+	cfg.CalculationJobs = jobs
+	handler := NewHandler(service.NewPackCalculatorService(), nil)
+	return NewRouter(handler, NewHealthHandler(), cfg), repo
+}
+
+func TestSubmitCalculationJob(t *testing.T) {
+	t.Run("queues the job", func(t *testing.T) {
+		router, repo := setupJobsRouter(t)
+		repo.EXPECT().CountUnfinished(mock.Anything).Return(0, nil)
+		repo.EXPECT().Create(mock.Anything, mock.MatchedBy(func(job *model.CalculationJob) bool {
+			return job.Items == 4 && job.Locale == "pt" && job.RequestID != ""
+		})).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/calculate", bytes.NewBufferString(mixedBatch))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "pt-BR")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		var resp struct {
+			Data dto.CalculationJobResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.NotEmpty(t, resp.Data.JobID)
+		assert.Equal(t, model.JobStatusQueued, resp.Data.Status)
+		assert.Equal(t, 4, resp.Data.Items)
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		router, repo := setupJobsRouter(t)
+		repo.EXPECT().CountUnfinished(mock.Anything).Return(service.DefaultMaxUnfinishedJobs, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/calculate", bytes.NewBufferString(mixedBatch))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		router, _ := setupJobsRouter(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/calculate", bytes.NewBufferString(`{"items": []}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not registered without a database", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/calculate", bytes.NewBufferString(mixedBatch))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		setupRouter().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetCalculationJob(t *testing.T) {
+	router, repo := setupJobsRouter(t)
+
+	results, err := json.Marshal([]dto.BatchItemResult{{Index: 0, Result: &model.PackResult{OrderedItems: 251, TotalItems: 500}}})
+	require.NoError(t, err)
+	job := &model.CalculationJob{
+		ID:        uuid.NewString(),
+		Status:    model.JobStatusCompleted,
+		Items:     1,
+		Succeeded: 1,
+		Results:   results,
+	}
+	repo.EXPECT().Get(mock.Anything, job.ID).Return(job, nil)
+
+	t.Run("completed job", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.ID, nil))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Data dto.CalculationJobResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, model.JobStatusCompleted, resp.Data.Status)
+		require.Len(t, resp.Data.Results, 1)
+		assert.Equal(t, 500, resp.Data.Results[0].Result.TotalItems)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		repo.EXPECT().Get(mock.Anything, "nope").Return(nil, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/jobs/nope", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), dto.ErrCodeNotFound)
+	})
+}
+
+func TestProcessCalculationJob(t *testing.T) {
+	history := mocks.NewMockCalculationHistoryService(t)
+	history.EXPECT().Record(mock.MatchedBy(func(calc *model.Calculation) bool {
+		return calc.Source == model.CalculationSourceJob && calc.UserID == "user-1" && calc.RequestID == "req-1"
+	})).Times(2)
+	handler := NewHandler(service.NewPackCalculatorService(), nil, WithCalculationHistory(history))
+
+	var req dto.BatchCalculateRequest
+	require.NoError(t, json.Unmarshal([]byte(mixedBatch), &req))
+	job := &model.CalculationJob{Owner: "user-1", RequestID: "req-1", Locale: "nl"}
+
+	results := handler.processCalculationJob(context.Background(), job, req.Items)
+	require.Len(t, results, 4)
+	assert.Equal(t, 500, results[0].Result.TotalItems)
+	require.NotNil(t, results[1].Error)
+	messageKey := validationMessageKey(req.Items[1].Validate())
+	assert.Equal(t, i18n.GetTranslator().Translate(messageKey, "nl"), results[1].Error.Message)
+	assert.Equal(t, 12250, results[2].Result.TotalItems)
+	require.NotNil(t, results[3].Error)
+	assert.Equal(t, dto.ErrCodeInvalidRequest, results[3].Error.Code)
+}
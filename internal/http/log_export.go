@@ -0,0 +1,222 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"github.com/rs/zerolog/log"
+)
+
+// MIMECSV is the content type of CSV exports.
+const MIMECSV = "text/csv"
+
+// logExportFlushRows is how many exported entries are written between flushes.
+const logExportFlushRows = 100
+
+// logExportColumns are the CSV columns of a log export. Fields is JSON-encoded.
+var logExportColumns = []string{
+	"id", "timestamp", "level", "action_type", "user_id", "user_email", "message",
+	"request_id", "method", "path", "status_code", "duration_ms", "ip", "user_agent",
+	"error", "fields",
+}
+
+// ExportLogs handles GET /api/admin/logs/export requests.
+//
+// @Summary      Export audit logs
+// @Description  Streams every audit log entry matching the filter, oldest first, with timestamps in the configured display timezone (UTC by default). The format follows the Accept header: application/x-ndjson (default) writes one JSON entry per line, text/csv one row per entry with the fields column JSON-encoded. Entries are read from MongoDB in batches, so exports of any size use bounded memory. Requires the logs:read permission.
+// @Tags         Admin
+// @Produce      application/x-ndjson
+// @Produce      text/csv
+// @Param        Authorization header string true "Bearer token"
+// @Param        action query string false "Only entries of this action type, e.g. login"
+// @Param        request_id query string false "Only entries of this request"
+// @Param        correlation_id query string false "Only entries of the requests with this correlation ID"
+// @Param        level query string false "Only entries of this level, e.g. error"
+// @Param        user query string false "Only entries of this user ID or email"
+// @Param        method query string false "Only entries of this HTTP method"
+// @Param        path query string false "Only entries whose path contains this text (case-insensitive)"
+// @Param        from query string false "Only entries at or after this time (RFC 3339)"
+// @Param        to query string false "Only entries at or before this time (RFC 3339)"
+// @Success      200 {string} string "Audit log entries"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid query parameter"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing logs:read permission"
+// @Failure      406 {object} dto.ErrorResponse "Not acceptable - Accept allows neither NDJSON nor CSV"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/logs/export [get]
+func (h *LogHandler) ExportLogs(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	format := c.NegotiateFormat(MIMENDJSON, MIMECSV)
+	if format == "" {
+		builder.Error(http.StatusNotAcceptable, i18n.ErrKeyNotAcceptable, nil)
+		return
+	}
+
+	opts, err := parseLogFilter(c)
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+	opts.ActionType = c.Query("action")
+	opts.AuditOnly = true
+
+	export := &logExport{c: c, format: format, location: h.location}
+	err = h.logs.ExportLogs(c.Request.Context(), opts, export.write)
+	if err == nil {
+		err = export.finish()
+	}
+	if err != nil && !export.started {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	if err != nil {
+		// The status is already sent; the client sees the export end early
+		log.Error().Err(err).Str("request_id", middleware.GetRequestID(c)).
+			Int("rows", export.rows).Msg("Audit log export stopped early")
+	}
+
+	middleware.AuditLog(h.logs, c, "export_logs", "Audit logs exported", map[string]interface{}{
+		"format":   format,
+		"rows":     export.rows,
+		"complete": err == nil,
+		"filter":   c.Request.URL.RawQuery,
+	})
+}
+
+// logExport writes exported log entries to the response as they arrive.
+// The response is only started by the first entry, so a query that fails
+// outright still gets an error response.
+type logExport struct {
+	c        *gin.Context
+	format   string
+	location *time.Location
+
+	started bool
+	rows    int
+	csv     *csv.Writer
+	json    *json.Encoder
+	rc      *http.ResponseController
+}
+
+// start writes the headers, and the column names of a CSV export.
+func (e *logExport) start() error {
+	e.started = true
+
+	w := e.c.Writer
+	extension := "ndjson"
+	if e.format == MIMECSV {
+		extension = "csv"
+	}
+	w.Header().Set("Content-Type", e.format)
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-logs.`+extension+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	e.rc = http.NewResponseController(w)
+	if e.format == MIMECSV {
+		e.csv = csv.NewWriter(w)
+		return e.csv.Write(logExportColumns)
+	}
+	e.json = json.NewEncoder(w)
+	return nil
+}
+
+// write writes one entry, flushing every logExportFlushRows entries.
+func (e *logExport) write(entry model.LogEntry) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	entry.Timestamp = timeutil.In(entry.Timestamp, e.location)
+	var err error
+	if e.csv != nil {
+		err = e.writeCSV(entry)
+	} else {
+		err = e.json.Encode(entry)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%logExportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// writeCSV writes entry as a CSV row in the order of logExportColumns.
+func (e *logExport) writeCSV(entry model.LogEntry) error {
+	var fields string
+	if len(entry.Fields) > 0 {
+		encoded, err := json.Marshal(entry.Fields)
+		if err != nil {
+			return err
+		}
+		fields = string(encoded)
+	}
+	return e.csv.Write([]string{
+		entry.ID.Hex(),
+		entry.Timestamp.Format(time.RFC3339Nano),
+		entry.Level,
+		entry.ActionType,
+		entry.UserID,
+		entry.UserEmail,
+		entry.Message,
+		entry.RequestID,
+		entry.Method,
+		entry.Path,
+		optionalInt(int64(entry.StatusCode)),
+		optionalInt(entry.Duration),
+		entry.IP,
+		entry.UserAgent,
+		entry.Error,
+		fields,
+	})
+}
+
+// flush sends the buffered entries to the client, renewing the write
+// deadline so long exports are not cut off by the server's WriteTimeout.
+func (e *logExport) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	// Best effort: wrappers that do not expose the connection keep the server deadline
+	_ = e.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	e.c.Writer.Flush()
+	return nil
+}
+
+// finish starts an export that matched nothing and flushes the rest.
+func (e *logExport) finish() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+// optionalInt formats n, leaving zero, which log entries omit, empty.
+func optionalInt(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// exportEntries returns a mock ExportLogs that passes entries to fn, then
+// returns err.
+func exportEntries(entries []model.LogEntry, err error) func(context.Context, model.LogQueryOptions, func(model.LogEntry) error) error {
+	return func(_ context.Context, _ model.LogQueryOptions, fn func(model.LogEntry) error) error {
+		for _, entry := range entries {
+			if fnErr := fn(entry); fnErr != nil {
+				return fnErr
+			}
+		}
+		return err
+	}
+}
+
+func TestLogHandler_ExportLogs(t *testing.T) {
+	entries := []model.LogEntry{
+		{
+			ID: primitive.NewObjectID(), Timestamp: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Level: "info",
+			Message: "User logged in", ActionType: "login", UserEmail: "ops@example.com", StatusCode: 200,
+			Fields: map[string]interface{}{"method": "password"},
+		},
+		{ID: primitive.NewObjectID(), Timestamp: time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC), Level: "info", Message: "User logged out", ActionType: "logout"},
+	}
+
+	serve := func(mockLogs *mocks.MockLoggingService, query, accept string) *httptest.ResponseRecorder {
+		mockLogs.EXPECT().CreateLog(mock.Anything, mock.Anything).Return(nil).Maybe()
+		router := gin.New()
+		router.GET("/api/admin/logs/export", NewLogHandler(mockLogs, nil, nil).ExportLogs)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/logs/export"+query, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("streams NDJSON by default", func(t *testing.T) {
+		mockLogs := mocks.NewMockLoggingService(t)
+		mockLogs.EXPECT().ExportLogs(mock.Anything, model.LogQueryOptions{ActionType: "login", User: "ops@example.com", AuditOnly: true}, mock.Anything).
+			RunAndReturn(exportEntries(entries, nil))
+
+		w := serve(mockLogs, "?action=login&user=ops@example.com", "")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, MIMENDJSON, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "audit-logs.ndjson")
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var first model.LogEntry
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, "login", first.ActionType)
+	})
+
+	t.Run("writes CSV when accepted", func(t *testing.T) {
+		mockLogs := mocks.NewMockLoggingService(t)
+		mockLogs.EXPECT().ExportLogs(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(exportEntries(entries, nil))
+
+		w := serve(mockLogs, "", "text/csv")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, MIMECSV, w.Header().Get("Content-Type"))
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, logExportColumns, rows[0])
+		assert.Equal(t, []string{
+			entries[0].ID.Hex(), "2026-03-01T10:00:00Z", "info", "login", "", "ops@example.com", "User logged in",
+			"", "", "", "200", "", "", "", "", `{"method":"password"}`,
+		}, rows[1])
+		assert.Equal(t, "", rows[2][len(rows[2])-1])
+	})
+
+	t.Run("writes the CSV header when nothing matches", func(t *testing.T) {
+		mockLogs := mocks.NewMockLoggingService(t)
+		mockLogs.EXPECT().ExportLogs(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(exportEntries(nil, nil))
+
+		w := serve(mockLogs, "", "text/csv")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, strings.Join(logExportColumns, ",")+"\n", w.Body.String())
+	})
+
+	t.Run("rejects other formats", func(t *testing.T) {
+		w := serve(mocks.NewMockLoggingService(t), "", "application/xml")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+
+	t.Run("rejects an invalid filter", func(t *testing.T) {
+		w := serve(mocks.NewMockLoggingService(t), "?from=yesterday", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("reports a query that fails before any entry", func(t *testing.T) {
+		mockLogs := mocks.NewMockLoggingService(t)
+		mockLogs.EXPECT().ExportLogs(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(exportEntries(nil, errors.New("db down")))
+
+		w := serve(mockLogs, "", "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("ends early when the query fails midway", func(t *testing.T) {
+		mockLogs := mocks.NewMockLoggingService(t)
+		mockLogs.EXPECT().ExportLogs(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(exportEntries(entries[:1], errors.New("cursor lost")))
+
+		w := serve(mockLogs, "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, strings.Count(w.Body.String(), "\n"))
+	})
+}
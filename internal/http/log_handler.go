@@ -0,0 +1,141 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// TotalCountHeader carries the number of items matching a paginated query.
+const TotalCountHeader = "X-Total-Count"
+
+// LogHandler serves the stored request and audit logs.
+type LogHandler struct {
+	logs     service.LoggingService
+	location *time.Location
+	cursors  *pagination.Codec
+}
+
+// NewLogHandler creates a new LogHandler instance. Timestamps are shown in
+// location, or in UTC when it is nil. Pages link to the next one with
+// cursors encoded by cursors, when set.
+func NewLogHandler(logs service.LoggingService, location *time.Location, cursors *pagination.Codec) *LogHandler {
+	return &LogHandler{logs: logs, location: location, cursors: cursors}
+}
+
+// ListLogs handles GET /api/admin/logs requests.
+//
+// @Summary      Query logs
+// @Description  Returns a page of stored request and audit log entries, newest first, with timestamps in the configured display timezone (UTC by default). The number of matching entries is also sent in the X-Total-Count header. Requires the logs:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        limit query int false "Page size (default 50, max 500)"
+// @Param        offset query int false "Number of entries to skip"
+// @Param        cursor query string false "next_cursor of the previous page, instead of offset"
+// @Param        request_id query string false "Only entries of this request"
+// @Param        correlation_id query string false "Only entries of the requests with this correlation ID"
+// @Param        level query string false "Only entries of this level, e.g. error"
+// @Param        user query string false "Only entries of this user ID or email"
+// @Param        method query string false "Only entries of this HTTP method"
+// @Param        path query string false "Only entries whose path contains this text (case-insensitive)"
+// @Param        from query string false "Only entries at or after this time (RFC 3339)"
+// @Param        to query string false "Only entries at or before this time (RFC 3339)"
+// @Success      200 {object} dto.SuccessResponse{data=dto.LogPage} "Log entries page"
+// @Header       200 {integer} X-Total-Count "Number of matching entries"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid query parameter, or invalid_cursor to restart from the first page"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing logs:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/logs [get]
+func (h *LogHandler) ListLogs(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	opts, err := parseLogQuery(c, h.cursors)
+	if err != nil {
+		writeQueryError(builder, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	entries, err := h.logs.QueryLogs(ctx, opts)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	total, err := h.logs.CountLogs(ctx, opts)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	for i := range entries {
+		entries[i].Timestamp = timeutil.In(entries[i].Timestamp, h.location)
+	}
+	if entries == nil {
+		entries = []model.LogEntry{}
+	}
+
+	c.Header(TotalCountHeader, strconv.FormatInt(total, 10))
+	builder.SuccessOK(dto.LogPage{
+		Logs:       entries,
+		Total:      total,
+		Limit:      opts.Limit,
+		Offset:     opts.Skip,
+		NextCursor: nextCursor(c, h.cursors, "logs", opts.Skip, len(entries), total),
+	})
+}
+
+// parseLogQuery reads the log filter and page from the query string.
+func parseLogQuery(c *gin.Context, cursors *pagination.Codec) (model.LogQueryOptions, error) {
+	opts, err := parseLogFilter(c)
+	if err != nil {
+		return opts, err
+	}
+
+	if opts.Limit, err = queryInt(c, "limit"); err != nil {
+		return opts, err
+	}
+	if opts.Skip, err = pageOffset(c, cursors, "logs"); err != nil {
+		return opts, err
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = service.DefaultLogPageSize
+	}
+	opts.Limit = min(opts.Limit, service.MaxLogPageSize)
+	opts.Skip = max(opts.Skip, 0)
+	return opts, nil
+}
+
+// parseLogFilter reads the log filter from the query string.
+func parseLogFilter(c *gin.Context) (model.LogQueryOptions, error) {
+	opts := model.LogQueryOptions{
+		RequestID:     c.Query("request_id"),
+		CorrelationID: c.Query("correlation_id"),
+		Level:         strings.ToLower(c.Query("level")),
+		User:          c.Query("user"),
+		Method:        strings.ToUpper(c.Query("method")),
+		Path:          c.Query("path"),
+	}
+
+	var err error
+	if opts.StartTime, err = queryTime(c, "from"); err != nil {
+		return opts, err
+	}
+	if opts.EndTime, err = queryTime(c, "to"); err != nil {
+		return opts, err
+	}
+	if opts.StartTime != nil && opts.EndTime != nil && opts.StartTime.After(*opts.EndTime) {
+		return opts, &dto.ValidationError{Field: "from", Message: "must not be after to"}
+	}
+	return opts, nil
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogHandler_ListLogs(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	defaults := model.LogQueryOptions{Limit: service.DefaultLogPageSize}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockLoggingService)
+		expectedStatus int
+		expectedTotal  string
+	}{
+		{
+			name:  "lists with defaults",
+			query: "",
+			setupMock: func(m *mocks.MockLoggingService) {
+				m.EXPECT().QueryLogs(mock.Anything, defaults).Return([]model.LogEntry{{Message: "Request completed"}}, nil)
+				m.EXPECT().CountLogs(mock.Anything, defaults).Return(int64(120), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  "120",
+		},
+		{
+			name:  "applies filters",
+			query: "?request_id=req-1&level=ERROR&user=ops@example.com&method=post&path=/api/calculate&from=2026-03-01T00:00:00Z&to=2026-03-02T00:00:00Z&limit=10&offset=20",
+			setupMock: func(m *mocks.MockLoggingService) {
+				opts := model.LogQueryOptions{
+					RequestID: "req-1", Level: "error", User: "ops@example.com", Method: "POST", Path: "/api/calculate",
+					StartTime: &from, EndTime: &to, Limit: 10, Skip: 20,
+				}
+				m.EXPECT().QueryLogs(mock.Anything, opts).Return(nil, nil)
+				m.EXPECT().CountLogs(mock.Anything, opts).Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  "0",
+		},
+		{
+			name:  "caps the page size",
+			query: "?limit=100000",
+			setupMock: func(m *mocks.MockLoggingService) {
+				capped := model.LogQueryOptions{Limit: service.MaxLogPageSize}
+				m.EXPECT().QueryLogs(mock.Anything, capped).Return(nil, nil)
+				m.EXPECT().CountLogs(mock.Anything, capped).Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  "0",
+		},
+		{name: "invalid from", query: "?from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "invalid offset", query: "?offset=x", expectedStatus: http.StatusBadRequest},
+		{name: "inverted time range", query: "?from=2026-03-02T00:00:00Z&to=2026-03-01T00:00:00Z", expectedStatus: http.StatusBadRequest},
+		{
+			name:  "query error",
+			query: "",
+			setupMock: func(m *mocks.MockLoggingService) {
+				m.EXPECT().QueryLogs(mock.Anything, defaults).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:  "count error",
+			query: "",
+			setupMock: func(m *mocks.MockLoggingService) {
+				m.EXPECT().QueryLogs(mock.Anything, defaults).Return(nil, nil)
+				m.EXPECT().CountLogs(mock.Anything, defaults).Return(int64(0), errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogs := mocks.NewMockLoggingService(t)
+			if tt.setupMock != nil {
+				tt.setupMock(mockLogs)
+			}
+
+			router := gin.New()
+			router.GET("/api/admin/logs", NewLogHandler(mockLogs, nil, nil).ListLogs)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/logs"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedTotal, w.Header().Get(TotalCountHeader))
+		})
+	}
+}
+
+func TestLogHandler_ListLogs_Page(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+
+	mockLogs := mocks.NewMockLoggingService(t)
+	mockLogs.EXPECT().QueryLogs(mock.Anything, mock.Anything).Return([]model.LogEntry{
+		{Timestamp: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Level: "error", RequestID: "req-1"},
+	}, nil)
+	mockLogs.EXPECT().CountLogs(mock.Anything, mock.Anything).Return(int64(51), nil)
+
+	router := gin.New()
+	router.GET("/api/admin/logs", NewLogHandler(mockLogs, saoPaulo, nil).ListLogs)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/logs?offset=50", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data dto.LogPage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(51), resp.Data.Total)
+	assert.Equal(t, service.DefaultLogPageSize, resp.Data.Limit)
+	assert.Equal(t, 50, resp.Data.Offset)
+	require.Len(t, resp.Data.Logs, 1)
+	assert.Contains(t, w.Body.String(), `"timestamp":"2026-03-01T07:00:00-03:00"`)
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// MessageOverrideHandler serves and changes the messages tenants replaced.
+type MessageOverrideHandler struct {
+	overrides service.MessageOverrideService
+}
+
+// NewMessageOverrideHandler creates a new MessageOverrideHandler instance.
+func NewMessageOverrideHandler(overrides service.MessageOverrideService) *MessageOverrideHandler {
+	return &MessageOverrideHandler{overrides: overrides}
+}
+
+// ListMessageOverrides handles GET /api/admin/message-overrides requests.
+//
+// @Summary      List message overrides
+// @Description  Lists the catalog messages tenants replaced, sorted by tenant, locale and key. Tenants are API keys, identified by their fingerprint.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        tenant query string false "Only list the overrides of this tenant"
+// @Success      200 {object} dto.SuccessResponse{data=[]model.MessageOverride} "Message overrides"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/message-overrides [get]
+func (h *MessageOverrideHandler) ListMessageOverrides(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	overrides, err := h.overrides.List(c.Request.Context(), c.Query("tenant"))
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	builder.SuccessOK(overrides)
+}
+
+// SetMessageOverride handles PUT /api/admin/message-overrides/:tenant/:locale/:key requests.
+//
+// @Summary      Replace a message for a tenant
+// @Description  Replaces the catalog message key in locale for requests authenticated with the tenant's API key. The message must have the placeholders of the catalog message, such as %d, in the same order. Other instances apply the change within I18N_OVERRIDE_REFRESH_INTERVAL.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        tenant path string true "API key fingerprint of the tenant"
+// @Param        locale path string true "Locale, e.g. en"
+// @Param        key path string true "Message key, e.g. error.rate_limit_exceeded"
+// @Param        request body dto.MessageOverrideRequest true "Message"
+// @Success      200 {object} dto.SuccessResponse{data=model.MessageOverride} "Stored override"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - unknown key or locale, or placeholders that differ from the catalog message"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/message-overrides/{tenant}/{locale}/{key} [put]
+func (h *MessageOverrideHandler) SetMessageOverride(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.MessageOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+
+	override := &model.MessageOverride{
+		Tenant:    c.Param("tenant"),
+		Locale:    c.Param("locale"),
+		Key:       c.Param("key"),
+		Message:   req.Message,
+		UpdatedBy: userIDFromContext(c),
+	}
+	if err := h.overrides.Set(c.Request.Context(), override); err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "set_message_override", "Message override set", override)
+	builder.SuccessOK(override)
+}
+
+// DeleteMessageOverride handles DELETE /api/admin/message-overrides/:tenant/:locale/:key requests.
+//
+// @Summary      Restore a message for a tenant
+// @Description  Removes the tenant's override of the message key in locale, so the catalog message is used again.
+// @Tags         Admin
+// @Param        Authorization header string true "Bearer token"
+// @Param        tenant path string true "API key fingerprint of the tenant"
+// @Param        locale path string true "Locale, e.g. en"
+// @Param        key path string true "Message key, e.g. error.rate_limit_exceeded"
+// @Success      204 "Override removed"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      404 {object} dto.ErrorResponse "The tenant has no override of the message"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/message-overrides/{tenant}/{locale}/{key} [delete]
+func (h *MessageOverrideHandler) DeleteMessageOverride(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	override := &model.MessageOverride{Tenant: c.Param("tenant"), Locale: c.Param("locale"), Key: c.Param("key")}
+	if err := h.overrides.Delete(c.Request.Context(), override.Tenant, override.Locale, override.Key); err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "delete_message_override", "Message override removed", override)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MessageOverrideHandler) audit(c *gin.Context, action, message string, override *model.MessageOverride) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, action, message, map[string]interface{}{
+				"tenant": override.Tenant,
+				"locale": override.Locale,
+				"key":    override.Key,
+			})
+		}
+	}
+}
@@ -0,0 +1,101 @@
+//go:build !integration
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestMessageOverrideHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer i18n.GetTranslator().SetOverrides(nil)
+
+	tenant := middleware.APIKeyFingerprint("key-acme")
+	var stored []*model.MessageOverride
+	repo := mocks.NewMockMessageOverrideRepositoryInterface(t)
+	repo.EXPECT().Upsert(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, o *model.MessageOverride) error {
+		stored = append(stored, o)
+		return nil
+	}).Maybe()
+	repo.EXPECT().List(mock.Anything).RunAndReturn(func(context.Context) ([]*model.MessageOverride, error) {
+		return stored, nil
+	}).Maybe()
+	repo.EXPECT().Delete(mock.Anything, tenant, "en", i18n.ErrKeyInvalidRequestBody).RunAndReturn(func(context.Context, string, string, string) (bool, error) {
+		deleted := len(stored) > 0
+		stored = nil
+		return deleted, nil
+	}).Maybe()
+	overrides := service.NewMessageOverrideService(repo, i18n.GetTranslator())
+
+	cfg := DefaultRouterConfig()
+	cfg.APIKeys = map[string]bool{"key-acme": true, "key-globex": true}
+	cfg.MessageOverrides = overrides
+	router := NewRouter(NewHandler(service.NewPackCalculatorService(), nil), NewHealthHandler(), cfg)
+
+	// Admin routes need JWT auth; mount the handler directly
+	handler := NewMessageOverrideHandler(overrides)
+	router.GET("/api/admin/message-overrides", handler.ListMessageOverrides)
+	router.PUT("/api/admin/message-overrides/:tenant/:locale/:key", handler.SetMessageOverride)
+	router.DELETE("/api/admin/message-overrides/:tenant/:locale/:key", handler.DeleteMessageOverride)
+
+	do := func(method, path, body, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set(middleware.APIKeyHeader, apiKey)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	overridePath := "/api/admin/message-overrides/" + tenant + "/en/" + i18n.ErrKeyInvalidRequestBody
+	calculate := func(apiKey string) string {
+		w := do(http.MethodPost, "/api/calculate", `{"items_ordered":-1}`, apiKey)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		return w.Body.String()
+	}
+
+	t.Run("rejects invalid overrides", func(t *testing.T) {
+		w := do(http.MethodPut, "/api/admin/message-overrides/"+tenant+"/en/error.unknown", `{"message":"Oops"}`, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = do(http.MethodPut, "/api/admin/message-overrides/"+tenant+"/en/"+i18n.ErrKeyValidationItemsOrderedMax, `{"message":"Too large"}`, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_message_override")
+		w = do(http.MethodPut, overridePath, `{}`, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("applies an override to the tenant's requests only", func(t *testing.T) {
+		w := do(http.MethodPut, overridePath, `{"message":"Acme could not read this order"}`, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Contains(t, calculate("key-acme"), "Acme could not read this order")
+		assert.NotContains(t, calculate("key-globex"), "Acme")
+		assert.NotContains(t, calculate(""), "Acme")
+
+		w = do(http.MethodGet, "/api/admin/message-overrides?tenant="+tenant, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Acme could not read this order")
+	})
+
+	t.Run("deleting restores the catalog message", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, overridePath, "", "").Code)
+		assert.NotContains(t, calculate("key-acme"), "Acme")
+		assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, overridePath, "", "").Code)
+	})
+}
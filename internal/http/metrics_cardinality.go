@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsCardinalityHandler serves the metric cardinality report.
+type MetricsCardinalityHandler struct {
+	gatherer prometheus.Gatherer
+}
+
+// NewMetricsCardinalityHandler creates a new MetricsCardinalityHandler instance.
+func NewMetricsCardinalityHandler(gatherer prometheus.Gatherer) *MetricsCardinalityHandler {
+	return &MetricsCardinalityHandler{gatherer: gatherer}
+}
+
+// GetReport handles GET /api/admin/metrics/cardinality requests.
+//
+// @Summary      Metric cardinality
+// @Description  Lists every exported metric with its number of series and distinct values per label, highest first, along with the label limits in force. For capped labels it shows how many recordings were folded into the "other" value. Counts are per instance since its start.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=metrics.CardinalityReport} "Cardinality report"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/metrics/cardinality [get]
+func (h *MetricsCardinalityHandler) GetReport(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	report, err := metrics.Cardinality(h.gatherer)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	builder.SuccessOK(report)
+}
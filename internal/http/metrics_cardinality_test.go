@@ -0,0 +1,41 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+func TestMetricsCardinalityHandler_GetReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "test"}, []string{"client"})
+	registry.MustRegister(counter)
+	counter.WithLabelValues("a").Inc()
+	counter.WithLabelValues("b").Inc()
+
+	router := gin.New()
+	router.GET("/admin/metrics/cardinality", NewMetricsCardinalityHandler(registry).GetReport)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/metrics/cardinality", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data metrics.CardinalityReport `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Data.TotalSeries)
+	require.Len(t, resp.Data.Metrics, 1)
+	assert.Equal(t, map[string]int{"client": 2}, resp.Data.Metrics[0].Labels)
+}
@@ -1,19 +1,39 @@
 package http
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/deprecation"
 	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/edgecache"
+	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/guttosm/pack-service/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // PackSizesHandler provides HTTP handlers for pack sizes routes.
 type PackSizesHandler struct {
 	packSizesService service.PackSizesService
 	calculator       service.PackCalculator
+	// packSizesCache is the calculation handler's cache, primed on every update.
+	packSizesCache *packSizesCache
+	deprecations   *deprecation.Tracker
+	migrator       *service.PackSizesMigrator
+	// edgeCache is purged of results made with the old sizes on every update.
+	edgeCache *edgecache.Policy
+	// rules are checked before sizes are saved or staged.
+	rules service.PackSizeRules
+	// responseCache is dropped of the pack size responses on every update.
+	responseCache *middleware.ResponseCache
+	// activePackSizes is the calculation handler's view, set on every update.
+	activePackSizes *service.ActivePackSizes
 }
 
 // NewPackSizesHandler creates a new PackSizesHandler instance.
@@ -27,12 +47,15 @@ func NewPackSizesHandler(packSizesService service.PackSizesService, calculator s
 // GetActivePackSizes handles GET /api/pack-sizes requests.
 //
 // @Summary      Get active pack sizes
-// @Description  Returns the currently active pack size configuration
+// @Description  Returns the currently active pack size configuration. The ETag names its version; send it in If-None-Match to get 304 while it is still active.
 // @Tags         Pack Sizes
 // @Accept       json
 // @Produce      json
 // @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        If-None-Match header string false "ETag of a previously fetched configuration"
 // @Success      200 {object} dto.SuccessResponse "Active pack sizes"
+// @Header       200 {string} ETag "Version of the active configuration"
+// @Success      304 "The configuration named by If-None-Match is still active"
 // @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure      404 {object} dto.ErrorResponse "No active pack sizes found"
 // @Failure      500 {object} dto.ErrorResponse "Internal server error"
@@ -43,35 +66,40 @@ func (h *PackSizesHandler) GetActivePackSizes(c *gin.Context) {
 
 	config, err := h.packSizesService.GetActive(c.Request.Context())
 	if err != nil {
-		builder.Error(http.StatusInternalServerError, dto.ErrCodeInternal, err)
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
 		return
 	}
 
 	if config == nil {
-		builder.Error(http.StatusNotFound, dto.ErrCodeNotFound, nil)
+		builder.Error(http.StatusNotFound, i18n.ErrKeyNotFound, nil)
 		return
 	}
 
-	builder.SuccessOK(map[string]interface{}{
-		"sizes":     config.Sizes,
-		"version":   config.Version,
-		"created_at": config.CreatedAt,
-		"updated_at": config.UpdatedAt,
-	})
+	etag := packSizesETag(config.Version)
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	builder.SuccessOK(packSizesResponse(config))
 }
 
 // UpdatePackSizes handles PUT /api/pack-sizes requests.
 //
 // @Summary      Update pack sizes
-// @Description  Updates the active pack size configuration
+// @Description  Updates the active pack size configuration. With If-Match, the update is only made while the configuration with that ETag is active, so concurrent edits do not overwrite each other.
 // @Tags         Pack Sizes
 // @Accept       json
 // @Produce      json
 // @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        If-Match header string false "ETag of the configuration being edited"
 // @Param        request body dto.UpdatePackSizesRequest true "Pack sizes configuration"
 // @Success      200 {object} dto.SuccessResponse "Updated pack sizes"
-// @Failure      400 {object} dto.ErrorResponse "Bad request"
+// @Header       200 {string} ETag "Version of the new configuration"
+// @Failure      400 {object} dto.ErrorResponse "Bad request, or the sizes break a pack size rule"
 // @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      412 {object} dto.ErrorResponse "The configuration named by If-Match is no longer active"
 // @Failure      500 {object} dto.ErrorResponse "Internal server error"
 // @Security     BearerAuth
 // @Router       /api/pack-sizes [put]
@@ -80,25 +108,40 @@ func (h *PackSizesHandler) UpdatePackSizes(c *gin.Context) {
 
 	var req dto.UpdatePackSizesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		builder.Error(http.StatusBadRequest, dto.ErrCodeInvalidRequest, err)
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
 		return
 	}
 
 	if len(req.Sizes) == 0 {
-		builder.Error(http.StatusBadRequest, dto.ErrCodeInvalidRequest, nil)
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyValidationPackSizesRequired, nil)
 		return
 	}
 
-	config, err := h.packSizesService.Create(c.Request.Context(), req.Sizes, req.CreatedBy)
-	if err != nil {
-		builder.Error(http.StatusInternalServerError, dto.ErrCodeInternal, err)
+	if !req.ValidCosts() {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyValidationCosts, nil)
 		return
 	}
 
-	if h.calculator != nil {
-		h.calculator.InvalidateCache()
+	violations := h.rules.Check(req.Sizes)
+	if service.HasPackSizeErrors(violations) {
+		ruleViolations(builder, violations)
+		return
+	}
+
+	createdBy := req.CreatedBy
+	if createdBy == "" {
+		createdBy = userIDFromContext(c)
+	}
+
+	config, err := h.createPackSizes(c, req.Sizes, req.Costs, createdBy)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
 	}
 
+	h.applyPackSizes(config)
+	c.Header("ETag", packSizesETag(config.Version))
+
 	if loggingService, exists := c.Get("logging_service"); exists {
 		if ls, ok := loggingService.(service.LoggingService); ok {
 			middleware.AuditLog(ls, c, "update_pack_sizes", "Pack sizes configuration updated", map[string]interface{}{
@@ -108,18 +151,162 @@ func (h *PackSizesHandler) UpdatePackSizes(c *gin.Context) {
 		}
 	}
 
-	builder.SuccessOK(map[string]interface{}{
+	builder.SuccessWithWarnings(http.StatusOK, packSizesResponse(config), append(deprecationWarnings(c, h.deprecations, &req, nil), ruleWarnings(violations)...))
+}
+
+// createPackSizes creates and activates sizes with costs. When the request has If-Match,
+// it does so only while the configuration it names is active, and returns
+// service.ErrPackSizesChanged otherwise.
+func (h *PackSizesHandler) createPackSizes(c *gin.Context, sizes []int, costs map[int]float64, createdBy string) (*repository.PackSizeConfig, error) {
+	ctx := c.Request.Context()
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return h.packSizesService.Create(ctx, sizes, costs, createdBy)
+	}
+
+	active, err := h.packSizesService.GetActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil || !etagMatches(ifMatch, packSizesETag(active.Version), false) {
+		return nil, service.ErrPackSizesChanged
+	}
+	return h.packSizesService.CreateIfActive(ctx, sizes, costs, createdBy, active.Version)
+}
+
+// packSizesResponse is the response body describing config.
+func packSizesResponse(config *repository.PackSizeConfig) map[string]interface{} {
+	body := map[string]interface{}{
 		"sizes":      config.Sizes,
 		"version":    config.Version,
 		"created_at": config.CreatedAt,
 		"updated_at": config.UpdatedAt,
-	})
+	}
+	if len(config.Costs) > 0 {
+		body["costs"] = config.Costs
+	}
+	return body
+}
+
+// packSizesETag returns the entity tag of the pack size configuration with
+// version.
+func packSizesETag(version int) string {
+	return `"pack-sizes-` + strconv.Itoa(version) + `"`
+}
+
+// etagMatches reports whether the If-Match or If-None-Match header value
+// names etag or is "*". Weak comparison, used for If-None-Match, ignores the
+// W/ prefix; strong comparison never matches a weak tag.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePackSizes handles POST /api/pack-sizes/validate requests.
+//
+// @Summary      Validate pack sizes
+// @Description  Checks pack sizes against the pack size rules without saving them, and when they can be saved, calculates the sample orders with both the active and the proposed sizes. Warnings, such as all sizes sharing a large common divisor, do not keep sizes from being saved.
+// @Tags         Pack Sizes
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.ValidatePackSizesRequest true "Pack sizes and sample orders"
+// @Success      200 {object} dto.SuccessResponse{data=dto.PackSizesValidation} "Violations and impact"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid request body"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      422 {object} dto.ErrorResponse "A sample order is above the maximum order"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/validate [post]
+func (h *PackSizesHandler) ValidatePackSizes(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.ValidatePackSizesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+
+	maxItems := service.MaxItemsOrdered(h.calculator)
+	for _, items := range req.SampleOrders {
+		if items > maxItems {
+			ordersTooLarge(builder, "sample_orders", maxItems)
+			return
+		}
+	}
+
+	violations := h.rules.Check(req.Sizes)
+	validation := dto.PackSizesValidation{
+		Valid:      !service.HasPackSizeErrors(violations),
+		Sizes:      req.Sizes,
+		Violations: append([]dto.PackSizeViolation{}, violations...),
+	}
+
+	if validation.Valid && len(req.SampleOrders) > 0 && h.calculator != nil {
+		active, err := h.packSizesService.GetActive(c.Request.Context())
+		if err != nil {
+			builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+			return
+		}
+		var current []int
+		if active != nil {
+			validation.ActiveVersion = active.Version
+			validation.ActiveSizes = active.Sizes
+			current = active.Sizes
+		}
+		validation.Impact = service.PackSizesImpact(h.calculator, current, req.Sizes, req.SampleOrders)
+	}
+
+	builder.SuccessOK(validation)
+}
+
+// ordersTooLarge responds 422 to orders listed in field above maxItems.
+func ordersTooLarge(builder *ResponseBuilder, field string, maxItems int) {
+	builder.ErrorWithDetails(http.StatusUnprocessableEntity, fmt.Sprintf("%s: must be at most %d", field, maxItems),
+		map[string]string{"max_items_ordered": strconv.Itoa(maxItems)}, nil)
+}
+
+// ruleViolations responds 400 to sizes that break a pack size rule, listing
+// the broken rules in the details.
+func ruleViolations(builder *ResponseBuilder, violations []dto.PackSizeViolation) {
+	details := make(map[string]string, len(violations))
+	for _, v := range violations {
+		if v.Severity == service.PackSizeRuleError {
+			details[v.Rule] = v.Message
+		}
+	}
+	builder.ErrorWithDetails(http.StatusBadRequest, service.ErrPackSizeRules.Error(), details, service.ErrPackSizeRules)
+}
+
+// ruleWarnings returns the warnings among violations as response warnings.
+func ruleWarnings(violations []dto.PackSizeViolation) []dto.Warning {
+	var warnings []dto.Warning
+	for _, v := range violations {
+		if v.Severity == service.PackSizeRuleWarning {
+			warnings = append(warnings, dto.Warning{Code: "pack_sizes_" + v.Rule, Field: "sizes", Message: v.Message})
+		}
+	}
+	return warnings
 }
 
 // ListPackSizes handles GET /api/pack-sizes/history requests.
 //
 // @Summary      List pack sizes history
-// @Description  Returns all pack size configurations (history)
+// @Description  Returns all pack size configurations (history), newest first, with who activated each one and when
 // @Tags         Pack Sizes
 // @Accept       json
 // @Produce      json
@@ -142,13 +329,173 @@ func (h *PackSizesHandler) ListPackSizes(c *gin.Context) {
 
 	configs, err := h.packSizesService.List(c.Request.Context(), limit)
 	if err != nil {
-		builder.Error(http.StatusInternalServerError, dto.ErrCodeInternal, err)
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
 		return
 	}
 
 	builder.SuccessOK(configs)
 }
 
+// ActivatePackSizes handles POST /api/pack-sizes/:id/activate requests.
+//
+// @Summary      Activate pack sizes
+// @Description  Makes a stored pack size configuration, e.g. one listed by GET /api/pack-sizes/history, the active one. Calculations use it immediately.
+// @Tags         Pack Sizes
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        id path string true "Pack size configuration ID"
+// @Success      200 {object} dto.SuccessResponse "Activated pack sizes"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid ID"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "Pack size configuration not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/{id}/activate [post]
+func (h *PackSizesHandler) ActivatePackSizes(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		NewResponseBuilder(c).Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+
+	h.switchPackSizes(c, "activate_pack_sizes", "Pack sizes configuration activated",
+		func(ctx context.Context, activatedBy string) (*repository.PackSizeConfig, error) {
+			return h.packSizesService.Activate(ctx, id, activatedBy)
+		})
+}
+
+// RollbackPackSizes handles POST /api/pack-sizes/rollback requests.
+//
+// @Summary      Roll back pack sizes
+// @Description  Activates the pack size configuration with the highest version below the active one. Rolling back again goes further back; activate a configuration to undo a rollback.
+// @Tags         Pack Sizes
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Success      200 {object} dto.SuccessResponse "Activated pack sizes"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      409 {object} dto.ErrorResponse "No configuration precedes the active one"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/rollback [post]
+func (h *PackSizesHandler) RollbackPackSizes(c *gin.Context) {
+	h.switchPackSizes(c, "rollback_pack_sizes", "Pack sizes configuration rolled back", h.packSizesService.Rollback)
+}
+
+// switchPackSizes activates a configuration with activate, then makes the
+// calculations use it and audits the change from the previously active one.
+func (h *PackSizesHandler) switchPackSizes(c *gin.Context, action, message string,
+	activate func(ctx context.Context, activatedBy string) (*repository.PackSizeConfig, error)) {
+	builder := NewResponseBuilder(c)
+	ctx := c.Request.Context()
+
+	previous, err := h.packSizesService.GetActive(ctx)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	config, err := activate(ctx, userIDFromContext(c))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.applyPackSizes(config)
+	c.Header("ETag", packSizesETag(config.Version))
+
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			fields := map[string]interface{}{
+				"pack_sizes": config.Sizes,
+				"version":    config.Version,
+			}
+			if previous != nil {
+				fields["previous_version"] = previous.Version
+				fields["previous_pack_sizes"] = previous.Sizes
+			}
+			middleware.AuditLog(ls, c, action, message, fields)
+		}
+	}
+
+	builder.SuccessOK(map[string]interface{}{
+		"sizes":        config.Sizes,
+		"version":      config.Version,
+		"created_at":   config.CreatedAt,
+		"updated_at":   config.UpdatedAt,
+		"activated_at": config.ActivatedAt,
+	})
+}
+
+// applyPackSizes makes the next calculations on this instance use config,
+// which was just activated.
+func (h *PackSizesHandler) applyPackSizes(config *repository.PackSizeConfig) {
+	if h.packSizesCache != nil {
+		h.packSizesCache.prime(config.Sizes, config.Costs, config.Version)
+	}
+	if h.activePackSizes != nil {
+		h.activePackSizes.Set(config)
+	}
+	if h.calculator != nil {
+		h.calculator.InvalidateCache()
+	}
+	if h.edgeCache != nil {
+		h.edgeCache.PurgePackSizes()
+	}
+	h.responseCache.Invalidate(responseCachePackSizes)
+}
+
+// GetAffectedCalculations handles GET /api/pack-sizes/affected requests.
+//
+// @Summary      Calculations affected by pack size configs
+// @Description  Returns calculation counts per pack size config version, for every version since the given one. Used to assess the blast radius of a bad configuration.
+// @Tags         Pack Sizes
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        since_version query int true "First config version to include"
+// @Success      200 {object} dto.SuccessResponse "Calculations per config version"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - missing or invalid since_version"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Failure      503 {object} dto.ErrorResponse "Audit logs not available"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/affected [get]
+func (h *PackSizesHandler) GetAffectedCalculations(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	sinceVersion, err := parseInt(c.Query("since_version"))
+	if err != nil || sinceVersion < 1 {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyValidationSinceVersion, err)
+		return
+	}
+
+	var ls service.LoggingService
+	if loggingService, exists := c.Get("logging_service"); exists {
+		ls, _ = loggingService.(service.LoggingService)
+	}
+	if ls == nil {
+		builder.Error(http.StatusServiceUnavailable, i18n.ErrKeyUnavailable, nil)
+		return
+	}
+
+	impacts, err := ls.CalculationsSinceConfigVersion(c.Request.Context(), sinceVersion)
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	var total int64
+	for _, impact := range impacts {
+		total += impact.Calculations
+	}
+
+	builder.SuccessOK(map[string]interface{}{
+		"since_version":      sinceVersion,
+		"total_calculations": total,
+		"versions":           impacts,
+	})
+}
+
 func parseInt(s string) (int, error) {
 	return strconv.Atoi(s)
 }
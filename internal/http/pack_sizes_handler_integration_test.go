@@ -42,7 +42,7 @@ func setupPackSizesIntegrationRouter(dbName string) *gin.Engine {
 	handler := NewHandler(calculator, packSizesService)
 	healthHandler := NewHealthHandler()
 	healthHandler.RegisterCircuitBreaker("mongodb_pack_sizes", packSizesCB)
-	healthHandler.RegisterCircuitBreaker("mongodb_logs", logsCB)
+	healthHandler.RegisterOptionalCircuitBreaker("mongodb_logs", logsCB)
 
 	cfg := RouterConfig{
 		RateLimit:       100,
@@ -85,7 +85,7 @@ func TestPackSizesHandler_Integration(t *testing.T) {
 		}()
 
 		repo := repository.NewPackSizesRepository(db)
-		_, createErr := repo.Create(ctx, []int{100, 200, 500}, "test")
+		_, createErr := repo.Create(ctx, []int{100, 200, 500}, nil, "test")
 		require.NoError(t, createErr)
 
 		// Create a router with the same database where we created pack sizes
@@ -121,7 +121,7 @@ func TestPackSizesHandler_Integration(t *testing.T) {
 
 		// First create initial pack sizes
 		repo := repository.NewPackSizesRepository(db)
-		_, createErr := repo.Create(ctx, []int{100, 200}, "test-user-init")
+		_, createErr := repo.Create(ctx, []int{100, 200}, nil, "test-user-init")
 		require.NoError(t, createErr)
 
 		// Create router with the same database
@@ -163,9 +163,9 @@ func TestPackSizesHandler_Integration(t *testing.T) {
 		}()
 
 		repo := repository.NewPackSizesRepository(db)
-		_, createErr := repo.Create(ctx, []int{100, 200}, "test-user-1")
+		_, createErr := repo.Create(ctx, []int{100, 200}, nil, "test-user-1")
 		require.NoError(t, createErr)
-		_, createErr = repo.Create(ctx, []int{250, 500}, "test-user-2")
+		_, createErr = repo.Create(ctx, []int{250, 500}, nil, "test-user-2")
 		require.NoError(t, createErr)
 
 		// Create a router with the same database where we created pack sizes
@@ -227,7 +227,8 @@ func TestHealthCheckWithCircuitBreaker_Integration(t *testing.T) {
 		checks := response["checks"].(map[string]interface{})
 		assert.Contains(t, checks, "mongodb_pack_sizes_circuit")
 		assert.Contains(t, checks, "mongodb_logs_circuit")
-		assert.Equal(t, "closed", checks["mongodb_pack_sizes_circuit"])
-		assert.Equal(t, "closed", checks["mongodb_logs_circuit"])
+		assert.Equal(t, "ok", checks["mongodb_pack_sizes_circuit"].(map[string]interface{})["status"])
+		assert.Equal(t, "ok", checks["mongodb_logs_circuit"].(map[string]interface{})["status"])
+		assert.Equal(t, false, checks["mongodb_logs_circuit"].(map[string]interface{})["critical"])
 	})
 }
@@ -2,9 +2,11 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +14,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/guttosm/pack-service/internal/deprecation"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/middleware"
 	"github.com/guttosm/pack-service/internal/mocks"
 	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/guttosm/pack-service/internal/service"
@@ -101,7 +107,7 @@ func TestPackSizesHandler_UpdatePackSizes(t *testing.T) {
 					CreatedAt: time.Now(),
 					UpdatedAt: time.Now(),
 				}
-				mockRepo.On("Create", mock.Anything, []int{250, 500, 1000}, mock.Anything).Return(config, nil)
+				mockRepo.On("Create", mock.Anything, []int{250, 500, 1000}, mock.Anything, mock.Anything).Return(config, nil)
 				// Audit logging is async, so we allow it but don't assert
 				mockLogging.On("CreateLog", mock.Anything, mock.Anything).Maybe().Return(nil)
 			},
@@ -127,13 +133,48 @@ func TestPackSizesHandler_UpdatePackSizes(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "duplicate pack sizes",
+			requestBody: map[string]interface{}{
+				"sizes": []int{250, 500, 250},
+			},
+			setupMocks: func(mockRepo *mocks.MockPackSizesRepositoryInterface, mockLogging *mocks.MockLoggingService) {
+				// No calls expected
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "update with costs",
+			requestBody: map[string]interface{}{
+				"sizes": []int{250, 500},
+				"costs": map[string]float64{"250": 3, "500": 4.5},
+			},
+			setupMocks: func(mockRepo *mocks.MockPackSizesRepositoryInterface, mockLogging *mocks.MockLoggingService) {
+				costs := map[int]float64{250: 3, 500: 4.5}
+				config := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Costs: costs, Version: 2}
+				mockRepo.On("Create", mock.Anything, []int{250, 500}, costs, mock.Anything).Return(config, nil)
+				mockLogging.On("CreateLog", mock.Anything, mock.Anything).Maybe().Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "costs of sizes not listed",
+			requestBody: map[string]interface{}{
+				"sizes": []int{250, 500},
+				"costs": map[string]float64{"1000": 3},
+			},
+			setupMocks: func(mockRepo *mocks.MockPackSizesRepositoryInterface, mockLogging *mocks.MockLoggingService) {
+				// No calls expected
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name: "repository create error",
 			requestBody: map[string]interface{}{
 				"sizes": []int{250, 500},
 			},
 			setupMocks: func(mockRepo *mocks.MockPackSizesRepositoryInterface, mockLogging *mocks.MockLoggingService) {
-				mockRepo.On("Create", mock.Anything, []int{250, 500}, mock.Anything).Return(nil, assert.AnError)
+				mockRepo.On("Create", mock.Anything, []int{250, 500}, mock.Anything, mock.Anything).Return(nil, assert.AnError)
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -230,6 +271,297 @@ func TestPackSizesHandler_ListPackSizes(t *testing.T) {
 	}
 }
 
+func TestPackSizesHandler_GetAffectedCalculations(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		withLogging    bool
+		setupMocks     func(*mocks.MockLoggingService)
+		expectedStatus int
+		expectedTotal  float64
+	}{
+		{
+			name:        "calculations per version",
+			query:       "?since_version=2",
+			withLogging: true,
+			setupMocks: func(mockLogging *mocks.MockLoggingService) {
+				mockLogging.On("CalculationsSinceConfigVersion", mock.Anything, 2).Return([]model.ConfigVersionImpact{
+					{Version: 2, Calculations: 5},
+					{Version: 3, Calculations: 2},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  7,
+		},
+		{
+			name:           "missing since_version",
+			query:          "",
+			withLogging:    true,
+			setupMocks:     func(mockLogging *mocks.MockLoggingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non-positive since_version",
+			query:          "?since_version=0",
+			withLogging:    true,
+			setupMocks:     func(mockLogging *mocks.MockLoggingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "logging service unavailable",
+			query:          "?since_version=1",
+			withLogging:    false,
+			setupMocks:     func(mockLogging *mocks.MockLoggingService) {},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:        "aggregation error",
+			query:       "?since_version=1",
+			withLogging: true,
+			setupMocks: func(mockLogging *mocks.MockLoggingService) {
+				mockLogging.On("CalculationsSinceConfigVersion", mock.Anything, 1).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockLogging := new(mocks.MockLoggingService)
+
+			tt.setupMocks(mockLogging)
+
+			handler := NewPackSizesHandler(service.NewPackSizesService(new(mocks.MockPackSizesRepositoryInterface)), nil)
+			if tt.withLogging {
+				router.Use(func(c *gin.Context) {
+					c.Set("logging_service", mockLogging)
+					c.Next()
+				})
+			}
+			router.GET("/pack-sizes/affected", handler.GetAffectedCalculations)
+
+			req := httptest.NewRequest(http.MethodGet, "/pack-sizes/affected"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedTotal, resp.Data["total_calculations"])
+			}
+			mockLogging.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPackSizesHandler_ValidatePackSizes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	mockRepo.On("GetActive", mock.Anything).Return(&repository.PackSizeConfig{Sizes: []int{250, 500, 1000}, Version: 3}, nil)
+
+	routes := NewPackRoutes(service.NewPackCalculatorService(), service.NewPackSizesService(mockRepo),
+		WithPackSizeRules(service.PackSizeRules{MaxSize: 5000, CommonDivisorWarning: 50}))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	validate := func(body string) (*httptest.ResponseRecorder, dto.PackSizesValidation) {
+		req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/validate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Data dto.PackSizesValidation `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.Data
+	}
+
+	t.Run("valid sizes with impact", func(t *testing.T) {
+		w, validation := validate(`{"sizes": [250, 500, 800], "sample_orders": [251, 751]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, validation.Valid)
+		assert.Equal(t, 3, validation.ActiveVersion)
+		assert.Len(t, validation.Violations, 1)
+		assert.Equal(t, service.RuleCommonDivisor, validation.Violations[0].Rule)
+		assert.Len(t, validation.Impact, 2)
+		assert.False(t, validation.Impact[0].Changed)
+		assert.True(t, validation.Impact[1].Changed)
+		assert.Equal(t, -200, validation.Impact[1].TotalItemsChange)
+	})
+
+	t.Run("invalid sizes are not calculated", func(t *testing.T) {
+		w, validation := validate(`{"sizes": [23, 31, 10000, 31], "sample_orders": [251]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, validation.Valid)
+		assert.Len(t, validation.Violations, 2)
+		assert.Empty(t, validation.Impact)
+	})
+
+	t.Run("sample order above the maximum", func(t *testing.T) {
+		w, _ := validate(`{"sizes": [250], "sample_orders": [20000000]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("saving is refused with the broken rules", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/pack-sizes", bytes.NewBufferString(`{"sizes": [250, 10000]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var resp dto.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Details, service.RuleMaxSize)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPackSizesHandler_ConditionalRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	active := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Active: true, Version: 3}
+
+	newRouter := func(mockRepo *mocks.MockPackSizesRepositoryInterface) *gin.Engine {
+		handler := NewPackSizesHandler(service.NewPackSizesService(mockRepo), nil)
+		router := gin.New()
+		router.GET("/pack-sizes", handler.GetActivePackSizes)
+		router.PUT("/pack-sizes", handler.UpdatePackSizes)
+		return router
+	}
+	update := func(router *gin.Engine, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/pack-sizes", strings.NewReader(`{"sizes":[250,500,1000]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("get returns the version etag", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("GetActive", mock.Anything).Return(active, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockRepo).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pack-sizes", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"pack-sizes-3"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("get with matching if-none-match", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("GetActive", mock.Anything).Return(active, nil)
+
+		for _, tag := range []string{`"pack-sizes-3"`, `W/"pack-sizes-3"`, `"pack-sizes-2", "pack-sizes-3"`, `*`} {
+			req := httptest.NewRequest(http.MethodGet, "/pack-sizes", nil)
+			req.Header.Set("If-None-Match", tag)
+			w := httptest.NewRecorder()
+			newRouter(mockRepo).ServeHTTP(w, req)
+			assert.Equal(t, http.StatusNotModified, w.Code, tag)
+			assert.Empty(t, w.Body.String(), tag)
+		}
+	})
+
+	t.Run("get with stale if-none-match", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("GetActive", mock.Anything).Return(active, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/pack-sizes", nil)
+		req.Header.Set("If-None-Match", `"pack-sizes-2"`)
+		w := httptest.NewRecorder()
+		newRouter(mockRepo).ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("update with matching if-match", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("GetActive", mock.Anything).Return(active, nil)
+		mockRepo.On("CreateIfActive", mock.Anything, []int{250, 500, 1000}, mock.Anything, mock.Anything, 3).
+			Return(&repository.PackSizeConfig{Sizes: []int{250, 500, 1000}, Active: true, Version: 4}, nil)
+
+		w := update(newRouter(mockRepo), `"pack-sizes-3"`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"pack-sizes-4"`, w.Header().Get("ETag"))
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update with stale if-match", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("GetActive", mock.Anything).Return(active, nil)
+
+		for _, tag := range []string{`"pack-sizes-2"`, `W/"pack-sizes-3"`} {
+			w := update(newRouter(mockRepo), tag)
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code, tag)
+
+			var resp dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, dto.ErrCodePreconditionFailed, resp.Error)
+		}
+		mockRepo.AssertNotCalled(t, "CreateIfActive", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update loses the race", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("GetActive", mock.Anything).Return(active, nil)
+		mockRepo.On("CreateIfActive", mock.Anything, []int{250, 500, 1000}, mock.Anything, mock.Anything, 3).Return(nil, nil)
+
+		w := update(newRouter(mockRepo), `"pack-sizes-3"`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("update with if-match and no active configuration", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("GetActive", mock.Anything).Return(nil, nil)
+
+		w := update(newRouter(mockRepo), `*`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestPackSizesHandler_ResponseCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	oldConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Active: true, Version: 1}
+	newConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{23, 31, 53}, Active: true, Version: 2}
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	mockRepo.On("GetActive", mock.Anything).Return(oldConfig, nil).Once()
+	mockRepo.On("Create", mock.Anything, newConfig.Sizes, mock.Anything, mock.Anything).Return(newConfig, nil)
+	mockRepo.On("GetActive", mock.Anything).Return(newConfig, nil).Once()
+
+	routes := NewPackRoutes(service.NewPackCalculatorService(), service.NewPackSizesService(mockRepo),
+		WithResponseCache(middleware.NewResponseCache(time.Minute, 0)))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/pack-sizes", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	first := get()
+	second := get()
+	assert.Equal(t, "HIT", second.Header().Get(middleware.ResponseCacheHeader))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+
+	req := httptest.NewRequest(http.MethodPut, "/api/pack-sizes", strings.NewReader(`{"sizes": [23, 31, 53]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// The update drops the cached response
+	w := get()
+	assert.Equal(t, "MISS", w.Header().Get(middleware.ResponseCacheHeader))
+	assert.Equal(t, `"pack-sizes-2"`, w.Header().Get("ETag"))
+	mockRepo.AssertExpectations(t)
+}
+
 func TestPackSizesHandler_parseInt(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -276,3 +608,387 @@ func TestPackSizesHandler_parseInt(t *testing.T) {
 		})
 	}
 }
+
+// TestPackSizesHandler_UpdatePackSizes_ReadYourWrites reproduces the race where a
+// calculation right after an update still used the previously cached sizes.
+func TestPackSizesHandler_UpdatePackSizes_ReadYourWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	oldConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Version: 1}
+	newConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{23, 31, 53}, Version: 1}
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	// A lagging read keeps returning the old config, as a stale secondary would.
+	mockRepo.On("GetActive", mock.Anything).Return(oldConfig, nil)
+	mockRepo.On("Create", mock.Anything, newConfig.Sizes, mock.Anything, mock.Anything).Return(newConfig, nil)
+
+	routes := NewPackRoutes(service.NewPackCalculatorService(), service.NewPackSizesService(mockRepo))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	calculate := func() []int {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"items_ordered": 263}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data struct {
+				Packs []struct {
+					Size int `json:"size"`
+				} `json:"packs"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		sizes := make([]int, 0, len(resp.Data.Packs))
+		for _, p := range resp.Data.Packs {
+			sizes = append(sizes, p.Size)
+		}
+		return sizes
+	}
+
+	// Warm the cache with the old configuration
+	for _, size := range calculate() {
+		assert.Contains(t, oldConfig.Sizes, size)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/pack-sizes", bytes.NewBufferString(`{"sizes": [23, 31, 53]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The very next calculation must see the write
+	sizes := calculate()
+	assert.NotEmpty(t, sizes)
+	for _, size := range sizes {
+		assert.Contains(t, newConfig.Sizes, size)
+	}
+}
+
+// replicaChanges reports a pack size change for every value sent on it.
+type replicaChanges chan struct{}
+
+func (r replicaChanges) WatchPackSizes(ctx context.Context, fn func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r:
+			fn()
+		}
+	}
+}
+
+func TestHandler_CalculatePacks_PackSizesChangedByOtherReplica(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	oldConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Version: 1}
+	newConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{23, 31, 53}, Version: 2}
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	mockRepo.On("GetActive", mock.Anything).Return(oldConfig, nil).Once()
+	mockRepo.On("GetActive", mock.Anything).Return(newConfig, nil)
+
+	changes := make(replicaChanges)
+	routes := NewPackRoutes(service.NewPackCalculatorService(), service.NewPackSizesService(mockRepo),
+		WithPackSizesWatcher(changes))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	calculate := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"items_ordered": 263}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	// Cached until the change is reported, well within the cache TTL
+	assert.Contains(t, calculate(), `"size":500`)
+	assert.Contains(t, calculate(), `"size":500`)
+	mockRepo.AssertNumberOfCalls(t, "GetActive", 1)
+
+	changes <- struct{}{}
+	assert.Eventually(t, func() bool {
+		return strings.Contains(calculate(), `"size":31`)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHandler_CalculatePacks_ActivePackSizes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	oldConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Version: 1}
+	newConfig := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{23, 31, 53}, Version: 2}
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	mockRepo.On("GetActive", mock.Anything).Return(oldConfig, nil)
+
+	packSizesService := service.NewPackSizesService(mockRepo)
+	view := service.NewActivePackSizes(packSizesService, nil, time.Hour)
+	assert.NoError(t, view.Reload(context.Background()))
+	changes := make(replicaChanges)
+	routes := NewPackRoutes(service.NewPackCalculatorService(), packSizesService,
+		WithActivePackSizes(view), WithPackSizesWatcher(changes))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	calculate := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"items_ordered": 263}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	for range 3 {
+		assert.Contains(t, calculate(), `"size":500`)
+	}
+
+	// The view takes the place of the watcher
+	select {
+	case changes <- struct{}{}:
+		t.Fatal("the pack sizes watcher should not be used with a view")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// An update on this instance applies to the next calculation
+	routes.packSizesHandler.applyPackSizes(newConfig)
+	assert.Contains(t, calculate(), `"size":31`)
+
+	// Calculations read the view, never the database: the only reads are
+	// the load at startup and the one when the routes started the view
+	view.Stop()
+	mockRepo.AssertNumberOfCalls(t, "GetActive", 2)
+}
+
+func TestHandler_CalculatePacks_AuditsConfigVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	mockRepo.On("GetActive", mock.Anything).Return(&repository.PackSizeConfig{Sizes: []int{250, 500}, Version: 4}, nil)
+
+	logged := make(chan *model.LogEntry, 1)
+	mockLogging := new(mocks.MockLoggingService)
+	mockLogging.On("CreateLog", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entry, _ := args.Get(1).(*model.LogEntry)
+		logged <- entry
+	}).Return(nil)
+
+	handler := NewHandler(service.NewPackCalculatorService(), service.NewPackSizesService(mockRepo))
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("logging_service", mockLogging)
+		c.Next()
+	})
+	router.POST("/calculate", handler.CalculatePacks)
+
+	req := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewBufferString(`{"items_ordered": 251}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	select {
+	case entry := <-logged:
+		assert.Equal(t, model.ActionCalculate, entry.ActionType)
+		assert.Equal(t, 4, entry.Fields[model.FieldPackSizesVersion])
+	case <-time.After(time.Second):
+		t.Fatal("calculation was not audited")
+	}
+}
+
+func TestPackSizesHandler_UpdatePackSizes_DeprecatedCreatedBy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := primitive.NewObjectID()
+	config := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Version: 2}
+
+	tests := []struct {
+		name          string
+		body          map[string]interface{}
+		wantCreatedBy string
+		wantWarning   bool
+	}{
+		{
+			name:          "created_by is honoured but flagged",
+			body:          map[string]interface{}{"sizes": []int{250, 500}, "created_by": "ops"},
+			wantCreatedBy: "ops",
+			wantWarning:   true,
+		},
+		{
+			name:          "authenticated user is recorded when created_by is omitted",
+			body:          map[string]interface{}{"sizes": []int{250, 500}},
+			wantCreatedBy: userID.Hex(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+			mockRepo.On("Create", mock.Anything, []int{250, 500}, mock.Anything, tt.wantCreatedBy).Return(config, nil)
+			t.Cleanup(func() { mockRepo.AssertExpectations(t) })
+
+			tracker := deprecation.NewTracker()
+			handler := NewPackSizesHandler(service.NewPackSizesService(mockRepo), nil)
+			handler.deprecations = tracker
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", userID)
+				c.Next()
+			})
+			router.PUT("/pack-sizes", handler.UpdatePackSizes)
+
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPut, "/pack-sizes", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var resp dto.SuccessResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			usage := tracker.Report().Usage
+			if !tt.wantWarning {
+				assert.Empty(t, resp.Warnings)
+				assert.Empty(t, usage)
+				return
+			}
+			if assert.Len(t, resp.Warnings, 1) {
+				assert.Equal(t, deprecation.WarningCode, resp.Warnings[0].Code)
+				assert.Equal(t, "created_by", resp.Warnings[0].Field)
+			}
+			if assert.Len(t, usage, 1) {
+				assert.Equal(t, "user:"+userID.Hex(), usage[0].Client)
+				assert.Equal(t, "PUT /pack-sizes", usage[0].Endpoint)
+			}
+		})
+	}
+}
+
+func TestPackSizesHandler_ActivatePackSizes(t *testing.T) {
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name           string
+		id             string
+		setupMocks     func(*mocks.MockPackSizesRepositoryInterface, *mocks.MockLoggingService)
+		expectedStatus int
+	}{
+		{
+			name: "successful activate",
+			id:   id.Hex(),
+			setupMocks: func(mockRepo *mocks.MockPackSizesRepositoryInterface, mockLogging *mocks.MockLoggingService) {
+				mockRepo.On("GetActive", mock.Anything).Return(&repository.PackSizeConfig{Sizes: []int{250, 500}, Version: 3}, nil)
+				mockRepo.On("Activate", mock.Anything, id, mock.Anything).Return(&repository.PackSizeConfig{ID: id, Sizes: []int{100, 200}, Version: 1, Active: true}, nil)
+				mockLogging.On("CreateLog", mock.Anything, mock.MatchedBy(func(entry *model.LogEntry) bool {
+					return entry.ActionType == "activate_pack_sizes" &&
+						entry.Fields["version"] == 1 && entry.Fields["previous_version"] == 3
+				})).Maybe().Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid id",
+			id:             "not-an-id",
+			setupMocks:     func(*mocks.MockPackSizesRepositoryInterface, *mocks.MockLoggingService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "unknown configuration",
+			id:   id.Hex(),
+			setupMocks: func(mockRepo *mocks.MockPackSizesRepositoryInterface, mockLogging *mocks.MockLoggingService) {
+				mockRepo.On("GetActive", mock.Anything).Return(nil, nil)
+				mockRepo.On("Activate", mock.Anything, id, mock.Anything).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "repository error",
+			id:   id.Hex(),
+			setupMocks: func(mockRepo *mocks.MockPackSizesRepositoryInterface, mockLogging *mocks.MockLoggingService) {
+				mockRepo.On("GetActive", mock.Anything).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+			mockLogging := new(mocks.MockLoggingService)
+
+			tt.setupMocks(mockRepo, mockLogging)
+
+			handler := NewPackSizesHandler(service.NewPackSizesService(mockRepo), nil)
+			router.Use(func(c *gin.Context) {
+				c.Set("logging_service", mockLogging)
+				c.Next()
+			})
+			router.POST("/pack-sizes/:id/activate", handler.ActivatePackSizes)
+
+			req := httptest.NewRequest(http.MethodPost, "/pack-sizes/"+tt.id+"/activate", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPackSizesHandler_RollbackPackSizes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	current := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500}, Version: 2, Active: true}
+	previous := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{23, 31, 53}, Version: 1}
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	mockRepo.On("GetActive", mock.Anything).Return(current, nil)
+	mockRepo.On("PreviousVersion", mock.Anything, 2).Return(previous, nil).Once()
+	mockRepo.On("Activate", mock.Anything, previous.ID, mock.Anything).Return(
+		&repository.PackSizeConfig{ID: previous.ID, Sizes: previous.Sizes, Version: 1, Active: true}, nil)
+
+	routes := NewPackRoutes(service.NewPackCalculatorService(), service.NewPackSizesService(mockRepo))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/rollback", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The calculation cache is primed with the rolled back sizes, although
+	// reads still return the old configuration
+	req = httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"items_ordered": 263}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data struct {
+			Packs []struct {
+				Size int `json:"size"`
+			} `json:"packs"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.Data.Packs)
+	for _, p := range resp.Data.Packs {
+		assert.Contains(t, previous.Sizes, p.Size)
+	}
+
+	// Once no configuration precedes the active one, there is nothing to roll back to
+	mockRepo.On("PreviousVersion", mock.Anything, 2).Return(nil, nil)
+	req = httptest.NewRequest(http.MethodPost, "/api/pack-sizes/rollback", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// GetPackSizesMigration handles GET /api/pack-sizes/migration requests.
+//
+// @Summary      Get pack size migration
+// @Description  Returns the current or last staged pack size migration: the staged and baseline sizes, the comparison of recent stored calculations (replay) and of live traffic (shadow), and whether it can be promoted. A shadowing migration whose window has ended is reported as ready.
+// @Tags         Pack Sizes
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Success      200 {object} dto.SuccessResponse{data=dto.PackSizesMigration} "Migration"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "No migration was started"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/migration [get]
+func (h *PackSizesHandler) GetPackSizesMigration(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	migration, err := h.migrator.Status()
+	if err != nil {
+		h.writeMigrationError(builder, err)
+		return
+	}
+	builder.SuccessOK(migration)
+}
+
+// StartPackSizesMigration handles POST /api/pack-sizes/migration requests.
+//
+// @Summary      Stage pack sizes
+// @Description  Stages new pack sizes without activating them. Recent stored calculations made with the active configuration are replayed with them right away, and live calculations are compared with them in the background for the shadow window. Live traffic is only compared on the instance that received this request.
+// @Tags         Pack Sizes
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.StartPackSizesMigrationRequest true "Staged pack sizes"
+// @Success      201 {object} dto.SuccessResponse{data=dto.PackSizesMigration} "Migration"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid request body, or the sizes break a pack size rule"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      409 {object} dto.ErrorResponse "A migration is in progress, or there is no active configuration"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/migration [post]
+func (h *PackSizesHandler) StartPackSizesMigration(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.StartPackSizesMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if violations := h.rules.Check(req.Sizes); service.HasPackSizeErrors(violations) {
+		ruleViolations(builder, violations)
+		return
+	}
+
+	window := time.Duration(req.ShadowMinutes) * time.Minute
+	migration, err := h.migrator.Start(c.Request.Context(), req.Sizes, window, userIDFromContext(c))
+	if err != nil {
+		h.writeMigrationError(builder, err)
+		return
+	}
+
+	h.auditMigration(c, "start_pack_sizes_migration", "Pack sizes migration started", migration)
+	builder.SuccessCreated(migration)
+}
+
+// PromotePackSizesMigration handles POST /api/pack-sizes/migration/promote requests.
+//
+// @Summary      Promote staged pack sizes
+// @Description  Stores the staged pack sizes as the new active configuration, which calculations use immediately. Allowed once the shadow window has ended, or earlier with force. Fails if the active configuration changed since the migration started.
+// @Tags         Pack Sizes
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        force query bool false "Promote before the shadow window has ended"
+// @Success      200 {object} dto.SuccessResponse{data=dto.PackSizesMigration} "Promoted migration"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid force"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "No migration in progress"
+// @Failure      409 {object} dto.ErrorResponse "Shadow window not over, or the active configuration changed"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/migration/promote [post]
+func (h *PackSizesHandler) PromotePackSizesMigration(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	force := false
+	if raw := c.Query("force"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+			return
+		}
+		force = parsed
+	}
+
+	config, migration, err := h.migrator.Promote(c.Request.Context(), userIDFromContext(c), force)
+	if err != nil {
+		h.writeMigrationError(builder, err)
+		return
+	}
+	h.applyPackSizes(config)
+
+	h.auditMigration(c, "promote_pack_sizes_migration", "Pack sizes migration promoted", migration)
+	builder.SuccessOK(migration)
+}
+
+// CancelPackSizesMigration handles DELETE /api/pack-sizes/migration requests.
+//
+// @Summary      Cancel pack size migration
+// @Description  Abandons the staged pack sizes. The active configuration is unchanged.
+// @Tags         Pack Sizes
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Success      200 {object} dto.SuccessResponse{data=dto.PackSizesMigration} "Cancelled migration"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "No migration in progress"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/migration [delete]
+func (h *PackSizesHandler) CancelPackSizesMigration(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	migration, err := h.migrator.Cancel()
+	if err != nil {
+		h.writeMigrationError(builder, err)
+		return
+	}
+
+	h.auditMigration(c, "cancel_pack_sizes_migration", "Pack sizes migration cancelled", migration)
+	builder.SuccessOK(migration)
+}
+
+// writeMigrationError maps migrator errors to HTTP responses. A migration
+// cannot start without an active configuration, a conflict rather than a
+// missing resource.
+func (h *PackSizesHandler) writeMigrationError(builder *ResponseBuilder, err error) {
+	if errors.Is(err, service.ErrPackSizesNotFound) {
+		builder.Error(http.StatusConflict, i18n.ErrKeyNoActivePackSizes, err)
+		return
+	}
+	builder.ErrorFrom(err)
+}
+
+// auditMigration records a migration step with the divergence seen so far.
+func (h *PackSizesHandler) auditMigration(c *gin.Context, action, message string, migration dto.PackSizesMigration) {
+	loggingService, exists := c.Get("logging_service")
+	if !exists {
+		return
+	}
+	ls, ok := loggingService.(service.LoggingService)
+	if !ok {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"migration_id":     migration.ID,
+		"pack_sizes":       migration.Sizes,
+		"baseline_version": migration.BaselineVersion,
+		"replay_compared":  migration.Replay.Compared,
+		"replay_diverged":  migration.Replay.Diverged,
+		"shadow_compared":  migration.Shadow.Compared,
+		"shadow_diverged":  migration.Shadow.Diverged,
+	}
+	if migration.PromotedVersion > 0 {
+		fields["version"] = migration.PromotedVersion
+	}
+	middleware.AuditLog(ls, c, action, message, fields)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPackSizesMigration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	baseline := &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{250, 500, 1000}, Version: 3, Active: true}
+	staged := []int{250, 500, 750, 1000}
+
+	mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+	mockRepo.On("GetActive", mock.Anything).Return(baseline, nil)
+	mockRepo.On("Create", mock.Anything, staged, mock.Anything, mock.Anything).Return(
+		&repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: staged, Version: 4, Active: true}, nil)
+
+	calculator := service.NewPackCalculatorService()
+	packSizesService := service.NewPackSizesService(mockRepo)
+	migrator := service.NewPackSizesMigrator(packSizesService, calculator, nil)
+	routes := NewPackRoutes(calculator, packSizesService, WithPackSizesMigrator(migrator))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	do := func(method, path, body string) (*httptest.ResponseRecorder, dto.PackSizesMigration) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Data dto.PackSizesMigration `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.Data
+	}
+
+	w, _ := do(http.MethodGet, "/api/pack-sizes/migration", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = do(http.MethodPost, "/api/pack-sizes/migration", `{"sizes": [0]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, migration := do(http.MethodPost, "/api/pack-sizes/migration", `{"sizes": [250, 500, 750, 1000], "shadow_minutes": 30}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, service.MigrationShadowing, migration.Status)
+	assert.Equal(t, 3, migration.BaselineVersion)
+	assert.Equal(t, 30*time.Minute, migration.ShadowEndsAt.Sub(migration.StartedAt))
+
+	w, _ = do(http.MethodPost, "/api/pack-sizes/migration", `{"sizes": [500]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Live calculations with the stored sizes are compared in the background
+	w, _ = do(http.MethodPost, "/api/calculate", `{"items_ordered": 750}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"size":500`)
+	require.Eventually(t, func() bool {
+		_, migration := do(http.MethodGet, "/api/pack-sizes/migration", "")
+		return migration.Shadow.Compared == 1
+	}, time.Second, time.Millisecond)
+	_, migration = do(http.MethodGet, "/api/pack-sizes/migration", "")
+	assert.Equal(t, int64(1), migration.Shadow.Diverged)
+
+	w, _ = do(http.MethodPost, "/api/pack-sizes/migration/promote", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, migration = do(http.MethodPost, "/api/pack-sizes/migration/promote?force=true", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, service.MigrationPromoted, migration.Status)
+	assert.Equal(t, 4, migration.PromotedVersion)
+
+	// The next calculation uses the promoted sizes
+	w, _ = do(http.MethodPost, "/api/calculate", `{"items_ordered": 750}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"size":750`)
+
+	w, _ = do(http.MethodDelete, "/api/pack-sizes/migration", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
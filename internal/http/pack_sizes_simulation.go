@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// SimulatePackSizes handles POST /api/pack-sizes/simulate requests.
+//
+// @Summary      Simulate pack sizes
+// @Description  Calculates a list or a distribution of order quantities with two pack size sets and compares them: items shipped, overage, packs per order and the worst orders of each. Nothing is stored; use it to evaluate new pack sizes before activating them.
+// @Tags         Pack Sizes
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.SimulatePackSizesRequest true "Pack size sets and orders"
+// @Success      200 {object} dto.SuccessResponse{data=dto.PackSizesSimulation} "Comparison"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid request body, no or too many orders, or sizes breaking a pack size rule"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      422 {object} dto.ErrorResponse "An order is above the maximum order"
+// @Failure      503 {object} dto.ErrorResponse "Too many heavy requests in progress"
+// @Security     BearerAuth
+// @Router       /api/pack-sizes/simulate [post]
+func (h *PackSizesHandler) SimulatePackSizes(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.SimulatePackSizesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	orders, err := req.SimulationOrders()
+	if err != nil {
+		builder.ErrorWithMessage(http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	maxItems := service.MaxItemsOrdered(h.calculator)
+	for _, order := range orders {
+		if order.ItemsOrdered > maxItems {
+			ordersTooLarge(builder, "orders", maxItems)
+			return
+		}
+	}
+
+	// Both sets are calculated, so they must be sizes that could be saved
+	var violations []dto.PackSizeViolation
+	for _, set := range []struct {
+		field string
+		sizes []int
+	}{{"sizes_a", req.SizesA}, {"sizes_b", req.SizesB}} {
+		for _, v := range h.rules.Check(set.sizes) {
+			v.Rule = set.field + "." + v.Rule
+			violations = append(violations, v)
+		}
+	}
+	if service.HasPackSizeErrors(violations) {
+		ruleViolations(builder, violations)
+		return
+	}
+
+	builder.SuccessOK(service.SimulatePackSizes(h.calculator, req.SizesA, req.SizesB, orders))
+}
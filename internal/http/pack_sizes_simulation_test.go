@@ -0,0 +1,65 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackSizesHandler_SimulatePackSizes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	routes := NewPackRoutes(service.NewPackCalculatorService(), service.NewPackSizesService(new(mocks.MockPackSizesRepositoryInterface)))
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	simulate := func(body string) (*httptest.ResponseRecorder, dto.PackSizesSimulation) {
+		req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/simulate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Data dto.PackSizesSimulation `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.Data
+	}
+
+	t.Run("compares both sets", func(t *testing.T) {
+		w, simulation := simulate(`{"sizes_a": [250, 500, 1000], "sizes_b": [250, 500, 800],
+			"orders": [251], "distribution": [{"items_ordered": 751, "count": 3}]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(4), simulation.A.Orders)
+		assert.Equal(t, int64(4*249), simulation.A.OverageItems)
+		assert.Equal(t, int64(249+3*49), simulation.B.OverageItems)
+		assert.Equal(t, int64(3), simulation.BetterWithB)
+		assert.NotEmpty(t, simulation.B.WorstCases)
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "missing sizes", body: `{"sizes_a": [250], "orders": [251]}`, wantStatus: http.StatusBadRequest},
+		{name: "no orders", body: `{"sizes_a": [250], "sizes_b": [500]}`, wantStatus: http.StatusBadRequest},
+		{name: "zero count", body: `{"sizes_a": [250], "sizes_b": [500], "distribution": [{"items_ordered": 1, "count": 0}]}`, wantStatus: http.StatusBadRequest},
+		{name: "sizes break a rule", body: `{"sizes_a": [250, 250], "sizes_b": [500], "orders": [251]}`, wantStatus: http.StatusBadRequest},
+		{name: "order too large", body: `{"sizes_a": [250], "sizes_b": [500], "orders": [20000000]}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := simulate(tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/pagination"
+)
+
+// pageOffset returns the offset of the page asked for: the one held by the
+// cursor query parameter when given, else the offset parameter. A cursor is
+// only accepted by the listing that issued it, with the same filter.
+func pageOffset(c *gin.Context, cursors *pagination.Codec, listing string) (int, error) {
+	cursor := c.Query("cursor")
+	if cursor == "" {
+		return queryInt(c, "offset")
+	}
+	if c.Query("offset") != "" {
+		return 0, &dto.ValidationError{Field: "cursor", Message: "cannot be combined with offset"}
+	}
+	if cursors == nil {
+		return 0, pagination.ErrInvalidCursor
+	}
+	return cursors.Decode(cursorScope(c, listing), cursor)
+}
+
+// nextCursor returns the cursor of the page after the n items listed from
+// offset, or "" when they are the last of total.
+func nextCursor(c *gin.Context, cursors *pagination.Codec, listing string, offset, n int, total int64) string {
+	next := max(offset, 0) + n
+	if cursors == nil || n == 0 || int64(next) >= total {
+		return ""
+	}
+	return cursors.Encode(cursorScope(c, listing), next)
+}
+
+// cursorScope binds cursors to listing and its filter: every query
+// parameter but the page ones.
+func cursorScope(c *gin.Context, listing string) string {
+	query := c.Request.URL.Query()
+	query.Del("cursor")
+	query.Del("offset")
+	query.Del("limit")
+	return listing + "?" + query.Encode()
+}
+
+// writeQueryError rejects an invalid query string, with a code telling
+// clients whose cursor was rejected to restart from the first page.
+func writeQueryError(builder *ResponseBuilder, err error) {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		builder.ErrorWithCode(http.StatusBadRequest, dto.ErrCodeInvalidCursor, i18n.ErrKeyInvalidCursor, err)
+		return
+	}
+	builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+}
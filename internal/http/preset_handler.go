@@ -0,0 +1,200 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// PresetHandler provides HTTP handlers for calculation preset routes.
+type PresetHandler struct {
+	presetService service.PresetService
+}
+
+// NewPresetHandler creates a new PresetHandler instance.
+func NewPresetHandler(presetService service.PresetService) *PresetHandler {
+	return &PresetHandler{presetService: presetService}
+}
+
+// presetOwner returns the owner presets are scoped to: the authenticated
+// user, or the shared empty owner when authentication is disabled.
+func presetOwner(c *gin.Context) string {
+	return userIDFromContext(c)
+}
+
+// CreatePreset handles POST /api/presets requests.
+//
+// @Summary      Create calculation preset
+// @Description  Saves a named pack size configuration that calculate requests can reference with the preset field
+// @Tags         Presets
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.PresetRequest true "Preset"
+// @Success      201 {object} dto.SuccessResponse "Created preset"
+// @Failure      400 {object} dto.ErrorResponse "Bad request"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      409 {object} dto.ErrorResponse "A preset with this name already exists"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/presets [post]
+func (h *PresetHandler) CreatePreset(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.PresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		builder.Error(http.StatusBadRequest, validationMessageKey(err), err)
+		return
+	}
+
+	preset, err := h.presetService.Create(c.Request.Context(), presetOwner(c), &model.Preset{
+		Name:        req.Name,
+		Description: req.Description,
+		PackSizes:   req.PackSizes,
+		Constraints: req.Constraints(),
+	})
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "create_preset", "Calculation preset created", preset)
+	builder.SuccessCreated(preset)
+}
+
+// ListPresets handles GET /api/presets requests.
+//
+// @Summary      List calculation presets
+// @Description  Returns the caller's saved presets, sorted by name
+// @Tags         Presets
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Success      200 {object} dto.SuccessResponse "Presets"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/presets [get]
+func (h *PresetHandler) ListPresets(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	presets, err := h.presetService.List(c.Request.Context(), presetOwner(c))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	builder.SuccessOK(presets)
+}
+
+// GetPreset handles GET /api/presets/:name requests.
+//
+// @Summary      Get calculation preset
+// @Description  Returns one of the caller's saved presets
+// @Tags         Presets
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        name path string true "Preset name"
+// @Success      200 {object} dto.SuccessResponse "Preset"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "Preset not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/presets/{name} [get]
+func (h *PresetHandler) GetPreset(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	preset, err := h.presetService.Get(c.Request.Context(), presetOwner(c), c.Param("name"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	builder.SuccessOK(preset)
+}
+
+// UpdatePreset handles PUT /api/presets/:name requests.
+//
+// @Summary      Update calculation preset
+// @Description  Replaces the description and pack sizes of one of the caller's presets
+// @Tags         Presets
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        name path string true "Preset name"
+// @Param        request body dto.UpdatePresetRequest true "Preset changes"
+// @Success      200 {object} dto.SuccessResponse "Updated preset"
+// @Failure      400 {object} dto.ErrorResponse "Bad request"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "Preset not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/presets/{name} [put]
+func (h *PresetHandler) UpdatePreset(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.UpdatePresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		builder.Error(http.StatusBadRequest, validationMessageKey(err), err)
+		return
+	}
+
+	preset, err := h.presetService.Update(c.Request.Context(), presetOwner(c), c.Param("name"), req.Description, req.PackSizes, req.Constraints())
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "update_preset", "Calculation preset updated", preset)
+	builder.SuccessOK(preset)
+}
+
+// DeletePreset handles DELETE /api/presets/:name requests.
+//
+// @Summary      Delete calculation preset
+// @Description  Deletes one of the caller's presets
+// @Tags         Presets
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        name path string true "Preset name"
+// @Success      204 "Preset deleted"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "Preset not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/presets/{name} [delete]
+func (h *PresetHandler) DeletePreset(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	name := c.Param("name")
+	if err := h.presetService.Delete(c.Request.Context(), presetOwner(c), name); err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "delete_preset", "Calculation preset deleted", &model.Preset{Name: name})
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PresetHandler) audit(c *gin.Context, action, message string, preset *model.Preset) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, action, message, map[string]interface{}{
+				"preset":     preset.Name,
+				"pack_sizes": preset.PackSizes,
+			})
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testPresetOwner = "507f1f77bcf86cd799439011"
+
+// setupPresetRouter registers the preset routes with the given user ID set in
+// context, mimicking the JWT middleware. An empty userID means auth is disabled.
+func setupPresetRouter(mockPresets *mocks.MockPresetService, userID string) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != "" {
+			id, _ := primitive.ObjectIDFromHex(userID)
+			c.Set("user_id", id)
+		}
+		c.Next()
+	})
+	NewPresetRoutes(mockPresets).RegisterPublicRoutes(router.Group("/api"))
+	return router
+}
+
+func TestPresetHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		userID         string
+		setupMock      func(*mocks.MockPresetService)
+		expectedStatus int
+	}{
+		{
+			name:   "create preset",
+			method: http.MethodPost,
+			path:   "/api/presets",
+			body:   `{"name": "warehouse-a", "pack_sizes": [23, 31, 53]}`,
+			userID: testPresetOwner,
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Create(mock.Anything, testPresetOwner, mock.MatchedBy(func(p *model.Preset) bool {
+					return p.Name == "warehouse-a" && len(p.PackSizes) == 3
+				})).RunAndReturn(func(_ context.Context, owner string, p *model.Preset) (*model.Preset, error) {
+					p.Owner = owner
+					return p, nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create preset with missing fields",
+			method:         http.MethodPost,
+			path:           "/api/presets",
+			body:           `{"name": "warehouse-a"}`,
+			setupMock:      func(*mocks.MockPresetService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "create invalid preset",
+			method: http.MethodPost,
+			path:   "/api/presets",
+			body:   `{"name": "bad name", "pack_sizes": [23]}`,
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Create(mock.Anything, "", mock.Anything).Return(nil, service.ErrInvalidPreset)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "create duplicate preset",
+			method: http.MethodPost,
+			path:   "/api/presets",
+			body:   `{"name": "warehouse-a", "pack_sizes": [23]}`,
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Create(mock.Anything, "", mock.Anything).Return(nil, service.ErrPresetExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "list presets",
+			method: http.MethodGet,
+			path:   "/api/presets",
+			userID: testPresetOwner,
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().List(mock.Anything, testPresetOwner).Return([]*model.Preset{{Name: "warehouse-a"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "list presets failure",
+			method: http.MethodGet,
+			path:   "/api/presets",
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().List(mock.Anything, "").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "get preset",
+			method: http.MethodGet,
+			path:   "/api/presets/warehouse-a",
+			userID: testPresetOwner,
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Get(mock.Anything, testPresetOwner, "warehouse-a").Return(&model.Preset{Name: "warehouse-a"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get missing preset",
+			method: http.MethodGet,
+			path:   "/api/presets/missing",
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Get(mock.Anything, "", "missing").Return(nil, service.ErrPresetNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "update preset",
+			method: http.MethodPut,
+			path:   "/api/presets/warehouse-a",
+			body:   `{"description": "updated", "pack_sizes": [31]}`,
+			userID: testPresetOwner,
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Update(mock.Anything, testPresetOwner, "warehouse-a", "updated", []int{31}, (*model.PackConstraints)(nil)).
+					Return(&model.Preset{Name: "warehouse-a", PackSizes: []int{31}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "update preset with invalid body",
+			method:         http.MethodPut,
+			path:           "/api/presets/warehouse-a",
+			body:           `{`,
+			setupMock:      func(*mocks.MockPresetService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "delete preset",
+			method: http.MethodDelete,
+			path:   "/api/presets/warehouse-a",
+			userID: testPresetOwner,
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Delete(mock.Anything, testPresetOwner, "warehouse-a").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "delete missing preset",
+			method: http.MethodDelete,
+			path:   "/api/presets/missing",
+			setupMock: func(m *mocks.MockPresetService) {
+				m.EXPECT().Delete(mock.Anything, "", "missing").Return(service.ErrPresetNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPresets := mocks.NewMockPresetService(t)
+			tt.setupMock(mockPresets)
+			router := setupPresetRouter(mockPresets, tt.userID)
+
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
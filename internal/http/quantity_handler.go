@@ -0,0 +1,111 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/fixedpoint"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// CalculateQuantity handles POST /api/calculate/quantity requests.
+//
+// @Summary      Calculate packs for a decimal quantity
+// @Description  Calculates the packs for an order of a product packed by weight or volume, such as 12.5 kg in bags of 2.5 and 5 kg. The quantity and pack sizes are decimal numbers, or strings holding them, with at most the server's precision of decimal places (3 by default), and are calculated exactly in fixed point rather than as floating point numbers. Like /api/calculate, the result ships the smallest quantity covering the order, then uses the fewest packs. Optional max_packs and max_overage_percent restrict the acceptable combinations; when none qualifies the response is 422.
+// @Tags         Packs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.CalculateQuantityRequest true "Order quantity and pack sizes"
+// @Success      200 {object} dto.SuccessResponse{data=dto.QuantityResult} "Successful calculation"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid quantity, pack sizes or constraints"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - insufficient permissions"
+// @Failure      422 {object} dto.ErrorResponse "The order is too large to calculate exactly, or no pack combination satisfies the constraints"
+// @Failure      429 {object} dto.ErrorResponse "Too many requests - rate limit exceeded"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/calculate/quantity [post]
+func (h *Handler) CalculateQuantity(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.CalculateQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+
+	err := req.Validate()
+	var quantity int
+	var sizes []int
+	if err == nil {
+		quantity, sizes, err = req.Units(h.decimalPrecision)
+	}
+	if err != nil {
+		metrics.RecordPackCalculation(0, "validation_error")
+		h.quantityValidationError(c, builder, err)
+		return
+	}
+
+	start := time.Now()
+	result, err := service.CalculateUnits(h.calculator, quantity, sizes, req.Constraints())
+	duration := time.Since(start)
+
+	if errors.Is(err, service.ErrConstraintsUnsatisfiable) {
+		metrics.RecordPackCalculation(duration, "unsatisfiable")
+		builder.Error(http.StatusUnprocessableEntity, i18n.ErrKeyConstraintsUnsatisfiable, err)
+		return
+	}
+	if errors.Is(err, service.ErrOrderTooLarge) {
+		metrics.RecordPackCalculation(duration, "validation_error")
+		builder.Error(http.StatusUnprocessableEntity, i18n.ErrKeyValidationQuantityTooLarge, err)
+		return
+	}
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	metrics.RecordPackCalculation(duration, calculationStatus(result))
+	builder.SuccessOK(quantityResult(result, h.decimalPrecision))
+}
+
+// quantityValidationError responds 400 to an invalid quantity request,
+// stating the precision in the message when it is about a decimal.
+func (h *Handler) quantityValidationError(c *gin.Context, builder *ResponseBuilder, err error) {
+	switch err {
+	case dto.ErrInvalidQuantity:
+		builder.ErrorWithMessage(http.StatusBadRequest,
+			fmt.Sprintf(i18n.Message(c, i18n.ErrKeyValidationQuantity), h.decimalPrecision), err)
+	case dto.ErrInvalidPackQuantities:
+		builder.ErrorWithMessage(http.StatusBadRequest,
+			fmt.Sprintf(i18n.Message(c, i18n.ErrKeyValidationPackQuantities), h.decimalPrecision), err)
+	default:
+		builder.Error(http.StatusBadRequest, validationMessageKey(err), err)
+	}
+}
+
+// quantityResult formats a result counted in units of precision decimal
+// places.
+func quantityResult(result model.PackResult, precision int) dto.QuantityResult {
+	packs := make([]dto.QuantityPack, len(result.Packs))
+	for i, pack := range result.Packs {
+		packs[i] = dto.QuantityPack{
+			Size:     dto.Decimal(fixedpoint.Format(int64(pack.Size), precision)),
+			Quantity: pack.Quantity,
+		}
+	}
+	return dto.QuantityResult{
+		OrderedQuantity: dto.Decimal(fixedpoint.Format(int64(result.OrderedItems), precision)),
+		TotalQuantity:   dto.Decimal(fixedpoint.Format(int64(result.TotalItems), precision)),
+		Packs:           packs,
+		Precision:       precision,
+	}
+}
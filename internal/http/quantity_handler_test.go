@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateQuantity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		precision  int
+		body       string
+		wantStatus int
+		want       dto.QuantityResult
+		wantError  string
+	}{
+		{
+			name:       "decimal weights",
+			body:       `{"quantity": 12.6, "pack_sizes": [2.5, 5]}`,
+			wantStatus: http.StatusOK,
+			want: dto.QuantityResult{
+				OrderedQuantity: "12.6",
+				TotalQuantity:   "15",
+				Packs:           []dto.QuantityPack{{Size: "5", Quantity: 3}},
+				Precision:       3,
+			},
+		},
+		{
+			name:       "decimals as strings",
+			body:       `{"quantity": "0.75", "pack_sizes": ["0.25", "0.5"]}`,
+			wantStatus: http.StatusOK,
+			want: dto.QuantityResult{
+				OrderedQuantity: "0.75",
+				TotalQuantity:   "0.75",
+				Packs:           []dto.QuantityPack{{Size: "0.5", Quantity: 1}, {Size: "0.25", Quantity: 1}},
+				Precision:       3,
+			},
+		},
+		{
+			name:       "more decimal places than the precision",
+			precision:  1,
+			body:       `{"quantity": 2.25, "pack_sizes": [1]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "quantity: must be a positive number with at most 1 decimal places",
+		},
+		{
+			name:       "invalid pack size",
+			body:       `{"quantity": 2, "pack_sizes": [1, -1]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "pack_sizes: 1 to 100 positive numbers are required, each with at most 3 decimal places",
+		},
+		{
+			name:       "missing pack sizes",
+			body:       `{"quantity": 2}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsatisfiable constraints",
+			body:       `{"quantity": 10, "pack_sizes": [2.5], "max_packs": 3}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "too large to calculate exactly",
+			body:       `{"quantity": 100000.001, "pack_sizes": [1]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  "quantity: the order is too large to calculate exactly with these pack sizes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(service.NewPackCalculatorService(), nil, WithDecimalPrecision(tt.precision))
+			router := gin.New()
+			router.POST("/api/calculate/quantity", handler.CalculateQuantity)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate/quantity", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				var errorResp dto.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+				if tt.wantError != "" {
+					assert.Equal(t, tt.wantError, errorResp.Message)
+				}
+				return
+			}
+
+			var resp struct {
+				Data dto.QuantityResult `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, resp.Data)
+		})
+	}
+}
@@ -2,15 +2,21 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // Response DTO pools for reducing allocations.
@@ -42,6 +48,7 @@ func putSuccessResponse(resp *dto.SuccessResponse) {
 	resp.Data = nil
 	resp.RequestID = ""
 	resp.Timestamp = time.Time{}
+	resp.Warnings = nil
 	successResponsePool.Put(resp)
 }
 
@@ -59,6 +66,7 @@ func putErrorResponse(resp *dto.ErrorResponse) {
 	resp.Error = ""
 	resp.Message = ""
 	resp.RequestID = ""
+	resp.CorrelationID = ""
 	resp.Timestamp = time.Time{}
 	resp.Details = nil
 	resp.TraceID = ""
@@ -115,6 +123,12 @@ func NewResponseBuilder(c *gin.Context) *ResponseBuilder {
 // Success sends a successful response with the given data.
 // Uses pooled SuccessResponse to reduce allocations.
 func (b *ResponseBuilder) Success(statusCode int, data interface{}) {
+	b.SuccessWithWarnings(statusCode, data, nil)
+}
+
+// SuccessWithWarnings sends a successful response with the given data and
+// non-fatal input warnings.
+func (b *ResponseBuilder) SuccessWithWarnings(statusCode int, data interface{}, warnings []dto.Warning) {
 	requestID := middleware.GetRequestID(b.c)
 
 	// Get pooled response
@@ -123,7 +137,8 @@ func (b *ResponseBuilder) Success(statusCode int, data interface{}) {
 	// Set values
 	resp.Data = data
 	resp.RequestID = requestID
-	resp.Timestamp = time.Now()
+	resp.Timestamp = timeutil.Now()
+	resp.Warnings = warnings
 
 	// Send response (this copies the data)
 	b.c.JSON(statusCode, resp)
@@ -149,12 +164,21 @@ func (b *ResponseBuilder) SuccessAccepted(data interface{}) {
 }
 
 // Error sends an error response with the given status code and message key.
-// Uses pooled ErrorResponse to reduce allocations.
+// Uses pooled ErrorResponse to reduce allocations. Internal errors caused by
+// an open circuit breaker are sent as Unavailable does, and those wrapping a
+// domain error as ErrorFrom does.
 func (b *ResponseBuilder) Error(statusCode int, messageKey string, err error) {
-	requestID := middleware.GetRequestID(b.c)
-	locale := i18n.GetLocale(b.c)
+	if statusCode == http.StatusInternalServerError {
+		if b.Unavailable(err) {
+			return
+		}
+		if domainErr, ok := domainerr.From(err); ok {
+			b.domainError(domainErr, err)
+			return
+		}
+	}
 
-	translatedMessage := i18n.GetTranslator().Translate(messageKey, locale)
+	translatedMessage := i18n.Message(b.c, messageKey)
 
 	// Get pooled response
 	resp := getErrorResponse()
@@ -162,44 +186,167 @@ func (b *ResponseBuilder) Error(statusCode int, messageKey string, err error) {
 	// Set values
 	resp.Error = dto.ErrCodeFromStatus(statusCode)
 	resp.Message = translatedMessage
-	resp.RequestID = requestID
-	resp.Timestamp = time.Now()
+	b.setIDs(resp)
+	resp.Timestamp = timeutil.Now()
 
 	// Add error to context for error handler middleware to log
 	if err != nil {
 		_ = b.c.Error(err)
 	}
 
-	b.c.AbortWithStatusJSON(statusCode, resp)
+	b.abort(statusCode, resp)
 
 	// Return to pool after response is sent
 	putErrorResponse(resp)
 }
 
+// ErrorWithCode sends an error response like Error, with an error code more
+// specific than the one of the status.
+func (b *ResponseBuilder) ErrorWithCode(statusCode int, code, messageKey string, err error) {
+	resp := getErrorResponse()
+	resp.Error = code
+	resp.Message = i18n.Message(b.c, messageKey)
+	b.setIDs(resp)
+	resp.Timestamp = timeutil.Now()
+
+	if err != nil {
+		_ = b.c.Error(err)
+	}
+
+	b.abort(statusCode, resp)
+	putErrorResponse(resp)
+}
+
+// ErrorFrom sends the response of err returned by a service: the status,
+// code and message of the domain error it wraps, the 503 of Unavailable, or
+// an internal error.
+func (b *ResponseBuilder) ErrorFrom(err error) {
+	b.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+}
+
+// domainError sends the response of domainErr, which err wraps. Errors
+// without a message key are described by the catalogue message of their
+// code, as the details wrapped around domainErr may reveal internals; err
+// itself is logged by the error handler.
+func (b *ResponseBuilder) domainError(domainErr *domainerr.Error, err error) {
+	resp := getErrorResponse()
+	resp.Error = domainErr.Code
+	resp.Message = b.domainErrorMessage(domainErr)
+	b.setIDs(resp)
+	resp.Timestamp = timeutil.Now()
+
+	_ = b.c.Error(err)
+
+	b.abort(domainErr.Status, resp)
+	putErrorResponse(resp)
+}
+
+// domainErrorMessage returns the client message of domainErr: its own
+// translated message, or else that of the catalogued error with its code.
+func (b *ResponseBuilder) domainErrorMessage(domainErr *domainerr.Error) string {
+	if domainErr.MessageKey != "" {
+		return i18n.Message(b.c, domainErr.MessageKey)
+	}
+	catalogued, ok := domainerr.Lookup(domainErr.Code)
+	if !ok {
+		return domainErr.Message
+	}
+	if catalogued.MessageKey != "" {
+		return i18n.Message(b.c, catalogued.MessageKey)
+	}
+	return catalogued.Message
+}
+
+// Unavailable sends a 503 maintenance error when err comes from the open
+// circuit breaker of a repository, with a Retry-After when the breaker
+// reports when it will try the database again. It reports whether it did.
+func (b *ResponseBuilder) Unavailable(err error) bool {
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		return false
+	}
+	var openErr *circuitbreaker.OpenError
+	if errors.As(err, &openErr) {
+		b.c.Header("Retry-After", retryAfterSeconds(openErr.RetryAfter))
+	}
+	b.ErrorWithCode(http.StatusServiceUnavailable, dto.ErrCodeDatabaseUnavailable, i18n.ErrKeyDatabaseUnavailable, err)
+	return true
+}
+
+// retryAfterSeconds formats d as a Retry-After header, in whole seconds
+// rounded up.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(int((d+time.Second-1)/time.Second), 1))
+}
+
 // ErrorWithMessage sends an error response with a custom message.
 // Uses pooled ErrorResponse to reduce allocations.
 func (b *ResponseBuilder) ErrorWithMessage(statusCode int, message string, err error) {
-	requestID := middleware.GetRequestID(b.c)
+	// Get pooled response
+	resp := getErrorResponse()
 
+	// Set values
+	resp.Error = dto.ErrCodeFromStatus(statusCode)
+	resp.Message = message
+	b.setIDs(resp)
+	resp.Timestamp = timeutil.Now()
+
+	if err != nil {
+		_ = b.c.Error(err)
+	}
+
+	b.abort(statusCode, resp)
+
+	// Return to pool after response is sent
+	putErrorResponse(resp)
+}
+
+// ErrorWithDetails sends an error response with a custom message and
+// details, such as the limit a request exceeded.
+func (b *ResponseBuilder) ErrorWithDetails(statusCode int, message string, details map[string]string, err error) {
 	// Get pooled response
 	resp := getErrorResponse()
 
 	// Set values
 	resp.Error = dto.ErrCodeFromStatus(statusCode)
 	resp.Message = message
-	resp.RequestID = requestID
-	resp.Timestamp = time.Now()
+	resp.Details = details
+	b.setIDs(resp)
+	resp.Timestamp = timeutil.Now()
 
 	if err != nil {
 		_ = b.c.Error(err)
 	}
 
-	b.c.AbortWithStatusJSON(statusCode, resp)
+	b.abort(statusCode, resp)
 
 	// Return to pool after response is sent
 	putErrorResponse(resp)
 }
 
+// setIDs sets the request, correlation and trace IDs of resp.
+func (b *ResponseBuilder) setIDs(resp *dto.ErrorResponse) {
+	ids := middleware.RequestIDs(b.c)
+	resp.RequestID = ids.RequestID
+	resp.CorrelationID = ids.CorrelationID
+	resp.TraceID = ids.TraceID
+}
+
+// problemDetailsKey is the context key set when every error response of
+// the router is problem details.
+const problemDetailsKey = "problem_details"
+
+// abort writes resp as the response, as problem details when the router
+// sends them or the client accepts them, and aborts the request.
+func (b *ResponseBuilder) abort(statusCode int, resp *dto.ErrorResponse) {
+	if !b.c.GetBool(problemDetailsKey) && !strings.Contains(b.c.GetHeader("Accept"), dto.ProblemContentType) {
+		b.c.AbortWithStatusJSON(statusCode, resp)
+		return
+	}
+	// Gin keeps a content type set before rendering
+	b.c.Header("Content-Type", dto.ProblemContentType)
+	b.c.AbortWithStatusJSON(statusCode, resp.Problem(statusCode, b.c.Request.URL.Path))
+}
+
 // MarshalJSON marshals the provided value to JSON bytes.
 func MarshalJSON(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
@@ -3,14 +3,19 @@ package http
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -257,6 +262,210 @@ func TestResponseBuilder_ErrorWithCustomMessage(t *testing.T) {
 	assert.Equal(t, customMessage, errorResp.Message)
 }
 
+func TestResponseBuilder_ErrorIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Tracing())
+	router.GET("/", func(c *gin.Context) {
+		NewResponseBuilder(c).Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, nil)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	req.Header.Set(middleware.CorrelationIDHeader, "order-42")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var errorResp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+	assert.Equal(t, "req-1", errorResp.RequestID)
+	assert.Equal(t, "order-42", errorResp.CorrelationID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", errorResp.TraceID, "traced without a configured exporter")
+}
+
+func TestResponseBuilder_ErrorCircuitOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		status     int
+		err        error
+		wantStatus int
+		wantCode   string
+		retryAfter string
+	}{
+		{
+			name:       "open circuit with retry after",
+			status:     http.StatusInternalServerError,
+			err:        fmt.Errorf("list users: %w", &circuitbreaker.OpenError{Name: "mongodb-auth", RetryAfter: 1500 * time.Millisecond}),
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   dto.ErrCodeDatabaseUnavailable,
+			retryAfter: "2",
+		},
+		{
+			name:       "open circuit without retry after",
+			status:     http.StatusInternalServerError,
+			err:        circuitbreaker.ErrCircuitOpen,
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   dto.ErrCodeDatabaseUnavailable,
+		},
+		{
+			name:       "other internal error",
+			status:     http.StatusInternalServerError,
+			err:        fmt.Errorf("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   dto.ErrCodeInternal,
+		},
+		{
+			name:       "client error keeps its status",
+			status:     http.StatusBadRequest,
+			err:        circuitbreaker.ErrCircuitOpen,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   dto.ErrCodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			NewResponseBuilder(c).Error(tt.status, i18n.ErrKeyInternalError, tt.err)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+			var errorResp dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+			assert.Equal(t, tt.wantCode, errorResp.Error)
+		})
+	}
+}
+
+func TestResponseBuilder_ErrorFrom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:        "domain error with a message key",
+			err:         fmt.Errorf("get user: %w", service.ErrUserNotFound),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "user_not_found",
+			wantMessage: i18n.GetTranslator().Translate(i18n.ErrKeyUserNotFound, i18n.DefaultLocale),
+		},
+		{
+			name:        "domain error without a message key hides its details",
+			err:         fmt.Errorf("%w: email is required", service.ErrInvalidUser),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "invalid_user",
+			wantMessage: "invalid user",
+		},
+		{
+			name:        "derived domain error",
+			err:         fmt.Errorf("%w: version 3 is active", service.ErrPackSizesChanged),
+			wantStatus:  http.StatusPreconditionFailed,
+			wantCode:    dto.ErrCodePreconditionFailed,
+			wantMessage: domainerr.PreconditionFailed.Message,
+		},
+		{
+			name:        "derived domain error described by its code",
+			err:         fmt.Errorf("%w: 4 rules broken", service.ErrPackSizeRules),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    dto.ErrCodeInvalidRequest,
+			wantMessage: i18n.GetTranslator().Translate(i18n.ErrKeyInvalidRequest, i18n.DefaultLocale),
+		},
+		{
+			name:       "open circuit",
+			err:        circuitbreaker.ErrCircuitOpen,
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   dto.ErrCodeDatabaseUnavailable,
+		},
+		{
+			name:       "other error",
+			err:        fmt.Errorf("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   dto.ErrCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			NewResponseBuilder(c).ErrorFrom(tt.err)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var errorResp dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+			assert.Equal(t, tt.wantCode, errorResp.Error)
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, errorResp.Message)
+			}
+		})
+	}
+}
+
+func TestResponseBuilder_ErrorAsProblemDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		accept   string
+		router   bool
+		expected bool
+	}{
+		{name: "default format", accept: "application/json", expected: false},
+		{name: "accepted by the client", accept: "application/problem+json, application/json;q=0.5", expected: true},
+		{name: "set for the router", router: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/calculate", nil)
+			c.Request.Header.Set("Accept", tt.accept)
+			if tt.router {
+				c.Set(problemDetailsKey, true)
+			}
+
+			middleware.RequestID()(c)
+			NewResponseBuilder(c).ErrorWithDetails(http.StatusUnprocessableEntity, "too large", map[string]string{"max": "10"}, nil)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			if !tt.expected {
+				assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+				var errorResp dto.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResp))
+				assert.Equal(t, dto.ErrCodeUnprocessable, errorResp.Error)
+				return
+			}
+
+			assert.Equal(t, dto.ProblemContentType, w.Header().Get("Content-Type"))
+			var problem dto.ProblemDetails
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, "about:blank", problem.Type)
+			assert.Equal(t, "Unprocessable Entity", problem.Title)
+			assert.Equal(t, http.StatusUnprocessableEntity, problem.Status)
+			assert.Equal(t, "too large", problem.Detail)
+			assert.Equal(t, "/api/calculate", problem.Instance)
+			assert.Equal(t, dto.ErrCodeUnprocessable, problem.Code)
+			assert.Equal(t, "10", problem.Details["max"])
+			assert.Equal(t, middleware.GetRequestID(c), problem.RequestID)
+		})
+	}
+}
+
 func TestMarshalJSON(t *testing.T) {
 	data := dto.CalculatePacksRequest{ItemsOrdered: 251}
 	result, err := MarshalJSON(data)
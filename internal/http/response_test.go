@@ -196,6 +196,31 @@ func TestResponseBuilder_SuccessAccepted(t *testing.T) {
 	}
 }
 
+func TestResponseBuilder_SuccessWithWarnings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/warn", func(c *gin.Context) {
+		NewResponseBuilder(c).SuccessWithWarnings(http.StatusOK, "ok", []dto.Warning{
+			{Code: "duplicate_pack_sizes", Field: "pack_sizes", Message: "listed twice"},
+		})
+	})
+	router.GET("/plain", func(c *gin.Context) {
+		NewResponseBuilder(c).SuccessOK("ok")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/warn", nil))
+	var resp dto.SuccessResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Warnings, 1)
+	assert.Equal(t, "duplicate_pack_sizes", resp.Warnings[0].Code)
+
+	// Pooled responses must not carry warnings into later responses
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/plain", nil))
+	assert.NotContains(t, w.Body.String(), "warnings")
+}
+
 func TestSuccessResponse_JSON(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// RouteAuthHandler serves and changes the runtime auth setting of route groups.
+type RouteAuthHandler struct {
+	policy *middleware.RouteAuthPolicy
+}
+
+// NewRouteAuthHandler creates a new RouteAuthHandler instance.
+func NewRouteAuthHandler(policy *middleware.RouteAuthPolicy) *RouteAuthHandler {
+	return &RouteAuthHandler{policy: policy}
+}
+
+// ListRouteAuth handles GET /api/admin/route-auth requests.
+//
+// @Summary      List route group auth
+// @Description  Lists the route groups and whether each requires an API key, with its startup default and whether it was overridden at runtime. Only available without JWT auth.
+// @Tags         Admin
+// @Produce      json
+// @Param        X-API-Key header string true "API key"
+// @Success      200 {object} dto.SuccessResponse{data=[]middleware.RouteGroupAuth} "Route groups"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid API key"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/route-auth [get]
+func (h *RouteAuthHandler) ListRouteAuth(c *gin.Context) {
+	NewResponseBuilder(c).SuccessOK(h.policy.Groups())
+}
+
+// SetRouteAuth handles PUT /api/admin/route-auth/:group requests.
+//
+// @Summary      Override route group auth
+// @Description  Makes a route group require an API key, or opens it, until the override is removed or the instance restarts. Overrides apply to the instance that receives them only.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key header string true "API key"
+// @Param        group path string true "Route group: calculate, pack-sizes, presets or calculations"
+// @Param        request body dto.RouteAuthRequest true "Auth setting"
+// @Success      200 {object} dto.SuccessResponse{data=middleware.RouteGroupAuth} "Route group"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid request body"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid API key"
+// @Failure      404 {object} dto.ErrorResponse "Unknown route group"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/route-auth/{group} [put]
+func (h *RouteAuthHandler) SetRouteAuth(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.RouteAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+
+	group := c.Param("group")
+	if err := h.policy.Override(group, *req.Required); err != nil {
+		h.writeError(builder, err)
+		return
+	}
+
+	h.audit(c, "override_route_auth", "Route group auth overridden", group, map[string]interface{}{
+		"required": *req.Required,
+	})
+	builder.SuccessOK(h.group(group))
+}
+
+// ResetRouteAuth handles DELETE /api/admin/route-auth/:group requests.
+//
+// @Summary      Remove route group auth override
+// @Description  Returns a route group to its startup auth setting.
+// @Tags         Admin
+// @Produce      json
+// @Param        X-API-Key header string true "API key"
+// @Param        group path string true "Route group: calculate, pack-sizes, presets or calculations"
+// @Success      200 {object} dto.SuccessResponse{data=middleware.RouteGroupAuth} "Route group"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid API key"
+// @Failure      404 {object} dto.ErrorResponse "Unknown route group"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/route-auth/{group} [delete]
+func (h *RouteAuthHandler) ResetRouteAuth(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	group := c.Param("group")
+	if err := h.policy.Reset(group); err != nil {
+		h.writeError(builder, err)
+		return
+	}
+
+	h.audit(c, "reset_route_auth", "Route group auth override removed", group, nil)
+	builder.SuccessOK(h.group(group))
+}
+
+// group returns the current setting of a known group.
+func (h *RouteAuthHandler) group(name string) middleware.RouteGroupAuth {
+	for _, group := range h.policy.Groups() {
+		if group.Group == name {
+			return group
+		}
+	}
+	return middleware.RouteGroupAuth{Group: name}
+}
+
+// writeError maps policy errors to HTTP responses.
+func (h *RouteAuthHandler) writeError(builder *ResponseBuilder, err error) {
+	if errors.Is(err, middleware.ErrUnknownRouteGroup) {
+		builder.Error(http.StatusNotFound, i18n.ErrKeyNotFound, err)
+		return
+	}
+	builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+}
+
+// audit records a route group change, which can open routes to anonymous
+// clients, in the log and the audit log.
+func (h *RouteAuthHandler) audit(c *gin.Context, action, message, group string, fields map[string]interface{}) {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	fields["route_group"] = group
+
+	state := h.group(group)
+	log.Warn().Str("route_group", group).Bool("required", state.Required).
+		Str("api_key_id", c.GetString(middleware.APIKeyIDContextKey)).Msg(message)
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, action, message, fields)
+		}
+	}
+}
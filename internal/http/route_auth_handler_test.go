@@ -0,0 +1,68 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteAuthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := middleware.NewRouteAuthPolicy(map[string]bool{RouteGroupPackSizes: false})
+	router := gin.New()
+	NewRouteAuthRoutes(policy).RegisterAPIKeyRoutes(router.Group("/api"), middleware.NewAPIKeySet(map[string]bool{"test-key": true}))
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.APIKeyHeader, "test-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("overrides a group", func(t *testing.T) {
+		w := send(http.MethodPut, "/api/admin/route-auth/pack-sizes", `{"required":true}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data middleware.RouteGroupAuth `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, middleware.RouteGroupAuth{Group: RouteGroupPackSizes, Required: true, Overridden: true}, resp.Data)
+		assert.True(t, policy.Required(RouteGroupPackSizes))
+	})
+
+	t.Run("lists groups", func(t *testing.T) {
+		w := send(http.MethodGet, "/api/admin/route-auth", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data []middleware.RouteGroupAuth `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 1)
+	})
+
+	t.Run("resets a group", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(http.MethodDelete, "/api/admin/route-auth/pack-sizes", "").Code)
+		assert.False(t, policy.Required(RouteGroupPackSizes))
+	})
+
+	t.Run("rejects a missing setting", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/api/admin/route-auth/pack-sizes", `{}`).Code)
+	})
+
+	t.Run("rejects an unknown group", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, send(http.MethodPut, "/api/admin/route-auth/admin", `{"required":false}`).Code)
+		assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/api/admin/route-auth/admin", "").Code)
+	})
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// Route groups whose authentication can be toggled without a JWT.
+const (
+	RouteGroupCalculate    = "calculate"
+	RouteGroupPackSizes    = "pack-sizes"
+	RouteGroupPresets      = "presets"
+	RouteGroupCalculations = "calculations"
+)
+
+// routeGroups is the route table auth toggles act on: each group and the
+// paths it covers, below /api. Routes outside every group, such as the admin
+// reports, keep the startup setting.
+var routeGroups = []struct {
+	name     string
+	prefixes []string
+}{
+	{RouteGroupCalculate, []string{"/calculate", "/jobs"}},
+	{RouteGroupPackSizes, []string{"/pack-sizes"}},
+	{RouteGroupPresets, []string{"/presets"}},
+	{RouteGroupCalculations, []string{"/calculations", "/analytics/calculations"}},
+}
+
+// routeGroupOf returns the group of the route at path, or "" when it is in
+// none.
+func routeGroupOf(path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	for _, group := range routeGroups {
+		for _, prefix := range group.prefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return group.name
+			}
+		}
+	}
+	return ""
+}
+
+// newRouteAuthPolicy creates the policy of cfg. A group requires an API key
+// when auth is enabled with API keys, as every route did before groups, or
+// when it is listed in cfg.RequiredRouteGroups.
+func newRouteAuthPolicy(cfg *RouterConfig) *middleware.RouteAuthPolicy {
+	enabled := cfg.EnableAuth && len(cfg.APIKeys) > 0
+	defaults := make(map[string]bool, len(routeGroups))
+	for _, group := range routeGroups {
+		defaults[group.name] = enabled || slices.Contains(cfg.RequiredRouteGroups, group.name)
+	}
+	for _, name := range cfg.RequiredRouteGroups {
+		if _, ok := defaults[name]; !ok {
+			log.Warn().Str("route_group", name).Msg("Unknown route group in required route groups, ignored")
+		}
+	}
+	return middleware.NewRouteAuthPolicy(defaults)
+}
+
+// routeGroupAuth returns the API key check of the /api routes when JWT auth
+// is disabled. A group that requires auth without any API key configured is
+// locked: every request to it is rejected.
+func routeGroupAuth(cfg *RouterConfig) gin.HandlerFunc {
+	requireKey := cfg.apiKeySet().Require()
+	return func(c *gin.Context) {
+		required := cfg.EnableAuth && len(cfg.APIKeys) > 0
+		if group := routeGroupOf(c.FullPath()); group != "" {
+			required = cfg.routeAuth.Required(group)
+		}
+		if !required {
+			c.Next()
+			return
+		}
+		requireKey(c)
+	}
+}
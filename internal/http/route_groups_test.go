@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteGroupOf(t *testing.T) {
+	assert.Equal(t, RouteGroupCalculate, routeGroupOf("/api/calculate"))
+	assert.Equal(t, RouteGroupCalculate, routeGroupOf("/api/calculate/batch"))
+	assert.Equal(t, RouteGroupCalculate, routeGroupOf("/api/jobs/:id"))
+	assert.Equal(t, RouteGroupPackSizes, routeGroupOf("/api/pack-sizes/history"))
+	assert.Equal(t, RouteGroupCalculations, routeGroupOf("/api/calculations"))
+	assert.Equal(t, RouteGroupCalculations, routeGroupOf("/api/analytics/calculations/:day"))
+	assert.Equal(t, RouteGroupPresets, routeGroupOf("/api/presets/:name"))
+	assert.Empty(t, routeGroupOf("/api/admin/clients"))
+	assert.Empty(t, routeGroupOf(""))
+}
+
+// routeGroupRequest sends a request with an optional API key and body.
+func routeGroupRequest(router *gin.Engine, method, path, key, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(middleware.APIKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRouter_RouteGroupAuth(t *testing.T) {
+	handler := NewHandler(service.NewPackCalculatorService(), nil)
+	apiKeys := map[string]bool{"test-key": true}
+
+	t.Run("configured group requires a key while auth is disabled", func(t *testing.T) {
+		cfg := DefaultRouterConfig()
+		cfg.APIKeys = apiKeys
+		cfg.RequiredRouteGroups = []string{RouteGroupCalculate}
+		router := NewRouter(handler, NewHealthHandler(), cfg)
+
+		assert.Equal(t, http.StatusUnauthorized, routeGroupRequest(router, http.MethodPost, "/api/calculate", "", ""))
+		assert.Equal(t, http.StatusBadRequest, routeGroupRequest(router, http.MethodPost, "/api/calculate", "test-key", ""))
+	})
+
+	t.Run("group is locked down and reopened at runtime", func(t *testing.T) {
+		cfg := DefaultRouterConfig()
+		cfg.APIKeys = apiKeys
+		router := NewRouter(handler, NewHealthHandler(), cfg)
+
+		assert.Equal(t, http.StatusBadRequest, routeGroupRequest(router, http.MethodPost, "/api/calculate", "", ""))
+		assert.Equal(t, http.StatusUnauthorized, routeGroupRequest(router, http.MethodPut, "/api/admin/route-auth/calculate", "", `{"required":true}`))
+
+		assert.Equal(t, http.StatusOK, routeGroupRequest(router, http.MethodPut, "/api/admin/route-auth/calculate", "test-key", `{"required":true}`))
+		assert.Equal(t, http.StatusUnauthorized, routeGroupRequest(router, http.MethodPost, "/api/calculate", "", ""))
+
+		assert.Equal(t, http.StatusOK, routeGroupRequest(router, http.MethodDelete, "/api/admin/route-auth/calculate", "test-key", ""))
+		assert.Equal(t, http.StatusBadRequest, routeGroupRequest(router, http.MethodPost, "/api/calculate", "", ""))
+	})
+
+	t.Run("group is opened while auth is enabled", func(t *testing.T) {
+		cfg := DefaultRouterConfig()
+		cfg.EnableAuth = true
+		cfg.APIKeys = apiKeys
+		router := NewRouter(handler, NewHealthHandler(), cfg)
+
+		assert.Equal(t, http.StatusUnauthorized, routeGroupRequest(router, http.MethodPost, "/api/calculate", "", ""))
+		assert.Equal(t, http.StatusOK, routeGroupRequest(router, http.MethodPut, "/api/admin/route-auth/calculate", "test-key", `{"required":false}`))
+		assert.Equal(t, http.StatusBadRequest, routeGroupRequest(router, http.MethodPost, "/api/calculate", "", ""))
+	})
+
+	t.Run("group without API keys is locked", func(t *testing.T) {
+		cfg := DefaultRouterConfig()
+		cfg.RequiredRouteGroups = []string{RouteGroupCalculate}
+		router := NewRouter(handler, NewHealthHandler(), cfg)
+
+		assert.Equal(t, http.StatusUnauthorized, routeGroupRequest(router, http.MethodPost, "/api/calculate", "", ""))
+		assert.Equal(t, http.StatusNotFound, routeGroupRequest(router, http.MethodGet, "/api/admin/route-auth", "", ""))
+	})
+}
@@ -5,12 +5,23 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/deprecation"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/edgecache"
+	"github.com/guttosm/pack-service/internal/hooks"
 	"github.com/guttosm/pack-service/internal/metrics"
 	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/presentation"
+	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/support"
+	"github.com/guttosm/pack-service/internal/workerpool"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/rs/zerolog/log"
 )
 
 // RouterConfig holds router configuration options.
@@ -21,14 +32,148 @@ type RouterConfig struct {
 	EnableAuth        bool
 	EnableIdempotency bool
 	CORSOrigins       []string
-	SwaggerUser       string
-	SwaggerPass       string
+	// TrustedProxies are the IPs and CIDR ranges of the proxies trusted to
+	// set X-Forwarded-For. The client IP of requests from other peers, which
+	// IP rules, rate limits and login lockouts go by, is the peer's.
+	TrustedProxies []string
+	// SwaggerEnabled serves Swagger UI with the public API spec under /docs.
+	SwaggerEnabled bool
+	// SwaggerUser and SwaggerPass put /docs behind basic auth when both are set.
+	SwaggerUser string
+	SwaggerPass string
+	// SwaggerAdminSpec also serves Swagger UI with the whole spec, admin
+	// operations included, under /docs/admin.
+	SwaggerAdminSpec  bool
 	LoggingService    service.LoggingService
 	PackSizesService  service.PackSizesService
 	AuthService       service.AuthService
 	RoleService       service.RoleService
 	PermissionService service.PermissionService
 	Calculator        service.PackCalculator
+	PresetService     service.PresetService
+	// UserService enables the profile and admin user management routes
+	// when set.
+	UserService service.UserService
+	// DPoPVerifier enables sender-constrained tokens; RequireDPoP rejects bearer tokens.
+	DPoPVerifier *dpop.Verifier
+	RequireDPoP  bool
+	// SupportBundle enables the admin support bundle endpoint when set.
+	SupportBundle *support.Generator
+	// Deprecations records deprecated field usage and enables the admin report when set.
+	Deprecations *deprecation.Tracker
+	// ClientUsage records client versions and user agents and enables the admin report when set.
+	ClientUsage service.ClientUsageService
+	// MetricsGatherer enables the admin metric cardinality report when set.
+	MetricsGatherer prometheus.Gatherer
+	// WebhookMonitor enables the admin webhook health report when set.
+	WebhookMonitor *notify.WebhookMonitor
+	// CircuitBreakers enables the admin circuit breaker routes when set.
+	CircuitBreakers *circuitbreaker.Registry
+	// Drainer enables the admin drain routes and rejects requests while
+	// draining when set.
+	Drainer *middleware.Drainer
+	// TokenCleanup enables the admin expired token cleanup route when set.
+	TokenCleanup *service.TokenCleanupJob
+	// MessageOverrides applies the messages tenants replaced and enables the
+	// admin message override routes when set.
+	MessageOverrides service.MessageOverrideService
+	// GeoFence restricts requests to allowed CIDR ranges and countries, and
+	// enables the admin geofence routes, when set.
+	GeoFence *middleware.GeoFence
+	// CountryHeader is the header the edge sets to the client's country,
+	// recorded with logins when set.
+	CountryHeader string
+	// IPRules rejects requests from denied or not allowed IP ranges, and
+	// enables the admin IP rule routes, when set.
+	IPRules *service.IPRuleService
+	// EdgeCache lets an API gateway or CDN cache anonymous calculate
+	// responses when set.
+	EdgeCache *edgecache.Policy
+	// BatchPool calculates batch items concurrently when set.
+	BatchPool *workerpool.Pool
+	// HeavyRequests caps the batch and streamed calculations served at once
+	// when set.
+	HeavyRequests *workerpool.Pool
+	// CalculationHistory stores calculations and enables the history listing when set.
+	CalculationHistory service.CalculationHistoryService
+	// DisplayLocation is the timezone reports show timestamps in; nil means UTC.
+	DisplayLocation *time.Location
+	// Cursors encodes the next_cursor of listing pages; nil leaves listings
+	// to offsets.
+	Cursors *pagination.Codec
+	// MaxCompute caps the max_compute_ms of calculate requests; zero leaves it uncapped.
+	MaxCompute time.Duration
+	// MaxBodyBytes caps the request body of the calculate and auth endpoints;
+	// zero leaves it unlimited.
+	MaxBodyBytes int64
+	// ProblemDetails sends every error response built by ResponseBuilder as
+	// RFC 7807 problem details.
+	ProblemDetails bool
+	// DecimalPrecision is the number of decimal places of quantity
+	// calculations; zero means fixedpoint.DefaultPrecision.
+	DecimalPrecision int
+	// StreamJobs enables the streaming bulk calculation endpoints when set.
+	StreamJobs *service.StreamJobRunner
+	// CalculationJobs enables the asynchronous calculation job endpoints when
+	// set. Its workers are started with the router.
+	CalculationJobs service.CalculationJobService
+	// RequiredRouteGroups lists route groups that require an API key even
+	// when EnableAuth is off. Without JWT auth, API key holders can change
+	// every group's setting at runtime through /api/admin/route-auth.
+	RequiredRouteGroups []string
+	// Hooks are the deployment's calculate pipeline hooks, if any.
+	Hooks *hooks.Registry
+	// Presentation lays out calculated results by the deployment's
+	// presentation rules, if any.
+	Presentation *presentation.Engine
+	// PackSizesMigrator enables the staged pack size migration endpoints
+	// when set together with PackSizesService.
+	PackSizesMigrator *service.PackSizesMigrator
+	// PackSizesWatcher reports pack size changes made by other replicas, so
+	// their cached sizes and results are dropped right away, when set.
+	PackSizesWatcher repository.PackSizesWatcher
+	// ActivePackSizes keeps the active pack sizes in memory for calculations,
+	// in place of PackSizesWatcher, when set. The pack routes start it.
+	ActivePackSizes *service.ActivePackSizes
+	// PackSizeRules are the rules pack sizes must follow to be saved; zero
+	// limits fall back to service.DefaultPackSizeRules.
+	PackSizeRules service.PackSizeRules
+	// ResponseCache caches the responses of the pack size and admin listing
+	// endpoints dashboards poll, when set.
+	ResponseCache *middleware.ResponseCache
+	// RequestValidator checks JSON request bodies against the OpenAPI
+	// document when set.
+	RequestValidator *middleware.RequestValidator
+	// APIKeySet holds the API keys requests are checked against, so they can
+	// be replaced at runtime, when set; otherwise they are APIKeys. Whether
+	// API key routes are registered still depends on APIKeys.
+	APIKeySet *middleware.APIKeySet
+	// RateLimiters collects the rate limiters the router creates, so their
+	// limits can be changed at runtime, when set.
+	RateLimiters *middleware.RateLimiterSet
+	// ConfigReloader enables the admin config reload route when set.
+	ConfigReloader *service.ConfigReloader
+	// SigningKeys enables the admin JWT signing key routes when set.
+	SigningKeys *service.SigningKeyRing
+	// RequestLogging samples the successful requests logged; the zero value
+	// logs every request.
+	RequestLogging middleware.RequestLoggerConfig
+
+	// routeAuth holds the runtime auth setting of each route group.
+	routeAuth *middleware.RouteAuthPolicy
+	// apiKeys is APIKeySet, or the set of APIKeys without one.
+	apiKeys *middleware.APIKeySet
+}
+
+// apiKeySet returns the API keys requests are checked against.
+func (cfg *RouterConfig) apiKeySet() *middleware.APIKeySet {
+	if cfg.apiKeys == nil {
+		cfg.apiKeys = cfg.APIKeySet
+		if cfg.apiKeys == nil {
+			cfg.apiKeys = middleware.NewAPIKeySet(cfg.APIKeys)
+		}
+	}
+	return cfg.apiKeys
 }
 
 // DefaultRouterConfig returns the default router configuration.
@@ -40,19 +185,49 @@ func DefaultRouterConfig() RouterConfig {
 	}
 }
 
+// MiddlewareChain lists the middleware NewRouter registers, in the order
+// they run.
+type MiddlewareChain struct {
+	// Global runs on every route.
+	Global []gin.HandlerFunc
+	// API runs on routes under /api.
+	API []gin.HandlerFunc
+	// Protected runs on /api routes that require a JWT; it is empty without
+	// an AuthService.
+	Protected []gin.HandlerFunc
+}
+
+// NewMiddlewareChain builds the middleware NewRouter registers for cfg.
+// Every call returns fresh instances, so rate limits are not shared.
+func NewMiddlewareChain(cfg RouterConfig) MiddlewareChain {
+	chain := MiddlewareChain{
+		Global: globalMiddleware(&cfg),
+		API:    apiMiddleware(&cfg),
+	}
+	if cfg.AuthService != nil {
+		authRoutes := NewAuthRoutes(cfg.AuthService, WithDPoP(cfg.DPoPVerifier, cfg.RequireDPoP), WithCountryHeader(cfg.CountryHeader))
+		chain.Protected = authRoutes.protectedMiddleware(&cfg)
+	}
+	return chain
+}
+
 // NewRouter creates and configures the Gin router for the pack service.
 func NewRouter(handler *Handler, healthHandler *HealthHandler, cfg RouterConfig) *gin.Engine {
 	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Error().Err(err).Msg("Invalid trusted proxies, trusting none")
+		_ = router.SetTrustedProxies(nil)
+	}
 
 	// Configure global middleware
-	configureGlobalMiddleware(router, &cfg)
+	router.Use(globalMiddleware(&cfg)...)
 
 	// Register infrastructure routes (health, metrics, swagger)
 	registerInfrastructureRoutes(router, healthHandler, &cfg)
 
 	// Configure API routes
-	api := router.Group("/api")
-	configureAPIMiddleware(api, &cfg)
+	api := router.Group("/api", apiMiddleware(&cfg)...)
+	api.GET("/errors", NewErrorCatalogHandler().ListErrors)
 
 	// Register business routes based on authentication mode
 	if cfg.AuthService != nil {
@@ -64,8 +239,8 @@ func NewRouter(handler *Handler, healthHandler *HealthHandler, cfg RouterConfig)
 	return router
 }
 
-// configureGlobalMiddleware sets up middleware applied to all routes.
-func configureGlobalMiddleware(router *gin.Engine, cfg *RouterConfig) {
+// globalMiddleware returns the middleware applied to all routes.
+func globalMiddleware(cfg *RouterConfig) []gin.HandlerFunc {
 	// CORS configuration
 	allowedOrigins := cfg.CORSOrigins
 	if len(allowedOrigins) == 0 {
@@ -74,70 +249,109 @@ func configureGlobalMiddleware(router *gin.Engine, cfg *RouterConfig) {
 	corsConfig := cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "Accept-Language", "X-CSRF-Token", "Authorization", "X-Refresh-Token", "accept", "Cache-Control", "X-Requested-With", "X-API-Key", "Idempotency-Key", "X-Request-ID"},
-		ExposeHeaders:    []string{"X-Request-ID"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "Accept-Language", "X-CSRF-Token", "Authorization", "X-Refresh-Token", "accept", "Cache-Control", "X-Requested-With", "X-API-Key", "Idempotency-Key", "X-Request-ID", middleware.CorrelationIDHeader, "traceparent", "tracestate", "DPoP", "If-Match", "If-None-Match", middleware.ClientVersionHeader},
+		ExposeHeaders:    []string{"X-Request-ID", middleware.CorrelationIDHeader, "WWW-Authenticate", "ETag", TotalCountHeader},
 		AllowCredentials: true,
 		MaxAge:           86400,
 	}
-	router.Use(cors.New(corsConfig))
+	chain := []gin.HandlerFunc{cors.New(corsConfig)}
 
-	// Core middleware stack
-	router.Use(
+	// Core middleware stack. Recovery sits inside metrics and the request
+	// logger so a panic is still counted and logged as a 500.
+	chain = append(chain,
 		middleware.RequestID(),
-		middleware.Recovery(),
+		middleware.Tracing(),
 		metrics.PrometheusMiddleware(),
 		middleware.Compression(),
-		middleware.RequestLogger(cfg.LoggingService),
+		middleware.RequestLoggerWithConfig(cfg.LoggingService, cfg.RequestLogging),
+		middleware.Recovery(),
 		middleware.ErrorHandler(),
 	)
 
+	// Tenant message overrides apply to every message, rejections included;
+	// presentation rules and geofences can be set per tenant too
+	if (cfg.MessageOverrides != nil || cfg.Presentation != nil || cfg.GeoFence != nil) && len(cfg.APIKeys) > 0 {
+		chain = append(chain, cfg.apiKeySet().Tenant())
+	}
+
+	// Draining rejects new requests but leaves health checks to report it
+	if cfg.Drainer != nil {
+		chain = append(chain, cfg.Drainer.Reject("/healthz", "/readyz", "/metrics", drainPath))
+	}
+
+	// Calculate and auth bodies are small; batch and bulk endpoints have their own item limits
+	if cfg.MaxBodyBytes > 0 {
+		chain = append(chain, middleware.BodyLimit(cfg.MaxBodyBytes, "/api/calculate", "/api/auth/"))
+	}
+
 	// Context setup middleware
-	router.Use(func(c *gin.Context) {
+	chain = append(chain, func(c *gin.Context) {
 		c.Set("logging_service", cfg.LoggingService)
+		if cfg.ProblemDetails {
+			c.Set(problemDetailsKey, true)
+		}
 		c.Next()
 	})
 
+	// The geofence runs after the context setup so blocked requests are audited
+	if cfg.GeoFence != nil {
+		chain = append(chain, cfg.GeoFence.Enforce("/healthz", "/readyz", "/metrics"))
+	}
+	// IP rules run before rate limiting so blocked ranges do not use up the limits
+	if cfg.IPRules != nil {
+		chain = append(chain, middleware.IPFilter(cfg.IPRules, "/healthz", "/readyz", "/metrics"))
+	}
+
 	// Global rate limiting
 	if cfg.RateLimit > 0 {
-		limiter := middleware.NewRateLimiter(cfg.RateLimit, cfg.RateWindow)
-		router.Use(limiter.RateLimit())
+		limiter := middleware.NewRateLimiter(cfg.RateLimit, cfg.RateWindow, middleware.WithLimiterName("global"))
+		cfg.RateLimiters.Add(limiter)
+		chain = append(chain, limiter.RateLimit())
 	}
+	return chain
 }
 
 // registerInfrastructureRoutes registers health, metrics, and documentation routes.
 func registerInfrastructureRoutes(router *gin.Engine, healthHandler *HealthHandler, cfg *RouterConfig) {
 	healthHandler.Register(router)
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	registerDocsRoutes(router, cfg)
+}
 
-	// Swagger with optional basic auth
-	if cfg.SwaggerUser != "" && cfg.SwaggerPass != "" {
-		authorized := router.Group("/swagger", gin.BasicAuth(gin.Accounts{
-			cfg.SwaggerUser: cfg.SwaggerPass,
-		}))
-		authorized.GET("/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	} else {
-		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+// apiMiddleware returns the middleware for the API group.
+func apiMiddleware(cfg *RouterConfig) []gin.HandlerFunc {
+	var chain []gin.HandlerFunc
+
+	// Bodies breaking the documented schema never reach the handlers or the idempotency cache
+	if cfg.RequestValidator != nil {
+		chain = append(chain, cfg.RequestValidator.Validate())
 	}
-}
 
-// configureAPIMiddleware sets up middleware for the API group.
-func configureAPIMiddleware(api *gin.RouterGroup, cfg *RouterConfig) {
 	// Idempotency middleware
 	if cfg.EnableIdempotency {
 		idempotencyCfg := middleware.DefaultIdempotencyConfig()
-		api.Use(middleware.Idempotency(idempotencyCfg))
+		chain = append(chain, middleware.Idempotency(idempotencyCfg))
+	}
+
+	// Client usage is recorded after the request, once auth has identified the caller
+	if cfg.ClientUsage != nil {
+		chain = append(chain, middleware.ClientUsage(cfg.ClientUsage))
 	}
 
-	// API key authentication (when JWT auth is not enabled)
-	if cfg.EnableAuth && cfg.AuthService == nil && len(cfg.APIKeys) > 0 {
-		api.Use(middleware.APIKeyAuth(cfg.APIKeys))
+	// API key authentication per route group (when JWT auth is not enabled)
+	if cfg.AuthService == nil {
+		if cfg.routeAuth == nil {
+			cfg.routeAuth = newRouteAuthPolicy(cfg)
+		}
+		chain = append(chain, routeGroupAuth(cfg))
 	}
+	return chain
 }
 
 // registerAuthenticatedRoutes registers routes when JWT authentication is enabled.
 func registerAuthenticatedRoutes(api *gin.RouterGroup, handler *Handler, cfg *RouterConfig) {
 	// Create auth routes
-	authRoutes := NewAuthRoutes(cfg.AuthService)
+	authRoutes := NewAuthRoutes(cfg.AuthService, WithDPoP(cfg.DPoPVerifier, cfg.RequireDPoP), WithCountryHeader(cfg.CountryHeader))
 
 	// Register public auth routes (login, register, refresh)
 	authRoutes.RegisterPublicRoutes(api)
@@ -145,12 +359,36 @@ func registerAuthenticatedRoutes(api *gin.RouterGroup, handler *Handler, cfg *Ro
 	// Get protected group with JWT auth
 	protected := authRoutes.GetProtectedGroup(api, cfg)
 
-	// Register logout route
+	// Register logout routes
 	protected.POST("/auth/logout", authRoutes.handler.Logout)
+	protected.POST("/auth/logout-all", authRoutes.handler.LogoutAll)
 
 	// Create and register pack routes
-	packRoutes := NewPackRoutes(handler.calculator, cfg.PackSizesService)
+	packRoutes := NewPackRoutes(handler.calculator, cfg.PackSizesService, packHandlerOptions(cfg)...)
 	packRoutes.RegisterProtectedRoutes(protected, cfg)
+
+	if cfg.PresetService != nil {
+		NewPresetRoutes(cfg.PresetService).RegisterProtectedRoutes(protected, cfg)
+	}
+
+	if cfg.UserService != nil {
+		userRoutes := NewUserRoutes(cfg.UserService, cfg.Cursors)
+		userRoutes.RegisterSelfRoutes(protected)
+		userRoutes.RegisterProtectedRoutes(protected, cfg)
+	}
+
+	if cfg.CalculationHistory != nil {
+		NewCalculationRoutes(cfg.CalculationHistory, cfg.DisplayLocation, cfg.Cursors).RegisterProtectedRoutes(protected, cfg)
+	}
+
+	if cfg.LoggingService != nil {
+		NewLogRoutes(cfg.LoggingService, cfg.DisplayLocation, cfg.Cursors).RegisterProtectedRoutes(protected, cfg)
+	}
+
+	// Register admin routes
+	if adminRoutes := NewAdminRoutes(cfg); adminRoutes.HasRoutes() {
+		adminRoutes.RegisterProtectedRoutes(protected, cfg)
+	}
 }
 
 // registerPublicRoutes registers routes when authentication is disabled.
@@ -158,6 +396,45 @@ func registerPublicRoutes(api *gin.RouterGroup, handler *Handler, cfg *RouterCon
 	if handler == nil {
 		return
 	}
-	packRoutes := NewPackRoutes(handler.calculator, cfg.PackSizesService)
+	packRoutes := NewPackRoutes(handler.calculator, cfg.PackSizesService, packHandlerOptions(cfg)...)
 	packRoutes.RegisterPublicRoutes(api)
+
+	if cfg.PresetService != nil {
+		NewPresetRoutes(cfg.PresetService).RegisterPublicRoutes(api)
+	}
+
+	if cfg.CalculationHistory != nil {
+		NewCalculationRoutes(cfg.CalculationHistory, cfg.DisplayLocation, cfg.Cursors).RegisterPublicRoutes(api)
+	}
+
+	if cfg.EnableAuth && len(cfg.APIKeys) > 0 {
+		NewAdminRoutes(cfg).RegisterAPIKeyRoutes(api)
+	}
+
+	if len(cfg.APIKeys) > 0 && cfg.routeAuth != nil {
+		NewRouteAuthRoutes(cfg.routeAuth).RegisterAPIKeyRoutes(api, cfg.apiKeySet())
+	}
+}
+
+// packHandlerOptions configures the pack handler from the router dependencies.
+func packHandlerOptions(cfg *RouterConfig) []HandlerOption {
+	return []HandlerOption{
+		WithPresetService(cfg.PresetService),
+		WithDeprecationTracker(cfg.Deprecations),
+		WithBatchPool(cfg.BatchPool),
+		WithHeavyRequests(cfg.HeavyRequests),
+		WithCalculationHistory(cfg.CalculationHistory),
+		WithMaxCompute(cfg.MaxCompute),
+		WithDecimalPrecision(cfg.DecimalPrecision),
+		WithStreamJobs(cfg.StreamJobs),
+		WithCalculationJobs(cfg.CalculationJobs),
+		WithHooks(cfg.Hooks),
+		WithPresentation(cfg.Presentation),
+		WithPackSizesMigrator(cfg.PackSizesMigrator),
+		WithPackSizesWatcher(cfg.PackSizesWatcher),
+		WithActivePackSizes(cfg.ActivePackSizes),
+		WithEdgeCache(cfg.EdgeCache),
+		WithPackSizeRules(cfg.PackSizeRules),
+		WithResponseCache(cfg.ResponseCache),
+	}
 }
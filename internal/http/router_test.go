@@ -3,10 +3,13 @@ package http
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/docs"
+	"github.com/guttosm/pack-service/internal/middleware"
 	"github.com/guttosm/pack-service/internal/service"
 	"github.com/stretchr/testify/assert"
 )
@@ -63,6 +66,25 @@ func TestNewRouter(t *testing.T) {
 		},
 	}
 
+	validator, err := middleware.NewRequestValidator([]byte(docs.SwaggerInfo.ReadDoc()), middleware.RequestValidationEnforce)
+	assert.NoError(t, err)
+	tests = append(tests, struct {
+		name string
+		cfg  RouterConfig
+		test func(*testing.T, *gin.Engine)
+	}{
+		name: "creates router with request validation",
+		cfg:  RouterConfig{RequestValidator: validator},
+		test: func(t *testing.T, router *gin.Engine) {
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader(`{"items_ordered": 10, "pack_sizes": ["5"]}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), `"pack_sizes.0"`)
+		},
+	})
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			router := NewRouter(handler, healthHandler, tt.cfg)
@@ -104,9 +126,15 @@ func TestRouter_Endpoints(t *testing.T) {
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:           "swagger endpoint",
+			name:           "docs disabled by default",
+			method:         http.MethodGet,
+			path:           "/docs/index.html",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "error catalogue endpoint",
 			method:         http.MethodGet,
-			path:           "/swagger/index.html",
+			path:           "/api/errors",
 			expectedStatus: http.StatusOK,
 		},
 		{
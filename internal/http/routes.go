@@ -21,3 +21,12 @@ type ProtectedRouteGroup interface {
 	// RegisterProtectedRoutes registers protected routes to the given router group.
 	RegisterProtectedRoutes(rg *gin.RouterGroup, cfg *RouterConfig)
 }
+
+// Response cache groups, each dropped when the resource it shows changes.
+const (
+	responseCachePackSizes        = "pack-sizes"
+	responseCacheUsers            = "users"
+	responseCacheLogs             = "logs"
+	responseCacheClients          = "clients"
+	responseCacheMessageOverrides = "message-overrides"
+)
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+)
+
+// AdminRoutes handles administrative route registration.
+type AdminRoutes struct {
+	supportHandler     *SupportHandler
+	deprecationHandler *DeprecationHandler
+	clientUsageHandler *ClientUsageHandler
+	webhookHandler     *WebhookHealthHandler
+	metricsHandler     *MetricsCardinalityHandler
+	breakerHandler     *CircuitBreakerHandler
+	drainHandler       *DrainHandler
+	geoFenceHandler    *GeoFenceHandler
+	ipRuleHandler      *IPRuleHandler
+	messageHandler     *MessageOverrideHandler
+	tokenHandler       *TokenCleanupHandler
+	configHandler      *ConfigReloadHandler
+	signingKeyHandler  *SigningKeyHandler
+	cacheHandler       *CacheHandler
+	responseCache      *middleware.ResponseCache
+}
+
+// NewAdminRoutes creates a new AdminRoutes instance from the admin
+// dependencies in cfg. Routes whose dependency is nil are not registered.
+func NewAdminRoutes(cfg *RouterConfig) *AdminRoutes {
+	r := &AdminRoutes{responseCache: cfg.ResponseCache}
+	if cfg.SupportBundle != nil {
+		r.supportHandler = NewSupportHandler(cfg.SupportBundle)
+	}
+	if cfg.Deprecations != nil {
+		r.deprecationHandler = NewDeprecationHandler(cfg.Deprecations)
+	}
+	if cfg.ClientUsage != nil {
+		r.clientUsageHandler = NewClientUsageHandler(cfg.ClientUsage, cfg.DisplayLocation)
+	}
+	if cfg.WebhookMonitor != nil {
+		r.webhookHandler = NewWebhookHealthHandler(cfg.WebhookMonitor)
+	}
+	if cfg.MetricsGatherer != nil {
+		r.metricsHandler = NewMetricsCardinalityHandler(cfg.MetricsGatherer)
+	}
+	if cfg.CircuitBreakers != nil {
+		r.breakerHandler = NewCircuitBreakerHandler(cfg.CircuitBreakers)
+	}
+	if cfg.Drainer != nil {
+		r.drainHandler = NewDrainHandler(cfg.Drainer)
+	}
+	if cfg.GeoFence != nil {
+		r.geoFenceHandler = NewGeoFenceHandler(cfg.GeoFence)
+	}
+	if cfg.IPRules != nil {
+		r.ipRuleHandler = NewIPRuleHandler(cfg.IPRules)
+	}
+	if cfg.MessageOverrides != nil {
+		r.messageHandler = NewMessageOverrideHandler(cfg.MessageOverrides)
+	}
+	if cfg.TokenCleanup != nil {
+		r.tokenHandler = NewTokenCleanupHandler(cfg.TokenCleanup)
+	}
+	if cfg.ConfigReloader != nil {
+		r.configHandler = NewConfigReloadHandler(cfg.ConfigReloader)
+	}
+	if cfg.SigningKeys != nil {
+		r.signingKeyHandler = NewSigningKeyHandler(cfg.SigningKeys)
+	}
+	if calculator, ok := cfg.Calculator.(CacheManager); ok {
+		r.cacheHandler = NewCacheHandler(calculator, cfg.ActivePackSizes)
+	}
+	return r
+}
+
+// HasRoutes reports whether any admin route would be registered.
+func (r *AdminRoutes) HasRoutes() bool {
+	return r.supportHandler != nil || r.deprecationHandler != nil || r.clientUsageHandler != nil ||
+		r.webhookHandler != nil || r.metricsHandler != nil || r.breakerHandler != nil || r.drainHandler != nil ||
+		r.geoFenceHandler != nil || r.messageHandler != nil || r.tokenHandler != nil || r.configHandler != nil ||
+		r.signingKeyHandler != nil || r.ipRuleHandler != nil || r.cacheHandler != nil
+}
+
+// RegisterProtectedRoutes registers admin routes (when auth is enabled).
+// Unlike pack routes, admin routes fail closed: they are only registered
+// when the system:read permission can be enforced.
+func (r *AdminRoutes) RegisterProtectedRoutes(protected *gin.RouterGroup, cfg *RouterConfig) {
+	if cfg.PermissionService == nil || cfg.RoleService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	systemReadPermID := cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "system", "read")
+	if systemReadPermID == "" {
+		return
+	}
+
+	admin := protected.Group("/admin")
+	admin.Use(middleware.RequireAuthorization(middleware.AuthorizationConfig{
+		RequiredPermissions: []string{systemReadPermID},
+	}, cfg.RoleService, cfg.PermissionService))
+
+	if r.supportHandler != nil {
+		admin.GET("/support-bundle", r.supportHandler.DownloadBundle)
+	}
+	if r.deprecationHandler != nil {
+		admin.GET("/deprecations", r.deprecationHandler.GetReport)
+	}
+	if r.clientUsageHandler != nil {
+		admin.GET("/clients", r.responseCache.Cached(responseCacheClients), r.clientUsageHandler.ListClients)
+	}
+	if r.webhookHandler != nil {
+		admin.GET("/webhooks", r.webhookHandler.GetHealth)
+	}
+	if r.metricsHandler != nil {
+		admin.GET("/metrics/cardinality", r.metricsHandler.GetReport)
+	}
+	if r.breakerHandler != nil {
+		admin.GET("/circuit-breakers", r.breakerHandler.ListBreakers)
+	}
+	if r.geoFenceHandler != nil {
+		admin.GET("/geofence", r.geoFenceHandler.GetGeoFence)
+		admin.GET("/geofence/tenants", r.geoFenceHandler.ListTenantGeoFences)
+	}
+	if r.ipRuleHandler != nil {
+		admin.GET("/ip-rules", r.ipRuleHandler.ListIPRules)
+	}
+	if r.messageHandler != nil {
+		admin.GET("/message-overrides", r.responseCache.Cached(responseCacheMessageOverrides), r.messageHandler.ListMessageOverrides)
+	}
+	if r.signingKeyHandler != nil {
+		admin.GET("/signing-keys", r.signingKeyHandler.ListSigningKeys)
+	}
+	if r.cacheHandler != nil {
+		admin.GET("/cache/stats", r.cacheHandler.GetStats)
+	}
+
+	// Operations change how the service behaves, so they also need system:write
+	if r.breakerHandler == nil && r.drainHandler == nil && r.geoFenceHandler == nil && r.messageHandler == nil &&
+		r.tokenHandler == nil && r.configHandler == nil && r.signingKeyHandler == nil && r.ipRuleHandler == nil &&
+		r.cacheHandler == nil {
+		return
+	}
+	systemWritePermID := cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "system", "write")
+	if systemWritePermID == "" {
+		return
+	}
+	systemWrite := middleware.RequireAuthorization(middleware.AuthorizationConfig{
+		RequiredPermissions: []string{systemWritePermID},
+	}, cfg.RoleService, cfg.PermissionService)
+	if r.breakerHandler != nil {
+		admin.POST("/circuit-breakers/:name/reset", systemWrite, r.breakerHandler.ResetBreaker)
+	}
+	if r.drainHandler != nil {
+		admin.POST("/drain", systemWrite, r.drainHandler.Drain)
+		admin.DELETE("/drain", systemWrite, r.drainHandler.Resume)
+	}
+	if r.geoFenceHandler != nil {
+		admin.PUT("/geofence", systemWrite, r.geoFenceHandler.SetGeoFence)
+		admin.PUT("/geofence/tenants/:tenant", systemWrite, r.geoFenceHandler.SetTenantGeoFence)
+		admin.DELETE("/geofence/tenants/:tenant", systemWrite, r.geoFenceHandler.DeleteTenantGeoFence)
+	}
+	if r.ipRuleHandler != nil {
+		admin.POST("/ip-rules", systemWrite, r.ipRuleHandler.CreateIPRule)
+		admin.DELETE("/ip-rules/:id", systemWrite, r.ipRuleHandler.DeleteIPRule)
+	}
+	if r.messageHandler != nil {
+		invalidate := r.responseCache.Invalidates(responseCacheMessageOverrides)
+		admin.PUT("/message-overrides/:tenant/:locale/:key", systemWrite, invalidate, r.messageHandler.SetMessageOverride)
+		admin.DELETE("/message-overrides/:tenant/:locale/:key", systemWrite, invalidate, r.messageHandler.DeleteMessageOverride)
+	}
+	if r.tokenHandler != nil {
+		admin.POST("/tokens/cleanup", systemWrite, r.tokenHandler.Cleanup)
+	}
+	if r.configHandler != nil {
+		admin.POST("/config/reload", systemWrite, r.configHandler.Reload)
+	}
+	if r.signingKeyHandler != nil {
+		admin.POST("/signing-keys", systemWrite, r.signingKeyHandler.AddSigningKey)
+		admin.POST("/signing-keys/:kid/retire", systemWrite, r.signingKeyHandler.RetireSigningKey)
+	}
+	if r.cacheHandler != nil {
+		admin.POST("/cache/clear", systemWrite, r.cacheHandler.Clear)
+		admin.DELETE("/cache/:key", systemWrite, r.cacheHandler.DeleteEntry)
+	}
+}
+
+// RegisterAPIKeyRoutes registers the client reports for API key holders when
+// JWT auth is disabled. Without JWT auth there is no admin role, and API key
+// holders are the only clients the reports describe. The support bundle and
+// the operational reports and actions (webhook health, metric cardinality,
+// circuit breakers, draining, geofencing, IP rules, message overrides, token
+// cleanup, config reloads, signing keys, the result cache) are never exposed
+// this way.
+func (r *AdminRoutes) RegisterAPIKeyRoutes(api *gin.RouterGroup) {
+	if r.deprecationHandler != nil {
+		api.GET("/admin/deprecations", r.deprecationHandler.GetReport)
+	}
+	if r.clientUsageHandler != nil {
+		api.GET("/admin/clients", r.responseCache.Cached(responseCacheClients), r.clientUsageHandler.ListClients)
+	}
+}
@@ -13,13 +13,22 @@ type AuthRoutes struct {
 }
 
 // NewAuthRoutes creates a new AuthRoutes instance.
-func NewAuthRoutes(authService service.AuthService) *AuthRoutes {
+func NewAuthRoutes(authService service.AuthService, opts ...AuthHandlerOption) *AuthRoutes {
 	return &AuthRoutes{
-		handler:     NewAuthHandler(authService),
+		handler:     NewAuthHandler(authService, opts...),
 		authService: authService,
 	}
 }
 
+// jwtAuth returns the JWT middleware, verifying DPoP proofs with the same
+// verifier as the token endpoints so proof replays are caught across both,
+// and enriching claims with the hooks of cfg.
+func (r *AuthRoutes) jwtAuth(cfg *RouterConfig) gin.HandlerFunc {
+	return middleware.JWTAuth(r.authService,
+		middleware.WithDPoP(r.handler.dpopVerifier, r.handler.requireDPoP),
+		middleware.WithHooks(cfg.Hooks))
+}
+
 // RegisterPublicRoutes registers public authentication routes.
 // These routes don't require authentication.
 func (r *AuthRoutes) RegisterPublicRoutes(rg *gin.RouterGroup) {
@@ -34,30 +43,27 @@ func (r *AuthRoutes) RegisterPublicRoutes(rg *gin.RouterGroup) {
 // RegisterProtectedRoutes registers protected authentication routes.
 // These routes require JWT authentication.
 func (r *AuthRoutes) RegisterProtectedRoutes(rg *gin.RouterGroup, cfg *RouterConfig) {
-	// Apply JWT authentication middleware
-	protected := rg.Group("")
-	protected.Use(middleware.JWTAuth(r.authService))
-
-	// Apply user-specific rate limiting if configured
-	if cfg.RateLimit > 0 {
-		userLimiter := middleware.NewRateLimiter(cfg.RateLimit, cfg.RateWindow)
-		protected.Use(userLimiter.UserRateLimit())
-	}
+	// Apply JWT authentication and user-specific rate limiting
+	protected := rg.Group("", r.protectedMiddleware(cfg)...)
 
 	// Register logout endpoint
 	protected.POST("/auth/logout", r.handler.Logout)
+	protected.POST("/auth/logout-all", r.handler.LogoutAll)
 }
 
 // GetProtectedGroup returns a protected router group with JWT auth middleware applied.
-// This is useful for other route registrars that need to register protected routes.
 func (r *AuthRoutes) GetProtectedGroup(rg *gin.RouterGroup, cfg *RouterConfig) *gin.RouterGroup {
-	protected := rg.Group("")
-	protected.Use(middleware.JWTAuth(r.authService))
+	return rg.Group("", r.protectedMiddleware(cfg)...)
+}
 
+// protectedMiddleware returns JWT authentication followed by user-specific
+// rate limiting when a rate limit is configured.
+func (r *AuthRoutes) protectedMiddleware(cfg *RouterConfig) []gin.HandlerFunc {
+	chain := []gin.HandlerFunc{r.jwtAuth(cfg)}
 	if cfg.RateLimit > 0 {
-		userLimiter := middleware.NewRateLimiter(cfg.RateLimit, cfg.RateWindow)
-		protected.Use(userLimiter.UserRateLimit())
+		userLimiter := middleware.NewRateLimiter(cfg.RateLimit, cfg.RateWindow, middleware.WithLimiterName("user"))
+		cfg.RateLimiters.Add(userLimiter)
+		chain = append(chain, userLimiter.UserRateLimit())
 	}
-
-	return protected
+	return chain
 }
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// CalculationRoutes handles calculation history route registration.
+type CalculationRoutes struct {
+	handler *CalculationHandler
+}
+
+// NewCalculationRoutes creates a new CalculationRoutes instance.
+func NewCalculationRoutes(history service.CalculationHistoryService, location *time.Location, cursors *pagination.Codec) *CalculationRoutes {
+	return &CalculationRoutes{
+		handler: NewCalculationHandler(history, location, cursors),
+	}
+}
+
+// RegisterPublicRoutes registers the history and analytics routes when auth
+// is disabled.
+func (r *CalculationRoutes) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.GET("/calculations", r.handler.ListCalculations)
+	rg.GET("/analytics/calculations", r.handler.CalculationRollups)
+	rg.GET("/analytics/calculations/:day", r.handler.CalculationDrilldown)
+}
+
+// RegisterProtectedRoutes registers the history and analytics routes (when
+// auth is enabled). They cover every user's calculations, so like the admin
+// routes they require system:read and are not registered when that cannot be
+// enforced.
+func (r *CalculationRoutes) RegisterProtectedRoutes(protected *gin.RouterGroup, cfg *RouterConfig) {
+	if cfg.PermissionService == nil || cfg.RoleService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	systemReadPermID := cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "system", "read")
+	if systemReadPermID == "" {
+		return
+	}
+
+	requireSystemRead := middleware.RequireAuthorization(middleware.AuthorizationConfig{
+		RequiredPermissions: []string{systemReadPermID},
+	}, cfg.RoleService, cfg.PermissionService)
+	protected.GET("/calculations", requireSystemRead, r.handler.ListCalculations)
+	protected.GET("/analytics/calculations", requireSystemRead, r.handler.CalculationRollups)
+	protected.GET("/analytics/calculations/:day", requireSystemRead, r.handler.CalculationDrilldown)
+}
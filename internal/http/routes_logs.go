@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// LogRoutes handles log query route registration.
+type LogRoutes struct {
+	handler *LogHandler
+}
+
+// NewLogRoutes creates a new LogRoutes instance.
+func NewLogRoutes(logs service.LoggingService, location *time.Location, cursors *pagination.Codec) *LogRoutes {
+	return &LogRoutes{
+		handler: NewLogHandler(logs, location, cursors),
+	}
+}
+
+// RegisterProtectedRoutes registers the log query and export routes (when
+// auth is enabled), guarded by the logs:read permission. Logs carry user
+// emails and IP addresses, so the routes fail closed and have no API key
+// variant.
+func (r *LogRoutes) RegisterProtectedRoutes(protected *gin.RouterGroup, cfg *RouterConfig) {
+	if cfg.PermissionService == nil || cfg.RoleService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	logsReadPermID := cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "logs", "read")
+	if logsReadPermID == "" {
+		return
+	}
+
+	logsRead := middleware.RequireAuthorization(middleware.AuthorizationConfig{
+		RequiredPermissions: []string{logsReadPermID},
+	}, cfg.RoleService, cfg.PermissionService)
+	protected.GET("/admin/logs", logsRead, cfg.ResponseCache.Cached(responseCacheLogs), r.handler.ListLogs)
+	protected.GET("/admin/logs/export", logsRead, r.handler.ExportLogs)
+}
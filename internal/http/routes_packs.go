@@ -5,6 +5,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/deprecation"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/middleware"
 	"github.com/guttosm/pack-service/internal/service"
 )
@@ -16,12 +19,29 @@ type PackRoutes struct {
 }
 
 // NewPackRoutes creates a new PackRoutes instance.
-func NewPackRoutes(calculator service.PackCalculator, packSizesService service.PackSizesService) *PackRoutes {
-	handler := NewHandler(calculator, packSizesService)
+func NewPackRoutes(calculator service.PackCalculator, packSizesService service.PackSizesService, opts ...HandlerOption) *PackRoutes {
+	handler := NewHandler(calculator, packSizesService, opts...)
 	
 	var packSizesHandler *PackSizesHandler
 	if packSizesService != nil {
 		packSizesHandler = NewPackSizesHandler(packSizesService, calculator)
+		// Share the calculation cache so an update is visible to the next calculation
+		packSizesHandler.packSizesCache = handler.packSizesCache
+		packSizesHandler.deprecations = handler.deprecations
+		packSizesHandler.migrator = handler.migrator
+		packSizesHandler.edgeCache = handler.edgeCache
+		packSizesHandler.rules = handler.packSizeRules
+		packSizesHandler.responseCache = handler.responseCache
+		packSizesHandler.activePackSizes = handler.activePackSizes
+	}
+	if handler.calculationJobs != nil {
+		// Jobs run like batches, so the workers need this handler
+		handler.calculationJobs.Start(handler.processCalculationJob)
+	}
+	if handler.activePackSizes != nil {
+		handler.activePackSizes.Start(handler.invalidatePackSizes)
+	} else if handler.packSizesWatcher != nil {
+		service.WatchPackSizes(context.Background(), handler.packSizesWatcher, handler.invalidatePackSizes)
 	}
 	
 	return &PackRoutes{
@@ -32,17 +52,42 @@ func NewPackRoutes(calculator service.PackCalculator, packSizesService service.P
 
 // RegisterPublicRoutes registers public pack routes (when auth is disabled).
 func (r *PackRoutes) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	r.declareDeprecations(rg)
+
 	rg.POST("/calculate", r.handler.CalculatePacks)
+	rg.POST("/calculate/quantity", r.handler.CalculateQuantity)
+	rg.POST("/calculate/batch", r.heavy(r.handler.CalculateBatch)...)
+	if r.handler.streamJobs != nil {
+		rg.POST("/calculate/stream", r.handler.SubmitCalculationStream)
+		rg.GET("/calculate/stream", r.heavy(r.handler.StreamCalculation)...)
+	}
+	if r.handler.calculationJobs != nil {
+		rg.POST("/jobs/calculate", r.handler.SubmitCalculationJob)
+		rg.GET("/jobs/:id", r.handler.GetCalculationJob)
+	}
 	
 	if r.packSizesHandler != nil {
-		rg.GET("/pack-sizes", r.packSizesHandler.GetActivePackSizes)
+		rg.GET("/pack-sizes", r.cached(r.packSizesHandler.GetActivePackSizes)...)
 		rg.PUT("/pack-sizes", r.packSizesHandler.UpdatePackSizes)
-		rg.GET("/pack-sizes/history", r.packSizesHandler.ListPackSizes)
+		rg.POST("/pack-sizes/:id/activate", r.packSizesHandler.ActivatePackSizes)
+		rg.POST("/pack-sizes/rollback", r.packSizesHandler.RollbackPackSizes)
+		rg.GET("/pack-sizes/history", r.cached(r.packSizesHandler.ListPackSizes)...)
+		rg.GET("/pack-sizes/affected", r.packSizesHandler.GetAffectedCalculations)
+		rg.POST("/pack-sizes/validate", r.packSizesHandler.ValidatePackSizes)
+		rg.POST("/pack-sizes/simulate", r.heavy(r.packSizesHandler.SimulatePackSizes)...)
+		if r.packSizesHandler.migrator != nil {
+			rg.GET("/pack-sizes/migration", r.packSizesHandler.GetPackSizesMigration)
+			rg.POST("/pack-sizes/migration", r.packSizesHandler.StartPackSizesMigration)
+			rg.POST("/pack-sizes/migration/promote", r.packSizesHandler.PromotePackSizesMigration)
+			rg.DELETE("/pack-sizes/migration", r.packSizesHandler.CancelPackSizesMigration)
+		}
 	}
 }
 
 // RegisterProtectedRoutes registers protected pack routes (when auth is enabled).
 func (r *PackRoutes) RegisterProtectedRoutes(protected *gin.RouterGroup, cfg *RouterConfig) {
+	r.declareDeprecations(protected)
+
 	// Get permission IDs for authorization
 	packsReadPermID, packsWritePermID := r.getPermissionIDs(cfg)
 	
@@ -58,11 +103,31 @@ func (r *PackRoutes) RegisterProtectedRoutes(protected *gin.RouterGroup, cfg *Ro
 		return nil
 	}
 	
-	// Register calculate endpoint
+	// Register calculate endpoints
 	if writeAuth := authMiddleware(packsWritePermID); writeAuth != nil {
 		protected.POST("/calculate", append(writeAuth, r.handler.CalculatePacks)...)
+		protected.POST("/calculate/quantity", append(writeAuth, r.handler.CalculateQuantity)...)
+		protected.POST("/calculate/batch", append(writeAuth, r.heavy(r.handler.CalculateBatch)...)...)
+		if r.handler.streamJobs != nil {
+			protected.POST("/calculate/stream", append(writeAuth, r.handler.SubmitCalculationStream)...)
+			protected.GET("/calculate/stream", append(writeAuth, r.heavy(r.handler.StreamCalculation)...)...)
+		}
+		if r.handler.calculationJobs != nil {
+			protected.POST("/jobs/calculate", append(writeAuth, r.handler.SubmitCalculationJob)...)
+			protected.GET("/jobs/:id", append(writeAuth, r.handler.GetCalculationJob)...)
+		}
 	} else {
 		protected.POST("/calculate", r.handler.CalculatePacks)
+		protected.POST("/calculate/quantity", r.handler.CalculateQuantity)
+		protected.POST("/calculate/batch", r.heavy(r.handler.CalculateBatch)...)
+		if r.handler.streamJobs != nil {
+			protected.POST("/calculate/stream", r.handler.SubmitCalculationStream)
+			protected.GET("/calculate/stream", r.heavy(r.handler.StreamCalculation)...)
+		}
+		if r.handler.calculationJobs != nil {
+			protected.POST("/jobs/calculate", r.handler.SubmitCalculationJob)
+			protected.GET("/jobs/:id", r.handler.GetCalculationJob)
+		}
 	}
 	
 	// Register pack sizes endpoints if service is available
@@ -79,18 +144,77 @@ func (r *PackRoutes) registerPackSizesRoutes(
 ) {
 	// GET /pack-sizes
 	if readAuth := authMiddleware(packsReadPermID); readAuth != nil {
-		protected.GET("/pack-sizes", append(readAuth, r.packSizesHandler.GetActivePackSizes)...)
-		protected.GET("/pack-sizes/history", append(readAuth, r.packSizesHandler.ListPackSizes)...)
+		protected.GET("/pack-sizes", append(readAuth, r.cached(r.packSizesHandler.GetActivePackSizes)...)...)
+		protected.GET("/pack-sizes/history", append(readAuth, r.cached(r.packSizesHandler.ListPackSizes)...)...)
+		protected.GET("/pack-sizes/affected", append(readAuth, r.packSizesHandler.GetAffectedCalculations)...)
+		protected.POST("/pack-sizes/validate", append(readAuth, r.packSizesHandler.ValidatePackSizes)...)
+		protected.POST("/pack-sizes/simulate", append(readAuth, r.heavy(r.packSizesHandler.SimulatePackSizes)...)...)
+		if r.packSizesHandler.migrator != nil {
+			protected.GET("/pack-sizes/migration", append(readAuth, r.packSizesHandler.GetPackSizesMigration)...)
+		}
 	} else {
-		protected.GET("/pack-sizes", r.packSizesHandler.GetActivePackSizes)
-		protected.GET("/pack-sizes/history", r.packSizesHandler.ListPackSizes)
+		protected.GET("/pack-sizes", r.cached(r.packSizesHandler.GetActivePackSizes)...)
+		protected.GET("/pack-sizes/history", r.cached(r.packSizesHandler.ListPackSizes)...)
+		protected.GET("/pack-sizes/affected", r.packSizesHandler.GetAffectedCalculations)
+		protected.POST("/pack-sizes/validate", r.packSizesHandler.ValidatePackSizes)
+		protected.POST("/pack-sizes/simulate", r.heavy(r.packSizesHandler.SimulatePackSizes)...)
+		if r.packSizesHandler.migrator != nil {
+			protected.GET("/pack-sizes/migration", r.packSizesHandler.GetPackSizesMigration)
+		}
 	}
 	
-	// PUT /pack-sizes
+	// PUT /pack-sizes and version switches
 	if writeAuth := authMiddleware(packsWritePermID); writeAuth != nil {
 		protected.PUT("/pack-sizes", append(writeAuth, r.packSizesHandler.UpdatePackSizes)...)
+		protected.POST("/pack-sizes/:id/activate", append(writeAuth, r.packSizesHandler.ActivatePackSizes)...)
+		protected.POST("/pack-sizes/rollback", append(writeAuth, r.packSizesHandler.RollbackPackSizes)...)
+		if r.packSizesHandler.migrator != nil {
+			protected.POST("/pack-sizes/migration", append(writeAuth, r.packSizesHandler.StartPackSizesMigration)...)
+			protected.POST("/pack-sizes/migration/promote", append(writeAuth, r.packSizesHandler.PromotePackSizesMigration)...)
+			protected.DELETE("/pack-sizes/migration", append(writeAuth, r.packSizesHandler.CancelPackSizesMigration)...)
+		}
 	} else {
 		protected.PUT("/pack-sizes", r.packSizesHandler.UpdatePackSizes)
+		protected.POST("/pack-sizes/:id/activate", r.packSizesHandler.ActivatePackSizes)
+		protected.POST("/pack-sizes/rollback", r.packSizesHandler.RollbackPackSizes)
+		if r.packSizesHandler.migrator != nil {
+			protected.POST("/pack-sizes/migration", r.packSizesHandler.StartPackSizesMigration)
+			protected.POST("/pack-sizes/migration/promote", r.packSizesHandler.PromotePackSizesMigration)
+			protected.DELETE("/pack-sizes/migration", r.packSizesHandler.CancelPackSizesMigration)
+		}
+	}
+}
+
+// heavy returns the handler chain of a CPU-heavy route: handler, run on a
+// worker of the heavy request pool when there is one.
+func (r *PackRoutes) heavy(handler gin.HandlerFunc) []gin.HandlerFunc {
+	if r.handler.heavyRequests == nil {
+		return []gin.HandlerFunc{handler}
+	}
+	return []gin.HandlerFunc{middleware.ConcurrencyLimit(r.handler.heavyRequests), handler}
+}
+
+// declareDeprecations lists the deprecated fields of each route in the
+// deprecation report, so unused fields show up as safe to remove.
+// cached returns the handler chain of a pack size read: handler, answered
+// from the response cache when there is one. The pack sizes handler drops
+// the cached responses whenever the sizes change.
+func (r *PackRoutes) cached(handler gin.HandlerFunc) []gin.HandlerFunc {
+	if r.handler.responseCache == nil {
+		return []gin.HandlerFunc{handler}
+	}
+	return []gin.HandlerFunc{r.handler.responseCache.Cached(responseCachePackSizes), handler}
+}
+
+func (r *PackRoutes) declareDeprecations(rg *gin.RouterGroup) {
+	tracker := r.handler.deprecations
+	if tracker == nil {
+		return
+	}
+	tracker.Declare("POST "+rg.BasePath()+"/calculate", deprecation.Sent, dto.CalculatePacksRequest{})
+	tracker.Declare("POST "+rg.BasePath()+"/calculate", deprecation.Returned, model.PackResult{})
+	if r.packSizesHandler != nil {
+		tracker.Declare("PUT "+rg.BasePath()+"/pack-sizes", deprecation.Sent, dto.UpdatePackSizesRequest{})
 	}
 }
 
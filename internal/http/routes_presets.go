@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// PresetRoutes handles calculation preset route registration.
+type PresetRoutes struct {
+	handler *PresetHandler
+}
+
+// NewPresetRoutes creates a new PresetRoutes instance.
+func NewPresetRoutes(presetService service.PresetService) *PresetRoutes {
+	return &PresetRoutes{
+		handler: NewPresetHandler(presetService),
+	}
+}
+
+// RegisterPublicRoutes registers preset routes (when auth is disabled).
+func (r *PresetRoutes) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	presets := rg.Group("/presets")
+	presets.GET("", r.handler.ListPresets)
+	presets.POST("", r.handler.CreatePreset)
+	presets.GET("/:name", r.handler.GetPreset)
+	presets.PUT("/:name", r.handler.UpdatePreset)
+	presets.DELETE("/:name", r.handler.DeletePreset)
+}
+
+// RegisterProtectedRoutes registers preset routes (when auth is enabled).
+// Presets are scoped to the authenticated user and guarded by the packs
+// permissions, like the calculations that use them.
+func (r *PresetRoutes) RegisterProtectedRoutes(protected *gin.RouterGroup, cfg *RouterConfig) {
+	readAuth, writeAuth := r.authMiddleware(cfg)
+
+	presets := protected.Group("/presets")
+	presets.GET("", append(readAuth, r.handler.ListPresets)...)
+	presets.POST("", append(writeAuth, r.handler.CreatePreset)...)
+	presets.GET("/:name", append(readAuth, r.handler.GetPreset)...)
+	presets.PUT("/:name", append(writeAuth, r.handler.UpdatePreset)...)
+	presets.DELETE("/:name", append(writeAuth, r.handler.DeletePreset)...)
+}
+
+// authMiddleware returns the packs read and write authorization middleware,
+// or empty chains when permissions are not configured.
+func (r *PresetRoutes) authMiddleware(cfg *RouterConfig) (readAuth, writeAuth []gin.HandlerFunc) {
+	if cfg.PermissionService == nil || cfg.RoleService == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require := func(permID string) []gin.HandlerFunc {
+		if permID == "" {
+			return nil
+		}
+		return []gin.HandlerFunc{
+			middleware.RequireAuthorization(middleware.AuthorizationConfig{
+				RequiredPermissions: []string{permID},
+			}, cfg.RoleService, cfg.PermissionService),
+		}
+	}
+
+	readAuth = require(cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "packs", "read"))
+	writeAuth = require(cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "packs", "write"))
+	return readAuth, writeAuth
+}
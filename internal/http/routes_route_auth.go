@@ -0,0 +1,28 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+)
+
+// RouteAuthRoutes handles route group auth route registration.
+type RouteAuthRoutes struct {
+	handler *RouteAuthHandler
+}
+
+// NewRouteAuthRoutes creates a new RouteAuthRoutes instance.
+func NewRouteAuthRoutes(policy *middleware.RouteAuthPolicy) *RouteAuthRoutes {
+	return &RouteAuthRoutes{
+		handler: NewRouteAuthHandler(policy),
+	}
+}
+
+// RegisterAPIKeyRoutes registers the route group auth routes for API key
+// holders when JWT auth is disabled. They require a key even while auth is
+// otherwise off, so that routes can be locked down in an emergency.
+func (r *RouteAuthRoutes) RegisterAPIKeyRoutes(api *gin.RouterGroup, apiKeys *middleware.APIKeySet) {
+	routeAuth := api.Group("/admin/route-auth", apiKeys.Require())
+	routeAuth.GET("", r.handler.ListRouteAuth)
+	routeAuth.PUT("/:group", r.handler.SetRouteAuth)
+	routeAuth.DELETE("/:group", r.handler.ResetRouteAuth)
+}
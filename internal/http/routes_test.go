@@ -1,14 +1,19 @@
 package http
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/deprecation"
 	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/support"
+	"github.com/guttosm/pack-service/internal/workerpool"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func init() {
@@ -70,13 +75,15 @@ func TestAuthRoutes_RegisterProtectedRoutes(t *testing.T) {
 
 	routes.RegisterProtectedRoutes(api, cfg)
 
-	// Verify logout route is registered
-	req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	// Verify logout routes are registered
+	for _, path := range []string{"/api/auth/logout", "/api/auth/logout-all"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
 
-	// Should not return 404 - route exists (will fail auth but that's expected)
-	assert.NotEqual(t, http.StatusNotFound, w.Code)
+		// Should not return 404 - route exists (will fail auth but that's expected)
+		assert.NotEqual(t, http.StatusNotFound, w.Code, path)
+	}
 }
 
 func TestAuthRoutes_GetProtectedGroup(t *testing.T) {
@@ -183,6 +190,29 @@ func TestPackRoutes_RegisterPublicRoutes_WithoutPackSizesService(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w2.Code)
 }
 
+func TestPackRoutes_RegisterPublicRoutes_HeavyRequests(t *testing.T) {
+	pool := workerpool.New(workerpool.Config{Name: "test_heavy_routes", Workers: 1, QueueTimeout: 10 * time.Millisecond})
+	routes := NewPackRoutes(mocks.NewMockPackCalculator(t), nil, WithHeavyRequests(pool))
+
+	router := gin.New()
+	routes.RegisterPublicRoutes(router.Group("/api"))
+
+	post := func(path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w.Code
+	}
+
+	// Hold the only heavy request worker
+	release, err := pool.Acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, post("/api/calculate/batch"))
+	assert.NotEqual(t, http.StatusServiceUnavailable, post("/api/calculate"), "single calculations are not capped")
+
+	release()
+	assert.NotEqual(t, http.StatusServiceUnavailable, post("/api/calculate/batch"))
+}
+
 func TestPackRoutes_GetHandler(t *testing.T) {
 	mockCalc := mocks.NewMockPackCalculator(t)
 	routes := NewPackRoutes(mockCalc, nil)
@@ -228,3 +258,218 @@ func TestPackRoutes_GetPermissionIDs(t *testing.T) {
 	assert.Equal(t, "", readID)
 	assert.Equal(t, "", writeID)
 }
+
+// Tests for AdminRoutes
+
+func TestAdminRoutes_RegisterProtectedRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupCfg   func(t *testing.T) *RouterConfig
+		registered bool
+	}{
+		{
+			name: "not registered without permission service",
+			setupCfg: func(t *testing.T) *RouterConfig {
+				return &RouterConfig{}
+			},
+			registered: false,
+		},
+		{
+			name: "not registered without system:read permission",
+			setupCfg: func(t *testing.T) *RouterConfig {
+				mockPermService := mocks.NewMockPermissionService(t)
+				mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "system", "read").Return("").Once()
+				return &RouterConfig{
+					PermissionService: mockPermService,
+					RoleService:       mocks.NewMockRoleService(t),
+				}
+			},
+			registered: false,
+		},
+		{
+			name: "registered behind authorization",
+			setupCfg: func(t *testing.T) *RouterConfig {
+				mockPermService := mocks.NewMockPermissionService(t)
+				mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "system", "read").Return("perm-system-read").Once()
+				return &RouterConfig{
+					PermissionService: mockPermService,
+					RoleService:       mocks.NewMockRoleService(t),
+				}
+			},
+			registered: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.setupCfg(t)
+			cfg.SupportBundle = support.NewGenerator(support.Sources{})
+			routes := NewAdminRoutes(cfg)
+
+			router := gin.New()
+			api := router.Group("/api")
+			routes.RegisterProtectedRoutes(api, cfg)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/support-bundle", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if tt.registered {
+				// No authenticated user in context, so authorization rejects the request
+				assert.Equal(t, http.StatusUnauthorized, w.Code)
+			} else {
+				assert.Equal(t, http.StatusNotFound, w.Code)
+			}
+		})
+	}
+}
+
+func TestAdminRoutes_RegistersOnlyConfiguredHandlers(t *testing.T) {
+	mockPermService := mocks.NewMockPermissionService(t)
+	mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "system", "read").Return("perm-system-read").Once()
+	cfg := &RouterConfig{
+		PermissionService: mockPermService,
+		RoleService:       mocks.NewMockRoleService(t),
+		Deprecations:      deprecation.NewTracker(),
+	}
+
+	router := gin.New()
+	NewAdminRoutes(cfg).RegisterProtectedRoutes(router.Group("/api"), cfg)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/deprecations", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/support-bundle", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// Tests for PresetRoutes
+
+func TestPresetRoutes_RegisterProtectedRoutes(t *testing.T) {
+	mockPermService := mocks.NewMockPermissionService(t)
+	mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "packs", "read").Return("perm-read").Once()
+	mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "packs", "write").Return("perm-write").Once()
+	cfg := &RouterConfig{
+		PermissionService: mockPermService,
+		RoleService:       mocks.NewMockRoleService(t),
+	}
+
+	router := gin.New()
+	NewPresetRoutes(mocks.NewMockPresetService(t)).RegisterProtectedRoutes(router.Group("/api"), cfg)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/presets"},
+		{http.MethodPost, "/api/presets"},
+		{http.MethodGet, "/api/presets/warehouse-a"},
+		{http.MethodPut, "/api/presets/warehouse-a"},
+		{http.MethodDelete, "/api/presets/warehouse-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Authorization runs before the handler, so the service is never called
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+// Tests for LogRoutes
+
+func TestLogRoutes_RegisterProtectedRoutes(t *testing.T) {
+	t.Run("requires logs:read", func(t *testing.T) {
+		mockPermService := mocks.NewMockPermissionService(t)
+		mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "logs", "read").Return("perm-logs-read").Once()
+		cfg := &RouterConfig{
+			PermissionService: mockPermService,
+			RoleService:       mocks.NewMockRoleService(t),
+		}
+
+		router := gin.New()
+		NewLogRoutes(mocks.NewMockLoggingService(t), nil, nil).RegisterProtectedRoutes(router.Group("/api"), cfg)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/logs", nil))
+		// Authorization runs before the handler, so the service is never called
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/logs/export", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("fails closed without the permission", func(t *testing.T) {
+		mockPermService := mocks.NewMockPermissionService(t)
+		mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "logs", "read").Return("").Once()
+		cfg := &RouterConfig{
+			PermissionService: mockPermService,
+			RoleService:       mocks.NewMockRoleService(t),
+		}
+
+		router := gin.New()
+		NewLogRoutes(mocks.NewMockLoggingService(t), nil, nil).RegisterProtectedRoutes(router.Group("/api"), cfg)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/logs", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// Tests for UserRoutes
+
+func TestUserRoutes_RegisterProtectedRoutes(t *testing.T) {
+	mockPermService := mocks.NewMockPermissionService(t)
+	mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "users", "read").Return("perm-users-read").Once()
+	mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "users", "write").Return("perm-users-write").Once()
+	mockPermService.On("GetPermissionIDByResourceAndAction", mock.Anything, "users", "delete").Return("").Once()
+	cfg := &RouterConfig{
+		PermissionService: mockPermService,
+		RoleService:       mocks.NewMockRoleService(t),
+	}
+
+	router := gin.New()
+	NewUserRoutes(mocks.NewMockUserService(t), nil).RegisterProtectedRoutes(router.Group("/api"), cfg)
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{http.MethodGet, "/api/admin/users", http.StatusUnauthorized},
+		{http.MethodGet, "/api/admin/users/stale", http.StatusUnauthorized},
+		{http.MethodGet, "/api/admin/users/507f1f77bcf86cd799439012", http.StatusUnauthorized},
+		{http.MethodPatch, "/api/admin/users/507f1f77bcf86cd799439012", http.StatusUnauthorized},
+		{http.MethodPost, "/api/admin/users/507f1f77bcf86cd799439012/reactivate", http.StatusUnauthorized},
+		{http.MethodPost, "/api/admin/users/507f1f77bcf86cd799439012/restore", http.StatusUnauthorized},
+		// users:delete is not configured, so deactivation fails closed
+		{http.MethodPost, "/api/admin/users/507f1f77bcf86cd799439012/deactivate", http.StatusNotFound},
+		{http.MethodDelete, "/api/admin/users/507f1f77bcf86cd799439012", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestUserRoutes_NotRegisteredWithoutPermissionService(t *testing.T) {
+	router := gin.New()
+	NewUserRoutes(mocks.NewMockUserService(t), nil).RegisterProtectedRoutes(router.Group("/api"), &RouterConfig{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/users", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// UserRoutes handles admin user management route registration.
+type UserRoutes struct {
+	handler *UserHandler
+}
+
+// NewUserRoutes creates a new UserRoutes instance.
+func NewUserRoutes(userService service.UserService, cursors *pagination.Codec) *UserRoutes {
+	return &UserRoutes{
+		handler: NewUserHandler(userService, cursors),
+	}
+}
+
+// RegisterSelfRoutes registers the /me routes, through which any
+// authenticated user manages their own account.
+func (r *UserRoutes) RegisterSelfRoutes(protected *gin.RouterGroup) {
+	protected.GET("/me", r.handler.GetMe)
+	protected.PATCH("/me", r.handler.UpdateMe)
+}
+
+// RegisterProtectedRoutes registers the /admin/users routes (when auth is
+// enabled), each guarded by its users:read, users:write or users:delete
+// permission. Like the other admin routes they fail closed: a route whose
+// permission cannot be resolved is not registered.
+func (r *UserRoutes) RegisterProtectedRoutes(protected *gin.RouterGroup, cfg *RouterConfig) {
+	if cfg.PermissionService == nil || cfg.RoleService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require := func(action string) (gin.HandlerFunc, bool) {
+		permID := cfg.PermissionService.GetPermissionIDByResourceAndAction(ctx, "users", action)
+		if permID == "" {
+			return nil, false
+		}
+		return middleware.RequireAuthorization(middleware.AuthorizationConfig{
+			RequiredPermissions: []string{permID},
+		}, cfg.RoleService, cfg.PermissionService), true
+	}
+
+	users := protected.Group("/admin/users")
+	cached, invalidate := cfg.ResponseCache.Cached(responseCacheUsers), cfg.ResponseCache.Invalidates(responseCacheUsers)
+	if readAuth, ok := require("read"); ok {
+		users.GET("", readAuth, cached, r.handler.ListUsers)
+		users.GET("/stale", readAuth, cached, r.handler.ListStaleUsers)
+		users.GET("/:id", readAuth, r.handler.GetUser)
+		users.GET("/:id/effective-permissions", readAuth, r.handler.GetEffectivePermissions)
+	}
+	if writeAuth, ok := require("write"); ok {
+		users.POST("/import", writeAuth, invalidate, r.handler.ImportUsers)
+		users.PATCH("/:id", writeAuth, invalidate, r.handler.UpdateUser)
+		users.POST("/:id/reactivate", writeAuth, invalidate, r.handler.ReactivateUser)
+		users.POST("/:id/restore", writeAuth, invalidate, r.handler.RestoreUser)
+		users.POST("/:id/revoke-sessions", writeAuth, r.handler.RevokeUserSessions)
+	}
+	if deleteAuth, ok := require("delete"); ok {
+		users.POST("/:id/deactivate", deleteAuth, invalidate, r.handler.DeactivateUser)
+		users.POST("/:id/disable", deleteAuth, invalidate, r.handler.DisableUser)
+		users.DELETE("/:id", deleteAuth, invalidate, r.handler.PurgeUser)
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// SigningKeyHandler lists, adds and retires the keys JWTs are signed with.
+type SigningKeyHandler struct {
+	keys *service.SigningKeyRing
+}
+
+// NewSigningKeyHandler creates a new SigningKeyHandler instance.
+func NewSigningKeyHandler(keys *service.SigningKeyRing) *SigningKeyHandler {
+	return &SigningKeyHandler{keys: keys}
+}
+
+// ListSigningKeys handles GET /api/admin/signing-keys requests.
+//
+// @Summary      List JWT signing keys
+// @Description  Lists the keys JWTs are signed with, retired ones included, newest first. signing marks the key new tokens are signed with; tokens without kid are signed with JWT_SECRET_KEY. Secrets are never returned.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=[]model.SigningKey} "Signing keys"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/signing-keys [get]
+func (h *SigningKeyHandler) ListSigningKeys(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	keys, err := h.keys.List(c.Request.Context())
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+	builder.SuccessOK(keys)
+}
+
+// AddSigningKey handles POST /api/admin/signing-keys requests.
+//
+// @Summary      Add a JWT signing key
+// @Description  Generates a key with random secrets. Once every instance has loaded it, after AUTH_SIGNING_KEY_REFRESH_INTERVAL, new tokens are signed with it; tokens signed with the previous keys stay valid until those are retired.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      201 {object} dto.SuccessResponse{data=model.SigningKey} "Added key"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/signing-keys [post]
+func (h *SigningKeyHandler) AddSigningKey(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	key, err := h.keys.Add(c.Request.Context(), userIDFromContext(c))
+	if err != nil {
+		builder.Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	h.audit(c, "add_signing_key", "JWT signing key added", key)
+	builder.SuccessCreated(key)
+}
+
+// RetireSigningKey handles POST /api/admin/signing-keys/:kid/retire requests.
+//
+// @Summary      Retire a JWT signing key
+// @Description  Stops accepting the tokens the key signed, on this instance at once and on the others within AUTH_SIGNING_KEY_REFRESH_INTERVAL. If it was signing, the newest remaining key, or JWT_SECRET_KEY, signs instead. Retire a key once the tokens it signed have expired, or to sign everyone out after a leak.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        kid path string true "Key ID"
+// @Success      200 {object} dto.SuccessResponse{data=model.SigningKey} "Retired key"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      404 {object} dto.ErrorResponse "No active key has this ID"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/signing-keys/{kid}/retire [post]
+func (h *SigningKeyHandler) RetireSigningKey(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	key, err := h.keys.Retire(c.Request.Context(), c.Param("kid"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "retire_signing_key", "JWT signing key retired", key)
+	builder.SuccessOK(key)
+}
+
+func (h *SigningKeyHandler) audit(c *gin.Context, action, message string, key *model.SigningKey) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, action, message, map[string]interface{}{
+				"kid": key.KID,
+			})
+		}
+	}
+}
@@ -0,0 +1,100 @@
+//go:build !integration
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func newSigningKeyRouter(repo *mocks.MockSigningKeyRepositoryInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewSigningKeyHandler(service.NewSigningKeyRing(repo, 0))
+	router := gin.New()
+	router.GET("/api/admin/signing-keys", handler.ListSigningKeys)
+	router.POST("/api/admin/signing-keys", handler.AddSigningKey)
+	router.POST("/api/admin/signing-keys/:kid/retire", handler.RetireSigningKey)
+	return router
+}
+
+func TestSigningKeyHandler_ListSigningKeys(t *testing.T) {
+	repo := mocks.NewMockSigningKeyRepositoryInterface(t)
+	repo.EXPECT().List(mock.Anything).Return([]*model.SigningKey{
+		{KID: "k2", Secret: []byte("secret"), CreatedAt: time.Now()},
+	}, nil).Once()
+	repo.EXPECT().List(mock.Anything).Return(nil, errors.New("db down")).Once()
+	router := newSigningKeyRouter(repo)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/signing-keys", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret", "secrets are never returned")
+	var resp struct {
+		Data []model.SigningKey `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "k2", resp.Data[0].KID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/signing-keys", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestSigningKeyHandler_AddSigningKey(t *testing.T) {
+	repo := mocks.NewMockSigningKeyRepositoryInterface(t)
+	var created *model.SigningKey
+	repo.EXPECT().Create(mock.Anything, mock.Anything).Run(func(_ context.Context, key *model.SigningKey) {
+		created = key
+	}).Return(nil).Once()
+	repo.EXPECT().List(mock.Anything).RunAndReturn(func(context.Context) ([]*model.SigningKey, error) {
+		return []*model.SigningKey{created}, nil
+	}).Once()
+	router := newSigningKeyRouter(repo)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/signing-keys", nil))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp struct {
+		Data model.SigningKey `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, created.KID, resp.Data.KID)
+	assert.NotContains(t, w.Body.String(), "secret")
+}
+
+func TestSigningKeyHandler_RetireSigningKey(t *testing.T) {
+	retiredAt := time.Now()
+	repo := mocks.NewMockSigningKeyRepositoryInterface(t)
+	repo.EXPECT().Retire(mock.Anything, "k1", mock.Anything).Return(true, nil).Once()
+	repo.EXPECT().Retire(mock.Anything, "missing", mock.Anything).Return(false, nil).Once()
+	repo.EXPECT().List(mock.Anything).Return([]*model.SigningKey{{KID: "k1", RetiredAt: &retiredAt}}, nil)
+	router := newSigningKeyRouter(repo)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/signing-keys/k1/retire", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data model.SigningKey `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotNil(t, resp.Data.RetiredAt)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/signing-keys/missing/retire", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
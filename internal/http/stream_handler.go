@@ -0,0 +1,117 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// MIMEEventStream is the content type of server-sent event streams.
+const MIMEEventStream = "text/event-stream"
+
+// SubmitCalculationStream handles POST /api/calculate/stream requests.
+//
+// @Summary      Submit a bulk calculation for streaming
+// @Description  Registers up to 10000 orders as a stream job and returns its ID. Open stream_url with GET before expires_at to run the job and receive its results as server-sent events. A job can be streamed once, by the caller that submitted it, from the same instance.
+// @Tags         Packs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        request body dto.BatchCalculateRequest true "Orders"
+// @Success      202 {object} dto.SuccessResponse{data=dto.StreamJobResponse} "Job submitted"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid body or too many items"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - insufficient permissions"
+// @Failure      429 {object} dto.ErrorResponse "Too many requests - rate limit exceeded"
+// @Failure      503 {object} dto.ErrorResponse "Too many jobs waiting to be streamed"
+// @Security     BearerAuth
+// @Router       /api/calculate/stream [post]
+func (h *Handler) SubmitCalculationStream(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.BatchCalculateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+	if len(req.Items) > dto.MaxBatchItems {
+		builder.ErrorWithMessage(http.StatusBadRequest,
+			fmt.Sprintf(i18n.Message(c, i18n.ErrKeyValidationBatchItems), dto.MaxBatchItems), nil)
+		return
+	}
+
+	job, err := h.streamJobs.Submit(userIDFromContext(c), req.Items)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	builder.SuccessAccepted(dto.StreamJobResponse{
+		JobID:     job.ID,
+		Items:     len(job.Items),
+		StreamURL: c.Request.URL.Path + "?job=" + url.QueryEscape(job.ID),
+		ExpiresAt: job.ExpiresAt,
+	})
+}
+
+// StreamCalculation handles GET /api/calculate/stream requests.
+//
+// @Summary      Stream a submitted bulk calculation
+// @Description  Runs a job submitted with POST /api/calculate/stream and streams it as server-sent events, in request order as each item completes: a "result" event per item (a BatchItemResult), "progress" events about every percent and a final "done" event (both a JobProgress). Closing the connection stops the job.
+// @Tags         Packs
+// @Produce      text/event-stream
+// @Param        Authorization header string false "Bearer token (required if auth enabled)"
+// @Param        job query string true "Job ID returned on submission"
+// @Success      200 {object} dto.JobProgress "Event stream"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - insufficient permissions"
+// @Failure      404 {object} dto.ErrorResponse "Job not found, expired or already streamed"
+// @Security     BearerAuth
+// @Router       /api/calculate/stream [get]
+func (h *Handler) StreamCalculation(c *gin.Context) {
+	job, err := h.streamJobs.Claim(c.Query("job"), userIDFromContext(c))
+	if err != nil {
+		NewResponseBuilder(c).ErrorFrom(err)
+		return
+	}
+
+	batch := h.newBatchCalculator(c, job.Items)
+	progress := batch.streamEvents(c, h.streamJobs, job)
+	h.auditBatch(c, progress.Total, progress.Succeeded, progress.Failed, batch.configVersion, true)
+}
+
+// streamEvents runs job, writing each event as it is emitted. It stops
+// early when the client goes away.
+func (b *batchCalculator) streamEvents(c *gin.Context, runner *service.StreamJobRunner, job *service.StreamJob) dto.JobProgress {
+	w := c.Writer
+	w.Header().Set("Content-Type", MIMEEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	ctx := b.ctx
+
+	return runner.Run(job,
+		func(emit func(dto.BatchItemResult) bool) {
+			b.run(job.Items, emit)
+		},
+		func(event service.StreamEvent) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			c.SSEvent(event.Name, event.Data)
+			if c.IsAborted() {
+				return false
+			}
+			w.Flush()
+			return true
+		})
+}
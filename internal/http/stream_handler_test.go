@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStreamRouter(maxJobs int) *gin.Engine {
+	cfg := DefaultRouterConfig()
+	cfg.StreamJobs = service.NewStreamJobRunner(time.Minute, maxJobs)
+	handler := NewHandler(service.NewPackCalculatorService(), nil)
+	return NewRouter(handler, NewHealthHandler(), cfg)
+}
+
+func submitStream(t *testing.T, router *gin.Engine, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate/stream", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func openStream(router *gin.Engine, streamURL string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, streamURL, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// sseEvent is one parsed server-sent event.
+type sseEvent struct {
+	name string
+	data string
+}
+
+func parseEvents(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			current.name = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			current.data = strings.TrimPrefix(line, "data:")
+		case line == "" && current.name != "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestCalculationStream(t *testing.T) {
+	router := setupStreamRouter(10)
+
+	w := submitStream(t, router, mixedBatch)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var submitted struct {
+		Data dto.StreamJobResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &submitted))
+	job := submitted.Data
+	assert.NotEmpty(t, job.JobID)
+	assert.Equal(t, 4, job.Items)
+	assert.Equal(t, "/api/calculate/stream?job="+job.JobID, job.StreamURL)
+
+	w = openStream(router, job.StreamURL)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), MIMEEventStream)
+
+	events := parseEvents(t, w.Body.String())
+	require.NotEmpty(t, events)
+	assert.Equal(t, service.StreamEventProgress, events[0].name)
+
+	var results []dto.BatchItemResult
+	for _, event := range events {
+		if event.name != service.StreamEventResult {
+			continue
+		}
+		var result dto.BatchItemResult
+		require.NoError(t, json.Unmarshal([]byte(event.data), &result))
+		results = append(results, result)
+	}
+	require.Len(t, results, 4)
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+	}
+	require.NotNil(t, results[0].Result)
+	assert.Equal(t, 500, results[0].Result.TotalItems)
+	assert.NotNil(t, results[1].Error)
+
+	last := events[len(events)-1]
+	require.Equal(t, service.StreamEventDone, last.name)
+	var progress dto.JobProgress
+	require.NoError(t, json.Unmarshal([]byte(last.data), &progress))
+	assert.Equal(t, dto.JobProgress{Completed: 4, Total: 4, Succeeded: 2, Failed: 2}, progress)
+
+	t.Run("a job streams once", func(t *testing.T) {
+		w := openStream(router, job.StreamURL)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), dto.ErrCodeNotFound)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		w := openStream(router, "/api/calculate/stream?job=missing")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSubmitCalculationStream_Errors(t *testing.T) {
+	t.Run("invalid body", func(t *testing.T) {
+		w := submitStream(t, setupStreamRouter(10), `{"items": "many"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("too many waiting jobs", func(t *testing.T) {
+		router := setupStreamRouter(1)
+		require.Equal(t, http.StatusAccepted, submitStream(t, router, mixedBatch).Code)
+
+		w := submitStream(t, router, mixedBatch)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("not registered without a runner", func(t *testing.T) {
+		w := submitStream(t, setupRouter(), mixedBatch)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
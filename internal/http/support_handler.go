@@ -0,0 +1,59 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/support"
+)
+
+// SupportHandler provides HTTP handlers for diagnostic routes.
+type SupportHandler struct {
+	generator *support.Generator
+}
+
+// NewSupportHandler creates a new SupportHandler instance.
+func NewSupportHandler(generator *support.Generator) *SupportHandler {
+	return &SupportHandler{generator: generator}
+}
+
+// DownloadBundle handles GET /api/admin/support-bundle requests.
+//
+// @Summary      Download support bundle
+// @Description  Returns a zip archive with redacted configuration, recent error logs, circuit breaker states, cache metrics, version info and runtime profiles
+// @Tags         Admin
+// @Produce      application/zip
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {file} file "Support bundle archive"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/support-bundle [get]
+func (h *SupportHandler) DownloadBundle(c *gin.Context) {
+	// Buffer the archive so a failure can still be reported as a JSON error
+	var buf bytes.Buffer
+	if err := h.generator.Write(c.Request.Context(), &buf); err != nil {
+		NewResponseBuilder(c).Error(http.StatusInternalServerError, dto.ErrCodeInternal, err)
+		return
+	}
+
+	filename := h.generator.Filename()
+
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, "support_bundle", "Support bundle downloaded", map[string]interface{}{
+				"filename":   filename,
+				"size_bytes": buf.Len(),
+			})
+		}
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
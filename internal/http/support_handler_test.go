@@ -0,0 +1,51 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/support"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportHandler_DownloadBundle(t *testing.T) {
+	mockLogging := mocks.NewMockLoggingService(t)
+	mockLogging.On("QueryLogs", mock.Anything, mock.Anything).Return([]model.LogEntry{}, nil).Once()
+	mockLogging.On("CreateLog", mock.Anything, mock.MatchedBy(func(e *model.LogEntry) bool {
+		return e.ActionType == "support_bundle"
+	})).Return(nil).Maybe()
+
+	handler := NewSupportHandler(support.NewGenerator(support.Sources{LoggingService: mockLogging}))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("logging_service", mockLogging)
+		c.Next()
+	})
+	router.GET("/api/admin/support-bundle", handler.DownloadBundle)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/support-bundle", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="support-bundle-\d{8}-\d{6}\.zip"$`, w.Header().Get("Content-Disposition"))
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "manifest.json")
+	assert.Contains(t, names, "errors.json")
+}
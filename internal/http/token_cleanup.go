@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// TokenCleanupHandler lets admins delete expired tokens without waiting for
+// the scheduled cleanup.
+type TokenCleanupHandler struct {
+	job *service.TokenCleanupJob
+}
+
+// NewTokenCleanupHandler creates a new TokenCleanupHandler instance.
+func NewTokenCleanupHandler(job *service.TokenCleanupJob) *TokenCleanupHandler {
+	return &TokenCleanupHandler{job: job}
+}
+
+// Cleanup handles POST /api/admin/tokens/cleanup requests.
+//
+// @Summary      Delete expired tokens
+// @Description  Deletes the expired refresh and blacklist tokens now, as the scheduled cleanup does every AUTH_TOKEN_CLEANUP_INTERVAL.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=dto.TokenCleanupResult} "Expired tokens deleted"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:write permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/tokens/cleanup [post]
+func (h *TokenCleanupHandler) Cleanup(c *gin.Context) {
+	deleted, err := h.job.Run(c.Request.Context())
+	if err != nil {
+		NewResponseBuilder(c).Error(http.StatusInternalServerError, i18n.ErrKeyInternalError, err)
+		return
+	}
+
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			middleware.AuditLog(ls, c, "cleanup_tokens", "Expired tokens deleted", map[string]interface{}{"deleted": deleted})
+		}
+	}
+	NewResponseBuilder(c).SuccessOK(dto.TokenCleanupResult{Deleted: deleted})
+}
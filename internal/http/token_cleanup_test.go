@@ -0,0 +1,58 @@
+//go:build !integration
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestTokenCleanupHandler_Cleanup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		deleted        int64
+		err            error
+		expectedStatus int
+	}{
+		{name: "deletes expired tokens", deleted: 3, expectedStatus: http.StatusOK},
+		{name: "repository error", err: errors.New("db down"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockTokenRepositoryInterface(t)
+			repo.EXPECT().CleanupExpired(mock.Anything).Return(tt.deleted, tt.err).Once()
+
+			router := gin.New()
+			router.POST("/api/admin/tokens/cleanup", NewTokenCleanupHandler(service.NewTokenCleanupJob(repo, time.Hour)).Cleanup)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/tokens/cleanup", nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.err != nil {
+				return
+			}
+			var resp struct {
+				Data dto.TokenCleanupResult `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.deleted, resp.Data.Deleted)
+		})
+	}
+}
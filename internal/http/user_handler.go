@@ -0,0 +1,513 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/pagination"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// UserHandler provides HTTP handlers for the admin user management routes.
+type UserHandler struct {
+	userService service.UserService
+	cursors     *pagination.Codec
+}
+
+// NewUserHandler creates a new UserHandler instance. Pages link to the next
+// one with cursors encoded by cursors, when set.
+func NewUserHandler(userService service.UserService, cursors *pagination.Codec) *UserHandler {
+	return &UserHandler{userService: userService, cursors: cursors}
+}
+
+// ListUsers handles GET /api/admin/users requests.
+//
+// @Summary      List users
+// @Description  Returns a page of users, newest first. Requires the users:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        limit query int false "Page size (default 50, max 200)"
+// @Param        offset query int false "Number of users to skip"
+// @Param        cursor query string false "next_cursor of the previous page, instead of offset"
+// @Param        active query bool false "Only active (true) or deactivated (false) users"
+// @Param        role query string false "Role ID or name"
+// @Param        q query string false "Case-insensitive search in email, username and name"
+// @Success      200 {object} dto.SuccessResponse "Users page"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid query parameter, or invalid_cursor to restart from the first page"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+	offset, err := pageOffset(c, h.cursors, "users")
+	if err != nil {
+		writeQueryError(builder, err)
+		return
+	}
+
+	filter := dto.UserFilter{
+		Role:   c.Query("role"),
+		Search: c.Query("q"),
+	}
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+			return
+		}
+		filter.Active = &active
+	}
+
+	page, err := h.userService.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+	page.NextCursor = nextCursor(c, h.cursors, "users", page.Offset, len(page.Users), page.Total)
+
+	builder.SuccessOK(page)
+}
+
+// ListStaleUsers handles GET /api/admin/users/stale requests.
+//
+// @Summary      List stale users
+// @Description  Returns a page of active users without a login in the last days days, for access reviews. Users who never logged in count from their creation. Requires the users:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        days query int false "Days without a login (default AUTH_STALE_ACCOUNT_DAYS)"
+// @Param        limit query int false "Page size (default 50, max 200)"
+// @Param        offset query int false "Number of users to skip"
+// @Param        cursor query string false "next_cursor of the previous page, instead of offset"
+// @Success      200 {object} dto.SuccessResponse{data=dto.StaleUserPage} "Stale users page"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - invalid query parameter, or invalid_cursor to restart from the first page"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:read permission"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/stale [get]
+func (h *UserHandler) ListStaleUsers(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	days, err := queryInt(c, "days")
+	if err == nil && days < 0 {
+		err = errors.New("days must not be negative")
+	}
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequest, err)
+		return
+	}
+	offset, err := pageOffset(c, h.cursors, "users/stale")
+	if err != nil {
+		writeQueryError(builder, err)
+		return
+	}
+
+	page, err := h.userService.ListStale(c.Request.Context(), days, limit, offset)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+	page.NextCursor = nextCursor(c, h.cursors, "users/stale", page.Offset, len(page.Users), page.Total)
+
+	builder.SuccessOK(page)
+}
+
+// GetUser handles GET /api/admin/users/:id requests.
+//
+// @Summary      Get user
+// @Description  Returns a user by ID. Requires the users:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      200 {object} dto.SuccessResponse "User"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:read permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	user, err := h.userService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	builder.SuccessOK(user)
+}
+
+// GetEffectivePermissions handles GET /api/admin/users/:id/effective-permissions requests.
+//
+// @Summary      Get a user's effective permissions
+// @Description  Returns the user's roles and every permission they grant, each with the roles granting it, to find out why a user can or cannot do something. Every assigned role grants its permissions, including deactivated roles. Roles and permissions referenced but since deleted are listed apart, as they grant nothing. Access tokens carry the roles of when they were issued, so role changes reach the user at their next token refresh. Requires the users:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      200 {object} dto.SuccessResponse{data=dto.EffectivePermissions} "Effective permissions"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:read permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/effective-permissions [get]
+func (h *UserHandler) GetEffectivePermissions(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	permissions, err := h.userService.EffectivePermissions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	builder.SuccessOK(permissions)
+}
+
+// UpdateUser handles PATCH /api/admin/users/:id requests.
+//
+// @Summary      Update user
+// @Description  Changes a user's email, username, name or roles. Omitted fields are left unchanged. Role changes apply when the user's access token is next refreshed. Requires the users:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Param        request body dto.UpdateUserRequest true "User changes"
+// @Success      200 {object} dto.SuccessResponse "Updated user"
+// @Failure      400 {object} dto.ErrorResponse "Bad request"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:write permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      409 {object} dto.ErrorResponse "Email or username already taken"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id} [patch]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var req dto.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+
+	user, err := h.userService.Update(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	fields := map[string]interface{}{}
+	if req.Email != nil {
+		fields["email"] = user.Email
+	}
+	if req.Username != nil {
+		fields["username"] = user.Username
+	}
+	if req.Name != nil {
+		fields["name"] = user.Name
+	}
+	if req.Roles != nil {
+		fields["roles"] = user.Roles
+	}
+	h.audit(c, "update_user", "User updated", user, fields)
+	builder.SuccessOK(user)
+}
+
+// DeactivateUser handles POST /api/admin/users/:id/deactivate requests.
+//
+// @Summary      Deactivate user
+// @Description  Disables a user's account and revokes their refresh tokens. Issued access tokens remain valid until they expire. Admins cannot deactivate themselves. Requires the users:delete permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      200 {object} dto.SuccessResponse "Deactivated user"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:delete permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      409 {object} dto.ErrorResponse "Cannot deactivate your own account"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	user, err := h.userService.Deactivate(c.Request.Context(), c.Param("id"), userIDFromContext(c))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "deactivate_user", "User deactivated", user, nil)
+	builder.SuccessOK(user)
+}
+
+// DisableUser handles POST /api/admin/users/:id/disable requests.
+//
+// @Summary      Disable user
+// @Description  Deactivates a user's account and revokes every access and refresh token issued to them, for responding to a compromised account: issued access tokens are rejected from the next request on. Unlike deactivate, the request fails when the tokens cannot be revoked, and can be retried, also for an account already deactivated. Admins cannot disable themselves. Requires the users:delete permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      200 {object} dto.SuccessResponse "Disabled user"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:delete permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      409 {object} dto.ErrorResponse "Cannot disable your own account"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error, the tokens may not be revoked"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/disable [post]
+func (h *UserHandler) DisableUser(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	user, err := h.userService.Disable(c.Request.Context(), c.Param("id"), userIDFromContext(c))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "disable_user", "User disabled and sessions revoked", user, nil)
+	builder.SuccessOK(user)
+}
+
+// RevokeUserSessions handles POST /api/admin/users/:id/revoke-sessions requests.
+//
+// @Summary      Revoke a user's sessions
+// @Description  Revokes every access and refresh token issued to a user, who stays active and can log in again. Issued access tokens are rejected from the next request on. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      200 {object} dto.SuccessResponse "User whose sessions were revoked"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:write permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/revoke-sessions [post]
+func (h *UserHandler) RevokeUserSessions(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	user, err := h.userService.RevokeSessions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "revoke_user_sessions", "User sessions revoked", user, nil)
+	builder.SuccessOK(user)
+}
+
+// ReactivateUser handles POST /api/admin/users/:id/reactivate requests.
+//
+// @Summary      Reactivate user
+// @Description  Re-enables a deactivated user's account. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      200 {object} dto.SuccessResponse "Reactivated user"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:write permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	user, err := h.userService.Reactivate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "reactivate_user", "User reactivated", user, nil)
+	builder.SuccessOK(user)
+}
+
+// RestoreUser handles POST /api/admin/users/:id/restore requests.
+//
+// @Summary      Restore user
+// @Description  Undoes the soft delete of a deactivated user, who can log in again. Purged users cannot be restored. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      200 {object} dto.SuccessResponse "Restored user"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:write permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	user, err := h.userService.Restore(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "restore_user", "User restored", user, nil)
+	builder.SuccessOK(user)
+}
+
+// PurgeUser handles DELETE /api/admin/users/:id requests.
+//
+// @Summary      Purge user
+// @Description  Permanently deletes a deactivated user and their tokens, once AUTH_DELETED_USER_RETENTION has passed since the user was deactivated. Active users and users within the retention cannot be purged. Requires the users:delete permission.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        id path string true "User ID"
+// @Success      204 "User purged"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:delete permission"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      409 {object} dto.ErrorResponse "User still active or within the retention"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/{id} [delete]
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	user, err := h.userService.HardDelete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	h.audit(c, "purge_user", "User purged", user, nil)
+	c.Status(http.StatusNoContent)
+}
+
+// GetMe handles GET /api/me requests.
+//
+// @Summary      Get own profile
+// @Description  Returns the account of the authenticated user.
+// @Tags         Auth
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse "User"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	userID := userIDFromContext(c)
+	if userID == "" {
+		builder.Error(http.StatusUnauthorized, i18n.ErrKeyUnauthorized, nil)
+		return
+	}
+	user, err := h.userService.Get(c.Request.Context(), userID)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	builder.SuccessOK(user)
+}
+
+// UpdateMe handles PATCH /api/me requests.
+//
+// @Summary      Update own profile
+// @Description  Changes the name, username or password of the authenticated user. Omitted fields are left unchanged. Changing the password requires current_password and revokes every token of the user, including the one used for this request, so the user signs in again with the new password.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        request body dto.UpdateProfileRequest true "Profile changes"
+// @Success      200 {object} dto.SuccessResponse "Updated user"
+// @Failure      400 {object} dto.ErrorResponse "Bad request"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Current password is incorrect"
+// @Failure      404 {object} dto.ErrorResponse "User not found"
+// @Failure      409 {object} dto.ErrorResponse "Username already taken"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/me [patch]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	userID := userIDFromContext(c)
+	if userID == "" {
+		builder.Error(http.StatusUnauthorized, i18n.ErrKeyUnauthorized, nil)
+		return
+	}
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	fields := map[string]interface{}{}
+	if req.Username != nil {
+		fields["username"] = user.Username
+	}
+	if req.Name != nil {
+		fields["name"] = user.Name
+	}
+	if req.NewPassword != nil {
+		fields["password_changed"] = true
+	}
+	h.audit(c, "update_profile", "User updated their profile", user, fields)
+	builder.SuccessOK(user)
+}
+
+func (h *UserHandler) audit(c *gin.Context, action, message string, user *model.User, fields map[string]interface{}) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			if fields == nil {
+				fields = map[string]interface{}{}
+			}
+			fields["target_user_id"] = user.ID.Hex()
+			middleware.AuditLog(ls, c, action, message, fields)
+		}
+	}
+}
+
+// queryInt parses an optional integer query parameter, returning 0 when it is absent.
+func queryInt(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	return parseInt(value)
+}
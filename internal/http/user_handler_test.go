@@ -0,0 +1,430 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	testAdminID  = "507f1f77bcf86cd799439011"
+	testTargetID = "507f1f77bcf86cd799439012"
+)
+
+// setupUserRouter registers the user handlers without authorization, with the
+// admin's ID set in context as the JWT middleware would.
+func setupUserRouter(mockUsers *mocks.MockUserService) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		id, _ := primitive.ObjectIDFromHex(testAdminID)
+		c.Set("user_id", id)
+		c.Next()
+	})
+	handler := NewUserHandler(mockUsers, nil)
+	users := router.Group("/api/admin/users")
+	users.GET("", handler.ListUsers)
+	users.GET("/stale", handler.ListStaleUsers)
+	users.GET("/:id", handler.GetUser)
+	users.GET("/:id/effective-permissions", handler.GetEffectivePermissions)
+	users.POST("/import", handler.ImportUsers)
+	users.PATCH("/:id", handler.UpdateUser)
+	users.POST("/:id/deactivate", handler.DeactivateUser)
+	users.POST("/:id/reactivate", handler.ReactivateUser)
+	users.POST("/:id/disable", handler.DisableUser)
+	users.POST("/:id/revoke-sessions", handler.RevokeUserSessions)
+	users.POST("/:id/restore", handler.RestoreUser)
+	users.DELETE("/:id", handler.PurgeUser)
+	router.GET("/api/me", handler.GetMe)
+	router.PATCH("/api/me", handler.UpdateMe)
+	return router
+}
+
+func testUser() *model.User {
+	id, _ := primitive.ObjectIDFromHex(testTargetID)
+	return &model.User{ID: id, Email: "user@example.com", Username: "user", Password: "hash", Active: true}
+}
+
+func TestUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		setupMock      func(*mocks.MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:   "list users with filters",
+			method: http.MethodGet,
+			path:   "/api/admin/users?limit=10&offset=20&active=false&role=admin&q=example",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().List(mock.Anything, mock.MatchedBy(func(f dto.UserFilter) bool {
+					return f.Active != nil && !*f.Active && f.Role == "admin" && f.Search == "example"
+				}), 10, 20).Return(&dto.UserPage{Users: []*model.User{testUser()}, Total: 21, Limit: 10, Offset: 20}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "list users with invalid limit",
+			method:         http.MethodGet,
+			path:           "/api/admin/users?limit=ten",
+			setupMock:      func(*mocks.MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "list users with invalid active filter",
+			method:         http.MethodGet,
+			path:           "/api/admin/users?active=maybe",
+			setupMock:      func(*mocks.MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "list stale users",
+			method: http.MethodGet,
+			path:   "/api/admin/users/stale?days=30&limit=10",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().ListStale(mock.Anything, 30, 10, 0).Return(&dto.StaleUserPage{
+					UserPage:     dto.UserPage{Users: []*model.User{testUser()}, Total: 1, Limit: 10},
+					InactiveDays: 30,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "list stale users with negative days",
+			method:         http.MethodGet,
+			path:           "/api/admin/users/stale?days=-1",
+			setupMock:      func(*mocks.MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "get user",
+			method: http.MethodGet,
+			path:   "/api/admin/users/" + testTargetID,
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Get(mock.Anything, testTargetID).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get missing user",
+			method: http.MethodGet,
+			path:   "/api/admin/users/missing",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Get(mock.Anything, "missing").Return(nil, service.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "get effective permissions",
+			method: http.MethodGet,
+			path:   "/api/admin/users/" + testTargetID + "/effective-permissions",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().EffectivePermissions(mock.Anything, testTargetID).Return(&dto.EffectivePermissions{
+					UserID:      testTargetID,
+					Roles:       []dto.EffectiveRole{{Name: "user", Active: true}},
+					Permissions: []dto.EffectivePermission{{Resource: "packs", Action: "read", GrantedBy: []string{"user"}}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get effective permissions of a missing user",
+			method: http.MethodGet,
+			path:   "/api/admin/users/missing/effective-permissions",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().EffectivePermissions(mock.Anything, "missing").Return(nil, service.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "update user",
+			method: http.MethodPatch,
+			path:   "/api/admin/users/" + testTargetID,
+			body:   `{"name": "New Name", "roles": ["admin"]}`,
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Update(mock.Anything, testTargetID, mock.MatchedBy(func(req *dto.UpdateUserRequest) bool {
+					return req.Name != nil && *req.Name == "New Name" && req.Roles != nil && len(*req.Roles) == 1 && req.Email == nil
+				})).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "update user with invalid body",
+			method:         http.MethodPatch,
+			path:           "/api/admin/users/" + testTargetID,
+			body:           `{`,
+			setupMock:      func(*mocks.MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "update user with invalid role",
+			method: http.MethodPatch,
+			path:   "/api/admin/users/" + testTargetID,
+			body:   `{"roles": ["nope"]}`,
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Update(mock.Anything, testTargetID, mock.Anything).Return(nil, fmt.Errorf("%w: unknown role", service.ErrInvalidUser))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "update user with taken email",
+			method: http.MethodPatch,
+			path:   "/api/admin/users/" + testTargetID,
+			body:   `{"email": "taken@example.com"}`,
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Update(mock.Anything, testTargetID, mock.Anything).Return(nil, service.ErrUserExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "deactivate user",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/deactivate",
+			setupMock: func(m *mocks.MockUserService) {
+				user := testUser()
+				user.Active = false
+				m.EXPECT().Deactivate(mock.Anything, testTargetID, testAdminID).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "deactivate self",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testAdminID + "/deactivate",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Deactivate(mock.Anything, testAdminID, testAdminID).Return(nil, service.ErrSelfDeactivation)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "reactivate user",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/reactivate",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Reactivate(mock.Anything, testTargetID).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "disable user",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/disable",
+			setupMock: func(m *mocks.MockUserService) {
+				user := testUser()
+				user.Active = false
+				m.EXPECT().Disable(mock.Anything, testTargetID, testAdminID).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "disable user whose sessions cannot be revoked",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/disable",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Disable(mock.Anything, testTargetID, testAdminID).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "revoke user sessions",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/revoke-sessions",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().RevokeSessions(mock.Anything, testTargetID).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "revoke sessions of a missing user",
+			method: http.MethodPost,
+			path:   "/api/admin/users/missing/revoke-sessions",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().RevokeSessions(mock.Anything, "missing").Return(nil, service.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "restore user",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/restore",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Restore(mock.Anything, testTargetID).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "restore a purged user",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/restore",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Restore(mock.Anything, testTargetID).Return(nil, service.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "purge user",
+			method: http.MethodDelete,
+			path:   "/api/admin/users/" + testTargetID,
+			setupMock: func(m *mocks.MockUserService) {
+				user := testUser()
+				user.Active = false
+				m.EXPECT().HardDelete(mock.Anything, testTargetID).Return(user, nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "purge user within the retention",
+			method: http.MethodDelete,
+			path:   "/api/admin/users/" + testTargetID,
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().HardDelete(mock.Anything, testTargetID).Return(nil, fmt.Errorf("%w: deactivated users are kept until later", service.ErrUserNotPurgeable))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "get own profile",
+			method: http.MethodGet,
+			path:   "/api/me",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Get(mock.Anything, testAdminID).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "update own profile",
+			method: http.MethodPatch,
+			path:   "/api/me",
+			body:   `{"name": "New Name", "current_password": "old-password", "new_password": "new-password"}`,
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().UpdateProfile(mock.Anything, testAdminID, mock.MatchedBy(func(req *dto.UpdateProfileRequest) bool {
+					return *req.Name == "New Name" && req.CurrentPassword == "old-password" && *req.NewPassword == "new-password" && req.Username == nil
+				})).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "update own password with a wrong current password",
+			method: http.MethodPatch,
+			path:   "/api/me",
+			body:   `{"current_password": "guess", "new_password": "new-password"}`,
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().UpdateProfile(mock.Anything, testAdminID, mock.Anything).Return(nil, service.ErrIncorrectPassword)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "reactivate fails",
+			method: http.MethodPost,
+			path:   "/api/admin/users/" + testTargetID + "/reactivate",
+			setupMock: func(m *mocks.MockUserService) {
+				m.EXPECT().Reactivate(mock.Anything, testTargetID).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsers := mocks.NewMockUserService(t)
+			tt.setupMock(mockUsers)
+			router := setupUserRouter(mockUsers)
+
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.NotContains(t, w.Body.String(), "hash", "password hashes must never be returned")
+		})
+	}
+}
+
+func TestUserHandler_MeRequiresAuthentication(t *testing.T) {
+	router := gin.New()
+	handler := NewUserHandler(mocks.NewMockUserService(t), nil)
+	router.GET("/api/me", handler.GetMe)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/me", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUserHandler_ImportUsers(t *testing.T) {
+	send := func(router *gin.Engine, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/users/import", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	imported := &dto.ImportUsersResult{Imported: 1, Users: []dto.ImportedUser{{Row: 1, ID: testTargetID, Email: "user@example.com"}}}
+
+	t.Run("imports JSON", func(t *testing.T) {
+		mockUsers := mocks.NewMockUserService(t)
+		mockUsers.EXPECT().Import(mock.Anything, []dto.ImportUser{{Email: "user@example.com", Roles: []string{"admin"}, Invite: true}}).Return(imported, nil)
+
+		w := send(setupUserRouter(mockUsers), "application/json", `{"users":[{"email":"user@example.com","roles":["admin"],"invite":true}]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"imported":1`)
+	})
+
+	t.Run("imports CSV", func(t *testing.T) {
+		mockUsers := mocks.NewMockUserService(t)
+		mockUsers.EXPECT().Import(mock.Anything, []dto.ImportUser{
+			{Email: "ana@example.com", Name: "Ana Lima", Roles: []string{"user", "admin"}, Password: " pass word"},
+			{Email: "bo@example.com", Invite: true},
+		}).Return(imported, nil)
+
+		w := send(setupUserRouter(mockUsers), "text/csv; charset=utf-8",
+			"Email,name,roles,password,invite\nana@example.com,Ana Lima,user; admin, pass word,\nbo@example.com,,,,true\n")
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("rejects malformed CSV", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"empty":          "",
+			"unknown column": "email,phone\na@example.com,123\n",
+			"no email":       "name\nAna\n",
+			"invalid invite": "email,invite\na@example.com,maybe\n",
+			"short row":      "email,name\na@example.com\n",
+		} {
+			w := send(setupUserRouter(mocks.NewMockUserService(t)), "text/csv", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+	})
+
+	t.Run("reports invalid users by row", func(t *testing.T) {
+		mockUsers := mocks.NewMockUserService(t)
+		mockUsers.EXPECT().Import(mock.Anything, mock.Anything).Return(nil, &service.UserImportError{Rows: map[int]string{2: "email already registered"}})
+
+		w := send(setupUserRouter(mockUsers), "application/json", `{"users":[{"email":"a@example.com"},{"email":"b@example.com"}]}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"row 2":"email already registered"`)
+	})
+
+	t.Run("rejects an empty import", func(t *testing.T) {
+		mockUsers := mocks.NewMockUserService(t)
+		mockUsers.EXPECT().Import(mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: no users to import", service.ErrInvalidUser))
+
+		w := send(setupUserRouter(mockUsers), "application/json", `{"users":[]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/middleware"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// userImportColumns are the CSV columns of a user import; only email is
+// required. Roles are separated by semicolons.
+var userImportColumns = []string{"email", "username", "name", "roles", "password", "invite"}
+
+// ImportUsers handles POST /api/admin/users/import requests.
+//
+// @Summary      Import users
+// @Description  Creates users in bulk, all or none, for migrations from another auth system. The body is JSON, or CSV with Content-Type text/csv: a header row naming any of the columns email (required), username, name, roles (IDs or names separated by semicolons), password and invite, then one user per row. Users get the "user" role unless roles are given, and need a password of at least 6 characters unless invite is true; invited users get a temporary password, returned once in the response. If any user is invalid, none is created and the response details the reason for each invalid user by its position, counting from 1 without the CSV header. At most 5000 users per request. Requires the users:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Accept       text/csv
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Param        request body dto.ImportUsersRequest true "Users to create"
+// @Success      201 {object} dto.SuccessResponse{data=dto.ImportUsersResult} "Users created"
+// @Failure      400 {object} dto.ErrorResponse "Bad request - malformed body, no users or too many"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing users:write permission"
+// @Failure      422 {object} dto.ErrorResponse "Invalid users, detailed by row; none was created"
+// @Failure      500 {object} dto.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Router       /api/admin/users/import [post]
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	builder := NewResponseBuilder(c)
+
+	var users []dto.ImportUser
+	if c.ContentType() == MIMECSV {
+		var err error
+		if users, err = parseUserImportCSV(c.Request.Body); err != nil {
+			builder.ErrorWithMessage(http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	} else {
+		var req dto.ImportUsersRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			builder.Error(http.StatusBadRequest, i18n.ErrKeyInvalidRequestBody, err)
+			return
+		}
+		users = req.Users
+	}
+
+	result, err := h.userService.Import(c.Request.Context(), users)
+	var importErr *service.UserImportError
+	if errors.As(err, &importErr) {
+		details := make(map[string]string, len(importErr.Rows))
+		for row, reason := range importErr.Rows {
+			details["row "+strconv.Itoa(row)] = reason
+		}
+		builder.ErrorWithDetails(http.StatusUnprocessableEntity, "No user was imported: "+importErr.Error(), details, err)
+		return
+	}
+	if err != nil {
+		builder.ErrorFrom(err)
+		return
+	}
+
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			ids := make([]string, len(result.Users))
+			for i, user := range result.Users {
+				ids[i] = user.ID
+			}
+			middleware.AuditLog(ls, c, "import_users", "Users imported", map[string]interface{}{
+				"imported":        result.Imported,
+				"target_user_ids": ids,
+			})
+		}
+	}
+	builder.SuccessCreated(result)
+}
+
+// parseUserImportCSV reads the users of a CSV import.
+func parseUserImportCSV(r io.Reader) ([]dto.ImportUser, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("CSV header row missing: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(userImportColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q, expected %s", name, strings.Join(userImportColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, errors.New("CSV header has no email column")
+	}
+
+	var users []dto.ImportUser
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return users, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == service.MaxUserImportSize {
+			return nil, fmt.Errorf("at most %d users can be imported at once", service.MaxUserImportSize)
+		}
+
+		raw := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		field := func(name string) string { return strings.TrimSpace(raw(name)) }
+		user := dto.ImportUser{
+			Email:    field("email"),
+			Username: field("username"),
+			Name:     field("name"),
+			Password: raw("password"),
+		}
+		for _, role := range strings.Split(field("roles"), ";") {
+			if role = strings.TrimSpace(role); role != "" {
+				user.Roles = append(user.Roles, role)
+			}
+		}
+		if invite := field("invite"); invite != "" {
+			if user.Invite, err = strconv.ParseBool(invite); err != nil {
+				return nil, fmt.Errorf("row %d: invite must be true or false", len(users)+1)
+			}
+		}
+		users = append(users, user)
+	}
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/notify"
+)
+
+// WebhookHealthHandler serves the health of the monitored webhooks.
+type WebhookHealthHandler struct {
+	monitor *notify.WebhookMonitor
+}
+
+// NewWebhookHealthHandler creates a new WebhookHealthHandler instance.
+func NewWebhookHealthHandler(monitor *notify.WebhookMonitor) *WebhookHealthHandler {
+	return &WebhookHealthHandler{monitor: monitor}
+}
+
+// GetHealth handles GET /api/admin/webhooks requests.
+//
+// @Summary      Webhook health
+// @Description  Lists the monitored webhooks (currently the alert webhook) with their probe status: healthy, failing, paused or unknown before the first probe, consecutive failures, last check and success, and until when delivery is paused. URLs only show scheme and host.
+// @Tags         Admin
+// @Produce      json
+// @Param        Authorization header string true "Bearer token"
+// @Success      200 {object} dto.SuccessResponse{data=[]notify.WebhookHealth} "Webhook health"
+// @Failure      401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure      403 {object} dto.ErrorResponse "Forbidden - missing system:read permission"
+// @Security     BearerAuth
+// @Router       /api/admin/webhooks [get]
+func (h *WebhookHealthHandler) GetHealth(c *gin.Context) {
+	NewResponseBuilder(c).SuccessOK(h.monitor.Health())
+}
@@ -0,0 +1,47 @@
+//go:build !integration
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/notify"
+)
+
+func TestWebhookHealthHandler_GetHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer webhook.Close()
+
+	monitor := notify.NewWebhookMonitor(notify.DefaultWebhookMonitorConfig())
+	monitor.Watch("alerts", webhook.URL+"/T000/B000/secret")
+	monitor.ProbeDue(context.Background())
+
+	router := gin.New()
+	router.GET("/admin/webhooks", NewWebhookHealthHandler(monitor).GetHealth)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/webhooks", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+	var resp struct {
+		Data []notify.WebhookHealth `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "alerts", resp.Data[0].Name)
+	assert.Equal(t, notify.WebhookFailing, resp.Data[0].Status)
+	assert.Equal(t, 1, resp.Data[0].ConsecutiveFailures)
+	assert.Equal(t, "probe returned status 503", resp.Data[0].LastError)
+}
@@ -0,0 +1,310 @@
+// Package httpclient sends the HTTP requests the service makes to other
+// systems, such as alert webhooks and CDN purges, so each feature calling out
+// does not bring its own client.
+//
+// Clients share one pooled transport. Each destination host gets a circuit
+// breaker, and transient failures are retried with jittered exponential
+// backoff within a retry budget, so a struggling destination sees a bounded
+// share of extra requests instead of every request multiplied by the retries.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// Defaults for zero Config fields.
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultMaxRetries   = 2
+	defaultBaseBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff   = 5 * time.Second
+	defaultRetryRatio   = 0.1
+	defaultRetryReserve = 10
+)
+
+// errServerError marks a 5xx response as a failure for the circuit breaker.
+var errServerError = errors.New("server error")
+
+// sharedTransport pools the connections of every Client.
+var sharedTransport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 10
+	return t
+}()
+
+// Config configures a Client.
+type Config struct {
+	// Name labels the client's metrics and names its circuit breakers.
+	Name string
+	// Timeout bounds each attempt, reading the response included. Zero
+	// uses 10 seconds.
+	Timeout time.Duration
+	// MaxRetries is how many times a request is retried after a transient
+	// failure. Zero uses 2; a negative value disables retries.
+	MaxRetries int
+	// BaseBackoff is the wait before the first retry, doubled before each
+	// next one and jittered. Zero uses 100ms.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait before a retry. A response asking, through
+	// Retry-After, to wait longer is returned instead. Zero uses 5 seconds.
+	MaxBackoff time.Duration
+	// RetryRatio is the share of requests that may be retried once the
+	// reserve is spent: every request adds RetryRatio to the retry budget and
+	// every retry takes one from it. Zero uses 0.1.
+	RetryRatio float64
+	// RetryReserve is the retry budget the client starts with, and the most
+	// it saves up. Zero uses 10.
+	RetryReserve float64
+	// RetryNonIdempotent also retries POST and PATCH requests, for
+	// destinations where a duplicate is harmless. Requests with an
+	// Idempotency-Key header are retried regardless.
+	RetryNonIdempotent bool
+	// Breaker configures the circuit breaker of each destination host; its
+	// Name is set by the client. A zero FailureThreshold uses
+	// circuitbreaker.DefaultConfig.
+	Breaker circuitbreaker.Config
+	// Registry lists the circuit breakers for operators, named
+	// "<Name>_<host>". Nil still exports their state as the
+	// circuit_breaker_state gauge.
+	Registry *circuitbreaker.Registry
+}
+
+// Client sends outbound HTTP requests. It is safe for concurrent use.
+type Client struct {
+	cfg    Config
+	client *http.Client
+	budget *retryBudget
+
+	mu       sync.Mutex
+	breakers map[string]*circuitbreaker.CircuitBreaker
+}
+
+// New creates a Client. Zero config fields take their default.
+func New(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.RetryRatio <= 0 {
+		cfg.RetryRatio = defaultRetryRatio
+	}
+	if cfg.RetryReserve <= 0 {
+		cfg.RetryReserve = defaultRetryReserve
+	}
+	if cfg.Breaker.FailureThreshold <= 0 {
+		cfg.Breaker = circuitbreaker.DefaultConfig()
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = circuitbreaker.NewRegistry()
+	}
+	return &Client{
+		cfg:      cfg,
+		client:   &http.Client{Transport: sharedTransport, Timeout: cfg.Timeout},
+		budget:   &retryBudget{tokens: cfg.RetryReserve, ratio: cfg.RetryRatio, reserve: cfg.RetryReserve},
+		breakers: make(map[string]*circuitbreaker.CircuitBreaker),
+	}
+}
+
+// Do sends req like http.Client.Do, and the caller closes the response body
+// likewise. Connection errors and 429, 502, 503 and 504 responses are
+// retried when req can be sent again: its method is idempotent, or allowed
+// by the config, and its body, if any, can be rewound. The last response or
+// error is returned once the retries or the budget run out. Requests to a
+// host whose circuit breaker is open fail without being sent, with an error
+// wrapping circuitbreaker.ErrCircuitOpen.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	breaker := c.breaker(host)
+	replayable := c.replayable(req)
+	c.budget.deposit()
+
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, breaker, host, attemptReq)
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+			return nil, fmt.Errorf("%s: %w", host, err)
+		}
+
+		wait, transient := c.retryDelay(attempt, resp, err)
+		if !transient || !replayable || attempt >= c.cfg.MaxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+		if !c.budget.withdraw() {
+			metrics.RecordOutboundHTTPRetry(c.cfg.Name, host, "budget_exhausted")
+			return resp, err
+		}
+		metrics.RecordOutboundHTTPRetry(c.cfg.Name, host, "retried")
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if attemptReq, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends req once through the host's circuit breaker, which counts
+// connection errors and 5xx responses as failures.
+func (c *Client) attempt(ctx context.Context, breaker *circuitbreaker.CircuitBreaker, host string, req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var doErr error
+	start := time.Now()
+	err := breaker.Execute(ctx, func() error {
+		resp, doErr = c.client.Do(req)
+		switch {
+		case doErr != nil && ctx.Err() != nil:
+			// The caller gave up; the host is not at fault
+			return nil
+		case doErr != nil:
+			return doErr
+		case resp.StatusCode >= http.StatusInternalServerError:
+			return errServerError
+		}
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, circuitbreaker.ErrCircuitOpen):
+		metrics.RecordOutboundHTTPRequest(c.cfg.Name, host, "circuit_open", 0)
+		return nil, err
+	case doErr != nil:
+		metrics.RecordOutboundHTTPRequest(c.cfg.Name, host, "error", time.Since(start))
+		return nil, doErr
+	}
+	metrics.RecordOutboundHTTPRequest(c.cfg.Name, host, strconv.Itoa(resp.StatusCode/100)+"xx", time.Since(start))
+	return resp, nil
+}
+
+// retryDelay reports whether the outcome of an attempt is transient and how
+// long to wait before the next one: what a Retry-After header asks, or a
+// jittered backoff. Asking for more than MaxBackoff is not transient.
+func (c *Client) retryDelay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, wait <= c.cfg.MaxBackoff
+		}
+	}
+
+	backoff := c.cfg.BaseBackoff << attempt
+	if backoff <= 0 || backoff > c.cfg.MaxBackoff {
+		backoff = c.cfg.MaxBackoff
+	}
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// replayable reports whether req may be sent more than once.
+func (c *Client) replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return c.cfg.RetryNonIdempotent || req.Header.Get("Idempotency-Key") != ""
+}
+
+// breaker returns the circuit breaker of host, creating it on first use.
+func (c *Client) breaker(host string) *circuitbreaker.CircuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cb, ok := c.breakers[host]; ok {
+		return cb
+	}
+	cfg := c.cfg.Breaker
+	cfg.Name = c.cfg.Name + "_" + host
+	cb := circuitbreaker.New(cfg)
+	c.breakers[host] = cb
+	c.cfg.Registry.Register(cfg.Name, cb)
+	return cb
+}
+
+// rewind returns a copy of req with a fresh body, to send it again.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next := req.Clone(req.Context())
+	next.Body = body
+	return next, nil
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as a date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// retryBudget allows retries in proportion to requests: each request earns
+// a fraction of a retry and each retry spends a whole one, from a reserve
+// that also caps what is saved up while all goes well.
+type retryBudget struct {
+	mu      sync.Mutex
+	tokens  float64
+	ratio   float64
+	reserve float64
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	b.tokens = min(b.tokens+b.ratio, b.reserve)
+	b.mu.Unlock()
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
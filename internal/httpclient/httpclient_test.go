@@ -0,0 +1,188 @@
+//go:build !integration
+
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+)
+
+// newServer answers each request with the next of statuses, repeating the
+// last one, and counts the requests.
+func newServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func testConfig() Config {
+	return Config{Name: "test", BaseBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+}
+
+func send(t *testing.T, client *Client, method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, url, strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestClient_Do_Retries(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		statuses   []int
+		cfg        func(*Config)
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "transient failure then success", method: http.MethodPut, statuses: []int{503, 502, 200}, wantStatus: 200, wantCalls: 3},
+		{name: "gives up after the retries", method: http.MethodGet, statuses: []int{504}, wantStatus: 504, wantCalls: 3},
+		{name: "client errors are not retried", method: http.MethodGet, statuses: []int{400}, wantStatus: 400, wantCalls: 1},
+		{name: "internal server errors are not retried", method: http.MethodGet, statuses: []int{500}, wantStatus: 500, wantCalls: 1},
+		{name: "POST is not retried by default", method: http.MethodPost, statuses: []int{503, 200}, wantStatus: 503, wantCalls: 1},
+		{
+			name: "POST is retried when allowed", method: http.MethodPost, statuses: []int{503, 200},
+			cfg:        func(cfg *Config) { cfg.RetryNonIdempotent = true },
+			wantStatus: 200, wantCalls: 2,
+		},
+		{
+			name: "retries disabled", method: http.MethodGet, statuses: []int{503, 200},
+			cfg:        func(cfg *Config) { cfg.MaxRetries = -1 },
+			wantStatus: 503, wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newServer(t, tt.statuses...)
+			cfg := testConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+
+			resp, err := send(t, New(cfg), tt.method, server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, calls.Load())
+		})
+	}
+}
+
+func TestClient_Do_ResendsBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	req.Header.Set("Idempotency-Key", "abc")
+	resp, err := New(testConfig()).Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload"}, bodies)
+}
+
+func TestClient_Do_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", r.URL.Query().Get("after"))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := New(Config{Name: "test", MaxRetries: 1, MaxBackoff: 2 * time.Second})
+
+	start := time.Now()
+	resp, err := send(t, client, http.MethodGet, server.URL+"?after=1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "waits as asked")
+
+	calls.Store(0)
+	_, err = send(t, client, http.MethodGet, server.URL+"?after=60")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "waiting longer than MaxBackoff is not worth it")
+}
+
+func TestClient_Do_RetryBudget(t *testing.T) {
+	server, calls := newServer(t, http.StatusServiceUnavailable)
+	cfg := testConfig()
+	cfg.MaxRetries = 5
+	cfg.RetryReserve = 3
+	cfg.RetryRatio = 0.01
+	client := New(cfg)
+
+	_, err := send(t, client, http.MethodGet, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load(), "the reserve allows 3 retries")
+
+	calls.Store(0)
+	_, err = send(t, client, http.MethodGet, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "the spent budget allows no retry")
+}
+
+func TestClient_Do_CircuitBreaker(t *testing.T) {
+	server, calls := newServer(t, http.StatusInternalServerError)
+	registry := circuitbreaker.NewRegistry()
+	cfg := testConfig()
+	cfg.Breaker = circuitbreaker.Config{FailureThreshold: 2, SuccessThreshold: 1, Timeout: time.Minute}
+	cfg.Registry = registry
+	client := New(cfg)
+
+	for range 2 {
+		_, err := send(t, client, http.MethodGet, server.URL)
+		require.NoError(t, err)
+	}
+	_, err := send(t, client, http.MethodGet, server.URL)
+	assert.ErrorIs(t, err, circuitbreaker.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	cb, ok := registry.Get("test_" + strings.TrimPrefix(server.URL, "http://"))
+	require.True(t, ok)
+	assert.True(t, cb.IsOpen())
+}
+
+func TestClient_Do_ContextCanceled(t *testing.T) {
+	server, calls := newServer(t, http.StatusServiceUnavailable)
+	cfg := testConfig()
+	cfg.BaseBackoff = time.Minute
+	cfg.MaxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = New(cfg).Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+}
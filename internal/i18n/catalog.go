@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// locales holds the catalogs the service ships, one file per locale.
+//
+//go:embed locales
+var locales embed.FS
+
+// ErrInvalidCatalog is returned for a catalog that cannot be registered.
+var ErrInvalidCatalog = errors.New("invalid message catalog")
+
+// localePattern matches the locales catalogs may register: a language,
+// optionally followed by subtags such as a region, as in pt or pt-br.
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// embeddedCatalogs returns the catalogs the service ships.
+func embeddedCatalogs() fs.FS {
+	catalogs, _ := fs.Sub(locales, "locales")
+	return catalogs
+}
+
+// RegisterLocale adds the messages, by key, of locale, which is made a
+// supported locale. Messages of an already supported locale replace the ones
+// it had for the same keys and keep the others. Keys missing in a regional
+// locale, such as pt-br, fall back to its base locale, then to DefaultLocale.
+//
+// Messages of keys DefaultLocale holds must fill in the same placeholders as
+// its messages, since callers format them with the same arguments.
+// Locales are meant to be registered at startup, before serving requests.
+func (t *Translator) RegisterLocale(locale string, messages map[string]string) error {
+	locale = strings.ToLower(locale)
+	if !localePattern.MatchString(locale) {
+		return fmt.Errorf("%w: unsupported locale name %q", ErrInvalidCatalog, locale)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := t.catalog()
+	if locale != DefaultLocale {
+		for key, msg := range messages {
+			original, ok := current[DefaultLocale][key]
+			if !ok {
+				continue
+			}
+			if want, got := placeholders(original), placeholders(msg); !slices.Equal(want, got) {
+				return fmt.Errorf("%w: %s: %q has placeholders %v, want %v", ErrInvalidCatalog, locale, key, got, want)
+			}
+		}
+	}
+
+	updated := maps.Clone(current)
+	localeMessages := maps.Clone(current[locale])
+	if localeMessages == nil {
+		localeMessages = make(map[string]string, len(messages))
+	}
+	maps.Copy(localeMessages, messages)
+	updated[locale] = localeMessages
+	t.messages.Store(&updated)
+	return nil
+}
+
+// RegisterLocale adds messages to the default translator; see
+// Translator.RegisterLocale.
+func RegisterLocale(locale string, messages map[string]string) error {
+	return GetTranslator().RegisterLocale(locale, messages)
+}
+
+// LoadCatalogs registers the catalogs in the root of fsys: JSON or YAML
+// objects mapping keys to messages, in files named after their locale, such
+// as fr.json or pt-br.yaml. Other files are ignored.
+func (t *Translator) LoadCatalogs(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := path.Ext(name)
+		if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if ext == ".json" {
+			err = json.Unmarshal(data, &messages)
+		} else {
+			err = yaml.Unmarshal(data, &messages)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidCatalog, name, err)
+		}
+		if err := t.RegisterLocale(strings.TrimSuffix(name, ext), messages); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
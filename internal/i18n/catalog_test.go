@@ -0,0 +1,92 @@
+//go:build !integration
+
+package i18n
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedCatalogs(t *testing.T) {
+	translator := NewTranslator()
+	messages := translator.catalog()
+	assert.Equal(t, []string{"en", "nl", "pt"}, translator.Locales())
+
+	for _, key := range []string{ErrKeyTimeout, ErrKeyValidationEmail, ErrKeyValidationBatchItems, SuccessKeyLoggedOut} {
+		assert.Contains(t, messages[DefaultLocale], key)
+	}
+	for locale, localeMessages := range messages {
+		for key, msg := range messages[DefaultLocale] {
+			translated, ok := localeMessages[key]
+			if assert.True(t, ok, "%s lacks %s", locale, key) {
+				assert.Equal(t, placeholders(msg), placeholders(translated), "%s: %s", locale, key)
+			}
+		}
+		assert.Len(t, localeMessages, len(messages[DefaultLocale]), "%s has keys %s lacks", locale, DefaultLocale)
+	}
+}
+
+func TestTranslator_RegisterLocale(t *testing.T) {
+	translator := NewTranslator()
+
+	require.NoError(t, translator.RegisterLocale("pt-BR", map[string]string{ErrKeyNotFound: "Não achado"}))
+	assert.Equal(t, "Não achado", translator.Translate(ErrKeyNotFound, "pt-br"))
+	assert.Equal(t, "Requisição inválida", translator.Translate(ErrKeyInvalidRequest, "pt-BR"), "missing keys come from the base locale")
+	assert.Equal(t, "pt-br", translator.Negotiate("pt-BR,pt;q=0.9"))
+	assert.Equal(t, "pt", translator.Negotiate("pt-PT"))
+
+	require.NoError(t, translator.RegisterLocale("nl", map[string]string{ErrKeyNotFound: "Niet aanwezig"}))
+	assert.Equal(t, "Niet aanwezig", translator.Translate(ErrKeyNotFound, "nl"))
+	assert.Equal(t, "Ongeldig verzoek", translator.Translate(ErrKeyInvalidRequest, "nl"), "other messages are kept")
+
+	err := translator.RegisterLocale("de", map[string]string{ErrKeyValidationItemsOrderedMax: "items_ordered: höchstens %s"})
+	assert.ErrorIs(t, err, ErrInvalidCatalog, "placeholders must match")
+	assert.ErrorIs(t, translator.RegisterLocale("../de", nil), ErrInvalidCatalog)
+	assert.NotContains(t, translator.Locales(), "de")
+
+	assert.NotContains(t, NewTranslator().Locales(), "pt-br", "translators do not share catalogs")
+}
+
+func TestTranslator_LoadCatalogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr bool
+	}{
+		{
+			name: "JSON and YAML catalogs",
+			files: fstest.MapFS{
+				"fr.json":   {Data: []byte(`{"error.not_found": "Introuvable"}`)},
+				"de.yaml":   {Data: []byte("error.not_found: Nicht gefunden\n")},
+				"README.md": {Data: []byte("ignored")},
+			},
+		},
+		{
+			name:    "malformed catalog",
+			files:   fstest.MapFS{"fr.json": {Data: []byte(`{"error.not_found": 1}`)}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := NewTranslator()
+			err := translator.LoadCatalogs(tt.files)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCatalog)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Introuvable", translator.Translate(ErrKeyNotFound, "fr"))
+			assert.Equal(t, "Nicht gefunden", translator.Translate(ErrKeyNotFound, "de"))
+			assert.Equal(t, "Invalid request", translator.Translate(ErrKeyInvalidRequest, "de"))
+		})
+	}
+
+	_, err := fs.ReadDir(embeddedCatalogs(), ".")
+	assert.NoError(t, err)
+}
@@ -3,8 +3,11 @@
 package i18n
 
 import (
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,16 +25,27 @@ var (
 	translatorOnce    sync.Once
 )
 
+// catalogs holds the messages of each locale, by locale and key.
+type catalogs map[string]map[string]string
+
 // Translator handles message translation for different locales.
 type Translator struct {
-	messages map[string]map[string]string
+	// mu serializes catalog registrations, which replace messages
+	mu       sync.Mutex
+	messages atomic.Pointer[catalogs]
+	// overrides holds the messages tenants replaced, see SetOverrides
+	overrides atomic.Pointer[Overrides]
 }
 
-// NewTranslator creates a new translator with the default messages.
+// NewTranslator creates a new translator with the embedded catalogs.
 func NewTranslator() *Translator {
-	return &Translator{
-		messages: getDefaultMessages(),
+	t := &Translator{}
+	t.messages.Store(&catalogs{})
+	// The embedded catalogs are checked by the tests
+	if err := t.LoadCatalogs(embeddedCatalogs()); err != nil {
+		panic("i18n: loading embedded catalogs: " + err.Error())
 	}
+	return t
 }
 
 // GetTranslator returns the default singleton translator instance.
@@ -42,121 +56,120 @@ func GetTranslator() *Translator {
 	return defaultTranslator
 }
 
+// catalog returns the messages of every locale. It must not be modified.
+func (t *Translator) catalog() catalogs {
+	return *t.messages.Load()
+}
+
 // Translate returns the translated message for the given key and locale.
-// Falls back to DefaultLocale if the locale is not found.
+// A message missing in a regional locale, such as pt-br, comes from its base
+// locale; one missing there, or in an unsupported locale, from DefaultLocale.
 func (t *Translator) Translate(key, locale string) string {
-	if locale == "" {
-		locale = DefaultLocale
+	messages := t.catalog()
+	for locale = t.resolveLocale(locale); locale != ""; locale = parentLocale(locale) {
+		if msg, ok := messages[locale][key]; ok {
+			return msg
+		}
 	}
-
-	localeMessages, ok := t.messages[locale]
-	if !ok {
-		localeMessages = t.messages[DefaultLocale]
+	if msg, ok := messages[DefaultLocale][key]; ok {
+		return msg
 	}
+	return key
+}
 
-	msg, ok := localeMessages[key]
-	if !ok {
-		// Fallback to default locale
-		if defaultMessages := t.messages[DefaultLocale]; defaultMessages != nil {
-			if fallbackMsg, exists := defaultMessages[key]; exists {
-				return fallbackMsg
-			}
+// resolveLocale returns locale, or its nearest base locale, when it has
+// messages, DefaultLocale otherwise.
+func (t *Translator) resolveLocale(locale string) string {
+	messages := t.catalog()
+	for locale = strings.ToLower(locale); locale != ""; locale = parentLocale(locale) {
+		if _, ok := messages[locale]; ok {
+			return locale
 		}
-		return key
 	}
+	return DefaultLocale
+}
 
-	return msg
+// parentLocale returns locale without its last subtag, such as pt for
+// pt-br, or "" for a locale without subtags.
+func parentLocale(locale string) string {
+	if idx := strings.LastIndex(locale, "-"); idx > 0 {
+		return locale[:idx]
+	}
+	return ""
 }
 
-// GetLocale extracts the locale from the gin context.
-// Checks Accept-Language header and falls back to DefaultLocale.
-func GetLocale(c *gin.Context) string {
-	acceptLang := c.GetHeader(AcceptLanguageHeader)
-	if acceptLang == "" {
-		return DefaultLocale
+// Locales returns the supported locales, sorted.
+func (t *Translator) Locales() []string {
+	messages := t.catalog()
+	locales := make([]string, 0, len(messages))
+	for locale := range messages {
+		locales = append(locales, locale)
 	}
+	slices.Sort(locales)
+	return locales
+}
 
-	// Parse Accept-Language header (e.g., "en-US,en;q=0.9,pt;q=0.8")
-	parts := strings.Split(acceptLang, ",")
-	if len(parts) > 0 {
-		lang := strings.TrimSpace(strings.Split(parts[0], ";")[0])
-		// Extract base language (e.g., "en" from "en-US")
-		if idx := strings.Index(lang, "-"); idx > 0 {
-			lang = lang[:idx]
+// languageRange is a language of an Accept-Language header and its weight.
+type languageRange struct {
+	tag    string
+	weight float64
+}
+
+// Negotiate returns the supported locale that best matches an
+// Accept-Language header (RFC 9110 section 12.5.4), such as
+// "pt-BR,pt;q=0.9,en;q=0.8". Languages are tried by decreasing weight, each
+// as given and then by its base languages; DefaultLocale is returned when
+// none is supported.
+func (t *Translator) Negotiate(acceptLanguage string) string {
+	var ranges []languageRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				continue
+			}
+			weight = parsed
 		}
-		// Normalize to lowercase
-		lang = strings.ToLower(lang)
-		// Validate it's a supported locale
-		if _, ok := getDefaultMessages()[lang]; ok {
-			return lang
+		if weight > 0 {
+			ranges = append(ranges, languageRange{tag: tag, weight: weight})
 		}
 	}
+	slices.SortStableFunc(ranges, func(a, b languageRange) int {
+		switch {
+		case a.weight > b.weight:
+			return -1
+		case a.weight < b.weight:
+			return 1
+		}
+		return 0
+	})
 
+	messages := t.catalog()
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return DefaultLocale
+		}
+		for locale := r.tag; locale != ""; locale = parentLocale(locale) {
+			if _, ok := messages[locale]; ok {
+				return locale
+			}
+		}
+	}
 	return DefaultLocale
 }
 
-// getDefaultMessages returns the default message translations.
-func getDefaultMessages() map[string]map[string]string {
-	return map[string]map[string]string{
-		"en": {
-			// Error messages
-			"error.invalid_request":        "Invalid request",
-			"error.invalid_request_body":    "Invalid request body",
-			"error.internal_error":          "An unexpected error occurred",
-			"error.unauthorized":            "Unauthorized",
-			"error.invalid_credentials":     "User Not registered",
-			"error.api_key_required":        "API key is required",
-			"error.invalid_api_key":         "Invalid API key",
-			"error.forbidden":               "Forbidden",
-			"error.not_found":               "Not found",
-			"error.rate_limit_exceeded":     "Too many requests, please try again later",
-			"error.conflict":                "Conflict",
-			"error.validation.items_ordered": "items_ordered: must be a positive integer",
-			"error.invalid_token":           "Invalid or expired token",
-			"error.token_required":           "Authentication token is required",
-
-			// Success messages
-			"success.pack_calculated": "Pack calculation completed successfully",
-		},
-		"pt": {
-			// Error messages
-			"error.invalid_request":        "Requisição inválida",
-			"error.invalid_request_body":    "Corpo da requisição inválido",
-			"error.internal_error":          "Ocorreu um erro inesperado",
-			"error.unauthorized":            "Não autorizado",
-			"error.invalid_credentials":     "Usuário não registrado",
-			"error.api_key_required":        "Chave de API é obrigatória",
-			"error.invalid_api_key":         "Chave de API inválida",
-			"error.forbidden":               "Proibido",
-			"error.not_found":               "Não encontrado",
-			"error.rate_limit_exceeded":     "Muitas requisições, tente novamente mais tarde",
-			"error.conflict":                "Conflito",
-			"error.validation.items_ordered": "items_ordered: deve ser um inteiro positivo",
-			"error.invalid_token":           "Token inválido ou expirado",
-			"error.token_required":           "Token de autenticação é obrigatório",
-
-			// Success messages
-			"success.pack_calculated": "Cálculo de pacotes concluído com sucesso",
-		},
-		"nl": {
-			// Error messages
-			"error.invalid_request":        "Ongeldig verzoek",
-			"error.invalid_request_body":    "Ongeldige aanvraag body",
-			"error.internal_error":          "Er is een onverwachte fout opgetreden",
-			"error.unauthorized":            "Niet geautoriseerd",
-			"error.invalid_credentials":     "Gebruiker niet geregistreerd",
-			"error.api_key_required":        "API-sleutel is vereist",
-			"error.invalid_api_key":         "Ongeldige API-sleutel",
-			"error.forbidden":               "Verboden",
-			"error.not_found":               "Niet gevonden",
-			"error.rate_limit_exceeded":     "Te veel verzoeken, probeer het later opnieuw",
-			"error.conflict":                "Conflict",
-			"error.validation.items_ordered": "items_ordered: moet een positief geheel getal zijn",
-			"error.invalid_token":           "Ongeldig of verlopen token",
-			"error.token_required":          "Authenticatietoken is vereist",
-
-			// Success messages
-			"success.pack_calculated": "Pakketberekening succesvol voltooid",
-		},
+// GetLocale returns the locale of the request in c, negotiated from its
+// Accept-Language header.
+func GetLocale(c *gin.Context) string {
+	acceptLang := c.GetHeader(AcceptLanguageHeader)
+	if acceptLang == "" {
+		return DefaultLocale
 	}
+	return GetTranslator().Negotiate(acceptLang)
 }
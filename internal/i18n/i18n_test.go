@@ -146,6 +146,31 @@ func TestGetLocale(t *testing.T) {
 			acceptLanguage: "EN",
 			expected:       "en",
 		},
+		{
+			name:           "unsupported language falls to the next by weight",
+			acceptLanguage: "fr-FR,fr;q=0.9,nl;q=0.7,pt;q=0.8",
+			expected:       "pt",
+		},
+		{
+			name:           "regional locale falls back to its language",
+			acceptLanguage: "pt-BR",
+			expected:       "pt",
+		},
+		{
+			name:           "zero weight excludes a language",
+			acceptLanguage: "pt;q=0,nl",
+			expected:       "nl",
+		},
+		{
+			name:           "wildcard picks the default",
+			acceptLanguage: "fr,*;q=0.5,nl;q=0.1",
+			expected:       DefaultLocale,
+		},
+		{
+			name:           "malformed weight is ignored",
+			acceptLanguage: "pt;q=high,nl;q=0.5",
+			expected:       "nl",
+		},
 	}
 
 	for _, tt := range tests {
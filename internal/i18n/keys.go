@@ -13,6 +13,8 @@ const (
 	ErrKeyUnauthorized = "error.unauthorized"
 	// ErrKeyInvalidCredentials indicates invalid login credentials (user not registered or wrong password).
 	ErrKeyInvalidCredentials = "error.invalid_credentials"
+	// ErrKeyAccountLocked indicates logins are locked out after too many failed attempts.
+	ErrKeyAccountLocked = "error.account_locked"
 	// ErrKeyAPIKeyRequired indicates that an API key is required.
 	ErrKeyAPIKeyRequired = "error.api_key_required"
 	// ErrKeyInvalidAPIKey indicates an invalid API key.
@@ -27,16 +29,105 @@ const (
 	ErrKeyConflict = "error.conflict"
 	// ErrKeyValidationItemsOrdered indicates invalid items_ordered validation.
 	ErrKeyValidationItemsOrdered = "error.validation.items_ordered"
+	// ErrKeyValidationItemsOrderedMax indicates a calculate request's items_ordered exceeds the maximum,
+	// which the message formats with %d.
+	ErrKeyValidationItemsOrderedMax = "error.validation.items_ordered_max"
+	// ErrKeyValidationPackSizes indicates a calculate request lists too many or too large pack sizes.
+	ErrKeyValidationPackSizes = "error.validation.pack_sizes"
+	// ErrKeyValidationPresetWithPackSizes indicates a calculate request set both preset and pack_sizes.
+	ErrKeyValidationPresetWithPackSizes = "error.validation.preset_with_pack_sizes"
+	// ErrKeyValidationMaxPacks indicates a calculate request set max_packs below 1.
+	ErrKeyValidationMaxPacks = "error.validation.max_packs"
+	// ErrKeyValidationMaxOverage indicates a calculate request set a negative overage limit.
+	ErrKeyValidationMaxOverage = "error.validation.max_overage"
+	// ErrKeyValidationMetadata indicates a calculate request's metadata exceeds its limits.
+	ErrKeyValidationMetadata = "error.validation.metadata"
+	// ErrKeyValidationMaxCompute indicates a calculate request set a non-positive compute time limit.
+	ErrKeyValidationMaxCompute = "error.validation.max_compute"
+	// ErrKeyValidationOptimize indicates a calculate request set an unknown optimization mode, or min_cost with max_packs.
+	ErrKeyValidationOptimize = "error.validation.optimize"
+	// ErrKeyValidationPackCosts indicates a calculate request's pack costs exceed their limits.
+	ErrKeyValidationPackCosts = "error.validation.pack_costs"
+	// ErrKeyValidationCosts indicates a pack sizes update has costs out of range or of sizes it does not list.
+	ErrKeyValidationCosts = "error.validation.costs"
+	// ErrKeyConstraintsUnsatisfiable indicates no pack combination satisfies the request's constraints.
+	ErrKeyConstraintsUnsatisfiable = "error.constraints_unsatisfiable"
+	// ErrKeyMissingPackCost indicates a calculation optimizing for cost has a pack size without a cost.
+	ErrKeyMissingPackCost = "error.missing_pack_cost"
+	// ErrKeyPresetNotFound indicates a referenced calculation preset does not exist.
+	ErrKeyPresetNotFound = "error.preset_not_found"
+	// ErrKeyStreamJobNotFound indicates a stream job is unknown, expired or already streamed.
+	ErrKeyStreamJobNotFound = "error.stream_job_not_found"
+	// ErrKeyJobNotFound indicates a calculation job is unknown or expired.
+	ErrKeyJobNotFound = "error.job_not_found"
+	// ErrKeyUserNotFound indicates a user does not exist.
+	ErrKeyUserNotFound = "error.user_not_found"
 	// ErrKeyInvalidToken indicates an invalid or expired JWT token.
 	ErrKeyInvalidToken = "error.invalid_token"
 	// ErrKeyTokenRequired indicates that a JWT token is required.
 	ErrKeyTokenRequired = "error.token_required"
 	// ErrKeyTimeout indicates a request timeout.
 	ErrKeyTimeout = "error.timeout"
+	// ErrKeyServiceBusy indicates the service is too busy to take the work now.
+	ErrKeyServiceBusy = "error.service_busy"
+	// ErrKeyDatabaseUnavailable indicates the request needs the database, which is down.
+	ErrKeyDatabaseUnavailable = "error.database_unavailable"
+	// ErrKeyPayloadTooLarge indicates the request body exceeds the size limit.
+	ErrKeyPayloadTooLarge = "error.payload_too_large"
+	// ErrKeyDraining indicates the instance is draining before shutdown.
+	ErrKeyDraining = "error.draining"
+	// ErrKeyGeoBlocked indicates the request comes from outside the allowed regions.
+	ErrKeyGeoBlocked = "error.geo_blocked"
+	// ErrKeyIPBlocked indicates the client IP is blocked by the IP rules.
+	ErrKeyIPBlocked = "error.ip_blocked"
+	// ErrKeyInvalidCursor indicates a page cursor that is invalid or expired.
+	ErrKeyInvalidCursor = "error.invalid_cursor"
+	// ErrKeyUnavailable indicates a feature the instance was started without.
+	ErrKeyUnavailable = "error.unavailable"
+	// ErrKeyNotAcceptable indicates the Accept header allows no format the endpoint serves.
+	ErrKeyNotAcceptable = "error.not_acceptable"
+	// ErrKeyUserExists indicates a registration with an email or username already taken.
+	ErrKeyUserExists = "error.user_exists"
+	// ErrKeyIncorrectPassword indicates a password change with the wrong current password.
+	ErrKeyIncorrectPassword = "error.incorrect_password"
+	// ErrKeyRefreshTokenRequired indicates that the X-Refresh-Token header is required.
+	ErrKeyRefreshTokenRequired = "error.refresh_token_required"
+	// ErrKeyInvalidAuthorizationHeader indicates an Authorization header that is not a Bearer or DPoP token.
+	ErrKeyInvalidAuthorizationHeader = "error.invalid_authorization_header"
+	// ErrKeyDPoPUnsupported indicates a DPoP proof sent to an instance that does not verify them.
+	ErrKeyDPoPUnsupported = "error.dpop_unsupported"
+	// ErrKeyInvalidDPoPProof indicates a DPoP proof that is invalid.
+	ErrKeyInvalidDPoPProof = "error.invalid_dpop_proof"
+	// ErrKeyNoActivePackSizes indicates a pack size migration without an active configuration to start from.
+	ErrKeyNoActivePackSizes = "error.no_active_pack_sizes"
+	// ErrKeyValidationPackSizesRequired indicates a pack size update without sizes.
+	ErrKeyValidationPackSizesRequired = "error.validation.pack_sizes_required"
+	// ErrKeyValidationSinceVersion indicates a since_version that is not a positive integer.
+	ErrKeyValidationSinceVersion = "error.validation.since_version"
+	// ErrKeyValidationBatchItems indicates a batch with more items than allowed,
+	// which the message formats with %d.
+	ErrKeyValidationBatchItems = "error.validation.batch_items"
+	// ErrKeyValidationEmail indicates a login or registration without a valid email.
+	ErrKeyValidationEmail = "error.validation.email"
+	// ErrKeyValidationUsername indicates a registration username that is too short or too long.
+	ErrKeyValidationUsername = "error.validation.username"
+	// ErrKeyValidationPassword indicates a login or registration password that is too short.
+	ErrKeyValidationPassword = "error.validation.password"
+	// ErrKeyValidationQuantity indicates a quantity that is not a positive
+	// decimal of the precision, which the message formats with %d.
+	ErrKeyValidationQuantity = "error.validation.quantity"
+	// ErrKeyValidationPackQuantities indicates decimal pack sizes that are
+	// missing, too many or invalid, which the message formats with %d.
+	ErrKeyValidationPackQuantities = "error.validation.pack_quantities"
+	// ErrKeyValidationQuantityTooLarge indicates a decimal order too large to
+	// calculate exactly at the precision.
+	ErrKeyValidationQuantityTooLarge = "error.validation.quantity_too_large"
 )
 
 // Success message translation keys.
 const (
 	// SuccessKeyPackCalculated indicates successful pack calculation.
 	SuccessKeyPackCalculated = "success.pack_calculated"
+	// SuccessKeyLoggedOut indicates a successful logout.
+	SuccessKeyLoggedOut = "success.logged_out"
 )
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantContextKey holds the tenant whose message overrides apply to the
+// request.
+const TenantContextKey = "i18n_tenant"
+
+// MaxOverrideLength caps the length of an override message, in bytes.
+const MaxOverrideLength = 1024
+
+var (
+	// ErrUnknownMessageKey is returned for an override of a message key the
+	// catalog does not hold.
+	ErrUnknownMessageKey = errors.New("unknown message key")
+	// ErrUnsupportedLocale is returned for an override in a locale the
+	// catalog does not hold.
+	ErrUnsupportedLocale = errors.New("unsupported locale")
+	// ErrInvalidMessage is returned for an override message that is empty,
+	// too long, or whose placeholders differ from the catalog message's.
+	ErrInvalidMessage = errors.New("invalid message")
+)
+
+// placeholderPattern matches the fmt verbs of a message, such as %d or %.2f.
+var placeholderPattern = regexp.MustCompile(`%[-+#0]*[0-9]*(?:\.[0-9]+)?[vTtbcdoOqxXUeEfFgGsp%]`)
+
+// Overrides holds messages tenants replaced, by tenant, locale and key.
+type Overrides map[string]map[string]map[string]string
+
+// SetOverrides replaces the messages tenants replaced. Overrides are not
+// validated here; see ValidateOverride.
+func (t *Translator) SetOverrides(overrides Overrides) {
+	t.overrides.Store(&overrides)
+}
+
+// TranslateFor is like Translate, but returns the message tenant replaced
+// for the resolved locale when there is one.
+func (t *Translator) TranslateFor(tenant, key, locale string) string {
+	if tenant != "" {
+		if overrides := t.overrides.Load(); overrides != nil {
+			if msg, ok := (*overrides)[tenant][t.resolveLocale(locale)][key]; ok {
+				return msg
+			}
+		}
+	}
+	return t.Translate(key, locale)
+}
+
+// ValidateOverride checks that message can replace the message key in
+// locale: both must be in the catalog, and message must fill in the same
+// placeholders, in the same order, as the message it replaces, since
+// callers format it with the same arguments.
+func (t *Translator) ValidateOverride(key, locale, message string) error {
+	if _, ok := t.catalog()[locale]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedLocale, locale)
+	}
+	if _, ok := t.catalog()[DefaultLocale][key]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownMessageKey, key)
+	}
+	original := t.Translate(key, locale)
+	if strings.TrimSpace(message) == "" || len(message) > MaxOverrideLength {
+		return fmt.Errorf("%w: message must be 1 to %d bytes", ErrInvalidMessage, MaxOverrideLength)
+	}
+	if want, got := placeholders(original), placeholders(message); !slices.Equal(want, got) {
+		return fmt.Errorf("%w: placeholders %v, want %v", ErrInvalidMessage, got, want)
+	}
+	return nil
+}
+
+// placeholders returns the fmt verbs of message, leaving out escaped percent
+// signs.
+func placeholders(message string) []string {
+	verbs := []string{}
+	for _, verb := range placeholderPattern.FindAllString(message, -1) {
+		if verb != "%%" {
+			verbs = append(verbs, verb)
+		}
+	}
+	return verbs
+}
+
+// GetTenant returns the tenant of the request in c, or "" when it has none.
+func GetTenant(c *gin.Context) string {
+	return c.GetString(TenantContextKey)
+}
+
+// Message returns the message key in the locale of the request in c, as the
+// request's tenant replaced it when it did.
+func Message(c *gin.Context, key string) string {
+	return GetTranslator().TranslateFor(GetTenant(c), key, GetLocale(c))
+}
@@ -0,0 +1,84 @@
+//go:build !integration
+
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslator_TranslateFor(t *testing.T) {
+	translator := NewTranslator()
+	translator.SetOverrides(Overrides{
+		"acme": {
+			"en": {ErrKeyRateLimitExceeded: "Your Acme plan allows fewer requests, slow down"},
+			"pt": {ErrKeyRateLimitExceeded: "Seu plano Acme permite menos requisições"},
+		},
+	})
+
+	assert.Equal(t, "Your Acme plan allows fewer requests, slow down", translator.TranslateFor("acme", ErrKeyRateLimitExceeded, "en"))
+	assert.Equal(t, "Seu plano Acme permite menos requisições", translator.TranslateFor("acme", ErrKeyRateLimitExceeded, "pt"))
+	assert.Equal(t, "Your Acme plan allows fewer requests, slow down", translator.TranslateFor("acme", ErrKeyRateLimitExceeded, "fr"),
+		"unsupported locales resolve to English before overrides are looked up")
+	assert.Equal(t, "Te veel verzoeken, probeer het later opnieuw", translator.TranslateFor("acme", ErrKeyRateLimitExceeded, "nl"),
+		"locales the tenant did not override keep the catalog message")
+	assert.Equal(t, "Forbidden", translator.TranslateFor("acme", ErrKeyForbidden, "en"))
+	assert.Equal(t, "Too many requests, please try again later", translator.TranslateFor("other", ErrKeyRateLimitExceeded, "en"))
+	assert.Equal(t, "Too many requests, please try again later", translator.TranslateFor("", ErrKeyRateLimitExceeded, "en"))
+
+	translator.SetOverrides(nil)
+	assert.Equal(t, "Too many requests, please try again later", translator.TranslateFor("acme", ErrKeyRateLimitExceeded, "en"))
+}
+
+func TestTranslator_ValidateOverride(t *testing.T) {
+	translator := NewTranslator()
+
+	tests := []struct {
+		name    string
+		key     string
+		locale  string
+		message string
+		wantErr error
+	}{
+		{name: "plain message", key: ErrKeyRateLimitExceeded, locale: "en", message: "Slow down, 100% of your quota is used"},
+		{name: "same placeholders", key: ErrKeyValidationItemsOrderedMax, locale: "pt", message: "Pedido acima de %d itens"},
+		{name: "escaped percent sign", key: ErrKeyValidationItemsOrderedMax, locale: "en", message: "At most %d items, 100%% of the limit"},
+		{name: "unknown key", key: "error.unknown", locale: "en", message: "Oops", wantErr: ErrUnknownMessageKey},
+		{name: "unsupported locale", key: ErrKeyForbidden, locale: "fr", message: "Interdit", wantErr: ErrUnsupportedLocale},
+		{name: "empty message", key: ErrKeyForbidden, locale: "en", message: "  ", wantErr: ErrInvalidMessage},
+		{name: "missing placeholder", key: ErrKeyValidationItemsOrderedMax, locale: "en", message: "Order too large", wantErr: ErrInvalidMessage},
+		{name: "other placeholder", key: ErrKeyValidationItemsOrderedMax, locale: "en", message: "At most %s items", wantErr: ErrInvalidMessage},
+		{name: "added placeholder", key: ErrKeyForbidden, locale: "en", message: "Forbidden for %s", wantErr: ErrInvalidMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translator.ValidateOverride(tt.key, tt.locale, tt.message)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	GetTranslator().SetOverrides(Overrides{"acme": {"pt": {ErrKeyForbidden: "Acesso negado pela Acme"}}})
+	defer GetTranslator().SetOverrides(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set(AcceptLanguageHeader, "pt-BR")
+
+	assert.Equal(t, "Proibido", Message(c, ErrKeyForbidden))
+	c.Set(TenantContextKey, "acme")
+	assert.Equal(t, "acme", GetTenant(c))
+	assert.Equal(t, "Acesso negado pela Acme", Message(c, ErrKeyForbidden))
+}
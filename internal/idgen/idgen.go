@@ -0,0 +1,79 @@
+// Package idgen generates the IDs the service assigns: document ObjectIDs,
+// and UUIDs for requests and jobs.
+//
+// IDs are random unless Seed was called, as deterministic mode does for
+// contract and integration tests: IDs then come from a sequence derived from
+// the seed, so the same requests made in the same order get the same IDs on
+// every run and recorded fixtures stay valid.
+package idgen
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// sequence generates seeded IDs.
+type sequence struct {
+	mu      sync.Mutex
+	random  *rand.ChaCha8
+	prefix  [5]byte
+	counter uint32
+}
+
+// seeded is the sequence in use, or nil for random IDs.
+var seeded atomic.Pointer[sequence]
+
+// Seed makes IDs repeat across runs: every later ID comes from a sequence
+// derived from seed, restarted by each call.
+func Seed(seed uint64) {
+	var key [32]byte
+	binary.BigEndian.PutUint64(key[:], seed)
+	s := &sequence{random: rand.NewChaCha8(key)}
+	_, _ = s.random.Read(s.prefix[:])
+	seeded.Store(s)
+}
+
+// Reset makes IDs random again.
+func Reset() {
+	seeded.Store(nil)
+}
+
+// NewObjectID returns a new ObjectID. Seeded ObjectIDs still sort in the
+// order they were generated, like random ones: they hold the time of
+// timeutil.Now, a prefix derived from the seed and a counter.
+func NewObjectID() primitive.ObjectID {
+	s := seeded.Load()
+	if s == nil {
+		return primitive.NewObjectID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter++
+
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[:4], uint32(timeutil.Now().Unix()))
+	copy(id[4:9], s.prefix[:])
+	id[9], id[10], id[11] = byte(s.counter>>16), byte(s.counter>>8), byte(s.counter)
+	return id
+}
+
+// NewUUID returns a new version 4 UUID string.
+func NewUUID() string {
+	s := seeded.Load()
+	if s == nil {
+		return uuid.NewString()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Reading from ChaCha8 never fails
+	return uuid.Must(uuid.NewRandomFromReader(s.random)).String()
+}
@@ -0,0 +1,57 @@
+//go:build !integration
+
+package idgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// generate returns n ObjectIDs and n UUIDs, interleaved like a run would.
+func generate(n int) ([]primitive.ObjectID, []string) {
+	var objectIDs []primitive.ObjectID
+	var uuids []string
+	for range n {
+		objectIDs = append(objectIDs, NewObjectID())
+		uuids = append(uuids, NewUUID())
+	}
+	return objectIDs, uuids
+}
+
+func TestSeed(t *testing.T) {
+	defer Reset()
+	timeutil.Freeze(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer timeutil.Freeze(time.Time{})
+
+	Seed(42)
+	objectIDs, uuids := generate(3)
+	Seed(42)
+	againObjectIDs, againUUIDs := generate(3)
+
+	assert.Equal(t, objectIDs, againObjectIDs, "the same seed repeats the IDs")
+	assert.Equal(t, uuids, againUUIDs)
+	assert.Equal(t, timeutil.Now(), objectIDs[0].Timestamp())
+	for i := 1; i < len(objectIDs); i++ {
+		assert.Less(t, objectIDs[i-1].Hex(), objectIDs[i].Hex(), "ObjectIDs sort in generation order")
+		assert.NotEqual(t, uuids[i-1], uuids[i])
+	}
+	parsed, err := uuid.Parse(uuids[0])
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(4), parsed.Version())
+
+	Seed(7)
+	otherObjectIDs, otherUUIDs := generate(1)
+	assert.NotEqual(t, objectIDs[0], otherObjectIDs[0], "another seed gives other IDs")
+	assert.NotEqual(t, uuids[0], otherUUIDs[0])
+
+	Reset()
+	randomObjectIDs, _ := generate(1)
+	assert.NotEqual(t, timeutil.Now(), randomObjectIDs[0].Timestamp(), "random ObjectIDs use the real time")
+}
@@ -0,0 +1,194 @@
+// Package jwks fetches the keys an external token issuer publishes as a JSON
+// Web Key Set (RFC 7517), so tokens it signs can be verified without sharing
+// a secret.
+//
+// Keys are cached and refetched periodically, and early when a token names a
+// key the cached set does not hold, which is how an issuer rotating its keys
+// shows up. A failed refetch keeps the keys fetched before.
+package jwks
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/httpclient"
+)
+
+// Defaults for zero Config fields.
+const (
+	defaultRefreshInterval    = time.Hour
+	defaultMinRefreshInterval = time.Minute
+	fetchTimeout              = 5 * time.Second
+	maxKeySetBytes            = 1 << 20
+)
+
+// ErrKeyNotFound is returned when the key set holds no usable key with the
+// requested ID.
+var ErrKeyNotFound = errors.New("signing key not found in key set")
+
+// Config configures a KeySet.
+type Config struct {
+	// URL is where the issuer publishes its key set.
+	URL string
+	// RefreshInterval is how long fetched keys are used before the set is
+	// fetched again. Zero uses one hour.
+	RefreshInterval time.Duration
+	// MinRefreshInterval is how often, at most, a token naming an unknown
+	// key makes the set be fetched early, so tokens with made-up key IDs
+	// cannot flood the issuer. Zero uses one minute.
+	MinRefreshInterval time.Duration
+}
+
+// KeySet holds the RSA signing keys of a JSON Web Key Set, by key ID. It is
+// safe for concurrent use.
+type KeySet struct {
+	url                string
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	client             *httpclient.Client
+	now                func() time.Time
+
+	// mu is held while fetching, so concurrent lookups wait for one fetch
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	triedAt   time.Time
+}
+
+// New creates a KeySet for the key set at cfg.URL. Keys are fetched on the
+// first lookup.
+func New(cfg Config) *KeySet {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultRefreshInterval
+	}
+	if cfg.MinRefreshInterval <= 0 {
+		cfg.MinRefreshInterval = defaultMinRefreshInterval
+	}
+	return &KeySet{
+		url:                cfg.URL,
+		refreshInterval:    cfg.RefreshInterval,
+		minRefreshInterval: cfg.MinRefreshInterval,
+		client:             httpclient.New(httpclient.Config{Name: "jwks", Timeout: fetchTimeout}),
+		now:                time.Now,
+	}
+}
+
+// Key returns the public key with ID kid. An empty kid matches the only key
+// of a set holding a single one.
+func (s *KeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	key, found := s.lookup(kid)
+	stale := now.Sub(s.fetchedAt) >= s.refreshInterval
+	if (stale || !found) && now.Sub(s.triedAt) >= s.minRefreshInterval {
+		s.triedAt = now
+		if err := s.fetch(ctx); err != nil {
+			log.Warn().Err(err).Str("url", s.url).Bool("cached", s.keys != nil).Msg("Failed to fetch JWKS")
+			if s.keys == nil {
+				return nil, err
+			}
+		} else {
+			s.fetchedAt = now
+			key, found = s.lookup(kid)
+		}
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// lookup returns the cached key with ID kid.
+func (s *KeySet) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is the part of a JWK (RFC 7517, RFC 7518 section 6.3) needed
+// to verify RS256 signatures.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// fetch replaces the cached keys with the RSA signing keys of the set.
+// Other keys, such as encryption or EC keys, are skipped.
+func (s *KeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch key set: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch key set: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxKeySetBytes)).Decode(&set); err != nil {
+		return fmt.Errorf("decode key set: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != "RS256") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			log.Warn().Err(err).Str("kid", jwk.Kid).Msg("Skipping invalid JWKS key")
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+	return nil
+}
+
+// rsaPublicKey decodes the modulus and exponent of the key.
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+	exponent := new(big.Int).SetBytes(e).Int64()
+	if exponent < 3 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent)}, nil
+}
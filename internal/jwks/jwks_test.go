@@ -0,0 +1,129 @@
+//go:build !integration
+
+package jwks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issuer serves the keys it holds as a key set and counts the fetches.
+type issuer struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	status  int
+	fetches atomic.Int32
+}
+
+func newIssuer(t *testing.T) (*issuer, *httptest.Server) {
+	iss := &issuer{keys: map[string]*rsa.PublicKey{}, status: http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		iss.fetches.Add(1)
+		iss.mu.Lock()
+		defer iss.mu.Unlock()
+		if iss.status != http.StatusOK {
+			w.WriteHeader(iss.status)
+			return
+		}
+		keys := []map[string]string{{"kty": "EC", "kid": "ec", "crv": "P-256"}}
+		for kid, key := range iss.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+				"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	t.Cleanup(server.Close)
+	return iss, server
+}
+
+func (iss *issuer) set(kid string, key *rsa.PublicKey, status int) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	if key != nil {
+		iss.keys[kid] = key
+	}
+	iss.status = status
+}
+
+func generateKey(t *testing.T) *rsa.PublicKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &key.PublicKey
+}
+
+func TestKeySet_Key(t *testing.T) {
+	iss, server := newIssuer(t)
+	first := generateKey(t)
+	iss.set("first", first, http.StatusOK)
+
+	now := time.Now()
+	keys := New(Config{URL: server.URL, RefreshInterval: time.Hour, MinRefreshInterval: time.Minute})
+	keys.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, err := keys.Key(ctx, "first")
+	require.NoError(t, err)
+	assert.True(t, first.Equal(key))
+
+	t.Run("a single key matches an empty kid", func(t *testing.T) {
+		key, err := keys.Key(ctx, "")
+		require.NoError(t, err)
+		assert.True(t, first.Equal(key))
+		assert.Equal(t, int32(1), iss.fetches.Load(), "served from the cache")
+	})
+
+	t.Run("unknown kids refetch at most once per interval", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, err := keys.Key(ctx, "ec")
+		assert.ErrorIs(t, err, ErrKeyNotFound, "non-RSA keys are skipped")
+		_, err = keys.Key(ctx, "unknown")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		assert.Equal(t, int32(2), iss.fetches.Load())
+	})
+
+	t.Run("a rotated key is fetched when first used", func(t *testing.T) {
+		second := generateKey(t)
+		iss.set("second", second, http.StatusOK)
+		now = now.Add(time.Minute)
+
+		key, err := keys.Key(ctx, "second")
+		require.NoError(t, err)
+		assert.True(t, second.Equal(key))
+		assert.Equal(t, int32(3), iss.fetches.Load())
+	})
+
+	t.Run("a failed refresh keeps the keys fetched before", func(t *testing.T) {
+		iss.set("", nil, http.StatusInternalServerError)
+		now = now.Add(2 * time.Hour)
+
+		key, err := keys.Key(ctx, "first")
+		require.NoError(t, err)
+		assert.True(t, first.Equal(key))
+		assert.Greater(t, iss.fetches.Load(), int32(3), "the stale set was refetched")
+	})
+}
+
+func TestKeySet_Key_Unavailable(t *testing.T) {
+	iss, server := newIssuer(t)
+	iss.set("", nil, http.StatusNotFound)
+
+	_, err := New(Config{URL: server.URL}).Key(context.Background(), "first")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrKeyNotFound)
+}
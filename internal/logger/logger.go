@@ -7,10 +7,30 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // Init initializes the global logger with JSON format.
 func Init(level string, pretty bool) {
+	SetLevel(level)
+	zerolog.TimeFieldFormat = timeutil.Layout
+	zerolog.TimestampFunc = timeutil.Now
+
+	// Configure output
+	if pretty {
+		log.Logger = log.Output(zerolog.ConsoleWriter{
+			Out:        os.Stderr,
+			TimeFormat: time.RFC3339,
+		})
+	} else {
+		log.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+	}
+}
+
+// SetLevel sets the global log level: "debug", "info", "warn" or "error".
+// Any other level means info.
+func SetLevel(level string) {
 	logLevel := zerolog.InfoLevel
 	switch level {
 	case "debug":
@@ -22,19 +42,7 @@ func Init(level string, pretty bool) {
 	case "error":
 		logLevel = zerolog.ErrorLevel
 	}
-
 	zerolog.SetGlobalLevel(logLevel)
-	zerolog.TimeFieldFormat = time.RFC3339Nano
-
-	// Configure output
-	if pretty {
-		log.Logger = log.Output(zerolog.ConsoleWriter{
-			Out:        os.Stderr,
-			TimeFormat: time.RFC3339,
-		})
-	} else {
-		log.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
-	}
 }
 
 // Logger returns the global logger instance.
@@ -67,6 +67,16 @@ func TestInit(t *testing.T) {
 	}
 }
 
+func TestSetLevel(t *testing.T) {
+	Init("info", false)
+	defer SetLevel("info")
+
+	SetLevel("warn")
+	assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+	SetLevel("unknown")
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+}
+
 func TestLogger(t *testing.T) {
 	Init("info", false)
 	logger := Logger()
@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Client label modes.
+const (
+	// ClientLabelsRaw labels series with client identifiers as they are.
+	ClientLabelsRaw = "raw"
+	// ClientLabelsHash replaces client identifiers with a short stable hash.
+	ClientLabelsHash = "hash"
+	// ClientLabelsBucket folds client identifiers into a fixed number of
+	// buckets, bounding the series they add.
+	ClientLabelsBucket = "bucket"
+)
+
+// OverflowLabel replaces label values past a label's cap.
+const OverflowLabel = "other"
+
+// CardinalityConfig limits how many series client-facing labels can create.
+type CardinalityConfig struct {
+	// ClientLabels is ClientLabelsRaw, ClientLabelsHash or ClientLabelsBucket.
+	ClientLabels string
+	// ClientLabelBuckets is the number of buckets in ClientLabelsBucket mode.
+	ClientLabelBuckets int
+	// MaxLabelValues caps the distinct values of an unbounded label, such as
+	// the path of unmatched routes or a client. Zero means no cap.
+	MaxLabelValues int
+	// MaxPackSets caps the distinct pack size sets labelled. Zero means no cap.
+	MaxPackSets int
+}
+
+// DefaultCardinalityConfig returns the default limits.
+func DefaultCardinalityConfig() CardinalityConfig {
+	return CardinalityConfig{
+		ClientLabels:       ClientLabelsRaw,
+		ClientLabelBuckets: 64,
+		MaxLabelValues:     500,
+		MaxPackSets:        50,
+	}
+}
+
+// labelGuard caps the distinct values of one label. Values seen before the
+// cap was reached keep their series; later ones are folded into
+// OverflowLabel and counted.
+type labelGuard struct {
+	label   string
+	metrics []string
+	maxPack bool
+
+	mu         sync.RWMutex
+	max        int
+	seen       map[string]struct{}
+	overflowed int64
+}
+
+func newLabelGuard(label string, maxPack bool, metrics ...string) *labelGuard {
+	return &labelGuard{label: label, metrics: metrics, maxPack: maxPack, seen: make(map[string]struct{})}
+}
+
+// value returns v if it may be used as the label value, or OverflowLabel.
+func (g *labelGuard) value(v string) string {
+	g.mu.RLock()
+	_, ok := g.seen[v]
+	limit := g.max
+	g.mu.RUnlock()
+	if ok || limit <= 0 {
+		return v
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.seen[v]; ok {
+		return v
+	}
+	if len(g.seen) >= g.max {
+		g.overflowed++
+		return OverflowLabel
+	}
+	g.seen[v] = struct{}{}
+	return v
+}
+
+var (
+	cardinalityMu  sync.RWMutex
+	cardinalityCfg = DefaultCardinalityConfig()
+
+	httpPathLabels          = newLabelGuard("path", false, "http_request_duration_seconds", "http_requests_total")
+	deprecationClientLabels = newLabelGuard("client", false, "api_deprecated_field_usage_total")
+	packSetLabels           = newLabelGuard("pack_set", true, "pack_set_calculations_total")
+	labelGuards             = []*labelGuard{httpPathLabels, deprecationClientLabels, packSetLabels}
+
+	// PackSetCalculationsTotal tracks calculations by pack size set.
+	PackSetCalculationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pack_set_calculations_total",
+			Help: "Total number of calculations by pack size set (a hash of the sorted sizes, or \"default\")",
+		},
+		[]string{"pack_set"},
+	)
+)
+
+func init() {
+	ConfigureCardinality(DefaultCardinalityConfig())
+}
+
+// ConfigureCardinality sets the label limits. Call it at startup, before
+// metrics are recorded: values already admitted under a label keep their
+// series.
+func ConfigureCardinality(cfg CardinalityConfig) {
+	switch cfg.ClientLabels {
+	case ClientLabelsRaw, ClientLabelsHash, ClientLabelsBucket:
+	default:
+		cfg.ClientLabels = ClientLabelsRaw
+	}
+	if cfg.ClientLabelBuckets <= 0 {
+		cfg.ClientLabelBuckets = DefaultCardinalityConfig().ClientLabelBuckets
+	}
+
+	cardinalityMu.Lock()
+	cardinalityCfg = cfg
+	cardinalityMu.Unlock()
+
+	for _, guard := range labelGuards {
+		guard.mu.Lock()
+		guard.max = cfg.MaxLabelValues
+		if guard.maxPack {
+			guard.max = cfg.MaxPackSets
+		}
+		guard.mu.Unlock()
+	}
+}
+
+// ClientLabel returns the label value of a client identifier, such as an API
+// key fingerprint, user or tenant ID, in the configured mode.
+func ClientLabel(id string) string {
+	cardinalityMu.RLock()
+	cfg := cardinalityCfg
+	cardinalityMu.RUnlock()
+
+	switch cfg.ClientLabels {
+	case ClientLabelsHash:
+		return fmt.Sprintf("%08x", hash32(id))
+	case ClientLabelsBucket:
+		return "bucket_" + strconv.Itoa(int(hash32(id)%uint32(cfg.ClientLabelBuckets)))
+	default:
+		return id
+	}
+}
+
+// PackSetLabel returns the label value of a pack size set: a short hash of
+// the sorted sizes, or "default" for the calculator's own sizes.
+func PackSetLabel(sizes []int) string {
+	if len(sizes) == 0 {
+		return "default"
+	}
+	sorted := slices.Clone(sizes)
+	slices.Sort(sorted)
+	parts := make([]string, len(sorted))
+	for i, size := range sorted {
+		parts[i] = strconv.Itoa(size)
+	}
+	return fmt.Sprintf("%08x", hash32(strings.Join(parts, ",")))
+}
+
+// RecordPackSetCalculation records a calculation made with sizes, empty for
+// the calculator's own.
+func RecordPackSetCalculation(sizes []int) {
+	PackSetCalculationsTotal.WithLabelValues(packSetLabels.value(PackSetLabel(sizes))).Inc()
+}
+
+func hash32(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// MetricCardinality is the current cardinality of one metric.
+type MetricCardinality struct {
+	Name string `json:"name"`
+	// Series counts label combinations; each histogram series is also
+	// exported once per bucket.
+	Series int `json:"series"`
+	// Labels counts the distinct values of each label.
+	Labels map[string]int `json:"labels,omitempty"`
+	// Capped names the label whose values are capped, if any.
+	Capped string `json:"capped_label,omitempty"`
+	// Overflowed counts the recordings folded into OverflowLabel by the cap.
+	Overflowed int64 `json:"overflowed,omitempty"`
+}
+
+// CardinalityReport describes the series exported and the limits in force.
+type CardinalityReport struct {
+	ClientLabels   string              `json:"client_labels"`
+	MaxLabelValues int                 `json:"max_label_values"`
+	MaxPackSets    int                 `json:"max_pack_sets"`
+	TotalSeries    int                 `json:"total_series"`
+	Metrics        []MetricCardinality `json:"metrics"`
+}
+
+// Cardinality reports the series of every metric gathered from gatherer,
+// highest first.
+func Cardinality(gatherer prometheus.Gatherer) (CardinalityReport, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return CardinalityReport{}, err
+	}
+
+	cardinalityMu.RLock()
+	report := CardinalityReport{
+		ClientLabels:   cardinalityCfg.ClientLabels,
+		MaxLabelValues: cardinalityCfg.MaxLabelValues,
+		MaxPackSets:    cardinalityCfg.MaxPackSets,
+		Metrics:        make([]MetricCardinality, 0, len(families)),
+	}
+	cardinalityMu.RUnlock()
+
+	guards := make(map[string]*labelGuard)
+	for _, guard := range labelGuards {
+		for _, name := range guard.metrics {
+			guards[name] = guard
+		}
+	}
+
+	for _, family := range families {
+		metric := MetricCardinality{Name: family.GetName(), Series: len(family.GetMetric())}
+		values := make(map[string]map[string]struct{})
+		for _, m := range family.GetMetric() {
+			for _, pair := range m.GetLabel() {
+				if values[pair.GetName()] == nil {
+					values[pair.GetName()] = make(map[string]struct{})
+				}
+				values[pair.GetName()][pair.GetValue()] = struct{}{}
+			}
+		}
+		if len(values) > 0 {
+			metric.Labels = make(map[string]int, len(values))
+			for label, distinct := range values {
+				metric.Labels[label] = len(distinct)
+			}
+		}
+		if guard, ok := guards[metric.Name]; ok {
+			guard.mu.RLock()
+			metric.Capped = guard.label
+			metric.Overflowed = guard.overflowed
+			guard.mu.RUnlock()
+		}
+		report.TotalSeries += metric.Series
+		report.Metrics = append(report.Metrics, metric)
+	}
+
+	sort.SliceStable(report.Metrics, func(i, j int) bool {
+		if report.Metrics[i].Series != report.Metrics[j].Series {
+			return report.Metrics[i].Series > report.Metrics[j].Series
+		}
+		return report.Metrics[i].Name < report.Metrics[j].Name
+	})
+	return report, nil
+}
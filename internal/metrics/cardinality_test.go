@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientLabel(t *testing.T) {
+	defer ConfigureCardinality(DefaultCardinalityConfig())
+
+	ConfigureCardinality(CardinalityConfig{ClientLabels: ClientLabelsRaw})
+	assert.Equal(t, "ab12cd34", ClientLabel("ab12cd34"))
+
+	ConfigureCardinality(CardinalityConfig{ClientLabels: ClientLabelsHash})
+	hashed := ClientLabel("ab12cd34")
+	assert.Len(t, hashed, 8)
+	assert.NotEqual(t, "ab12cd34", hashed)
+	assert.Equal(t, hashed, ClientLabel("ab12cd34"))
+
+	ConfigureCardinality(CardinalityConfig{ClientLabels: ClientLabelsBucket, ClientLabelBuckets: 4})
+	buckets := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		buckets[ClientLabel("tenant-"+strconv.Itoa(i))] = true
+	}
+	assert.LessOrEqual(t, len(buckets), 4)
+	assert.Contains(t, ClientLabel("tenant-1"), "bucket_")
+
+	ConfigureCardinality(CardinalityConfig{ClientLabels: "unknown"})
+	assert.Equal(t, "ab12cd34", ClientLabel("ab12cd34"))
+}
+
+func TestPackSetLabel(t *testing.T) {
+	assert.Equal(t, "default", PackSetLabel(nil))
+	assert.Equal(t, PackSetLabel([]int{250, 500, 1000}), PackSetLabel([]int{1000, 250, 500}))
+	assert.NotEqual(t, PackSetLabel([]int{250, 500}), PackSetLabel([]int{250, 500, 1000}))
+}
+
+func TestLabelGuard(t *testing.T) {
+	guard := newLabelGuard("path", false)
+	guard.max = 2
+
+	assert.Equal(t, "/a", guard.value("/a"))
+	assert.Equal(t, "/b", guard.value("/b"))
+	assert.Equal(t, OverflowLabel, guard.value("/c"))
+	assert.Equal(t, "/a", guard.value("/a"))
+	assert.Equal(t, int64(1), guard.overflowed)
+
+	uncapped := newLabelGuard("path", false)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, strconv.Itoa(i), uncapped.value(strconv.Itoa(i)))
+	}
+}
+
+func TestPrometheusMiddleware_CapsUnmatchedPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer ConfigureCardinality(DefaultCardinalityConfig())
+	ConfigureCardinality(CardinalityConfig{MaxLabelValues: len(httpPathLabels.seen) + 1})
+
+	router := gin.New()
+	router.Use(PrometheusMiddleware())
+	for _, path := range []string{"/probe-a", "/probe-b"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(HTTPRequestTotal.WithLabelValues(http.MethodGet, "/probe-a", "404")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(HTTPRequestTotal.WithLabelValues(http.MethodGet, "/probe-b", "404")))
+	assert.GreaterOrEqual(t, testutil.ToFloat64(HTTPRequestTotal.WithLabelValues(http.MethodGet, OverflowLabel, "404")), float64(1))
+}
+
+func TestCardinality(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total", Help: "test"}, []string{"method", "path"})
+	calculations := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "pack_set_calculations_total", Help: "test"}, []string{"pack_set"})
+	registry.MustRegister(requests, calculations)
+
+	requests.WithLabelValues("GET", "/a").Inc()
+	requests.WithLabelValues("GET", "/b").Inc()
+	requests.WithLabelValues("POST", "/a").Inc()
+	calculations.WithLabelValues("default").Inc()
+
+	report, err := Cardinality(registry)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.TotalSeries)
+	assert.Equal(t, 50, report.MaxPackSets)
+	require.Len(t, report.Metrics, 2)
+	assert.Equal(t, "test_requests_total", report.Metrics[0].Name)
+	assert.Equal(t, 3, report.Metrics[0].Series)
+	assert.Equal(t, map[string]int{"method": 2, "path": 2}, report.Metrics[0].Labels)
+	assert.Empty(t, report.Metrics[0].Capped)
+	assert.Equal(t, "pack_set", report.Metrics[1].Capped)
+}
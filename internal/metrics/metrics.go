@@ -30,6 +30,25 @@ var (
 		[]string{"method", "path", "status_code"},
 	)
 
+	// GRPCRequestDuration tracks gRPC request duration by method and status code.
+	GRPCRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_request_duration_seconds",
+			Help:    "gRPC request duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "code"},
+	)
+
+	// GRPCRequestTotal tracks total gRPC requests by method and status code.
+	GRPCRequestTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_requests_total",
+			Help: "Total number of gRPC requests",
+		},
+		[]string{"method", "code"},
+	)
+
 	// PackCalculationsTotal tracks total pack calculations.
 	PackCalculationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -48,6 +67,89 @@ var (
 		},
 	)
 
+	// PackCalculationDurationByOrderSize tracks pack calculation duration
+	// by order size bucket (see OrderSizeBucket).
+	PackCalculationDurationByOrderSize = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pack_calculation_duration_by_order_size_seconds",
+			Help:    "Pack calculation duration in seconds, by order size bucket",
+			Buckets: []float64{0.0001, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1.0, 5.0},
+		},
+		[]string{"order_size"},
+	)
+
+	// PacksPerResult tracks how many packs calculated results contain.
+	PacksPerResult = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "pack_calculation_packs_per_result",
+			Help:    "Number of packs in calculated results",
+			Buckets: []float64{1, 2, 3, 5, 10, 25, 100, 1000, 10000},
+		},
+	)
+
+	// GreedyComparisonsTotal tracks whether the greedy combination would have
+	// matched the exact results served.
+	GreedyComparisonsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pack_greedy_comparisons_total",
+			Help: "Total number of exact pack results by whether the greedy combination matched them",
+		},
+		[]string{"outcome"},
+	)
+
+	// GreedySanityFailuresTotal tracks pack searches that did worse than greedy.
+	GreedySanityFailuresTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "pack_greedy_sanity_failures_total",
+			Help: "Total number of pack searches replaced by a better greedy combination",
+		},
+	)
+
+	// InputWarningsTotal tracks suspicious calculate inputs by warning code.
+	InputWarningsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pack_calculation_input_warnings_total",
+			Help: "Total number of calculate requests flagged with an input warning",
+		},
+		[]string{"code"},
+	)
+
+	// DeprecatedFieldUsageTotal tracks use of deprecated API fields by client.
+	DeprecatedFieldUsageTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_deprecated_field_usage_total",
+			Help: "Total number of requests that sent or received a deprecated API field",
+		},
+		[]string{"endpoint", "field", "direction", "client"},
+	)
+
+	// RateLimiterShardRequests tracks rate limit checks per limiter shard.
+	RateLimiterShardRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limiter_shard_requests_total",
+			Help: "Total number of rate limit checks per limiter shard",
+		},
+		[]string{"limiter", "shard"},
+	)
+
+	// RateLimiterShardVisitors tracks visitors tracked per limiter shard.
+	RateLimiterShardVisitors = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rate_limiter_shard_visitors",
+			Help: "Number of visitors tracked per limiter shard",
+		},
+		[]string{"limiter", "shard"},
+	)
+
+	// RateLimiterEvictionsTotal tracks visitors removed from limiter shards.
+	RateLimiterEvictionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limiter_evictions_total",
+			Help: "Total number of visitors evicted from rate limiter shards",
+		},
+		[]string{"limiter", "reason"},
+	)
+
 	// CacheOperationsTotal tracks cache operations.
 	CacheOperationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -57,6 +159,345 @@ var (
 		[]string{"operation", "result"},
 	)
 
+	// CacheBackendLatency tracks round-trip time to external cache backends.
+	CacheBackendLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cache_backend_latency_seconds",
+			Help:    "External cache backend round-trip time in seconds",
+			Buckets: []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1},
+		},
+		[]string{"backend", "operation"},
+	)
+
+	// CacheCompressionRatio tracks how much cache values shrink when compressed.
+	CacheCompressionRatio = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cache_compression_ratio",
+			Help:    "Ratio of uncompressed to compressed cache value size",
+			Buckets: []float64{1, 1.5, 2, 3, 5, 10, 20},
+		},
+		[]string{"algorithm"},
+	)
+
+	// CacheCompressedBytesTotal tracks cache value bytes before and after compression.
+	CacheCompressedBytesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_compressed_bytes_total",
+			Help: "Total cache value bytes passed through compression, before and after",
+		},
+		[]string{"algorithm", "stage"},
+	)
+
+	// WorkerPoolSize tracks the number of workers per pool.
+	WorkerPoolSize = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "worker_pool_size",
+			Help: "Number of workers in the pool",
+		},
+		[]string{"pool"},
+	)
+
+	// WorkerPoolBusy tracks workers running a task per pool.
+	WorkerPoolBusy = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "worker_pool_busy_workers",
+			Help: "Number of pool workers currently running a task",
+		},
+		[]string{"pool"},
+	)
+
+	// WorkerPoolQueued tracks tasks waiting for a worker per pool.
+	WorkerPoolQueued = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "worker_pool_queued_tasks",
+			Help: "Number of tasks waiting for a free worker",
+		},
+		[]string{"pool"},
+	)
+
+	// WorkerPoolQueueWait tracks how long tasks wait for a worker.
+	WorkerPoolQueueWait = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "worker_pool_queue_wait_seconds",
+			Help:    "Time tasks waited for a free worker in seconds",
+			Buckets: []float64{0.0001, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		},
+		[]string{"pool"},
+	)
+
+	// WorkerPoolRejectionsTotal tracks tasks that did not get a worker.
+	WorkerPoolRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_pool_rejections_total",
+			Help: "Total number of tasks rejected because no worker was free in time",
+		},
+		[]string{"pool", "reason"},
+	)
+
+	// CalculationHistoryDroppedTotal tracks calculations left out of the
+	// history because the write buffer was full.
+	CalculationHistoryDroppedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "calculation_history_dropped_total",
+			Help: "Total number of calculations not stored in the history because the write buffer was full",
+		},
+	)
+
+	// LoginLockoutsTotal tracks accounts and client IPs locked out after
+	// repeated failed logins.
+	LoginLockoutsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_lockouts_total",
+			Help: "Total number of lockouts after repeated failed logins, by scope (account or ip)",
+		},
+		[]string{"scope"},
+	)
+
+	// LoginAnomaliesTotal tracks suspicious logins by anomaly.
+	LoginAnomaliesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_anomalies_total",
+			Help: "Total number of suspicious successful logins, by anomaly type",
+		},
+		[]string{"type"},
+	)
+
+	// LoginsTotal tracks login attempts by outcome.
+	LoginsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_logins_total",
+			Help: "Total number of login attempts, by outcome (success, invalid_credentials, locked, unavailable or error)",
+		},
+		[]string{"outcome"},
+	)
+
+	// TokenRefreshesTotal tracks token refreshes by outcome.
+	TokenRefreshesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_token_refreshes_total",
+			Help: "Total number of token refreshes, by outcome (success, invalid_token, unavailable or error)",
+		},
+		[]string{"outcome"},
+	)
+
+	// DegradedTokenChecksTotal tracks access token checks skipped while the
+	// auth database is unavailable.
+	DegradedTokenChecksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_degraded_token_checks_total",
+			Help: "Total number of access token checks skipped while the auth database is unavailable, by check (blacklist or token_version)",
+		},
+		[]string{"check"},
+	)
+
+	// RateLimitRejectionsTotal tracks requests rejected by a rate limiter.
+	RateLimitRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limiter_rejections_total",
+			Help: "Total number of requests rejected by a rate limiter, by limiter and scope (ip or user)",
+		},
+		[]string{"limiter", "scope"},
+	)
+
+	// MongoCommandDuration tracks MongoDB command latency.
+	MongoCommandDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mongodb_command_duration_seconds",
+			Help:    "MongoDB command duration in seconds, by command, collection and status",
+			Buckets: []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1.0},
+		},
+		[]string{"command", "collection", "status"},
+	)
+
+	// MongoHedgedReadsTotal tracks hedged MongoDB reads by the read that answered.
+	MongoHedgedReadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mongodb_hedged_reads_total",
+			Help: "Total number of MongoDB reads hedged to a secondary, by collection and winner (primary or hedge)",
+		},
+		[]string{"collection", "winner"},
+	)
+
+	// GeoFenceRequestsTotal tracks requests outside the geofence.
+	GeoFenceRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "geofence_requests_total",
+			Help: "Total number of requests from outside the geofence, by outcome (blocked or bypassed)",
+		},
+		[]string{"outcome"},
+	)
+
+	// IPFilterBlockedTotal tracks requests blocked by the IP rules.
+	IPFilterBlockedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ip_filter_blocked_total",
+			Help: "Total number of requests blocked by the IP rules, by reason (denied or not_allowed)",
+		},
+		[]string{"reason"},
+	)
+
+	// OutboundHTTPRequestsTotal tracks outbound HTTP attempts.
+	OutboundHTTPRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbound_http_requests_total",
+			Help: "Total number of outbound HTTP attempts, by client, host and outcome (status class, error or circuit_open)",
+		},
+		[]string{"client", "host", "outcome"},
+	)
+
+	// OutboundHTTPRequestDuration tracks outbound HTTP attempt latency.
+	OutboundHTTPRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "outbound_http_request_duration_seconds",
+			Help:    "Outbound HTTP attempt duration in seconds, by client and host",
+			Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0},
+		},
+		[]string{"client", "host"},
+	)
+
+	// OutboundHTTPRetriesTotal tracks outbound HTTP retries.
+	OutboundHTTPRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbound_http_retries_total",
+			Help: "Total number of failed outbound HTTP attempts eligible for a retry, by client, host and result (retried or budget_exhausted)",
+		},
+		[]string{"client", "host", "result"},
+	)
+
+	// LegacyRefreshTokensRemaining tracks refresh tokens still stored in
+	// plaintext, as last counted by the migration.
+	LegacyRefreshTokensRemaining = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "auth_legacy_refresh_tokens_remaining",
+			Help: "Refresh tokens still stored in plaintext",
+		},
+	)
+
+	// LegacyRefreshTokensMigratedTotal tracks plaintext refresh tokens the
+	// migration hashed or, past the cutoff, deleted.
+	LegacyRefreshTokensMigratedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_legacy_refresh_tokens_migrated_total",
+			Help: "Total number of plaintext refresh tokens migrated, by action (hashed or invalidated)",
+		},
+		[]string{"action"},
+	)
+
+	// LegacyRefreshTokenReadsTotal tracks refreshes that used a token stored
+	// in plaintext.
+	LegacyRefreshTokenReadsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "auth_legacy_refresh_token_reads_total",
+			Help: "Total number of refresh tokens found stored in plaintext",
+		},
+	)
+
+	// StaleAccounts tracks active accounts without a recent login, as last
+	// counted by the stale account report.
+	StaleAccounts = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "auth_stale_accounts",
+			Help: "Active accounts without a login within the stale account threshold",
+		},
+	)
+
+	// StaleAccountsDeactivatedTotal tracks accounts deactivated for
+	// inactivity.
+	StaleAccountsDeactivatedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "auth_stale_accounts_deactivated_total",
+			Help: "Total number of accounts deactivated for inactivity",
+		},
+	)
+
+	// TokenCleanupsTotal tracks runs of the expired token cleanup.
+	TokenCleanupsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_token_cleanups_total",
+			Help: "Total number of expired token cleanup runs, by outcome (success or error)",
+		},
+		[]string{"outcome"},
+	)
+
+	// ExpiredTokensDeletedTotal tracks expired tokens deleted by the cleanup.
+	ExpiredTokensDeletedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "auth_expired_tokens_deleted_total",
+			Help: "Total number of expired refresh and blacklist tokens deleted by the cleanup",
+		},
+	)
+
+	// LogPurgesTotal tracks runs of the log retention purge.
+	LogPurgesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "log_purges_total",
+			Help: "Total number of log retention purge runs, by outcome (success or error)",
+		},
+		[]string{"outcome"},
+	)
+
+	// LogsPurgedTotal tracks log entries deleted by the retention purge.
+	LogsPurgedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "logs_purged_total",
+			Help: "Total number of log entries deleted by the retention purge, by reason (age or max_entries)",
+		},
+		[]string{"reason"},
+	)
+
+	// LogBufferDepth tracks log entries waiting to be written.
+	LogBufferDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "log_buffer_queue_depth",
+			Help: "Number of log entries buffered and not yet written",
+		},
+	)
+
+	// LogBufferDroppedTotal tracks log entries the write buffer dropped.
+	LogBufferDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "log_buffer_dropped_total",
+			Help: "Total number of log entries dropped by the write buffer, by reason (full, timeout or write_failed)",
+		},
+		[]string{"reason"},
+	)
+
+	// LogBufferFlushesTotal tracks batches the write buffer wrote.
+	LogBufferFlushesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "log_buffer_flushes_total",
+			Help: "Total number of log batches written by the write buffer, by outcome (success or error)",
+		},
+		[]string{"outcome"},
+	)
+
+	// CalculationJobsTotal tracks finished asynchronous calculation jobs.
+	CalculationJobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "calculation_jobs_total",
+			Help: "Total number of finished calculation jobs, by status (completed, failed or released)",
+		},
+		[]string{"status"},
+	)
+
+	// CalculationJobDuration tracks how long calculation jobs take to run.
+	CalculationJobDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "calculation_job_duration_seconds",
+			Help:    "Calculation job run time in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 5, 15, 60, 300, 900},
+		},
+	)
+
+	// CircuitBreakerState tracks each circuit breaker's state.
+	CircuitBreakerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "circuit_breaker_state",
+			Help: "Circuit breaker state: 0 closed, 1 open, 2 half-open",
+		},
+		[]string{"name"},
+	)
+
 	// CacheSize tracks current cache size.
 	CacheSize = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -80,7 +521,8 @@ func PrometheusMiddleware() gin.HandlerFunc {
 		start := time.Now()
 		path := c.FullPath()
 		if path == "" {
-			path = c.Request.URL.Path
+			// Unmatched paths are chosen by clients, so they are capped
+			path = httpPathLabels.value(c.Request.URL.Path)
 		}
 
 		c.Next()
@@ -94,17 +536,299 @@ func PrometheusMiddleware() gin.HandlerFunc {
 	}
 }
 
+// RecordGRPCRequest records metrics for a gRPC request. method is the full
+// method name, e.g. "/pack.v1.PackService/CalculatePacks".
+func RecordGRPCRequest(method, code string, duration time.Duration) {
+	GRPCRequestDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+	GRPCRequestTotal.WithLabelValues(method, code).Inc()
+}
+
 // RecordPackCalculation records metrics for a pack calculation.
 func RecordPackCalculation(duration time.Duration, status string) {
 	PackCalculationDuration.Observe(duration.Seconds())
 	PackCalculationsTotal.WithLabelValues(status).Inc()
 }
 
+// RecordPackResult records the duration and size of a calculated result.
+func RecordPackResult(orderedItems, packs int, duration time.Duration) {
+	PackCalculationDurationByOrderSize.WithLabelValues(OrderSizeBucket(orderedItems)).Observe(duration.Seconds())
+	PacksPerResult.Observe(float64(packs))
+}
+
+// OrderSizeBucket returns the order_size label of an order: "up_to_1k",
+// "up_to_10k", "up_to_100k", "up_to_1m" or "over_1m".
+func OrderSizeBucket(orderedItems int) string {
+	switch {
+	case orderedItems <= 1_000:
+		return "up_to_1k"
+	case orderedItems <= 10_000:
+		return "up_to_10k"
+	case orderedItems <= 100_000:
+		return "up_to_100k"
+	case orderedItems <= 1_000_000:
+		return "up_to_1m"
+	default:
+		return "over_1m"
+	}
+}
+
+// RecordGreedyComparison records whether the greedy combination differed
+// from an exact result.
+func RecordGreedyComparison(differs bool) {
+	outcome := "match"
+	if differs {
+		outcome = "differs"
+	}
+	GreedyComparisonsTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordGreedySanityFailure records a pack search that did worse than greedy.
+func RecordGreedySanityFailure() {
+	GreedySanityFailuresTotal.Inc()
+}
+
+// RecordInputWarning records a calculate input warning.
+func RecordInputWarning(code string) {
+	InputWarningsTotal.WithLabelValues(code).Inc()
+}
+
+// RecordDeprecatedFieldUsage records a deprecated field sent or returned to a
+// client. Distinct clients are capped like other unbounded labels.
+func RecordDeprecatedFieldUsage(endpoint, field, direction, client string) {
+	DeprecatedFieldUsageTotal.WithLabelValues(endpoint, field, direction, deprecationClientLabels.value(client)).Inc()
+}
+
+// RecordRateLimiterShardRequest records a rate limit check on a shard.
+func RecordRateLimiterShardRequest(limiter, shard string) {
+	RateLimiterShardRequests.WithLabelValues(limiter, shard).Inc()
+}
+
+// SetRateLimiterShardVisitors updates the visitor count of a shard.
+func SetRateLimiterShardVisitors(limiter, shard string, visitors int) {
+	RateLimiterShardVisitors.WithLabelValues(limiter, shard).Set(float64(visitors))
+}
+
+// RecordRateLimiterEviction records a visitor evicted from a shard.
+// reason is "capacity" or "expired".
+func RecordRateLimiterEviction(limiter, reason string) {
+	RecordRateLimiterEvictions(limiter, reason, 1)
+}
+
+// RecordRateLimiterEvictions records count visitors evicted from a shard.
+func RecordRateLimiterEvictions(limiter, reason string, count int) {
+	if count > 0 {
+		RateLimiterEvictionsTotal.WithLabelValues(limiter, reason).Add(float64(count))
+	}
+}
+
 // RecordCacheOperation records metrics for a cache operation.
 func RecordCacheOperation(operation, result string) {
 	CacheOperationsTotal.WithLabelValues(operation, result).Inc()
 }
 
+// RecordCacheBackendLatency records the round-trip time of an external cache operation.
+func RecordCacheBackendLatency(backend, operation string, duration time.Duration) {
+	CacheBackendLatency.WithLabelValues(backend, operation).Observe(duration.Seconds())
+}
+
+// RecordCacheCompression records the size of a cache value before and after compression.
+func RecordCacheCompression(algorithm string, original, compressed int) {
+	if compressed > 0 {
+		CacheCompressionRatio.WithLabelValues(algorithm).Observe(float64(original) / float64(compressed))
+	}
+	CacheCompressedBytesTotal.WithLabelValues(algorithm, "original").Add(float64(original))
+	CacheCompressedBytesTotal.WithLabelValues(algorithm, "compressed").Add(float64(compressed))
+}
+
+// SetWorkerPoolSize records the number of workers of a pool.
+func SetWorkerPoolSize(pool string, size int) {
+	WorkerPoolSize.WithLabelValues(pool).Set(float64(size))
+}
+
+// RecordWorkerPoolAcquire records a task that got a worker after waiting for wait.
+func RecordWorkerPoolAcquire(pool string, wait time.Duration) {
+	WorkerPoolQueueWait.WithLabelValues(pool).Observe(wait.Seconds())
+	WorkerPoolBusy.WithLabelValues(pool).Inc()
+}
+
+// RecordWorkerPoolRelease records a worker finishing its task.
+func RecordWorkerPoolRelease(pool string) {
+	WorkerPoolBusy.WithLabelValues(pool).Dec()
+}
+
+// AddWorkerPoolQueued adjusts the number of tasks waiting for a worker.
+func AddWorkerPoolQueued(pool string, delta int) {
+	WorkerPoolQueued.WithLabelValues(pool).Add(float64(delta))
+}
+
+// RecordWorkerPoolRejection records a task that did not get a worker.
+// reason is "queue_timeout", "queue_full" or "saturated".
+func RecordWorkerPoolRejection(pool, reason string) {
+	WorkerPoolRejectionsTotal.WithLabelValues(pool, reason).Inc()
+}
+
+// RecordCalculationHistoryDropped records calculations dropped from the history.
+func RecordCalculationHistoryDropped(count int) {
+	CalculationHistoryDroppedTotal.Add(float64(count))
+}
+
+// RecordLoginLockout records a lockout. scope is "account" or "ip".
+func RecordLoginLockout(scope string) {
+	LoginLockoutsTotal.WithLabelValues(scope).Inc()
+}
+
+// RecordLoginAnomaly records a suspicious login of the given anomaly type.
+func RecordLoginAnomaly(anomaly string) {
+	LoginAnomaliesTotal.WithLabelValues(anomaly).Inc()
+}
+
+// RecordLogin records a login attempt.
+func RecordLogin(outcome string) {
+	LoginsTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordTokenRefresh records a token refresh.
+func RecordTokenRefresh(outcome string) {
+	TokenRefreshesTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordDegradedTokenCheck records an access token check skipped in
+// degraded mode. check is "blacklist" or "token_version".
+func RecordDegradedTokenCheck(check string) {
+	DegradedTokenChecksTotal.WithLabelValues(check).Inc()
+}
+
+// RecordRateLimitRejection records a request rejected by a rate limiter.
+func RecordRateLimitRejection(limiter, scope string) {
+	RateLimitRejectionsTotal.WithLabelValues(limiter, scope).Inc()
+}
+
+// RecordMongoCommand records a MongoDB command. status is "success" or "error".
+func RecordMongoCommand(command, collection, status string, duration time.Duration) {
+	MongoCommandDuration.WithLabelValues(command, collection, status).Observe(duration.Seconds())
+}
+
+// RecordMongoHedgedRead records a hedged read answered by the hedge or, when
+// hedgeWon is false, by the first read.
+func RecordMongoHedgedRead(collection string, hedgeWon bool) {
+	winner := "primary"
+	if hedgeWon {
+		winner = "hedge"
+	}
+	MongoHedgedReadsTotal.WithLabelValues(collection, winner).Inc()
+}
+
+// RecordGeoFenceRequest records a request from outside the geofence that was
+// "blocked" or "bypassed".
+func RecordGeoFenceRequest(outcome string) {
+	GeoFenceRequestsTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordIPFilterBlocked records a request blocked by the IP rules, "denied"
+// by a deny rule or "not_allowed" by any allow rule.
+func RecordIPFilterBlocked(reason string) {
+	IPFilterBlockedTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordOutboundHTTPRequest records an outbound HTTP attempt. outcome is the
+// status class ("2xx"...), "error" or "circuit_open", which has no duration.
+func RecordOutboundHTTPRequest(client, host, outcome string, duration time.Duration) {
+	OutboundHTTPRequestsTotal.WithLabelValues(client, host, outcome).Inc()
+	if outcome != "circuit_open" {
+		OutboundHTTPRequestDuration.WithLabelValues(client, host).Observe(duration.Seconds())
+	}
+}
+
+// RecordOutboundHTTPRetry records a failed outbound HTTP attempt that was
+// "retried" or not, because the retry budget was "budget_exhausted".
+func RecordOutboundHTTPRetry(client, host, result string) {
+	OutboundHTTPRetriesTotal.WithLabelValues(client, host, result).Inc()
+}
+
+// SetLegacyRefreshTokensRemaining updates the count of plaintext refresh tokens.
+func SetLegacyRefreshTokensRemaining(count int64) {
+	LegacyRefreshTokensRemaining.Set(float64(count))
+}
+
+// RecordLegacyRefreshTokensMigrated records migrated plaintext refresh
+// tokens. action is "hashed" or "invalidated".
+func RecordLegacyRefreshTokensMigrated(action string, count int) {
+	LegacyRefreshTokensMigratedTotal.WithLabelValues(action).Add(float64(count))
+}
+
+// SetStaleAccounts updates the count of stale accounts.
+func SetStaleAccounts(count int64) {
+	StaleAccounts.Set(float64(count))
+}
+
+// RecordStaleAccountsDeactivated records accounts deactivated for inactivity.
+func RecordStaleAccountsDeactivated(count int) {
+	StaleAccountsDeactivatedTotal.Add(float64(count))
+}
+
+// RecordLegacyRefreshTokenRead records a refresh token found stored in plaintext.
+func RecordLegacyRefreshTokenRead() {
+	LegacyRefreshTokenReadsTotal.Inc()
+}
+
+// RecordTokenCleanup records a run of the expired token cleanup and the
+// tokens it deleted.
+func RecordTokenCleanup(deleted int64, err error) {
+	if err != nil {
+		TokenCleanupsTotal.WithLabelValues("error").Inc()
+		return
+	}
+	TokenCleanupsTotal.WithLabelValues("success").Inc()
+	ExpiredTokensDeletedTotal.Add(float64(deleted))
+}
+
+// RecordLogPurge records a run of the log retention purge.
+func RecordLogPurge(err error) {
+	if err != nil {
+		LogPurgesTotal.WithLabelValues("error").Inc()
+		return
+	}
+	LogPurgesTotal.WithLabelValues("success").Inc()
+}
+
+// RecordLogsPurged records log entries deleted by the retention purge.
+// reason is "age" or "max_entries".
+func RecordLogsPurged(reason string, count int64) {
+	LogsPurgedTotal.WithLabelValues(reason).Add(float64(count))
+}
+
+// SetLogBufferDepth records the log entries waiting to be written.
+func SetLogBufferDepth(depth int) {
+	LogBufferDepth.Set(float64(depth))
+}
+
+// RecordLogBufferDropped records log entries the write buffer dropped.
+// reason is "full", "timeout" or "write_failed".
+func RecordLogBufferDropped(reason string, count int) {
+	LogBufferDroppedTotal.WithLabelValues(reason).Add(float64(count))
+}
+
+// RecordLogBufferFlush records a batch written by the log write buffer.
+func RecordLogBufferFlush(err error) {
+	if err != nil {
+		LogBufferFlushesTotal.WithLabelValues("error").Inc()
+		return
+	}
+	LogBufferFlushesTotal.WithLabelValues("success").Inc()
+}
+
+// RecordCalculationJob records a calculation job that stopped running.
+// status is "completed", "failed" or "released" when it went back to the queue.
+func RecordCalculationJob(status string, duration time.Duration) {
+	CalculationJobsTotal.WithLabelValues(status).Inc()
+	CalculationJobDuration.Observe(duration.Seconds())
+}
+
+// SetCircuitBreakerState updates a circuit breaker's state gauge.
+func SetCircuitBreakerState(name string, state int) {
+	CircuitBreakerState.WithLabelValues(name).Set(float64(state))
+}
+
 // UpdateCacheMetrics updates cache size and capacity metrics.
 func UpdateCacheMetrics(size, capacity int) {
 	CacheSize.Set(float64(size))
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,6 +52,15 @@ func TestPrometheusMiddleware(t *testing.T) {
 	}
 }
 
+func TestRecordGRPCRequest(t *testing.T) {
+	method := "/pack.v1.PackService/CalculatePacks"
+	before := testutil.ToFloat64(GRPCRequestTotal.WithLabelValues(method, "OK"))
+
+	RecordGRPCRequest(method, "OK", 3*time.Millisecond)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(GRPCRequestTotal.WithLabelValues(method, "OK")))
+}
+
 func TestRecordPackCalculation(t *testing.T) {
 	RecordPackCalculation(100*time.Millisecond, "success")
 	RecordPackCalculation(50*time.Millisecond, "error")
@@ -58,6 +68,121 @@ func TestRecordPackCalculation(t *testing.T) {
 	assert.True(t, true)
 }
 
+func TestRecordPackResult(t *testing.T) {
+	RecordPackResult(251, 1, time.Millisecond)
+	RecordPackResult(5_000_000, 1000, time.Second)
+
+	// One series per order size bucket
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(PackCalculationDurationByOrderSize), 2)
+	assert.Equal(t, 1, testutil.CollectAndCount(PacksPerResult))
+}
+
+func TestOrderSizeBucket(t *testing.T) {
+	tests := map[int]string{
+		1:         "up_to_1k",
+		1_000:     "up_to_1k",
+		1_001:     "up_to_10k",
+		100_000:   "up_to_100k",
+		1_000_000: "up_to_1m",
+		1_000_001: "over_1m",
+	}
+	for items, bucket := range tests {
+		assert.Equal(t, bucket, OrderSizeBucket(items), items)
+	}
+}
+
+func TestRecordAuthMetrics(t *testing.T) {
+	logins := testutil.ToFloat64(LoginsTotal.WithLabelValues("invalid_credentials"))
+	refreshes := testutil.ToFloat64(TokenRefreshesTotal.WithLabelValues("success"))
+	rejections := testutil.ToFloat64(RateLimitRejectionsTotal.WithLabelValues("api", "ip"))
+
+	RecordLogin("invalid_credentials")
+	RecordTokenRefresh("success")
+	RecordRateLimitRejection("api", "ip")
+
+	assert.Equal(t, logins+1, testutil.ToFloat64(LoginsTotal.WithLabelValues("invalid_credentials")))
+	assert.Equal(t, refreshes+1, testutil.ToFloat64(TokenRefreshesTotal.WithLabelValues("success")))
+	assert.Equal(t, rejections+1, testutil.ToFloat64(RateLimitRejectionsTotal.WithLabelValues("api", "ip")))
+}
+
+func TestLegacyRefreshTokenMetrics(t *testing.T) {
+	hashed := testutil.ToFloat64(LegacyRefreshTokensMigratedTotal.WithLabelValues("hashed"))
+	reads := testutil.ToFloat64(LegacyRefreshTokenReadsTotal)
+
+	SetLegacyRefreshTokensRemaining(12)
+	RecordLegacyRefreshTokensMigrated("hashed", 5)
+	RecordLegacyRefreshTokenRead()
+
+	assert.Equal(t, 12.0, testutil.ToFloat64(LegacyRefreshTokensRemaining))
+	assert.Equal(t, hashed+5, testutil.ToFloat64(LegacyRefreshTokensMigratedTotal.WithLabelValues("hashed")))
+	assert.Equal(t, reads+1, testutil.ToFloat64(LegacyRefreshTokenReadsTotal))
+}
+
+func TestStaleAccountMetrics(t *testing.T) {
+	deactivated := testutil.ToFloat64(StaleAccountsDeactivatedTotal)
+
+	SetStaleAccounts(7)
+	RecordStaleAccountsDeactivated(2)
+
+	assert.Equal(t, 7.0, testutil.ToFloat64(StaleAccounts))
+	assert.Equal(t, deactivated+2, testutil.ToFloat64(StaleAccountsDeactivatedTotal))
+}
+
+func TestRecordMongoCommand(t *testing.T) {
+	RecordMongoCommand("find", "users", "success", 2*time.Millisecond)
+
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(MongoCommandDuration), 1)
+}
+
+func TestRecordMongoHedgedRead(t *testing.T) {
+	hedge := testutil.ToFloat64(MongoHedgedReadsTotal.WithLabelValues("users", "hedge"))
+	primary := testutil.ToFloat64(MongoHedgedReadsTotal.WithLabelValues("users", "primary"))
+
+	RecordMongoHedgedRead("users", true)
+	RecordMongoHedgedRead("users", false)
+	RecordMongoHedgedRead("users", false)
+
+	assert.Equal(t, hedge+1, testutil.ToFloat64(MongoHedgedReadsTotal.WithLabelValues("users", "hedge")))
+	assert.Equal(t, primary+2, testutil.ToFloat64(MongoHedgedReadsTotal.WithLabelValues("users", "primary")))
+}
+
+func TestRecordGreedyComparison(t *testing.T) {
+	match := testutil.ToFloat64(GreedyComparisonsTotal.WithLabelValues("match"))
+	differs := testutil.ToFloat64(GreedyComparisonsTotal.WithLabelValues("differs"))
+	failures := testutil.ToFloat64(GreedySanityFailuresTotal)
+
+	RecordGreedyComparison(false)
+	RecordGreedyComparison(true)
+	RecordGreedyComparison(true)
+	RecordGreedySanityFailure()
+
+	assert.Equal(t, match+1, testutil.ToFloat64(GreedyComparisonsTotal.WithLabelValues("match")))
+	assert.Equal(t, differs+2, testutil.ToFloat64(GreedyComparisonsTotal.WithLabelValues("differs")))
+	assert.Equal(t, failures+1, testutil.ToFloat64(GreedySanityFailuresTotal))
+}
+
+func TestRecordInputWarning(t *testing.T) {
+	before := testutil.ToFloat64(InputWarningsTotal.WithLabelValues("duplicate_pack_sizes"))
+
+	RecordInputWarning("duplicate_pack_sizes")
+
+	assert.Equal(t, before+1, testutil.ToFloat64(InputWarningsTotal.WithLabelValues("duplicate_pack_sizes")))
+}
+
+func TestRateLimiterMetrics(t *testing.T) {
+	RecordRateLimiterShardRequest("test", "0")
+	assert.Equal(t, 1.0, testutil.ToFloat64(RateLimiterShardRequests.WithLabelValues("test", "0")))
+
+	SetRateLimiterShardVisitors("test", "0", 42)
+	assert.Equal(t, 42.0, testutil.ToFloat64(RateLimiterShardVisitors.WithLabelValues("test", "0")))
+
+	RecordRateLimiterEviction("test", "capacity")
+	RecordRateLimiterEvictions("test", "expired", 3)
+	RecordRateLimiterEvictions("test", "expired", 0)
+	assert.Equal(t, 1.0, testutil.ToFloat64(RateLimiterEvictionsTotal.WithLabelValues("test", "capacity")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(RateLimiterEvictionsTotal.WithLabelValues("test", "expired")))
+}
+
 func TestRecordCacheOperation(t *testing.T) {
 	RecordCacheOperation("get", "hit")
 	RecordCacheOperation("get", "miss")
@@ -66,9 +191,62 @@ func TestRecordCacheOperation(t *testing.T) {
 	assert.True(t, true)
 }
 
+func TestRecordCacheBackendLatency(t *testing.T) {
+	RecordCacheBackendLatency("redis", "get", 2*time.Millisecond)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(CacheBackendLatency, "cache_backend_latency_seconds"))
+}
+
+func TestRecordCacheCompression(t *testing.T) {
+	RecordCacheCompression("zstd", 4000, 1000)
+
+	assert.Equal(t, 4000.0, testutil.ToFloat64(CacheCompressedBytesTotal.WithLabelValues("zstd", "original")))
+	assert.Equal(t, 1000.0, testutil.ToFloat64(CacheCompressedBytesTotal.WithLabelValues("zstd", "compressed")))
+}
+
 func TestUpdateCacheMetrics(t *testing.T) {
 	UpdateCacheMetrics(50, 100)
 	UpdateCacheMetrics(75, 100)
 
 	assert.True(t, true)
 }
+
+func TestWorkerPoolMetrics(t *testing.T) {
+	SetWorkerPoolSize("test", 4)
+	RecordWorkerPoolAcquire("test", time.Millisecond)
+	AddWorkerPoolQueued("test", 2)
+	AddWorkerPoolQueued("test", -1)
+	RecordWorkerPoolRejection("test", "queue_timeout")
+
+	assert.Equal(t, 4.0, testutil.ToFloat64(WorkerPoolSize.WithLabelValues("test")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(WorkerPoolBusy.WithLabelValues("test")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(WorkerPoolQueued.WithLabelValues("test")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(WorkerPoolRejectionsTotal.WithLabelValues("test", "queue_timeout")))
+
+	RecordWorkerPoolRelease("test")
+	assert.Equal(t, 0.0, testutil.ToFloat64(WorkerPoolBusy.WithLabelValues("test")))
+}
+
+func TestRecordCalculationHistoryDropped(t *testing.T) {
+	before := testutil.ToFloat64(CalculationHistoryDroppedTotal)
+	RecordCalculationHistoryDropped(3)
+	assert.Equal(t, before+3, testutil.ToFloat64(CalculationHistoryDroppedTotal))
+}
+
+func TestRecordLoginAnomaly(t *testing.T) {
+	before := testutil.ToFloat64(LoginAnomaliesTotal.WithLabelValues("new_ip"))
+	RecordLoginAnomaly("new_ip")
+	assert.Equal(t, before+1, testutil.ToFloat64(LoginAnomaliesTotal.WithLabelValues("new_ip")))
+}
+
+func TestRecordLoginLockout(t *testing.T) {
+	before := testutil.ToFloat64(LoginLockoutsTotal.WithLabelValues("account"))
+	RecordLoginLockout("account")
+	assert.Equal(t, before+1, testutil.ToFloat64(LoginLockoutsTotal.WithLabelValues("account")))
+}
+
+func TestRecordCalculationJob(t *testing.T) {
+	before := testutil.ToFloat64(CalculationJobsTotal.WithLabelValues("completed"))
+	RecordCalculationJob("completed", time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(CalculationJobsTotal.WithLabelValues("completed")))
+}
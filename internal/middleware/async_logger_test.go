@@ -50,6 +50,25 @@ func (m *MockLoggingService) CountLogs(ctx context.Context, opts model.LogQueryO
 	return count, err
 }
 
+func (m *MockLoggingService) ExportLogs(ctx context.Context, opts model.LogQueryOptions, fn func(model.LogEntry) error) error {
+	args := m.Called(ctx, opts, fn)
+	return args.Error(0)
+}
+
+func (m *MockLoggingService) CalculationsSinceConfigVersion(ctx context.Context, sinceVersion int) ([]model.ConfigVersionImpact, error) {
+	args := m.Called(ctx, sinceVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	impacts := args.Get(0).([]model.ConfigVersionImpact) //nolint:errcheck // args.Get doesn't return error
+	return impacts, args.Error(1)
+}
+
+func (m *MockLoggingService) PurgeLogs(ctx context.Context, retention model.LogRetention) (int64, error) {
+	args := m.Called(ctx, retention)
+	return args.Get(0).(int64), args.Error(1) //nolint:errcheck // args.Get doesn't return error
+}
+
 func TestDefaultAsyncLoggerConfig(t *testing.T) {
 	cfg := DefaultAsyncLoggerConfig()
 
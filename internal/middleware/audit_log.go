@@ -9,6 +9,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // AuditLog logs a user action for audit purposes.
@@ -18,18 +19,20 @@ func AuditLog(loggingService service.LoggingService, c *gin.Context, actionType
 		return
 	}
 
-	requestID := GetRequestID(c)
+	ids := RequestIDs(c)
 	entry := &model.LogEntry{
-		Timestamp:  time.Now(),
-		Level:      "info",
-		Message:    message,
-		RequestID:  requestID,
-		Method:     c.Request.Method,
-		Path:       c.Request.URL.Path,
-		IP:         c.ClientIP(),
-		UserAgent:  c.Request.UserAgent(),
-		ActionType: actionType,
-		Fields:     fields,
+		Timestamp:     timeutil.Now(),
+		Level:         "info",
+		Message:       message,
+		RequestID:     ids.RequestID,
+		CorrelationID: ids.CorrelationID,
+		TraceID:       ids.TraceID,
+		Method:        c.Request.Method,
+		Path:          c.Request.URL.Path,
+		IP:            c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		ActionType:    actionType,
+		Fields:        fields,
 	}
 
 	// Capture user information if available
@@ -58,19 +61,21 @@ func AuditLogError(loggingService service.LoggingService, c *gin.Context, action
 		return
 	}
 
-	requestID := GetRequestID(c)
+	ids := RequestIDs(c)
 	entry := &model.LogEntry{
-		Timestamp:  time.Now(),
-		Level:      "error",
-		Message:    message,
-		RequestID:  requestID,
-		Method:     c.Request.Method,
-		Path:       c.Request.URL.Path,
-		IP:         c.ClientIP(),
-		UserAgent:  c.Request.UserAgent(),
-		ActionType: actionType,
-		Error:      err.Error(),
-		Fields:     fields,
+		Timestamp:     timeutil.Now(),
+		Level:         "error",
+		Message:       message,
+		RequestID:     ids.RequestID,
+		CorrelationID: ids.CorrelationID,
+		TraceID:       ids.TraceID,
+		Method:        c.Request.Method,
+		Path:          c.Request.URL.Path,
+		IP:            c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		ActionType:    actionType,
+		Error:         err.Error(),
+		Fields:        fields,
 	}
 
 	// Capture user information if available
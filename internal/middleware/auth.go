@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guttosm/pack-service/internal/domain/dto"
@@ -13,40 +16,97 @@ const (
 	APIKeyHeader = "X-API-Key"
 	// APIKeyQuery is the query parameter name for API key authentication.
 	APIKeyQuery = "api_key"
+	// APIKeyIDContextKey holds the fingerprint of the API key that authenticated the request.
+	APIKeyIDContextKey = "api_key_id"
 )
 
-// APIKeyAuth returns a middleware that validates API keys.
-// It checks the X-API-Key header first, then falls back to api_key query parameter.
-// If validKeys is nil or empty, authentication is disabled.
-func APIKeyAuth(validKeys map[string]bool) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if len(validKeys) == 0 {
-			c.Next()
-			return
+// APIKeyFingerprint returns a short, non-reversible identifier for an API key,
+// safe to log and report in place of the key itself.
+func APIKeyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:4])
+}
+
+// APIKeySet is a set of valid API keys that can be replaced at runtime. It is
+// safe for concurrent use.
+type APIKeySet struct {
+	keys atomic.Pointer[map[string]bool]
+}
+
+// NewAPIKeySet creates a set of the keys enabled in keys.
+func NewAPIKeySet(keys map[string]bool) *APIKeySet {
+	s := &APIKeySet{}
+	s.Replace(keys)
+	return s
+}
+
+// Replace makes the keys enabled in keys the valid ones.
+func (s *APIKeySet) Replace(keys map[string]bool) {
+	valid := make(map[string]bool, len(keys))
+	for key, enabled := range keys {
+		if enabled {
+			valid[key] = true
 		}
+	}
+	s.keys.Store(&valid)
+}
+
+// Valid reports whether key is in the set.
+func (s *APIKeySet) Valid(key string) bool {
+	return (*s.keys.Load())[key]
+}
 
+// Len returns the number of keys in the set.
+func (s *APIKeySet) Len() int {
+	return len(*s.keys.Load())
+}
+
+// Require returns a middleware that validates API keys against the set, like
+// RequireAPIKey.
+func (s *APIKeySet) Require() gin.HandlerFunc {
+	return func(c *gin.Context) {
 		key := c.GetHeader(APIKeyHeader)
 		if key == "" {
 			key = c.Query(APIKeyQuery)
 		}
 
-		locale := i18n.GetLocale(c)
-		requestID := GetRequestID(c)
+		ids := RequestIDs(c)
 
 		if key == "" {
-			errorResp := dto.NewError(dto.ErrCodeUnauthorized, i18n.GetTranslator().Translate(i18n.ErrKeyAPIKeyRequired, locale)).
-				WithRequestID(requestID)
+			errorResp := dto.NewError(dto.ErrCodeUnauthorized, i18n.Message(c, i18n.ErrKeyAPIKeyRequired)).
+				WithIDs(ids)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
 			return
 		}
 
-		if !validKeys[key] {
-			errorResp := dto.NewError(dto.ErrCodeUnauthorized, i18n.GetTranslator().Translate(i18n.ErrKeyInvalidAPIKey, locale)).
-				WithRequestID(requestID)
+		if !s.Valid(key) {
+			errorResp := dto.NewError(dto.ErrCodeUnauthorized, i18n.Message(c, i18n.ErrKeyInvalidAPIKey)).
+				WithIDs(ids)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
 			return
 		}
 
+		c.Set(APIKeyIDContextKey, APIKeyFingerprint(key))
 		c.Next()
 	}
 }
+
+// APIKeyAuth returns a middleware that validates API keys.
+// It checks the X-API-Key header first, then falls back to api_key query parameter.
+// If validKeys is nil or empty, authentication is disabled.
+func APIKeyAuth(validKeys map[string]bool) gin.HandlerFunc {
+	requireKey := RequireAPIKey(validKeys)
+	return func(c *gin.Context) {
+		if len(validKeys) == 0 {
+			c.Next()
+			return
+		}
+		requireKey(c)
+	}
+}
+
+// RequireAPIKey is like APIKeyAuth, but fails closed: if validKeys is nil or
+// empty, every request is rejected.
+func RequireAPIKey(validKeys map[string]bool) gin.HandlerFunc {
+	return NewAPIKeySet(validKeys).Require()
+}
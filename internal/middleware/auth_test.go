@@ -84,3 +84,75 @@ func TestAPIKeyAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIKeyAuth_SetsKeyFingerprint(t *testing.T) {
+	router := gin.New()
+	router.Use(APIKeyAuth(map[string]bool{"valid-key-123": true}))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(APIKeyIDContextKey))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(APIKeyHeader, "valid-key-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, APIKeyFingerprint("valid-key-123"), w.Body.String())
+	assert.Len(t, w.Body.String(), 8)
+	assert.NotContains(t, w.Body.String(), "valid-key")
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		validKeys      map[string]bool
+		key            string
+		expectedStatus int
+	}{
+		{name: "valid key", validKeys: map[string]bool{"valid-key": true}, key: "valid-key", expectedStatus: http.StatusOK},
+		{name: "invalid key", validKeys: map[string]bool{"valid-key": true}, key: "other-key", expectedStatus: http.StatusUnauthorized},
+		{name: "no keys configured rejects every request", validKeys: nil, key: "valid-key", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequireAPIKey(tt.validKeys))
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set(APIKeyHeader, tt.key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAPIKeySet_Replace(t *testing.T) {
+	keys := NewAPIKeySet(map[string]bool{"old-key": true, "disabled-key": false})
+	assert.Equal(t, 1, keys.Len())
+
+	router := gin.New()
+	router.Use(keys.Require())
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	status := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(APIKeyHeader, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, status("old-key"))
+	assert.Equal(t, http.StatusUnauthorized, status("disabled-key"))
+
+	keys.Replace(map[string]bool{"new-key": true})
+	assert.Equal(t, http.StatusUnauthorized, status("old-key"))
+	assert.Equal(t, http.StatusOK, status("new-key"))
+}
@@ -28,23 +28,22 @@ type AuthorizationConfig struct {
 // This middleware must be used after JWTAuth middleware.
 func RequireAuthorization(cfg AuthorizationConfig, roleService service.RoleService, permissionService service.PermissionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		locale := i18n.GetLocale(c)
-		requestID := GetRequestID(c)
+		ids := RequestIDs(c)
 
 		claimsInterface, exists := c.Get("user_claims")
 		if !exists {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyUnauthorized, locale)
+			message := i18n.Message(c, i18n.ErrKeyUnauthorized)
 			errorResp := dto.NewError(dto.ErrCodeUnauthorized, message).
-				WithRequestID(requestID)
+				WithIDs(ids)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
 			return
 		}
 
 		claims, ok := claimsInterface.(*dto.Claims)
 		if !ok {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyUnauthorized, locale)
+			message := i18n.Message(c, i18n.ErrKeyUnauthorized)
 			errorResp := dto.NewError(dto.ErrCodeUnauthorized, message).
-				WithRequestID(requestID)
+				WithIDs(ids)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
 			return
 		}
@@ -63,9 +62,9 @@ func RequireAuthorization(cfg AuthorizationConfig, roleService service.RoleServi
 				}
 			}
 			if !hasRequiredRole {
-				message := i18n.GetTranslator().Translate(i18n.ErrKeyForbidden, locale)
+				message := i18n.Message(c, i18n.ErrKeyForbidden)
 				errorResp := dto.NewError(dto.ErrCodeForbidden, message).
-					WithRequestID(requestID)
+					WithIDs(ids)
 				c.AbortWithStatusJSON(http.StatusForbidden, errorResp)
 				return
 			}
@@ -80,6 +79,9 @@ func RequireAuthorization(cfg AuthorizationConfig, roleService service.RoleServi
 						continue
 					}
 					role, err := roleService.FindByID(c.Request.Context(), roleID)
+					if abortCircuitOpen(c, err) {
+						return
+					}
 					if err == nil && role != nil {
 						userRoles = append(userRoles, role)
 					}
@@ -99,9 +101,9 @@ func RequireAuthorization(cfg AuthorizationConfig, roleService service.RoleServi
 				// User must have ALL required permissions
 				for _, requiredPerm := range cfg.RequiredPermissions {
 					if !userPermissionIDs[requiredPerm] {
-						message := i18n.GetTranslator().Translate(i18n.ErrKeyForbidden, locale)
+						message := i18n.Message(c, i18n.ErrKeyForbidden)
 						errorResp := dto.NewError(dto.ErrCodeForbidden, message).
-							WithRequestID(requestID)
+							WithIDs(ids)
 						c.AbortWithStatusJSON(http.StatusForbidden, errorResp)
 						return
 					}
@@ -116,9 +118,9 @@ func RequireAuthorization(cfg AuthorizationConfig, roleService service.RoleServi
 					}
 				}
 				if !hasPermission {
-					message := i18n.GetTranslator().Translate(i18n.ErrKeyForbidden, locale)
+					message := i18n.Message(c, i18n.ErrKeyForbidden)
 					errorResp := dto.NewError(dto.ErrCodeForbidden, message).
-						WithRequestID(requestID)
+						WithIDs(ids)
 					c.AbortWithStatusJSON(http.StatusForbidden, errorResp)
 					return
 				}
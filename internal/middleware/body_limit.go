@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+// BodyLimit returns middleware that rejects request bodies larger than
+// maxBytes with 413 on the given paths. A path ending in "/" matches every
+// path under it; any other path must match exactly, so limiting
+// /api/calculate leaves the larger /api/calculate/batch bodies alone.
+//
+// Bodies declaring a larger Content-Length are rejected without being read.
+// Others, such as chunked bodies, are read up to the limit and handed on in
+// memory, so handlers still see the whole body.
+func BodyLimit(maxBytes int64, paths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody || !limitedPath(c.Request.URL.Path, paths) {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			rejectOversizedBody(c)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		_ = c.Request.Body.Close()
+		if err != nil {
+			errorResp := dto.NewError(dto.ErrCodeInvalidRequest, i18n.Message(c, i18n.ErrKeyInvalidRequestBody)).
+				WithIDs(RequestIDs(c))
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResp)
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			rejectOversizedBody(c)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// limitedPath reports whether path is one of paths or under one ending in "/".
+func limitedPath(path string, paths []string) bool {
+	for _, p := range paths {
+		if path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectOversizedBody aborts with 413. The connection is closed so the
+// server does not have to read the rest of the body to reuse it.
+func rejectOversizedBody(c *gin.Context) {
+	c.Header("Connection", "close")
+	errorResp := dto.NewError(dto.ErrCodePayloadTooLarge, i18n.Message(c, i18n.ErrKeyPayloadTooLarge)).
+		WithIDs(RequestIDs(c))
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, errorResp)
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(BodyLimit(16, "/api/calculate", "/api/auth/"))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	}
+	router.POST("/api/calculate", echo)
+	router.POST("/api/calculate/batch", echo)
+	router.POST("/api/auth/login", echo)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		chunked    bool
+		wantStatus int
+	}{
+		{name: "within the limit", path: "/api/calculate", body: strings.Repeat("x", 16), wantStatus: http.StatusOK},
+		{name: "over the limit", path: "/api/calculate", body: strings.Repeat("x", 17), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked over the limit", path: "/api/calculate", body: strings.Repeat("x", 17), chunked: true, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "under a prefix", path: "/api/auth/login", body: strings.Repeat("x", 17), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "other path", path: "/api/calculate/batch", body: strings.Repeat("x", 17), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.body, w.Body.String(), "the handler sees the whole body")
+			} else {
+				assert.Contains(t, w.Body.String(), "payload_too_large")
+				assert.Equal(t, "close", w.Header().Get("Connection"))
+			}
+		})
+	}
+}
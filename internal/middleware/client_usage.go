@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// ClientVersionHeader lets SDKs report their name and version explicitly,
+// e.g. "pack-sdk-go/1.4.2", instead of relying on the User-Agent.
+const ClientVersionHeader = "X-Client-Version"
+
+// ClientUsage returns a middleware that records the user agent and client
+// version of each authenticated request. It runs after the handler so the
+// API key or JWT middleware further down the chain has identified the caller;
+// anonymous requests are not recorded.
+func ClientUsage(recorder service.ClientUsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if client := usageClient(c); client != "" {
+			recorder.Record(client, c.Request.UserAgent(), c.GetHeader(ClientVersionHeader))
+		}
+	}
+}
+
+// usageClient identifies the caller as "api_key:<fingerprint>" or "user:<id>".
+func usageClient(c *gin.Context) string {
+	if keyID := c.GetString(APIKeyIDContextKey); keyID != "" {
+		return "api_key:" + keyID
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(primitive.ObjectID); ok && !id.IsZero() {
+			return "user:" + id.Hex()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/mocks"
+)
+
+func TestClientUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name       string
+		setup      func(*gin.Context)
+		wantClient string
+	}{
+		{
+			name:       "records API key clients",
+			setup:      func(c *gin.Context) { c.Set(APIKeyIDContextKey, "0a1b2c3d") },
+			wantClient: "api_key:0a1b2c3d",
+		},
+		{
+			name:       "records authenticated users",
+			setup:      func(c *gin.Context) { c.Set("user_id", userID) },
+			wantClient: "user:" + userID.Hex(),
+		},
+		{
+			name:  "skips anonymous requests",
+			setup: func(c *gin.Context) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := mocks.NewMockClientUsageService(t)
+			if tt.wantClient != "" {
+				recorder.On("Record", tt.wantClient, "pack-sdk-go/1.4.2", "1.4.2").Once()
+			}
+
+			router := gin.New()
+			router.Use(ClientUsage(recorder))
+			// The caller is identified by auth middleware after ClientUsage runs
+			router.GET("/test", func(c *gin.Context) {
+				tt.setup(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("User-Agent", "pack-sdk-go/1.4.2")
+			req.Header.Set(ClientVersionHeader, "1.4.2")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
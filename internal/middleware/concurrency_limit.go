@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/workerpool"
+)
+
+// concurrencyRetryAfter is the Retry-After sent with requests turned away
+// because the pool was full; heavy requests finish within seconds.
+const concurrencyRetryAfter = "1"
+
+// ConcurrencyLimit returns middleware that runs each request on a worker of
+// pool, so the routes it guards cannot take more than the pool's share of
+// the CPU however many arrive at once. Requests that get no worker before
+// the pool's queue timeout, or find its queue full, are rejected with 503.
+func ConcurrencyLimit(pool *workerpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, err := pool.Acquire(c.Request.Context())
+		if err != nil {
+			if !errors.Is(err, workerpool.ErrSaturated) {
+				// The client went away while queued
+				c.Abort()
+				return
+			}
+			c.Header("Retry-After", concurrencyRetryAfter)
+			errorResp := dto.NewError(dto.ErrCodeServiceUnavailable, i18n.Message(c, i18n.ErrKeyServiceBusy)).
+				WithIDs(RequestIDs(c))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, errorResp)
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
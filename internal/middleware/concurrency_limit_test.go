@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/workerpool"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	pool := workerpool.New(workerpool.Config{Name: "test_heavy", Workers: 1, QueueTimeout: 20 * time.Millisecond})
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	router := gin.New()
+	router.Use(ConcurrencyLimit(pool))
+	router.POST("/api/calculate/batch", func(c *gin.Context) {
+		if c.Query("hold") != "" {
+			close(started)
+			<-unblock
+		}
+		c.Status(http.StatusOK)
+	})
+
+	post := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/calculate/batch"+query, nil))
+		return w
+	}
+
+	// Hold the only worker
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post("?hold=1") }()
+	<-started
+
+	w := post("")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, concurrencyRetryAfter, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "service_unavailable")
+
+	close(unblock)
+	require.Equal(t, http.StatusOK, (<-done).Code)
+	assert.Equal(t, http.StatusOK, post("").Code, "the worker is released after the request")
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// drainRetryAfter is the Retry-After sent with requests rejected while
+// draining; by then the load balancer should route the retry elsewhere.
+const drainRetryAfter = "1"
+
+// Drainer tracks whether the instance is draining: taking itself out of the
+// load balancer ahead of termination. While draining, readiness fails and
+// new requests are turned away, but requests already running finish.
+type Drainer struct {
+	mu    sync.RWMutex
+	since time.Time
+}
+
+// NewDrainer creates a Drainer that is not draining.
+func NewDrainer() *Drainer {
+	return &Drainer{}
+}
+
+// Drain starts draining and returns when it started. Draining again keeps
+// the original start.
+func (d *Drainer) Drain() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.since.IsZero() {
+		d.since = time.Now().UTC()
+		log.Warn().Msg("Instance draining: readiness failing and new requests rejected")
+	}
+	return d.since
+}
+
+// Resume stops draining, for a deployment that was called off.
+func (d *Drainer) Resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.since.IsZero() {
+		d.since = time.Time{}
+		log.Info().Msg("Instance resumed taking requests")
+	}
+}
+
+// Draining reports whether the instance is draining and since when.
+func (d *Drainer) Draining() (bool, time.Time) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return !d.since.IsZero(), d.since
+}
+
+// Reject returns middleware that, while draining, answers every request with
+// "Connection: close" so clients reconnect through the load balancer, and
+// rejects requests with 503 unless their path starts with one of exempt
+// (health checks, and the drain endpoint itself so draining can be undone).
+func (d *Drainer) Reject(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if draining, _ := d.Draining(); !draining {
+			c.Next()
+			return
+		}
+
+		c.Header("Connection", "close")
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		c.Header("Retry-After", drainRetryAfter)
+		errorResp := dto.NewError(dto.ErrCodeServiceUnavailable, i18n.Message(c, i18n.ErrKeyDraining)).
+			WithIDs(RequestIDs(c))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, errorResp)
+	}
+}
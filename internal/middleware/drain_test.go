@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	drainer := NewDrainer()
+
+	router := gin.New()
+	router.Use(drainer.Reject("/healthz"))
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/calculate", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/calculate")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Connection"))
+
+	since := drainer.Drain()
+	assert.Equal(t, since, drainer.Drain(), "draining again keeps the start")
+	draining, got := drainer.Draining()
+	assert.True(t, draining)
+	assert.Equal(t, since, got)
+
+	w = get("/api/calculate")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+	assert.Equal(t, drainRetryAfter, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "service_unavailable")
+
+	w = get("/healthz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+
+	drainer.Resume()
+	draining, _ = drainer.Draining()
+	assert.False(t, draining)
+	assert.Equal(t, http.StatusOK, get("/api/calculate").Code)
+}
@@ -18,7 +18,6 @@ func ErrorHandler() gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
 			requestID := GetRequestID(c)
-			locale := i18n.GetLocale(c)
 			
 			log := logger.Logger()
 			log.Error().
@@ -29,9 +28,9 @@ func ErrorHandler() gin.HandlerFunc {
 				Msg("Request error")
 
 			if !c.Writer.Written() {
-				message := i18n.GetTranslator().Translate(i18n.ErrKeyInternalError, locale)
+				message := i18n.Message(c, i18n.ErrKeyInternalError)
 				errorResp := dto.NewError(dto.ErrCodeInternal, message).
-					WithRequestID(requestID)
+					WithIDs(RequestIDs(c))
 				c.JSON(http.StatusInternalServerError, errorResp)
 			}
 		}
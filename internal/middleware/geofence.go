@@ -0,0 +1,359 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultCountryHeader is the header the edge proxy or CDN sets to the ISO
+// 3166-1 alpha-2 country of the client IP.
+const DefaultCountryHeader = "CF-IPCountry"
+
+// ErrInvalidGeoFenceRule is returned when a geofence rule is not a CIDR range
+// or a two-letter country code.
+var ErrInvalidGeoFenceRule = errors.New("invalid geofence rule")
+
+// GeoFenceRules lists where requests may come from. A request is allowed when
+// its client IP is in one of AllowedCIDRs or its country is one of
+// AllowedCountries; with both empty, every request is allowed.
+type GeoFenceRules struct {
+	AllowedCIDRs     []string `json:"allowed_cidrs" example:"10.0.0.0/8,2001:db8::/32"`
+	AllowedCountries []string `json:"allowed_countries" example:"NL,DE"`
+} // @name GeoFenceRules
+
+// Enabled reports whether the rules restrict any request.
+func (r GeoFenceRules) Enabled() bool {
+	return len(r.AllowedCIDRs) > 0 || len(r.AllowedCountries) > 0
+}
+
+// GeoFence restricts requests to allowed CIDR ranges and countries. The
+// service has no GeoIP database of its own: the country is read from a header
+// set by the edge in front of it. Clients can set that header themselves, so
+// it is only read from requests arriving from a trusted proxy, and requests
+// without a country are only allowed by their IP. Rules start from the
+// configuration and can be replaced at runtime, for every client or for one
+// tenant; like route auth overrides, changes apply to this instance only and
+// are lost on restart.
+type GeoFence struct {
+	mu       sync.RWMutex
+	defaults geoFenceRuleSet
+	tenants  map[string]geoFenceRuleSet
+
+	countryHeader  string
+	trustedProxies []netip.Prefix
+	bypass         func(*gin.Context) bool
+}
+
+// geoFenceRuleSet is a set of rules with its ranges and countries parsed.
+type geoFenceRuleSet struct {
+	rules     GeoFenceRules
+	prefixes  []netip.Prefix
+	countries map[string]bool
+}
+
+// GeoFenceOption configures a GeoFence.
+type GeoFenceOption func(*GeoFence)
+
+// WithCountryHeader reads the client country from header instead of
+// DefaultCountryHeader.
+func WithCountryHeader(header string) GeoFenceOption {
+	return func(g *GeoFence) {
+		if header != "" {
+			g.countryHeader = header
+		}
+	}
+}
+
+// WithTrustedProxies trusts the edge proxies in prefixes to set the country
+// header and X-Forwarded-For. Without any, the country of every request is
+// unknown and the client IP is the peer's.
+func WithTrustedProxies(prefixes ...netip.Prefix) GeoFenceOption {
+	return func(g *GeoFence) {
+		g.trustedProxies = append(g.trustedProxies, prefixes...)
+	}
+}
+
+// WithGeoFenceBypass lets requests for which bypass returns true through the
+// fence, so that global admins can still reach the service when the rules
+// lock everyone out. Bypassed requests are audited.
+func WithGeoFenceBypass(bypass func(*gin.Context) bool) GeoFenceOption {
+	return func(g *GeoFence) {
+		g.bypass = bypass
+	}
+}
+
+// NewGeoFence creates a GeoFence enforcing rules.
+func NewGeoFence(rules GeoFenceRules, opts ...GeoFenceOption) (*GeoFence, error) {
+	g := &GeoFence{countryHeader: DefaultCountryHeader, tenants: make(map[string]geoFenceRuleSet)}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if err := g.SetRules(rules); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Rules returns the rules being enforced for clients without rules of their
+// own.
+func (g *GeoFence) Rules() GeoFenceRules {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.defaults.clone()
+}
+
+// SetRules replaces the rules of clients without rules of their own. It
+// returns ErrInvalidGeoFenceRule and keeps the current rules when a CIDR
+// range or country code does not parse. Country codes are stored upper case
+// and CIDR ranges in canonical form.
+func (g *GeoFence) SetRules(rules GeoFenceRules) error {
+	set, err := parseGeoFenceRules(rules)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.defaults = set
+	return nil
+}
+
+// TenantRules returns the rules of every tenant that has its own, by tenant.
+// Tenants are API keys, identified by their fingerprint.
+func (g *GeoFence) TenantRules() map[string]GeoFenceRules {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	rules := make(map[string]GeoFenceRules, len(g.tenants))
+	for tenant, set := range g.tenants {
+		rules[tenant] = set.clone()
+	}
+	return rules
+}
+
+// SetTenantRules replaces the rules of tenant, which then apply to its
+// requests instead of the rules of other clients. Invalid rules are rejected
+// like SetRules rejects them.
+func (g *GeoFence) SetTenantRules(tenant string, rules GeoFenceRules) error {
+	set, err := parseGeoFenceRules(rules)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.tenants[tenant] = set
+	return nil
+}
+
+// DeleteTenantRules removes the rules of tenant, so the rules of other
+// clients apply to it again. It reports whether tenant had rules.
+func (g *GeoFence) DeleteTenantRules(tenant string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.tenants[tenant]
+	delete(g.tenants, tenant)
+	return ok
+}
+
+// parseGeoFenceRules validates and normalizes rules.
+func parseGeoFenceRules(rules GeoFenceRules) (geoFenceRuleSet, error) {
+	set := geoFenceRuleSet{
+		rules: GeoFenceRules{
+			AllowedCIDRs:     make([]string, 0, len(rules.AllowedCIDRs)),
+			AllowedCountries: make([]string, 0, len(rules.AllowedCountries)),
+		},
+		prefixes:  make([]netip.Prefix, 0, len(rules.AllowedCIDRs)),
+		countries: make(map[string]bool, len(rules.AllowedCountries)),
+	}
+	for _, cidr := range rules.AllowedCIDRs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return geoFenceRuleSet{}, fmt.Errorf("%w: %q is not a CIDR range", ErrInvalidGeoFenceRule, cidr)
+		}
+		prefix = prefix.Masked()
+		set.prefixes = append(set.prefixes, prefix)
+		set.rules.AllowedCIDRs = append(set.rules.AllowedCIDRs, prefix.String())
+	}
+	for _, country := range rules.AllowedCountries {
+		code := strings.ToUpper(strings.TrimSpace(country))
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return geoFenceRuleSet{}, fmt.Errorf("%w: %q is not a two-letter country code", ErrInvalidGeoFenceRule, country)
+		}
+		set.countries[code] = true
+		set.rules.AllowedCountries = append(set.rules.AllowedCountries, code)
+	}
+	return set, nil
+}
+
+// clone returns a copy of the rules of s.
+func (s geoFenceRuleSet) clone() GeoFenceRules {
+	return GeoFenceRules{
+		AllowedCIDRs:     slices.Clone(s.rules.AllowedCIDRs),
+		AllowedCountries: slices.Clone(s.rules.AllowedCountries),
+	}
+}
+
+// Allowed reports whether a request of tenant from ip in country passes the
+// rules of tenant, or the rules of every client when tenant is empty or has
+// none. country may be empty when the edge did not resolve it.
+func (g *GeoFence) Allowed(tenant, ip, country string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	set := g.defaults
+	if tenantSet, ok := g.tenants[tenant]; ok && tenant != "" {
+		set = tenantSet
+	}
+	if !set.rules.Enabled() {
+		return true
+	}
+	if country != "" && set.countries[strings.ToUpper(country)] {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return containsAddr(set.prefixes, addr)
+}
+
+// clientLocation returns the client IP and country of the request in c. They
+// come from X-Forwarded-For and the country header only when the peer is a
+// trusted proxy, skipping the trusted proxies that forwarded the request;
+// otherwise the client is the peer and its country is unknown.
+func (g *GeoFence) clientLocation(c *gin.Context) (ip, country string) {
+	ip = c.RemoteIP()
+	if !g.trustedProxy(ip) {
+		return ip, ""
+	}
+	hops := strings.Split(c.GetHeader("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !g.trustedProxy(hop) {
+			break
+		}
+	}
+	return ip, c.GetHeader(g.countryHeader)
+}
+
+// trustedProxy reports whether ip is one of the trusted proxies.
+func (g *GeoFence) trustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	return err == nil && containsAddr(g.trustedProxies, addr)
+}
+
+// containsAddr reports whether addr is in one of prefixes.
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Enforce returns middleware rejecting requests the rules do not allow with
+// 403, unless their path starts with one of exempt (health checks and
+// metrics, which the infrastructure reaches from its own network). The rules
+// of the request's tenant apply when it has its own. Blocked and bypassed
+// requests are recorded in the audit log.
+func (g *GeoFence) Enforce(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip, country := g.clientLocation(c)
+		tenant := i18n.GetTenant(c)
+		if g.Allowed(tenant, ip, country) {
+			c.Next()
+			return
+		}
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		fields := map[string]interface{}{"country": country}
+		if tenant != "" {
+			fields["tenant"] = tenant
+		}
+		if g.bypass != nil && g.bypass(c) {
+			metrics.RecordGeoFenceRequest("bypassed")
+			log.Warn().Str("ip", ip).Str("country", country).Str("path", c.Request.URL.Path).Msg("Geofence bypassed by global admin")
+			geoFenceAudit(c, "geofence_bypassed", "Geofence bypassed by global admin", fields)
+			c.Next()
+			return
+		}
+
+		metrics.RecordGeoFenceRequest("blocked")
+		log.Info().Str("ip", ip).Str("country", country).Str("tenant", tenant).Str("path", c.Request.URL.Path).Msg("Request blocked by geofence")
+		geoFenceAudit(c, "geofence_blocked", "Request blocked by geofence", fields)
+		errorResp := dto.NewError(dto.ErrCodeForbidden, i18n.Message(c, i18n.ErrKeyGeoBlocked)).
+			WithIDs(RequestIDs(c))
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResp)
+	}
+}
+
+// GlobalAdminBypass returns a geofence bypass for requests carrying a valid
+// access token of a user holding the system:write permission. The token is
+// validated as JWTAuth validates it with opts, which must be the options of
+// the JWTAuth middleware: DPoP-bound tokens need their proof. The routes
+// reuse the validated claims rather than checking the token and its proof
+// again.
+func GlobalAdminBypass(authService service.AuthService, roleService service.RoleService, permissionService service.PermissionService, opts ...JWTAuthOption) func(*gin.Context) bool {
+	cfg := &jwtAuthConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *gin.Context) bool {
+		claims, err := cfg.authenticate(c, authService)
+		if err != nil {
+			return false
+		}
+		ctx := c.Request.Context()
+		permID := permissionService.GetPermissionIDByResourceAndAction(ctx, "system", "write")
+		if permID == "" {
+			return false
+		}
+		roles, err := roleService.FindByIDs(ctx, claims.Roles)
+		if err != nil {
+			return false
+		}
+		for _, role := range roles {
+			if role != nil && slices.Contains(role.Permissions, permID) {
+				// Audit the bypass under the admin's identity
+				c.Set("user_id", claims.UserID)
+				c.Set("user_email", claims.Email)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// geoFenceAudit records a geofence decision in the audit log, when the
+// request carries a logging service.
+func geoFenceAudit(c *gin.Context, action, message string, fields map[string]interface{}) {
+	if loggingService, exists := c.Get("logging_service"); exists {
+		if ls, ok := loggingService.(service.LoggingService); ok {
+			AuditLog(ls, c, action, message, fields)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+//go:build !integration
+
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGeoFence_Allowed(t *testing.T) {
+	fence, err := NewGeoFence(GeoFenceRules{
+		AllowedCIDRs:     []string{"10.0.0.0/8", "2001:db8::/32"},
+		AllowedCountries: []string{"nl", " DE "},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		ip      string
+		country string
+		want    bool
+	}{
+		{name: "allowed IPv4 range", ip: "10.1.2.3", want: true},
+		{name: "allowed IPv6 range", ip: "2001:db8::1", want: true},
+		{name: "IPv4-mapped IPv6 address", ip: "::ffff:10.1.2.3", want: true},
+		{name: "allowed country", ip: "203.0.113.7", country: "NL", want: true},
+		{name: "country header is case-insensitive", ip: "203.0.113.7", country: "de", want: true},
+		{name: "other country", ip: "203.0.113.7", country: "US", want: false},
+		{name: "unknown country outside the ranges", ip: "203.0.113.7", want: false},
+		{name: "unparseable IP", ip: "not-an-ip", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fence.Allowed("", tt.ip, tt.country))
+		})
+	}
+
+	t.Run("empty rules allow everything", func(t *testing.T) {
+		open, err := NewGeoFence(GeoFenceRules{})
+		require.NoError(t, err)
+		assert.True(t, open.Allowed("", "203.0.113.7", "US"))
+	})
+}
+
+func TestGeoFence_SetRules(t *testing.T) {
+	fence, err := NewGeoFence(GeoFenceRules{AllowedCountries: []string{"NL"}})
+	require.NoError(t, err)
+
+	require.NoError(t, fence.SetRules(GeoFenceRules{AllowedCIDRs: []string{"192.168.1.77/24"}, AllowedCountries: []string{"be"}}))
+	assert.Equal(t, GeoFenceRules{AllowedCIDRs: []string{"192.168.1.0/24"}, AllowedCountries: []string{"BE"}}, fence.Rules())
+
+	for _, rules := range []GeoFenceRules{
+		{AllowedCIDRs: []string{"10.0.0.0/33"}},
+		{AllowedCIDRs: []string{"10.0.0.1"}},
+		{AllowedCountries: []string{"NLD"}},
+		{AllowedCountries: []string{"1A"}},
+	} {
+		err := fence.SetRules(rules)
+		assert.ErrorIs(t, err, ErrInvalidGeoFenceRule, "%+v", rules)
+	}
+	assert.Equal(t, []string{"BE"}, fence.Rules().AllowedCountries, "invalid rules keep the current ones")
+
+	_, err = NewGeoFence(GeoFenceRules{AllowedCountries: []string{"Netherlands"}})
+	assert.ErrorIs(t, err, ErrInvalidGeoFenceRule)
+}
+
+func TestGeoFence_TenantRules(t *testing.T) {
+	fence, err := NewGeoFence(GeoFenceRules{AllowedCountries: []string{"NL"}})
+	require.NoError(t, err)
+
+	require.NoError(t, fence.SetTenantRules("tenant-a", GeoFenceRules{AllowedCountries: []string{"us"}}))
+	assert.ErrorIs(t, fence.SetTenantRules("tenant-b", GeoFenceRules{AllowedCountries: []string{"USA"}}), ErrInvalidGeoFenceRule)
+	assert.Equal(t, map[string]GeoFenceRules{"tenant-a": {AllowedCIDRs: []string{}, AllowedCountries: []string{"US"}}}, fence.TenantRules())
+
+	assert.True(t, fence.Allowed("tenant-a", "203.0.113.7", "US"))
+	assert.False(t, fence.Allowed("tenant-a", "203.0.113.7", "NL"), "tenant rules replace the others")
+	assert.True(t, fence.Allowed("tenant-c", "203.0.113.7", "NL"))
+	assert.False(t, fence.Allowed("", "203.0.113.7", "US"))
+
+	assert.True(t, fence.DeleteTenantRules("tenant-a"))
+	assert.False(t, fence.DeleteTenantRules("tenant-a"))
+	assert.False(t, fence.Allowed("tenant-a", "203.0.113.7", "US"))
+}
+
+func TestGeoFence_Enforce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(fence *GeoFence, loggingService *mocks.MockLoggingService) *gin.Engine {
+		router := gin.New()
+		router.Use(RequestID(), func(c *gin.Context) {
+			c.Set("logging_service", loggingService)
+			c.Next()
+		}, fence.Enforce("/healthz"))
+		router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+		router.GET("/api/calculate", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return router
+	}
+	// get sends a request from ip in country through the edge proxy
+	get := func(router *gin.Engine, path, ip, country string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.0.2.10:40000"
+		req.Header.Set("X-Forwarded-For", ip)
+		req.Header.Set("X-Country", country)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// expectAudit expects one audit log entry of action, closing the returned
+	// channel once it is written
+	expectAudit := func(loggingService *mocks.MockLoggingService, action string) chan struct{} {
+		audited := make(chan struct{})
+		loggingService.On("CreateLog", mock.Anything, mock.MatchedBy(func(entry *model.LogEntry) bool {
+			return entry.ActionType == action && entry.Fields["country"] == "US"
+		})).Return(nil).Once().Run(func(mock.Arguments) { close(audited) })
+		return audited
+	}
+	edgeProxy := WithTrustedProxies(netip.MustParsePrefix("192.0.2.0/24"))
+	waitForAudit := func(t *testing.T, audited chan struct{}) {
+		select {
+		case <-audited:
+		case <-time.After(time.Second):
+			t.Fatal("audit log entry not written")
+		}
+	}
+
+	t.Run("blocks and audits requests from elsewhere", func(t *testing.T) {
+		loggingService := new(mocks.MockLoggingService)
+		audited := expectAudit(loggingService, "geofence_blocked")
+		fence, err := NewGeoFence(GeoFenceRules{AllowedCountries: []string{"NL"}}, WithCountryHeader("X-Country"), edgeProxy)
+		require.NoError(t, err)
+		router := newRouter(fence, loggingService)
+
+		assert.Equal(t, http.StatusOK, get(router, "/api/calculate", "203.0.113.7", "NL").Code)
+		assert.Equal(t, http.StatusOK, get(router, "/healthz", "203.0.113.7", "US").Code)
+
+		w := get(router, "/api/calculate", "203.0.113.7", "US")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), dto.ErrCodeForbidden)
+		waitForAudit(t, audited)
+		loggingService.AssertExpectations(t)
+	})
+
+	t.Run("ignores the location headers of untrusted peers", func(t *testing.T) {
+		fence, err := NewGeoFence(GeoFenceRules{AllowedCIDRs: []string{"10.0.0.0/8"}, AllowedCountries: []string{"NL"}}, WithCountryHeader("X-Country"), edgeProxy)
+		require.NoError(t, err)
+		loggingService := new(mocks.MockLoggingService)
+		loggingService.On("CreateLog", mock.Anything, mock.Anything).Return(nil).Maybe()
+		router := newRouter(fence, loggingService)
+
+		for _, spoofed := range []map[string]string{{"X-Country": "NL"}, {"X-Forwarded-For": "10.1.2.3"}} {
+			req := httptest.NewRequest(http.MethodGet, "/api/calculate", nil)
+			req.RemoteAddr = "203.0.113.7:40000"
+			for header, value := range spoofed {
+				req.Header.Set(header, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code, "%v", spoofed)
+		}
+
+		assert.Equal(t, http.StatusForbidden, get(router, "/api/calculate", "10.1.2.3, 203.0.113.7", "").Code, "hops before the edge are set by the client")
+		assert.Equal(t, http.StatusOK, get(router, "/api/calculate", "10.1.2.3", "").Code)
+	})
+
+	t.Run("applies the rules of the tenant", func(t *testing.T) {
+		fence, err := NewGeoFence(GeoFenceRules{AllowedCountries: []string{"NL"}}, WithCountryHeader("X-Country"), edgeProxy)
+		require.NoError(t, err)
+		require.NoError(t, fence.SetTenantRules("tenant-a", GeoFenceRules{AllowedCountries: []string{"US"}}))
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(i18n.TenantContextKey, c.GetHeader("X-Tenant"))
+			c.Next()
+		}, fence.Enforce())
+		router.GET("/api/calculate", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		for _, tt := range []struct {
+			tenant, country string
+			want            int
+		}{
+			{"tenant-a", "US", http.StatusOK},
+			{"tenant-a", "NL", http.StatusForbidden},
+			{"", "NL", http.StatusOK},
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/api/calculate", nil)
+			req.RemoteAddr = "192.0.2.10:40000"
+			req.Header.Set("X-Tenant", tt.tenant)
+			req.Header.Set("X-Country", tt.country)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code, "%+v", tt)
+		}
+	})
+
+	t.Run("lets the bypass through and audits it", func(t *testing.T) {
+		loggingService := new(mocks.MockLoggingService)
+		audited := expectAudit(loggingService, "geofence_bypassed")
+		bypass := func(c *gin.Context) bool { return c.GetHeader("Authorization") == "Bearer admin" }
+		fence, err := NewGeoFence(GeoFenceRules{AllowedCountries: []string{"NL"}}, WithCountryHeader("X-Country"), edgeProxy, WithGeoFenceBypass(bypass))
+		require.NoError(t, err)
+		router := newRouter(fence, loggingService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/calculate", nil)
+		req.Header.Set("X-Country", "US")
+		req.Header.Set("Authorization", "Bearer admin")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		waitForAudit(t, audited)
+		loggingService.AssertExpectations(t)
+	})
+}
+
+func TestGlobalAdminBypass(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adminRoleID := primitive.NewObjectID()
+	userRoleID := primitive.NewObjectID()
+	writePermID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name          string
+		authorization string
+		setupMocks    func(*mocks.MockAuthService, *mocks.MockRoleService, *mocks.MockPermissionService)
+		want          bool
+	}{
+		{
+			name:          "admin token",
+			authorization: "Bearer admin-token",
+			setupMocks: func(auth *mocks.MockAuthService, roles *mocks.MockRoleService, perms *mocks.MockPermissionService) {
+				auth.On("ValidateToken", mock.Anything, "admin-token").Return(&dto.Claims{UserID: primitive.NewObjectID(), Roles: []string{adminRoleID.Hex()}}, nil)
+				perms.On("GetPermissionIDByResourceAndAction", mock.Anything, "system", "write").Return(writePermID)
+				roles.On("FindByIDs", mock.Anything, []string{adminRoleID.Hex()}).Return([]*model.Role{{ID: adminRoleID, Permissions: []string{writePermID}}}, nil)
+			},
+			want: true,
+		},
+		{
+			name:          "token without system:write",
+			authorization: "Bearer user-token",
+			setupMocks: func(auth *mocks.MockAuthService, roles *mocks.MockRoleService, perms *mocks.MockPermissionService) {
+				auth.On("ValidateToken", mock.Anything, "user-token").Return(&dto.Claims{UserID: primitive.NewObjectID(), Roles: []string{userRoleID.Hex()}}, nil)
+				perms.On("GetPermissionIDByResourceAndAction", mock.Anything, "system", "write").Return(writePermID)
+				roles.On("FindByIDs", mock.Anything, []string{userRoleID.Hex()}).Return([]*model.Role{{ID: userRoleID}}, nil)
+			},
+		},
+		{
+			name:          "invalid token",
+			authorization: "Bearer expired-token",
+			setupMocks: func(auth *mocks.MockAuthService, _ *mocks.MockRoleService, _ *mocks.MockPermissionService) {
+				auth.On("ValidateToken", mock.Anything, "expired-token").Return(nil, errors.New("token expired"))
+			},
+		},
+		{
+			name:       "no token",
+			setupMocks: func(*mocks.MockAuthService, *mocks.MockRoleService, *mocks.MockPermissionService) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := new(mocks.MockAuthService)
+			roleService := new(mocks.MockRoleService)
+			permissionService := new(mocks.MockPermissionService)
+			tt.setupMocks(authService, roleService, permissionService)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/calculate", nil)
+			if tt.authorization != "" {
+				c.Request.Header.Set("Authorization", tt.authorization)
+			}
+
+			assert.Equal(t, tt.want, GlobalAdminBypass(authService, roleService, permissionService)(c))
+			authService.AssertExpectations(t)
+			roleService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGlobalAdminBypass_DPoP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const target = "http://example.com/api/admin/clients"
+	key := testutil.NewDPoPKey(t)
+	adminRoleID := primitive.NewObjectID()
+	writePermID := primitive.NewObjectID().Hex()
+	claims := &dto.Claims{
+		UserID:       primitive.NewObjectID(),
+		Roles:        []string{adminRoleID.Hex()},
+		Confirmation: &dto.Confirmation{JKT: key.Thumbprint()},
+	}
+
+	authService := new(mocks.MockAuthService)
+	authService.On("ValidateToken", mock.Anything, "bound-token").Return(claims, nil)
+	roleService := new(mocks.MockRoleService)
+	roleService.On("FindByIDs", mock.Anything, claims.Roles).Return([]*model.Role{{ID: adminRoleID, Permissions: []string{writePermID}}}, nil)
+	permissionService := new(mocks.MockPermissionService)
+	permissionService.On("GetPermissionIDByResourceAndAction", mock.Anything, "system", "write").Return(writePermID)
+
+	jwtOpts := []JWTAuthOption{WithDPoP(dpop.NewVerifier(dpop.DefaultConfig()), false)}
+	fence, err := NewGeoFence(GeoFenceRules{AllowedCIDRs: []string{"10.0.0.0/8"}},
+		WithGeoFenceBypass(GlobalAdminBypass(authService, roleService, permissionService, jwtOpts...)))
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(RequestID(), fence.Enforce(), JWTAuth(authService, jwtOpts...))
+	router.GET("/api/admin/clients", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(proof string) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", "DPoP bound-token")
+		if proof != "" {
+			req.Header.Set(dpop.HeaderName, proof)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, send(""), "a bound token needs its proof to bypass the fence")
+	assert.Equal(t, http.StatusForbidden, send(testutil.NewDPoPKey(t).Proof(http.MethodGet, target, "bound-token")))
+	assert.Equal(t, http.StatusOK, send(key.Proof(http.MethodGet, target, "bound-token")), "the proof is verified once")
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 const (
@@ -76,7 +78,11 @@ func Idempotency(cfg IdempotencyConfig) gin.HandlerFunc {
 				c.Header(k, v)
 			}
 			c.Header("X-Idempotency-Replayed", "true")
-			c.Data(cachedResp.StatusCode, "application/json", cachedResp.Body)
+			contentType := cachedResp.Headers["Content-Type"]
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			c.Data(cachedResp.StatusCode, contentType, cachedResp.Body)
 			c.Abort()
 			return
 		}
@@ -94,11 +100,16 @@ func Idempotency(cfg IdempotencyConfig) gin.HandlerFunc {
 
 		// Cache successful responses (2xx)
 		if writer.statusCode >= 200 && writer.statusCode < 300 {
+			// Headers are captured when read, which can be before the handler sets
+			// them, so take the final content type (JSON or a stream such as NDJSON).
+			if contentType := writer.ResponseWriter.Header().Get("Content-Type"); contentType != "" {
+				writer.headers["Content-Type"] = contentType
+			}
 			cachedResp := &cachedResponse{
 				StatusCode: writer.statusCode,
 				Headers:    writer.headers,
 				Body:       writer.body.Bytes(),
-				Timestamp:  time.Now(),
+				Timestamp:  timeutil.Now(),
 			}
 			cfg.Cache.Set(cacheKey, cachedResp)
 		}
@@ -146,6 +157,17 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// streaming handlers can flush and extend write deadlines.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func (w *responseWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 	w.ResponseWriter.WriteHeader(statusCode)
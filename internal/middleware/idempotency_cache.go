@@ -4,6 +4,8 @@ package middleware
 import (
 	"sync"
 	"time"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // idempotencyCache stores cached HTTP responses for idempotency.
@@ -45,7 +47,7 @@ func (c *idempotencyCache) Set(key int, resp *cachedResponse) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	resp.Timestamp = time.Now()
+	resp.Timestamp = timeutil.Now()
 	c.items[key] = resp
 }
 
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -145,3 +146,33 @@ func TestIdempotencyCache_cleanup(t *testing.T) {
 		})
 	}
 }
+
+func TestIdempotency_ReplaysContentType(t *testing.T) {
+	cfg := DefaultIdempotencyConfig()
+	cfg.Cache = newIdempotencyCache(time.Minute)
+
+	router := gin.New()
+	router.Use(Idempotency(cfg))
+	router.POST("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("{\"index\":0}\n")
+		c.Writer.Flush()
+	})
+
+	var bodies []string
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/stream", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "stream-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		bodies = append(bodies, w.Body.String())
+		if i == 1 {
+			assert.Equal(t, "true", w.Header().Get("X-Idempotency-Replayed"))
+		}
+	}
+	assert.Equal(t, bodies[0], bodies[1])
+}
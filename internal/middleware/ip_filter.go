@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// IPFilter returns middleware rejecting requests from client IPs the rules
+// do not allow with 403, unless their path starts with one of exempt (health
+// checks and metrics, which the infrastructure reaches from its own network).
+// Blocked requests are recorded in the audit log.
+func IPFilter(rules *service.IPRuleService, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		allowed, rule := rules.Check(ip)
+		if allowed {
+			c.Next()
+			return
+		}
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		reason := "not_allowed"
+		fields := map[string]interface{}{}
+		if rule != nil {
+			reason = "denied"
+			fields["cidr"] = rule.CIDR
+			if !rule.ID.IsZero() {
+				fields["rule_id"] = rule.ID.Hex()
+			}
+		}
+		fields["reason"] = reason
+		metrics.RecordIPFilterBlocked(reason)
+		log.Info().Str("ip", ip).Str("reason", reason).Str("path", c.Request.URL.Path).Msg("Request blocked by IP filter")
+		if loggingService, exists := c.Get("logging_service"); exists {
+			if ls, ok := loggingService.(service.LoggingService); ok {
+				AuditLog(ls, c, "ip_blocked", "Request blocked by IP filter", fields)
+			}
+		}
+
+		errorResp := dto.NewError(dto.ErrCodeForbidden, i18n.Message(c, i18n.ErrKeyIPBlocked)).
+			WithIDs(RequestIDs(c))
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResp)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build !integration
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rules, err := service.NewIPRuleService(nil, nil, []string{"203.0.113.0/24"})
+	require.NoError(t, err)
+
+	loggingService := new(mocks.MockLoggingService)
+	audited := make(chan struct{})
+	loggingService.On("CreateLog", mock.Anything, mock.MatchedBy(func(entry *model.LogEntry) bool {
+		return entry.ActionType == "ip_blocked" && entry.Fields["cidr"] == "203.0.113.0/24" && entry.Fields["reason"] == "denied"
+	})).Return(nil).Once().Run(func(mock.Arguments) { close(audited) })
+
+	router := gin.New()
+	router.Use(RequestID(), func(c *gin.Context) {
+		c.Set("logging_service", loggingService)
+		c.Next()
+	}, IPFilter(rules, "/healthz"))
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/calculate", func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":40000"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, get("/api/calculate", "198.51.100.7").Code)
+	assert.Equal(t, http.StatusOK, get("/healthz", "203.0.113.7").Code)
+
+	w := get("/api/calculate", "203.0.113.7")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), dto.ErrCodeForbidden)
+	select {
+	case <-audited:
+	case <-time.After(time.Second):
+		t.Fatal("audit log entry not written")
+	}
+	loggingService.AssertExpectations(t)
+}
@@ -2,67 +2,215 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
 	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/hooks"
 	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/rs/zerolog/log"
 )
 
+const (
+	bearerScheme = "Bearer "
+	dpopScheme   = "DPoP "
+)
 
-// JWTAuth returns a middleware that validates JWT tokens.
-func JWTAuth(authService service.AuthService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		locale := i18n.GetLocale(c)
-		requestID := GetRequestID(c)
+// jwtAuthConfig holds optional JWTAuth settings.
+type jwtAuthConfig struct {
+	dpopVerifier *dpop.Verifier
+	requireDPoP  bool
+	hooks        *hooks.Registry
+}
 
-		// Get token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyTokenRequired, locale)
-			errorResp := dto.NewError(dto.ErrCodeUnauthorized, message).
-				WithRequestID(requestID)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
-			return
-		}
+// JWTAuthOption configures the JWTAuth middleware.
+type JWTAuthOption func(*jwtAuthConfig)
 
-		// Extract token from "Bearer <token>"
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyInvalidToken, locale)
-			errorResp := dto.NewError(dto.ErrCodeUnauthorized, message).
-				WithRequestID(requestID)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
-			return
-		}
+// WithDPoP verifies DPoP proofs for tokens bound to a client key. When required
+// is true, bearer tokens without a key binding are rejected as well.
+func WithDPoP(verifier *dpop.Verifier, required bool) JWTAuthOption {
+	return func(cfg *jwtAuthConfig) {
+		cfg.dpopVerifier = verifier
+		cfg.requireDPoP = required
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == "" {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyTokenRequired, locale)
-			errorResp := dto.NewError(dto.ErrCodeUnauthorized, message).
-				WithRequestID(requestID)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
-			return
-		}
+// WithHooks runs the claim enrichers of registry on every authenticated
+// request, adding their attributes to the request context. A request whose
+// enrichment fails is rejected as forbidden.
+func WithHooks(registry *hooks.Registry) JWTAuthOption {
+	return func(cfg *jwtAuthConfig) {
+		cfg.hooks = registry
+	}
+}
+
+// AccessTokenFromHeader extracts the access token from an Authorization header
+// using the Bearer or DPoP scheme. It returns false for any other format.
+func AccessTokenFromHeader(authHeader string) (token string, isDPoP bool, ok bool) {
+	switch {
+	case strings.HasPrefix(authHeader, bearerScheme):
+		return strings.TrimPrefix(authHeader, bearerScheme), false, true
+	case strings.HasPrefix(authHeader, dpopScheme):
+		return strings.TrimPrefix(authHeader, dpopScheme), true, true
+	default:
+		return "", false, false
+	}
+}
+
+// JWTAuth returns a middleware that validates JWT tokens.
+func JWTAuth(authService service.AuthService, opts ...JWTAuthOption) gin.HandlerFunc {
+	cfg := &jwtAuthConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
 
-		// Validate token
-		claims, err := authService.ValidateToken(c.Request.Context(), tokenString)
+	return func(c *gin.Context) {
+		requestID := GetRequestID(c)
+
+		claims, err := cfg.authenticate(c, authService)
 		if err != nil {
-			message := i18n.GetTranslator().Translate(i18n.ErrKeyInvalidToken, locale)
-			errorResp := dto.NewError(dto.ErrCodeUnauthorized, message).
-				WithRequestID(requestID)
+			if abortCircuitOpen(c, err) {
+				return
+			}
+			key := i18n.ErrKeyInvalidToken
+			if errors.Is(err, errTokenRequired) {
+				key = i18n.ErrKeyTokenRequired
+			}
+			if errors.Is(err, errInvalidProof) {
+				c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+			}
+			errorResp := dto.NewError(dto.ErrCodeUnauthorized, i18n.Message(c, key)).
+				WithIDs(RequestIDs(c))
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResp)
 			return
 		}
 
-		// Store user information in context
-		c.Set("user_id", claims.UserID)
+		// Store user information in context; service tokens have no user
+		if !claims.IsService() {
+			c.Set("user_id", claims.UserID)
+		}
 		c.Set("user_email", claims.Email)
 		c.Set("user_name", claims.Name)
 		c.Set("user_roles", claims.Roles)
 		c.Set("user_claims", claims)
 
+		if cfg.hooks.HasClaimEnrichers() {
+			attrs, err := cfg.hooks.EnrichClaims(c.Request.Context(), claims)
+			if err != nil {
+				log.Warn().Err(err).Str("request_id", requestID).Str("user_id", claims.UserID.Hex()).Msg("Claim enrichment rejected request")
+				message := i18n.Message(c, i18n.ErrKeyForbidden)
+				errorResp := dto.NewError(dto.ErrCodeForbidden, message).
+					WithIDs(RequestIDs(c))
+				c.AbortWithStatusJSON(http.StatusForbidden, errorResp)
+				return
+			}
+			c.Request = c.Request.WithContext(hooks.ContextWithAttributes(c.Request.Context(), attrs))
+		}
+
 		c.Next()
 	}
 }
+
+var (
+	// errTokenRequired is returned by authenticate when the request carries
+	// no access token.
+	errTokenRequired = errors.New("access token required")
+	// errInvalidToken is returned by authenticate when the Authorization
+	// header uses neither the Bearer nor the DPoP scheme.
+	errInvalidToken = errors.New("invalid authorization header")
+	// errInvalidProof is returned by authenticate when a token needing a
+	// DPoP proof comes without a valid one.
+	errInvalidProof = errors.New("invalid DPoP proof")
+)
+
+// authenticatedKey is the context key of the access token authenticate
+// validated, so that it is not validated twice for the same request: a DPoP
+// proof can only be used once.
+const authenticatedKey = "jwt_authenticated"
+
+// authenticatedToken is an access token authenticate validated.
+type authenticatedToken struct {
+	authorization string
+	claims        *dto.Claims
+	// proven is true when a DPoP proof was verified with the token.
+	proven bool
+}
+
+// authenticate validates the access token of the request in c with
+// authService, and the DPoP proof of sender-constrained tokens. It returns
+// the claims already validated when the request was authenticated earlier
+// with the same Authorization header, unless cfg requires a proof that was
+// not checked then.
+func (cfg *jwtAuthConfig) authenticate(c *gin.Context, authService service.AuthService) (*dto.Claims, error) {
+	authHeader := c.GetHeader("Authorization")
+	if value, exists := c.Get(authenticatedKey); exists {
+		if token, ok := value.(authenticatedToken); ok && token.authorization == authHeader && (token.proven || !cfg.requireDPoP) {
+			return token.claims, nil
+		}
+	}
+	if authHeader == "" {
+		return nil, errTokenRequired
+	}
+
+	// Extract token from "Bearer <token>" or "DPoP <token>"
+	tokenString, isDPoP, ok := AccessTokenFromHeader(authHeader)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	if tokenString == "" {
+		return nil, errTokenRequired
+	}
+
+	claims, err := authService.ValidateToken(c.Request.Context(), tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sender-constrained tokens must come with a proof from the bound key
+	proven := claims.IsBound() || isDPoP || cfg.requireDPoP
+	if proven && !cfg.verifyProof(c, claims, tokenString, isDPoP) {
+		return nil, errInvalidProof
+	}
+
+	c.Set(authenticatedKey, authenticatedToken{authorization: authHeader, claims: claims, proven: proven})
+	return claims, nil
+}
+
+// abortCircuitOpen answers 503 when err comes from the open circuit breaker
+// of a repository, so clients back off until MongoDB can be tried again
+// instead of being told their token is invalid. It reports whether it did.
+func abortCircuitOpen(c *gin.Context, err error) bool {
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		return false
+	}
+	var openErr *circuitbreaker.OpenError
+	if errors.As(err, &openErr) {
+		c.Header("Retry-After", strconv.Itoa(max(int((openErr.RetryAfter+time.Second-1)/time.Second), 1)))
+	}
+	errorResp := dto.NewError(dto.ErrCodeDatabaseUnavailable, i18n.Message(c, i18n.ErrKeyDatabaseUnavailable)).
+		WithIDs(RequestIDs(c))
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, errorResp)
+	return true
+}
+
+// verifyProof checks that a bound token was presented with the DPoP scheme and
+// a valid proof signed by the key it is bound to. A verifier must be configured:
+// without one, bound tokens are rejected rather than accepted as bearer tokens.
+func (cfg *jwtAuthConfig) verifyProof(c *gin.Context, claims *dto.Claims, tokenString string, isDPoP bool) bool {
+	if !claims.IsBound() || !isDPoP || cfg.dpopVerifier == nil {
+		return false
+	}
+
+	proof, err := cfg.dpopVerifier.Verify(c.GetHeader(dpop.HeaderName), c.Request.Method, dpop.RequestURL(c.Request), tokenString)
+	if err != nil {
+		return false
+	}
+	return proof.Thumbprint == claims.Confirmation.JKT
+}
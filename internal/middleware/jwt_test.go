@@ -1,15 +1,22 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
 	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/hooks"
+	"github.com/guttosm/pack-service/internal/testutil"
 	"github.com/guttosm/pack-service/internal/mocks"
 	"github.com/guttosm/pack-service/internal/service"
 )
@@ -151,6 +158,24 @@ func TestJWTAuth_UserInfoInContext(t *testing.T) {
 				assert.Equal(t, expectedClaims.Roles, roles)
 			},
 		},
+		{
+			name:       "service token sets no user ID",
+			authHeader: "Bearer service-token",
+			setupMocks: func(mockAuth *mocks.MockAuthService) (*dto.Claims, primitive.ObjectID) {
+				claims := &dto.Claims{Name: "billing", Roles: []string{"service"}, Service: "billing"}
+				mockAuth.On("ValidateToken", mock.Anything, "service-token").Return(claims, nil)
+				return claims, primitive.NilObjectID
+			},
+			expectedStatus: http.StatusOK,
+			validateContext: func(t *testing.T, c *gin.Context, _ primitive.ObjectID, expectedClaims *dto.Claims) {
+				_, exists := c.Get("user_id")
+				assert.False(t, exists)
+
+				claims, exists := c.Get("user_claims")
+				assert.True(t, exists)
+				assert.Equal(t, expectedClaims, claims)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -183,3 +208,212 @@ func TestJWTAuth_UserInfoInContext(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTAuth_DPoP(t *testing.T) {
+	const target = "http://example.com/test"
+	key := testutil.NewDPoPKey(t)
+
+	boundClaims := &dto.Claims{
+		UserID:       primitive.NewObjectID(),
+		Confirmation: &dto.Confirmation{JKT: key.Thumbprint()},
+	}
+	bearerClaims := &dto.Claims{UserID: primitive.NewObjectID()}
+
+	tests := []struct {
+		name           string
+		claims         *dto.Claims
+		scheme         string
+		proof          func() string
+		required       bool
+		noVerifier     bool
+		expectedStatus int
+	}{
+		{
+			name:           "bound token with valid proof",
+			claims:         boundClaims,
+			scheme:         "DPoP ",
+			proof:          func() string { return key.Proof(http.MethodGet, target, "bound-token") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "bound token without proof",
+			claims:         boundClaims,
+			scheme:         "DPoP ",
+			proof:          func() string { return "" },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "bound token presented as bearer",
+			claims:         boundClaims,
+			scheme:         "Bearer ",
+			proof:          func() string { return key.Proof(http.MethodGet, target, "bound-token") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "proof signed by another key",
+			claims:         boundClaims,
+			scheme:         "DPoP ",
+			proof:          func() string { return testutil.NewDPoPKey(t).Proof(http.MethodGet, target, "bound-token") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "proof for another token",
+			claims:         boundClaims,
+			scheme:         "DPoP ",
+			proof:          func() string { return key.Proof(http.MethodGet, target, "other-token") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "bound token without verifier",
+			claims:         boundClaims,
+			scheme:         "DPoP ",
+			proof:          func() string { return key.Proof(http.MethodGet, target, "bound-token") },
+			noVerifier:     true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "bearer token when dpop is optional",
+			claims:         bearerClaims,
+			scheme:         "Bearer ",
+			proof:          func() string { return "" },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "bearer token when dpop is required",
+			claims:         bearerClaims,
+			scheme:         "Bearer ",
+			proof:          func() string { return "" },
+			required:       true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unbound token with dpop scheme",
+			claims:         bearerClaims,
+			scheme:         "DPoP ",
+			proof:          func() string { return key.Proof(http.MethodGet, target, "bound-token") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockAuthService := new(mocks.MockAuthService)
+			mockAuthService.On("ValidateToken", mock.Anything, "bound-token").Return(tt.claims, nil)
+
+			var opts []JWTAuthOption
+			if !tt.noVerifier {
+				opts = append(opts, WithDPoP(dpop.NewVerifier(dpop.DefaultConfig()), tt.required))
+			}
+
+			router := gin.New()
+			router.Use(RequestID(), JWTAuth(mockAuthService, opts...))
+			router.GET("/test", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set("Authorization", tt.scheme+"bound-token")
+			if proof := tt.proof(); proof != "" {
+				req.Header.Set(dpop.HeaderName, proof)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, `DPoP error="invalid_dpop_proof"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+type tierEnricher struct{ err error }
+
+func (e tierEnricher) EnrichClaims(_ context.Context, claims *dto.Claims) (hooks.Attributes, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return hooks.Attributes{"tier": "gold", "email": claims.Email}, nil
+}
+
+func TestJWTAuth_CircuitOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockAuthService := new(mocks.MockAuthService)
+	openErr := &circuitbreaker.OpenError{Name: "mongodb-auth", RetryAfter: 20 * time.Second}
+	mockAuthService.On("ValidateToken", mock.Anything, "valid-token").Return(nil, openErr)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(JWTAuth(mockAuthService))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), dto.ErrCodeDatabaseUnavailable)
+}
+
+func TestJWTAuth_ClaimEnrichment(t *testing.T) {
+	tests := []struct {
+		name           string
+		enrichErr      error
+		expectedStatus int
+	}{
+		{name: "attributes added to request context", expectedStatus: http.StatusOK},
+		{name: "enrichment error rejects request", enrichErr: errors.New("unknown customer"), expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockAuthService := new(mocks.MockAuthService)
+			claims := &dto.Claims{UserID: primitive.NewObjectID(), Email: "test@example.com"}
+			mockAuthService.On("ValidateToken", mock.Anything, "valid-token").Return(claims, nil)
+
+			registry := hooks.NewRegistry()
+			registry.Register("tier", tierEnricher{err: tt.enrichErr})
+
+			var attrs hooks.Attributes
+			router := gin.New()
+			router.Use(RequestID())
+			router.Use(JWTAuth(mockAuthService, WithHooks(registry)))
+			router.GET("/test", func(c *gin.Context) {
+				attrs = hooks.AttributesFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.enrichErr == nil {
+				assert.Equal(t, hooks.Attributes{"tier": "gold", "email": "test@example.com"}, attrs)
+			}
+		})
+	}
+}
+
+func TestAccessTokenFromHeader(t *testing.T) {
+	token, isDPoP, ok := AccessTokenFromHeader("Bearer abc")
+	assert.Equal(t, "abc", token)
+	assert.False(t, isDPoP)
+	assert.True(t, ok)
+
+	token, isDPoP, ok = AccessTokenFromHeader("DPoP abc")
+	assert.Equal(t, "abc", token)
+	assert.True(t, isDPoP)
+	assert.True(t, ok)
+
+	_, _, ok = AccessTokenFromHeader("Basic abc")
+	assert.False(t, ok)
+}
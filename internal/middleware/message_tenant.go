@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+// MessageTenant returns middleware that makes the messages a tenant replaced,
+// and its presentation rules and geofence, apply to its requests. Tenants are API keys,
+// identified by their fingerprint. The key is read like APIKeyAuth reads it, but a missing or
+// invalid key is left for the auth middleware to reject: such requests get
+// the catalog messages.
+//
+// It runs ahead of rate limiting and authentication, so their rejections
+// use the tenant's wording too.
+func MessageTenant(validKeys map[string]bool) gin.HandlerFunc {
+	return NewAPIKeySet(validKeys).Tenant()
+}
+
+// Tenant returns the MessageTenant middleware of the keys in the set.
+func (s *APIKeySet) Tenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			key = c.Query(APIKeyQuery)
+		}
+		if key != "" && s.Valid(key) {
+			c.Set(i18n.TenantContextKey, APIKeyFingerprint(key))
+		}
+		c.Next()
+	}
+}
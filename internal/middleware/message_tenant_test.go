@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+func TestMessageTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MessageTenant(map[string]bool{"key-acme": true}))
+	router.GET("/api/tenant", func(c *gin.Context) { c.String(http.StatusOK, i18n.GetTenant(c)) })
+
+	tenant := func(header, query string) string {
+		req := httptest.NewRequest(http.MethodGet, "/api/tenant"+query, nil)
+		if header != "" {
+			req.Header.Set(APIKeyHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, APIKeyFingerprint("key-acme"), tenant("key-acme", ""))
+	assert.Equal(t, APIKeyFingerprint("key-acme"), tenant("", "?api_key=key-acme"))
+	assert.Empty(t, tenant("key-unknown", ""), "invalid keys have no tenant")
+	assert.Empty(t, tenant("", ""))
+}
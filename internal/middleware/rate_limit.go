@@ -1,54 +1,116 @@
 package middleware
 
 import (
+	"container/list"
 	"hash/fnv"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
 	// defaultNumShards is the default number of shards for the rate limiter.
 	defaultNumShards = 16
+	// defaultVirtualNodes is the default number of ring positions per shard.
+	defaultVirtualNodes = 64
+	// defaultMaxVisitorsPerShard caps the visitors tracked by one shard.
+	defaultMaxVisitorsPerShard = 10000
 )
 
 // visitor tracks rate limit state for a single identifier.
 type visitor struct {
-	tokens    int
+	id        string
+	requests  int // requests made in the current window
 	lastReset time.Time
+	lastSeen  time.Time
 }
 
-// rateLimiterShard is a single shard of the rate limiter.
+// rateLimiterShard is a single shard of the rate limiter. Visitors are kept in
+// LRU order so a shard at capacity evicts its least recently seen visitor.
 type rateLimiterShard struct {
 	mu       sync.Mutex
-	visitors map[string]*visitor
+	visitors map[string]*list.Element // values hold *visitor
+	lru      *list.List
+	index    string // shard label for metrics
+}
+
+// ringNode is a virtual node on the consistent hash ring.
+type ringNode struct {
+	hash  uint32
+	shard int
+}
+
+// RateLimiterOption configures a ShardedRateLimiter.
+type RateLimiterOption func(*ShardedRateLimiter)
+
+// WithLimiterName sets the name used to label the limiter's metrics.
+func WithLimiterName(name string) RateLimiterOption {
+	return func(rl *ShardedRateLimiter) {
+		if name != "" {
+			rl.name = name
+		}
+	}
+}
+
+// WithVirtualNodes sets the number of consistent hash ring positions per shard.
+// More virtual nodes spread identifiers more evenly across shards.
+func WithVirtualNodes(n int) RateLimiterOption {
+	return func(rl *ShardedRateLimiter) {
+		if n > 0 {
+			rl.virtualNodes = n
+		}
+	}
+}
+
+// WithMaxVisitorsPerShard caps the number of visitors a shard tracks. When a
+// shard is full, its least recently seen visitor is evicted, so memory stays
+// bounded even when one shard receives a disproportionate share of identifiers.
+// Evicting a visitor whose window has not passed resets its quota; those
+// evictions are counted with reason "capacity", the others with "expired".
+func WithMaxVisitorsPerShard(n int) RateLimiterOption {
+	return func(rl *ShardedRateLimiter) {
+		if n > 0 {
+			rl.maxVisitorsPerShard = n
+		}
+	}
 }
 
 // ShardedRateLimiter implements a high-performance sharded rate limiter.
-// It distributes visitors across multiple shards to reduce lock contention.
+// It distributes visitors across multiple shards to reduce lock contention,
+// using a consistent hash ring with virtual nodes to balance shard load.
 type ShardedRateLimiter struct {
-	shards    []*rateLimiterShard
-	numShards int
-	rate      int
-	window    time.Duration
-	stopCh    chan struct{}
+	shards              []*rateLimiterShard
+	numShards           int
+	ring                []ringNode
+	virtualNodes        int
+	maxVisitorsPerShard int
+	name                string
+	stopCh              chan struct{}
+
+	// limitMu guards rate and window, which SetLimit changes at runtime.
+	limitMu sync.RWMutex
+	rate    int
+	window  time.Duration
 }
 
 // RateLimiter is an alias for ShardedRateLimiter for backward compatibility.
 type RateLimiter = ShardedRateLimiter
 
 // NewRateLimiter creates a new sharded rate limiter with the specified rate and window.
-func NewRateLimiter(rate int, window time.Duration) *ShardedRateLimiter {
-	return NewShardedRateLimiter(rate, window, defaultNumShards)
+func NewRateLimiter(rate int, window time.Duration, opts ...RateLimiterOption) *ShardedRateLimiter {
+	return NewShardedRateLimiter(rate, window, defaultNumShards, opts...)
 }
 
 // NewShardedRateLimiter creates a new sharded rate limiter with custom shard count.
-func NewShardedRateLimiter(rate int, window time.Duration, numShards int) *ShardedRateLimiter {
+func NewShardedRateLimiter(rate int, window time.Duration, numShards int, opts ...RateLimiterOption) *ShardedRateLimiter {
 	if numShards <= 0 {
 		numShards = defaultNumShards
 	}
@@ -56,50 +118,143 @@ func NewShardedRateLimiter(rate int, window time.Duration, numShards int) *Shard
 	shards := make([]*rateLimiterShard, numShards)
 	for i := range shards {
 		shards[i] = &rateLimiterShard{
-			visitors: make(map[string]*visitor),
+			visitors: make(map[string]*list.Element),
+			lru:      list.New(),
+			index:    strconv.Itoa(i),
 		}
 	}
 
 	rl := &ShardedRateLimiter{
-		shards:    shards,
-		numShards: numShards,
-		rate:      rate,
-		window:    window,
-		stopCh:    make(chan struct{}),
+		shards:              shards,
+		numShards:           numShards,
+		virtualNodes:        defaultVirtualNodes,
+		maxVisitorsPerShard: defaultMaxVisitorsPerShard,
+		name:                "default",
+		rate:                rate,
+		window:              window,
+		stopCh:              make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(rl)
 	}
 
+	rl.ring = buildRing(numShards, rl.virtualNodes)
+
 	go rl.cleanup()
+
 	return rl
 }
 
-// getShard returns the shard for the given identifier using FNV hash.
-func (rl *ShardedRateLimiter) getShard(identifier string) *rateLimiterShard {
+// buildRing places virtualNodes positions per shard on a hash ring, sorted by hash.
+func buildRing(numShards, virtualNodes int) []ringNode {
+	ring := make([]ringNode, 0, numShards*virtualNodes)
+	for shard := 0; shard < numShards; shard++ {
+		for v := 0; v < virtualNodes; v++ {
+			ring = append(ring, ringNode{
+				hash:  hashString(strconv.Itoa(shard) + "#" + strconv.Itoa(v)),
+				shard: shard,
+			})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+	return ring
+}
+
+// hashString hashes s onto the ring. FNV alone clusters short, similar keys
+// such as virtual node names, so the result goes through the murmur3 finalizer.
+func hashString(s string) uint32 {
 	h := fnv.New32a()
-	h.Write([]byte(identifier))
-	return rl.shards[h.Sum32()%uint32(rl.numShards)]
+	_, _ = h.Write([]byte(s))
+	x := h.Sum32()
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
+
+// getShard returns the shard owning the identifier: the first ring node
+// clockwise from the identifier's hash.
+func (rl *ShardedRateLimiter) getShard(identifier string) *rateLimiterShard {
+	hash := hashString(identifier)
+	i := sort.Search(len(rl.ring), func(i int) bool { return rl.ring[i].hash >= hash })
+	if i == len(rl.ring) {
+		i = 0
+	}
+	return rl.shards[rl.ring[i].shard]
+}
+
+// SetLimit changes the requests allowed per window. Requests already made in
+// a visitor's current window count against the new rate.
+func (rl *ShardedRateLimiter) SetLimit(rate int, window time.Duration) {
+	rl.limitMu.Lock()
+	defer rl.limitMu.Unlock()
+	rl.rate = rate
+	rl.window = window
+}
+
+// limits returns the requests allowed per window and the window.
+func (rl *ShardedRateLimiter) limits() (int, time.Duration) {
+	rl.limitMu.RLock()
+	defer rl.limitMu.RUnlock()
+	return rl.rate, rl.window
 }
 
 // checkRateLimit is the core rate limiting logic used by both IP and user limiters.
 func (rl *ShardedRateLimiter) checkRateLimit(identifier string) (allowed bool, remaining int) {
+	rate, window := rl.limits()
 	shard := rl.getShard(identifier)
+	metrics.RecordRateLimiterShardRequest(rl.name, shard.index)
 
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	v, exists := shard.visitors[identifier]
 	now := time.Now()
+	elem, exists := shard.visitors[identifier]
+	if !exists {
+		if shard.lru.Len() >= rl.maxVisitorsPerShard {
+			// Rejecting newcomers would let a flood of identifiers lock every
+			// new client of the shard out, so a live visitor goes if need be
+			reason := "capacity"
+			if shard.evictOldest(now, window) {
+				reason = "expired"
+			}
+			metrics.RecordRateLimiterEviction(rl.name, reason)
+		}
+		shard.visitors[identifier] = shard.lru.PushFront(&visitor{id: identifier, requests: 1, lastReset: now, lastSeen: now})
+		return true, rate - 1
+	}
 
-	if !exists || now.Sub(v.lastReset) > rl.window {
-		shard.visitors[identifier] = &visitor{tokens: rl.rate - 1, lastReset: now}
-		return true, rl.rate - 1
+	shard.lru.MoveToFront(elem)
+	v := elem.Value.(*visitor)
+	v.lastSeen = now
+	if now.Sub(v.lastReset) > window {
+		v.requests = 1
+		v.lastReset = now
+		return true, rate - 1
 	}
 
-	if v.tokens <= 0 {
+	if v.requests >= rate {
 		return false, 0
 	}
 
-	v.tokens--
-	return true, v.tokens
+	v.requests++
+	return true, rate - v.requests
+}
+
+// evictOldest removes the least recently seen visitor and reports whether
+// its window had passed, so that no live state was lost. Caller must hold s.mu.
+func (s *rateLimiterShard) evictOldest(now time.Time, window time.Duration) (expired bool) {
+	oldest := s.lru.Back()
+	if oldest == nil {
+		return false
+	}
+	v := oldest.Value.(*visitor)
+	s.lru.Remove(oldest)
+	delete(s.visitors, v.id)
+	return now.Sub(v.lastReset) > window
 }
 
 // RateLimit returns a middleware that limits requests per IP.
@@ -108,17 +263,17 @@ func (rl *ShardedRateLimiter) RateLimit() gin.HandlerFunc {
 		identifier := c.ClientIP()
 
 		allowed, remaining := rl.checkRateLimit(identifier)
+		rate, window := rl.limits()
 
 		// Set rate limit headers
-		c.Header("X-RateLimit-Limit", string(rune(rl.rate)))
+		c.Header("X-RateLimit-Limit", string(rune(rate)))
 		c.Header("X-RateLimit-Remaining", string(rune(remaining)))
 
 		if !allowed {
-			locale := i18n.GetLocale(c)
-			requestID := GetRequestID(c)
-			c.Header("Retry-After", rl.window.String())
-			errorResp := dto.NewError(dto.ErrCodeRateLimit, i18n.GetTranslator().Translate(i18n.ErrKeyRateLimitExceeded, locale)).
-				WithRequestID(requestID)
+			metrics.RecordRateLimitRejection(rl.name, "ip")
+			c.Header("Retry-After", window.String())
+			errorResp := dto.NewError(dto.ErrCodeRateLimit, i18n.Message(c, i18n.ErrKeyRateLimitExceeded)).
+				WithIDs(RequestIDs(c))
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, errorResp)
 			return
 		}
@@ -134,17 +289,17 @@ func (rl *ShardedRateLimiter) UserRateLimit() gin.HandlerFunc {
 		identifier := rl.getUserIdentifier(c)
 
 		allowed, remaining := rl.checkRateLimit(identifier)
+		rate, window := rl.limits()
 
 		// Set rate limit headers
-		c.Header("X-RateLimit-Limit", string(rune(rl.rate)))
+		c.Header("X-RateLimit-Limit", string(rune(rate)))
 		c.Header("X-RateLimit-Remaining", string(rune(remaining)))
 
 		if !allowed {
-			locale := i18n.GetLocale(c)
-			requestID := GetRequestID(c)
-			c.Header("Retry-After", rl.window.String())
-			errorResp := dto.NewError(dto.ErrCodeRateLimit, i18n.GetTranslator().Translate(i18n.ErrKeyRateLimitExceeded, locale)).
-				WithRequestID(requestID)
+			metrics.RecordRateLimitRejection(rl.name, "user")
+			c.Header("Retry-After", window.String())
+			errorResp := dto.NewError(dto.ErrCodeRateLimit, i18n.Message(c, i18n.ErrKeyRateLimitExceeded)).
+				WithIDs(RequestIDs(c))
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, errorResp)
 			return
 		}
@@ -180,19 +335,30 @@ func (rl *ShardedRateLimiter) cleanup() {
 	}
 }
 
-// cleanupExpired removes expired visitors from all shards.
+// cleanupExpired removes visitors not seen for two windows and publishes shard load.
+// Shards are ordered by last use, so each sweep stops at the first recent visitor.
 func (rl *ShardedRateLimiter) cleanupExpired() {
 	now := time.Now()
-	threshold := rl.window * 2
+	_, window := rl.limits()
+	threshold := window * 2
 
 	for _, shard := range rl.shards {
 		shard.mu.Lock()
-		for id, v := range shard.visitors {
-			if now.Sub(v.lastReset) > threshold {
-				delete(shard.visitors, id)
+		expired := 0
+		for elem := shard.lru.Back(); elem != nil; elem = shard.lru.Back() {
+			v := elem.Value.(*visitor)
+			if now.Sub(v.lastSeen) <= threshold {
+				break
 			}
+			shard.lru.Remove(elem)
+			delete(shard.visitors, v.id)
+			expired++
 		}
+		size := shard.lru.Len()
 		shard.mu.Unlock()
+
+		metrics.RecordRateLimiterEvictions(rl.name, "expired", expired)
+		metrics.SetRateLimiterShardVisitors(rl.name, shard.index, size)
 	}
 }
 
@@ -206,9 +372,43 @@ func (rl *ShardedRateLimiter) Stats() (totalVisitors int, perShard []int) {
 	perShard = make([]int, rl.numShards)
 	for i, shard := range rl.shards {
 		shard.mu.Lock()
-		perShard[i] = len(shard.visitors)
+		perShard[i] = shard.lru.Len()
 		totalVisitors += perShard[i]
 		shard.mu.Unlock()
 	}
 	return totalVisitors, perShard
 }
+
+// RateLimiterSet collects the rate limiters of a router, so their limits can
+// be changed together at runtime. A nil *RateLimiterSet collects nothing.
+type RateLimiterSet struct {
+	mu       sync.Mutex
+	limiters []*ShardedRateLimiter
+}
+
+// NewRateLimiterSet creates an empty RateLimiterSet.
+func NewRateLimiterSet() *RateLimiterSet {
+	return &RateLimiterSet{}
+}
+
+// Add adds rl to the set.
+func (s *RateLimiterSet) Add(rl *ShardedRateLimiter) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limiters = append(s.limiters, rl)
+}
+
+// SetLimit changes the limits of every limiter in the set.
+func (s *RateLimiterSet) SetLimit(rate int, window time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rl := range s.limiters {
+		rl.SetLimit(rate, window)
+	}
+}
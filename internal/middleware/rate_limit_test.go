@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/metrics"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -366,3 +369,188 @@ func TestShardedRateLimiter_WindowReset(t *testing.T) {
 	assert.True(t, allowed)
 	assert.Equal(t, 1, remaining)
 }
+
+func TestShardedRateLimiter_SetLimit(t *testing.T) {
+	rl := NewShardedRateLimiter(5, time.Minute, 4)
+	defer rl.Stop()
+
+	rl.checkRateLimit("test")
+	rl.checkRateLimit("test")
+
+	// Lowering the rate caps the requests left in the current window
+	set := NewRateLimiterSet()
+	set.Add(rl)
+	set.SetLimit(2, 50*time.Millisecond)
+	allowed, _ := rl.checkRateLimit("test")
+	assert.False(t, allowed)
+
+	time.Sleep(60 * time.Millisecond)
+	allowed, remaining := rl.checkRateLimit("test")
+	assert.True(t, allowed)
+	assert.Equal(t, 1, remaining)
+
+	// A nil set ignores limiters
+	var none *RateLimiterSet
+	none.Add(rl)
+	none.SetLimit(100, time.Minute)
+	rate, _ := rl.limits()
+	assert.Equal(t, 2, rate)
+}
+
+func TestShardedRateLimiter_Options(t *testing.T) {
+	rl := NewShardedRateLimiter(10, time.Minute, 4,
+		WithLimiterName("user"),
+		WithVirtualNodes(8),
+		WithMaxVisitorsPerShard(100),
+	)
+	defer rl.Stop()
+
+	assert.Equal(t, "user", rl.name)
+	assert.Equal(t, 8, rl.virtualNodes)
+	assert.Equal(t, 100, rl.maxVisitorsPerShard)
+	assert.Len(t, rl.ring, 4*8)
+
+	// Invalid values keep the defaults
+	rl2 := NewShardedRateLimiter(10, time.Minute, 4, WithLimiterName(""), WithVirtualNodes(0), WithMaxVisitorsPerShard(-1))
+	defer rl2.Stop()
+
+	assert.Equal(t, "default", rl2.name)
+	assert.Equal(t, defaultVirtualNodes, rl2.virtualNodes)
+	assert.Equal(t, defaultMaxVisitorsPerShard, rl2.maxVisitorsPerShard)
+}
+
+func TestShardedRateLimiter_ConsistentHashing(t *testing.T) {
+	rl := NewShardedRateLimiter(10, time.Minute, 8)
+	defer rl.Stop()
+
+	// The same identifier always maps to the same shard
+	assert.Same(t, rl.getShard("ip:10.0.0.1"), rl.getShard("ip:10.0.0.1"))
+
+	// Virtual nodes spread identifiers across every shard without a hot spot
+	for i := 0; i < 8000; i++ {
+		rl.checkRateLimit(fmt.Sprintf("ip:10.0.%d.%d", i/256, i%256))
+	}
+	total, perShard := rl.Stats()
+	assert.Equal(t, 8000, total)
+	for shard, count := range perShard {
+		assert.Greater(t, count, 500, "shard %d is underloaded", shard)
+		assert.Less(t, count, 1500, "shard %d is overloaded", shard)
+	}
+}
+
+func TestShardedRateLimiter_ShardCapacityEvictsExpired(t *testing.T) {
+	// A single shard makes placement deterministic
+	rl := NewShardedRateLimiter(2, time.Minute, 1, WithLimiterName("capacity-expired-test"), WithMaxVisitorsPerShard(2))
+	defer rl.Stop()
+
+	rl.checkRateLimit("a")
+	rl.checkRateLimit("b")
+	rl.checkRateLimit("a") // a is now the most recently seen
+
+	// Age b past its window
+	shard := rl.shards[0]
+	shard.mu.Lock()
+	shard.visitors["b"].Value.(*visitor).lastReset = time.Now().Add(-2 * time.Minute)
+	shard.mu.Unlock()
+
+	allowed, _ := rl.checkRateLimit("c") // evicts b
+	assert.True(t, allowed)
+
+	total, _ := rl.Stats()
+	assert.Equal(t, 2, total)
+	assert.Contains(t, shard.visitors, "a")
+	assert.Contains(t, shard.visitors, "c")
+	assert.NotContains(t, shard.visitors, "b")
+
+	// a kept its state: its quota is exhausted
+	allowed, _ = rl.checkRateLimit("a")
+	assert.False(t, allowed)
+
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(metrics.RateLimiterEvictionsTotal.WithLabelValues("capacity-expired-test", "expired")))
+	assert.Equal(t, 0.0, promtestutil.ToFloat64(metrics.RateLimiterEvictionsTotal.WithLabelValues("capacity-expired-test", "capacity")))
+}
+
+func TestShardedRateLimiter_ShardCapacityFull(t *testing.T) {
+	rl := NewShardedRateLimiter(2, time.Minute, 1, WithLimiterName("capacity-full-test"), WithMaxVisitorsPerShard(2))
+	defer rl.Stop()
+
+	rl.checkRateLimit("a")
+	rl.checkRateLimit("b")
+	rl.checkRateLimit("a")
+	rl.checkRateLimit("a") // a is the most recently seen and exhausted
+
+	// Both windows are live, yet new identifiers are still served
+	for _, id := range []string{"c", "d"} {
+		allowed, remaining := rl.checkRateLimit(id)
+		assert.True(t, allowed, id)
+		assert.Equal(t, 1, remaining, id)
+	}
+
+	shard := rl.shards[0]
+	assert.Len(t, shard.visitors, 2)
+	assert.Contains(t, shard.visitors, "c")
+	assert.Contains(t, shard.visitors, "d")
+
+	// Each live eviction is counted
+	assert.Equal(t, 2.0, promtestutil.ToFloat64(metrics.RateLimiterEvictionsTotal.WithLabelValues("capacity-full-test", "capacity")))
+	assert.Equal(t, 0.0, promtestutil.ToFloat64(metrics.RateLimiterEvictionsTotal.WithLabelValues("capacity-full-test", "expired")))
+}
+
+func TestShardedRateLimiter_ShardCapacityEvictsLeastRecentlySeen(t *testing.T) {
+	rl := NewShardedRateLimiter(2, time.Minute, 1, WithMaxVisitorsPerShard(2))
+	defer rl.Stop()
+
+	rl.checkRateLimit("a")
+	rl.checkRateLimit("a") // a is exhausted
+	rl.checkRateLimit("b")
+	rl.checkRateLimit("a") // rejected, but a is now the most recently seen
+
+	allowed, _ := rl.checkRateLimit("c") // evicts b
+	assert.True(t, allowed)
+
+	shard := rl.shards[0]
+	assert.Contains(t, shard.visitors, "a")
+	assert.NotContains(t, shard.visitors, "b")
+
+	// a kept its exhausted quota
+	allowed, _ = rl.checkRateLimit("a")
+	assert.False(t, allowed)
+}
+
+func TestShardedRateLimiter_CleanupExpired(t *testing.T) {
+	rl := NewShardedRateLimiter(10, time.Minute, 1)
+	defer rl.Stop()
+
+	rl.checkRateLimit("stale")
+	rl.checkRateLimit("fresh")
+
+	// Age the stale visitor past two windows
+	shard := rl.shards[0]
+	shard.mu.Lock()
+	shard.visitors["stale"].Value.(*visitor).lastSeen = time.Now().Add(-3 * time.Minute)
+	shard.mu.Unlock()
+
+	rl.cleanupExpired()
+
+	assert.NotContains(t, shard.visitors, "stale")
+	assert.Contains(t, shard.visitors, "fresh")
+}
+
+func TestShardedRateLimiter_RecordsRejections(t *testing.T) {
+	rl := NewShardedRateLimiter(1, time.Minute, 1, WithLimiterName("rejections-test"))
+	defer rl.Stop()
+
+	router := gin.New()
+	router.GET("/ip", rl.RateLimit(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/user", rl.UserRateLimit(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/ip", "/ip", "/user", "/user"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Each limit was used up by the first request
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(metrics.RateLimitRejectionsTotal.WithLabelValues("rejections-test", "ip")))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(metrics.RateLimitRejectionsTotal.WithLabelValues("rejections-test", "user")))
+}
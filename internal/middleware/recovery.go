@@ -5,11 +5,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/logger"
 )
 
 // Recovery returns a middleware that recovers from panics and returns a 500 error.
-// It logs the panic details with the request ID for debugging.
+// It logs the panic details with the request ID for debugging and returns
+// the request ID in the error body so clients can report it.
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
@@ -21,10 +23,10 @@ func Recovery() gin.HandlerFunc {
 					Interface("panic", err).
 					Msg("PANIC recovered")
 
-				c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{
-					Error:   dto.ErrCodeInternal,
-					Message: "An unexpected error occurred",
-				})
+				message := i18n.Message(c, i18n.ErrKeyInternalError)
+				errorResp := dto.NewError(dto.ErrCodeInternal, message).
+					WithIDs(RequestIDs(c))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, errorResp)
 			}
 		}()
 		c.Next()
@@ -30,6 +30,7 @@ func TestRecovery(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
 				assert.Contains(t, w.Body.String(), "internal_error")
+				assert.Contains(t, w.Body.String(), w.Header().Get(RequestIDHeader))
 			},
 		},
 		{
@@ -3,12 +3,17 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+
+	"github.com/guttosm/pack-service/internal/correlation"
+	"github.com/guttosm/pack-service/internal/idgen"
 )
 
 const (
 	// RequestIDHeader is the HTTP header name for request ID.
 	RequestIDHeader = "X-Request-ID"
+	// CorrelationIDHeader is the HTTP header carrying the ID shared by the
+	// requests of one operation across services.
+	CorrelationIDHeader = "X-Correlation-ID"
 )
 
 // ContextKey type for context keys to avoid collisions.
@@ -17,20 +22,37 @@ type ContextKey string
 const (
 	// RequestIDKey is the context key for request ID.
 	RequestIDKey ContextKey = "request_id"
+	// CorrelationIDKey is the context key for the correlation ID.
+	CorrelationIDKey ContextKey = "correlation_id"
 )
 
 // RequestID returns a middleware that ensures each request has a unique ID.
 // If the client provides X-Request-ID header, it will be used.
 // Otherwise, a new UUID v4 will be generated.
+//
+// The request also gets a correlation ID: the client's X-Correlation-ID
+// when it is a valid one, otherwise the request ID. Both are echoed in the
+// response headers and put in the request context, for the database
+// operations the request causes.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader(RequestIDHeader)
 		if requestID == "" {
-			requestID = uuid.New().String()
+			requestID = idgen.NewUUID()
+		}
+		correlationID := c.GetHeader(CorrelationIDHeader)
+		if !correlation.ValidID(correlationID) {
+			correlationID = requestID
 		}
 
 		c.Set(string(RequestIDKey), requestID)
+		c.Set(string(CorrelationIDKey), correlationID)
 		c.Header(RequestIDHeader, requestID)
+		c.Header(CorrelationIDHeader, correlationID)
+		c.Request = c.Request.WithContext(correlation.ContextWithIDs(c.Request.Context(), correlation.IDs{
+			RequestID:     requestID,
+			CorrelationID: correlationID,
+		}))
 		c.Next()
 	}
 }
@@ -44,3 +66,17 @@ func GetRequestID(c *gin.Context) string {
 	}
 	return ""
 }
+
+// GetCorrelationID retrieves the correlation ID from the gin context.
+func GetCorrelationID(c *gin.Context) string {
+	return c.GetString(string(CorrelationIDKey))
+}
+
+// RequestIDs returns the request, correlation and trace IDs of the request.
+func RequestIDs(c *gin.Context) correlation.IDs {
+	return correlation.IDs{
+		RequestID:     GetRequestID(c),
+		CorrelationID: GetCorrelationID(c),
+		TraceID:       traceID(c),
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/internal/correlation"
 )
 
 func TestRequestID(t *testing.T) {
@@ -107,3 +109,53 @@ func TestGetRequestID(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestID_CorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		correlationID string
+		expected      string
+	}{
+		{
+			name:          "passes the caller's correlation ID through",
+			correlationID: "order-42",
+			expected:      "order-42",
+		},
+		{
+			name:     "defaults to the request ID",
+			expected: "req-1",
+		},
+		{
+			name:          "replaces an invalid correlation ID with the request ID",
+			correlationID: "order 42",
+			expected:      "req-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestID())
+			var fromContext correlation.IDs
+			router.GET("/test", func(c *gin.Context) {
+				fromContext = correlation.FromContext(c.Request.Context())
+				c.String(http.StatusOK, GetCorrelationID(c))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set(RequestIDHeader, "req-1")
+			if tt.correlationID != "" {
+				req.Header.Set(CorrelationIDHeader, tt.correlationID)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Body.String())
+			assert.Equal(t, tt.expected, w.Header().Get(CorrelationIDHeader))
+			assert.Equal(t, correlation.IDs{RequestID: "req-1", CorrelationID: tt.expected}, fromContext)
+		})
+	}
+}
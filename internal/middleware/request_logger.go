@@ -2,42 +2,79 @@ package middleware
 
 import (
 	"context"
+	"math/rand/v2"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/logger"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// RequestLoggerConfig configures the RequestLoggerWithConfig middleware.
+type RequestLoggerConfig struct {
+	// SuccessSampleRate is the fraction of requests answered below 400 that
+	// are logged, above 0 and up to 1; zero logs all of them. Failed requests
+	// are always logged.
+	SuccessSampleRate float64
+}
+
+// DefaultRequestLoggerConfig returns a config logging every request.
+func DefaultRequestLoggerConfig() RequestLoggerConfig {
+	return RequestLoggerConfig{SuccessSampleRate: 1}
+}
+
 // RequestLogger returns a middleware that logs HTTP request details in JSON format.
-// It logs: request ID, method, path, status code, latency, IP, and user agent.
+// It logs: request ID, trace ID, method, path, status code, latency, IP, and user agent.
 // Uses async logger with worker pool when available, falls back to goroutine-per-request.
 func RequestLogger(loggingService service.LoggingService) gin.HandlerFunc {
+	return RequestLoggerWithConfig(loggingService, DefaultRequestLoggerConfig())
+}
+
+// RequestLoggerWithConfig is RequestLogger logging only a sample of the
+// successful requests. Stored entries of sampled requests carry the
+// sample_rate field, so totals can be estimated from them.
+func RequestLoggerWithConfig(loggingService service.LoggingService, cfg RequestLoggerConfig) gin.HandlerFunc {
+	sampleRate := cfg.SuccessSampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		requestID := GetRequestID(c)
+		correlationID := GetCorrelationID(c)
 
 		c.Next()
 
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
+		sampled := statusCode < 400 && sampleRate < 1
+		if sampled && rand.Float64() >= sampleRate {
+			return
+		}
+		trace := traceID(c)
 		method := c.Request.Method
 		path := c.Request.URL.Path
 		ip := c.ClientIP()
 		userAgent := c.Request.UserAgent()
 
 		// Create structured log entry for console
-		log := logger.Logger().With().
+		logCtx := logger.Logger().With().
 			Str("request_id", requestID).
+			Str("correlation_id", correlationID).
 			Str("method", method).
 			Str("path", path).
 			Int("status_code", statusCode).
 			Int64("duration_ms", latency.Milliseconds()).
 			Str("ip", ip).
-			Str("user_agent", userAgent).
-			Logger()
+			Str("user_agent", userAgent)
+		if trace != "" {
+			logCtx = logCtx.Str("trace_id", trace)
+		}
+		log := logCtx.Logger()
 
 		// Log level based on status code
 		switch {
@@ -52,16 +89,22 @@ func RequestLogger(loggingService service.LoggingService) gin.HandlerFunc {
 		// Store in MongoDB if logging service is provided
 		if loggingService != nil {
 			entry := &model.LogEntry{
-				Timestamp:  time.Now(),
-				Level:      getLogLevel(statusCode),
-				Message:    "HTTP request",
-				RequestID:  requestID,
-				Method:     method,
-				Path:       path,
-				StatusCode: statusCode,
-				Duration:   latency.Milliseconds(),
-				IP:         ip,
-				UserAgent:  userAgent,
+				Timestamp:     timeutil.Now(),
+				Level:         getLogLevel(statusCode),
+				Message:       "HTTP request",
+				RequestID:     requestID,
+				CorrelationID: correlationID,
+				TraceID:       trace,
+				Method:        method,
+				Path:          path,
+				StatusCode:    statusCode,
+				Duration:      latency.Milliseconds(),
+				IP:            ip,
+				UserAgent:     userAgent,
+			}
+
+			if sampled {
+				entry.WithField("sample_rate", sampleRate)
 			}
 
 			// Capture user information if available (from JWT middleware)
@@ -3,14 +3,19 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_getLogLevel(t *testing.T) {
@@ -188,3 +193,97 @@ func TestRequestLogger_WithUserInfo(t *testing.T) {
 		})
 	}
 }
+
+// capturedLogs serves requests through RequestLoggerWithConfig and returns
+// the entries stored for them.
+func capturedLogs(t *testing.T, cfg RequestLoggerConfig, requests func(router *gin.Engine)) []*model.LogEntry {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	entries := make(chan *model.LogEntry, 100)
+	loggingService := mocks.NewMockLoggingService(t)
+	loggingService.EXPECT().CreateLog(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, entry *model.LogEntry) { entries <- entry }).
+		Return(nil).Maybe()
+
+	router := gin.New()
+	router.Use(RequestID(), Tracing(), RequestLoggerWithConfig(loggingService, cfg))
+	router.GET("/status/:code", func(c *gin.Context) {
+		code, _ := strconv.Atoi(c.Param("code"))
+		c.Status(code)
+	})
+	requests(router)
+
+	// Entries are stored in the background
+	var stored []*model.LogEntry
+	for {
+		select {
+		case entry := <-entries:
+			stored = append(stored, entry)
+		case <-time.After(100 * time.Millisecond):
+			return stored
+		}
+	}
+}
+
+func TestRequestLoggerWithConfig_Sampling(t *testing.T) {
+	stored := capturedLogs(t, RequestLoggerConfig{SuccessSampleRate: 1e-9}, func(router *gin.Engine) {
+		for _, code := range []string{"200", "204", "302", "404", "500"} {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/"+code, nil))
+		}
+	})
+
+	codes := make([]int, 0, len(stored))
+	for _, entry := range stored {
+		codes = append(codes, entry.StatusCode)
+		assert.Nil(t, entry.Fields, "failed requests are not sampled")
+	}
+	assert.ElementsMatch(t, []int{404, 500}, codes)
+}
+
+func TestRequestLoggerWithConfig_SampleRateField(t *testing.T) {
+	stored := capturedLogs(t, RequestLoggerConfig{SuccessSampleRate: 0.999999999}, func(router *gin.Engine) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/200", nil))
+	})
+
+	require.Len(t, stored, 1)
+	assert.Equal(t, 0.999999999, stored[0].Fields["sample_rate"])
+}
+
+func TestRequestLoggerWithConfig_ZeroLogsEverything(t *testing.T) {
+	stored := capturedLogs(t, RequestLoggerConfig{}, func(router *gin.Engine) {
+		for range 3 {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/200", nil))
+		}
+	})
+
+	require.Len(t, stored, 3)
+	assert.Nil(t, stored[0].Fields)
+}
+
+func TestRequestLogger_TraceID(t *testing.T) {
+	recordSpans(t)
+
+	stored := capturedLogs(t, DefaultRequestLoggerConfig(), func(router *gin.Engine) {
+		req := httptest.NewRequest(http.MethodGet, "/status/200", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/200", nil))
+	})
+
+	require.Len(t, stored, 2)
+	traceIDs := []string{stored[0].TraceID, stored[1].TraceID}
+	assert.Contains(t, traceIDs, "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.NotContains(t, traceIDs, "")
+}
+
+func TestRequestLogger_CorrelationID(t *testing.T) {
+	stored := capturedLogs(t, DefaultRequestLoggerConfig(), func(router *gin.Engine) {
+		req := httptest.NewRequest(http.MethodGet, "/status/200", nil)
+		req.Header.Set(CorrelationIDHeader, "order-42")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	})
+
+	require.Len(t, stored, 1)
+	assert.Equal(t, "order-42", stored[0].CorrelationID)
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+// Request validation modes.
+const (
+	// RequestValidationOff does not validate requests.
+	RequestValidationOff = "off"
+	// RequestValidationReport logs the requests that break the schema and
+	// lets them through.
+	RequestValidationReport = "report"
+	// RequestValidationEnforce rejects the requests that break the schema
+	// with 400.
+	RequestValidationEnforce = "enforce"
+)
+
+// requestBodyField is the detail key of errors about the body as a whole,
+// such as malformed JSON.
+const requestBodyField = "body"
+
+// RequestValidator checks JSON request bodies against the schemas of the
+// OpenAPI document describing the API, so the documented contract and the
+// validation of the handlers cannot drift apart unnoticed.
+type RequestValidator struct {
+	router  routers.Router
+	enforce bool
+}
+
+// NewRequestValidator creates a validator from spec, a Swagger 2.0 document
+// as generated by swag, in the given mode. It returns nil for
+// RequestValidationOff or an empty mode.
+func NewRequestValidator(spec []byte, mode string) (*RequestValidator, error) {
+	switch mode {
+	case "", RequestValidationOff:
+		return nil, nil
+	case RequestValidationReport, RequestValidationEnforce:
+	default:
+		return nil, fmt.Errorf("unknown request validation mode %q, use off, report or enforce", mode)
+	}
+
+	var swagger openapi2.T
+	if err := json.Unmarshal(spec, &swagger); err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&swagger)
+	if err != nil {
+		return nil, fmt.Errorf("convert OpenAPI document: %w", err)
+	}
+	// Match operations by path whatever host the service is reached at
+	doc.Servers = nil
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("route OpenAPI document: %w", err)
+	}
+	return &RequestValidator{router: router, enforce: mode == RequestValidationEnforce}, nil
+}
+
+// Validate returns middleware checking the JSON body of each request whose
+// operation the document describes. Other requests pass untouched. Broken
+// bodies are rejected with 400 and the violations in details, one per
+// field, or only logged in report mode.
+func (v *RequestValidator) Validate() gin.HandlerFunc {
+	if v == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		req := c.Request
+		if req.Body == nil || req.Body == http.NoBody || !isJSON(req.Header.Get("Content-Type")) {
+			c.Next()
+			return
+		}
+		route, pathParams, err := v.router.FindRoute(req)
+		if err != nil || route.Operation.RequestBody == nil || route.Operation.RequestBody.Value == nil {
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    &openapi3filter.Options{MultiError: true, SkipSettingDefaults: true},
+		}
+		err = openapi3filter.ValidateRequestBody(req.Context(), input, route.Operation.RequestBody.Value)
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		details := requestValidationDetails(err)
+		if !v.enforce {
+			log.Warn().
+				Str("method", req.Method).
+				Str("path", route.Path).
+				Interface("violations", details).
+				Msg("Request body does not match the OpenAPI document")
+			c.Next()
+			return
+		}
+
+		errorResp := dto.NewError(dto.ErrCodeInvalidRequest, i18n.Message(c, i18n.ErrKeyInvalidRequestBody)).
+			WithIDs(RequestIDs(c))
+		errorResp.Details = details
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResp)
+	}
+}
+
+// isJSON reports whether contentType is JSON, such as application/json or
+// application/merge-patch+json.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// requestValidationDetails maps each field of a validation error to why it
+// is invalid. Fields are dotted paths such as pack_sizes.0; errors about the
+// body as a whole are under "body".
+func requestValidationDetails(err error) map[string]string {
+	details := make(map[string]string)
+	var collect func(err error)
+	collect = func(err error) {
+		var multi openapi3.MultiError
+		if errors.As(err, &multi) {
+			for _, e := range multi {
+				collect(e)
+			}
+			return
+		}
+
+		var schemaErr *openapi3.SchemaError
+		if !errors.As(err, &schemaErr) {
+			var requestErr *openapi3filter.RequestError
+			if errors.As(err, &requestErr) && requestErr.Err != nil {
+				err = requestErr.Err
+			}
+			details[requestBodyField] = err.Error()
+			return
+		}
+
+		field := strings.Join(schemaErr.JSONPointer(), ".")
+		if field == "" {
+			field = requestBodyField
+		}
+		details[field] = schemaErr.Reason
+	}
+	collect(err)
+	return details
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/docs"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+)
+
+func newRequestValidationRouter(t *testing.T, mode string) (*gin.Engine, *int) {
+	t.Helper()
+	validator, err := NewRequestValidator([]byte(docs.SwaggerInfo.ReadDoc()), mode)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(validator.Validate())
+	calls := 0
+	handler := func(c *gin.Context) {
+		calls++
+		var body map[string]any
+		_ = c.ShouldBindJSON(&body)
+		c.JSON(http.StatusOK, body)
+	}
+	router.POST("/api/calculate", handler)
+	router.PUT("/api/pack-sizes", handler)
+	router.POST("/api/calculate/batch", handler)
+	return router, &calls
+}
+
+func sendJSON(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequestValidator_Enforce(t *testing.T) {
+	router, calls := newRequestValidationRouter(t, RequestValidationEnforce)
+
+	t.Run("valid body reaches the handler unchanged", func(t *testing.T) {
+		w := sendJSON(router, http.MethodPost, "/api/calculate", `{"items_ordered": 251, "pack_sizes": [23, 31], "explain": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"items_ordered": 251, "pack_sizes": [23, 31], "explain": true}`, w.Body.String())
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		details map[string]string
+	}{
+		{
+			name:    "missing required field",
+			method:  http.MethodPost,
+			path:    "/api/calculate",
+			body:    `{"pack_sizes": [23]}`,
+			details: map[string]string{"items_ordered": `property "items_ordered" is missing`},
+		},
+		{
+			name:   "several violations",
+			method: http.MethodPost,
+			path:   "/api/calculate",
+			body:   `{"items_ordered": 0, "pack_sizes": [23, "31"]}`,
+			details: map[string]string{
+				"items_ordered": "number must be at least 1",
+				"pack_sizes.1":  `value must be an integer`,
+			},
+		},
+		{
+			name:    "empty sizes",
+			method:  http.MethodPut,
+			path:    "/api/pack-sizes",
+			body:    `{"sizes": []}`,
+			details: map[string]string{"sizes": "minimum number of items is 1"},
+		},
+		{
+			name:    "malformed JSON",
+			method:  http.MethodPost,
+			path:    "/api/calculate",
+			body:    `{"items_ordered": `,
+			details: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := *calls
+			w := sendJSON(router, tt.method, tt.path, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, before, *calls)
+
+			var resp dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, dto.ErrCodeInvalidRequest, resp.Error)
+			if tt.details != nil {
+				assert.Equal(t, tt.details, resp.Details)
+			} else {
+				assert.Contains(t, resp.Details, requestBodyField)
+			}
+		})
+	}
+
+	t.Run("undocumented operations pass", func(t *testing.T) {
+		w := sendJSON(router, http.MethodPost, "/api/calculate/batch", `{"items": "anything"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("other content types pass", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", strings.NewReader("items_ordered=0"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestRequestValidator_Report(t *testing.T) {
+	router, calls := newRequestValidationRouter(t, RequestValidationReport)
+
+	w := sendJSON(router, http.MethodPost, "/api/calculate", `{"items_ordered": 0}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestNewRequestValidator(t *testing.T) {
+	validator, err := NewRequestValidator(nil, RequestValidationOff)
+	assert.NoError(t, err)
+	assert.Nil(t, validator)
+
+	_, err = NewRequestValidator([]byte(docs.SwaggerInfo.ReadDoc()), "strict")
+	assert.Error(t, err)
+
+	_, err = NewRequestValidator([]byte("not json"), RequestValidationEnforce)
+	assert.Error(t, err)
+
+	// A nil validator lets every request through
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(validator.Validate())
+	router.POST("/api/calculate", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	assert.Equal(t, http.StatusNoContent, sendJSON(router, http.MethodPost, "/api/calculate", `{}`).Code)
+}
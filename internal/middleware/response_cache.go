@@ -0,0 +1,406 @@
+// Package middleware provides HTTP middleware components for the pack service.
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/service/cache"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// ResponseCacheHeader tells whether a response came from the response
+	// cache: "HIT" or "MISS".
+	ResponseCacheHeader = "X-Cache"
+	// DefaultResponseCacheEntries is the number of responses a ResponseCache
+	// keeps unless configured otherwise.
+	DefaultResponseCacheEntries = 1000
+	// responseCacheMaxBody is the largest body that is cached.
+	responseCacheMaxBody = 1 << 20
+)
+
+// ResponseCache keeps the responses of GET endpoints in memory for a TTL, so
+// dashboards polling them do not query MongoDB every time. Responses are kept
+// in groups named after the resource they show, and invalidating a group
+// drops them when the resource changes.
+//
+// Like the result caches of the cache package it is safe for concurrent use
+// and reports cache.Metrics. A nil *ResponseCache caches nothing.
+type ResponseCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	groups     map[string]*responseCacheGroup
+	size       int
+	hits       int64
+	misses     int64
+	evictions  int64
+}
+
+// responseCacheGroup holds the responses of one group. generation changes on
+// every invalidation, so responses rendered before it are not stored.
+type responseCacheGroup struct {
+	entries    map[string]*responseCacheEntry
+	generation uint64
+}
+
+// responseCacheEntry is a cached response.
+type responseCacheEntry struct {
+	status   int
+	header   http.Header
+	body     []byte
+	storedAt time.Time
+}
+
+// NewResponseCache creates a response cache keeping up to maxEntries
+// responses for ttl, or returns nil when ttl is not positive. A maxEntries of
+// zero means DefaultResponseCacheEntries.
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultResponseCacheEntries
+	}
+	return &ResponseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		groups:     make(map[string]*responseCacheGroup),
+	}
+}
+
+// Cached serves GET requests from the cache and caches the 200 and 304
+// responses of the handlers after it under group. Responses are cached per
+// caller, URL, and Accept, Accept-Language and If-None-Match header, and sent
+// with Cache-Control: private. Requests with Cache-Control: no-cache skip the
+// cache but refresh it, and handlers can opt out with Cache-Control: no-store.
+//
+// It must run after authentication and authorization, since cached responses
+// are served without reaching the handler.
+func (rc *ResponseCache) Cached(group string) gin.HandlerFunc {
+	if rc == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		key := responseCacheKey(c)
+		if !strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			if entry, ok := rc.get(group, key); ok {
+				metrics.RecordCacheOperation("response_get", "hit")
+				rc.serve(c, entry)
+				return
+			}
+		}
+		metrics.RecordCacheOperation("response_get", "miss")
+
+		generation := rc.generation(group)
+		before := c.Writer.Header().Clone()
+		writer := &responseCacheWriter{ResponseWriter: c.Writer, maxAge: rc.ttl}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		status := writer.Status()
+		if (status != http.StatusOK && status != http.StatusNotModified) || writer.overflow ||
+			strings.Contains(writer.Header().Get("Cache-Control"), "no-store") {
+			return
+		}
+		header := handlerHeaders(before, writer.Header())
+		if writer.cacheControl {
+			header.Del("Cache-Control")
+		}
+		rc.set(group, key, generation, &responseCacheEntry{
+			status:   status,
+			header:   header,
+			body:     writer.body.Bytes(),
+			storedAt: timeutil.Now(),
+		})
+	}
+}
+
+// Invalidates drops the cached responses of group after every request the
+// handlers after it answered successfully, for routes that change what the
+// group shows.
+func (rc *ResponseCache) Invalidates(group string) gin.HandlerFunc {
+	if rc == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		c.Next()
+		if c.Writer.Status() < http.StatusBadRequest {
+			rc.Invalidate(group)
+		}
+	}
+}
+
+// Invalidate drops the cached responses of group.
+func (rc *ResponseCache) Invalidate(group string) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	g, ok := rc.groups[group]
+	if !ok {
+		return
+	}
+	rc.size -= len(g.entries)
+	g.entries = make(map[string]*responseCacheEntry)
+	g.generation++
+}
+
+// Clear drops every cached response.
+func (rc *ResponseCache) Clear() {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, g := range rc.groups {
+		g.entries = make(map[string]*responseCacheEntry)
+		g.generation++
+	}
+	rc.size = 0
+}
+
+// Metrics returns the cache's performance metrics.
+func (rc *ResponseCache) Metrics() cache.Metrics {
+	if rc == nil {
+		return cache.Metrics{}
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return cache.Metrics{
+		Hits:      rc.hits,
+		Misses:    rc.misses,
+		Evictions: rc.evictions,
+		Size:      rc.size,
+		Capacity:  rc.maxEntries,
+	}
+}
+
+// get returns the unexpired response cached for key in group.
+func (rc *ResponseCache) get(group, key string) (*responseCacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if g, ok := rc.groups[group]; ok {
+		if entry, ok := g.entries[key]; ok && time.Since(entry.storedAt) < rc.ttl {
+			rc.hits++
+			return entry, true
+		}
+	}
+	rc.misses++
+	return nil, false
+}
+
+// generation returns the current generation of group, creating the group so
+// that invalidations from now on are seen by set.
+func (rc *ResponseCache) generation(group string) uint64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return rc.group(group).generation
+}
+
+// group returns the named group, creating it when missing. rc.mu must be held.
+func (rc *ResponseCache) group(name string) *responseCacheGroup {
+	g, ok := rc.groups[name]
+	if !ok {
+		g = &responseCacheGroup{entries: make(map[string]*responseCacheEntry)}
+		rc.groups[name] = g
+	}
+	return g
+}
+
+// set caches entry for key in group, unless the group was invalidated since
+// generation. When the cache is full it drops expired responses, then the
+// oldest one.
+func (rc *ResponseCache) set(group, key string, generation uint64, entry *responseCacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	g := rc.group(group)
+	if g.generation != generation {
+		return
+	}
+	if _, replaced := g.entries[key]; !replaced {
+		if rc.size >= rc.maxEntries {
+			rc.evict()
+		}
+		rc.size++
+	}
+	g.entries[key] = entry
+}
+
+// evict drops the expired responses, or the oldest one when none expired.
+func (rc *ResponseCache) evict() {
+	var oldestGroup *responseCacheGroup
+	var oldestKey string
+	var oldest time.Time
+	for _, g := range rc.groups {
+		for key, entry := range g.entries {
+			switch {
+			case time.Since(entry.storedAt) >= rc.ttl:
+				delete(g.entries, key)
+				rc.size--
+			case oldestGroup == nil || entry.storedAt.Before(oldest):
+				oldestGroup, oldestKey, oldest = g, key, entry.storedAt
+			}
+		}
+	}
+	if rc.size >= rc.maxEntries && oldestGroup != nil {
+		delete(oldestGroup.entries, oldestKey)
+		rc.size--
+		rc.evictions++
+	}
+}
+
+// serve answers c with entry.
+func (rc *ResponseCache) serve(c *gin.Context, entry *responseCacheEntry) {
+	header := c.Writer.Header()
+	for name, values := range entry.header {
+		header[name] = values
+	}
+	age := time.Since(entry.storedAt)
+	header.Set("Age", strconv.Itoa(int(age.Seconds())))
+	if entry.header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", cacheControl(rc.ttl-age))
+	}
+	header.Set(ResponseCacheHeader, "HIT")
+
+	c.Writer.WriteHeader(entry.status)
+	if len(entry.body) > 0 {
+		_, _ = c.Writer.Write(entry.body)
+	}
+	c.Abort()
+}
+
+// responseCacheKey identifies the response to a request: the caller, the URL
+// and the headers that change the response.
+func responseCacheKey(c *gin.Context) string {
+	caller := c.GetHeader("X-API-Key")
+	if userID, exists := c.Get("user_id"); exists {
+		caller = fmt.Sprint(userID)
+	}
+	h := sha256.New()
+	for _, part := range []string{
+		caller,
+		c.Request.URL.RequestURI(),
+		c.GetHeader("Accept"),
+		c.GetHeader("Accept-Language"),
+		c.GetHeader("If-None-Match"),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// handlerHeaders returns the headers of after that the handlers set, leaving
+// out those set before, such as the request ID and content encoding.
+func handlerHeaders(before, after http.Header) http.Header {
+	header := make(http.Header)
+	for name, values := range after {
+		switch name {
+		case "Content-Length", "Set-Cookie", "Age", ResponseCacheHeader:
+			continue
+		}
+		if slices.Equal(before[name], values) {
+			continue
+		}
+		header[name] = append([]string(nil), values...)
+	}
+	return header
+}
+
+// cacheControl returns the Cache-Control of a cached response that stays
+// fresh for maxAge.
+func cacheControl(maxAge time.Duration) string {
+	return "private, max-age=" + strconv.Itoa(max(int(maxAge.Seconds()), 0))
+}
+
+// responseCacheWriter captures the body of a response, and marks it with
+// Cache-Control and X-Cache as its headers are written.
+type responseCacheWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	maxAge   time.Duration
+	prepared bool
+	overflow bool
+	// cacheControl is set when the writer, not the handler, set Cache-Control.
+	cacheControl bool
+}
+
+func (w *responseCacheWriter) prepare(status int) {
+	if w.prepared {
+		return
+	}
+	w.prepared = true
+	if status == http.StatusOK || status == http.StatusNotModified {
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", cacheControl(w.maxAge))
+			w.cacheControl = true
+		}
+		w.Header().Set(ResponseCacheHeader, "MISS")
+	}
+}
+
+func (w *responseCacheWriter) WriteHeader(statusCode int) {
+	w.prepare(statusCode)
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseCacheWriter) Write(b []byte) (int, error) {
+	w.prepare(w.Status())
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseCacheWriter) WriteString(s string) (int, error) {
+	w.prepare(w.Status())
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseCacheWriter) capture(b []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(b) > responseCacheMaxBody {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *responseCacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
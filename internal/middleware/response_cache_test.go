@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResponseCacheRouter serves GET /items from rc, counting the handler
+// calls, and PUT /items invalidating the cached responses.
+func newResponseCacheRouter(rc *ResponseCache, calls *int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items", rc.Cached("items"), func(c *gin.Context) {
+		n := atomic.AddInt64(calls, 1)
+		c.Header("ETag", `"v1"`)
+		c.JSON(http.StatusOK, gin.H{"call": n, "lang": c.GetHeader("Accept-Language")})
+	})
+	router.GET("/missing", rc.Cached("items"), func(c *gin.Context) {
+		atomic.AddInt64(calls, 1)
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+	})
+	router.GET("/private", rc.Cached("items"), func(c *gin.Context) {
+		atomic.AddInt64(calls, 1)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	router.PUT("/items", rc.Invalidates("items"), func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func get(router *gin.Engine, path string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseCache_Cached(t *testing.T) {
+	t.Run("serves repeated requests from the cache", func(t *testing.T) {
+		var calls int64
+		router := newResponseCacheRouter(NewResponseCache(time.Minute, 0), &calls)
+
+		first := get(router, "/items")
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "MISS", first.Header().Get(ResponseCacheHeader))
+		assert.Equal(t, "private, max-age=60", first.Header().Get("Cache-Control"))
+
+		second := get(router, "/items")
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, "HIT", second.Header().Get(ResponseCacheHeader))
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, `"v1"`, second.Header().Get("ETag"))
+		assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+		assert.Contains(t, second.Header().Get("Cache-Control"), "private, max-age=")
+		assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("varies by query and language", func(t *testing.T) {
+		var calls int64
+		router := newResponseCacheRouter(NewResponseCache(time.Minute, 0), &calls)
+
+		get(router, "/items")
+		get(router, "/items?page=2")
+		get(router, "/items", "Accept-Language", "pt-BR")
+		assert.Equal(t, int64(3), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("no-cache requests refresh the cache", func(t *testing.T) {
+		var calls int64
+		router := newResponseCacheRouter(NewResponseCache(time.Minute, 0), &calls)
+
+		get(router, "/items")
+		w := get(router, "/items", "Cache-Control", "no-cache")
+		assert.Equal(t, "MISS", w.Header().Get(ResponseCacheHeader))
+		assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+		assert.Contains(t, get(router, "/items").Body.String(), `"call":2`)
+	})
+
+	t.Run("does not cache errors or no-store responses", func(t *testing.T) {
+		var calls int64
+		router := newResponseCacheRouter(NewResponseCache(time.Minute, 0), &calls)
+
+		get(router, "/missing")
+		w := get(router, "/missing")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("Cache-Control"))
+		get(router, "/private")
+		get(router, "/private")
+		assert.Equal(t, int64(4), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		var calls int64
+		router := newResponseCacheRouter(NewResponseCache(20*time.Millisecond, 0), &calls)
+
+		get(router, "/items")
+		time.Sleep(30 * time.Millisecond)
+		get(router, "/items")
+		assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("nil cache passes requests through", func(t *testing.T) {
+		var calls int64
+		router := newResponseCacheRouter(nil, &calls)
+
+		get(router, "/items")
+		w := get(router, "/items")
+		assert.Empty(t, w.Header().Get(ResponseCacheHeader))
+		assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+	})
+}
+
+func TestResponseCache_Invalidates(t *testing.T) {
+	var calls int64
+	rc := NewResponseCache(time.Minute, 0)
+	router := newResponseCacheRouter(rc, &calls)
+
+	put := func(path string) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, path, nil))
+	}
+
+	get(router, "/items")
+	put("/items?fail=1")
+	get(router, "/items")
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls), "failed writes keep the cached responses")
+
+	put("/items")
+	assert.Contains(t, get(router, "/items").Body.String(), `"call":2`)
+	assert.Equal(t, 1, rc.Metrics().Size)
+}
+
+func TestResponseCache_SkipsResponsesRenderedBeforeInvalidation(t *testing.T) {
+	rc := NewResponseCache(time.Minute, 0)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items", rc.Cached("items"), func(c *gin.Context) {
+		// The resource changes while the response is rendered
+		rc.Invalidate("items")
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	get(router, "/items")
+	assert.Zero(t, rc.Metrics().Size)
+}
+
+func TestResponseCache_Eviction(t *testing.T) {
+	var calls int64
+	rc := NewResponseCache(time.Minute, 2)
+	router := newResponseCacheRouter(rc, &calls)
+
+	for i := range 3 {
+		get(router, "/items?page="+strconv.Itoa(i))
+		time.Sleep(time.Millisecond)
+	}
+	m := rc.Metrics()
+	assert.Equal(t, 2, m.Size)
+	assert.Equal(t, 2, m.Capacity)
+	assert.Equal(t, int64(1), m.Evictions)
+
+	get(router, "/items?page=0")
+	assert.Equal(t, int64(4), atomic.LoadInt64(&calls), "the oldest response was evicted")
+
+	rc.Clear()
+	assert.Zero(t, rc.Metrics().Size)
+}
+
+func TestNewResponseCache(t *testing.T) {
+	assert.Nil(t, NewResponseCache(0, 10))
+
+	rc := NewResponseCache(time.Second, 0)
+	require.NotNil(t, rc)
+	assert.Equal(t, DefaultResponseCacheEntries, rc.Metrics().Capacity)
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownRouteGroup is returned when overriding a route group the policy
+// does not know.
+var ErrUnknownRouteGroup = errors.New("unknown route group")
+
+// RouteGroupAuth reports whether a route group requires authentication.
+type RouteGroupAuth struct {
+	Group    string `json:"group"`
+	Required bool   `json:"required"`
+	// Default is the setting the group started with.
+	Default bool `json:"default"`
+	// Overridden reports whether Required was set at runtime.
+	Overridden bool `json:"overridden"`
+} // @name RouteGroupAuth
+
+// RouteAuthPolicy decides per route group whether requests must
+// authenticate. Each group starts with its configured default, which can be
+// overridden at runtime without a restart. Overrides are kept in memory, so
+// they apply to this instance only and are lost on restart.
+type RouteAuthPolicy struct {
+	mu        sync.RWMutex
+	defaults  map[string]bool
+	overrides map[string]bool
+}
+
+// NewRouteAuthPolicy creates a policy for the groups in defaults, each
+// mapped to whether it requires authentication.
+func NewRouteAuthPolicy(defaults map[string]bool) *RouteAuthPolicy {
+	p := &RouteAuthPolicy{
+		defaults:  make(map[string]bool, len(defaults)),
+		overrides: make(map[string]bool),
+	}
+	for group, required := range defaults {
+		p.defaults[group] = required
+	}
+	return p
+}
+
+// Required reports whether requests to group must authenticate. Unknown
+// groups never do.
+func (p *RouteAuthPolicy) Required(group string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if required, ok := p.overrides[group]; ok {
+		return required
+	}
+	return p.defaults[group]
+}
+
+// Override sets whether group requires authentication until Reset is called.
+func (p *RouteAuthPolicy) Override(group string, required bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.defaults[group]; !ok {
+		return ErrUnknownRouteGroup
+	}
+	p.overrides[group] = required
+	return nil
+}
+
+// Reset drops the override of group, returning it to its default.
+func (p *RouteAuthPolicy) Reset(group string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.defaults[group]; !ok {
+		return ErrUnknownRouteGroup
+	}
+	delete(p.overrides, group)
+	return nil
+}
+
+// Groups returns the current setting of every group, sorted by name.
+func (p *RouteAuthPolicy) Groups() []RouteGroupAuth {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	groups := make([]RouteGroupAuth, 0, len(p.defaults))
+	for group, required := range p.defaults {
+		state := RouteGroupAuth{Group: group, Required: required, Default: required}
+		if override, ok := p.overrides[group]; ok {
+			state.Required = override
+			state.Overridden = true
+		}
+		groups = append(groups, state)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Group < groups[j].Group })
+	return groups
+}
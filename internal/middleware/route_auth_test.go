@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteAuthPolicy(t *testing.T) {
+	policy := NewRouteAuthPolicy(map[string]bool{"calculate": false, "pack-sizes": true})
+
+	assert.False(t, policy.Required("calculate"))
+	assert.True(t, policy.Required("pack-sizes"))
+	assert.False(t, policy.Required("unknown"))
+
+	assert.NoError(t, policy.Override("calculate", true))
+	assert.NoError(t, policy.Override("pack-sizes", false))
+	assert.True(t, policy.Required("calculate"))
+	assert.False(t, policy.Required("pack-sizes"))
+	assert.ErrorIs(t, policy.Override("unknown", true), ErrUnknownRouteGroup)
+
+	assert.Equal(t, []RouteGroupAuth{
+		{Group: "calculate", Required: true, Default: false, Overridden: true},
+		{Group: "pack-sizes", Required: false, Default: true, Overridden: true},
+	}, policy.Groups())
+
+	assert.NoError(t, policy.Reset("pack-sizes"))
+	assert.True(t, policy.Required("pack-sizes"))
+	assert.False(t, policy.Groups()[1].Overridden)
+	assert.ErrorIs(t, policy.Reset("unknown"), ErrUnknownRouteGroup)
+}
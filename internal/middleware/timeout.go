@@ -37,6 +37,8 @@ func Timeout(cfg TimeoutConfig) gin.HandlerFunc {
 
 		// Replace request context with the timeout context
 		c.Request = c.Request.WithContext(ctx)
+		// Read before the handler runs, it may replace the request
+		ids := RequestIDs(c)
 
 		// Mutex to protect concurrent access to gin context
 		var mu sync.Mutex
@@ -70,16 +72,15 @@ func Timeout(cfg TimeoutConfig) gin.HandlerFunc {
 			}
 			if !c.Writer.Written() {
 				locale := i18n.GetLocale(c)
-				requestID := GetRequestID(c)
 				translator := i18n.GetTranslator()
 
 				message := cfg.ErrorMessage
 				if translator != nil {
-					message = translator.Translate(i18n.ErrKeyTimeout, locale)
+					message = translator.TranslateFor(i18n.GetTenant(c), i18n.ErrKeyTimeout, locale)
 				}
 
 				errorResp := dto.NewError(dto.ErrCodeTimeout, message).
-					WithRequestID(requestID)
+					WithIDs(ids)
 				c.AbortWithStatusJSON(http.StatusGatewayTimeout, errorResp)
 			}
 		}
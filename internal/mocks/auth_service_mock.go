@@ -367,6 +367,53 @@ func (_c *MockAuthService_Register_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// RevokeUserTokens provides a mock function with given fields: ctx, userID
+func (_m *MockAuthService) RevokeUserTokens(ctx context.Context, userID primitive.ObjectID) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeUserTokens")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthService_RevokeUserTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeUserTokens'
+type MockAuthService_RevokeUserTokens_Call struct {
+	*mock.Call
+}
+
+// RevokeUserTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID primitive.ObjectID
+func (_e *MockAuthService_Expecter) RevokeUserTokens(ctx interface{}, userID interface{}) *MockAuthService_RevokeUserTokens_Call {
+	return &MockAuthService_RevokeUserTokens_Call{Call: _e.mock.On("RevokeUserTokens", ctx, userID)}
+}
+
+func (_c *MockAuthService_RevokeUserTokens_Call) Run(run func(ctx context.Context, userID primitive.ObjectID)) *MockAuthService_RevokeUserTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID))
+	})
+	return _c
+}
+
+func (_c *MockAuthService_RevokeUserTokens_Call) Return(_a0 error) *MockAuthService_RevokeUserTokens_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthService_RevokeUserTokens_Call) RunAndReturn(run func(context.Context, primitive.ObjectID) error) *MockAuthService_RevokeUserTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ValidateToken provides a mock function with given fields: ctx, tokenString
 func (_m *MockAuthService) ValidateToken(ctx context.Context, tokenString string) (*dto.Claims, error) {
 	ret := _m.Called(ctx, tokenString)
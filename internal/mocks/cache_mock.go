@@ -3,8 +3,10 @@
 package mocks
 
 import (
-	model "github.com/guttosm/pack-service/internal/domain/model"
+	cache "github.com/guttosm/pack-service/internal/service/cache"
 	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
 )
 
 // MockCache is an autogenerated mock type for the Cache type
@@ -53,7 +55,7 @@ func (_c *MockCache_Clear_Call) RunAndReturn(run func()) *MockCache_Clear_Call {
 }
 
 // Get provides a mock function with given fields: key
-func (_m *MockCache) Get(key int) (model.PackResult, bool) {
+func (_m *MockCache) Get(key cache.Key) (model.PackResult, bool) {
 	ret := _m.Called(key)
 
 	if len(ret) == 0 {
@@ -62,16 +64,16 @@ func (_m *MockCache) Get(key int) (model.PackResult, bool) {
 
 	var r0 model.PackResult
 	var r1 bool
-	if rf, ok := ret.Get(0).(func(int) (model.PackResult, bool)); ok {
+	if rf, ok := ret.Get(0).(func(cache.Key) (model.PackResult, bool)); ok {
 		return rf(key)
 	}
-	if rf, ok := ret.Get(0).(func(int) model.PackResult); ok {
+	if rf, ok := ret.Get(0).(func(cache.Key) model.PackResult); ok {
 		r0 = rf(key)
 	} else {
 		r0 = ret.Get(0).(model.PackResult)
 	}
 
-	if rf, ok := ret.Get(1).(func(int) bool); ok {
+	if rf, ok := ret.Get(1).(func(cache.Key) bool); ok {
 		r1 = rf(key)
 	} else {
 		r1 = ret.Get(1).(bool)
@@ -86,14 +88,14 @@ type MockCache_Get_Call struct {
 }
 
 // Get is a helper method to define mock.On call
-//   - key int
+//   - key cache.Key
 func (_e *MockCache_Expecter) Get(key interface{}) *MockCache_Get_Call {
 	return &MockCache_Get_Call{Call: _e.mock.On("Get", key)}
 }
 
-func (_c *MockCache_Get_Call) Run(run func(key int)) *MockCache_Get_Call {
+func (_c *MockCache_Get_Call) Run(run func(key cache.Key)) *MockCache_Get_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int))
+		run(args[0].(cache.Key))
 	})
 	return _c
 }
@@ -103,13 +105,13 @@ func (_c *MockCache_Get_Call) Return(_a0 model.PackResult, _a1 bool) *MockCache_
 	return _c
 }
 
-func (_c *MockCache_Get_Call) RunAndReturn(run func(int) (model.PackResult, bool)) *MockCache_Get_Call {
+func (_c *MockCache_Get_Call) RunAndReturn(run func(cache.Key) (model.PackResult, bool)) *MockCache_Get_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Invalidate provides a mock function with given fields: key
-func (_m *MockCache) Invalidate(key int) {
+func (_m *MockCache) Invalidate(key cache.Key) {
 	_m.Called(key)
 }
 
@@ -119,14 +121,14 @@ type MockCache_Invalidate_Call struct {
 }
 
 // Invalidate is a helper method to define mock.On call
-//   - key int
+//   - key cache.Key
 func (_e *MockCache_Expecter) Invalidate(key interface{}) *MockCache_Invalidate_Call {
 	return &MockCache_Invalidate_Call{Call: _e.mock.On("Invalidate", key)}
 }
 
-func (_c *MockCache_Invalidate_Call) Run(run func(key int)) *MockCache_Invalidate_Call {
+func (_c *MockCache_Invalidate_Call) Run(run func(key cache.Key)) *MockCache_Invalidate_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int))
+		run(args[0].(cache.Key))
 	})
 	return _c
 }
@@ -136,13 +138,13 @@ func (_c *MockCache_Invalidate_Call) Return() *MockCache_Invalidate_Call {
 	return _c
 }
 
-func (_c *MockCache_Invalidate_Call) RunAndReturn(run func(int)) *MockCache_Invalidate_Call {
+func (_c *MockCache_Invalidate_Call) RunAndReturn(run func(cache.Key)) *MockCache_Invalidate_Call {
 	_c.Run(run)
 	return _c
 }
 
 // Set provides a mock function with given fields: key, value
-func (_m *MockCache) Set(key int, value model.PackResult) {
+func (_m *MockCache) Set(key cache.Key, value model.PackResult) {
 	_m.Called(key, value)
 }
 
@@ -152,15 +154,15 @@ type MockCache_Set_Call struct {
 }
 
 // Set is a helper method to define mock.On call
-//   - key int
+//   - key cache.Key
 //   - value model.PackResult
 func (_e *MockCache_Expecter) Set(key interface{}, value interface{}) *MockCache_Set_Call {
 	return &MockCache_Set_Call{Call: _e.mock.On("Set", key, value)}
 }
 
-func (_c *MockCache_Set_Call) Run(run func(key int, value model.PackResult)) *MockCache_Set_Call {
+func (_c *MockCache_Set_Call) Run(run func(key cache.Key, value model.PackResult)) *MockCache_Set_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int), args[1].(model.PackResult))
+		run(args[0].(cache.Key), args[1].(model.PackResult))
 	})
 	return _c
 }
@@ -170,7 +172,7 @@ func (_c *MockCache_Set_Call) Return() *MockCache_Set_Call {
 	return _c
 }
 
-func (_c *MockCache_Set_Call) RunAndReturn(run func(int, model.PackResult)) *MockCache_Set_Call {
+func (_c *MockCache_Set_Call) RunAndReturn(run func(cache.Key, model.PackResult)) *MockCache_Set_Call {
 	_c.Run(run)
 	return _c
 }
@@ -55,7 +55,7 @@ func (_c *MockCacheWithMetrics_Clear_Call) RunAndReturn(run func()) *MockCacheWi
 }
 
 // Get provides a mock function with given fields: key
-func (_m *MockCacheWithMetrics) Get(key int) (model.PackResult, bool) {
+func (_m *MockCacheWithMetrics) Get(key cache.Key) (model.PackResult, bool) {
 	ret := _m.Called(key)
 
 	if len(ret) == 0 {
@@ -64,16 +64,16 @@ func (_m *MockCacheWithMetrics) Get(key int) (model.PackResult, bool) {
 
 	var r0 model.PackResult
 	var r1 bool
-	if rf, ok := ret.Get(0).(func(int) (model.PackResult, bool)); ok {
+	if rf, ok := ret.Get(0).(func(cache.Key) (model.PackResult, bool)); ok {
 		return rf(key)
 	}
-	if rf, ok := ret.Get(0).(func(int) model.PackResult); ok {
+	if rf, ok := ret.Get(0).(func(cache.Key) model.PackResult); ok {
 		r0 = rf(key)
 	} else {
 		r0 = ret.Get(0).(model.PackResult)
 	}
 
-	if rf, ok := ret.Get(1).(func(int) bool); ok {
+	if rf, ok := ret.Get(1).(func(cache.Key) bool); ok {
 		r1 = rf(key)
 	} else {
 		r1 = ret.Get(1).(bool)
@@ -88,14 +88,14 @@ type MockCacheWithMetrics_Get_Call struct {
 }
 
 // Get is a helper method to define mock.On call
-//   - key int
+//   - key cache.Key
 func (_e *MockCacheWithMetrics_Expecter) Get(key interface{}) *MockCacheWithMetrics_Get_Call {
 	return &MockCacheWithMetrics_Get_Call{Call: _e.mock.On("Get", key)}
 }
 
-func (_c *MockCacheWithMetrics_Get_Call) Run(run func(key int)) *MockCacheWithMetrics_Get_Call {
+func (_c *MockCacheWithMetrics_Get_Call) Run(run func(key cache.Key)) *MockCacheWithMetrics_Get_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int))
+		run(args[0].(cache.Key))
 	})
 	return _c
 }
@@ -105,13 +105,13 @@ func (_c *MockCacheWithMetrics_Get_Call) Return(_a0 model.PackResult, _a1 bool)
 	return _c
 }
 
-func (_c *MockCacheWithMetrics_Get_Call) RunAndReturn(run func(int) (model.PackResult, bool)) *MockCacheWithMetrics_Get_Call {
+func (_c *MockCacheWithMetrics_Get_Call) RunAndReturn(run func(cache.Key) (model.PackResult, bool)) *MockCacheWithMetrics_Get_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Invalidate provides a mock function with given fields: key
-func (_m *MockCacheWithMetrics) Invalidate(key int) {
+func (_m *MockCacheWithMetrics) Invalidate(key cache.Key) {
 	_m.Called(key)
 }
 
@@ -121,14 +121,14 @@ type MockCacheWithMetrics_Invalidate_Call struct {
 }
 
 // Invalidate is a helper method to define mock.On call
-//   - key int
+//   - key cache.Key
 func (_e *MockCacheWithMetrics_Expecter) Invalidate(key interface{}) *MockCacheWithMetrics_Invalidate_Call {
 	return &MockCacheWithMetrics_Invalidate_Call{Call: _e.mock.On("Invalidate", key)}
 }
 
-func (_c *MockCacheWithMetrics_Invalidate_Call) Run(run func(key int)) *MockCacheWithMetrics_Invalidate_Call {
+func (_c *MockCacheWithMetrics_Invalidate_Call) Run(run func(key cache.Key)) *MockCacheWithMetrics_Invalidate_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int))
+		run(args[0].(cache.Key))
 	})
 	return _c
 }
@@ -138,7 +138,7 @@ func (_c *MockCacheWithMetrics_Invalidate_Call) Return() *MockCacheWithMetrics_I
 	return _c
 }
 
-func (_c *MockCacheWithMetrics_Invalidate_Call) RunAndReturn(run func(int)) *MockCacheWithMetrics_Invalidate_Call {
+func (_c *MockCacheWithMetrics_Invalidate_Call) RunAndReturn(run func(cache.Key)) *MockCacheWithMetrics_Invalidate_Call {
 	_c.Run(run)
 	return _c
 }
@@ -189,7 +189,7 @@ func (_c *MockCacheWithMetrics_Metrics_Call) RunAndReturn(run func() cache.Metri
 }
 
 // Set provides a mock function with given fields: key, value
-func (_m *MockCacheWithMetrics) Set(key int, value model.PackResult) {
+func (_m *MockCacheWithMetrics) Set(key cache.Key, value model.PackResult) {
 	_m.Called(key, value)
 }
 
@@ -199,15 +199,15 @@ type MockCacheWithMetrics_Set_Call struct {
 }
 
 // Set is a helper method to define mock.On call
-//   - key int
+//   - key cache.Key
 //   - value model.PackResult
 func (_e *MockCacheWithMetrics_Expecter) Set(key interface{}, value interface{}) *MockCacheWithMetrics_Set_Call {
 	return &MockCacheWithMetrics_Set_Call{Call: _e.mock.On("Set", key, value)}
 }
 
-func (_c *MockCacheWithMetrics_Set_Call) Run(run func(key int, value model.PackResult)) *MockCacheWithMetrics_Set_Call {
+func (_c *MockCacheWithMetrics_Set_Call) Run(run func(key cache.Key, value model.PackResult)) *MockCacheWithMetrics_Set_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int), args[1].(model.PackResult))
+		run(args[0].(cache.Key), args[1].(model.PackResult))
 	})
 	return _c
 }
@@ -217,7 +217,7 @@ func (_c *MockCacheWithMetrics_Set_Call) Return() *MockCacheWithMetrics_Set_Call
 	return _c
 }
 
-func (_c *MockCacheWithMetrics_Set_Call) RunAndReturn(run func(int, model.PackResult)) *MockCacheWithMetrics_Set_Call {
+func (_c *MockCacheWithMetrics_Set_Call) RunAndReturn(run func(cache.Key, model.PackResult)) *MockCacheWithMetrics_Set_Call {
 	_c.Run(run)
 	return _c
 }
@@ -0,0 +1,270 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/guttosm/pack-service/internal/domain/dto"
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// MockCalculationHistoryService is an autogenerated mock type for the CalculationHistoryService type
+type MockCalculationHistoryService struct {
+	mock.Mock
+}
+
+type MockCalculationHistoryService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCalculationHistoryService) EXPECT() *MockCalculationHistoryService_Expecter {
+	return &MockCalculationHistoryService_Expecter{mock: &_m.Mock}
+}
+
+// Flush provides a mock function with given fields: ctx
+func (_m *MockCalculationHistoryService) Flush(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Flush")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockCalculationHistoryService_Flush_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Flush'
+type MockCalculationHistoryService_Flush_Call struct {
+	*mock.Call
+}
+
+// Flush is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockCalculationHistoryService_Expecter) Flush(ctx interface{}) *MockCalculationHistoryService_Flush_Call {
+	return &MockCalculationHistoryService_Flush_Call{Call: _e.mock.On("Flush", ctx)}
+}
+
+func (_c *MockCalculationHistoryService_Flush_Call) Run(run func(ctx context.Context)) *MockCalculationHistoryService_Flush_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Flush_Call) Return(_a0 error) *MockCalculationHistoryService_Flush_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Flush_Call) RunAndReturn(run func(context.Context) error) *MockCalculationHistoryService_Flush_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, filter, limit, offset
+func (_m *MockCalculationHistoryService) List(ctx context.Context, filter dto.CalculationFilter, limit int, offset int) (*dto.CalculationPage, error) {
+	ret := _m.Called(ctx, filter, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 *dto.CalculationPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.CalculationFilter, int, int) (*dto.CalculationPage, error)); ok {
+		return rf(ctx, filter, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.CalculationFilter, int, int) *dto.CalculationPage); ok {
+		r0 = rf(ctx, filter, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CalculationPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.CalculationFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCalculationHistoryService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockCalculationHistoryService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter dto.CalculationFilter
+//   - limit int
+//   - offset int
+func (_e *MockCalculationHistoryService_Expecter) List(ctx interface{}, filter interface{}, limit interface{}, offset interface{}) *MockCalculationHistoryService_List_Call {
+	return &MockCalculationHistoryService_List_Call{Call: _e.mock.On("List", ctx, filter, limit, offset)}
+}
+
+func (_c *MockCalculationHistoryService_List_Call) Run(run func(ctx context.Context, filter dto.CalculationFilter, limit int, offset int)) *MockCalculationHistoryService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.CalculationFilter), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_List_Call) Return(_a0 *dto.CalculationPage, _a1 error) *MockCalculationHistoryService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_List_Call) RunAndReturn(run func(context.Context, dto.CalculationFilter, int, int) (*dto.CalculationPage, error)) *MockCalculationHistoryService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Record provides a mock function with given fields: calc
+func (_m *MockCalculationHistoryService) Record(calc *model.Calculation) {
+	_m.Called(calc)
+}
+
+// MockCalculationHistoryService_Record_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Record'
+type MockCalculationHistoryService_Record_Call struct {
+	*mock.Call
+}
+
+// Record is a helper method to define mock.On call
+//   - calc *model.Calculation
+func (_e *MockCalculationHistoryService_Expecter) Record(calc interface{}) *MockCalculationHistoryService_Record_Call {
+	return &MockCalculationHistoryService_Record_Call{Call: _e.mock.On("Record", calc)}
+}
+
+func (_c *MockCalculationHistoryService_Record_Call) Run(run func(calc *model.Calculation)) *MockCalculationHistoryService_Record_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*model.Calculation))
+	})
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Record_Call) Return() *MockCalculationHistoryService_Record_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Record_Call) RunAndReturn(run func(*model.Calculation)) *MockCalculationHistoryService_Record_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Rollups provides a mock function with given fields: ctx, filter
+func (_m *MockCalculationHistoryService) Rollups(ctx context.Context, filter dto.RollupFilter) (*dto.CalculationRollupReport, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollups")
+	}
+
+	var r0 *dto.CalculationRollupReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.RollupFilter) (*dto.CalculationRollupReport, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.RollupFilter) *dto.CalculationRollupReport); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CalculationRollupReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.RollupFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCalculationHistoryService_Rollups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rollups'
+type MockCalculationHistoryService_Rollups_Call struct {
+	*mock.Call
+}
+
+// Rollups is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter dto.RollupFilter
+func (_e *MockCalculationHistoryService_Expecter) Rollups(ctx interface{}, filter interface{}) *MockCalculationHistoryService_Rollups_Call {
+	return &MockCalculationHistoryService_Rollups_Call{Call: _e.mock.On("Rollups", ctx, filter)}
+}
+
+func (_c *MockCalculationHistoryService_Rollups_Call) Run(run func(ctx context.Context, filter dto.RollupFilter)) *MockCalculationHistoryService_Rollups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.RollupFilter))
+	})
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Rollups_Call) Return(_a0 *dto.CalculationRollupReport, _a1 error) *MockCalculationHistoryService_Rollups_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Rollups_Call) RunAndReturn(run func(context.Context, dto.RollupFilter) (*dto.CalculationRollupReport, error)) *MockCalculationHistoryService_Rollups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stop provides a mock function with no fields
+func (_m *MockCalculationHistoryService) Stop() {
+	_m.Called()
+}
+
+// MockCalculationHistoryService_Stop_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stop'
+type MockCalculationHistoryService_Stop_Call struct {
+	*mock.Call
+}
+
+// Stop is a helper method to define mock.On call
+func (_e *MockCalculationHistoryService_Expecter) Stop() *MockCalculationHistoryService_Stop_Call {
+	return &MockCalculationHistoryService_Stop_Call{Call: _e.mock.On("Stop")}
+}
+
+func (_c *MockCalculationHistoryService_Stop_Call) Run(run func()) *MockCalculationHistoryService_Stop_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Stop_Call) Return() *MockCalculationHistoryService_Stop_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockCalculationHistoryService_Stop_Call) RunAndReturn(run func()) *MockCalculationHistoryService_Stop_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewMockCalculationHistoryService creates a new instance of MockCalculationHistoryService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCalculationHistoryService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCalculationHistoryService {
+	mock := &MockCalculationHistoryService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
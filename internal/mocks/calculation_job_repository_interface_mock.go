@@ -0,0 +1,407 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockCalculationJobRepositoryInterface is an autogenerated mock type for the CalculationJobRepositoryInterface type
+type MockCalculationJobRepositoryInterface struct {
+	mock.Mock
+}
+
+type MockCalculationJobRepositoryInterface_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCalculationJobRepositoryInterface) EXPECT() *MockCalculationJobRepositoryInterface_Expecter {
+	return &MockCalculationJobRepositoryInterface_Expecter{mock: &_m.Mock}
+}
+
+// ClaimNext provides a mock function with given fields: ctx, lease
+func (_m *MockCalculationJobRepositoryInterface) ClaimNext(ctx context.Context, lease time.Duration) (*model.CalculationJob, error) {
+	ret := _m.Called(ctx, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimNext")
+	}
+
+	var r0 *model.CalculationJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) (*model.CalculationJob, error)); ok {
+		return rf(ctx, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) *model.CalculationJob); ok {
+		r0 = rf(ctx, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CalculationJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCalculationJobRepositoryInterface_ClaimNext_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimNext'
+type MockCalculationJobRepositoryInterface_ClaimNext_Call struct {
+	*mock.Call
+}
+
+// ClaimNext is a helper method to define mock.On call
+//   - ctx context.Context
+//   - lease time.Duration
+func (_e *MockCalculationJobRepositoryInterface_Expecter) ClaimNext(ctx interface{}, lease interface{}) *MockCalculationJobRepositoryInterface_ClaimNext_Call {
+	return &MockCalculationJobRepositoryInterface_ClaimNext_Call{Call: _e.mock.On("ClaimNext", ctx, lease)}
+}
+
+func (_c *MockCalculationJobRepositoryInterface_ClaimNext_Call) Run(run func(ctx context.Context, lease time.Duration)) *MockCalculationJobRepositoryInterface_ClaimNext_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_ClaimNext_Call) Return(_a0 *model.CalculationJob, _a1 error) *MockCalculationJobRepositoryInterface_ClaimNext_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_ClaimNext_Call) RunAndReturn(run func(context.Context, time.Duration) (*model.CalculationJob, error)) *MockCalculationJobRepositoryInterface_ClaimNext_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Complete provides a mock function with given fields: ctx, id, results, succeeded, failed, expiresAt
+func (_m *MockCalculationJobRepositoryInterface) Complete(ctx context.Context, id string, results []byte, succeeded int, failed int, expiresAt time.Time) error {
+	ret := _m.Called(ctx, id, results, succeeded, failed, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, int, int, time.Time) error); ok {
+		r0 = rf(ctx, id, results, succeeded, failed, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockCalculationJobRepositoryInterface_Complete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Complete'
+type MockCalculationJobRepositoryInterface_Complete_Call struct {
+	*mock.Call
+}
+
+// Complete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - results []byte
+//   - succeeded int
+//   - failed int
+//   - expiresAt time.Time
+func (_e *MockCalculationJobRepositoryInterface_Expecter) Complete(ctx interface{}, id interface{}, results interface{}, succeeded interface{}, failed interface{}, expiresAt interface{}) *MockCalculationJobRepositoryInterface_Complete_Call {
+	return &MockCalculationJobRepositoryInterface_Complete_Call{Call: _e.mock.On("Complete", ctx, id, results, succeeded, failed, expiresAt)}
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Complete_Call) Run(run func(ctx context.Context, id string, results []byte, succeeded int, failed int, expiresAt time.Time)) *MockCalculationJobRepositoryInterface_Complete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte), args[3].(int), args[4].(int), args[5].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Complete_Call) Return(_a0 error) *MockCalculationJobRepositoryInterface_Complete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Complete_Call) RunAndReturn(run func(context.Context, string, []byte, int, int, time.Time) error) *MockCalculationJobRepositoryInterface_Complete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountUnfinished provides a mock function with given fields: ctx
+func (_m *MockCalculationJobRepositoryInterface) CountUnfinished(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUnfinished")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCalculationJobRepositoryInterface_CountUnfinished_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountUnfinished'
+type MockCalculationJobRepositoryInterface_CountUnfinished_Call struct {
+	*mock.Call
+}
+
+// CountUnfinished is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockCalculationJobRepositoryInterface_Expecter) CountUnfinished(ctx interface{}) *MockCalculationJobRepositoryInterface_CountUnfinished_Call {
+	return &MockCalculationJobRepositoryInterface_CountUnfinished_Call{Call: _e.mock.On("CountUnfinished", ctx)}
+}
+
+func (_c *MockCalculationJobRepositoryInterface_CountUnfinished_Call) Run(run func(ctx context.Context)) *MockCalculationJobRepositoryInterface_CountUnfinished_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_CountUnfinished_Call) Return(_a0 int64, _a1 error) *MockCalculationJobRepositoryInterface_CountUnfinished_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_CountUnfinished_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockCalculationJobRepositoryInterface_CountUnfinished_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: ctx, job
+func (_m *MockCalculationJobRepositoryInterface) Create(ctx context.Context, job *model.CalculationJob) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CalculationJob) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockCalculationJobRepositoryInterface_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockCalculationJobRepositoryInterface_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - job *model.CalculationJob
+func (_e *MockCalculationJobRepositoryInterface_Expecter) Create(ctx interface{}, job interface{}) *MockCalculationJobRepositoryInterface_Create_Call {
+	return &MockCalculationJobRepositoryInterface_Create_Call{Call: _e.mock.On("Create", ctx, job)}
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Create_Call) Run(run func(ctx context.Context, job *model.CalculationJob)) *MockCalculationJobRepositoryInterface_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.CalculationJob))
+	})
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Create_Call) Return(_a0 error) *MockCalculationJobRepositoryInterface_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Create_Call) RunAndReturn(run func(context.Context, *model.CalculationJob) error) *MockCalculationJobRepositoryInterface_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Fail provides a mock function with given fields: ctx, id, reason, expiresAt
+func (_m *MockCalculationJobRepositoryInterface) Fail(ctx context.Context, id string, reason string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, id, reason, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Fail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, id, reason, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockCalculationJobRepositoryInterface_Fail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fail'
+type MockCalculationJobRepositoryInterface_Fail_Call struct {
+	*mock.Call
+}
+
+// Fail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - reason string
+//   - expiresAt time.Time
+func (_e *MockCalculationJobRepositoryInterface_Expecter) Fail(ctx interface{}, id interface{}, reason interface{}, expiresAt interface{}) *MockCalculationJobRepositoryInterface_Fail_Call {
+	return &MockCalculationJobRepositoryInterface_Fail_Call{Call: _e.mock.On("Fail", ctx, id, reason, expiresAt)}
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Fail_Call) Run(run func(ctx context.Context, id string, reason string, expiresAt time.Time)) *MockCalculationJobRepositoryInterface_Fail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Fail_Call) Return(_a0 error) *MockCalculationJobRepositoryInterface_Fail_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Fail_Call) RunAndReturn(run func(context.Context, string, string, time.Time) error) *MockCalculationJobRepositoryInterface_Fail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: ctx, id
+func (_m *MockCalculationJobRepositoryInterface) Get(ctx context.Context, id string) (*model.CalculationJob, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.CalculationJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.CalculationJob, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.CalculationJob); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CalculationJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCalculationJobRepositoryInterface_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockCalculationJobRepositoryInterface_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockCalculationJobRepositoryInterface_Expecter) Get(ctx interface{}, id interface{}) *MockCalculationJobRepositoryInterface_Get_Call {
+	return &MockCalculationJobRepositoryInterface_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Get_Call) Run(run func(ctx context.Context, id string)) *MockCalculationJobRepositoryInterface_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Get_Call) Return(_a0 *model.CalculationJob, _a1 error) *MockCalculationJobRepositoryInterface_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Get_Call) RunAndReturn(run func(context.Context, string) (*model.CalculationJob, error)) *MockCalculationJobRepositoryInterface_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Release provides a mock function with given fields: ctx, id
+func (_m *MockCalculationJobRepositoryInterface) Release(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockCalculationJobRepositoryInterface_Release_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Release'
+type MockCalculationJobRepositoryInterface_Release_Call struct {
+	*mock.Call
+}
+
+// Release is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockCalculationJobRepositoryInterface_Expecter) Release(ctx interface{}, id interface{}) *MockCalculationJobRepositoryInterface_Release_Call {
+	return &MockCalculationJobRepositoryInterface_Release_Call{Call: _e.mock.On("Release", ctx, id)}
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Release_Call) Run(run func(ctx context.Context, id string)) *MockCalculationJobRepositoryInterface_Release_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Release_Call) Return(_a0 error) *MockCalculationJobRepositoryInterface_Release_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCalculationJobRepositoryInterface_Release_Call) RunAndReturn(run func(context.Context, string) error) *MockCalculationJobRepositoryInterface_Release_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockCalculationJobRepositoryInterface creates a new instance of MockCalculationJobRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCalculationJobRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCalculationJobRepositoryInterface {
+	mock := &MockCalculationJobRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
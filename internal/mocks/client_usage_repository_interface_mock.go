@@ -0,0 +1,143 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockClientUsageRepositoryInterface is an autogenerated mock type for the ClientUsageRepositoryInterface type
+type MockClientUsageRepositoryInterface struct {
+	mock.Mock
+}
+
+type MockClientUsageRepositoryInterface_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockClientUsageRepositoryInterface) EXPECT() *MockClientUsageRepositoryInterface_Expecter {
+	return &MockClientUsageRepositoryInterface_Expecter{mock: &_m.Mock}
+}
+
+// Increment provides a mock function with given fields: ctx, usages
+func (_m *MockClientUsageRepositoryInterface) Increment(ctx context.Context, usages []*model.ClientUsage) error {
+	ret := _m.Called(ctx, usages)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Increment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.ClientUsage) error); ok {
+		r0 = rf(ctx, usages)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockClientUsageRepositoryInterface_Increment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Increment'
+type MockClientUsageRepositoryInterface_Increment_Call struct {
+	*mock.Call
+}
+
+// Increment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - usages []*model.ClientUsage
+func (_e *MockClientUsageRepositoryInterface_Expecter) Increment(ctx interface{}, usages interface{}) *MockClientUsageRepositoryInterface_Increment_Call {
+	return &MockClientUsageRepositoryInterface_Increment_Call{Call: _e.mock.On("Increment", ctx, usages)}
+}
+
+func (_c *MockClientUsageRepositoryInterface_Increment_Call) Run(run func(ctx context.Context, usages []*model.ClientUsage)) *MockClientUsageRepositoryInterface_Increment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]*model.ClientUsage))
+	})
+	return _c
+}
+
+func (_c *MockClientUsageRepositoryInterface_Increment_Call) Return(_a0 error) *MockClientUsageRepositoryInterface_Increment_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockClientUsageRepositoryInterface_Increment_Call) RunAndReturn(run func(context.Context, []*model.ClientUsage) error) *MockClientUsageRepositoryInterface_Increment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, client
+func (_m *MockClientUsageRepositoryInterface) List(ctx context.Context, client string) ([]*model.ClientUsage, error) {
+	ret := _m.Called(ctx, client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.ClientUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.ClientUsage, error)); ok {
+		return rf(ctx, client)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.ClientUsage); ok {
+		r0 = rf(ctx, client)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.ClientUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, client)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClientUsageRepositoryInterface_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockClientUsageRepositoryInterface_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+func (_e *MockClientUsageRepositoryInterface_Expecter) List(ctx interface{}, client interface{}) *MockClientUsageRepositoryInterface_List_Call {
+	return &MockClientUsageRepositoryInterface_List_Call{Call: _e.mock.On("List", ctx, client)}
+}
+
+func (_c *MockClientUsageRepositoryInterface_List_Call) Run(run func(ctx context.Context, client string)) *MockClientUsageRepositoryInterface_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockClientUsageRepositoryInterface_List_Call) Return(_a0 []*model.ClientUsage, _a1 error) *MockClientUsageRepositoryInterface_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClientUsageRepositoryInterface_List_Call) RunAndReturn(run func(context.Context, string) ([]*model.ClientUsage, error)) *MockClientUsageRepositoryInterface_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockClientUsageRepositoryInterface creates a new instance of MockClientUsageRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockClientUsageRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockClientUsageRepositoryInterface {
+	mock := &MockClientUsageRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,209 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockClientUsageService is an autogenerated mock type for the ClientUsageService type
+type MockClientUsageService struct {
+	mock.Mock
+}
+
+type MockClientUsageService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockClientUsageService) EXPECT() *MockClientUsageService_Expecter {
+	return &MockClientUsageService_Expecter{mock: &_m.Mock}
+}
+
+// Flush provides a mock function with given fields: ctx
+func (_m *MockClientUsageService) Flush(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Flush")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockClientUsageService_Flush_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Flush'
+type MockClientUsageService_Flush_Call struct {
+	*mock.Call
+}
+
+// Flush is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockClientUsageService_Expecter) Flush(ctx interface{}) *MockClientUsageService_Flush_Call {
+	return &MockClientUsageService_Flush_Call{Call: _e.mock.On("Flush", ctx)}
+}
+
+func (_c *MockClientUsageService_Flush_Call) Run(run func(ctx context.Context)) *MockClientUsageService_Flush_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockClientUsageService_Flush_Call) Return(_a0 error) *MockClientUsageService_Flush_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockClientUsageService_Flush_Call) RunAndReturn(run func(context.Context) error) *MockClientUsageService_Flush_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, client
+func (_m *MockClientUsageService) List(ctx context.Context, client string) ([]*model.ClientUsage, error) {
+	ret := _m.Called(ctx, client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.ClientUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.ClientUsage, error)); ok {
+		return rf(ctx, client)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.ClientUsage); ok {
+		r0 = rf(ctx, client)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.ClientUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, client)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClientUsageService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockClientUsageService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+func (_e *MockClientUsageService_Expecter) List(ctx interface{}, client interface{}) *MockClientUsageService_List_Call {
+	return &MockClientUsageService_List_Call{Call: _e.mock.On("List", ctx, client)}
+}
+
+func (_c *MockClientUsageService_List_Call) Run(run func(ctx context.Context, client string)) *MockClientUsageService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockClientUsageService_List_Call) Return(_a0 []*model.ClientUsage, _a1 error) *MockClientUsageService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClientUsageService_List_Call) RunAndReturn(run func(context.Context, string) ([]*model.ClientUsage, error)) *MockClientUsageService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Record provides a mock function with given fields: client, userAgent, clientVersion
+func (_m *MockClientUsageService) Record(client string, userAgent string, clientVersion string) {
+	_m.Called(client, userAgent, clientVersion)
+}
+
+// MockClientUsageService_Record_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Record'
+type MockClientUsageService_Record_Call struct {
+	*mock.Call
+}
+
+// Record is a helper method to define mock.On call
+//   - client string
+//   - userAgent string
+//   - clientVersion string
+func (_e *MockClientUsageService_Expecter) Record(client interface{}, userAgent interface{}, clientVersion interface{}) *MockClientUsageService_Record_Call {
+	return &MockClientUsageService_Record_Call{Call: _e.mock.On("Record", client, userAgent, clientVersion)}
+}
+
+func (_c *MockClientUsageService_Record_Call) Run(run func(client string, userAgent string, clientVersion string)) *MockClientUsageService_Record_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockClientUsageService_Record_Call) Return() *MockClientUsageService_Record_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockClientUsageService_Record_Call) RunAndReturn(run func(string, string, string)) *MockClientUsageService_Record_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Stop provides a mock function with no fields
+func (_m *MockClientUsageService) Stop() {
+	_m.Called()
+}
+
+// MockClientUsageService_Stop_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stop'
+type MockClientUsageService_Stop_Call struct {
+	*mock.Call
+}
+
+// Stop is a helper method to define mock.On call
+func (_e *MockClientUsageService_Expecter) Stop() *MockClientUsageService_Stop_Call {
+	return &MockClientUsageService_Stop_Call{Call: _e.mock.On("Stop")}
+}
+
+func (_c *MockClientUsageService_Stop_Call) Run(run func()) *MockClientUsageService_Stop_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClientUsageService_Stop_Call) Return() *MockClientUsageService_Stop_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockClientUsageService_Stop_Call) RunAndReturn(run func()) *MockClientUsageService_Stop_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewMockClientUsageService creates a new instance of MockClientUsageService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockClientUsageService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockClientUsageService {
+	mock := &MockClientUsageService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
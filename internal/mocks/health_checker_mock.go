@@ -2,7 +2,11 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockHealthChecker is an autogenerated mock type for the HealthChecker type
 type MockHealthChecker struct {
@@ -17,17 +21,17 @@ func (_m *MockHealthChecker) EXPECT() *MockHealthChecker_Expecter {
 	return &MockHealthChecker_Expecter{mock: &_m.Mock}
 }
 
-// Check provides a mock function with no fields
-func (_m *MockHealthChecker) Check() error {
-	ret := _m.Called()
+// Check provides a mock function with given fields: ctx
+func (_m *MockHealthChecker) Check(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Check")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -41,13 +45,14 @@ type MockHealthChecker_Check_Call struct {
 }
 
 // Check is a helper method to define mock.On call
-func (_e *MockHealthChecker_Expecter) Check() *MockHealthChecker_Check_Call {
-	return &MockHealthChecker_Check_Call{Call: _e.mock.On("Check")}
+//   - ctx context.Context
+func (_e *MockHealthChecker_Expecter) Check(ctx interface{}) *MockHealthChecker_Check_Call {
+	return &MockHealthChecker_Check_Call{Call: _e.mock.On("Check", ctx)}
 }
 
-func (_c *MockHealthChecker_Check_Call) Run(run func()) *MockHealthChecker_Check_Call {
+func (_c *MockHealthChecker_Check_Call) Run(run func(ctx context.Context)) *MockHealthChecker_Check_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
@@ -57,7 +62,7 @@ func (_c *MockHealthChecker_Check_Call) Return(_a0 error) *MockHealthChecker_Che
 	return _c
 }
 
-func (_c *MockHealthChecker_Check_Call) RunAndReturn(run func() error) *MockHealthChecker_Check_Call {
+func (_c *MockHealthChecker_Check_Call) RunAndReturn(run func(context.Context) error) *MockHealthChecker_Check_Call {
 	_c.Call.Return(run)
 	return _c
 }
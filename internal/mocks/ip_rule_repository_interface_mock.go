@@ -0,0 +1,201 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockIPRuleRepositoryInterface is an autogenerated mock type for the IPRuleRepositoryInterface type
+type MockIPRuleRepositoryInterface struct {
+	mock.Mock
+}
+
+type MockIPRuleRepositoryInterface_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockIPRuleRepositoryInterface) EXPECT() *MockIPRuleRepositoryInterface_Expecter {
+	return &MockIPRuleRepositoryInterface_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, rule
+func (_m *MockIPRuleRepositoryInterface) Create(ctx context.Context, rule *model.IPRule) error {
+	ret := _m.Called(ctx, rule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.IPRule) error); ok {
+		r0 = rf(ctx, rule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockIPRuleRepositoryInterface_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockIPRuleRepositoryInterface_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rule *model.IPRule
+func (_e *MockIPRuleRepositoryInterface_Expecter) Create(ctx interface{}, rule interface{}) *MockIPRuleRepositoryInterface_Create_Call {
+	return &MockIPRuleRepositoryInterface_Create_Call{Call: _e.mock.On("Create", ctx, rule)}
+}
+
+func (_c *MockIPRuleRepositoryInterface_Create_Call) Run(run func(ctx context.Context, rule *model.IPRule)) *MockIPRuleRepositoryInterface_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.IPRule))
+	})
+	return _c
+}
+
+func (_c *MockIPRuleRepositoryInterface_Create_Call) Return(_a0 error) *MockIPRuleRepositoryInterface_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockIPRuleRepositoryInterface_Create_Call) RunAndReturn(run func(context.Context, *model.IPRule) error) *MockIPRuleRepositoryInterface_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *MockIPRuleRepositoryInterface) Delete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIPRuleRepositoryInterface_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockIPRuleRepositoryInterface_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+func (_e *MockIPRuleRepositoryInterface_Expecter) Delete(ctx interface{}, id interface{}) *MockIPRuleRepositoryInterface_Delete_Call {
+	return &MockIPRuleRepositoryInterface_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *MockIPRuleRepositoryInterface_Delete_Call) Run(run func(ctx context.Context, id primitive.ObjectID)) *MockIPRuleRepositoryInterface_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID))
+	})
+	return _c
+}
+
+func (_c *MockIPRuleRepositoryInterface_Delete_Call) Return(_a0 bool, _a1 error) *MockIPRuleRepositoryInterface_Delete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIPRuleRepositoryInterface_Delete_Call) RunAndReturn(run func(context.Context, primitive.ObjectID) (bool, error)) *MockIPRuleRepositoryInterface_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx
+func (_m *MockIPRuleRepositoryInterface) List(ctx context.Context) ([]*model.IPRule, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.IPRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.IPRule, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.IPRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.IPRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockIPRuleRepositoryInterface_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockIPRuleRepositoryInterface_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockIPRuleRepositoryInterface_Expecter) List(ctx interface{}) *MockIPRuleRepositoryInterface_List_Call {
+	return &MockIPRuleRepositoryInterface_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockIPRuleRepositoryInterface_List_Call) Run(run func(ctx context.Context)) *MockIPRuleRepositoryInterface_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockIPRuleRepositoryInterface_List_Call) Return(_a0 []*model.IPRule, _a1 error) *MockIPRuleRepositoryInterface_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockIPRuleRepositoryInterface_List_Call) RunAndReturn(run func(context.Context) ([]*model.IPRule, error)) *MockIPRuleRepositoryInterface_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockIPRuleRepositoryInterface creates a new instance of MockIPRuleRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockIPRuleRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockIPRuleRepositoryInterface {
+	mock := &MockIPRuleRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -22,6 +22,65 @@ func (_m *MockLoggingService) EXPECT() *MockLoggingService_Expecter {
 	return &MockLoggingService_Expecter{mock: &_m.Mock}
 }
 
+// CalculationsSinceConfigVersion provides a mock function with given fields: ctx, sinceVersion
+func (_m *MockLoggingService) CalculationsSinceConfigVersion(ctx context.Context, sinceVersion int) ([]model.ConfigVersionImpact, error) {
+	ret := _m.Called(ctx, sinceVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CalculationsSinceConfigVersion")
+	}
+
+	var r0 []model.ConfigVersionImpact
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]model.ConfigVersionImpact, error)); ok {
+		return rf(ctx, sinceVersion)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []model.ConfigVersionImpact); ok {
+		r0 = rf(ctx, sinceVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ConfigVersionImpact)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, sinceVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLoggingService_CalculationsSinceConfigVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CalculationsSinceConfigVersion'
+type MockLoggingService_CalculationsSinceConfigVersion_Call struct {
+	*mock.Call
+}
+
+// CalculationsSinceConfigVersion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sinceVersion int
+func (_e *MockLoggingService_Expecter) CalculationsSinceConfigVersion(ctx interface{}, sinceVersion interface{}) *MockLoggingService_CalculationsSinceConfigVersion_Call {
+	return &MockLoggingService_CalculationsSinceConfigVersion_Call{Call: _e.mock.On("CalculationsSinceConfigVersion", ctx, sinceVersion)}
+}
+
+func (_c *MockLoggingService_CalculationsSinceConfigVersion_Call) Run(run func(ctx context.Context, sinceVersion int)) *MockLoggingService_CalculationsSinceConfigVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockLoggingService_CalculationsSinceConfigVersion_Call) Return(_a0 []model.ConfigVersionImpact, _a1 error) *MockLoggingService_CalculationsSinceConfigVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLoggingService_CalculationsSinceConfigVersion_Call) RunAndReturn(run func(context.Context, int) ([]model.ConfigVersionImpact, error)) *MockLoggingService_CalculationsSinceConfigVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CountLogs provides a mock function with given fields: ctx, opts
 func (_m *MockLoggingService) CountLogs(ctx context.Context, opts model.LogQueryOptions) (int64, error) {
 	ret := _m.Called(ctx, opts)
@@ -173,6 +232,111 @@ func (_c *MockLoggingService_CreateLogs_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// ExportLogs provides a mock function with given fields: ctx, opts, fn
+func (_m *MockLoggingService) ExportLogs(ctx context.Context, opts model.LogQueryOptions, fn func(model.LogEntry) error) error {
+	ret := _m.Called(ctx, opts, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportLogs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.LogQueryOptions, func(model.LogEntry) error) error); ok {
+		r0 = rf(ctx, opts, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockLoggingService_ExportLogs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportLogs'
+type MockLoggingService_ExportLogs_Call struct {
+	*mock.Call
+}
+
+// ExportLogs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opts model.LogQueryOptions
+//   - fn func(model.LogEntry) error
+func (_e *MockLoggingService_Expecter) ExportLogs(ctx interface{}, opts interface{}, fn interface{}) *MockLoggingService_ExportLogs_Call {
+	return &MockLoggingService_ExportLogs_Call{Call: _e.mock.On("ExportLogs", ctx, opts, fn)}
+}
+
+func (_c *MockLoggingService_ExportLogs_Call) Run(run func(ctx context.Context, opts model.LogQueryOptions, fn func(model.LogEntry) error)) *MockLoggingService_ExportLogs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.LogQueryOptions), args[2].(func(model.LogEntry) error))
+	})
+	return _c
+}
+
+func (_c *MockLoggingService_ExportLogs_Call) Return(_a0 error) *MockLoggingService_ExportLogs_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockLoggingService_ExportLogs_Call) RunAndReturn(run func(context.Context, model.LogQueryOptions, func(model.LogEntry) error) error) *MockLoggingService_ExportLogs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeLogs provides a mock function with given fields: ctx, retention
+func (_m *MockLoggingService) PurgeLogs(ctx context.Context, retention model.LogRetention) (int64, error) {
+	ret := _m.Called(ctx, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeLogs")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.LogRetention) (int64, error)); ok {
+		return rf(ctx, retention)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.LogRetention) int64); ok {
+		r0 = rf(ctx, retention)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.LogRetention) error); ok {
+		r1 = rf(ctx, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLoggingService_PurgeLogs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeLogs'
+type MockLoggingService_PurgeLogs_Call struct {
+	*mock.Call
+}
+
+// PurgeLogs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - retention model.LogRetention
+func (_e *MockLoggingService_Expecter) PurgeLogs(ctx interface{}, retention interface{}) *MockLoggingService_PurgeLogs_Call {
+	return &MockLoggingService_PurgeLogs_Call{Call: _e.mock.On("PurgeLogs", ctx, retention)}
+}
+
+func (_c *MockLoggingService_PurgeLogs_Call) Run(run func(ctx context.Context, retention model.LogRetention)) *MockLoggingService_PurgeLogs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.LogRetention))
+	})
+	return _c
+}
+
+func (_c *MockLoggingService_PurgeLogs_Call) Return(_a0 int64, _a1 error) *MockLoggingService_PurgeLogs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLoggingService_PurgeLogs_Call) RunAndReturn(run func(context.Context, model.LogRetention) (int64, error)) *MockLoggingService_PurgeLogs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // QueryLogs provides a mock function with given fields: ctx, opts
 func (_m *MockLoggingService) QueryLogs(ctx context.Context, opts model.LogQueryOptions) ([]model.LogEntry, error) {
 	ret := _m.Called(ctx, opts)
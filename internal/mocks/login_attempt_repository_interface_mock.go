@@ -0,0 +1,253 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockLoginAttemptRepositoryInterface is an autogenerated mock type for the LoginAttemptRepositoryInterface type
+type MockLoginAttemptRepositoryInterface struct {
+	mock.Mock
+}
+
+type MockLoginAttemptRepositoryInterface_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockLoginAttemptRepositoryInterface) EXPECT() *MockLoginAttemptRepositoryInterface_Expecter {
+	return &MockLoginAttemptRepositoryInterface_Expecter{mock: &_m.Mock}
+}
+
+// Get provides a mock function with given fields: ctx, key
+func (_m *MockLoginAttemptRepositoryInterface) Get(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.LoginAttempt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.LoginAttempt, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.LoginAttempt); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LoginAttempt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLoginAttemptRepositoryInterface_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockLoginAttemptRepositoryInterface_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *MockLoginAttemptRepositoryInterface_Expecter) Get(ctx interface{}, key interface{}) *MockLoginAttemptRepositoryInterface_Get_Call {
+	return &MockLoginAttemptRepositoryInterface_Get_Call{Call: _e.mock.On("Get", ctx, key)}
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Get_Call) Run(run func(ctx context.Context, key string)) *MockLoginAttemptRepositoryInterface_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Get_Call) Return(_a0 *model.LoginAttempt, _a1 error) *MockLoginAttemptRepositoryInterface_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Get_Call) RunAndReturn(run func(context.Context, string) (*model.LoginAttempt, error)) *MockLoginAttemptRepositoryInterface_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Lock provides a mock function with given fields: ctx, key, until
+func (_m *MockLoginAttemptRepositoryInterface) Lock(ctx context.Context, key string, until time.Time) error {
+	ret := _m.Called(ctx, key, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Lock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, key, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockLoginAttemptRepositoryInterface_Lock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Lock'
+type MockLoginAttemptRepositoryInterface_Lock_Call struct {
+	*mock.Call
+}
+
+// Lock is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - until time.Time
+func (_e *MockLoginAttemptRepositoryInterface_Expecter) Lock(ctx interface{}, key interface{}, until interface{}) *MockLoginAttemptRepositoryInterface_Lock_Call {
+	return &MockLoginAttemptRepositoryInterface_Lock_Call{Call: _e.mock.On("Lock", ctx, key, until)}
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Lock_Call) Run(run func(ctx context.Context, key string, until time.Time)) *MockLoginAttemptRepositoryInterface_Lock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Lock_Call) Return(_a0 error) *MockLoginAttemptRepositoryInterface_Lock_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Lock_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *MockLoginAttemptRepositoryInterface_Lock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordFailure provides a mock function with given fields: ctx, key, window
+func (_m *MockLoginAttemptRepositoryInterface) RecordFailure(ctx context.Context, key string, window time.Duration) (*model.LoginAttempt, error) {
+	ret := _m.Called(ctx, key, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordFailure")
+	}
+
+	var r0 *model.LoginAttempt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (*model.LoginAttempt, error)); ok {
+		return rf(ctx, key, window)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) *model.LoginAttempt); ok {
+		r0 = rf(ctx, key, window)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.LoginAttempt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, key, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLoginAttemptRepositoryInterface_RecordFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordFailure'
+type MockLoginAttemptRepositoryInterface_RecordFailure_Call struct {
+	*mock.Call
+}
+
+// RecordFailure is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - window time.Duration
+func (_e *MockLoginAttemptRepositoryInterface_Expecter) RecordFailure(ctx interface{}, key interface{}, window interface{}) *MockLoginAttemptRepositoryInterface_RecordFailure_Call {
+	return &MockLoginAttemptRepositoryInterface_RecordFailure_Call{Call: _e.mock.On("RecordFailure", ctx, key, window)}
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_RecordFailure_Call) Run(run func(ctx context.Context, key string, window time.Duration)) *MockLoginAttemptRepositoryInterface_RecordFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_RecordFailure_Call) Return(_a0 *model.LoginAttempt, _a1 error) *MockLoginAttemptRepositoryInterface_RecordFailure_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_RecordFailure_Call) RunAndReturn(run func(context.Context, string, time.Duration) (*model.LoginAttempt, error)) *MockLoginAttemptRepositoryInterface_RecordFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reset provides a mock function with given fields: ctx, key
+func (_m *MockLoginAttemptRepositoryInterface) Reset(ctx context.Context, key string) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockLoginAttemptRepositoryInterface_Reset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reset'
+type MockLoginAttemptRepositoryInterface_Reset_Call struct {
+	*mock.Call
+}
+
+// Reset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *MockLoginAttemptRepositoryInterface_Expecter) Reset(ctx interface{}, key interface{}) *MockLoginAttemptRepositoryInterface_Reset_Call {
+	return &MockLoginAttemptRepositoryInterface_Reset_Call{Call: _e.mock.On("Reset", ctx, key)}
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Reset_Call) Run(run func(ctx context.Context, key string)) *MockLoginAttemptRepositoryInterface_Reset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Reset_Call) Return(_a0 error) *MockLoginAttemptRepositoryInterface_Reset_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepositoryInterface_Reset_Call) RunAndReturn(run func(context.Context, string) error) *MockLoginAttemptRepositoryInterface_Reset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockLoginAttemptRepositoryInterface creates a new instance of MockLoginAttemptRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockLoginAttemptRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockLoginAttemptRepositoryInterface {
+	mock := &MockLoginAttemptRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
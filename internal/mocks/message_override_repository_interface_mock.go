@@ -0,0 +1,201 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockMessageOverrideRepositoryInterface is an autogenerated mock type for the MessageOverrideRepositoryInterface type
+type MockMessageOverrideRepositoryInterface struct {
+	mock.Mock
+}
+
+type MockMessageOverrideRepositoryInterface_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMessageOverrideRepositoryInterface) EXPECT() *MockMessageOverrideRepositoryInterface_Expecter {
+	return &MockMessageOverrideRepositoryInterface_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: ctx, tenant, locale, key
+func (_m *MockMessageOverrideRepositoryInterface) Delete(ctx context.Context, tenant string, locale string, key string) (bool, error) {
+	ret := _m.Called(ctx, tenant, locale, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (bool, error)); ok {
+		return rf(ctx, tenant, locale, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) bool); ok {
+		r0 = rf(ctx, tenant, locale, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, tenant, locale, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMessageOverrideRepositoryInterface_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockMessageOverrideRepositoryInterface_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tenant string
+//   - locale string
+//   - key string
+func (_e *MockMessageOverrideRepositoryInterface_Expecter) Delete(ctx interface{}, tenant interface{}, locale interface{}, key interface{}) *MockMessageOverrideRepositoryInterface_Delete_Call {
+	return &MockMessageOverrideRepositoryInterface_Delete_Call{Call: _e.mock.On("Delete", ctx, tenant, locale, key)}
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_Delete_Call) Run(run func(ctx context.Context, tenant string, locale string, key string)) *MockMessageOverrideRepositoryInterface_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_Delete_Call) Return(_a0 bool, _a1 error) *MockMessageOverrideRepositoryInterface_Delete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_Delete_Call) RunAndReturn(run func(context.Context, string, string, string) (bool, error)) *MockMessageOverrideRepositoryInterface_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx
+func (_m *MockMessageOverrideRepositoryInterface) List(ctx context.Context) ([]*model.MessageOverride, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.MessageOverride
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.MessageOverride, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.MessageOverride); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.MessageOverride)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMessageOverrideRepositoryInterface_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockMessageOverrideRepositoryInterface_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockMessageOverrideRepositoryInterface_Expecter) List(ctx interface{}) *MockMessageOverrideRepositoryInterface_List_Call {
+	return &MockMessageOverrideRepositoryInterface_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_List_Call) Run(run func(ctx context.Context)) *MockMessageOverrideRepositoryInterface_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_List_Call) Return(_a0 []*model.MessageOverride, _a1 error) *MockMessageOverrideRepositoryInterface_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_List_Call) RunAndReturn(run func(context.Context) ([]*model.MessageOverride, error)) *MockMessageOverrideRepositoryInterface_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function with given fields: ctx, override
+func (_m *MockMessageOverrideRepositoryInterface) Upsert(ctx context.Context, override *model.MessageOverride) error {
+	ret := _m.Called(ctx, override)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.MessageOverride) error); ok {
+		r0 = rf(ctx, override)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMessageOverrideRepositoryInterface_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockMessageOverrideRepositoryInterface_Upsert_Call struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - override *model.MessageOverride
+func (_e *MockMessageOverrideRepositoryInterface_Expecter) Upsert(ctx interface{}, override interface{}) *MockMessageOverrideRepositoryInterface_Upsert_Call {
+	return &MockMessageOverrideRepositoryInterface_Upsert_Call{Call: _e.mock.On("Upsert", ctx, override)}
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_Upsert_Call) Run(run func(ctx context.Context, override *model.MessageOverride)) *MockMessageOverrideRepositoryInterface_Upsert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.MessageOverride))
+	})
+	return _c
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_Upsert_Call) Return(_a0 error) *MockMessageOverrideRepositoryInterface_Upsert_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMessageOverrideRepositoryInterface_Upsert_Call) RunAndReturn(run func(context.Context, *model.MessageOverride) error) *MockMessageOverrideRepositoryInterface_Upsert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMessageOverrideRepositoryInterface creates a new instance of MockMessageOverrideRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMessageOverrideRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMessageOverrideRepositoryInterface {
+	mock := &MockMessageOverrideRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
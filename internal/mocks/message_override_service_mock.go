@@ -0,0 +1,238 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockMessageOverrideService is an autogenerated mock type for the MessageOverrideService type
+type MockMessageOverrideService struct {
+	mock.Mock
+}
+
+type MockMessageOverrideService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMessageOverrideService) EXPECT() *MockMessageOverrideService_Expecter {
+	return &MockMessageOverrideService_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: ctx, tenant, locale, key
+func (_m *MockMessageOverrideService) Delete(ctx context.Context, tenant string, locale string, key string) error {
+	ret := _m.Called(ctx, tenant, locale, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenant, locale, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMessageOverrideService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockMessageOverrideService_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tenant string
+//   - locale string
+//   - key string
+func (_e *MockMessageOverrideService_Expecter) Delete(ctx interface{}, tenant interface{}, locale interface{}, key interface{}) *MockMessageOverrideService_Delete_Call {
+	return &MockMessageOverrideService_Delete_Call{Call: _e.mock.On("Delete", ctx, tenant, locale, key)}
+}
+
+func (_c *MockMessageOverrideService_Delete_Call) Run(run func(ctx context.Context, tenant string, locale string, key string)) *MockMessageOverrideService_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockMessageOverrideService_Delete_Call) Return(_a0 error) *MockMessageOverrideService_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMessageOverrideService_Delete_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockMessageOverrideService_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, tenant
+func (_m *MockMessageOverrideService) List(ctx context.Context, tenant string) ([]*model.MessageOverride, error) {
+	ret := _m.Called(ctx, tenant)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.MessageOverride
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.MessageOverride, error)); ok {
+		return rf(ctx, tenant)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.MessageOverride); ok {
+		r0 = rf(ctx, tenant)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.MessageOverride)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenant)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMessageOverrideService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockMessageOverrideService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tenant string
+func (_e *MockMessageOverrideService_Expecter) List(ctx interface{}, tenant interface{}) *MockMessageOverrideService_List_Call {
+	return &MockMessageOverrideService_List_Call{Call: _e.mock.On("List", ctx, tenant)}
+}
+
+func (_c *MockMessageOverrideService_List_Call) Run(run func(ctx context.Context, tenant string)) *MockMessageOverrideService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockMessageOverrideService_List_Call) Return(_a0 []*model.MessageOverride, _a1 error) *MockMessageOverrideService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMessageOverrideService_List_Call) RunAndReturn(run func(context.Context, string) ([]*model.MessageOverride, error)) *MockMessageOverrideService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reload provides a mock function with given fields: ctx
+func (_m *MockMessageOverrideService) Reload(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMessageOverrideService_Reload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reload'
+type MockMessageOverrideService_Reload_Call struct {
+	*mock.Call
+}
+
+// Reload is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockMessageOverrideService_Expecter) Reload(ctx interface{}) *MockMessageOverrideService_Reload_Call {
+	return &MockMessageOverrideService_Reload_Call{Call: _e.mock.On("Reload", ctx)}
+}
+
+func (_c *MockMessageOverrideService_Reload_Call) Run(run func(ctx context.Context)) *MockMessageOverrideService_Reload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockMessageOverrideService_Reload_Call) Return(_a0 error) *MockMessageOverrideService_Reload_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMessageOverrideService_Reload_Call) RunAndReturn(run func(context.Context) error) *MockMessageOverrideService_Reload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Set provides a mock function with given fields: ctx, override
+func (_m *MockMessageOverrideService) Set(ctx context.Context, override *model.MessageOverride) error {
+	ret := _m.Called(ctx, override)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.MessageOverride) error); ok {
+		r0 = rf(ctx, override)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMessageOverrideService_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
+type MockMessageOverrideService_Set_Call struct {
+	*mock.Call
+}
+
+// Set is a helper method to define mock.On call
+//   - ctx context.Context
+//   - override *model.MessageOverride
+func (_e *MockMessageOverrideService_Expecter) Set(ctx interface{}, override interface{}) *MockMessageOverrideService_Set_Call {
+	return &MockMessageOverrideService_Set_Call{Call: _e.mock.On("Set", ctx, override)}
+}
+
+func (_c *MockMessageOverrideService_Set_Call) Run(run func(ctx context.Context, override *model.MessageOverride)) *MockMessageOverrideService_Set_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.MessageOverride))
+	})
+	return _c
+}
+
+func (_c *MockMessageOverrideService_Set_Call) Return(_a0 error) *MockMessageOverrideService_Set_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMessageOverrideService_Set_Call) RunAndReturn(run func(context.Context, *model.MessageOverride) error) *MockMessageOverrideService_Set_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMessageOverrideService creates a new instance of MockMessageOverrideService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMessageOverrideService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMessageOverrideService {
+	mock := &MockMessageOverrideService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
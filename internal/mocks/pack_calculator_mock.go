@@ -5,6 +5,8 @@ package mocks
 import (
 	model "github.com/guttosm/pack-service/internal/domain/model"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockPackCalculator is an autogenerated mock type for the PackCalculator type
@@ -66,6 +68,111 @@ func (_c *MockPackCalculator_Calculate_Call) RunAndReturn(run func(int) model.Pa
 	return _c
 }
 
+// CalculateGreedy provides a mock function with given fields: itemsOrdered, packSizes
+func (_m *MockPackCalculator) CalculateGreedy(itemsOrdered int, packSizes []int) model.PackResult {
+	ret := _m.Called(itemsOrdered, packSizes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CalculateGreedy")
+	}
+
+	var r0 model.PackResult
+	if rf, ok := ret.Get(0).(func(int, []int) model.PackResult); ok {
+		r0 = rf(itemsOrdered, packSizes)
+	} else {
+		r0 = ret.Get(0).(model.PackResult)
+	}
+
+	return r0
+}
+
+// MockPackCalculator_CalculateGreedy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CalculateGreedy'
+type MockPackCalculator_CalculateGreedy_Call struct {
+	*mock.Call
+}
+
+// CalculateGreedy is a helper method to define mock.On call
+//   - itemsOrdered int
+//   - packSizes []int
+func (_e *MockPackCalculator_Expecter) CalculateGreedy(itemsOrdered interface{}, packSizes interface{}) *MockPackCalculator_CalculateGreedy_Call {
+	return &MockPackCalculator_CalculateGreedy_Call{Call: _e.mock.On("CalculateGreedy", itemsOrdered, packSizes)}
+}
+
+func (_c *MockPackCalculator_CalculateGreedy_Call) Run(run func(itemsOrdered int, packSizes []int)) *MockPackCalculator_CalculateGreedy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].([]int))
+	})
+	return _c
+}
+
+func (_c *MockPackCalculator_CalculateGreedy_Call) Return(_a0 model.PackResult) *MockPackCalculator_CalculateGreedy_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPackCalculator_CalculateGreedy_Call) RunAndReturn(run func(int, []int) model.PackResult) *MockPackCalculator_CalculateGreedy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CalculateWithConstraints provides a mock function with given fields: itemsOrdered, packSizes, constraints
+func (_m *MockPackCalculator) CalculateWithConstraints(itemsOrdered int, packSizes []int, constraints model.PackConstraints) (model.PackResult, error) {
+	ret := _m.Called(itemsOrdered, packSizes, constraints)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CalculateWithConstraints")
+	}
+
+	var r0 model.PackResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, []int, model.PackConstraints) (model.PackResult, error)); ok {
+		return rf(itemsOrdered, packSizes, constraints)
+	}
+	if rf, ok := ret.Get(0).(func(int, []int, model.PackConstraints) model.PackResult); ok {
+		r0 = rf(itemsOrdered, packSizes, constraints)
+	} else {
+		r0 = ret.Get(0).(model.PackResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(int, []int, model.PackConstraints) error); ok {
+		r1 = rf(itemsOrdered, packSizes, constraints)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPackCalculator_CalculateWithConstraints_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CalculateWithConstraints'
+type MockPackCalculator_CalculateWithConstraints_Call struct {
+	*mock.Call
+}
+
+// CalculateWithConstraints is a helper method to define mock.On call
+//   - itemsOrdered int
+//   - packSizes []int
+//   - constraints model.PackConstraints
+func (_e *MockPackCalculator_Expecter) CalculateWithConstraints(itemsOrdered interface{}, packSizes interface{}, constraints interface{}) *MockPackCalculator_CalculateWithConstraints_Call {
+	return &MockPackCalculator_CalculateWithConstraints_Call{Call: _e.mock.On("CalculateWithConstraints", itemsOrdered, packSizes, constraints)}
+}
+
+func (_c *MockPackCalculator_CalculateWithConstraints_Call) Run(run func(itemsOrdered int, packSizes []int, constraints model.PackConstraints)) *MockPackCalculator_CalculateWithConstraints_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].([]int), args[2].(model.PackConstraints))
+	})
+	return _c
+}
+
+func (_c *MockPackCalculator_CalculateWithConstraints_Call) Return(_a0 model.PackResult, _a1 error) *MockPackCalculator_CalculateWithConstraints_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPackCalculator_CalculateWithConstraints_Call) RunAndReturn(run func(int, []int, model.PackConstraints) (model.PackResult, error)) *MockPackCalculator_CalculateWithConstraints_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CalculateWithPackSizes provides a mock function with given fields: itemsOrdered, packSizes
 func (_m *MockPackCalculator) CalculateWithPackSizes(itemsOrdered int, packSizes []int) model.PackResult {
 	ret := _m.Called(itemsOrdered, packSizes)
@@ -113,6 +220,115 @@ func (_c *MockPackCalculator_CalculateWithPackSizes_Call) RunAndReturn(run func(
 	return _c
 }
 
+// CalculateWithin provides a mock function with given fields: itemsOrdered, packSizes, constraints, budget
+func (_m *MockPackCalculator) CalculateWithin(itemsOrdered int, packSizes []int, constraints model.PackConstraints, budget time.Duration) (model.PackResult, error) {
+	ret := _m.Called(itemsOrdered, packSizes, constraints, budget)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CalculateWithin")
+	}
+
+	var r0 model.PackResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, []int, model.PackConstraints, time.Duration) (model.PackResult, error)); ok {
+		return rf(itemsOrdered, packSizes, constraints, budget)
+	}
+	if rf, ok := ret.Get(0).(func(int, []int, model.PackConstraints, time.Duration) model.PackResult); ok {
+		r0 = rf(itemsOrdered, packSizes, constraints, budget)
+	} else {
+		r0 = ret.Get(0).(model.PackResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(int, []int, model.PackConstraints, time.Duration) error); ok {
+		r1 = rf(itemsOrdered, packSizes, constraints, budget)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPackCalculator_CalculateWithin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CalculateWithin'
+type MockPackCalculator_CalculateWithin_Call struct {
+	*mock.Call
+}
+
+// CalculateWithin is a helper method to define mock.On call
+//   - itemsOrdered int
+//   - packSizes []int
+//   - constraints model.PackConstraints
+//   - budget time.Duration
+func (_e *MockPackCalculator_Expecter) CalculateWithin(itemsOrdered interface{}, packSizes interface{}, constraints interface{}, budget interface{}) *MockPackCalculator_CalculateWithin_Call {
+	return &MockPackCalculator_CalculateWithin_Call{Call: _e.mock.On("CalculateWithin", itemsOrdered, packSizes, constraints, budget)}
+}
+
+func (_c *MockPackCalculator_CalculateWithin_Call) Run(run func(itemsOrdered int, packSizes []int, constraints model.PackConstraints, budget time.Duration)) *MockPackCalculator_CalculateWithin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].([]int), args[2].(model.PackConstraints), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockPackCalculator_CalculateWithin_Call) Return(_a0 model.PackResult, _a1 error) *MockPackCalculator_CalculateWithin_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPackCalculator_CalculateWithin_Call) RunAndReturn(run func(int, []int, model.PackConstraints, time.Duration) (model.PackResult, error)) *MockPackCalculator_CalculateWithin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Explain provides a mock function with given fields: result, packSizes, constraints
+func (_m *MockPackCalculator) Explain(result model.PackResult, packSizes []int, constraints model.PackConstraints) *model.Explanation {
+	ret := _m.Called(result, packSizes, constraints)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Explain")
+	}
+
+	var r0 *model.Explanation
+	if rf, ok := ret.Get(0).(func(model.PackResult, []int, model.PackConstraints) *model.Explanation); ok {
+		r0 = rf(result, packSizes, constraints)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Explanation)
+		}
+	}
+
+	return r0
+}
+
+// MockPackCalculator_Explain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Explain'
+type MockPackCalculator_Explain_Call struct {
+	*mock.Call
+}
+
+// Explain is a helper method to define mock.On call
+//   - result model.PackResult
+//   - packSizes []int
+//   - constraints model.PackConstraints
+func (_e *MockPackCalculator_Expecter) Explain(result interface{}, packSizes interface{}, constraints interface{}) *MockPackCalculator_Explain_Call {
+	return &MockPackCalculator_Explain_Call{Call: _e.mock.On("Explain", result, packSizes, constraints)}
+}
+
+func (_c *MockPackCalculator_Explain_Call) Run(run func(result model.PackResult, packSizes []int, constraints model.PackConstraints)) *MockPackCalculator_Explain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(model.PackResult), args[1].([]int), args[2].(model.PackConstraints))
+	})
+	return _c
+}
+
+func (_c *MockPackCalculator_Explain_Call) Return(_a0 *model.Explanation) *MockPackCalculator_Explain_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPackCalculator_Explain_Call) RunAndReturn(run func(model.PackResult, []int, model.PackConstraints) *model.Explanation) *MockPackCalculator_Explain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // InvalidateCache provides a mock function with no fields
 func (_m *MockPackCalculator) InvalidateCache() {
 	_m.Called()
@@ -21,8 +21,16 @@ func (m *MockPackSizesRepositoryInterface) GetActive(ctx context.Context) (*repo
 	return args.Get(0).(*repository.PackSizeConfig), args.Error(1)
 }
 
-func (m *MockPackSizesRepositoryInterface) Create(ctx context.Context, sizes []int, createdBy string) (*repository.PackSizeConfig, error) {
-	args := m.Called(ctx, sizes, createdBy)
+func (m *MockPackSizesRepositoryInterface) Create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string) (*repository.PackSizeConfig, error) {
+	args := m.Called(ctx, sizes, costs, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PackSizeConfig), args.Error(1)
+}
+
+func (m *MockPackSizesRepositoryInterface) CreateIfActive(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int) (*repository.PackSizeConfig, error) {
+	args := m.Called(ctx, sizes, costs, createdBy, activeVersion)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -44,3 +52,19 @@ func (m *MockPackSizesRepositoryInterface) List(ctx context.Context, limit int)
 	}
 	return args.Get(0).([]repository.PackSizeConfig), args.Error(1)
 }
+
+func (m *MockPackSizesRepositoryInterface) Activate(ctx context.Context, id primitive.ObjectID, activatedBy string) (*repository.PackSizeConfig, error) {
+	args := m.Called(ctx, id, activatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PackSizeConfig), args.Error(1)
+}
+
+func (m *MockPackSizesRepositoryInterface) PreviousVersion(ctx context.Context, version int) (*repository.PackSizeConfig, error) {
+	args := m.Called(ctx, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PackSizeConfig), args.Error(1)
+}
@@ -24,9 +24,69 @@ func (_m *MockPackSizesService) EXPECT() *MockPackSizesService_Expecter {
 	return &MockPackSizesService_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: ctx, sizes, createdBy
-func (_m *MockPackSizesService) Create(ctx context.Context, sizes []int, createdBy string) (*repository.PackSizeConfig, error) {
-	ret := _m.Called(ctx, sizes, createdBy)
+// Activate provides a mock function with given fields: ctx, id, activatedBy
+func (_m *MockPackSizesService) Activate(ctx context.Context, id primitive.ObjectID, activatedBy string) (*repository.PackSizeConfig, error) {
+	ret := _m.Called(ctx, id, activatedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Activate")
+	}
+
+	var r0 *repository.PackSizeConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) (*repository.PackSizeConfig, error)); ok {
+		return rf(ctx, id, activatedBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) *repository.PackSizeConfig); ok {
+		r0 = rf(ctx, id, activatedBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.PackSizeConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, string) error); ok {
+		r1 = rf(ctx, id, activatedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPackSizesService_Activate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Activate'
+type MockPackSizesService_Activate_Call struct {
+	*mock.Call
+}
+
+// Activate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+//   - activatedBy string
+func (_e *MockPackSizesService_Expecter) Activate(ctx interface{}, id interface{}, activatedBy interface{}) *MockPackSizesService_Activate_Call {
+	return &MockPackSizesService_Activate_Call{Call: _e.mock.On("Activate", ctx, id, activatedBy)}
+}
+
+func (_c *MockPackSizesService_Activate_Call) Run(run func(ctx context.Context, id primitive.ObjectID, activatedBy string)) *MockPackSizesService_Activate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockPackSizesService_Activate_Call) Return(_a0 *repository.PackSizeConfig, _a1 error) *MockPackSizesService_Activate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPackSizesService_Activate_Call) RunAndReturn(run func(context.Context, primitive.ObjectID, string) (*repository.PackSizeConfig, error)) *MockPackSizesService_Activate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: ctx, sizes, costs, createdBy
+func (_m *MockPackSizesService) Create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string) (*repository.PackSizeConfig, error) {
+	ret := _m.Called(ctx, sizes, costs, createdBy)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
@@ -34,19 +94,19 @@ func (_m *MockPackSizesService) Create(ctx context.Context, sizes []int, created
 
 	var r0 *repository.PackSizeConfig
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []int, string) (*repository.PackSizeConfig, error)); ok {
-		return rf(ctx, sizes, createdBy)
+	if rf, ok := ret.Get(0).(func(context.Context, []int, map[int]float64, string) (*repository.PackSizeConfig, error)); ok {
+		return rf(ctx, sizes, costs, createdBy)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []int, string) *repository.PackSizeConfig); ok {
-		r0 = rf(ctx, sizes, createdBy)
+	if rf, ok := ret.Get(0).(func(context.Context, []int, map[int]float64, string) *repository.PackSizeConfig); ok {
+		r0 = rf(ctx, sizes, costs, createdBy)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*repository.PackSizeConfig)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []int, string) error); ok {
-		r1 = rf(ctx, sizes, createdBy)
+	if rf, ok := ret.Get(1).(func(context.Context, []int, map[int]float64, string) error); ok {
+		r1 = rf(ctx, sizes, costs, createdBy)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -62,14 +122,15 @@ type MockPackSizesService_Create_Call struct {
 // Create is a helper method to define mock.On call
 //   - ctx context.Context
 //   - sizes []int
+//   - costs map[int]float64
 //   - createdBy string
-func (_e *MockPackSizesService_Expecter) Create(ctx interface{}, sizes interface{}, createdBy interface{}) *MockPackSizesService_Create_Call {
-	return &MockPackSizesService_Create_Call{Call: _e.mock.On("Create", ctx, sizes, createdBy)}
+func (_e *MockPackSizesService_Expecter) Create(ctx interface{}, sizes interface{}, costs interface{}, createdBy interface{}) *MockPackSizesService_Create_Call {
+	return &MockPackSizesService_Create_Call{Call: _e.mock.On("Create", ctx, sizes, costs, createdBy)}
 }
 
-func (_c *MockPackSizesService_Create_Call) Run(run func(ctx context.Context, sizes []int, createdBy string)) *MockPackSizesService_Create_Call {
+func (_c *MockPackSizesService_Create_Call) Run(run func(ctx context.Context, sizes []int, costs map[int]float64, createdBy string)) *MockPackSizesService_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].([]int), args[2].(string))
+		run(args[0].(context.Context), args[1].([]int), args[2].(map[int]float64), args[3].(string))
 	})
 	return _c
 }
@@ -79,7 +140,69 @@ func (_c *MockPackSizesService_Create_Call) Return(_a0 *repository.PackSizeConfi
 	return _c
 }
 
-func (_c *MockPackSizesService_Create_Call) RunAndReturn(run func(context.Context, []int, string) (*repository.PackSizeConfig, error)) *MockPackSizesService_Create_Call {
+func (_c *MockPackSizesService_Create_Call) RunAndReturn(run func(context.Context, []int, map[int]float64, string) (*repository.PackSizeConfig, error)) *MockPackSizesService_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateIfActive provides a mock function with given fields: ctx, sizes, costs, createdBy, activeVersion
+func (_m *MockPackSizesService) CreateIfActive(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int) (*repository.PackSizeConfig, error) {
+	ret := _m.Called(ctx, sizes, costs, createdBy, activeVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIfActive")
+	}
+
+	var r0 *repository.PackSizeConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, map[int]float64, string, int) (*repository.PackSizeConfig, error)); ok {
+		return rf(ctx, sizes, costs, createdBy, activeVersion)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int, map[int]float64, string, int) *repository.PackSizeConfig); ok {
+		r0 = rf(ctx, sizes, costs, createdBy, activeVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.PackSizeConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int, map[int]float64, string, int) error); ok {
+		r1 = rf(ctx, sizes, costs, createdBy, activeVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPackSizesService_CreateIfActive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateIfActive'
+type MockPackSizesService_CreateIfActive_Call struct {
+	*mock.Call
+}
+
+// CreateIfActive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sizes []int
+//   - costs map[int]float64
+//   - createdBy string
+//   - activeVersion int
+func (_e *MockPackSizesService_Expecter) CreateIfActive(ctx interface{}, sizes interface{}, costs interface{}, createdBy interface{}, activeVersion interface{}) *MockPackSizesService_CreateIfActive_Call {
+	return &MockPackSizesService_CreateIfActive_Call{Call: _e.mock.On("CreateIfActive", ctx, sizes, costs, createdBy, activeVersion)}
+}
+
+func (_c *MockPackSizesService_CreateIfActive_Call) Run(run func(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int)) *MockPackSizesService_CreateIfActive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int), args[2].(map[int]float64), args[3].(string), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockPackSizesService_CreateIfActive_Call) Return(_a0 *repository.PackSizeConfig, _a1 error) *MockPackSizesService_CreateIfActive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPackSizesService_CreateIfActive_Call) RunAndReturn(run func(context.Context, []int, map[int]float64, string, int) (*repository.PackSizeConfig, error)) *MockPackSizesService_CreateIfActive_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -201,6 +324,65 @@ func (_c *MockPackSizesService_List_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// Rollback provides a mock function with given fields: ctx, activatedBy
+func (_m *MockPackSizesService) Rollback(ctx context.Context, activatedBy string) (*repository.PackSizeConfig, error) {
+	ret := _m.Called(ctx, activatedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollback")
+	}
+
+	var r0 *repository.PackSizeConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.PackSizeConfig, error)); ok {
+		return rf(ctx, activatedBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.PackSizeConfig); ok {
+		r0 = rf(ctx, activatedBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.PackSizeConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, activatedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPackSizesService_Rollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rollback'
+type MockPackSizesService_Rollback_Call struct {
+	*mock.Call
+}
+
+// Rollback is a helper method to define mock.On call
+//   - ctx context.Context
+//   - activatedBy string
+func (_e *MockPackSizesService_Expecter) Rollback(ctx interface{}, activatedBy interface{}) *MockPackSizesService_Rollback_Call {
+	return &MockPackSizesService_Rollback_Call{Call: _e.mock.On("Rollback", ctx, activatedBy)}
+}
+
+func (_c *MockPackSizesService_Rollback_Call) Run(run func(ctx context.Context, activatedBy string)) *MockPackSizesService_Rollback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockPackSizesService_Rollback_Call) Return(_a0 *repository.PackSizeConfig, _a1 error) *MockPackSizesService_Rollback_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPackSizesService_Rollback_Call) RunAndReturn(run func(context.Context, string) (*repository.PackSizeConfig, error)) *MockPackSizesService_Rollback_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Update provides a mock function with given fields: ctx, id, sizes, updatedBy
 func (_m *MockPackSizesService) Update(ctx context.Context, id primitive.ObjectID, sizes []int, updatedBy string) (*repository.PackSizeConfig, error) {
 	ret := _m.Called(ctx, id, sizes, updatedBy)
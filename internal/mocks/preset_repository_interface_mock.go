@@ -0,0 +1,308 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockPresetRepositoryInterface is an autogenerated mock type for the PresetRepositoryInterface type
+type MockPresetRepositoryInterface struct {
+	mock.Mock
+}
+
+type MockPresetRepositoryInterface_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPresetRepositoryInterface) EXPECT() *MockPresetRepositoryInterface_Expecter {
+	return &MockPresetRepositoryInterface_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, preset
+func (_m *MockPresetRepositoryInterface) Create(ctx context.Context, preset *model.Preset) error {
+	ret := _m.Called(ctx, preset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Preset) error); ok {
+		r0 = rf(ctx, preset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPresetRepositoryInterface_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockPresetRepositoryInterface_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - preset *model.Preset
+func (_e *MockPresetRepositoryInterface_Expecter) Create(ctx interface{}, preset interface{}) *MockPresetRepositoryInterface_Create_Call {
+	return &MockPresetRepositoryInterface_Create_Call{Call: _e.mock.On("Create", ctx, preset)}
+}
+
+func (_c *MockPresetRepositoryInterface_Create_Call) Run(run func(ctx context.Context, preset *model.Preset)) *MockPresetRepositoryInterface_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.Preset))
+	})
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_Create_Call) Return(_a0 error) *MockPresetRepositoryInterface_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_Create_Call) RunAndReturn(run func(context.Context, *model.Preset) error) *MockPresetRepositoryInterface_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, owner, name
+func (_m *MockPresetRepositoryInterface) Delete(ctx context.Context, owner string, name string) (bool, error) {
+	ret := _m.Called(ctx, owner, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, owner, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, owner, name)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, owner, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPresetRepositoryInterface_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockPresetRepositoryInterface_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - name string
+func (_e *MockPresetRepositoryInterface_Expecter) Delete(ctx interface{}, owner interface{}, name interface{}) *MockPresetRepositoryInterface_Delete_Call {
+	return &MockPresetRepositoryInterface_Delete_Call{Call: _e.mock.On("Delete", ctx, owner, name)}
+}
+
+func (_c *MockPresetRepositoryInterface_Delete_Call) Run(run func(ctx context.Context, owner string, name string)) *MockPresetRepositoryInterface_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_Delete_Call) Return(_a0 bool, _a1 error) *MockPresetRepositoryInterface_Delete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_Delete_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *MockPresetRepositoryInterface_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByName provides a mock function with given fields: ctx, owner, name
+func (_m *MockPresetRepositoryInterface) FindByName(ctx context.Context, owner string, name string) (*model.Preset, error) {
+	ret := _m.Called(ctx, owner, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByName")
+	}
+
+	var r0 *model.Preset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Preset, error)); ok {
+		return rf(ctx, owner, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Preset); ok {
+		r0 = rf(ctx, owner, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Preset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, owner, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPresetRepositoryInterface_FindByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByName'
+type MockPresetRepositoryInterface_FindByName_Call struct {
+	*mock.Call
+}
+
+// FindByName is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - name string
+func (_e *MockPresetRepositoryInterface_Expecter) FindByName(ctx interface{}, owner interface{}, name interface{}) *MockPresetRepositoryInterface_FindByName_Call {
+	return &MockPresetRepositoryInterface_FindByName_Call{Call: _e.mock.On("FindByName", ctx, owner, name)}
+}
+
+func (_c *MockPresetRepositoryInterface_FindByName_Call) Run(run func(ctx context.Context, owner string, name string)) *MockPresetRepositoryInterface_FindByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_FindByName_Call) Return(_a0 *model.Preset, _a1 error) *MockPresetRepositoryInterface_FindByName_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_FindByName_Call) RunAndReturn(run func(context.Context, string, string) (*model.Preset, error)) *MockPresetRepositoryInterface_FindByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByOwner provides a mock function with given fields: ctx, owner
+func (_m *MockPresetRepositoryInterface) ListByOwner(ctx context.Context, owner string) ([]*model.Preset, error) {
+	ret := _m.Called(ctx, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByOwner")
+	}
+
+	var r0 []*model.Preset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.Preset, error)); ok {
+		return rf(ctx, owner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.Preset); ok {
+		r0 = rf(ctx, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Preset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPresetRepositoryInterface_ListByOwner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByOwner'
+type MockPresetRepositoryInterface_ListByOwner_Call struct {
+	*mock.Call
+}
+
+// ListByOwner is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+func (_e *MockPresetRepositoryInterface_Expecter) ListByOwner(ctx interface{}, owner interface{}) *MockPresetRepositoryInterface_ListByOwner_Call {
+	return &MockPresetRepositoryInterface_ListByOwner_Call{Call: _e.mock.On("ListByOwner", ctx, owner)}
+}
+
+func (_c *MockPresetRepositoryInterface_ListByOwner_Call) Run(run func(ctx context.Context, owner string)) *MockPresetRepositoryInterface_ListByOwner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_ListByOwner_Call) Return(_a0 []*model.Preset, _a1 error) *MockPresetRepositoryInterface_ListByOwner_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_ListByOwner_Call) RunAndReturn(run func(context.Context, string) ([]*model.Preset, error)) *MockPresetRepositoryInterface_ListByOwner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, preset
+func (_m *MockPresetRepositoryInterface) Update(ctx context.Context, preset *model.Preset) error {
+	ret := _m.Called(ctx, preset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Preset) error); ok {
+		r0 = rf(ctx, preset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPresetRepositoryInterface_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockPresetRepositoryInterface_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - preset *model.Preset
+func (_e *MockPresetRepositoryInterface_Expecter) Update(ctx interface{}, preset interface{}) *MockPresetRepositoryInterface_Update_Call {
+	return &MockPresetRepositoryInterface_Update_Call{Call: _e.mock.On("Update", ctx, preset)}
+}
+
+func (_c *MockPresetRepositoryInterface_Update_Call) Run(run func(ctx context.Context, preset *model.Preset)) *MockPresetRepositoryInterface_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.Preset))
+	})
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_Update_Call) Return(_a0 error) *MockPresetRepositoryInterface_Update_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPresetRepositoryInterface_Update_Call) RunAndReturn(run func(context.Context, *model.Preset) error) *MockPresetRepositoryInterface_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPresetRepositoryInterface creates a new instance of MockPresetRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPresetRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPresetRepositoryInterface {
+	mock := &MockPresetRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,327 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockPresetService is an autogenerated mock type for the PresetService type
+type MockPresetService struct {
+	mock.Mock
+}
+
+type MockPresetService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPresetService) EXPECT() *MockPresetService_Expecter {
+	return &MockPresetService_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, owner, preset
+func (_m *MockPresetService) Create(ctx context.Context, owner string, preset *model.Preset) (*model.Preset, error) {
+	ret := _m.Called(ctx, owner, preset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *model.Preset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.Preset) (*model.Preset, error)); ok {
+		return rf(ctx, owner, preset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.Preset) *model.Preset); ok {
+		r0 = rf(ctx, owner, preset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Preset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.Preset) error); ok {
+		r1 = rf(ctx, owner, preset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPresetService_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockPresetService_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - preset *model.Preset
+func (_e *MockPresetService_Expecter) Create(ctx interface{}, owner interface{}, preset interface{}) *MockPresetService_Create_Call {
+	return &MockPresetService_Create_Call{Call: _e.mock.On("Create", ctx, owner, preset)}
+}
+
+func (_c *MockPresetService_Create_Call) Run(run func(ctx context.Context, owner string, preset *model.Preset)) *MockPresetService_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*model.Preset))
+	})
+	return _c
+}
+
+func (_c *MockPresetService_Create_Call) Return(_a0 *model.Preset, _a1 error) *MockPresetService_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPresetService_Create_Call) RunAndReturn(run func(context.Context, string, *model.Preset) (*model.Preset, error)) *MockPresetService_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, owner, name
+func (_m *MockPresetService) Delete(ctx context.Context, owner string, name string) error {
+	ret := _m.Called(ctx, owner, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, owner, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPresetService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockPresetService_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - name string
+func (_e *MockPresetService_Expecter) Delete(ctx interface{}, owner interface{}, name interface{}) *MockPresetService_Delete_Call {
+	return &MockPresetService_Delete_Call{Call: _e.mock.On("Delete", ctx, owner, name)}
+}
+
+func (_c *MockPresetService_Delete_Call) Run(run func(ctx context.Context, owner string, name string)) *MockPresetService_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockPresetService_Delete_Call) Return(_a0 error) *MockPresetService_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPresetService_Delete_Call) RunAndReturn(run func(context.Context, string, string) error) *MockPresetService_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: ctx, owner, name
+func (_m *MockPresetService) Get(ctx context.Context, owner string, name string) (*model.Preset, error) {
+	ret := _m.Called(ctx, owner, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.Preset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Preset, error)); ok {
+		return rf(ctx, owner, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Preset); ok {
+		r0 = rf(ctx, owner, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Preset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, owner, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPresetService_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockPresetService_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - name string
+func (_e *MockPresetService_Expecter) Get(ctx interface{}, owner interface{}, name interface{}) *MockPresetService_Get_Call {
+	return &MockPresetService_Get_Call{Call: _e.mock.On("Get", ctx, owner, name)}
+}
+
+func (_c *MockPresetService_Get_Call) Run(run func(ctx context.Context, owner string, name string)) *MockPresetService_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockPresetService_Get_Call) Return(_a0 *model.Preset, _a1 error) *MockPresetService_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPresetService_Get_Call) RunAndReturn(run func(context.Context, string, string) (*model.Preset, error)) *MockPresetService_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, owner
+func (_m *MockPresetService) List(ctx context.Context, owner string) ([]*model.Preset, error) {
+	ret := _m.Called(ctx, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.Preset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.Preset, error)); ok {
+		return rf(ctx, owner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.Preset); ok {
+		r0 = rf(ctx, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Preset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPresetService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockPresetService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+func (_e *MockPresetService_Expecter) List(ctx interface{}, owner interface{}) *MockPresetService_List_Call {
+	return &MockPresetService_List_Call{Call: _e.mock.On("List", ctx, owner)}
+}
+
+func (_c *MockPresetService_List_Call) Run(run func(ctx context.Context, owner string)) *MockPresetService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockPresetService_List_Call) Return(_a0 []*model.Preset, _a1 error) *MockPresetService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPresetService_List_Call) RunAndReturn(run func(context.Context, string) ([]*model.Preset, error)) *MockPresetService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, owner, name, description, packSizes, constraints
+func (_m *MockPresetService) Update(ctx context.Context, owner string, name string, description string, packSizes []int, constraints *model.PackConstraints) (*model.Preset, error) {
+	ret := _m.Called(ctx, owner, name, description, packSizes, constraints)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *model.Preset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []int, *model.PackConstraints) (*model.Preset, error)); ok {
+		return rf(ctx, owner, name, description, packSizes, constraints)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []int, *model.PackConstraints) *model.Preset); ok {
+		r0 = rf(ctx, owner, name, description, packSizes, constraints)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Preset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []int, *model.PackConstraints) error); ok {
+		r1 = rf(ctx, owner, name, description, packSizes, constraints)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPresetService_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockPresetService_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - name string
+//   - description string
+//   - packSizes []int
+//   - constraints *model.PackConstraints
+func (_e *MockPresetService_Expecter) Update(ctx interface{}, owner interface{}, name interface{}, description interface{}, packSizes interface{}, constraints interface{}) *MockPresetService_Update_Call {
+	return &MockPresetService_Update_Call{Call: _e.mock.On("Update", ctx, owner, name, description, packSizes, constraints)}
+}
+
+func (_c *MockPresetService_Update_Call) Run(run func(ctx context.Context, owner string, name string, description string, packSizes []int, constraints *model.PackConstraints)) *MockPresetService_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].([]int), args[5].(*model.PackConstraints))
+	})
+	return _c
+}
+
+func (_c *MockPresetService_Update_Call) Return(_a0 *model.Preset, _a1 error) *MockPresetService_Update_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPresetService_Update_Call) RunAndReturn(run func(context.Context, string, string, string, []int, *model.PackConstraints) (*model.Preset, error)) *MockPresetService_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPresetService creates a new instance of MockPresetService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPresetService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPresetService {
+	mock := &MockPresetService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
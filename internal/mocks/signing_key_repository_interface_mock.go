@@ -0,0 +1,202 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockSigningKeyRepositoryInterface is an autogenerated mock type for the SigningKeyRepositoryInterface type
+type MockSigningKeyRepositoryInterface struct {
+	mock.Mock
+}
+
+type MockSigningKeyRepositoryInterface_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSigningKeyRepositoryInterface) EXPECT() *MockSigningKeyRepositoryInterface_Expecter {
+	return &MockSigningKeyRepositoryInterface_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, key
+func (_m *MockSigningKeyRepositoryInterface) Create(ctx context.Context, key *model.SigningKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.SigningKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSigningKeyRepositoryInterface_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockSigningKeyRepositoryInterface_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key *model.SigningKey
+func (_e *MockSigningKeyRepositoryInterface_Expecter) Create(ctx interface{}, key interface{}) *MockSigningKeyRepositoryInterface_Create_Call {
+	return &MockSigningKeyRepositoryInterface_Create_Call{Call: _e.mock.On("Create", ctx, key)}
+}
+
+func (_c *MockSigningKeyRepositoryInterface_Create_Call) Run(run func(ctx context.Context, key *model.SigningKey)) *MockSigningKeyRepositoryInterface_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.SigningKey))
+	})
+	return _c
+}
+
+func (_c *MockSigningKeyRepositoryInterface_Create_Call) Return(_a0 error) *MockSigningKeyRepositoryInterface_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSigningKeyRepositoryInterface_Create_Call) RunAndReturn(run func(context.Context, *model.SigningKey) error) *MockSigningKeyRepositoryInterface_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx
+func (_m *MockSigningKeyRepositoryInterface) List(ctx context.Context) ([]*model.SigningKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.SigningKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.SigningKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSigningKeyRepositoryInterface_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockSigningKeyRepositoryInterface_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockSigningKeyRepositoryInterface_Expecter) List(ctx interface{}) *MockSigningKeyRepositoryInterface_List_Call {
+	return &MockSigningKeyRepositoryInterface_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockSigningKeyRepositoryInterface_List_Call) Run(run func(ctx context.Context)) *MockSigningKeyRepositoryInterface_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockSigningKeyRepositoryInterface_List_Call) Return(_a0 []*model.SigningKey, _a1 error) *MockSigningKeyRepositoryInterface_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSigningKeyRepositoryInterface_List_Call) RunAndReturn(run func(context.Context) ([]*model.SigningKey, error)) *MockSigningKeyRepositoryInterface_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Retire provides a mock function with given fields: ctx, kid, at
+func (_m *MockSigningKeyRepositoryInterface) Retire(ctx context.Context, kid string, at time.Time) (bool, error) {
+	ret := _m.Called(ctx, kid, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Retire")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, kid, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, kid, at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, kid, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSigningKeyRepositoryInterface_Retire_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Retire'
+type MockSigningKeyRepositoryInterface_Retire_Call struct {
+	*mock.Call
+}
+
+// Retire is a helper method to define mock.On call
+//   - ctx context.Context
+//   - kid string
+//   - at time.Time
+func (_e *MockSigningKeyRepositoryInterface_Expecter) Retire(ctx interface{}, kid interface{}, at interface{}) *MockSigningKeyRepositoryInterface_Retire_Call {
+	return &MockSigningKeyRepositoryInterface_Retire_Call{Call: _e.mock.On("Retire", ctx, kid, at)}
+}
+
+func (_c *MockSigningKeyRepositoryInterface_Retire_Call) Run(run func(ctx context.Context, kid string, at time.Time)) *MockSigningKeyRepositoryInterface_Retire_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockSigningKeyRepositoryInterface_Retire_Call) Return(_a0 bool, _a1 error) *MockSigningKeyRepositoryInterface_Retire_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSigningKeyRepositoryInterface_Retire_Call) RunAndReturn(run func(context.Context, string, time.Time) (bool, error)) *MockSigningKeyRepositoryInterface_Retire_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockSigningKeyRepositoryInterface creates a new instance of MockSigningKeyRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSigningKeyRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSigningKeyRepositoryInterface {
+	mock := &MockSigningKeyRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
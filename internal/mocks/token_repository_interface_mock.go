@@ -25,21 +25,31 @@ func (_m *MockTokenRepositoryInterface) EXPECT() *MockTokenRepositoryInterface_E
 }
 
 // CleanupExpired provides a mock function with given fields: ctx
-func (_m *MockTokenRepositoryInterface) CleanupExpired(ctx context.Context) error {
+func (_m *MockTokenRepositoryInterface) CleanupExpired(ctx context.Context) (int64, error) {
 	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CleanupExpired")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
 		r0 = rf(ctx)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // MockTokenRepositoryInterface_CleanupExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CleanupExpired'
@@ -60,12 +70,68 @@ func (_c *MockTokenRepositoryInterface_CleanupExpired_Call) Run(run func(ctx con
 	return _c
 }
 
-func (_c *MockTokenRepositoryInterface_CleanupExpired_Call) Return(_a0 error) *MockTokenRepositoryInterface_CleanupExpired_Call {
-	_c.Call.Return(_a0)
+func (_c *MockTokenRepositoryInterface_CleanupExpired_Call) Return(_a0 int64, _a1 error) *MockTokenRepositoryInterface_CleanupExpired_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockTokenRepositoryInterface_CleanupExpired_Call) RunAndReturn(run func(context.Context) error) *MockTokenRepositoryInterface_CleanupExpired_Call {
+func (_c *MockTokenRepositoryInterface_CleanupExpired_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockTokenRepositoryInterface_CleanupExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountLegacyRefreshTokens provides a mock function with given fields: ctx
+func (_m *MockTokenRepositoryInterface) CountLegacyRefreshTokens(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountLegacyRefreshTokens")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountLegacyRefreshTokens'
+type MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call struct {
+	*mock.Call
+}
+
+// CountLegacyRefreshTokens is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTokenRepositoryInterface_Expecter) CountLegacyRefreshTokens(ctx interface{}) *MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call {
+	return &MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call{Call: _e.mock.On("CountLegacyRefreshTokens", ctx)}
+}
+
+func (_c *MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call) Run(run func(ctx context.Context)) *MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call) Return(_a0 int64, _a1 error) *MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockTokenRepositoryInterface_CountLegacyRefreshTokens_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -164,6 +230,63 @@ func (_c *MockTokenRepositoryInterface_Delete_Call) RunAndReturn(run func(contex
 	return _c
 }
 
+// DeleteAllByUserID provides a mock function with given fields: ctx, userID
+func (_m *MockTokenRepositoryInterface) DeleteAllByUserID(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAllByUserID")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) (int64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) int64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenRepositoryInterface_DeleteAllByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAllByUserID'
+type MockTokenRepositoryInterface_DeleteAllByUserID_Call struct {
+	*mock.Call
+}
+
+// DeleteAllByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID primitive.ObjectID
+func (_e *MockTokenRepositoryInterface_Expecter) DeleteAllByUserID(ctx interface{}, userID interface{}) *MockTokenRepositoryInterface_DeleteAllByUserID_Call {
+	return &MockTokenRepositoryInterface_DeleteAllByUserID_Call{Call: _e.mock.On("DeleteAllByUserID", ctx, userID)}
+}
+
+func (_c *MockTokenRepositoryInterface_DeleteAllByUserID_Call) Run(run func(ctx context.Context, userID primitive.ObjectID)) *MockTokenRepositoryInterface_DeleteAllByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID))
+	})
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_DeleteAllByUserID_Call) Return(_a0 int64, _a1 error) *MockTokenRepositoryInterface_DeleteAllByUserID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_DeleteAllByUserID_Call) RunAndReturn(run func(context.Context, primitive.ObjectID) (int64, error)) *MockTokenRepositoryInterface_DeleteAllByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteByToken provides a mock function with given fields: ctx, tokenString
 func (_m *MockTokenRepositoryInterface) DeleteByToken(ctx context.Context, tokenString string) error {
 	ret := _m.Called(ctx, tokenString)
@@ -378,6 +501,65 @@ func (_c *MockTokenRepositoryInterface_FindByUserID_Call) RunAndReturn(run func(
 	return _c
 }
 
+// FindLegacyRefreshTokens provides a mock function with given fields: ctx, limit
+func (_m *MockTokenRepositoryInterface) FindLegacyRefreshTokens(ctx context.Context, limit int) ([]*model.Token, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindLegacyRefreshTokens")
+	}
+
+	var r0 []*model.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*model.Token, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*model.Token); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindLegacyRefreshTokens'
+type MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call struct {
+	*mock.Call
+}
+
+// FindLegacyRefreshTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+func (_e *MockTokenRepositoryInterface_Expecter) FindLegacyRefreshTokens(ctx interface{}, limit interface{}) *MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call {
+	return &MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call{Call: _e.mock.On("FindLegacyRefreshTokens", ctx, limit)}
+}
+
+func (_c *MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call) Run(run func(ctx context.Context, limit int)) *MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call) Return(_a0 []*model.Token, _a1 error) *MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call) RunAndReturn(run func(context.Context, int) ([]*model.Token, error)) *MockTokenRepositoryInterface_FindLegacyRefreshTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // IsBlacklisted provides a mock function with given fields: ctx, tokenString
 func (_m *MockTokenRepositoryInterface) IsBlacklisted(ctx context.Context, tokenString string) (bool, error) {
 	ret := _m.Called(ctx, tokenString)
@@ -435,6 +617,54 @@ func (_c *MockTokenRepositoryInterface_IsBlacklisted_Call) RunAndReturn(run func
 	return _c
 }
 
+// UpdateToken provides a mock function with given fields: ctx, id, tokenString
+func (_m *MockTokenRepositoryInterface) UpdateToken(ctx context.Context, id primitive.ObjectID, tokenString string) error {
+	ret := _m.Called(ctx, id, tokenString)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) error); ok {
+		r0 = rf(ctx, id, tokenString)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTokenRepositoryInterface_UpdateToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateToken'
+type MockTokenRepositoryInterface_UpdateToken_Call struct {
+	*mock.Call
+}
+
+// UpdateToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+//   - tokenString string
+func (_e *MockTokenRepositoryInterface_Expecter) UpdateToken(ctx interface{}, id interface{}, tokenString interface{}) *MockTokenRepositoryInterface_UpdateToken_Call {
+	return &MockTokenRepositoryInterface_UpdateToken_Call{Call: _e.mock.On("UpdateToken", ctx, id, tokenString)}
+}
+
+func (_c *MockTokenRepositoryInterface_UpdateToken_Call) Run(run func(ctx context.Context, id primitive.ObjectID, tokenString string)) *MockTokenRepositoryInterface_UpdateToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_UpdateToken_Call) Return(_a0 error) *MockTokenRepositoryInterface_UpdateToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTokenRepositoryInterface_UpdateToken_Call) RunAndReturn(run func(context.Context, primitive.ObjectID, string) error) *MockTokenRepositoryInterface_UpdateToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockTokenRepositoryInterface creates a new instance of MockTokenRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockTokenRepositoryInterface(t interface {
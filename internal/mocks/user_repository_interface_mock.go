@@ -12,6 +12,8 @@ import (
 	model "github.com/guttosm/pack-service/internal/domain/model"
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	time "time"
 )
 
 // MockUserRepositoryInterface is an autogenerated mock type for the UserRepositoryInterface type
@@ -27,6 +29,63 @@ func (_m *MockUserRepositoryInterface) EXPECT() *MockUserRepositoryInterface_Exp
 	return &MockUserRepositoryInterface_Expecter{mock: &_m.Mock}
 }
 
+// Count provides a mock function with given fields: ctx, filter
+func (_m *MockUserRepositoryInterface) Count(ctx context.Context, filter bson.M) (int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bson.M) (int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bson.M) int64); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bson.M) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepositoryInterface_Count_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Count'
+type MockUserRepositoryInterface_Count_Call struct {
+	*mock.Call
+}
+
+// Count is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter bson.M
+func (_e *MockUserRepositoryInterface_Expecter) Count(ctx interface{}, filter interface{}) *MockUserRepositoryInterface_Count_Call {
+	return &MockUserRepositoryInterface_Count_Call{Call: _e.mock.On("Count", ctx, filter)}
+}
+
+func (_c *MockUserRepositoryInterface_Count_Call) Run(run func(ctx context.Context, filter bson.M)) *MockUserRepositoryInterface_Count_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(bson.M))
+	})
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_Count_Call) Return(_a0 int64, _a1 error) *MockUserRepositoryInterface_Count_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_Count_Call) RunAndReturn(run func(context.Context, bson.M) (int64, error)) *MockUserRepositoryInterface_Count_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function with given fields: ctx, user
 func (_m *MockUserRepositoryInterface) Create(ctx context.Context, user *model.User) error {
 	ret := _m.Called(ctx, user)
@@ -74,6 +133,53 @@ func (_c *MockUserRepositoryInterface_Create_Call) RunAndReturn(run func(context
 	return _c
 }
 
+// CreateMany provides a mock function with given fields: ctx, users
+func (_m *MockUserRepositoryInterface) CreateMany(ctx context.Context, users []*model.User) error {
+	ret := _m.Called(ctx, users)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMany")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.User) error); ok {
+		r0 = rf(ctx, users)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepositoryInterface_CreateMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMany'
+type MockUserRepositoryInterface_CreateMany_Call struct {
+	*mock.Call
+}
+
+// CreateMany is a helper method to define mock.On call
+//   - ctx context.Context
+//   - users []*model.User
+func (_e *MockUserRepositoryInterface_Expecter) CreateMany(ctx interface{}, users interface{}) *MockUserRepositoryInterface_CreateMany_Call {
+	return &MockUserRepositoryInterface_CreateMany_Call{Call: _e.mock.On("CreateMany", ctx, users)}
+}
+
+func (_c *MockUserRepositoryInterface_CreateMany_Call) Run(run func(ctx context.Context, users []*model.User)) *MockUserRepositoryInterface_CreateMany_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]*model.User))
+	})
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_CreateMany_Call) Return(_a0 error) *MockUserRepositoryInterface_CreateMany_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_CreateMany_Call) RunAndReturn(run func(context.Context, []*model.User) error) *MockUserRepositoryInterface_CreateMany_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Delete provides a mock function with given fields: ctx, id
 func (_m *MockUserRepositoryInterface) Delete(ctx context.Context, id primitive.ObjectID) error {
 	ret := _m.Called(ctx, id)
@@ -416,6 +522,178 @@ func (_c *MockUserRepositoryInterface_FindByUsername_Call) RunAndReturn(run func
 	return _c
 }
 
+// GetTokenVersion provides a mock function with given fields: ctx, id
+func (_m *MockUserRepositoryInterface) GetTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenVersion")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) (int, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) int); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepositoryInterface_GetTokenVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTokenVersion'
+type MockUserRepositoryInterface_GetTokenVersion_Call struct {
+	*mock.Call
+}
+
+// GetTokenVersion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+func (_e *MockUserRepositoryInterface_Expecter) GetTokenVersion(ctx interface{}, id interface{}) *MockUserRepositoryInterface_GetTokenVersion_Call {
+	return &MockUserRepositoryInterface_GetTokenVersion_Call{Call: _e.mock.On("GetTokenVersion", ctx, id)}
+}
+
+func (_c *MockUserRepositoryInterface_GetTokenVersion_Call) Run(run func(ctx context.Context, id primitive.ObjectID)) *MockUserRepositoryInterface_GetTokenVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID))
+	})
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_GetTokenVersion_Call) Return(_a0 int, _a1 error) *MockUserRepositoryInterface_GetTokenVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_GetTokenVersion_Call) RunAndReturn(run func(context.Context, primitive.ObjectID) (int, error)) *MockUserRepositoryInterface_GetTokenVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HardDelete provides a mock function with given fields: ctx, id, deletedBefore
+func (_m *MockUserRepositoryInterface) HardDelete(ctx context.Context, id primitive.ObjectID, deletedBefore time.Time) (bool, error) {
+	ret := _m.Called(ctx, id, deletedBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HardDelete")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, time.Time) (bool, error)); ok {
+		return rf(ctx, id, deletedBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, time.Time) bool); ok {
+		r0 = rf(ctx, id, deletedBefore)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, time.Time) error); ok {
+		r1 = rf(ctx, id, deletedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepositoryInterface_HardDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HardDelete'
+type MockUserRepositoryInterface_HardDelete_Call struct {
+	*mock.Call
+}
+
+// HardDelete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+//   - deletedBefore time.Time
+func (_e *MockUserRepositoryInterface_Expecter) HardDelete(ctx interface{}, id interface{}, deletedBefore interface{}) *MockUserRepositoryInterface_HardDelete_Call {
+	return &MockUserRepositoryInterface_HardDelete_Call{Call: _e.mock.On("HardDelete", ctx, id, deletedBefore)}
+}
+
+func (_c *MockUserRepositoryInterface_HardDelete_Call) Run(run func(ctx context.Context, id primitive.ObjectID, deletedBefore time.Time)) *MockUserRepositoryInterface_HardDelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_HardDelete_Call) Return(_a0 bool, _a1 error) *MockUserRepositoryInterface_HardDelete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_HardDelete_Call) RunAndReturn(run func(context.Context, primitive.ObjectID, time.Time) (bool, error)) *MockUserRepositoryInterface_HardDelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementTokenVersion provides a mock function with given fields: ctx, id
+func (_m *MockUserRepositoryInterface) IncrementTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementTokenVersion")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) (int, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) int); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepositoryInterface_IncrementTokenVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementTokenVersion'
+type MockUserRepositoryInterface_IncrementTokenVersion_Call struct {
+	*mock.Call
+}
+
+// IncrementTokenVersion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+func (_e *MockUserRepositoryInterface_Expecter) IncrementTokenVersion(ctx interface{}, id interface{}) *MockUserRepositoryInterface_IncrementTokenVersion_Call {
+	return &MockUserRepositoryInterface_IncrementTokenVersion_Call{Call: _e.mock.On("IncrementTokenVersion", ctx, id)}
+}
+
+func (_c *MockUserRepositoryInterface_IncrementTokenVersion_Call) Run(run func(ctx context.Context, id primitive.ObjectID)) *MockUserRepositoryInterface_IncrementTokenVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID))
+	})
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_IncrementTokenVersion_Call) Return(_a0 int, _a1 error) *MockUserRepositoryInterface_IncrementTokenVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_IncrementTokenVersion_Call) RunAndReturn(run func(context.Context, primitive.ObjectID) (int, error)) *MockUserRepositoryInterface_IncrementTokenVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // List provides a mock function with given fields: ctx, filter, limit, skip
 func (_m *MockUserRepositoryInterface) List(ctx context.Context, filter bson.M, limit int64, skip int64) ([]*model.User, error) {
 	ret := _m.Called(ctx, filter, limit, skip)
@@ -477,6 +755,101 @@ func (_c *MockUserRepositoryInterface_List_Call) RunAndReturn(run func(context.C
 	return _c
 }
 
+// RecordLogin provides a mock function with given fields: ctx, id, login
+func (_m *MockUserRepositoryInterface) RecordLogin(ctx context.Context, id primitive.ObjectID, login model.LoginRecord) error {
+	ret := _m.Called(ctx, id, login)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, model.LoginRecord) error); ok {
+		r0 = rf(ctx, id, login)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepositoryInterface_RecordLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordLogin'
+type MockUserRepositoryInterface_RecordLogin_Call struct {
+	*mock.Call
+}
+
+// RecordLogin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+//   - login model.LoginRecord
+func (_e *MockUserRepositoryInterface_Expecter) RecordLogin(ctx interface{}, id interface{}, login interface{}) *MockUserRepositoryInterface_RecordLogin_Call {
+	return &MockUserRepositoryInterface_RecordLogin_Call{Call: _e.mock.On("RecordLogin", ctx, id, login)}
+}
+
+func (_c *MockUserRepositoryInterface_RecordLogin_Call) Run(run func(ctx context.Context, id primitive.ObjectID, login model.LoginRecord)) *MockUserRepositoryInterface_RecordLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID), args[2].(model.LoginRecord))
+	})
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_RecordLogin_Call) Return(_a0 error) *MockUserRepositoryInterface_RecordLogin_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_RecordLogin_Call) RunAndReturn(run func(context.Context, primitive.ObjectID, model.LoginRecord) error) *MockUserRepositoryInterface_RecordLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *MockUserRepositoryInterface) Restore(ctx context.Context, id primitive.ObjectID) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepositoryInterface_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type MockUserRepositoryInterface_Restore_Call struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id primitive.ObjectID
+func (_e *MockUserRepositoryInterface_Expecter) Restore(ctx interface{}, id interface{}) *MockUserRepositoryInterface_Restore_Call {
+	return &MockUserRepositoryInterface_Restore_Call{Call: _e.mock.On("Restore", ctx, id)}
+}
+
+func (_c *MockUserRepositoryInterface_Restore_Call) Run(run func(ctx context.Context, id primitive.ObjectID)) *MockUserRepositoryInterface_Restore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(primitive.ObjectID))
+	})
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_Restore_Call) Return(_a0 error) *MockUserRepositoryInterface_Restore_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepositoryInterface_Restore_Call) RunAndReturn(run func(context.Context, primitive.ObjectID) error) *MockUserRepositoryInterface_Restore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Update provides a mock function with given fields: ctx, user
 func (_m *MockUserRepositoryInterface) Update(ctx context.Context, user *model.User) error {
 	ret := _m.Called(ctx, user)
@@ -0,0 +1,873 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/guttosm/pack-service/internal/domain/dto"
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// MockUserService is an autogenerated mock type for the UserService type
+type MockUserService struct {
+	mock.Mock
+}
+
+type MockUserService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUserService) EXPECT() *MockUserService_Expecter {
+	return &MockUserService_Expecter{mock: &_m.Mock}
+}
+
+// Deactivate provides a mock function with given fields: ctx, id, actorID
+func (_m *MockUserService) Deactivate(ctx context.Context, id string, actorID string) (*model.User, error) {
+	ret := _m.Called(ctx, id, actorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deactivate")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.User, error)); ok {
+		return rf(ctx, id, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.User); ok {
+		r0 = rf(ctx, id, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, id, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_Deactivate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Deactivate'
+type MockUserService_Deactivate_Call struct {
+	*mock.Call
+}
+
+// Deactivate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - actorID string
+func (_e *MockUserService_Expecter) Deactivate(ctx interface{}, id interface{}, actorID interface{}) *MockUserService_Deactivate_Call {
+	return &MockUserService_Deactivate_Call{Call: _e.mock.On("Deactivate", ctx, id, actorID)}
+}
+
+func (_c *MockUserService_Deactivate_Call) Run(run func(ctx context.Context, id string, actorID string)) *MockUserService_Deactivate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Deactivate_Call) Return(_a0 *model.User, _a1 error) *MockUserService_Deactivate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_Deactivate_Call) RunAndReturn(run func(context.Context, string, string) (*model.User, error)) *MockUserService_Deactivate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeactivateStale provides a mock function with given fields: ctx, days
+func (_m *MockUserService) DeactivateStale(ctx context.Context, days int) ([]*model.User, error) {
+	ret := _m.Called(ctx, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeactivateStale")
+	}
+
+	var r0 []*model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*model.User, error)); ok {
+		return rf(ctx, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*model.User); ok {
+		r0 = rf(ctx, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_DeactivateStale_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeactivateStale'
+type MockUserService_DeactivateStale_Call struct {
+	*mock.Call
+}
+
+// DeactivateStale is a helper method to define mock.On call
+//   - ctx context.Context
+//   - days int
+func (_e *MockUserService_Expecter) DeactivateStale(ctx interface{}, days interface{}) *MockUserService_DeactivateStale_Call {
+	return &MockUserService_DeactivateStale_Call{Call: _e.mock.On("DeactivateStale", ctx, days)}
+}
+
+func (_c *MockUserService_DeactivateStale_Call) Run(run func(ctx context.Context, days int)) *MockUserService_DeactivateStale_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserService_DeactivateStale_Call) Return(_a0 []*model.User, _a1 error) *MockUserService_DeactivateStale_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_DeactivateStale_Call) RunAndReturn(run func(context.Context, int) ([]*model.User, error)) *MockUserService_DeactivateStale_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Disable provides a mock function with given fields: ctx, id, actorID
+func (_m *MockUserService) Disable(ctx context.Context, id string, actorID string) (*model.User, error) {
+	ret := _m.Called(ctx, id, actorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Disable")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.User, error)); ok {
+		return rf(ctx, id, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.User); ok {
+		r0 = rf(ctx, id, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, id, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_Disable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Disable'
+type MockUserService_Disable_Call struct {
+	*mock.Call
+}
+
+// Disable is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - actorID string
+func (_e *MockUserService_Expecter) Disable(ctx interface{}, id interface{}, actorID interface{}) *MockUserService_Disable_Call {
+	return &MockUserService_Disable_Call{Call: _e.mock.On("Disable", ctx, id, actorID)}
+}
+
+func (_c *MockUserService_Disable_Call) Run(run func(ctx context.Context, id string, actorID string)) *MockUserService_Disable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Disable_Call) Return(_a0 *model.User, _a1 error) *MockUserService_Disable_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_Disable_Call) RunAndReturn(run func(context.Context, string, string) (*model.User, error)) *MockUserService_Disable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EffectivePermissions provides a mock function with given fields: ctx, id
+func (_m *MockUserService) EffectivePermissions(ctx context.Context, id string) (*dto.EffectivePermissions, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EffectivePermissions")
+	}
+
+	var r0 *dto.EffectivePermissions
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.EffectivePermissions, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.EffectivePermissions); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.EffectivePermissions)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_EffectivePermissions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EffectivePermissions'
+type MockUserService_EffectivePermissions_Call struct {
+	*mock.Call
+}
+
+// EffectivePermissions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserService_Expecter) EffectivePermissions(ctx interface{}, id interface{}) *MockUserService_EffectivePermissions_Call {
+	return &MockUserService_EffectivePermissions_Call{Call: _e.mock.On("EffectivePermissions", ctx, id)}
+}
+
+func (_c *MockUserService_EffectivePermissions_Call) Run(run func(ctx context.Context, id string)) *MockUserService_EffectivePermissions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_EffectivePermissions_Call) Return(_a0 *dto.EffectivePermissions, _a1 error) *MockUserService_EffectivePermissions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_EffectivePermissions_Call) RunAndReturn(run func(context.Context, string) (*dto.EffectivePermissions, error)) *MockUserService_EffectivePermissions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: ctx, id
+func (_m *MockUserService) Get(ctx context.Context, id string) (*model.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockUserService_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserService_Expecter) Get(ctx interface{}, id interface{}) *MockUserService_Get_Call {
+	return &MockUserService_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *MockUserService_Get_Call) Run(run func(ctx context.Context, id string)) *MockUserService_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Get_Call) Return(_a0 *model.User, _a1 error) *MockUserService_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_Get_Call) RunAndReturn(run func(context.Context, string) (*model.User, error)) *MockUserService_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HardDelete provides a mock function with given fields: ctx, id
+func (_m *MockUserService) HardDelete(ctx context.Context, id string) (*model.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HardDelete")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_HardDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HardDelete'
+type MockUserService_HardDelete_Call struct {
+	*mock.Call
+}
+
+// HardDelete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserService_Expecter) HardDelete(ctx interface{}, id interface{}) *MockUserService_HardDelete_Call {
+	return &MockUserService_HardDelete_Call{Call: _e.mock.On("HardDelete", ctx, id)}
+}
+
+func (_c *MockUserService_HardDelete_Call) Run(run func(ctx context.Context, id string)) *MockUserService_HardDelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_HardDelete_Call) Return(_a0 *model.User, _a1 error) *MockUserService_HardDelete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_HardDelete_Call) RunAndReturn(run func(context.Context, string) (*model.User, error)) *MockUserService_HardDelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Import provides a mock function with given fields: ctx, users
+func (_m *MockUserService) Import(ctx context.Context, users []dto.ImportUser) (*dto.ImportUsersResult, error) {
+	ret := _m.Called(ctx, users)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Import")
+	}
+
+	var r0 *dto.ImportUsersResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []dto.ImportUser) (*dto.ImportUsersResult, error)); ok {
+		return rf(ctx, users)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []dto.ImportUser) *dto.ImportUsersResult); ok {
+		r0 = rf(ctx, users)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ImportUsersResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []dto.ImportUser) error); ok {
+		r1 = rf(ctx, users)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_Import_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Import'
+type MockUserService_Import_Call struct {
+	*mock.Call
+}
+
+// Import is a helper method to define mock.On call
+//   - ctx context.Context
+//   - users []dto.ImportUser
+func (_e *MockUserService_Expecter) Import(ctx interface{}, users interface{}) *MockUserService_Import_Call {
+	return &MockUserService_Import_Call{Call: _e.mock.On("Import", ctx, users)}
+}
+
+func (_c *MockUserService_Import_Call) Run(run func(ctx context.Context, users []dto.ImportUser)) *MockUserService_Import_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]dto.ImportUser))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Import_Call) Return(_a0 *dto.ImportUsersResult, _a1 error) *MockUserService_Import_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_Import_Call) RunAndReturn(run func(context.Context, []dto.ImportUser) (*dto.ImportUsersResult, error)) *MockUserService_Import_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, filter, limit, offset
+func (_m *MockUserService) List(ctx context.Context, filter dto.UserFilter, limit int, offset int) (*dto.UserPage, error) {
+	ret := _m.Called(ctx, filter, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 *dto.UserPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.UserFilter, int, int) (*dto.UserPage, error)); ok {
+		return rf(ctx, filter, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.UserFilter, int, int) *dto.UserPage); ok {
+		r0 = rf(ctx, filter, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.UserFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockUserService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter dto.UserFilter
+//   - limit int
+//   - offset int
+func (_e *MockUserService_Expecter) List(ctx interface{}, filter interface{}, limit interface{}, offset interface{}) *MockUserService_List_Call {
+	return &MockUserService_List_Call{Call: _e.mock.On("List", ctx, filter, limit, offset)}
+}
+
+func (_c *MockUserService_List_Call) Run(run func(ctx context.Context, filter dto.UserFilter, limit int, offset int)) *MockUserService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.UserFilter), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserService_List_Call) Return(_a0 *dto.UserPage, _a1 error) *MockUserService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_List_Call) RunAndReturn(run func(context.Context, dto.UserFilter, int, int) (*dto.UserPage, error)) *MockUserService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListStale provides a mock function with given fields: ctx, days, limit, offset
+func (_m *MockUserService) ListStale(ctx context.Context, days int, limit int, offset int) (*dto.StaleUserPage, error) {
+	ret := _m.Called(ctx, days, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListStale")
+	}
+
+	var r0 *dto.StaleUserPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) (*dto.StaleUserPage, error)); ok {
+		return rf(ctx, days, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) *dto.StaleUserPage); ok {
+		r0 = rf(ctx, days, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.StaleUserPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(ctx, days, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_ListStale_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListStale'
+type MockUserService_ListStale_Call struct {
+	*mock.Call
+}
+
+// ListStale is a helper method to define mock.On call
+//   - ctx context.Context
+//   - days int
+//   - limit int
+//   - offset int
+func (_e *MockUserService_Expecter) ListStale(ctx interface{}, days interface{}, limit interface{}, offset interface{}) *MockUserService_ListStale_Call {
+	return &MockUserService_ListStale_Call{Call: _e.mock.On("ListStale", ctx, days, limit, offset)}
+}
+
+func (_c *MockUserService_ListStale_Call) Run(run func(ctx context.Context, days int, limit int, offset int)) *MockUserService_ListStale_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListStale_Call) Return(_a0 *dto.StaleUserPage, _a1 error) *MockUserService_ListStale_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_ListStale_Call) RunAndReturn(run func(context.Context, int, int, int) (*dto.StaleUserPage, error)) *MockUserService_ListStale_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reactivate provides a mock function with given fields: ctx, id
+func (_m *MockUserService) Reactivate(ctx context.Context, id string) (*model.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reactivate")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_Reactivate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reactivate'
+type MockUserService_Reactivate_Call struct {
+	*mock.Call
+}
+
+// Reactivate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserService_Expecter) Reactivate(ctx interface{}, id interface{}) *MockUserService_Reactivate_Call {
+	return &MockUserService_Reactivate_Call{Call: _e.mock.On("Reactivate", ctx, id)}
+}
+
+func (_c *MockUserService_Reactivate_Call) Run(run func(ctx context.Context, id string)) *MockUserService_Reactivate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Reactivate_Call) Return(_a0 *model.User, _a1 error) *MockUserService_Reactivate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_Reactivate_Call) RunAndReturn(run func(context.Context, string) (*model.User, error)) *MockUserService_Reactivate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *MockUserService) Restore(ctx context.Context, id string) (*model.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type MockUserService_Restore_Call struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserService_Expecter) Restore(ctx interface{}, id interface{}) *MockUserService_Restore_Call {
+	return &MockUserService_Restore_Call{Call: _e.mock.On("Restore", ctx, id)}
+}
+
+func (_c *MockUserService_Restore_Call) Run(run func(ctx context.Context, id string)) *MockUserService_Restore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Restore_Call) Return(_a0 *model.User, _a1 error) *MockUserService_Restore_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_Restore_Call) RunAndReturn(run func(context.Context, string) (*model.User, error)) *MockUserService_Restore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSessions provides a mock function with given fields: ctx, id
+func (_m *MockUserService) RevokeSessions(ctx context.Context, id string) (*model.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessions")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_RevokeSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSessions'
+type MockUserService_RevokeSessions_Call struct {
+	*mock.Call
+}
+
+// RevokeSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserService_Expecter) RevokeSessions(ctx interface{}, id interface{}) *MockUserService_RevokeSessions_Call {
+	return &MockUserService_RevokeSessions_Call{Call: _e.mock.On("RevokeSessions", ctx, id)}
+}
+
+func (_c *MockUserService_RevokeSessions_Call) Run(run func(ctx context.Context, id string)) *MockUserService_RevokeSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RevokeSessions_Call) Return(_a0 *model.User, _a1 error) *MockUserService_RevokeSessions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_RevokeSessions_Call) RunAndReturn(run func(context.Context, string) (*model.User, error)) *MockUserService_RevokeSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, id, update
+func (_m *MockUserService) Update(ctx context.Context, id string, update *dto.UpdateUserRequest) (*model.User, error) {
+	ret := _m.Called(ctx, id, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateUserRequest) (*model.User, error)); ok {
+		return rf(ctx, id, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateUserRequest) *model.User); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.UpdateUserRequest) error); ok {
+		r1 = rf(ctx, id, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockUserService_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - update *dto.UpdateUserRequest
+func (_e *MockUserService_Expecter) Update(ctx interface{}, id interface{}, update interface{}) *MockUserService_Update_Call {
+	return &MockUserService_Update_Call{Call: _e.mock.On("Update", ctx, id, update)}
+}
+
+func (_c *MockUserService_Update_Call) Run(run func(ctx context.Context, id string, update *dto.UpdateUserRequest)) *MockUserService_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*dto.UpdateUserRequest))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Update_Call) Return(_a0 *model.User, _a1 error) *MockUserService_Update_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_Update_Call) RunAndReturn(run func(context.Context, string, *dto.UpdateUserRequest) (*model.User, error)) *MockUserService_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, id, update
+func (_m *MockUserService) UpdateProfile(ctx context.Context, id string, update *dto.UpdateProfileRequest) (*model.User, error) {
+	ret := _m.Called(ctx, id, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProfile")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateProfileRequest) (*model.User, error)); ok {
+		return rf(ctx, id, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateProfileRequest) *model.User); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.UpdateProfileRequest) error); ok {
+		r1 = rf(ctx, id, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_UpdateProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProfile'
+type MockUserService_UpdateProfile_Call struct {
+	*mock.Call
+}
+
+// UpdateProfile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - update *dto.UpdateProfileRequest
+func (_e *MockUserService_Expecter) UpdateProfile(ctx interface{}, id interface{}, update interface{}) *MockUserService_UpdateProfile_Call {
+	return &MockUserService_UpdateProfile_Call{Call: _e.mock.On("UpdateProfile", ctx, id, update)}
+}
+
+func (_c *MockUserService_UpdateProfile_Call) Run(run func(ctx context.Context, id string, update *dto.UpdateProfileRequest)) *MockUserService_UpdateProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*dto.UpdateProfileRequest))
+	})
+	return _c
+}
+
+func (_c *MockUserService_UpdateProfile_Call) Return(_a0 *model.User, _a1 error) *MockUserService_UpdateProfile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_UpdateProfile_Call) RunAndReturn(run func(context.Context, string, *dto.UpdateProfileRequest) (*model.User, error)) *MockUserService_UpdateProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockUserService creates a new instance of MockUserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUserService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUserService {
+	mock := &MockUserService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
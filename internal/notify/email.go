@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EmailConfig holds SMTP settings for the email notifier.
+type EmailConfig struct {
+	// Addr is the SMTP server address in host:port form.
+	Addr string
+	// Username and Password enable PLAIN auth when Username is set.
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// EmailNotifier sends notifications as plain-text email over SMTP.
+type EmailNotifier struct {
+	config EmailConfig
+	auth   smtp.Auth
+	send   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier creates an email notifier for the given SMTP settings.
+func NewEmailNotifier(cfg EmailConfig) *EmailNotifier {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			host = cfg.Addr
+		}
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	return &EmailNotifier{
+		config: cfg,
+		auth:   auth,
+		send:   smtp.SendMail,
+	}
+}
+
+// Notify sends n to all configured recipients.
+// smtp.SendMail does not accept a context, so ctx is only checked before sending.
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return e.send(e.config.Addr, e.auth, e.config.From, e.config.To, e.buildMessage(n))
+}
+
+// buildMessage renders n as an RFC 5322 message.
+func (e *EmailNotifier) buildMessage(n Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&b, "Subject: [%s] %s\r\n", strings.ToUpper(string(n.Severity)), n.Title)
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(n.Message)
+	b.WriteString("\r\n")
+
+	if len(n.Fields) > 0 {
+		keys := make([]string, 0, len(n.Fields))
+		for k := range n.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("\r\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "%s: %v\r\n", k, n.Fields[k])
+		}
+	}
+
+	fmt.Fprintf(&b, "\r\nsource: %s\r\ntime: %s\r\n", n.Source, n.Timestamp.UTC().Format(time.RFC3339))
+	return []byte(b.String())
+}
@@ -0,0 +1,68 @@
+//go:build !integration
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	notifier := NewEmailNotifier(EmailConfig{
+		Addr:     "smtp.example.com:587",
+		Username: "alerts",
+		Password: "secret",
+		From:     "alerts@example.com",
+		To:       []string{"oncall@example.com", "ops@example.com"},
+	})
+	assert.NotNil(t, notifier.auth)
+
+	var (
+		gotAddr string
+		gotTo   []string
+		gotMsg  string
+	)
+	notifier.send = func(addr string, _ smtp.Auth, _ string, to []string, msg []byte) error {
+		gotAddr, gotTo, gotMsg = addr, to, string(msg)
+		return nil
+	}
+
+	err := notifier.Notify(context.Background(), Notification{
+		Title:     "Circuit breaker db is flapping",
+		Message:   "Opened 3 times",
+		Severity:  SeverityCritical,
+		Source:    "circuit_breaker",
+		Fields:    map[string]interface{}{"trips": 3, "circuit_breaker": "db"},
+		Timestamp: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, []string{"oncall@example.com", "ops@example.com"}, gotTo)
+	assert.Contains(t, gotMsg, "Subject: [CRITICAL] Circuit breaker db is flapping\r\n")
+	assert.Contains(t, gotMsg, "To: oncall@example.com, ops@example.com\r\n")
+	assert.Contains(t, gotMsg, "circuit_breaker: db\r\ntrips: 3\r\n")
+	assert.Contains(t, gotMsg, "time: 2026-01-01T00:00:00Z")
+}
+
+func TestEmailNotifier_NoAuthWithoutUsername(t *testing.T) {
+	notifier := NewEmailNotifier(EmailConfig{Addr: "localhost:25"})
+	assert.Nil(t, notifier.auth)
+}
+
+func TestEmailNotifier_Errors(t *testing.T) {
+	notifier := NewEmailNotifier(EmailConfig{Addr: "localhost:25", From: "a@example.com", To: []string{"b@example.com"}})
+	notifier.send = func(string, smtp.Auth, string, []string, []byte) error {
+		return errors.New("connection refused")
+	}
+	assert.Error(t, notifier.Notify(context.Background(), Notification{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, notifier.Notify(ctx, Notification{}), context.Canceled)
+}
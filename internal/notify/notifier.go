@@ -0,0 +1,85 @@
+// Package notify provides outbound notifications for operational events.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Severity indicates how urgent a notification is.
+type Severity string
+
+const (
+	// SeverityInfo is used for informational notifications.
+	SeverityInfo Severity = "info"
+	// SeverityWarning is used for conditions that need attention soon.
+	SeverityWarning Severity = "warning"
+	// SeverityCritical is used for conditions that need attention now.
+	SeverityCritical Severity = "critical"
+)
+
+// Notification is a single message delivered by a Notifier.
+type Notification struct {
+	Title     string                 `json:"title"`
+	Message   string                 `json:"message"`
+	Severity  Severity               `json:"severity"`
+	Source    string                 `json:"source"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// Notifier delivers notifications to an external channel.
+// This interface can be mocked for testing using mockery.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// MultiNotifier delivers each notification to all of its notifiers.
+type MultiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier creates a notifier that fans out to the given notifiers.
+func NewMultiNotifier(notifiers ...Notifier) *MultiNotifier {
+	return &MultiNotifier{notifiers: notifiers}
+}
+
+// Notify delivers n to every notifier and returns the joined errors, if any.
+// A failing notifier does not prevent delivery to the others.
+func (m *MultiNotifier) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogNotifier writes notifications to the application log.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a notifier that writes to the application log.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify logs the notification at a level matching its severity.
+func (l *LogNotifier) Notify(_ context.Context, n Notification) error {
+	event := log.Info()
+	switch n.Severity {
+	case SeverityWarning:
+		event = log.Warn()
+	case SeverityCritical:
+		event = log.Error()
+	}
+	event.
+		Str("title", n.Title).
+		Str("source", n.Source).
+		Fields(n.Fields).
+		Msg(n.Message)
+	return nil
+}
@@ -0,0 +1,52 @@
+//go:build !integration
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier records the notifications it receives.
+type recordingNotifier struct {
+	received []Notification
+	err      error
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n Notification) error {
+	r.received = append(r.received, n)
+	return r.err
+}
+
+func TestMultiNotifier_Notify(t *testing.T) {
+	first := &recordingNotifier{}
+	failing := &recordingNotifier{err: errors.New("boom")}
+	last := &recordingNotifier{}
+
+	multi := NewMultiNotifier(first, failing, last)
+	err := multi.Notify(context.Background(), Notification{Title: "test"})
+
+	assert.ErrorContains(t, err, "boom")
+	assert.Len(t, first.received, 1)
+	assert.Len(t, failing.received, 1)
+	assert.Len(t, last.received, 1, "a failing notifier must not block the others")
+}
+
+func TestMultiNotifier_Empty(t *testing.T) {
+	assert.NoError(t, NewMultiNotifier().Notify(context.Background(), Notification{}))
+}
+
+func TestLogNotifier_Notify(t *testing.T) {
+	notifier := NewLogNotifier()
+
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityCritical} {
+		assert.NoError(t, notifier.Notify(context.Background(), Notification{
+			Title:    "test",
+			Severity: severity,
+			Fields:   map[string]interface{}{"key": "value"},
+		}))
+	}
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/httpclient"
+)
+
+// WebhookNotifier posts notifications as JSON to an HTTP endpoint.
+type WebhookNotifier struct {
+	url    string
+	client *httpclient.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with the given request timeout.
+// Failed deliveries are retried, since a duplicate alert beats a lost one.
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url: url,
+		client: httpclient.New(httpclient.Config{
+			Name:               "alert_webhook",
+			Timeout:            timeout,
+			RetryNonIdempotent: true,
+		}),
+	}
+}
+
+// Notify posts n to the webhook URL. Any non-2xx response is an error.
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Webhook health states.
+const (
+	WebhookUnknown = "unknown"
+	WebhookHealthy = "healthy"
+	WebhookFailing = "failing"
+	WebhookPaused  = "paused"
+)
+
+// ErrWebhookPaused is returned instead of delivering to a paused webhook.
+var ErrWebhookPaused = errors.New("webhook delivery paused after repeated failures")
+
+// WebhookMonitorConfig configures a WebhookMonitor.
+type WebhookMonitorConfig struct {
+	// Interval is the time between probes of a webhook that is not paused,
+	// and the first pause once it is.
+	Interval time.Duration
+	// Timeout bounds each probe.
+	Timeout time.Duration
+	// FailureThreshold pauses delivery after this many consecutive failed
+	// probes or deliveries.
+	FailureThreshold int
+	// MaxPause caps the pause, which doubles with every failed probe.
+	MaxPause time.Duration
+}
+
+// DefaultWebhookMonitorConfig returns the default monitor configuration.
+func DefaultWebhookMonitorConfig() WebhookMonitorConfig {
+	return WebhookMonitorConfig{
+		Interval:         time.Minute,
+		Timeout:          5 * time.Second,
+		FailureThreshold: 3,
+		MaxPause:         time.Hour,
+	}
+}
+
+// WebhookHealth is the health of one monitored webhook.
+type WebhookHealth struct {
+	Name string `json:"name"`
+	// URL has its path and query removed, since webhook URLs often embed secrets.
+	URL                 string     `json:"url"`
+	Status              string     `json:"status"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastCheckedAt       *time.Time `json:"last_checked_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	PausedUntil         *time.Time `json:"paused_until,omitempty"`
+	SkippedDeliveries   int64      `json:"skipped_deliveries"`
+}
+
+// webhookEndpoint is the state of one monitored webhook. Fields are guarded
+// by the monitor's mutex.
+type webhookEndpoint struct {
+	url    string
+	health WebhookHealth
+	// nextProbe is when the endpoint is probed next.
+	nextProbe time.Time
+}
+
+// WebhookMonitor probes webhook URLs in the background and pauses delivery to
+// the ones that keep failing, so notifications are not spent on dead
+// endpoints. A paused webhook is probed again after a pause that doubles with
+// every failed probe, and resumes on the first successful probe.
+type WebhookMonitor struct {
+	cfg    WebhookMonitorConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	endpoints map[string]*webhookEndpoint
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewWebhookMonitor creates a monitor. Zero config fields take their default.
+// Call Start to run the probes.
+func NewWebhookMonitor(cfg WebhookMonitorConfig) *WebhookMonitor {
+	defaults := DefaultWebhookMonitorConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaults.Interval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaults.FailureThreshold
+	}
+	if cfg.MaxPause < cfg.Interval {
+		cfg.MaxPause = cfg.Interval
+	}
+	return &WebhookMonitor{
+		cfg: cfg,
+		// Probes report the endpoint's own response, not where it redirects
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now:       time.Now,
+		endpoints: make(map[string]*webhookEndpoint),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// Watch monitors rawURL under name. Watching a name again keeps its health.
+func (m *WebhookMonitor) Watch(name, rawURL string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.endpoints[name]; ok {
+		return
+	}
+	m.endpoints[name] = &webhookEndpoint{
+		url: rawURL,
+		health: WebhookHealth{
+			Name:   name,
+			URL:    redactURL(rawURL),
+			Status: WebhookUnknown,
+		},
+	}
+}
+
+// Guard watches rawURL under name and returns a notifier that delivers to
+// next unless the webhook is paused. Delivery outcomes count towards the
+// webhook's health like probes do.
+func (m *WebhookMonitor) Guard(name, rawURL string, next Notifier) Notifier {
+	m.Watch(name, rawURL)
+	return &guardedWebhook{monitor: m, name: name, next: next}
+}
+
+// Start probes every webhook right away, then every interval until Stop is
+// called.
+func (m *WebhookMonitor) Start() {
+	go func() {
+		defer close(m.doneCh)
+
+		m.ProbeDue(context.Background())
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.ProbeDue(context.Background())
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the probes and waits for a running round to finish. It must
+// only be called after Start.
+func (m *WebhookMonitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+		<-m.doneCh
+	})
+}
+
+// ProbeDue probes the webhooks whose next probe is due.
+func (m *WebhookMonitor) ProbeDue(ctx context.Context) {
+	now := m.now()
+	m.mu.Lock()
+	due := make(map[string]string)
+	for name, endpoint := range m.endpoints {
+		if !now.Before(endpoint.nextProbe) {
+			due[name] = endpoint.url
+		}
+	}
+	m.mu.Unlock()
+
+	for name, rawURL := range due {
+		m.record(name, m.probe(ctx, rawURL))
+	}
+}
+
+// probe sends a HEAD request to rawURL, or OPTIONS if HEAD is not allowed.
+// Any response below 500 means the endpoint is up: a webhook receiver need
+// not accept these methods, only answer.
+func (m *WebhookMonitor) probe(ctx context.Context, rawURL string) error {
+	status, err := m.send(ctx, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = m.send(ctx, http.MethodOptions, rawURL)
+	}
+	if err != nil {
+		return err
+	}
+	if status >= 500 {
+		return fmt.Errorf("probe returned status %d", status)
+	}
+	return nil
+}
+
+func (m *WebhookMonitor) send(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// record updates a webhook's health with the outcome of a probe or delivery.
+func (m *WebhookMonitor) record(name string, err error) {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	endpoint, ok := m.endpoints[name]
+	if !ok {
+		return
+	}
+	health := &endpoint.health
+	health.LastCheckedAt = &now
+
+	if err == nil {
+		if health.Status == WebhookPaused {
+			log.Info().Str("webhook", name).Msg("Webhook recovered, resuming delivery")
+		}
+		health.Status = WebhookHealthy
+		health.ConsecutiveFailures = 0
+		health.LastSuccessAt = &now
+		health.LastError = ""
+		health.PausedUntil = nil
+		endpoint.nextProbe = now.Add(m.cfg.Interval)
+		return
+	}
+
+	health.ConsecutiveFailures++
+	health.LastError = err.Error()
+	if health.ConsecutiveFailures < m.cfg.FailureThreshold {
+		health.Status = WebhookFailing
+		endpoint.nextProbe = now.Add(m.cfg.Interval)
+		return
+	}
+
+	pause := m.pause(health.ConsecutiveFailures - m.cfg.FailureThreshold)
+	pausedUntil := now.Add(pause)
+	if health.Status != WebhookPaused {
+		log.Warn().Str("webhook", name).Err(err).Int("failures", health.ConsecutiveFailures).
+			Dur("pause", pause).Msg("Webhook keeps failing, pausing delivery")
+	}
+	health.Status = WebhookPaused
+	health.PausedUntil = &pausedUntil
+	endpoint.nextProbe = pausedUntil
+}
+
+// pause returns the interval doubled once per failure past the threshold,
+// capped at MaxPause.
+func (m *WebhookMonitor) pause(failuresPastThreshold int) time.Duration {
+	pause := m.cfg.Interval
+	for i := 0; i < failuresPastThreshold && pause < m.cfg.MaxPause; i++ {
+		pause *= 2
+	}
+	if pause > m.cfg.MaxPause {
+		pause = m.cfg.MaxPause
+	}
+	return pause
+}
+
+// paused reports whether delivery to name is paused, counting the skipped
+// delivery if so.
+func (m *WebhookMonitor) paused(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	endpoint, ok := m.endpoints[name]
+	if !ok || endpoint.health.Status != WebhookPaused {
+		return false
+	}
+	endpoint.health.SkippedDeliveries++
+	return true
+}
+
+// Health returns the health of every monitored webhook, sorted by name.
+func (m *WebhookMonitor) Health() []WebhookHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	health := make([]WebhookHealth, 0, len(m.endpoints))
+	for _, endpoint := range m.endpoints {
+		health = append(health, endpoint.health)
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Name < health[j].Name })
+	return health
+}
+
+// guardedWebhook skips delivery while its webhook is paused.
+type guardedWebhook struct {
+	monitor *WebhookMonitor
+	name    string
+	next    Notifier
+}
+
+// Notify delivers n unless the webhook is paused, in which case it returns
+// ErrWebhookPaused without contacting it.
+func (g *guardedWebhook) Notify(ctx context.Context, n Notification) error {
+	if g.monitor.paused(g.name) {
+		return ErrWebhookPaused
+	}
+	err := g.next.Notify(ctx, n)
+	// A cancelled caller says nothing about the webhook
+	if ctx.Err() == nil {
+		g.monitor.record(g.name, err)
+	}
+	return err
+}
+
+// redactURL keeps the scheme and host of rawURL.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "invalid"
+	}
+	return u.Scheme + "://" + u.Host
+}
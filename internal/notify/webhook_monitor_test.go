@@ -0,0 +1,179 @@
+//go:build !integration
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingNotifier struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (c *countingNotifier) Notify(context.Context, Notification) error {
+	c.calls.Add(1)
+	return c.err
+}
+
+func newTestMonitor(t *testing.T) (*WebhookMonitor, *time.Time) {
+	t.Helper()
+	monitor := NewWebhookMonitor(WebhookMonitorConfig{
+		Interval:         time.Minute,
+		Timeout:          time.Second,
+		FailureThreshold: 2,
+		MaxPause:         5 * time.Minute,
+	})
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	monitor.now = func() time.Time { return now }
+	return monitor, &now
+}
+
+func TestWebhookMonitor_Probe(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus string
+	}{
+		{
+			name:       "head accepted",
+			handler:    func(w http.ResponseWriter, r *http.Request) {},
+			wantStatus: WebhookHealthy,
+		},
+		{
+			name: "falls back to options",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				assert.Equal(t, http.MethodOptions, r.Method)
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantStatus: WebhookHealthy,
+		},
+		{
+			name:       "client error still answers",
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusUnauthorized) },
+			wantStatus: WebhookHealthy,
+		},
+		{
+			name:       "server error",
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) },
+			wantStatus: WebhookFailing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			monitor, _ := newTestMonitor(t)
+			monitor.Guard("alerts", server.URL+"/hooks/secret-token", &countingNotifier{})
+			monitor.ProbeDue(context.Background())
+
+			health := monitor.Health()
+			require.Len(t, health, 1)
+			assert.Equal(t, tt.wantStatus, health[0].Status)
+			assert.Equal(t, server.URL, health[0].URL)
+			assert.NotNil(t, health[0].LastCheckedAt)
+		})
+	}
+}
+
+func TestWebhookMonitor_PausesAndResumes(t *testing.T) {
+	var up atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	monitor, now := newTestMonitor(t)
+	next := &countingNotifier{}
+	notifier := monitor.Guard("alerts", server.URL, next)
+
+	monitor.ProbeDue(context.Background())
+	assert.Equal(t, WebhookFailing, monitor.Health()[0].Status)
+
+	// Not due yet
+	monitor.ProbeDue(context.Background())
+	assert.Equal(t, 1, monitor.Health()[0].ConsecutiveFailures)
+
+	*now = now.Add(time.Minute)
+	monitor.ProbeDue(context.Background())
+	health := monitor.Health()[0]
+	assert.Equal(t, WebhookPaused, health.Status)
+	require.NotNil(t, health.PausedUntil)
+	assert.Equal(t, now.Add(time.Minute), *health.PausedUntil)
+
+	// Deliveries are skipped while paused
+	assert.ErrorIs(t, notifier.Notify(context.Background(), Notification{}), ErrWebhookPaused)
+	assert.Equal(t, int32(0), next.calls.Load())
+	assert.Equal(t, int64(1), monitor.Health()[0].SkippedDeliveries)
+
+	// Each failed probe doubles the pause, up to the maximum
+	for _, want := range []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute} {
+		*now = *monitor.Health()[0].PausedUntil
+		monitor.ProbeDue(context.Background())
+		assert.Equal(t, now.Add(want), *monitor.Health()[0].PausedUntil)
+	}
+
+	up.Store(true)
+	*now = *monitor.Health()[0].PausedUntil
+	monitor.ProbeDue(context.Background())
+	health = monitor.Health()[0]
+	assert.Equal(t, WebhookHealthy, health.Status)
+	assert.Zero(t, health.ConsecutiveFailures)
+	assert.Nil(t, health.PausedUntil)
+
+	require.NoError(t, notifier.Notify(context.Background(), Notification{}))
+	assert.Equal(t, int32(1), next.calls.Load())
+}
+
+func TestWebhookMonitor_DeliveryFailures(t *testing.T) {
+	monitor, _ := newTestMonitor(t)
+	next := &countingNotifier{err: errors.New("connection refused")}
+	notifier := monitor.Guard("alerts", "https://hooks.example.com/alerts", next)
+
+	assert.Error(t, notifier.Notify(context.Background(), Notification{}))
+	assert.Error(t, notifier.Notify(context.Background(), Notification{}))
+	assert.ErrorIs(t, notifier.Notify(context.Background(), Notification{}), ErrWebhookPaused)
+
+	assert.Equal(t, int32(2), next.calls.Load())
+	health := monitor.Health()[0]
+	assert.Equal(t, WebhookPaused, health.Status)
+	assert.Equal(t, "connection refused", health.LastError)
+	assert.Equal(t, "https://hooks.example.com", health.URL)
+
+	// The shared name shares the pause
+	other := monitor.Guard("alerts", "https://hooks.example.com/alerts", next)
+	assert.ErrorIs(t, other.Notify(context.Background(), Notification{}), ErrWebhookPaused)
+	assert.Len(t, monitor.Health(), 1)
+}
+
+func TestWebhookMonitor_StartStop(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+	}))
+	defer server.Close()
+
+	monitor := NewWebhookMonitor(WebhookMonitorConfig{Interval: time.Hour})
+	monitor.Guard("alerts", server.URL, &countingNotifier{})
+	monitor.Start()
+	require.Eventually(t, func() bool { return probes.Load() == 1 }, time.Second, time.Millisecond)
+	monitor.Stop()
+	monitor.Stop()
+}
@@ -0,0 +1,64 @@
+//go:build !integration
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantError bool
+	}{
+		{name: "accepted", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "server error", status: http.StatusInternalServerError, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Notification
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			notifier := NewWebhookNotifier(server.URL, time.Second)
+			err := notifier.Notify(context.Background(), Notification{
+				Title:    "breaker open",
+				Severity: SeverityCritical,
+				Fields:   map[string]interface{}{"trips": 3},
+			})
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "breaker open", got.Title)
+			assert.Equal(t, SeverityCritical, got.Severity)
+		})
+	}
+}
+
+func TestWebhookNotifier_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	notifier := NewWebhookNotifier(url, time.Second)
+	assert.Error(t, notifier.Notify(context.Background(), Notification{}))
+}
@@ -0,0 +1,74 @@
+//go:build !integration
+
+package pagination
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	codec, err := NewCodec(key, time.Minute)
+	require.NoError(t, err)
+
+	cursor := codec.Encode("calculations?user_id=42", 150)
+	assert.NotContains(t, cursor, "150")
+
+	t.Run("round trip", func(t *testing.T) {
+		offset, err := codec.Decode("calculations?user_id=42", cursor)
+		require.NoError(t, err)
+		assert.Equal(t, 150, offset)
+	})
+
+	t.Run("same position gives different cursors", func(t *testing.T) {
+		assert.NotEqual(t, cursor, codec.Encode("calculations?user_id=42", 150))
+	})
+
+	t.Run("another codec with the same key decodes it", func(t *testing.T) {
+		other, err := NewCodec(key, time.Minute)
+		require.NoError(t, err)
+		offset, err := other.Decode("calculations?user_id=42", cursor)
+		require.NoError(t, err)
+		assert.Equal(t, 150, offset)
+	})
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	require.NoError(t, err)
+	raw[len(raw)-20] ^= 1
+	tampered := base64.RawURLEncoding.EncodeToString(raw)
+	randomKey, err := NewCodec(nil, time.Minute)
+	require.NoError(t, err)
+
+	for name, decode := range map[string]func() (int, error){
+		"other scope":   func() (int, error) { return codec.Decode("calculations?user_id=7", cursor) },
+		"tampered":      func() (int, error) { return codec.Decode("calculations?user_id=42", tampered) },
+		"other key":     func() (int, error) { return randomKey.Decode("calculations?user_id=42", cursor) },
+		"not base64":    func() (int, error) { return codec.Decode("calculations?user_id=42", "not a cursor!") },
+		"truncated":     func() (int, error) { return codec.Decode("calculations?user_id=42", cursor[:10]) },
+		"empty":         func() (int, error) { return codec.Decode("calculations?user_id=42", "") },
+		"other version": func() (int, error) { return codec.Decode("calculations?user_id=42", "B"+cursor[1:]) },
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := decode()
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+
+	t.Run("rejects expired cursors", func(t *testing.T) {
+		codec.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		defer func() { codec.now = time.Now }()
+		_, err := codec.Decode("calculations?user_id=42", cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestNewCodec_InvalidKey(t *testing.T) {
+	_, err := NewCodec([]byte(strings.Repeat("k", 20)), 0)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
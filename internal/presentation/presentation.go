@@ -0,0 +1,216 @@
+// Package presentation lays out calculated pack results for display, after
+// the calculation and its result processor hooks, without changing the packs
+// calculated. Deployments configure rules in a YAML file, with a default rule
+// set and optional rule sets for tenants:
+//
+//	default:
+//	  order: largest_first
+//	tenants:
+//	  6371cb7a:
+//	    order: largest_first
+//	    merge_below_items: 100
+//	    pallet_items: 2000
+//	    pallet_rounding: up
+//
+// A tenant is an API key, identified by its fingerprint; a tenant's rule set
+// replaces the default one as a whole.
+package presentation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// Pack line orders.
+const (
+	OrderLargestFirst  = "largest_first"
+	OrderSmallestFirst = "smallest_first"
+)
+
+// Pallet roundings. Without one, pallets are rounded to two decimals.
+const (
+	RoundUp      = "up"
+	RoundDown    = "down"
+	RoundNearest = "nearest"
+)
+
+// ErrInvalidRules is returned for rules that cannot be applied.
+var ErrInvalidRules = errors.New("invalid presentation rules")
+
+// Rules is a rule set. The zero value presents nothing.
+type Rules struct {
+	// Order sorts the pack lines by size: OrderLargestFirst or
+	// OrderSmallestFirst. Empty keeps the calculator's order.
+	Order string `yaml:"order"`
+	// MergeBelowItems merges the pack lines shipping fewer items into one
+	// line, listed last, when there are at least two of them. Zero merges
+	// nothing.
+	MergeBelowItems int `yaml:"merge_below_items"`
+	// PalletItems is the number of items a pallet holds, to annotate the
+	// pallets the shipment fills. Zero leaves pallets out.
+	PalletItems int `yaml:"pallet_items"`
+	// PalletRounding rounds pallets to a whole number: RoundUp, RoundDown
+	// or RoundNearest.
+	PalletRounding string `yaml:"pallet_rounding"`
+}
+
+// Config holds the rule sets of a deployment.
+type Config struct {
+	// Default applies to requests without a tenant or whose tenant has no
+	// rule set.
+	Default Rules `yaml:"default"`
+	// Tenants holds the rule sets of tenants, by API key fingerprint.
+	Tenants map[string]Rules `yaml:"tenants"`
+}
+
+// LoadFile reads a Config from a YAML file. Unknown keys are rejected so
+// typos surface instead of being silently ignored.
+func LoadFile(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("read presentation rules: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%w: %s: %v", ErrInvalidRules, path, err)
+	}
+	return cfg, nil
+}
+
+// Engine applies the rule sets of a Config. A nil Engine presents nothing.
+type Engine struct {
+	defaults Rules
+	tenants  map[string]Rules
+}
+
+// NewEngine validates cfg and creates an Engine applying it.
+func NewEngine(cfg Config) (*Engine, error) {
+	if err := cfg.Default.validate(); err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+	for tenant, rules := range cfg.Tenants {
+		if tenant == "" {
+			return nil, fmt.Errorf("%w: empty tenant", ErrInvalidRules)
+		}
+		if err := rules.validate(); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	return &Engine{defaults: cfg.Default, tenants: cfg.Tenants}, nil
+}
+
+// validate checks the rules can be applied.
+func (r Rules) validate() error {
+	switch r.Order {
+	case "", OrderLargestFirst, OrderSmallestFirst:
+	default:
+		return fmt.Errorf("%w: unknown order %q", ErrInvalidRules, r.Order)
+	}
+	switch r.PalletRounding {
+	case "", RoundUp, RoundDown, RoundNearest:
+	default:
+		return fmt.Errorf("%w: unknown pallet rounding %q", ErrInvalidRules, r.PalletRounding)
+	}
+	if r.MergeBelowItems < 0 || r.PalletItems < 0 {
+		return fmt.Errorf("%w: merge_below_items and pallet_items must not be negative", ErrInvalidRules)
+	}
+	return nil
+}
+
+// Rules returns the rule set applying to tenant.
+func (e *Engine) Rules(tenant string) Rules {
+	if e == nil {
+		return Rules{}
+	}
+	if rules, ok := e.tenants[tenant]; ok && tenant != "" {
+		return rules
+	}
+	return e.defaults
+}
+
+// Present returns result laid out by the rules of tenant, or nil when they
+// are the zero Rules. result is not modified.
+func (e *Engine) Present(tenant string, result model.PackResult) *model.Presentation {
+	rules := e.Rules(tenant)
+	if rules == (Rules{}) {
+		return nil
+	}
+
+	// Merging a single line would only hide its size
+	mergeBelow := rules.MergeBelowItems
+	small := 0
+	for _, pack := range result.Packs {
+		if pack.TotalItems() < mergeBelow {
+			small++
+		}
+	}
+	if small < 2 {
+		mergeBelow = 0
+	}
+
+	lines := make([]model.PresentationLine, 0, len(result.Packs))
+	var merged []model.Pack
+	for _, pack := range result.Packs {
+		if pack.TotalItems() < mergeBelow {
+			merged = append(merged, pack)
+			continue
+		}
+		lines = append(lines, model.PresentationLine{Size: pack.Size, Quantity: pack.Quantity, Items: pack.TotalItems()})
+	}
+	sortLines(lines, merged, rules.Order)
+
+	if len(merged) > 0 {
+		line := model.PresentationLine{Merged: merged}
+		for _, pack := range merged {
+			line.Quantity += pack.Quantity
+			line.Items += pack.TotalItems()
+		}
+		lines = append(lines, line)
+	}
+
+	presentation := &model.Presentation{Lines: lines}
+	if rules.PalletItems > 0 {
+		pallets := roundPallets(float64(result.TotalItems)/float64(rules.PalletItems), rules.PalletRounding)
+		presentation.Pallets = &pallets
+	}
+	return presentation
+}
+
+// sortLines sorts lines, and the packs merged, by size in order.
+func sortLines(lines []model.PresentationLine, merged []model.Pack, order string) {
+	var sign int
+	switch order {
+	case OrderLargestFirst:
+		sign = -1
+	case OrderSmallestFirst:
+		sign = 1
+	default:
+		return
+	}
+	slices.SortStableFunc(lines, func(a, b model.PresentationLine) int { return sign * (a.Size - b.Size) })
+	slices.SortStableFunc(merged, func(a, b model.Pack) int { return sign * (a.Size - b.Size) })
+}
+
+// roundPallets rounds pallets as rounding says.
+func roundPallets(pallets float64, rounding string) float64 {
+	switch rounding {
+	case RoundUp:
+		return math.Ceil(pallets)
+	case RoundDown:
+		return math.Floor(pallets)
+	case RoundNearest:
+		return math.Round(pallets)
+	default:
+		return math.Round(pallets*100) / 100
+	}
+}
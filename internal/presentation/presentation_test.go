@@ -0,0 +1,146 @@
+//go:build !integration
+
+package presentation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestEngine_Present(t *testing.T) {
+	result := model.PackResult{
+		OrderedItems: 2600,
+		TotalItems:   2750,
+		Packs: []model.Pack{
+			{Size: 250, Quantity: 1},
+			{Size: 2000, Quantity: 1},
+			{Size: 500, Quantity: 1},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		rules    Rules
+		expected *model.Presentation
+	}{
+		{
+			name:     "zero rules present nothing",
+			expected: nil,
+		},
+		{
+			name:  "largest size first",
+			rules: Rules{Order: OrderLargestFirst},
+			expected: &model.Presentation{Lines: []model.PresentationLine{
+				{Size: 2000, Quantity: 1, Items: 2000},
+				{Size: 500, Quantity: 1, Items: 500},
+				{Size: 250, Quantity: 1, Items: 250},
+			}},
+		},
+		{
+			name:  "lines below the threshold are merged last",
+			rules: Rules{Order: OrderSmallestFirst, MergeBelowItems: 1000},
+			expected: &model.Presentation{Lines: []model.PresentationLine{
+				{Size: 2000, Quantity: 1, Items: 2000},
+				{Quantity: 2, Items: 750, Merged: []model.Pack{{Size: 250, Quantity: 1}, {Size: 500, Quantity: 1}}},
+			}},
+		},
+		{
+			name:  "a single small line is not merged",
+			rules: Rules{MergeBelowItems: 500},
+			expected: &model.Presentation{Lines: []model.PresentationLine{
+				{Size: 250, Quantity: 1, Items: 250},
+				{Size: 2000, Quantity: 1, Items: 2000},
+				{Size: 500, Quantity: 1, Items: 500},
+			}},
+		},
+		{
+			name:  "pallets to two decimals",
+			rules: Rules{PalletItems: 2000},
+			expected: &model.Presentation{Lines: []model.PresentationLine{
+				{Size: 250, Quantity: 1, Items: 250},
+				{Size: 2000, Quantity: 1, Items: 2000},
+				{Size: 500, Quantity: 1, Items: 500},
+			}, Pallets: ptr(1.38)},
+		},
+		{
+			name:  "pallets rounded up",
+			rules: Rules{Order: OrderLargestFirst, MergeBelowItems: 2500, PalletItems: 2000, PalletRounding: RoundUp},
+			expected: &model.Presentation{Lines: []model.PresentationLine{
+				{Quantity: 3, Items: 2750, Merged: []model.Pack{{Size: 2000, Quantity: 1}, {Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}}},
+			}, Pallets: ptr(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine(Config{Default: tt.rules})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, engine.Present("", result))
+			assert.Equal(t, []model.Pack{{Size: 250, Quantity: 1}, {Size: 2000, Quantity: 1}, {Size: 500, Quantity: 1}}, result.Packs, "the result is unchanged")
+		})
+	}
+}
+
+func TestEngine_Rules(t *testing.T) {
+	acme := Rules{PalletItems: 1000, PalletRounding: RoundDown}
+	engine, err := NewEngine(Config{Default: Rules{Order: OrderLargestFirst}, Tenants: map[string]Rules{"acme": acme}})
+	require.NoError(t, err)
+
+	assert.Equal(t, acme, engine.Rules("acme"), "a tenant's rules replace the default ones")
+	assert.Equal(t, Rules{Order: OrderLargestFirst}, engine.Rules("other"))
+	assert.Equal(t, Rules{Order: OrderLargestFirst}, engine.Rules(""))
+
+	var none *Engine
+	assert.Nil(t, none.Present("acme", model.PackResult{Packs: []model.Pack{{Size: 250, Quantity: 1}}}))
+}
+
+func TestNewEngine_Invalid(t *testing.T) {
+	for _, cfg := range []Config{
+		{Default: Rules{Order: "alphabetical"}},
+		{Default: Rules{PalletRounding: "sideways"}},
+		{Default: Rules{MergeBelowItems: -1}},
+		{Tenants: map[string]Rules{"acme": {PalletItems: -1}}},
+		{Tenants: map[string]Rules{"": {}}},
+	} {
+		_, err := NewEngine(cfg)
+		assert.ErrorIs(t, err, ErrInvalidRules, "%+v", cfg)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+default:
+  order: largest_first
+tenants:
+  acme:
+    merge_below_items: 100
+    pallet_items: 2000
+    pallet_rounding: up
+`), 0o600))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Default: Rules{Order: OrderLargestFirst},
+		Tenants: map[string]Rules{"acme": {MergeBelowItems: 100, PalletItems: 2000, PalletRounding: RoundUp}},
+	}, cfg)
+
+	require.NoError(t, os.WriteFile(path, []byte("default:\n  pallet_size: 10\n"), 0o600))
+	_, err = LoadFile(path)
+	assert.ErrorIs(t, err, ErrInvalidRules, "unknown keys are rejected")
+
+	_, err = LoadFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// Package repository provides calculation job data access layer.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CalculationJobRepositoryInterface defines the interface for calculation job repository operations.
+type CalculationJobRepositoryInterface interface {
+	// Create stores a queued job.
+	Create(ctx context.Context, job *model.CalculationJob) error
+	// Get returns the job with id, or nil when there is none.
+	Get(ctx context.Context, id string) (*model.CalculationJob, error)
+	// ClaimNext marks the oldest queued job, or a running job whose lease
+	// has ended, as running for lease and returns it. Returns nil when no
+	// job is waiting.
+	ClaimNext(ctx context.Context, lease time.Duration) (*model.CalculationJob, error)
+	// Complete stores the results of a running job.
+	Complete(ctx context.Context, id string, results []byte, succeeded, failed int, expiresAt time.Time) error
+	// Fail marks a running job as failed for reason.
+	Fail(ctx context.Context, id string, reason string, expiresAt time.Time) error
+	// Release puts a running job back in the queue without counting the
+	// interrupted run as an attempt.
+	Release(ctx context.Context, id string) error
+	// CountUnfinished returns the number of queued and running jobs.
+	CountUnfinished(ctx context.Context) (int64, error)
+}
+
+// CalculationJobRepository implements CalculationJobRepositoryInterface using MongoDB.
+type CalculationJobRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCalculationJobRepository creates a new calculation job repository.
+func NewCalculationJobRepository(db *mongo.Database) *CalculationJobRepository {
+	return &CalculationJobRepository{
+		collection: db.Collection("calculation_jobs"),
+	}
+}
+
+// Create inserts job.
+func (r *CalculationJobRepository) Create(ctx context.Context, job *model.CalculationJob) error {
+	_, err := r.collection.InsertOne(ctx, job)
+	return err
+}
+
+// Get finds a job by ID.
+func (r *CalculationJobRepository) Get(ctx context.Context, id string) (*model.CalculationJob, error) {
+	var job model.CalculationJob
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext claims a job in a single update, so two workers never claim
+// the same job.
+func (r *CalculationJobRepository) ClaimNext(ctx context.Context, lease time.Duration) (*model.CalculationJob, error) {
+	now := timeutil.Now()
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": model.JobStatusQueued},
+		bson.M{"status": model.JobStatusRunning, "lease_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": model.JobStatusRunning, "started_at": now, "lease_until": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job model.CalculationJob
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Complete stores a running job's results.
+func (r *CalculationJobRepository) Complete(ctx context.Context, id string, results []byte, succeeded, failed int, expiresAt time.Time) error {
+	return r.finish(ctx, id, bson.M{
+		"status":    model.JobStatusCompleted,
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    failed,
+	}, expiresAt)
+}
+
+// Fail marks a running job as failed.
+func (r *CalculationJobRepository) Fail(ctx context.Context, id string, reason string, expiresAt time.Time) error {
+	return r.finish(ctx, id, bson.M{
+		"status": model.JobStatusFailed,
+		"error":  reason,
+	}, expiresAt)
+}
+
+func (r *CalculationJobRepository) finish(ctx context.Context, id string, set bson.M, expiresAt time.Time) error {
+	set["completed_at"] = timeutil.Now()
+	set["expires_at"] = expiresAt
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.JobStatusRunning},
+		bson.M{"$set": set, "$unset": bson.M{"lease_until": ""}},
+	)
+	return err
+}
+
+// Release re-queues a running job.
+func (r *CalculationJobRepository) Release(ctx context.Context, id string) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.JobStatusRunning},
+		bson.M{
+			"$set":   bson.M{"status": model.JobStatusQueued},
+			"$inc":   bson.M{"attempts": -1},
+			"$unset": bson.M{"started_at": "", "lease_until": ""},
+		},
+	)
+	return err
+}
+
+// CountUnfinished counts queued and running jobs.
+func (r *CalculationJobRepository) CountUnfinished(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"status": bson.M{"$in": bson.A{model.JobStatusQueued, model.JobStatusRunning}},
+	})
+}
@@ -0,0 +1,85 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestCalculationJobRepository(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewCalculationJobRepository(db.Database)
+
+	first := &model.CalculationJob{ID: uuid.NewString(), Owner: "user-1", Status: model.JobStatusQueued, Items: 2, Request: []byte(`[{},{}]`), CreatedAt: time.Now().Add(-time.Minute)}
+	second := &model.CalculationJob{ID: uuid.NewString(), Status: model.JobStatusQueued, Items: 1, Request: []byte(`[{}]`), CreatedAt: time.Now()}
+	require.NoError(t, repo.Create(ctx, first))
+	require.NoError(t, repo.Create(ctx, second))
+
+	unfinished, err := repo.CountUnfinished(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), unfinished)
+
+	// Oldest first, and a claimed job is not claimed again while leased
+	claimed, err := repo.ClaimNext(ctx, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, first.ID, claimed.ID)
+	assert.Equal(t, model.JobStatusRunning, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempts)
+
+	claimed, err = repo.ClaimNext(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, claimed.ID)
+	claimed, err = repo.ClaimNext(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// A released job is queued again without losing an attempt
+	require.NoError(t, repo.Release(ctx, second.ID))
+	claimed, err = repo.ClaimNext(ctx, -time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, claimed.ID)
+	assert.Equal(t, 1, claimed.Attempts)
+
+	// An expired lease lets another worker take over
+	claimed, err = repo.ClaimNext(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, claimed.ID)
+	assert.Equal(t, 2, claimed.Attempts)
+
+	expiresAt := time.Now().Add(time.Hour)
+	require.NoError(t, repo.Complete(ctx, first.ID, []byte(`[{"index":0},{"index":1}]`), 1, 1, expiresAt))
+	require.NoError(t, repo.Fail(ctx, second.ID, "job did not finish", expiresAt))
+
+	job, err := repo.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusCompleted, job.Status)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.JSONEq(t, `[{"index":0},{"index":1}]`, string(job.Results))
+	require.NotNil(t, job.ExpiresAt)
+	assert.Nil(t, job.LeaseUntil)
+
+	job, err = repo.Get(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusFailed, job.Status)
+	assert.Equal(t, "job did not finish", job.Error)
+
+	unfinished, err = repo.CountUnfinished(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, unfinished)
+}
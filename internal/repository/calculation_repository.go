@@ -0,0 +1,281 @@
+// Package repository provides calculation history data access layer.
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CalculationRepositoryInterface defines the interface for calculation history repository operations.
+type CalculationRepositoryInterface interface {
+	CreateMany(ctx context.Context, calculations []*model.Calculation) error
+	List(ctx context.Context, query CalculationQuery) ([]*model.Calculation, error)
+	Count(ctx context.Context, query CalculationQuery) (int64, error)
+	RollUp(ctx context.Context, from, to, asOf time.Time) error
+	ListRollups(ctx context.Context, query RollupQuery) ([]*model.CalculationRollup, error)
+	TopQuantities(ctx context.Context, since time.Time, limit int) ([]int, error)
+}
+
+// CalculationQuery filters the calculation history. Zero fields match every
+// calculation; Limit and Skip only apply to List.
+type CalculationQuery struct {
+	UserID   string
+	From     *time.Time
+	To       *time.Time
+	MinItems *int
+	MaxItems *int
+	// PackSet keeps calculations made with this pack set, in the form of
+	// model.CalculationRollup.PackSet.
+	PackSet string
+	Limit   int
+	Skip    int
+}
+
+// filter builds the MongoDB filter for the query.
+func (q CalculationQuery) filter() bson.M {
+	filter := bson.M{}
+	if q.UserID != "" {
+		filter["user_id"] = q.UserID
+	}
+	if q.From != nil || q.To != nil {
+		createdAt := bson.M{}
+		if q.From != nil {
+			createdAt["$gte"] = *q.From
+		}
+		if q.To != nil {
+			createdAt["$lte"] = *q.To
+		}
+		filter["created_at"] = createdAt
+	}
+	if q.MinItems != nil || q.MaxItems != nil {
+		items := bson.M{}
+		if q.MinItems != nil {
+			items["$gte"] = *q.MinItems
+		}
+		if q.MaxItems != nil {
+			items["$lte"] = *q.MaxItems
+		}
+		filter["items_ordered"] = items
+	}
+	if q.PackSet != "" {
+		filter["pack_sizes"] = packSetFilter(q.PackSet)
+	}
+	return filter
+}
+
+// packSetFilter matches the pack_sizes of calculations made with packSet,
+// whatever the order the sizes were stored in.
+func packSetFilter(packSet string) bson.M {
+	if packSet == model.PackSetDefault {
+		return bson.M{"$exists": false}
+	}
+	sizes := bson.A{}
+	for _, size := range strings.Split(packSet, ",") {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			// Stored pack sets never contain a non-number, so match nothing
+			return bson.M{"$in": bson.A{}}
+		}
+		sizes = append(sizes, n)
+	}
+	return bson.M{"$all": sizes, "$size": len(sizes)}
+}
+
+// RollupQuery filters calculation rollups. From and To bound the days, both
+// inclusive; empty fields match every rollup.
+type RollupQuery struct {
+	From    time.Time
+	To      time.Time
+	UserID  string
+	PackSet string
+}
+
+// CalculationRepository implements CalculationRepositoryInterface using MongoDB.
+type CalculationRepository struct {
+	collection *mongo.Collection
+	rollups    *mongo.Collection
+}
+
+// NewCalculationRepository creates a new calculation history repository.
+func NewCalculationRepository(db *mongo.Database) *CalculationRepository {
+	return &CalculationRepository{
+		collection: db.Collection("calculations"),
+		rollups:    db.Collection("calculation_rollups"),
+	}
+}
+
+// CreateMany stores calculations. Inserts are unordered, so one bad document
+// does not stop the rest.
+func (r *CalculationRepository) CreateMany(ctx context.Context, calculations []*model.Calculation) error {
+	if len(calculations) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(calculations))
+	for i, calc := range calculations {
+		docs[i] = calc
+	}
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false), insertManyComment(ctx))
+	return err
+}
+
+// List returns calculations matching query, newest first.
+func (r *CalculationRepository) List(ctx context.Context, query CalculationQuery) ([]*model.Calculation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+	if query.Skip > 0 {
+		opts.SetSkip(int64(query.Skip))
+	}
+
+	cursor, err := r.collection.Find(ctx, query.filter(), opts, findComment(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	calculations := make([]*model.Calculation, 0)
+	if err := cursor.All(ctx, &calculations); err != nil {
+		return nil, err
+	}
+	return calculations, nil
+}
+
+// Count returns the number of calculations matching query.
+func (r *CalculationRepository) Count(ctx context.Context, query CalculationQuery) (int64, error) {
+	return r.collection.CountDocuments(ctx, query.filter(), countComment(ctx))
+}
+
+// TopQuantities returns up to limit of the order quantities calculated most
+// often since since, most frequent first.
+func (r *CalculationRepository) TopQuantities(ctx context.Context, since time.Time, limit int) ([]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$items_ordered", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline, aggregateComment(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	var groups []struct {
+		Quantity int `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	quantities := make([]int, len(groups))
+	for i, group := range groups {
+		quantities[i] = group.Quantity
+	}
+	return quantities, nil
+}
+
+// RollUp recomputes the daily rollups of the calculations made in [from, to)
+// inside MongoDB and merges them into the calculation_rollups collection,
+// stamped with asOf. Rollups of those days left with an older stamp no longer
+// have calculations and are removed. Recomputing a day is idempotent, so
+// several instances may roll up the same days.
+func (r *CalculationRepository) RollUp(ctx context.Context, from, to, asOf time.Time) error {
+	packSizes := bson.M{"$sortArray": bson.M{"input": "$pack_sizes", "sortBy": 1}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$set", Value: bson.M{
+			"day": bson.M{"$dateTrunc": bson.M{"date": "$created_at", "unit": "day", "timezone": "UTC"}},
+			"pack_set": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$pack_sizes", bson.A{}}}}, 0}},
+				bson.M{"$reduce": bson.M{
+					"input":        packSizes,
+					"initialValue": "",
+					"in": bson.M{"$concat": bson.A{
+						"$$value",
+						bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$value", ""}}, "", ","}},
+						bson.M{"$toString": "$$this"},
+					}},
+				}},
+				model.PackSetDefault,
+			}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.D{
+				{Key: "day", Value: "$day"},
+				{Key: "user_id", Value: bson.M{"$ifNull": bson.A{"$user_id", ""}}},
+				{Key: "pack_set", Value: "$pack_set"},
+			},
+			"calculations":      bson.M{"$sum": 1},
+			"items_ordered":     bson.M{"$sum": "$items_ordered"},
+			"total_items":       bson.M{"$sum": "$total_items"},
+			"packs":             bson.M{"$sum": bson.M{"$sum": "$packs.quantity"}},
+			"approximate":       bson.M{"$sum": bson.M{"$cond": bson.A{"$approximate", 1, 0}}},
+			"min_items_ordered": bson.M{"$min": "$items_ordered"},
+			"max_items_ordered": bson.M{"$max": "$items_ordered"},
+			"latency_us":        bson.M{"$sum": "$latency_us"},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"day":        "$_id.day",
+			"user_id":    "$_id.user_id",
+			"pack_set":   "$_id.pack_set",
+			"updated_at": asOf,
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           r.rollups.Name(),
+			"on":             "_id",
+			"whenMatched":    "replace",
+			"whenNotMatched": "insert",
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline, aggregateComment(ctx))
+	if err != nil {
+		return err
+	}
+	_ = cursor.Close(ctx)
+
+	_, err = r.rollups.DeleteMany(ctx, bson.M{
+		"day":        bson.M{"$gte": from, "$lt": to},
+		"updated_at": bson.M{"$lt": asOf},
+	})
+	return err
+}
+
+// ListRollups returns the rollups matching query, by day, user and pack set.
+func (r *CalculationRepository) ListRollups(ctx context.Context, query RollupQuery) ([]*model.CalculationRollup, error) {
+	filter := bson.M{"day": bson.M{"$gte": query.From, "$lte": query.To}}
+	if query.UserID != "" {
+		filter["user_id"] = query.UserID
+	}
+	if query.PackSet != "" {
+		filter["pack_set"] = query.PackSet
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "user_id", Value: 1}, {Key: "pack_set", Value: 1}})
+	cursor, err := r.rollups.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	rollups := make([]*model.CalculationRollup, 0)
+	if err := cursor.All(ctx, &rollups); err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
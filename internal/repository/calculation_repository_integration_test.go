@@ -0,0 +1,156 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCalculationRepository_CreateAndList(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewCalculationRepository(db.Database)
+	t0 := time.Now().UTC().Truncate(time.Millisecond)
+	maxPacks := 2
+
+	require.NoError(t, repo.CreateMany(ctx, []*model.Calculation{
+		{CreatedAt: t0, UserID: "u1", Source: model.CalculationSourceHTTP, ItemsOrdered: 251, TotalItems: 500, Packs: []model.Pack{{Size: 500, Quantity: 1}}},
+		{CreatedAt: t0.Add(time.Second), UserID: "u2", Source: model.CalculationSourceBatch, ItemsOrdered: 12001, PackSizes: []int{250, 500}, TotalItems: 12250},
+		{CreatedAt: t0.Add(2 * time.Second), UserID: "u1", Source: model.CalculationSourceGRPC, ItemsOrdered: 1, TotalItems: 250, Constraints: &model.PackConstraints{MaxPacks: &maxPacks}},
+	}))
+	require.NoError(t, repo.CreateMany(ctx, nil))
+
+	all, err := repo.List(ctx, CalculationQuery{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, 1, all[0].ItemsOrdered, "newest first")
+	require.NotNil(t, all[0].Constraints)
+	assert.Equal(t, 2, *all[0].Constraints.MaxPacks)
+	assert.Equal(t, []model.Pack{{Size: 500, Quantity: 1}}, all[2].Packs)
+
+	minItems := 100
+	from := t0.Add(-time.Minute)
+	query := CalculationQuery{UserID: "u1", From: &from, MinItems: &minItems}
+	filtered, err := repo.List(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, 251, filtered[0].ItemsOrdered)
+
+	total, err := repo.Count(ctx, CalculationQuery{UserID: "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	page, err := repo.List(ctx, CalculationQuery{Limit: 1, Skip: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 12001, page[0].ItemsOrdered)
+}
+
+func TestCalculationRepository_RollUp(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewCalculationRepository(db.Database)
+	day := time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+
+	require.NoError(t, repo.CreateMany(ctx, []*model.Calculation{
+		{CreatedAt: day.Add(time.Hour), UserID: "u1", ItemsOrdered: 251, TotalItems: 500, Packs: []model.Pack{{Size: 500, Quantity: 1}}, LatencyMicros: 10},
+		{CreatedAt: day.Add(2 * time.Hour), UserID: "u1", ItemsOrdered: 12001, TotalItems: 12250, Packs: []model.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}}, LatencyMicros: 30},
+		{CreatedAt: day.Add(3 * time.Hour), UserID: "u1", ItemsOrdered: 100, PackSizes: []int{53, 23, 31}, TotalItems: 100, Approximate: true},
+		{CreatedAt: day.Add(4 * time.Hour), ItemsOrdered: 1, PackSizes: []int{23, 31, 53}, TotalItems: 23},
+		{CreatedAt: nextDay.Add(time.Hour), UserID: "u1", ItemsOrdered: 1, TotalItems: 250},
+	}))
+
+	asOf := time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repo.RollUp(ctx, day, nextDay, asOf))
+
+	rollups, err := repo.ListRollups(ctx, RollupQuery{From: day, To: nextDay})
+	require.NoError(t, err)
+	require.Len(t, rollups, 3, "the next day is not rolled up")
+
+	anonymous := rollups[0]
+	assert.Equal(t, "", anonymous.UserID)
+	assert.Equal(t, "23,31,53", anonymous.PackSet)
+
+	custom := rollups[1]
+	assert.Equal(t, "u1", custom.UserID)
+	assert.Equal(t, "23,31,53", custom.PackSet, "sizes are sorted")
+	assert.Equal(t, int64(1), custom.Approximate)
+
+	defaults := rollups[2]
+	assert.Equal(t, day, defaults.Day)
+	assert.Equal(t, "u1", defaults.UserID)
+	assert.Equal(t, model.PackSetDefault, defaults.PackSet)
+	assert.Equal(t, int64(2), defaults.Calculations)
+	assert.Equal(t, int64(12252), defaults.ItemsOrdered)
+	assert.Equal(t, int64(12750), defaults.TotalItems)
+	assert.Equal(t, int64(5), defaults.Packs)
+	assert.Equal(t, 251, defaults.MinItemsOrdered)
+	assert.Equal(t, 12001, defaults.MaxItemsOrdered)
+	assert.Equal(t, int64(40), defaults.LatencyMicros)
+	assert.Equal(t, asOf, defaults.UpdatedAt)
+
+	// The drill-down finds the calculations behind a pack set in any order
+	sized, err := repo.List(ctx, CalculationQuery{PackSet: "23,31,53"})
+	require.NoError(t, err)
+	assert.Len(t, sized, 2)
+	unsized, err := repo.Count(ctx, CalculationQuery{PackSet: model.PackSetDefault})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), unsized)
+
+	// Recomputing replaces the day's rollups and drops emptied ones
+	_, err = db.Calculations.DeleteMany(ctx, bson.M{"pack_sizes": bson.M{"$exists": true}})
+	require.NoError(t, err)
+	require.NoError(t, repo.RollUp(ctx, day, nextDay, asOf.Add(time.Hour)))
+
+	rollups, err = repo.ListRollups(ctx, RollupQuery{From: day, To: day, UserID: "u1"})
+	require.NoError(t, err)
+	require.Len(t, rollups, 1)
+	assert.Equal(t, int64(2), rollups[0].Calculations)
+}
+
+func TestCalculationRepository_TopQuantities(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewCalculationRepository(db.Database)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var calculations []*model.Calculation
+	for items, count := range map[int]int{251: 3, 12001: 2, 500: 2, 1: 1} {
+		for range count {
+			calculations = append(calculations, &model.Calculation{CreatedAt: now, ItemsOrdered: items})
+		}
+	}
+	for range 4 {
+		calculations = append(calculations, &model.Calculation{CreatedAt: now.Add(-48 * time.Hour), ItemsOrdered: 7})
+	}
+	require.NoError(t, repo.CreateMany(ctx, calculations))
+
+	top, err := repo.TopQuantities(ctx, now.Add(-time.Hour), 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int{251, 500, 12001}, top, "most frequent first, ties by quantity, old calculations left out")
+}
@@ -3,9 +3,15 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // PackSizesRepositoryWithCircuitBreaker wraps PackSizesRepository with circuit breaker protection.
@@ -38,11 +44,23 @@ func (r *PackSizesRepositoryWithCircuitBreaker) GetActive(ctx context.Context) (
 }
 
 // Create creates a new pack size configuration with circuit breaker protection.
-func (r *PackSizesRepositoryWithCircuitBreaker) Create(ctx context.Context, sizes []int, createdBy string) (*PackSizeConfig, error) {
+func (r *PackSizesRepositoryWithCircuitBreaker) Create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string) (*PackSizeConfig, error) {
 	var result *PackSizeConfig
 	err := r.circuitBreaker.Execute(ctx, func() error {
 		var cbErr error
-		result, cbErr = r.repo.Create(ctx, sizes, createdBy)
+		result, cbErr = r.repo.Create(ctx, sizes, costs, createdBy)
+		return cbErr
+	})
+	return result, err
+}
+
+// CreateIfActive creates a new pack size configuration while activeVersion is
+// active, with circuit breaker protection.
+func (r *PackSizesRepositoryWithCircuitBreaker) CreateIfActive(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int) (*PackSizeConfig, error) {
+	var result *PackSizeConfig
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.CreateIfActive(ctx, sizes, costs, createdBy, activeVersion)
 		return cbErr
 	})
 	return result, err
@@ -70,6 +88,34 @@ func (r *PackSizesRepositoryWithCircuitBreaker) List(ctx context.Context, limit
 	return result, err
 }
 
+// Activate activates a pack size configuration with circuit breaker protection.
+func (r *PackSizesRepositoryWithCircuitBreaker) Activate(ctx context.Context, id primitive.ObjectID, activatedBy string) (*PackSizeConfig, error) {
+	var result *PackSizeConfig
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.Activate(ctx, id, activatedBy)
+		return cbErr
+	})
+	return result, err
+}
+
+// PreviousVersion returns the configuration preceding a version with circuit breaker protection.
+func (r *PackSizesRepositoryWithCircuitBreaker) PreviousVersion(ctx context.Context, version int) (*PackSizeConfig, error) {
+	var result *PackSizeConfig
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.PreviousVersion(ctx, version)
+		return cbErr
+	})
+	return result, err
+}
+
+// WatchPackSizes watches the underlying repository. A change stream is one
+// long-lived call that reopens on its own, so it bypasses the circuit breaker.
+func (r *PackSizesRepositoryWithCircuitBreaker) WatchPackSizes(ctx context.Context, fn func()) error {
+	return r.repo.WatchPackSizes(ctx, fn)
+}
+
 // GetCircuitBreaker returns the underlying circuit breaker for monitoring.
 func (r *PackSizesRepositoryWithCircuitBreaker) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
 	return r.circuitBreaker
@@ -137,7 +183,666 @@ func (r *LogsRepositoryWithCircuitBreaker) Count(ctx context.Context, opts LogQu
 	return result, err
 }
 
+// Each iterates over log entries with circuit breaker protection. Errors
+// returned by fn stop the iteration but are not counted as failures, since
+// they come from the caller rather than the database.
+func (r *LogsRepositoryWithCircuitBreaker) Each(ctx context.Context, opts LogQueryOptions, fn func(*LogEntryDocument) error) error {
+	var fnErr error
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		err := r.repo.Each(ctx, opts, func(entry *LogEntryDocument) error {
+			fnErr = fn(entry)
+			return fnErr
+		})
+		if fnErr != nil {
+			return nil
+		}
+		return err
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// CalculationsByConfigVersion aggregates calculations per config version with circuit breaker protection.
+func (r *LogsRepositoryWithCircuitBreaker) CalculationsByConfigVersion(ctx context.Context, sinceVersion int) ([]ConfigVersionImpactDocument, error) {
+	var result []ConfigVersionImpactDocument
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.CalculationsByConfigVersion(ctx, sinceVersion)
+		return cbErr
+	})
+	return result, err
+}
+
+// DeleteLogs deletes log entries with circuit breaker protection.
+func (r *LogsRepositoryWithCircuitBreaker) DeleteLogs(ctx context.Context, opts LogPurgeOptions) (int64, error) {
+	var result int64
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.DeleteLogs(ctx, opts)
+		return cbErr
+	})
+	return result, err
+}
+
+// TrimLogs deletes the oldest log entries with circuit breaker protection.
+func (r *LogsRepositoryWithCircuitBreaker) TrimLogs(ctx context.Context, maxEntries int64) (int64, error) {
+	var result int64
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.TrimLogs(ctx, maxEntries)
+		return cbErr
+	})
+	return result, err
+}
+
 // GetCircuitBreaker returns the underlying circuit breaker for monitoring.
 func (r *LogsRepositoryWithCircuitBreaker) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
 	return r.circuitBreaker
 }
+
+// CalculationRepositoryWithCircuitBreaker wraps a calculation history
+// repository with circuit breaker protection.
+type CalculationRepositoryWithCircuitBreaker struct {
+	repo           CalculationRepositoryInterface
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+// NewCalculationRepositoryWithCircuitBreaker creates a new repository wrapper with circuit breaker.
+func NewCalculationRepositoryWithCircuitBreaker(repo CalculationRepositoryInterface, cb *circuitbreaker.CircuitBreaker) *CalculationRepositoryWithCircuitBreaker {
+	return &CalculationRepositoryWithCircuitBreaker{
+		repo:           repo,
+		circuitBreaker: cb,
+	}
+}
+
+// CreateMany stores calculations with circuit breaker protection. Unlike log
+// writes, an open circuit is reported so the caller can keep the batch for
+// the next attempt.
+func (r *CalculationRepositoryWithCircuitBreaker) CreateMany(ctx context.Context, calculations []*model.Calculation) error {
+	return r.circuitBreaker.Execute(ctx, func() error {
+		return r.repo.CreateMany(ctx, calculations)
+	})
+}
+
+// List retrieves calculations with circuit breaker protection.
+func (r *CalculationRepositoryWithCircuitBreaker) List(ctx context.Context, query CalculationQuery) ([]*model.Calculation, error) {
+	var result []*model.Calculation
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.List(ctx, query)
+		return cbErr
+	})
+	return result, err
+}
+
+// Count counts calculations with circuit breaker protection.
+func (r *CalculationRepositoryWithCircuitBreaker) Count(ctx context.Context, query CalculationQuery) (int64, error) {
+	var result int64
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.Count(ctx, query)
+		return cbErr
+	})
+	return result, err
+}
+
+// RollUp recomputes calculation rollups with circuit breaker protection.
+func (r *CalculationRepositoryWithCircuitBreaker) RollUp(ctx context.Context, from, to, asOf time.Time) error {
+	return r.circuitBreaker.Execute(ctx, func() error {
+		return r.repo.RollUp(ctx, from, to, asOf)
+	})
+}
+
+// ListRollups retrieves calculation rollups with circuit breaker protection.
+func (r *CalculationRepositoryWithCircuitBreaker) ListRollups(ctx context.Context, query RollupQuery) ([]*model.CalculationRollup, error) {
+	var result []*model.CalculationRollup
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.ListRollups(ctx, query)
+		return cbErr
+	})
+	return result, err
+}
+
+// TopQuantities retrieves the most calculated quantities with circuit breaker protection.
+func (r *CalculationRepositoryWithCircuitBreaker) TopQuantities(ctx context.Context, since time.Time, limit int) ([]int, error) {
+	var result []int
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		var cbErr error
+		result, cbErr = r.repo.TopQuantities(ctx, since, limit)
+		return cbErr
+	})
+	return result, err
+}
+
+// GetCircuitBreaker returns the underlying circuit breaker for monitoring.
+func (r *CalculationRepositoryWithCircuitBreaker) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
+	return r.circuitBreaker
+}
+
+// executeAuth runs an auth repository call with circuit breaker protection.
+// Only database failures count against the circuit: a duplicate, a rejected
+// document, a missing one or a cancelled request is an answer, and a flood
+// of bad registrations must not open it. An open circuit is reported as a
+// *circuitbreaker.OpenError, so auth endpoints can tell clients when to retry.
+func executeAuth(ctx context.Context, cb *circuitbreaker.CircuitBreaker, fn func() error) error {
+	var answer error
+	err := cb.Execute(ctx, func() error {
+		err := fn()
+		if err != nil && !isDatabaseFailure(err) {
+			answer = err
+			return nil
+		}
+		return err
+	})
+	if answer != nil {
+		return answer
+	}
+	if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		return cb.OpenError()
+	}
+	return err
+}
+
+// isDatabaseFailure reports whether err, returned by an auth repository,
+// means the database could not serve the call.
+func isDatabaseFailure(err error) bool {
+	var writeErr *UserWriteError
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, mongo.ErrNoDocuments),
+		errors.Is(err, ErrUserExists),
+		errors.Is(err, ErrTokenExists),
+		errors.As(err, &writeErr),
+		mongo.IsDuplicateKeyError(err):
+		return false
+	}
+	return true
+}
+
+// UserRepositoryWithCircuitBreaker wraps a user repository with circuit
+// breaker protection.
+type UserRepositoryWithCircuitBreaker struct {
+	repo           UserRepositoryInterface
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+// NewUserRepositoryWithCircuitBreaker creates a new repository wrapper with circuit breaker.
+func NewUserRepositoryWithCircuitBreaker(repo UserRepositoryInterface, cb *circuitbreaker.CircuitBreaker) *UserRepositoryWithCircuitBreaker {
+	return &UserRepositoryWithCircuitBreaker{
+		repo:           repo,
+		circuitBreaker: cb,
+	}
+}
+
+// Create inserts a user with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) Create(ctx context.Context, user *model.User) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Create(ctx, user)
+	})
+}
+
+// CreateMany inserts users with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) CreateMany(ctx context.Context, users []*model.User) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.CreateMany(ctx, users)
+	})
+}
+
+// FindByEmail finds a user by email with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	var result *model.User
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByEmail(ctx, email)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByEmailForAuth finds a user by email for authentication with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) FindByEmailForAuth(ctx context.Context, email string) (*model.User, error) {
+	var result *model.User
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByEmailForAuth(ctx, email)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByUsername finds a user by username with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	var result *model.User
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByUsername(ctx, username)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByID finds a user by ID with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) FindByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	var result *model.User
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByID(ctx, id)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByIDMinimal finds a user's essential fields by ID with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) FindByIDMinimal(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	var result *model.User
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByIDMinimal(ctx, id)
+		return cbErr
+	})
+	return result, err
+}
+
+// Update updates a user with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) Update(ctx context.Context, user *model.User) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Update(ctx, user)
+	})
+}
+
+// Delete deactivates a user with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Delete(ctx, id)
+	})
+}
+
+// Restore reactivates a user with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Restore(ctx, id)
+	})
+}
+
+// HardDelete purges a user with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) HardDelete(ctx context.Context, id primitive.ObjectID, deletedBefore time.Time) (bool, error) {
+	var result bool
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.HardDelete(ctx, id, deletedBefore)
+		return cbErr
+	})
+	return result, err
+}
+
+// List retrieves users with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) List(ctx context.Context, filter bson.M, limit, skip int64) ([]*model.User, error) {
+	var result []*model.User
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.List(ctx, filter, limit, skip)
+		return cbErr
+	})
+	return result, err
+}
+
+// Count counts users with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) Count(ctx context.Context, filter bson.M) (int64, error) {
+	var result int64
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.Count(ctx, filter)
+		return cbErr
+	})
+	return result, err
+}
+
+// IncrementTokenVersion increments a user's token version with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) IncrementTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error) {
+	var result int
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.IncrementTokenVersion(ctx, id)
+		return cbErr
+	})
+	return result, err
+}
+
+// GetTokenVersion returns a user's token version with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) GetTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error) {
+	var result int
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.GetTokenVersion(ctx, id)
+		return cbErr
+	})
+	return result, err
+}
+
+// RecordLogin records a login with circuit breaker protection.
+func (r *UserRepositoryWithCircuitBreaker) RecordLogin(ctx context.Context, id primitive.ObjectID, login model.LoginRecord) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.RecordLogin(ctx, id, login)
+	})
+}
+
+// WatchTokenVersions watches the underlying repository, bypassing the circuit
+// breaker like WatchPackSizes. It fails with errors.ErrUnsupported when that
+// repository cannot be watched.
+func (r *UserRepositoryWithCircuitBreaker) WatchTokenVersions(ctx context.Context, fn func(id primitive.ObjectID, version int)) error {
+	watcher, ok := r.repo.(TokenVersionWatcher)
+	if !ok {
+		return fmt.Errorf("watch token versions: %w", errors.ErrUnsupported)
+	}
+	return watcher.WatchTokenVersions(ctx, fn)
+}
+
+// GetCircuitBreaker returns the underlying circuit breaker for monitoring.
+func (r *UserRepositoryWithCircuitBreaker) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
+	return r.circuitBreaker
+}
+
+// RoleRepositoryWithCircuitBreaker wraps a role repository with circuit
+// breaker protection.
+type RoleRepositoryWithCircuitBreaker struct {
+	repo           RoleRepositoryInterface
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+// NewRoleRepositoryWithCircuitBreaker creates a new repository wrapper with circuit breaker.
+func NewRoleRepositoryWithCircuitBreaker(repo RoleRepositoryInterface, cb *circuitbreaker.CircuitBreaker) *RoleRepositoryWithCircuitBreaker {
+	return &RoleRepositoryWithCircuitBreaker{
+		repo:           repo,
+		circuitBreaker: cb,
+	}
+}
+
+// Create inserts a role with circuit breaker protection.
+func (r *RoleRepositoryWithCircuitBreaker) Create(ctx context.Context, role *model.Role) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Create(ctx, role)
+	})
+}
+
+// FindByID finds a role by ID with circuit breaker protection.
+func (r *RoleRepositoryWithCircuitBreaker) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Role, error) {
+	var result *model.Role
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByID(ctx, id)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByName finds a role by name with circuit breaker protection.
+func (r *RoleRepositoryWithCircuitBreaker) FindByName(ctx context.Context, name string) (*model.Role, error) {
+	var result *model.Role
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByName(ctx, name)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByIDs finds roles by ID with circuit breaker protection.
+func (r *RoleRepositoryWithCircuitBreaker) FindByIDs(ctx context.Context, ids []string) ([]*model.Role, error) {
+	var result []*model.Role
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByIDs(ctx, ids)
+		return cbErr
+	})
+	return result, err
+}
+
+// Update updates a role with circuit breaker protection.
+func (r *RoleRepositoryWithCircuitBreaker) Update(ctx context.Context, role *model.Role) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Update(ctx, role)
+	})
+}
+
+// Delete deletes a role with circuit breaker protection.
+func (r *RoleRepositoryWithCircuitBreaker) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Delete(ctx, id)
+	})
+}
+
+// List retrieves roles with circuit breaker protection.
+func (r *RoleRepositoryWithCircuitBreaker) List(ctx context.Context, filter bson.M, limit, skip int64) ([]*model.Role, error) {
+	var result []*model.Role
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.List(ctx, filter, limit, skip)
+		return cbErr
+	})
+	return result, err
+}
+
+// GetCircuitBreaker returns the underlying circuit breaker for monitoring.
+func (r *RoleRepositoryWithCircuitBreaker) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
+	return r.circuitBreaker
+}
+
+// PermissionRepositoryWithCircuitBreaker wraps a permission repository with
+// circuit breaker protection.
+type PermissionRepositoryWithCircuitBreaker struct {
+	repo           PermissionRepositoryInterface
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+// NewPermissionRepositoryWithCircuitBreaker creates a new repository wrapper with circuit breaker.
+func NewPermissionRepositoryWithCircuitBreaker(repo PermissionRepositoryInterface, cb *circuitbreaker.CircuitBreaker) *PermissionRepositoryWithCircuitBreaker {
+	return &PermissionRepositoryWithCircuitBreaker{
+		repo:           repo,
+		circuitBreaker: cb,
+	}
+}
+
+// Create inserts a permission with circuit breaker protection.
+func (r *PermissionRepositoryWithCircuitBreaker) Create(ctx context.Context, permission *model.Permission) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Create(ctx, permission)
+	})
+}
+
+// FindByID finds a permission by ID with circuit breaker protection.
+func (r *PermissionRepositoryWithCircuitBreaker) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Permission, error) {
+	var result *model.Permission
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByID(ctx, id)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByResourceAndAction finds a permission by resource and action with circuit breaker protection.
+func (r *PermissionRepositoryWithCircuitBreaker) FindByResourceAndAction(ctx context.Context, resource, action string) (*model.Permission, error) {
+	var result *model.Permission
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByResourceAndAction(ctx, resource, action)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByIDs finds permissions by ID with circuit breaker protection.
+func (r *PermissionRepositoryWithCircuitBreaker) FindByIDs(ctx context.Context, ids []string) ([]*model.Permission, error) {
+	var result []*model.Permission
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByIDs(ctx, ids)
+		return cbErr
+	})
+	return result, err
+}
+
+// Update updates a permission with circuit breaker protection.
+func (r *PermissionRepositoryWithCircuitBreaker) Update(ctx context.Context, permission *model.Permission) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Update(ctx, permission)
+	})
+}
+
+// Delete deletes a permission with circuit breaker protection.
+func (r *PermissionRepositoryWithCircuitBreaker) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Delete(ctx, id)
+	})
+}
+
+// List retrieves permissions with circuit breaker protection.
+func (r *PermissionRepositoryWithCircuitBreaker) List(ctx context.Context, filter bson.M, limit, skip int64) ([]*model.Permission, error) {
+	var result []*model.Permission
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.List(ctx, filter, limit, skip)
+		return cbErr
+	})
+	return result, err
+}
+
+// GetCircuitBreaker returns the underlying circuit breaker for monitoring.
+func (r *PermissionRepositoryWithCircuitBreaker) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
+	return r.circuitBreaker
+}
+
+// TokenRepositoryWithCircuitBreaker wraps a token repository with circuit
+// breaker protection.
+type TokenRepositoryWithCircuitBreaker struct {
+	repo           TokenRepositoryInterface
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+// NewTokenRepositoryWithCircuitBreaker creates a new repository wrapper with circuit breaker.
+func NewTokenRepositoryWithCircuitBreaker(repo TokenRepositoryInterface, cb *circuitbreaker.CircuitBreaker) *TokenRepositoryWithCircuitBreaker {
+	return &TokenRepositoryWithCircuitBreaker{
+		repo:           repo,
+		circuitBreaker: cb,
+	}
+}
+
+// Create stores a token with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) Create(ctx context.Context, token *model.Token) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Create(ctx, token)
+	})
+}
+
+// FindByToken finds a token by its string with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) FindByToken(ctx context.Context, tokenString string) (*model.Token, error) {
+	var result *model.Token
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByToken(ctx, tokenString)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindByUserID finds a user's tokens with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) FindByUserID(ctx context.Context, userID primitive.ObjectID, tokenType string) ([]*model.Token, error) {
+	var result []*model.Token
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindByUserID(ctx, userID, tokenType)
+		return cbErr
+	})
+	return result, err
+}
+
+// Delete deletes a token by ID with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.Delete(ctx, id)
+	})
+}
+
+// DeleteByToken deletes a token by its string with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) DeleteByToken(ctx context.Context, tokenString string) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.DeleteByToken(ctx, tokenString)
+	})
+}
+
+// DeleteByUserID deletes a user's tokens with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) DeleteByUserID(ctx context.Context, userID primitive.ObjectID, tokenType string) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.DeleteByUserID(ctx, userID, tokenType)
+	})
+}
+
+// DeleteAllByUserID deletes all of a user's tokens with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) DeleteAllByUserID(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	var result int64
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.DeleteAllByUserID(ctx, userID)
+		return cbErr
+	})
+	return result, err
+}
+
+// IsBlacklisted checks the token blacklist with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) IsBlacklisted(ctx context.Context, tokenString string) (bool, error) {
+	var result bool
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.IsBlacklisted(ctx, tokenString)
+		return cbErr
+	})
+	return result, err
+}
+
+// CleanupExpired deletes expired tokens with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) CleanupExpired(ctx context.Context) (int64, error) {
+	var result int64
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.CleanupExpired(ctx)
+		return cbErr
+	})
+	return result, err
+}
+
+// FindLegacyRefreshTokens finds plaintext refresh tokens with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) FindLegacyRefreshTokens(ctx context.Context, limit int) ([]*model.Token, error) {
+	var result []*model.Token
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.FindLegacyRefreshTokens(ctx, limit)
+		return cbErr
+	})
+	return result, err
+}
+
+// CountLegacyRefreshTokens counts plaintext refresh tokens with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) CountLegacyRefreshTokens(ctx context.Context) (int64, error) {
+	var result int64
+	err := executeAuth(ctx, r.circuitBreaker, func() error {
+		var cbErr error
+		result, cbErr = r.repo.CountLegacyRefreshTokens(ctx)
+		return cbErr
+	})
+	return result, err
+}
+
+// UpdateToken replaces a stored token string with circuit breaker protection.
+func (r *TokenRepositoryWithCircuitBreaker) UpdateToken(ctx context.Context, id primitive.ObjectID, tokenString string) error {
+	return executeAuth(ctx, r.circuitBreaker, func() error {
+		return r.repo.UpdateToken(ctx, id, tokenString)
+	})
+}
+
+// GetCircuitBreaker returns the underlying circuit breaker for monitoring.
+func (r *TokenRepositoryWithCircuitBreaker) GetCircuitBreaker() *circuitbreaker.CircuitBreaker {
+	return r.circuitBreaker
+}
@@ -28,7 +28,7 @@ func TestPackSizesRepositoryWithCircuitBreaker_Update(t *testing.T) {
 
 	// Create initial config
 	sizes := []int{100, 200, 500}
-	config, err := wrappedRepo.Create(ctx, sizes, "test-user")
+	config, err := wrappedRepo.Create(ctx, sizes, nil, "test-user")
 	require.NoError(t, err)
 	require.NotNil(t, config)
 
@@ -56,8 +56,8 @@ func TestPackSizesRepositoryWithCircuitBreaker_List(t *testing.T) {
 	wrappedRepo := NewPackSizesRepositoryWithCircuitBreaker(repo, cb)
 
 	// Create some configs
-	_, _ = wrappedRepo.Create(ctx, []int{100, 200}, "user1")
-	_, _ = wrappedRepo.Create(ctx, []int{250, 500}, "user2")
+	_, _ = wrappedRepo.Create(ctx, []int{100, 200}, nil, "user1")
+	_, _ = wrappedRepo.Create(ctx, []int{250, 500}, nil, "user2")
 
 	// List via circuit breaker wrapper
 	configs, err := wrappedRepo.List(ctx, 10)
@@ -3,9 +3,17 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // TestCircuitBreakerWrapperStructure tests basic structure and type existence.
@@ -17,3 +25,76 @@ func TestCircuitBreakerWrapperStructure(t *testing.T) {
 		assert.True(t, true)
 	})
 }
+
+// stubUserRepo answers FindByEmail and Create with err, counting the calls.
+type stubUserRepo struct {
+	UserRepositoryInterface
+	err   error
+	calls int
+}
+
+func (r *stubUserRepo) FindByEmail(context.Context, string) (*model.User, error) {
+	r.calls++
+	return nil, r.err
+}
+
+func (r *stubUserRepo) Create(context.Context, *model.User) error {
+	r.calls++
+	return r.err
+}
+
+func newAuthCircuitBreaker() *circuitbreaker.CircuitBreaker {
+	return circuitbreaker.New(circuitbreaker.Config{
+		FailureThreshold: 2,
+		SuccessThreshold: 1,
+		Timeout:          time.Minute,
+		Name:             "mongodb-auth",
+	})
+}
+
+func TestUserRepositoryWithCircuitBreaker_OpensOnDatabaseFailures(t *testing.T) {
+	ctx := context.Background()
+	repo := &stubUserRepo{err: errors.New("server selection timeout")}
+	cb := newAuthCircuitBreaker()
+	wrapped := NewUserRepositoryWithCircuitBreaker(repo, cb)
+
+	for range 2 {
+		_, err := wrapped.FindByEmail(ctx, "user@example.com")
+		assert.EqualError(t, err, "server selection timeout")
+	}
+	require.True(t, cb.IsOpen())
+
+	_, err := wrapped.FindByEmail(ctx, "user@example.com")
+	assert.ErrorIs(t, err, circuitbreaker.ErrCircuitOpen)
+	var openErr *circuitbreaker.OpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, "mongodb-auth", openErr.Name)
+	assert.Greater(t, openErr.RetryAfter, time.Duration(0))
+	assert.Equal(t, 2, repo.calls, "an open circuit must not reach the database")
+}
+
+func TestUserRepositoryWithCircuitBreaker_AnswersDoNotCount(t *testing.T) {
+	ctx := context.Background()
+	answers := []error{
+		ErrUserExists,
+		&UserWriteError{Index: 1, Err: ErrUserExists},
+		mongo.ErrNoDocuments,
+		context.Canceled,
+	}
+	for _, answer := range answers {
+		cb := newAuthCircuitBreaker()
+		wrapped := NewUserRepositoryWithCircuitBreaker(&stubUserRepo{err: answer}, cb)
+
+		for range 3 {
+			assert.ErrorIs(t, wrapped.Create(ctx, &model.User{}), answer)
+		}
+		assert.Equal(t, circuitbreaker.StateClosed, cb.State(), answer.Error())
+	}
+}
+
+func TestUserRepositoryWithCircuitBreaker_WatchTokenVersions(t *testing.T) {
+	wrapped := NewUserRepositoryWithCircuitBreaker(&stubUserRepo{}, newAuthCircuitBreaker())
+
+	err := wrapped.WatchTokenVersions(context.Background(), func(primitive.ObjectID, int) {})
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+}
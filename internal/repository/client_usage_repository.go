@@ -0,0 +1,81 @@
+// Package repository provides client usage statistics data access layer.
+package repository
+
+import (
+	"context"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ClientUsageRepositoryInterface defines the interface for client usage repository operations.
+type ClientUsageRepositoryInterface interface {
+	Increment(ctx context.Context, usages []*model.ClientUsage) error
+	List(ctx context.Context, client string) ([]*model.ClientUsage, error)
+}
+
+// ClientUsageRepository implements ClientUsageRepositoryInterface using MongoDB.
+type ClientUsageRepository struct {
+	collection *mongo.Collection
+}
+
+// NewClientUsageRepository creates a new client usage repository.
+func NewClientUsageRepository(db *mongo.Database) *ClientUsageRepository {
+	return &ClientUsageRepository{
+		collection: db.Collection("client_usage"),
+	}
+}
+
+// Increment adds each usage's request count to the stored document for its
+// client, user agent and client version, creating it if needed. FirstSeen and
+// LastSeen only ever move outwards, so flushes from several instances merge.
+func (r *ClientUsageRepository) Increment(ctx context.Context, usages []*model.ClientUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(usages))
+	for _, u := range usages {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"client":         u.Client,
+				"user_agent":     u.UserAgent,
+				"client_version": u.ClientVersion,
+			}).
+			SetUpdate(bson.M{
+				"$inc":         bson.M{"request_count": u.RequestCount},
+				"$min":         bson.M{"first_seen": u.FirstSeen},
+				"$max":         bson.M{"last_seen": u.LastSeen},
+				"$setOnInsert": bson.M{"client_name": u.ClientName},
+			}).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// List returns usage sorted by most recently seen. An empty client lists all clients.
+func (r *ClientUsageRepository) List(ctx context.Context, client string) ([]*model.ClientUsage, error) {
+	filter := bson.M{}
+	if client != "" {
+		filter["client"] = client
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "last_seen", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	usages := make([]*model.ClientUsage, 0)
+	if err := cursor.All(ctx, &usages); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
@@ -0,0 +1,50 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientUsageRepository_IncrementAndList(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewClientUsageRepository(db.Database)
+	t0 := time.Now().UTC().Truncate(time.Millisecond)
+
+	require.NoError(t, repo.Increment(ctx, []*model.ClientUsage{
+		{Client: "api_key:0a1b2c3d", UserAgent: "pack-sdk-go/1.2.0", ClientName: "pack-sdk-go", ClientVersion: "1.2.0", RequestCount: 3, FirstSeen: t0, LastSeen: t0.Add(time.Second)},
+		{Client: "user:42", UserAgent: "curl/8.5.0", ClientName: "curl", ClientVersion: "8.5.0", RequestCount: 1, FirstSeen: t0, LastSeen: t0},
+	}))
+	// A second flush, e.g. from another instance, merges into the same document
+	require.NoError(t, repo.Increment(ctx, []*model.ClientUsage{
+		{Client: "api_key:0a1b2c3d", UserAgent: "pack-sdk-go/1.2.0", ClientName: "pack-sdk-go", ClientVersion: "1.2.0", RequestCount: 2, FirstSeen: t0.Add(-time.Minute), LastSeen: t0.Add(time.Minute)},
+	}))
+
+	usages, err := repo.List(ctx, "api_key:0a1b2c3d")
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	assert.Equal(t, int64(5), usages[0].RequestCount)
+	assert.Equal(t, "pack-sdk-go", usages[0].ClientName)
+	assert.True(t, usages[0].FirstSeen.Equal(t0.Add(-time.Minute)))
+	assert.True(t, usages[0].LastSeen.Equal(t0.Add(time.Minute)))
+
+	all, err := repo.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "api_key:0a1b2c3d", all[0].Client, "most recently seen first")
+
+	require.NoError(t, repo.Increment(ctx, nil))
+}
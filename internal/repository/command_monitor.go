@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/tracing"
+)
+
+// startedCommand is what a command monitor keeps of a started command until
+// it finishes.
+type startedCommand struct {
+	collection string
+	// span is nil for commands sent outside a trace
+	span trace.Span
+}
+
+// newCommandMonitor returns a command monitor that records the latency of
+// every command sent to MongoDB, labelled with the collection it targets.
+// Commands sent with a context carrying a span, such as a request's, are
+// also traced as child spans of it.
+func newCommandMonitor() *event.CommandMonitor {
+	// Finished events do not carry the command, so the collection is kept
+	// from the started event until then
+	var commands sync.Map
+
+	finished := func(e event.CommandFinishedEvent, failure string) {
+		value, _ := commands.LoadAndDelete(e.RequestID)
+		command, _ := value.(startedCommand)
+		status := "success"
+		if failure != "" {
+			status = "error"
+		}
+		metrics.RecordMongoCommand(e.CommandName, command.collection, status, e.Duration)
+
+		if command.span != nil {
+			if failure != "" {
+				command.span.RecordError(errors.New(failure))
+				command.span.SetStatus(codes.Error, failure)
+			}
+			command.span.End()
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			command := startedCommand{collection: commandCollection(e.CommandName, e.Command)}
+			// Background work such as the job workers would otherwise start
+			// a trace per poll
+			if trace.SpanContextFromContext(ctx).IsValid() {
+				command.span = startCommandSpan(ctx, e, command.collection)
+			}
+			commands.Store(e.RequestID, command)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finished(e.CommandFinishedEvent, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			failure := e.Failure
+			if failure == "" {
+				failure = "command failed"
+			}
+			finished(e.CommandFinishedEvent, failure)
+		},
+	}
+}
+
+// startCommandSpan starts a client span for a command sent with ctx.
+func startCommandSpan(ctx context.Context, e *event.CommandStartedEvent, collection string) trace.Span {
+	name := e.CommandName
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameMongoDB,
+		semconv.DBOperationName(e.CommandName),
+		semconv.DBNamespace(e.DatabaseName),
+	}
+	if collection != "" {
+		name += " " + collection
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+	}
+	_, span := tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return span
+}
+
+// commandCollection returns the collection a command targets, or "" for
+// commands that do not target one, such as ping.
+func commandCollection(commandName string, command bson.Raw) string {
+	field := commandName
+	if commandName == "getMore" {
+		// getMore's value is the cursor ID
+		field = "collection"
+	}
+	collection, _ := command.Lookup(field).StringValueOK()
+	return collection
+}
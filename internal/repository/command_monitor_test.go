@@ -0,0 +1,92 @@
+//go:build !integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+func TestCommandCollection(t *testing.T) {
+	raw := func(doc bson.D) bson.Raw {
+		b, err := bson.Marshal(doc)
+		require.NoError(t, err)
+		return b
+	}
+
+	assert.Equal(t, "users", commandCollection("find", raw(bson.D{{Key: "find", Value: "users"}, {Key: "filter", Value: bson.D{}}})))
+	assert.Equal(t, "logs", commandCollection("getMore", raw(bson.D{{Key: "getMore", Value: int64(42)}, {Key: "collection", Value: "logs"}})))
+	assert.Equal(t, "", commandCollection("ping", raw(bson.D{{Key: "ping", Value: 1}})))
+}
+
+func TestCommandMonitor(t *testing.T) {
+	monitor := newCommandMonitor()
+	command, err := bson.Marshal(bson.D{{Key: "monitorTest", Value: "calculations"}})
+	require.NoError(t, err)
+	before := promtestutil.CollectAndCount(metrics.MongoCommandDuration)
+
+	monitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: "monitorTest", Command: command, RequestID: 7})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		CommandName: "monitorTest", RequestID: 7, Duration: 3 * time.Millisecond,
+	}})
+	monitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: "monitorTest", Command: command, RequestID: 8})
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		CommandName: "monitorTest", RequestID: 8, Duration: time.Millisecond,
+	}})
+
+	assert.Equal(t, before+2, promtestutil.CollectAndCount(metrics.MongoCommandDuration))
+	// Both series are labelled with the collection: looking them up adds none
+	metrics.MongoCommandDuration.WithLabelValues("monitorTest", "calculations", "success")
+	metrics.MongoCommandDuration.WithLabelValues("monitorTest", "calculations", "error")
+	assert.Equal(t, before+2, promtestutil.CollectAndCount(metrics.MongoCommandDuration))
+}
+
+func TestCommandMonitor_Spans(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	monitor := newCommandMonitor()
+	command, err := bson.Marshal(bson.D{{Key: "find", Value: "users"}})
+	require.NoError(t, err)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "find", DatabaseName: "packs", Command: command, RequestID: 11})
+	monitor.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 11},
+		Failure:              "connection reset",
+	})
+	// Commands outside a trace are not traced
+	monitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: "find", Command: command, RequestID: 12})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 12}})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "find users", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Contains(t, span.Attributes(), attribute.String("db.system.name", "mongodb"))
+	assert.Contains(t, span.Attributes(), attribute.String("db.collection.name", "users"))
+	assert.Contains(t, span.Attributes(), attribute.String("db.namespace", "packs"))
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "connection reset", span.Status().Description)
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/guttosm/pack-service/internal/correlation"
+)
+
+// The comment helpers return options that only set the comment of an
+// operation sent with ctx: the request, correlation and trace IDs of the
+// request it serves, so the MongoDB profiler, slow query log and currentOp
+// can be joined with the logs of this and the calling services. They return
+// nil, which the driver ignores, outside a request. Repositories queried
+// while serving requests pass them to their operations.
+
+func findComment(ctx context.Context) *options.FindOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.Find().SetComment(comment)
+	}
+	return nil
+}
+
+func findOneComment(ctx context.Context) *options.FindOneOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.FindOne().SetComment(comment)
+	}
+	return nil
+}
+
+func findOneAndUpdateComment(ctx context.Context) *options.FindOneAndUpdateOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.FindOneAndUpdate().SetComment(comment)
+	}
+	return nil
+}
+
+func aggregateComment(ctx context.Context) *options.AggregateOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.Aggregate().SetComment(comment)
+	}
+	return nil
+}
+
+func countComment(ctx context.Context) *options.CountOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.Count().SetComment(comment)
+	}
+	return nil
+}
+
+func insertOneComment(ctx context.Context) *options.InsertOneOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.InsertOne().SetComment(comment)
+	}
+	return nil
+}
+
+func insertManyComment(ctx context.Context) *options.InsertManyOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.InsertMany().SetComment(comment)
+	}
+	return nil
+}
+
+func updateComment(ctx context.Context) *options.UpdateOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.Update().SetComment(comment)
+	}
+	return nil
+}
+
+func deleteComment(ctx context.Context) *options.DeleteOptions {
+	if comment := correlation.FromContext(ctx).String(); comment != "" {
+		return options.Delete().SetComment(comment)
+	}
+	return nil
+}
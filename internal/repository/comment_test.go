@@ -0,0 +1,23 @@
+//go:build !integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/correlation"
+)
+
+func TestFindComment(t *testing.T) {
+	assert.Nil(t, findComment(context.Background()), "no comment outside a request")
+
+	ctx := correlation.ContextWithIDs(context.Background(), correlation.IDs{RequestID: "req-1", CorrelationID: "order-42"})
+	opts := findComment(ctx)
+	require.NotNil(t, opts)
+	require.NotNil(t, opts.Comment)
+	assert.Equal(t, "request_id=req-1 correlation_id=order-42", *opts.Comment)
+}
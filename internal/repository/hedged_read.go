@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// ReadOption configures the reads of a repository.
+type ReadOption func(*hedge)
+
+// WithHedgedReads makes the latency-critical lookups of a repository issue a
+// second read to a secondary when the first has not answered within delay,
+// and take whichever answers first. Zero or less leaves hedging off.
+//
+// A secondary may lag the primary, so a hedge that wins can return data a
+// replication delay old, such as a token blacklisted a moment ago. Only reads
+// slower than delay are exposed to this.
+func WithHedgedReads(delay time.Duration) ReadOption {
+	return func(h *hedge) {
+		h.delay = delay
+	}
+}
+
+// hedge holds the hedged read settings of a collection.
+type hedge struct {
+	delay time.Duration
+	// secondary is the collection read by the hedge; nil when hedging is off.
+	secondary *mongo.Collection
+}
+
+// newHedge applies opts to reads of coll.
+func newHedge(coll *mongo.Collection, opts []ReadOption) hedge {
+	var h hedge
+	for _, opt := range opts {
+		opt(&h)
+	}
+	if h.delay <= 0 || coll == nil {
+		return hedge{}
+	}
+	secondary, err := coll.Clone(options.Collection().SetReadPreference(readpref.SecondaryPreferred()))
+	if err != nil {
+		return hedge{}
+	}
+	h.secondary = secondary
+	return h
+}
+
+// hedgeResult is the outcome of one of the reads of a hedged read.
+type hedgeResult[T any] struct {
+	value  T
+	err    error
+	hedged bool
+}
+
+// hedgedRead calls read with primary and, unless it returns within h.delay,
+// again with h.secondary. The first successful result wins and the other read
+// is cancelled; when both fail, the primary's error is returned.
+func hedgedRead[T any](ctx context.Context, h hedge, primary *mongo.Collection, read func(context.Context, *mongo.Collection) (T, error)) (T, error) {
+	if h.secondary == nil {
+		return read(ctx, primary)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult[T], 2)
+	start := func(coll *mongo.Collection, hedged bool) {
+		go func() {
+			value, err := read(ctx, coll)
+			results <- hedgeResult[T]{value: value, err: err, hedged: hedged}
+		}()
+	}
+	start(primary, false)
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	pending, hedged := 1, false
+	var primaryErr error
+	for {
+		select {
+		case <-timer.C:
+			start(h.secondary, true)
+			pending, hedged = pending+1, true
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				if hedged {
+					metrics.RecordMongoHedgedRead(primary.Name(), result.hedged)
+				}
+				return result.value, nil
+			}
+			if !result.hedged {
+				primaryErr = result.err
+			}
+			// A primary failing before the delay is not hedged: a failure is
+			// not slowness, and retrying is the caller's decision
+			if pending == 0 {
+				return result.value, primaryErr
+			}
+		}
+	}
+}
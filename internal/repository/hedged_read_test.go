@@ -0,0 +1,95 @@
+//go:build !integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestHedgedRead(t *testing.T) {
+	// Collections are not contacted until a command runs, which the fake
+	// reads below never do
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:1"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+	users := client.Database("test").Collection("users")
+
+	errPrimary := errors.New("primary failed")
+	errHedge := errors.New("hedge failed")
+
+	// fakeRead answers after the delay and with the error of the collection
+	// it is given, counting the reads issued.
+	type behavior struct {
+		delay time.Duration
+		err   error
+	}
+	fakeRead := func(h hedge, primary, secondary behavior, reads *atomic.Int32) func(context.Context, *mongo.Collection) (string, error) {
+		return func(ctx context.Context, coll *mongo.Collection) (string, error) {
+			reads.Add(1)
+			b, name := primary, "primary"
+			if coll == h.secondary {
+				b, name = secondary, "hedge"
+			}
+			select {
+			case <-time.After(b.delay):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+			if b.err != nil {
+				return "", b.err
+			}
+			return name, nil
+		}
+	}
+
+	tests := []struct {
+		name      string
+		primary   behavior
+		secondary behavior
+		want      string
+		wantErr   error
+		wantReads int32
+	}{
+		{name: "fast primary is not hedged", primary: behavior{}, want: "primary", wantReads: 1},
+		{name: "slow primary loses to the hedge", primary: behavior{delay: time.Second}, want: "hedge", wantReads: 2},
+		{name: "slow primary still wins over a slower hedge", primary: behavior{delay: 30 * time.Millisecond}, secondary: behavior{delay: time.Second}, want: "primary", wantReads: 2},
+		{name: "failing primary is not hedged", primary: behavior{err: errPrimary}, wantErr: errPrimary, wantReads: 1},
+		{name: "failed hedge waits for the primary", primary: behavior{delay: 30 * time.Millisecond}, secondary: behavior{err: errHedge}, want: "primary", wantReads: 2},
+		{name: "both failing return the primary error", primary: behavior{delay: 30 * time.Millisecond, err: errPrimary}, secondary: behavior{err: errHedge}, wantErr: errPrimary, wantReads: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHedge(users, []ReadOption{WithHedgedReads(10 * time.Millisecond)})
+			require.NotNil(t, h.secondary)
+			var reads atomic.Int32
+
+			got, err := hedgedRead(context.Background(), h, users, fakeRead(h, tt.primary, tt.secondary, &reads))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantReads, reads.Load())
+		})
+	}
+
+	t.Run("disabled without a delay", func(t *testing.T) {
+		h := newHedge(users, []ReadOption{WithHedgedReads(0)})
+		assert.Nil(t, h.secondary)
+		var reads atomic.Int32
+
+		got, err := hedgedRead(context.Background(), h, users, fakeRead(h, behavior{delay: 30 * time.Millisecond}, behavior{}, &reads))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "primary", got)
+		assert.Equal(t, int32(1), reads.Load())
+	})
+}
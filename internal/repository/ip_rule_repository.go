@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrIPRuleExists is returned when a rule for the CIDR range is already stored.
+var ErrIPRuleExists = domainerr.New("ip_rule_exists", http.StatusConflict, i18n.ErrKeyConflict, "IP rule already exists")
+
+// IPRuleRepositoryInterface defines the interface for IP rule repository operations.
+type IPRuleRepositoryInterface interface {
+	List(ctx context.Context) ([]*model.IPRule, error)
+	Create(ctx context.Context, rule *model.IPRule) error
+	Delete(ctx context.Context, id primitive.ObjectID) (bool, error)
+}
+
+// IPRuleRepository implements IPRuleRepositoryInterface using MongoDB.
+type IPRuleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewIPRuleRepository creates a new IP rule repository.
+func NewIPRuleRepository(db *mongo.Database) *IPRuleRepository {
+	return &IPRuleRepository{
+		collection: db.Collection("ip_rules"),
+	}
+}
+
+// List returns every stored rule, newest first. Expired rules the TTL index
+// has not deleted yet are included.
+func (r *IPRuleRepository) List(ctx context.Context) ([]*model.IPRule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	rules := make([]*model.IPRule, 0)
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Create stores a new rule, setting its ID. It returns ErrIPRuleExists when
+// the CIDR range already has a rule.
+func (r *IPRuleRepository) Create(ctx context.Context, rule *model.IPRule) error {
+	if rule.ID.IsZero() {
+		rule.ID = primitive.NewObjectID()
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = timeutil.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, rule)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrIPRuleExists
+	}
+	return err
+}
+
+// Delete deletes the rule id. It reports whether a rule was deleted.
+func (r *IPRuleRepository) Delete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
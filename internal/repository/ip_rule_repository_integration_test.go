@@ -0,0 +1,56 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestIPRuleRepository(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewIPRuleRepository(db.Database)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	expires := now.Add(time.Hour)
+	older := &model.IPRule{CIDR: "10.0.0.0/8", Action: model.IPRuleAllow, CreatedAt: now.Add(-time.Hour)}
+	newer := &model.IPRule{CIDR: "203.0.113.0/24", Action: model.IPRuleDeny, Reason: "abuse", CreatedAt: now, ExpiresAt: &expires}
+	require.NoError(t, repo.Create(ctx, older))
+	require.NoError(t, repo.Create(ctx, newer))
+	assert.False(t, newer.ID.IsZero())
+
+	err := repo.Create(ctx, &model.IPRule{CIDR: "203.0.113.0/24", Action: model.IPRuleAllow})
+	assert.ErrorIs(t, err, ErrIPRuleExists)
+
+	rules, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, newer.ID, rules[0].ID, "newest first")
+	assert.Equal(t, "abuse", rules[0].Reason)
+	require.NotNil(t, rules[0].ExpiresAt)
+	assert.True(t, rules[0].ExpiresAt.Equal(expires))
+
+	deleted, err := repo.Delete(ctx, older.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.Delete(ctx, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	rules, err = repo.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+}
@@ -0,0 +1,88 @@
+// Package repository provides failed login tracking data access layer.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginAttemptRepositoryInterface defines the interface for failed login tracking.
+type LoginAttemptRepositoryInterface interface {
+	// Get returns the attempt tracked under key, or nil when there is none.
+	Get(ctx context.Context, key string) (*model.LoginAttempt, error)
+	// RecordFailure counts a failed login for key and returns the updated
+	// attempt. The count starts over once window passes without failures.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*model.LoginAttempt, error)
+	// Lock locks key out until the given time and resets its failure count.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset forgets key's failures and any lock.
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginAttemptRepository implements LoginAttemptRepositoryInterface using MongoDB.
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository.
+func NewLoginAttemptRepository(db *mongo.Database) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		collection: db.Collection("login_attempts"),
+	}
+}
+
+// Get returns the attempt tracked under key. Expired documents the TTL
+// monitor has not removed yet are treated as missing.
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	err := r.collection.FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gt": timeutil.Now()}}, findOneComment(ctx)).Decode(&attempt)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// RecordFailure increments key's failure count in a single update, so
+// concurrent failures from several replicas are all counted.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*model.LoginAttempt, error) {
+	now := timeutil.Now()
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$expires_at", now}},
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}},
+			1,
+		}},
+		// A lock outlives the failure window
+		"expires_at": bson.M{"$max": bson.A{now.Add(window), "$locked_until"}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt model.LoginAttempt
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts, findOneAndUpdateComment(ctx)).Decode(&attempt); err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Lock locks key out until the given time.
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{
+		"$set": bson.M{"failures": 0, "locked_until": until, "expires_at": until},
+	}, options.Update().SetUpsert(true), updateComment(ctx))
+	return err
+}
+
+// Reset deletes key's attempt.
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key}, deleteComment(ctx))
+	return err
+}
@@ -0,0 +1,61 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewLoginAttemptRepository(db.Database)
+	key := "account:jane@example.com"
+
+	attempt, err := repo.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Nil(t, attempt)
+
+	for i := 1; i <= 3; i++ {
+		attempt, err = repo.RecordFailure(ctx, key, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, attempt.Failures)
+	}
+
+	until := time.Now().Add(10 * time.Minute).UTC().Truncate(time.Millisecond)
+	require.NoError(t, repo.Lock(ctx, key, until))
+	attempt, err = repo.Get(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, attempt)
+	assert.Zero(t, attempt.Failures)
+	assert.True(t, attempt.IsLocked(time.Now()))
+	assert.True(t, attempt.LockedUntil.Equal(until))
+
+	// Failures during a lock do not shorten it
+	attempt, err = repo.RecordFailure(ctx, key, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, attempt.ExpiresAt.Equal(until))
+
+	require.NoError(t, repo.Reset(ctx, key))
+	attempt, err = repo.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Nil(t, attempt)
+
+	// Failures outside the window start a new count
+	_, err = repo.RecordFailure(ctx, "ip:203.0.113.7", -time.Second)
+	require.NoError(t, err)
+	attempt, err = repo.RecordFailure(ctx, "ip:203.0.113.7", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempt.Failures)
+}
@@ -3,12 +3,16 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // LogEntryDocument represents a log entry document in MongoDB.
@@ -19,6 +23,9 @@ type LogEntryDocument struct {
 	Level      string                 `bson:"level" json:"level"`
 	Message    string                 `bson:"message" json:"message"`
 	RequestID  string                 `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	// CorrelationID and TraceID tie the entry to the requests of other services
+	CorrelationID string              `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
+	TraceID    string                 `bson:"trace_id,omitempty" json:"trace_id,omitempty"`
 	Method     string                 `bson:"method,omitempty" json:"method,omitempty"`
 	Path       string                 `bson:"path,omitempty" json:"path,omitempty"`
 	StatusCode int                    `bson:"status_code,omitempty" json:"status_code,omitempty"`
@@ -51,7 +58,7 @@ func (r *LogsRepository) Create(ctx context.Context, entry *LogEntryDocument) er
 		entry.ID = primitive.NewObjectID()
 	}
 	if entry.Timestamp.IsZero() {
-		entry.Timestamp = time.Now()
+		entry.Timestamp = timeutil.Now()
 	}
 
 	_, err := r.collection.InsertOne(ctx, entry)
@@ -70,7 +77,7 @@ func (r *LogsRepository) CreateMany(ctx context.Context, entries []*LogEntryDocu
 			entry.ID = primitive.NewObjectID()
 		}
 		if entry.Timestamp.IsZero() {
-			entry.Timestamp = time.Now()
+			entry.Timestamp = timeutil.Now()
 		}
 		docs[i] = entry
 	}
@@ -85,6 +92,14 @@ type LogQueryOptions struct {
 	Level     string
 	Method    string
 	Path      string
+	// CorrelationID matches the entries of every request of one operation.
+	CorrelationID string
+	// User matches the user ID or email of audit entries.
+	User string
+	// ActionType matches the action of audit entries.
+	ActionType string
+	// AuditOnly keeps only audit entries, which have an action type.
+	AuditOnly bool
 	StartTime *time.Time
 	EndTime   *time.Time
 	Limit     int
@@ -93,30 +108,7 @@ type LogQueryOptions struct {
 
 // Query queries log entry documents with filters.
 func (r *LogsRepository) Query(ctx context.Context, opts LogQueryOptions) ([]*LogEntryDocument, error) {
-	filter := bson.M{}
-
-	if opts.RequestID != "" {
-		filter["request_id"] = opts.RequestID
-	}
-	if opts.Level != "" {
-		filter["level"] = opts.Level
-	}
-	if opts.Method != "" {
-		filter["method"] = opts.Method
-	}
-	if opts.Path != "" {
-		filter["path"] = bson.M{"$regex": opts.Path, "$options": "i"}
-	}
-	if opts.StartTime != nil || opts.EndTime != nil {
-		timeFilter := bson.M{}
-		if opts.StartTime != nil {
-			timeFilter["$gte"] = *opts.StartTime
-		}
-		if opts.EndTime != nil {
-			timeFilter["$lte"] = *opts.EndTime
-		}
-		filter["timestamp"] = timeFilter
-	}
+	filter := logFilter(opts)
 
 	findOptions := options.Find().SetSort(bson.M{"timestamp": -1})
 	if opts.Limit > 0 {
@@ -142,16 +134,75 @@ func (r *LogsRepository) Query(ctx context.Context, opts LogQueryOptions) ([]*Lo
 	return entries, nil
 }
 
+// exportBatchSize is how many log entries Each fetches from MongoDB at a time.
+const exportBatchSize = 1000
+
+// Each calls fn for every log entry document matching the filter, oldest
+// first, stopping at the first error fn returns. Limit and Skip are ignored.
+// Entries are read through a cursor a batch at a time, so memory stays
+// bounded however many match.
+func (r *LogsRepository) Each(ctx context.Context, opts LogQueryOptions, fn func(*LogEntryDocument) error) error {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(exportBatchSize)
+
+	cursor, err := r.collection.Find(ctx, logFilter(opts), findOptions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	for cursor.Next(ctx) {
+		var entry LogEntryDocument
+		if err := cursor.Decode(&entry); err != nil {
+			return err
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
 // Count returns the count of log entry documents matching the filter.
 func (r *LogsRepository) Count(ctx context.Context, opts LogQueryOptions) (int64, error) {
+	return r.collection.CountDocuments(ctx, logFilter(opts))
+}
+
+// logFilter builds the filter shared by Query and Count, so a page and its
+// total always agree.
+func logFilter(opts LogQueryOptions) bson.M {
 	filter := bson.M{}
 
 	if opts.RequestID != "" {
 		filter["request_id"] = opts.RequestID
 	}
+	if opts.CorrelationID != "" {
+		filter["correlation_id"] = opts.CorrelationID
+	}
 	if opts.Level != "" {
 		filter["level"] = opts.Level
 	}
+	if opts.Method != "" {
+		filter["method"] = opts.Method
+	}
+	if opts.Path != "" {
+		// Paths are matched as text: callers pass them straight from query strings
+		filter["path"] = bson.M{"$regex": regexp.QuoteMeta(opts.Path), "$options": "i"}
+	}
+	if opts.ActionType != "" {
+		filter["action_type"] = opts.ActionType
+	} else if opts.AuditOnly {
+		filter["action_type"] = bson.M{"$exists": true, "$ne": ""}
+	}
+	if opts.User != "" {
+		filter["$or"] = bson.A{
+			bson.M{"user_id": opts.User},
+			bson.M{"user_email": opts.User},
+		}
+	}
 	if opts.StartTime != nil || opts.EndTime != nil {
 		timeFilter := bson.M{}
 		if opts.StartTime != nil {
@@ -163,5 +214,99 @@ func (r *LogsRepository) Count(ctx context.Context, opts LogQueryOptions) (int64
 		filter["timestamp"] = timeFilter
 	}
 
-	return r.collection.CountDocuments(ctx, filter)
+	return filter
+}
+
+// LogPurgeOptions selects the log entries DeleteLogs deletes.
+type LogPurgeOptions struct {
+	// Before deletes entries logged before this time.
+	Before time.Time
+	// Levels restricts the purge to entries of these levels; empty matches
+	// every level.
+	Levels []string
+	// ExceptLevels keeps entries of these levels.
+	ExceptLevels []string
+}
+
+// DeleteLogs deletes the log entries matching opts and returns how many it
+// deleted.
+func (r *LogsRepository) DeleteLogs(ctx context.Context, opts LogPurgeOptions) (int64, error) {
+	filter := bson.M{"timestamp": bson.M{"$lt": opts.Before}}
+	switch {
+	case len(opts.Levels) > 0:
+		filter["level"] = bson.M{"$in": opts.Levels}
+	case len(opts.ExceptLevels) > 0:
+		filter["level"] = bson.M{"$nin": opts.ExceptLevels}
+	}
+
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// TrimLogs deletes the oldest log entries beyond the newest maxEntries and
+// returns how many it deleted. Entries logged at the same instant as the
+// newest one deleted go with it, so slightly fewer may be kept.
+func (r *LogsRepository) TrimLogs(ctx context.Context, maxEntries int64) (int64, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(maxEntries).
+		SetProjection(bson.M{"timestamp": 1})
+	var cut LogEntryDocument
+	if err := r.collection.FindOne(ctx, bson.M{}, opts).Decode(&cut); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"timestamp": bson.M{"$lte": cut.Timestamp}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// ConfigVersionImpactDocument is one row of the calculations-by-config-version aggregation.
+type ConfigVersionImpactDocument struct {
+	Version      int       `bson:"_id"`
+	Calculations int64     `bson:"calculations"`
+	FirstSeen    time.Time `bson:"first_seen"`
+	LastSeen     time.Time `bson:"last_seen"`
+}
+
+// CalculationsByConfigVersion aggregates calculation audit entries by the pack
+// size config version they were computed with, for versions >= sinceVersion.
+// Results are sorted by version ascending.
+func (r *LogsRepository) CalculationsByConfigVersion(ctx context.Context, sinceVersion int) ([]ConfigVersionImpactDocument, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"action_type":               "calculate",
+			"fields.pack_sizes_version": bson.M{"$gte": sinceVersion},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          "$fields.pack_sizes_version",
+			"calculations": bson.M{"$sum": 1},
+			"first_seen":   bson.M{"$min": "$timestamp"},
+			"last_seen":    bson.M{"$max": "$timestamp"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	var results []ConfigVersionImpactDocument
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
 }
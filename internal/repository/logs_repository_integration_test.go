@@ -4,6 +4,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -87,6 +88,121 @@ func TestLogsRepository_Integration(t *testing.T) {
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, count, int64(1))
 	})
+
+	t.Run("query and count by user and path", func(t *testing.T) {
+		entries := []*LogEntryDocument{
+			{Level: "info", Message: "Login", Path: "/api/auth/login", UserID: "user-42", UserEmail: "ops@example.com"},
+			{Level: "info", Message: "Calculate", Path: "/api/calculate", UserID: "user-42", UserEmail: "ops@example.com"},
+			{Level: "info", Message: "Other user", Path: "/api/calculate", UserID: "user-7"},
+		}
+		require.NoError(t, repo.CreateMany(ctx, entries))
+
+		byEmail := LogQueryOptions{User: "ops@example.com", Path: "/api/calc"}
+		found, err := repo.Query(ctx, byEmail)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, "Calculate", found[0].Message)
+		count, err := repo.Count(ctx, byEmail)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = repo.Count(ctx, LogQueryOptions{User: "user-42"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("each audit entry oldest first", func(t *testing.T) {
+		start := time.Now().UTC().Truncate(time.Millisecond)
+		entries := []*LogEntryDocument{
+			{Timestamp: start.Add(2 * time.Second), Level: "info", Message: "Second", ActionType: "export_each", UserID: "user-99"},
+			{Timestamp: start.Add(time.Second), Level: "info", Message: "First", ActionType: "export_each", UserID: "user-99"},
+			{Timestamp: start.Add(time.Second), Level: "info", Message: "Not audited", UserID: "user-99"},
+		}
+		require.NoError(t, repo.CreateMany(ctx, entries))
+
+		var messages []string
+		err := repo.Each(ctx, LogQueryOptions{User: "user-99", AuditOnly: true, Limit: 1}, func(entry *LogEntryDocument) error {
+			messages = append(messages, entry.Message)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"First", "Second"}, messages)
+
+		stop := errors.New("stop")
+		calls := 0
+		err = repo.Each(ctx, LogQueryOptions{ActionType: "export_each"}, func(*LogEntryDocument) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("calculations by config version", func(t *testing.T) {
+		entries := []*LogEntryDocument{
+			{Level: "info", ActionType: "calculate", Fields: map[string]interface{}{"pack_sizes_version": 1}},
+			{Level: "info", ActionType: "calculate", Fields: map[string]interface{}{"pack_sizes_version": 2}},
+			{Level: "info", ActionType: "calculate", Fields: map[string]interface{}{"pack_sizes_version": 2}},
+			{Level: "info", ActionType: "calculate", Fields: map[string]interface{}{"pack_sizes_version": 3}},
+			{Level: "info", ActionType: "login", Fields: map[string]interface{}{"pack_sizes_version": 3}},
+		}
+		require.NoError(t, repo.CreateMany(ctx, entries))
+
+		results, err := repo.CalculationsByConfigVersion(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 2, results[0].Version)
+		assert.Equal(t, int64(2), results[0].Calculations)
+		assert.Equal(t, 3, results[1].Version)
+		assert.Equal(t, int64(1), results[1].Calculations)
+		assert.False(t, results[0].FirstSeen.After(results[0].LastSeen))
+	})
+}
+
+func TestLogsRepository_Purge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+	repo := NewLogsRepository(db)
+
+	now := time.Now()
+	require.NoError(t, repo.CreateMany(ctx, []*LogEntryDocument{
+		{Level: "info", Message: "old info", Timestamp: now.Add(-48 * time.Hour)},
+		{Level: "error", Message: "old error", Timestamp: now.Add(-48 * time.Hour)},
+		{Level: "info", Message: "recent info", Timestamp: now.Add(-2 * time.Hour)},
+		{Level: "error", Message: "recent error", Timestamp: now.Add(-time.Hour)},
+		{Level: "info", Message: "new info", Timestamp: now},
+	}))
+
+	t.Run("by age except some levels", func(t *testing.T) {
+		deleted, err := repo.DeleteLogs(ctx, LogPurgeOptions{Before: now.Add(-24 * time.Hour), ExceptLevels: []string{"error"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("by age for some levels", func(t *testing.T) {
+		deleted, err := repo.DeleteLogs(ctx, LogPurgeOptions{Before: now.Add(-24 * time.Hour), Levels: []string{"error"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("keeps the newest entries", func(t *testing.T) {
+		deleted, err := repo.TrimLogs(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		remaining, err := repo.Query(ctx, LogQueryOptions{})
+		require.NoError(t, err)
+		require.Len(t, remaining, 2)
+
+		deleted, err = repo.TrimLogs(ctx, 2)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+	})
 }
 
 func TestLogsRepositoryWithCircuitBreaker_Integration(t *testing.T) {
@@ -113,6 +229,14 @@ func TestLogsRepositoryWithCircuitBreaker_Integration(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("export callback errors do not trip the breaker", func(t *testing.T) {
+		stop := errors.New("client gone")
+		for i := 0; i < circuitbreaker.DefaultConfig().FailureThreshold+1; i++ {
+			err := wrappedRepo.Each(ctx, LogQueryOptions{}, func(*LogEntryDocument) error { return stop })
+			assert.ErrorIs(t, err, stop)
+		}
+	})
+
 	t.Run("circuit breaker stats", func(t *testing.T) {
 		stats := cb.GetStats()
 		assert.Equal(t, "closed", stats.State)
@@ -4,8 +4,10 @@ package repository
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 // TestLogsRepositoryStructure tests basic structure and type existence.
@@ -17,3 +19,34 @@ func TestLogsRepositoryStructure(t *testing.T) {
 		assert.True(t, true)
 	})
 }
+
+func TestLogFilter(t *testing.T) {
+	assert.Equal(t, bson.M{}, logFilter(LogQueryOptions{Limit: 10, Skip: 5}))
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	filter := logFilter(LogQueryOptions{
+		RequestID:     "req-1",
+		CorrelationID: "order-42",
+		Level:         "error",
+		Method:        "POST",
+		Path:          "/api/calculate?x=(",
+		User:          "ops@example.com",
+		StartTime:     &start,
+	})
+	assert.Equal(t, bson.M{
+		"request_id":     "req-1",
+		"correlation_id": "order-42",
+		"level":          "error",
+		"method":         "POST",
+		// Regex metacharacters in the path are matched literally
+		"path": bson.M{"$regex": `/api/calculate\?x=\(`, "$options": "i"},
+		"$or": bson.A{
+			bson.M{"user_id": "ops@example.com"},
+			bson.M{"user_email": "ops@example.com"},
+		},
+		"timestamp": bson.M{"$gte": start},
+	}, filter)
+
+	assert.Equal(t, bson.M{"action_type": bson.M{"$exists": true, "$ne": ""}}, logFilter(LogQueryOptions{AuditOnly: true}))
+	assert.Equal(t, bson.M{"action_type": "login"}, logFilter(LogQueryOptions{ActionType: "login", AuditOnly: true}))
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MessageOverrideRepositoryInterface defines the interface for message override repository operations.
+type MessageOverrideRepositoryInterface interface {
+	List(ctx context.Context) ([]*model.MessageOverride, error)
+	Upsert(ctx context.Context, override *model.MessageOverride) error
+	Delete(ctx context.Context, tenant, locale, key string) (bool, error)
+}
+
+// MessageOverrideRepository implements MessageOverrideRepositoryInterface using MongoDB.
+type MessageOverrideRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMessageOverrideRepository creates a new message override repository.
+func NewMessageOverrideRepository(db *mongo.Database) *MessageOverrideRepository {
+	return &MessageOverrideRepository{
+		collection: db.Collection("message_overrides"),
+	}
+}
+
+// List returns every override, sorted by tenant, locale and key.
+func (r *MessageOverrideRepository) List(ctx context.Context) ([]*model.MessageOverride, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "tenant", Value: 1}, {Key: "locale", Value: 1}, {Key: "key", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	overrides := make([]*model.MessageOverride, 0)
+	if err := cursor.All(ctx, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// Upsert stores override, replacing the tenant's override of the same
+// message and locale.
+func (r *MessageOverrideRepository) Upsert(ctx context.Context, override *model.MessageOverride) error {
+	override.UpdatedAt = timeutil.Now()
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"tenant": override.Tenant, "locale": override.Locale, "key": override.Key},
+		bson.M{"$set": bson.M{
+			"message":    override.Message,
+			"updated_by": override.UpdatedBy,
+			"updated_at": override.UpdatedAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Delete removes a tenant's override of a message. It reports whether an
+// override was deleted.
+func (r *MessageOverrideRepository) Delete(ctx context.Context, tenant, locale, key string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"tenant": tenant, "locale": locale, "key": key})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
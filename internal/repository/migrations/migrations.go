@@ -0,0 +1,131 @@
+// Package migrations declares the collections and indexes of the service's
+// MongoDB database as versioned migrations, and applies the ones a database
+// has not had yet.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// Collection is the collection recording the applied migrations, one
+// document per version.
+const Collection = "schema_migrations"
+
+// ErrInvalidMigrations is returned for a list of migrations that cannot be run.
+var ErrInvalidMigrations = errors.New("invalid migrations")
+
+// Migration changes the database from the previous version to Version.
+//
+// Replicas starting together may apply the same migration, so Up must be
+// idempotent, as creating an index that already exists is.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record is the document recording an applied migration.
+type Record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Runner applies migrations to a database.
+type Runner struct {
+	db         *mongo.Database
+	migrations []Migration
+}
+
+// NewRunner creates a runner applying migrations, ordered by increasing
+// version, to db.
+func NewRunner(db *mongo.Database, migrations []Migration) *Runner {
+	return &Runner{db: db, migrations: migrations}
+}
+
+// Applied returns the migrations recorded in db, by increasing version.
+func (r *Runner) Applied(ctx context.Context) ([]Record, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.db.Collection(Collection).Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Pending returns the migrations not applied to db yet, in the order they run.
+func (r *Runner) Pending(ctx context.Context) ([]Migration, error) {
+	if err := Validate(r.migrations); err != nil {
+		return nil, err
+	}
+	records, err := r.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+
+	var pending []Migration
+	for _, m := range r.migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Run applies the pending migrations in order, recording each one once it
+// succeeded, and returns the ones it applied. It stops at the first failure.
+func (r *Runner) Run(ctx context.Context) ([]Migration, error) {
+	pending, err := r.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range pending {
+		if err := m.Up(ctx, r.db); err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		record := Record{Version: m.Version, Description: m.Description, AppliedAt: timeutil.Now()}
+		// A duplicate means another replica applied it at the same time
+		if _, err := r.db.Collection(Collection).InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return applied, fmt.Errorf("recording migration %d: %w", m.Version, err)
+		}
+		log.Info().Int("version", m.Version).Str("description", m.Description).Msg("Applied database migration")
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Validate checks that migrations have positive, strictly increasing
+// versions and an Up function.
+func Validate(migrations []Migration) error {
+	previous := 0
+	for _, m := range migrations {
+		if m.Version <= previous {
+			return fmt.Errorf("%w: version %d follows version %d", ErrInvalidMigrations, m.Version, previous)
+		}
+		if m.Up == nil {
+			return fmt.Errorf("%w: version %d has no Up function", ErrInvalidMigrations, m.Version)
+		}
+		previous = m.Version
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestAll(t *testing.T) {
+	migrations := All()
+
+	assert.NoError(t, Validate(migrations))
+	for _, m := range migrations {
+		assert.NotEmpty(t, m.Description, "migration %d", m.Version)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	up := func(context.Context, *mongo.Database) error { return nil }
+
+	tests := []struct {
+		name       string
+		migrations []Migration
+		wantErr    bool
+	}{
+		{name: "empty", migrations: nil},
+		{name: "increasing versions", migrations: []Migration{{Version: 1, Up: up}, {Version: 3, Up: up}}},
+		{name: "zero version", migrations: []Migration{{Version: 0, Up: up}}, wantErr: true},
+		{name: "duplicate version", migrations: []Migration{{Version: 1, Up: up}, {Version: 1, Up: up}}, wantErr: true},
+		{name: "decreasing versions", migrations: []Migration{{Version: 2, Up: up}, {Version: 1, Up: up}}, wantErr: true},
+		{name: "missing Up", migrations: []Migration{{Version: 1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.migrations)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidMigrations)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
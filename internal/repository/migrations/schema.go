@@ -0,0 +1,215 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All returns the migrations of the service's database, by increasing
+// version. New migrations are appended with the next version; applied ones
+// are never changed, since databases will not run them again.
+//
+// Creating an index creates its collection, so the collections the
+// repositories use exist once their indexes do. The logs TTL index is not
+// declared here: its expiry is configured, see repository.MongoDB.SetLogsTTL.
+func All() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "index active pack sizes",
+			Up: createIndexes("pack_sizes",
+				mongo.IndexModel{Keys: bson.D{{Key: "active", Value: 1}}},
+			),
+		},
+		{
+			Version:     2,
+			Description: "index logs by request and by pack sizes version",
+			Up: createIndexes("logs",
+				mongo.IndexModel{Keys: bson.D{{Key: "request_id", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "action_type", Value: 1}, {Key: "fields.pack_sizes_version", Value: 1}}},
+			),
+		},
+		{
+			Version:     3,
+			Description: "unique user emails and usernames",
+			Up: createIndexes("users",
+				mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+				// Usernames are optional, so uniqueness is only enforced for non-empty values
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "username", Value: 1}},
+					Options: options.Index().
+						SetUnique(true).
+						SetPartialFilterExpression(bson.M{"username": bson.M{"$gt": ""}}),
+				},
+			),
+		},
+		{
+			Version:     4,
+			Description: "unique role names and permissions",
+			Up: sequence(
+				createIndexes("roles",
+					mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+				),
+				createIndexes("permissions",
+					mongo.IndexModel{Keys: bson.D{{Key: "resource", Value: 1}, {Key: "action", Value: 1}}, Options: options.Index().SetUnique(true)},
+				),
+			),
+		},
+		{
+			Version:     5,
+			Description: "token lookup and expiry",
+			Up: createIndexes("tokens",
+				mongo.IndexModel{Keys: bson.D{{Key: "token", Value: 1}}, Options: options.Index().SetUnique(true)},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "type", Value: 1}}},
+				// Expired tokens are deleted by MongoDB at their expires_at
+				mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+			),
+		},
+		{
+			Version:     6,
+			Description: "unique preset names per owner",
+			Up: createIndexes("presets",
+				mongo.IndexModel{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+			),
+		},
+		{
+			Version:     7,
+			Description: "client usage per client, user agent and version",
+			Up: createIndexes("client_usage",
+				mongo.IndexModel{
+					Keys: bson.D{
+						{Key: "client", Value: 1},
+						{Key: "user_agent", Value: 1},
+						{Key: "client_version", Value: 1},
+					},
+					Options: options.Index().SetUnique(true),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "last_seen", Value: -1}}},
+			),
+		},
+		{
+			Version:     8,
+			Description: "calculation history, newest first, per user and by order size",
+			Up: createIndexes("calculations",
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "items_ordered", Value: 1}}},
+			),
+		},
+		{
+			Version:     9,
+			Description: "login attempts expiry",
+			Up: createIndexes("login_attempts",
+				mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+			),
+		},
+		{
+			Version:     10,
+			Description: "calculation jobs claim order and expiry of finished jobs",
+			Up: createIndexes("calculation_jobs",
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+				// Unfinished jobs have no expires_at
+				mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+			),
+		},
+		{
+			Version:     11,
+			Description: "calculation rollups by day, user and pack set",
+			Up: createIndexes("calculation_rollups",
+				mongo.IndexModel{Keys: bson.D{{Key: "day", Value: 1}, {Key: "user_id", Value: 1}, {Key: "pack_set", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "day", Value: 1}}},
+			),
+		},
+		{
+			Version:     12,
+			Description: "unique message overrides per tenant, locale and key",
+			Up: createIndexes("message_overrides",
+				mongo.IndexModel{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "locale", Value: 1}, {Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+			),
+		},
+		{
+			Version:     13,
+			Description: "unique JWT signing key IDs",
+			Up: createIndexes("signing_keys",
+				mongo.IndexModel{Keys: bson.D{{Key: "kid", Value: 1}}, Options: options.Index().SetUnique(true)},
+			),
+		},
+		{
+			Version:     14,
+			Description: "unique IP rule ranges and rule expiry",
+			Up: createIndexes("ip_rules",
+				mongo.IndexModel{Keys: bson.D{{Key: "cidr", Value: 1}}, Options: options.Index().SetUnique(true)},
+				mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+			),
+		},
+		{
+			Version:     15,
+			Description: "unique pack sizes configuration versions",
+			Up: sequence(
+				renumberDuplicatePackSizesVersions,
+				createIndexes("pack_sizes",
+					mongo.IndexModel{Keys: bson.D{{Key: "version", Value: 1}}, Options: options.Index().SetUnique(true)},
+				),
+			),
+		},
+	}
+}
+
+// renumberDuplicatePackSizesVersions gives the pack sizes configurations
+// sharing their version with an older one the versions after the highest,
+// so that versions can be made unique.
+func renumberDuplicatePackSizesVersions(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection("pack_sizes")
+	cursor, err := coll.Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "version", Value: 1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"version": 1}))
+	if err != nil {
+		return err
+	}
+	var configs []struct {
+		ID      primitive.ObjectID `bson:"_id"`
+		Version int                `bson:"version"`
+	}
+	if err := cursor.All(ctx, &configs); err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+
+	next := configs[len(configs)-1].Version + 1
+	for i := 1; i < len(configs); i++ {
+		if configs[i].Version != configs[i-1].Version {
+			continue
+		}
+		if _, err := coll.UpdateByID(ctx, configs[i].ID, bson.M{"$set": bson.M{"version": next}}); err != nil {
+			return err
+		}
+		next++
+	}
+	return nil
+}
+
+// createIndexes returns a migration step creating indexes on collection.
+func createIndexes(collection string, indexes ...mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes)
+		return err
+	}
+}
+
+// sequence returns a migration step running steps in order.
+func sequence(steps ...func(context.Context, *mongo.Database) error) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, step := range steps {
+			if err := step(ctx, db); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
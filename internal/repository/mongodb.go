@@ -7,6 +7,8 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/guttosm/pack-service/internal/repository/migrations"
 )
 
 // MongoConfig holds MongoDB connection pool configuration.
@@ -25,6 +27,9 @@ type MongoConfig struct {
 	SocketTimeout time.Duration
 	// EnableCompression enables wire protocol compression.
 	EnableCompression bool
+	// MigrationTimeout bounds the migrations applied on connection, which
+	// may build indexes on large collections.
+	MigrationTimeout time.Duration
 }
 
 // DefaultMongoConfig returns production-optimized MongoDB configuration.
@@ -37,19 +42,29 @@ func DefaultMongoConfig() MongoConfig {
 		ServerSelectionTimeout: 5 * time.Second,
 		SocketTimeout:          30 * time.Second,
 		EnableCompression:      true,
+		MigrationTimeout:       2 * time.Minute,
 	}
 }
 
 // MongoDB provides MongoDB client and database access.
 type MongoDB struct {
-	Client      *mongo.Client
-	Database    *mongo.Database
-	PackSizes   *mongo.Collection
-	Logs        *mongo.Collection
-	Users       *mongo.Collection
-	Roles       *mongo.Collection
-	Permissions *mongo.Collection
-	Tokens      *mongo.Collection
+	Client             *mongo.Client
+	Database           *mongo.Database
+	PackSizes          *mongo.Collection
+	Logs               *mongo.Collection
+	Users              *mongo.Collection
+	Roles              *mongo.Collection
+	Permissions        *mongo.Collection
+	Tokens             *mongo.Collection
+	Presets            *mongo.Collection
+	ClientUsage        *mongo.Collection
+	Calculations       *mongo.Collection
+	LoginAttempts      *mongo.Collection
+	CalculationJobs    *mongo.Collection
+	CalculationRollups *mongo.Collection
+	MessageOverrides   *mongo.Collection
+	SigningKeys        *mongo.Collection
+	IPRules            *mongo.Collection
 }
 
 // NewMongoDB creates a new MongoDB connection with default configuration.
@@ -57,7 +72,8 @@ func NewMongoDB(uri, databaseName string) (*MongoDB, error) {
 	return NewMongoDBWithConfig(uri, databaseName, DefaultMongoConfig())
 }
 
-// NewMongoDBWithConfig creates a new MongoDB connection with custom configuration.
+// NewMongoDBWithConfig creates a new MongoDB connection with custom
+// configuration, and applies the pending migrations.
 func NewMongoDBWithConfig(uri, databaseName string, cfg MongoConfig) (*MongoDB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
 	defer cancel()
@@ -70,7 +86,8 @@ func NewMongoDBWithConfig(uri, databaseName string, cfg MongoConfig) (*MongoDB,
 		SetMaxConnIdleTime(cfg.MaxConnIdleTime).
 		SetConnectTimeout(cfg.ConnectTimeout).
 		SetServerSelectionTimeout(cfg.ServerSelectionTimeout).
-		SetSocketTimeout(cfg.SocketTimeout)
+		SetSocketTimeout(cfg.SocketTimeout).
+		SetMonitor(newCommandMonitor())
 
 	// Enable compression if configured
 	if cfg.EnableCompression {
@@ -93,89 +110,50 @@ func NewMongoDBWithConfig(uri, databaseName string, cfg MongoConfig) (*MongoDB,
 
 	db := client.Database(databaseName)
 	mongoDB := &MongoDB{
-		Client:      client,
-		Database:    db,
-		PackSizes:   db.Collection("pack_sizes"),
-		Logs:        db.Collection("logs"),
-		Users:       db.Collection("users"),
-		Roles:       db.Collection("roles"),
-		Permissions: db.Collection("permissions"),
-		Tokens:      db.Collection("tokens"),
-	}
-
-	// Create indexes
-	if err := mongoDB.createIndexes(ctx); err != nil {
+		Client:             client,
+		Database:           db,
+		PackSizes:          db.Collection("pack_sizes"),
+		Logs:               db.Collection("logs"),
+		Users:              db.Collection("users"),
+		Roles:              db.Collection("roles"),
+		Permissions:        db.Collection("permissions"),
+		Tokens:             db.Collection("tokens"),
+		Presets:            db.Collection("presets"),
+		ClientUsage:        db.Collection("client_usage"),
+		Calculations:       db.Collection("calculations"),
+		LoginAttempts:      db.Collection("login_attempts"),
+		CalculationJobs:    db.Collection("calculation_jobs"),
+		CalculationRollups: db.Collection("calculation_rollups"),
+		MessageOverrides:   db.Collection("message_overrides"),
+		SigningKeys:        db.Collection("signing_keys"),
+		IPRules:            db.Collection("ip_rules"),
+	}
+
+	// Bring the collections and indexes up to date
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), cfg.MigrationTimeout)
+	defer cancelMigrate()
+	if _, err := mongoDB.Migrate(migrateCtx); err != nil {
+		_ = client.Disconnect(ctx)
 		return nil, err
 	}
 
 	return mongoDB, nil
 }
 
-// createIndexes creates necessary indexes for collections.
-func (m *MongoDB) createIndexes(ctx context.Context) error {
-	// Pack sizes index: active pack sizes
-	packSizesIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"active": 1},
-		Options: options.Index().SetUnique(false),
-	}
-	_, err := m.PackSizes.Indexes().CreateOne(ctx, packSizesIndex)
-	if err != nil {
-		return err
-	}
-
-	// Logs index: TTL index for automatic cleanup (will be updated by SetLogsTTL)
-	// Don't create here to avoid conflicts - SetLogsTTL will handle it
-
-	// Logs index: request_id for querying
-	requestIDIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"request_id": 1},
-		Options: options.Index().SetUnique(false),
-	}
-	_, _ = m.Logs.Indexes().CreateOne(ctx, requestIDIndex)
-	// Ignore errors if index already exists (index might already exist, that's okay)
-
-	// Users indexes
-	emailIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"email": 1},
-		Options: options.Index().SetUnique(true),
-	}
-	_, _ = m.Users.Indexes().CreateOne(ctx, emailIndex)
-
-	// Roles indexes
-	roleNameIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"name": 1},
-		Options: options.Index().SetUnique(true),
-	}
-	_, _ = m.Roles.Indexes().CreateOne(ctx, roleNameIndex)
-
-	// Permissions indexes
-	permissionResourceActionIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"resource": 1, "action": 1},
-		Options: options.Index().SetUnique(true),
-	}
-	_, _ = m.Permissions.Indexes().CreateOne(ctx, permissionResourceActionIndex)
-
-	// Tokens indexes
-	tokenIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"token": 1},
-		Options: options.Index().SetUnique(true),
-	}
-	_, _ = m.Tokens.Indexes().CreateOne(ctx, tokenIndex)
-
-	userIDTypeIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"user_id": 1, "type": 1},
-		Options: options.Index().SetUnique(false),
-	}
-	_, _ = m.Tokens.Indexes().CreateOne(ctx, userIDTypeIndex)
+// Migrate applies the migrations the database has not had yet and returns
+// the ones it applied.
+func (m *MongoDB) Migrate(ctx context.Context) ([]migrations.Migration, error) {
+	return migrations.NewRunner(m.Database, migrations.All()).Run(ctx)
+}
 
-	// TTL index for tokens (auto-delete expired tokens)
-	tokenTTLIndex := mongo.IndexModel{
-		Keys:    map[string]interface{}{"expires_at": 1},
-		Options: options.Index().SetExpireAfterSeconds(0), // 0 means use expires_at field
+// SchemaVersion returns the version of the last migration applied to the
+// database, or 0 when none was.
+func (m *MongoDB) SchemaVersion(ctx context.Context) (int, error) {
+	records, err := migrations.NewRunner(m.Database, migrations.All()).Applied(ctx)
+	if err != nil || len(records) == 0 {
+		return 0, err
 	}
-	_, _ = m.Tokens.Indexes().CreateOne(ctx, tokenTTLIndex)
-
-	return nil
+	return records[len(records)-1].Version, nil
 }
 
 // SetLogsTTL updates the TTL index for logs collection.
@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/repository/migrations"
 )
 
 func TestMongoDB_Integration(t *testing.T) {
@@ -57,6 +59,16 @@ func TestMongoDB_Integration(t *testing.T) {
 		_ = err2
 	})
 
+	t.Run("migrations applied once", func(t *testing.T) {
+		version, err := db.SchemaVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, migrations.All()[len(migrations.All())-1].Version, version)
+
+		applied, err := db.Migrate(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+
 	t.Run("verify collections exist", func(t *testing.T) {
 		// Collections are created during NewMongoDB
 		// Verify collections exist
@@ -3,42 +3,77 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // PackSizeConfig represents a pack size configuration document.
 type PackSizeConfig struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Sizes     []int              `bson:"sizes" json:"sizes"`
-	Active    bool               `bson:"active" json:"active"`
-	Version   int                `bson:"version" json:"version"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
-	CreatedBy string             `bson:"created_by,omitempty" json:"created_by,omitempty"`
-	Metadata  map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Sizes       []int                  `bson:"sizes" json:"sizes"`
+	Costs       map[int]float64        `bson:"costs,omitempty" json:"costs,omitempty"`
+	Active      bool                   `bson:"active" json:"active"`
+	Version     int                    `bson:"version" json:"version"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time              `bson:"updated_at" json:"updated_at"`
+	CreatedBy   string                 `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	ActivatedAt *time.Time             `bson:"activated_at,omitempty" json:"activated_at,omitempty"`
+	ActivatedBy string                 `bson:"activated_by,omitempty" json:"activated_by,omitempty"`
+	Metadata    map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
 }
 
+// errActiveVersionChanged aborts CreateIfActive when the expected version is
+// no longer active.
+var errActiveVersionChanged = errors.New("active pack sizes version changed")
+
 // PackSizesRepository provides methods for pack sizes operations.
 type PackSizesRepository struct {
 	collection *mongo.Collection
 }
 
 // NewPackSizesRepository creates a new pack sizes repository.
+//
+// Pack sizes are read far more often than written and a client that just updated
+// them expects to see the change immediately, so the collection is used with
+// majority write/read concerns and primary reads. A write acknowledged by a
+// majority is then visible to every subsequent read, regardless of which node
+// the driver would otherwise have picked.
 func NewPackSizesRepository(db *MongoDB) *PackSizesRepository {
 	return &PackSizesRepository{
-		collection: db.PackSizes,
+		collection: consistentCollection(db.PackSizes),
 	}
 }
 
+// consistentCollection returns a copy of coll configured for read-your-writes.
+func consistentCollection(coll *mongo.Collection) *mongo.Collection {
+	if coll == nil {
+		return nil
+	}
+	clone, err := coll.Clone(options.Collection().
+		SetWriteConcern(writeconcern.Majority()).
+		SetReadConcern(readconcern.Majority()).
+		SetReadPreference(readpref.Primary()))
+	if err != nil {
+		return coll
+	}
+	return clone
+}
+
 // GetActive returns the active pack size configuration.
 func (r *PackSizesRepository) GetActive(ctx context.Context) (*PackSizeConfig, error) {
 	var config PackSizeConfig
-	err := r.collection.FindOne(ctx, bson.M{"active": true}).Decode(&config)
+	err := r.collection.FindOne(ctx, bson.M{"active": true}, findOneComment(ctx)).Decode(&config)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil // No active config found
 	}
@@ -48,29 +83,77 @@ func (r *PackSizesRepository) GetActive(ctx context.Context) (*PackSizeConfig, e
 	return &config, nil
 }
 
-// Create creates a new pack size configuration.
-func (r *PackSizesRepository) Create(ctx context.Context, sizes []int, createdBy string) (*PackSizeConfig, error) {
-	_, err := r.collection.UpdateMany(
-		ctx,
-		bson.M{"active": true},
-		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now()}},
-	)
-	if err != nil {
-		return nil, err
-	}
+// Create creates a new pack size configuration, with the optional cost of
+// one pack of each size.
+// The deactivation of the previous config and the insert run in a
+// transaction, so concurrent creates cannot leave two configs active.
+func (r *PackSizesRepository) Create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string) (*PackSizeConfig, error) {
+	return r.create(ctx, sizes, costs, createdBy, bson.M{"active": true}, false)
+}
+
+// CreateIfActive creates and activates a configuration like Create, only
+// while activeVersion is the active one. The active configuration is
+// deactivated only if it still has that version, so of two concurrent calls
+// with the same activeVersion only one succeeds. It returns nil otherwise.
+func (r *PackSizesRepository) CreateIfActive(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int) (*PackSizeConfig, error) {
+	return r.create(ctx, sizes, costs, createdBy, bson.M{"active": true, "version": activeVersion}, true)
+}
+
+// maxVersionAttempts bounds the attempts of a write whose version another
+// write took first.
+const maxVersionAttempts = 5
 
+// create stores sizes and costs as the next version after deactivating the
+// configurations matching active. When conditional, it returns nil without
+// storing anything if none matched. Versions are unique: a create losing the
+// next version to a concurrent one retries with the version after it.
+func (r *PackSizesRepository) create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, active bson.M, conditional bool) (*PackSizeConfig, error) {
+	now := timeutil.Now()
 	config := PackSizeConfig{
-		ID:        primitive.NewObjectID(),
-		Sizes:     sizes,
-		Active:    true,
-		Version:   1,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		CreatedBy: createdBy,
-		Metadata:  make(map[string]interface{}),
+		ID:          idgen.NewObjectID(),
+		Sizes:       sizes,
+		Costs:       costs,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		CreatedBy:   createdBy,
+		ActivatedAt: &now,
+		ActivatedBy: createdBy,
+		Metadata:    make(map[string]interface{}),
 	}
 
-	_, err = r.collection.InsertOne(ctx, config)
+	var err error
+	for attempt := 1; attempt <= maxVersionAttempts; attempt++ {
+		err = r.inTransaction(ctx, func(sc mongo.SessionContext) error {
+			version, err := r.nextVersion(sc)
+			if err != nil {
+				return err
+			}
+			config.Version = version
+
+			deactivated, err := r.collection.UpdateMany(
+				sc,
+				active,
+				bson.M{"$set": bson.M{"active": false, "updated_at": timeutil.Now()}},
+				updateComment(sc),
+			)
+			if err != nil {
+				return err
+			}
+			if conditional && deactivated.MatchedCount == 0 {
+				return errActiveVersionChanged
+			}
+
+			_, err = r.collection.InsertOne(sc, config, insertOneComment(sc))
+			return err
+		})
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	if errors.Is(err, errActiveVersionChanged) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -78,38 +161,145 @@ func (r *PackSizesRepository) Create(ctx context.Context, sizes []int, createdBy
 	return &config, nil
 }
 
-// Update updates an existing pack size configuration.
-func (r *PackSizesRepository) Update(ctx context.Context, id primitive.ObjectID, sizes []int, updatedBy string) (*PackSizeConfig, error) {
-	var current PackSizeConfig
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&current)
+// nextVersion returns the version after the highest stored one. Versions
+// increase monotonically across configs so audit entries can be correlated
+// with the configuration that produced them.
+func (r *PackSizesRepository) nextVersion(ctx context.Context) (int, error) {
+	var latest PackSizeConfig
+	err := r.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"version": -1}), findOneComment(ctx)).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return 1, nil
+	}
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	return latest.Version + 1, nil
+}
+
+// inTransaction runs fn in a transaction. Standalone servers have no
+// transactions, so there fn runs in a causally consistent session instead,
+// its writes ordered but not atomic.
+func (r *PackSizesRepository) inTransaction(ctx context.Context, fn func(mongo.SessionContext) error) error {
+	session, err := r.collection.Database().Client().StartSession(options.Session().SetCausalConsistency(true))
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(errCodeIllegalOperation) {
+		return mongo.WithSession(ctx, session, fn)
 	}
+	return err
+}
+
+// Update updates an existing pack size configuration, giving it the next
+// version.
+func (r *PackSizesRepository) Update(ctx context.Context, id primitive.ObjectID, sizes []int, updatedBy string) (*PackSizeConfig, error) {
+	var config PackSizeConfig
+	var err error
+	for attempt := 1; attempt <= maxVersionAttempts; attempt++ {
+		var version int
+		if version, err = r.nextVersion(ctx); err != nil {
+			return nil, err
+		}
 
-	update := bson.M{
-		"$set": bson.M{
+		set := bson.M{
 			"sizes":      sizes,
-			"updated_at": time.Now(),
-			"version":    current.Version + 1,
-		},
+			"updated_at": timeutil.Now(),
+			"version":    version,
+		}
+		if updatedBy != "" {
+			set["updated_by"] = updatedBy
+		}
+
+		err = r.collection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": set},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+			findOneAndUpdateComment(ctx),
+		).Decode(&config)
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
-	if updatedBy != "" {
-		if setDoc, ok := update["$set"].(bson.M); ok {
-			setDoc["updated_by"] = updatedBy
+
+	return &config, nil
+}
+
+// Activate makes the configuration with the given ID the active one,
+// deactivating the others in a transaction as Create does.
+// It returns nil if no configuration has the ID.
+func (r *PackSizesRepository) Activate(ctx context.Context, id primitive.ObjectID, activatedBy string) (*PackSizeConfig, error) {
+	var config *PackSizeConfig
+	err := r.inTransaction(ctx, func(sc mongo.SessionContext) error {
+		config = nil
+		if err := r.collection.FindOne(sc, bson.M{"_id": id}, findOneComment(sc)).Err(); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil
+			}
+			return err
+		}
+
+		now := timeutil.Now()
+		if _, err := r.collection.UpdateMany(
+			sc,
+			bson.M{"active": true, "_id": bson.M{"$ne": id}},
+			bson.M{"$set": bson.M{"active": false, "updated_at": now}},
+			updateComment(sc),
+		); err != nil {
+			return err
+		}
+
+		var activated PackSizeConfig
+		err := r.collection.FindOneAndUpdate(
+			sc,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{
+				"active":       true,
+				"updated_at":   now,
+				"activated_at": now,
+				"activated_by": activatedBy,
+			}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+			findOneAndUpdateComment(sc),
+		).Decode(&activated)
+		if err != nil {
+			return err
 		}
+		config = &activated
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return config, nil
+}
+
+// PreviousVersion returns the configuration with the highest version below
+// version, or nil if there is none.
+func (r *PackSizesRepository) PreviousVersion(ctx context.Context, version int) (*PackSizeConfig, error) {
 	var config PackSizeConfig
-	err = r.collection.FindOneAndUpdate(
+	err := r.collection.FindOne(
 		ctx,
-		bson.M{"_id": id},
-		update,
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
+		bson.M{"version": bson.M{"$lt": version}},
+		options.FindOne().SetSort(bson.M{"version": -1}),
+		findOneComment(ctx),
 	).Decode(&config)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-
 	return &config, nil
 }
 
@@ -120,7 +310,7 @@ func (r *PackSizesRepository) List(ctx context.Context, limit int) ([]PackSizeCo
 		opts.SetLimit(int64(limit))
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts, findComment(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -135,3 +325,24 @@ func (r *PackSizesRepository) List(ctx context.Context, limit int) ([]PackSizeCo
 
 	return configs, nil
 }
+
+// WatchPackSizes implements PackSizesWatcher with a change stream on the
+// pack_sizes collection. Change streams require a replica set or sharded
+// cluster; on a standalone server it returns an error right away.
+func (r *PackSizesRepository) WatchPackSizes(ctx context.Context, fn func()) error {
+	stream, err := r.collection.Watch(ctx, mongo.Pipeline{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stream.Close(context.Background())
+	}()
+
+	for stream.Next(ctx) {
+		fn()
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return stream.Err()
+}
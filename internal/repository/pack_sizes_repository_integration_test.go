@@ -4,11 +4,16 @@ package repository
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/repository/migrations"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestPackSizesRepository_Integration(t *testing.T) {
@@ -31,7 +36,7 @@ func TestPackSizesRepository_Integration(t *testing.T) {
 
 	t.Run("create pack sizes", func(t *testing.T) {
 		sizes := []int{100, 200, 500}
-		config, err := repo.Create(ctx, sizes, "test-user")
+		config, err := repo.Create(ctx, sizes, nil, "test-user")
 		require.NoError(t, err)
 		assert.NotNil(t, config)
 		assert.Equal(t, sizes, config.Sizes)
@@ -55,7 +60,7 @@ func TestPackSizesRepository_Integration(t *testing.T) {
 		require.NotNil(t, oldActive)
 
 		newSizes := []int{250, 500, 1000}
-		newConfig, err := repo.Create(ctx, newSizes, "test-user-2")
+		newConfig, err := repo.Create(ctx, newSizes, nil, "test-user-2")
 		require.NoError(t, err)
 		assert.NotNil(t, newConfig)
 
@@ -64,6 +69,7 @@ func TestPackSizesRepository_Integration(t *testing.T) {
 		require.NotNil(t, active)
 		assert.Equal(t, newSizes, active.Sizes)
 		assert.NotEqual(t, oldActive.ID, active.ID)
+		assert.Equal(t, oldActive.Version+1, newConfig.Version)
 	})
 
 	t.Run("update pack sizes", func(t *testing.T) {
@@ -89,6 +95,153 @@ func TestPackSizesRepository_Integration(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(configs))
 	})
+
+	t.Run("previous version", func(t *testing.T) {
+		active, err := repo.GetActive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, active)
+
+		previous, err := repo.PreviousVersion(ctx, active.Version)
+		require.NoError(t, err)
+		require.NotNil(t, previous)
+		assert.Equal(t, []int{100, 200, 500}, previous.Sizes)
+
+		none, err := repo.PreviousVersion(ctx, previous.Version)
+		require.NoError(t, err)
+		assert.Nil(t, none)
+	})
+
+	t.Run("activate older config", func(t *testing.T) {
+		active, err := repo.GetActive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, active)
+		previous, err := repo.PreviousVersion(ctx, active.Version)
+		require.NoError(t, err)
+		require.NotNil(t, previous)
+
+		activated, err := repo.Activate(ctx, previous.ID, "test-admin")
+		require.NoError(t, err)
+		require.NotNil(t, activated)
+		assert.True(t, activated.Active)
+		assert.Equal(t, previous.Version, activated.Version)
+		assert.Equal(t, "test-admin", activated.ActivatedBy)
+		require.NotNil(t, activated.ActivatedAt)
+
+		current, err := repo.GetActive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, current)
+		assert.Equal(t, previous.ID, current.ID)
+	})
+
+	t.Run("activate unknown config", func(t *testing.T) {
+		activated, err := repo.Activate(ctx, primitive.NewObjectID(), "test-admin")
+		require.NoError(t, err)
+		assert.Nil(t, activated)
+	})
+
+	t.Run("create if active", func(t *testing.T) {
+		active, err := repo.GetActive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, active)
+
+		stale, err := repo.CreateIfActive(ctx, []int{23, 31}, nil, "test-user", active.Version+100)
+		require.NoError(t, err)
+		assert.Nil(t, stale)
+
+		created, err := repo.CreateIfActive(ctx, []int{23, 31, 53}, nil, "test-user", active.Version)
+		require.NoError(t, err)
+		require.NotNil(t, created)
+		assert.True(t, created.Active)
+
+		again, err := repo.CreateIfActive(ctx, []int{23, 31}, nil, "test-user", active.Version)
+		require.NoError(t, err)
+		assert.Nil(t, again, "the expected version is no longer active")
+
+		current, err := repo.GetActive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, current)
+		assert.Equal(t, created.ID, current.ID)
+	})
+}
+
+func TestPackSizesRepository_ConcurrentCreate_Integration(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewPackSizesRepository(db)
+
+	const creates = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, creates)
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := repo.Create(ctx, []int{100 + i, 500}, nil, "test-user")
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+		}
+	}
+	require.Positive(t, created)
+
+	configs, err := repo.List(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, configs, created)
+	versions := make(map[int]bool)
+	active := 0
+	for _, config := range configs {
+		assert.False(t, versions[config.Version], "version %d stored twice", config.Version)
+		versions[config.Version] = true
+		if config.Active {
+			active++
+		}
+	}
+	assert.Equal(t, 1, active)
+}
+
+func TestPackSizesMigration_RenumbersDuplicateVersions_Integration(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	// Drop the unique index to store the duplicates older releases allowed
+	coll := db.Database.Collection("pack_sizes")
+	require.NoError(t, coll.Drop(ctx))
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	_, err := coll.InsertMany(ctx, []interface{}{
+		PackSizeConfig{ID: first, Sizes: []int{250}, Version: 1},
+		PackSizeConfig{ID: second, Sizes: []int{500}, Version: 1},
+		PackSizeConfig{ID: primitive.NewObjectID(), Sizes: []int{1000}, Version: 2, Active: true},
+	})
+	require.NoError(t, err)
+	_, err = db.Database.Collection(migrations.Collection).DeleteMany(ctx, bson.M{"version": bson.M{"$gte": 15}})
+	require.NoError(t, err)
+
+	_, err = db.Migrate(ctx)
+	require.NoError(t, err)
+
+	var renumbered PackSizeConfig
+	require.NoError(t, coll.FindOne(ctx, bson.M{"_id": second}).Decode(&renumbered))
+	assert.Equal(t, 3, renumbered.Version)
+	_, err = coll.InsertOne(ctx, PackSizeConfig{ID: primitive.NewObjectID(), Version: 2})
+	assert.True(t, mongo.IsDuplicateKeyError(err), "versions are unique")
 }
 
 func TestPackSizesRepositoryWithCircuitBreaker_Integration(t *testing.T) {
@@ -107,7 +260,7 @@ func TestPackSizesRepositoryWithCircuitBreaker_Integration(t *testing.T) {
 
 	t.Run("circuit breaker allows successful operations", func(t *testing.T) {
 		sizes := []int{100, 200}
-		config, err := wrappedRepo.Create(ctx, sizes, "test")
+		config, err := wrappedRepo.Create(ctx, sizes, nil, "test")
 		require.NoError(t, err)
 		assert.NotNil(t, config)
 
@@ -143,4 +296,17 @@ func TestPackSizesRepositoryWithCircuitBreaker_Integration(t *testing.T) {
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(configs), 0)
 	})
+
+	t.Run("circuit breaker Activate", func(t *testing.T) {
+		active, err := wrappedRepo.GetActive(ctx)
+		require.NoError(t, err)
+		if active != nil {
+			activated, err := wrappedRepo.Activate(ctx, active.ID, "test-admin")
+			require.NoError(t, err)
+			assert.NotNil(t, activated)
+
+			_, err = wrappedRepo.PreviousVersion(ctx, active.Version)
+			require.NoError(t, err)
+		}
+	})
 }
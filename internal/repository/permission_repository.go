@@ -3,13 +3,14 @@ package repository
 
 import (
 	"context"
-	"time"
 
+	"github.com/guttosm/pack-service/internal/idgen"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // PermissionRepositoryInterface defines the interface for permission repository operations.
@@ -37,20 +38,20 @@ func NewPermissionRepository(db *mongo.Database) *PermissionRepository {
 
 // Create inserts a new permission into the database.
 func (r *PermissionRepository) Create(ctx context.Context, permission *model.Permission) error {
-	permission.CreatedAt = time.Now()
-	permission.UpdatedAt = time.Now()
+	permission.CreatedAt = timeutil.Now()
+	permission.UpdatedAt = timeutil.Now()
 	if permission.ID.IsZero() {
-		permission.ID = primitive.NewObjectID()
+		permission.ID = idgen.NewObjectID()
 	}
-	
-	_, err := r.collection.InsertOne(ctx, permission)
+
+	_, err := r.collection.InsertOne(ctx, permission, insertOneComment(ctx))
 	return err
 }
 
 // FindByID finds a permission by ID.
 func (r *PermissionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Permission, error) {
 	var permission model.Permission
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&permission)
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}, findOneComment(ctx)).Decode(&permission)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -63,7 +64,7 @@ func (r *PermissionRepository) FindByID(ctx context.Context, id primitive.Object
 // FindByResourceAndAction finds a permission by resource and action.
 func (r *PermissionRepository) FindByResourceAndAction(ctx context.Context, resource, action string) (*model.Permission, error) {
 	var permission model.Permission
-	err := r.collection.FindOne(ctx, bson.M{"resource": resource, "action": action}).Decode(&permission)
+	err := r.collection.FindOne(ctx, bson.M{"resource": resource, "action": action}, findOneComment(ctx)).Decode(&permission)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -82,7 +83,7 @@ func (r *PermissionRepository) FindByIDs(ctx context.Context, ids []string) ([]*
 		}
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}}, findComment(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -99,11 +100,12 @@ func (r *PermissionRepository) FindByIDs(ctx context.Context, ids []string) ([]*
 
 // Update updates an existing permission.
 func (r *PermissionRepository) Update(ctx context.Context, permission *model.Permission) error {
-	permission.UpdatedAt = time.Now()
+	permission.UpdatedAt = timeutil.Now()
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": permission.ID},
 		bson.M{"$set": permission},
+		updateComment(ctx),
 	)
 	return err
 }
@@ -113,7 +115,8 @@ func (r *PermissionRepository) Delete(ctx context.Context, id primitive.ObjectID
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": id},
-		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now()}},
+		bson.M{"$set": bson.M{"active": false, "updated_at": timeutil.Now()}},
+		updateComment(ctx),
 	)
 	return err
 }
@@ -121,7 +124,7 @@ func (r *PermissionRepository) Delete(ctx context.Context, id primitive.ObjectID
 // List retrieves permissions with pagination.
 func (r *PermissionRepository) List(ctx context.Context, filter bson.M, limit, skip int64) ([]*model.Permission, error) {
 	opts := options.Find().SetLimit(limit).SetSkip(skip)
-	cursor, err := r.collection.Find(ctx, filter, opts)
+	cursor, err := r.collection.Find(ctx, filter, opts, findComment(ctx))
 	if err != nil {
 		return nil, err
 	}
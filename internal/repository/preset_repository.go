@@ -0,0 +1,113 @@
+// Package repository provides calculation preset data access layer.
+package repository
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrPresetExists is returned when an owner already has a preset with the same name.
+var ErrPresetExists = domainerr.New("preset_exists", http.StatusConflict, i18n.ErrKeyConflict, "preset already exists")
+
+// PresetRepositoryInterface defines the interface for preset repository operations.
+type PresetRepositoryInterface interface {
+	Create(ctx context.Context, preset *model.Preset) error
+	FindByName(ctx context.Context, owner, name string) (*model.Preset, error)
+	ListByOwner(ctx context.Context, owner string) ([]*model.Preset, error)
+	Update(ctx context.Context, preset *model.Preset) error
+	Delete(ctx context.Context, owner, name string) (bool, error)
+}
+
+// PresetRepository implements PresetRepositoryInterface using MongoDB.
+type PresetRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPresetRepository creates a new preset repository.
+func NewPresetRepository(db *mongo.Database) *PresetRepository {
+	return &PresetRepository{
+		collection: db.Collection("presets"),
+	}
+}
+
+// Create inserts a new preset. Names are unique per owner.
+func (r *PresetRepository) Create(ctx context.Context, preset *model.Preset) error {
+	preset.CreatedAt = timeutil.Now()
+	preset.UpdatedAt = preset.CreatedAt
+	if preset.ID.IsZero() {
+		preset.ID = idgen.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, preset, insertOneComment(ctx))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrPresetExists
+	}
+	return err
+}
+
+// FindByName finds an owner's preset by name.
+func (r *PresetRepository) FindByName(ctx context.Context, owner, name string) (*model.Preset, error) {
+	var preset model.Preset
+	err := r.collection.FindOne(ctx, bson.M{"owner": owner, "name": name}, findOneComment(ctx)).Decode(&preset)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// ListByOwner returns an owner's presets sorted by name.
+func (r *PresetRepository) ListByOwner(ctx context.Context, owner string) ([]*model.Preset, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"owner": owner}, opts, findComment(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	presets := make([]*model.Preset, 0)
+	if err := cursor.All(ctx, &presets); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// Update replaces the description, pack sizes and constraints of an existing
+// preset.
+func (r *PresetRepository) Update(ctx context.Context, preset *model.Preset) error {
+	preset.UpdatedAt = timeutil.Now()
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": preset.ID},
+		bson.M{"$set": bson.M{
+			"description": preset.Description,
+			"pack_sizes":  preset.PackSizes,
+			"constraints": preset.Constraints,
+			"updated_at":  preset.UpdatedAt,
+		}},
+		updateComment(ctx),
+	)
+	return err
+}
+
+// Delete removes an owner's preset by name. It reports whether a preset was deleted.
+func (r *PresetRepository) Delete(ctx context.Context, owner, name string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"owner": owner, "name": name}, deleteComment(ctx))
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,60 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetRepository_CRUD(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewPresetRepository(db.Database)
+
+	preset := &model.Preset{Owner: "user-1", Name: "warehouse-a", PackSizes: []int{23, 31, 53}}
+	require.NoError(t, repo.Create(ctx, preset))
+	assert.False(t, preset.ID.IsZero())
+
+	// Names are unique per owner, not globally
+	err := repo.Create(ctx, &model.Preset{Owner: "user-1", Name: "warehouse-a", PackSizes: []int{1}})
+	assert.ErrorIs(t, err, ErrPresetExists)
+	require.NoError(t, repo.Create(ctx, &model.Preset{Owner: "user-2", Name: "warehouse-a", PackSizes: []int{1}}))
+
+	found, err := repo.FindByName(ctx, "user-1", "warehouse-a")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, []int{23, 31, 53}, found.PackSizes)
+
+	missing, err := repo.FindByName(ctx, "user-3", "warehouse-a")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	found.PackSizes = []int{250, 500}
+	found.Description = "updated"
+	require.NoError(t, repo.Update(ctx, found))
+
+	presets, err := repo.ListByOwner(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, presets, 1)
+	assert.Equal(t, []int{250, 500}, presets[0].PackSizes)
+	assert.Equal(t, "updated", presets[0].Description)
+
+	deleted, err := repo.Delete(ctx, "user-1", "warehouse-a")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = repo.Delete(ctx, "user-1", "warehouse-a")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
@@ -10,9 +10,21 @@ import (
 // PackSizesRepositoryInterface defines the interface for pack sizes repository operations.
 type PackSizesRepositoryInterface interface {
 	GetActive(ctx context.Context) (*PackSizeConfig, error)
-	Create(ctx context.Context, sizes []int, createdBy string) (*PackSizeConfig, error)
+	Create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string) (*PackSizeConfig, error)
+	// CreateIfActive creates and activates a configuration like Create, only
+	// while activeVersion is the active one. It returns nil otherwise.
+	CreateIfActive(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int) (*PackSizeConfig, error)
 	Update(ctx context.Context, id primitive.ObjectID, sizes []int, updatedBy string) (*PackSizeConfig, error)
 	List(ctx context.Context, limit int) ([]PackSizeConfig, error)
+	Activate(ctx context.Context, id primitive.ObjectID, activatedBy string) (*PackSizeConfig, error)
+	PreviousVersion(ctx context.Context, version int) (*PackSizeConfig, error)
+}
+
+// PackSizesWatcher streams pack size configuration changes made by any replica.
+type PackSizesWatcher interface {
+	// WatchPackSizes calls fn after every change to the stored configurations
+	// until ctx is done or the stream fails.
+	WatchPackSizes(ctx context.Context, fn func()) error
 }
 
 // LogsRepositoryInterface defines the interface for logs repository operations.
@@ -21,4 +33,11 @@ type LogsRepositoryInterface interface {
 	CreateMany(ctx context.Context, entries []*LogEntryDocument) error
 	Query(ctx context.Context, opts LogQueryOptions) ([]*LogEntryDocument, error)
 	Count(ctx context.Context, opts LogQueryOptions) (int64, error)
+	Each(ctx context.Context, opts LogQueryOptions, fn func(*LogEntryDocument) error) error
+	CalculationsByConfigVersion(ctx context.Context, sinceVersion int) ([]ConfigVersionImpactDocument, error)
+	// DeleteLogs deletes the entries matching opts and returns how many it deleted.
+	DeleteLogs(ctx context.Context, opts LogPurgeOptions) (int64, error)
+	// TrimLogs deletes the oldest entries beyond the newest maxEntries and
+	// returns how many it deleted.
+	TrimLogs(ctx context.Context, maxEntries int64) (int64, error)
 }
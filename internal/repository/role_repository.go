@@ -3,13 +3,14 @@ package repository
 
 import (
 	"context"
-	"time"
 
+	"github.com/guttosm/pack-service/internal/idgen"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // RoleRepositoryInterface defines the interface for role repository operations.
@@ -37,20 +38,20 @@ func NewRoleRepository(db *mongo.Database) *RoleRepository {
 
 // Create inserts a new role into the database.
 func (r *RoleRepository) Create(ctx context.Context, role *model.Role) error {
-	role.CreatedAt = time.Now()
-	role.UpdatedAt = time.Now()
+	role.CreatedAt = timeutil.Now()
+	role.UpdatedAt = timeutil.Now()
 	if role.ID.IsZero() {
-		role.ID = primitive.NewObjectID()
+		role.ID = idgen.NewObjectID()
 	}
-	
-	_, err := r.collection.InsertOne(ctx, role)
+
+	_, err := r.collection.InsertOne(ctx, role, insertOneComment(ctx))
 	return err
 }
 
 // FindByID finds a role by ID.
 func (r *RoleRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Role, error) {
 	var role model.Role
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&role)
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}, findOneComment(ctx)).Decode(&role)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -63,7 +64,7 @@ func (r *RoleRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*
 // FindByName finds a role by name.
 func (r *RoleRepository) FindByName(ctx context.Context, name string) (*model.Role, error) {
 	var role model.Role
-	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&role)
+	err := r.collection.FindOne(ctx, bson.M{"name": name}, findOneComment(ctx)).Decode(&role)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -82,7 +83,7 @@ func (r *RoleRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.
 		}
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}}, findComment(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -99,11 +100,12 @@ func (r *RoleRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.
 
 // Update updates an existing role.
 func (r *RoleRepository) Update(ctx context.Context, role *model.Role) error {
-	role.UpdatedAt = time.Now()
+	role.UpdatedAt = timeutil.Now()
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": role.ID},
 		bson.M{"$set": role},
+		updateComment(ctx),
 	)
 	return err
 }
@@ -113,7 +115,8 @@ func (r *RoleRepository) Delete(ctx context.Context, id primitive.ObjectID) erro
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": id},
-		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now()}},
+		bson.M{"$set": bson.M{"active": false, "updated_at": timeutil.Now()}},
+		updateComment(ctx),
 	)
 	return err
 }
@@ -121,7 +124,7 @@ func (r *RoleRepository) Delete(ctx context.Context, id primitive.ObjectID) erro
 // List retrieves roles with pagination.
 func (r *RoleRepository) List(ctx context.Context, filter bson.M, limit, skip int64) ([]*model.Role, error) {
 	opts := options.Find().SetLimit(limit).SetSkip(skip)
-	cursor, err := r.collection.Find(ctx, filter, opts)
+	cursor, err := r.collection.Find(ctx, filter, opts, findComment(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SigningKeyRepositoryInterface defines the interface for JWT signing key repository operations.
+type SigningKeyRepositoryInterface interface {
+	List(ctx context.Context) ([]*model.SigningKey, error)
+	Create(ctx context.Context, key *model.SigningKey) error
+	Retire(ctx context.Context, kid string, at time.Time) (bool, error)
+}
+
+// SigningKeyRepository implements SigningKeyRepositoryInterface using MongoDB.
+type SigningKeyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSigningKeyRepository creates a new signing key repository.
+func NewSigningKeyRepository(db *mongo.Database) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		collection: db.Collection("signing_keys"),
+	}
+}
+
+// List returns every key, retired ones included, newest first.
+func (r *SigningKeyRepository) List(ctx context.Context) ([]*model.SigningKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "kid", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	keys := make([]*model.SigningKey, 0)
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Create stores a new key.
+func (r *SigningKeyRepository) Create(ctx context.Context, key *model.SigningKey) error {
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = timeutil.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+// Retire marks the key kid retired at at. It reports whether an active key
+// was retired.
+func (r *SigningKeyRepository) Retire(ctx context.Context, kid string, at time.Time) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"kid": kid, "retired_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"retired_at": at}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,49 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestSigningKeyRepository(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewSigningKeyRepository(db.Database)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repo.Create(ctx, &model.SigningKey{KID: "k1", Secret: []byte("s1"), RefreshSecret: []byte("r1"), CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, repo.Create(ctx, &model.SigningKey{KID: "k2", Secret: []byte("s2"), RefreshSecret: []byte("r2"), CreatedAt: now}))
+
+	keys, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "k2", keys[0].KID, "newest first")
+	assert.Equal(t, []byte("s2"), keys[0].Secret)
+	assert.Equal(t, []byte("r2"), keys[0].RefreshSecret)
+	assert.True(t, keys[1].Active())
+
+	retired, err := repo.Retire(ctx, "k1", now)
+	require.NoError(t, err)
+	assert.True(t, retired)
+	retired, err = repo.Retire(ctx, "k1", now)
+	require.NoError(t, err)
+	assert.False(t, retired, "retired keys stay retired at their first date")
+
+	keys, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, keys[1].RetiredAt)
+	assert.True(t, keys[1].RetiredAt.Equal(now))
+}
@@ -0,0 +1,183 @@
+// Package repository provides a Redis cache for token blacklist lookups.
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// DefaultBlacklistKeyPrefix is used when no key prefix is configured.
+const DefaultBlacklistKeyPrefix = "pack-service:blacklist"
+
+// DefaultBlacklistLookupTTL is how long answers read from MongoDB are cached by default.
+const DefaultBlacklistLookupTTL = 30 * time.Second
+
+const defaultBlacklistOperationTimeout = 100 * time.Millisecond
+
+// Values stored per token; a missing key means the answer is not cached.
+const (
+	blacklistedValue    = "1"
+	notBlacklistedValue = "0"
+)
+
+// TokenBlacklistCache caches token blacklist lookups.
+type TokenBlacklistCache interface {
+	// Get returns whether the token is blacklisted, and false for found when
+	// the answer is not cached.
+	Get(ctx context.Context, tokenString string) (blacklisted, found bool, err error)
+	// SetBlacklisted records a blacklisted token until it expires.
+	SetBlacklisted(ctx context.Context, tokenString string, expiresAt time.Time) error
+	// SetNotBlacklisted records a token that is not blacklisted for ttl,
+	// unless it has been blacklisted in the meantime.
+	SetNotBlacklisted(ctx context.Context, tokenString string, ttl time.Duration) error
+}
+
+// RedisTokenBlacklistCache stores blacklist lookups in Redis, shared by all
+// replicas. Keys are token hashes, so raw tokens never leave MongoDB.
+type RedisTokenBlacklistCache struct {
+	client  redis.UniversalClient
+	prefix  string
+	timeout time.Duration
+}
+
+// NewRedisTokenBlacklistCache creates a blacklist cache on client. An empty
+// prefix uses DefaultBlacklistKeyPrefix and a zero timeout 100ms.
+func NewRedisTokenBlacklistCache(client redis.UniversalClient, prefix string, timeout time.Duration) *RedisTokenBlacklistCache {
+	if prefix == "" {
+		prefix = DefaultBlacklistKeyPrefix
+	}
+	if timeout <= 0 {
+		timeout = defaultBlacklistOperationTimeout
+	}
+	return &RedisTokenBlacklistCache{client: client, prefix: prefix, timeout: timeout}
+}
+
+func (c *RedisTokenBlacklistCache) key(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return c.prefix + ":" + hex.EncodeToString(sum[:])
+}
+
+// Get implements TokenBlacklistCache.
+func (c *RedisTokenBlacklistCache) Get(ctx context.Context, tokenString string) (bool, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	value, err := c.client.Get(ctx, c.key(tokenString)).Result()
+	metrics.RecordCacheBackendLatency("redis", "blacklist_get", time.Since(start))
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return false, false, nil
+	case err != nil:
+		return false, false, err
+	}
+	return value == blacklistedValue, true, nil
+}
+
+// SetBlacklisted implements TokenBlacklistCache. Already expired tokens are
+// not stored.
+func (c *RedisTokenBlacklistCache) SetBlacklisted(ctx context.Context, tokenString string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.client.Set(ctx, c.key(tokenString), blacklistedValue, ttl).Err()
+	metrics.RecordCacheBackendLatency("redis", "blacklist_set", time.Since(start))
+	return err
+}
+
+// SetNotBlacklisted implements TokenBlacklistCache. It only sets missing
+// keys, so a lookup racing with a logout cannot overwrite the blacklist entry.
+func (c *RedisTokenBlacklistCache) SetNotBlacklisted(ctx context.Context, tokenString string, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.client.SetArgs(ctx, c.key(tokenString), notBlacklistedValue, redis.SetArgs{Mode: "NX", TTL: ttl}).Err()
+	metrics.RecordCacheBackendLatency("redis", "blacklist_set", time.Since(start))
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// TokenRepositoryWithBlacklistCache answers IsBlacklisted from a cache and
+// falls back to the wrapped repository on a miss or cache error. Tokens
+// blacklisted through Create are cached until they expire; answers read from
+// the repository for lookupTTL, which bounds how long a token stays usable
+// if caching its logout fails.
+type TokenRepositoryWithBlacklistCache struct {
+	TokenRepositoryInterface
+	cache     TokenBlacklistCache
+	lookupTTL time.Duration
+}
+
+// NewTokenRepositoryWithBlacklistCache wraps repo with cache. A zero
+// lookupTTL uses DefaultBlacklistLookupTTL.
+func NewTokenRepositoryWithBlacklistCache(repo TokenRepositoryInterface, cache TokenBlacklistCache, lookupTTL time.Duration) *TokenRepositoryWithBlacklistCache {
+	if lookupTTL <= 0 {
+		lookupTTL = DefaultBlacklistLookupTTL
+	}
+	return &TokenRepositoryWithBlacklistCache{
+		TokenRepositoryInterface: repo,
+		cache:                    cache,
+		lookupTTL:                lookupTTL,
+	}
+}
+
+// Create stores the token and, for blacklist entries, caches it.
+func (r *TokenRepositoryWithBlacklistCache) Create(ctx context.Context, token *model.Token) error {
+	if err := r.TokenRepositoryInterface.Create(ctx, token); err != nil {
+		return err
+	}
+	if token.Type == "blacklist" {
+		if err := r.cache.SetBlacklisted(ctx, token.Token, token.ExpiresAt); err != nil {
+			log.Warn().Err(err).Msg("Failed to cache blacklisted token")
+		}
+	}
+	return nil
+}
+
+// IsBlacklisted checks the cache first and MongoDB on a miss.
+func (r *TokenRepositoryWithBlacklistCache) IsBlacklisted(ctx context.Context, tokenString string) (bool, error) {
+	blacklisted, found, err := r.cache.Get(ctx, tokenString)
+	switch {
+	case err != nil:
+		metrics.RecordCacheOperation("blacklist_get", "error")
+		log.Debug().Err(err).Msg("Token blacklist cache lookup failed")
+	case found:
+		metrics.RecordCacheOperation("blacklist_get", "hit")
+		return blacklisted, nil
+	default:
+		metrics.RecordCacheOperation("blacklist_get", "miss")
+	}
+
+	blacklisted, err = r.TokenRepositoryInterface.IsBlacklisted(ctx, tokenString)
+	if err != nil {
+		return false, err
+	}
+	if blacklisted {
+		err = r.cache.SetBlacklisted(ctx, tokenString, time.Now().Add(r.lookupTTL))
+	} else {
+		err = r.cache.SetNotBlacklisted(ctx, tokenString, r.lookupTTL)
+	}
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to cache token blacklist lookup")
+	}
+	return blacklisted, nil
+}
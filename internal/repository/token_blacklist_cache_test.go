@@ -0,0 +1,130 @@
+//go:build !integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// fakeTokenRepo serves blacklist lookups from a map and counts them.
+type fakeTokenRepo struct {
+	TokenRepositoryInterface
+	blacklisted map[string]bool
+	lookups     int
+	err         error
+}
+
+func (r *fakeTokenRepo) Create(_ context.Context, token *model.Token) error {
+	if r.err != nil {
+		return r.err
+	}
+	if token.Type == "blacklist" {
+		r.blacklisted[token.Token] = true
+	}
+	return nil
+}
+
+func (r *fakeTokenRepo) IsBlacklisted(_ context.Context, tokenString string) (bool, error) {
+	r.lookups++
+	return r.blacklisted[tokenString], r.err
+}
+
+func newBlacklistCacheTest(t *testing.T) (*miniredis.Miniredis, *fakeTokenRepo, *TokenRepositoryWithBlacklistCache) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	inner := &fakeTokenRepo{blacklisted: map[string]bool{}}
+	cache := NewRedisTokenBlacklistCache(client, "", 0)
+	return mr, inner, NewTokenRepositoryWithBlacklistCache(inner, cache, time.Minute)
+}
+
+func TestTokenRepositoryWithBlacklistCache_CachesLookups(t *testing.T) {
+	_, inner, repo := newBlacklistCacheTest(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		blacklisted, err := repo.IsBlacklisted(ctx, "valid-token")
+		require.NoError(t, err)
+		assert.False(t, blacklisted)
+	}
+	assert.Equal(t, 1, inner.lookups, "only the first lookup reaches MongoDB")
+
+	inner.blacklisted["old-token"] = true
+	for i := 0; i < 2; i++ {
+		blacklisted, err := repo.IsBlacklisted(ctx, "old-token")
+		require.NoError(t, err)
+		assert.True(t, blacklisted)
+	}
+	assert.Equal(t, 2, inner.lookups)
+}
+
+func TestTokenRepositoryWithBlacklistCache_LogoutOverridesCachedLookup(t *testing.T) {
+	mr, inner, repo := newBlacklistCacheTest(t)
+	ctx := context.Background()
+
+	blacklisted, err := repo.IsBlacklisted(ctx, "token")
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	expiresAt := time.Now().Add(15 * time.Minute)
+	require.NoError(t, repo.Create(ctx, &model.Token{Token: "token", Type: "blacklist", ExpiresAt: expiresAt}))
+
+	blacklisted, err = repo.IsBlacklisted(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+	assert.Equal(t, 1, inner.lookups)
+
+	key := repo.cache.(*RedisTokenBlacklistCache).key("token")
+	assert.NotContains(t, key, "token", "raw tokens are not stored in Redis")
+	assert.InDelta(t, 15*time.Minute, mr.TTL(key), float64(time.Second))
+}
+
+func TestTokenRepositoryWithBlacklistCache_LookupDoesNotOverwriteBlacklist(t *testing.T) {
+	_, _, repo := newBlacklistCacheTest(t)
+	ctx := context.Background()
+	cache := repo.cache
+
+	require.NoError(t, cache.SetBlacklisted(ctx, "token", time.Now().Add(time.Minute)))
+	require.NoError(t, cache.SetNotBlacklisted(ctx, "token", time.Minute))
+
+	blacklisted, found, err := cache.Get(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, blacklisted)
+}
+
+func TestTokenRepositoryWithBlacklistCache_FallsBackWhenRedisIsDown(t *testing.T) {
+	mr, inner, repo := newBlacklistCacheTest(t)
+	ctx := context.Background()
+	inner.blacklisted["token"] = true
+	mr.Close()
+
+	blacklisted, err := repo.IsBlacklisted(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+
+	// Storing the token still succeeds; only caching it fails
+	require.NoError(t, repo.Create(ctx, &model.Token{Token: "other", Type: "blacklist", ExpiresAt: time.Now().Add(time.Minute)}))
+	assert.Equal(t, 1, inner.lookups)
+}
+
+func TestTokenRepositoryWithBlacklistCache_RepositoryErrors(t *testing.T) {
+	_, inner, repo := newBlacklistCacheTest(t)
+	inner.err = errors.New("mongo down")
+
+	_, err := repo.IsBlacklisted(context.Background(), "token")
+	assert.Error(t, err)
+	assert.Error(t, repo.Create(context.Background(), &model.Token{Token: "token", Type: "blacklist"}))
+}
@@ -3,14 +3,20 @@ package repository
 
 import (
 	"context"
-	"time"
+	"errors"
 
+	"github.com/guttosm/pack-service/internal/idgen"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
+// ErrTokenExists is returned when a token string is already stored.
+var ErrTokenExists = errors.New("token already exists")
+
 // TokenRepositoryInterface defines the interface for token repository operations.
 type TokenRepositoryInterface interface {
 	Create(ctx context.Context, token *model.Token) error
@@ -19,37 +25,54 @@ type TokenRepositoryInterface interface {
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	DeleteByToken(ctx context.Context, tokenString string) error
 	DeleteByUserID(ctx context.Context, userID primitive.ObjectID, tokenType string) error
+	// DeleteAllByUserID deletes every token of a user, of any type, and
+	// returns how many it deleted.
+	DeleteAllByUserID(ctx context.Context, userID primitive.ObjectID) (int64, error)
 	IsBlacklisted(ctx context.Context, tokenString string) (bool, error)
-	CleanupExpired(ctx context.Context) error
+	// CleanupExpired deletes the tokens past their expiry and returns how
+	// many it deleted.
+	CleanupExpired(ctx context.Context) (int64, error)
+	// FindLegacyRefreshTokens returns up to limit refresh tokens stored in
+	// plaintext, oldest first.
+	FindLegacyRefreshTokens(ctx context.Context, limit int) ([]*model.Token, error)
+	// CountLegacyRefreshTokens counts the refresh tokens stored in plaintext.
+	CountLegacyRefreshTokens(ctx context.Context) (int64, error)
+	// UpdateToken replaces the stored token string of a token. It returns
+	// ErrTokenExists when another token has that string.
+	UpdateToken(ctx context.Context, id primitive.ObjectID, tokenString string) error
 }
 
 // TokenRepository implements TokenRepositoryInterface using MongoDB.
 type TokenRepository struct {
 	collection *mongo.Collection
+	hedge      hedge
 }
 
-// NewTokenRepository creates a new token repository.
-func NewTokenRepository(db *mongo.Database) *TokenRepository {
+// NewTokenRepository creates a new token repository. WithHedgedReads applies
+// to IsBlacklisted.
+func NewTokenRepository(db *mongo.Database, opts ...ReadOption) *TokenRepository {
+	collection := db.Collection("tokens")
 	return &TokenRepository{
-		collection: db.Collection("tokens"),
+		collection: collection,
+		hedge:      newHedge(collection, opts),
 	}
 }
 
 // Create inserts a new token into the database.
 func (r *TokenRepository) Create(ctx context.Context, token *model.Token) error {
-	token.CreatedAt = time.Now()
+	token.CreatedAt = timeutil.Now()
 	if token.ID.IsZero() {
-		token.ID = primitive.NewObjectID()
+		token.ID = idgen.NewObjectID()
 	}
-	
-	_, err := r.collection.InsertOne(ctx, token)
+
+	_, err := r.collection.InsertOne(ctx, token, insertOneComment(ctx))
 	return err
 }
 
 // FindByToken finds a token by token string.
 func (r *TokenRepository) FindByToken(ctx context.Context, tokenString string) (*model.Token, error) {
 	var token model.Token
-	err := r.collection.FindOne(ctx, bson.M{"token": tokenString}).Decode(&token)
+	err := r.collection.FindOne(ctx, bson.M{"token": tokenString}, findOneComment(ctx)).Decode(&token)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -62,7 +85,7 @@ func (r *TokenRepository) FindByToken(ctx context.Context, tokenString string) (
 // FindByUserID finds all tokens for a user by type.
 func (r *TokenRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID, tokenType string) ([]*model.Token, error) {
 	filter := bson.M{"user_id": userID, "type": tokenType}
-	cursor, err := r.collection.Find(ctx, filter)
+	cursor, err := r.collection.Find(ctx, filter, findComment(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -79,38 +102,93 @@ func (r *TokenRepository) FindByUserID(ctx context.Context, userID primitive.Obj
 
 // Delete deletes a token by ID.
 func (r *TokenRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}, deleteComment(ctx))
 	return err
 }
 
 // DeleteByToken deletes a token by token string.
 func (r *TokenRepository) DeleteByToken(ctx context.Context, tokenString string) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"token": tokenString})
+	_, err := r.collection.DeleteOne(ctx, bson.M{"token": tokenString}, deleteComment(ctx))
 	return err
 }
 
 // DeleteByUserID deletes all tokens for a user by type.
 func (r *TokenRepository) DeleteByUserID(ctx context.Context, userID primitive.ObjectID, tokenType string) error {
-	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID, "type": tokenType})
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID, "type": tokenType}, deleteComment(ctx))
 	return err
 }
 
+// DeleteAllByUserID deletes all tokens for a user, refresh and blacklist.
+func (r *TokenRepository) DeleteAllByUserID(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}, deleteComment(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
 // IsBlacklisted checks if a token is blacklisted.
 func (r *TokenRepository) IsBlacklisted(ctx context.Context, tokenString string) (bool, error) {
-	count, err := r.collection.CountDocuments(ctx, bson.M{
-		"token": tokenString,
-		"type":  "blacklist",
+	return hedgedRead(ctx, r.hedge, r.collection, func(ctx context.Context, coll *mongo.Collection) (bool, error) {
+		count, err := coll.CountDocuments(ctx, bson.M{
+			"token": tokenString,
+			"type":  "blacklist",
+		})
+		if err != nil {
+			return false, err
+		}
+		return count > 0, nil
 	})
+}
+
+// CleanupExpired removes expired tokens from the database. The TTL index on
+// expires_at deletes them too, but only when MongoDB's TTL monitor next runs.
+func (r *TokenRepository) CleanupExpired(ctx context.Context) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{
+		"expires_at": bson.M{"$lt": timeutil.Now()},
+	}, deleteComment(ctx))
 	if err != nil {
-		return false, err
+		return 0, err
 	}
-	return count > 0, nil
+	return result.DeletedCount, nil
 }
 
-// CleanupExpired removes expired tokens from the database.
-func (r *TokenRepository) CleanupExpired(ctx context.Context) error {
-	_, err := r.collection.DeleteMany(ctx, bson.M{
-		"expires_at": bson.M{"$lt": time.Now()},
-	})
+// legacyRefreshTokenFilter matches refresh tokens stored before hashing.
+func legacyRefreshTokenFilter() bson.M {
+	return bson.M{
+		"type":  "refresh",
+		"token": bson.M{"$not": primitive.Regex{Pattern: "^" + model.HashedTokenPrefix}},
+	}
+}
+
+// FindLegacyRefreshTokens returns up to limit refresh tokens stored in plaintext.
+func (r *TokenRepository) FindLegacyRefreshTokens(ctx context.Context, limit int) ([]*model.Token, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, legacyRefreshTokenFilter(), opts, findComment(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	var tokens []*model.Token
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// CountLegacyRefreshTokens counts the refresh tokens stored in plaintext.
+func (r *TokenRepository) CountLegacyRefreshTokens(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, legacyRefreshTokenFilter(), countComment(ctx))
+}
+
+// UpdateToken replaces the stored token string of a token.
+func (r *TokenRepository) UpdateToken(ctx context.Context, id primitive.ObjectID, tokenString string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"token": tokenString}}, updateComment(ctx))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrTokenExists
+	}
 	return err
 }
@@ -320,6 +320,30 @@ func TestTokenRepository_DeleteByUserID(t *testing.T) {
 	}
 }
 
+func TestTokenRepository_DeleteAllByUserID(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := setupTestDBFromSharedContainer(t)
+	defer func() {
+		require.NoError(t, db.Close(ctx))
+	}()
+
+	repo := NewTokenRepository(db.Database)
+	userID, otherID := primitive.NewObjectID(), primitive.NewObjectID()
+	expiresAt := time.Now().Add(24 * time.Hour)
+	require.NoError(t, repo.Create(ctx, &model.Token{UserID: userID, Token: "refresh-1", Type: "refresh", ExpiresAt: expiresAt}))
+	require.NoError(t, repo.Create(ctx, &model.Token{UserID: userID, Token: "access-1", Type: "blacklist", ExpiresAt: expiresAt}))
+	require.NoError(t, repo.Create(ctx, &model.Token{UserID: otherID, Token: "refresh-2", Type: "refresh", ExpiresAt: expiresAt}))
+
+	deleted, err := repo.DeleteAllByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	tokens, err := repo.FindByUserID(ctx, otherID, "refresh")
+	require.NoError(t, err)
+	assert.Len(t, tokens, 1, "other users' tokens are kept")
+}
+
 func TestTokenRepository_CleanupExpired(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -367,12 +391,14 @@ func TestTokenRepository_CleanupExpired(t *testing.T) {
 			repo := NewTokenRepository(db.Database)
 			tt.setupDB(t, repo)
 
-			err := repo.CleanupExpired(ctx)
+			deleted, err := repo.CleanupExpired(ctx)
 
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				// The TTL monitor may have deleted the expired token first
+				assert.LessOrEqual(t, deleted, int64(1))
 
 				// Verify expired token is deleted
 				expiredToken, _ := repo.FindByToken(ctx, "expired-token")
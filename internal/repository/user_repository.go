@@ -3,18 +3,48 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/idgen"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
+// ErrUserExists is returned when a user with the same email or username already exists.
+// It is derived from the unique indexes on the users collection, so it also covers
+// concurrent inserts that both passed an application-level existence check.
+var ErrUserExists = domainerr.New("user_exists", http.StatusConflict, i18n.ErrKeyUserExists, "user already exists")
+
+// UserWriteError reports the user a CreateMany failed on.
+type UserWriteError struct {
+	// Index is the position of the user in the slice given to CreateMany.
+	Index int
+	Err   error
+}
+
+func (e *UserWriteError) Error() string {
+	return fmt.Sprintf("user %d: %v", e.Index, e.Err)
+}
+
+func (e *UserWriteError) Unwrap() error {
+	return e.Err
+}
+
 // UserRepositoryInterface defines the interface for user repository operations.
 type UserRepositoryInterface interface {
 	Create(ctx context.Context, user *model.User) error
+	// CreateMany inserts users all or none, returning a *UserWriteError
+	// when one of them cannot be inserted.
+	CreateMany(ctx context.Context, users []*model.User) error
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
 	FindByEmailForAuth(ctx context.Context, email string) (*model.User, error)
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
@@ -22,37 +52,123 @@ type UserRepositoryInterface interface {
 	FindByIDMinimal(ctx context.Context, id primitive.ObjectID) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
+	// Restore reactivates a soft deleted user.
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	// HardDelete removes the user for good when it was soft deleted at or
+	// before deletedBefore, and reports whether it did.
+	HardDelete(ctx context.Context, id primitive.ObjectID, deletedBefore time.Time) (bool, error)
 	List(ctx context.Context, filter bson.M, limit, skip int64) ([]*model.User, error)
+	Count(ctx context.Context, filter bson.M) (int64, error)
+	// IncrementTokenVersion increments the user's token version and returns the new value.
+	IncrementTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error)
+	// GetTokenVersion returns the user's token version, zero for unknown users.
+	GetTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error)
+	// RecordLogin records a successful login as the user's last one and adds
+	// it to their recent logins.
+	RecordLogin(ctx context.Context, id primitive.ObjectID, login model.LoginRecord) error
+}
+
+// TokenVersionWatcher streams token version changes made by any replica.
+type TokenVersionWatcher interface {
+	// WatchTokenVersions calls fn for every token version change until ctx is
+	// done or the stream fails.
+	WatchTokenVersions(ctx context.Context, fn func(id primitive.ObjectID, version int)) error
 }
 
 // UserRepository implements UserRepositoryInterface using MongoDB.
 type UserRepository struct {
 	collection *mongo.Collection
+	hedge      hedge
 }
 
-// NewUserRepository creates a new user repository.
-func NewUserRepository(db *mongo.Database) *UserRepository {
+// NewUserRepository creates a new user repository. WithHedgedReads applies
+// to the lookups by ID.
+func NewUserRepository(db *mongo.Database, opts ...ReadOption) *UserRepository {
+	collection := db.Collection("users")
 	return &UserRepository{
-		collection: db.Collection("users"),
+		collection: collection,
+		hedge:      newHedge(collection, opts),
 	}
 }
 
 // Create inserts a new user into the database.
 func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
-	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
+	user.CreatedAt = timeutil.Now()
+	user.UpdatedAt = timeutil.Now()
 	if user.ID.IsZero() {
-		user.ID = primitive.NewObjectID()
+		user.ID = idgen.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, user, insertOneComment(ctx))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrUserExists
 	}
-	
-	_, err := r.collection.InsertOne(ctx, user)
 	return err
 }
 
+// CreateMany inserts users in a transaction, so that either all of them are
+// created or none is. Standalone servers have no transactions: there the
+// users are inserted in order and the ones inserted are deleted again when
+// one fails, which concurrent readers may briefly observe.
+func (r *UserRepository) CreateMany(ctx context.Context, users []*model.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	now := timeutil.Now()
+	docs := make([]interface{}, len(users))
+	ids := make([]primitive.ObjectID, len(users))
+	for i, user := range users {
+		user.CreatedAt = now
+		user.UpdatedAt = now
+		if user.ID.IsZero() {
+			user.ID = idgen.NewObjectID()
+		}
+		docs[i] = user
+		ids[i] = user.ID
+	}
+
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return r.collection.InsertMany(sc, docs, insertManyComment(sc))
+	})
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(errCodeIllegalOperation) {
+		if _, err = r.collection.InsertMany(ctx, docs, insertManyComment(ctx)); err != nil {
+			if _, cleanupErr := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, deleteComment(ctx)); cleanupErr != nil {
+				return errors.Join(userWriteError(err), cleanupErr)
+			}
+		}
+	}
+	return userWriteError(err)
+}
+
+// errCodeIllegalOperation is the server error a standalone server answers
+// transactions with.
+const errCodeIllegalOperation = 20
+
+// userWriteError maps the first failed insert of a CreateMany to a
+// *UserWriteError, reporting duplicates as ErrUserExists.
+func userWriteError(err error) error {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+		return err
+	}
+	first := bulkErr.WriteErrors[0]
+	if mongo.IsDuplicateKeyError(first) {
+		return &UserWriteError{Index: first.Index, Err: ErrUserExists}
+	}
+	return &UserWriteError{Index: first.Index, Err: first}
+}
+
 // FindByEmail finds a user by email address (returns all fields).
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, bson.M{"email": email}, findOneComment(ctx)).Decode(&user)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -67,18 +183,19 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.
 func (r *UserRepository) FindByEmailForAuth(ctx context.Context, email string) (*model.User, error) {
 	// Projection for auth: only fields needed for authentication
 	projection := bson.M{
-		"_id":      1,
-		"email":    1,
-		"password": 1,
-		"active":   1,
-		"roles":    1,
-		"name":     1,
-		"username": 1,
+		"_id":           1,
+		"email":         1,
+		"password":      1,
+		"active":        1,
+		"roles":         1,
+		"name":          1,
+		"username":      1,
+		"token_version": 1,
 	}
 	opts := options.FindOne().SetProjection(projection)
 
 	var user model.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}, opts).Decode(&user)
+	err := r.collection.FindOne(ctx, bson.M{"email": email}, opts, findOneComment(ctx)).Decode(&user)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -91,7 +208,7 @@ func (r *UserRepository) FindByEmailForAuth(ctx context.Context, email string) (
 // FindByUsername finds a user by username.
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	err := r.collection.FindOne(ctx, bson.M{"username": username}, findOneComment(ctx)).Decode(&user)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -103,15 +220,17 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 
 // FindByID finds a user by ID (returns all fields).
 func (r *UserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
-	var user model.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
-	if err == mongo.ErrNoDocuments {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return hedgedRead(ctx, r.hedge, r.collection, func(ctx context.Context, coll *mongo.Collection) (*model.User, error) {
+		var user model.User
+		err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &user, nil
+	})
 }
 
 // FindByIDMinimal finds a user by ID with minimal fields for display.
@@ -130,42 +249,98 @@ func (r *UserRepository) FindByIDMinimal(ctx context.Context, id primitive.Objec
 	}
 	opts := options.FindOne().SetProjection(projection)
 
-	var user model.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}, opts).Decode(&user)
-	if err == mongo.ErrNoDocuments {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return hedgedRead(ctx, r.hedge, r.collection, func(ctx context.Context, coll *mongo.Collection) (*model.User, error) {
+		var user model.User
+		err := coll.FindOne(ctx, bson.M{"_id": id}, opts).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &user, nil
+	})
 }
 
-// Update updates an existing user.
+// Update updates an existing user. The token version and last login are
+// left untouched, so saving a user read before a revocation or login cannot
+// undo it.
 func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = timeutil.Now()
+	fields := *user
+	fields.TokenVersion = 0  // omitted from $set
+	fields.LastLoginAt = nil // logins are only written by RecordLogin
+	fields.LastLoginIP = ""
+	fields.RecentLogins = nil
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": user.ID},
-		bson.M{"$set": user},
+		bson.M{"$set": &fields},
+		updateComment(ctx),
 	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrUserExists
+	}
 	return err
 }
 
-// Delete soft deletes a user by setting active to false.
+// Delete soft deletes a user by setting active to false. The time of the
+// first deletion is kept as deleted_at.
 func (r *UserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	now := timeutil.Now()
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": id},
-		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now()}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"active":     false,
+			"updated_at": now,
+			"deleted_at": bson.M{"$ifNull": bson.A{"$deleted_at", now}},
+		}}}},
+		updateComment(ctx),
 	)
 	return err
 }
 
-// List retrieves users with pagination.
+// Restore reactivates a soft deleted user and clears deleted_at.
+func (r *UserRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$set":   bson.M{"active": true, "updated_at": timeutil.Now()},
+			"$unset": bson.M{"deleted_at": ""},
+		},
+		updateComment(ctx),
+	)
+	return err
+}
+
+// HardDelete deletes the user when it is soft deleted and was deleted at or
+// before deletedBefore. Users deactivated before deleted_at was recorded
+// count from their last update. The check and the deletion are one
+// operation, so a user restored meanwhile is kept.
+func (r *UserRepository) HardDelete(ctx context.Context, id primitive.ObjectID, deletedBefore time.Time) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id":    id,
+		"active": false,
+		"$or": bson.A{
+			bson.M{"deleted_at": bson.M{"$lte": deletedBefore}},
+			bson.M{"deleted_at": nil, "updated_at": bson.M{"$lte": deletedBefore}},
+		},
+	}, deleteComment(ctx))
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// List retrieves users with pagination, newest first.
 func (r *UserRepository) List(ctx context.Context, filter bson.M, limit, skip int64) ([]*model.User, error) {
-	opts := options.Find().SetLimit(limit).SetSkip(skip)
-	cursor, err := r.collection.Find(ctx, filter, opts)
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts, findComment(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -179,3 +354,97 @@ func (r *UserRepository) List(ctx context.Context, filter bson.M, limit, skip in
 	}
 	return users, nil
 }
+
+// Count returns the number of users matching filter.
+func (r *UserRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter, countComment(ctx))
+}
+
+// IncrementTokenVersion atomically increments the user's token version.
+func (r *UserRepository) IncrementTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error) {
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"token_version": 1})
+
+	var user model.User
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"token_version": 1}, "$set": bson.M{"updated_at": timeutil.Now()}},
+		opts,
+		findOneAndUpdateComment(ctx),
+	).Decode(&user)
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+// RecordLogin sets the user's last login and pushes it onto their recent
+// logins, keeping the latest model.MaxRecentLogins.
+func (r *UserRepository) RecordLogin(ctx context.Context, id primitive.ObjectID, login model.LoginRecord) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"last_login_at": login.At, "last_login_ip": login.IP},
+		"$push": bson.M{"recent_logins": bson.M{
+			"$each":     bson.A{login},
+			"$position": 0,
+			"$slice":    model.MaxRecentLogins,
+		}},
+	}, updateComment(ctx))
+	return err
+}
+
+// GetTokenVersion reads only the user's token version.
+func (r *UserRepository) GetTokenVersion(ctx context.Context, id primitive.ObjectID) (int, error) {
+	opts := options.FindOne().SetProjection(bson.M{"token_version": 1})
+
+	var user model.User
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}, opts, findOneComment(ctx)).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+// WatchTokenVersions implements TokenVersionWatcher with a change stream on
+// the users collection. Change streams require a replica set or sharded
+// cluster; on a standalone server it returns an error right away.
+func (r *UserRepository) WatchTokenVersions(ctx context.Context, fn func(id primitive.ObjectID, version int)) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"operationType": "update",
+			"updateDescription.updatedFields.token_version": bson.M{"$exists": true},
+		}}},
+	}
+	stream, err := r.collection.Watch(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stream.Close(context.Background())
+	}()
+
+	for stream.Next(ctx) {
+		var event struct {
+			DocumentKey struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+			UpdateDescription struct {
+				UpdatedFields struct {
+					TokenVersion int `bson:"token_version"`
+				} `bson:"updatedFields"`
+			} `bson:"updateDescription"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+		fn(event.DocumentKey.ID, event.UpdateDescription.UpdatedFields.TokenVersion)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return stream.Err()
+}
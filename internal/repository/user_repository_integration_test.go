@@ -4,6 +4,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -58,6 +59,31 @@ func TestUserRepository_Create(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "create with existing username should fail",
+			user: &model.User{
+				Email:    "other@example.com",
+				Username: "taken",
+				Password: "hashedpassword",
+				Name:     "Other User",
+				Roles:    []string{},
+				Active:   true,
+			},
+			setupDB: func(t *testing.T) *MongoDB {
+				db := setupTestDB(t)
+				repo := NewUserRepository(db.Database)
+				existingUser := &model.User{
+					Email:    "taken@example.com",
+					Username: "taken",
+					Password: "hashedpassword",
+					Name:     "Existing User",
+					Active:   true,
+				}
+				_ = repo.Create(context.Background(), existingUser)
+				return db
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -70,7 +96,7 @@ func TestUserRepository_Create(t *testing.T) {
 			err := repo.Create(context.Background(), tt.user)
 
 			if tt.wantError {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrUserExists)
 			} else {
 				assert.NoError(t, err)
 				assert.False(t, tt.user.ID.IsZero())
@@ -81,6 +107,41 @@ func TestUserRepository_Create(t *testing.T) {
 	}
 }
 
+func TestUserRepository_CreateMany(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+	repo := NewUserRepository(db.Database)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &model.User{Email: "taken@example.com", Active: true}))
+
+	// A duplicate leaves none of the batch behind
+	err := repo.CreateMany(ctx, []*model.User{
+		{Email: "first@example.com", Active: true},
+		{Email: "taken@example.com", Active: true},
+	})
+	var writeErr *UserWriteError
+	require.ErrorAs(t, err, &writeErr)
+	assert.Equal(t, 1, writeErr.Index)
+	assert.ErrorIs(t, err, ErrUserExists)
+	count, err := repo.Count(ctx, bson.M{"email": "first@example.com"})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	users := []*model.User{
+		{Email: "first@example.com", Active: true},
+		{Email: "second@example.com", Active: true},
+	}
+	require.NoError(t, repo.CreateMany(ctx, users))
+	for _, user := range users {
+		assert.False(t, user.ID.IsZero())
+		assert.NotZero(t, user.CreatedAt)
+	}
+	count, err = repo.Count(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
 func TestUserRepository_FindByEmail(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -200,6 +261,26 @@ func TestUserRepository_FindByID(t *testing.T) {
 	}
 }
 
+func TestUserRepository_FindByID_HedgedReads(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	// A nanosecond delay hedges every lookup; on a standalone server the
+	// hedge reads the same node, so both reads see the user
+	repo := NewUserRepository(db.Database, WithHedgedReads(time.Nanosecond))
+	user := &model.User{Email: "hedged@example.com", Password: "hashedpassword", Name: "Hedged", Active: true}
+	require.NoError(t, repo.Create(context.Background(), user))
+
+	found, err := repo.FindByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, user.ID, found.ID)
+
+	found, err = repo.FindByIDMinimal(context.Background(), primitive.NewObjectID())
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
 func TestUserRepository_Update(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -304,6 +385,53 @@ func TestUserRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestUserRepository_RestoreAndHardDelete(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	repo := NewUserRepository(db.Database)
+	ctx := context.Background()
+
+	user := &model.User{Email: "test@example.com", Username: "tester", Name: "Test User", Active: true}
+	require.NoError(t, repo.Create(ctx, user))
+
+	// Active users are never purged
+	deleted, err := repo.HardDelete(ctx, user.ID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	found, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.DeletedAt)
+	firstDeletedAt := *found.DeletedAt
+
+	// Deleting again keeps the first deletion time
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	found, err = repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, firstDeletedAt.Equal(*found.DeletedAt))
+
+	require.NoError(t, repo.Restore(ctx, user.ID))
+	found, err = repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, found.Active)
+	assert.Nil(t, found.DeletedAt)
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	// Within the retention
+	deleted, err = repo.HardDelete(ctx, user.ID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = repo.HardDelete(ctx, user.ID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	found, err = repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
 func TestUserRepository_FindByUsername(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -501,6 +629,29 @@ func TestUserRepository_List(t *testing.T) {
 	}
 }
 
+func TestUserRepository_Count(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	repo := NewUserRepository(db.Database)
+	for i, active := range []bool{true, true, false} {
+		require.NoError(t, repo.Create(context.Background(), &model.User{
+			Email:    "user" + string(rune('0'+i)) + "@example.com",
+			Username: "user" + string(rune('0'+i)),
+			Password: "hashedpassword",
+			Active:   active,
+		}))
+	}
+
+	total, err := repo.Count(context.Background(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+
+	inactive, err := repo.Count(context.Background(), bson.M{"active": false})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), inactive)
+}
+
 // Helper functions for testing
 func setupTestDB(t *testing.T) *MongoDB {
 	// Use shared container with unique database name per test for isolation
@@ -523,3 +674,69 @@ func cleanupTestDB(t *testing.T, db *MongoDB) {
 		_ = db.Logs.Drop(ctx)
 	}
 }
+
+func TestUserRepository_TokenVersion(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	repo := NewUserRepository(db.Database)
+	ctx := context.Background()
+
+	user := &model.User{Email: "test@example.com", Username: "tester", Name: "Test User", Active: true}
+	require.NoError(t, repo.Create(ctx, user))
+
+	version, err := repo.GetTokenVersion(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, version)
+
+	version, err = repo.IncrementTokenVersion(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	// Saving a copy read before the increment keeps the new version
+	user.Name = "Updated Name"
+	require.NoError(t, repo.Update(ctx, user))
+
+	version, err = repo.GetTokenVersion(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	version, err = repo.GetTokenVersion(ctx, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Zero(t, version)
+}
+
+func TestUserRepository_RecordLogin(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	repo := NewUserRepository(db.Database)
+	ctx := context.Background()
+
+	user := &model.User{Email: "test@example.com", Username: "tester", Name: "Test User", Active: true}
+	require.NoError(t, repo.Create(ctx, user))
+
+	start := time.Now().UTC().Truncate(time.Millisecond)
+	var loginAt time.Time
+	for i := range model.MaxRecentLogins + 2 {
+		loginAt = start.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.RecordLogin(ctx, user.ID, model.LoginRecord{At: loginAt, IP: fmt.Sprintf("10.0.0.%d", i)}))
+	}
+
+	// Saving a copy read before the login keeps the recorded logins
+	user.Name = "Updated Name"
+	require.NoError(t, repo.Update(ctx, user))
+
+	found, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.LastLoginAt)
+	assert.True(t, loginAt.Equal(*found.LastLoginAt))
+	assert.Equal(t, fmt.Sprintf("10.0.0.%d", model.MaxRecentLogins+1), found.LastLoginIP)
+	require.Len(t, found.RecentLogins, model.MaxRecentLogins)
+	assert.True(t, loginAt.Equal(found.RecentLogins[0].At))
+	assert.Equal(t, "10.0.0.2", found.RecentLogins[model.MaxRecentLogins-1].IP)
+
+	stale, err := repo.Count(ctx, bson.M{"last_login_at": bson.M{"$lt": loginAt.Add(time.Minute)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stale)
+}
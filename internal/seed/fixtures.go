@@ -0,0 +1,141 @@
+// Package seed loads declarative development fixtures and applies them to the database.
+package seed
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures is the merged content of a fixture directory.
+type Fixtures struct {
+	Roles     []RoleFixture     `yaml:"roles"`
+	Users     []UserFixture     `yaml:"users"`
+	PackSizes []PackSizeFixture `yaml:"pack_sizes"`
+	APIKeys   []string          `yaml:"api_keys"`
+}
+
+// RoleFixture declares a role and the permissions it grants, by permission
+// name ("resource:action").
+type RoleFixture struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Permissions []string `yaml:"permissions"`
+}
+
+// UserFixture declares a user. Passwords are plain text and hashed on seeding,
+// which is why fixtures are only loaded in development and test environments.
+type UserFixture struct {
+	Email    string   `yaml:"email"`
+	Username string   `yaml:"username"`
+	Name     string   `yaml:"name"`
+	Password string   `yaml:"password"`
+	Roles    []string `yaml:"roles"`
+}
+
+// PackSizeFixture declares a pack size configuration. When several are listed
+// they are created in order, so the last one ends up active.
+type PackSizeFixture struct {
+	Sizes []int `yaml:"sizes"`
+}
+
+// Load reads every .yaml and .yml file in dir, in name order, and merges them.
+// Unknown keys are rejected so typos surface instead of being silently ignored.
+func Load(dir string) (*Fixtures, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read seed directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	fixtures := &Fixtures{}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		var file Fixtures
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+
+		fixtures.Roles = append(fixtures.Roles, file.Roles...)
+		fixtures.Users = append(fixtures.Users, file.Users...)
+		fixtures.PackSizes = append(fixtures.PackSizes, file.PackSizes...)
+		fixtures.APIKeys = append(fixtures.APIKeys, file.APIKeys...)
+	}
+
+	if err := fixtures.Validate(); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// Validate checks that every fixture has its required fields.
+func (f *Fixtures) Validate() error {
+	var errs []error
+
+	for i, role := range f.Roles {
+		if role.Name == "" {
+			errs = append(errs, fmt.Errorf("roles[%d]: name is required", i))
+		}
+		for _, perm := range role.Permissions {
+			if _, _, ok := splitPermission(perm); !ok {
+				errs = append(errs, fmt.Errorf("roles[%d]: permission %q must be resource:action", i, perm))
+			}
+		}
+	}
+
+	for i, user := range f.Users {
+		if user.Email == "" {
+			errs = append(errs, fmt.Errorf("users[%d]: email is required", i))
+		}
+		if len(user.Password) < 6 {
+			errs = append(errs, fmt.Errorf("users[%d]: password must be at least 6 characters", i))
+		}
+	}
+
+	for i, config := range f.PackSizes {
+		if len(config.Sizes) == 0 {
+			errs = append(errs, fmt.Errorf("pack_sizes[%d]: sizes are required", i))
+		}
+		for _, size := range config.Sizes {
+			if size <= 0 {
+				errs = append(errs, fmt.Errorf("pack_sizes[%d]: sizes must be positive", i))
+				break
+			}
+		}
+	}
+
+	for i, key := range f.APIKeys {
+		if strings.TrimSpace(key) == "" {
+			errs = append(errs, fmt.Errorf("api_keys[%d]: key is empty", i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// splitPermission parses a permission name of the form "resource:action".
+func splitPermission(name string) (resource, action string, ok bool) {
+	resource, action, ok = strings.Cut(name, ":")
+	return resource, action, ok && resource != "" && action != ""
+}
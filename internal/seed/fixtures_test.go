@@ -0,0 +1,95 @@
+//go:build !integration
+
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFixture(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestLoad_MergesFilesInNameOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "b_users.yml", `
+users:
+  - email: admin@example.com
+    password: admin123
+    roles: [admin]
+`)
+	writeFixture(t, dir, "a_roles.yaml", `
+roles:
+  - name: viewer
+    permissions: [packs:read]
+pack_sizes:
+  - sizes: [250, 500]
+api_keys: [key-one]
+`)
+	writeFixture(t, dir, "c_more.yaml", `
+pack_sizes:
+  - sizes: [23, 31, 53]
+api_keys: [key-two]
+`)
+	writeFixture(t, dir, "empty.yaml", "")
+	writeFixture(t, dir, "README.md", "not a fixture")
+
+	fixtures, err := Load(dir)
+
+	require.NoError(t, err)
+	require.Len(t, fixtures.Roles, 1)
+	assert.Equal(t, []string{"packs:read"}, fixtures.Roles[0].Permissions)
+	require.Len(t, fixtures.Users, 1)
+	assert.Equal(t, []string{"admin"}, fixtures.Users[0].Roles)
+	assert.Equal(t, []PackSizeFixture{{Sizes: []int{250, 500}}, {Sizes: []int{23, 31, 53}}}, fixtures.PackSizes)
+	assert.Equal(t, []string{"key-one", "key-two"}, fixtures.APIKeys)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown key", "roles:\n  - name: viewer\n    permisions: [packs:read]\n", "permisions"},
+		{"invalid yaml", "roles: [\n", "parse fixtures.yaml"},
+		{"missing role name", "roles:\n  - description: nameless\n", "roles[0]: name is required"},
+		{"bad permission", "roles:\n  - name: viewer\n    permissions: [packs]\n", `permission "packs"`},
+		{"short password", "users:\n  - email: a@example.com\n    password: abc\n", "users[0]: password"},
+		{"missing email", "users:\n  - password: secret123\n", "users[0]: email is required"},
+		{"non-positive size", "pack_sizes:\n  - sizes: [250, 0]\n", "sizes must be positive"},
+		{"empty api key", "api_keys: [\" \"]\n", "api_keys[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFixture(t, dir, "fixtures.yaml", tt.content)
+
+			_, err := Load(dir)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoad_MissingDirectory(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing"))
+
+	assert.Error(t, err)
+}
+
+func TestLoad_RepositoryFixtures(t *testing.T) {
+	fixtures, err := Load(filepath.Join("..", "..", "scripts", "seed"))
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, fixtures.Users)
+	assert.NotEmpty(t, fixtures.PackSizes)
+}
@@ -0,0 +1,220 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+// createdBy marks pack size configurations created from fixtures.
+const createdBy = "seed"
+
+// Result summarises what a seeding run changed.
+type Result struct {
+	RolesCreated     int
+	RolesUpdated     int
+	UsersCreated     int
+	PackSizesCreated int
+}
+
+// Seeder applies fixtures to the repositories. Seeding is idempotent: existing
+// users are left untouched, existing roles only gain missing permissions, and
+// pack sizes are only written when the active configuration differs.
+type Seeder struct {
+	roleRepo       repository.RoleRepositoryInterface
+	permissionRepo repository.PermissionRepositoryInterface
+	userRepo       repository.UserRepositoryInterface
+	packSizesRepo  repository.PackSizesRepositoryInterface
+}
+
+// NewSeeder creates a Seeder.
+func NewSeeder(
+	roleRepo repository.RoleRepositoryInterface,
+	permissionRepo repository.PermissionRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	packSizesRepo repository.PackSizesRepositoryInterface,
+) *Seeder {
+	return &Seeder{
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+		packSizesRepo:  packSizesRepo,
+	}
+}
+
+// Apply seeds roles, then users (which reference roles), then pack sizes.
+// API keys are not stored in the database; callers add them to the config.
+func (s *Seeder) Apply(ctx context.Context, fixtures *Fixtures) (Result, error) {
+	var result Result
+
+	for _, role := range fixtures.Roles {
+		created, updated, err := s.seedRole(ctx, role)
+		if err != nil {
+			return result, fmt.Errorf("seed role %q: %w", role.Name, err)
+		}
+		if created {
+			result.RolesCreated++
+		}
+		if updated {
+			result.RolesUpdated++
+		}
+	}
+
+	for _, user := range fixtures.Users {
+		created, err := s.seedUser(ctx, user)
+		if err != nil {
+			return result, fmt.Errorf("seed user %q: %w", user.Email, err)
+		}
+		if created {
+			result.UsersCreated++
+		}
+	}
+
+	created, err := s.seedPackSizes(ctx, fixtures.PackSizes)
+	if err != nil {
+		return result, fmt.Errorf("seed pack sizes: %w", err)
+	}
+	result.PackSizesCreated = created
+
+	return result, nil
+}
+
+func (s *Seeder) seedRole(ctx context.Context, fixture RoleFixture) (created, updated bool, err error) {
+	permissionIDs, err := s.permissionIDs(ctx, fixture.Permissions)
+	if err != nil {
+		return false, false, err
+	}
+
+	existing, err := s.roleRepo.FindByName(ctx, fixture.Name)
+	if err != nil {
+		return false, false, err
+	}
+
+	if existing == nil {
+		role := &model.Role{
+			Name:        fixture.Name,
+			Description: fixture.Description,
+			Permissions: permissionIDs,
+			Active:      true,
+		}
+		if err := s.roleRepo.Create(ctx, role); err != nil {
+			return false, false, err
+		}
+		log.Info().Str("role", fixture.Name).Msg("Seeded role")
+		return true, false, nil
+	}
+
+	missing := false
+	for _, id := range permissionIDs {
+		if !slices.Contains(existing.Permissions, id) {
+			existing.Permissions = append(existing.Permissions, id)
+			missing = true
+		}
+	}
+	if !missing {
+		return false, false, nil
+	}
+	if err := s.roleRepo.Update(ctx, existing); err != nil {
+		return false, false, err
+	}
+	log.Info().Str("role", fixture.Name).Msg("Granted seeded permissions to existing role")
+	return false, true, nil
+}
+
+func (s *Seeder) permissionIDs(ctx context.Context, names []string) ([]string, error) {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		resource, action, _ := splitPermission(name)
+		perm, err := s.permissionRepo.FindByResourceAndAction(ctx, resource, action)
+		if err != nil {
+			return nil, err
+		}
+		if perm == nil {
+			return nil, fmt.Errorf("unknown permission %q", name)
+		}
+		ids = append(ids, perm.ID.Hex())
+	}
+	return ids, nil
+}
+
+func (s *Seeder) seedUser(ctx context.Context, fixture UserFixture) (bool, error) {
+	existing, err := s.userRepo.FindByEmail(ctx, fixture.Email)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, nil
+	}
+
+	roleIDs := make([]string, 0, len(fixture.Roles))
+	for _, name := range fixture.Roles {
+		role, err := s.roleRepo.FindByName(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		if role == nil {
+			return false, fmt.Errorf("unknown role %q", name)
+		}
+		roleIDs = append(roleIDs, role.ID.Hex())
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(fixture.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, err
+	}
+
+	username := fixture.Username
+	if username == "" {
+		username, _, _ = strings.Cut(fixture.Email, "@")
+	}
+
+	user := &model.User{
+		Email:    fixture.Email,
+		Username: username,
+		Password: string(hashedPassword),
+		Name:     fixture.Name,
+		Roles:    roleIDs,
+		Active:   true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		// Another instance seeding the same fixtures got there first
+		if errors.Is(err, repository.ErrUserExists) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	log.Info().Str("email", fixture.Email).Msg("Seeded user")
+	return true, nil
+}
+
+func (s *Seeder) seedPackSizes(ctx context.Context, configs []PackSizeFixture) (int, error) {
+	if len(configs) == 0 {
+		return 0, nil
+	}
+
+	active, err := s.packSizesRepo.GetActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if active != nil && slices.Equal(active.Sizes, configs[len(configs)-1].Sizes) {
+		return 0, nil
+	}
+
+	for _, config := range configs {
+		if _, err := s.packSizesRepo.Create(ctx, config.Sizes, nil, createdBy); err != nil {
+			return 0, err
+		}
+	}
+
+	log.Info().Ints("sizes", configs[len(configs)-1].Sizes).Msg("Seeded pack sizes")
+	return len(configs), nil
+}
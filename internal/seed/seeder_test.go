@@ -0,0 +1,160 @@
+//go:build !integration
+
+package seed
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type seederMocks struct {
+	roles       *mocks.MockRoleRepositoryInterface
+	permissions *mocks.MockPermissionRepositoryInterface
+	users       *mocks.MockUserRepositoryInterface
+	packSizes   *mocks.MockPackSizesRepositoryInterface
+}
+
+func newTestSeeder(t *testing.T) (*Seeder, seederMocks) {
+	m := seederMocks{
+		roles:       mocks.NewMockRoleRepositoryInterface(t),
+		permissions: mocks.NewMockPermissionRepositoryInterface(t),
+		users:       mocks.NewMockUserRepositoryInterface(t),
+		packSizes:   new(mocks.MockPackSizesRepositoryInterface),
+	}
+	t.Cleanup(func() { m.packSizes.AssertExpectations(t) })
+	return NewSeeder(m.roles, m.permissions, m.users, m.packSizes), m
+}
+
+func TestSeeder_Apply(t *testing.T) {
+	seeder, m := newTestSeeder(t)
+
+	readPerm := &model.Permission{ID: primitive.NewObjectID()}
+	viewer := &model.Role{ID: primitive.NewObjectID(), Name: "viewer"}
+
+	m.permissions.On("FindByResourceAndAction", mock.Anything, "packs", "read").Return(readPerm, nil).Once()
+	m.roles.On("FindByName", mock.Anything, "viewer").Return(nil, nil).Once()
+	m.roles.On("Create", mock.Anything, mock.MatchedBy(func(r *model.Role) bool {
+		return r.Name == "viewer" && r.Active && len(r.Permissions) == 1 && r.Permissions[0] == readPerm.ID.Hex()
+	})).Return(nil).Once()
+
+	m.users.On("FindByEmail", mock.Anything, "viewer@example.com").Return(nil, nil).Once()
+	m.roles.On("FindByName", mock.Anything, "viewer").Return(viewer, nil).Once()
+	m.users.On("Create", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+		return u.Username == "viewer" && u.Active &&
+			len(u.Roles) == 1 && u.Roles[0] == viewer.ID.Hex() &&
+			bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("viewer123")) == nil
+	})).Return(nil).Once()
+
+	m.packSizes.On("GetActive", mock.Anything).Return(&repository.PackSizeConfig{Sizes: []int{250, 500}}, nil).Once()
+	m.packSizes.On("Create", mock.Anything, []int{100, 200}, mock.Anything, "seed").Return(&repository.PackSizeConfig{}, nil).Once()
+	m.packSizes.On("Create", mock.Anything, []int{23, 31, 53}, mock.Anything, "seed").Return(&repository.PackSizeConfig{}, nil).Once()
+
+	result, err := seeder.Apply(context.Background(), &Fixtures{
+		Roles:     []RoleFixture{{Name: "viewer", Permissions: []string{"packs:read"}}},
+		Users:     []UserFixture{{Email: "viewer@example.com", Password: "viewer123", Roles: []string{"viewer"}}},
+		PackSizes: []PackSizeFixture{{Sizes: []int{100, 200}}, {Sizes: []int{23, 31, 53}}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Result{RolesCreated: 1, UsersCreated: 1, PackSizesCreated: 2}, result)
+}
+
+func TestSeeder_Apply_Idempotent(t *testing.T) {
+	seeder, m := newTestSeeder(t)
+
+	readPerm := &model.Permission{ID: primitive.NewObjectID()}
+	m.permissions.On("FindByResourceAndAction", mock.Anything, "packs", "read").Return(readPerm, nil).Once()
+	m.roles.On("FindByName", mock.Anything, "viewer").
+		Return(&model.Role{Name: "viewer", Permissions: []string{readPerm.ID.Hex()}}, nil).Once()
+	m.users.On("FindByEmail", mock.Anything, "viewer@example.com").Return(&model.User{}, nil).Once()
+	m.packSizes.On("GetActive", mock.Anything).Return(&repository.PackSizeConfig{Sizes: []int{23, 31, 53}}, nil).Once()
+
+	result, err := seeder.Apply(context.Background(), &Fixtures{
+		Roles:     []RoleFixture{{Name: "viewer", Permissions: []string{"packs:read"}}},
+		Users:     []UserFixture{{Email: "viewer@example.com", Password: "viewer123"}},
+		PackSizes: []PackSizeFixture{{Sizes: []int{23, 31, 53}}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Result{}, result)
+}
+
+func TestSeeder_Apply_GrantsMissingPermissions(t *testing.T) {
+	seeder, m := newTestSeeder(t)
+
+	readPerm := &model.Permission{ID: primitive.NewObjectID()}
+	writePerm := &model.Permission{ID: primitive.NewObjectID()}
+	m.permissions.On("FindByResourceAndAction", mock.Anything, "packs", "read").Return(readPerm, nil).Once()
+	m.permissions.On("FindByResourceAndAction", mock.Anything, "packs", "write").Return(writePerm, nil).Once()
+	m.roles.On("FindByName", mock.Anything, "operator").
+		Return(&model.Role{Name: "operator", Permissions: []string{readPerm.ID.Hex()}}, nil).Once()
+	m.roles.On("Update", mock.Anything, mock.MatchedBy(func(r *model.Role) bool {
+		return len(r.Permissions) == 2 && r.Permissions[1] == writePerm.ID.Hex()
+	})).Return(nil).Once()
+
+	result, err := seeder.Apply(context.Background(), &Fixtures{
+		Roles: []RoleFixture{{Name: "operator", Permissions: []string{"packs:read", "packs:write"}}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.RolesUpdated)
+}
+
+func TestSeeder_Apply_Errors(t *testing.T) {
+	t.Run("unknown permission", func(t *testing.T) {
+		seeder, m := newTestSeeder(t)
+		m.permissions.On("FindByResourceAndAction", mock.Anything, "reports", "read").Return(nil, nil).Once()
+
+		_, err := seeder.Apply(context.Background(), &Fixtures{
+			Roles: []RoleFixture{{Name: "viewer", Permissions: []string{"reports:read"}}},
+		})
+
+		assert.ErrorContains(t, err, `unknown permission "reports:read"`)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		seeder, m := newTestSeeder(t)
+		m.users.On("FindByEmail", mock.Anything, "a@example.com").Return(nil, nil).Once()
+		m.roles.On("FindByName", mock.Anything, "ghost").Return(nil, nil).Once()
+
+		_, err := seeder.Apply(context.Background(), &Fixtures{
+			Users: []UserFixture{{Email: "a@example.com", Password: "secret123", Roles: []string{"ghost"}}},
+		})
+
+		assert.ErrorContains(t, err, `unknown role "ghost"`)
+	})
+
+	t.Run("concurrent user creation is not an error", func(t *testing.T) {
+		seeder, m := newTestSeeder(t)
+		m.users.On("FindByEmail", mock.Anything, "a@example.com").Return(nil, nil).Once()
+		m.users.On("Create", mock.Anything, mock.Anything).Return(repository.ErrUserExists).Once()
+
+		result, err := seeder.Apply(context.Background(), &Fixtures{
+			Users: []UserFixture{{Email: "a@example.com", Password: "secret123"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Zero(t, result.UsersCreated)
+	})
+
+	t.Run("pack sizes repository failure", func(t *testing.T) {
+		seeder, m := newTestSeeder(t)
+		m.packSizes.On("GetActive", mock.Anything).Return(nil, errors.New("circuit breaker is open")).Once()
+
+		_, err := seeder.Apply(context.Background(), &Fixtures{
+			PackSizes: []PackSizeFixture{{Sizes: []int{250}}},
+		})
+
+		assert.ErrorContains(t, err, "seed pack sizes")
+	})
+}
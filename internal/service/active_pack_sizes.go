@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+const (
+	// DefaultPackSizesPollInterval is how often the active pack sizes are
+	// read while the change stream is down, when no interval is configured.
+	DefaultPackSizesPollInterval = 30 * time.Second
+	// packSizesRefreshTimeout bounds a read of the active pack sizes.
+	packSizesRefreshTimeout = 5 * time.Second
+)
+
+// ActivePackSizes keeps the active pack size configuration in memory, so
+// calculations read it without waiting on the database. A change stream
+// reloads it as soon as any replica changes the stored configurations. While
+// the stream is down, or on a standalone MongoDB without change streams, it
+// is read every poll interval instead, and the stream is reopened after a
+// pause. Failed reads keep the configuration already loaded.
+type ActivePackSizes struct {
+	packSizes    PackSizesService
+	watcher      repository.PackSizesWatcher
+	pollInterval time.Duration
+	onChange     func()
+
+	current atomic.Pointer[repository.PackSizeConfig]
+
+	subscribersMu sync.Mutex
+	subscribers   []func()
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	startOnce sync.Once
+	stopOnce  sync.Once
+	doneCh    chan struct{}
+}
+
+// NewActivePackSizes creates a view of the configuration packSizes reports
+// active, reloaded on the changes watcher reports. watcher may be nil to
+// poll only; a zero pollInterval uses DefaultPackSizesPollInterval. Call
+// Reload or Start to load it.
+func NewActivePackSizes(packSizes PackSizesService, watcher repository.PackSizesWatcher, pollInterval time.Duration) *ActivePackSizes {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPackSizesPollInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ActivePackSizes{
+		packSizes:    packSizes,
+		watcher:      watcher,
+		pollInterval: pollInterval,
+		ctx:          ctx,
+		cancel:       cancel,
+		doneCh:       make(chan struct{}),
+	}
+}
+
+// Current returns the active configuration, or nil until it is first loaded
+// and when none is active; callers then use their default pack sizes. The
+// configuration is shared and must not be modified.
+func (a *ActivePackSizes) Current() *repository.PackSizeConfig {
+	return a.current.Load()
+}
+
+// Set replaces the active configuration, after this instance activated
+// config, so the next calculations use it without waiting for the stream.
+func (a *ActivePackSizes) Set(config *repository.PackSizeConfig) {
+	if previous := a.current.Swap(config); !samePackSizeConfig(previous, config) {
+		a.notify()
+	}
+}
+
+// Subscribe calls fn whenever the active configuration changes, by a reload
+// or Set, once the new one is current. fn must not block.
+func (a *ActivePackSizes) Subscribe(fn func()) {
+	a.subscribersMu.Lock()
+	defer a.subscribersMu.Unlock()
+	a.subscribers = append(a.subscribers, fn)
+}
+
+// notify calls the subscribers.
+func (a *ActivePackSizes) notify() {
+	a.subscribersMu.Lock()
+	subscribers := slices.Clone(a.subscribers)
+	a.subscribersMu.Unlock()
+
+	for _, fn := range subscribers {
+		fn()
+	}
+}
+
+// Start loads the active configuration in the background and keeps it
+// current until Stop is called, calling onChange, if not nil, whenever a
+// reload finds a different one. Only the first call has an effect.
+func (a *ActivePackSizes) Start(onChange func()) {
+	a.startOnce.Do(func() {
+		a.onChange = onChange
+		go a.run()
+	})
+}
+
+// Stop stops keeping the configuration current and waits for a running
+// reload. Current keeps returning the last configuration loaded.
+func (a *ActivePackSizes) Stop() {
+	a.stopOnce.Do(func() {
+		a.cancel()
+		// Without Start there is nothing to wait for
+		a.startOnce.Do(func() { close(a.doneCh) })
+		<-a.doneCh
+	})
+}
+
+func (a *ActivePackSizes) run() {
+	defer close(a.doneCh)
+
+	a.refresh()
+	for failures := 0; ; failures++ {
+		if a.watcher != nil {
+			err := a.watcher.WatchPackSizes(a.ctx, a.refresh)
+			if a.ctx.Err() != nil {
+				return
+			}
+			event := log.Debug()
+			if failures == 0 {
+				event = log.Warn()
+			}
+			event.Err(err).Dur("poll_interval", a.pollInterval).Dur("retry_in", packSizesWatchRetry).
+				Msg("Pack sizes change stream stopped, polling the active pack sizes")
+		}
+		if !a.poll() {
+			return
+		}
+	}
+}
+
+// poll reloads the configuration every poll interval until Stop is called
+// or, when there is a change stream to reopen, until it is time to retry
+// it. It returns false once stopped.
+func (a *ActivePackSizes) poll() bool {
+	var retry <-chan time.Time
+	if a.watcher != nil {
+		timer := time.NewTimer(packSizesWatchRetry)
+		defer timer.Stop()
+		retry = timer.C
+	}
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return false
+		case <-ticker.C:
+			a.refresh()
+		case <-retry:
+			// Catch up before reopening, the stream only reports later changes
+			a.refresh()
+			return true
+		}
+	}
+}
+
+// Reload reads the active configuration. On error the current one is kept.
+func (a *ActivePackSizes) Reload(ctx context.Context) error {
+	config, err := a.packSizes.GetActive(ctx)
+	if err != nil {
+		return err
+	}
+	if config != nil && len(config.Sizes) == 0 {
+		config = nil
+	}
+	if previous := a.current.Swap(config); !samePackSizeConfig(previous, config) {
+		if a.onChange != nil {
+			a.onChange()
+		}
+		a.notify()
+	}
+	return nil
+}
+
+// refresh reloads the configuration in the background.
+func (a *ActivePackSizes) refresh() {
+	ctx, cancel := context.WithTimeout(a.ctx, packSizesRefreshTimeout)
+	defer cancel()
+
+	if err := a.Reload(ctx); err != nil && a.ctx.Err() == nil {
+		log.Warn().Err(err).Msg("Failed to read the active pack sizes, keeping the current ones")
+	}
+}
+
+// samePackSizeConfig reports whether a and b are the same configuration.
+func samePackSizeConfig(a, b *repository.PackSizeConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ID == b.ID && a.Version == b.Version && slices.Equal(a.Sizes, b.Sizes)
+}
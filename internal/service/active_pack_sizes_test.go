@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// storedPackSizes is a PackSizesService whose active configuration tests
+// change while views read it.
+type storedPackSizes struct {
+	service.PackSizesService
+
+	mu     sync.Mutex
+	active *repository.PackSizeConfig
+	err    error
+}
+
+func (s *storedPackSizes) GetActive(context.Context) (*repository.PackSizeConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active, s.err
+}
+
+func (s *storedPackSizes) set(active *repository.PackSizeConfig, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active, s.err = active, err
+}
+
+// failingPackSizesWatcher is a MongoDB without change streams.
+type failingPackSizesWatcher struct{}
+
+func (failingPackSizesWatcher) WatchPackSizes(context.Context, func()) error {
+	return errors.New("the $changeStream stage is only supported on replica sets")
+}
+
+func packSizeConfig(version int, sizes ...int) *repository.PackSizeConfig {
+	return &repository.PackSizeConfig{ID: primitive.NewObjectID(), Sizes: sizes, Version: version}
+}
+
+func TestActivePackSizes_Reload(t *testing.T) {
+	ctx := context.Background()
+	stored := &storedPackSizes{}
+	view := service.NewActivePackSizes(stored, nil, 0)
+	assert.Nil(t, view.Current(), "nothing is loaded before Reload")
+
+	first := packSizeConfig(1, 250, 500)
+	stored.set(first, nil)
+	require.NoError(t, view.Reload(ctx))
+	assert.Same(t, first, view.Current())
+
+	// Failed reads keep the configuration loaded
+	stored.set(nil, errors.New("connection refused"))
+	assert.Error(t, view.Reload(ctx))
+	assert.Same(t, first, view.Current())
+
+	// Without an active configuration, or with an empty one, callers use their defaults
+	stored.set(packSizeConfig(2), nil)
+	require.NoError(t, view.Reload(ctx))
+	assert.Nil(t, view.Current())
+
+	second := packSizeConfig(3, 23, 31, 53)
+	view.Set(second)
+	assert.Same(t, second, view.Current())
+}
+
+func TestActivePackSizes_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	first := packSizeConfig(1, 250, 500)
+	stored := &storedPackSizes{active: first}
+	view := service.NewActivePackSizes(stored, nil, 0)
+
+	var changes atomic.Int32
+	view.Subscribe(func() { changes.Add(1) })
+
+	require.NoError(t, view.Reload(ctx))
+	require.NoError(t, view.Reload(ctx))
+	assert.Equal(t, int32(1), changes.Load(), "reloading the same configuration is not a change")
+
+	view.Set(packSizeConfig(2, 23, 31, 53))
+	assert.Equal(t, int32(2), changes.Load())
+	view.Set(view.Current())
+	assert.Equal(t, int32(2), changes.Load())
+}
+
+func TestActivePackSizes_ReloadsOnChanges(t *testing.T) {
+	first := packSizeConfig(1, 250, 500)
+	stored := &storedPackSizes{active: first}
+	watcher := &fakePackSizesWatcher{changes: make(chan struct{})}
+	view := service.NewActivePackSizes(stored, watcher, time.Hour)
+	defer view.Stop()
+
+	var changes atomic.Int32
+	view.Start(func() { changes.Add(1) })
+	assert.Eventually(t, func() bool { return view.Current() == first }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), changes.Load())
+
+	// A change event without a different configuration changes nothing
+	watcher.changes <- struct{}{}
+	second := packSizeConfig(2, 23, 31, 53)
+	stored.set(second, nil)
+	watcher.changes <- struct{}{}
+	assert.Eventually(t, func() bool { return view.Current() == second }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), changes.Load())
+}
+
+func TestActivePackSizes_PollsWithoutChangeStream(t *testing.T) {
+	stored := &storedPackSizes{active: packSizeConfig(1, 250, 500)}
+	view := service.NewActivePackSizes(stored, failingPackSizesWatcher{}, 10*time.Millisecond)
+	view.Start(nil)
+	defer view.Stop()
+
+	second := packSizeConfig(2, 23, 31, 53)
+	stored.set(second, nil)
+	assert.Eventually(t, func() bool { return view.Current() == second }, time.Second, 5*time.Millisecond)
+
+	// The database going away keeps the configuration loaded
+	stored.set(nil, errors.New("connection refused"))
+	time.Sleep(30 * time.Millisecond)
+	assert.Same(t, second, view.Current())
+}
+
+func TestActivePackSizes_StopWithoutStart(t *testing.T) {
+	view := service.NewActivePackSizes(&storedPackSizes{}, nil, 0)
+	view.Stop()
+	view.Stop()
+
+	// Starting after Stop has no effect
+	view.Start(func() { t.Error("stopped view reloaded") })
+}
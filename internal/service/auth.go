@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
+	"net/http"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
@@ -12,20 +12,27 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 var (
 	// ErrInvalidCredentials is returned when email or password is incorrect.
-	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidCredentials = domainerr.New("invalid_credentials", http.StatusUnauthorized, i18n.ErrKeyInvalidCredentials, "invalid email or password")
 	// ErrUserExists is returned when trying to register an existing user.
-	ErrUserExists = errors.New("user already exists")
+	// It aliases the repository error so duplicate-key races surface the same way.
+	ErrUserExists = repository.ErrUserExists
 	// ErrInvalidToken is returned when token is invalid or expired.
-	ErrInvalidToken = errors.New("invalid or expired token")
+	ErrInvalidToken = domainerr.New("invalid_token", http.StatusUnauthorized, i18n.ErrKeyInvalidToken, "invalid or expired token")
 	// ErrTokenBlacklisted is returned when token is blacklisted.
-	ErrTokenBlacklisted = errors.New("token is blacklisted")
+	ErrTokenBlacklisted = ErrInvalidToken.Derive(i18n.ErrKeyInvalidToken, "token is blacklisted")
+	// ErrTokenRevoked is returned when the user's tokens were revoked after the token was issued.
+	ErrTokenRevoked = ErrInvalidToken.Derive(i18n.ErrKeyInvalidToken, "token has been revoked")
 )
 
 // TokenPair and Claims are now in dto package to avoid import cycles.
@@ -47,6 +54,7 @@ type AuthService interface {
 	ValidateToken(ctx context.Context, tokenString string) (*dto.Claims, error)
 	InvalidateToken(ctx context.Context, tokenString string) error
 	InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID) error
+	RevokeUserTokens(ctx context.Context, userID primitive.ObjectID) error
 	Logout(ctx context.Context, accessToken, refreshToken string) error
 }
 
@@ -64,9 +72,10 @@ func NewAuthService(
 	roleRepo repository.RoleRepositoryInterface,
 	tokenRepo repository.TokenRepositoryInterface,
 	authConfig config.AuthConfig,
+	opts ...TokenServiceOption,
 ) AuthService {
 	tokenConfig := NewTokenConfigFromAuthConfig(authConfig)
-	tokenService := NewTokenService(tokenRepo, tokenConfig)
+	tokenService := NewTokenService(tokenRepo, tokenConfig, opts...)
 
 	return &AuthServiceImpl{
 		userRepo:     userRepo,
@@ -123,6 +132,14 @@ func (s *AuthServiceImpl) Login(ctx context.Context, email, password string) (*d
 		return nil, nil, fmt.Errorf("failed to generate token pair: %w", err)
 	}
 
+	// A failure here only makes the account look staler than it is
+	login := model.LoginRecord{At: timeutil.Now(), IP: ClientIPFromContext(ctx), Country: ClientCountryFromContext(ctx)}
+	if err := s.userRepo.RecordLogin(ctx, user.ID, login); err != nil {
+		log.Warn().Err(err).Str("user_id", user.ID.Hex()).Msg("Failed to record last login")
+	} else {
+		user.AddLogin(login)
+	}
+
 	return tokenPair, user, nil
 }
 
@@ -165,7 +182,12 @@ func (s *AuthServiceImpl) Register(ctx context.Context, email, username, passwor
 		Active:   true,
 	}
 
+	// The lookups above are only a fast path: two concurrent registrations can both
+	// pass them, so the unique indexes are the source of truth for duplicates.
 	if err := s.userRepo.Create(ctx, user); err != nil {
+		if errors.Is(err, ErrUserExists) {
+			return nil, nil, ErrUserExists
+		}
 		return nil, nil, err
 	}
 
@@ -191,7 +213,12 @@ func (s *AuthServiceImpl) RefreshToken(ctx context.Context, refreshToken string)
 		return nil, ErrInvalidToken
 	}
 
-	if time.Now().After(token.ExpiresAt) {
+	if timeutil.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	// A bound refresh token can only be used with a proof from the same key
+	if token.JKT != "" && token.JKT != dpop.ThumbprintFromContext(ctx) {
 		return nil, ErrInvalidToken
 	}
 
@@ -228,6 +255,11 @@ func (s *AuthServiceImpl) InvalidateUserTokens(ctx context.Context, userID primi
 	return s.tokenService.InvalidateUserTokens(ctx, userID)
 }
 
+// RevokeUserTokens signs the user out everywhere. See TokenService.RevokeUserTokens.
+func (s *AuthServiceImpl) RevokeUserTokens(ctx context.Context, userID primitive.ObjectID) error {
+	return s.tokenService.RevokeUserTokens(ctx, userID)
+}
+
 func (s *AuthServiceImpl) Logout(ctx context.Context, accessToken, refreshToken string) error {
 	var errs []error
 
@@ -2,20 +2,35 @@ package service_test
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/jwks"
+	"github.com/guttosm/pack-service/internal/metrics"
 	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/testutil"
 )
 
 // testAuthConfig returns a config.AuthConfig for testing.
@@ -51,6 +66,7 @@ func TestAuthService_Login(t *testing.T) {
 					Active:   true,
 				}
 				mockRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
+				mockRepo.On("RecordLogin", mock.Anything, user.ID, mock.AnythingOfType("model.LoginRecord")).Return(nil)
 			},
 			expectedError: nil,
 			validateToken: true,
@@ -133,6 +149,7 @@ func TestAuthService_Login(t *testing.T) {
 				assert.NotEmpty(t, tokenPair.AccessToken)
 				assert.NotEmpty(t, tokenPair.RefreshToken)
 				assert.Equal(t, tt.email, user.Email)
+				assert.NotNil(t, user.LastLoginAt)
 
 				// Validate token can be parsed
 				// Note: We need to use a type that implements jwt.Claims, so we parse with map claims
@@ -240,6 +257,21 @@ func TestAuthService_Register(t *testing.T) {
 			expectedError: errors.New("user role not found - please ensure default roles are initialized"),
 			validateToken: false,
 		},
+		{
+			name:      "duplicate key on create after lookups passed",
+			email:     "race@example.com",
+			username:  "raceuser",
+			password:  "password123",
+			nameField: "Race User",
+			setupMocks: func(mockUserRepo *mocks.MockUserRepositoryInterface, mockRoleRepo *mocks.MockRoleRepositoryInterface, mockTokenRepo *mocks.MockTokenRepositoryInterface) {
+				mockUserRepo.On("FindByEmail", mock.Anything, "race@example.com").Return(nil, nil)
+				mockUserRepo.On("FindByUsername", mock.Anything, "raceuser").Return(nil, nil)
+				mockRoleRepo.On("FindByName", mock.Anything, "user").Return(&model.Role{ID: primitive.NewObjectID(), Name: "user"}, nil)
+				mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(repository.ErrUserExists)
+			},
+			expectedError: service.ErrUserExists,
+			validateToken: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -276,6 +308,62 @@ func TestAuthService_Register(t *testing.T) {
 	}
 }
 
+func TestAuthService_Register_ConcurrentDuplicate(t *testing.T) {
+	const attempts = 10
+
+	mockUserRepo := new(mocks.MockUserRepositoryInterface)
+	mockRoleRepo := new(mocks.MockRoleRepositoryInterface)
+	mockTokenRepo := new(mocks.MockTokenRepositoryInterface)
+
+	// Every goroutine passes the existence checks, as they would when racing.
+	mockUserRepo.On("FindByEmail", mock.Anything, "race@example.com").Return(nil, nil)
+	mockUserRepo.On("FindByUsername", mock.Anything, "raceuser").Return(nil, nil)
+	mockRoleRepo.On("FindByName", mock.Anything, "user").Return(&model.Role{ID: primitive.NewObjectID(), Name: "user"}, nil)
+
+	// Emulate the unique email index: only the first insert wins.
+	var mu sync.Mutex
+	inserted := make(map[string]bool)
+	mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(func(_ context.Context, user *model.User) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if inserted[user.Email] {
+			return repository.ErrUserExists
+		}
+		inserted[user.Email] = true
+		user.ID = primitive.NewObjectID()
+		return nil
+	})
+	mockTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Token")).Return(nil)
+
+	authService := service.NewAuthService(mockUserRepo, mockRoleRepo, mockTokenRepo, testAuthConfig())
+
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = authService.Register(context.Background(), "race@example.com", "raceuser", "password123", "Race User")
+		}(i)
+	}
+	wg.Wait()
+
+	var succeeded, conflicts int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, service.ErrUserExists):
+			conflicts++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, attempts-1, conflicts)
+}
+
 func TestAuthService_RefreshToken(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -298,6 +386,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 				
 				// Generate a real refresh token by logging in
 				mockUserRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
+				mockUserRepo.On("RecordLogin", mock.Anything, userID, mock.AnythingOfType("model.LoginRecord")).Return(nil)
 				mockTokenRepo.On("DeleteByUserID", mock.Anything, userID, "refresh").Return(nil)
 				mockTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Token")).Return(nil).Times(2)
 				
@@ -316,12 +405,13 @@ func TestAuthService_RefreshToken(t *testing.T) {
 				token := &model.Token{
 					ID:        primitive.NewObjectID(),
 					UserID:    userID,
-					Token:     refreshToken,
+					Token:     model.HashToken(refreshToken),
 					Type:      "refresh",
 					ExpiresAt: time.Now().Add(24 * time.Hour),
 				}
-				mockTokenRepo.On("FindByToken", mock.Anything, refreshToken).Return(token, nil)
+				mockTokenRepo.On("FindByToken", mock.Anything, model.HashToken(refreshToken)).Return(token, nil)
 				mockUserRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
+				mockTokenRepo.On("DeleteByToken", mock.Anything, model.HashToken(refreshToken)).Return(nil)
 				mockTokenRepo.On("DeleteByToken", mock.Anything, refreshToken).Return(nil)
 				mockTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Token")).Return(nil)
 				
@@ -364,6 +454,138 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	}
 }
 
+func TestAuthService_Login_BindsDPoPKey(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepositoryInterface)
+	mockTokenRepo := new(mocks.MockTokenRepositoryInterface)
+
+	userID := primitive.NewObjectID()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	user := &model.User{ID: userID, Email: "test@example.com", Password: string(hashedPassword), Active: true}
+
+	mockUserRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
+	mockUserRepo.On("RecordLogin", mock.Anything, userID, mock.AnythingOfType("model.LoginRecord")).Return(nil)
+	mockTokenRepo.On("DeleteByUserID", mock.Anything, userID, "refresh").Return(nil)
+	mockTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token *model.Token) bool {
+		return token.Type == "refresh" && token.JKT == "client-jkt"
+	})).Return(nil).Once()
+	mockTokenRepo.On("IsBlacklisted", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+
+	authService := service.NewAuthService(mockUserRepo, new(mocks.MockRoleRepositoryInterface), mockTokenRepo, testAuthConfig())
+	ctx := dpop.NewContext(context.Background(), "client-jkt")
+
+	tokenPair, _, err := authService.Login(ctx, "test@example.com", "password123")
+	assert.NoError(t, err)
+
+	claims, err := authService.ValidateToken(context.Background(), tokenPair.AccessToken)
+	assert.NoError(t, err)
+	assert.True(t, claims.IsBound())
+	assert.Equal(t, "client-jkt", claims.Confirmation.JKT)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestAuthService_Login_LastLoginFailure(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepositoryInterface)
+	mockTokenRepo := new(mocks.MockTokenRepositoryInterface)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com", Password: string(hashedPassword), Active: true}
+
+	mockUserRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
+	mockUserRepo.On("RecordLogin", mock.Anything, user.ID, mock.AnythingOfType("model.LoginRecord")).Return(errors.New("write failed"))
+	mockTokenRepo.On("DeleteByUserID", mock.Anything, user.ID, "refresh").Return(nil)
+	mockTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Token")).Return(nil)
+
+	authService := service.NewAuthService(mockUserRepo, new(mocks.MockRoleRepositoryInterface), mockTokenRepo, testAuthConfig())
+
+	tokenPair, loggedIn, err := authService.Login(context.Background(), "test@example.com", "password123")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokenPair)
+	assert.Nil(t, loggedIn.LastLoginAt)
+}
+
+func TestAuthService_Login_RecordsLogin(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepositoryInterface)
+	mockTokenRepo := new(mocks.MockTokenRepositoryInterface)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	earlier := model.LoginRecord{At: time.Now().Add(-48 * time.Hour), IP: "198.51.100.2"}
+	user := &model.User{
+		ID:           primitive.NewObjectID(),
+		Email:        "test@example.com",
+		Password:     string(hashedPassword),
+		Active:       true,
+		RecentLogins: []model.LoginRecord{earlier},
+	}
+
+	mockUserRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
+	mockUserRepo.On("RecordLogin", mock.Anything, user.ID, mock.MatchedBy(func(login model.LoginRecord) bool {
+		return login.IP == "203.0.113.7" && time.Since(login.At) < time.Minute
+	})).Return(nil)
+	mockTokenRepo.On("DeleteByUserID", mock.Anything, user.ID, "refresh").Return(nil)
+	mockTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Token")).Return(nil)
+
+	authService := service.NewAuthService(mockUserRepo, new(mocks.MockRoleRepositoryInterface), mockTokenRepo, testAuthConfig())
+
+	ctx := service.ContextWithClientIP(context.Background(), "203.0.113.7")
+	_, loggedIn, err := authService.Login(ctx, "test@example.com", "password123")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", loggedIn.LastLoginIP)
+	require.NotNil(t, loggedIn.LastLoginAt)
+	require.Len(t, loggedIn.RecentLogins, 2)
+	assert.Equal(t, &earlier, loggedIn.PreviousLogin())
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestAuthService_RefreshToken_BoundToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		thumbprint    string
+		expectedError error
+	}{
+		{name: "proof from the bound key", thumbprint: "client-jkt"},
+		{name: "proof from another key", thumbprint: "other-jkt", expectedError: service.ErrInvalidToken},
+		{name: "no proof", expectedError: service.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepositoryInterface)
+			mockTokenRepo := new(mocks.MockTokenRepositoryInterface)
+			tokenService := service.NewTokenService(mockTokenRepo, service.NewTokenConfigFromAuthConfig(testAuthConfig()))
+			authService := service.NewAuthServiceWithTokenService(mockUserRepo, new(mocks.MockRoleRepositoryInterface), tokenService)
+
+			user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com", Active: true}
+			mockTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Token")).Return(nil)
+			tokenPair, err := tokenService.GenerateTokenPair(dpop.NewContext(context.Background(), "client-jkt"), user)
+			assert.NoError(t, err)
+
+			mockTokenRepo.On("FindByToken", mock.Anything, model.HashToken(tokenPair.RefreshToken)).Return(&model.Token{
+				UserID:    user.ID,
+				Token:     model.HashToken(tokenPair.RefreshToken),
+				Type:      "refresh",
+				JKT:       "client-jkt",
+				ExpiresAt: time.Now().Add(time.Hour),
+			}, nil)
+			mockUserRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil).Maybe()
+			mockTokenRepo.On("DeleteByToken", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			ctx := context.Background()
+			if tt.thumbprint != "" {
+				ctx = dpop.NewContext(ctx, tt.thumbprint)
+			}
+			refreshed, err := authService.RefreshToken(ctx, tokenPair.RefreshToken)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				mockTokenRepo.AssertNotCalled(t, "DeleteByToken", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, refreshed.AccessToken)
+			}
+		})
+	}
+}
+
 func TestAuthService_ValidateToken(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -386,10 +608,8 @@ func TestAuthService_ValidateToken(t *testing.T) {
 			expectedError: service.ErrTokenBlacklisted,
 		},
 		{
-			name: "invalid token format",
-			setupMocks: func(mockTokenRepo *mocks.MockTokenRepositoryInterface) {
-				mockTokenRepo.On("IsBlacklisted", mock.Anything, "invalid").Return(false, nil)
-			},
+			name:          "invalid token format",
+			setupMocks:    func(mockTokenRepo *mocks.MockTokenRepositoryInterface) {},
 			expectedError: service.ErrInvalidToken,
 		},
 	}
@@ -405,7 +625,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 			// Generate a valid token for testing
 			var tokenString string
 			switch tt.name {
-			case "valid token":
+			case "valid token", "blacklisted token":
 				userID := primitive.NewObjectID()
 				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 				user := &model.User{
@@ -416,15 +636,14 @@ func TestAuthService_ValidateToken(t *testing.T) {
 					Active:   true,
 				}
 				mockUserRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
+				mockUserRepo.On("RecordLogin", mock.Anything, userID, mock.AnythingOfType("model.LoginRecord")).Return(nil)
 				mockTokenRepo.On("DeleteByUserID", mock.Anything, userID, "refresh").Return(nil)
 				mockTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Token")).Return(nil)
 
 				tokenPair, _, _ := authService.Login(context.Background(), "test@example.com", "password123")
 				tokenString = tokenPair.AccessToken
-			case "invalid token format":
-				tokenString = "invalid"
 			default:
-				tokenString = "blacklisted-token"
+				tokenString = "invalid"
 			}
 
 			tt.setupMocks(mockTokenRepo)
@@ -432,7 +651,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 			claims, err := authService.ValidateToken(context.Background(), tokenString)
 
 			if tt.expectedError != nil {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
 				assert.Nil(t, claims)
 			} else {
 				assert.NoError(t, err)
@@ -467,6 +686,7 @@ func TestAuthService_Logout(t *testing.T) {
 			accessToken:  "",
 			refreshToken: "valid-refresh-token",
 			setupMocks: func(mockTokenRepo *mocks.MockTokenRepositoryInterface) {
+				mockTokenRepo.On("DeleteByToken", mock.Anything, model.HashToken("valid-refresh-token")).Return(nil)
 				mockTokenRepo.On("DeleteByToken", mock.Anything, "valid-refresh-token").Return(nil)
 			},
 			expectedError: nil,
@@ -478,6 +698,7 @@ func TestAuthService_Logout(t *testing.T) {
 			setupMocks: func(mockTokenRepo *mocks.MockTokenRepositoryInterface) {
 				// InvalidateToken will fail due to invalid JWT format
 				// But refresh token deletion should still succeed
+				mockTokenRepo.On("DeleteByToken", mock.Anything, model.HashToken("valid-refresh-token")).Return(nil)
 				mockTokenRepo.On("DeleteByToken", mock.Anything, "valid-refresh-token").Return(nil)
 			},
 			expectedError: errors.New("invalidate access token"), // Now returns error for invalid tokens
@@ -487,7 +708,7 @@ func TestAuthService_Logout(t *testing.T) {
 			accessToken:  "",
 			refreshToken: "valid-refresh-token",
 			setupMocks: func(mockTokenRepo *mocks.MockTokenRepositoryInterface) {
-				mockTokenRepo.On("DeleteByToken", mock.Anything, "valid-refresh-token").Return(errors.New("deletion failed"))
+				mockTokenRepo.On("DeleteByToken", mock.Anything, model.HashToken("valid-refresh-token")).Return(errors.New("deletion failed"))
 			},
 			expectedError: errors.New("delete refresh token"),
 		},
@@ -516,3 +737,283 @@ func TestAuthService_Logout(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthService_RevokeUserTokens(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	versions := service.NewTokenVersionCache(userRepo, time.Minute)
+	authService := service.NewAuthService(userRepo, mocks.NewMockRoleRepositoryInterface(t), tokenRepo, testAuthConfig(),
+		service.WithTokenVersions(versions))
+	ctx := context.Background()
+
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com", TokenVersion: 2}
+	tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+	tokenPair, err := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(testAuthConfig())).
+		GenerateTokenPair(ctx, user)
+	assert.NoError(t, err)
+
+	// Issued at the current version
+	userRepo.EXPECT().GetTokenVersion(mock.Anything, user.ID).Return(2, nil).Once()
+	tokenRepo.EXPECT().IsBlacklisted(mock.Anything, tokenPair.AccessToken).Return(false, nil)
+	claims, err := authService.ValidateToken(ctx, tokenPair.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, claims.TokenVersion)
+
+	userRepo.EXPECT().IncrementTokenVersion(mock.Anything, user.ID).Return(3, nil)
+	tokenRepo.EXPECT().DeleteByUserID(mock.Anything, user.ID, "refresh").Return(nil)
+	assert.NoError(t, authService.RevokeUserTokens(ctx, user.ID))
+
+	// The new version is cached, so no further lookup is needed
+	_, err = authService.ValidateToken(ctx, tokenPair.AccessToken)
+	assert.ErrorIs(t, err, service.ErrTokenRevoked)
+}
+
+func TestAuthService_RevokeUserTokens_IncrementFails(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	authService := service.NewAuthService(userRepo, mocks.NewMockRoleRepositoryInterface(t), tokenRepo, testAuthConfig(),
+		service.WithTokenVersions(service.NewTokenVersionCache(userRepo, time.Minute)))
+
+	userID := primitive.NewObjectID()
+	userRepo.EXPECT().IncrementTokenVersion(mock.Anything, userID).Return(0, errors.New("db down"))
+
+	err := authService.RevokeUserTokens(context.Background(), userID)
+	assert.Error(t, err)
+	tokenRepo.AssertNotCalled(t, "DeleteByUserID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthService_VersionRevocationSkipsBlacklist(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	cfg := testAuthConfig()
+	cfg.TokenRevocation = service.TokenRevocationVersion
+	authService := service.NewAuthService(userRepo, mocks.NewMockRoleRepositoryInterface(t), tokenRepo, cfg,
+		service.WithTokenVersions(service.NewTokenVersionCache(userRepo, time.Minute)))
+	ctx := context.Background()
+
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+	tokenRepo.EXPECT().Create(mock.Anything, mock.MatchedBy(func(token *model.Token) bool {
+		return token.Type == "refresh"
+	})).Return(nil)
+	tokenPair, err := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(cfg)).GenerateTokenPair(ctx, user)
+	assert.NoError(t, err)
+
+	userRepo.EXPECT().GetTokenVersion(mock.Anything, user.ID).Return(0, nil).Once()
+	for i := 0; i < 3; i++ {
+		_, err := authService.ValidateToken(ctx, tokenPair.AccessToken)
+		assert.NoError(t, err)
+	}
+
+	// Logout only removes the refresh token; the access token expires on its own
+	tokenRepo.EXPECT().DeleteByToken(mock.Anything, model.HashToken(tokenPair.RefreshToken)).Return(nil)
+	tokenRepo.EXPECT().DeleteByToken(mock.Anything, tokenPair.RefreshToken).Return(nil)
+	assert.NoError(t, authService.Logout(ctx, tokenPair.AccessToken, tokenPair.RefreshToken))
+	tokenRepo.AssertNotCalled(t, "IsBlacklisted", mock.Anything, mock.Anything)
+}
+
+func TestTokenService_DegradedMode(t *testing.T) {
+	openErr := &circuitbreaker.OpenError{Name: "mongodb-auth", RetryAfter: time.Minute}
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+
+	tests := []struct {
+		name     string
+		degraded bool
+		wantErr  error
+	}{
+		{name: "accepts tokens unchecked", degraded: true},
+		{name: "fails without degraded mode", wantErr: circuitbreaker.ErrCircuitOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewMockUserRepositoryInterface(t)
+			tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+			cfg := service.NewTokenConfigFromAuthConfig(testAuthConfig())
+			cfg.DegradedMode = tt.degraded
+			tokenService := service.NewTokenService(tokenRepo, cfg,
+				service.WithTokenVersions(service.NewTokenVersionCache(userRepo, time.Minute)))
+			ctx := context.Background()
+
+			tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+			tokenPair, err := tokenService.GenerateTokenPair(ctx, user)
+			require.NoError(t, err)
+
+			tokenRepo.EXPECT().IsBlacklisted(mock.Anything, mock.Anything).Return(false, openErr)
+			userRepo.EXPECT().GetTokenVersion(mock.Anything, user.ID).Return(0, openErr).Maybe()
+			blacklistSkips := promtestutil.ToFloat64(metrics.DegradedTokenChecksTotal.WithLabelValues("blacklist"))
+
+			claims, err := tokenService.ValidateAccessToken(ctx, tokenPair.AccessToken)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, blacklistSkips, promtestutil.ToFloat64(metrics.DegradedTokenChecksTotal.WithLabelValues("blacklist")))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, user.Email, claims.Email)
+			assert.Equal(t, blacklistSkips+1, promtestutil.ToFloat64(metrics.DegradedTokenChecksTotal.WithLabelValues("blacklist")))
+
+			// Signatures are still checked
+			_, err = tokenService.ValidateAccessToken(ctx, tokenPair.AccessToken+"x")
+			assert.ErrorIs(t, err, service.ErrInvalidToken)
+		})
+	}
+}
+
+func TestTokenService_DegradedModeNeedsOpenCircuit(t *testing.T) {
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	cfg := service.NewTokenConfigFromAuthConfig(testAuthConfig())
+	cfg.DegradedMode = true
+	tokenService := service.NewTokenService(tokenRepo, cfg)
+
+	tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+	tokenPair, err := tokenService.GenerateTokenPair(context.Background(), &model.User{ID: primitive.NewObjectID()})
+	require.NoError(t, err)
+
+	// A single failed lookup is not an outage
+	tokenRepo.EXPECT().IsBlacklisted(mock.Anything, tokenPair.AccessToken).Return(false, errors.New("connection reset"))
+	_, err = tokenService.ValidateAccessToken(context.Background(), tokenPair.AccessToken)
+	assert.EqualError(t, err, "connection reset")
+}
+
+func TestTokenService_ForgedTokenSkipsBlacklist(t *testing.T) {
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	tokenService := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(testAuthConfig()))
+
+	// A token signed with another key is rejected before any lookup, so it
+	// cannot be cached as not blacklisted
+	forgedCfg := testAuthConfig()
+	forgedCfg.JWTSecretKey = "forged-secret-key"
+	tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+	forged, err := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(forgedCfg)).
+		GenerateTokenPair(context.Background(), &model.User{ID: primitive.NewObjectID()})
+	require.NoError(t, err)
+
+	_, err = tokenService.ValidateAccessToken(context.Background(), forged.AccessToken)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+	tokenRepo.AssertNotCalled(t, "IsBlacklisted", mock.Anything, mock.Anything)
+}
+
+func TestNewTokenService_VersionRevocationRequiresTokenVersions(t *testing.T) {
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	cfg := testAuthConfig()
+	cfg.TokenRevocation = service.TokenRevocationVersion
+	tokenService := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(cfg))
+
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+	tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+	tokenPair, err := tokenService.GenerateTokenPair(context.Background(), user)
+	assert.NoError(t, err)
+
+	// Falls back to the blacklist, the only way to revoke access tokens here
+	tokenRepo.EXPECT().IsBlacklisted(mock.Anything, tokenPair.AccessToken).Return(true, nil)
+	_, err = tokenService.ValidateAccessToken(context.Background(), tokenPair.AccessToken)
+	assert.ErrorIs(t, err, service.ErrTokenBlacklisted)
+}
+
+func TestTokenService_ServiceTokens(t *testing.T) {
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":"platform-1","use":"sig","alg":"RS256","n":%q,"e":"AQAB"}]}`,
+			base64.RawURLEncoding.EncodeToString(signingKey.N.Bytes()))
+	}))
+	defer server.Close()
+
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	tokenService := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(testAuthConfig()),
+		service.WithServiceTokens(jwks.New(jwks.Config{URL: server.URL}), service.ServiceTokenConfig{
+			Issuer:   "https://platform.internal",
+			Audience: "pack-service",
+			Roles:    []string{"service-role"},
+		}))
+	ctx := context.Background()
+
+	sign := func(key *rsa.PrivateKey, kid string, edit func(jwt.MapClaims)) string {
+		claims := jwt.MapClaims{
+			"iss": "https://platform.internal",
+			"aud": []string{"pack-service", "other-service"},
+			"sub": "billing",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+		if edit != nil {
+			edit(claims)
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	t.Run("valid service token", func(t *testing.T) {
+		claims, err := tokenService.ValidateAccessToken(ctx, sign(signingKey, "platform-1", nil))
+		require.NoError(t, err)
+		assert.True(t, claims.IsService())
+		assert.Equal(t, "billing", claims.Service)
+		assert.Equal(t, []string{"service-role"}, claims.Roles)
+		assert.True(t, claims.UserID.IsZero())
+	})
+
+	for name, tokenString := range map[string]string{
+		"another issuer":    sign(signingKey, "platform-1", func(c jwt.MapClaims) { c["iss"] = "https://elsewhere" }),
+		"another audience":  sign(signingKey, "platform-1", func(c jwt.MapClaims) { c["aud"] = "other-service" }),
+		"expired":           sign(signingKey, "platform-1", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }),
+		"no expiry":         sign(signingKey, "platform-1", func(c jwt.MapClaims) { delete(c, "exp") }),
+		"no subject":        sign(signingKey, "platform-1", func(c jwt.MapClaims) { delete(c, "sub") }),
+		"unknown key":       sign(signingKey, "platform-2", nil),
+		"signed by another": sign(otherKey, "platform-1", nil),
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := tokenService.ValidateAccessToken(ctx, tokenString)
+			assert.ErrorIs(t, err, service.ErrInvalidToken)
+		})
+	}
+
+	t.Run("user tokens are still verified with the secret key", func(t *testing.T) {
+		user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+		tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+		tokenPair, err := tokenService.GenerateTokenPair(ctx, user)
+		require.NoError(t, err)
+
+		tokenRepo.EXPECT().IsBlacklisted(mock.Anything, tokenPair.AccessToken).Return(false, nil)
+		claims, err := tokenService.ValidateAccessToken(ctx, tokenPair.AccessToken)
+		require.NoError(t, err)
+		assert.False(t, claims.IsService())
+		assert.Equal(t, user.ID, claims.UserID)
+	})
+
+	t.Run("rejected without a key set", func(t *testing.T) {
+		_, err := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(testAuthConfig())).
+			ValidateAccessToken(ctx, sign(signingKey, "platform-1", nil))
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+	})
+}
+
+func TestTokenService_Deterministic(t *testing.T) {
+	testutil.Deterministic(t, 1)
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+	tokenRepo.EXPECT().IsBlacklisted(mock.Anything, mock.Anything).Return(false, nil)
+	tokenService := service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(testAuthConfig()))
+	ctx := context.Background()
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+
+	first, err := tokenService.GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	second, err := tokenService.GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.AccessToken, second.AccessToken, "nonces tell apart tokens issued at the same instant")
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// Tokens issued at the frozen instant stay valid on it
+	claims, err := tokenService.ValidateAccessToken(ctx, first.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	testutil.Deterministic(t, 1)
+	replayed, err := tokenService.GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, first, replayed, "the same seed issues the same tokens")
+}
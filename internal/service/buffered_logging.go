@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// LogOverflowPolicy decides what CreateLog does when the log buffer is full.
+type LogOverflowPolicy string
+
+const (
+	// LogOverflowDropNewest drops the entry being logged.
+	LogOverflowDropNewest LogOverflowPolicy = "drop_newest"
+	// LogOverflowDropOldest drops the oldest buffered entry to make room.
+	LogOverflowDropOldest LogOverflowPolicy = "drop_oldest"
+	// LogOverflowBlock waits for room until the caller's context is done,
+	// then drops the entry.
+	LogOverflowBlock LogOverflowPolicy = "block"
+)
+
+const (
+	// DefaultLogBufferSize is the number of log entries buffered when no
+	// size is configured.
+	DefaultLogBufferSize = 10000
+	// DefaultLogBatchSize is the number of log entries written at once when
+	// no batch size is configured.
+	DefaultLogBatchSize = 500
+	// DefaultLogFlushInterval is how often buffered log entries are written
+	// when no interval is configured.
+	DefaultLogFlushInterval = time.Second
+
+	// logFlushTimeout bounds the write of one batch.
+	logFlushTimeout = 5 * time.Second
+)
+
+var (
+	// ErrLogBufferFull is returned by CreateLog when the entry was dropped
+	// because the log buffer was full.
+	ErrLogBufferFull = errors.New("log buffer full")
+	// ErrInvalidLogOverflowPolicy is returned for an unknown overflow policy.
+	ErrInvalidLogOverflowPolicy = errors.New("invalid log overflow policy")
+)
+
+// ParseLogOverflowPolicy returns the policy named s; empty is drop_newest.
+func ParseLogOverflowPolicy(s string) (LogOverflowPolicy, error) {
+	switch policy := LogOverflowPolicy(s); policy {
+	case "":
+		return LogOverflowDropNewest, nil
+	case LogOverflowDropNewest, LogOverflowDropOldest, LogOverflowBlock:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidLogOverflowPolicy, s)
+	}
+}
+
+// BufferedLoggingConfig configures a BufferedLoggingService. Zero values use
+// the defaults.
+type BufferedLoggingConfig struct {
+	BufferSize     int
+	BatchSize      int
+	FlushInterval  time.Duration
+	OverflowPolicy LogOverflowPolicy
+}
+
+// BufferedLoggingService is a LoggingService whose CreateLog only queues the
+// entry, so callers never wait on MongoDB. A background goroutine writes the
+// queued entries in batches every flush interval, or as soon as a batch is
+// full. The other methods call the wrapped service directly.
+type BufferedLoggingService struct {
+	inner     LoggingService
+	policy    LogOverflowPolicy
+	batchSize int
+	entries   chan *model.LogEntry
+
+	// mu is held for reading while entries are queued, so Close knows no
+	// writer is left once it holds it for writing.
+	mu     sync.RWMutex
+	closed bool
+
+	closeOnce sync.Once
+	stopCh    chan struct{}
+	drainCh   chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewBufferedLoggingService creates a buffered logging service writing to
+// inner. Call Close to write the remaining entries.
+func NewBufferedLoggingService(inner LoggingService, cfg BufferedLoggingConfig) *BufferedLoggingService {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultLogBufferSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultLogBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultLogFlushInterval
+	}
+	if cfg.OverflowPolicy == "" {
+		cfg.OverflowPolicy = LogOverflowDropNewest
+	}
+
+	s := &BufferedLoggingService{
+		inner:     inner,
+		policy:    cfg.OverflowPolicy,
+		batchSize: cfg.BatchSize,
+		entries:   make(chan *model.LogEntry, cfg.BufferSize),
+		stopCh:    make(chan struct{}),
+		drainCh:   make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+	go s.flushLoop(cfg.FlushInterval)
+	return s
+}
+
+// CreateLog queues entry. When the buffer is full the overflow policy
+// applies, and ErrLogBufferFull is returned if entry was dropped. Once the
+// service is closed entries are written directly.
+func (s *BufferedLoggingService) CreateLog(ctx context.Context, entry *model.LogEntry) error {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return s.inner.CreateLog(ctx, entry)
+	}
+	queued, err := s.enqueue(ctx, entry)
+	s.mu.RUnlock()
+
+	if !queued && err == nil {
+		// Closing while waiting for room
+		return s.inner.CreateLog(ctx, entry)
+	}
+	return err
+}
+
+func (s *BufferedLoggingService) enqueue(ctx context.Context, entry *model.LogEntry) (bool, error) {
+	defer func() { metrics.SetLogBufferDepth(len(s.entries)) }()
+
+	select {
+	case s.entries <- entry:
+		return true, nil
+	default:
+	}
+
+	switch s.policy {
+	case LogOverflowDropOldest:
+		for {
+			select {
+			case <-s.entries:
+				metrics.RecordLogBufferDropped("full", 1)
+			default:
+			}
+			select {
+			case s.entries <- entry:
+				return true, nil
+			default:
+			}
+		}
+	case LogOverflowBlock:
+		select {
+		case s.entries <- entry:
+			return true, nil
+		case <-s.stopCh:
+			return false, nil
+		case <-ctx.Done():
+			metrics.RecordLogBufferDropped("timeout", 1)
+			return false, ErrLogBufferFull
+		}
+	default:
+		metrics.RecordLogBufferDropped("full", 1)
+		return false, ErrLogBufferFull
+	}
+}
+
+// CreateLogs stores multiple log entries in bulk. They already form a batch,
+// so they are written directly.
+func (s *BufferedLoggingService) CreateLogs(ctx context.Context, entries []*model.LogEntry) error {
+	return s.inner.CreateLogs(ctx, entries)
+}
+
+// QueryLogs retrieves log entries matching the query options. Entries still
+// buffered are not returned.
+func (s *BufferedLoggingService) QueryLogs(ctx context.Context, opts model.LogQueryOptions) ([]model.LogEntry, error) {
+	return s.inner.QueryLogs(ctx, opts)
+}
+
+// CountLogs returns the count of log entries matching the query options.
+func (s *BufferedLoggingService) CountLogs(ctx context.Context, opts model.LogQueryOptions) (int64, error) {
+	return s.inner.CountLogs(ctx, opts)
+}
+
+// ExportLogs calls fn for every stored log entry matching the query options.
+func (s *BufferedLoggingService) ExportLogs(ctx context.Context, opts model.LogQueryOptions, fn func(model.LogEntry) error) error {
+	return s.inner.ExportLogs(ctx, opts, fn)
+}
+
+// CalculationsSinceConfigVersion returns per-version calculation counts for
+// pack size config versions >= sinceVersion.
+func (s *BufferedLoggingService) CalculationsSinceConfigVersion(ctx context.Context, sinceVersion int) ([]model.ConfigVersionImpact, error) {
+	return s.inner.CalculationsSinceConfigVersion(ctx, sinceVersion)
+}
+
+// PurgeLogs deletes the log entries retention no longer keeps.
+func (s *BufferedLoggingService) PurgeLogs(ctx context.Context, retention model.LogRetention) (int64, error) {
+	return s.inner.PurgeLogs(ctx, retention)
+}
+
+// Close stops buffering and writes the queued entries. It returns ctx's
+// error if they are not written before ctx is done; the write goes on in
+// the background.
+func (s *BufferedLoggingService) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		close(s.stopCh)
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		close(s.drainCh)
+	})
+
+	select {
+	case <-s.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *BufferedLoggingService) flushLoop(interval time.Duration) {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]*model.LogEntry, 0, s.batchSize)
+	for {
+		select {
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				batch = s.write(batch)
+			}
+		case <-ticker.C:
+			batch = s.write(batch)
+		case <-s.drainCh:
+			for {
+				select {
+				case entry := <-s.entries:
+					batch = append(batch, entry)
+					if len(batch) >= s.batchSize {
+						batch = s.write(batch)
+					}
+				default:
+					s.write(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// write stores batch and returns an empty batch to fill next. A batch that
+// fails to write is dropped: the logs circuit breaker is likely open, and
+// keeping it would only delay the entries behind it.
+func (s *BufferedLoggingService) write(batch []*model.LogEntry) []*model.LogEntry {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), logFlushTimeout)
+	defer cancel()
+
+	err := s.inner.CreateLogs(ctx, batch)
+	metrics.RecordLogBufferFlush(err)
+	if err != nil {
+		metrics.RecordLogBufferDropped("write_failed", len(batch))
+		log.Warn().Err(err).Int("entries", len(batch)).Msg("Failed to write buffered logs")
+	}
+	metrics.SetLogBufferDepth(len(s.entries))
+	return make([]*model.LogEntry, 0, s.batchSize)
+}
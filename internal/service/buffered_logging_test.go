@@ -0,0 +1,222 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// blockedWriter makes CreateLogs wait until release is closed, so entries
+// stay queued.
+func blockedWriter(inner *mocks.MockLoggingService) (started, release chan struct{}, written func() []string) {
+	started = make(chan struct{}, 1)
+	release = make(chan struct{})
+	var mu sync.Mutex
+	var messages []string
+	inner.EXPECT().CreateLogs(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, entries []*model.LogEntry) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			mu.Lock()
+			defer mu.Unlock()
+			for _, entry := range entries {
+				messages = append(messages, entry.Message)
+			}
+			return nil
+		}).Maybe()
+	return started, release, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), messages...)
+	}
+}
+
+func TestParseLogOverflowPolicy(t *testing.T) {
+	policy, err := service.ParseLogOverflowPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, service.LogOverflowDropNewest, policy)
+
+	policy, err = service.ParseLogOverflowPolicy("block")
+	require.NoError(t, err)
+	assert.Equal(t, service.LogOverflowBlock, policy)
+
+	_, err = service.ParseLogOverflowPolicy("drop_all")
+	assert.ErrorIs(t, err, service.ErrInvalidLogOverflowPolicy)
+}
+
+func TestBufferedLoggingService_WritesBatches(t *testing.T) {
+	inner := mocks.NewMockLoggingService(t)
+	batches := make(chan int, 10)
+	inner.EXPECT().CreateLogs(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, entries []*model.LogEntry) error {
+			batches <- len(entries)
+			return nil
+		})
+
+	s := service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{BatchSize: 2, FlushInterval: time.Hour})
+	for range 3 {
+		require.NoError(t, s.CreateLog(context.Background(), &model.LogEntry{Message: "request"}))
+	}
+
+	select {
+	case n := <-batches:
+		assert.Equal(t, 2, n, "a full batch is written before the interval")
+	case <-time.After(time.Second):
+		t.Fatal("full batch was not written")
+	}
+
+	require.NoError(t, s.Close(context.Background()))
+	assert.Equal(t, 1, <-batches, "the rest is written on close")
+}
+
+func TestBufferedLoggingService_FlushInterval(t *testing.T) {
+	inner := mocks.NewMockLoggingService(t)
+	written := make(chan []*model.LogEntry, 1)
+	inner.EXPECT().CreateLogs(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, entries []*model.LogEntry) error {
+			written <- entries
+			return nil
+		}).Once()
+
+	s := service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{FlushInterval: 10 * time.Millisecond})
+	defer func() { _ = s.Close(context.Background()) }()
+	entry := &model.LogEntry{Message: "request"}
+	require.NoError(t, s.CreateLog(context.Background(), entry))
+
+	select {
+	case entries := <-written:
+		assert.Equal(t, []*model.LogEntry{entry}, entries)
+	case <-time.After(time.Second):
+		t.Fatal("entry was not written on the interval")
+	}
+}
+
+func TestBufferedLoggingService_Overflow(t *testing.T) {
+	log := func(s *service.BufferedLoggingService, ctx context.Context, message string) error {
+		return s.CreateLog(ctx, &model.LogEntry{Message: message})
+	}
+
+	// The first entry is taken by the blocked write; the buffer holds one more.
+	fill := func(t *testing.T, policy service.LogOverflowPolicy) (*service.BufferedLoggingService, chan struct{}, func() []string) {
+		inner := mocks.NewMockLoggingService(t)
+		started, release, written := blockedWriter(inner)
+		s := service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{
+			BufferSize:     1,
+			BatchSize:      1,
+			FlushInterval:  time.Hour,
+			OverflowPolicy: policy,
+		})
+		require.NoError(t, log(s, context.Background(), "first"))
+		<-started
+		require.NoError(t, log(s, context.Background(), "second"))
+		return s, release, written
+	}
+
+	t.Run("drop newest", func(t *testing.T) {
+		s, release, written := fill(t, service.LogOverflowDropNewest)
+		assert.ErrorIs(t, log(s, context.Background(), "third"), service.ErrLogBufferFull)
+
+		close(release)
+		require.NoError(t, s.Close(context.Background()))
+		assert.Equal(t, []string{"first", "second"}, written())
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		s, release, written := fill(t, service.LogOverflowDropOldest)
+		assert.NoError(t, log(s, context.Background(), "third"))
+
+		close(release)
+		require.NoError(t, s.Close(context.Background()))
+		assert.Equal(t, []string{"first", "third"}, written())
+	})
+
+	t.Run("block until the context is done", func(t *testing.T) {
+		s, release, written := fill(t, service.LogOverflowBlock)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, log(s, ctx, "third"), service.ErrLogBufferFull)
+
+		close(release)
+		require.NoError(t, s.Close(context.Background()))
+		assert.Equal(t, []string{"first", "second"}, written())
+	})
+
+	t.Run("block until there is room", func(t *testing.T) {
+		s, release, written := fill(t, service.LogOverflowBlock)
+		done := make(chan error, 1)
+		go func() { done <- log(s, context.Background(), "third") }()
+
+		close(release)
+		require.NoError(t, <-done)
+		require.NoError(t, s.Close(context.Background()))
+		assert.Equal(t, []string{"first", "second", "third"}, written())
+	})
+}
+
+func TestBufferedLoggingService_DropsFailedBatch(t *testing.T) {
+	inner := mocks.NewMockLoggingService(t)
+	inner.EXPECT().CreateLogs(mock.Anything, mock.Anything).Return(errors.New("circuit open")).Once()
+
+	s := service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{FlushInterval: time.Hour})
+	require.NoError(t, s.CreateLog(context.Background(), &model.LogEntry{Message: "request"}))
+	require.NoError(t, s.Close(context.Background()))
+}
+
+func TestBufferedLoggingService_Close(t *testing.T) {
+	t.Run("writes directly once closed", func(t *testing.T) {
+		inner := mocks.NewMockLoggingService(t)
+		entry := &model.LogEntry{Message: "late"}
+		inner.EXPECT().CreateLog(mock.Anything, entry).Return(nil).Once()
+
+		s := service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{})
+		require.NoError(t, s.Close(context.Background()))
+		require.NoError(t, s.Close(context.Background()), "closing again is a no-op")
+		assert.NoError(t, s.CreateLog(context.Background(), entry))
+	})
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		inner := mocks.NewMockLoggingService(t)
+		started, release, _ := blockedWriter(inner)
+		defer close(release)
+
+		s := service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{BatchSize: 1})
+		require.NoError(t, s.CreateLog(context.Background(), &model.LogEntry{Message: "request"}))
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, s.Close(ctx), context.DeadlineExceeded)
+	})
+}
+
+func TestBufferedLoggingService_Delegates(t *testing.T) {
+	inner := mocks.NewMockLoggingService(t)
+	s := service.NewBufferedLoggingService(inner, service.BufferedLoggingConfig{})
+	defer func() { _ = s.Close(context.Background()) }()
+
+	opts := model.LogQueryOptions{Level: "error"}
+	inner.EXPECT().QueryLogs(mock.Anything, opts).Return([]model.LogEntry{{Message: "stored"}}, nil).Once()
+	inner.EXPECT().CountLogs(mock.Anything, opts).Return(int64(1), nil).Once()
+	inner.EXPECT().CreateLogs(mock.Anything, mock.Anything).Return(nil).Once()
+
+	entries, err := s.QueryLogs(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	count, err := s.CountLogs(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.NoError(t, s.CreateLogs(context.Background(), []*model.LogEntry{{Message: "bulk"}}))
+}
@@ -2,6 +2,7 @@
 package service
 
 import (
+	"encoding/binary"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -86,23 +87,28 @@ func NewShardedCache(capacity int, ttl time.Duration, numShards int) *ShardedCac
 }
 
 // getShard returns the shard for the given key.
-func (sc *ShardedCache) getShard(key int) *ttlCache {
-	// Simple hash function for integers
-	return sc.shards[key&sc.shardMask]
+func (sc *ShardedCache) getShard(key cache.Key) *ttlCache {
+	return sc.shards[keyBits(key)&uint64(sc.shardMask)]
+}
+
+// keyBits folds key into an integer. Consecutive quantities with the same
+// pack sizes differ in their low bits, so they spread evenly over shards.
+func keyBits(key cache.Key) uint64 {
+	return uint64(key.Items) ^ binary.BigEndian.Uint64(key.PackSizes[:8])
 }
 
 // Get retrieves a value from the appropriate shard.
-func (sc *ShardedCache) Get(key int) (model.PackResult, bool) {
+func (sc *ShardedCache) Get(key cache.Key) (model.PackResult, bool) {
 	return sc.getShard(key).Get(key)
 }
 
 // Set stores a value in the appropriate shard.
-func (sc *ShardedCache) Set(key int, value model.PackResult) {
+func (sc *ShardedCache) Set(key cache.Key, value model.PackResult) {
 	sc.getShard(key).Set(key, value)
 }
 
 // Invalidate removes a key from the appropriate shard.
-func (sc *ShardedCache) Invalidate(key int) {
+func (sc *ShardedCache) Invalidate(key cache.Key) {
 	sc.getShard(key).Invalidate(key)
 }
 
@@ -120,6 +126,56 @@ func (sc *ShardedCache) Stop() {
 	}
 }
 
+// HotEntries returns up to limit unexpired entries, taking the most recently
+// used entries of each shard in turn.
+func (sc *ShardedCache) HotEntries(limit int) []cache.Entry {
+	perShard := (limit + sc.numShards - 1) / sc.numShards
+	entries := make([]cache.Entry, 0, limit)
+	for _, shard := range sc.shards {
+		entries = append(entries, shard.HotEntries(min(perShard, limit-len(entries)))...)
+		if len(entries) >= limit {
+			break
+		}
+	}
+	return entries
+}
+
+// Restore adds entries to their shards and returns how many it added.
+func (sc *ShardedCache) Restore(entries []cache.Entry) int {
+	byShard := make(map[*ttlCache][]cache.Entry, sc.numShards)
+	for _, entry := range entries {
+		shard := sc.getShard(entry.Key)
+		byShard[shard] = append(byShard[shard], entry)
+	}
+	restored := 0
+	for shard, shardEntries := range byShard {
+		restored += shard.Restore(shardEntries)
+	}
+	return restored
+}
+
+// setCompressor compresses large values in every shard.
+func (sc *ShardedCache) setCompressor(compressor *cache.Compressor) {
+	for _, shard := range sc.shards {
+		shard.compressor = compressor
+	}
+}
+
+// setEvictionPolicy sets the eviction policy of every shard.
+func (sc *ShardedCache) setEvictionPolicy(policy string) {
+	for _, shard := range sc.shards {
+		shard.setEvictionPolicy(policy)
+	}
+}
+
+// resize splits capacity between the shards and sets their TTL.
+func (sc *ShardedCache) resize(capacity int, ttl time.Duration) {
+	perShardCapacity := max(capacity/sc.numShards, 1)
+	for _, shard := range sc.shards {
+		shard.resize(perShardCapacity, ttl)
+	}
+}
+
 // Metrics returns aggregated metrics from all shards.
 func (sc *ShardedCache) Metrics() cache.Metrics {
 	var total cache.Metrics
@@ -134,6 +190,15 @@ func (sc *ShardedCache) Metrics() cache.Metrics {
 	return total
 }
 
+// ShardMetrics returns the metrics of each shard, in shard order.
+func (sc *ShardedCache) ShardMetrics() []cache.Metrics {
+	shards := make([]cache.Metrics, len(sc.shards))
+	for i, shard := range sc.shards {
+		shards[i] = shard.Metrics()
+	}
+	return shards
+}
+
 // ttlCache provides thread-safe LRU caching with TTL expiration.
 // It combines LRU eviction with time-based expiration for optimal memory management.
 // It implements the cache.Cache interface.
@@ -141,7 +206,7 @@ type ttlCache struct {
 	mu                   sync.RWMutex
 	capacity             int
 	ttl                  time.Duration
-	items                map[int]*cacheEntry
+	items                map[cache.Key]*cacheEntry
 	head                 *cacheEntry
 	tail                 *cacheEntry
 	stopCh               chan struct{}
@@ -150,12 +215,15 @@ type ttlCache struct {
 	evictions            int64
 	probabilisticCounter uint32 // For probabilistic LRU updates
 	lruUpdateRate        int    // 1 = always update, 10 = update 10% of time
+	compressor           *cache.Compressor
+	admission            *frequencySketch // set for CacheEvictionLFU
 }
 
 // cacheEntry represents a single cached item with expiration tracking.
 type cacheEntry struct {
-	key       int
+	key       cache.Key
 	value     model.PackResult
+	packed    []byte // compressed value; set instead of value for large results
 	expiresAt time.Time
 	prev      *cacheEntry
 	next      *cacheEntry
@@ -172,7 +240,7 @@ func newTTLCacheOptimized(capacity int, ttl time.Duration) *ttlCache {
 	c := &ttlCache{
 		capacity:      capacity,
 		ttl:           ttl,
-		items:         make(map[int]*cacheEntry, capacity),
+		items:         make(map[cache.Key]*cacheEntry, capacity),
 		stopCh:        make(chan struct{}),
 		lruUpdateRate: 1, // Always update by default (1 = 100% of the time)
 	}
@@ -180,6 +248,39 @@ func newTTLCacheOptimized(capacity int, ttl time.Duration) *ttlCache {
 	return c
 }
 
+// setCompressor compresses values whose encoded size reaches the compressor's threshold.
+func (c *ttlCache) setCompressor(compressor *cache.Compressor) {
+	c.compressor = compressor
+}
+
+// setEvictionPolicy switches between CacheEvictionLRU and CacheEvictionLFU.
+func (c *ttlCache) setEvictionPolicy(policy string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.admission = nil
+	if policy == CacheEvictionLFU {
+		c.admission = newFrequencySketch(c.capacity)
+	}
+}
+
+// resize sets the capacity and TTL, evicting the least recently used entries
+// that no longer fit. Cached entries keep their expiry.
+func (c *ttlCache) resize(capacity int, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = capacity
+	c.ttl = ttl
+	for len(c.items) > c.capacity {
+		c.removeTail()
+		atomic.AddInt64(&c.evictions, 1)
+		metrics.RecordCacheOperation("evict", "capacity")
+	}
+	if c.admission != nil {
+		c.admission = newFrequencySketch(capacity)
+	}
+}
+
 // Stop gracefully shuts down the cache and cleans up resources.
 func (c *ttlCache) Stop() {
 	close(c.stopCh)
@@ -206,11 +307,17 @@ func (c *ttlCache) fastRand() uint32 {
 
 // Get retrieves a value from the cache if it exists and hasn't expired.
 // Uses probabilistic LRU updates to reduce lock contention.
-func (c *ttlCache) Get(key int) (model.PackResult, bool) {
+func (c *ttlCache) Get(key cache.Key) (model.PackResult, bool) {
 	c.mu.RLock()
 	entry, ok := c.items[key]
+	admission := c.admission
 	c.mu.RUnlock()
 
+	// Misses count too: they are what makes a key worth admitting
+	if admission != nil {
+		admission.increment(key)
+	}
+
 	if !ok {
 		atomic.AddInt64(&c.misses, 1)
 		metrics.RecordCacheOperation("get", "miss")
@@ -240,27 +347,67 @@ func (c *ttlCache) Get(key int) (model.PackResult, bool) {
 		c.mu.Unlock()
 	}
 
+	if entry.packed != nil {
+		value, err := c.compressor.Decode(entry.packed)
+		if err != nil {
+			atomic.AddInt64(&c.misses, 1)
+			metrics.RecordCacheOperation("get", "error")
+			return model.PackResult{}, false
+		}
+		atomic.AddInt64(&c.hits, 1)
+		metrics.RecordCacheOperation("get", "hit")
+		return value, true
+	}
+
 	atomic.AddInt64(&c.hits, 1)
 	metrics.RecordCacheOperation("get", "hit")
 	return entry.value, true
 }
 
+// pack compresses value when a compressor is configured and the encoded value
+// reaches its threshold. It returns nil when value should be stored as is.
+func (c *ttlCache) pack(value model.PackResult) []byte {
+	if c.compressor == nil {
+		return nil
+	}
+	data, err := c.compressor.Encode(value)
+	if err != nil || len(data) == 0 || data[0] == '{' {
+		return nil
+	}
+	return data
+}
+
 // Set adds or updates a value in the cache with the configured TTL.
-// If the cache is at capacity, the least recently used entry is evicted.
-func (c *ttlCache) Set(key int, value model.PackResult) {
+// If the cache is at capacity, the least recently used entry is evicted. With
+// CacheEvictionLFU, a new key that has not been requested more often than
+// that entry is dropped instead.
+func (c *ttlCache) Set(key cache.Key, value model.PackResult) {
+	// Compress outside the lock; encoding large results is the expensive part
+	packed := c.pack(value)
+	if packed != nil {
+		value = model.PackResult{}
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if entry, ok := c.items[key]; ok {
 		entry.value = value
+		entry.packed = packed
 		entry.expiresAt = now().Add(c.ttl)
 		c.moveToFront(entry)
 		return
 	}
 
+	if c.rejects(key) {
+		metrics.RecordCacheOperation("set", "rejected")
+		return
+	}
+
 	entry := &cacheEntry{
 		key:       key,
 		value:     value,
+		packed:    packed,
 		expiresAt: now().Add(c.ttl),
 	}
 	c.items[key] = entry
@@ -274,6 +421,18 @@ func (c *ttlCache) Set(key int, value model.PackResult) {
 	metrics.RecordCacheOperation("set", "success")
 }
 
+// rejects reports whether admission keeps a new key out of a full cache. An
+// expired victim is always replaced. Callers must hold the write lock.
+func (c *ttlCache) rejects(key cache.Key) bool {
+	if c.admission == nil || len(c.items) < c.capacity || c.tail == nil {
+		return false
+	}
+	if time.Now().After(c.tail.expiresAt) {
+		return false
+	}
+	return c.admission.estimate(key) <= c.admission.estimate(c.tail.key)
+}
+
 // startCleanup runs an adaptive background cleanup routine.
 func (c *ttlCache) startCleanup() {
 	ticker := time.NewTicker(time.Minute)
@@ -361,7 +520,7 @@ func (c *ttlCache) removeTail() {
 }
 
 // Invalidate removes a specific key from the cache.
-func (c *ttlCache) Invalidate(key int) {
+func (c *ttlCache) Invalidate(key cache.Key) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -377,7 +536,7 @@ func (c *ttlCache) Clear() {
 	defer c.mu.Unlock()
 
 	// Clear all entries
-	c.items = make(map[int]*cacheEntry, c.capacity)
+	c.items = make(map[cache.Key]*cacheEntry, c.capacity)
 
 	// Reset linked list
 	c.head = nil
@@ -390,3 +549,73 @@ func (c *ttlCache) Clear() {
 
 	metrics.RecordCacheOperation("clear", "success")
 }
+
+// HotEntries returns up to limit unexpired entries, most recently used first.
+func (c *ttlCache) HotEntries(limit int) []cache.Entry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	currentTime := time.Now()
+	entries := make([]cache.Entry, 0, min(limit, len(c.items)))
+	for entry := c.head; entry != nil && len(entries) < limit; entry = entry.next {
+		if currentTime.After(entry.expiresAt) {
+			continue
+		}
+		value := entry.value
+		if entry.packed != nil {
+			decoded, err := c.compressor.Decode(entry.packed)
+			if err != nil {
+				continue
+			}
+			value = decoded
+		}
+		entries = append(entries, cache.Entry{Key: entry.key, Value: value, ExpiresAt: entry.expiresAt})
+	}
+	return entries
+}
+
+// Restore adds unexpired entries, given most recently used first, keeping
+// their expiry. Keys already cached are left alone since their values are
+// newer, and nothing is evicted to make room. It returns how many entries
+// were added.
+func (c *ttlCache) Restore(entries []cache.Entry) int {
+	if len(entries) > c.capacity {
+		entries = entries[:c.capacity]
+	}
+	packed := make([][]byte, len(entries))
+	for i, entry := range entries {
+		packed[i] = c.pack(entry.Value)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	currentTime := time.Now()
+	selected := make([]int, 0, len(entries))
+	for i, entry := range entries {
+		if len(c.items)+len(selected) >= c.capacity {
+			break
+		}
+		if _, ok := c.items[entry.Key]; ok || !currentTime.Before(entry.ExpiresAt) {
+			continue
+		}
+		selected = append(selected, i)
+	}
+
+	// Add the coldest entry first so the hottest ends up at the front
+	restored := 0
+	for j := len(selected) - 1; j >= 0; j-- {
+		i := selected[j]
+		if _, ok := c.items[entries[i].Key]; ok {
+			continue
+		}
+		entry := &cacheEntry{key: entries[i].Key, value: entries[i].Value, packed: packed[i], expiresAt: entries[i].ExpiresAt}
+		if packed[i] != nil {
+			entry.value = model.PackResult{}
+		}
+		c.items[entry.key] = entry
+		c.addToFront(entry)
+		restored++
+	}
+	return restored
+}
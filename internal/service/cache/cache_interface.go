@@ -1,12 +1,74 @@
 package cache
 
-import "github.com/guttosm/pack-service/internal/domain/model"
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"slices"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// Key identifies a cached result: the order quantity and the pack sizes it
+// was calculated with, as hashed by PackSizesHash.
+type Key struct {
+	Items     int             `json:"items"`
+	PackSizes PackSizesDigest `json:"pack_sizes"`
+}
+
+// PackSizesDigest is the SHA-256 of a set of pack sizes. Clients choose the
+// pack sizes of their requests, and results of every set share the cache,
+// so the digest must be collision resistant: a set hashing like the active
+// configuration would answer for it.
+type PackSizesDigest [sha256.Size]byte
+
+// String returns the digest in hex.
+func (d PackSizesDigest) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+// MarshalText encodes the digest in hex.
+func (d PackSizesDigest) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a digest encoded by MarshalText.
+func (d *PackSizesDigest) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != len(d) {
+		return hex.ErrLength
+	}
+	_, err := hex.Decode(d[:], text)
+	return err
+}
+
+// NewKey returns the key of the result for itemsOrdered with packSizes.
+func NewKey(itemsOrdered int, packSizes []int) Key {
+	return Key{Items: itemsOrdered, PackSizes: PackSizesHash(packSizes)}
+}
+
+// PackSizesHash identifies a set of pack sizes: the same sizes hash alike
+// in any order and with repeats.
+func PackSizesHash(packSizes []int) PackSizesDigest {
+	sizes := slices.Clone(packSizes)
+	slices.Sort(sizes)
+	sizes = slices.Compact(sizes)
+
+	h := sha256.New()
+	var buf [8]byte
+	for _, size := range sizes {
+		binary.BigEndian.PutUint64(buf[:], uint64(size))
+		_, _ = h.Write(buf[:])
+	}
+	var digest PackSizesDigest
+	h.Sum(digest[:0])
+	return digest
+}
 
 // Cache defines the interface for cache operations.
 type Cache interface {
-	Get(key int) (model.PackResult, bool)
-	Set(key int, value model.PackResult)
-	Invalidate(key int)
+	Get(key Key) (model.PackResult, bool)
+	Set(key Key, value model.PackResult)
+	Invalidate(key Key)
 	Clear()
 	Stop()
 }
@@ -3,9 +3,12 @@
 package cache
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/guttosm/pack-service/internal/domain/model"
 )
 
@@ -17,11 +20,11 @@ func TestCacheInterface(t *testing.T) {
 	
 	var cache Cache = &mockCache{}
 	
-	result, found := cache.Get(100)
+	result, found := cache.Get(Key{Items: 100})
 	assert.False(t, found)
 	assert.Equal(t, model.PackResult{}, result)
 	
-	cache.Set(100, model.PackResult{OrderedItems: 100})
+	cache.Set(Key{Items: 100}, model.PackResult{OrderedItems: 100})
 	cache.Stop()
 }
 
@@ -29,11 +32,11 @@ func TestCacheInterface(t *testing.T) {
 func TestCacheWithMetricsInterface(t *testing.T) {
 	var cache CacheWithMetrics = &mockCacheWithMetrics{}
 	
-	result, found := cache.Get(100)
+	result, found := cache.Get(Key{Items: 100})
 	assert.False(t, found)
 	assert.Equal(t, model.PackResult{}, result)
 	
-	cache.Set(100, model.PackResult{OrderedItems: 100})
+	cache.Set(Key{Items: 100}, model.PackResult{OrderedItems: 100})
 	
 	metrics := cache.Metrics()
 	assert.Equal(t, Metrics{}, metrics)
@@ -41,6 +44,32 @@ func TestCacheWithMetricsInterface(t *testing.T) {
 	cache.Stop()
 }
 
+func TestPackSizesHash(t *testing.T) {
+	assert.Equal(t, PackSizesHash([]int{250, 500, 1000}), PackSizesHash([]int{1000, 250, 500, 250}))
+	assert.NotEqual(t, PackSizesHash([]int{250, 500}), PackSizesHash([]int{250, 500, 1000}))
+	assert.NotEqual(t, PackSizesHash([]int{23, 31, 53}), PackSizesHash([]int{23, 31, 54}))
+
+	sizes := []int{500, 250}
+	assert.Equal(t, Key{Items: 251, PackSizes: PackSizesHash(sizes)}, NewKey(251, sizes))
+	assert.Equal(t, []int{500, 250}, sizes, "the sizes are not reordered")
+}
+
+func TestPackSizesDigest_Text(t *testing.T) {
+	key := NewKey(251, []int{250, 500})
+
+	data, err := json.Marshal(key)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":251,"pack_sizes":"`+key.PackSizes.String()+`"}`, string(data))
+	assert.Len(t, key.PackSizes.String(), 64)
+
+	var decoded Key
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, key, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"pack_sizes":"9f3c2a1b4d5e6f70"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"pack_sizes":"`+strings.Repeat("zz", 32)+`"}`), &decoded))
+}
+
 // TestMetricsStructure tests the Metrics struct.
 func TestMetricsStructure(t *testing.T) {
 	metrics := Metrics{
@@ -61,13 +90,13 @@ func TestMetricsStructure(t *testing.T) {
 // mockCache is a minimal implementation of Cache for testing.
 type mockCache struct{}
 
-func (m *mockCache) Get(key int) (model.PackResult, bool) {
+func (m *mockCache) Get(key Key) (model.PackResult, bool) {
 	return model.PackResult{}, false
 }
 
-func (m *mockCache) Set(key int, value model.PackResult) {}
+func (m *mockCache) Set(key Key, value model.PackResult) {}
 
-func (m *mockCache) Invalidate(key int) {}
+func (m *mockCache) Invalidate(key Key) {}
 
 func (m *mockCache) Clear() {}
 
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// Supported compression algorithms.
+const (
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+// DefaultCompressionThreshold is the encoded size, in bytes, above which values
+// are compressed when no threshold is configured.
+const DefaultCompressionThreshold = 1024
+
+// Compressed values start with a marker byte so they can be told apart from
+// plain JSON, which always starts with '{'. Entries written before compression
+// was enabled therefore remain readable.
+const (
+	markerSnappy byte = 0x01
+	markerZstd   byte = 0x02
+)
+
+var errUnknownMarker = errors.New("unknown compression marker")
+
+// zstdDecoder is shared by all compressors; DecodeAll is safe for concurrent use.
+var zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+	return zstd.NewReader(nil)
+})
+
+// Compressor encodes cache values as JSON and compresses those larger than a
+// threshold. A nil *Compressor encodes plain JSON.
+type Compressor struct {
+	algorithm string
+	threshold int
+	encoder   *zstd.Encoder
+}
+
+// NewCompressor creates a Compressor for the given algorithm. It returns nil
+// for CompressionNone or an empty algorithm.
+func NewCompressor(algorithm string, threshold int) (*Compressor, error) {
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+
+	c := &Compressor{algorithm: algorithm, threshold: threshold}
+	switch algorithm {
+	case "", CompressionNone:
+		return nil, nil
+	case CompressionSnappy:
+	case CompressionZstd:
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return nil, fmt.Errorf("create zstd encoder: %w", err)
+		}
+		c.encoder = encoder
+	default:
+		return nil, fmt.Errorf("unsupported cache compression %q", algorithm)
+	}
+	return c, nil
+}
+
+// Algorithm returns the configured algorithm.
+func (c *Compressor) Algorithm() string {
+	if c == nil {
+		return CompressionNone
+	}
+	return c.algorithm
+}
+
+// ShouldCompress reports whether a value of the given encoded size is compressed.
+func (c *Compressor) ShouldCompress(size int) bool {
+	return c != nil && size >= c.threshold
+}
+
+// Encode serialises value, compressing it when it reaches the threshold.
+func (c *Compressor) Encode(value model.PackResult) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if !c.ShouldCompress(len(data)) {
+		return data, nil
+	}
+	return c.Compress(data), nil
+}
+
+// Compress compresses data and prefixes it with the algorithm marker.
+func (c *Compressor) Compress(data []byte) []byte {
+	var out []byte
+	switch c.algorithm {
+	case CompressionSnappy:
+		out = append([]byte{markerSnappy}, snappy.Encode(nil, data)...)
+	case CompressionZstd:
+		out = c.encoder.EncodeAll(data, []byte{markerZstd})
+	default:
+		return data
+	}
+	metrics.RecordCacheCompression(c.algorithm, len(data), len(out))
+	return out
+}
+
+// Decode reverses Encode. Values compressed with either algorithm are accepted
+// regardless of the configured one, so changing CACHE_COMPRESSION does not
+// invalidate entries already in a shared cache.
+func (c *Compressor) Decode(data []byte) (model.PackResult, error) {
+	var result model.PackResult
+	raw, err := decompress(data)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(raw, &result)
+	return result, err
+}
+
+func decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] == '{' {
+		return data, nil
+	}
+
+	switch data[0] {
+	case markerSnappy:
+		return snappy.Decode(nil, data[1:])
+	case markerZstd:
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return decoder.DecodeAll(data[1:], nil)
+	default:
+		return nil, errUnknownMarker
+	}
+}
@@ -0,0 +1,96 @@
+//go:build !integration
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// largeResult returns a result whose JSON encoding is well above the default threshold.
+func largeResult() model.PackResult {
+	packs := make([]model.Pack, 100)
+	for i := range packs {
+		packs[i] = model.Pack{Size: (i + 1) * 10, Quantity: 1}
+	}
+	return model.PackResult{OrderedItems: 50000, TotalItems: 50500, Packs: packs}
+}
+
+func TestNewCompressor(t *testing.T) {
+	c, err := NewCompressor(CompressionNone, 0)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+	assert.Equal(t, CompressionNone, c.Algorithm())
+
+	c, err = NewCompressor(CompressionZstd, 0)
+	require.NoError(t, err)
+	assert.Equal(t, CompressionZstd, c.Algorithm())
+	assert.False(t, c.ShouldCompress(DefaultCompressionThreshold-1))
+	assert.True(t, c.ShouldCompress(DefaultCompressionThreshold))
+
+	_, err = NewCompressor("gzip", 0)
+	assert.Error(t, err)
+}
+
+func TestCompressor_RoundTrip(t *testing.T) {
+	for _, algorithm := range []string{CompressionSnappy, CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			c, err := NewCompressor(algorithm, 256)
+			require.NoError(t, err)
+
+			large := largeResult()
+			data, err := c.Encode(large)
+			require.NoError(t, err)
+			assert.NotEqual(t, byte('{'), data[0], "large values should be compressed")
+
+			decoded, err := c.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, large, decoded)
+
+			small := model.PackResult{OrderedItems: 1, TotalItems: 250, Packs: []model.Pack{{Size: 250, Quantity: 1}}}
+			data, err = c.Encode(small)
+			require.NoError(t, err)
+			assert.Equal(t, byte('{'), data[0], "small values should stay plain JSON")
+
+			decoded, err = c.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, small, decoded)
+		})
+	}
+}
+
+func TestCompressor_DecodesAnyAlgorithm(t *testing.T) {
+	snappyCompressor, err := NewCompressor(CompressionSnappy, 1)
+	require.NoError(t, err)
+	data, err := snappyCompressor.Encode(largeResult())
+	require.NoError(t, err)
+
+	// A nil compressor (compression disabled) still reads compressed entries
+	var disabled *Compressor
+	decoded, err := disabled.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, largeResult(), decoded)
+
+	_, err = disabled.Decode([]byte{0x7f, 0x00})
+	assert.ErrorIs(t, err, errUnknownMarker)
+}
+
+func TestRedisCache_Compression(t *testing.T) {
+	compressor, err := NewCompressor(CompressionZstd, 256)
+	require.NoError(t, err)
+	c, mr := newTestRedisCache(t, RedisConfig{Compressor: compressor})
+
+	c.Set(Key{Items: 1}, largeResult())
+
+	stored, err := mr.Get(DefaultRedisKeyPrefix + ":" + zeroDigest + ":1")
+	require.NoError(t, err)
+	assert.Equal(t, markerZstd, stored[0])
+
+	got, found := c.Get(Key{Items: 1})
+	require.True(t, found)
+	assert.Equal(t, largeResult(), got)
+}
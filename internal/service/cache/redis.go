@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// DefaultRedisKeyPrefix is used when RedisConfig.KeyPrefix is empty.
+const DefaultRedisKeyPrefix = "pack-service:calc"
+
+const (
+	defaultRedisOperationTimeout = 100 * time.Millisecond
+	clearScanBatch               = 500
+)
+
+// RedisConfig holds the settings for a Redis-backed cache.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// PoolSize and MinIdleConns size the connection pool; zero uses the client defaults.
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	// OperationTimeout bounds each cache round-trip so a slow Redis degrades to a miss.
+	OperationTimeout time.Duration
+	// KeyPrefix namespaces keys so several services can share one Redis.
+	KeyPrefix string
+	TTL       time.Duration
+	// Compressor compresses large values; nil stores plain JSON.
+	Compressor *Compressor
+}
+
+// RedisCache stores results in Redis so they survive restarts and are shared
+// across replicas. Redis errors are treated as misses: the caller recalculates
+// instead of failing the request.
+type RedisCache struct {
+	client     redis.UniversalClient
+	prefix     string
+	ttl        time.Duration
+	timeout    time.Duration
+	compressor *Compressor
+	hits       int64
+	misses     int64
+}
+
+// NewRedisCache creates a RedisCache with its own connection pool.
+func NewRedisCache(cfg RedisConfig) *RedisCache {
+	client := redis.NewClient(&redis.Options{
+		Addr:         cfg.Addr,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+		DialTimeout:  cfg.DialTimeout,
+	})
+	return NewRedisCacheWithClient(client, cfg)
+}
+
+// NewRedisCacheWithClient creates a RedisCache using an existing client.
+// Connection settings in cfg are ignored; the cache takes ownership of the
+// client and closes it on Stop.
+func NewRedisCacheWithClient(client redis.UniversalClient, cfg RedisConfig) *RedisCache {
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = DefaultRedisKeyPrefix
+	}
+	timeout := cfg.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultRedisOperationTimeout
+	}
+	return &RedisCache{
+		client:     client,
+		prefix:     prefix,
+		ttl:        cfg.TTL,
+		timeout:    timeout,
+		compressor: cfg.Compressor,
+	}
+}
+
+// Ping checks that Redis is reachable.
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// key returns the Redis key for an order quantity and pack sizes, e.g.
+// "pack-service:calc:9f3c2a1b…4d5e6f70:1200" with the whole digest.
+func (c *RedisCache) key(key Key) string {
+	return c.prefix + ":" + key.PackSizes.String() + ":" + strconv.Itoa(key.Items)
+}
+
+// Get retrieves a result from Redis.
+func (c *RedisCache) Get(key Key) (model.PackResult, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	metrics.RecordCacheBackendLatency("redis", "get", time.Since(start))
+
+	if errors.Is(err, redis.Nil) {
+		atomic.AddInt64(&c.misses, 1)
+		metrics.RecordCacheOperation("get", "miss")
+		return model.PackResult{}, false
+	}
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		metrics.RecordCacheOperation("get", "error")
+		log.Debug().Err(err).Int("items", key.Items).Msg("Redis cache get failed")
+		return model.PackResult{}, false
+	}
+
+	result, err := c.compressor.Decode(data)
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		metrics.RecordCacheOperation("get", "error")
+		log.Debug().Err(err).Int("items", key.Items).Msg("Discarding malformed Redis cache entry")
+		return model.PackResult{}, false
+	}
+
+	atomic.AddInt64(&c.hits, 1)
+	metrics.RecordCacheOperation("get", "hit")
+	return result, true
+}
+
+// Set stores a result in Redis with the configured TTL. A zero TTL stores the
+// entry without expiry.
+func (c *RedisCache) Set(key Key, value model.PackResult) {
+	data, err := c.compressor.Encode(value)
+	if err != nil {
+		metrics.RecordCacheOperation("set", "error")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err = c.client.Set(ctx, c.key(key), data, c.ttl).Err()
+	metrics.RecordCacheBackendLatency("redis", "set", time.Since(start))
+	if err != nil {
+		metrics.RecordCacheOperation("set", "error")
+		log.Debug().Err(err).Int("items", key.Items).Msg("Redis cache set failed")
+		return
+	}
+	metrics.RecordCacheOperation("set", "success")
+}
+
+// Invalidate removes a result from Redis.
+func (c *RedisCache) Invalidate(key Key) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	removed, err := c.client.Del(ctx, c.key(key)).Result()
+	metrics.RecordCacheBackendLatency("redis", "invalidate", time.Since(start))
+	if err != nil {
+		metrics.RecordCacheOperation("invalidate", "error")
+		log.Warn().Err(err).Int("items", key.Items).Msg("Redis cache invalidate failed")
+		return
+	}
+	if removed > 0 {
+		metrics.RecordCacheOperation("invalidate", "success")
+	}
+}
+
+// Clear removes every key under the cache prefix. Keys are found with SCAN so
+// Redis is never blocked, which means Clear may take longer than a single
+// operation timeout; it is bounded by a timeout per batch instead.
+func (c *RedisCache) Clear() {
+	var cursor uint64
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		keys, next, err := c.client.Scan(ctx, cursor, c.prefix+":*", clearScanBatch).Result()
+		if err == nil && len(keys) > 0 {
+			err = c.client.Unlink(ctx, keys...).Err()
+		}
+		cancel()
+		if err != nil {
+			metrics.RecordCacheOperation("clear", "error")
+			log.Warn().Err(err).Str("prefix", c.prefix).Msg("Redis cache clear failed")
+			return
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	metrics.RecordCacheOperation("clear", "success")
+}
+
+// Stop closes the connection pool.
+func (c *RedisCache) Stop() {
+	if err := c.client.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close Redis cache client")
+	}
+}
+
+// Metrics returns hit and miss counts for this instance. Evictions, Size and
+// Capacity are left at zero: entries are shared with other replicas and Redis
+// bounds memory through its own maxmemory policy.
+func (c *RedisCache) Metrics() Metrics {
+	return Metrics{
+		Hits:   atomic.LoadInt64(&c.hits),
+		Misses: atomic.LoadInt64(&c.misses),
+	}
+}
+
+// PoolStats returns connection pool statistics.
+func (c *RedisCache) PoolStats() *redis.PoolStats {
+	return c.client.PoolStats()
+}
@@ -0,0 +1,120 @@
+//go:build !integration
+
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// zeroDigest is the Redis key segment of keys without pack sizes.
+var zeroDigest = PackSizesDigest{}.String()
+
+func newTestRedisCache(t *testing.T, cfg RedisConfig) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cfg.Addr = mr.Addr()
+	c := NewRedisCache(cfg)
+	t.Cleanup(c.Stop)
+	return c, mr
+}
+
+func TestRedisCache_GetSet(t *testing.T) {
+	c, mr := newTestRedisCache(t, RedisConfig{TTL: time.Minute})
+	result := model.PackResult{
+		OrderedItems: 251,
+		TotalItems:   500,
+		Packs:        []model.Pack{{Size: 500, Quantity: 1}},
+	}
+
+	_, found := c.Get(Key{Items: 251})
+	assert.False(t, found)
+
+	c.Set(Key{Items: 251}, result)
+	got, found := c.Get(Key{Items: 251})
+	require.True(t, found)
+	assert.Equal(t, result, got)
+
+	assert.True(t, mr.Exists(DefaultRedisKeyPrefix+":"+zeroDigest+":251"))
+	assert.Equal(t, time.Minute, mr.TTL(DefaultRedisKeyPrefix+":"+zeroDigest+":251"))
+	assert.Equal(t, Metrics{Hits: 1, Misses: 1}, c.Metrics())
+}
+
+func TestRedisCache_TTLExpiry(t *testing.T) {
+	c, mr := newTestRedisCache(t, RedisConfig{TTL: time.Second})
+
+	c.Set(Key{Items: 1}, model.PackResult{OrderedItems: 1})
+	mr.FastForward(2 * time.Second)
+
+	_, found := c.Get(Key{Items: 1})
+	assert.False(t, found)
+}
+
+func TestRedisCache_Invalidate(t *testing.T) {
+	c, _ := newTestRedisCache(t, RedisConfig{KeyPrefix: "test"})
+
+	c.Set(Key{Items: 1}, model.PackResult{OrderedItems: 1})
+	c.Set(Key{Items: 2}, model.PackResult{OrderedItems: 2})
+	c.Invalidate(Key{Items: 1})
+
+	_, found := c.Get(Key{Items: 1})
+	assert.False(t, found)
+	_, found = c.Get(Key{Items: 2})
+	assert.True(t, found)
+}
+
+func TestRedisCache_ClearOnlyRemovesOwnPrefix(t *testing.T) {
+	c, mr := newTestRedisCache(t, RedisConfig{KeyPrefix: "calc:250"})
+	require.NoError(t, mr.Set("calc:500:1", "other"))
+
+	for i := 1; i <= clearScanBatch+10; i++ {
+		c.Set(Key{Items: i}, model.PackResult{OrderedItems: i})
+	}
+	c.Get(Key{Items: 1})
+
+	c.Clear()
+
+	for i := 1; i <= clearScanBatch+10; i++ {
+		assert.False(t, mr.Exists("calc:250:"+strconv.Itoa(i)))
+	}
+	assert.True(t, mr.Exists("calc:500:1"))
+	assert.Equal(t, Metrics{}, c.Metrics())
+}
+
+func TestRedisCache_ErrorsDegradeToMiss(t *testing.T) {
+	c, mr := newTestRedisCache(t, RedisConfig{OperationTimeout: 50 * time.Millisecond})
+	c.Set(Key{Items: 1}, model.PackResult{OrderedItems: 1})
+
+	mr.Close()
+
+	_, found := c.Get(Key{Items: 1})
+	assert.False(t, found)
+	assert.Equal(t, int64(1), c.Metrics().Misses)
+
+	// Writes and deletes must not panic or block when Redis is down
+	c.Set(Key{Items: 2}, model.PackResult{OrderedItems: 2})
+	c.Invalidate(Key{Items: 1})
+	c.Clear()
+}
+
+func TestRedisCache_MalformedEntryIsMiss(t *testing.T) {
+	c, mr := newTestRedisCache(t, RedisConfig{})
+	require.NoError(t, mr.Set(DefaultRedisKeyPrefix+":"+zeroDigest+":1", "not json"))
+
+	_, found := c.Get(Key{Items: 1})
+	assert.False(t, found)
+}
+
+func TestRedisCache_PingAndPoolStats(t *testing.T) {
+	c, _ := newTestRedisCache(t, RedisConfig{PoolSize: 5})
+
+	require.NoError(t, c.Ping(t.Context()))
+	assert.NotNil(t, c.PoolStats())
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// snapshotFormat is bumped whenever the snapshot layout changes; snapshots
+// in another format are discarded.
+const snapshotFormat = 3
+
+var (
+	// ErrSnapshotStale is returned when a snapshot is older than the allowed age.
+	ErrSnapshotStale = errors.New("cache snapshot is too old")
+	// ErrSnapshotMismatch is returned when a snapshot was taken with other
+	// pack sizes, another pack sizes configuration version or another format.
+	ErrSnapshotMismatch = errors.New("cache snapshot does not match the current pack sizes")
+)
+
+// Entry is a cached result with its expiry, as saved in a snapshot.
+type Entry struct {
+	Key       Key              `json:"key"`
+	Value     model.PackResult `json:"value"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+// Snapshotter is implemented by caches whose entries can be saved and
+// restored across restarts.
+type Snapshotter interface {
+	// HotEntries returns up to limit unexpired entries, most recently used first.
+	HotEntries(limit int) []Entry
+	// Restore adds entries that have not expired and returns how many it added.
+	Restore(entries []Entry) int
+}
+
+// Snapshot is the on-disk form of a cache snapshot. PackSizes and
+// ConfigVersion identify the configuration the results were calculated with.
+type Snapshot struct {
+	Format        int       `json:"format"`
+	CreatedAt     time.Time `json:"created_at"`
+	PackSizes     []int     `json:"pack_sizes"`
+	ConfigVersion int       `json:"config_version,omitempty"`
+	Entries       []Entry   `json:"entries"`
+}
+
+// WriteSnapshot writes snap to path. The file is replaced atomically, so a
+// crash while writing leaves the previous snapshot intact.
+func WriteSnapshot(path string, snap Snapshot) error {
+	snap.Format = snapshotFormat
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadSnapshot reads the snapshot at path and checks it against the current
+// configuration. It returns ErrSnapshotStale when the snapshot is older than
+// maxAge (zero disables the check) and ErrSnapshotMismatch when it was taken
+// with other pack sizes or another configuration version.
+func ReadSnapshot(path string, maxAge time.Duration, packSizes []int, configVersion int) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("decode cache snapshot: %w", err)
+	}
+	if snap.Format != snapshotFormat ||
+		!slices.Equal(snap.PackSizes, packSizes) ||
+		snap.ConfigVersion != configVersion {
+		return nil, ErrSnapshotMismatch
+	}
+	if maxAge > 0 && time.Since(snap.CreatedAt) > maxAge {
+		return nil, ErrSnapshotStale
+	}
+	return &snap, nil
+}
@@ -0,0 +1,69 @@
+//go:build !integration
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	expiresAt := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	entries := []Entry{{
+		Key:       Key{Items: 251},
+		Value:     model.PackResult{OrderedItems: 251, TotalItems: 500, Packs: []model.Pack{{Size: 500, Quantity: 1}}},
+		ExpiresAt: expiresAt,
+	}}
+
+	require.NoError(t, WriteSnapshot(path, Snapshot{
+		CreatedAt:     time.Now(),
+		PackSizes:     []int{500, 250},
+		ConfigVersion: 3,
+		Entries:       entries,
+	}))
+
+	snap, err := ReadSnapshot(path, time.Hour, []int{500, 250}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, entries, snap.Entries)
+
+	// No temporary files are left behind
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestReadSnapshot_Rejects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, WriteSnapshot(path, Snapshot{
+		CreatedAt:     time.Now().Add(-2 * time.Hour),
+		PackSizes:     []int{500, 250},
+		ConfigVersion: 3,
+	}))
+
+	_, err := ReadSnapshot(path, time.Hour, []int{500, 250}, 3)
+	assert.ErrorIs(t, err, ErrSnapshotStale)
+
+	_, err = ReadSnapshot(path, 0, []int{500, 250}, 3)
+	assert.NoError(t, err, "a zero max age accepts any age")
+
+	_, err = ReadSnapshot(path, 0, []int{1000, 500, 250}, 3)
+	assert.ErrorIs(t, err, ErrSnapshotMismatch)
+
+	_, err = ReadSnapshot(path, 0, []int{500, 250}, 4)
+	assert.ErrorIs(t, err, ErrSnapshotMismatch)
+
+	_, err = ReadSnapshot(filepath.Join(t.TempDir(), "missing.json"), 0, nil, 0)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err = ReadSnapshot(path, 0, []int{500, 250}, 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/guttosm/pack-service/internal/service/cache"
+)
+
+// Eviction policies of the in-memory result cache.
+const (
+	// CacheEvictionLRU evicts the least recently used entry to make room for
+	// every new one.
+	CacheEvictionLRU = "lru"
+	// CacheEvictionLFU keeps the LRU order but only admits a new entry when it
+	// has been requested more often than the entry it would evict (TinyLFU
+	// admission), so a burst of one-off quantities cannot flush the hot ones.
+	CacheEvictionLFU = "lfu"
+)
+
+const (
+	sketchDepth = 4
+	// sketchWidthRatio sizes each row for several keys per cached entry:
+	// misses are counted too, and collisions overestimate one-off keys.
+	sketchWidthRatio = 4
+	sketchMaxCount   = 15
+	sketchResetRatio = 10
+)
+
+// frequencySketch estimates how often keys were requested recently. It is a
+// count-min sketch of small saturating counters that are halved once the
+// sketch has seen sketchResetRatio times the cache capacity in requests, so
+// old popularity fades.
+type frequencySketch struct {
+	mu        sync.Mutex
+	rows      [sketchDepth][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+// newFrequencySketch sizes the sketch for a cache holding capacity entries.
+func newFrequencySketch(capacity int) *frequencySketch {
+	capacity = max(capacity, 1)
+	width := 16
+	for width < sketchWidthRatio*capacity {
+		width *= 2
+	}
+	s := &frequencySketch{mask: uint64(width - 1), resetAt: sketchResetRatio * capacity}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// increment records a request for key.
+func (s *frequencySketch) increment(key cache.Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.rows {
+		idx := s.index(key, i)
+		if s.rows[i][idx] < sketchMaxCount {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+// estimate returns the approximate number of recent requests for key.
+func (s *frequencySketch) estimate(key cache.Key) uint8 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := uint8(sketchMaxCount)
+	for i := range s.rows {
+		count = min(count, s.rows[i][s.index(key, i)])
+	}
+	return count
+}
+
+// reset halves every counter.
+func (s *frequencySketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+// index hashes key for row i with a splitmix64 finalizer seeded per row.
+func (s *frequencySketch) index(key cache.Key, row int) uint64 {
+	h := keyBits(key) + uint64(row+1)*0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return h & s.mask
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrequencySketch(t *testing.T) {
+	sketch := newFrequencySketch(10)
+	for i := 0; i < 5; i++ {
+		sketch.increment(itemsKey(7))
+	}
+	sketch.increment(itemsKey(8))
+
+	assert.GreaterOrEqual(t, sketch.estimate(itemsKey(7)), uint8(5))
+	assert.GreaterOrEqual(t, sketch.estimate(itemsKey(8)), uint8(1))
+	assert.Less(t, sketch.estimate(itemsKey(8)), sketch.estimate(itemsKey(7)))
+
+	// Counters saturate
+	for i := 0; i < 50; i++ {
+		sketch.increment(itemsKey(9))
+	}
+	assert.Equal(t, uint8(sketchMaxCount), sketch.estimate(itemsKey(9)))
+
+	// Old popularity fades once the sketch has seen enough requests
+	for i := 0; i < sketch.resetAt; i++ {
+		sketch.increment(itemsKey(10_000 + i))
+	}
+	assert.Less(t, sketch.estimate(itemsKey(9)), uint8(sketchMaxCount))
+}
+
+func TestTTLCache_EvictionPolicy(t *testing.T) {
+	access := func(c *ttlCache, key int) {
+		if _, ok := c.Get(itemsKey(key)); !ok {
+			c.Set(itemsKey(key), model.PackResult{OrderedItems: key})
+		}
+	}
+
+	tests := []struct {
+		policy       string
+		wantHotKept  bool
+		wantScanKept bool
+	}{
+		{policy: CacheEvictionLRU, wantHotKept: false, wantScanKept: true},
+		{policy: CacheEvictionLFU, wantHotKept: true, wantScanKept: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			c := newTTLCache(10, time.Minute)
+			defer c.Stop()
+			c.setEvictionPolicy(tt.policy)
+
+			for round := 0; round < 3; round++ {
+				for key := 1; key <= 10; key++ {
+					access(c, key)
+				}
+			}
+			// A scan of one-off quantities
+			for key := 1000; key < 1050; key++ {
+				access(c, key)
+			}
+
+			_, hot := c.Get(itemsKey(5))
+			assert.Equal(t, tt.wantHotKept, hot)
+			_, scanned := c.Get(itemsKey(1049))
+			assert.Equal(t, tt.wantScanKept, scanned)
+			assert.LessOrEqual(t, c.Metrics().Size, 10)
+		})
+	}
+}
+
+func TestTTLCache_LFUReplacesExpiredEntries(t *testing.T) {
+	c := newTTLCache(1, time.Millisecond)
+	defer c.Stop()
+	c.setEvictionPolicy(CacheEvictionLFU)
+
+	for i := 0; i < 3; i++ {
+		c.Get(itemsKey(1))
+	}
+	c.Set(itemsKey(1), model.PackResult{OrderedItems: 1})
+	time.Sleep(5 * time.Millisecond)
+
+	// Key 2 was never requested, yet replaces the expired entry
+	c.Set(itemsKey(2), model.PackResult{OrderedItems: 2})
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	assert.Contains(t, c.items, itemsKey(2))
+	assert.NotContains(t, c.items, itemsKey(1))
+}
+
+func TestPackCalculatorService_WithCacheEviction(t *testing.T) {
+	svc := NewPackCalculatorService(WithCacheEviction(CacheEvictionLFU), WithCache(10, time.Minute))
+	c, ok := svc.cache.(*ttlCache)
+	require.True(t, ok)
+	assert.NotNil(t, c.admission)
+
+	svc = NewPackCalculatorService(WithCache(10, time.Minute))
+	assert.Nil(t, svc.cache.(*ttlCache).admission)
+
+	sharded := NewShardedCache(64, time.Minute, 4)
+	defer sharded.Stop()
+	NewPackCalculatorService(WithCacheInterface(sharded), WithCacheEviction(CacheEvictionLFU))
+	for _, shard := range sharded.shards {
+		assert.NotNil(t, shard.admission)
+	}
+}
+
+// BenchmarkCacheHitRate replays a trace of requested quantities against each
+// eviction policy and reports the hit rate. Set CACHE_TRACE to a file with one
+// items_ordered per line, e.g. exported from the calculations collection, to
+// replay production traffic; otherwise a skewed synthetic trace is used.
+func BenchmarkCacheHitRate(b *testing.B) {
+	trace := loadCacheTrace(b)
+	for _, policy := range []string{CacheEvictionLRU, CacheEvictionLFU} {
+		b.Run(policy, func(b *testing.B) {
+			var hitRate float64
+			for i := 0; i < b.N; i++ {
+				c := newTTLCache(1000, time.Hour)
+				c.setEvictionPolicy(policy)
+				hits := 0
+				for _, key := range trace {
+					if _, ok := c.Get(itemsKey(key)); ok {
+						hits++
+						continue
+					}
+					c.Set(itemsKey(key), model.PackResult{OrderedItems: key})
+				}
+				c.Stop()
+				hitRate = 100 * float64(hits) / float64(len(trace))
+			}
+			b.ReportMetric(hitRate, "hit%")
+		})
+	}
+}
+
+// loadCacheTrace reads the trace named by CACHE_TRACE, or generates a Zipf
+// distributed trace over 100,000 quantities interleaved with scans of
+// quantities requested once.
+func loadCacheTrace(b *testing.B) []int {
+	b.Helper()
+	if path := os.Getenv("CACHE_TRACE"); path != "" {
+		f, err := os.Open(path)
+		require.NoError(b, err)
+		defer func() { _ = f.Close() }()
+
+		var trace []int
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if items, err := strconv.Atoi(strings.TrimSpace(scanner.Text())); err == nil && items > 0 {
+				trace = append(trace, items)
+			}
+		}
+		require.NoError(b, scanner.Err())
+		require.NotEmpty(b, trace, "CACHE_TRACE has no quantities")
+		return trace
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(rng, 1.1, 1, 100_000)
+	trace := make([]int, 0, 200_000)
+	scan := 1_000_000
+	for len(trace) < cap(trace) {
+		if len(trace)%20_000 < 2_000 {
+			trace = append(trace, scan)
+			scan++
+			continue
+		}
+		trace = append(trace, int(zipf.Uint64())+1)
+	}
+	return trace
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/service/cache"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// ErrCacheSnapshotUnsupported is returned when the result cache cannot be
+// snapshotted: caching is disabled or results live in Redis already.
+var ErrCacheSnapshotUnsupported = errors.New("result cache does not support snapshots")
+
+// SaveCacheSnapshot writes up to maxEntries of the most recently used cached
+// results to path and returns how many it wrote. configVersion is the active
+// pack sizes configuration version, zero without a database.
+func (s *PackCalculatorService) SaveCacheSnapshot(path string, maxEntries, configVersion int) (int, error) {
+	snapshotter, ok := s.cache.(cache.Snapshotter)
+	if !ok {
+		return 0, ErrCacheSnapshotUnsupported
+	}
+
+	entries := snapshotter.HotEntries(maxEntries)
+	err := cache.WriteSnapshot(path, cache.Snapshot{
+		CreatedAt:     timeutil.Now(),
+		PackSizes:     s.packSizes,
+		ConfigVersion: configVersion,
+		Entries:       entries,
+	})
+	if err != nil {
+		metrics.RecordCacheOperation("snapshot_save", "error")
+		return 0, err
+	}
+	metrics.RecordCacheOperation("snapshot_save", "success")
+	return len(entries), nil
+}
+
+// RestoreCacheSnapshot loads the snapshot at path into the result cache and
+// returns how many results it restored. A snapshot older than maxAge, or
+// taken with other pack sizes or another configVersion, is deleted instead,
+// since its results may no longer be correct.
+func (s *PackCalculatorService) RestoreCacheSnapshot(path string, maxAge time.Duration, configVersion int) (int, error) {
+	snapshotter, ok := s.cache.(cache.Snapshotter)
+	if !ok {
+		return 0, ErrCacheSnapshotUnsupported
+	}
+
+	snap, err := cache.ReadSnapshot(path, maxAge, s.packSizes, configVersion)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		metrics.RecordCacheOperation("snapshot_restore", "missing")
+		return 0, err
+	case errors.Is(err, cache.ErrSnapshotStale):
+		metrics.RecordCacheOperation("snapshot_restore", "stale")
+		_ = os.Remove(path)
+		return 0, err
+	case err != nil:
+		metrics.RecordCacheOperation("snapshot_restore", "discarded")
+		_ = os.Remove(path)
+		return 0, err
+	}
+
+	metrics.RecordCacheOperation("snapshot_restore", "success")
+	return snapshotter.Restore(snap.Entries), nil
+}
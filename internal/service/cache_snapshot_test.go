@@ -0,0 +1,138 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/service/cache"
+)
+
+func TestPackCalculatorService_CacheSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	calc := NewPackCalculatorService(WithCache(100, time.Minute))
+	defer calc.cache.Stop()
+	calc.Calculate(251)
+	calc.Calculate(12001)
+
+	saved, err := calc.SaveCacheSnapshot(path, 100, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, saved)
+
+	restarted := NewPackCalculatorService(WithCache(100, time.Minute))
+	defer restarted.cache.Stop()
+	restored, err := restarted.RestoreCacheSnapshot(path, time.Hour, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored)
+
+	result, ok := restarted.cache.Get(restarted.cacheKey(12001, nil))
+	require.True(t, ok)
+	assert.Equal(t, 12250, result.TotalItems)
+}
+
+func TestPackCalculatorService_RestoreCacheSnapshot_Discards(t *testing.T) {
+	tests := []struct {
+		name          string
+		calculator    *PackCalculatorService
+		maxAge        time.Duration
+		configVersion int
+		wantErr       error
+	}{
+		{
+			name:          "pack sizes changed",
+			calculator:    NewPackCalculatorService(WithCache(100, time.Minute), WithPackSizes([]int{23, 31, 53})),
+			configVersion: 2,
+			wantErr:       cache.ErrSnapshotMismatch,
+		},
+		{
+			name:          "config version changed",
+			calculator:    NewPackCalculatorService(WithCache(100, time.Minute)),
+			configVersion: 3,
+			wantErr:       cache.ErrSnapshotMismatch,
+		},
+		{
+			name:          "too old",
+			calculator:    NewPackCalculatorService(WithCache(100, time.Minute)),
+			maxAge:        time.Nanosecond,
+			configVersion: 2,
+			wantErr:       cache.ErrSnapshotStale,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer tt.calculator.cache.Stop()
+			path := filepath.Join(t.TempDir(), "cache.json")
+			source := NewPackCalculatorService(WithCache(100, time.Minute))
+			defer source.cache.Stop()
+			source.Calculate(251)
+			_, err := source.SaveCacheSnapshot(path, 100, 2)
+			require.NoError(t, err)
+
+			restored, err := tt.calculator.RestoreCacheSnapshot(path, tt.maxAge, tt.configVersion)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Zero(t, restored)
+			assert.NoFileExists(t, path, "a rejected snapshot is deleted")
+		})
+	}
+}
+
+func TestPackCalculatorService_CacheSnapshot_Unsupported(t *testing.T) {
+	calc := NewPackCalculatorService()
+
+	_, err := calc.SaveCacheSnapshot(filepath.Join(t.TempDir(), "cache.json"), 10, 0)
+	assert.ErrorIs(t, err, ErrCacheSnapshotUnsupported)
+	_, err = calc.RestoreCacheSnapshot(filepath.Join(t.TempDir(), "cache.json"), 0, 0)
+	assert.ErrorIs(t, err, ErrCacheSnapshotUnsupported)
+}
+
+func TestTTLCache_HotEntriesAndRestore(t *testing.T) {
+	source := newTTLCache(10, time.Minute)
+	defer source.Stop()
+	for _, key := range []int{1, 2, 3} {
+		source.Set(itemsKey(key), model.PackResult{OrderedItems: key})
+	}
+	source.Get(itemsKey(1))
+
+	entries := source.HotEntries(2)
+	require.Len(t, entries, 2)
+	assert.Equal(t, itemsKey(1), entries[0].Key, "most recently used first")
+	assert.Equal(t, itemsKey(3), entries[1].Key)
+
+	target := newTTLCache(2, time.Minute)
+	defer target.Stop()
+	target.Set(itemsKey(3), model.PackResult{OrderedItems: 33})
+	expired := cache.Entry{Key: itemsKey(4), ExpiresAt: time.Now().Add(-time.Second)}
+
+	restored := target.Restore(append(entries, expired))
+	assert.Equal(t, 1, restored, "live keys are kept and expired entries skipped")
+	assert.Equal(t, itemsKey(1), target.head.key)
+	value, _ := target.Get(itemsKey(3))
+	assert.Equal(t, 33, value.OrderedItems)
+	_, ok := target.Get(itemsKey(4))
+	assert.False(t, ok)
+}
+
+func TestShardedCache_HotEntriesAndRestore(t *testing.T) {
+	source := NewShardedCache(64, time.Minute, 4)
+	defer source.Stop()
+	for key := range 8 {
+		source.Set(itemsKey(key), model.PackResult{OrderedItems: key})
+	}
+
+	assert.Len(t, source.HotEntries(6), 6)
+	entries := source.HotEntries(100)
+	require.Len(t, entries, 8)
+
+	target := NewShardedCache(64, time.Minute, 4)
+	defer target.Stop()
+	assert.Equal(t, 8, target.Restore(entries))
+	value, ok := target.Get(itemsKey(5))
+	require.True(t, ok)
+	assert.Equal(t, 5, value.OrderedItems)
+}
@@ -21,7 +21,7 @@ func TestTTLCache_Get(t *testing.T) {
 			name: "returns value when exists and not expired",
 			setupCache: func() *ttlCache {
 				c := newTTLCache(10, time.Minute)
-				c.Set(100, model.PackResult{OrderedItems: 100, TotalItems: 250})
+				c.Set(itemsKey(100), model.PackResult{OrderedItems: 100, TotalItems: 250})
 				return c
 			},
 			key:           100,
@@ -40,7 +40,7 @@ func TestTTLCache_Get(t *testing.T) {
 			name: "returns false when expired",
 			setupCache: func() *ttlCache {
 				c := newTTLCache(10, 50*time.Millisecond)
-				c.Set(100, model.PackResult{OrderedItems: 100})
+				c.Set(itemsKey(100), model.PackResult{OrderedItems: 100})
 				time.Sleep(100 * time.Millisecond)
 				return c
 			},
@@ -52,7 +52,7 @@ func TestTTLCache_Get(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cache := tt.setupCache()
-			value, found := cache.Get(tt.key)
+			value, found := cache.Get(itemsKey(tt.key))
 
 			assert.Equal(t, tt.expectedFound, found)
 			if tt.expectedFound {
@@ -86,9 +86,9 @@ func TestTTLCache_Set(t *testing.T) {
 				{"set", 3, model.PackResult{OrderedItems: 3}},
 			},
 			validate: func(t *testing.T, c *ttlCache) {
-				_, ok1 := c.Get(1)
-				_, ok2 := c.Get(2)
-				_, ok3 := c.Get(3)
+				_, ok1 := c.Get(itemsKey(1))
+				_, ok2 := c.Get(itemsKey(2))
+				_, ok3 := c.Get(itemsKey(3))
 				assert.False(t, ok1, "first entry evicted")
 				assert.True(t, ok2)
 				assert.True(t, ok3)
@@ -106,7 +106,7 @@ func TestTTLCache_Set(t *testing.T) {
 				{"set", 100, model.PackResult{OrderedItems: 100, TotalItems: 500}},
 			},
 			validate: func(t *testing.T, c *ttlCache) {
-				value, ok := c.Get(100)
+				value, ok := c.Get(itemsKey(100))
 				assert.True(t, ok)
 				assert.Equal(t, 500, value.TotalItems)
 			},
@@ -117,7 +117,7 @@ func TestTTLCache_Set(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			cache := newTTLCache(tt.capacity, time.Minute)
 			for _, op := range tt.operations {
-				cache.Set(op.key, op.value)
+				cache.Set(itemsKey(op.key), op.value)
 			}
 			if tt.validate != nil {
 				tt.validate(t, cache)
@@ -128,7 +128,7 @@ func TestTTLCache_Set(t *testing.T) {
 
 func TestTTLCache_Stop(t *testing.T) {
 	cache := newTTLCache(10, time.Minute)
-	cache.Set(100, model.PackResult{OrderedItems: 100})
+	cache.Set(itemsKey(100), model.PackResult{OrderedItems: 100})
 
 	// Stop should not panic
 	assert.NotPanics(t, func() {
@@ -140,11 +140,11 @@ func TestTTLCache_Metrics(t *testing.T) {
 	cache := newTTLCache(10, time.Minute)
 
 	// Perform operations
-	cache.Set(100, model.PackResult{OrderedItems: 100})
-	cache.Get(100) // hit
-	cache.Get(200) // miss
-	cache.Set(200, model.PackResult{OrderedItems: 200})
-	cache.Set(300, model.PackResult{OrderedItems: 300})
+	cache.Set(itemsKey(100), model.PackResult{OrderedItems: 100})
+	cache.Get(itemsKey(100)) // hit
+	cache.Get(itemsKey(200)) // miss
+	cache.Set(itemsKey(200), model.PackResult{OrderedItems: 200})
+	cache.Set(itemsKey(300), model.PackResult{OrderedItems: 300})
 
 	metrics := cache.Metrics()
 	assert.Greater(t, metrics.Hits, int64(0))
@@ -167,8 +167,8 @@ func TestTTLCache_Concurrency(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		go func(key int) {
 			for j := 0; j < 10; j++ {
-				cache.Set(key*100+j, model.PackResult{OrderedItems: key*100 + j})
-				cache.Get(key*100 + j)
+				cache.Set(itemsKey(key*100+j), model.PackResult{OrderedItems: key*100 + j})
+				cache.Get(itemsKey(key*100 + j))
 			}
 			done <- true
 		}(i)
@@ -188,21 +188,21 @@ func TestTTLCache_Eviction(t *testing.T) {
 	defer cache.Stop()
 
 	// Fill cache to capacity
-	cache.Set(1, model.PackResult{OrderedItems: 1})
-	cache.Set(2, model.PackResult{OrderedItems: 2})
-	cache.Set(3, model.PackResult{OrderedItems: 3})
+	cache.Set(itemsKey(1), model.PackResult{OrderedItems: 1})
+	cache.Set(itemsKey(2), model.PackResult{OrderedItems: 2})
+	cache.Set(itemsKey(3), model.PackResult{OrderedItems: 3})
 
 	// Access 2 and 3 to make 1 the LRU
-	cache.Get(2)
-	cache.Get(3)
+	cache.Get(itemsKey(2))
+	cache.Get(itemsKey(3))
 
 	// Add 4, should evict 1
-	cache.Set(4, model.PackResult{OrderedItems: 4})
+	cache.Set(itemsKey(4), model.PackResult{OrderedItems: 4})
 
-	_, ok1 := cache.Get(1)
-	_, ok2 := cache.Get(2)
-	_, ok3 := cache.Get(3)
-	_, ok4 := cache.Get(4)
+	_, ok1 := cache.Get(itemsKey(1))
+	_, ok2 := cache.Get(itemsKey(2))
+	_, ok3 := cache.Get(itemsKey(3))
+	_, ok4 := cache.Get(itemsKey(4))
 
 	assert.False(t, ok1, "entry 1 should be evicted")
 	assert.True(t, ok2)
@@ -218,8 +218,8 @@ func TestTTLCache_Cleanup(t *testing.T) {
 	defer cache.Stop()
 
 	// Add entries
-	cache.Set(1, model.PackResult{OrderedItems: 1})
-	cache.Set(2, model.PackResult{OrderedItems: 2})
+	cache.Set(itemsKey(1), model.PackResult{OrderedItems: 1})
+	cache.Set(itemsKey(2), model.PackResult{OrderedItems: 2})
 
 	// Wait for expiration (must be > TTL + cachedTime update interval of 100ms)
 	time.Sleep(200 * time.Millisecond)
@@ -236,14 +236,14 @@ func TestTTLCache_RemoveTail(t *testing.T) {
 	cache := newTTLCache(2, time.Minute)
 	defer cache.Stop()
 
-	cache.Set(1, model.PackResult{OrderedItems: 1})
-	cache.Set(2, model.PackResult{OrderedItems: 2})
+	cache.Set(itemsKey(1), model.PackResult{OrderedItems: 1})
+	cache.Set(itemsKey(2), model.PackResult{OrderedItems: 2})
 
 	// Force eviction by adding third item
-	cache.Set(3, model.PackResult{OrderedItems: 3})
+	cache.Set(itemsKey(3), model.PackResult{OrderedItems: 3})
 
 	// First item should be evicted (LRU)
-	_, ok := cache.Get(1)
+	_, ok := cache.Get(itemsKey(1))
 	assert.False(t, ok)
 }
 
@@ -251,20 +251,20 @@ func TestTTLCache_MoveToFront(t *testing.T) {
 	cache := newTTLCache(3, time.Minute)
 	defer cache.Stop()
 
-	cache.Set(1, model.PackResult{OrderedItems: 1})
-	cache.Set(2, model.PackResult{OrderedItems: 2})
-	cache.Set(3, model.PackResult{OrderedItems: 3})
+	cache.Set(itemsKey(1), model.PackResult{OrderedItems: 1})
+	cache.Set(itemsKey(2), model.PackResult{OrderedItems: 2})
+	cache.Set(itemsKey(3), model.PackResult{OrderedItems: 3})
 
 	// Access 1 to move it to front (making 2 the LRU)
-	cache.Get(1)
+	cache.Get(itemsKey(1))
 
 	// Add 4, should evict 2 (LRU) since capacity is 3
-	cache.Set(4, model.PackResult{OrderedItems: 4})
+	cache.Set(itemsKey(4), model.PackResult{OrderedItems: 4})
 
-	_, ok1 := cache.Get(1)
-	_, ok2 := cache.Get(2)
-	_, ok3 := cache.Get(3)
-	_, ok4 := cache.Get(4)
+	_, ok1 := cache.Get(itemsKey(1))
+	_, ok2 := cache.Get(itemsKey(2))
+	_, ok3 := cache.Get(itemsKey(3))
+	_, ok4 := cache.Get(itemsKey(4))
 
 	assert.True(t, ok1, "entry 1 should still exist (was accessed)")
 	assert.False(t, ok2, "entry 2 should be evicted (was LRU)")
@@ -276,13 +276,13 @@ func TestTTLCache_ExpiredEntryRemoval(t *testing.T) {
 	cache := newTTLCache(10, 50*time.Millisecond)
 	defer cache.Stop()
 
-	cache.Set(100, model.PackResult{OrderedItems: 100})
+	cache.Set(itemsKey(100), model.PackResult{OrderedItems: 100})
 
 	// Wait for expiration
 	time.Sleep(100 * time.Millisecond)
 
 	// Get should return false and remove expired entry
-	value, found := cache.Get(100)
+	value, found := cache.Get(itemsKey(100))
 	assert.False(t, found)
 	assert.Equal(t, model.PackResult{}, value)
 
@@ -294,13 +294,13 @@ func TestTTLCache_UpdateExistingEntry(t *testing.T) {
 	cache := newTTLCache(10, time.Minute)
 	defer cache.Stop()
 
-	cache.Set(100, model.PackResult{OrderedItems: 100, TotalItems: 250})
-	value1, _ := cache.Get(100)
+	cache.Set(itemsKey(100), model.PackResult{OrderedItems: 100, TotalItems: 250})
+	value1, _ := cache.Get(itemsKey(100))
 	assert.Equal(t, 250, value1.TotalItems)
 
 	// Update same key
-	cache.Set(100, model.PackResult{OrderedItems: 100, TotalItems: 500})
-	value2, found := cache.Get(100)
+	cache.Set(itemsKey(100), model.PackResult{OrderedItems: 100, TotalItems: 500})
+	value2, found := cache.Get(itemsKey(100))
 
 	assert.True(t, found)
 	assert.Equal(t, 500, value2.TotalItems)
@@ -308,3 +308,9 @@ func TestTTLCache_UpdateExistingEntry(t *testing.T) {
 	metrics := cache.Metrics()
 	assert.Equal(t, 1, metrics.Size, "should still have only one entry")
 }
+
+// itemsKey is the cache key of a quantity, for tests that do not vary the
+// pack sizes.
+func itemsKey(items int) cache.Key {
+	return cache.Key{Items: items}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// DefaultCacheWarmupWindow is how far back the calculation history is
+	// read for the most requested quantities when no window is configured.
+	DefaultCacheWarmupWindow = 7 * 24 * time.Hour
+
+	// cacheWarmupTimeout bounds one warm-up.
+	cacheWarmupTimeout = 5 * time.Minute
+)
+
+// CacheWarmupConfig configures a CacheWarmer.
+type CacheWarmupConfig struct {
+	// Quantities are always warmed.
+	Quantities []int
+	// Top adds the Top most requested quantities of the history over Window;
+	// zero reads no history.
+	Top    int
+	Window time.Duration
+	// Interval repeats the warm-up; zero warms only on Start and when the
+	// active pack sizes change.
+	Interval time.Duration
+}
+
+// CacheWarmer calculates the results of common order quantities ahead of
+// requests, so latency does not spike while the result cache fills after a
+// deploy or a pack size change. Results are calculated with the pack sizes
+// requests use: the active configuration, or the calculator's own sizes.
+type CacheWarmer struct {
+	calculator PackCalculator
+	history    repository.CalculationRepositoryInterface
+	packSizes  *ActivePackSizes
+	cfg        CacheWarmupConfig
+	now        func() time.Time
+
+	triggerCh chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewCacheWarmer creates a warmer filling calculator's cache with the
+// quantities in cfg and the most requested ones in history, which may be
+// nil. packSizes, when not nil, provides the active pack sizes and triggers
+// a warm-up when they change. Call Start to run it.
+func NewCacheWarmer(calculator PackCalculator, history repository.CalculationRepositoryInterface, packSizes *ActivePackSizes, cfg CacheWarmupConfig) *CacheWarmer {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultCacheWarmupWindow
+	}
+	return &CacheWarmer{
+		calculator: calculator,
+		history:    history,
+		packSizes:  packSizes,
+		cfg:        cfg,
+		now:        timeutil.Now,
+		triggerCh:  make(chan struct{}, 1),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+// Start warms the cache in the background now, then every cfg.Interval and
+// whenever the active pack sizes change, until Stop is called. Only the
+// first call has an effect.
+func (w *CacheWarmer) Start() {
+	w.startOnce.Do(func() {
+		if w.packSizes != nil {
+			w.packSizes.Subscribe(w.Trigger)
+		}
+		go w.run()
+	})
+}
+
+// Trigger warms the cache again as soon as the running warm-up, if any, is
+// done. It does not block.
+func (w *CacheWarmer) Trigger() {
+	select {
+	case w.triggerCh <- struct{}{}:
+	default:
+	}
+}
+
+// Stop stops the warmer and waits for a running warm-up to finish.
+func (w *CacheWarmer) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		// Without Start there is nothing to wait for
+		w.startOnce.Do(func() { close(w.doneCh) })
+		<-w.doneCh
+	})
+}
+
+func (w *CacheWarmer) run() {
+	defer close(w.doneCh)
+
+	var tick <-chan time.Time
+	if w.cfg.Interval > 0 {
+		ticker := time.NewTicker(w.cfg.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	w.warmInBackground()
+	for {
+		select {
+		case <-tick:
+			w.warmInBackground()
+		case <-w.triggerCh:
+			w.warmInBackground()
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// Warm calculates the result of every quantity to warm, stopping early when
+// ctx is done or the warmer is stopped, and returns how many it calculated.
+// When the history cannot be read, the configured quantities are still
+// warmed and the error is returned.
+func (w *CacheWarmer) Warm(ctx context.Context) (int, error) {
+	quantities, err := w.quantities(ctx)
+
+	var sizes []int
+	if w.packSizes != nil {
+		if config := w.packSizes.Current(); config != nil {
+			sizes = config.Sizes
+		}
+	}
+
+	warmed := 0
+	for _, quantity := range quantities {
+		select {
+		case <-ctx.Done():
+			return warmed, ctx.Err()
+		case <-w.stopCh:
+			return warmed, err
+		default:
+		}
+		w.calculator.CalculateWithPackSizes(quantity, sizes)
+		warmed++
+	}
+	return warmed, err
+}
+
+// quantities returns the configured quantities followed by the most
+// requested ones, without repeats or quantities the calculator refuses.
+func (w *CacheWarmer) quantities(ctx context.Context) ([]int, error) {
+	candidates := w.cfg.Quantities
+	var err error
+	if w.cfg.Top > 0 && w.history != nil {
+		var top []int
+		top, err = w.history.TopQuantities(ctx, w.now().Add(-w.cfg.Window), w.cfg.Top)
+		candidates = slices.Concat(candidates, top)
+	}
+
+	maxItems := MaxItemsOrdered(w.calculator)
+	seen := make(map[int]struct{}, len(candidates))
+	quantities := make([]int, 0, len(candidates))
+	for _, quantity := range candidates {
+		if _, ok := seen[quantity]; ok || quantity <= 0 || quantity > maxItems {
+			continue
+		}
+		seen[quantity] = struct{}{}
+		quantities = append(quantities, quantity)
+	}
+	return quantities, err
+}
+
+func (w *CacheWarmer) warmInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheWarmupTimeout)
+	defer cancel()
+
+	start := time.Now()
+	warmed, err := w.Warm(ctx)
+	if err != nil {
+		metrics.RecordCacheOperation("warmup", "error")
+		log.Warn().Err(err).Int("quantities", warmed).Msg("Cache warm-up incomplete")
+		return
+	}
+	metrics.RecordCacheOperation("warmup", "success")
+	log.Debug().Int("quantities", warmed).Dur("duration", time.Since(start)).Msg("Warmed the result cache")
+}
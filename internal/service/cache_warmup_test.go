@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestCacheWarmer_Warm(t *testing.T) {
+	t.Run("warms configured and most requested quantities", func(t *testing.T) {
+		history := mocks.NewMockCalculationRepositoryInterface(t)
+		history.EXPECT().TopQuantities(mock.Anything, mock.Anything, 3).Return([]int{251, 12001, 500}, nil)
+		calculator := service.NewPackCalculatorService(service.WithCache(10, time.Minute))
+
+		warmer := service.NewCacheWarmer(calculator, history, nil, service.CacheWarmupConfig{
+			Quantities: []int{251, 0, dto.DefaultMaxItemsOrdered + 1},
+			Top:        3,
+		})
+		warmed, err := warmer.Warm(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, warmed, "repeats and quantities the calculator refuses are skipped")
+		for _, quantity := range []int{251, 500, 12001} {
+			assert.True(t, calculator.Calculate(quantity).CacheHit, "quantity %d", quantity)
+		}
+	})
+
+	t.Run("reads the history over the window", func(t *testing.T) {
+		history := mocks.NewMockCalculationRepositoryInterface(t)
+		history.EXPECT().TopQuantities(mock.Anything, mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since).Round(time.Hour) == 24*time.Hour
+		}), 5).Return(nil, nil)
+
+		warmer := service.NewCacheWarmer(service.NewPackCalculatorService(), history, nil, service.CacheWarmupConfig{Top: 5, Window: 24 * time.Hour})
+		warmed, err := warmer.Warm(context.Background())
+
+		require.NoError(t, err)
+		assert.Zero(t, warmed)
+	})
+
+	t.Run("warms configured quantities when the history fails", func(t *testing.T) {
+		history := mocks.NewMockCalculationRepositoryInterface(t)
+		history.EXPECT().TopQuantities(mock.Anything, mock.Anything, 10).Return(nil, errors.New("db down"))
+		calculator := service.NewPackCalculatorService(service.WithCache(10, time.Minute))
+
+		warmer := service.NewCacheWarmer(calculator, history, nil, service.CacheWarmupConfig{Quantities: []int{251}, Top: 10})
+		warmed, err := warmer.Warm(context.Background())
+
+		assert.EqualError(t, err, "db down")
+		assert.Equal(t, 1, warmed)
+		assert.True(t, calculator.Calculate(251).CacheHit)
+	})
+
+	t.Run("warms with the active pack sizes", func(t *testing.T) {
+		view := service.NewActivePackSizes(&storedPackSizes{active: packSizeConfig(2, 23, 31, 53)}, nil, 0)
+		require.NoError(t, view.Reload(context.Background()))
+		calculator := mocks.NewMockPackCalculator(t)
+		calculator.EXPECT().CalculateWithPackSizes(263, []int{23, 31, 53}).Return(model.PackResult{}).Once()
+
+		warmer := service.NewCacheWarmer(calculator, nil, view, service.CacheWarmupConfig{Quantities: []int{263}, Top: 10})
+		warmed, err := warmer.Warm(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, warmed)
+	})
+}
+
+func TestCacheWarmer_WarmsAfterPackSizeChanges(t *testing.T) {
+	stored := &storedPackSizes{active: packSizeConfig(1, 250, 500)}
+	view := service.NewActivePackSizes(stored, nil, 0)
+	require.NoError(t, view.Reload(context.Background()))
+
+	warmedSizes := make(chan []int, 4)
+	calculator := mocks.NewMockPackCalculator(t)
+	calculator.EXPECT().CalculateWithPackSizes(251, mock.Anything).
+		Run(func(_ int, sizes []int) { warmedSizes <- sizes }).
+		Return(model.PackResult{})
+
+	warmer := service.NewCacheWarmer(calculator, nil, view, service.CacheWarmupConfig{Quantities: []int{251}})
+	warmer.Start()
+	defer warmer.Stop()
+
+	assert.Equal(t, []int{250, 500}, receiveSizes(t, warmedSizes), "warmed on start")
+
+	view.Set(packSizeConfig(2, 23, 31, 53))
+	assert.Equal(t, []int{23, 31, 53}, receiveSizes(t, warmedSizes), "warmed again with the new sizes")
+}
+
+func TestCacheWarmer_StopWithoutStart(t *testing.T) {
+	warmer := service.NewCacheWarmer(service.NewPackCalculatorService(), nil, nil, service.CacheWarmupConfig{})
+	warmer.Stop()
+	warmer.Stop()
+}
+
+func receiveSizes(t *testing.T, ch <-chan []int) []int {
+	t.Helper()
+	select {
+	case sizes := <-ch:
+		return sizes
+	case <-time.After(2 * time.Second):
+		t.Fatal("cache was not warmed")
+		return nil
+	}
+}
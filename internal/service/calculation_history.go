@@ -0,0 +1,304 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// DefaultCalculationHistoryFlushInterval is how often buffered
+	// calculations are written when no interval is configured.
+	DefaultCalculationHistoryFlushInterval = 5 * time.Second
+	// DefaultCalculationPageSize is the page size used when a listing sets no limit.
+	DefaultCalculationPageSize = 50
+	// MaxCalculationPageSize caps the page size of a calculation listing.
+	MaxCalculationPageSize = 500
+
+	// maxPendingCalculations bounds the calculations buffered between flushes.
+	maxPendingCalculations = 10000
+	// calculationFlushBatchSize triggers a flush before the interval elapses.
+	calculationFlushBatchSize = 500
+	// calculationFlushTimeout bounds a background flush.
+	calculationFlushTimeout = 5 * time.Second
+)
+
+// CalculationHistoryService stores every pack calculation. Record is called
+// on the request path, so calculations are buffered in memory and written in
+// batches by a background goroutine.
+type CalculationHistoryService interface {
+	Record(calc *model.Calculation)
+	List(ctx context.Context, filter dto.CalculationFilter, limit, offset int) (*dto.CalculationPage, error)
+	Rollups(ctx context.Context, filter dto.RollupFilter) (*dto.CalculationRollupReport, error)
+	Flush(ctx context.Context) error
+	Stop()
+}
+
+// CalculationHistoryServiceImpl implements CalculationHistoryService.
+type CalculationHistoryServiceImpl struct {
+	repo    repository.CalculationRepositoryInterface
+	mu      sync.Mutex
+	pending []*model.Calculation
+	now     func() time.Time
+
+	flushCh  chan struct{}
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewCalculationHistoryService creates a calculation history service that
+// writes buffered calculations to repo every flushInterval, or sooner when
+// enough are buffered. Call Stop to write the remaining calculations.
+func NewCalculationHistoryService(repo repository.CalculationRepositoryInterface, flushInterval time.Duration) CalculationHistoryService {
+	if flushInterval <= 0 {
+		flushInterval = DefaultCalculationHistoryFlushInterval
+	}
+
+	s := &CalculationHistoryServiceImpl{
+		repo:    repo,
+		now:     timeutil.Now,
+		flushCh: make(chan struct{}, 1),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	go s.flushLoop(flushInterval)
+	return s
+}
+
+// Record buffers a calculation. CreatedAt defaults to now. Calculations
+// beyond the buffer limit are dropped and counted until the next flush.
+func (s *CalculationHistoryServiceImpl) Record(calc *model.Calculation) {
+	if calc == nil {
+		return
+	}
+	if calc.CreatedAt.IsZero() {
+		calc.CreatedAt = s.now()
+	}
+
+	s.mu.Lock()
+	if len(s.pending) >= maxPendingCalculations {
+		s.mu.Unlock()
+		metrics.RecordCalculationHistoryDropped(1)
+		return
+	}
+	s.pending = append(s.pending, calc)
+	full := len(s.pending) >= calculationFlushBatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// List flushes this instance's buffered calculations and returns a page of
+// the stored history matching filter, newest first.
+func (s *CalculationHistoryServiceImpl) List(ctx context.Context, filter dto.CalculationFilter, limit, offset int) (*dto.CalculationPage, error) {
+	if s.repo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if limit <= 0 {
+		limit = DefaultCalculationPageSize
+	}
+	if limit > MaxCalculationPageSize {
+		limit = MaxCalculationPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if err := s.Flush(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to flush calculation history before listing")
+	}
+
+	query := repository.CalculationQuery{
+		UserID:   filter.UserID,
+		From:     filter.From,
+		To:       filter.To,
+		MinItems: filter.MinItems,
+		MaxItems: filter.MaxItems,
+		PackSet:  filter.PackSet,
+		Limit:    limit,
+		Skip:     offset,
+	}
+	calculations, err := s.repo.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.Count(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if calculations == nil {
+		calculations = []*model.Calculation{}
+	}
+
+	return &dto.CalculationPage{Calculations: calculations, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Rollups returns the stored daily rollups in filter's range, summed by
+// month when asked. Rollups are maintained by the CalculationRollupJob, so
+// they trail the raw history by up to its interval.
+func (s *CalculationHistoryServiceImpl) Rollups(ctx context.Context, filter dto.RollupFilter) (*dto.CalculationRollupReport, error) {
+	if s.repo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if filter.Granularity == "" {
+		filter.Granularity = dto.GranularityDay
+	}
+
+	rollups, err := s.repo.ListRollups(ctx, repository.RollupQuery{
+		From:    filter.From,
+		To:      filter.To,
+		UserID:  filter.UserID,
+		PackSet: filter.PackSet,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if filter.Granularity == dto.GranularityMonth {
+		rollups = rollUpMonths(rollups)
+	}
+	if rollups == nil {
+		rollups = []*model.CalculationRollup{}
+	}
+
+	return &dto.CalculationRollupReport{
+		From:        filter.From,
+		To:          filter.To,
+		Granularity: filter.Granularity,
+		Rollups:     rollups,
+	}, nil
+}
+
+// rollUpMonths sums daily rollups into monthly ones per user and pack set,
+// sorted like the daily ones.
+func rollUpMonths(daily []*model.CalculationRollup) []*model.CalculationRollup {
+	type monthKey struct {
+		month   time.Time
+		userID  string
+		packSet string
+	}
+
+	var monthly []*model.CalculationRollup
+	index := make(map[monthKey]*model.CalculationRollup)
+	for _, day := range daily {
+		month := time.Date(day.Day.Year(), day.Day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		key := monthKey{month: month, userID: day.UserID, packSet: day.PackSet}
+		m, ok := index[key]
+		if !ok {
+			m = &model.CalculationRollup{
+				Day:             month,
+				UserID:          day.UserID,
+				PackSet:         day.PackSet,
+				MinItemsOrdered: day.MinItemsOrdered,
+			}
+			index[key] = m
+			monthly = append(monthly, m)
+		}
+		m.Calculations += day.Calculations
+		m.ItemsOrdered += day.ItemsOrdered
+		m.TotalItems += day.TotalItems
+		m.Packs += day.Packs
+		m.Approximate += day.Approximate
+		m.LatencyMicros += day.LatencyMicros
+		m.MinItemsOrdered = min(m.MinItemsOrdered, day.MinItemsOrdered)
+		m.MaxItemsOrdered = max(m.MaxItemsOrdered, day.MaxItemsOrdered)
+		if day.UpdatedAt.After(m.UpdatedAt) {
+			m.UpdatedAt = day.UpdatedAt
+		}
+	}
+
+	slices.SortFunc(monthly, func(a, b *model.CalculationRollup) int {
+		return cmp.Or(a.Day.Compare(b.Day), cmp.Compare(a.UserID, b.UserID), cmp.Compare(a.PackSet, b.PackSet))
+	})
+	return monthly
+}
+
+// Flush writes buffered calculations. On failure they are kept for the next
+// flush, up to the buffer limit.
+func (s *CalculationHistoryServiceImpl) Flush(ctx context.Context) error {
+	if s.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := s.repo.CreateMany(ctx, batch); err != nil {
+		s.restore(batch)
+		return err
+	}
+	return nil
+}
+
+// restore puts a batch that failed to flush back in front of the buffer.
+func (s *CalculationHistoryServiceImpl) restore(batch []*model.Calculation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keep := min(len(batch), maxPendingCalculations-len(s.pending))
+	if dropped := len(batch) - keep; dropped > 0 {
+		metrics.RecordCalculationHistoryDropped(dropped)
+	}
+	s.pending = append(batch[:keep:keep], s.pending...)
+}
+
+// Stop ends the background flush and writes the remaining calculations.
+func (s *CalculationHistoryServiceImpl) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.doneCh
+	})
+}
+
+func (s *CalculationHistoryServiceImpl) flushLoop(interval time.Duration) {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flushInBackground()
+		case <-s.flushCh:
+			s.flushInBackground()
+		case <-s.stopCh:
+			s.flushInBackground()
+			return
+		}
+	}
+}
+
+func (s *CalculationHistoryServiceImpl) flushInBackground() {
+	if s.repo == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), calculationFlushTimeout)
+	defer cancel()
+
+	if err := s.Flush(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to flush calculation history")
+	}
+}
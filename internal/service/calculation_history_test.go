@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestCalculationHistoryService_FlushWritesBatch(t *testing.T) {
+	repo := mocks.NewMockCalculationRepositoryInterface(t)
+	svc := service.NewCalculationHistoryService(repo, time.Hour)
+
+	svc.Record(&model.Calculation{ItemsOrdered: 251, TotalItems: 500})
+	svc.Record(&model.Calculation{ItemsOrdered: 12001, TotalItems: 12250})
+	svc.Record(nil)
+
+	repo.EXPECT().CreateMany(mock.Anything, mock.MatchedBy(func(calcs []*model.Calculation) bool {
+		return len(calcs) == 2 && calcs[0].ItemsOrdered == 251 && !calcs[1].CreatedAt.IsZero()
+	})).Return(nil).Once()
+
+	require.NoError(t, svc.Flush(context.Background()))
+	// Nothing left to write
+	require.NoError(t, svc.Flush(context.Background()))
+	svc.Stop()
+}
+
+func TestCalculationHistoryService_FailedFlushIsRetried(t *testing.T) {
+	repo := mocks.NewMockCalculationRepositoryInterface(t)
+	svc := service.NewCalculationHistoryService(repo, time.Hour)
+	defer svc.Stop()
+
+	svc.Record(&model.Calculation{ItemsOrdered: 1})
+	repo.EXPECT().CreateMany(mock.Anything, mock.Anything).Return(errors.New("circuit breaker is open")).Once()
+	require.Error(t, svc.Flush(context.Background()))
+
+	svc.Record(&model.Calculation{ItemsOrdered: 2})
+	repo.EXPECT().CreateMany(mock.Anything, mock.MatchedBy(func(calcs []*model.Calculation) bool {
+		return len(calcs) == 2 && calcs[0].ItemsOrdered == 1 && calcs[1].ItemsOrdered == 2
+	})).Return(nil).Once()
+	require.NoError(t, svc.Flush(context.Background()))
+}
+
+func TestCalculationHistoryService_StopFlushes(t *testing.T) {
+	repo := mocks.NewMockCalculationRepositoryInterface(t)
+	svc := service.NewCalculationHistoryService(repo, time.Hour)
+
+	svc.Record(&model.Calculation{ItemsOrdered: 1})
+	repo.EXPECT().CreateMany(mock.Anything, mock.Anything).Return(nil).Once()
+	svc.Stop()
+}
+
+func TestCalculationHistoryService_List(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	minItems := 100
+
+	t.Run("applies filter and page bounds", func(t *testing.T) {
+		repo := mocks.NewMockCalculationRepositoryInterface(t)
+		svc := service.NewCalculationHistoryService(repo, time.Hour)
+		defer svc.Stop()
+
+		want := repository.CalculationQuery{
+			UserID:   "6512bd43d9caa6e02c990b0a",
+			From:     &from,
+			MinItems: &minItems,
+			Limit:    service.MaxCalculationPageSize,
+			Skip:     0,
+		}
+		repo.EXPECT().List(mock.Anything, want).Return(nil, nil)
+		repo.EXPECT().Count(mock.Anything, want).Return(int64(0), nil)
+
+		page, err := svc.List(context.Background(), dto.CalculationFilter{
+			UserID:   "6512bd43d9caa6e02c990b0a",
+			From:     &from,
+			MinItems: &minItems,
+		}, 10000, -5)
+		require.NoError(t, err)
+		assert.NotNil(t, page.Calculations)
+		assert.Equal(t, service.MaxCalculationPageSize, page.Limit)
+		assert.Equal(t, 0, page.Offset)
+	})
+
+	t.Run("includes buffered calculations", func(t *testing.T) {
+		repo := mocks.NewMockCalculationRepositoryInterface(t)
+		svc := service.NewCalculationHistoryService(repo, time.Hour)
+		defer svc.Stop()
+
+		svc.Record(&model.Calculation{ItemsOrdered: 251})
+		repo.EXPECT().CreateMany(mock.Anything, mock.Anything).Return(nil).Once()
+		repo.EXPECT().List(mock.Anything, mock.Anything).Return([]*model.Calculation{{ItemsOrdered: 251}}, nil)
+		repo.EXPECT().Count(mock.Anything, mock.Anything).Return(int64(1), nil)
+
+		page, err := svc.List(context.Background(), dto.CalculationFilter{}, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), page.Total)
+		assert.Equal(t, service.DefaultCalculationPageSize, page.Limit)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := mocks.NewMockCalculationRepositoryInterface(t)
+		svc := service.NewCalculationHistoryService(repo, time.Hour)
+		defer svc.Stop()
+
+		repo.EXPECT().List(mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+		_, err := svc.List(context.Background(), dto.CalculationFilter{}, 0, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("without repository", func(t *testing.T) {
+		svc := service.NewCalculationHistoryService(nil, time.Hour)
+		defer svc.Stop()
+
+		_, err := svc.List(context.Background(), dto.CalculationFilter{}, 0, 0)
+		assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	})
+}
+
+func TestCalculationHistoryService_Rollups(t *testing.T) {
+	jan1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2 := jan1.AddDate(0, 0, 1)
+	feb1 := jan1.AddDate(0, 1, 0)
+	daily := func() []*model.CalculationRollup {
+		return []*model.CalculationRollup{
+			{Day: jan1, UserID: "u2", PackSet: "default", Calculations: 1, ItemsOrdered: 10, MinItemsOrdered: 10, MaxItemsOrdered: 10},
+			{Day: jan2, UserID: "u1", PackSet: "default", Calculations: 2, ItemsOrdered: 300, TotalItems: 500, Packs: 2, MinItemsOrdered: 50, MaxItemsOrdered: 250},
+			{Day: jan2, UserID: "u2", PackSet: "default", Calculations: 1, ItemsOrdered: 5, MinItemsOrdered: 5, MaxItemsOrdered: 5},
+			{Day: feb1, UserID: "u1", PackSet: "default", Calculations: 1, ItemsOrdered: 1, MinItemsOrdered: 1, MaxItemsOrdered: 1},
+		}
+	}
+
+	t.Run("daily", func(t *testing.T) {
+		repo := mocks.NewMockCalculationRepositoryInterface(t)
+		svc := service.NewCalculationHistoryService(repo, time.Hour)
+		defer svc.Stop()
+
+		repo.EXPECT().ListRollups(mock.Anything, repository.RollupQuery{From: jan1, To: feb1, UserID: "u1", PackSet: "default"}).
+			Return(daily(), nil)
+
+		report, err := svc.Rollups(context.Background(), dto.RollupFilter{From: jan1, To: feb1, UserID: "u1", PackSet: "default"})
+		require.NoError(t, err)
+		assert.Equal(t, dto.GranularityDay, report.Granularity)
+		assert.Len(t, report.Rollups, 4)
+	})
+
+	t.Run("monthly sums the days", func(t *testing.T) {
+		repo := mocks.NewMockCalculationRepositoryInterface(t)
+		svc := service.NewCalculationHistoryService(repo, time.Hour)
+		defer svc.Stop()
+
+		repo.EXPECT().ListRollups(mock.Anything, mock.Anything).Return(daily(), nil)
+
+		report, err := svc.Rollups(context.Background(), dto.RollupFilter{From: jan1, To: feb1, Granularity: dto.GranularityMonth})
+		require.NoError(t, err)
+		require.Len(t, report.Rollups, 3)
+
+		u1 := report.Rollups[0]
+		assert.Equal(t, jan1, u1.Day)
+		assert.Equal(t, "u1", u1.UserID)
+		assert.Equal(t, int64(2), u1.Calculations)
+		assert.Equal(t, 50, u1.MinItemsOrdered)
+
+		u2 := report.Rollups[1]
+		assert.Equal(t, "u2", u2.UserID)
+		assert.Equal(t, int64(2), u2.Calculations)
+		assert.Equal(t, int64(15), u2.ItemsOrdered)
+		assert.Equal(t, 5, u2.MinItemsOrdered)
+		assert.Equal(t, 10, u2.MaxItemsOrdered)
+
+		assert.Equal(t, feb1, report.Rollups[2].Day)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		svc := service.NewCalculationHistoryService(mocks.NewMockCalculationRepositoryInterface(t), time.Hour)
+		defer svc.Stop()
+
+		_, err := svc.Rollups(context.Background(), dto.RollupFilter{From: feb1, To: jan1})
+		var validationErr *dto.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("without repository", func(t *testing.T) {
+		svc := service.NewCalculationHistoryService(nil, time.Hour)
+		defer svc.Stop()
+
+		_, err := svc.Rollups(context.Background(), dto.RollupFilter{From: jan1, To: feb1})
+		assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	})
+}
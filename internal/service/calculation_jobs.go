@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// Calculation job defaults.
+const (
+	// DefaultJobWorkers is how many jobs an instance runs at once.
+	DefaultJobWorkers = 2
+	// DefaultJobRetention is how long finished jobs are kept.
+	DefaultJobRetention = 24 * time.Hour
+	// DefaultJobTimeout bounds one run of a job.
+	DefaultJobTimeout = 10 * time.Minute
+	// DefaultMaxUnfinishedJobs caps the jobs queued or running across instances.
+	DefaultMaxUnfinishedJobs = 1000
+	// DefaultJobPollInterval is how often idle workers look for queued jobs.
+	DefaultJobPollInterval = time.Second
+
+	// maxJobAttempts is how many runs a job gets before it is failed, so a
+	// job that keeps taking its instance down is not retried forever.
+	maxJobAttempts = 3
+	// jobStoreTimeout bounds each job repository call.
+	jobStoreTimeout = 5 * time.Second
+)
+
+var (
+	// ErrJobNotFound is returned for unknown or expired jobs, and for jobs
+	// submitted by another caller.
+	ErrJobNotFound = domainerr.New("job_not_found", http.StatusNotFound, i18n.ErrKeyJobNotFound, "calculation job not found")
+	// ErrJobQueueFull is returned when too many jobs are unfinished.
+	ErrJobQueueFull = domainerr.ServiceUnavailable.Derive(i18n.ErrKeyServiceBusy, "calculation job queue is full")
+)
+
+// CalculationJobConfig configures the calculation job workers.
+type CalculationJobConfig struct {
+	Workers      int
+	Retention    time.Duration
+	Timeout      time.Duration
+	MaxQueued    int
+	PollInterval time.Duration
+}
+
+// CalculationJobProcessor calculates a job's items and returns one result
+// per item, in request order. It should stop early once ctx is done.
+type CalculationJobProcessor func(ctx context.Context, job *model.CalculationJob, items []dto.CalculatePacksRequest) []dto.BatchItemResult
+
+// CalculationJobService queues batch calculations in MongoDB and runs them
+// in the background. Any instance's workers can run a queued job.
+type CalculationJobService interface {
+	// Submit queues items as a job. Owner, RequestID and Locale are taken
+	// from job.
+	Submit(ctx context.Context, job *model.CalculationJob, items []dto.CalculatePacksRequest) (*dto.CalculationJobResponse, error)
+	// Get returns owner's job with its results once completed.
+	Get(ctx context.Context, id, owner string) (*dto.CalculationJobResponse, error)
+	// Start starts the workers, which run jobs with process. Only the first
+	// call has an effect.
+	Start(process CalculationJobProcessor)
+	// Shutdown stops taking jobs and waits for the running ones until ctx
+	// is done; jobs still running then are put back in the queue.
+	Shutdown(ctx context.Context)
+}
+
+// CalculationJobServiceImpl implements CalculationJobService.
+type CalculationJobServiceImpl struct {
+	repo    repository.CalculationJobRepositoryInterface
+	cfg     CalculationJobConfig
+	process CalculationJobProcessor
+	now     func() time.Time
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wake      chan struct{}
+	stopCh    chan struct{}
+	// runCtx is cancelled when shutdown gives up waiting for running jobs.
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewCalculationJobService creates a calculation job service. Zero config
+// values use the defaults. Call Start to run jobs on this instance.
+func NewCalculationJobService(repo repository.CalculationJobRepositoryInterface, cfg CalculationJobConfig) CalculationJobService {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultJobWorkers
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultJobRetention
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultJobTimeout
+	}
+	if cfg.MaxQueued <= 0 {
+		cfg.MaxQueued = DefaultMaxUnfinishedJobs
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultJobPollInterval
+	}
+
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	return &CalculationJobServiceImpl{
+		repo:      repo,
+		cfg:       cfg,
+		now:       timeutil.Now,
+		wake:      make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+		runCtx:    runCtx,
+		cancelRun: cancelRun,
+	}
+}
+
+// Submit stores a queued job and wakes a worker.
+func (s *CalculationJobServiceImpl) Submit(ctx context.Context, job *model.CalculationJob, items []dto.CalculatePacksRequest) (*dto.CalculationJobResponse, error) {
+	unfinished, err := s.repo.CountUnfinished(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if unfinished >= int64(s.cfg.MaxQueued) {
+		return nil, ErrJobQueueFull
+	}
+
+	request, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = idgen.NewUUID()
+	job.Status = model.JobStatusQueued
+	job.Items = len(items)
+	job.Request = request
+	job.CreatedAt = s.now()
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return jobResponse(job)
+}
+
+// Get loads a job. Jobs of other callers are reported as not found.
+func (s *CalculationJobServiceImpl) Get(ctx context.Context, id, owner string) (*dto.CalculationJobResponse, error) {
+	job, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Finished jobs linger until the TTL monitor runs
+	if job == nil || job.Owner != owner || (job.ExpiresAt != nil && !s.now().Before(*job.ExpiresAt)) {
+		return nil, ErrJobNotFound
+	}
+	return jobResponse(job)
+}
+
+// jobResponse converts job, decoding its results.
+func jobResponse(job *model.CalculationJob) (*dto.CalculationJobResponse, error) {
+	resp := &dto.CalculationJobResponse{
+		JobID:       job.ID,
+		Status:      job.Status,
+		Items:       job.Items,
+		Succeeded:   job.Succeeded,
+		Failed:      job.Failed,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+	}
+	if len(job.Results) > 0 {
+		if err := json.Unmarshal(job.Results, &resp.Results); err != nil {
+			return nil, fmt.Errorf("decoding job results: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// Start starts cfg.Workers workers.
+func (s *CalculationJobServiceImpl) Start(process CalculationJobProcessor) {
+	s.startOnce.Do(func() {
+		s.process = process
+		for i := 0; i < s.cfg.Workers; i++ {
+			s.wg.Add(1)
+			go s.work()
+		}
+	})
+}
+
+// Shutdown stops the workers.
+func (s *CalculationJobServiceImpl) Shutdown(ctx context.Context) {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+
+	// Interrupt the running jobs and give them a moment to be re-queued
+	s.cancelRun()
+	select {
+	case <-done:
+	case <-time.After(jobStoreTimeout):
+		log.Warn().Msg("Calculation jobs still running at shutdown; they are retried once their lease ends")
+	}
+}
+
+// work runs queued jobs until the service shuts down, polling when idle.
+func (s *CalculationJobServiceImpl) work() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for s.runNext() {
+		}
+		select {
+		case <-s.stopCh:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one job. It returns false when there is none, the
+// queue could not be read or the service is shutting down.
+func (s *CalculationJobServiceImpl) runNext() bool {
+	select {
+	case <-s.stopCh:
+		return false
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobStoreTimeout)
+	job, err := s.repo.ClaimNext(ctx, s.cfg.Timeout)
+	cancel()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to claim calculation job")
+		return false
+	}
+	if job == nil {
+		return false
+	}
+	s.run(job)
+	return true
+}
+
+// run runs a claimed job and stores its outcome.
+func (s *CalculationJobServiceImpl) run(job *model.CalculationJob) {
+	start := time.Now()
+	if job.Attempts > maxJobAttempts {
+		s.fail(job, fmt.Sprintf("job did not finish in %d attempts", maxJobAttempts), start)
+		return
+	}
+	var items []dto.CalculatePacksRequest
+	if err := json.Unmarshal(job.Request, &items); err != nil {
+		s.fail(job, "job request is unreadable", start)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.runCtx, s.cfg.Timeout)
+	results := s.process(ctx, job, items)
+	cancel()
+
+	switch {
+	case s.runCtx.Err() != nil:
+		s.release(job, start)
+		return
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		s.fail(job, fmt.Sprintf("job did not finish within %s", s.cfg.Timeout), start)
+		return
+	}
+
+	succeeded, failed := 0, 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+		} else {
+			succeeded++
+		}
+	}
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		s.fail(job, "job results could not be stored", start)
+		return
+	}
+
+	storeCtx, storeCancel := context.WithTimeout(context.Background(), jobStoreTimeout)
+	defer storeCancel()
+	if err := s.repo.Complete(storeCtx, job.ID, encoded, succeeded, failed, s.now().Add(s.cfg.Retention)); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to store calculation job results")
+		return
+	}
+	metrics.RecordCalculationJob(model.JobStatusCompleted, time.Since(start))
+}
+
+func (s *CalculationJobServiceImpl) fail(job *model.CalculationJob, reason string, start time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobStoreTimeout)
+	defer cancel()
+	if err := s.repo.Fail(ctx, job.ID, reason, s.now().Add(s.cfg.Retention)); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to mark calculation job as failed")
+		return
+	}
+	log.Warn().Str("job_id", job.ID).Str("reason", reason).Msg("Calculation job failed")
+	metrics.RecordCalculationJob(model.JobStatusFailed, time.Since(start))
+}
+
+func (s *CalculationJobServiceImpl) release(job *model.CalculationJob, start time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobStoreTimeout)
+	defer cancel()
+	if err := s.repo.Release(ctx, job.ID); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to re-queue calculation job")
+		return
+	}
+	metrics.RecordCalculationJob("released", time.Since(start))
+}
@@ -0,0 +1,274 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func jobConfig() service.CalculationJobConfig {
+	return service.CalculationJobConfig{
+		Workers:      1,
+		Retention:    time.Hour,
+		Timeout:      time.Minute,
+		MaxQueued:    10,
+		PollInterval: 10 * time.Millisecond,
+	}
+}
+
+// queuedJob returns a claimed job for items.
+func queuedJob(t *testing.T, items ...dto.CalculatePacksRequest) *model.CalculationJob {
+	t.Helper()
+	request, err := json.Marshal(items)
+	require.NoError(t, err)
+	return &model.CalculationJob{
+		ID:       uuid.NewString(),
+		Owner:    "user-1",
+		Status:   model.JobStatusRunning,
+		Items:    len(items),
+		Request:  request,
+		Attempts: 1,
+	}
+}
+
+// oneResultPerItem is a processor that fails items ordering 0.
+func oneResultPerItem(_ context.Context, _ *model.CalculationJob, items []dto.CalculatePacksRequest) []dto.BatchItemResult {
+	results := make([]dto.BatchItemResult, len(items))
+	for i, item := range items {
+		results[i].Index = i
+		if item.ItemsOrdered == 0 {
+			results[i].Error = &dto.BatchItemError{Code: dto.ErrCodeInvalidRequest}
+		} else {
+			results[i].Result = &model.PackResult{OrderedItems: item.ItemsOrdered, TotalItems: item.ItemsOrdered}
+		}
+	}
+	return results
+}
+
+func TestCalculationJobService_Submit(t *testing.T) {
+	items := []dto.CalculatePacksRequest{{ItemsOrdered: 251}, {ItemsOrdered: 0}}
+
+	t.Run("queues the job", func(t *testing.T) {
+		repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+		svc := service.NewCalculationJobService(repo, jobConfig())
+
+		repo.EXPECT().CountUnfinished(mock.Anything).Return(3, nil)
+		repo.EXPECT().Create(mock.Anything, mock.MatchedBy(func(job *model.CalculationJob) bool {
+			var stored []dto.CalculatePacksRequest
+			return job.Status == model.JobStatusQueued && job.Owner == "user-1" && job.Items == 2 &&
+				json.Unmarshal(job.Request, &stored) == nil && assert.ObjectsAreEqual(items, stored)
+		})).Return(nil)
+
+		resp, err := svc.Submit(context.Background(), &model.CalculationJob{Owner: "user-1"}, items)
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.JobID)
+		assert.Equal(t, model.JobStatusQueued, resp.Status)
+		assert.Equal(t, 2, resp.Items)
+		assert.False(t, resp.CreatedAt.IsZero())
+	})
+
+	t.Run("rejects jobs when the queue is full", func(t *testing.T) {
+		repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+		svc := service.NewCalculationJobService(repo, jobConfig())
+
+		repo.EXPECT().CountUnfinished(mock.Anything).Return(10, nil)
+
+		_, err := svc.Submit(context.Background(), &model.CalculationJob{}, items)
+		assert.ErrorIs(t, err, service.ErrJobQueueFull)
+	})
+}
+
+func TestCalculationJobService_Get(t *testing.T) {
+	repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+	svc := service.NewCalculationJobService(repo, jobConfig())
+
+	results, err := json.Marshal([]dto.BatchItemResult{{Index: 0, Result: &model.PackResult{TotalItems: 500}}})
+	require.NoError(t, err)
+	expiresAt := time.Now().Add(time.Hour)
+	job := &model.CalculationJob{
+		ID:        uuid.NewString(),
+		Owner:     "user-1",
+		Status:    model.JobStatusCompleted,
+		Items:     1,
+		Results:   results,
+		Succeeded: 1,
+		ExpiresAt: &expiresAt,
+	}
+	repo.EXPECT().Get(mock.Anything, job.ID).Return(job, nil)
+
+	t.Run("returns the owner's job with its results", func(t *testing.T) {
+		resp, err := svc.Get(context.Background(), job.ID, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, model.JobStatusCompleted, resp.Status)
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, 500, resp.Results[0].Result.TotalItems)
+	})
+
+	t.Run("hides other callers' jobs", func(t *testing.T) {
+		_, err := svc.Get(context.Background(), job.ID, "user-2")
+		assert.ErrorIs(t, err, service.ErrJobNotFound)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		missing := uuid.NewString()
+		repo.EXPECT().Get(mock.Anything, missing).Return(nil, nil)
+
+		_, err := svc.Get(context.Background(), missing, "user-1")
+		assert.ErrorIs(t, err, service.ErrJobNotFound)
+	})
+
+	t.Run("expired jobs the TTL monitor has not removed yet", func(t *testing.T) {
+		expired := *job
+		past := time.Now().Add(-time.Minute)
+		expired.ID = uuid.NewString()
+		expired.ExpiresAt = &past
+		repo.EXPECT().Get(mock.Anything, expired.ID).Return(&expired, nil)
+
+		_, err := svc.Get(context.Background(), expired.ID, "user-1")
+		assert.ErrorIs(t, err, service.ErrJobNotFound)
+	})
+}
+
+func TestCalculationJobService_Workers(t *testing.T) {
+	t.Run("runs queued jobs and stores their results", func(t *testing.T) {
+		repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+		svc := service.NewCalculationJobService(repo, jobConfig())
+		job := queuedJob(t, dto.CalculatePacksRequest{ItemsOrdered: 251}, dto.CalculatePacksRequest{})
+
+		done := make(chan []dto.BatchItemResult, 1)
+		repo.EXPECT().ClaimNext(mock.Anything, time.Minute).Return(job, nil).Once()
+		repo.EXPECT().ClaimNext(mock.Anything, time.Minute).Return(nil, nil).Maybe()
+		repo.EXPECT().Complete(mock.Anything, job.ID, mock.Anything, 1, 1, mock.Anything).
+			Run(func(_ context.Context, _ string, encoded []byte, _, _ int, _ time.Time) {
+				var results []dto.BatchItemResult
+				_ = json.Unmarshal(encoded, &results)
+				done <- results
+			}).Return(nil)
+
+		svc.Start(oneResultPerItem)
+		defer svc.Shutdown(context.Background())
+
+		select {
+		case results := <-done:
+			require.Len(t, results, 2)
+			assert.Equal(t, 251, results[0].Result.OrderedItems)
+			assert.NotNil(t, results[1].Error)
+		case <-time.After(2 * time.Second):
+			t.Fatal("job was not completed")
+		}
+	})
+
+	t.Run("fails jobs that keep being interrupted", func(t *testing.T) {
+		repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+		svc := service.NewCalculationJobService(repo, jobConfig())
+		job := queuedJob(t, dto.CalculatePacksRequest{ItemsOrdered: 251})
+		job.Attempts = 4
+
+		failed := make(chan string, 1)
+		repo.EXPECT().ClaimNext(mock.Anything, mock.Anything).Return(job, nil).Once()
+		repo.EXPECT().ClaimNext(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+		repo.EXPECT().Fail(mock.Anything, job.ID, mock.Anything, mock.Anything).
+			Run(func(_ context.Context, _ string, reason string, _ time.Time) {
+				failed <- reason
+			}).Return(nil)
+
+		svc.Start(func(context.Context, *model.CalculationJob, []dto.CalculatePacksRequest) []dto.BatchItemResult {
+			t.Error("job was run again")
+			return nil
+		})
+		defer svc.Shutdown(context.Background())
+
+		select {
+		case reason := <-failed:
+			assert.Contains(t, reason, "3 attempts")
+		case <-time.After(2 * time.Second):
+			t.Fatal("job was not failed")
+		}
+	})
+
+	t.Run("fails jobs that run out of time", func(t *testing.T) {
+		repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+		cfg := jobConfig()
+		cfg.Timeout = 20 * time.Millisecond
+		svc := service.NewCalculationJobService(repo, cfg)
+		job := queuedJob(t, dto.CalculatePacksRequest{ItemsOrdered: 251})
+
+		failed := make(chan string, 1)
+		repo.EXPECT().ClaimNext(mock.Anything, cfg.Timeout).Return(job, nil).Once()
+		repo.EXPECT().ClaimNext(mock.Anything, cfg.Timeout).Return(nil, nil).Maybe()
+		repo.EXPECT().Fail(mock.Anything, job.ID, mock.Anything, mock.Anything).
+			Run(func(_ context.Context, _ string, reason string, _ time.Time) {
+				failed <- reason
+			}).Return(nil)
+
+		svc.Start(func(ctx context.Context, _ *model.CalculationJob, _ []dto.CalculatePacksRequest) []dto.BatchItemResult {
+			<-ctx.Done()
+			return nil
+		})
+		defer svc.Shutdown(context.Background())
+
+		select {
+		case reason := <-failed:
+			assert.Contains(t, reason, "did not finish within")
+		case <-time.After(2 * time.Second):
+			t.Fatal("job was not failed")
+		}
+	})
+}
+
+func TestCalculationJobService_Shutdown(t *testing.T) {
+	t.Run("waits for running jobs", func(t *testing.T) {
+		repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+		svc := service.NewCalculationJobService(repo, jobConfig())
+		job := queuedJob(t, dto.CalculatePacksRequest{ItemsOrdered: 251})
+
+		started := make(chan struct{})
+		repo.EXPECT().ClaimNext(mock.Anything, mock.Anything).Return(job, nil).Once()
+		repo.EXPECT().ClaimNext(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+		repo.EXPECT().Complete(mock.Anything, job.ID, mock.Anything, 1, 0, mock.Anything).Return(nil).Once()
+
+		svc.Start(func(ctx context.Context, job *model.CalculationJob, items []dto.CalculatePacksRequest) []dto.BatchItemResult {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return oneResultPerItem(ctx, job, items)
+		})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		svc.Shutdown(ctx)
+	})
+
+	t.Run("re-queues jobs still running at the deadline", func(t *testing.T) {
+		repo := mocks.NewMockCalculationJobRepositoryInterface(t)
+		svc := service.NewCalculationJobService(repo, jobConfig())
+		job := queuedJob(t, dto.CalculatePacksRequest{ItemsOrdered: 251})
+
+		started := make(chan struct{})
+		repo.EXPECT().ClaimNext(mock.Anything, mock.Anything).Return(job, nil).Once()
+		repo.EXPECT().ClaimNext(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+		repo.EXPECT().Release(mock.Anything, job.ID).Return(nil).Once()
+
+		svc.Start(func(ctx context.Context, _ *model.CalculationJob, _ []dto.CalculatePacksRequest) []dto.BatchItemResult {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		svc.Shutdown(ctx)
+	})
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// DefaultCalculationRollupLookbackDays is how many days, today included,
+	// each run recomputes unless configured otherwise. Yesterday is included
+	// so calculations flushed just after midnight are counted.
+	DefaultCalculationRollupLookbackDays = 2
+
+	// calculationRollupTimeout bounds one run.
+	calculationRollupTimeout = 5 * time.Minute
+)
+
+// CalculationRollupConfig configures a CalculationRollupJob.
+type CalculationRollupConfig struct {
+	// Interval is how often the rollups are recomputed.
+	Interval time.Duration
+	// LookbackDays is how many days, today included, each run recomputes.
+	LookbackDays int
+}
+
+// CalculationRollupJob maintains the daily calculation rollups that the
+// analytics API reads. Each run recomputes the recent days from the raw
+// history; recomputing is idempotent, so every instance may run the job.
+type CalculationRollupJob struct {
+	repo repository.CalculationRepositoryInterface
+	cfg  CalculationRollupConfig
+	now  func() time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewCalculationRollupJob creates a rollup job writing through repo. Call
+// Start to run it.
+func NewCalculationRollupJob(repo repository.CalculationRepositoryInterface, cfg CalculationRollupConfig) *CalculationRollupJob {
+	if cfg.LookbackDays <= 0 {
+		cfg.LookbackDays = DefaultCalculationRollupLookbackDays
+	}
+	return &CalculationRollupJob{
+		repo:   repo,
+		cfg:    cfg,
+		now:    timeutil.Now,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+}
+
+// Start runs the job now and then every cfg.Interval until Stop is called.
+func (j *CalculationRollupJob) Start() {
+	go func() {
+		defer close(j.doneCh)
+
+		j.runInBackground()
+		ticker := time.NewTicker(j.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				j.runInBackground()
+			case <-j.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the job and waits for a running recomputation to finish.
+func (j *CalculationRollupJob) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stopCh)
+		<-j.doneCh
+	})
+}
+
+// Run recomputes the rollups of the last cfg.LookbackDays UTC days, one day
+// at a time so a long lookback does not hold one large aggregation open.
+func (j *CalculationRollupJob) Run(ctx context.Context) error {
+	if j.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+
+	now := j.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for day := today.AddDate(0, 0, 1-j.cfg.LookbackDays); !day.After(today); day = day.AddDate(0, 0, 1) {
+		if err := j.repo.RollUp(ctx, day, day.AddDate(0, 0, 1), j.now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *CalculationRollupJob) runInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), calculationRollupTimeout)
+	defer cancel()
+
+	if err := j.Run(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to roll up calculation history")
+	}
+}
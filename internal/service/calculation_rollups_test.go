@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestCalculationRollupJob_Run(t *testing.T) {
+	t.Run("recomputes each lookback day", func(t *testing.T) {
+		repo := mocks.NewMockCalculationRepositoryInterface(t)
+		job := service.NewCalculationRollupJob(repo, service.CalculationRollupConfig{Interval: time.Hour, LookbackDays: 3})
+
+		var days []time.Time
+		repo.EXPECT().RollUp(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			RunAndReturn(func(_ context.Context, from, to, asOf time.Time) error {
+				assert.Equal(t, from.AddDate(0, 0, 1), to)
+				assert.False(t, asOf.Before(from))
+				days = append(days, from)
+				return nil
+			}).Times(3)
+
+		require.NoError(t, job.Run(context.Background()))
+		require.Len(t, days, 3)
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		assert.Equal(t, []time.Time{today.AddDate(0, 0, -2), today.AddDate(0, 0, -1), today}, days)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		repo := mocks.NewMockCalculationRepositoryInterface(t)
+		job := service.NewCalculationRollupJob(repo, service.CalculationRollupConfig{Interval: time.Hour})
+
+		repo.EXPECT().RollUp(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+		assert.Error(t, job.Run(context.Background()))
+	})
+
+	t.Run("without repository", func(t *testing.T) {
+		job := service.NewCalculationRollupJob(nil, service.CalculationRollupConfig{Interval: time.Hour})
+		assert.ErrorIs(t, job.Run(context.Background()), service.ErrRepositoryNotConfigured)
+	})
+}
+
+func TestCalculationRollupJob_StartRunsImmediately(t *testing.T) {
+	repo := mocks.NewMockCalculationRepositoryInterface(t)
+	job := service.NewCalculationRollupJob(repo, service.CalculationRollupConfig{Interval: time.Hour})
+
+	ran := make(chan struct{}, service.DefaultCalculationRollupLookbackDays)
+	repo.EXPECT().RollUp(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(context.Context, time.Time, time.Time, time.Time) error {
+			ran <- struct{}{}
+			return nil
+		}).Times(service.DefaultCalculationRollupLookbackDays)
+
+	job.Start()
+	for range service.DefaultCalculationRollupLookbackDays {
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatal("rollups were not recomputed on start")
+		}
+	}
+	job.Stop()
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// DefaultClientUsageFlushInterval is how often buffered counts are written
+	// when no interval is configured.
+	DefaultClientUsageFlushInterval = 30 * time.Second
+
+	// maxPendingClientUsages bounds the counts buffered between flushes.
+	maxPendingClientUsages = 10000
+	// maxUserAgentLength and maxClientFieldLength bound stored header values.
+	maxUserAgentLength   = 256
+	maxClientFieldLength = 64
+	// clientUsageFlushTimeout bounds a background flush.
+	clientUsageFlushTimeout = 5 * time.Second
+)
+
+// ClientUsageService records which user agents and client versions each
+// client uses. Record is called on the request path, so counts are buffered
+// in memory and written in batches.
+type ClientUsageService interface {
+	Record(client, userAgent, clientVersion string)
+	List(ctx context.Context, client string) ([]*model.ClientUsage, error)
+	Flush(ctx context.Context) error
+	Stop()
+}
+
+// clientUsageKey identifies a buffered count.
+type clientUsageKey struct {
+	client    string
+	userAgent string
+	version   string
+}
+
+// ClientUsageServiceImpl implements ClientUsageService.
+type ClientUsageServiceImpl struct {
+	repo    repository.ClientUsageRepositoryInterface
+	mu      sync.Mutex
+	pending map[clientUsageKey]*model.ClientUsage
+	now     func() time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewClientUsageService creates a client usage service that flushes buffered
+// counts to repo every flushInterval. Call Stop to write the remaining counts.
+func NewClientUsageService(repo repository.ClientUsageRepositoryInterface, flushInterval time.Duration) ClientUsageService {
+	if flushInterval <= 0 {
+		flushInterval = DefaultClientUsageFlushInterval
+	}
+
+	s := &ClientUsageServiceImpl{
+		repo:    repo,
+		pending: make(map[clientUsageKey]*model.ClientUsage),
+		now:     timeutil.Now,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	go s.flushLoop(flushInterval)
+	return s
+}
+
+// Record counts one request by client. The client version is taken from
+// clientVersion (the X-Client-Version header) when set, otherwise from the
+// user agent. Requests beyond the buffer limit are dropped until the next flush.
+func (s *ClientUsageServiceImpl) Record(client, userAgent, clientVersion string) {
+	if client == "" {
+		return
+	}
+
+	userAgent = truncate(strings.TrimSpace(userAgent), maxUserAgentLength)
+	name, version := ParseClientVersion(clientVersion, userAgent)
+	key := clientUsageKey{client: client, userAgent: userAgent, version: version}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.pending[key]
+	if !ok {
+		if len(s.pending) >= maxPendingClientUsages {
+			return
+		}
+		u = &model.ClientUsage{
+			Client:        client,
+			UserAgent:     userAgent,
+			ClientName:    name,
+			ClientVersion: version,
+			FirstSeen:     now,
+		}
+		s.pending[key] = u
+	}
+	u.RequestCount++
+	u.LastSeen = now
+}
+
+// List flushes this instance's buffered counts and returns the stored usage,
+// most recently seen first. An empty client lists all clients.
+func (s *ClientUsageServiceImpl) List(ctx context.Context, client string) ([]*model.ClientUsage, error) {
+	if s.repo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if err := s.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, client)
+}
+
+// Flush writes buffered counts. On failure they are kept for the next flush.
+func (s *ClientUsageServiceImpl) Flush(ctx context.Context) error {
+	if s.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[clientUsageKey]*model.ClientUsage, len(batch))
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	usages := make([]*model.ClientUsage, 0, len(batch))
+	for _, u := range batch {
+		usages = append(usages, u)
+	}
+	if err := s.repo.Increment(ctx, usages); err != nil {
+		s.restore(batch)
+		return err
+	}
+	return nil
+}
+
+// restore merges a batch that failed to flush back into the buffer.
+func (s *ClientUsageServiceImpl) restore(batch map[clientUsageKey]*model.ClientUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, u := range batch {
+		current, ok := s.pending[key]
+		if !ok {
+			if len(s.pending) < maxPendingClientUsages {
+				s.pending[key] = u
+			}
+			continue
+		}
+		current.RequestCount += u.RequestCount
+		if u.FirstSeen.Before(current.FirstSeen) {
+			current.FirstSeen = u.FirstSeen
+		}
+	}
+}
+
+// Stop ends the background flush and writes the remaining counts.
+func (s *ClientUsageServiceImpl) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.doneCh
+	})
+}
+
+func (s *ClientUsageServiceImpl) flushLoop(interval time.Duration) {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flushInBackground()
+		case <-s.stopCh:
+			s.flushInBackground()
+			return
+		}
+	}
+}
+
+func (s *ClientUsageServiceImpl) flushInBackground() {
+	if s.repo == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clientUsageFlushTimeout)
+	defer cancel()
+
+	if err := s.Flush(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to flush client usage stats")
+	}
+}
+
+// ParseClientVersion extracts the client name and version. header is the
+// X-Client-Version value, either "name/version" or a bare version. Without it,
+// the first product token of the user agent is used, e.g. "pack-sdk-go/1.4.2"
+// in "pack-sdk-go/1.4.2 (linux; go1.25)".
+func ParseClientVersion(header, userAgent string) (name, version string) {
+	token := strings.TrimSpace(header)
+	if token == "" {
+		token, _, _ = strings.Cut(strings.TrimSpace(userAgent), " ")
+		if !strings.Contains(token, "/") {
+			return truncate(token, maxClientFieldLength), ""
+		}
+	} else if !strings.Contains(token, "/") {
+		return "", truncate(token, maxClientFieldLength)
+	}
+
+	name, version, _ = strings.Cut(token, "/")
+	return truncate(name, maxClientFieldLength), truncate(version, maxClientFieldLength)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
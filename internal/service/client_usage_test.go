@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestParseClientVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		userAgent   string
+		wantName    string
+		wantVersion string
+	}{
+		{"header with name", "pack-sdk-js/2.0.1", "Mozilla/5.0", "pack-sdk-js", "2.0.1"},
+		{"bare header version", "2.0.1", "pack-sdk-go/1.4.2", "", "2.0.1"},
+		{"user agent product token", "", "pack-sdk-go/1.4.2 (linux; go1.25)", "pack-sdk-go", "1.4.2"},
+		{"user agent without version", "", "internal-cron", "internal-cron", ""},
+		{"nothing", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, version := service.ParseClientVersion(tt.header, tt.userAgent)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
+
+func TestClientUsageService_FlushAggregates(t *testing.T) {
+	repo := mocks.NewMockClientUsageRepositoryInterface(t)
+	svc := service.NewClientUsageService(repo, time.Hour)
+
+	svc.Record("api_key:0a1b2c3d", "pack-sdk-go/1.4.2", "")
+	svc.Record("api_key:0a1b2c3d", "pack-sdk-go/1.4.2", "")
+	svc.Record("api_key:0a1b2c3d", "pack-sdk-go/1.5.0", "")
+	svc.Record("", "curl/8.5.0", "") // anonymous requests are not tracked
+
+	repo.On("Increment", mock.Anything, mock.MatchedBy(func(usages []*model.ClientUsage) bool {
+		counts := map[string]int64{}
+		for _, u := range usages {
+			counts[u.ClientVersion] = u.RequestCount
+		}
+		return len(usages) == 2 && counts["1.4.2"] == 2 && counts["1.5.0"] == 1
+	})).Return(nil).Once()
+
+	require.NoError(t, svc.Flush(context.Background()))
+	// Nothing left to write
+	require.NoError(t, svc.Flush(context.Background()))
+	svc.Stop()
+}
+
+func TestClientUsageService_FailedFlushIsRetried(t *testing.T) {
+	repo := mocks.NewMockClientUsageRepositoryInterface(t)
+	svc := service.NewClientUsageService(repo, time.Hour)
+	defer svc.Stop()
+
+	svc.Record("user:42", "pack-sdk-go/1.4.2", "")
+	repo.On("Increment", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+	require.Error(t, svc.Flush(context.Background()))
+
+	svc.Record("user:42", "pack-sdk-go/1.4.2", "")
+	repo.On("Increment", mock.Anything, mock.MatchedBy(func(usages []*model.ClientUsage) bool {
+		return len(usages) == 1 && usages[0].RequestCount == 2
+	})).Return(nil).Once()
+	require.NoError(t, svc.Flush(context.Background()))
+}
+
+func TestClientUsageService_ListFlushesFirst(t *testing.T) {
+	repo := mocks.NewMockClientUsageRepositoryInterface(t)
+	svc := service.NewClientUsageService(repo, time.Hour)
+	defer svc.Stop()
+
+	svc.Record("user:42", "", "3.0.0")
+	stored := []*model.ClientUsage{{Client: "user:42", ClientVersion: "3.0.0", RequestCount: 1}}
+	repo.On("Increment", mock.Anything, mock.Anything).Return(nil).Once()
+	repo.On("List", mock.Anything, "user:42").Return(stored, nil).Once()
+
+	usages, err := svc.List(context.Background(), "user:42")
+	require.NoError(t, err)
+	assert.Equal(t, stored, usages)
+}
+
+func TestClientUsageService_StopFlushesRemaining(t *testing.T) {
+	repo := mocks.NewMockClientUsageRepositoryInterface(t)
+	svc := service.NewClientUsageService(repo, time.Hour)
+
+	svc.Record("user:42", "pack-sdk-go/1.4.2", "")
+	repo.On("Increment", mock.Anything, mock.Anything).Return(nil).Once()
+
+	svc.Stop()
+	svc.Stop() // idempotent
+}
+
+func TestClientUsageService_NilRepository(t *testing.T) {
+	svc := service.NewClientUsageService(nil, time.Hour)
+	defer svc.Stop()
+
+	svc.Record("user:42", "pack-sdk-go/1.4.2", "")
+	_, err := svc.List(context.Background(), "")
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// ReloadableSetting is a setting ConfigReloader changes without a restart.
+type ReloadableSetting struct {
+	// Name is the setting's environment variable, such as RATE_LIMIT.
+	Name string
+	// Value formats the setting of a Config, as reported in changes. The
+	// setting is applied when its value changes.
+	Value func(cfg config.Config) string
+	// Apply makes the setting of cfg take effect.
+	Apply func(cfg config.Config)
+}
+
+// ConfigReloader reloads the configuration at runtime and applies the
+// settings that can change without a restart. Other settings keep their
+// startup values.
+type ConfigReloader struct {
+	mu       sync.Mutex
+	load     func() (config.Config, error)
+	current  config.Config
+	settings []ReloadableSetting
+	logging  LoggingService
+}
+
+// NewConfigReloader creates a reloader applying settings from the Config
+// load returns, starting from current. Reloads that Watch triggers are
+// audited through logging, if set.
+func NewConfigReloader(current config.Config, load func() (config.Config, error), settings []ReloadableSetting, logging LoggingService) *ConfigReloader {
+	return &ConfigReloader{
+		load:     load,
+		current:  current,
+		settings: settings,
+		logging:  logging,
+	}
+}
+
+// Reload loads the configuration and applies the settings whose value
+// changed, returning them. When loading fails, nothing is applied.
+func (r *ConfigReloader) Reload() (dto.ConfigReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := r.load()
+	if err != nil {
+		return dto.ConfigReloadResult{}, err
+	}
+
+	changes := []dto.ConfigChange{}
+	for _, setting := range r.settings {
+		oldValue, newValue := setting.Value(r.current), setting.Value(cfg)
+		if oldValue == newValue {
+			continue
+		}
+		setting.Apply(cfg)
+		changes = append(changes, dto.ConfigChange{Setting: setting.Name, Old: oldValue, New: newValue})
+	}
+	r.current = cfg
+	return dto.ConfigReloadResult{Changes: changes, ReloadedAt: timeutil.Now()}, nil
+}
+
+// Watch reloads on every signal received from signals and, when interval is
+// positive, whenever the modification time of the file at path changes,
+// checked every interval. It returns when ctx is done. Each reload is logged
+// and audited.
+func (r *ConfigReloader) Watch(ctx context.Context, signals <-chan os.Signal, path string, interval time.Duration) {
+	var ticks <-chan time.Time
+	var modTime time.Time
+	if interval > 0 && path != "" {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+		modTime = fileModTime(path)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			r.reloadInBackground(sig.String())
+		case <-ticks:
+			if t := fileModTime(path); !t.IsZero() && !t.Equal(modTime) {
+				modTime = t
+				r.reloadInBackground("file")
+			}
+		}
+	}
+}
+
+// reloadInBackground reloads for trigger, logging and auditing the result.
+func (r *ConfigReloader) reloadInBackground(trigger string) {
+	result, err := r.Reload()
+	entry := &model.LogEntry{
+		Timestamp:  timeutil.Now(),
+		Level:      "info",
+		Message:    "Configuration reloaded",
+		ActionType: model.ActionReloadConfig,
+		Fields:     map[string]interface{}{"trigger": trigger, "changes": result.Changes},
+	}
+	if err != nil {
+		log.Error().Err(err).Str("trigger", trigger).Msg("Configuration reload failed, settings unchanged")
+		entry.Level = "error"
+		entry.Message = "Configuration reload failed"
+		entry.Error = err.Error()
+	} else {
+		log.Info().Str("trigger", trigger).Interface("changes", result.Changes).Msg("Configuration reloaded")
+	}
+
+	if r.logging == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.logging.CreateLog(ctx, entry); err != nil {
+		log.Warn().Err(err).Msg("Failed to audit configuration reload")
+	}
+}
+
+// fileModTime returns the modification time of the file at path, or the zero
+// time when it cannot be read.
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
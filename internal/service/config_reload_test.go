@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// rateLimitSetting is a reloadable RATE_LIMIT recording the applied values.
+func rateLimitSetting(applied *[]int) service.ReloadableSetting {
+	return service.ReloadableSetting{
+		Name:  "RATE_LIMIT",
+		Value: func(cfg config.Config) string { return strconv.Itoa(cfg.Server.RateLimit) },
+		Apply: func(cfg config.Config) { *applied = append(*applied, cfg.Server.RateLimit) },
+	}
+}
+
+func TestConfigReloader_Reload(t *testing.T) {
+	var current config.Config
+	current.Server.RateLimit = 100
+	next := current
+	var loadErr error
+	load := func() (config.Config, error) { return next, loadErr }
+
+	var applied []int
+	reloader := service.NewConfigReloader(current, load, []service.ReloadableSetting{rateLimitSetting(&applied)}, nil)
+
+	t.Run("unchanged settings are not applied", func(t *testing.T) {
+		result, err := reloader.Reload()
+		require.NoError(t, err)
+		assert.Empty(t, result.Changes)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("applies changed settings", func(t *testing.T) {
+		next.Server.RateLimit = 200
+		result, err := reloader.Reload()
+		require.NoError(t, err)
+		assert.Equal(t, []dto.ConfigChange{{Setting: "RATE_LIMIT", Old: "100", New: "200"}}, result.Changes)
+		assert.Equal(t, []int{200}, applied)
+	})
+
+	t.Run("applies nothing when loading fails", func(t *testing.T) {
+		next.Server.RateLimit = 300
+		loadErr = errors.New("invalid line")
+		_, err := reloader.Reload()
+		assert.Error(t, err)
+		assert.Equal(t, []int{200}, applied)
+
+		loadErr = nil
+		result, err := reloader.Reload()
+		require.NoError(t, err)
+		assert.Equal(t, "200", result.Changes[0].Old)
+	})
+}
+
+func TestConfigReloader_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pack-service.env")
+	require.NoError(t, os.WriteFile(path, []byte("RATE_LIMIT=100\n"), 0o600))
+	load := func() (config.Config, error) { return config.LoadFile(path) }
+	current, err := load()
+	require.NoError(t, err)
+
+	audited := make(chan *model.LogEntry, 3)
+	logging := mocks.NewMockLoggingService(t)
+	logging.EXPECT().CreateLog(mock.Anything, mock.Anything).Run(func(_ context.Context, entry *model.LogEntry) {
+		audited <- entry
+	}).Return(nil)
+
+	var applied []int
+	reloader := service.NewConfigReloader(current, load, []service.ReloadableSetting{rateLimitSetting(&applied)}, logging)
+	signals := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		reloader.Watch(ctx, signals, path, 10*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// A signal reloads the file
+	signals <- syscall.SIGHUP
+	entry := <-audited
+	assert.Equal(t, model.ActionReloadConfig, entry.ActionType)
+	assert.Equal(t, "hangup", entry.Fields["trigger"])
+	assert.Empty(t, entry.Fields["changes"])
+
+	// A modified file is reloaded on its own
+	require.NoError(t, os.WriteFile(path, []byte("RATE_LIMIT=150\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	entry = <-audited
+	assert.Equal(t, "file", entry.Fields["trigger"])
+	assert.Equal(t, []dto.ConfigChange{{Setting: "RATE_LIMIT", Old: "100", New: "150"}}, entry.Fields["changes"])
+
+	// Failures are audited as errors
+	require.NoError(t, os.Remove(path))
+	signals <- syscall.SIGHUP
+	entry = <-audited
+	assert.Equal(t, "error", entry.Level)
+	assert.NotEmpty(t, entry.Error)
+	assert.Equal(t, []int{150}, applied)
+}
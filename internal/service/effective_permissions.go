@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// EffectivePermissions resolves the user's roles and the permissions they
+// grant the way authorization does: every role assigned, active or not,
+// grants every permission it lists. Roles and permissions that no longer
+// exist are reported apart, since they grant nothing.
+func (s *UserServiceImpl) EffectivePermissions(ctx context.Context, id string) (*dto.EffectivePermissions, error) {
+	if s.roleRepo == nil || s.permissionRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.EffectivePermissions{
+		UserID:      user.ID.Hex(),
+		Email:       user.Email,
+		Active:      user.Active,
+		Roles:       []dto.EffectiveRole{},
+		Permissions: []dto.EffectivePermission{},
+	}
+
+	roles, err := s.roleRepo.FindByIDs(ctx, user.Roles)
+	if err != nil {
+		return nil, err
+	}
+	rolesByID := make(map[string]*model.Role, len(roles))
+	for _, role := range roles {
+		rolesByID[role.ID.Hex()] = role
+	}
+
+	// grantedBy maps each permission ID to the roles listing it, in the
+	// order of the user's roles
+	grantedBy := make(map[string][]string)
+	var permissionIDs []string
+	for _, roleID := range user.Roles {
+		role, ok := rolesByID[roleID]
+		if !ok {
+			result.MissingRoleIDs = append(result.MissingRoleIDs, roleID)
+			continue
+		}
+		result.Roles = append(result.Roles, dto.EffectiveRole{ID: roleID, Name: role.Name, Active: role.Active})
+		for _, permissionID := range role.Permissions {
+			if _, ok := grantedBy[permissionID]; !ok {
+				permissionIDs = append(permissionIDs, permissionID)
+			}
+			if !slices.Contains(grantedBy[permissionID], role.Name) {
+				grantedBy[permissionID] = append(grantedBy[permissionID], role.Name)
+			}
+		}
+	}
+	if len(permissionIDs) == 0 {
+		return result, nil
+	}
+
+	permissions, err := s.permissionRepo.FindByIDs(ctx, permissionIDs)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		permissionID := permission.ID.Hex()
+		found[permissionID] = true
+		result.Permissions = append(result.Permissions, dto.EffectivePermission{
+			ID:        permissionID,
+			Name:      permission.Name,
+			Resource:  permission.Resource,
+			Action:    permission.Action,
+			GrantedBy: grantedBy[permissionID],
+		})
+	}
+	for _, permissionID := range permissionIDs {
+		if !found[permissionID] {
+			result.MissingPermissionIDs = append(result.MissingPermissionIDs, permissionID)
+		}
+	}
+
+	slices.SortFunc(result.Permissions, func(a, b dto.EffectivePermission) int {
+		if c := strings.Compare(a.Resource, b.Resource); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Action, b.Action)
+	})
+	return result, nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// explainAlternatives is the most alternatives an explanation lists of each
+// kind found by total.
+const explainAlternatives = 3
+
+// Explain tells why result, calculated for its order with packSizes (the
+// configured ones when empty) within constraints, was chosen. It compares the
+// result with the combinations of the nearest other totals, with the same
+// total split into more packs, and with the greedy combination. Results
+// optimized for cost are only summarized. It returns nil for empty results.
+func (s *PackCalculatorService) Explain(result model.PackResult, packSizes []int, constraints model.PackConstraints) *model.Explanation {
+	if len(result.Packs) == 0 {
+		return nil
+	}
+	sizes := s.sortedSizes(packSizes)
+	target := result.OrderedItems
+	if result.Approximate {
+		return &model.Explanation{
+			Summary: fmt.Sprintf("%d items in %s is the greedy combination, filling the order of %d with the largest packs first: "+
+				"it was not compared with other combinations because the exact search ran out of time or memory",
+				result.TotalItems, packsText(result.PackCount()), target),
+			Alternatives: []model.Alternative{},
+		}
+	}
+
+	if constraints.MinCost() {
+		cost := ""
+		if result.TotalCost != nil {
+			cost = fmt.Sprintf(" at a cost of %s", strconv.FormatFloat(*result.TotalCost, 'f', -1, 64))
+		}
+		return &model.Explanation{
+			Summary: fmt.Sprintf("%d items in %s is the cheapest combination covering the order of %d%s: "+
+				"fewer items or packs were only preferred among equally cheap combinations",
+				result.TotalItems, packsText(result.PackCount()), target, cost),
+			Alternatives: []model.Alternative{},
+		}
+	}
+
+	limit := result.TotalItems + sizes[0]
+	combination := s.fewestPacks(target, limit, sizes)
+	explanation := &model.Explanation{Alternatives: []model.Alternative{}}
+	add := func(kind string, alt model.PackResult, reason string) {
+		if slices.Equal(alt.Packs, result.Packs) {
+			return
+		}
+		for _, existing := range explanation.Alternatives {
+			if slices.Equal(existing.Packs, alt.Packs) {
+				return
+			}
+		}
+		explanation.Alternatives = append(explanation.Alternatives, model.Alternative{
+			Kind:         kind,
+			TotalItems:   alt.TotalItems,
+			OverageItems: alt.TotalItems - target,
+			PackCount:    alt.PackCount(),
+			Packs:        alt.Packs,
+			Reason:       reason,
+		})
+	}
+
+	// Smaller totals are only passed over when the constraints rule them out
+	fewer := 0
+	for total := target; total < result.TotalItems && fewer < explainAlternatives; total++ {
+		if alt, ok := combination(total); ok {
+			add(model.AlternativeFewerItems, alt, cmp.Or(rejection(alt, constraints), model.ReasonExceedsMaxPacks))
+			fewer++
+		}
+	}
+	for _, pack := range result.Packs {
+		if alt, ok := splitPack(result, pack.Size, sizes); ok {
+			add(model.AlternativeSplitPack, alt, cmp.Or(rejection(alt, constraints), model.ReasonMorePacks))
+		}
+	}
+	// A greedy combination as good as the result is just another optimum
+	if greedy := greedyResult(target, sizes); result.GreedyDiffers {
+		reason := model.ReasonMorePacks
+		if greedy.TotalItems > result.TotalItems {
+			reason = model.ReasonMoreItems
+		}
+		add(model.AlternativeGreedy, greedy, cmp.Or(rejection(greedy, constraints), reason))
+	}
+	more := 0
+	for total := result.TotalItems + 1; total <= limit && more < explainAlternatives; total++ {
+		if alt, ok := combination(total); ok {
+			add(model.AlternativeMoreItems, alt, cmp.Or(rejection(alt, constraints), model.ReasonMoreItems))
+			more++
+		}
+	}
+
+	slices.SortStableFunc(explanation.Alternatives, func(a, b model.Alternative) int {
+		if a.TotalItems != b.TotalItems {
+			return a.TotalItems - b.TotalItems
+		}
+		return a.PackCount - b.PackCount
+	})
+
+	within := ""
+	if fewer > 0 {
+		within = " within the constraints"
+	}
+	explanation.Summary = fmt.Sprintf("%d items is the smallest total covering the order of %d (%d over)%s, and no combination ships it in fewer than %s",
+		result.TotalItems, target, result.TotalItems-target, within, packsText(result.PackCount()))
+	return explanation
+}
+
+// fewestPacks returns a function giving the fewest-pack combination of each
+// total up to limit, for an order of target, and false for totals no
+// combination reaches. Orders the bounded search handles are answered from
+// its residue table, which does not know every small total.
+func (s *PackCalculatorService) fewestPacks(target, limit int, sizes []int) func(total int) (model.PackResult, bool) {
+	if s.boundedSearchThreshold > 0 && target >= s.boundedSearchThreshold && sizes[0] <= target/2 {
+		paths := residuePaths(sizes[0], sizes[1:])
+		return func(total int) (model.PackResult, bool) {
+			residue := total % sizes[0]
+			if paths.cost[residue] < 0 || paths.sum[residue] > total {
+				return model.PackResult{}, false
+			}
+			return paths.result(target, total, sizes), true
+		}
+	}
+
+	dp := make([]int, limit+1)
+	parent := make([]int, limit+1)
+	for i := 1; i <= limit; i++ {
+		dp[i] = -1
+	}
+	for i := 0; i <= limit; i++ {
+		if dp[i] < 0 {
+			continue
+		}
+		for _, size := range sizes {
+			if next := i + size; next <= limit && (dp[next] < 0 || dp[i]+1 < dp[next]) {
+				dp[next] = dp[i] + 1
+				parent[next] = size
+			}
+		}
+	}
+	return func(total int) (model.PackResult, bool) {
+		if total > limit || dp[total] < 0 {
+			return model.PackResult{}, false
+		}
+		return s.buildResultWithSizes(target, total, parent, sizes), true
+	}
+}
+
+// splitPack returns result with one pack of size replaced by packs of the
+// largest smaller size that divides it, and false when none does.
+func splitPack(result model.PackResult, size int, sizes []int) (model.PackResult, bool) {
+	for _, smaller := range sizes {
+		if smaller >= size || size%smaller != 0 {
+			continue
+		}
+		counts := make(map[int]int, len(result.Packs)+1)
+		for _, pack := range result.Packs {
+			counts[pack.Size] = pack.Quantity
+		}
+		counts[size]--
+		counts[smaller] += size / smaller
+
+		alt := model.PackResult{OrderedItems: result.OrderedItems, TotalItems: result.TotalItems, Packs: make([]model.Pack, 0, len(counts))}
+		for _, s := range sizes {
+			if counts[s] > 0 {
+				alt.Packs = append(alt.Packs, model.Pack{Size: s, Quantity: counts[s]})
+			}
+		}
+		return alt, true
+	}
+	return model.PackResult{}, false
+}
+
+// rejection returns the constraint alt breaks, or "" when it satisfies them.
+func rejection(alt model.PackResult, constraints model.PackConstraints) string {
+	if maxTotal, ok := constraints.MaxTotalItems(alt.OrderedItems); ok && alt.TotalItems > maxTotal {
+		return model.ReasonExceedsMaxOverage
+	}
+	if constraints.MaxPacks != nil && alt.PackCount() > *constraints.MaxPacks {
+		return model.ReasonExceedsMaxPacks
+	}
+	return ""
+}
+
+// packsText formats a pack count, e.g. "1 pack" or "3 packs".
+func packsText(count int) string {
+	if count == 1 {
+		return "1 pack"
+	}
+	return fmt.Sprintf("%d packs", count)
+}
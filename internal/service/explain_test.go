@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestPackCalculatorService_Explain(t *testing.T) {
+	calc := NewPackCalculatorService()
+
+	t.Run("compares with splitting the pack and larger totals", func(t *testing.T) {
+		result := calc.Calculate(251)
+
+		explanation := calc.Explain(result, nil, model.PackConstraints{})
+
+		require.NotNil(t, explanation)
+		assert.Equal(t, "500 items is the smallest total covering the order of 251 (249 over), and no combination ships it in fewer than 1 pack", explanation.Summary)
+		assert.Equal(t, []model.Alternative{
+			{Kind: model.AlternativeSplitPack, TotalItems: 500, OverageItems: 249, PackCount: 2, Packs: []model.Pack{{Size: 250, Quantity: 2}}, Reason: model.ReasonMorePacks},
+			{Kind: model.AlternativeMoreItems, TotalItems: 750, OverageItems: 499, PackCount: 2, Packs: []model.Pack{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}}, Reason: model.ReasonMoreItems},
+			{Kind: model.AlternativeMoreItems, TotalItems: 1000, OverageItems: 749, PackCount: 1, Packs: []model.Pack{{Size: 1000, Quantity: 1}}, Reason: model.ReasonMoreItems},
+			{Kind: model.AlternativeMoreItems, TotalItems: 1250, OverageItems: 999, PackCount: 2, Packs: []model.Pack{{Size: 1000, Quantity: 1}, {Size: 250, Quantity: 1}}, Reason: model.ReasonMoreItems},
+		}, explanation.Alternatives)
+	})
+
+	t.Run("lists smaller totals ruled out by the constraints", func(t *testing.T) {
+		sizes := []int{23, 31, 53}
+		maxPacks := 2
+		constraints := model.PackConstraints{MaxPacks: &maxPacks}
+		result, err := calc.CalculateWithConstraints(100, sizes, constraints)
+		require.NoError(t, err)
+
+		explanation := calc.Explain(result, sizes, constraints)
+
+		require.NotNil(t, explanation)
+		assert.Contains(t, explanation.Summary, "106 items is the smallest total covering the order of 100 (6 over) within the constraints")
+		require.NotEmpty(t, explanation.Alternatives)
+		assert.Equal(t, model.Alternative{
+			Kind: model.AlternativeFewerItems, TotalItems: 100, OverageItems: 0, PackCount: 4,
+			Packs: []model.Pack{{Size: 31, Quantity: 1}, {Size: 23, Quantity: 3}}, Reason: model.ReasonExceedsMaxPacks,
+		}, explanation.Alternatives[0])
+		for _, alt := range explanation.Alternatives[1:] {
+			assert.Greater(t, alt.TotalItems, result.TotalItems)
+			assert.Equal(t, model.ReasonExceedsMaxPacks, alt.Reason)
+		}
+	})
+
+	t.Run("reports the greedy combination when it differs", func(t *testing.T) {
+		sizes := []int{23, 31, 53}
+		result := calc.CalculateWithPackSizes(500000, sizes)
+		require.True(t, result.GreedyDiffers)
+
+		explanation := calc.Explain(result, sizes, model.PackConstraints{})
+
+		require.NotNil(t, explanation)
+		assert.Contains(t, explanation.Alternatives, model.Alternative{
+			Kind: model.AlternativeGreedy, TotalItems: 500003, OverageItems: 3, PackCount: 9435,
+			Packs: []model.Pack{{Size: 53, Quantity: 9433}, {Size: 31, Quantity: 1}, {Size: 23, Quantity: 1}}, Reason: model.ReasonMoreItems,
+		})
+	})
+
+	t.Run("approximate results are not compared", func(t *testing.T) {
+		result, err := calc.CalculateWithin(500000, []int{23, 31, 53}, model.PackConstraints{}, time.Nanosecond)
+		require.NoError(t, err)
+		require.True(t, result.Approximate)
+
+		explanation := calc.Explain(result, []int{23, 31, 53}, model.PackConstraints{})
+
+		require.NotNil(t, explanation)
+		assert.Contains(t, explanation.Summary, "greedy combination")
+		assert.Empty(t, explanation.Alternatives)
+	})
+
+	t.Run("empty results have no explanation", func(t *testing.T) {
+		assert.Nil(t, calc.Explain(model.Empty(0), nil, model.PackConstraints{}))
+	})
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// CalculateGreedy fills the order with the largest packs first. Unlike the
+// other calculations it does not search, so it suits approximate answers and
+// running degraded. Greedy results are not cached.
+func (s *PackCalculatorService) CalculateGreedy(itemsOrdered int, packSizes []int) model.PackResult {
+	if itemsOrdered <= 0 {
+		return model.Empty(itemsOrdered)
+	}
+	sizes := s.sortedSizes(packSizes)
+	if len(sizes) == 0 {
+		return model.Empty(itemsOrdered)
+	}
+	return greedyResult(itemsOrdered, sizes)
+}
+
+// greedyResult fills the order with the largest packs first and covers the
+// remainder with one smallest pack. It is linear in the number of sizes but
+// may ship more items or packs than the optimal combination.
+func greedyResult(target int, packSizes []int) model.PackResult {
+	counts := make([]int, len(packSizes))
+	remaining := target
+	for i, size := range packSizes {
+		counts[i] = remaining / size
+		remaining -= counts[i] * size
+	}
+	if remaining > 0 {
+		counts[len(counts)-1]++
+	}
+
+	result := model.PackResult{OrderedItems: target, Packs: make([]model.Pack, 0, len(packSizes))}
+	for i, count := range counts {
+		if count > 0 {
+			result.Packs = append(result.Packs, model.Pack{Size: packSizes[i], Quantity: count})
+			result.TotalItems += packSizes[i] * count
+		}
+	}
+	return result
+}
+
+// satisfies reports whether result is within constraints.
+func satisfies(result model.PackResult, constraints model.PackConstraints) bool {
+	if maxTotal, ok := constraints.MaxTotalItems(result.OrderedItems); ok && result.TotalItems > maxTotal {
+		return false
+	}
+	return constraints.MaxPacks == nil || result.PackCount() <= *constraints.MaxPacks
+}
+
+// compareWithGreedy sets result.GreedyDiffers, the exact result being
+// computed for packSizes (sorted descending) within constraints.
+//
+// The greedy combination doubles as a sanity check: the search must never
+// do worse than it. If it did, the greedy combination is returned instead.
+func compareWithGreedy(result model.PackResult, packSizes []int, constraints model.PackConstraints) model.PackResult {
+	greedy := greedyResult(result.OrderedItems, packSizes)
+	if !satisfies(greedy, constraints) {
+		result.GreedyDiffers = true
+		return result
+	}
+
+	greedyPacks, resultPacks := greedy.PackCount(), result.PackCount()
+	if greedy.TotalItems < result.TotalItems || (greedy.TotalItems == result.TotalItems && greedyPacks < resultPacks) {
+		log.Error().
+			Int("items_ordered", result.OrderedItems).
+			Ints("pack_sizes", packSizes).
+			Int("total_items", result.TotalItems).
+			Int("greedy_total_items", greedy.TotalItems).
+			Msg("Pack search returned a worse combination than greedy; using the greedy one")
+		metrics.RecordGreedySanityFailure()
+		return greedy
+	}
+
+	result.GreedyDiffers = greedy.TotalItems != result.TotalItems || greedyPacks != resultPacks
+	return result
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestPackCalculatorService_CalculateGreedy(t *testing.T) {
+	calc := NewPackCalculatorService()
+
+	t.Run("uses the configured pack sizes", func(t *testing.T) {
+		result := calc.CalculateGreedy(12001, nil)
+
+		assert.Equal(t, 12250, result.TotalItems)
+		assert.Equal(t, []model.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}}, result.Packs)
+		assert.False(t, result.Approximate)
+	})
+
+	t.Run("sorts custom pack sizes", func(t *testing.T) {
+		result := calc.CalculateGreedy(500000, []int{23, 53, 31})
+
+		assert.Equal(t, 500003, result.TotalItems)
+		assert.Equal(t, []model.Pack{{Size: 53, Quantity: 9433}, {Size: 31, Quantity: 1}, {Size: 23, Quantity: 1}}, result.Packs)
+	})
+
+	t.Run("empty order", func(t *testing.T) {
+		assert.Equal(t, model.Empty(0), calc.CalculateGreedy(0, nil))
+	})
+}
+
+func TestPackCalculatorService_GreedyDiffers(t *testing.T) {
+	calc := NewPackCalculatorService()
+
+	t.Run("greedy matches", func(t *testing.T) {
+		assert.False(t, calc.Calculate(12001).GreedyDiffers)
+		assert.False(t, calc.Calculate(250).GreedyDiffers)
+	})
+
+	t.Run("greedy ships more items", func(t *testing.T) {
+		// Greedy sends two 250 packs where one 500 pack does
+		result := calc.Calculate(251)
+
+		assert.Equal(t, 500, result.TotalItems)
+		assert.True(t, result.GreedyDiffers)
+	})
+
+	t.Run("greedy breaks the constraints", func(t *testing.T) {
+		maxOverage := 0
+		result, err := calc.CalculateWithConstraints(500000, []int{23, 31, 53}, model.PackConstraints{MaxOverageItems: &maxOverage})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 500000, result.TotalItems)
+		assert.True(t, result.GreedyDiffers)
+	})
+}
+
+func TestCompareWithGreedy(t *testing.T) {
+	sizes := []int{500, 250}
+
+	t.Run("replaces results worse than greedy", func(t *testing.T) {
+		worse := model.PackResult{OrderedItems: 500, TotalItems: 500, Packs: []model.Pack{{Size: 250, Quantity: 2}}}
+
+		result := compareWithGreedy(worse, sizes, model.PackConstraints{})
+
+		assert.Equal(t, []model.Pack{{Size: 500, Quantity: 1}}, result.Packs)
+		assert.False(t, result.GreedyDiffers)
+	})
+
+	t.Run("keeps results greedy cannot match", func(t *testing.T) {
+		maxPacks := 1
+		exact := model.PackResult{OrderedItems: 251, TotalItems: 500, Packs: []model.Pack{{Size: 500, Quantity: 1}}}
+
+		result := compareWithGreedy(exact, sizes, model.PackConstraints{MaxPacks: &maxPacks})
+
+		assert.Equal(t, exact.Packs, result.Packs)
+		assert.True(t, result.GreedyDiffers)
+	})
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// Defaults for InputWarningDetector.
+const (
+	defaultLargeOrderThreshold = 1_000_000
+	defaultRepeatThreshold     = 3
+	defaultRepeatWindow        = time.Minute
+	// maxTrackedOrders bounds the memory used to detect repeated orders.
+	maxTrackedOrders = 10000
+)
+
+// scaledPackSizeFactor is the multiplier checked by the scaled pack size warning.
+const scaledPackSizeFactor = 1000
+
+// InputWarningDetector inspects calculate inputs for values that are valid
+// but look like client bugs.
+type InputWarningDetector interface {
+	// Inspect returns warnings for a request from clientID. rawPackSizes are the
+	// pack sizes as sent by the client and effectivePackSizes those used for the
+	// calculation; either may be empty.
+	Inspect(clientID string, itemsOrdered int, rawPackSizes, effectivePackSizes []int) []model.InputWarning
+}
+
+// InputWarningOption configures an InputWarningDetector.
+type InputWarningOption func(*inputWarningDetector)
+
+// WithLargeOrderThreshold sets the order size from which repeated identical
+// orders are flagged.
+func WithLargeOrderThreshold(items int) InputWarningOption {
+	return func(d *inputWarningDetector) {
+		if items > 0 {
+			d.largeOrderThreshold = items
+		}
+	}
+}
+
+// WithRepeatDetection sets how many identical large orders within window
+// trigger a repeated order warning.
+func WithRepeatDetection(threshold int, window time.Duration) InputWarningOption {
+	return func(d *inputWarningDetector) {
+		if threshold > 1 {
+			d.repeatThreshold = threshold
+		}
+		if window > 0 {
+			d.repeatWindow = window
+		}
+	}
+}
+
+// inputWarningDetector implements InputWarningDetector.
+type inputWarningDetector struct {
+	largeOrderThreshold int
+	repeatThreshold     int
+	repeatWindow        time.Duration
+	now                 func() time.Time
+
+	mu     sync.Mutex
+	orders map[string]*orderSeen
+}
+
+// orderSeen tracks repetitions of one large order within the current window.
+type orderSeen struct {
+	count       int
+	windowStart time.Time
+}
+
+// NewInputWarningDetector creates an InputWarningDetector with the given options.
+func NewInputWarningDetector(opts ...InputWarningOption) InputWarningDetector {
+	d := &inputWarningDetector{
+		largeOrderThreshold: defaultLargeOrderThreshold,
+		repeatThreshold:     defaultRepeatThreshold,
+		repeatWindow:        defaultRepeatWindow,
+		now:                 time.Now,
+		orders:              make(map[string]*orderSeen),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Inspect implements InputWarningDetector.
+func (d *inputWarningDetector) Inspect(clientID string, itemsOrdered int, rawPackSizes, effectivePackSizes []int) []model.InputWarning {
+	warnings := checkPackSizes(rawPackSizes)
+
+	for _, size := range effectivePackSizes {
+		if size > 0 && itemsOrdered == size*scaledPackSizeFactor {
+			warnings = append(warnings, model.InputWarning{
+				Code:  model.WarningScaledPackSize,
+				Field: "items_ordered",
+				Message: fmt.Sprintf("items_ordered is exactly %d times the pack size %d; check that items are not sent in the wrong unit",
+					scaledPackSizeFactor, size),
+			})
+			break
+		}
+	}
+
+	if itemsOrdered >= d.largeOrderThreshold {
+		if count := d.recordLargeOrder(clientID, itemsOrdered, rawPackSizes); count >= d.repeatThreshold {
+			warnings = append(warnings, model.InputWarning{
+				Code:  model.WarningRepeatedLargeOrder,
+				Field: "items_ordered",
+				Message: fmt.Sprintf("identical order of %d items received %d times within %s; check for a client retry loop",
+					itemsOrdered, count, d.repeatWindow),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// checkPackSizes flags non-positive and duplicate client pack sizes.
+func checkPackSizes(sizes []int) []model.InputWarning {
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	var warnings []model.InputWarning
+	seen := make(map[int]bool, len(sizes))
+	ignored, duplicates := 0, 0
+	for _, size := range sizes {
+		if size <= 0 {
+			ignored++
+			continue
+		}
+		if seen[size] {
+			duplicates++
+		}
+		seen[size] = true
+	}
+
+	if ignored > 0 {
+		warnings = append(warnings, model.InputWarning{
+			Code:    model.WarningIgnoredPackSizes,
+			Field:   "pack_sizes",
+			Message: fmt.Sprintf("%d non-positive pack size(s) were ignored", ignored),
+		})
+	}
+	if duplicates > 0 {
+		warnings = append(warnings, model.InputWarning{
+			Code:    model.WarningDuplicatePackSizes,
+			Field:   "pack_sizes",
+			Message: fmt.Sprintf("%d pack size(s) were listed more than once", duplicates),
+		})
+	}
+
+	return warnings
+}
+
+// recordLargeOrder counts an order against its fixed window and returns the
+// number of times it was seen in that window.
+func (d *inputWarningDetector) recordLargeOrder(clientID string, itemsOrdered int, packSizes []int) int {
+	key := orderKey(clientID, itemsOrdered, packSizes)
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	seen, ok := d.orders[key]
+	if !ok || now.Sub(seen.windowStart) >= d.repeatWindow {
+		if !ok && len(d.orders) >= maxTrackedOrders {
+			d.evictExpired(now)
+		}
+		if len(d.orders) >= maxTrackedOrders {
+			// Still full of live entries: skip tracking rather than grow unbounded
+			return 1
+		}
+		seen = &orderSeen{windowStart: now}
+		d.orders[key] = seen
+	}
+
+	seen.count++
+	return seen.count
+}
+
+// evictExpired removes orders whose window has passed. Caller must hold d.mu.
+func (d *inputWarningDetector) evictExpired(now time.Time) {
+	for key, seen := range d.orders {
+		if now.Sub(seen.windowStart) >= d.repeatWindow {
+			delete(d.orders, key)
+		}
+	}
+}
+
+// orderKey identifies an order by client, quantity and requested pack sizes.
+func orderKey(clientID string, itemsOrdered int, packSizes []int) string {
+	var b strings.Builder
+	b.WriteString(clientID)
+	b.WriteByte('|')
+	b.WriteString(strconv.Itoa(itemsOrdered))
+	for _, size := range packSizes {
+		b.WriteByte(',')
+		b.WriteString(strconv.Itoa(size))
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func warningCodes(warnings []model.InputWarning) []string {
+	codes := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestInputWarningDetector_Inspect(t *testing.T) {
+	tests := []struct {
+		name           string
+		itemsOrdered   int
+		rawPackSizes   []int
+		effectiveSizes []int
+		expected       []string
+	}{
+		{
+			name:           "ordinary order",
+			itemsOrdered:   251,
+			effectiveSizes: []int{250, 500, 1000},
+			expected:       []string{},
+		},
+		{
+			name:           "pack size times 1000",
+			itemsOrdered:   250000,
+			effectiveSizes: []int{250, 500, 1000},
+			expected:       []string{model.WarningScaledPackSize},
+		},
+		{
+			name:           "multiple of 1000 that is not a pack size",
+			itemsOrdered:   300000,
+			effectiveSizes: []int{250, 500, 1000},
+			expected:       []string{},
+		},
+		{
+			name:         "non-positive custom sizes",
+			itemsOrdered: 100,
+			rawPackSizes: []int{0, -5, 23},
+			expected:     []string{model.WarningIgnoredPackSizes},
+		},
+		{
+			name:           "duplicate custom sizes",
+			itemsOrdered:   100,
+			rawPackSizes:   []int{23, 31, 23},
+			effectiveSizes: []int{23, 31, 23},
+			expected:       []string{model.WarningDuplicatePackSizes},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewInputWarningDetector()
+
+			warnings := d.Inspect("client", tt.itemsOrdered, tt.rawPackSizes, tt.effectiveSizes)
+
+			assert.Equal(t, tt.expected, warningCodes(warnings))
+			for _, w := range warnings {
+				assert.NotEmpty(t, w.Field)
+				assert.NotEmpty(t, w.Message)
+			}
+		})
+	}
+}
+
+func TestInputWarningDetector_RepeatedLargeOrder(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	d := NewInputWarningDetector(
+		WithLargeOrderThreshold(1000),
+		WithRepeatDetection(3, time.Minute),
+	).(*inputWarningDetector)
+	d.now = func() time.Time { return now }
+
+	inspect := func(clientID string, items int) []string {
+		return warningCodes(d.Inspect(clientID, items, nil, nil))
+	}
+
+	assert.Empty(t, inspect("client-a", 5000))
+	assert.Empty(t, inspect("client-a", 5000))
+	assert.Equal(t, []string{model.WarningRepeatedLargeOrder}, inspect("client-a", 5000))
+
+	// Other clients, other quantities and small orders are tracked separately
+	assert.Empty(t, inspect("client-b", 5000))
+	assert.Empty(t, inspect("client-a", 6000))
+	for i := 0; i < 5; i++ {
+		assert.Empty(t, inspect("client-a", 999))
+	}
+
+	// A new window starts the count over
+	now = now.Add(time.Minute)
+	assert.Empty(t, inspect("client-a", 5000))
+}
+
+func TestInputWarningDetector_BoundedTracking(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	d := NewInputWarningDetector(WithLargeOrderThreshold(1)).(*inputWarningDetector)
+	d.now = func() time.Time { return now }
+
+	for i := 0; i < maxTrackedOrders+10; i++ {
+		d.Inspect(fmt.Sprintf("client-%d", i), 10, nil, nil)
+	}
+	assert.Len(t, d.orders, maxTrackedOrders)
+
+	// Expired entries are evicted to make room
+	now = now.Add(defaultRepeatWindow)
+	d.Inspect("late-client", 10, nil, nil)
+	assert.Len(t, d.orders, 1)
+}
+
+func TestInputWarningOptions_IgnoreInvalid(t *testing.T) {
+	d := NewInputWarningDetector(
+		WithLargeOrderThreshold(0),
+		WithRepeatDetection(1, -time.Second),
+	).(*inputWarningDetector)
+
+	assert.Equal(t, defaultLargeOrderThreshold, d.largeOrderThreshold)
+	assert.Equal(t, defaultRepeatThreshold, d.repeatThreshold)
+	assert.Equal(t, defaultRepeatWindow, d.repeatWindow)
+}
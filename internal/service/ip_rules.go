@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// maxIPRuleReasonLength bounds the stored reason of a rule.
+	maxIPRuleReasonLength = 256
+	// ipRuleReloadTimeout bounds a background reload.
+	ipRuleReloadTimeout = 5 * time.Second
+)
+
+var (
+	// ErrInvalidIPRule is returned when a rule fails validation.
+	ErrInvalidIPRule = domainerr.New("invalid_ip_rule", http.StatusBadRequest, "", "invalid IP rule")
+	// ErrIPRuleNotFound is returned when no stored rule has the ID.
+	ErrIPRuleNotFound = domainerr.New("ip_rule_not_found", http.StatusNotFound, i18n.ErrKeyNotFound, "IP rule not found")
+)
+
+// IPRuleService decides which client IPs may reach the service from CIDR
+// allow and deny rules. Rules come from the configuration and from MongoDB,
+// where admins add and delete them at runtime to block abusive ranges during
+// incidents. Changes apply at once on the instance that made them, and on the
+// others at their next reload.
+//
+// The most specific rule matching an IP decides, deny winning between rules
+// of the same range size, so a single address can be let through a denied
+// range or blocked inside an allowed one. IPs matching no rule are allowed,
+// unless an allow rule exists.
+type IPRuleService struct {
+	repo       repository.IPRuleRepositoryInterface
+	configured []*model.IPRule
+	now        func() time.Time
+	// rules holds the rules being enforced.
+	rules atomic.Pointer[ipRuleSet]
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// ipRuleSet is a parsed set of rules.
+type ipRuleSet struct {
+	rules    []*model.IPRule
+	prefixes []netip.Prefix
+	hasAllow bool
+}
+
+// NewIPRuleService creates a service enforcing the configured allow and deny
+// CIDR ranges and the rules stored in repo, which may be nil to enforce the
+// configured ones only. Call Reload to load the stored rules.
+func NewIPRuleService(repo repository.IPRuleRepositoryInterface, allow, deny []string) (*IPRuleService, error) {
+	s := &IPRuleService{
+		repo:   repo,
+		now:    timeutil.Now,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	addConfigured := func(action string, cidrs []string) error {
+		for _, cidr := range cidrs {
+			rule := &model.IPRule{CIDR: cidr, Action: action, Configured: true}
+			if err := normalizeIPRule(rule); err != nil {
+				return err
+			}
+			s.configured = append(s.configured, rule)
+		}
+		return nil
+	}
+	if err := addConfigured(model.IPRuleAllow, allow); err != nil {
+		return nil, err
+	}
+	if err := addConfigured(model.IPRuleDeny, deny); err != nil {
+		return nil, err
+	}
+	s.rules.Store(newIPRuleSet(s.configured, s.now()))
+	return s, nil
+}
+
+// Check reports whether a request from ip is allowed, and returns the rule
+// that decided it, or nil when no rule matches. Unparsable IPs are only
+// allowed when no allow rule exists.
+func (s *IPRuleService) Check(ip string) (bool, *model.IPRule) {
+	set := s.rules.Load()
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return !set.hasAllow, nil
+	}
+	addr = addr.Unmap()
+
+	now := s.now()
+	var match *model.IPRule
+	bits := -1
+	for i, prefix := range set.prefixes {
+		rule := set.rules[i]
+		if !prefix.Contains(addr) || rule.Expired(now) {
+			continue
+		}
+		if prefix.Bits() > bits || (prefix.Bits() == bits && rule.Action == model.IPRuleDeny) {
+			match, bits = rule, prefix.Bits()
+		}
+	}
+	if match == nil {
+		return !set.hasAllow, nil
+	}
+	return match.Action == model.IPRuleAllow, match
+}
+
+// List returns the configured rules followed by the stored ones, newest
+// first. Expired rules are left out.
+func (s *IPRuleService) List(ctx context.Context) ([]*model.IPRule, error) {
+	rules := make([]*model.IPRule, 0, len(s.configured))
+	rules = append(rules, s.configured...)
+	if s.repo == nil {
+		return rules, nil
+	}
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	for _, rule := range stored {
+		if !rule.Expired(now) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// Add validates and stores rule, and applies it. The CIDR range is stored in
+// canonical form; it returns ErrInvalidIPRule for a malformed rule and
+// repository.ErrIPRuleExists when the range already has a stored rule.
+func (s *IPRuleService) Add(ctx context.Context, rule *model.IPRule) error {
+	if s.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+	if err := normalizeIPRule(rule); err != nil {
+		return err
+	}
+	rule.CreatedAt = s.now()
+	if rule.ExpiresAt != nil && !rule.ExpiresAt.After(rule.CreatedAt) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrInvalidIPRule)
+	}
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// Delete deletes the stored rule id and stops applying it.
+func (s *IPRuleService) Delete(ctx context.Context, id string) error {
+	if s.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrIPRuleNotFound
+	}
+	deleted, err := s.repo.Delete(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrIPRuleNotFound
+	}
+	return s.Reload(ctx)
+}
+
+// Reload loads the stored rules from the repository. Stored rules that no
+// longer parse are skipped.
+func (s *IPRuleService) Reload(ctx context.Context) error {
+	if s.repo == nil {
+		return nil
+	}
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	rules := make([]*model.IPRule, 0, len(s.configured)+len(stored))
+	rules = append(rules, s.configured...)
+	rules = append(rules, stored...)
+	s.rules.Store(newIPRuleSet(rules, s.now()))
+	return nil
+}
+
+// Start reloads the rules every interval until Stop is called, so rules
+// added or deleted through other instances apply here too.
+func (s *IPRuleService) Start(interval time.Duration) {
+	go func() {
+		defer close(s.doneCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.reloadInBackground()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reloading started by Start and waits for a running reload.
+func (s *IPRuleService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.doneCh
+	})
+}
+
+func (s *IPRuleService) reloadInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), ipRuleReloadTimeout)
+	defer cancel()
+
+	if err := s.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to reload IP rules")
+	}
+}
+
+// newIPRuleSet parses rules, leaving out the expired ones and those that do
+// not parse.
+func newIPRuleSet(rules []*model.IPRule, now time.Time) *ipRuleSet {
+	set := &ipRuleSet{}
+	for _, rule := range rules {
+		if rule.Expired(now) {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(rule.CIDR)
+		if err != nil {
+			log.Warn().Str("cidr", rule.CIDR).Msg("Skipping IP rule with an invalid CIDR range")
+			continue
+		}
+		set.rules = append(set.rules, rule)
+		set.prefixes = append(set.prefixes, prefix.Masked())
+		set.hasAllow = set.hasAllow || rule.Action == model.IPRuleAllow
+	}
+	return set
+}
+
+// normalizeIPRule validates rule and stores its CIDR range in canonical
+// form. A bare IP address is taken as a single-address range.
+func normalizeIPRule(rule *model.IPRule) error {
+	if rule.Action != model.IPRuleAllow && rule.Action != model.IPRuleDeny {
+		return fmt.Errorf("%w: action must be %q or %q", ErrInvalidIPRule, model.IPRuleAllow, model.IPRuleDeny)
+	}
+	if len(rule.Reason) > maxIPRuleReasonLength {
+		return fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidIPRule, maxIPRuleReasonLength)
+	}
+
+	cidr := strings.TrimSpace(rule.CIDR)
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		addr, addrErr := netip.ParseAddr(cidr)
+		if addrErr != nil {
+			return fmt.Errorf("%w: %q is not a CIDR range or IP address", ErrInvalidIPRule, rule.CIDR)
+		}
+		prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+	}
+	rule.CIDR = prefix.Masked().String()
+	return nil
+}
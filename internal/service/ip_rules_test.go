@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestIPRuleService_Check(t *testing.T) {
+	svc, err := service.NewIPRuleService(nil, nil, []string{"203.0.113.0/24", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	allowed, rule := svc.Check("203.0.113.7")
+	assert.False(t, allowed)
+	require.NotNil(t, rule)
+	assert.Equal(t, "203.0.113.0/24", rule.CIDR)
+	assert.True(t, rule.Configured)
+
+	allowed, _ = svc.Check("2001:db8::1")
+	assert.False(t, allowed)
+	allowed, _ = svc.Check("::ffff:203.0.113.7")
+	assert.False(t, allowed, "IPv4-mapped addresses match IPv4 ranges")
+
+	allowed, rule = svc.Check("198.51.100.1")
+	assert.True(t, allowed, "without allow rules unmatched IPs are allowed")
+	assert.Nil(t, rule)
+	allowed, _ = svc.Check("not-an-ip")
+	assert.True(t, allowed)
+}
+
+func TestIPRuleService_CheckAllowList(t *testing.T) {
+	svc, err := service.NewIPRuleService(nil,
+		[]string{"10.0.0.0/8", "10.1.2.3"},
+		[]string{"10.1.0.0/16", "10.0.0.0/8"},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		ip   string
+		want bool
+	}{
+		{name: "deny wins over an allow rule of the same range", ip: "10.2.0.1", want: false},
+		{name: "more specific deny rule", ip: "10.1.0.1", want: false},
+		{name: "more specific allow rule", ip: "10.1.2.3", want: true},
+		{name: "outside the allow list", ip: "192.0.2.1", want: false},
+		{name: "unparsable IP with an allow list", ip: "not-an-ip", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, _ := svc.Check(tt.ip)
+			assert.Equal(t, tt.want, allowed)
+		})
+	}
+}
+
+func TestNewIPRuleService_InvalidCIDR(t *testing.T) {
+	_, err := service.NewIPRuleService(nil, []string{"10.0.0.0/33"}, nil)
+	assert.ErrorIs(t, err, service.ErrInvalidIPRule)
+	_, err = service.NewIPRuleService(nil, nil, []string{"example.com"})
+	assert.ErrorIs(t, err, service.ErrInvalidIPRule)
+}
+
+func TestIPRuleService_Add(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores and applies the rule", func(t *testing.T) {
+		repo := mocks.NewMockIPRuleRepositoryInterface(t)
+		svc, err := service.NewIPRuleService(repo, nil, nil)
+		require.NoError(t, err)
+
+		stored := &model.IPRule{ID: primitive.NewObjectID(), CIDR: "198.51.100.0/24", Action: model.IPRuleDeny}
+		repo.EXPECT().Create(ctx, mock.MatchedBy(func(r *model.IPRule) bool {
+			return r.CIDR == "198.51.100.0/24" && !r.CreatedAt.IsZero()
+		})).Return(nil).Once()
+		repo.EXPECT().List(ctx).Return([]*model.IPRule{stored}, nil).Once()
+
+		rule := &model.IPRule{CIDR: " 198.51.100.77/24 ", Action: model.IPRuleDeny, Reason: "credential stuffing"}
+		require.NoError(t, svc.Add(ctx, rule))
+		assert.Equal(t, "198.51.100.0/24", rule.CIDR)
+
+		allowed, match := svc.Check("198.51.100.9")
+		assert.False(t, allowed)
+		assert.Equal(t, stored.ID, match.ID)
+	})
+
+	t.Run("single addresses become single-address ranges", func(t *testing.T) {
+		repo := mocks.NewMockIPRuleRepositoryInterface(t)
+		svc, err := service.NewIPRuleService(repo, nil, nil)
+		require.NoError(t, err)
+		repo.EXPECT().Create(ctx, mock.Anything).Return(nil).Once()
+		repo.EXPECT().List(ctx).Return(nil, nil).Once()
+
+		rule := &model.IPRule{CIDR: "2001:db8::1", Action: model.IPRuleDeny}
+		require.NoError(t, svc.Add(ctx, rule))
+		assert.Equal(t, "2001:db8::1/128", rule.CIDR)
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		repo := mocks.NewMockIPRuleRepositoryInterface(t)
+		svc, err := service.NewIPRuleService(repo, nil, nil)
+		require.NoError(t, err)
+
+		past := time.Now().Add(-time.Minute)
+		for _, rule := range []*model.IPRule{
+			{CIDR: "10.0.0.0/8", Action: "block"},
+			{CIDR: "10.0.0.0/8"},
+			{CIDR: "10.0.0.300", Action: model.IPRuleDeny},
+			{CIDR: "10.0.0.0/8", Action: model.IPRuleDeny, ExpiresAt: &past},
+		} {
+			assert.ErrorIs(t, svc.Add(ctx, rule), service.ErrInvalidIPRule, "%+v", rule)
+		}
+	})
+
+	t.Run("duplicate range", func(t *testing.T) {
+		repo := mocks.NewMockIPRuleRepositoryInterface(t)
+		svc, err := service.NewIPRuleService(repo, nil, nil)
+		require.NoError(t, err)
+		repo.EXPECT().Create(ctx, mock.Anything).Return(repository.ErrIPRuleExists).Once()
+
+		err = svc.Add(ctx, &model.IPRule{CIDR: "10.0.0.0/8", Action: model.IPRuleDeny})
+		assert.ErrorIs(t, err, repository.ErrIPRuleExists)
+	})
+
+	t.Run("without repository", func(t *testing.T) {
+		svc, err := service.NewIPRuleService(nil, nil, nil)
+		require.NoError(t, err)
+		err = svc.Add(ctx, &model.IPRule{CIDR: "10.0.0.0/8", Action: model.IPRuleDeny})
+		assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	})
+}
+
+func TestIPRuleService_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockIPRuleRepositoryInterface(t)
+	svc, err := service.NewIPRuleService(repo, nil, nil)
+	require.NoError(t, err)
+
+	id := primitive.NewObjectID()
+	repo.EXPECT().Delete(ctx, id).Return(true, nil).Once()
+	repo.EXPECT().List(ctx).Return(nil, nil).Once()
+	require.NoError(t, svc.Delete(ctx, id.Hex()))
+
+	missing := primitive.NewObjectID()
+	repo.EXPECT().Delete(ctx, missing).Return(false, nil).Once()
+	assert.ErrorIs(t, svc.Delete(ctx, missing.Hex()), service.ErrIPRuleNotFound)
+	assert.ErrorIs(t, svc.Delete(ctx, "not-an-id"), service.ErrIPRuleNotFound)
+}
+
+func TestIPRuleService_ListAndReload(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockIPRuleRepositoryInterface(t)
+	svc, err := service.NewIPRuleService(repo, []string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	stored := []*model.IPRule{
+		{ID: primitive.NewObjectID(), CIDR: "192.0.2.0/24", Action: model.IPRuleAllow},
+		{ID: primitive.NewObjectID(), CIDR: "198.51.100.0/24", Action: model.IPRuleAllow, ExpiresAt: &past},
+		{ID: primitive.NewObjectID(), CIDR: "garbage", Action: model.IPRuleAllow},
+	}
+	repo.EXPECT().List(ctx).Return(stored, nil).Twice()
+
+	rules, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Equal(t, "10.0.0.0/8", rules[0].CIDR)
+	assert.True(t, rules[0].Configured)
+	assert.Equal(t, "192.0.2.0/24", rules[1].CIDR)
+
+	require.NoError(t, svc.Reload(ctx))
+	allowed, _ := svc.Check("192.0.2.1")
+	assert.True(t, allowed)
+	allowed, _ = svc.Check("198.51.100.1")
+	assert.False(t, allowed, "expired rules are not applied")
+
+	// A failed reload keeps the current rules
+	repo.EXPECT().List(ctx).Return(nil, errors.New("connection refused")).Once()
+	assert.Error(t, svc.Reload(ctx))
+	allowed, _ = svc.Check("192.0.2.1")
+	assert.True(t, allowed)
+}
+
+func TestIPRuleService_StartStop(t *testing.T) {
+	repo := mocks.NewMockIPRuleRepositoryInterface(t)
+	svc, err := service.NewIPRuleService(repo, nil, nil)
+	require.NoError(t, err)
+
+	reloaded := make(chan struct{}, 1)
+	repo.EXPECT().List(mock.Anything).Return([]*model.IPRule{
+		{ID: primitive.NewObjectID(), CIDR: "203.0.113.0/24", Action: model.IPRuleDeny},
+	}, nil).Run(func(context.Context) {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+	}).Maybe()
+
+	svc.Start(10 * time.Millisecond)
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("rules not reloaded")
+	}
+	svc.Stop()
+	svc.Stop()
+
+	assert.Eventually(t, func() bool {
+		allowed, _ := svc.Check("203.0.113.1")
+		return !allowed
+	}, time.Second, 5*time.Millisecond)
+}
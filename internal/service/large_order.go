@@ -0,0 +1,136 @@
+package service
+
+import (
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// DefaultBoundedSearchThreshold is the order size from which the calculator
+// stops allocating a table of every total up to the order.
+const DefaultBoundedSearchThreshold = 1_000_000
+
+// DefaultMaxTableTotals is the largest table of totals, 16 bytes each, the
+// exact search allocates for an order the bounded search cannot solve.
+const DefaultMaxTableTotals = 1 << 24
+
+// largeOrderResult finds the optimal combination for target using memory
+// proportional to the largest pack size L rather than to the order.
+//
+// Any combination splits into largest packs and the rest, whose sum R shares
+// the total's residue modulo L. For each residue, residuePaths finds the rest
+// minimizing L×packs - R; a total T with that residue then needs
+// (T + minimum) / L packs, provided R <= T. Totals are tried from target up to
+// maxItems, as the table search would, keeping the first within maxPacks.
+//
+// The result is exact when every R used is at most its total, which holds for
+// orders of at least (L-1) times the second largest size. It returns false
+// when it is not, and the caller must search another way.
+func largeOrderResult(target, maxItems, maxPacks int, packSizes []int) (model.PackResult, bool) {
+	largest := packSizes[0]
+	paths := residuePaths(largest, packSizes[1:])
+
+	for total := target; total <= maxItems; total++ {
+		residue := total % largest
+		if paths.cost[residue] < 0 {
+			continue
+		}
+		if paths.sum[residue] > total {
+			return model.PackResult{}, false
+		}
+		if maxPacks > 0 && (total+paths.cost[residue])/largest > maxPacks {
+			continue
+		}
+		return paths.result(target, total, packSizes), true
+	}
+	return model.Empty(target), true
+}
+
+// residueTable holds, for each residue modulo the largest pack size, the
+// cheapest combination of smaller packs with that residue.
+type residueTable struct {
+	largest int
+	// cost is L×packs - R of the combination, or -1 when no combination of
+	// smaller packs has the residue.
+	cost []int
+	// sum is R, the combination's total.
+	sum []int
+	// last is the pack added last, to walk the combination back.
+	last []int
+}
+
+// residuePaths builds the residue table with the round-robin algorithm of
+// Böcker and Lipták: each smaller size s adds s items for L - s, so its
+// effect is relaxed around every cycle of residues it links, starting from
+// the cycle's cheapest residue. It takes O(len(smaller) × L) time and no
+// memory beyond the table.
+func residuePaths(largest int, smaller []int) residueTable {
+	t := residueTable{
+		largest: largest,
+		cost:    make([]int, largest),
+		sum:     make([]int, largest),
+		last:    make([]int, largest),
+	}
+	for i := range t.cost {
+		t.cost[i] = -1
+	}
+	t.cost[0] = 0
+
+	for _, size := range smaller {
+		if size >= largest {
+			continue
+		}
+		cycles := gcd(size, largest)
+		length := largest / cycles
+		for start := 0; start < cycles; start++ {
+			cheapest := -1
+			for k, residue := 0, start; k < length; k, residue = k+1, (residue+size)%largest {
+				if t.cost[residue] >= 0 && (cheapest < 0 || t.cost[residue] < t.cost[cheapest]) {
+					cheapest = residue
+				}
+			}
+			if cheapest < 0 {
+				continue
+			}
+			for k, residue := 0, cheapest; k < length; k, residue = k+1, (residue+size)%largest {
+				next := (residue + size) % largest
+				cost := t.cost[residue] + largest - size
+				if t.cost[next] < 0 || cost < t.cost[next] {
+					t.cost[next] = cost
+					t.sum[next] = t.sum[residue] + size
+					t.last[next] = size
+				}
+			}
+		}
+	}
+	return t
+}
+
+// result builds the combination shipping total items for an order of target:
+// the smaller packs recorded for total's residue, and largest packs for the
+// rest. packSizes are sorted descending.
+func (t residueTable) result(target, total int, packSizes []int) model.PackResult {
+	counts := make(map[int]int, len(packSizes))
+	residue := total % t.largest
+	counts[t.largest] = (total - t.sum[residue]) / t.largest
+	for residue != 0 {
+		size := t.last[residue]
+		counts[size]++
+		residue = (residue - size%t.largest + t.largest) % t.largest
+	}
+
+	result := model.PackResult{OrderedItems: target, TotalItems: total, Packs: make([]model.Pack, 0, len(counts))}
+	for _, size := range packSizes {
+		if count := counts[size]; count > 0 {
+			result.Packs = append(result.Packs, model.Pack{Size: size, Quantity: count})
+			counts[size] = 0
+		}
+	}
+	return result
+}
+
+// gcd returns the greatest common divisor of two positive integers.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
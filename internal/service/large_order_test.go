@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// TestPackCalculatorService_BoundedSearch checks the bounded search against
+// the table search, which a zero threshold forces.
+func TestPackCalculatorService_BoundedSearch(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+	bounded := NewPackCalculatorService(WithBoundedSearchThreshold(1))
+	table := NewPackCalculatorService(WithBoundedSearchThreshold(0))
+
+	sets := [][]int{
+		{23, 31, 53},
+		{250, 500, 1000, 2000, 5000},
+		{6, 9, 20},
+		{250, 500},
+		{7, 7, 12},
+	}
+	constraints := []model.PackConstraints{
+		{},
+		{MaxPacks: intPtr(40)},
+		{MaxOverageItems: intPtr(0)},
+		{MaxOveragePercent: floatPtr(1)},
+	}
+
+	for _, sizes := range sets {
+		for _, c := range constraints {
+			for target := 1; target <= 3000; target += 7 {
+				want, wantErr := table.CalculateWithConstraints(target, sizes, c)
+				got, gotErr := bounded.CalculateWithConstraints(target, sizes, c)
+
+				if got.Approximate {
+					assert.NoError(t, gotErr)
+					assert.GreaterOrEqual(t, got.TotalItems, target)
+					continue
+				}
+				assert.Equal(t, wantErr, gotErr, "sizes %v target %d", sizes, target)
+				assert.Equal(t, want, got, "sizes %v target %d", sizes, target)
+			}
+		}
+	}
+}
+
+func TestPackCalculatorService_BoundedSearchLargeOrders(t *testing.T) {
+	t.Run("is exact above the threshold", func(t *testing.T) {
+		calc := NewPackCalculatorService(WithPackSizes([]int{23, 31, 53}), WithMaxItemsOrdered(1_000_000_000))
+
+		result := calc.Calculate(500_000_000)
+
+		assert.False(t, result.Approximate)
+		assert.Equal(t, 500_000_000, result.TotalItems)
+	})
+
+	t.Run("searches the table when it cannot prove the optimum", func(t *testing.T) {
+		calc := NewPackCalculatorService(WithBoundedSearchThreshold(1000))
+
+		result := calc.CalculateWithPackSizes(1500, []int{700, 699})
+
+		assert.False(t, result.Approximate)
+		assert.Equal(t, 2097, result.TotalItems)
+		assert.Equal(t, []model.Pack{{Size: 699, Quantity: 3}}, result.Packs)
+	})
+
+	t.Run("falls back to the greedy result when the table does not fit", func(t *testing.T) {
+		calc := NewPackCalculatorService(WithBoundedSearchThreshold(1000), WithMaxTableTotals(2000))
+
+		result := calc.CalculateWithPackSizes(1500, []int{700, 699})
+
+		assert.True(t, result.Approximate)
+		assert.Equal(t, greedyResult(1500, []int{700, 699}).TotalItems, result.TotalItems)
+	})
+
+	t.Run("leaves orders below the threshold to the table search", func(t *testing.T) {
+		calc := NewPackCalculatorService(WithBoundedSearchThreshold(1000))
+
+		assert.Equal(t, []model.Pack{{Size: 2, Quantity: 1}}, calc.CalculateWithPackSizes(2, []int{2, 3}).Packs)
+	})
+}
+
+func TestLargeOrderResult(t *testing.T) {
+	sizes := []int{53, 31, 23}
+
+	result, ok := largeOrderResult(1000, 1022, 0, sizes)
+	assert.True(t, ok)
+	assert.Equal(t, 1000, result.TotalItems)
+	assert.Equal(t, 20, result.PackCount())
+
+	result, ok = largeOrderResult(1001, 1001, 0, []int{10, 4})
+	assert.True(t, ok)
+	assert.Equal(t, model.Empty(1001), result)
+}
+
+func BenchmarkCalculate_BoundedSearch(b *testing.B) {
+	calc := NewPackCalculatorService(WithPackSizes([]int{23, 31, 53}))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calc.Calculate(5_000_000 + i%100_000)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// Legacy token migration defaults.
+const (
+	// DefaultLegacyTokenBatchSize is how many tokens a batch handles.
+	DefaultLegacyTokenBatchSize = 500
+	// DefaultLegacyTokenBatchPause is the wait between batches, which keeps
+	// the migration from competing with requests for the database.
+	DefaultLegacyTokenBatchPause = 100 * time.Millisecond
+)
+
+// LegacyTokenMigrationConfig configures a LegacyTokenMigration.
+type LegacyTokenMigrationConfig struct {
+	BatchSize  int
+	BatchPause time.Duration
+	// Cutoff is when plaintext tokens stop being accepted. Before it they
+	// are hashed, from then on deleted. Zero always hashes them.
+	Cutoff time.Time
+}
+
+// LegacyTokenMigrationProgress counts the tokens a migration run handled.
+type LegacyTokenMigrationProgress struct {
+	// Total is the number of plaintext tokens when the run started.
+	Total       int64
+	Hashed      int
+	Invalidated int
+}
+
+// LegacyTokenMigration moves refresh tokens stored in plaintext, from before
+// refresh tokens were hashed, to hashed storage. Token lookups accept both
+// until the cutoff (see TokenConfig.LegacyTokenCutoff), so sessions survive
+// the migration. Progress is kept in the tokens themselves: a run stopped
+// midway resumes with the tokens still in plaintext.
+type LegacyTokenMigration struct {
+	repo repository.TokenRepositoryInterface
+	cfg  LegacyTokenMigrationConfig
+	now  func() time.Time
+}
+
+// NewLegacyTokenMigration creates a migration. Zero config values use the
+// defaults.
+func NewLegacyTokenMigration(repo repository.TokenRepositoryInterface, cfg LegacyTokenMigrationConfig) *LegacyTokenMigration {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultLegacyTokenBatchSize
+	}
+	if cfg.BatchPause <= 0 {
+		cfg.BatchPause = DefaultLegacyTokenBatchPause
+	}
+	return &LegacyTokenMigration{repo: repo, cfg: cfg, now: timeutil.Now}
+}
+
+// Run migrates plaintext tokens batch by batch until none are left, ctx is
+// done or the database fails. Expired tokens are deleted rather than hashed.
+func (m *LegacyTokenMigration) Run(ctx context.Context) (LegacyTokenMigrationProgress, error) {
+	var progress LegacyTokenMigrationProgress
+	total, err := m.repo.CountLegacyRefreshTokens(ctx)
+	if err != nil {
+		return progress, err
+	}
+	progress.Total = total
+	metrics.SetLegacyRefreshTokensRemaining(total)
+	if total == 0 {
+		return progress, nil
+	}
+	log.Info().Int64("tokens", total).Time("cutoff", m.cfg.Cutoff).Msg("Migrating plaintext refresh tokens")
+
+	for {
+		tokens, err := m.repo.FindLegacyRefreshTokens(ctx, m.cfg.BatchSize)
+		if err != nil {
+			return progress, err
+		}
+
+		hashed, invalidated, err := m.migrateBatch(ctx, tokens)
+		progress.Hashed += hashed
+		progress.Invalidated += invalidated
+		metrics.RecordLegacyRefreshTokensMigrated("hashed", hashed)
+		metrics.RecordLegacyRefreshTokensMigrated("invalidated", invalidated)
+		remaining := max(total-int64(progress.Hashed+progress.Invalidated), 0)
+		metrics.SetLegacyRefreshTokensRemaining(remaining)
+		if err != nil {
+			return progress, err
+		}
+		if len(tokens) < m.cfg.BatchSize {
+			break
+		}
+		log.Info().Int("hashed", progress.Hashed).Int("invalidated", progress.Invalidated).
+			Int64("remaining", remaining).Msg("Plaintext refresh token migration in progress")
+
+		select {
+		case <-ctx.Done():
+			return progress, ctx.Err()
+		case <-time.After(m.cfg.BatchPause):
+		}
+	}
+
+	log.Info().Int("hashed", progress.Hashed).Int("invalidated", progress.Invalidated).
+		Msg("Plaintext refresh token migration finished")
+	return progress, nil
+}
+
+// migrateBatch hashes or deletes tokens, stopping at the first failure.
+func (m *LegacyTokenMigration) migrateBatch(ctx context.Context, tokens []*model.Token) (hashed, invalidated int, err error) {
+	now := m.now()
+	pastCutoff := !m.cfg.Cutoff.IsZero() && !now.Before(m.cfg.Cutoff)
+	for _, token := range tokens {
+		if pastCutoff || !now.Before(token.ExpiresAt) {
+			if err := m.repo.Delete(ctx, token.ID); err != nil {
+				return hashed, invalidated, err
+			}
+			invalidated++
+			continue
+		}
+
+		err := m.repo.UpdateToken(ctx, token.ID, model.HashToken(token.Token))
+		if errors.Is(err, repository.ErrTokenExists) {
+			// Already stored hashed: the plaintext copy is redundant
+			err = m.repo.Delete(ctx, token.ID)
+		}
+		if err != nil {
+			return hashed, invalidated, err
+		}
+		hashed++
+	}
+	return hashed, invalidated, nil
+}
@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// legacyTokens returns n plaintext refresh tokens expiring at expiresAt.
+func legacyTokens(n int, expiresAt time.Time) []*model.Token {
+	tokens := make([]*model.Token, n)
+	for i := range tokens {
+		tokens[i] = &model.Token{
+			ID:        primitive.NewObjectID(),
+			Token:     fmt.Sprintf("refresh-%d", i),
+			Type:      "refresh",
+			ExpiresAt: expiresAt,
+		}
+	}
+	return tokens
+}
+
+func TestLegacyTokenMigration_Run(t *testing.T) {
+	ctx := context.Background()
+	valid := time.Now().Add(time.Hour)
+
+	t.Run("hashes tokens in batches", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		tokens := legacyTokens(3, valid)
+		repo.EXPECT().CountLegacyRefreshTokens(mock.Anything).Return(3, nil)
+		repo.EXPECT().FindLegacyRefreshTokens(mock.Anything, 2).Return(tokens[:2], nil).Once()
+		repo.EXPECT().FindLegacyRefreshTokens(mock.Anything, 2).Return(tokens[2:], nil).Once()
+		for _, token := range tokens {
+			repo.EXPECT().UpdateToken(mock.Anything, token.ID, model.HashToken(token.Token)).Return(nil).Once()
+		}
+
+		migration := service.NewLegacyTokenMigration(repo, service.LegacyTokenMigrationConfig{BatchSize: 2, BatchPause: time.Millisecond})
+		progress, err := migration.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, service.LegacyTokenMigrationProgress{Total: 3, Hashed: 3}, progress)
+	})
+
+	t.Run("deletes expired tokens", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		expired := legacyTokens(1, time.Now().Add(-time.Minute))
+		repo.EXPECT().CountLegacyRefreshTokens(mock.Anything).Return(1, nil)
+		repo.EXPECT().FindLegacyRefreshTokens(mock.Anything, service.DefaultLegacyTokenBatchSize).Return(expired, nil).Once()
+		repo.EXPECT().Delete(mock.Anything, expired[0].ID).Return(nil).Once()
+
+		progress, err := service.NewLegacyTokenMigration(repo, service.LegacyTokenMigrationConfig{}).Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, progress.Invalidated)
+	})
+
+	t.Run("deletes every token past the cutoff", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		tokens := legacyTokens(2, valid)
+		repo.EXPECT().CountLegacyRefreshTokens(mock.Anything).Return(2, nil)
+		repo.EXPECT().FindLegacyRefreshTokens(mock.Anything, service.DefaultLegacyTokenBatchSize).Return(tokens, nil).Once()
+		repo.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil).Times(2)
+
+		migration := service.NewLegacyTokenMigration(repo, service.LegacyTokenMigrationConfig{Cutoff: time.Now().Add(-time.Hour)})
+		progress, err := migration.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, service.LegacyTokenMigrationProgress{Total: 2, Invalidated: 2}, progress)
+	})
+
+	t.Run("drops plaintext copies of hashed tokens", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		tokens := legacyTokens(1, valid)
+		repo.EXPECT().CountLegacyRefreshTokens(mock.Anything).Return(1, nil)
+		repo.EXPECT().FindLegacyRefreshTokens(mock.Anything, service.DefaultLegacyTokenBatchSize).Return(tokens, nil).Once()
+		repo.EXPECT().UpdateToken(mock.Anything, tokens[0].ID, mock.Anything).Return(repository.ErrTokenExists).Once()
+		repo.EXPECT().Delete(mock.Anything, tokens[0].ID).Return(nil).Once()
+
+		progress, err := service.NewLegacyTokenMigration(repo, service.LegacyTokenMigrationConfig{}).Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, progress.Hashed)
+	})
+
+	t.Run("stops at the first database error", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		tokens := legacyTokens(2, valid)
+		repo.EXPECT().CountLegacyRefreshTokens(mock.Anything).Return(2, nil)
+		repo.EXPECT().FindLegacyRefreshTokens(mock.Anything, service.DefaultLegacyTokenBatchSize).Return(tokens, nil).Once()
+		repo.EXPECT().UpdateToken(mock.Anything, tokens[0].ID, mock.Anything).Return(nil).Once()
+		repo.EXPECT().UpdateToken(mock.Anything, tokens[1].ID, mock.Anything).Return(errors.New("connection lost")).Once()
+
+		progress, err := service.NewLegacyTokenMigration(repo, service.LegacyTokenMigrationConfig{}).Run(ctx)
+		assert.Error(t, err)
+		assert.Equal(t, 1, progress.Hashed)
+	})
+
+	t.Run("nothing to migrate", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		repo.EXPECT().CountLegacyRefreshTokens(mock.Anything).Return(0, nil)
+
+		progress, err := service.NewLegacyTokenMigration(repo, service.LegacyTokenMigrationConfig{}).Run(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, progress.Total)
+	})
+}
+
+func TestTokenService_FindRefreshToken_Legacy(t *testing.T) {
+	ctx := context.Background()
+	stored := &model.Token{ID: primitive.NewObjectID(), Token: "plain", Type: "refresh"}
+
+	t.Run("falls back to plaintext before the cutoff", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		repo.EXPECT().FindByToken(mock.Anything, model.HashToken("plain")).Return(nil, nil).Once()
+		repo.EXPECT().FindByToken(mock.Anything, "plain").Return(stored, nil).Once()
+
+		svc := service.NewTokenService(repo, service.TokenConfig{LegacyTokenCutoff: time.Now().Add(time.Hour)})
+		token, err := svc.FindRefreshToken(ctx, "plain")
+		require.NoError(t, err)
+		assert.Equal(t, stored, token)
+	})
+
+	t.Run("only reads hashed tokens past the cutoff", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		repo.EXPECT().FindByToken(mock.Anything, model.HashToken("plain")).Return(nil, nil).Once()
+
+		svc := service.NewTokenService(repo, service.TokenConfig{LegacyTokenCutoff: time.Now().Add(-time.Hour)})
+		token, err := svc.FindRefreshToken(ctx, "plain")
+		require.NoError(t, err)
+		assert.Nil(t, token)
+	})
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// RedactedValue replaces the values of redacted log fields.
+const RedactedValue = "[REDACTED]"
+
+// LogRedactor masks sensitive fields of log entries before they are stored.
+// A field is redacted when its name is one of the configured names or ends
+// with one of them after an underscore, so "token" covers "refresh_token"
+// but not "token_version". Nested maps and lists are searched too, and the
+// user email of an entry is redacted when "email" or "user_email" is
+// configured.
+type LogRedactor struct {
+	fields []string
+}
+
+// NewLogRedactor creates a redactor of fields, or returns nil when there are
+// none. Names are matched case-insensitively.
+func NewLogRedactor(fields []string) *LogRedactor {
+	normalized := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			normalized = append(normalized, field)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+	return &LogRedactor{fields: normalized}
+}
+
+// Redact returns entry with its sensitive fields masked. entry itself is left
+// unchanged, since callers may share its fields.
+func (r *LogRedactor) Redact(entry *model.LogEntry) *model.LogEntry {
+	if r == nil || entry == nil {
+		return entry
+	}
+
+	redacted := *entry
+	if redacted.UserEmail != "" && r.sensitive("user_email") {
+		redacted.UserEmail = RedactedValue
+	}
+	if redacted.Fields != nil {
+		redacted.Fields = r.redactMap(redacted.Fields)
+	}
+	return &redacted
+}
+
+// sensitive reports whether the field name is redacted.
+func (r *LogRedactor) sensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, field := range r.fields {
+		if name == field || strings.HasSuffix(name, "_"+field) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LogRedactor) redactMap(fields map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if r.sensitive(key) {
+			redacted[key] = RedactedValue
+			continue
+		}
+		redacted[key] = r.redactValue(value)
+	}
+	return redacted
+}
+
+func (r *LogRedactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.redactMap(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+		return redacted
+	case []map[string]interface{}:
+		redacted := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactMap(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// RedactingLoggingService is a LoggingService redacting the entries it
+// stores. The other methods call the wrapped service directly.
+type RedactingLoggingService struct {
+	LoggingService
+	redactor *LogRedactor
+}
+
+// NewRedactingLoggingService returns inner redacting the entries it stores
+// with redactor, or inner itself when either is nil.
+func NewRedactingLoggingService(inner LoggingService, redactor *LogRedactor) LoggingService {
+	if inner == nil || redactor == nil {
+		return inner
+	}
+	return &RedactingLoggingService{LoggingService: inner, redactor: redactor}
+}
+
+// CreateLog stores entry with its sensitive fields masked.
+func (s *RedactingLoggingService) CreateLog(ctx context.Context, entry *model.LogEntry) error {
+	return s.LoggingService.CreateLog(ctx, s.redactor.Redact(entry))
+}
+
+// CreateLogs stores entries with their sensitive fields masked.
+func (s *RedactingLoggingService) CreateLogs(ctx context.Context, entries []*model.LogEntry) error {
+	redacted := make([]*model.LogEntry, len(entries))
+	for i, entry := range entries {
+		redacted[i] = s.redactor.Redact(entry)
+	}
+	return s.LoggingService.CreateLogs(ctx, redacted)
+}
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestLogRedactor_Redact(t *testing.T) {
+	redactor := service.NewLogRedactor([]string{"Password", " token ", "email", ""})
+	require.NotNil(t, redactor)
+
+	entry := &model.LogEntry{
+		Message:   "login",
+		UserID:    "u1",
+		UserEmail: "jane@example.com",
+		Fields: map[string]interface{}{
+			"password":         "hunter2",
+			"current_password": "hunter2",
+			"password_changed": true,
+			"refresh_token":    "abc",
+			"token_version":    3,
+			"email":            "jane@example.com",
+			"attempts":         2,
+			"target": map[string]interface{}{
+				"email": "john@example.com",
+				"role":  "admin",
+			},
+			"users": []interface{}{
+				map[string]interface{}{"email": "a@example.com", "id": "a"},
+				"plain",
+			},
+			"items": []map[string]interface{}{{"access_token": "x"}},
+		},
+	}
+
+	redacted := redactor.Redact(entry)
+
+	assert.Equal(t, service.RedactedValue, redacted.UserEmail)
+	assert.Equal(t, "u1", redacted.UserID)
+	assert.Equal(t, map[string]interface{}{
+		"password":         service.RedactedValue,
+		"current_password": service.RedactedValue,
+		"password_changed": true,
+		"refresh_token":    service.RedactedValue,
+		"token_version":    3,
+		"email":            service.RedactedValue,
+		"attempts":         2,
+		"target": map[string]interface{}{
+			"email": service.RedactedValue,
+			"role":  "admin",
+		},
+		"users": []interface{}{
+			map[string]interface{}{"email": service.RedactedValue, "id": "a"},
+			"plain",
+		},
+		"items": []map[string]interface{}{{"access_token": service.RedactedValue}},
+	}, redacted.Fields)
+
+	// The original entry is left unchanged
+	assert.Equal(t, "jane@example.com", entry.UserEmail)
+	assert.Equal(t, "hunter2", entry.Fields["password"])
+	assert.Equal(t, "john@example.com", entry.Fields["target"].(map[string]interface{})["email"])
+}
+
+func TestLogRedactor_KeepsEmailUnlessConfigured(t *testing.T) {
+	redactor := service.NewLogRedactor([]string{"password"})
+
+	redacted := redactor.Redact(&model.LogEntry{UserEmail: "jane@example.com"})
+	assert.Equal(t, "jane@example.com", redacted.UserEmail)
+	assert.Nil(t, redacted.Fields)
+}
+
+func TestNewLogRedactor_NoFields(t *testing.T) {
+	assert.Nil(t, service.NewLogRedactor(nil))
+	assert.Nil(t, service.NewLogRedactor([]string{" ", ""}))
+
+	// A nil redactor returns entries as they are
+	var redactor *service.LogRedactor
+	entry := &model.LogEntry{Fields: map[string]interface{}{"password": "x"}}
+	assert.Same(t, entry, redactor.Redact(entry))
+}
+
+func TestRedactingLoggingService(t *testing.T) {
+	ctx := context.Background()
+	inner := mocks.NewMockLoggingService(t)
+	redactor := service.NewLogRedactor([]string{"token"})
+	svc := service.NewRedactingLoggingService(inner, redactor)
+
+	inner.EXPECT().CreateLog(ctx, mock.MatchedBy(func(e *model.LogEntry) bool {
+		return e.Fields["token"] == service.RedactedValue
+	})).Return(nil).Once()
+	inner.EXPECT().CreateLogs(ctx, mock.MatchedBy(func(entries []*model.LogEntry) bool {
+		return len(entries) == 2 && entries[0].Fields["api_token"] == service.RedactedValue && entries[1].Fields == nil
+	})).Return(nil).Once()
+	inner.EXPECT().CountLogs(ctx, model.LogQueryOptions{}).Return(int64(3), nil).Once()
+
+	require.NoError(t, svc.CreateLog(ctx, &model.LogEntry{Fields: map[string]interface{}{"token": "secret"}}))
+	require.NoError(t, svc.CreateLogs(ctx, []*model.LogEntry{
+		{Fields: map[string]interface{}{"api_token": "secret"}},
+		{Message: "plain"},
+	}))
+	count, err := svc.CountLogs(ctx, model.LogQueryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// Without a redactor or a service nothing is wrapped
+	assert.Same(t, inner, service.NewRedactingLoggingService(inner, nil))
+	assert.Nil(t, service.NewRedactingLoggingService(nil, redactor))
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// logPurgeTimeout bounds one purge run.
+const logPurgeTimeout = 5 * time.Minute
+
+// LogRetentionJob purges the log entries a model.LogRetention no longer keeps.
+// Purging is idempotent, so every instance may run the job.
+type LogRetentionJob struct {
+	logging   LoggingService
+	retention model.LogRetention
+	interval  time.Duration
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewLogRetentionJob creates a job purging through logging every interval.
+// Call Start to run it.
+func NewLogRetentionJob(logging LoggingService, retention model.LogRetention, interval time.Duration) *LogRetentionJob {
+	return &LogRetentionJob{
+		logging:   logging,
+		retention: retention,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// Start runs the job now and then every interval until Stop is called.
+func (j *LogRetentionJob) Start() {
+	go func() {
+		defer close(j.doneCh)
+
+		j.runInBackground()
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				j.runInBackground()
+			case <-j.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the job and waits for a running purge to finish.
+func (j *LogRetentionJob) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stopCh)
+		<-j.doneCh
+	})
+}
+
+// Run purges the log entries now and returns how many it deleted.
+func (j *LogRetentionJob) Run(ctx context.Context) (int64, error) {
+	if j.logging == nil {
+		return 0, ErrRepositoryNotConfigured
+	}
+
+	deleted, err := j.logging.PurgeLogs(ctx, j.retention)
+	metrics.RecordLogPurge(err)
+	return deleted, err
+}
+
+func (j *LogRetentionJob) runInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), logPurgeTimeout)
+	defer cancel()
+
+	deleted, err := j.Run(ctx)
+	if err != nil {
+		log.Warn().Err(err).Int64("deleted", deleted).Msg("Failed to purge logs")
+		return
+	}
+	log.Debug().Int64("deleted", deleted).Msg("Purged logs")
+}
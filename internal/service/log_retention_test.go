@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestLogRetentionJob_Run(t *testing.T) {
+	retention := model.LogRetention{MaxAge: time.Hour, MaxEntries: 100}
+
+	t.Run("purges with the retention", func(t *testing.T) {
+		logging := mocks.NewMockLoggingService(t)
+		logging.EXPECT().PurgeLogs(mock.Anything, retention).Return(int64(3), nil).Once()
+
+		deleted, err := service.NewLogRetentionJob(logging, retention, time.Hour).Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+	})
+
+	t.Run("without logging service", func(t *testing.T) {
+		_, err := service.NewLogRetentionJob(nil, retention, time.Hour).Run(context.Background())
+		assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	})
+}
+
+func TestLogRetentionJob_StartRunsImmediately(t *testing.T) {
+	logging := mocks.NewMockLoggingService(t)
+	ran := make(chan struct{}, 1)
+	logging.EXPECT().PurgeLogs(mock.Anything, mock.Anything).
+		RunAndReturn(func(context.Context, model.LogRetention) (int64, error) {
+			ran <- struct{}{}
+			return 0, nil
+		}).Once()
+
+	job := service.NewLogRetentionJob(logging, model.LogRetention{MaxAge: time.Hour}, time.Hour)
+	job.Start()
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("purge did not run on start")
+	}
+	job.Stop()
+}
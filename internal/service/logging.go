@@ -2,13 +2,22 @@ package service
 
 import (
 	"context"
-	"time"
+	"slices"
 
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
 	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	// DefaultLogPageSize is the page size used when a log query sets no limit.
+	DefaultLogPageSize = 50
+	// MaxLogPageSize caps the page size of a log query.
+	MaxLogPageSize = 500
+)
+
 // LoggingService defines the interface for logging operations.
 // This interface can be mocked for testing using mockery.
 type LoggingService interface {
@@ -23,6 +32,19 @@ type LoggingService interface {
 
 	// CountLogs returns the count of log entries matching the query options.
 	CountLogs(ctx context.Context, opts model.LogQueryOptions) (int64, error)
+
+	// ExportLogs calls fn for every log entry matching the query options,
+	// oldest first, ignoring the page. It stops at the first error fn
+	// returns, and holds only one batch of entries in memory at a time.
+	ExportLogs(ctx context.Context, opts model.LogQueryOptions, fn func(model.LogEntry) error) error
+
+	// CalculationsSinceConfigVersion returns per-version calculation counts for
+	// pack size config versions >= sinceVersion.
+	CalculationsSinceConfigVersion(ctx context.Context, sinceVersion int) ([]model.ConfigVersionImpact, error)
+
+	// PurgeLogs deletes the log entries retention no longer keeps and
+	// returns how many it deleted.
+	PurgeLogs(ctx context.Context, retention model.LogRetention) (int64, error)
 }
 
 // LoggingServiceImpl implements the LoggingService interface.
@@ -60,18 +82,7 @@ func (s *LoggingServiceImpl) CreateLogs(ctx context.Context, entries []*model.Lo
 
 // QueryLogs retrieves log entries matching the query options.
 func (s *LoggingServiceImpl) QueryLogs(ctx context.Context, opts model.LogQueryOptions) ([]model.LogEntry, error) {
-	repoOpts := repository.LogQueryOptions{
-		RequestID: opts.RequestID,
-		Level:     opts.Level,
-		Method:    opts.Method,
-		Path:      opts.Path,
-		StartTime: opts.StartTime,
-		EndTime:   opts.EndTime,
-		Limit:     opts.Limit,
-		Skip:      opts.Skip,
-	}
-
-	docs, err := s.repo.Query(ctx, repoOpts)
+	docs, err := s.repo.Query(ctx, repositoryLogQuery(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -86,67 +97,155 @@ func (s *LoggingServiceImpl) QueryLogs(ctx context.Context, opts model.LogQueryO
 
 // CountLogs returns the count of log entries matching the query options.
 func (s *LoggingServiceImpl) CountLogs(ctx context.Context, opts model.LogQueryOptions) (int64, error) {
-	repoOpts := repository.LogQueryOptions{
-		RequestID: opts.RequestID,
-		Level:     opts.Level,
-		Method:    opts.Method,
-		Path:      opts.Path,
-		StartTime: opts.StartTime,
-		EndTime:   opts.EndTime,
-		Limit:     opts.Limit,
-		Skip:      opts.Skip,
-	}
-
-	return s.repo.Count(ctx, repoOpts)
+	return s.repo.Count(ctx, repositoryLogQuery(opts))
+}
+
+// ExportLogs calls fn for every log entry matching the query options.
+func (s *LoggingServiceImpl) ExportLogs(ctx context.Context, opts model.LogQueryOptions, fn func(model.LogEntry) error) error {
+	return s.repo.Each(ctx, repositoryLogQuery(opts), func(doc *repository.LogEntryDocument) error {
+		return fn(s.documentToModel(doc))
+	})
+}
+
+// repositoryLogQuery converts query options to their repository form.
+func repositoryLogQuery(opts model.LogQueryOptions) repository.LogQueryOptions {
+	return repository.LogQueryOptions{
+		RequestID:     opts.RequestID,
+		CorrelationID: opts.CorrelationID,
+		Level:         opts.Level,
+		Method:        opts.Method,
+		Path:          opts.Path,
+		User:          opts.User,
+		ActionType:    opts.ActionType,
+		AuditOnly:     opts.AuditOnly,
+		StartTime:     opts.StartTime,
+		EndTime:       opts.EndTime,
+		Limit:         opts.Limit,
+		Skip:          opts.Skip,
+	}
+}
+
+// CalculationsSinceConfigVersion returns per-version calculation counts for
+// pack size config versions >= sinceVersion.
+func (s *LoggingServiceImpl) CalculationsSinceConfigVersion(ctx context.Context, sinceVersion int) ([]model.ConfigVersionImpact, error) {
+	docs, err := s.repo.CalculationsByConfigVersion(ctx, sinceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	impacts := make([]model.ConfigVersionImpact, len(docs))
+	for i, doc := range docs {
+		impacts[i] = model.ConfigVersionImpact{
+			Version:      doc.Version,
+			Calculations: doc.Calculations,
+			FirstSeen:    doc.FirstSeen,
+			LastSeen:     doc.LastSeen,
+		}
+	}
+
+	return impacts, nil
+}
+
+// PurgeLogs deletes the log entries older than the retention of their level,
+// then the oldest entries beyond retention.MaxEntries. It stops at the first
+// failure, returning what was deleted until then.
+func (s *LoggingServiceImpl) PurgeLogs(ctx context.Context, retention model.LogRetention) (int64, error) {
+	now := timeutil.Now()
+	var total int64
+
+	levels := make([]string, 0, len(retention.LevelMaxAge))
+	for level := range retention.LevelMaxAge {
+		levels = append(levels, level)
+	}
+	slices.Sort(levels)
+	for _, level := range levels {
+		deleted, err := s.repo.DeleteLogs(ctx, repository.LogPurgeOptions{
+			Before: now.Add(-retention.LevelMaxAge[level]),
+			Levels: []string{level},
+		})
+		metrics.RecordLogsPurged("age", deleted)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+	}
+
+	if retention.MaxAge > 0 {
+		deleted, err := s.repo.DeleteLogs(ctx, repository.LogPurgeOptions{
+			Before:       now.Add(-retention.MaxAge),
+			ExceptLevels: levels,
+		})
+		metrics.RecordLogsPurged("age", deleted)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+	}
+
+	if retention.MaxEntries > 0 {
+		deleted, err := s.repo.TrimLogs(ctx, retention.MaxEntries)
+		metrics.RecordLogsPurged("max_entries", deleted)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
 }
 
 // modelToDocument converts a domain model to a repository document.
 func (s *LoggingServiceImpl) modelToDocument(entry *model.LogEntry) *repository.LogEntryDocument {
+	// Entries are written in the background, in no fixed order, so their
+	// IDs stay random rather than shifting the seeded idgen sequence
 	if entry.ID.IsZero() {
 		entry.ID = primitive.NewObjectID()
 	}
 	if entry.Timestamp.IsZero() {
-		entry.Timestamp = time.Now()
+		entry.Timestamp = timeutil.Now()
 	}
 
 	return &repository.LogEntryDocument{
-		ID:         entry.ID,
-		Timestamp:  entry.Timestamp,
-		Level:      entry.Level,
-		Message:    entry.Message,
-		RequestID:  entry.RequestID,
-		Method:     entry.Method,
-		Path:       entry.Path,
-		StatusCode: entry.StatusCode,
-		Duration:   entry.Duration,
-		IP:         entry.IP,
-		UserAgent:  entry.UserAgent,
-		Error:      entry.Error,
-		UserID:     entry.UserID,
-		UserEmail:  entry.UserEmail,
-		ActionType: entry.ActionType,
-		Fields:     entry.Fields,
+		ID:            entry.ID,
+		Timestamp:     entry.Timestamp,
+		Level:         entry.Level,
+		Message:       entry.Message,
+		RequestID:     entry.RequestID,
+		CorrelationID: entry.CorrelationID,
+		TraceID:       entry.TraceID,
+		Method:        entry.Method,
+		Path:          entry.Path,
+		StatusCode:    entry.StatusCode,
+		Duration:      entry.Duration,
+		IP:            entry.IP,
+		UserAgent:     entry.UserAgent,
+		Error:         entry.Error,
+		UserID:        entry.UserID,
+		UserEmail:     entry.UserEmail,
+		ActionType:    entry.ActionType,
+		Fields:        entry.Fields,
 	}
 }
 
 // documentToModel converts a repository document to a domain model.
 func (s *LoggingServiceImpl) documentToModel(doc *repository.LogEntryDocument) model.LogEntry {
 	return model.LogEntry{
-		ID:         doc.ID,
-		Timestamp:  doc.Timestamp,
-		Level:      doc.Level,
-		Message:    doc.Message,
-		RequestID:  doc.RequestID,
-		Method:     doc.Method,
-		Path:       doc.Path,
-		StatusCode: doc.StatusCode,
-		Duration:   doc.Duration,
-		IP:         doc.IP,
-		UserAgent:  doc.UserAgent,
-		Error:      doc.Error,
-		UserID:     doc.UserID,
-		UserEmail:  doc.UserEmail,
-		ActionType: doc.ActionType,
-		Fields:     doc.Fields,
+		ID:            doc.ID,
+		Timestamp:     doc.Timestamp,
+		Level:         doc.Level,
+		Message:       doc.Message,
+		RequestID:     doc.RequestID,
+		CorrelationID: doc.CorrelationID,
+		TraceID:       doc.TraceID,
+		Method:        doc.Method,
+		Path:          doc.Path,
+		StatusCode:    doc.StatusCode,
+		Duration:      doc.Duration,
+		IP:            doc.IP,
+		UserAgent:     doc.UserAgent,
+		Error:         doc.Error,
+		UserID:        doc.UserID,
+		UserEmail:     doc.UserEmail,
+		ActionType:    doc.ActionType,
+		Fields:        doc.Fields,
 	}
 }
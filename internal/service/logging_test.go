@@ -12,6 +12,7 @@ import (
 	"github.com/guttosm/pack-service/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -44,6 +45,36 @@ func (m *MockLogsRepository) Count(ctx context.Context, opts repository.LogQuery
 	return count, args.Error(1)
 }
 
+func (m *MockLogsRepository) Each(ctx context.Context, opts repository.LogQueryOptions, fn func(*repository.LogEntryDocument) error) error {
+	args := m.Called(ctx, opts)
+	docs, _ := args.Get(0).([]*repository.LogEntryDocument)
+	for _, doc := range docs {
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockLogsRepository) CalculationsByConfigVersion(ctx context.Context, sinceVersion int) ([]repository.ConfigVersionImpactDocument, error) {
+	args := m.Called(ctx, sinceVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	docs, _ := args.Get(0).([]repository.ConfigVersionImpactDocument)
+	return docs, args.Error(1)
+}
+
+func (m *MockLogsRepository) DeleteLogs(ctx context.Context, opts repository.LogPurgeOptions) (int64, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockLogsRepository) TrimLogs(ctx context.Context, maxEntries int64) (int64, error) {
+	args := m.Called(ctx, maxEntries)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestNewLoggingService(t *testing.T) {
 	mockRepo := new(MockLogsRepository)
 	service := NewLoggingService(mockRepo)
@@ -304,6 +335,23 @@ func TestLoggingService_CountLogs(t *testing.T) {
 			wantCount: 5,
 			wantError: false,
 		},
+		{
+			name: "count with user and path filters",
+			opts: model.LogQueryOptions{
+				User:   "ops@example.com",
+				Method: "POST",
+				Path:   "/api/calculate",
+			},
+			setupMock: func(m *MockLogsRepository) {
+				m.On("Count", mock.Anything, repository.LogQueryOptions{
+					User:   "ops@example.com",
+					Method: "POST",
+					Path:   "/api/calculate",
+				}).Return(int64(2), nil)
+			},
+			wantCount: 2,
+			wantError: false,
+		},
 		{
 			name: "count error",
 			opts: model.LogQueryOptions{},
@@ -334,6 +382,100 @@ func TestLoggingService_CountLogs(t *testing.T) {
 	}
 }
 
+func TestLoggingService_ExportLogs(t *testing.T) {
+	docs := []*repository.LogEntryDocument{
+		{ID: primitive.NewObjectID(), ActionType: "login", UserEmail: "ops@example.com"},
+		{ID: primitive.NewObjectID(), ActionType: "logout", UserEmail: "ops@example.com"},
+	}
+
+	t.Run("passes every entry to fn", func(t *testing.T) {
+		mockRepo := new(MockLogsRepository)
+		mockRepo.On("Each", mock.Anything, repository.LogQueryOptions{AuditOnly: true, ActionType: "login"}).Return(docs, nil)
+		service := NewLoggingService(mockRepo)
+
+		var actions []string
+		err := service.ExportLogs(context.Background(), model.LogQueryOptions{AuditOnly: true, ActionType: "login"}, func(entry model.LogEntry) error {
+			actions = append(actions, entry.ActionType)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"login", "logout"}, actions)
+	})
+
+	t.Run("stops at the first error of fn", func(t *testing.T) {
+		mockRepo := new(MockLogsRepository)
+		mockRepo.On("Each", mock.Anything, mock.Anything).Return(docs, nil)
+		service := NewLoggingService(mockRepo)
+
+		stop := errors.New("client gone")
+		calls := 0
+		err := service.ExportLogs(context.Background(), model.LogQueryOptions{}, func(model.LogEntry) error {
+			calls++
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestLoggingService_CalculationsSinceConfigVersion(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		setupMock   func(*MockLogsRepository)
+		wantImpacts []model.ConfigVersionImpact
+		wantError   bool
+	}{
+		{
+			name: "converts aggregation rows",
+			setupMock: func(m *MockLogsRepository) {
+				m.On("CalculationsByConfigVersion", mock.Anything, 2).Return([]repository.ConfigVersionImpactDocument{
+					{Version: 2, Calculations: 7, FirstSeen: now, LastSeen: now},
+					{Version: 3, Calculations: 1, FirstSeen: now, LastSeen: now},
+				}, nil)
+			},
+			wantImpacts: []model.ConfigVersionImpact{
+				{Version: 2, Calculations: 7, FirstSeen: now, LastSeen: now},
+				{Version: 3, Calculations: 1, FirstSeen: now, LastSeen: now},
+			},
+		},
+		{
+			name: "no calculations",
+			setupMock: func(m *MockLogsRepository) {
+				m.On("CalculationsByConfigVersion", mock.Anything, 2).Return([]repository.ConfigVersionImpactDocument{}, nil)
+			},
+			wantImpacts: []model.ConfigVersionImpact{},
+		},
+		{
+			name: "repository error",
+			setupMock: func(m *MockLogsRepository) {
+				m.On("CalculationsByConfigVersion", mock.Anything, 2).Return(nil, errors.New("database error"))
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockLogsRepository)
+			tt.setupMock(mockRepo)
+			service := NewLoggingService(mockRepo)
+
+			impacts, err := service.CalculationsSinceConfigVersion(context.Background(), 2)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantImpacts, impacts)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestLoggingService_modelToDocument(t *testing.T) {
 	service := &LoggingServiceImpl{}
 
@@ -445,3 +587,57 @@ func TestLoggingService_documentToModel(t *testing.T) {
 	assert.Equal(t, doc.ActionType, entry.ActionType)
 	assert.Equal(t, doc.Fields, entry.Fields)
 }
+
+func TestLoggingService_PurgeLogs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("purges each level by its retention, then caps the entries", func(t *testing.T) {
+		mockRepo := new(MockLogsRepository)
+		service := NewLoggingService(mockRepo)
+		retention := model.LogRetention{
+			MaxAge:      7 * 24 * time.Hour,
+			LevelMaxAge: map[string]time.Duration{"error": 90 * 24 * time.Hour, "debug": time.Hour},
+			MaxEntries:  1000,
+		}
+
+		olderThan := func(age time.Duration) func(time.Time) bool {
+			return func(before time.Time) bool {
+				return before.Sub(time.Now().Add(-age)).Abs() < time.Minute
+			}
+		}
+		mockRepo.On("DeleteLogs", ctx, mock.MatchedBy(func(opts repository.LogPurgeOptions) bool {
+			return assert.ObjectsAreEqual([]string{"debug"}, opts.Levels) && olderThan(time.Hour)(opts.Before)
+		})).Return(int64(4), nil).Once()
+		mockRepo.On("DeleteLogs", ctx, mock.MatchedBy(func(opts repository.LogPurgeOptions) bool {
+			return assert.ObjectsAreEqual([]string{"error"}, opts.Levels) && olderThan(90*24*time.Hour)(opts.Before)
+		})).Return(int64(1), nil).Once()
+		mockRepo.On("DeleteLogs", ctx, mock.MatchedBy(func(opts repository.LogPurgeOptions) bool {
+			return len(opts.Levels) == 0 && assert.ObjectsAreEqual([]string{"debug", "error"}, opts.ExceptLevels) &&
+				olderThan(7*24*time.Hour)(opts.Before)
+		})).Return(int64(10), nil).Once()
+		mockRepo.On("TrimLogs", ctx, int64(1000)).Return(int64(5), nil).Once()
+
+		deleted, err := service.PurgeLogs(ctx, retention)
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), deleted)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps everything without a retention", func(t *testing.T) {
+		mockRepo := new(MockLogsRepository)
+		deleted, err := NewLoggingService(mockRepo).PurgeLogs(ctx, model.LogRetention{})
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+		mockRepo.AssertNotCalled(t, "DeleteLogs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		mockRepo := new(MockLogsRepository)
+		mockRepo.On("DeleteLogs", ctx, mock.Anything).Return(int64(2), errors.New("db down")).Once()
+
+		deleted, err := NewLoggingService(mockRepo).PurgeLogs(ctx, model.LogRetention{MaxAge: time.Hour, MaxEntries: 10})
+		assert.Error(t, err)
+		assert.Equal(t, int64(2), deleted)
+		mockRepo.AssertNotCalled(t, "TrimLogs", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/correlation"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// DefaultLoginAnomalyWindow is how long failed logins are counted, and how
+// recent a login from another country counts as a concurrent session, when
+// no window is configured.
+const DefaultLoginAnomalyWindow = time.Hour
+
+// loginAnomalyReportTimeout bounds the audit entry and alert of a login.
+const loginAnomalyReportTimeout = 10 * time.Second
+
+// Login anomaly types, also used as the login_anomalies_total label.
+const (
+	// LoginAnomalyNewIP is a login from an IP none of the recent logins used.
+	LoginAnomalyNewIP = "new_ip"
+	// LoginAnomalyNewCountry is a login from a country none of the recent
+	// logins came from.
+	LoginAnomalyNewCountry = "new_country"
+	// LoginAnomalyFailuresBeforeSuccess is a login after many failed ones,
+	// as when a password was guessed.
+	LoginAnomalyFailuresBeforeSuccess = "failures_before_success"
+	// LoginAnomalyDistantSession is a login from another country than one
+	// made shortly before, whose session may still be in use.
+	LoginAnomalyDistantSession = "distant_session"
+)
+
+// countryCode matches an ISO 3166-1 alpha-2 code.
+var countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type clientCountryKey struct{}
+
+// ContextWithClientCountry returns a copy of ctx carrying the country the
+// edge resolved the client IP of a login to. Codes that are not ISO 3166-1
+// alpha-2, and XX for unknown, are ignored.
+func ContextWithClientCountry(ctx context.Context, country string) context.Context {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if !countryCode.MatchString(country) || country == "XX" {
+		return ctx
+	}
+	return context.WithValue(ctx, clientCountryKey{}, country)
+}
+
+// ClientCountryFromContext returns the country stored by
+// ContextWithClientCountry, or "" when it is unknown.
+func ClientCountryFromContext(ctx context.Context) string {
+	country, _ := ctx.Value(clientCountryKey{}).(string)
+	return country
+}
+
+// LoginAnomalyConfig configures a LoginAnomalyDetector.
+type LoginAnomalyConfig struct {
+	// FailureThreshold is how many failed logins within Window make the
+	// next successful one suspicious; zero disables the check.
+	FailureThreshold int
+	// Window is how long failed logins are counted, and how recent a login
+	// from another country makes a new one a distant session.
+	Window time.Duration
+}
+
+// LoginAnomalyDetector flags suspicious successful logins: from a new IP or
+// country, after many failed attempts, or from another country than a
+// login shortly before. Each one is stored in the audit log with the
+// login_anomaly action type and sent to the notifier, so the security team
+// is alerted. Failed logins are counted in the repository, so every replica
+// sees them; repository errors never block a login.
+type LoginAnomalyDetector struct {
+	attempts repository.LoginAttemptRepositoryInterface
+	logging  LoggingService
+	notifier notify.Notifier
+	cfg      LoginAnomalyConfig
+}
+
+// NewLoginAnomalyDetector creates a detector counting failed logins in
+// attempts. logging and notifier may be nil.
+func NewLoginAnomalyDetector(attempts repository.LoginAttemptRepositoryInterface, logging LoggingService, notifier notify.Notifier, cfg LoginAnomalyConfig) *LoginAnomalyDetector {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultLoginAnomalyWindow
+	}
+	return &LoginAnomalyDetector{attempts: attempts, logging: logging, notifier: notifier, cfg: cfg}
+}
+
+// failuresKey is what the failed logins of email are counted under, apart
+// from the lockout's counts, which a lock resets.
+func failuresKey(email string) string {
+	return "anomaly:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// RecordFailure counts a failed login for email.
+func (d *LoginAnomalyDetector) RecordFailure(ctx context.Context, email string) {
+	if d.cfg.FailureThreshold <= 0 || email == "" {
+		return
+	}
+	if _, err := d.attempts.RecordFailure(ctx, failuresKey(email), d.cfg.Window); err != nil {
+		log.Warn().Err(err).Msg("Failed to count failed login for anomaly detection")
+	}
+}
+
+// RecordSuccess checks the successful login current of user, whose earlier
+// logins are previous, most recent first, and forgets the failed logins of
+// email. The anomalies found are audited and alerted in the background and
+// returned.
+func (d *LoginAnomalyDetector) RecordSuccess(ctx context.Context, email string, user *model.User, current model.LoginRecord, previous []model.LoginRecord) []string {
+	failures := 0
+	if d.cfg.FailureThreshold > 0 && email != "" {
+		key := failuresKey(email)
+		attempt, err := d.attempts.Get(ctx, key)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to read failed logins for anomaly detection")
+		}
+		if attempt != nil {
+			failures = attempt.Failures
+			if err := d.attempts.Reset(ctx, key); err != nil {
+				log.Warn().Err(err).Msg("Failed to reset failed logins for anomaly detection")
+			}
+		}
+	}
+
+	anomalies := d.Detect(current, previous, failures)
+	if len(anomalies) == 0 {
+		return nil
+	}
+	for _, anomaly := range anomalies {
+		metrics.RecordLoginAnomaly(anomaly)
+	}
+
+	// The login does not wait for the audit log or the alert channels
+	ids := correlation.FromContext(ctx)
+	go d.report(ids, user, current, previous, failures, anomalies)
+	return anomalies
+}
+
+// Detect returns the anomalies of the login current, given the earlier
+// logins of the user, most recent first, and the failed logins since the
+// last successful one. A user's first recorded login has no location to
+// compare with, and logins without a country are only compared by IP.
+func (d *LoginAnomalyDetector) Detect(current model.LoginRecord, previous []model.LoginRecord, failures int) []string {
+	var anomalies []string
+	if len(previous) > 0 {
+		knownIP, knownCountry, anyCountry := false, false, false
+		for _, login := range previous {
+			knownIP = knownIP || login.IP == current.IP
+			knownCountry = knownCountry || login.Country == current.Country
+			anyCountry = anyCountry || login.Country != ""
+		}
+		if current.IP != "" && !knownIP {
+			anomalies = append(anomalies, LoginAnomalyNewIP)
+		}
+		if current.Country != "" && anyCountry && !knownCountry {
+			anomalies = append(anomalies, LoginAnomalyNewCountry)
+		}
+		if last := lastLocatedLogin(previous); last != nil && current.Country != "" &&
+			last.Country != current.Country && current.At.Sub(last.At) < d.cfg.Window {
+			anomalies = append(anomalies, LoginAnomalyDistantSession)
+		}
+	}
+	if d.cfg.FailureThreshold > 0 && failures >= d.cfg.FailureThreshold {
+		anomalies = append(anomalies, LoginAnomalyFailuresBeforeSuccess)
+	}
+	return anomalies
+}
+
+// lastLocatedLogin returns the most recent of logins with a country.
+func lastLocatedLogin(logins []model.LoginRecord) *model.LoginRecord {
+	for i := range logins {
+		if logins[i].Country != "" {
+			return &logins[i]
+		}
+	}
+	return nil
+}
+
+// report stores the audit entry of a suspicious login and alerts on it.
+func (d *LoginAnomalyDetector) report(ids correlation.IDs, user *model.User, current model.LoginRecord, previous []model.LoginRecord, failures int, anomalies []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), loginAnomalyReportTimeout)
+	defer cancel()
+
+	fields := map[string]interface{}{
+		"anomalies": anomalies,
+		"failures":  failures,
+	}
+	if current.Country != "" {
+		fields["country"] = current.Country
+	}
+	if len(previous) > 0 {
+		fields["previous_ip"] = previous[0].IP
+		if previous[0].Country != "" {
+			fields["previous_country"] = previous[0].Country
+		}
+	}
+	log.Warn().Str("user_id", user.ID.Hex()).Str("ip", current.IP).Strs("anomalies", anomalies).Msg("Suspicious login")
+
+	if d.logging != nil {
+		entry := &model.LogEntry{
+			Timestamp:     timeutil.Now(),
+			Level:         "warn",
+			Message:       "Suspicious login",
+			RequestID:     ids.RequestID,
+			CorrelationID: ids.CorrelationID,
+			TraceID:       ids.TraceID,
+			IP:            current.IP,
+			UserID:        user.ID.Hex(),
+			UserEmail:     user.Email,
+			ActionType:    model.ActionLoginAnomaly,
+			Fields:        fields,
+		}
+		if err := d.logging.CreateLog(ctx, entry); err != nil {
+			log.Warn().Err(err).Msg("Failed to audit suspicious login")
+		}
+	}
+
+	if d.notifier != nil {
+		alertFields := map[string]interface{}{"user_id": user.ID.Hex(), "user_email": user.Email, "ip": current.IP}
+		for k, v := range fields {
+			alertFields[k] = v
+		}
+		err := d.notifier.Notify(ctx, notify.Notification{
+			Title:     "Suspicious login",
+			Message:   fmt.Sprintf("Suspicious login for %s from %s: %s", user.Email, current.IP, strings.Join(anomalies, ", ")),
+			Severity:  notify.SeverityWarning,
+			Source:    "login_anomaly",
+			Fields:    alertFields,
+			Timestamp: timeutil.Now(),
+		})
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to alert on suspicious login")
+		}
+	}
+}
+
+// anomalyAuthService runs a LoginAnomalyDetector on an AuthService's logins.
+type anomalyAuthService struct {
+	AuthService
+	detector *LoginAnomalyDetector
+}
+
+// NewAnomalyAuthService returns auth with its logins checked by detector.
+// The client IP and country are read from the context (see
+// ContextWithClientIP and ContextWithClientCountry). A nil detector returns
+// auth unchanged.
+func NewAnomalyAuthService(auth AuthService, detector *LoginAnomalyDetector) AuthService {
+	if detector == nil {
+		return auth
+	}
+	return &anomalyAuthService{AuthService: auth, detector: detector}
+}
+
+// Login implements AuthService.
+func (s *anomalyAuthService) Login(ctx context.Context, email, password string) (*dto.TokenPair, *model.User, error) {
+	start := timeutil.Now()
+	tokens, user, err := s.AuthService.Login(ctx, email, password)
+	if err != nil {
+		var lockedErr *AccountLockedError
+		if errors.Is(err, ErrInvalidCredentials) || (errors.As(err, &lockedErr) && lockedErr.NewlyLocked) {
+			s.detector.RecordFailure(ctx, email)
+		}
+		return nil, nil, err
+	}
+
+	current := model.LoginRecord{At: timeutil.Now(), IP: ClientIPFromContext(ctx), Country: ClientCountryFromContext(ctx)}
+	previous := user.RecentLogins
+	// Login records this login first, unless that failed
+	if len(previous) > 0 && !previous[0].At.Before(start) {
+		current = previous[0]
+		previous = previous[1:]
+	}
+	s.detector.RecordSuccess(ctx, email, user, current, previous)
+	return tokens, user, nil
+}
@@ -0,0 +1,194 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+var anomalyConfig = service.LoginAnomalyConfig{FailureThreshold: 5, Window: time.Hour}
+
+func TestClientCountryFromContext(t *testing.T) {
+	assert.Empty(t, service.ClientCountryFromContext(context.Background()))
+	assert.Equal(t, "PT", service.ClientCountryFromContext(service.ContextWithClientCountry(context.Background(), " pt ")))
+	assert.Empty(t, service.ClientCountryFromContext(service.ContextWithClientCountry(context.Background(), "XX")), "unknown")
+	assert.Empty(t, service.ClientCountryFromContext(service.ContextWithClientCountry(context.Background(), "Portugal")))
+}
+
+func TestLoginAnomalyDetector_Detect(t *testing.T) {
+	now := time.Now()
+	detector := service.NewLoginAnomalyDetector(nil, nil, nil, anomalyConfig)
+	usual := []model.LoginRecord{
+		{At: now.Add(-48 * time.Hour), IP: "203.0.113.7", Country: "PT"},
+		{At: now.Add(-72 * time.Hour), IP: "203.0.113.8", Country: "PT"},
+	}
+
+	tests := []struct {
+		name     string
+		current  model.LoginRecord
+		previous []model.LoginRecord
+		failures int
+		want     []string
+	}{
+		{
+			name:     "usual login",
+			current:  model.LoginRecord{At: now, IP: "203.0.113.8", Country: "PT"},
+			previous: usual,
+		},
+		{
+			name:    "first login",
+			current: model.LoginRecord{At: now, IP: "198.51.100.1", Country: "BR"},
+		},
+		{
+			name:     "new IP",
+			current:  model.LoginRecord{At: now, IP: "203.0.113.9", Country: "PT"},
+			previous: usual,
+			want:     []string{service.LoginAnomalyNewIP},
+		},
+		{
+			name:     "new country",
+			current:  model.LoginRecord{At: now, IP: "198.51.100.1", Country: "BR"},
+			previous: usual,
+			want:     []string{service.LoginAnomalyNewIP, service.LoginAnomalyNewCountry},
+		},
+		{
+			name:     "country unknown before",
+			current:  model.LoginRecord{At: now, IP: "203.0.113.7", Country: "BR"},
+			previous: []model.LoginRecord{{At: now.Add(-time.Minute), IP: "203.0.113.7"}},
+		},
+		{
+			name:    "another country shortly before",
+			current: model.LoginRecord{At: now, IP: "198.51.100.1", Country: "BR"},
+			previous: []model.LoginRecord{
+				{At: now.Add(-10 * time.Minute), IP: "198.51.100.1", Country: "PT"},
+				{At: now.Add(-48 * time.Hour), IP: "198.51.100.2", Country: "BR"},
+			},
+			want: []string{service.LoginAnomalyDistantSession},
+		},
+		{
+			name:     "failures before success",
+			current:  model.LoginRecord{At: now, IP: "203.0.113.7", Country: "PT"},
+			previous: usual,
+			failures: 5,
+			want:     []string{service.LoginAnomalyFailuresBeforeSuccess},
+		},
+		{
+			name:     "failures below the threshold",
+			current:  model.LoginRecord{At: now, IP: "203.0.113.7", Country: "PT"},
+			previous: usual,
+			failures: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detector.Detect(tt.current, tt.previous, tt.failures))
+		})
+	}
+}
+
+func TestAnomalyAuthService_Login(t *testing.T) {
+	user := &model.User{ID: primitive.NewObjectID(), Email: "jane@example.com"}
+
+	t.Run("audits and alerts on a suspicious login", func(t *testing.T) {
+		user := *user
+		user.RecentLogins = []model.LoginRecord{{At: time.Now().Add(-24 * time.Hour), IP: "203.0.113.7", Country: "PT"}}
+
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, "anomaly:jane@example.com").Return(&model.LoginAttempt{Failures: 6}, nil)
+		repo.EXPECT().Reset(mock.Anything, "anomaly:jane@example.com").Return(nil)
+
+		inner := mocks.NewMockAuthService(t)
+		inner.EXPECT().Login(mock.Anything, "Jane@example.com", "secret").
+			RunAndReturn(func(ctx context.Context, _, _ string) (*dto.TokenPair, *model.User, error) {
+				user.AddLogin(model.LoginRecord{At: time.Now(), IP: service.ClientIPFromContext(ctx), Country: service.ClientCountryFromContext(ctx)})
+				return &dto.TokenPair{AccessToken: "token"}, &user, nil
+			})
+
+		audited := make(chan *model.LogEntry, 1)
+		logging := mocks.NewMockLoggingService(t)
+		logging.EXPECT().CreateLog(mock.Anything, mock.Anything).
+			Run(func(_ context.Context, entry *model.LogEntry) { audited <- entry }).Return(nil)
+		alerted := make(chan notify.Notification, 1)
+		notifier := mocks.NewMockNotifier(t)
+		notifier.EXPECT().Notify(mock.Anything, mock.Anything).
+			Run(func(_ context.Context, n notify.Notification) { alerted <- n }).Return(nil)
+
+		auth := service.NewAnomalyAuthService(inner, service.NewLoginAnomalyDetector(repo, logging, notifier, anomalyConfig))
+		ctx := service.ContextWithClientCountry(service.ContextWithClientIP(context.Background(), "198.51.100.1"), "BR")
+		tokens, _, err := auth.Login(ctx, "Jane@example.com", "secret")
+		require.NoError(t, err)
+		assert.Equal(t, "token", tokens.AccessToken)
+
+		entry := receive(t, audited)
+		assert.Equal(t, model.ActionLoginAnomaly, entry.ActionType)
+		assert.Equal(t, "warn", entry.Level)
+		assert.Equal(t, "198.51.100.1", entry.IP)
+		assert.Equal(t, user.ID.Hex(), entry.UserID)
+		assert.Equal(t, []string{service.LoginAnomalyNewIP, service.LoginAnomalyNewCountry, service.LoginAnomalyFailuresBeforeSuccess}, entry.Fields["anomalies"])
+		assert.Equal(t, "BR", entry.Fields["country"])
+		assert.Equal(t, "203.0.113.7", entry.Fields["previous_ip"])
+		assert.Equal(t, 6, entry.Fields["failures"])
+
+		n := receive(t, alerted)
+		assert.Equal(t, notify.SeverityWarning, n.Severity)
+		assert.Equal(t, "login_anomaly", n.Source)
+		assert.Equal(t, "jane@example.com", n.Fields["user_email"])
+	})
+
+	t.Run("does not report a usual login", func(t *testing.T) {
+		user := *user
+		user.RecentLogins = []model.LoginRecord{{At: time.Now().Add(-24 * time.Hour), IP: "203.0.113.7"}}
+
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, nil)
+		inner := mocks.NewMockAuthService(t)
+		inner.EXPECT().Login(mock.Anything, mock.Anything, mock.Anything).Return(&dto.TokenPair{}, &user, nil)
+
+		auth := service.NewAnomalyAuthService(inner, service.NewLoginAnomalyDetector(repo, mocks.NewMockLoggingService(t), mocks.NewMockNotifier(t), anomalyConfig))
+		_, _, err := auth.Login(loginContext(), "jane@example.com", "secret")
+		require.NoError(t, err)
+	})
+
+	t.Run("counts failed logins", func(t *testing.T) {
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().RecordFailure(mock.Anything, "anomaly:jane@example.com", time.Hour).Return(&model.LoginAttempt{Failures: 1}, nil).Twice()
+		inner := mocks.NewMockAuthService(t)
+		inner.EXPECT().Login(mock.Anything, mock.Anything, "wrong").Return(nil, nil, service.ErrInvalidCredentials).Once()
+		inner.EXPECT().Login(mock.Anything, mock.Anything, "locking").Return(nil, nil, &service.AccountLockedError{NewlyLocked: true}).Once()
+		inner.EXPECT().Login(mock.Anything, mock.Anything, "locked").Return(nil, nil, &service.AccountLockedError{}).Once()
+
+		auth := service.NewAnomalyAuthService(inner, service.NewLoginAnomalyDetector(repo, nil, nil, anomalyConfig))
+		for _, password := range []string{"wrong", "locking", "locked"} {
+			_, _, err := auth.Login(loginContext(), "jane@example.com", password)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("nil detector", func(t *testing.T) {
+		inner := mocks.NewMockAuthService(t)
+		assert.Same(t, inner, service.NewAnomalyAuthService(inner, nil))
+	})
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out")
+		var zero T
+		return zero
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// DefaultLockoutDuration is how long a lockout lasts when no duration is configured.
+const DefaultLockoutDuration = 15 * time.Minute
+
+// Lockout scopes, also used as the login_lockouts_total label.
+const (
+	LockoutScopeAccount = "account"
+	LockoutScopeIP      = "ip"
+)
+
+// ErrAccountLocked is returned by Login while the account or client IP is
+// locked out after too many failed logins. The error is an
+// *AccountLockedError carrying when to retry.
+var ErrAccountLocked = domainerr.New(dto.ErrCodeAccountLocked, http.StatusLocked, i18n.ErrKeyAccountLocked, "too many failed logins, try again later")
+
+// AccountLockedError reports a lockout and how long it has left.
+type AccountLockedError struct {
+	// Scope is LockoutScopeAccount or LockoutScopeIP.
+	Scope      string
+	RetryAfter time.Duration
+	// NewlyLocked is true when this login attempt caused the lockout.
+	NewlyLocked bool
+}
+
+func (e *AccountLockedError) Error() string {
+	return ErrAccountLocked.Error()
+}
+
+// Is makes errors.Is(err, ErrAccountLocked) match.
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// Unwrap returns ErrAccountLocked, for its code and status.
+func (e *AccountLockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
+// LockoutConfig configures a LoginLockout.
+type LockoutConfig struct {
+	// Threshold is how many consecutive failed logins lock an account; zero disables it.
+	Threshold int
+	// IPThreshold is how many consecutive failed logins from one IP, across
+	// accounts, lock that IP out; zero disables it.
+	IPThreshold int
+	// Duration is how long a lockout lasts and how long failures are remembered.
+	Duration time.Duration
+}
+
+type clientIPKey struct{}
+
+// ContextWithClientIP returns a copy of ctx carrying the client IP of a login,
+// used for IP lockouts.
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP stored by ContextWithClientIP.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// LoginLockout locks accounts and client IPs out after repeated failed
+// logins. State is kept in the repository so every replica enforces it.
+// Repository errors never block a login: lockout fails open and logs.
+type LoginLockout struct {
+	repo repository.LoginAttemptRepositoryInterface
+	cfg  LockoutConfig
+	now  func() time.Time
+}
+
+// NewLoginLockout creates a LoginLockout. It returns nil when both
+// thresholds are zero.
+func NewLoginLockout(repo repository.LoginAttemptRepositoryInterface, cfg LockoutConfig) *LoginLockout {
+	if cfg.Threshold <= 0 && cfg.IPThreshold <= 0 {
+		return nil
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = DefaultLockoutDuration
+	}
+	return &LoginLockout{repo: repo, cfg: cfg, now: timeutil.Now}
+}
+
+// lockoutKey is a tracked account or IP with its threshold.
+type lockoutKey struct {
+	scope     string
+	key       string
+	threshold int
+}
+
+// keys returns what a login for email from ip is tracked under.
+func (l *LoginLockout) keys(email, ip string) []lockoutKey {
+	keys := make([]lockoutKey, 0, 2)
+	if l.cfg.Threshold > 0 && email != "" {
+		keys = append(keys, lockoutKey{LockoutScopeAccount, "account:" + strings.ToLower(strings.TrimSpace(email)), l.cfg.Threshold})
+	}
+	if l.cfg.IPThreshold > 0 && ip != "" {
+		keys = append(keys, lockoutKey{LockoutScopeIP, "ip:" + ip, l.cfg.IPThreshold})
+	}
+	return keys
+}
+
+// Check returns an *AccountLockedError when the account or IP is locked out.
+func (l *LoginLockout) Check(ctx context.Context, email, ip string) error {
+	now := l.now()
+	for _, k := range l.keys(email, ip) {
+		attempt, err := l.repo.Get(ctx, k.key)
+		if err != nil {
+			log.Warn().Err(err).Str("scope", k.scope).Msg("Failed to read login attempts, skipping lockout check")
+			continue
+		}
+		if attempt.IsLocked(now) {
+			return &AccountLockedError{Scope: k.scope, RetryAfter: attempt.LockedUntil.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login and locks the account or IP once it
+// reaches its threshold, returning an *AccountLockedError in that case.
+func (l *LoginLockout) RecordFailure(ctx context.Context, email, ip string) error {
+	var locked *AccountLockedError
+	for _, k := range l.keys(email, ip) {
+		attempt, err := l.repo.RecordFailure(ctx, k.key, l.cfg.Duration)
+		if err != nil {
+			log.Warn().Err(err).Str("scope", k.scope).Msg("Failed to record failed login")
+			continue
+		}
+		if attempt.Failures < k.threshold {
+			continue
+		}
+
+		if err := l.repo.Lock(ctx, k.key, l.now().Add(l.cfg.Duration)); err != nil {
+			log.Warn().Err(err).Str("scope", k.scope).Msg("Failed to lock out after failed logins")
+			continue
+		}
+		metrics.RecordLoginLockout(k.scope)
+		if locked == nil {
+			locked = &AccountLockedError{Scope: k.scope, RetryAfter: l.cfg.Duration, NewlyLocked: true}
+		}
+	}
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// RecordSuccess clears the account's failures after a successful login.
+// The IP's failures are kept, so one valid account cannot be used to reset
+// the count while guessing passwords for others.
+func (l *LoginLockout) RecordSuccess(ctx context.Context, email string) {
+	for _, k := range l.keys(email, "") {
+		if err := l.repo.Reset(ctx, k.key); err != nil {
+			log.Warn().Err(err).Str("scope", k.scope).Msg("Failed to reset failed logins")
+		}
+	}
+}
+
+// lockoutAuthService enforces a LoginLockout around an AuthService's Login.
+type lockoutAuthService struct {
+	AuthService
+	lockout *LoginLockout
+}
+
+// NewLockoutAuthService returns auth with brute-force protection: Login
+// fails with ErrAccountLocked while the account or the client IP (see
+// ContextWithClientIP) is locked out, without checking the password. A nil
+// lockout returns auth unchanged.
+func NewLockoutAuthService(auth AuthService, lockout *LoginLockout) AuthService {
+	if lockout == nil {
+		return auth
+	}
+	return &lockoutAuthService{AuthService: auth, lockout: lockout}
+}
+
+// Login implements AuthService.
+func (s *lockoutAuthService) Login(ctx context.Context, email, password string) (*dto.TokenPair, *model.User, error) {
+	ip := ClientIPFromContext(ctx)
+	if err := s.lockout.Check(ctx, email, ip); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, user, err := s.AuthService.Login(ctx, email, password)
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		if lockErr := s.lockout.RecordFailure(ctx, email, ip); lockErr != nil {
+			return nil, nil, lockErr
+		}
+		return nil, nil, err
+	case err != nil:
+		return nil, nil, err
+	}
+
+	s.lockout.RecordSuccess(ctx, email)
+	return tokens, user, nil
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+var lockoutConfig = service.LockoutConfig{Threshold: 3, IPThreshold: 10, Duration: 15 * time.Minute}
+
+func loginContext() context.Context {
+	return service.ContextWithClientIP(context.Background(), "203.0.113.7")
+}
+
+func TestNewLoginLockout_Disabled(t *testing.T) {
+	assert.Nil(t, service.NewLoginLockout(mocks.NewMockLoginAttemptRepositoryInterface(t), service.LockoutConfig{}))
+
+	auth := mocks.NewMockAuthService(t)
+	assert.Same(t, auth, service.NewLockoutAuthService(auth, nil))
+}
+
+func TestLockoutAuthService_Login(t *testing.T) {
+	t.Run("rejects a locked account without checking the password", func(t *testing.T) {
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, "account:jane@example.com").
+			Return(&model.LoginAttempt{LockedUntil: time.Now().Add(10 * time.Minute)}, nil)
+
+		auth := service.NewLockoutAuthService(mocks.NewMockAuthService(t), service.NewLoginLockout(repo, lockoutConfig))
+		_, _, err := auth.Login(loginContext(), "Jane@example.com", "secret")
+
+		require.ErrorIs(t, err, service.ErrAccountLocked)
+		var lockedErr *service.AccountLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.Equal(t, service.LockoutScopeAccount, lockedErr.Scope)
+		assert.InDelta(t, 10*time.Minute, lockedErr.RetryAfter, float64(time.Second))
+		assert.False(t, lockedErr.NewlyLocked)
+	})
+
+	t.Run("rejects a locked IP", func(t *testing.T) {
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, "account:jane@example.com").Return(nil, nil)
+		repo.EXPECT().Get(mock.Anything, "ip:203.0.113.7").
+			Return(&model.LoginAttempt{LockedUntil: time.Now().Add(time.Minute)}, nil)
+
+		auth := service.NewLockoutAuthService(mocks.NewMockAuthService(t), service.NewLoginLockout(repo, lockoutConfig))
+		_, _, err := auth.Login(loginContext(), "jane@example.com", "secret")
+
+		var lockedErr *service.AccountLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.Equal(t, service.LockoutScopeIP, lockedErr.Scope)
+	})
+
+	t.Run("counts failures and locks at the threshold", func(t *testing.T) {
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, nil)
+		repo.EXPECT().RecordFailure(mock.Anything, "account:jane@example.com", 15*time.Minute).
+			Return(&model.LoginAttempt{Failures: 3}, nil)
+		repo.EXPECT().RecordFailure(mock.Anything, "ip:203.0.113.7", 15*time.Minute).
+			Return(&model.LoginAttempt{Failures: 3}, nil)
+		repo.EXPECT().Lock(mock.Anything, "account:jane@example.com", mock.Anything).Return(nil)
+
+		inner := mocks.NewMockAuthService(t)
+		inner.EXPECT().Login(mock.Anything, "jane@example.com", "wrong").Return(nil, nil, service.ErrInvalidCredentials)
+
+		auth := service.NewLockoutAuthService(inner, service.NewLoginLockout(repo, lockoutConfig))
+		_, _, err := auth.Login(loginContext(), "jane@example.com", "wrong")
+
+		var lockedErr *service.AccountLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.True(t, lockedErr.NewlyLocked)
+		assert.Equal(t, 15*time.Minute, lockedErr.RetryAfter)
+	})
+
+	t.Run("returns invalid credentials below the threshold", func(t *testing.T) {
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, nil)
+		repo.EXPECT().RecordFailure(mock.Anything, mock.Anything, mock.Anything).Return(&model.LoginAttempt{Failures: 1}, nil)
+
+		inner := mocks.NewMockAuthService(t)
+		inner.EXPECT().Login(mock.Anything, "jane@example.com", "wrong").Return(nil, nil, service.ErrInvalidCredentials)
+
+		auth := service.NewLockoutAuthService(inner, service.NewLoginLockout(repo, lockoutConfig))
+		_, _, err := auth.Login(loginContext(), "jane@example.com", "wrong")
+		assert.Equal(t, service.ErrInvalidCredentials, err)
+	})
+
+	t.Run("resets the account after a successful login", func(t *testing.T) {
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, mock.Anything).Return(&model.LoginAttempt{Failures: 2}, nil)
+		repo.EXPECT().Reset(mock.Anything, "account:jane@example.com").Return(nil)
+
+		inner := mocks.NewMockAuthService(t)
+		inner.EXPECT().Login(mock.Anything, "jane@example.com", "secret").
+			Return(&dto.TokenPair{AccessToken: "access"}, &model.User{Email: "jane@example.com"}, nil)
+
+		auth := service.NewLockoutAuthService(inner, service.NewLoginLockout(repo, lockoutConfig))
+		tokens, _, err := auth.Login(loginContext(), "jane@example.com", "secret")
+		require.NoError(t, err)
+		assert.Equal(t, "access", tokens.AccessToken)
+	})
+
+	t.Run("fails open when the repository is unavailable", func(t *testing.T) {
+		repo := mocks.NewMockLoginAttemptRepositoryInterface(t)
+		repo.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+		repo.EXPECT().RecordFailure(mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+		inner := mocks.NewMockAuthService(t)
+		inner.EXPECT().Login(mock.Anything, "jane@example.com", "wrong").Return(nil, nil, service.ErrInvalidCredentials)
+
+		auth := service.NewLockoutAuthService(inner, service.NewLoginLockout(repo, lockoutConfig))
+		_, _, err := auth.Login(loginContext(), "jane@example.com", "wrong")
+		assert.Equal(t, service.ErrInvalidCredentials, err)
+	})
+}
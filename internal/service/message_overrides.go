@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+const (
+	// maxMessageOverrideTenantLength bounds stored tenant IDs.
+	maxMessageOverrideTenantLength = 64
+	// messageOverrideReloadTimeout bounds a background reload.
+	messageOverrideReloadTimeout = 5 * time.Second
+)
+
+var (
+	// ErrInvalidMessageOverride is returned when an override fails validation.
+	ErrInvalidMessageOverride = domainerr.New("invalid_message_override", http.StatusBadRequest, "", "invalid message override")
+	// ErrMessageOverrideNotFound is returned when a tenant has no override
+	// of the message.
+	ErrMessageOverrideNotFound = domainerr.New("message_override_not_found", http.StatusNotFound, i18n.ErrKeyNotFound, "message override not found")
+)
+
+// MessageOverrideService manages the catalog messages tenants replaced,
+// such as branded wording for rate limit errors, and keeps the translator's
+// copy of them current.
+type MessageOverrideService interface {
+	// List returns the overrides of tenant, or of every tenant when tenant is empty.
+	List(ctx context.Context, tenant string) ([]*model.MessageOverride, error)
+	// Set validates and stores override, replacing the tenant's override of
+	// the same message and locale.
+	Set(ctx context.Context, override *model.MessageOverride) error
+	Delete(ctx context.Context, tenant, locale, key string) error
+	// Reload loads the stored overrides into the translator.
+	Reload(ctx context.Context) error
+}
+
+// MessageOverrideServiceImpl implements MessageOverrideService.
+type MessageOverrideServiceImpl struct {
+	repo       repository.MessageOverrideRepositoryInterface
+	translator *i18n.Translator
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewMessageOverrideService creates a service storing overrides in repo and
+// applying them to translator.
+func NewMessageOverrideService(repo repository.MessageOverrideRepositoryInterface, translator *i18n.Translator) *MessageOverrideServiceImpl {
+	return &MessageOverrideServiceImpl{
+		repo:       repo,
+		translator: translator,
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+// List returns the overrides of tenant, or of every tenant when tenant is empty.
+func (s *MessageOverrideServiceImpl) List(ctx context.Context, tenant string) ([]*model.MessageOverride, error) {
+	if s.repo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	overrides, err := s.repo.List(ctx)
+	if err != nil || tenant == "" {
+		return overrides, err
+	}
+	filtered := make([]*model.MessageOverride, 0)
+	for _, override := range overrides {
+		if override.Tenant == tenant {
+			filtered = append(filtered, override)
+		}
+	}
+	return filtered, nil
+}
+
+// Set validates and stores override, and applies it on this instance at
+// once. Other instances apply it on their next reload.
+func (s *MessageOverrideServiceImpl) Set(ctx context.Context, override *model.MessageOverride) error {
+	if s.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+	if override.Tenant == "" || len(override.Tenant) > maxMessageOverrideTenantLength {
+		return fmt.Errorf("%w: tenant must be 1 to %d characters", ErrInvalidMessageOverride, maxMessageOverrideTenantLength)
+	}
+	if err := s.translator.ValidateOverride(override.Key, override.Locale, override.Message); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMessageOverride, err)
+	}
+	if err := s.repo.Upsert(ctx, override); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// Delete removes a tenant's override, restoring the catalog message.
+func (s *MessageOverrideServiceImpl) Delete(ctx context.Context, tenant, locale, key string) error {
+	if s.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+	deleted, err := s.repo.Delete(ctx, tenant, locale, key)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrMessageOverrideNotFound
+	}
+	return s.Reload(ctx)
+}
+
+// Reload loads the stored overrides into the translator. Overrides the
+// catalog no longer accepts, such as those of a removed message, are
+// skipped.
+func (s *MessageOverrideServiceImpl) Reload(ctx context.Context) error {
+	if s.repo == nil {
+		return ErrRepositoryNotConfigured
+	}
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	overrides := make(i18n.Overrides)
+	for _, override := range stored {
+		if err := s.translator.ValidateOverride(override.Key, override.Locale, override.Message); err != nil {
+			log.Warn().Err(err).Str("tenant", override.Tenant).Str("locale", override.Locale).Str("key", override.Key).
+				Msg("Skipping invalid message override")
+			continue
+		}
+		if overrides[override.Tenant] == nil {
+			overrides[override.Tenant] = make(map[string]map[string]string)
+		}
+		if overrides[override.Tenant][override.Locale] == nil {
+			overrides[override.Tenant][override.Locale] = make(map[string]string)
+		}
+		overrides[override.Tenant][override.Locale][override.Key] = override.Message
+	}
+	s.translator.SetOverrides(overrides)
+	return nil
+}
+
+// Start reloads the overrides every interval until Stop is called, so
+// changes made through other instances apply here too.
+func (s *MessageOverrideServiceImpl) Start(interval time.Duration) {
+	go func() {
+		defer close(s.doneCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.reloadInBackground()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reloading started by Start and waits for a running reload.
+func (s *MessageOverrideServiceImpl) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.doneCh
+	})
+}
+
+func (s *MessageOverrideServiceImpl) reloadInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), messageOverrideReloadTimeout)
+	defer cancel()
+
+	if err := s.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to reload message overrides")
+	}
+}
@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestMessageOverrideService_Set(t *testing.T) {
+	branded := &model.MessageOverride{Tenant: "acme", Locale: "en", Key: i18n.ErrKeyRateLimitExceeded, Message: "Your Acme plan allows fewer requests"}
+
+	t.Run("stores and applies a valid override", func(t *testing.T) {
+		repo := mocks.NewMockMessageOverrideRepositoryInterface(t)
+		repo.EXPECT().Upsert(mock.Anything, branded).Return(nil).Once()
+		repo.EXPECT().List(mock.Anything).Return([]*model.MessageOverride{branded}, nil).Once()
+		translator := i18n.NewTranslator()
+
+		require.NoError(t, service.NewMessageOverrideService(repo, translator).Set(context.Background(), branded))
+		assert.Equal(t, branded.Message, translator.TranslateFor("acme", i18n.ErrKeyRateLimitExceeded, "en"))
+	})
+
+	tests := []struct {
+		name     string
+		override *model.MessageOverride
+		wantErr  error
+	}{
+		{name: "missing tenant", override: &model.MessageOverride{Locale: "en", Key: i18n.ErrKeyForbidden, Message: "No"}},
+		{name: "unknown key", override: &model.MessageOverride{Tenant: "acme", Locale: "en", Key: "error.unknown", Message: "No"}, wantErr: i18n.ErrUnknownMessageKey},
+		{name: "unsupported locale", override: &model.MessageOverride{Tenant: "acme", Locale: "fr", Key: i18n.ErrKeyForbidden, Message: "Non"}, wantErr: i18n.ErrUnsupportedLocale},
+		{name: "placeholder mismatch", override: &model.MessageOverride{Tenant: "acme", Locale: "en", Key: i18n.ErrKeyValidationItemsOrderedMax, Message: "Too large"}, wantErr: i18n.ErrInvalidMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockMessageOverrideRepositoryInterface(t)
+
+			err := service.NewMessageOverrideService(repo, i18n.NewTranslator()).Set(context.Background(), tt.override)
+			assert.ErrorIs(t, err, service.ErrInvalidMessageOverride)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageOverrideService_Delete(t *testing.T) {
+	repo := mocks.NewMockMessageOverrideRepositoryInterface(t)
+	repo.EXPECT().Delete(mock.Anything, "acme", "en", i18n.ErrKeyForbidden).Return(true, nil).Once()
+	repo.EXPECT().List(mock.Anything).Return([]*model.MessageOverride{}, nil).Once()
+	repo.EXPECT().Delete(mock.Anything, "acme", "en", i18n.ErrKeyForbidden).Return(false, nil).Once()
+	translator := i18n.NewTranslator()
+	translator.SetOverrides(i18n.Overrides{"acme": {"en": {i18n.ErrKeyForbidden: "Acme says no"}}})
+	svc := service.NewMessageOverrideService(repo, translator)
+
+	require.NoError(t, svc.Delete(context.Background(), "acme", "en", i18n.ErrKeyForbidden))
+	assert.Equal(t, "Forbidden", translator.TranslateFor("acme", i18n.ErrKeyForbidden, "en"))
+	assert.ErrorIs(t, svc.Delete(context.Background(), "acme", "en", i18n.ErrKeyForbidden), service.ErrMessageOverrideNotFound)
+}
+
+func TestMessageOverrideService_Reload(t *testing.T) {
+	repo := mocks.NewMockMessageOverrideRepositoryInterface(t)
+	repo.EXPECT().List(mock.Anything).Return([]*model.MessageOverride{
+		{Tenant: "acme", Locale: "pt", Key: i18n.ErrKeyForbidden, Message: "A Acme diz não"},
+		{Tenant: "acme", Locale: "en", Key: "error.removed", Message: "Stale"},
+		{Tenant: "globex", Locale: "en", Key: i18n.ErrKeyForbidden, Message: "Globex says no"},
+	}, nil).Once()
+	repo.EXPECT().List(mock.Anything).Return(nil, errors.New("db down")).Once()
+	translator := i18n.NewTranslator()
+	svc := service.NewMessageOverrideService(repo, translator)
+
+	require.NoError(t, svc.Reload(context.Background()))
+	assert.Equal(t, "A Acme diz não", translator.TranslateFor("acme", i18n.ErrKeyForbidden, "pt"))
+	assert.Equal(t, "Globex says no", translator.TranslateFor("globex", i18n.ErrKeyForbidden, "en"))
+	assert.Equal(t, "error.removed", translator.TranslateFor("acme", "error.removed", "en"), "invalid stored overrides are skipped")
+
+	assert.Error(t, svc.Reload(context.Background()))
+	assert.Equal(t, "Globex says no", translator.TranslateFor("globex", i18n.ErrKeyForbidden, "en"), "a failed reload keeps the overrides")
+}
+
+func TestMessageOverrideService_List(t *testing.T) {
+	repo := mocks.NewMockMessageOverrideRepositoryInterface(t)
+	repo.EXPECT().List(mock.Anything).Return([]*model.MessageOverride{
+		{Tenant: "acme", Locale: "en", Key: i18n.ErrKeyForbidden, Message: "Acme says no"},
+		{Tenant: "globex", Locale: "en", Key: i18n.ErrKeyForbidden, Message: "Globex says no"},
+	}, nil).Twice()
+	svc := service.NewMessageOverrideService(repo, i18n.NewTranslator())
+
+	all, err := svc.List(context.Background(), "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	acme, err := svc.List(context.Background(), "acme")
+	require.NoError(t, err)
+	require.Len(t, acme, 1)
+	assert.Equal(t, "Acme says no", acme[0].Message)
+}
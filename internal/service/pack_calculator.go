@@ -1,19 +1,36 @@
 package service
 
 import (
+	"context"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/service/cache"
 )
 
 var (
 	// DefaultPackSizes defines the standard pack sizes available for orders.
 	DefaultPackSizes = []int{5000, 2000, 1000, 500, 250}
+
+	// ErrConstraintsUnsatisfiable is returned when no pack combination
+	// satisfies the requested constraints.
+	ErrConstraintsUnsatisfiable = domainerr.Unprocessable.Derive(i18n.ErrKeyConstraintsUnsatisfiable, "no pack combination satisfies the constraints")
+
+	// ErrOrderTooLarge is returned when an order is above the calculator's
+	// maximum items ordered.
+	ErrOrderTooLarge = domainerr.Unprocessable.Derive("", "order exceeds the maximum items ordered")
 )
 
+// deadlineCheckInterval is how many DP steps run between compute deadline checks.
+const deadlineCheckInterval = 4096
+
 // dpState holds the dynamic programming arrays for reuse via sync.Pool.
 // This significantly reduces allocations for high-volume traffic.
 type dpState struct {
@@ -75,6 +92,22 @@ func putDPState(state *dpState) {
 type PackCalculator interface {
 	Calculate(itemsOrdered int) model.PackResult
 	CalculateWithPackSizes(itemsOrdered int, packSizes []int) model.PackResult
+	// CalculateWithConstraints calculates the best combination that satisfies
+	// constraints, using the configured pack sizes when packSizes is empty.
+	// It returns ErrConstraintsUnsatisfiable when there is none, and
+	// ErrMissingPackCost when optimizing for cost with a size without one.
+	CalculateWithConstraints(itemsOrdered int, packSizes []int, constraints model.PackConstraints) (model.PackResult, error)
+	// CalculateWithin is CalculateWithConstraints with a compute time limit:
+	// once budget has elapsed it returns a greedy result marked Approximate.
+	CalculateWithin(itemsOrdered int, packSizes []int, constraints model.PackConstraints, budget time.Duration) (model.PackResult, error)
+	// CalculateGreedy returns the greedy combination for the order, using the
+	// configured pack sizes when packSizes is empty. It is fast for any order
+	// size but may ship more items or packs than Calculate.
+	CalculateGreedy(itemsOrdered int, packSizes []int) model.PackResult
+	// Explain tells why result, calculated with packSizes (the configured
+	// ones when empty) within constraints, was chosen over other
+	// combinations. It returns nil for empty results.
+	Explain(result model.PackResult, packSizes []int, constraints model.PackConstraints) *model.Explanation
 	// InvalidateCache clears the calculation cache (useful when pack sizes change)
 	InvalidateCache()
 }
@@ -88,13 +121,28 @@ type Option func(*PackCalculatorService)
 type PackCalculatorService struct {
 	packSizes    []int
 	smallestPack int
-	cache        cache.Cache
+	// packSizesHash identifies packSizes in cache keys
+	packSizesHash cache.PackSizesDigest
+	cache         cache.Cache
+	redisConfig   *cache.RedisConfig
+	compressor    *cache.Compressor
+	eviction      string
+	maxItems      int
+	// boundedSearchThreshold is the order size from which largeOrderResult
+	// replaces the table search; zero disables it.
+	boundedSearchThreshold int
+	// maxTableTotals caps the table search of the orders largeOrderResult
+	// cannot solve; zero leaves it uncapped.
+	maxTableTotals int
 }
 
 // NewPackCalculatorService creates a new PackCalculatorService with the given options.
 func NewPackCalculatorService(opts ...Option) *PackCalculatorService {
 	s := &PackCalculatorService{
-		packSizes: make([]int, len(DefaultPackSizes)),
+		packSizes:              make([]int, len(DefaultPackSizes)),
+		maxItems:               dto.DefaultMaxItemsOrdered,
+		boundedSearchThreshold: DefaultBoundedSearchThreshold,
+		maxTableTotals:         DefaultMaxTableTotals,
 	}
 	copy(s.packSizes, DefaultPackSizes)
 
@@ -103,9 +151,35 @@ func NewPackCalculatorService(opts ...Option) *PackCalculatorService {
 	}
 
 	s.smallestPack = s.packSizes[len(s.packSizes)-1]
+	s.packSizesHash = cache.PackSizesHash(s.packSizes)
+
+	// The Redis cache is built last so its keys can be namespaced by the final pack sizes
+	if s.redisConfig != nil {
+		cfg := *s.redisConfig
+		cfg.KeyPrefix = redisKeyPrefix(cfg.KeyPrefix, s.packSizes)
+		if s.compressor != nil {
+			cfg.Compressor = s.compressor
+		}
+		s.cache = cache.NewRedisCache(cfg)
+	}
+	if c, ok := s.cache.(interface{ setCompressor(*cache.Compressor) }); ok && s.compressor != nil {
+		c.setCompressor(s.compressor)
+	}
+	if c, ok := s.cache.(interface{ setEvictionPolicy(string) }); ok && s.eviction != "" {
+		c.setEvictionPolicy(s.eviction)
+	}
 	return s
 }
 
+// PingCache checks that the result cache is reachable. It always succeeds for
+// the in-memory cache or when caching is disabled.
+func (s *PackCalculatorService) PingCache(ctx context.Context) error {
+	if pinger, ok := s.cache.(interface{ Ping(context.Context) error }); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
 // WithPackSizes sets custom pack sizes for the calculator.
 func WithPackSizes(sizes []int) Option {
 	return func(s *PackCalculatorService) {
@@ -122,6 +196,7 @@ func WithCache(capacity int, ttl time.Duration) Option {
 	return func(s *PackCalculatorService) {
 		if capacity > 0 {
 			s.cache = newTTLCache(capacity, ttl)
+			s.redisConfig = nil
 		}
 	}
 }
@@ -130,33 +205,109 @@ func WithCache(capacity int, ttl time.Duration) Option {
 func WithCacheInterface(c cache.Cache) Option {
 	return func(s *PackCalculatorService) {
 		s.cache = c
+		s.redisConfig = nil
 	}
 }
 
-// Calculate determines the optimal packs needed for the given order.
-func (s *PackCalculatorService) Calculate(itemsOrdered int) model.PackResult {
-	if itemsOrdered <= 0 {
-		return model.Empty(itemsOrdered)
+// WithRedisCache stores results in Redis so they survive restarts and are
+// shared between replicas. Keys are namespaced by pack sizes, so replicas with
+// different PACK_SIZES never read each other's results.
+func WithRedisCache(cfg cache.RedisConfig) Option {
+	return func(s *PackCalculatorService) {
+		s.cache = nil
+		s.redisConfig = &cfg
 	}
+}
 
-	if s.cache != nil {
-		if result, ok := s.cache.Get(itemsOrdered); ok {
-			return result
+// WithCompression compresses cached results whose encoded size reaches the
+// compressor's threshold. It applies to the built-in memory and Redis caches
+// regardless of option order.
+func WithCompression(compressor *cache.Compressor) Option {
+	return func(s *PackCalculatorService) {
+		s.compressor = compressor
+	}
+}
+
+// WithCacheEviction selects the eviction policy of the built-in memory cache,
+// CacheEvictionLRU (the default) or CacheEvictionLFU, regardless of option
+// order. Redis evicts by its own policy, so it does not apply there.
+func WithCacheEviction(policy string) Option {
+	return func(s *PackCalculatorService) {
+		s.eviction = policy
+	}
+}
+
+// WithMaxItemsOrdered sets the largest order calculated. The DP arrays grow
+// with the order, so larger ones are refused rather than risk running out of
+// memory: CalculateWithConstraints and CalculateWithin return
+// ErrOrderTooLarge and the other methods an empty result. Zero or less keeps
+// dto.DefaultMaxItemsOrdered.
+func WithMaxItemsOrdered(maxItems int) Option {
+	return func(s *PackCalculatorService) {
+		if maxItems > 0 {
+			s.maxItems = maxItems
 		}
 	}
+}
 
-	result := s.calculateCore(itemsOrdered, s.packSizes, s.smallestPack)
+// WithBoundedSearchThreshold sets the order size from which the calculator
+// searches with memory proportional to the largest pack size instead of to
+// the order. Orders the bounded search cannot solve exactly are searched
+// with the table when it fits WithMaxTableTotals, and otherwise get a greedy
+// result marked Approximate. Zero or less disables it, so every order is
+// searched exactly in memory proportional to its size.
+func WithBoundedSearchThreshold(threshold int) Option {
+	return func(s *PackCalculatorService) {
+		s.boundedSearchThreshold = max(threshold, 0)
+	}
+}
 
-	if s.cache != nil {
-		s.cache.Set(itemsOrdered, result)
+// WithMaxTableTotals sets the largest table of totals the exact search
+// allocates for orders the bounded search cannot solve exactly; larger ones
+// get the greedy result. Zero or less removes the cap.
+func WithMaxTableTotals(totals int) Option {
+	return func(s *PackCalculatorService) {
+		s.maxTableTotals = max(totals, 0)
 	}
+}
 
-	return result
+// MaxItemsOrdered returns the largest order the calculator accepts.
+func (s *PackCalculatorService) MaxItemsOrdered() int {
+	return s.maxItems
+}
+
+// MaxItemsOrdered returns the largest order calculator accepts, or
+// dto.DefaultMaxItemsOrdered when it does not report one.
+func MaxItemsOrdered(calculator PackCalculator) int {
+	if c, ok := calculator.(interface{ MaxItemsOrdered() int }); ok {
+		return c.MaxItemsOrdered()
+	}
+	return dto.DefaultMaxItemsOrdered
+}
+
+// redisKeyPrefix appends the pack sizes to prefix, e.g. "pack-service:calc:500-250".
+func redisKeyPrefix(prefix string, packSizes []int) string {
+	if prefix == "" {
+		prefix = cache.DefaultRedisKeyPrefix
+	}
+	sizes := make([]string, len(packSizes))
+	for i, size := range packSizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+	return prefix + ":" + strings.Join(sizes, "-")
+}
+
+// Calculate determines the optimal packs needed for the given order.
+func (s *PackCalculatorService) Calculate(itemsOrdered int) model.PackResult {
+	if itemsOrdered <= 0 || itemsOrdered > s.maxItems {
+		return model.Empty(itemsOrdered)
+	}
+	return s.calculateCached(itemsOrdered, s.packSizes, s.smallestPack)
 }
 
 // CalculateWithPackSizes calculates packs using custom pack sizes provided in the request.
 func (s *PackCalculatorService) CalculateWithPackSizes(itemsOrdered int, packSizes []int) model.PackResult {
-	if itemsOrdered <= 0 {
+	if itemsOrdered <= 0 || itemsOrdered > s.maxItems {
 		return model.Empty(itemsOrdered)
 	}
 
@@ -169,22 +320,181 @@ func (s *PackCalculatorService) CalculateWithPackSizes(itemsOrdered int, packSiz
 	sort.Sort(sort.Reverse(sort.IntSlice(tempSizes)))
 
 	smallestPack := tempSizes[len(tempSizes)-1]
-	return s.calculateCore(itemsOrdered, tempSizes, smallestPack)
+	return s.calculateCached(itemsOrdered, tempSizes, smallestPack)
+}
+
+// calculateCached returns the cached result for itemsOrdered with packSizes,
+// sorted descending, or calculates and caches it.
+func (s *PackCalculatorService) calculateCached(itemsOrdered int, packSizes []int, smallestPack int) model.PackResult {
+	key := s.cacheKey(itemsOrdered, packSizes)
+	if s.cache != nil {
+		if result, ok := s.cache.Get(key); ok {
+			result.CacheHit = true
+			return result
+		}
+	}
+
+	result := s.calculateCore(itemsOrdered, packSizes, smallestPack, model.PackConstraints{}, time.Time{})
+
+	if s.cache != nil {
+		s.cache.Set(key, result)
+	}
+
+	return result
+}
+
+// cacheKey returns the cache key of the result for itemsOrdered with
+// packSizes, the configured ones when empty. Results calculated with other
+// pack sizes have other keys, so they never answer for each other.
+func (s *PackCalculatorService) cacheKey(itemsOrdered int, packSizes []int) cache.Key {
+	if len(packSizes) == 0 {
+		return cache.Key{Items: itemsOrdered, PackSizes: s.packSizesHash}
+	}
+	return cache.NewKey(itemsOrdered, packSizes)
+}
+
+// CalculateWithConstraints calculates packs that satisfy constraints. Among
+// the acceptable combinations it still ships the fewest items, then uses the
+// fewest packs, unless constraints optimize for cost. Results are priced
+// when the pack sizes have costs. Constrained results are not cached.
+func (s *PackCalculatorService) CalculateWithConstraints(itemsOrdered int, packSizes []int, constraints model.PackConstraints) (model.PackResult, error) {
+	if itemsOrdered <= 0 {
+		return model.Empty(itemsOrdered), nil
+	}
+	if itemsOrdered > s.maxItems {
+		return model.Empty(itemsOrdered), ErrOrderTooLarge
+	}
+	if constraints.IsZero() {
+		return s.CalculateWithPackSizes(itemsOrdered, packSizes), nil
+	}
+	if constraints.MaxPacks != nil && *constraints.MaxPacks < 1 {
+		return model.Empty(itemsOrdered), ErrConstraintsUnsatisfiable
+	}
+
+	sizes := s.sortedSizes(packSizes)
+	if constraints.MinCost() {
+		return s.calculateCheapest(itemsOrdered, sizes, constraints, time.Time{})
+	}
+	result := s.calculateCore(itemsOrdered, sizes, sizes[len(sizes)-1], constraints, time.Time{})
+	if len(result.Packs) == 0 {
+		return result, ErrConstraintsUnsatisfiable
+	}
+	return priceResult(result, constraints.PackCosts), nil
 }
 
-// calculateCore is the unified DP algorithm implementation.
+// CalculateWithin calculates like CalculateWithConstraints, but stops the
+// search once budget has elapsed and returns a greedy combination marked
+// Approximate instead. When the greedy combination does not satisfy the
+// constraints, the search runs to completion. Approximate results are not
+// cached.
+func (s *PackCalculatorService) CalculateWithin(itemsOrdered int, packSizes []int, constraints model.PackConstraints, budget time.Duration) (model.PackResult, error) {
+	if itemsOrdered <= 0 {
+		return model.Empty(itemsOrdered), nil
+	}
+	if itemsOrdered > s.maxItems {
+		return model.Empty(itemsOrdered), ErrOrderTooLarge
+	}
+	if constraints.MaxPacks != nil && *constraints.MaxPacks < 1 {
+		return model.Empty(itemsOrdered), ErrConstraintsUnsatisfiable
+	}
+
+	cacheable := s.cache != nil && constraints.IsZero()
+	key := s.cacheKey(itemsOrdered, packSizes)
+	if cacheable {
+		if result, ok := s.cache.Get(key); ok {
+			result.CacheHit = true
+			return result, nil
+		}
+	}
+
+	sizes := s.sortedSizes(packSizes)
+	if constraints.MinCost() {
+		return s.calculateCheapest(itemsOrdered, sizes, constraints, time.Now().Add(budget))
+	}
+	result := s.calculateCore(itemsOrdered, sizes, sizes[len(sizes)-1], constraints, time.Now().Add(budget))
+	if len(result.Packs) == 0 {
+		return result, ErrConstraintsUnsatisfiable
+	}
+	if cacheable && !result.Approximate {
+		s.cache.Set(key, result)
+	}
+	return priceResult(result, constraints.PackCosts), nil
+}
+
+// sortedSizes returns packSizes sorted descending, or the configured sizes
+// when packSizes is empty.
+func (s *PackCalculatorService) sortedSizes(packSizes []int) []int {
+	if len(packSizes) == 0 {
+		return s.packSizes
+	}
+	sizes := make([]int, len(packSizes))
+	copy(sizes, packSizes)
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+// calculateCore computes the result for target and checks exact results
+// against the greedy combination (see compareWithGreedy).
+// It returns an empty result when no combination satisfies constraints.
+// Past a non-zero deadline it returns the greedy result instead, when that
+// satisfies constraints.
+func (s *PackCalculatorService) calculateCore(target int, packSizes []int, smallestPack int, constraints model.PackConstraints, deadline time.Time) model.PackResult {
+	result := s.optimalResult(target, packSizes, smallestPack, constraints, deadline)
+	if result.Approximate || len(result.Packs) == 0 {
+		return result
+	}
+	return compareWithGreedy(result, packSizes, constraints)
+}
+
+// optimalResult is the unified DP algorithm implementation.
 // It uses sync.Pool for slice reuse to minimize allocations.
-func (s *PackCalculatorService) calculateCore(target int, packSizes []int, smallestPack int) model.PackResult {
+func (s *PackCalculatorService) optimalResult(target int, packSizes []int, smallestPack int, constraints model.PackConstraints, deadline time.Time) model.PackResult {
 	if len(packSizes) == 0 {
 		return model.Empty(target)
 	}
+	maxTotal, limitTotal := constraints.MaxTotalItems(target)
 
-	// Handle small orders efficiently without DP
+	// Handle small orders efficiently without DP. A single pack satisfies
+	// any pack limit, and no other combination ships fewer items.
 	if target <= smallestPack {
-		return s.smallOrderWithSizes(target, packSizes, smallestPack)
+		result := s.smallOrderWithSizes(target, packSizes, smallestPack)
+		if limitTotal && result.TotalItems > maxTotal {
+			return model.Empty(target)
+		}
+		return result
 	}
 
 	maxItems := target + smallestPack - 1
+	maxPacks := 0
+	if constraints.MaxPacks != nil {
+		// The smallest total within the pack limit is below target plus the
+		// largest pack: otherwise dropping any one pack still covers the order.
+		maxPacks = *constraints.MaxPacks
+		maxItems = target + packSizes[0] - 1
+	}
+	if limitTotal {
+		maxItems = min(maxItems, maxTotal)
+	}
+	if maxItems < target {
+		return model.Empty(target)
+	}
+
+	// Large orders avoid the table of every total, as long as the largest
+	// pack is small enough for the bounded search to need less memory
+	if s.boundedSearchThreshold > 0 && target >= s.boundedSearchThreshold && packSizes[0] <= target/2 {
+		if result, ok := largeOrderResult(target, maxItems, maxPacks, packSizes); ok {
+			return result
+		}
+		// The table search is exact; only a table too large to allocate
+		// settles for the greedy result
+		if s.maxTableTotals > 0 && maxItems >= s.maxTableTotals {
+			if result := greedyResult(target, packSizes); satisfies(result, constraints) {
+				result.Approximate = true
+				return result
+			}
+		}
+		// No acceptable shortcut: fall back to the exact table search
+	}
 
 	// Get pooled DP state
 	state := getDPState(maxItems + 1)
@@ -195,7 +505,15 @@ func (s *PackCalculatorService) calculateCore(target int, packSizes []int, small
 
 	// Dynamic programming
 	for i := 0; i <= maxItems; i++ {
-		if dp[i] == -1 {
+		if !deadline.IsZero() && i%deadlineCheckInterval == 0 && time.Now().After(deadline) {
+			if result := greedyResult(target, packSizes); satisfies(result, constraints) {
+				result.Approximate = true
+				return result
+			}
+			// No acceptable shortcut: finish the exact search
+			deadline = time.Time{}
+		}
+		if dp[i] == -1 || (maxPacks > 0 && dp[i] >= maxPacks) {
 			continue
 		}
 		for _, packSize := range packSizes {
@@ -209,8 +527,9 @@ func (s *PackCalculatorService) calculateCore(target int, packSizes []int, small
 				parent[next] = packSize
 			}
 		}
-		// Early exit optimization
-		if i >= target && dp[i] != -1 {
+		// Early exit optimization; with a pack limit, larger totals may be
+		// the only ones within it
+		if maxPacks == 0 && i >= target && dp[i] != -1 {
 			hasBetter := false
 			for j := i + 1; j <= maxItems && j < i+smallestPack; j++ {
 				if dp[j] != -1 && dp[j] < dp[i] {
@@ -226,8 +545,8 @@ func (s *PackCalculatorService) calculateCore(target int, packSizes []int, small
 
 	// Find minimum items >= target
 	minItems := -1
-	for items := target; items <= maxItems && items < target+smallestPack; items++ {
-		if dp[items] != -1 {
+	for items := target; items <= maxItems; items++ {
+		if dp[items] != -1 && (maxPacks == 0 || dp[items] <= maxPacks) {
 			minItems = items
 			break
 		}
@@ -303,3 +622,47 @@ func (s *PackCalculatorService) InvalidateCache() {
 		}
 	}
 }
+
+// InvalidateCachedResult removes the cached result for itemsOrdered with
+// packSizes, the configured ones when empty.
+func (s *PackCalculatorService) InvalidateCachedResult(itemsOrdered int, packSizes []int) {
+	if s.cache != nil {
+		s.cache.Invalidate(s.cacheKey(itemsOrdered, packSizes))
+	}
+}
+
+// ResizeCache changes the capacity and TTL of the in-memory result cache,
+// evicting the least recently used results that no longer fit. It returns
+// false, changing nothing, when capacity is not positive or the calculator
+// was created without an in-memory cache.
+func (s *PackCalculatorService) ResizeCache(capacity int, ttl time.Duration) bool {
+	c, ok := s.cache.(interface{ resize(int, time.Duration) })
+	if !ok || capacity <= 0 {
+		return false
+	}
+	c.resize(capacity, ttl)
+	return true
+}
+
+// CacheMetrics returns the result cache metrics.
+// The second return value is false when caching is disabled or the cache
+// implementation does not report metrics.
+func (s *PackCalculatorService) CacheMetrics() (cache.Metrics, bool) {
+	if c, ok := s.cache.(cache.CacheWithMetrics); ok {
+		return c.Metrics(), true
+	}
+	return cache.Metrics{}, false
+}
+
+// CacheShardMetrics returns the metrics of each shard of the in-memory
+// result cache, an unsharded cache being a single shard. It returns nil when
+// caching is disabled or the results are not cached in memory.
+func (s *PackCalculatorService) CacheShardMetrics() []cache.Metrics {
+	switch c := s.cache.(type) {
+	case *ShardedCache:
+		return c.ShardMetrics()
+	case *ttlCache:
+		return []cache.Metrics{c.Metrics()}
+	}
+	return nil
+}
@@ -5,8 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service/cache"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -235,11 +238,12 @@ func TestPackCalculatorService_WithCacheInterface(t *testing.T) {
 			name:         "cache miss then cache set",
 			itemsOrdered: 251,
 			setupMock: func(mockCache *mocks.MockCache) {
-				mockCache.EXPECT().Get(251).Return(model.PackResult{}, false).Once()
-				mockCache.EXPECT().Set(251, model.PackResult{
-					OrderedItems: 251,
-					TotalItems:   500,
-					Packs:        []model.Pack{{Size: 500, Quantity: 1}},
+				mockCache.EXPECT().Get(cache.NewKey(251, DefaultPackSizes)).Return(model.PackResult{}, false).Once()
+				mockCache.EXPECT().Set(cache.NewKey(251, DefaultPackSizes), model.PackResult{
+					OrderedItems:  251,
+					TotalItems:    500,
+					Packs:         []model.Pack{{Size: 500, Quantity: 1}},
+					GreedyDiffers: true,
 				}).Once()
 			},
 			validate: func(t *testing.T, result model.PackResult) {
@@ -281,9 +285,36 @@ func TestPackCalculatorService_Cache(t *testing.T) {
 
 				// Second call - cache hit (same result)
 				result2 := svc.Calculate(itemsOrdered)
+				assert.False(t, result1.CacheHit)
+				assert.True(t, result2.CacheHit)
+				result2.CacheHit = false
 				assert.Equal(t, result1, result2)
 			},
 		},
+		{
+			name:         "custom pack sizes are cached by their set",
+			itemsOrdered: 251,
+			validate: func(t *testing.T, svc *PackCalculatorService, itemsOrdered int) {
+				assert.False(t, svc.CalculateWithPackSizes(itemsOrdered, []int{100, 50}).CacheHit)
+
+				result := svc.CalculateWithPackSizes(itemsOrdered, []int{50, 100, 50})
+				assert.True(t, result.CacheHit, "the same sizes in another order hit the cache")
+				assert.Equal(t, 300, result.TotalItems)
+			},
+		},
+		{
+			name:         "results of other pack sizes are not reused",
+			itemsOrdered: 251,
+			validate: func(t *testing.T, svc *PackCalculatorService, itemsOrdered int) {
+				svc.Calculate(itemsOrdered)
+
+				result := svc.CalculateWithPackSizes(itemsOrdered, []int{100, 50})
+				assert.False(t, result.CacheHit)
+				assert.Equal(t, 300, result.TotalItems)
+				assert.True(t, svc.CalculateWithPackSizes(itemsOrdered, DefaultPackSizes).CacheHit,
+					"the configured sizes share the results of Calculate")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -421,6 +452,36 @@ func BenchmarkCalculate_Parallel(b *testing.B) {
 	})
 }
 
+// BenchmarkCalculate_MaxItemsOrdered calculates the largest order accepted by
+// default with pack sizes that keep the greedy shortcut from applying, so the
+// full DP runs. Check its time and B/op when raising MAX_ITEMS_ORDERED.
+func BenchmarkCalculate_MaxItemsOrdered(b *testing.B) {
+	svc := NewPackCalculatorService()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		svc.CalculateWithPackSizes(dto.DefaultMaxItemsOrdered, []int{23, 31, 53})
+	}
+}
+
+func TestPackCalculatorService_MaxItemsOrdered(t *testing.T) {
+	svc := NewPackCalculatorService(WithMaxItemsOrdered(1000), WithCache(10, time.Minute))
+	maxPacks := 5
+
+	assert.Equal(t, 1000, svc.MaxItemsOrdered())
+	assert.NotEmpty(t, svc.Calculate(1000).Packs)
+	assert.Equal(t, model.Empty(1001), svc.Calculate(1001))
+	assert.Equal(t, model.Empty(1001), svc.CalculateWithPackSizes(1001, []int{23, 31}))
+
+	_, err := svc.CalculateWithConstraints(1001, nil, model.PackConstraints{MaxPacks: &maxPacks})
+	assert.ErrorIs(t, err, ErrOrderTooLarge)
+	_, err = svc.CalculateWithin(1001, nil, model.PackConstraints{}, time.Second)
+	assert.ErrorIs(t, err, ErrOrderTooLarge)
+
+	assert.Equal(t, dto.DefaultMaxItemsOrdered, NewPackCalculatorService(WithMaxItemsOrdered(0)).MaxItemsOrdered())
+	assert.Equal(t, dto.DefaultMaxItemsOrdered, MaxItemsOrdered(nil))
+}
+
 // TestPackCalculatorService_CalculateWithPackSizes tests calculation with custom pack sizes.
 func TestPackCalculatorService_CalculateWithPackSizes(t *testing.T) {
 	svc := NewPackCalculatorService()
@@ -650,3 +711,333 @@ func TestPackCalculatorService_CalculateWithPackSizesInternal(t *testing.T) {
 		})
 	}
 }
+
+func TestPackCalculatorService_CacheMetrics(t *testing.T) {
+	t.Run("no cache", func(t *testing.T) {
+		_, ok := NewPackCalculatorService().CacheMetrics()
+		assert.False(t, ok)
+	})
+
+	t.Run("cache without metrics", func(t *testing.T) {
+		calc := NewPackCalculatorService(WithCacheInterface(mocks.NewMockCache(t)))
+		_, ok := calc.CacheMetrics()
+		assert.False(t, ok)
+	})
+
+	t.Run("ttl cache reports metrics", func(t *testing.T) {
+		calc := NewPackCalculatorService(WithCache(10, time.Minute))
+		calc.Calculate(251)
+		calc.Calculate(251)
+
+		metrics, ok := calc.CacheMetrics()
+		assert.True(t, ok)
+		assert.Equal(t, 10, metrics.Capacity)
+		assert.Equal(t, int64(1), metrics.Hits)
+		assert.Equal(t, int64(1), metrics.Misses)
+	})
+}
+
+func TestPackCalculatorService_CacheShardMetrics(t *testing.T) {
+	assert.Nil(t, NewPackCalculatorService().CacheShardMetrics())
+
+	calc := NewPackCalculatorService(WithCache(10, time.Minute))
+	calc.Calculate(251)
+	assert.Equal(t, []cache.Metrics{{Misses: 1, Size: 1, Capacity: 10}}, calc.CacheShardMetrics())
+
+	sharded := NewShardedCache(8, time.Minute, 4)
+	defer sharded.Stop()
+	calc = NewPackCalculatorService(WithCacheInterface(sharded))
+	assert.Len(t, calc.CacheShardMetrics(), 4)
+}
+
+func TestPackCalculatorService_InvalidateCachedResult(t *testing.T) {
+	NewPackCalculatorService().InvalidateCachedResult(251, nil)
+
+	calc := NewPackCalculatorService(WithCache(10, time.Minute))
+	calc.Calculate(251)
+	calc.Calculate(500)
+	calc.CalculateWithPackSizes(251, []int{23, 31, 53})
+
+	calc.InvalidateCachedResult(251, nil)
+	assert.False(t, calc.Calculate(251).CacheHit)
+	assert.True(t, calc.Calculate(500).CacheHit)
+	assert.True(t, calc.CalculateWithPackSizes(251, []int{23, 31, 53}).CacheHit, "other pack sizes are kept")
+
+	calc.InvalidateCachedResult(251, []int{53, 31, 23})
+	assert.False(t, calc.CalculateWithPackSizes(251, []int{23, 31, 53}).CacheHit)
+}
+
+func TestPackCalculatorService_ResizeCache(t *testing.T) {
+	assert.False(t, NewPackCalculatorService().ResizeCache(10, time.Minute))
+
+	calc := NewPackCalculatorService(WithCache(10, time.Minute))
+	for items := 1; items <= 5; items++ {
+		calc.Calculate(items)
+	}
+	assert.False(t, calc.ResizeCache(0, time.Minute))
+	assert.True(t, calc.ResizeCache(3, 20*time.Millisecond))
+
+	metrics, _ := calc.CacheMetrics()
+	assert.Equal(t, 3, metrics.Capacity)
+	assert.Equal(t, 3, metrics.Size)
+	assert.True(t, calc.Calculate(5).CacheHit)
+	assert.False(t, calc.Calculate(1).CacheHit, "the least recently used results are evicted")
+
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, calc.Calculate(1).CacheHit, "results cached after the resize use the new TTL")
+}
+
+func TestPackCalculatorService_WithRedisCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	calc := NewPackCalculatorService(
+		WithRedisCache(cache.RedisConfig{Addr: mr.Addr(), KeyPrefix: "test", TTL: time.Minute}),
+		WithPackSizes([]int{250, 500}),
+	)
+	t.Cleanup(calc.cache.Stop)
+
+	resultKey := "test:500-250:" + cache.PackSizesHash([]int{250, 500}).String() + ":251"
+	first := calc.Calculate(251)
+	assert.True(t, mr.Exists(resultKey))
+
+	// A second replica with the same pack sizes reads the shared result
+	replica := NewPackCalculatorService(
+		WithPackSizes([]int{500, 250}),
+		WithRedisCache(cache.RedisConfig{Addr: mr.Addr(), KeyPrefix: "test", TTL: time.Minute}),
+	)
+	t.Cleanup(replica.cache.Stop)
+	shared := replica.Calculate(251)
+	assert.True(t, shared.CacheHit)
+	shared.CacheHit = false
+	assert.Equal(t, first, shared)
+	metrics, ok := replica.CacheMetrics()
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), metrics.Hits)
+
+	calc.InvalidateCache()
+	assert.False(t, mr.Exists(resultKey))
+}
+
+func TestPackCalculatorService_LaterCacheOptionWins(t *testing.T) {
+	calc := NewPackCalculatorService(
+		WithRedisCache(cache.RedisConfig{Addr: "localhost:0"}),
+		WithCache(10, time.Minute),
+	)
+
+	_, isRedis := calc.cache.(*cache.RedisCache)
+	assert.False(t, isRedis)
+}
+
+func TestPackCalculatorService_WithCompression(t *testing.T) {
+	compressor, err := cache.NewCompressor(cache.CompressionZstd, 1)
+	assert.NoError(t, err)
+
+	// Compression applies regardless of option order
+	calc := NewPackCalculatorService(WithCompression(compressor), WithCache(10, time.Minute))
+	defer calc.cache.Stop()
+
+	first := calc.Calculate(12001)
+	cached := calc.Calculate(12001)
+	cached.CacheHit = false
+	assert.Equal(t, first, cached)
+
+	ttl, ok := calc.cache.(*ttlCache)
+	assert.True(t, ok)
+	assert.Same(t, compressor, ttl.compressor)
+}
+
+func TestPackCalculatorService_CalculateWithConstraints(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name          string
+		itemsOrdered  int
+		packSizes     []int
+		constraints   model.PackConstraints
+		expectedTotal int
+		expectedPacks []model.Pack
+		expectedErr   error
+	}{
+		{
+			name:          "no constraints matches the unconstrained result",
+			itemsOrdered:  12001,
+			expectedTotal: 12250,
+			expectedPacks: []model.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			name:          "pack limit trades items for fewer packs",
+			itemsOrdered:  12001,
+			constraints:   model.PackConstraints{MaxPacks: intPtr(3)},
+			expectedTotal: 15000,
+			expectedPacks: []model.Pack{{Size: 5000, Quantity: 3}},
+		},
+		{
+			name:          "pack limit already met",
+			itemsOrdered:  12001,
+			constraints:   model.PackConstraints{MaxPacks: intPtr(4)},
+			expectedTotal: 12250,
+			expectedPacks: []model.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			name:         "pack limit and overage cannot both hold",
+			itemsOrdered: 12001,
+			constraints:  model.PackConstraints{MaxPacks: intPtr(3), MaxOverageItems: intPtr(1000)},
+			expectedErr:  ErrConstraintsUnsatisfiable,
+		},
+		{
+			name:         "overage items exceeded",
+			itemsOrdered: 251,
+			constraints:  model.PackConstraints{MaxOverageItems: intPtr(100)},
+			expectedErr:  ErrConstraintsUnsatisfiable,
+		},
+		{
+			name:          "overage percent allowed",
+			itemsOrdered:  251,
+			constraints:   model.PackConstraints{MaxOveragePercent: floatPtr(100)},
+			expectedTotal: 500,
+			expectedPacks: []model.Pack{{Size: 500, Quantity: 1}},
+		},
+		{
+			name:         "small order over overage",
+			itemsOrdered: 1,
+			constraints:  model.PackConstraints{MaxOverageItems: intPtr(0)},
+			expectedErr:  ErrConstraintsUnsatisfiable,
+		},
+		{
+			name:          "exact match required",
+			itemsOrdered:  500000,
+			packSizes:     []int{23, 31, 53},
+			constraints:   model.PackConstraints{MaxOverageItems: intPtr(0)},
+			expectedTotal: 500000,
+			expectedPacks: []model.Pack{{Size: 53, Quantity: 9429}, {Size: 31, Quantity: 7}, {Size: 23, Quantity: 2}},
+		},
+		{
+			name:         "zero pack limit",
+			itemsOrdered: 10,
+			constraints:  model.PackConstraints{MaxPacks: intPtr(0)},
+			expectedErr:  ErrConstraintsUnsatisfiable,
+		},
+	}
+
+	calc := NewPackCalculatorService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.CalculateWithConstraints(tt.itemsOrdered, tt.packSizes, tt.constraints)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, result.Packs)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.itemsOrdered, result.OrderedItems)
+			assert.Equal(t, tt.expectedTotal, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+		})
+	}
+}
+
+// TestPackCalculatorService_CalculateWithConstraints_BruteForce checks pack
+// limits against an exhaustive search over small orders.
+func TestPackCalculatorService_CalculateWithConstraints_BruteForce(t *testing.T) {
+	sizes := []int{23, 31, 53}
+	calc := NewPackCalculatorService()
+
+	// best returns the smallest total >= target using at most limit packs.
+	var best func(target, limit int) int
+	best = func(target, limit int) int {
+		if target <= 0 {
+			return 0
+		}
+		if limit == 0 {
+			return -1
+		}
+		result := -1
+		for _, size := range sizes {
+			if rest := best(target-size, limit-1); rest >= 0 && (result == -1 || size+rest < result) {
+				result = size + rest
+			}
+		}
+		return result
+	}
+
+	for limit := 1; limit <= 4; limit++ {
+		for target := 1; target <= 180; target++ {
+			l := limit
+			result, err := calc.CalculateWithConstraints(target, sizes, model.PackConstraints{MaxPacks: &l})
+			want := best(target, limit)
+			if want == -1 {
+				assert.ErrorIs(t, err, ErrConstraintsUnsatisfiable, "target %d limit %d", target, limit)
+				continue
+			}
+			assert.NoError(t, err, "target %d limit %d", target, limit)
+			assert.Equal(t, want, result.TotalItems, "target %d limit %d", target, limit)
+
+			packs := 0
+			for _, p := range result.Packs {
+				packs += p.Quantity
+			}
+			assert.LessOrEqual(t, packs, limit)
+		}
+	}
+}
+
+func TestPackCalculatorService_CalculateWithin(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	sizes := []int{23, 31, 53}
+
+	t.Run("returns the greedy result when the budget runs out", func(t *testing.T) {
+		calc := NewPackCalculatorService()
+
+		result, err := calc.CalculateWithin(500000, sizes, model.PackConstraints{}, time.Nanosecond)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Approximate)
+		assert.Equal(t, 500003, result.TotalItems)
+		assert.Equal(t, []model.Pack{{Size: 53, Quantity: 9433}, {Size: 31, Quantity: 1}, {Size: 23, Quantity: 1}}, result.Packs)
+	})
+
+	t.Run("returns the optimal result within the budget", func(t *testing.T) {
+		calc := NewPackCalculatorService()
+
+		result, err := calc.CalculateWithin(12001, nil, model.PackConstraints{}, time.Minute)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Approximate)
+		assert.Equal(t, calc.Calculate(12001), result)
+	})
+
+	t.Run("finishes the search when the greedy result breaks the constraints", func(t *testing.T) {
+		calc := NewPackCalculatorService()
+
+		result, err := calc.CalculateWithin(500000, sizes, model.PackConstraints{MaxOverageItems: intPtr(0)}, time.Nanosecond)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Approximate)
+		assert.Equal(t, 500000, result.TotalItems)
+	})
+
+	t.Run("reports unsatisfiable constraints", func(t *testing.T) {
+		calc := NewPackCalculatorService()
+
+		_, err := calc.CalculateWithin(12001, nil, model.PackConstraints{MaxPacks: intPtr(0)}, time.Minute)
+
+		assert.ErrorIs(t, err, ErrConstraintsUnsatisfiable)
+	})
+
+	t.Run("caches optimal results only", func(t *testing.T) {
+		calc := NewPackCalculatorService(WithPackSizes(sizes), WithCache(10, time.Minute))
+
+		result, err := calc.CalculateWithin(500000, nil, model.PackConstraints{}, time.Nanosecond)
+		assert.NoError(t, err)
+		assert.True(t, result.Approximate)
+		_, cached := calc.cache.Get(calc.cacheKey(500000, nil))
+		assert.False(t, cached)
+
+		result, err = calc.CalculateWithin(251, nil, model.PackConstraints{}, time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, result.Approximate)
+		_, cached = calc.cache.Get(calc.cacheKey(251, nil))
+		assert.True(t, cached)
+	})
+}
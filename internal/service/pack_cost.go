@@ -0,0 +1,159 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+)
+
+var (
+	// ErrMissingPackCost is returned when a calculation optimizing for cost
+	// has a pack size without a cost.
+	ErrMissingPackCost = domainerr.InvalidRequest.Derive(i18n.ErrKeyMissingPackCost, "optimizing for cost needs a cost for every pack size")
+
+	// ErrMinCostWithMaxPacks is returned when a calculation optimizing for
+	// cost also limits the number of packs, which the cost search does not
+	// support.
+	ErrMinCostWithMaxPacks = domainerr.InvalidRequest.Derive(i18n.ErrKeyValidationOptimize, "max_packs cannot be combined with optimizing for cost")
+)
+
+// costScale is the number of cost units in one unit of currency. Costs are
+// rounded to four decimal places and summed as integers, so totals are
+// exact and equal costs compare equal.
+const costScale = 10_000
+
+// costUnits converts cost to cost units.
+func costUnits(cost float64) int64 {
+	return int64(math.Round(cost * costScale))
+}
+
+// packCostUnits returns the cost units of one pack of each of packSizes, and
+// false when a size has no cost.
+func packCostUnits(packSizes []int, costs map[int]float64) ([]int64, bool) {
+	units := make([]int64, len(packSizes))
+	for i, size := range packSizes {
+		cost, ok := costs[size]
+		if !ok {
+			return nil, false
+		}
+		units[i] = costUnits(cost)
+	}
+	return units, true
+}
+
+// priceResult sets the total cost of result by costs. Results with a pack
+// size without a cost are returned unpriced.
+func priceResult(result model.PackResult, costs map[int]float64) model.PackResult {
+	if len(costs) == 0 || len(result.Packs) == 0 {
+		return result
+	}
+	var units int64
+	for _, pack := range result.Packs {
+		cost, ok := costs[pack.Size]
+		if !ok {
+			return result
+		}
+		units += costUnits(cost) * int64(pack.Quantity)
+	}
+	total := float64(units) / costScale
+	result.TotalCost = &total
+	return result
+}
+
+// calculateCheapest calculates the cheapest combination of packSizes,
+// sorted descending, for target within constraints, and prices it. Past a
+// non-zero deadline it returns the greedy result marked Approximate instead,
+// when that satisfies constraints.
+func (s *PackCalculatorService) calculateCheapest(target int, packSizes []int, constraints model.PackConstraints, deadline time.Time) (model.PackResult, error) {
+	if constraints.MaxPacks != nil {
+		return model.Empty(target), ErrMinCostWithMaxPacks
+	}
+	costs, ok := packCostUnits(packSizes, constraints.PackCosts)
+	if !ok {
+		return model.Empty(target), ErrMissingPackCost
+	}
+
+	result := cheapestResult(target, packSizes, costs, constraints, deadline)
+	if len(result.Packs) == 0 {
+		return result, ErrConstraintsUnsatisfiable
+	}
+	return priceResult(result, constraints.PackCosts), nil
+}
+
+// cheapestResult searches every total from target up to the largest pack
+// beyond it for the cheapest combination, preferring fewer items, then fewer
+// packs, among equally cheap ones. Larger totals need not be searched: any
+// pack can be dropped from them and the order is still covered for no more.
+// Memory grows with the order, as for the search by items: the bounded
+// search for large orders does not know costs.
+func cheapestResult(target int, packSizes []int, costs []int64, constraints model.PackConstraints, deadline time.Time) model.PackResult {
+	maxItems := target + packSizes[0] - 1
+	if maxTotal, ok := constraints.MaxTotalItems(target); ok {
+		maxItems = min(maxItems, maxTotal)
+	}
+	if maxItems < target {
+		return model.Empty(target)
+	}
+
+	// cost is -1 for totals no combination reaches; packs and parent fit in
+	// 32 bits as totals are capped by the calculator's maximum
+	cost := make([]int64, maxItems+1)
+	packs := make([]int32, maxItems+1)
+	parent := make([]int32, maxItems+1)
+	for i := 1; i <= maxItems; i++ {
+		cost[i] = -1
+	}
+
+	for i := 0; i <= maxItems; i++ {
+		if !deadline.IsZero() && i%deadlineCheckInterval == 0 && time.Now().After(deadline) {
+			if result := greedyResult(target, packSizes); satisfies(result, constraints) {
+				result.Approximate = true
+				return result
+			}
+			// No acceptable shortcut: finish the exact search
+			deadline = time.Time{}
+		}
+		if cost[i] == -1 {
+			continue
+		}
+		for j, size := range packSizes {
+			next := i + size
+			if next > maxItems {
+				continue
+			}
+			nextCost, nextPacks := cost[i]+costs[j], packs[i]+1
+			if cost[next] == -1 || nextCost < cost[next] || (nextCost == cost[next] && nextPacks < packs[next]) {
+				cost[next] = nextCost
+				packs[next] = nextPacks
+				parent[next] = int32(size)
+			}
+		}
+	}
+
+	// The first of the cheapest totals ships the fewest items
+	best := -1
+	for total := target; total <= maxItems; total++ {
+		if cost[total] != -1 && (best == -1 || cost[total] < cost[best]) {
+			best = total
+		}
+	}
+	if best == -1 {
+		return model.Empty(target)
+	}
+
+	counts := make(map[int]int, len(packSizes))
+	for curr := best; curr > 0; curr -= int(parent[curr]) {
+		counts[int(parent[curr])]++
+	}
+	result := model.PackResult{OrderedItems: target, TotalItems: best, Packs: make([]model.Pack, 0, len(counts))}
+	for _, size := range packSizes {
+		if count := counts[size]; count > 0 {
+			result.Packs = append(result.Packs, model.Pack{Size: size, Quantity: count})
+			delete(counts, size)
+		}
+	}
+	return result
+}
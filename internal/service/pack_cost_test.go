@@ -0,0 +1,185 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculatorService_CalculateWithConstraints_MinCost(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	minCost := func(costs map[int]float64) model.PackConstraints {
+		return model.PackConstraints{Optimize: model.OptimizeMinCost, PackCosts: costs}
+	}
+
+	tests := []struct {
+		name          string
+		itemsOrdered  int
+		packSizes     []int
+		constraints   model.PackConstraints
+		expectedTotal int
+		expectedPacks []model.Pack
+		expectedCost  float64
+		expectedErr   error
+	}{
+		{
+			name:          "cheaper packs win over fewer items",
+			itemsOrdered:  251,
+			packSizes:     []int{250, 500},
+			constraints:   minCost(map[int]float64{250: 1, 500: 5}),
+			expectedTotal: 500,
+			expectedPacks: []model.Pack{{Size: 250, Quantity: 2}},
+			expectedCost:  2,
+		},
+		{
+			name:          "bulk discount",
+			itemsOrdered:  251,
+			packSizes:     []int{250, 500},
+			constraints:   minCost(map[int]float64{250: 3, 500: 4.5}),
+			expectedTotal: 500,
+			expectedPacks: []model.Pack{{Size: 500, Quantity: 1}},
+			expectedCost:  4.5,
+		},
+		{
+			name:          "fewer items among equally cheap combinations",
+			itemsOrdered:  6,
+			packSizes:     []int{3, 7},
+			constraints:   minCost(map[int]float64{3: 1, 7: 2}),
+			expectedTotal: 6,
+			expectedPacks: []model.Pack{{Size: 3, Quantity: 2}},
+			expectedCost:  2,
+		},
+		{
+			name:          "fewer packs among equally cheap combinations of the same items",
+			itemsOrdered:  4,
+			packSizes:     []int{2, 4},
+			constraints:   minCost(map[int]float64{2: 1, 4: 2}),
+			expectedTotal: 4,
+			expectedPacks: []model.Pack{{Size: 4, Quantity: 1}},
+			expectedCost:  2,
+		},
+		{
+			name:          "costs are summed exactly",
+			itemsOrdered:  30,
+			packSizes:     []int{10},
+			constraints:   minCost(map[int]float64{10: 0.1}),
+			expectedTotal: 30,
+			expectedPacks: []model.Pack{{Size: 10, Quantity: 3}},
+			expectedCost:  0.3,
+		},
+		{
+			name:          "overage limit",
+			itemsOrdered:  250,
+			packSizes:     []int{100, 300},
+			constraints:   model.PackConstraints{Optimize: model.OptimizeMinCost, PackCosts: map[int]float64{100: 1, 300: 10}, MaxOverageItems: intPtr(50)},
+			expectedTotal: 300,
+			expectedPacks: []model.Pack{{Size: 100, Quantity: 3}},
+			expectedCost:  3,
+		},
+		{
+			name:         "overage limit unreachable",
+			itemsOrdered: 250,
+			packSizes:    []int{100, 300},
+			constraints:  model.PackConstraints{Optimize: model.OptimizeMinCost, PackCosts: map[int]float64{100: 1, 300: 10}, MaxOverageItems: intPtr(49)},
+			expectedErr:  ErrConstraintsUnsatisfiable,
+		},
+		{
+			name:         "size without a cost",
+			itemsOrdered: 251,
+			packSizes:    []int{250, 500},
+			constraints:  minCost(map[int]float64{250: 1}),
+			expectedErr:  ErrMissingPackCost,
+		},
+		{
+			name:         "pack limit",
+			itemsOrdered: 251,
+			packSizes:    []int{250, 500},
+			constraints:  model.PackConstraints{Optimize: model.OptimizeMinCost, PackCosts: map[int]float64{250: 1, 500: 5}, MaxPacks: intPtr(2)},
+			expectedErr:  ErrMinCostWithMaxPacks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := NewPackCalculatorService()
+
+			result, err := calc.CalculateWithConstraints(tt.itemsOrdered, tt.packSizes, tt.constraints)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTotal, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+			require.NotNil(t, result.TotalCost)
+			assert.Equal(t, tt.expectedCost, *result.TotalCost)
+		})
+	}
+}
+
+func TestPackCalculatorService_CalculateWithConstraints_MinCostBruteForce(t *testing.T) {
+	sizes := []int{23, 31, 53}
+	costs := map[int]float64{23: 2.3, 31: 2.5, 53: 5}
+	calc := NewPackCalculatorService()
+
+	// cheapest returns the cost in cents of the cheapest combination covering
+	// target.
+	var cheapest func(target int) int
+	cheapest = func(target int) int {
+		if target <= 0 {
+			return 0
+		}
+		result := -1
+		for _, size := range sizes {
+			if cost := int(math.Round(costs[size]*100)) + cheapest(target-size); result == -1 || cost < result {
+				result = cost
+			}
+		}
+		return result
+	}
+
+	for target := 1; target <= 150; target++ {
+		result, err := calc.CalculateWithConstraints(target, sizes, model.PackConstraints{Optimize: model.OptimizeMinCost, PackCosts: costs})
+		require.NoError(t, err)
+		require.NotNil(t, result.TotalCost)
+		assert.InDelta(t, float64(cheapest(target))/100, *result.TotalCost, 1e-9, "target %d", target)
+	}
+}
+
+func TestPackCalculatorService_PricesResults(t *testing.T) {
+	calc := NewPackCalculatorService()
+
+	t.Run("prices the fewest items result", func(t *testing.T) {
+		result, err := calc.CalculateWithConstraints(251, []int{250, 500}, model.PackConstraints{PackCosts: map[int]float64{250: 1, 500: 5}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.Pack{{Size: 500, Quantity: 1}}, result.Packs)
+		require.NotNil(t, result.TotalCost)
+		assert.Equal(t, 5.0, *result.TotalCost)
+	})
+
+	t.Run("leaves results with a size without a cost unpriced", func(t *testing.T) {
+		result, err := calc.CalculateWithConstraints(251, []int{250, 500}, model.PackConstraints{PackCosts: map[int]float64{250: 1}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.Pack{{Size: 500, Quantity: 1}}, result.Packs)
+		assert.Nil(t, result.TotalCost)
+	})
+
+	t.Run("returns the priced greedy result when the budget runs out", func(t *testing.T) {
+		costs := map[int]float64{23: 1, 31: 1, 53: 1}
+
+		result, err := calc.CalculateWithin(500000, []int{23, 31, 53}, model.PackConstraints{Optimize: model.OptimizeMinCost, PackCosts: costs}, time.Nanosecond)
+
+		require.NoError(t, err)
+		assert.True(t, result.Approximate)
+		assert.Equal(t, []model.Pack{{Size: 53, Quantity: 9433}, {Size: 31, Quantity: 1}, {Size: 23, Quantity: 1}}, result.Packs)
+		require.NotNil(t, result.TotalCost)
+		assert.Equal(t, 9435.0, *result.TotalCost)
+	})
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// Severities of pack size rule violations.
+const (
+	// PackSizeRuleError violations keep the sizes from being saved.
+	PackSizeRuleError = "error"
+	// PackSizeRuleWarning violations are reported but allowed.
+	PackSizeRuleWarning = "warning"
+)
+
+// Pack size rules.
+const (
+	RuleMaxSizes      = "max_sizes"
+	RuleMinSize       = "min_size"
+	RuleMaxSize       = "max_size"
+	RuleUniqueSizes   = "unique_sizes"
+	RuleCommonDivisor = "common_divisor"
+)
+
+// ErrPackSizeRules is returned when pack sizes break a rule of error severity.
+var ErrPackSizeRules = domainerr.InvalidRequest.Derive("", "pack sizes break the pack size rules")
+
+// PackSizeRules are the policies a pack size configuration must follow to be
+// saved. Zero limits fall back to the request limits of dto.
+type PackSizeRules struct {
+	// MaxSizes is the most sizes a configuration may have.
+	MaxSizes int
+	// MinSize is the smallest allowed pack size.
+	MinSize int
+	// MaxSize is the largest allowed pack size.
+	MaxSize int
+	// CommonDivisorWarning warns when every size is a multiple of a number at
+	// least this large, since orders are then always rounded up to a multiple
+	// of it. Zero disables the warning.
+	CommonDivisorWarning int
+}
+
+// DefaultPackSizeRules returns the rules used unless configured otherwise.
+func DefaultPackSizeRules() PackSizeRules {
+	return PackSizeRules{
+		MaxSizes: dto.MaxPackSizes,
+		MinSize:  1,
+		MaxSize:  dto.MaxPackSize,
+	}
+}
+
+// withDefaults fills in the limits left at zero.
+func (r PackSizeRules) withDefaults() PackSizeRules {
+	defaults := DefaultPackSizeRules()
+	if r.MaxSizes <= 0 {
+		r.MaxSizes = defaults.MaxSizes
+	}
+	if r.MinSize <= 0 {
+		r.MinSize = defaults.MinSize
+	}
+	if r.MaxSize <= 0 {
+		r.MaxSize = defaults.MaxSize
+	}
+	return r
+}
+
+// Check returns the rules sizes break, errors first, or nil when it follows
+// them all.
+func (r PackSizeRules) Check(sizes []int) []dto.PackSizeViolation {
+	r = r.withDefaults()
+
+	var violations []dto.PackSizeViolation
+	fail := func(rule, message string, offending []int) {
+		violations = append(violations, dto.PackSizeViolation{
+			Rule:     rule,
+			Severity: PackSizeRuleError,
+			Message:  message,
+			Sizes:    offending,
+		})
+	}
+
+	if len(sizes) > r.MaxSizes {
+		fail(RuleMaxSizes, fmt.Sprintf("at most %d pack sizes are allowed, got %d", r.MaxSizes, len(sizes)), nil)
+	}
+
+	var tooSmall, tooLarge, duplicates []int
+	seen := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		switch {
+		case size < r.MinSize:
+			tooSmall = append(tooSmall, size)
+		case size > r.MaxSize:
+			tooLarge = append(tooLarge, size)
+		}
+		if seen[size] && !slices.Contains(duplicates, size) {
+			duplicates = append(duplicates, size)
+		}
+		seen[size] = true
+	}
+	if len(tooSmall) > 0 {
+		fail(RuleMinSize, fmt.Sprintf("pack sizes must be at least %d", r.MinSize), tooSmall)
+	}
+	if len(tooLarge) > 0 {
+		fail(RuleMaxSize, fmt.Sprintf("pack sizes must be at most %d", r.MaxSize), tooLarge)
+	}
+	if len(duplicates) > 0 {
+		fail(RuleUniqueSizes, "pack sizes must be unique", duplicates)
+	}
+
+	if r.CommonDivisorWarning > 0 && len(tooSmall) == 0 {
+		if divisor := commonDivisor(sizes); divisor >= r.CommonDivisorWarning {
+			violations = append(violations, dto.PackSizeViolation{
+				Rule:     RuleCommonDivisor,
+				Severity: PackSizeRuleWarning,
+				Message: fmt.Sprintf("every pack size is a multiple of %d, so every order is rounded up to a multiple of %d; "+
+					"add a smaller size to ship closer to the ordered quantity", divisor, divisor),
+			})
+		}
+	}
+	return violations
+}
+
+// HasPackSizeErrors reports whether violations include one of error severity.
+func HasPackSizeErrors(violations []dto.PackSizeViolation) bool {
+	return slices.ContainsFunc(violations, func(v dto.PackSizeViolation) bool {
+		return v.Severity == PackSizeRuleError
+	})
+}
+
+// commonDivisor returns the greatest common divisor of sizes, which must be
+// positive.
+func commonDivisor(sizes []int) int {
+	divisor := 0
+	for _, size := range sizes {
+		a, b := divisor, size
+		for b != 0 {
+			a, b = b, a%b
+		}
+		divisor = a
+	}
+	return divisor
+}
+
+// PackSizesImpact calculates each of orders with the current and the
+// proposed sizes, the current ones being the calculator's configured sizes
+// when empty.
+func PackSizesImpact(calculator PackCalculator, current, proposed, orders []int) []dto.PackSizeImpact {
+	impacts := make([]dto.PackSizeImpact, 0, len(orders))
+	for _, items := range orders {
+		var before dto.PackSizeOutcome
+		if len(current) > 0 {
+			before = packSizeOutcome(calculator.CalculateWithPackSizes(items, current))
+		} else {
+			before = packSizeOutcome(calculator.Calculate(items))
+		}
+		after := packSizeOutcome(calculator.CalculateWithPackSizes(items, proposed))
+
+		impacts = append(impacts, dto.PackSizeImpact{
+			ItemsOrdered:     items,
+			Current:          before,
+			Proposed:         after,
+			Changed:          !slices.Equal(before.Packs, after.Packs),
+			TotalItemsChange: after.TotalItems - before.TotalItems,
+			PackCountChange:  after.PackCount - before.PackCount,
+		})
+	}
+	return impacts
+}
+
+// packSizeOutcome summarizes a result for PackSizesImpact.
+func packSizeOutcome(result model.PackResult) dto.PackSizeOutcome {
+	return dto.PackSizeOutcome{
+		TotalItems:   result.TotalItems,
+		OverageItems: result.TotalItems - result.OrderedItems,
+		PackCount:    result.PackCount(),
+		Packs:        result.Packs,
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func violationRules(violations []dto.PackSizeViolation) []string {
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestPackSizeRules_Check(t *testing.T) {
+	rules := PackSizeRules{MaxSizes: 4, MinSize: 10, MaxSize: 5000, CommonDivisorWarning: 100}
+
+	tests := []struct {
+		name      string
+		sizes     []int
+		wantRules []string
+		wantError bool
+	}{
+		{name: "valid", sizes: []int{23, 31, 53}, wantRules: []string{}},
+		{name: "too many sizes", sizes: []int{10, 20, 30, 40, 50}, wantRules: []string{RuleMaxSizes}, wantError: true},
+		{name: "too small", sizes: []int{5, 250}, wantRules: []string{RuleMinSize}, wantError: true},
+		{name: "too large", sizes: []int{251, 10000}, wantRules: []string{RuleMaxSize}, wantError: true},
+		{name: "duplicates", sizes: []int{250, 500, 250, 250}, wantRules: []string{RuleUniqueSizes, RuleCommonDivisor}, wantError: true},
+		{name: "large common divisor", sizes: []int{250, 500, 1000}, wantRules: []string{RuleCommonDivisor}},
+		{name: "small common divisor", sizes: []int{50, 100}, wantRules: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := rules.Check(tt.sizes)
+			assert.Equal(t, tt.wantRules, violationRules(violations))
+			assert.Equal(t, tt.wantError, HasPackSizeErrors(violations))
+		})
+	}
+
+	t.Run("lists offending sizes", func(t *testing.T) {
+		violations := rules.Check([]int{250, 250, 1, 0})
+		assert.Equal(t, []int{1, 0}, violations[0].Sizes)
+		assert.Equal(t, []int{250}, violations[1].Sizes)
+		assert.Equal(t, PackSizeRuleWarning, PackSizeRules{CommonDivisorWarning: 250}.Check([]int{250, 500})[0].Severity)
+	})
+
+	t.Run("zero limits use the defaults", func(t *testing.T) {
+		assert.Empty(t, PackSizeRules{}.Check([]int{1, dto.MaxPackSize}))
+		assert.Equal(t, []string{RuleMinSize, RuleMaxSize}, violationRules(PackSizeRules{}.Check([]int{0, dto.MaxPackSize + 1})))
+	})
+}
+
+func TestPackSizesImpact(t *testing.T) {
+	calculator := NewPackCalculatorService(WithPackSizes([]int{250, 500, 1000}))
+
+	impacts := PackSizesImpact(calculator, nil, []int{250, 500, 800}, []int{251, 751})
+	assert.Len(t, impacts, 2)
+
+	assert.Equal(t, 251, impacts[0].ItemsOrdered)
+	assert.False(t, impacts[0].Changed)
+	assert.Equal(t, 500, impacts[0].Proposed.TotalItems)
+
+	assert.True(t, impacts[1].Changed)
+	assert.Equal(t, dto.PackSizeOutcome{TotalItems: 1000, OverageItems: 249, PackCount: 1, Packs: []model.Pack{{Size: 1000, Quantity: 1}}}, impacts[1].Current)
+	assert.Equal(t, []model.Pack{{Size: 800, Quantity: 1}}, impacts[1].Proposed.Packs)
+	assert.Equal(t, -200, impacts[1].TotalItemsChange)
+	assert.Zero(t, impacts[1].PackCountChange)
+
+	current := PackSizesImpact(calculator, []int{250, 500, 800}, []int{250, 500, 800}, []int{751})
+	assert.False(t, current[0].Changed)
+}
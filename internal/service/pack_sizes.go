@@ -3,35 +3,86 @@ package service
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/i18n"
 	"github.com/guttosm/pack-service/internal/repository"
 )
 
 // ErrRepositoryNotConfigured is returned when the repository is not configured.
 var ErrRepositoryNotConfigured = errors.New("repository not configured")
 
+var (
+	// ErrPackSizesNotFound is returned when a pack size configuration does not exist.
+	ErrPackSizesNotFound = domainerr.New("pack_sizes_not_found", http.StatusNotFound, i18n.ErrKeyNotFound, "pack size configuration not found")
+	// ErrNoPreviousPackSizes is returned by Rollback when no configuration
+	// precedes the active one.
+	ErrNoPreviousPackSizes = domainerr.New("no_previous_pack_sizes", http.StatusConflict, "", "no previous pack size configuration")
+	// ErrPackSizesChanged is returned by CreateIfActive when the expected
+	// configuration is no longer the active one.
+	ErrPackSizesChanged = domainerr.PreconditionFailed.Derive("", "the active pack size configuration changed")
+)
+
 // PackSizesService provides pack sizes-related operations.
 type PackSizesService interface {
 	GetActive(ctx context.Context) (*repository.PackSizeConfig, error)
-	Create(ctx context.Context, sizes []int, createdBy string) (*repository.PackSizeConfig, error)
+	Create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string) (*repository.PackSizeConfig, error)
+	CreateIfActive(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int) (*repository.PackSizeConfig, error)
 	Update(ctx context.Context, id primitive.ObjectID, sizes []int, updatedBy string) (*repository.PackSizeConfig, error)
 	List(ctx context.Context, limit int) ([]repository.PackSizeConfig, error)
+	Activate(ctx context.Context, id primitive.ObjectID, activatedBy string) (*repository.PackSizeConfig, error)
+	Rollback(ctx context.Context, activatedBy string) (*repository.PackSizeConfig, error)
 }
 
 // PackSizesServiceImpl implements PackSizesService.
 type PackSizesServiceImpl struct {
 	packSizesRepo repository.PackSizesRepositoryInterface
+	onActivate    []func(*repository.PackSizeConfig)
 }
 
-// NewPackSizesService creates a new pack sizes service.
-func NewPackSizesService(packSizesRepo repository.PackSizesRepositoryInterface) PackSizesService {
-	if packSizesRepo == nil {
-		return &PackSizesServiceImpl{}
+// PackSizesServiceOption configures a PackSizesService.
+type PackSizesServiceOption func(*PackSizesServiceImpl)
+
+// WithActivationHook calls fn with every configuration the service makes
+// active: created, updated while active, activated or rolled back to.
+func WithActivationHook(fn func(*repository.PackSizeConfig)) PackSizesServiceOption {
+	return func(s *PackSizesServiceImpl) {
+		if fn != nil {
+			s.onActivate = append(s.onActivate, fn)
+		}
 	}
-	return &PackSizesServiceImpl{
+}
+
+// WithCalculatorInvalidation clears the result cache of calculator whenever
+// the service activates a configuration, so results calculated with the
+// previous pack sizes do not linger in it.
+func WithCalculatorInvalidation(calculator PackCalculator) PackSizesServiceOption {
+	return WithActivationHook(func(*repository.PackSizeConfig) {
+		calculator.InvalidateCache()
+	})
+}
+
+// NewPackSizesService creates a new pack sizes service.
+func NewPackSizesService(packSizesRepo repository.PackSizesRepositoryInterface, opts ...PackSizesServiceOption) PackSizesService {
+	s := &PackSizesServiceImpl{
 		packSizesRepo: packSizesRepo,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// activated runs the activation hooks for config when it is active.
+func (s *PackSizesServiceImpl) activated(config *repository.PackSizeConfig) {
+	if config == nil || !config.Active {
+		return
+	}
+	for _, fn := range s.onActivate {
+		fn(config)
+	}
 }
 
 func (s *PackSizesServiceImpl) GetActive(ctx context.Context) (*repository.PackSizeConfig, error) {
@@ -41,18 +92,45 @@ func (s *PackSizesServiceImpl) GetActive(ctx context.Context) (*repository.PackS
 	return s.packSizesRepo.GetActive(ctx)
 }
 
-func (s *PackSizesServiceImpl) Create(ctx context.Context, sizes []int, createdBy string) (*repository.PackSizeConfig, error) {
+func (s *PackSizesServiceImpl) Create(ctx context.Context, sizes []int, costs map[int]float64, createdBy string) (*repository.PackSizeConfig, error) {
 	if s.packSizesRepo == nil {
 		return nil, ErrRepositoryNotConfigured
 	}
-	return s.packSizesRepo.Create(ctx, sizes, createdBy)
+	config, err := s.packSizesRepo.Create(ctx, sizes, costs, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	s.activated(config)
+	return config, nil
+}
+
+// CreateIfActive creates and activates a configuration like Create, only
+// while activeVersion is the active one, or returns ErrPackSizesChanged.
+func (s *PackSizesServiceImpl) CreateIfActive(ctx context.Context, sizes []int, costs map[int]float64, createdBy string, activeVersion int) (*repository.PackSizeConfig, error) {
+	if s.packSizesRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	config, err := s.packSizesRepo.CreateIfActive(ctx, sizes, costs, createdBy, activeVersion)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, ErrPackSizesChanged
+	}
+	s.activated(config)
+	return config, nil
 }
 
 func (s *PackSizesServiceImpl) Update(ctx context.Context, id primitive.ObjectID, sizes []int, updatedBy string) (*repository.PackSizeConfig, error) {
 	if s.packSizesRepo == nil {
 		return nil, ErrRepositoryNotConfigured
 	}
-	return s.packSizesRepo.Update(ctx, id, sizes, updatedBy)
+	config, err := s.packSizesRepo.Update(ctx, id, sizes, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	s.activated(config)
+	return config, nil
 }
 
 func (s *PackSizesServiceImpl) List(ctx context.Context, limit int) ([]repository.PackSizeConfig, error) {
@@ -61,3 +139,44 @@ func (s *PackSizesServiceImpl) List(ctx context.Context, limit int) ([]repositor
 	}
 	return s.packSizesRepo.List(ctx, limit)
 }
+
+// Activate makes the configuration with the given ID the active one, or
+// returns ErrPackSizesNotFound.
+func (s *PackSizesServiceImpl) Activate(ctx context.Context, id primitive.ObjectID, activatedBy string) (*repository.PackSizeConfig, error) {
+	if s.packSizesRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	config, err := s.packSizesRepo.Activate(ctx, id, activatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, ErrPackSizesNotFound
+	}
+	s.activated(config)
+	return config, nil
+}
+
+// Rollback activates the configuration with the highest version below the
+// active one, or returns ErrNoPreviousPackSizes. Rolling back repeatedly
+// walks back through the versions.
+func (s *PackSizesServiceImpl) Rollback(ctx context.Context, activatedBy string) (*repository.PackSizeConfig, error) {
+	if s.packSizesRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	active, err := s.packSizesRepo.GetActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, ErrNoPreviousPackSizes
+	}
+	previous, err := s.packSizesRepo.PreviousVersion(ctx, active.Version)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, ErrNoPreviousPackSizes
+	}
+	return s.Activate(ctx, previous.ID, activatedBy)
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// Pack size migration states.
+const (
+	MigrationShadowing = "shadowing"
+	MigrationReady     = "ready"
+	MigrationPromoting = "promoting"
+	MigrationPromoted  = "promoted"
+	MigrationCancelled = "cancelled"
+)
+
+const (
+	// DefaultShadowWindow is how long live traffic is compared when the
+	// migration does not say.
+	DefaultShadowWindow = 15 * time.Minute
+	// migrationReplayLimit caps the stored calculations replayed on start.
+	migrationReplayLimit = 500
+	// migrationShadowWorkers caps the shadow calculations running at once.
+	migrationShadowWorkers = 4
+	// migrationSamples is how many diverging orders each comparison keeps.
+	migrationSamples = 10
+)
+
+var (
+	// ErrMigrationInProgress is returned when starting a migration while
+	// another one has not been promoted or cancelled.
+	ErrMigrationInProgress = domainerr.New("migration_in_progress", http.StatusConflict, "", "a pack size migration is already in progress")
+	// ErrNoMigration is returned when there is no migration to act on.
+	ErrNoMigration = domainerr.New("migration_not_found", http.StatusNotFound, "", "no pack size migration in progress")
+	// ErrMigrationNotReady is returned when promoting before the shadow
+	// window has ended.
+	ErrMigrationNotReady = domainerr.New("migration_not_ready", http.StatusConflict, "", "the shadow window of the pack size migration has not ended")
+	// ErrMigrationStale is returned when promoting after the active pack
+	// sizes changed, since the comparison no longer applies.
+	ErrMigrationStale = domainerr.New("migration_stale", http.StatusConflict, "", "the active pack sizes changed since the migration started")
+)
+
+// PackSizesMigrator guides a pack size change: it stages the new sizes,
+// replays recent stored calculations with them, compares them with the active
+// sizes on live traffic for a shadow window, then promotes them as the new
+// active configuration. Shadow calculations never delay or change responses.
+// Migrations live in memory, so live traffic is only compared on the replica
+// the migration was started on.
+type PackSizesMigrator struct {
+	packSizes  PackSizesService
+	calculator PackCalculator
+	history    CalculationHistoryService
+	now        func() time.Time
+	slots      chan struct{}
+
+	mu        sync.Mutex
+	migration *dto.PackSizesMigration
+}
+
+// NewPackSizesMigrator creates a PackSizesMigrator. history may be nil, in
+// which case nothing is replayed.
+func NewPackSizesMigrator(packSizes PackSizesService, calculator PackCalculator, history CalculationHistoryService) *PackSizesMigrator {
+	return &PackSizesMigrator{
+		packSizes:  packSizes,
+		calculator: calculator,
+		history:    history,
+		now:        timeutil.Now,
+		slots:      make(chan struct{}, migrationShadowWorkers),
+	}
+}
+
+// Start stages sizes against the active configuration and replays recent
+// stored calculations with them. Live traffic is compared for window, or
+// DefaultShadowWindow when it is not positive.
+func (m *PackSizesMigrator) Start(ctx context.Context, sizes []int, window time.Duration, startedBy string) (dto.PackSizesMigration, error) {
+	if window <= 0 {
+		window = DefaultShadowWindow
+	}
+
+	m.mu.Lock()
+	if m.migration != nil && !m.finished() {
+		m.mu.Unlock()
+		return dto.PackSizesMigration{}, ErrMigrationInProgress
+	}
+	// Reserve the slot while the baseline is loaded
+	now := m.now()
+	migration := &dto.PackSizesMigration{
+		ID:           idgen.NewUUID(),
+		Status:       MigrationShadowing,
+		Sizes:        append([]int(nil), sizes...),
+		StartedBy:    startedBy,
+		StartedAt:    now,
+		ShadowEndsAt: now.Add(window),
+	}
+	previous := m.migration
+	m.migration = migration
+	m.mu.Unlock()
+
+	active, err := m.packSizes.GetActive(ctx)
+	if err == nil && active == nil {
+		err = ErrPackSizesNotFound
+	}
+	if err != nil {
+		m.mu.Lock()
+		m.migration = previous
+		m.mu.Unlock()
+		return dto.PackSizesMigration{}, err
+	}
+
+	replay := m.replay(ctx, active.Version, migration.Sizes)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	migration.BaselineVersion = active.Version
+	migration.BaselineSizes = active.Sizes
+	migration.Replay = replay
+	return m.snapshot(), nil
+}
+
+// replay compares the stored calculations made with the baseline version
+// against sizes.
+func (m *PackSizesMigrator) replay(ctx context.Context, baselineVersion int, sizes []int) dto.MigrationDivergence {
+	var divergence dto.MigrationDivergence
+	if m.history == nil {
+		return divergence
+	}
+	page, err := m.history.List(ctx, dto.CalculationFilter{}, migrationReplayLimit, 0)
+	if err != nil {
+		return divergence
+	}
+	for _, calc := range page.Calculations {
+		if calc.PackSizesVersion != baselineVersion {
+			continue
+		}
+		var constraints model.PackConstraints
+		if calc.Constraints != nil {
+			constraints = *calc.Constraints
+		}
+		current := model.PackResult{OrderedItems: calc.ItemsOrdered, TotalItems: calc.TotalItems, Packs: calc.Packs}
+		staged, err := m.calculate(calc.ItemsOrdered, sizes, constraints)
+		compare(&divergence, current, staged, err)
+	}
+	return divergence
+}
+
+// Observe compares a live calculation made with the stored configuration
+// configVersion against the staged sizes, in the background. It does nothing
+// unless a migration from that version is shadowing, and skips the order when
+// every shadow worker is busy. It is safe to call on a nil PackSizesMigrator.
+func (m *PackSizesMigrator) Observe(itemsOrdered int, constraints model.PackConstraints, configVersion int, current model.PackResult) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	migration := m.migration
+	if migration == nil || migration.Status != MigrationShadowing || migration.BaselineVersion == 0 ||
+		migration.BaselineVersion != configVersion || !m.now().Before(migration.ShadowEndsAt) {
+		m.mu.Unlock()
+		return
+	}
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		migration.Shadow.Skipped++
+		m.mu.Unlock()
+		return
+	}
+	sizes := migration.Sizes
+	m.mu.Unlock()
+
+	go func() {
+		defer func() { <-m.slots }()
+		staged, err := m.calculate(itemsOrdered, sizes, constraints)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		// The migration may have been cancelled meanwhile
+		if m.migration == migration && migration.Status == MigrationShadowing {
+			compare(&migration.Shadow, current, staged, err)
+		}
+	}()
+}
+
+// calculate calculates an order with the staged sizes.
+func (m *PackSizesMigrator) calculate(itemsOrdered int, sizes []int, constraints model.PackConstraints) (model.PackResult, error) {
+	if constraints.IsZero() {
+		return m.calculator.CalculateWithPackSizes(itemsOrdered, sizes), nil
+	}
+	return m.calculator.CalculateWithConstraints(itemsOrdered, sizes, constraints)
+}
+
+// compare adds one order to divergence.
+func compare(divergence *dto.MigrationDivergence, current, staged model.PackResult, err error) {
+	divergence.Compared++
+	diverged := false
+	if err != nil {
+		divergence.Unsatisfiable++
+		diverged = true
+	} else {
+		divergence.ItemsDelta += int64(staged.TotalItems - current.TotalItems)
+		divergence.PacksDelta += int64(staged.PackCount() - current.PackCount())
+		diverged = !samePacks(current, staged)
+	}
+	if diverged {
+		divergence.Diverged++
+		if len(divergence.Samples) < migrationSamples {
+			sample := dto.MigrationSample{ItemsOrdered: current.OrderedItems, Current: &current}
+			if err == nil {
+				sample.Staged = &staged
+			}
+			divergence.Samples = append(divergence.Samples, sample)
+		}
+	}
+	divergence.DivergenceRate = float64(divergence.Diverged) / float64(divergence.Compared)
+}
+
+// samePacks reports whether two results ship the same packs.
+func samePacks(a, b model.PackResult) bool {
+	if a.TotalItems != b.TotalItems || len(a.Packs) != len(b.Packs) {
+		return false
+	}
+	for i := range a.Packs {
+		if a.Packs[i] != b.Packs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Status returns the current or last migration, or ErrNoMigration.
+func (m *PackSizesMigrator) Status() (dto.PackSizesMigration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.migration == nil {
+		return dto.PackSizesMigration{}, ErrNoMigration
+	}
+	return m.snapshot(), nil
+}
+
+// Promote stores the staged sizes as the new active configuration once the
+// shadow window has ended, or right away with force. It fails with
+// ErrMigrationStale if the active configuration is no longer the baseline.
+func (m *PackSizesMigrator) Promote(ctx context.Context, promotedBy string, force bool) (*repository.PackSizeConfig, dto.PackSizesMigration, error) {
+	m.mu.Lock()
+	migration := m.migration
+	if migration == nil || m.finished() || migration.Status == MigrationPromoting {
+		m.mu.Unlock()
+		return nil, dto.PackSizesMigration{}, ErrNoMigration
+	}
+	if !force && m.now().Before(migration.ShadowEndsAt) {
+		m.mu.Unlock()
+		return nil, dto.PackSizesMigration{}, ErrMigrationNotReady
+	}
+	migration.Status = MigrationPromoting
+	m.mu.Unlock()
+
+	config, err := m.promote(ctx, migration, promotedBy)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		migration.Status = MigrationShadowing
+		return nil, dto.PackSizesMigration{}, err
+	}
+	finishedAt := m.now()
+	migration.Status = MigrationPromoted
+	migration.PromotedVersion = config.Version
+	migration.FinishedAt = &finishedAt
+	return config, m.snapshot(), nil
+}
+
+// promote checks the baseline is still active and stores the staged sizes.
+func (m *PackSizesMigrator) promote(ctx context.Context, migration *dto.PackSizesMigration, promotedBy string) (*repository.PackSizeConfig, error) {
+	active, err := m.packSizes.GetActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil || active.Version != migration.BaselineVersion {
+		return nil, ErrMigrationStale
+	}
+	return m.packSizes.Create(ctx, migration.Sizes, nil, promotedBy)
+}
+
+// Cancel abandons the migration, leaving the active configuration as it is.
+func (m *PackSizesMigrator) Cancel() (dto.PackSizesMigration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.migration == nil || m.finished() || m.migration.Status == MigrationPromoting {
+		return dto.PackSizesMigration{}, ErrNoMigration
+	}
+	finishedAt := m.now()
+	m.migration.Status = MigrationCancelled
+	m.migration.FinishedAt = &finishedAt
+	return m.snapshot(), nil
+}
+
+// finished reports whether the migration was promoted or cancelled. The
+// caller must hold m.mu.
+func (m *PackSizesMigrator) finished() bool {
+	return m.migration.Status == MigrationPromoted || m.migration.Status == MigrationCancelled
+}
+
+// snapshot copies the migration, reporting a shadowing migration whose window
+// has ended as ready. The caller must hold m.mu.
+func (m *PackSizesMigrator) snapshot() dto.PackSizesMigration {
+	migration := *m.migration
+	migration.Replay.Samples = append([]dto.MigrationSample(nil), migration.Replay.Samples...)
+	migration.Shadow.Samples = append([]dto.MigrationSample(nil), migration.Shadow.Samples...)
+	if migration.Status == MigrationShadowing && !m.now().Before(migration.ShadowEndsAt) {
+		migration.Status = MigrationReady
+	}
+	return migration
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+// fakePackSizes keeps one active configuration in memory.
+type fakePackSizes struct {
+	PackSizesService
+	active *repository.PackSizeConfig
+}
+
+func (f *fakePackSizes) GetActive(context.Context) (*repository.PackSizeConfig, error) {
+	return f.active, nil
+}
+
+func (f *fakePackSizes) Create(_ context.Context, sizes []int, _ map[int]float64, createdBy string) (*repository.PackSizeConfig, error) {
+	f.active = &repository.PackSizeConfig{Sizes: sizes, Version: f.active.Version + 1, Active: true, CreatedBy: createdBy}
+	return f.active, nil
+}
+
+// fakeHistory returns the same stored calculations for every listing.
+type fakeHistory struct {
+	CalculationHistoryService
+	calculations []*model.Calculation
+}
+
+func (f *fakeHistory) List(context.Context, dto.CalculationFilter, int, int) (*dto.CalculationPage, error) {
+	return &dto.CalculationPage{Calculations: f.calculations}, nil
+}
+
+func newTestMigrator(t *testing.T, history CalculationHistoryService) (*PackSizesMigrator, *fakePackSizes, *time.Time) {
+	t.Helper()
+	packSizes := &fakePackSizes{active: &repository.PackSizeConfig{Sizes: []int{250, 500, 1000}, Version: 3, Active: true}}
+	migrator := NewPackSizesMigrator(packSizes, NewPackCalculatorService(), history)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	migrator.now = func() time.Time { return now }
+	return migrator, packSizes, &now
+}
+
+func TestPackSizesMigrator_StartReplaysHistory(t *testing.T) {
+	calculator := NewPackCalculatorService()
+	stored := func(items, version int) *model.Calculation {
+		result := calculator.CalculateWithPackSizes(items, []int{250, 500, 1000})
+		return &model.Calculation{ItemsOrdered: items, PackSizesVersion: version, TotalItems: result.TotalItems, Packs: result.Packs}
+	}
+	history := &fakeHistory{calculations: []*model.Calculation{
+		stored(500, 3),  // same with the staged sizes
+		stored(750, 3),  // one 750 pack instead of 500 + 250
+		stored(1000, 2), // older version, not replayed
+	}}
+	migrator, _, _ := newTestMigrator(t, history)
+
+	migration, err := migrator.Start(context.Background(), []int{250, 500, 750, 1000}, time.Minute, "admin")
+	require.NoError(t, err)
+
+	assert.Equal(t, MigrationShadowing, migration.Status)
+	assert.Equal(t, 3, migration.BaselineVersion)
+	assert.Equal(t, []int{250, 500, 1000}, migration.BaselineSizes)
+	assert.Equal(t, int64(2), migration.Replay.Compared)
+	assert.Equal(t, int64(1), migration.Replay.Diverged)
+	assert.Equal(t, 0.5, migration.Replay.DivergenceRate)
+	assert.Equal(t, int64(-1), migration.Replay.PacksDelta)
+	require.Len(t, migration.Replay.Samples, 1)
+	assert.Equal(t, 750, migration.Replay.Samples[0].ItemsOrdered)
+
+	_, err = migrator.Start(context.Background(), []int{100}, time.Minute, "admin")
+	assert.ErrorIs(t, err, ErrMigrationInProgress)
+}
+
+func TestPackSizesMigrator_Observe(t *testing.T) {
+	migrator, _, now := newTestMigrator(t, nil)
+	_, err := migrator.Start(context.Background(), []int{250, 500, 750, 1000}, time.Minute, "admin")
+	require.NoError(t, err)
+
+	current := NewPackCalculatorService().CalculateWithPackSizes(750, []int{250, 500, 1000})
+	migrator.Observe(750, model.PackConstraints{}, 3, current)
+	migrator.Observe(750, model.PackConstraints{}, 2, current) // other version, ignored
+
+	require.Eventually(t, func() bool {
+		migration, err := migrator.Status()
+		return err == nil && migration.Shadow.Compared == 1
+	}, time.Second, time.Millisecond)
+	migration, err := migrator.Status()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), migration.Shadow.Diverged)
+	assert.Equal(t, int64(0), migration.Shadow.ItemsDelta)
+
+	// Nothing is compared once the window has ended
+	*now = now.Add(time.Minute)
+	migrator.Observe(750, model.PackConstraints{}, 3, current)
+	migration, err = migrator.Status()
+	require.NoError(t, err)
+	assert.Equal(t, MigrationReady, migration.Status)
+	assert.Equal(t, int64(1), migration.Shadow.Compared)
+}
+
+func TestPackSizesMigrator_Promote(t *testing.T) {
+	t.Run("promotes after the shadow window", func(t *testing.T) {
+		migrator, packSizes, now := newTestMigrator(t, nil)
+		_, err := migrator.Start(context.Background(), []int{250, 500, 750, 1000}, time.Minute, "admin")
+		require.NoError(t, err)
+
+		_, _, err = migrator.Promote(context.Background(), "admin", false)
+		assert.ErrorIs(t, err, ErrMigrationNotReady)
+
+		*now = now.Add(time.Minute)
+		config, migration, err := migrator.Promote(context.Background(), "admin", false)
+		require.NoError(t, err)
+		assert.Equal(t, 4, config.Version)
+		assert.Equal(t, []int{250, 500, 750, 1000}, packSizes.active.Sizes)
+		assert.Equal(t, MigrationPromoted, migration.Status)
+		assert.Equal(t, 4, migration.PromotedVersion)
+		assert.NotNil(t, migration.FinishedAt)
+
+		// A finished migration can be replaced by a new one
+		_, err = migrator.Start(context.Background(), []int{500}, time.Minute, "admin")
+		assert.NoError(t, err)
+	})
+
+	t.Run("force promotes during the shadow window", func(t *testing.T) {
+		migrator, _, _ := newTestMigrator(t, nil)
+		_, err := migrator.Start(context.Background(), []int{500}, time.Minute, "admin")
+		require.NoError(t, err)
+
+		_, migration, err := migrator.Promote(context.Background(), "admin", true)
+		require.NoError(t, err)
+		assert.Equal(t, MigrationPromoted, migration.Status)
+	})
+
+	t.Run("refuses when the active configuration changed", func(t *testing.T) {
+		migrator, packSizes, _ := newTestMigrator(t, nil)
+		_, err := migrator.Start(context.Background(), []int{500}, time.Minute, "admin")
+		require.NoError(t, err)
+		packSizes.active = &repository.PackSizeConfig{Sizes: []int{100}, Version: 4, Active: true}
+
+		_, _, err = migrator.Promote(context.Background(), "admin", true)
+		assert.ErrorIs(t, err, ErrMigrationStale)
+		migration, err := migrator.Status()
+		require.NoError(t, err)
+		assert.Equal(t, MigrationShadowing, migration.Status)
+	})
+}
+
+func TestPackSizesMigrator_Cancel(t *testing.T) {
+	migrator, packSizes, _ := newTestMigrator(t, nil)
+
+	_, err := migrator.Status()
+	assert.ErrorIs(t, err, ErrNoMigration)
+	_, err = migrator.Cancel()
+	assert.ErrorIs(t, err, ErrNoMigration)
+
+	_, err = migrator.Start(context.Background(), []int{500}, time.Minute, "admin")
+	require.NoError(t, err)
+	migration, err := migrator.Cancel()
+	require.NoError(t, err)
+	assert.Equal(t, MigrationCancelled, migration.Status)
+	assert.Equal(t, 3, packSizes.active.Version)
+
+	_, _, err = migrator.Promote(context.Background(), "admin", true)
+	assert.ErrorIs(t, err, ErrNoMigration)
+}
+
+func TestPackSizesMigrator_StartWithoutActiveConfig(t *testing.T) {
+	migrator, packSizes, _ := newTestMigrator(t, nil)
+	packSizes.active = nil
+
+	_, err := migrator.Start(context.Background(), []int{500}, time.Minute, "admin")
+	assert.ErrorIs(t, err, ErrPackSizesNotFound)
+
+	_, err = migrator.Status()
+	assert.ErrorIs(t, err, ErrNoMigration)
+}
+
+func TestPackSizesMigrator_ObserveNil(t *testing.T) {
+	var migrator *PackSizesMigrator
+	assert.NotPanics(t, func() {
+		migrator.Observe(250, model.PackConstraints{}, 1, model.PackResult{})
+	})
+}
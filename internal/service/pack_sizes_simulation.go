@@ -0,0 +1,97 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+)
+
+// simulationWorstCases is how many orders with the most overage each
+// simulated configuration reports.
+const simulationWorstCases = 5
+
+// SimulatePackSizes calculates every order with sizesA and with sizesB and
+// compares the totals. Orders are weighted by their count.
+func SimulatePackSizes(calculator PackCalculator, sizesA, sizesB []int, orders []dto.SimulationOrder) dto.PackSizesSimulation {
+	simulation := dto.PackSizesSimulation{
+		A: dto.SimulationStats{Sizes: sizesA},
+		B: dto.SimulationStats{Sizes: sizesB},
+	}
+	for _, order := range orders {
+		a := simulationCase(calculator, order.ItemsOrdered, sizesA)
+		b := simulationCase(calculator, order.ItemsOrdered, sizesB)
+		addSimulationCase(&simulation.A, a, order.Count)
+		addSimulationCase(&simulation.B, b, order.Count)
+
+		switch {
+		case worseCase(b, a):
+			simulation.BetterWithA += order.Count
+		case worseCase(a, b):
+			simulation.BetterWithB += order.Count
+		}
+		if !slices.Equal(a.Packs, b.Packs) {
+			simulation.DifferingOrders += order.Count
+		}
+	}
+	finishSimulationStats(&simulation.A)
+	finishSimulationStats(&simulation.B)
+	return simulation
+}
+
+// simulationCase calculates one order with sizes.
+func simulationCase(calculator PackCalculator, itemsOrdered int, sizes []int) dto.SimulationCase {
+	result := calculator.CalculateWithPackSizes(itemsOrdered, sizes)
+	return dto.SimulationCase{
+		ItemsOrdered: itemsOrdered,
+		OverageItems: result.TotalItems - result.OrderedItems,
+		PackCount:    result.PackCount(),
+		Packs:        result.Packs,
+		Approximate:  result.Approximate,
+	}
+}
+
+// addSimulationCase adds count orders filled as c to stats, keeping the
+// worst cases sorted by overage, then by pack count.
+func addSimulationCase(stats *dto.SimulationStats, c dto.SimulationCase, count int64) {
+	stats.Orders += count
+	stats.ItemsOrdered += int64(c.ItemsOrdered) * count
+	stats.OverageItems += int64(c.OverageItems) * count
+	stats.Packs += int64(c.PackCount) * count
+	stats.MaxOverageItems = max(stats.MaxOverageItems, c.OverageItems)
+	stats.MaxPacks = max(stats.MaxPacks, c.PackCount)
+	if c.Approximate {
+		stats.Approximate += count
+	}
+
+	i := slices.IndexFunc(stats.WorstCases, func(w dto.SimulationCase) bool { return worseCase(c, w) })
+	if i < 0 {
+		i = len(stats.WorstCases)
+	}
+	if i < simulationWorstCases {
+		stats.WorstCases = slices.Insert(stats.WorstCases, i, c)
+		if len(stats.WorstCases) > simulationWorstCases {
+			stats.WorstCases = stats.WorstCases[:simulationWorstCases]
+		}
+	}
+}
+
+// worseCase reports whether a ships more overage than b, or as much in more
+// packs.
+func worseCase(a, b dto.SimulationCase) bool {
+	if a.OverageItems != b.OverageItems {
+		return a.OverageItems > b.OverageItems
+	}
+	return a.PackCount > b.PackCount
+}
+
+// finishSimulationStats derives the totals and averages of stats.
+func finishSimulationStats(stats *dto.SimulationStats) {
+	stats.ItemsShipped = stats.ItemsOrdered + stats.OverageItems
+	if stats.Orders > 0 {
+		stats.AverageOverageItems = float64(stats.OverageItems) / float64(stats.Orders)
+		stats.AveragePacks = float64(stats.Packs) / float64(stats.Orders)
+	}
+	if stats.ItemsOrdered > 0 {
+		stats.OveragePercent = float64(stats.OverageItems) * 100 / float64(stats.ItemsOrdered)
+	}
+}
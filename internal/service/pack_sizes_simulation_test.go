@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatePackSizes(t *testing.T) {
+	calculator := NewPackCalculatorService()
+	orders := []dto.SimulationOrder{
+		{ItemsOrdered: 251, Count: 1},
+		{ItemsOrdered: 751, Count: 3},
+		{ItemsOrdered: 1000, Count: 2},
+	}
+
+	simulation := SimulatePackSizes(calculator, []int{250, 500, 1000}, []int{250, 500, 800}, orders)
+
+	a := simulation.A
+	assert.Equal(t, []int{250, 500, 1000}, a.Sizes)
+	assert.Equal(t, int64(6), a.Orders)
+	assert.Equal(t, int64(251+3*751+2*1000), a.ItemsOrdered)
+	// 251 -> 500, 751 -> 1000, 1000 -> 1000
+	assert.Equal(t, int64(249+3*249), a.OverageItems)
+	assert.Equal(t, a.ItemsOrdered+a.OverageItems, a.ItemsShipped)
+	assert.Equal(t, int64(6), a.Packs)
+	assert.InDelta(t, 1, a.AveragePacks, 1e-9)
+	assert.InDelta(t, float64(4*249)/6, a.AverageOverageItems, 1e-9)
+	assert.Equal(t, 249, a.MaxOverageItems)
+	assert.Len(t, a.WorstCases, 3)
+	assert.Equal(t, 0, a.WorstCases[2].OverageItems)
+
+	b := simulation.B
+	// 251 -> 500, 751 -> 800, 1000 -> 800 + 250 = 1050 or 500 + 500 = 1000
+	assert.Equal(t, int64(249+3*49), b.OverageItems)
+	assert.Equal(t, 2, b.MaxPacks)
+	assert.Equal(t, dto.SimulationCase{ItemsOrdered: 251, OverageItems: 249, PackCount: 1, Packs: []model.Pack{{Size: 500, Quantity: 1}}}, b.WorstCases[0])
+
+	assert.Equal(t, int64(5), simulation.DifferingOrders)
+	assert.Equal(t, int64(2), simulation.BetterWithA)
+	assert.Equal(t, int64(3), simulation.BetterWithB)
+}
+
+func TestSimulatePackSizes_KeepsWorstCases(t *testing.T) {
+	var orders []dto.SimulationOrder
+	for items := 1; items <= 20; items++ {
+		orders = append(orders, dto.SimulationOrder{ItemsOrdered: items, Count: 1})
+	}
+
+	simulation := SimulatePackSizes(NewPackCalculatorService(), []int{10}, []int{1}, orders)
+
+	assert.Len(t, simulation.A.WorstCases, simulationWorstCases)
+	for i, items := range []int{11, 1, 12, 2, 13} {
+		assert.Equal(t, items, simulation.A.WorstCases[i].ItemsOrdered)
+	}
+	assert.Equal(t, 9, simulation.A.MaxOverageItems)
+	assert.Zero(t, simulation.B.OverageItems)
+}
@@ -114,7 +114,7 @@ func TestPackSizesService_Create(t *testing.T) {
 					UpdatedAt: time.Now(),
 					CreatedBy: "admin@example.com",
 				}
-				m.On("Create", mock.Anything, []int{100, 250, 500}, "admin@example.com").Return(config, nil)
+				m.On("Create", mock.Anything, []int{100, 250, 500}, mock.Anything, "admin@example.com").Return(config, nil)
 			},
 			expectedError: nil,
 		},
@@ -123,7 +123,7 @@ func TestPackSizesService_Create(t *testing.T) {
 			sizes:     []int{100, 250},
 			createdBy: "user@example.com",
 			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
-				m.On("Create", mock.Anything, []int{100, 250}, "user@example.com").Return(nil, errors.New("duplicate key"))
+				m.On("Create", mock.Anything, []int{100, 250}, mock.Anything, "user@example.com").Return(nil, errors.New("duplicate key"))
 			},
 			expectedError: errors.New("duplicate key"),
 		},
@@ -135,7 +135,7 @@ func TestPackSizesService_Create(t *testing.T) {
 			tt.setupMock(mockRepo)
 
 			svc := service.NewPackSizesService(mockRepo)
-			config, err := svc.Create(context.Background(), tt.sizes, tt.createdBy)
+			config, err := svc.Create(context.Background(), tt.sizes, nil, tt.createdBy)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -154,13 +154,40 @@ func TestPackSizesService_Create(t *testing.T) {
 
 func TestPackSizesService_Create_NilRepository(t *testing.T) {
 	svc := service.NewPackSizesService(nil)
-	config, err := svc.Create(context.Background(), []int{100, 250}, "admin")
+	config, err := svc.Create(context.Background(), []int{100, 250}, nil, "admin")
 
 	assert.Error(t, err)
 	assert.Equal(t, service.ErrRepositoryNotConfigured, err)
 	assert.Nil(t, config)
 }
 
+func TestPackSizesService_CreateIfActive(t *testing.T) {
+	t.Run("creates while the version is active", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("CreateIfActive", mock.Anything, []int{250, 500}, mock.Anything, "admin", 3).
+			Return(&repository.PackSizeConfig{Sizes: []int{250, 500}, Active: true, Version: 4}, nil)
+
+		config, err := service.NewPackSizesService(mockRepo).CreateIfActive(context.Background(), []int{250, 500}, nil, "admin", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, config.Version)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("active version changed", func(t *testing.T) {
+		mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+		mockRepo.On("CreateIfActive", mock.Anything, []int{250, 500}, mock.Anything, "admin", 3).Return(nil, nil)
+
+		config, err := service.NewPackSizesService(mockRepo).CreateIfActive(context.Background(), []int{250, 500}, nil, "admin", 3)
+		assert.ErrorIs(t, err, service.ErrPackSizesChanged)
+		assert.Nil(t, config)
+	})
+
+	t.Run("nil repository", func(t *testing.T) {
+		_, err := service.NewPackSizesService(nil).CreateIfActive(context.Background(), []int{250}, nil, "admin", 1)
+		assert.Equal(t, service.ErrRepositoryNotConfigured, err)
+	})
+}
+
 func TestPackSizesService_Update(t *testing.T) {
 	testID := primitive.NewObjectID()
 
@@ -304,3 +331,203 @@ func TestPackSizesService_List_NilRepository(t *testing.T) {
 	assert.Equal(t, service.ErrRepositoryNotConfigured, err)
 	assert.Nil(t, configs)
 }
+
+func TestPackSizesService_Activate(t *testing.T) {
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name          string
+		setupMock     func(*mocks.MockPackSizesRepositoryInterface)
+		expectedError error
+	}{
+		{
+			name: "successful activate",
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("Activate", mock.Anything, id, "admin").Return(&repository.PackSizeConfig{ID: id, Active: true, Version: 2}, nil)
+			},
+		},
+		{
+			name: "unknown configuration",
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("Activate", mock.Anything, id, "admin").Return(nil, nil)
+			},
+			expectedError: service.ErrPackSizesNotFound,
+		},
+		{
+			name: "repository error",
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("Activate", mock.Anything, id, "admin").Return(nil, assert.AnError)
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewPackSizesService(mockRepo)
+			config, err := svc.Activate(context.Background(), id, "admin")
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, config)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, config.Active)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPackSizesService_WithCalculatorInvalidation(t *testing.T) {
+	id := primitive.NewObjectID()
+	active := &repository.PackSizeConfig{ID: id, Sizes: []int{500, 250}, Active: true, Version: 2}
+	inactive := &repository.PackSizeConfig{ID: id, Sizes: []int{500, 250}, Version: 1}
+
+	tests := []struct {
+		name            string
+		call            func(service.PackSizesService) error
+		setupMock       func(*mocks.MockPackSizesRepositoryInterface)
+		wantErr         error
+		wantInvalidated bool
+	}{
+		{
+			name: "activate",
+			call: func(svc service.PackSizesService) error {
+				_, err := svc.Activate(context.Background(), id, "admin")
+				return err
+			},
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("Activate", mock.Anything, id, "admin").Return(active, nil)
+			},
+			wantInvalidated: true,
+		},
+		{
+			name: "create",
+			call: func(svc service.PackSizesService) error {
+				_, err := svc.Create(context.Background(), []int{500, 250}, nil, "admin")
+				return err
+			},
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("Create", mock.Anything, []int{500, 250}, mock.Anything, "admin").Return(active, nil)
+			},
+			wantInvalidated: true,
+		},
+		{
+			name: "update of an inactive configuration",
+			call: func(svc service.PackSizesService) error {
+				_, err := svc.Update(context.Background(), id, []int{500, 250}, "admin")
+				return err
+			},
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("Update", mock.Anything, id, []int{500, 250}, "admin").Return(inactive, nil)
+			},
+		},
+		{
+			name: "failed activation",
+			call: func(svc service.PackSizesService) error {
+				_, err := svc.Activate(context.Background(), id, "admin")
+				return err
+			},
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("Activate", mock.Anything, id, "admin").Return(nil, assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+			tt.setupMock(mockRepo)
+			calculator := service.NewPackCalculatorService(service.WithCache(10, time.Minute))
+			calculator.Calculate(251)
+
+			svc := service.NewPackSizesService(mockRepo, service.WithCalculatorInvalidation(calculator))
+			err := tt.call(svc)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, !tt.wantInvalidated, calculator.Calculate(251).CacheHit)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPackSizesService_Rollback(t *testing.T) {
+	previousID := primitive.NewObjectID()
+	tests := []struct {
+		name            string
+		setupMock       func(*mocks.MockPackSizesRepositoryInterface)
+		expectedError   error
+		expectedVersion int
+	}{
+		{
+			name: "activates the previous version",
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("GetActive", mock.Anything).Return(&repository.PackSizeConfig{Version: 3, Active: true}, nil)
+				m.On("PreviousVersion", mock.Anything, 3).Return(&repository.PackSizeConfig{ID: previousID, Version: 2}, nil)
+				m.On("Activate", mock.Anything, previousID, "admin").Return(&repository.PackSizeConfig{ID: previousID, Version: 2, Active: true}, nil)
+			},
+			expectedVersion: 2,
+		},
+		{
+			name: "no active configuration",
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("GetActive", mock.Anything).Return(nil, nil)
+			},
+			expectedError: service.ErrNoPreviousPackSizes,
+		},
+		{
+			name: "active configuration is the first",
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("GetActive", mock.Anything).Return(&repository.PackSizeConfig{Version: 1, Active: true}, nil)
+				m.On("PreviousVersion", mock.Anything, 1).Return(nil, nil)
+			},
+			expectedError: service.ErrNoPreviousPackSizes,
+		},
+		{
+			name: "repository error",
+			setupMock: func(m *mocks.MockPackSizesRepositoryInterface) {
+				m.On("GetActive", mock.Anything).Return(nil, assert.AnError)
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockPackSizesRepositoryInterface)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewPackSizesService(mockRepo)
+			config, err := svc.Rollback(context.Background(), "admin")
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, config)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVersion, config.Version)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPackSizesService_Activate_NilRepository(t *testing.T) {
+	svc := service.NewPackSizesService(nil)
+
+	_, err := svc.Activate(context.Background(), primitive.NewObjectID(), "admin")
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+
+	_, err = svc.Rollback(context.Background(), "admin")
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+// packSizesWatchRetry is the pause before reopening a failed change stream.
+const packSizesWatchRetry = 30 * time.Second
+
+// WatchPackSizes calls invalidate whenever any replica changes the stored pack
+// sizes, until ctx is done, reopening the stream after failures. Callers use
+// it to drop the pack sizes and results they cache, so every replica picks up
+// a change within moments instead of when its cache expires. Without change
+// streams (a standalone MongoDB), other replicas' changes show up on expiry.
+func WatchPackSizes(ctx context.Context, watcher repository.PackSizesWatcher, invalidate func()) {
+	go func() {
+		for failures := 0; ; failures++ {
+			err := watcher.WatchPackSizes(ctx, invalidate)
+			if ctx.Err() != nil {
+				return
+			}
+			event := log.Debug()
+			if failures == 0 {
+				event = log.Warn()
+			}
+			event.Err(err).Dur("retry_in", packSizesWatchRetry).
+				Msg("Pack sizes change stream stopped, relying on cache TTL")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(packSizesWatchRetry):
+			}
+		}
+	}()
+}
@@ -0,0 +1,42 @@
+package service_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// fakePackSizesWatcher reports a change for every value sent on changes.
+type fakePackSizesWatcher struct {
+	changes chan struct{}
+}
+
+func (w *fakePackSizesWatcher) WatchPackSizes(ctx context.Context, fn func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.changes:
+			fn()
+		}
+	}
+}
+
+func TestWatchPackSizes(t *testing.T) {
+	watcher := &fakePackSizesWatcher{changes: make(chan struct{})}
+	var invalidations atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.WatchPackSizes(ctx, watcher, func() { invalidations.Add(1) })
+
+	// Changes made by other replicas
+	watcher.changes <- struct{}{}
+	watcher.changes <- struct{}{}
+	assert.Eventually(t, func() bool { return invalidations.Load() == 2 }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+// maxPresetPackSizes caps the number of pack sizes stored in one preset.
+const maxPresetPackSizes = 50
+
+// presetNamePattern restricts preset names to URL-safe identifiers.
+var presetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+var (
+	// ErrPresetNotFound is returned when a preset does not exist for the owner.
+	ErrPresetNotFound = domainerr.New("preset_not_found", http.StatusNotFound, i18n.ErrKeyPresetNotFound, "preset not found")
+	// ErrPresetExists is returned when the owner already has a preset with the name.
+	ErrPresetExists = repository.ErrPresetExists
+	// ErrInvalidPreset is returned when a preset fails validation.
+	ErrInvalidPreset = domainerr.New("invalid_preset", http.StatusBadRequest, "", "invalid preset")
+)
+
+// PresetService manages named calculation presets. Presets are scoped to an
+// owner (user ID); the empty owner holds presets shared by unauthenticated clients.
+type PresetService interface {
+	Create(ctx context.Context, owner string, preset *model.Preset) (*model.Preset, error)
+	Get(ctx context.Context, owner, name string) (*model.Preset, error)
+	List(ctx context.Context, owner string) ([]*model.Preset, error)
+	Update(ctx context.Context, owner, name, description string, packSizes []int, constraints *model.PackConstraints) (*model.Preset, error)
+	Delete(ctx context.Context, owner, name string) error
+}
+
+// PresetServiceImpl implements PresetService.
+type PresetServiceImpl struct {
+	presetRepo repository.PresetRepositoryInterface
+}
+
+// NewPresetService creates a new preset service.
+func NewPresetService(presetRepo repository.PresetRepositoryInterface) PresetService {
+	return &PresetServiceImpl{
+		presetRepo: presetRepo,
+	}
+}
+
+// Create validates and stores a new preset for owner.
+func (s *PresetServiceImpl) Create(ctx context.Context, owner string, preset *model.Preset) (*model.Preset, error) {
+	if s.presetRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if err := validatePresetName(preset.Name); err != nil {
+		return nil, err
+	}
+	if err := validatePresetPackSizes(preset.PackSizes); err != nil {
+		return nil, err
+	}
+
+	preset.Owner = owner
+	if err := s.presetRepo.Create(ctx, preset); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// Get returns owner's preset with the given name, or ErrPresetNotFound.
+func (s *PresetServiceImpl) Get(ctx context.Context, owner, name string) (*model.Preset, error) {
+	if s.presetRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	preset, err := s.presetRepo.FindByName(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if preset == nil {
+		return nil, ErrPresetNotFound
+	}
+	return preset, nil
+}
+
+// List returns all presets of owner.
+func (s *PresetServiceImpl) List(ctx context.Context, owner string) ([]*model.Preset, error) {
+	if s.presetRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	return s.presetRepo.ListByOwner(ctx, owner)
+}
+
+// Update replaces the description, pack sizes and constraints of owner's
+// preset.
+func (s *PresetServiceImpl) Update(ctx context.Context, owner, name, description string, packSizes []int, constraints *model.PackConstraints) (*model.Preset, error) {
+	if err := validatePresetPackSizes(packSizes); err != nil {
+		return nil, err
+	}
+
+	preset, err := s.Get(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	preset.Description = description
+	preset.PackSizes = packSizes
+	preset.Constraints = constraints
+	if err := s.presetRepo.Update(ctx, preset); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// Delete removes owner's preset, or returns ErrPresetNotFound.
+func (s *PresetServiceImpl) Delete(ctx context.Context, owner, name string) error {
+	if s.presetRepo == nil {
+		return ErrRepositoryNotConfigured
+	}
+	deleted, err := s.presetRepo.Delete(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPresetNotFound
+	}
+	return nil
+}
+
+func validatePresetName(name string) error {
+	if !presetNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: name must be 1-64 letters, digits, '.', '_' or '-' and start with a letter or digit", ErrInvalidPreset)
+	}
+	return nil
+}
+
+func validatePresetPackSizes(sizes []int) error {
+	if len(sizes) == 0 || len(sizes) > maxPresetPackSizes {
+		return fmt.Errorf("%w: pack_sizes must contain 1-%d sizes", ErrInvalidPreset, maxPresetPackSizes)
+	}
+	seen := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		if size <= 0 {
+			return fmt.Errorf("%w: pack sizes must be positive", ErrInvalidPreset)
+		}
+		if seen[size] {
+			return fmt.Errorf("%w: pack size %d is listed more than once", ErrInvalidPreset, size)
+		}
+		seen[size] = true
+	}
+	return nil
+}
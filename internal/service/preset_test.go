@@ -0,0 +1,200 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestPresetService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		preset        *model.Preset
+		setupMock     func(*mocks.MockPresetRepositoryInterface)
+		expectedError error
+	}{
+		{
+			name:   "successful create",
+			preset: &model.Preset{Name: "warehouse-a", PackSizes: []int{23, 31, 53}},
+			setupMock: func(m *mocks.MockPresetRepositoryInterface) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(p *model.Preset) bool {
+					return p.Owner == "user-1" && p.Name == "warehouse-a"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:          "invalid name",
+			preset:        &model.Preset{Name: "bad name!", PackSizes: []int{23}},
+			setupMock:     func(m *mocks.MockPresetRepositoryInterface) {},
+			expectedError: service.ErrInvalidPreset,
+		},
+		{
+			name:          "name too long",
+			preset:        &model.Preset{Name: strings.Repeat("a", 65), PackSizes: []int{23}},
+			setupMock:     func(m *mocks.MockPresetRepositoryInterface) {},
+			expectedError: service.ErrInvalidPreset,
+		},
+		{
+			name:          "no pack sizes",
+			preset:        &model.Preset{Name: "empty"},
+			setupMock:     func(m *mocks.MockPresetRepositoryInterface) {},
+			expectedError: service.ErrInvalidPreset,
+		},
+		{
+			name:          "non-positive pack size",
+			preset:        &model.Preset{Name: "zero", PackSizes: []int{23, 0}},
+			setupMock:     func(m *mocks.MockPresetRepositoryInterface) {},
+			expectedError: service.ErrInvalidPreset,
+		},
+		{
+			name:          "duplicate pack size",
+			preset:        &model.Preset{Name: "dupes", PackSizes: []int{23, 23}},
+			setupMock:     func(m *mocks.MockPresetRepositoryInterface) {},
+			expectedError: service.ErrInvalidPreset,
+		},
+		{
+			name:   "name already taken",
+			preset: &model.Preset{Name: "warehouse-a", PackSizes: []int{23}},
+			setupMock: func(m *mocks.MockPresetRepositoryInterface) {
+				m.On("Create", mock.Anything, mock.Anything).Return(repository.ErrPresetExists).Once()
+			},
+			expectedError: service.ErrPresetExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewPresetService(mockRepo)
+			preset, err := svc.Create(context.Background(), "user-1", tt.preset)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, preset)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", preset.Owner)
+		})
+	}
+}
+
+func TestPresetService_Get(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+		expected := &model.Preset{Owner: "user-1", Name: "warehouse-a", PackSizes: []int{23}}
+		mockRepo.On("FindByName", mock.Anything, "user-1", "warehouse-a").Return(expected, nil).Once()
+
+		preset, err := service.NewPresetService(mockRepo).Get(context.Background(), "user-1", "warehouse-a")
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, preset)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+		mockRepo.On("FindByName", mock.Anything, "user-1", "missing").Return(nil, nil).Once()
+
+		_, err := service.NewPresetService(mockRepo).Get(context.Background(), "user-1", "missing")
+
+		assert.ErrorIs(t, err, service.ErrPresetNotFound)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+		mockRepo.On("FindByName", mock.Anything, "user-1", "warehouse-a").Return(nil, errors.New("database error")).Once()
+
+		_, err := service.NewPresetService(mockRepo).Get(context.Background(), "user-1", "warehouse-a")
+
+		assert.EqualError(t, err, "database error")
+	})
+}
+
+func TestPresetService_List(t *testing.T) {
+	mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+	expected := []*model.Preset{{Name: "a"}, {Name: "b"}}
+	mockRepo.On("ListByOwner", mock.Anything, "user-1").Return(expected, nil).Once()
+
+	presets, err := service.NewPresetService(mockRepo).List(context.Background(), "user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, presets)
+}
+
+func TestPresetService_Update(t *testing.T) {
+	t.Run("successful update", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+		existing := &model.Preset{Owner: "user-1", Name: "warehouse-a", PackSizes: []int{23}}
+		mockRepo.On("FindByName", mock.Anything, "user-1", "warehouse-a").Return(existing, nil).Once()
+		mockRepo.On("Update", mock.Anything, existing).Return(nil).Once()
+
+		preset, err := service.NewPresetService(mockRepo).Update(context.Background(), "user-1", "warehouse-a", "new", []int{31, 53}, &model.PackConstraints{Optimize: model.OptimizeMinCost})
+
+		require.NoError(t, err)
+		assert.Equal(t, "new", preset.Description)
+		assert.Equal(t, []int{31, 53}, preset.PackSizes)
+		assert.Equal(t, &model.PackConstraints{Optimize: model.OptimizeMinCost}, preset.Constraints)
+	})
+
+	t.Run("invalid pack sizes", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+
+		_, err := service.NewPresetService(mockRepo).Update(context.Background(), "user-1", "warehouse-a", "", nil, nil)
+
+		assert.ErrorIs(t, err, service.ErrInvalidPreset)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+		mockRepo.On("FindByName", mock.Anything, "user-1", "missing").Return(nil, nil).Once()
+
+		_, err := service.NewPresetService(mockRepo).Update(context.Background(), "user-1", "missing", "", []int{23}, nil)
+
+		assert.ErrorIs(t, err, service.ErrPresetNotFound)
+	})
+}
+
+func TestPresetService_Delete(t *testing.T) {
+	t.Run("deleted", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+		mockRepo.On("Delete", mock.Anything, "user-1", "warehouse-a").Return(true, nil).Once()
+
+		assert.NoError(t, service.NewPresetService(mockRepo).Delete(context.Background(), "user-1", "warehouse-a"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo := mocks.NewMockPresetRepositoryInterface(t)
+		mockRepo.On("Delete", mock.Anything, "user-1", "missing").Return(false, nil).Once()
+
+		err := service.NewPresetService(mockRepo).Delete(context.Background(), "user-1", "missing")
+
+		assert.ErrorIs(t, err, service.ErrPresetNotFound)
+	})
+}
+
+func TestPresetService_NilRepository(t *testing.T) {
+	svc := service.NewPresetService(nil)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "", &model.Preset{Name: "a", PackSizes: []int{1}})
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	_, err = svc.Get(ctx, "", "a")
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	_, err = svc.List(ctx, "")
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	_, err = svc.Update(ctx, "", "a", "", []int{1}, nil)
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	assert.ErrorIs(t, svc.Delete(ctx, "", "a"), service.ErrRepositoryNotConfigured)
+}
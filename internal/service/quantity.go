@@ -0,0 +1,58 @@
+package service
+
+import (
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+// CalculateUnits calculates packs for an order and pack sizes counted in
+// units of a fixed-point quantity, such as grams of an order in kilograms
+// (see package fixedpoint), with calculator.
+//
+// The order and sizes are first divided by their greatest common divisor:
+// combinations of the divided sizes match those of the sizes one for one, so
+// the result is the same, but the calculator searches far fewer totals. A
+// 12.5kg order of 2.5kg and 5kg bags is calculated as 5 items of sizes 1 and
+// 2. The divided order must be within the calculator's maximum, and the
+// divided sizes within dto.MaxPackSize, or ErrOrderTooLarge is returned.
+//
+// The result counts units: OrderedItems and TotalItems are quantities and
+// each pack's Size a pack size. MaxOverageItems constrains the overage in
+// units too.
+func CalculateUnits(calculator PackCalculator, order int, packSizes []int, constraints model.PackConstraints) (model.PackResult, error) {
+	if order <= 0 || len(packSizes) == 0 {
+		return model.Empty(order), nil
+	}
+
+	divisor := order
+	for _, size := range packSizes {
+		divisor = gcd(divisor, size)
+	}
+
+	sizes := make([]int, len(packSizes))
+	for i, size := range packSizes {
+		sizes[i] = size / divisor
+		if sizes[i] > dto.MaxPackSize {
+			return model.Empty(order), ErrOrderTooLarge
+		}
+	}
+	if order/divisor > MaxItemsOrdered(calculator) {
+		return model.Empty(order), ErrOrderTooLarge
+	}
+	if constraints.MaxOverageItems != nil {
+		// Divided totals differ by whole multiples of divisor, so rounding
+		// the limit down excludes exactly the overages above it
+		maxOverage := *constraints.MaxOverageItems / divisor
+		constraints.MaxOverageItems = &maxOverage
+	}
+
+	result, err := calculator.CalculateWithConstraints(order/divisor, sizes, constraints)
+	result.OrderedItems *= divisor
+	result.TotalItems *= divisor
+	packs := make([]model.Pack, len(result.Packs))
+	for i, pack := range result.Packs {
+		packs[i] = model.Pack{Size: pack.Size * divisor, Quantity: pack.Quantity}
+	}
+	result.Packs = packs
+	return result, err
+}
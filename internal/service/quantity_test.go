@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/model"
+)
+
+func TestCalculateUnits(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	calc := NewPackCalculatorService()
+
+	t.Run("scales the result back to units", func(t *testing.T) {
+		// 12.6kg of 2.5kg and 5kg bags, in grams
+		result, err := CalculateUnits(calc, 12600, []int{2500, 5000}, model.PackConstraints{})
+		require.NoError(t, err)
+		assert.Equal(t, 12600, result.OrderedItems)
+		assert.Equal(t, 15000, result.TotalItems)
+		assert.ElementsMatch(t, []model.Pack{{Size: 5000, Quantity: 3}}, result.Packs)
+	})
+
+	t.Run("matches the calculation of the divided order", func(t *testing.T) {
+		for _, order := range []int{1, 250, 251, 501, 12001} {
+			want := calc.CalculateWithPackSizes(order, []int{250, 500, 1000})
+			got, err := CalculateUnits(calc, order*750, []int{250 * 750, 500 * 750, 1000 * 750}, model.PackConstraints{})
+			require.NoError(t, err)
+			assert.Equal(t, want.TotalItems*750, got.TotalItems, "order %d", order)
+			assert.Equal(t, want.PackCount(), got.PackCount(), "order %d", order)
+		}
+	})
+
+	t.Run("divides the overage limit", func(t *testing.T) {
+		_, err := CalculateUnits(calc, 2600, []int{2500}, model.PackConstraints{MaxOverageItems: intPtr(2399)})
+		assert.ErrorIs(t, err, ErrConstraintsUnsatisfiable)
+		result, err := CalculateUnits(calc, 2600, []int{2500}, model.PackConstraints{MaxOverageItems: intPtr(2400)})
+		require.NoError(t, err)
+		assert.Equal(t, 5000, result.TotalItems)
+	})
+
+	t.Run("refuses orders too large once divided", func(t *testing.T) {
+		small := NewPackCalculatorService(WithMaxItemsOrdered(1000))
+		_, err := CalculateUnits(small, 1001, []int{1, 3}, model.PackConstraints{})
+		assert.ErrorIs(t, err, ErrOrderTooLarge)
+		result, err := CalculateUnits(small, 1_000_000, []int{1000, 3000}, model.PackConstraints{})
+		require.NoError(t, err)
+		assert.Equal(t, 1_000_000, result.TotalItems)
+	})
+}
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
+	"sync"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/repository"
 )
@@ -18,25 +21,95 @@ type RoleService interface {
 // RoleServiceImpl implements RoleService.
 type RoleServiceImpl struct {
 	roleRepo repository.RoleRepositoryInterface
+
+	// known holds the roles last read, by ID, with WithLastKnownRoles
+	known *sync.Map
+}
+
+// RoleServiceOption configures a RoleService.
+type RoleServiceOption func(*RoleServiceImpl)
+
+// WithLastKnownRoles remembers the roles read, and answers with them while
+// the repository's circuit breaker is open, so permission checks keep
+// working during a database outage. Roles never read before the outage are
+// reported as the open circuit error.
+func WithLastKnownRoles() RoleServiceOption {
+	return func(s *RoleServiceImpl) {
+		s.known = &sync.Map{}
+	}
 }
 
 // NewRoleService creates a new role service.
-func NewRoleService(roleRepo repository.RoleRepositoryInterface) RoleService {
-	return &RoleServiceImpl{
+func NewRoleService(roleRepo repository.RoleRepositoryInterface, opts ...RoleServiceOption) RoleService {
+	s := &RoleServiceImpl{
 		roleRepo: roleRepo,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *RoleServiceImpl) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Role, error) {
 	if s.roleRepo == nil {
 		return nil, ErrRepositoryNotConfigured
 	}
-	return s.roleRepo.FindByID(ctx, id)
+	role, err := s.roleRepo.FindByID(ctx, id)
+	if s.known == nil {
+		return role, err
+	}
+	if s.unavailable(err) {
+		if known, ok := s.known.Load(id); ok {
+			return known.(*model.Role), nil
+		}
+		return nil, err
+	}
+	if err == nil && role != nil {
+		s.known.Store(role.ID, role)
+	}
+	return role, err
 }
 
 func (s *RoleServiceImpl) FindByIDs(ctx context.Context, ids []string) ([]*model.Role, error) {
 	if s.roleRepo == nil {
 		return nil, ErrRepositoryNotConfigured
 	}
-	return s.roleRepo.FindByIDs(ctx, ids)
+	roles, err := s.roleRepo.FindByIDs(ctx, ids)
+	if s.known == nil {
+		return roles, err
+	}
+	if s.unavailable(err) {
+		return s.lastKnown(ids, err)
+	}
+	if err == nil {
+		for _, role := range roles {
+			if role != nil {
+				s.known.Store(role.ID, role)
+			}
+		}
+	}
+	return roles, err
+}
+
+// unavailable reports whether err means the roles cannot be read for now.
+func (s *RoleServiceImpl) unavailable(err error) bool {
+	return errors.Is(err, circuitbreaker.ErrCircuitOpen)
+}
+
+// lastKnown returns the remembered roles of ids, or err if any of them is
+// unknown.
+func (s *RoleServiceImpl) lastKnown(ids []string, err error) ([]*model.Role, error) {
+	roles := make([]*model.Role, 0, len(ids))
+	for _, idStr := range ids {
+		id, parseErr := primitive.ObjectIDFromHex(idStr)
+		if parseErr != nil {
+			continue
+		}
+		known, ok := s.known.Load(id)
+		if !ok {
+			return nil, err
+		}
+		roles = append(roles, known.(*model.Role))
+	}
+	return roles, nil
 }
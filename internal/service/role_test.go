@@ -3,12 +3,15 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
 	"github.com/guttosm/pack-service/internal/domain/model"
 	"github.com/guttosm/pack-service/internal/mocks"
 	"github.com/guttosm/pack-service/internal/service"
@@ -189,3 +192,47 @@ func TestRoleService_FindByIDs_NilRepository(t *testing.T) {
 	assert.Equal(t, service.ErrRepositoryNotConfigured, err)
 	assert.Nil(t, roles)
 }
+
+func TestRoleService_WithLastKnownRoles(t *testing.T) {
+	roleID := primitive.NewObjectID()
+	unknownID := primitive.NewObjectID()
+	role := &model.Role{ID: roleID, Name: "admin", Active: true}
+	circuitOpen := fmt.Errorf("find role: %w", circuitbreaker.ErrCircuitOpen)
+
+	mockRepo := new(mocks.MockRoleRepositoryInterface)
+	mockRepo.On("FindByID", mock.Anything, roleID).Return(role, nil).Once()
+	mockRepo.On("FindByID", mock.Anything, roleID).Return(nil, circuitOpen).Once()
+	mockRepo.On("FindByID", mock.Anything, unknownID).Return(nil, circuitOpen).Once()
+	mockRepo.On("FindByIDs", mock.Anything, mock.Anything).Return(nil, circuitOpen)
+
+	svc := service.NewRoleService(mockRepo, service.WithLastKnownRoles())
+	ctx := context.Background()
+
+	found, err := svc.FindByID(ctx, roleID)
+	require.NoError(t, err)
+	assert.Equal(t, role, found)
+
+	found, err = svc.FindByID(ctx, roleID)
+	require.NoError(t, err, "the last known role is served while the circuit is open")
+	assert.Equal(t, role, found)
+
+	_, err = svc.FindByID(ctx, unknownID)
+	assert.ErrorIs(t, err, circuitbreaker.ErrCircuitOpen)
+
+	roles, err := svc.FindByIDs(ctx, []string{roleID.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Role{role}, roles)
+
+	_, err = svc.FindByIDs(ctx, []string{roleID.Hex(), unknownID.Hex()})
+	assert.ErrorIs(t, err, circuitbreaker.ErrCircuitOpen)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRoleService_WithoutLastKnownRoles(t *testing.T) {
+	mockRepo := new(mocks.MockRoleRepositoryInterface)
+	mockRepo.On("FindByID", mock.Anything, mock.Anything).Return(nil, circuitbreaker.ErrCircuitOpen)
+
+	_, err := service.NewRoleService(mockRepo).FindByID(context.Background(), primitive.NewObjectID())
+	assert.ErrorIs(t, err, circuitbreaker.ErrCircuitOpen)
+}
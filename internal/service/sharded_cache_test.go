@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/service/cache"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewShardedCache(t *testing.T) {
@@ -108,14 +110,14 @@ func TestShardedCache_GetSet(t *testing.T) {
 			defer cache.Stop()
 
 			// Initially should miss
-			_, found := cache.Get(tt.key)
+			_, found := cache.Get(itemsKey(tt.key))
 			assert.False(t, found)
 
 			// Set value
-			cache.Set(tt.key, tt.value)
+			cache.Set(itemsKey(tt.key), tt.value)
 
 			// Should now hit
-			result, found := cache.Get(tt.key)
+			result, found := cache.Get(itemsKey(tt.key))
 			assert.Equal(t, tt.wantHit, found)
 			if tt.wantHit {
 				assert.Equal(t, tt.value.TotalItems, result.TotalItems)
@@ -150,20 +152,20 @@ func TestShardedCache_Invalidate(t *testing.T) {
 
 			// Set initial values
 			for _, key := range tt.keys {
-				cache.Set(key, model.PackResult{TotalItems: key})
+				cache.Set(itemsKey(key), model.PackResult{TotalItems: key})
 			}
 
 			// Invalidate
-			cache.Invalidate(tt.invalidateKey)
+			cache.Invalidate(itemsKey(tt.invalidateKey))
 
 			// Check invalidated key is gone
-			_, found := cache.Get(tt.invalidateKey)
+			_, found := cache.Get(itemsKey(tt.invalidateKey))
 			assert.False(t, found)
 
 			// Other keys should still exist
 			for _, key := range tt.keys {
 				if key != tt.invalidateKey {
-					_, found := cache.Get(key)
+					_, found := cache.Get(itemsKey(key))
 					assert.True(t, found)
 				}
 			}
@@ -177,12 +179,12 @@ func TestShardedCache_Clear(t *testing.T) {
 
 	// Add some values
 	for i := 0; i < 10; i++ {
-		cache.Set(i, model.PackResult{TotalItems: i})
+		cache.Set(itemsKey(i), model.PackResult{TotalItems: i})
 	}
 
 	// Verify they exist
 	for i := 0; i < 10; i++ {
-		_, found := cache.Get(i)
+		_, found := cache.Get(itemsKey(i))
 		assert.True(t, found)
 	}
 
@@ -191,7 +193,7 @@ func TestShardedCache_Clear(t *testing.T) {
 
 	// All should be gone
 	for i := 0; i < 10; i++ {
-		_, found := cache.Get(i)
+		_, found := cache.Get(itemsKey(i))
 		assert.False(t, found)
 	}
 }
@@ -202,17 +204,17 @@ func TestShardedCache_Metrics(t *testing.T) {
 
 	// Set some values
 	for i := 0; i < 5; i++ {
-		cache.Set(i, model.PackResult{TotalItems: i})
+		cache.Set(itemsKey(i), model.PackResult{TotalItems: i})
 	}
 
 	// Generate hits
 	for i := 0; i < 5; i++ {
-		cache.Get(i)
+		cache.Get(itemsKey(i))
 	}
 
 	// Generate misses
 	for i := 100; i < 105; i++ {
-		cache.Get(i)
+		cache.Get(itemsKey(i))
 	}
 
 	metrics := cache.Metrics()
@@ -220,19 +222,66 @@ func TestShardedCache_Metrics(t *testing.T) {
 	assert.Equal(t, int64(5), metrics.Misses)
 }
 
+func TestShardedCache_ShardMetrics(t *testing.T) {
+	cache := NewShardedCache(100, time.Minute, 4)
+	defer cache.Stop()
+
+	for i := 0; i < 20; i++ {
+		cache.Set(itemsKey(i), model.PackResult{TotalItems: i})
+	}
+
+	shards := cache.ShardMetrics()
+	assert.Len(t, shards, 4)
+	size := 0
+	for _, shard := range shards {
+		assert.Equal(t, 25, shard.Capacity)
+		size += shard.Size
+	}
+	assert.Equal(t, cache.Metrics().Size, size)
+}
+
 func TestShardedCache_ShardDistribution(t *testing.T) {
 	cache := NewShardedCache(100, time.Minute, 4)
 	defer cache.Stop()
 
 	// Add values that should be distributed across shards
 	for i := 0; i < 100; i++ {
-		cache.Set(i, model.PackResult{TotalItems: i})
+		cache.Set(itemsKey(i), model.PackResult{TotalItems: i})
 	}
 
 	// Verify all can be retrieved
 	for i := 0; i < 100; i++ {
-		result, found := cache.Get(i)
+		result, found := cache.Get(itemsKey(i))
 		assert.True(t, found)
 		assert.Equal(t, i, result.TotalItems)
 	}
 }
+
+func TestTTLCache_Compression(t *testing.T) {
+	compressor, err := cache.NewCompressor(cache.CompressionSnappy, 64)
+	require.NoError(t, err)
+
+	c := NewShardedCache(10, time.Minute, 2)
+	defer c.Stop()
+	c.setCompressor(compressor)
+
+	large := model.PackResult{OrderedItems: 12001, TotalItems: 12250, Packs: []model.Pack{
+		{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1},
+	}}
+	small := model.PackResult{OrderedItems: 1, TotalItems: 250}
+
+	c.Set(itemsKey(12001), large)
+	c.Set(itemsKey(1), small)
+
+	entry := c.getShard(itemsKey(12001)).items[itemsKey(12001)]
+	assert.NotNil(t, entry.packed)
+	assert.Nil(t, c.getShard(itemsKey(1)).items[itemsKey(1)].packed)
+
+	got, found := c.Get(itemsKey(12001))
+	assert.True(t, found)
+	assert.Equal(t, large, got)
+
+	got, found = c.Get(itemsKey(1))
+	assert.True(t, found)
+	assert.Equal(t, small, got)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// signingKeySecretBytes is the length of generated signing secrets, as
+	// long as the HS256 hash.
+	signingKeySecretBytes = 32
+	// signingKeyReloadTimeout bounds a background reload.
+	signingKeyReloadTimeout = 5 * time.Second
+)
+
+// ErrSigningKeyNotFound is returned when no active signing key has the kid.
+var ErrSigningKeyNotFound = domainerr.New("signing_key_not_found", http.StatusNotFound, i18n.ErrKeyNotFound, "signing key not found")
+
+// SigningKeyRing holds the JWT signing keys stored in MongoDB, so the keys
+// can be rotated without invalidating the tokens already issued. Tokens are
+// signed with the newest active key and verified with the key their kid
+// header names, as long as it is active. Tokens without kid, issued before
+// any key was added, keep using JWT_SECRET_KEY.
+//
+// A new key only starts signing once every instance has loaded it, that is
+// after the propagation delay passed on creation, so tokens it signs are
+// accepted everywhere.
+type SigningKeyRing struct {
+	repo        repository.SigningKeyRepositoryInterface
+	propagation time.Duration
+	now         func() time.Time
+	// active holds the active keys, newest first.
+	active atomic.Pointer[[]*model.SigningKey]
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewSigningKeyRing creates a key ring storing keys in repo. New keys start
+// signing propagation after they were added; use the interval other
+// instances reload the keys at.
+func NewSigningKeyRing(repo repository.SigningKeyRepositoryInterface, propagation time.Duration) *SigningKeyRing {
+	r := &SigningKeyRing{
+		repo:        repo,
+		propagation: propagation,
+		now:         timeutil.Now,
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+	r.active.Store(&[]*model.SigningKey{})
+	return r
+}
+
+// List returns every stored key, retired ones included, newest first, with
+// the key new tokens are signed with marked.
+func (r *SigningKeyRing) List(ctx context.Context) ([]*model.SigningKey, error) {
+	keys, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if signing := r.signingKey(); signing != nil {
+		for _, key := range keys {
+			key.Signing = key.KID == signing.KID
+		}
+	}
+	return keys, nil
+}
+
+// Add generates and stores a new key with random secrets, and loads it. It
+// starts signing once the propagation delay has passed.
+func (r *SigningKeyRing) Add(ctx context.Context, createdBy string) (*model.SigningKey, error) {
+	kid := make([]byte, 8)
+	secret := make([]byte, signingKeySecretBytes)
+	refreshSecret := make([]byte, signingKeySecretBytes)
+	for _, b := range [][]byte{kid, secret, refreshSecret} {
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+	}
+
+	key := &model.SigningKey{
+		KID:           hex.EncodeToString(kid),
+		Secret:        secret,
+		RefreshSecret: refreshSecret,
+		CreatedBy:     createdBy,
+		CreatedAt:     r.now(),
+	}
+	if err := r.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, r.Reload(ctx)
+}
+
+// Retire retires the key kid: tokens it signed are rejected at once on this
+// instance, and on the others at their next reload.
+func (r *SigningKeyRing) Retire(ctx context.Context, kid string) (*model.SigningKey, error) {
+	retired, err := r.repo.Retire(ctx, kid, r.now())
+	if err != nil {
+		return nil, err
+	}
+	if !retired {
+		return nil, ErrSigningKeyNotFound
+	}
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.KID == kid {
+			return key, nil
+		}
+	}
+	return nil, ErrSigningKeyNotFound
+}
+
+// Reload loads the active keys from the repository.
+func (r *SigningKeyRing) Reload(ctx context.Context) error {
+	keys, err := r.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	active := make([]*model.SigningKey, 0, len(keys))
+	for _, key := range keys {
+		if key.Active() {
+			active = append(active, key)
+		}
+	}
+	r.active.Store(&active)
+	return nil
+}
+
+// Start reloads the keys every interval until Stop is called, so keys added
+// or retired through other instances apply here too.
+func (r *SigningKeyRing) Start(interval time.Duration) {
+	go func() {
+		defer close(r.doneCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.reloadInBackground()
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reloading started by Start and waits for a running reload.
+func (r *SigningKeyRing) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+		<-r.doneCh
+	})
+}
+
+func (r *SigningKeyRing) reloadInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), signingKeyReloadTimeout)
+	defer cancel()
+
+	if err := r.Reload(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to reload JWT signing keys")
+	}
+}
+
+// signingKey returns the newest active key added at least the propagation
+// delay ago, or nil when there is none.
+func (r *SigningKeyRing) signingKey() *model.SigningKey {
+	cutoff := r.now().Add(-r.propagation)
+	for _, key := range *r.active.Load() {
+		if !key.CreatedAt.After(cutoff) {
+			return key
+		}
+	}
+	return nil
+}
+
+// key returns the active key kid, or nil.
+func (r *SigningKeyRing) key(kid string) *model.SigningKey {
+	for _, key := range *r.active.Load() {
+		if key.KID == kid {
+			return key
+		}
+	}
+	return nil
+}
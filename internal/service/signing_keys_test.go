@@ -0,0 +1,206 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// fakeSigningKeys stores signing keys in memory, shared by the key rings of
+// several instances.
+type fakeSigningKeys struct {
+	mu   sync.Mutex
+	keys []*model.SigningKey
+}
+
+func (f *fakeSigningKeys) List(context.Context) ([]*model.SigningKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]*model.SigningKey, 0, len(f.keys))
+	for i := len(f.keys) - 1; i >= 0; i-- {
+		key := *f.keys[i]
+		keys = append(keys, &key)
+	}
+	return keys, nil
+}
+
+func (f *fakeSigningKeys) Create(_ context.Context, key *model.SigningKey) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := *key
+	f.keys = append(f.keys, &stored)
+	return nil
+}
+
+func (f *fakeSigningKeys) Retire(_ context.Context, kid string, at time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range f.keys {
+		if key.KID == kid && key.Active() {
+			key.RetiredAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tokenKID returns the kid header of a token.
+func tokenKID(t *testing.T, tokenString string) string {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	require.NoError(t, err)
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+func newSigningKeyTokenService(t *testing.T, keys *service.SigningKeyRing) service.TokenService {
+	t.Helper()
+	tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+	tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil).Maybe()
+	tokenRepo.EXPECT().IsBlacklisted(mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	return service.NewTokenService(tokenRepo, service.NewTokenConfigFromAuthConfig(testAuthConfig()), service.WithSigningKeys(keys))
+}
+
+func TestSigningKeyRing_Rotation(t *testing.T) {
+	ctx := context.Background()
+	keys := service.NewSigningKeyRing(&fakeSigningKeys{}, 0)
+	tokenService := newSigningKeyTokenService(t, keys)
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+
+	legacy, err := tokenService.GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	assert.Empty(t, tokenKID(t, legacy.AccessToken), "without keys tokens are signed with the configured secret")
+
+	first, err := keys.Add(ctx, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, "admin-1", first.CreatedBy)
+	assert.Len(t, first.Secret, 32)
+	firstPair, err := tokenService.GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, first.KID, tokenKID(t, firstPair.AccessToken))
+	assert.Equal(t, first.KID, tokenKID(t, firstPair.RefreshToken))
+
+	second, err := keys.Add(ctx, "admin-1")
+	require.NoError(t, err)
+	secondPair, err := tokenService.GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, second.KID, tokenKID(t, secondPair.AccessToken), "the newest key signs")
+
+	for _, pair := range []*dto.TokenPair{legacy, firstPair, secondPair} {
+		_, err := tokenService.ValidateAccessToken(ctx, pair.AccessToken)
+		assert.NoError(t, err)
+		_, err = tokenService.ValidateRefreshToken(pair.RefreshToken)
+		assert.NoError(t, err)
+	}
+
+	listed, err := keys.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, second.KID, listed[0].KID)
+	assert.True(t, listed[0].Signing)
+	assert.False(t, listed[1].Signing)
+
+	retired, err := keys.Retire(ctx, first.KID)
+	require.NoError(t, err)
+	assert.NotNil(t, retired.RetiredAt)
+	_, err = tokenService.ValidateAccessToken(ctx, firstPair.AccessToken)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+	_, err = tokenService.ValidateRefreshToken(firstPair.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+	_, err = tokenService.ValidateAccessToken(ctx, secondPair.AccessToken)
+	assert.NoError(t, err)
+	_, err = tokenService.ValidateAccessToken(ctx, legacy.AccessToken)
+	assert.NoError(t, err)
+
+	_, err = keys.Retire(ctx, first.KID)
+	assert.ErrorIs(t, err, service.ErrSigningKeyNotFound)
+}
+
+func TestTokenService_SecretKeyCutoff(t *testing.T) {
+	ctx := context.Background()
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+	newTokenService := func(keys *service.SigningKeyRing, cutoff time.Time) service.TokenService {
+		tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+		tokenRepo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil).Maybe()
+		tokenRepo.EXPECT().IsBlacklisted(mock.Anything, mock.Anything).Return(false, nil).Maybe()
+		cfg := service.NewTokenConfigFromAuthConfig(testAuthConfig())
+		cfg.SecretKeyCutoff = cutoff
+		return service.NewTokenService(tokenRepo, cfg, service.WithSigningKeys(keys))
+	}
+
+	keys := service.NewSigningKeyRing(&fakeSigningKeys{}, 0)
+	legacy, err := newTokenService(keys, time.Time{}).GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	_, err = keys.Add(ctx, "admin-1")
+	require.NoError(t, err)
+
+	t.Run("accepts tokens without kid before the cutoff", func(t *testing.T) {
+		tokenService := newTokenService(keys, time.Now().Add(time.Hour))
+		_, err := tokenService.ValidateAccessToken(ctx, legacy.AccessToken)
+		assert.NoError(t, err)
+		_, err = tokenService.ValidateRefreshToken(legacy.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects tokens without kid after the cutoff", func(t *testing.T) {
+		tokenService := newTokenService(keys, time.Now().Add(-time.Hour))
+		_, err := tokenService.ValidateAccessToken(ctx, legacy.AccessToken)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+		_, err = tokenService.ValidateRefreshToken(legacy.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+
+		pair, err := tokenService.GenerateTokenPair(ctx, user)
+		require.NoError(t, err)
+		_, err = tokenService.ValidateAccessToken(ctx, pair.AccessToken)
+		assert.NoError(t, err, "signing keys are unaffected")
+	})
+
+	t.Run("does not issue tokens without kid after the cutoff", func(t *testing.T) {
+		tokenService := newTokenService(service.NewSigningKeyRing(&fakeSigningKeys{}, 0), time.Now().Add(-time.Hour))
+		_, err := tokenService.GenerateTokenPair(ctx, user)
+		assert.Error(t, err)
+	})
+}
+
+func TestSigningKeyRing_Propagation(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeSigningKeys{}
+	keys := service.NewSigningKeyRing(repo, time.Hour)
+	tokenService := newSigningKeyTokenService(t, keys)
+	user := &model.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+
+	key, err := keys.Add(ctx, "")
+	require.NoError(t, err)
+	pair, err := tokenService.GenerateTokenPair(ctx, user)
+	require.NoError(t, err)
+	assert.Empty(t, tokenKID(t, pair.AccessToken), "a new key does not sign before other instances load it")
+
+	// Other instances accept the key once they reloaded
+	other := service.NewSigningKeyRing(repo, time.Hour)
+	otherTokens := newSigningKeyTokenService(t, other)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &service.ClaimsWithJWT{
+		Claims:           dto.Claims{UserID: user.ID},
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	token.Header["kid"] = key.KID
+	signed, err := token.SignedString(key.Secret)
+	require.NoError(t, err)
+
+	_, err = otherTokens.ValidateAccessToken(ctx, signed)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+	require.NoError(t, other.Reload(ctx))
+	_, err = otherTokens.ValidateAccessToken(ctx, signed)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// DefaultStaleAccountDays is how many days without a login make an
+	// account stale unless configured otherwise.
+	DefaultStaleAccountDays = 90
+
+	// staleReportUsers caps the accounts named in a stale account report.
+	staleReportUsers = 50
+	// staleReportTimeout bounds one stale account report.
+	staleReportTimeout = time.Minute
+)
+
+// ListStale returns a page of active users whose last login, or creation
+// when they never logged in, is older than days days.
+func (s *UserServiceImpl) ListStale(ctx context.Context, days, limit, offset int) (*dto.StaleUserPage, error) {
+	if s.userRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if days <= 0 {
+		days = s.staleDays
+	}
+	if limit <= 0 {
+		limit = DefaultUserPageSize
+	}
+	if limit > MaxUserPageSize {
+		limit = MaxUserPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	before := staleCutoff(days)
+	query := bson.M{
+		"active": true,
+		"$or": bson.A{
+			bson.M{"last_login_at": bson.M{"$lt": before}},
+			bson.M{"last_login_at": bson.M{"$exists": false}, "created_at": bson.M{"$lt": before}},
+		},
+	}
+
+	users, err := s.userRepo.List(ctx, query, int64(limit), int64(offset))
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.userRepo.Count(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []*model.User{}
+	}
+
+	return &dto.StaleUserPage{
+		UserPage:        dto.UserPage{Users: users, Total: total, Limit: limit, Offset: offset},
+		InactiveDays:    days,
+		LastLoginBefore: before,
+	}, nil
+}
+
+// DeactivateStale deactivates users a page at a time. Users created before
+// logins were recorded have no last login, so they are only reported: their
+// inactivity cannot be told apart from missing history.
+func (s *UserServiceImpl) DeactivateStale(ctx context.Context, days int) ([]*model.User, error) {
+	if s.userRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("%w: inactivity threshold must be positive", ErrInvalidUser)
+	}
+
+	query := bson.M{"active": true, "last_login_at": bson.M{"$lt": staleCutoff(days)}}
+	total, err := s.userRepo.Count(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deactivated users leave the query, so every page starts at the front
+	var deactivated []*model.User
+	for int64(len(deactivated)) < total {
+		users, err := s.userRepo.List(ctx, query, MaxUserPageSize, 0)
+		if err != nil {
+			return deactivated, err
+		}
+		if len(users) == 0 {
+			break
+		}
+		for _, user := range users {
+			if err := s.deactivate(ctx, user); err != nil {
+				return deactivated, err
+			}
+			deactivated = append(deactivated, user)
+		}
+	}
+	return deactivated, nil
+}
+
+// staleCutoff returns the time logins must be older than to be days stale.
+func staleCutoff(days int) time.Time {
+	return timeutil.Now().AddDate(0, 0, -days)
+}
+
+// StaleAccountReportConfig configures a StaleAccountReporter.
+type StaleAccountReportConfig struct {
+	// Interval is how often the report runs.
+	Interval time.Duration
+	// InactiveDays is how many days without a login make an account stale.
+	// Zero uses the user service's default.
+	InactiveDays int
+	// DeactivateDays deactivates accounts without a login for this many
+	// days before reporting; zero disables deactivation.
+	DeactivateDays int
+}
+
+// StaleAccountReporter periodically reports stale accounts for access
+// reviews, optionally deactivating the ones past the deactivation threshold.
+type StaleAccountReporter struct {
+	users    UserService
+	notifier notify.Notifier
+	cfg      StaleAccountReportConfig
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewStaleAccountReporter creates a reporter that sends its reports to
+// notifier. Call Start to run it.
+func NewStaleAccountReporter(users UserService, notifier notify.Notifier, cfg StaleAccountReportConfig) *StaleAccountReporter {
+	return &StaleAccountReporter{
+		users:    users,
+		notifier: notifier,
+		cfg:      cfg,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start runs the report every cfg.Interval until Stop is called. The first
+// report is sent one interval after start, so restarts do not repeat it.
+func (r *StaleAccountReporter) Start() {
+	go func() {
+		defer close(r.doneCh)
+
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), staleReportTimeout)
+				if err := r.Report(ctx); err != nil {
+					log.Warn().Err(err).Msg("Failed to report stale accounts")
+				}
+				cancel()
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the reporter and waits for a running report to finish.
+func (r *StaleAccountReporter) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+		<-r.doneCh
+	})
+}
+
+// Report deactivates accounts past the deactivation threshold, if set, then
+// sends the stale accounts and the deactivated ones to the notifier.
+func (r *StaleAccountReporter) Report(ctx context.Context) error {
+	var deactivated []*model.User
+	if r.cfg.DeactivateDays > 0 {
+		var err error
+		deactivated, err = r.users.DeactivateStale(ctx, r.cfg.DeactivateDays)
+		for _, user := range deactivated {
+			log.Info().Str("user_id", user.ID.Hex()).Str("email", user.Email).
+				Int("inactive_days", r.cfg.DeactivateDays).Msg("Deactivated inactive account")
+		}
+		metrics.RecordStaleAccountsDeactivated(len(deactivated))
+		if err != nil {
+			return err
+		}
+	}
+
+	page, err := r.users.ListStale(ctx, r.cfg.InactiveDays, staleReportUsers, 0)
+	if err != nil {
+		return err
+	}
+	metrics.SetStaleAccounts(page.Total)
+	if page.Total == 0 && len(deactivated) == 0 {
+		return nil
+	}
+
+	fields := map[string]interface{}{
+		"stale_accounts": page.Total,
+		"inactive_days":  page.InactiveDays,
+		"accounts":       staleAccountEmails(page.Users),
+	}
+	message := fmt.Sprintf("%d active accounts have not logged in for %d days", page.Total, page.InactiveDays)
+	if len(deactivated) > 0 {
+		fields["deactivated"] = staleAccountEmails(deactivated)
+		message += fmt.Sprintf("; %d accounts without a login for %d days were deactivated", len(deactivated), r.cfg.DeactivateDays)
+	}
+	if r.notifier == nil {
+		return nil
+	}
+	return r.notifier.Notify(ctx, notify.Notification{
+		Title:     "Stale account report",
+		Message:   message,
+		Severity:  notify.SeverityInfo,
+		Source:    "stale_accounts",
+		Fields:    fields,
+		Timestamp: timeutil.Now(),
+	})
+}
+
+// staleAccountEmails lists the emails of the first staleReportUsers users.
+func staleAccountEmails(users []*model.User) []string {
+	users = users[:min(len(users), staleReportUsers)]
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+	return emails
+}
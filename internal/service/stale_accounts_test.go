@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/notify"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// cutoffNear reports whether the stale query's cutoff is days ago.
+func cutoffNear(value interface{}, days int) bool {
+	cutoff, ok := value.(time.Time)
+	return ok && time.Since(cutoff).Round(time.Hour) == time.Duration(days)*24*time.Hour
+}
+
+func TestUserService_ListStale(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	svc := service.NewUserService(userRepo, nil, nil, service.WithStaleAccountDays(30))
+
+	isStaleQuery := mock.MatchedBy(func(q bson.M) bool {
+		or, ok := q["$or"].(bson.A)
+		if !ok || len(or) != 2 || q["active"] != true {
+			return false
+		}
+		loggedIn := or[0].(bson.M)["last_login_at"].(bson.M)
+		neverLoggedIn := or[1].(bson.M)
+		return cutoffNear(loggedIn["$lt"], 30) && neverLoggedIn["last_login_at"] != nil &&
+			cutoffNear(neverLoggedIn["created_at"].(bson.M)["$lt"], 30)
+	})
+	user := &model.User{ID: primitive.NewObjectID(), Email: "idle@example.com", Active: true}
+	userRepo.EXPECT().List(mock.Anything, isStaleQuery, int64(service.DefaultUserPageSize), int64(0)).Return([]*model.User{user}, nil)
+	userRepo.EXPECT().Count(mock.Anything, isStaleQuery).Return(1, nil)
+
+	page, err := svc.ListStale(context.Background(), 0, 0, -5)
+	require.NoError(t, err)
+	assert.Equal(t, 30, page.InactiveDays)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, []*model.User{user}, page.Users)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -30), page.LastLoginBefore, time.Minute)
+}
+
+func TestUserService_DeactivateStale(t *testing.T) {
+	t.Run("deactivates users past the threshold", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		authService := mocks.NewMockAuthService(t)
+		svc := service.NewUserService(userRepo, nil, authService)
+
+		isDeactivationQuery := mock.MatchedBy(func(q bson.M) bool {
+			_, hasOr := q["$or"]
+			lastLogin, ok := q["last_login_at"].(bson.M)
+			return !hasOr && ok && cutoffNear(lastLogin["$lt"], 180)
+		})
+		idle := []*model.User{
+			{ID: primitive.NewObjectID(), Active: true},
+			{ID: primitive.NewObjectID(), Active: true},
+		}
+		userRepo.EXPECT().Count(mock.Anything, isDeactivationQuery).Return(2, nil)
+		userRepo.EXPECT().List(mock.Anything, isDeactivationQuery, int64(service.MaxUserPageSize), int64(0)).Return(idle, nil).Once()
+		for _, user := range idle {
+			userRepo.EXPECT().Delete(mock.Anything, user.ID).Return(nil).Once()
+			authService.EXPECT().RevokeUserTokens(mock.Anything, user.ID).Return(nil).Once()
+		}
+
+		deactivated, err := svc.DeactivateStale(context.Background(), 180)
+		require.NoError(t, err)
+		require.Len(t, deactivated, 2)
+		assert.False(t, deactivated[0].Active)
+	})
+
+	t.Run("requires a threshold", func(t *testing.T) {
+		svc := service.NewUserService(mocks.NewMockUserRepositoryInterface(t), nil, nil)
+
+		_, err := svc.DeactivateStale(context.Background(), 0)
+		assert.ErrorIs(t, err, service.ErrInvalidUser)
+	})
+}
+
+func TestStaleAccountReporter_Report(t *testing.T) {
+	idle := &model.User{ID: primitive.NewObjectID(), Email: "idle@example.com"}
+	gone := &model.User{ID: primitive.NewObjectID(), Email: "gone@example.com"}
+	stalePage := &dto.StaleUserPage{
+		UserPage:     dto.UserPage{Users: []*model.User{idle}, Total: 1},
+		InactiveDays: 90,
+	}
+
+	t.Run("reports stale and deactivated accounts", func(t *testing.T) {
+		users := mocks.NewMockUserService(t)
+		notifier := mocks.NewMockNotifier(t)
+		users.EXPECT().DeactivateStale(mock.Anything, 365).Return([]*model.User{gone}, nil)
+		users.EXPECT().ListStale(mock.Anything, 90, mock.Anything, 0).Return(stalePage, nil)
+		notifier.EXPECT().Notify(mock.Anything, mock.MatchedBy(func(n notify.Notification) bool {
+			return n.Source == "stale_accounts" && n.Fields["stale_accounts"] == int64(1) &&
+				assert.ObjectsAreEqual([]string{"idle@example.com"}, n.Fields["accounts"]) &&
+				assert.ObjectsAreEqual([]string{"gone@example.com"}, n.Fields["deactivated"])
+		})).Return(nil)
+
+		reporter := service.NewStaleAccountReporter(users, notifier, service.StaleAccountReportConfig{
+			InactiveDays:   90,
+			DeactivateDays: 365,
+		})
+		require.NoError(t, reporter.Report(context.Background()))
+	})
+
+	t.Run("stays quiet without stale accounts", func(t *testing.T) {
+		users := mocks.NewMockUserService(t)
+		users.EXPECT().ListStale(mock.Anything, 90, mock.Anything, 0).Return(&dto.StaleUserPage{InactiveDays: 90}, nil)
+
+		reporter := service.NewStaleAccountReporter(users, mocks.NewMockNotifier(t), service.StaleAccountReportConfig{InactiveDays: 90})
+		require.NoError(t, reporter.Report(context.Background()))
+	})
+
+	t.Run("stops when deactivation fails", func(t *testing.T) {
+		users := mocks.NewMockUserService(t)
+		users.EXPECT().DeactivateStale(mock.Anything, 365).Return(nil, errors.New("db down"))
+
+		reporter := service.NewStaleAccountReporter(users, mocks.NewMockNotifier(t), service.StaleAccountReportConfig{DeactivateDays: 365})
+		assert.Error(t, reporter.Report(context.Background()))
+	})
+}
+
+func TestStaleAccountReporter_StartStop(t *testing.T) {
+	users := mocks.NewMockUserService(t)
+	reported := make(chan struct{}, 1)
+	users.EXPECT().ListStale(mock.Anything, 0, mock.Anything, 0).Run(func(context.Context, int, int, int) {
+		select {
+		case reported <- struct{}{}:
+		default:
+		}
+	}).Return(&dto.StaleUserPage{}, nil)
+
+	reporter := service.NewStaleAccountReporter(users, nil, service.StaleAccountReportConfig{Interval: 10 * time.Millisecond})
+	reporter.Start()
+	select {
+	case <-reported:
+	case <-time.After(2 * time.Second):
+		t.Fatal("report did not run")
+	}
+	reporter.Stop()
+	reporter.Stop()
+}
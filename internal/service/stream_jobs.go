@@ -0,0 +1,166 @@
+package service
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// Stream job defaults.
+const (
+	// DefaultStreamJobTTL is how long a submitted job waits for its stream.
+	DefaultStreamJobTTL = time.Minute
+	// DefaultMaxStreamJobs caps the jobs waiting for their stream at once.
+	DefaultMaxStreamJobs = 100
+)
+
+// progressSteps is roughly how many progress events a job emits.
+const progressSteps = 100
+
+// Stream event names, sent as the SSE event field.
+const (
+	StreamEventResult   = "result"
+	StreamEventProgress = "progress"
+	StreamEventDone     = "done"
+)
+
+var (
+	// ErrStreamJobNotFound is returned for unknown, expired or already
+	// streamed jobs, and for jobs submitted by another caller.
+	ErrStreamJobNotFound = domainerr.New("stream_job_not_found", http.StatusNotFound, i18n.ErrKeyStreamJobNotFound, "stream job not found")
+	// ErrTooManyStreamJobs is returned when too many jobs wait for their stream.
+	ErrTooManyStreamJobs = domainerr.ServiceUnavailable.Derive(i18n.ErrKeyServiceBusy, "too many stream jobs waiting")
+)
+
+// StreamJob is a bulk calculation waiting to be streamed.
+type StreamJob struct {
+	ID string
+	// Owner is the submitting user's ID, empty for API key and anonymous callers.
+	Owner     string
+	Items     []dto.CalculatePacksRequest
+	ExpiresAt time.Time
+}
+
+// StreamEvent is one event of a streamed job. Data is a dto.BatchItemResult
+// for StreamEventResult and a dto.JobProgress otherwise.
+type StreamEvent struct {
+	Name string
+	Data interface{}
+}
+
+// StreamJobRunner holds submitted bulk calculations until their client opens
+// the stream, then runs them, emitting every result as it is produced along
+// with progress events. Nothing is buffered beyond the job's input. Jobs live
+// in memory, so a job must be streamed from the replica it was submitted to.
+type StreamJobRunner struct {
+	mu      sync.Mutex
+	jobs    map[string]*StreamJob
+	ttl     time.Duration
+	maxJobs int
+	now     func() time.Time
+}
+
+// NewStreamJobRunner creates a StreamJobRunner. Non-positive values use
+// DefaultStreamJobTTL and DefaultMaxStreamJobs.
+func NewStreamJobRunner(ttl time.Duration, maxJobs int) *StreamJobRunner {
+	if ttl <= 0 {
+		ttl = DefaultStreamJobTTL
+	}
+	if maxJobs <= 0 {
+		maxJobs = DefaultMaxStreamJobs
+	}
+	return &StreamJobRunner{
+		jobs:    make(map[string]*StreamJob),
+		ttl:     ttl,
+		maxJobs: maxJobs,
+		now:     timeutil.Now,
+	}
+}
+
+// Submit registers items to be streamed by owner.
+func (r *StreamJobRunner) Submit(owner string, items []dto.CalculatePacksRequest) (*StreamJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for id, job := range r.jobs {
+		if !now.Before(job.ExpiresAt) {
+			delete(r.jobs, id)
+		}
+	}
+	if len(r.jobs) >= r.maxJobs {
+		return nil, ErrTooManyStreamJobs
+	}
+
+	job := &StreamJob{
+		ID:        idgen.NewUUID(),
+		Owner:     owner,
+		Items:     items,
+		ExpiresAt: now.Add(r.ttl),
+	}
+	r.jobs[job.ID] = job
+	return job, nil
+}
+
+// Claim hands owner's job over for streaming. A job can be claimed once.
+func (r *StreamJobRunner) Claim(id, owner string) (*StreamJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok || job.Owner != owner {
+		return nil, ErrStreamJobNotFound
+	}
+	delete(r.jobs, id)
+	if !r.now().Before(job.ExpiresAt) {
+		return nil, ErrStreamJobNotFound
+	}
+	return job, nil
+}
+
+// Run streams job. calculate must pass the job's results to its callback
+// as they are produced, stopping when the callback returns false. Run
+// emits a progress event first, then each result, a progress event about
+// every percent, and a done event at the end, and returns the progress
+// reached. It stops as soon as emit returns false.
+func (r *StreamJobRunner) Run(job *StreamJob, calculate func(func(dto.BatchItemResult) bool), emit func(StreamEvent) bool) dto.JobProgress {
+	progress := dto.JobProgress{Total: len(job.Items)}
+	if !emit(StreamEvent{Name: StreamEventProgress, Data: progress}) {
+		return progress
+	}
+
+	every := max(1, progress.Total/progressSteps)
+	stopped := false
+	calculate(func(result dto.BatchItemResult) bool {
+		progress.Completed++
+		if result.Error != nil {
+			progress.Failed++
+		} else {
+			progress.Succeeded++
+		}
+
+		if !emit(StreamEvent{Name: StreamEventResult, Data: result}) {
+			stopped = true
+			return false
+		}
+		if progress.Completed%every == 0 && progress.Completed < progress.Total {
+			if !emit(StreamEvent{Name: StreamEventProgress, Data: progress}) {
+				stopped = true
+				return false
+			}
+		}
+		return true
+	})
+
+	if !stopped {
+		emit(StreamEvent{Name: StreamEventDone, Data: progress})
+	}
+	return progress
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+)
+
+func TestStreamJobRunner_SubmitAndClaim(t *testing.T) {
+	items := []dto.CalculatePacksRequest{{ItemsOrdered: 251}}
+
+	t.Run("a job is claimed once by its owner", func(t *testing.T) {
+		runner := NewStreamJobRunner(time.Minute, 10)
+		job, err := runner.Submit("user-1", items)
+		require.NoError(t, err)
+		assert.NotEmpty(t, job.ID)
+
+		_, err = runner.Claim(job.ID, "user-2")
+		assert.ErrorIs(t, err, ErrStreamJobNotFound)
+
+		claimed, err := runner.Claim(job.ID, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, items, claimed.Items)
+
+		_, err = runner.Claim(job.ID, "user-1")
+		assert.ErrorIs(t, err, ErrStreamJobNotFound)
+	})
+
+	t.Run("expired jobs cannot be claimed and free their slot", func(t *testing.T) {
+		now := time.Date(2026, 1, 28, 10, 0, 0, 0, time.UTC)
+		runner := NewStreamJobRunner(time.Minute, 1)
+		runner.now = func() time.Time { return now }
+
+		job, err := runner.Submit("", items)
+		require.NoError(t, err)
+		_, err = runner.Submit("", items)
+		assert.ErrorIs(t, err, ErrTooManyStreamJobs)
+
+		now = now.Add(time.Minute)
+		_, err = runner.Claim(job.ID, "")
+		assert.ErrorIs(t, err, ErrStreamJobNotFound)
+		_, err = runner.Submit("", items)
+		assert.NoError(t, err)
+	})
+}
+
+func TestStreamJobRunner_Run(t *testing.T) {
+	runner := NewStreamJobRunner(0, 0)
+	job := &StreamJob{Items: make([]dto.CalculatePacksRequest, 3)}
+	calculate := func(emit func(dto.BatchItemResult) bool) {
+		for i := range job.Items {
+			result := dto.BatchItemResult{Index: i}
+			if i == 1 {
+				result.Error = &dto.BatchItemError{Code: dto.ErrCodeInvalidRequest}
+			}
+			if !emit(result) {
+				return
+			}
+		}
+	}
+
+	t.Run("emits results between progress and done events", func(t *testing.T) {
+		var names []string
+		progress := runner.Run(job, calculate, func(event StreamEvent) bool {
+			names = append(names, event.Name)
+			return true
+		})
+
+		assert.Equal(t, []string{
+			StreamEventProgress,
+			StreamEventResult, StreamEventProgress,
+			StreamEventResult, StreamEventProgress,
+			StreamEventResult,
+			StreamEventDone,
+		}, names)
+		assert.Equal(t, dto.JobProgress{Completed: 3, Total: 3, Succeeded: 2, Failed: 1}, progress)
+	})
+
+	t.Run("stops when the client goes away", func(t *testing.T) {
+		events := 0
+		progress := runner.Run(job, calculate, func(StreamEvent) bool {
+			events++
+			return events < 2
+		})
+
+		assert.Equal(t, 2, events)
+		assert.Equal(t, 1, progress.Completed)
+	})
+}
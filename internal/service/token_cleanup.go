@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+// tokenCleanupTimeout bounds one scheduled run.
+const tokenCleanupTimeout = time.Minute
+
+// TokenCleanupJob deletes expired refresh and blacklist tokens. The TTL
+// index on expires_at deletes them as well, but MongoDB's TTL monitor runs
+// about once a minute and falls behind under load, so the job keeps the
+// collection small on its own schedule. Deleting is idempotent, so every
+// instance may run the job.
+type TokenCleanupJob struct {
+	repo     repository.TokenRepositoryInterface
+	interval time.Duration
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewTokenCleanupJob creates a cleanup job deleting through repo. Call Start
+// to run it every interval; Run cleans up once, as the admin trigger does.
+func NewTokenCleanupJob(repo repository.TokenRepositoryInterface, interval time.Duration) *TokenCleanupJob {
+	return &TokenCleanupJob{
+		repo:     repo,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start runs the job now and then every interval until Stop is called.
+func (j *TokenCleanupJob) Start() {
+	go func() {
+		defer close(j.doneCh)
+
+		j.runInBackground()
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				j.runInBackground()
+			case <-j.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the job and waits for a running cleanup to finish.
+func (j *TokenCleanupJob) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stopCh)
+		<-j.doneCh
+	})
+}
+
+// Run deletes the expired tokens now and returns how many it deleted.
+func (j *TokenCleanupJob) Run(ctx context.Context) (int64, error) {
+	if j.repo == nil {
+		return 0, ErrRepositoryNotConfigured
+	}
+
+	deleted, err := j.repo.CleanupExpired(ctx)
+	metrics.RecordTokenCleanup(deleted, err)
+	return deleted, err
+}
+
+func (j *TokenCleanupJob) runInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCleanupTimeout)
+	defer cancel()
+
+	deleted, err := j.Run(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to delete expired tokens")
+		return
+	}
+	log.Debug().Int64("deleted", deleted).Msg("Deleted expired tokens")
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestTokenCleanupJob_Run(t *testing.T) {
+	t.Run("returns the deleted count", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		repo.EXPECT().CleanupExpired(mock.Anything).Return(int64(7), nil).Once()
+
+		deleted, err := service.NewTokenCleanupJob(repo, time.Hour).Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), deleted)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := mocks.NewMockTokenRepositoryInterface(t)
+		repo.EXPECT().CleanupExpired(mock.Anything).Return(int64(0), errors.New("db down")).Once()
+
+		_, err := service.NewTokenCleanupJob(repo, time.Hour).Run(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("without repository", func(t *testing.T) {
+		_, err := service.NewTokenCleanupJob(nil, time.Hour).Run(context.Background())
+		assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	})
+}
+
+func TestTokenCleanupJob_StartRunsImmediately(t *testing.T) {
+	repo := mocks.NewMockTokenRepositoryInterface(t)
+	ran := make(chan struct{}, 1)
+	repo.EXPECT().CleanupExpired(mock.Anything).
+		RunAndReturn(func(context.Context) (int64, error) {
+			ran <- struct{}{}
+			return 0, nil
+		}).Once()
+
+	job := service.NewTokenCleanupJob(repo, time.Hour)
+	job.Start()
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("cleanup did not run on start")
+	}
+	job.Stop()
+	job.Stop()
+}
@@ -4,15 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
 	"github.com/guttosm/pack-service/internal/domain/dto"
 	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/jwks"
+	"github.com/guttosm/pack-service/internal/metrics"
 	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
 )
 
 // TokenService provides token-related operations.
@@ -27,6 +36,8 @@ type TokenService interface {
 	InvalidateAccessToken(ctx context.Context, tokenString string) error
 	// InvalidateUserTokens removes all refresh tokens for a user.
 	InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID) error
+	// RevokeUserTokens revokes all access and refresh tokens of a user.
+	RevokeUserTokens(ctx context.Context, userID primitive.ObjectID) error
 	// DeleteRefreshToken removes a specific refresh token.
 	DeleteRefreshToken(ctx context.Context, tokenString string) error
 	// FindRefreshToken finds a refresh token by its string value.
@@ -40,14 +51,90 @@ type TokenServiceImpl struct {
 	accessTokenTTL   time.Duration
 	refreshTokenTTL  time.Duration
 	tokenRepo        repository.TokenRepositoryInterface
+	revocation       string
+	versions         *TokenVersionCache
+	legacyCutoff     time.Time
+	secretKeyCutoff  time.Time
+	serviceKeys      *jwks.KeySet
+	signingKeys      *SigningKeyRing
+	serviceTokens    ServiceTokenConfig
+	degraded         bool
+	degradedWarnedAt atomic.Int64
+	now              func() time.Time
 }
 
+// degradedWarnInterval spaces the warnings logged while access tokens are
+// accepted without their blacklist or token version check.
+const degradedWarnInterval = time.Minute
+
+// Token revocation modes, see config.AuthConfig.TokenRevocation.
+const (
+	TokenRevocationBlacklist = "blacklist"
+	TokenRevocationVersion   = "version"
+)
+
 // TokenConfig holds configuration for the token service.
 type TokenConfig struct {
 	SecretKey        string
 	RefreshSecretKey string
 	AccessTokenTTL   time.Duration
 	RefreshTokenTTL  time.Duration
+	Revocation       string
+	// LegacyTokenCutoff is when refresh tokens stored in plaintext stop being
+	// accepted. Zero accepts them until they are migrated or expire.
+	LegacyTokenCutoff time.Time
+	// SecretKeyCutoff is when tokens without kid, signed with SecretKey or
+	// RefreshSecretKey, stop being accepted, so those secrets can be retired
+	// once signing keys are in use. Zero accepts them.
+	SecretKeyCutoff time.Time
+	// DegradedMode accepts access tokens by signature and expiry alone while
+	// the circuit breaker in front of their revocation checks is open.
+	DegradedMode bool
+}
+
+// ServiceTokenConfig configures the service tokens accepted with
+// WithServiceTokens.
+type ServiceTokenConfig struct {
+	// Issuer is the iss claim service tokens must carry; empty accepts any.
+	Issuer string
+	// Audience is a value the aud claim of service tokens must hold; empty
+	// accepts any.
+	Audience string
+	// Roles are the role IDs granted to every service token, which decide
+	// the routes services may call.
+	Roles []string
+}
+
+// TokenServiceOption configures a TokenService.
+type TokenServiceOption func(*TokenServiceImpl)
+
+// WithTokenVersions checks access tokens against the users' current token
+// version, so RevokeUserTokens also revokes access tokens already issued.
+func WithTokenVersions(cache *TokenVersionCache) TokenServiceOption {
+	return func(s *TokenServiceImpl) {
+		s.versions = cache
+	}
+}
+
+// WithServiceTokens also accepts RS256 access tokens signed by a key of
+// keys, so other services can call the API with the tokens their platform
+// issues. Their subject becomes Claims.Service and they are granted
+// cfg.Roles. A nil keys leaves them rejected.
+func WithServiceTokens(keys *jwks.KeySet, cfg ServiceTokenConfig) TokenServiceOption {
+	return func(s *TokenServiceImpl) {
+		s.serviceKeys = keys
+		s.serviceTokens = cfg
+	}
+}
+
+// WithSigningKeys signs tokens with the newest key of keys and accepts those
+// signed by any of its active keys, so keys can be rotated without signing
+// everyone out. Tokens without kid keep using the configured secret keys
+// until TokenConfig.SecretKeyCutoff.
+func WithSigningKeys(keys *SigningKeyRing) TokenServiceOption {
+	return func(s *TokenServiceImpl) {
+		s.signingKeys = keys
+	}
 }
 
 // NewTokenConfigFromAuthConfig creates TokenConfig from config.AuthConfig.
@@ -57,18 +144,37 @@ func NewTokenConfigFromAuthConfig(authConfig config.AuthConfig) TokenConfig {
 		RefreshSecretKey: authConfig.JWTRefreshSecret,
 		AccessTokenTTL:   authConfig.AccessTokenTTL,
 		RefreshTokenTTL:  authConfig.RefreshTokenTTL,
+		Revocation:       authConfig.TokenRevocation,
+
+		LegacyTokenCutoff: authConfig.LegacyTokenCutoff,
+		SecretKeyCutoff:   authConfig.SecretKeyCutoff,
+		DegradedMode:      authConfig.DegradedMode,
 	}
 }
 
-// NewTokenService creates a new token service.
-func NewTokenService(tokenRepo repository.TokenRepositoryInterface, cfg TokenConfig) TokenService {
-	return &TokenServiceImpl{
+// NewTokenService creates a new token service. An empty or unknown
+// revocation mode uses TokenRevocationBlacklist.
+func NewTokenService(tokenRepo repository.TokenRepositoryInterface, cfg TokenConfig, opts ...TokenServiceOption) TokenService {
+	s := &TokenServiceImpl{
 		secretKey:        []byte(cfg.SecretKey),
 		refreshSecretKey: []byte(cfg.RefreshSecretKey),
 		accessTokenTTL:   cfg.AccessTokenTTL,
 		refreshTokenTTL:  cfg.RefreshTokenTTL,
 		tokenRepo:        tokenRepo,
+		revocation:       TokenRevocationBlacklist,
+		legacyCutoff:     cfg.LegacyTokenCutoff,
+		secretKeyCutoff:  cfg.SecretKeyCutoff,
+		degraded:         cfg.DegradedMode,
+		now:              timeutil.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	// Without token versions nothing else revokes access tokens
+	if cfg.Revocation == TokenRevocationVersion && s.versions != nil {
+		s.revocation = TokenRevocationVersion
+	}
+	return s
 }
 
 // GenerateTokenPair generates a new access and refresh token pair for a user.
@@ -77,7 +183,10 @@ func (s *TokenServiceImpl) GenerateTokenPair(ctx context.Context, user *model.Us
 		return nil, errors.New("user ID is zero, cannot create token")
 	}
 
-	accessToken, err := s.generateAccessToken(user)
+	// Tokens are sender-constrained when the caller verified a DPoP proof
+	jkt := dpop.ThumbprintFromContext(ctx)
+
+	accessToken, err := s.generateAccessToken(user, jkt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -87,11 +196,13 @@ func (s *TokenServiceImpl) GenerateTokenPair(ctx context.Context, user *model.Us
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
-	// Store refresh token
+	// Store a hash of the refresh token: a leaked tokens collection must not
+	// let anyone sign in
 	token := &model.Token{
 		UserID:    user.ID,
-		Token:     refreshToken,
+		Token:     model.HashToken(refreshToken),
 		Type:      "refresh",
+		JKT:       jkt,
 		ExpiresAt: refreshExpiresAt,
 	}
 	if err := s.tokenRepo.Create(ctx, token); err != nil {
@@ -106,43 +217,124 @@ func (s *TokenServiceImpl) GenerateTokenPair(ctx context.Context, user *model.Us
 }
 
 // ValidateAccessToken validates an access token and returns its claims.
+// Tokens issued before the user's token version was last incremented are
+// rejected with ErrTokenRevoked. With WithServiceTokens, RS256 tokens are
+// verified against the issuer's key set instead of the secret key.
 func (s *TokenServiceImpl) ValidateAccessToken(ctx context.Context, tokenString string) (*dto.Claims, error) {
-	// Check if token is blacklisted
-	isBlacklisted, err := s.tokenRepo.IsBlacklisted(ctx, tokenString)
-	if err != nil {
-		return nil, err
-	}
-	if isBlacklisted {
-		return nil, ErrTokenBlacklisted
+	// Service tokens are never blacklisted: logout only revokes our own
+	if s.serviceKeys != nil && signingAlg(tokenString) == jwt.SigningMethodRS256.Alg() {
+		return s.validateServiceToken(ctx, tokenString)
 	}
 
 	// Parse and validate the token
-	token, err := jwt.ParseWithClaims(tokenString, &ClaimsWithJWT{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return s.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &ClaimsWithJWT{}, s.keyFunc(false), jwt.WithTimeFunc(s.now))
 
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
 
-	if claimsWithJWT, ok := token.Claims.(*ClaimsWithJWT); ok && token.Valid {
-		return &claimsWithJWT.Claims, nil
+	claimsWithJWT, ok := token.Claims.(*ClaimsWithJWT)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
 	}
 
-	return nil, ErrInvalidToken
+	// Check if token is blacklisted. Only verified tokens get here, so forged
+	// ones cannot fill the blacklist cache or load the database.
+	var unchecked []string
+	if s.revocation == TokenRevocationBlacklist {
+		isBlacklisted, err := s.tokenRepo.IsBlacklisted(ctx, tokenString)
+		switch {
+		case s.skipsCheck(err):
+			unchecked = append(unchecked, "blacklist")
+		case err != nil:
+			return nil, err
+		case isBlacklisted:
+			return nil, ErrTokenBlacklisted
+		}
+	}
+
+	if s.versions != nil {
+		version, err := s.versions.Get(ctx, claimsWithJWT.UserID)
+		switch {
+		case s.skipsCheck(err):
+			unchecked = append(unchecked, "token_version")
+		case err != nil:
+			return nil, err
+		case claimsWithJWT.TokenVersion < version:
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	for _, check := range unchecked {
+		s.warnDegraded(check)
+	}
+	return &claimsWithJWT.Claims, nil
+}
+
+// skipsCheck reports whether a revocation check that failed with err is
+// skipped: in degraded mode, when the database is known to be down.
+func (s *TokenServiceImpl) skipsCheck(err error) bool {
+	return s.degraded && errors.Is(err, circuitbreaker.ErrCircuitOpen)
+}
+
+// warnDegraded records an otherwise valid access token accepted without its
+// check, logging it at most once per degradedWarnInterval.
+func (s *TokenServiceImpl) warnDegraded(check string) {
+	metrics.RecordDegradedTokenCheck(check)
+
+	now := s.now()
+	last := s.degradedWarnedAt.Load()
+	if now.Sub(time.Unix(0, last)) < degradedWarnInterval || !s.degradedWarnedAt.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	log.Warn().Str("check", check).
+		Msg("Auth database unavailable, accepting access tokens by signature and expiry only; revoked tokens are accepted until they expire")
+}
+
+// validateServiceToken verifies a token signed by the service token issuer,
+// with the key its kid header names.
+func (s *TokenServiceImpl) validateServiceToken(ctx context.Context, tokenString string) (*dto.Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	}
+	if s.serviceTokens.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.serviceTokens.Issuer))
+	}
+	if s.serviceTokens.Audience != "" {
+		opts = append(opts, jwt.WithAudience(s.serviceTokens.Audience))
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.serviceKeys.Key(ctx, kid)
+	}, opts...)
+	if err != nil || !token.Valid || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return &dto.Claims{
+		Name:    claims.Subject,
+		Roles:   slices.Clone(s.serviceTokens.Roles),
+		Service: claims.Subject,
+	}, nil
+}
+
+// signingAlg returns the alg header of a token, without verifying it, or ""
+// when it is malformed.
+func signingAlg(tokenString string) string {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return ""
+	}
+	return token.Method.Alg()
 }
 
 // ValidateRefreshToken validates a refresh token and returns its claims.
 func (s *TokenServiceImpl) ValidateRefreshToken(tokenString string) (*dto.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &ClaimsWithJWT{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return s.refreshSecretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &ClaimsWithJWT{}, s.keyFunc(true), jwt.WithTimeFunc(s.now))
 
 	if err != nil {
 		return nil, ErrInvalidToken
@@ -155,11 +347,14 @@ func (s *TokenServiceImpl) ValidateRefreshToken(tokenString string) (*dto.Claims
 	return nil, ErrInvalidToken
 }
 
-// InvalidateAccessToken blacklists an access token.
+// InvalidateAccessToken blacklists an access token. In version revocation
+// mode it does nothing: the token is short-lived and expires on its own.
 func (s *TokenServiceImpl) InvalidateAccessToken(ctx context.Context, tokenString string) error {
-	token, err := jwt.ParseWithClaims(tokenString, &ClaimsWithJWT{}, func(token *jwt.Token) (interface{}, error) {
-		return s.secretKey, nil
-	})
+	if s.revocation == TokenRevocationVersion {
+		return nil
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &ClaimsWithJWT{}, s.keyFunc(false), jwt.WithTimeFunc(s.now))
 
 	if err != nil {
 		return err
@@ -170,7 +365,7 @@ func (s *TokenServiceImpl) InvalidateAccessToken(ctx context.Context, tokenStrin
 		return ErrInvalidToken
 	}
 
-	expiresAt := time.Now().Add(s.accessTokenTTL)
+	expiresAt := s.now().Add(s.accessTokenTTL)
 	if claimsWithJWT.ExpiresAt != nil {
 		expiresAt = claimsWithJWT.ExpiresAt.Time
 	}
@@ -190,61 +385,178 @@ func (s *TokenServiceImpl) InvalidateUserTokens(ctx context.Context, userID prim
 	return s.tokenRepo.DeleteByUserID(ctx, userID, "refresh")
 }
 
-// DeleteRefreshToken removes a specific refresh token.
+// RevokeUserTokens increments the user's token version, which revokes all
+// access tokens issued so far, and removes the user's refresh tokens.
+// Without token versions, access tokens stay valid until they expire.
+func (s *TokenServiceImpl) RevokeUserTokens(ctx context.Context, userID primitive.ObjectID) error {
+	if s.versions != nil {
+		if _, err := s.versions.Increment(ctx, userID); err != nil {
+			return fmt.Errorf("failed to increment token version: %w", err)
+		}
+	}
+	return s.tokenRepo.DeleteByUserID(ctx, userID, "refresh")
+}
+
+// DeleteRefreshToken removes a specific refresh token, whether it is stored
+// hashed or, before the legacy cutoff, in plaintext.
 func (s *TokenServiceImpl) DeleteRefreshToken(ctx context.Context, tokenString string) error {
-	return s.tokenRepo.DeleteByToken(ctx, tokenString)
+	if err := s.tokenRepo.DeleteByToken(ctx, model.HashToken(tokenString)); err != nil {
+		return err
+	}
+	if s.acceptsLegacyTokens() {
+		return s.tokenRepo.DeleteByToken(ctx, tokenString)
+	}
+	return nil
 }
 
-// FindRefreshToken finds a refresh token by its string value.
+// FindRefreshToken finds a refresh token by its string value. Before the
+// legacy cutoff, tokens still stored in plaintext are found too.
 func (s *TokenServiceImpl) FindRefreshToken(ctx context.Context, tokenString string) (*model.Token, error) {
-	return s.tokenRepo.FindByToken(ctx, tokenString)
+	hashed := model.HashToken(tokenString)
+	token, err := s.tokenRepo.FindByToken(ctx, hashed)
+	if err != nil || token != nil || !s.acceptsLegacyTokens() {
+		return token, err
+	}
+
+	token, err = s.tokenRepo.FindByToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if token != nil {
+		metrics.RecordLegacyRefreshTokenRead()
+		return token, nil
+	}
+	// The migration may have hashed it between the two lookups
+	return s.tokenRepo.FindByToken(ctx, hashed)
 }
 
-// generateAccessToken creates a new JWT access token for a user.
-func (s *TokenServiceImpl) generateAccessToken(user *model.User) (string, error) {
-	expirationTime := time.Now().Add(s.accessTokenTTL)
+// acceptsLegacyTokens reports whether refresh tokens stored in plaintext
+// are still accepted.
+func (s *TokenServiceImpl) acceptsLegacyTokens() bool {
+	return s.legacyCutoff.IsZero() || s.now().Before(s.legacyCutoff)
+}
+
+// generateAccessToken creates a new JWT access token for a user, bound to the
+// DPoP key thumbprint jkt when it is non-empty.
+func (s *TokenServiceImpl) generateAccessToken(user *model.User, jkt string) (string, error) {
+	now := s.now()
+	expirationTime := now.Add(s.accessTokenTTL)
 
 	claims := &ClaimsWithJWT{
 		Claims: dto.Claims{
-			UserID: user.ID,
-			Email:  user.Email,
-			Name:   user.Name,
-			Roles:  user.Roles,
+			UserID:       user.ID,
+			Email:        user.Email,
+			Name:         user.Name,
+			Roles:        user.Roles,
+			TokenVersion: user.TokenVersion,
 		},
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        idgen.NewObjectID().Hex(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
+	if jkt != "" {
+		claims.Confirmation = &dto.Confirmation{JKT: jkt}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	return s.sign(claims, false)
 }
 
 // generateRefreshToken creates a new JWT refresh token for a user.
 func (s *TokenServiceImpl) generateRefreshToken(user *model.User) (string, time.Time, error) {
-	expirationTime := time.Now().Add(s.refreshTokenTTL)
+	now := s.now()
+	expirationTime := now.Add(s.refreshTokenTTL)
 
 	claims := &ClaimsWithJWT{
 		Claims: dto.Claims{
-			UserID: user.ID,
-			Email:  user.Email,
-			Name:   user.Name,
-			Roles:  user.Roles,
+			UserID:       user.ID,
+			Email:        user.Email,
+			Name:         user.Name,
+			Roles:        user.Roles,
+			TokenVersion: user.TokenVersion,
 		},
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        idgen.NewObjectID().Hex(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.refreshSecretKey)
+	tokenString, err := s.sign(claims, true)
 	if err != nil {
 		return "", time.Time{}, err
 	}
 
 	return tokenString, expirationTime, nil
 }
+
+// sign signs claims with the newest signing key, naming it in the kid
+// header, or with the configured secret key when there is none. Past the
+// secret key cutoff it fails without a signing key, rather than issue tokens
+// that are rejected.
+func (s *TokenServiceImpl) sign(claims jwt.Claims, refresh bool) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	secret := s.secretKey
+	if refresh {
+		secret = s.refreshSecretKey
+	}
+	if s.signingKeys != nil {
+		if key := s.signingKeys.signingKey(); key != nil {
+			token.Header["kid"] = key.KID
+			secret = key.Secret
+			if refresh {
+				secret = key.RefreshSecret
+			}
+		}
+	}
+	if token.Header["kid"] == nil && !s.acceptsSecretKey() {
+		return "", errSecretKeyRetired
+	}
+	return token.SignedString(secret)
+}
+
+// errSecretKeyRetired is returned when a token would be signed, or was
+// signed, with the configured secret keys past the secret key cutoff.
+var errSecretKeyRetired = errors.New("tokens without kid are no longer accepted")
+
+// acceptsSecretKey reports whether tokens without kid, signed with the
+// configured secret keys, are still accepted.
+func (s *TokenServiceImpl) acceptsSecretKey() bool {
+	return s.secretKeyCutoff.IsZero() || s.now().Before(s.secretKeyCutoff)
+}
+
+// keyFunc returns the secret verifying a token: that of the active signing
+// key its kid header names, or the configured secret key without kid until
+// the secret key cutoff.
+func (s *TokenServiceImpl) keyFunc(refresh bool) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			if !s.acceptsSecretKey() {
+				return nil, errSecretKeyRetired
+			}
+			if refresh {
+				return s.refreshSecretKey, nil
+			}
+			return s.secretKey, nil
+		}
+
+		var key *model.SigningKey
+		if s.signingKeys != nil {
+			key = s.signingKeys.key(kid)
+		}
+		if key == nil {
+			return nil, ErrSigningKeyNotFound
+		}
+		if refresh {
+			return key.RefreshSecret, nil
+		}
+		return key.Secret, nil
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// DefaultTokenVersionCacheTTL is how long a token version is cached when no TTL is configured.
+	DefaultTokenVersionCacheTTL = time.Minute
+
+	// maxCachedTokenVersions triggers a sweep of expired entries.
+	maxCachedTokenVersions = 100000
+	// tokenVersionWatchRetry is the pause before reopening a failed change stream.
+	tokenVersionWatchRetry = 30 * time.Second
+)
+
+// TokenVersionCache keeps users' token versions in memory so validating an
+// access token needs no database round trip in the common case. Entries
+// expire after ttl; Watch keeps them current across replicas in between.
+type TokenVersionCache struct {
+	repo repository.UserRepositoryInterface
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.RWMutex
+	entries map[primitive.ObjectID]tokenVersionEntry
+}
+
+type tokenVersionEntry struct {
+	version   int
+	expiresAt time.Time
+}
+
+// NewTokenVersionCache creates a token version cache reading from repo. A
+// zero ttl uses DefaultTokenVersionCacheTTL.
+func NewTokenVersionCache(repo repository.UserRepositoryInterface, ttl time.Duration) *TokenVersionCache {
+	if ttl <= 0 {
+		ttl = DefaultTokenVersionCacheTTL
+	}
+	return &TokenVersionCache{
+		repo:    repo,
+		ttl:     ttl,
+		now:     timeutil.Now,
+		entries: make(map[primitive.ObjectID]tokenVersionEntry),
+	}
+}
+
+// Get returns the user's current token version, reading it from the
+// repository when it is not cached.
+func (c *TokenVersionCache) Get(ctx context.Context, userID primitive.ObjectID) (int, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[userID]
+	c.mu.RUnlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		metrics.RecordCacheOperation("token_version_get", "hit")
+		return entry.version, nil
+	}
+	metrics.RecordCacheOperation("token_version_get", "miss")
+
+	version, err := c.repo.GetTokenVersion(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	c.Set(userID, version)
+	return version, nil
+}
+
+// Increment bumps the user's token version, revoking every token issued
+// before, and returns the new version.
+func (c *TokenVersionCache) Increment(ctx context.Context, userID primitive.ObjectID) (int, error) {
+	version, err := c.repo.IncrementTokenVersion(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	c.Set(userID, version)
+	return version, nil
+}
+
+// Set caches a token version. An older version never replaces a newer one,
+// so a lookup racing with a revocation cannot restore revoked tokens.
+func (c *TokenVersionCache) Set(userID primitive.ObjectID, version int) {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[userID]; ok && entry.version > version && now.Before(entry.expiresAt) {
+		return
+	}
+	if len(c.entries) >= maxCachedTokenVersions {
+		c.sweep(now)
+	}
+	c.entries[userID] = tokenVersionEntry{version: version, expiresAt: now.Add(c.ttl)}
+}
+
+// sweep drops expired entries, or all of them when none has expired. The
+// caller must hold mu.
+func (c *TokenVersionCache) sweep(now time.Time) {
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	if len(c.entries) >= maxCachedTokenVersions {
+		clear(c.entries)
+	}
+}
+
+// Watch applies token version changes reported by watcher until ctx is done,
+// reopening the stream after failures. Without change streams (a standalone
+// MongoDB), changes made by other replicas show up once entries expire.
+func (c *TokenVersionCache) Watch(ctx context.Context, watcher repository.TokenVersionWatcher) {
+	go func() {
+		for failures := 0; ; failures++ {
+			err := watcher.WatchTokenVersions(ctx, c.Set)
+			if ctx.Err() != nil {
+				return
+			}
+			event := log.Debug()
+			if failures == 0 {
+				event = log.Warn()
+			}
+			event.Err(err).Dur("retry_in", tokenVersionWatchRetry).
+				Msg("Token version change stream stopped, relying on cache TTL")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(tokenVersionWatchRetry):
+			}
+		}
+	}()
+}
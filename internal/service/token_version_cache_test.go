@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+// fakeTokenVersionWatcher delivers the versions sent on changes.
+type fakeTokenVersionWatcher struct {
+	changes chan int
+	userID  primitive.ObjectID
+}
+
+func (w *fakeTokenVersionWatcher) WatchTokenVersions(ctx context.Context, fn func(primitive.ObjectID, int)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case version := <-w.changes:
+			fn(w.userID, version)
+		}
+	}
+}
+
+func TestTokenVersionCache_Get(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	cache := service.NewTokenVersionCache(userRepo, time.Minute)
+	userID := primitive.NewObjectID()
+
+	userRepo.EXPECT().GetTokenVersion(mock.Anything, userID).Return(4, nil).Once()
+	for i := 0; i < 3; i++ {
+		version, err := cache.Get(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, version)
+	}
+}
+
+func TestTokenVersionCache_GetError(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	cache := service.NewTokenVersionCache(userRepo, time.Minute)
+	userID := primitive.NewObjectID()
+
+	userRepo.EXPECT().GetTokenVersion(mock.Anything, userID).Return(0, errors.New("db down")).Once()
+	_, err := cache.Get(context.Background(), userID)
+	assert.Error(t, err)
+
+	// Failures are not cached
+	userRepo.EXPECT().GetTokenVersion(mock.Anything, userID).Return(1, nil).Once()
+	version, err := cache.Get(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+}
+
+func TestTokenVersionCache_ExpiredEntriesAreReloaded(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	cache := service.NewTokenVersionCache(userRepo, 10*time.Millisecond)
+	userID := primitive.NewObjectID()
+
+	userRepo.EXPECT().GetTokenVersion(mock.Anything, userID).Return(1, nil).Once()
+	_, err := cache.Get(context.Background(), userID)
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	userRepo.EXPECT().GetTokenVersion(mock.Anything, userID).Return(2, nil).Once()
+	version, err := cache.Get(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+}
+
+func TestTokenVersionCache_SetKeepsNewerVersion(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	cache := service.NewTokenVersionCache(userRepo, time.Minute)
+	userID := primitive.NewObjectID()
+
+	userRepo.EXPECT().IncrementTokenVersion(mock.Anything, userID).Return(5, nil)
+	version, err := cache.Increment(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, version)
+
+	// A stale lookup finishing after the increment
+	cache.Set(userID, 4)
+
+	version, err = cache.Get(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, version)
+}
+
+func TestTokenVersionCache_Watch(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	cache := service.NewTokenVersionCache(userRepo, time.Minute)
+	watcher := &fakeTokenVersionWatcher{changes: make(chan int), userID: primitive.NewObjectID()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache.Watch(ctx, watcher)
+
+	// Delivered by another replica's revocation
+	watcher.changes <- 7
+	assert.Eventually(t, func() bool {
+		version, err := cache.Get(context.Background(), watcher.userID)
+		return err == nil && version == 7
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/guttosm/pack-service/internal/domain/domainerr"
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/i18n"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+const (
+	// DefaultDeletedUserRetention is how long deactivated users are kept
+	// before they can be purged, when no retention is configured.
+	DefaultDeletedUserRetention = 30 * 24 * time.Hour
+	// DefaultUserPageSize is the page size used when a listing sets no limit.
+	DefaultUserPageSize = 50
+	// MaxUserPageSize caps the page size of a user listing.
+	MaxUserPageSize = 200
+)
+
+var (
+	// ErrUserNotFound is returned when a user does not exist.
+	ErrUserNotFound = domainerr.New("user_not_found", http.StatusNotFound, i18n.ErrKeyUserNotFound, "user not found")
+	// ErrInvalidUser is returned when a user update fails validation.
+	ErrInvalidUser = domainerr.New("invalid_user", http.StatusBadRequest, "", "invalid user")
+	// ErrSelfDeactivation is returned when an admin tries to deactivate their own account.
+	ErrSelfDeactivation = domainerr.New("self_deactivation", http.StatusConflict, "", "cannot deactivate your own account")
+	// ErrIncorrectPassword is returned when a password change gives the
+	// wrong current password.
+	ErrIncorrectPassword = domainerr.New("incorrect_password", http.StatusForbidden, i18n.ErrKeyIncorrectPassword, "current password is incorrect")
+	// ErrUserNotPurgeable is returned when a user is purged while still
+	// active or within the deleted user retention.
+	ErrUserNotPurgeable = domainerr.New("user_not_purgeable", http.StatusConflict, "", "user cannot be purged")
+)
+
+// UserService provides user administration.
+type UserService interface {
+	List(ctx context.Context, filter dto.UserFilter, limit, offset int) (*dto.UserPage, error)
+	Get(ctx context.Context, id string) (*model.User, error)
+	Update(ctx context.Context, id string, update *dto.UpdateUserRequest) (*model.User, error)
+	// UpdateProfile applies the changes users make to their own account.
+	// A password change requires the current password and signs the user
+	// out everywhere.
+	UpdateProfile(ctx context.Context, id string, update *dto.UpdateProfileRequest) (*model.User, error)
+	// Deactivate disables the account and revokes its tokens.
+	// actorID is the admin making the change, who cannot deactivate themselves.
+	Deactivate(ctx context.Context, id, actorID string) (*model.User, error)
+	// Disable deactivates the account, if it is not already, and revokes its
+	// tokens, failing when they cannot be revoked. actorID is the admin
+	// making the change, who cannot disable themselves.
+	Disable(ctx context.Context, id, actorID string) (*model.User, error)
+	// RevokeSessions revokes every access and refresh token of the user,
+	// leaving the account active.
+	RevokeSessions(ctx context.Context, id string) (*model.User, error)
+	// Reactivate re-enables a deactivated user. It is Restore.
+	Reactivate(ctx context.Context, id string) (*model.User, error)
+	// Restore undoes a soft delete, re-enabling the user.
+	Restore(ctx context.Context, id string) (*model.User, error)
+	// HardDelete purges a deactivated user once the deleted user retention
+	// has passed, together with their tokens, and returns the purged user.
+	HardDelete(ctx context.Context, id string) (*model.User, error)
+	// ListStale returns a page of active users without a login in the last
+	// days days, or in the configured default when days is zero.
+	ListStale(ctx context.Context, days, limit, offset int) (*dto.StaleUserPage, error)
+	// DeactivateStale deactivates the active users whose last login is more
+	// than days days old and returns them. Users with no recorded login are
+	// left alone.
+	DeactivateStale(ctx context.Context, days int) ([]*model.User, error)
+	// Import creates users all or none, returning a *UserImportError that
+	// lists the invalid users when any is.
+	Import(ctx context.Context, users []dto.ImportUser) (*dto.ImportUsersResult, error)
+	// EffectivePermissions resolves the permissions the user's roles grant.
+	EffectivePermissions(ctx context.Context, id string) (*dto.EffectivePermissions, error)
+}
+
+// UserServiceImpl implements UserService.
+type UserServiceImpl struct {
+	userRepo       repository.UserRepositoryInterface
+	roleRepo       repository.RoleRepositoryInterface
+	permissionRepo repository.PermissionRepositoryInterface
+	tokenRepo      repository.TokenRepositoryInterface
+	authService    AuthService
+	staleDays      int
+	retention      time.Duration
+}
+
+// UserServiceOption configures a UserServiceImpl.
+type UserServiceOption func(*UserServiceImpl)
+
+// WithStaleAccountDays sets how many days without a login make an account
+// stale when ListStale is given none. Non-positive values are ignored.
+func WithStaleAccountDays(days int) UserServiceOption {
+	return func(s *UserServiceImpl) {
+		if days > 0 {
+			s.staleDays = days
+		}
+	}
+}
+
+// WithPermissionRepository sets the repository EffectivePermissions reads
+// permissions from.
+func WithPermissionRepository(repo repository.PermissionRepositoryInterface) UserServiceOption {
+	return func(s *UserServiceImpl) {
+		s.permissionRepo = repo
+	}
+}
+
+// WithTokenRepository sets the repository HardDelete deletes the tokens of
+// purged users from.
+func WithTokenRepository(repo repository.TokenRepositoryInterface) UserServiceOption {
+	return func(s *UserServiceImpl) {
+		s.tokenRepo = repo
+	}
+}
+
+// WithDeletedUserRetention sets how long a deactivated user is kept before
+// HardDelete purges it. Negative values are ignored; zero allows purging
+// right away.
+func WithDeletedUserRetention(retention time.Duration) UserServiceOption {
+	return func(s *UserServiceImpl) {
+		if retention >= 0 {
+			s.retention = retention
+		}
+	}
+}
+
+// NewUserService creates a new user service. authService revokes the
+// sessions of deactivated users and may be nil.
+func NewUserService(userRepo repository.UserRepositoryInterface, roleRepo repository.RoleRepositoryInterface, authService AuthService, opts ...UserServiceOption) UserService {
+	s := &UserServiceImpl{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		authService: authService,
+		staleDays:   DefaultStaleAccountDays,
+		retention:   DefaultDeletedUserRetention,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// List returns a page of users matching filter, newest first.
+func (s *UserServiceImpl) List(ctx context.Context, filter dto.UserFilter, limit, offset int) (*dto.UserPage, error) {
+	if s.userRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if limit <= 0 {
+		limit = DefaultUserPageSize
+	}
+	if limit > MaxUserPageSize {
+		limit = MaxUserPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := bson.M{}
+	if filter.Active != nil {
+		query["active"] = *filter.Active
+	}
+	if filter.Role != "" {
+		role, err := s.findRole(ctx, filter.Role)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return &dto.UserPage{Users: []*model.User{}, Limit: limit, Offset: offset}, nil
+		}
+		query["roles"] = role.ID.Hex()
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+		query["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"username": pattern},
+			bson.M{"name": pattern},
+		}
+	}
+
+	users, err := s.userRepo.List(ctx, query, int64(limit), int64(offset))
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.userRepo.Count(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []*model.User{}
+	}
+
+	return &dto.UserPage{Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Get returns the user with the given ID, or ErrUserNotFound.
+func (s *UserServiceImpl) Get(ctx context.Context, id string) (*model.User, error) {
+	if s.userRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.userRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Update applies the profile and role changes in update. Role changes reach
+// the user's access tokens on their next refresh.
+func (s *UserServiceImpl) Update(ctx context.Context, id string, update *dto.UpdateUserRequest) (*model.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Email != nil {
+		email := strings.TrimSpace(*update.Email)
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return nil, fmt.Errorf("%w: email is not a valid address", ErrInvalidUser)
+		}
+		user.Email = email
+	}
+	if update.Username != nil {
+		username := strings.TrimSpace(*update.Username)
+		if len(username) < 3 || len(username) > 30 {
+			return nil, fmt.Errorf("%w: username must be 3-30 characters", ErrInvalidUser)
+		}
+		user.Username = username
+	}
+	if update.Name != nil {
+		user.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Roles != nil {
+		roleIDs, err := s.resolveRoles(ctx, *update.Roles)
+		if err != nil {
+			return nil, err
+		}
+		user.Roles = roleIDs
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the name, username or password of the user with the
+// given ID. Changing the password revokes the user's tokens, including the
+// ones of the session making the change.
+func (s *UserServiceImpl) UpdateProfile(ctx context.Context, id string, update *dto.UpdateProfileRequest) (*model.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active {
+		return nil, ErrUserNotFound
+	}
+
+	if update.Username != nil {
+		username := strings.TrimSpace(*update.Username)
+		if len(username) < 3 || len(username) > 30 {
+			return nil, fmt.Errorf("%w: username must be 3-30 characters", ErrInvalidUser)
+		}
+		user.Username = username
+	}
+	if update.Name != nil {
+		user.Name = strings.TrimSpace(*update.Name)
+	}
+	changePassword := update.NewPassword != nil
+	if changePassword {
+		if len(*update.NewPassword) < 6 {
+			return nil, fmt.Errorf("%w: new_password must be at least 6 characters", ErrInvalidUser)
+		}
+		if update.CurrentPassword == "" {
+			return nil, fmt.Errorf("%w: current_password is required to change the password", ErrInvalidUser)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(update.CurrentPassword)) != nil {
+			return nil, ErrIncorrectPassword
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(*update.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = string(hashed)
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if changePassword && s.authService != nil {
+		if err := s.authService.RevokeUserTokens(ctx, user.ID); err != nil {
+			log.Warn().Err(err).Str("user_id", user.ID.Hex()).Msg("failed to revoke tokens after password change")
+		}
+	}
+	return user, nil
+}
+
+// Deactivate soft deletes the user and revokes their tokens. Without token
+// versions, access tokens already issued stay valid until they expire.
+func (s *UserServiceImpl) Deactivate(ctx context.Context, id, actorID string) (*model.User, error) {
+	if id == actorID {
+		return nil, ErrSelfDeactivation
+	}
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deactivate(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// deactivate soft deletes user and revokes their tokens.
+func (s *UserServiceImpl) deactivate(ctx context.Context, user *model.User) error {
+	if err := s.userRepo.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+	user.Active = false
+
+	if s.authService != nil {
+		if err := s.authService.RevokeUserTokens(ctx, user.ID); err != nil {
+			log.Warn().Err(err).Str("user_id", user.ID.Hex()).Msg("failed to revoke tokens of deactivated user")
+		}
+	}
+	return nil
+}
+
+// Disable deactivates the user and revokes their tokens, for responding to
+// a compromised account. Unlike Deactivate, it fails when the tokens cannot
+// be revoked, and it revokes them again for a user already deactivated.
+func (s *UserServiceImpl) Disable(ctx context.Context, id, actorID string) (*model.User, error) {
+	if id == actorID {
+		return nil, ErrSelfDeactivation
+	}
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Active {
+		if err := s.userRepo.Delete(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		user.Active = false
+	}
+	if err := s.revokeSessions(ctx, user); err != nil {
+		return nil, fmt.Errorf("account deactivated, but revoking its sessions failed: %w", err)
+	}
+	return user, nil
+}
+
+// RevokeSessions signs the user out everywhere: access tokens already issued
+// are rejected from the next request on, and refresh tokens are deleted.
+func (s *UserServiceImpl) RevokeSessions(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.revokeSessions(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// revokeSessions revokes the tokens of user.
+func (s *UserServiceImpl) revokeSessions(ctx context.Context, user *model.User) error {
+	if s.authService == nil {
+		return ErrRepositoryNotConfigured
+	}
+	return s.authService.RevokeUserTokens(ctx, user.ID)
+}
+
+// Reactivate re-enables a deactivated user.
+func (s *UserServiceImpl) Reactivate(ctx context.Context, id string) (*model.User, error) {
+	return s.Restore(ctx, id)
+}
+
+// Restore re-enables a soft deleted user. Active users are returned
+// unchanged; purged users are not found.
+func (s *UserServiceImpl) Restore(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Active {
+		return user, nil
+	}
+
+	if err := s.userRepo.Restore(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	user.Active = true
+	user.DeletedAt = nil
+	return user, nil
+}
+
+// HardDelete deletes a deactivated user for good, once the retention has
+// passed since it was deactivated, then deletes their refresh and blacklist
+// tokens. The retention has to outlast access tokens: a purged user has no
+// token version left to reject them.
+func (s *UserServiceImpl) HardDelete(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Active {
+		return nil, fmt.Errorf("%w: deactivate the user first", ErrUserNotPurgeable)
+	}
+	deletedAt := user.UpdatedAt
+	if user.DeletedAt != nil {
+		deletedAt = *user.DeletedAt
+	}
+	if purgeableAt := deletedAt.Add(s.retention); timeutil.Now().Before(purgeableAt) {
+		return nil, fmt.Errorf("%w: deactivated users are kept until %s", ErrUserNotPurgeable, timeutil.Format(purgeableAt))
+	}
+
+	deleted, err := s.userRepo.HardDelete(ctx, user.ID, timeutil.Now().Add(-s.retention))
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, fmt.Errorf("%w: the user was restored or purged meanwhile", ErrUserNotPurgeable)
+	}
+
+	var tokens int64
+	if s.tokenRepo != nil {
+		if tokens, err = s.tokenRepo.DeleteAllByUserID(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("user purged, but deleting its tokens failed: %w", err)
+		}
+	}
+	log.Info().Str("user_id", user.ID.Hex()).Int64("tokens_deleted", tokens).Msg("Purged user")
+	return user, nil
+}
+
+// resolveRoles maps role IDs or names to the IDs of existing, active roles.
+func (s *UserServiceImpl) resolveRoles(ctx context.Context, refs []string) ([]string, error) {
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("%w: a user needs at least one role", ErrInvalidUser)
+	}
+
+	ids := make([]string, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		role, err := s.findRole(ctx, strings.TrimSpace(ref))
+		if err != nil {
+			return nil, err
+		}
+		if role == nil || !role.Active {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidUser, ref)
+		}
+		if id := role.ID.Hex(); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// findRole looks a role up by ID, falling back to its name.
+func (s *UserServiceImpl) findRole(ctx context.Context, ref string) (*model.Role, error) {
+	if s.roleRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if id, err := primitive.ObjectIDFromHex(ref); err == nil {
+		role, err := s.roleRepo.FindByID(ctx, id)
+		if err != nil || role != nil {
+			return role, err
+		}
+	}
+	return s.roleRepo.FindByName(ctx, ref)
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"runtime"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/repository"
+)
+
+// MaxUserImportSize caps the users of one bulk import. Hashing passwords
+// takes most of an import's time, so larger migrations are split.
+const MaxUserImportSize = 5000
+
+// UserImportError rejects a bulk import: no user was created.
+type UserImportError struct {
+	// Rows maps the position of each rejected user, counting from 1, to why
+	// it was rejected.
+	Rows map[int]string
+}
+
+func (e *UserImportError) Error() string {
+	return fmt.Sprintf("%d of the users to import are invalid", len(e.Rows))
+}
+
+// Import creates users, all or none. Every user is checked first, against
+// the others and the existing users, so that a rejected import reports all
+// its invalid users at once in a *UserImportError.
+func (s *UserServiceImpl) Import(ctx context.Context, users []dto.ImportUser) (*dto.ImportUsersResult, error) {
+	if s.userRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w: no users to import", ErrInvalidUser)
+	}
+	if len(users) > MaxUserImportSize {
+		return nil, fmt.Errorf("%w: at most %d users can be imported at once", ErrInvalidUser, MaxUserImportSize)
+	}
+
+	rejected := make(map[int]string)
+	reject := func(i int, reason string) {
+		if _, ok := rejected[i+1]; !ok {
+			rejected[i+1] = reason
+		}
+	}
+
+	records := make([]*model.User, len(users))
+	passwords := make([]string, len(users))
+	emails := make(map[string]int, len(users))
+	usernames := make(map[string]int, len(users))
+	roles := make(map[string][]string)
+	for i, u := range users {
+		user, password, err := s.importRecord(ctx, u, roles)
+		if errors.Is(err, ErrInvalidUser) {
+			reject(i, strings.TrimPrefix(err.Error(), ErrInvalidUser.Error()+": "))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first, ok := emails[user.Email]; ok {
+			reject(i, fmt.Sprintf("email repeats user %d", first+1))
+			continue
+		}
+		emails[user.Email] = i
+		if user.Username != "" {
+			if first, ok := usernames[user.Username]; ok {
+				reject(i, fmt.Sprintf("username repeats user %d", first+1))
+				continue
+			}
+			usernames[user.Username] = i
+		}
+		records[i], passwords[i] = user, password
+	}
+
+	if err := s.rejectExisting(ctx, emails, usernames, reject); err != nil {
+		return nil, err
+	}
+	if len(rejected) > 0 {
+		return nil, &UserImportError{Rows: rejected}
+	}
+
+	if err := hashPasswords(records, passwords); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.CreateMany(ctx, records); err != nil {
+		// A user registered since the check above
+		var writeErr *repository.UserWriteError
+		if errors.As(err, &writeErr) && errors.Is(err, ErrUserExists) {
+			return nil, &UserImportError{Rows: map[int]string{writeErr.Index + 1: "email or username already taken"}}
+		}
+		return nil, err
+	}
+
+	result := &dto.ImportUsersResult{Imported: len(records), Users: make([]dto.ImportedUser, len(records))}
+	for i, user := range records {
+		result.Users[i] = dto.ImportedUser{Row: i + 1, ID: user.ID.Hex(), Email: user.Email}
+		if users[i].Invite {
+			result.Users[i].TemporaryPassword = passwords[i]
+		}
+	}
+	return result, nil
+}
+
+// importRecord validates u and returns the user to create with its plain
+// text password, generated for invited users. roles caches the resolved
+// roles of each role list.
+func (s *UserServiceImpl) importRecord(ctx context.Context, u dto.ImportUser, roles map[string][]string) (*model.User, string, error) {
+	email := strings.TrimSpace(u.Email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, "", fmt.Errorf("%w: email is not a valid address", ErrInvalidUser)
+	}
+	username := strings.TrimSpace(u.Username)
+	if username != "" && (len(username) < 3 || len(username) > 30) {
+		return nil, "", fmt.Errorf("%w: username must be 3-30 characters", ErrInvalidUser)
+	}
+
+	password := u.Password
+	switch {
+	case u.Invite && password != "":
+		return nil, "", fmt.Errorf("%w: set either a password or invite", ErrInvalidUser)
+	case u.Invite:
+		password = temporaryPassword()
+	case len(password) < 6:
+		return nil, "", fmt.Errorf("%w: password must be at least 6 characters", ErrInvalidUser)
+	}
+
+	refs := u.Roles
+	if len(refs) == 0 {
+		refs = []string{"user"}
+	}
+	key := strings.Join(refs, "\x00")
+	roleIDs, ok := roles[key]
+	if !ok {
+		var err error
+		if roleIDs, err = s.resolveRoles(ctx, refs); err != nil {
+			return nil, "", err
+		}
+		roles[key] = roleIDs
+	}
+
+	return &model.User{
+		Email:    email,
+		Username: username,
+		Name:     strings.TrimSpace(u.Name),
+		Roles:    roleIDs,
+		Active:   true,
+	}, password, nil
+}
+
+// rejectExisting rejects the users whose email or username is taken.
+// emails and usernames map each to the index of the user importing it.
+func (s *UserServiceImpl) rejectExisting(ctx context.Context, emails, usernames map[string]int, reject func(int, string)) error {
+	emailList := make([]string, 0, len(emails))
+	for email := range emails {
+		emailList = append(emailList, email)
+	}
+	usernameList := make([]string, 0, len(usernames))
+	for username := range usernames {
+		usernameList = append(usernameList, username)
+	}
+	if len(emailList) == 0 {
+		return nil
+	}
+
+	existing, err := s.userRepo.List(ctx, bson.M{"$or": bson.A{
+		bson.M{"email": bson.M{"$in": emailList}},
+		bson.M{"username": bson.M{"$in": usernameList}},
+	}}, int64(len(emailList)+len(usernameList)), 0)
+	if err != nil {
+		return err
+	}
+	for _, user := range existing {
+		if i, ok := emails[user.Email]; ok {
+			reject(i, "email already registered")
+		}
+		if i, ok := usernames[user.Username]; ok && user.Username != "" {
+			reject(i, "username already taken")
+		}
+	}
+	return nil
+}
+
+// hashPasswords sets the password of each user to the bcrypt hash of the
+// matching plain text one, hashing on every CPU.
+func hashPasswords(users []*model.User, passwords []string) error {
+	indexes := make(chan int)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(users)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				hash, err := bcrypt.GenerateFromPassword([]byte(passwords[i]), bcrypt.DefaultCost)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					continue
+				}
+				users[i].Password = string(hash)
+			}
+		}()
+	}
+	for i := range users {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// temporaryPassword returns a random password for an invited user.
+func temporaryPassword() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/repository"
+	"github.com/guttosm/pack-service/internal/service"
+)
+
+func TestUserService_Import(t *testing.T) {
+	userRole := &model.Role{ID: primitive.NewObjectID(), Name: "user", Active: true}
+	adminRole := &model.Role{ID: primitive.NewObjectID(), Name: "admin", Active: true}
+
+	t.Run("creates every user", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+		svc := service.NewUserService(userRepo, roleRepo, nil)
+
+		roleRepo.EXPECT().FindByName(mock.Anything, "user").Return(userRole, nil).Once()
+		roleRepo.EXPECT().FindByName(mock.Anything, "admin").Return(adminRole, nil).Once()
+		userRepo.EXPECT().List(mock.Anything, mock.Anything, int64(5), int64(0)).Return(nil, nil)
+		var created []*model.User
+		userRepo.EXPECT().CreateMany(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, users []*model.User) error {
+			for _, user := range users {
+				user.ID = primitive.NewObjectID()
+			}
+			created = users
+			return nil
+		})
+
+		result, err := svc.Import(context.Background(), []dto.ImportUser{
+			{Email: "ana@example.com", Username: "ana", Name: " Ana ", Password: "secret1"},
+			{Email: "bo@example.com", Roles: []string{"admin"}, Invite: true},
+			{Email: "cy@example.com", Username: "cyril", Password: "secret3"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, result.Imported)
+		require.Len(t, created, 3)
+		assert.Equal(t, "Ana", created[0].Name)
+		assert.Equal(t, []string{userRole.ID.Hex()}, created[0].Roles)
+		assert.Equal(t, []string{adminRole.ID.Hex()}, created[1].Roles)
+		assert.True(t, created[2].Active)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(created[0].Password), []byte("secret1")))
+
+		assert.Empty(t, result.Users[0].TemporaryPassword)
+		require.NotEmpty(t, result.Users[1].TemporaryPassword)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(created[1].Password), []byte(result.Users[1].TemporaryPassword)))
+		assert.Equal(t, dto.ImportedUser{Row: 3, ID: created[2].ID.Hex(), Email: "cy@example.com"}, result.Users[2])
+	})
+
+	t.Run("rejects the import with the reason of every invalid user", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+		svc := service.NewUserService(userRepo, roleRepo, nil)
+
+		roleRepo.EXPECT().FindByName(mock.Anything, "user").Return(userRole, nil)
+		roleRepo.EXPECT().FindByName(mock.Anything, "ghost").Return(nil, nil)
+		userRepo.EXPECT().List(mock.Anything, mock.Anything, mock.Anything, int64(0)).
+			Return([]*model.User{{Email: "taken@example.com"}, {Email: "other@example.com", Username: "dup"}}, nil)
+
+		_, err := svc.Import(context.Background(), []dto.ImportUser{
+			{Email: "ok@example.com", Password: "secret1"},
+			{Email: "not-an-email", Password: "secret1"},
+			{Email: "short@example.com", Password: "123"},
+			{Email: "both@example.com", Password: "secret1", Invite: true},
+			{Email: "ok@example.com", Password: "secret1"},
+			{Email: "taken@example.com", Password: "secret1"},
+			{Email: "new@example.com", Username: "dup", Password: "secret1"},
+			{Email: "role@example.com", Roles: []string{"ghost"}, Password: "secret1"},
+		})
+
+		var importErr *service.UserImportError
+		require.ErrorAs(t, err, &importErr)
+		assert.Equal(t, map[int]string{
+			2: "email is not a valid address",
+			3: "password must be at least 6 characters",
+			4: "set either a password or invite",
+			5: "email repeats user 1",
+			6: "email already registered",
+			7: "username already taken",
+			8: `unknown role "ghost"`,
+		}, importErr.Rows)
+		userRepo.AssertNotCalled(t, "CreateMany", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports a user registered during the import", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+		svc := service.NewUserService(userRepo, roleRepo, nil)
+
+		roleRepo.EXPECT().FindByName(mock.Anything, "user").Return(userRole, nil)
+		userRepo.EXPECT().List(mock.Anything, mock.Anything, mock.Anything, int64(0)).Return(nil, nil)
+		userRepo.EXPECT().CreateMany(mock.Anything, mock.Anything).Return(&repository.UserWriteError{Index: 1, Err: repository.ErrUserExists})
+
+		_, err := svc.Import(context.Background(), []dto.ImportUser{
+			{Email: "a@example.com", Password: "secret1"},
+			{Email: "b@example.com", Password: "secret1"},
+		})
+
+		var importErr *service.UserImportError
+		require.ErrorAs(t, err, &importErr)
+		assert.Equal(t, map[int]string{2: "email or username already taken"}, importErr.Rows)
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+		svc := service.NewUserService(userRepo, roleRepo, nil)
+
+		dbErr := errors.New("connection reset")
+		roleRepo.EXPECT().FindByName(mock.Anything, "user").Return(userRole, nil)
+		userRepo.EXPECT().List(mock.Anything, mock.Anything, mock.Anything, int64(0)).Return(nil, dbErr)
+
+		_, err := svc.Import(context.Background(), []dto.ImportUser{{Email: "a@example.com", Password: "secret1"}})
+		assert.ErrorIs(t, err, dbErr)
+	})
+
+	t.Run("rejects empty and oversized imports", func(t *testing.T) {
+		svc := service.NewUserService(mocks.NewMockUserRepositoryInterface(t), nil, nil)
+
+		_, err := svc.Import(context.Background(), nil)
+		assert.ErrorIs(t, err, service.ErrInvalidUser)
+
+		_, err = svc.Import(context.Background(), make([]dto.ImportUser, service.MaxUserImportSize+1))
+		assert.ErrorIs(t, err, service.ErrInvalidUser)
+	})
+}
@@ -0,0 +1,442 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/guttosm/pack-service/internal/domain/dto"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/mocks"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+func stringPtr(s string) *string { return &s }
+
+func TestUserService_List(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+	svc := service.NewUserService(userRepo, roleRepo, nil)
+
+	adminRole := &model.Role{ID: primitive.NewObjectID(), Name: "admin", Active: true}
+	roleRepo.EXPECT().FindByName(mock.Anything, "admin").Return(adminRole, nil)
+
+	active := true
+	userRepo.EXPECT().List(mock.Anything, mock.MatchedBy(func(q bson.M) bool {
+		or, hasSearch := q["$or"].(bson.A)
+		return q["active"] == true && q["roles"] == adminRole.ID.Hex() && hasSearch && len(or) == 3
+	}), int64(service.MaxUserPageSize), int64(0)).Return([]*model.User{{Email: "a@example.com"}}, nil)
+	userRepo.EXPECT().Count(mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	page, err := svc.List(context.Background(), dto.UserFilter{Active: &active, Role: "admin", Search: "a.b"}, 1000, -5)
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, service.MaxUserPageSize, page.Limit)
+	assert.Equal(t, 0, page.Offset)
+}
+
+func TestUserService_ListUnknownRole(t *testing.T) {
+	roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+	svc := service.NewUserService(mocks.NewMockUserRepositoryInterface(t), roleRepo, nil)
+
+	roleRepo.EXPECT().FindByName(mock.Anything, "ghost").Return(nil, nil)
+
+	page, err := svc.List(context.Background(), dto.UserFilter{Role: "ghost"}, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, page.Users)
+	assert.Equal(t, service.DefaultUserPageSize, page.Limit)
+}
+
+func TestUserService_Get(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	svc := service.NewUserService(userRepo, nil, nil)
+
+	_, err := svc.Get(context.Background(), "not-an-id")
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	id := primitive.NewObjectID()
+	userRepo.EXPECT().FindByID(mock.Anything, id).Return(nil, nil).Once()
+	_, err = svc.Get(context.Background(), id.Hex())
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+}
+
+func TestUserService_Update(t *testing.T) {
+	id := primitive.NewObjectID()
+	adminRole := &model.Role{ID: primitive.NewObjectID(), Name: "admin", Active: true}
+	disabledRole := &model.Role{ID: primitive.NewObjectID(), Name: "legacy", Active: false}
+
+	tests := []struct {
+		name      string
+		update    *dto.UpdateUserRequest
+		setup     func(*mocks.MockUserRepositoryInterface, *mocks.MockRoleRepositoryInterface)
+		wantErr   error
+		wantRoles []string
+	}{
+		{
+			name:   "updates profile and roles by name and ID",
+			update: &dto.UpdateUserRequest{Name: stringPtr(" New Name "), Roles: &[]string{"admin", adminRole.ID.Hex()}},
+			setup: func(u *mocks.MockUserRepositoryInterface, r *mocks.MockRoleRepositoryInterface) {
+				r.EXPECT().FindByName(mock.Anything, "admin").Return(adminRole, nil)
+				r.EXPECT().FindByID(mock.Anything, adminRole.ID).Return(adminRole, nil)
+				u.EXPECT().Update(mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Name == "New Name" && user.Password == "hash"
+				})).Return(nil)
+			},
+			wantRoles: []string{adminRole.ID.Hex()},
+		},
+		{
+			name:    "rejects invalid email",
+			update:  &dto.UpdateUserRequest{Email: stringPtr("Jane <jane@example.com>")},
+			setup:   func(*mocks.MockUserRepositoryInterface, *mocks.MockRoleRepositoryInterface) {},
+			wantErr: service.ErrInvalidUser,
+		},
+		{
+			name:    "rejects short username",
+			update:  &dto.UpdateUserRequest{Username: stringPtr("ab")},
+			setup:   func(*mocks.MockUserRepositoryInterface, *mocks.MockRoleRepositoryInterface) {},
+			wantErr: service.ErrInvalidUser,
+		},
+		{
+			name:    "rejects empty roles",
+			update:  &dto.UpdateUserRequest{Roles: &[]string{}},
+			setup:   func(*mocks.MockUserRepositoryInterface, *mocks.MockRoleRepositoryInterface) {},
+			wantErr: service.ErrInvalidUser,
+		},
+		{
+			name:   "rejects inactive role",
+			update: &dto.UpdateUserRequest{Roles: &[]string{"legacy"}},
+			setup: func(_ *mocks.MockUserRepositoryInterface, r *mocks.MockRoleRepositoryInterface) {
+				r.EXPECT().FindByName(mock.Anything, "legacy").Return(disabledRole, nil)
+			},
+			wantErr: service.ErrInvalidUser,
+		},
+		{
+			name:   "reports duplicate email",
+			update: &dto.UpdateUserRequest{Email: stringPtr("taken@example.com")},
+			setup: func(u *mocks.MockUserRepositoryInterface, _ *mocks.MockRoleRepositoryInterface) {
+				u.EXPECT().Update(mock.Anything, mock.Anything).Return(service.ErrUserExists)
+			},
+			wantErr: service.ErrUserExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewMockUserRepositoryInterface(t)
+			roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+			userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Email: "user@example.com", Password: "hash", Active: true}, nil)
+			tt.setup(userRepo, roleRepo)
+
+			user, err := service.NewUserService(userRepo, roleRepo, nil).Update(context.Background(), id.Hex(), tt.update)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRoles, user.Roles)
+		})
+	}
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	id := primitive.NewObjectID()
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		update  *dto.UpdateProfileRequest
+		setup   func(*mocks.MockUserRepositoryInterface, *mocks.MockAuthService)
+		wantErr error
+	}{
+		{
+			name:   "updates name and username",
+			update: &dto.UpdateProfileRequest{Name: stringPtr(" Jane "), Username: stringPtr("jane")},
+			setup: func(u *mocks.MockUserRepositoryInterface, _ *mocks.MockAuthService) {
+				u.EXPECT().Update(mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Name == "Jane" && user.Username == "jane" && user.Password == string(hash)
+				})).Return(nil)
+			},
+		},
+		{
+			name:   "changes the password and revokes tokens",
+			update: &dto.UpdateProfileRequest{CurrentPassword: "old-password", NewPassword: stringPtr("new-password")},
+			setup: func(u *mocks.MockUserRepositoryInterface, a *mocks.MockAuthService) {
+				u.EXPECT().Update(mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")) == nil
+				})).Return(nil)
+				a.EXPECT().RevokeUserTokens(mock.Anything, id).Return(nil)
+			},
+		},
+		{
+			name:    "rejects a wrong current password",
+			update:  &dto.UpdateProfileRequest{CurrentPassword: "guess", NewPassword: stringPtr("new-password")},
+			setup:   func(*mocks.MockUserRepositoryInterface, *mocks.MockAuthService) {},
+			wantErr: service.ErrIncorrectPassword,
+		},
+		{
+			name:    "requires the current password",
+			update:  &dto.UpdateProfileRequest{NewPassword: stringPtr("new-password")},
+			setup:   func(*mocks.MockUserRepositoryInterface, *mocks.MockAuthService) {},
+			wantErr: service.ErrInvalidUser,
+		},
+		{
+			name:    "rejects a short new password",
+			update:  &dto.UpdateProfileRequest{CurrentPassword: "old-password", NewPassword: stringPtr("abc")},
+			setup:   func(*mocks.MockUserRepositoryInterface, *mocks.MockAuthService) {},
+			wantErr: service.ErrInvalidUser,
+		},
+		{
+			name:    "rejects short username",
+			update:  &dto.UpdateProfileRequest{Username: stringPtr("ab")},
+			setup:   func(*mocks.MockUserRepositoryInterface, *mocks.MockAuthService) {},
+			wantErr: service.ErrInvalidUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewMockUserRepositoryInterface(t)
+			authService := mocks.NewMockAuthService(t)
+			userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Username: "user", Password: string(hash), Active: true}, nil)
+			tt.setup(userRepo, authService)
+
+			_, err := service.NewUserService(userRepo, nil, authService).UpdateProfile(context.Background(), id.Hex(), tt.update)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("deactivated users cannot update their profile", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: false}, nil)
+
+		_, err := service.NewUserService(userRepo, nil, nil).UpdateProfile(context.Background(), id.Hex(), &dto.UpdateProfileRequest{Name: stringPtr("x")})
+		assert.ErrorIs(t, err, service.ErrUserNotFound)
+	})
+}
+
+func TestUserService_Deactivate(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	authService := mocks.NewMockAuthService(t)
+	svc := service.NewUserService(userRepo, nil, authService)
+
+	adminID := primitive.NewObjectID().Hex()
+	_, err := svc.Deactivate(context.Background(), adminID, adminID)
+	assert.ErrorIs(t, err, service.ErrSelfDeactivation)
+
+	id := primitive.NewObjectID()
+	userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: true}, nil)
+	userRepo.EXPECT().Delete(mock.Anything, id).Return(nil)
+	// Failing to revoke sessions does not undo the deactivation
+	authService.EXPECT().RevokeUserTokens(mock.Anything, id).Return(errors.New("db down"))
+
+	user, err := svc.Deactivate(context.Background(), id.Hex(), adminID)
+	require.NoError(t, err)
+	assert.False(t, user.Active)
+}
+
+func TestUserService_Disable(t *testing.T) {
+	adminID := primitive.NewObjectID().Hex()
+
+	t.Run("deactivates and revokes sessions", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		authService := mocks.NewMockAuthService(t)
+		id := primitive.NewObjectID()
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: true}, nil)
+		userRepo.EXPECT().Delete(mock.Anything, id).Return(nil)
+		authService.EXPECT().RevokeUserTokens(mock.Anything, id).Return(nil)
+
+		user, err := service.NewUserService(userRepo, nil, authService).Disable(context.Background(), id.Hex(), adminID)
+		require.NoError(t, err)
+		assert.False(t, user.Active)
+	})
+
+	t.Run("fails when sessions cannot be revoked", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		authService := mocks.NewMockAuthService(t)
+		id := primitive.NewObjectID()
+		// Already deactivated: the retry only revokes the sessions again
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: false}, nil)
+		authService.EXPECT().RevokeUserTokens(mock.Anything, id).Return(errors.New("db down"))
+
+		_, err := service.NewUserService(userRepo, nil, authService).Disable(context.Background(), id.Hex(), adminID)
+		assert.Error(t, err)
+	})
+
+	t.Run("refuses to disable the admin", func(t *testing.T) {
+		_, err := service.NewUserService(nil, nil, nil).Disable(context.Background(), adminID, adminID)
+		assert.ErrorIs(t, err, service.ErrSelfDeactivation)
+	})
+}
+
+func TestUserService_RevokeSessions(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	authService := mocks.NewMockAuthService(t)
+	id := primitive.NewObjectID()
+	userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: true}, nil).Twice()
+	authService.EXPECT().RevokeUserTokens(mock.Anything, id).Return(nil)
+
+	user, err := service.NewUserService(userRepo, nil, authService).RevokeSessions(context.Background(), id.Hex())
+	require.NoError(t, err)
+	assert.True(t, user.Active, "the account stays active")
+
+	_, err = service.NewUserService(userRepo, nil, nil).RevokeSessions(context.Background(), id.Hex())
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+}
+
+func TestUserService_Reactivate(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	svc := service.NewUserService(userRepo, nil, nil)
+
+	id := primitive.NewObjectID()
+	deletedAt := timeutil.Now()
+	userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: false, DeletedAt: &deletedAt}, nil).Once()
+	userRepo.EXPECT().Restore(mock.Anything, id).Return(nil).Once()
+
+	user, err := svc.Reactivate(context.Background(), id.Hex())
+	require.NoError(t, err)
+	assert.True(t, user.Active)
+	assert.Nil(t, user.DeletedAt)
+
+	// Already active users are returned unchanged
+	userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: true}, nil).Once()
+	_, err = svc.Reactivate(context.Background(), id.Hex())
+	require.NoError(t, err)
+}
+
+func TestUserService_HardDelete(t *testing.T) {
+	now := timeutil.Now()
+	deletedAt := func(age time.Duration) *time.Time {
+		at := now.Add(-age)
+		return &at
+	}
+
+	t.Run("purges the user and their tokens after the retention", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+		id := primitive.NewObjectID()
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, DeletedAt: deletedAt(48 * time.Hour)}, nil)
+		userRepo.EXPECT().HardDelete(mock.Anything, id, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before).Round(time.Hour) == 24*time.Hour
+		})).Return(true, nil)
+		tokenRepo.EXPECT().DeleteAllByUserID(mock.Anything, id).Return(3, nil)
+
+		svc := service.NewUserService(userRepo, nil, nil, service.WithTokenRepository(tokenRepo), service.WithDeletedUserRetention(24*time.Hour))
+		user, err := svc.HardDelete(context.Background(), id.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, id, user.ID)
+	})
+
+	t.Run("keeps users within the retention", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		id := primitive.NewObjectID()
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, DeletedAt: deletedAt(time.Hour)}, nil)
+
+		_, err := service.NewUserService(userRepo, nil, nil, service.WithDeletedUserRetention(24*time.Hour)).HardDelete(context.Background(), id.Hex())
+		assert.ErrorIs(t, err, service.ErrUserNotPurgeable)
+	})
+
+	t.Run("counts users deactivated before deleted_at from their last update", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		id := primitive.NewObjectID()
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, UpdatedAt: now.Add(-time.Hour)}, nil)
+
+		_, err := service.NewUserService(userRepo, nil, nil).HardDelete(context.Background(), id.Hex())
+		assert.ErrorIs(t, err, service.ErrUserNotPurgeable, "default retention")
+	})
+
+	t.Run("refuses active users", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		id := primitive.NewObjectID()
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, Active: true}, nil)
+
+		_, err := service.NewUserService(userRepo, nil, nil, service.WithDeletedUserRetention(0)).HardDelete(context.Background(), id.Hex())
+		assert.ErrorIs(t, err, service.ErrUserNotPurgeable)
+	})
+
+	t.Run("keeps the tokens of a user restored meanwhile", func(t *testing.T) {
+		userRepo := mocks.NewMockUserRepositoryInterface(t)
+		tokenRepo := mocks.NewMockTokenRepositoryInterface(t)
+		id := primitive.NewObjectID()
+		userRepo.EXPECT().FindByID(mock.Anything, id).Return(&model.User{ID: id, DeletedAt: deletedAt(time.Hour)}, nil)
+		userRepo.EXPECT().HardDelete(mock.Anything, id, mock.Anything).Return(false, nil)
+
+		svc := service.NewUserService(userRepo, nil, nil, service.WithTokenRepository(tokenRepo), service.WithDeletedUserRetention(0))
+		_, err := svc.HardDelete(context.Background(), id.Hex())
+		assert.ErrorIs(t, err, service.ErrUserNotPurgeable)
+	})
+}
+
+func TestUserService_NilRepository(t *testing.T) {
+	svc := service.NewUserService(nil, nil, nil)
+
+	_, err := svc.List(context.Background(), dto.UserFilter{}, 0, 0)
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	_, err = svc.Get(context.Background(), primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+}
+
+func TestUserService_EffectivePermissions(t *testing.T) {
+	userRepo := mocks.NewMockUserRepositoryInterface(t)
+	roleRepo := mocks.NewMockRoleRepositoryInterface(t)
+	permissionRepo := mocks.NewMockPermissionRepositoryInterface(t)
+	svc := service.NewUserService(userRepo, roleRepo, nil, service.WithPermissionRepository(permissionRepo))
+
+	packsRead := &model.Permission{ID: primitive.NewObjectID(), Name: "packs:read", Resource: "packs", Action: "read"}
+	packsWrite := &model.Permission{ID: primitive.NewObjectID(), Name: "packs:write", Resource: "packs", Action: "write"}
+	auditRead := &model.Permission{ID: primitive.NewObjectID(), Name: "audit:read", Resource: "audit", Action: "read"}
+	deletedPermID := primitive.NewObjectID().Hex()
+	userRole := &model.Role{ID: primitive.NewObjectID(), Name: "user", Active: true, Permissions: []string{packsRead.ID.Hex()}}
+	legacyRole := &model.Role{ID: primitive.NewObjectID(), Name: "legacy", Permissions: []string{packsRead.ID.Hex(), packsWrite.ID.Hex(), auditRead.ID.Hex(), deletedPermID}}
+	deletedRoleID := primitive.NewObjectID().Hex()
+
+	user := &model.User{
+		ID:     primitive.NewObjectID(),
+		Email:  "user@example.com",
+		Active: true,
+		Roles:  []string{userRole.ID.Hex(), deletedRoleID, legacyRole.ID.Hex()},
+	}
+	userRepo.EXPECT().FindByID(mock.Anything, user.ID).Return(user, nil)
+	roleRepo.EXPECT().FindByIDs(mock.Anything, user.Roles).Return([]*model.Role{legacyRole, userRole}, nil)
+	permissionRepo.EXPECT().FindByIDs(mock.Anything, []string{packsRead.ID.Hex(), packsWrite.ID.Hex(), auditRead.ID.Hex(), deletedPermID}).
+		Return([]*model.Permission{packsWrite, auditRead, packsRead}, nil)
+
+	result, err := svc.EffectivePermissions(context.Background(), user.ID.Hex())
+	require.NoError(t, err)
+
+	assert.Equal(t, []dto.EffectiveRole{
+		{ID: userRole.ID.Hex(), Name: "user", Active: true},
+		{ID: legacyRole.ID.Hex(), Name: "legacy", Active: false},
+	}, result.Roles)
+	assert.Equal(t, []dto.EffectivePermission{
+		{ID: auditRead.ID.Hex(), Name: "audit:read", Resource: "audit", Action: "read", GrantedBy: []string{"legacy"}},
+		{ID: packsRead.ID.Hex(), Name: "packs:read", Resource: "packs", Action: "read", GrantedBy: []string{"user", "legacy"}},
+		{ID: packsWrite.ID.Hex(), Name: "packs:write", Resource: "packs", Action: "write", GrantedBy: []string{"legacy"}},
+	}, result.Permissions)
+	assert.Equal(t, []string{deletedRoleID}, result.MissingRoleIDs)
+	assert.Equal(t, []string{deletedPermID}, result.MissingPermissionIDs)
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := svc.EffectivePermissions(context.Background(), "not-an-id")
+		assert.ErrorIs(t, err, service.ErrUserNotFound)
+	})
+
+	t.Run("without a permission repository", func(t *testing.T) {
+		_, err := service.NewUserService(userRepo, roleRepo, nil).EffectivePermissions(context.Background(), user.ID.Hex())
+		assert.ErrorIs(t, err, service.ErrRepositoryNotConfigured)
+	})
+}
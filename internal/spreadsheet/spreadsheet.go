@@ -0,0 +1,93 @@
+// Package spreadsheet writes tables of results as CSV or XLSX, row by row,
+// so large tables can be streamed to the client.
+//
+// Cells are strings, integers, floats, booleans or nil for an empty cell.
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Content types of the supported formats.
+const (
+	MIMECSV  = "text/csv"
+	MIMEXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// Writer writes rows of a table.
+type Writer interface {
+	// Write writes one row.
+	Write(row []any) error
+	// Flush sends the rows written so far to the underlying writer, as far
+	// as the format allows.
+	Flush() error
+	// Close finishes the table. It does not close the underlying writer.
+	Close() error
+}
+
+// New returns a writer of the format with the given content type, with
+// sheet naming the sheet of formats that have one.
+func New(w io.Writer, contentType, sheet string) (Writer, error) {
+	switch contentType {
+	case MIMECSV:
+		return NewCSVWriter(w), nil
+	case MIMEXLSX:
+		return NewXLSXWriter(w, sheet)
+	default:
+		return nil, fmt.Errorf("unsupported spreadsheet format %q", contentType)
+	}
+}
+
+// CSVWriter writes a table as CSV.
+type CSVWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+// NewCSVWriter creates a CSV writer writing to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write writes row as a CSV record.
+func (w *CSVWriter) Write(row []any) error {
+	w.record = w.record[:0]
+	for _, cell := range row {
+		w.record = append(w.record, formatCell(cell))
+	}
+	return w.w.Write(w.record)
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (w *CSVWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// Close flushes the remaining records.
+func (w *CSVWriter) Close() error {
+	return w.Flush()
+}
+
+// formatCell returns the text of a cell.
+func formatCell(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !integration
+
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(&buf, MIMECSV, "ignored")
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write([]any{"index", "packs", "approximate"}))
+	require.NoError(t, w.Write([]any{0, "1x500, 1x250", false}))
+	require.NoError(t, w.Write([]any{int64(1), nil, true, 2.5}))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "index,packs,approximate\n0,\"1x500, 1x250\",false\n1,,true,2.5\n", buf.String())
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(&buf, MIMEXLSX, "Results: 2026/03")
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write([]any{"index", "message"}))
+	require.NoError(t, w.Write([]any{0, "<too> large & slow", nil, true}))
+	require.NoError(t, w.Flush())
+	require.NoError(t, w.Close())
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parts := make(map[string]string)
+	for _, f := range z.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		parts[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		require.Contains(t, parts, name)
+		assert.NoError(t, xml.Unmarshal([]byte(parts[name]), new(struct{})), "%s is well-formed", name)
+	}
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Results_ 2026_03"`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">index</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2"><v>0</v></c>`)
+	assert.Contains(t, sheet, `&lt;too&gt; large &amp; slow`)
+	assert.NotContains(t, sheet, `r="C2"`, "empty cells are left out")
+	assert.Contains(t, sheet, `<c r="D2" t="b"><v>1</v></c>`)
+}
+
+func TestNew_UnsupportedFormat(t *testing.T) {
+	_, err := New(io.Discard, "application/pdf", "")
+	assert.Error(t, err)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
+
+func TestSheetName(t *testing.T) {
+	assert.Equal(t, "Sheet1", sheetName(""))
+	assert.Equal(t, "a_b_c", sheetName("a[b]c"))
+	assert.Len(t, []rune(sheetName("a very long sheet name that Excel would reject")), maxSheetNameSize)
+}
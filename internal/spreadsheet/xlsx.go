@@ -0,0 +1,156 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The parts of a workbook with one worksheet, other than the worksheet.
+// Strings are written inline in their cells, so the workbook needs no
+// shared strings table and rows can be written as they come.
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookStart = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`
+	xlsxWorkbookEnd = `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetStart  = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd    = `</sheetData></worksheet>`
+)
+
+// maxSheetNameSize is the longest sheet name Excel accepts.
+const maxSheetNameSize = 31
+
+// XLSXWriter writes a table as an Office Open XML workbook with one sheet.
+type XLSXWriter struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	row   bytes.Buffer
+	rows  int
+}
+
+// NewXLSXWriter creates an XLSX writer writing to w, naming its sheet
+// sheet. The workbook is only complete once Close is called.
+func NewXLSXWriter(w io.Writer, sheet string) (*XLSXWriter, error) {
+	z := zip.NewWriter(w)
+
+	var name strings.Builder
+	if err := xml.EscapeText(&name, []byte(sheetName(sheet))); err != nil {
+		return nil, err
+	}
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbookStart + name.String() + xlsxWorkbookEnd},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(f, xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	return &XLSXWriter{zip: z, sheet: f}, nil
+}
+
+// Write writes row as the next row of the sheet. Numbers and booleans are
+// stored as such, everything else as text.
+func (x *XLSXWriter) Write(row []any) error {
+	x.rows++
+	rowRef := strconv.Itoa(x.rows)
+
+	w := &x.row
+	w.Reset()
+	w.WriteString(`<row r="` + rowRef + `">`)
+	for i, cell := range row {
+		if cell == nil {
+			continue
+		}
+		ref := columnName(i) + rowRef
+		switch v := cell.(type) {
+		case int, int64, float64:
+			w.WriteString(`<c r="` + ref + `"><v>` + formatCell(v) + `</v></c>`)
+		case bool:
+			value := "0"
+			if v {
+				value = "1"
+			}
+			w.WriteString(`<c r="` + ref + `" t="b"><v>` + value + `</v></c>`)
+		default:
+			w.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(w, []byte(formatCell(v))); err != nil {
+				return err
+			}
+			w.WriteString(`</t></is></c>`)
+		}
+	}
+	w.WriteString(`</row>`)
+	_, err := x.sheet.Write(w.Bytes())
+	return err
+}
+
+// Flush writes the buffered rows to the underlying writer, except for what
+// the compressor still holds.
+func (x *XLSXWriter) Flush() error {
+	return x.zip.Flush()
+}
+
+// Close ends the sheet and writes the end of the workbook.
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// columnName returns the letters of the zero-based column i: A, ..., Z, AA.
+func columnName(i int) string {
+	var name []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// sheetName returns name as a valid sheet name, without the characters
+// Excel rejects.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxSheetNameSize {
+		name = string(runes[:maxSheetNameSize])
+	}
+	if name == "" {
+		return "Sheet1"
+	}
+	return name
+}
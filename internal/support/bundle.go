@@ -0,0 +1,277 @@
+// Package support builds diagnostic bundles to attach to bug reports.
+package support
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/circuitbreaker"
+	"github.com/guttosm/pack-service/internal/domain/model"
+	"github.com/guttosm/pack-service/internal/service"
+	"github.com/guttosm/pack-service/internal/service/cache"
+	"github.com/guttosm/pack-service/internal/version"
+)
+
+// redacted replaces secret values in the bundled configuration.
+const redacted = "[REDACTED]"
+
+// CacheMetricsProvider reports result cache metrics.
+type CacheMetricsProvider interface {
+	CacheMetrics() (cache.Metrics, bool)
+}
+
+// Sources lists the inputs of a support bundle. Nil sources are skipped.
+type Sources struct {
+	Config          config.Config
+	LoggingService  service.LoggingService
+	CircuitBreakers map[string]*circuitbreaker.CircuitBreaker
+	Cache           CacheMetricsProvider
+	// RecentErrors is the maximum number of error log entries to include.
+	RecentErrors int
+}
+
+// Manifest describes the contents of a bundle. It is written last so it can
+// record sections that failed to collect.
+type Manifest struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Hostname    string            `json:"hostname,omitempty"`
+	Build       version.Info      `json:"build"`
+	Files       []string          `json:"files"`
+	Errors      map[string]string `json:"errors,omitempty"`
+}
+
+// Generator builds support bundles from a fixed set of sources.
+type Generator struct {
+	sources Sources
+	now     func() time.Time
+}
+
+// NewGenerator creates a support bundle generator.
+func NewGenerator(sources Sources) *Generator {
+	if sources.RecentErrors <= 0 {
+		sources.RecentErrors = 100
+	}
+	return &Generator{
+		sources: sources,
+		now:     time.Now,
+	}
+}
+
+// Filename returns a timestamped file name for a new bundle.
+func (g *Generator) Filename() string {
+	return fmt.Sprintf("support-bundle-%s.zip", g.now().UTC().Format("20060102-150405"))
+}
+
+// Write streams a zip archive with the bundle to w. A section that cannot be
+// collected is recorded in the manifest instead of failing the whole bundle;
+// only errors writing the archive itself are returned.
+func (g *Generator) Write(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	manifest := Manifest{
+		GeneratedAt: g.now().UTC(),
+		Build:       version.Get(),
+		Errors:      make(map[string]string),
+	}
+	manifest.Hostname, _ = os.Hostname()
+
+	sections := []struct {
+		name    string
+		collect func(ctx context.Context, w io.Writer) error
+	}{
+		{"version.json", g.writeJSON(func(context.Context) (interface{}, error) { return version.Get(), nil })},
+		{"config.json", g.writeJSON(func(context.Context) (interface{}, error) { return RedactConfig(g.sources.Config), nil })},
+		{"runtime.json", g.writeJSON(func(context.Context) (interface{}, error) { return collectRuntime(), nil })},
+		{"circuit_breakers.json", g.writeJSON(g.collectCircuitBreakers)},
+		{"cache.json", g.writeJSON(g.collectCache)},
+		{"errors.json", g.writeJSON(g.collectRecentErrors)},
+		{"profiles/goroutine.txt", writeProfile("goroutine", 1)},
+		{"profiles/heap.pprof", writeProfile("heap", 0)},
+	}
+
+	for _, section := range sections {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		f, err := zw.Create(section.name)
+		if err != nil {
+			return err
+		}
+		if err := section.collect(ctx, f); err != nil {
+			manifest.Errors[section.name] = err.Error()
+			continue
+		}
+		manifest.Files = append(manifest.Files, section.name)
+	}
+
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	if err := encodeJSON(f, manifest); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeJSON adapts a collector to a section writer.
+func (g *Generator) writeJSON(collect func(ctx context.Context) (interface{}, error)) func(context.Context, io.Writer) error {
+	return func(ctx context.Context, w io.Writer) error {
+		v, err := collect(ctx)
+		if err != nil {
+			return err
+		}
+		return encodeJSON(w, v)
+	}
+}
+
+// collectCircuitBreakers returns the current state of every breaker, keyed by name.
+func (g *Generator) collectCircuitBreakers(context.Context) (interface{}, error) {
+	states := make(map[string]circuitbreaker.Stats, len(g.sources.CircuitBreakers))
+	for name, cb := range g.sources.CircuitBreakers {
+		if cb != nil {
+			states[name] = cb.GetStats()
+		}
+	}
+	return states, nil
+}
+
+// collectCache returns the result cache metrics, if available.
+func (g *Generator) collectCache(context.Context) (interface{}, error) {
+	if g.sources.Cache == nil {
+		return map[string]interface{}{"enabled": false}, nil
+	}
+	metrics, ok := g.sources.Cache.CacheMetrics()
+	if !ok {
+		return map[string]interface{}{"enabled": false}, nil
+	}
+	return map[string]interface{}{"enabled": true, "metrics": metrics}, nil
+}
+
+// collectRecentErrors returns the most recent error-level log entries.
+func (g *Generator) collectRecentErrors(ctx context.Context) (interface{}, error) {
+	if g.sources.LoggingService == nil {
+		return []model.LogEntry{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return g.sources.LoggingService.QueryLogs(ctx, model.LogQueryOptions{
+		Level: "error",
+		Limit: g.sources.RecentErrors,
+	})
+}
+
+// runtimeInfo is a snapshot of the Go runtime.
+type runtimeInfo struct {
+	Goroutines   int    `json:"goroutines"`
+	NumCPU       int    `json:"num_cpu"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	HeapAlloc    uint64 `json:"heap_alloc_bytes"`
+	HeapInuse    uint64 `json:"heap_inuse_bytes"`
+	Sys          uint64 `json:"sys_bytes"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"gc_pause_total_ns"`
+}
+
+func collectRuntime() runtimeInfo {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return runtimeInfo{
+		Goroutines:   runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    m.HeapAlloc,
+		HeapInuse:    m.HeapInuse,
+		Sys:          m.Sys,
+		NumGC:        m.NumGC,
+		PauseTotalNs: m.PauseTotalNs,
+	}
+}
+
+// writeProfile writes the named runtime/pprof profile.
+func writeProfile(name string, debug int) func(context.Context, io.Writer) error {
+	return func(_ context.Context, w io.Writer) error {
+		profile := pprof.Lookup(name)
+		if profile == nil {
+			return fmt.Errorf("profile %q not available", name)
+		}
+		return profile.WriteTo(w, debug)
+	}
+}
+
+// RedactConfig returns a copy of cfg that is safe to share: the fields
+// tagged as secrets are replaced, credentials are stripped from URLs and API
+// keys are reduced to a count.
+func RedactConfig(cfg config.Config) map[string]interface{} {
+	apiKeyCount := len(cfg.Auth.APIKeys)
+	redactSecrets(reflect.ValueOf(&cfg).Elem())
+
+	return map[string]interface{}{
+		"server":        cfg.Server,
+		"cache":         cfg.Cache,
+		"auth":          cfg.Auth,
+		"auth_api_keys": apiKeyCount,
+		"database":      cfg.Database,
+		"alerting":      cfg.Alerting,
+	}
+}
+
+// redactSecrets redacts the fields of the struct v tagged `secret:"true"`,
+// strips the URLs tagged `secret:"url"` and recurses into nested structs.
+// Secrets other than strings are cleared.
+func redactSecrets(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		switch tag := t.Field(i).Tag.Get("secret"); {
+		case tag == "url" && field.Kind() == reflect.String:
+			field.SetString(redactURL(field.String()))
+		case tag != "" && field.Kind() == reflect.String:
+			field.SetString(redactSecret(field.String()))
+		case tag != "":
+			field.SetZero()
+		case field.Kind() == reflect.Struct:
+			redactSecrets(field)
+		}
+	}
+}
+
+// redactSecret hides a non-empty secret while keeping "unset" visible.
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactURL keeps the scheme and host of a URL and drops credentials, path and query,
+// which may all carry tokens.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func encodeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,24 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/idgen"
+	"github.com/guttosm/pack-service/internal/timeutil"
+)
+
+// Deterministic turns deterministic mode on until the test ends: IDs and
+// token nonces come from seed, and the clock is frozen at
+// timeutil.DefaultFrozenTime. The mode is process-wide, so the test must not
+// run in parallel with others.
+func Deterministic(t testing.TB, seed uint64) {
+	t.Helper()
+
+	timeutil.Freeze(timeutil.DefaultFrozenTime)
+	idgen.Seed(seed)
+	t.Cleanup(func() {
+		idgen.Reset()
+		timeutil.Freeze(time.Time{})
+	})
+}
@@ -0,0 +1,82 @@
+package testutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/guttosm/pack-service/internal/dpop"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DPoPKey is a P-256 client key that signs ES256 DPoP proofs.
+type DPoPKey struct {
+	t       testing.TB
+	private *ecdsa.PrivateKey
+	jwk     map[string]interface{}
+}
+
+// NewDPoPKey generates a DPoP client key.
+func NewDPoPKey(t testing.TB) *DPoPKey {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate dpop key: %v", err)
+	}
+	point, err := private.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("encode dpop key: %v", err)
+	}
+
+	return &DPoPKey{
+		t:       t,
+		private: private,
+		jwk: map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(point[1:33]),
+			"y":   base64.RawURLEncoding.EncodeToString(point[33:]),
+		},
+	}
+}
+
+// Thumbprint returns the key's JWK thumbprint, as bound into tokens.
+func (k *DPoPKey) Thumbprint() string {
+	k.t.Helper()
+	thumbprint, err := dpop.Thumbprint(k.jwk)
+	if err != nil {
+		k.t.Fatalf("dpop thumbprint: %v", err)
+	}
+	return thumbprint
+}
+
+// Proof signs a fresh proof for a request. accessToken is hashed into the ath
+// claim when non-empty.
+func (k *DPoPKey) Proof(method, url, accessToken string) string {
+	k.t.Helper()
+
+	claims := jwt.MapClaims{
+		"jti": primitive.NewObjectID().Hex(),
+		"htm": method,
+		"htu": url,
+		"iat": time.Now().Unix(),
+	}
+	if accessToken != "" {
+		claims["ath"] = dpop.TokenHash(accessToken)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = k.jwk
+
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		k.t.Fatalf("sign dpop proof: %v", err)
+	}
+	return signed
+}
@@ -0,0 +1,74 @@
+// Package timeutil defines the service's time policy: timestamps are taken
+// and stored in UTC, rendered as RFC 3339 with an explicit offset, and only
+// shifted to a display timezone by reporting and export layers.
+package timeutil
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Layout is the format of every rendered timestamp. It matches the JSON
+// encoding of time.Time.
+const Layout = time.RFC3339Nano
+
+// DefaultFrozenTime is the instant deterministic mode freezes the clock at
+// unless configured otherwise. It lies in the future, so TTL indexes do not
+// delete the documents stored meanwhile.
+var DefaultFrozenTime = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// frozen is the instant Now returns while the clock is frozen.
+var frozen atomic.Pointer[time.Time]
+
+// Now returns the current time in UTC. Use it for every timestamp that is
+// stored or returned; time.Now is still right for measuring durations.
+func Now() time.Time {
+	if t := frozen.Load(); t != nil {
+		return *t
+	}
+	return time.Now().UTC()
+}
+
+// Freeze makes Now return t from then on, so deterministic mode gets the same
+// timestamps on every run. The zero time unfreezes the clock.
+func Freeze(t time.Time) {
+	if t.IsZero() {
+		frozen.Store(nil)
+		return
+	}
+	t = t.UTC()
+	frozen.Store(&t)
+}
+
+// Format renders t in UTC using Layout.
+func Format(t time.Time) string {
+	return t.UTC().Format(Layout)
+}
+
+// LoadLocation resolves a display timezone name such as "Europe/Berlin".
+// An empty name or "UTC" is UTC; "Local" is rejected so reports never depend
+// on the host's timezone.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "", strings.EqualFold(name, "UTC"):
+		return time.UTC, nil
+	case strings.EqualFold(name, "Local"):
+		return nil, fmt.Errorf("timezone %q is not allowed, use an IANA name", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// In returns t in loc for display, or in UTC when loc is nil. Zero times are
+// returned unchanged.
+func In(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc)
+}
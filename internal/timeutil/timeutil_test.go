@@ -0,0 +1,77 @@
+//go:build !integration
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNow(t *testing.T) {
+	assert.Equal(t, time.UTC, Now().Location())
+}
+
+func TestFreeze(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	instant := time.Date(2030, 1, 1, 1, 0, 0, 0, berlin)
+
+	Freeze(instant)
+	defer Freeze(time.Time{})
+	assert.Equal(t, instant.UTC(), Now())
+	assert.Equal(t, Now(), Now())
+	assert.Equal(t, time.UTC, Now().Location())
+
+	Freeze(time.Time{})
+	assert.WithinDuration(t, time.Now(), Now(), time.Second)
+}
+
+func TestFormat(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	ts := time.Date(2025, 1, 28, 11, 0, 0, 500, berlin)
+	assert.Equal(t, "2025-01-28T10:00:00.0000005Z", Format(ts))
+}
+
+func TestLoadLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: "UTC"},
+		{name: "utc", want: "UTC"},
+		{name: " America/Sao_Paulo ", want: "America/Sao_Paulo"},
+		{name: "Local", wantErr: true},
+		{name: "Mars/Olympus_Mons", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := LoadLocation(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, loc.String())
+		})
+	}
+}
+
+func TestIn(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+	ts := time.Date(2025, 1, 28, 10, 0, 0, 0, time.UTC)
+
+	local := In(ts, saoPaulo)
+	assert.True(t, local.Equal(ts))
+	assert.Equal(t, "2025-01-28T07:00:00-03:00", local.Format(time.RFC3339))
+
+	assert.Equal(t, time.UTC, In(ts.In(saoPaulo), nil).Location())
+	assert.True(t, In(time.Time{}, saoPaulo).IsZero())
+}
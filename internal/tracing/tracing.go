@@ -0,0 +1,70 @@
+// Package tracing sets up OpenTelemetry tracing. Spans are started through
+// Tracer and carried in contexts, so a request's HTTP, calculation and
+// MongoDB spans form one trace, joined to the caller's by W3C trace context
+// headers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/guttosm/pack-service/config"
+	"github.com/guttosm/pack-service/internal/version"
+)
+
+// instrumentationName names the tracer of every span the service starts.
+const instrumentationName = "github.com/guttosm/pack-service"
+
+// Span attributes set by the service.
+const (
+	// ItemsOrdered is the order size of a calculation.
+	ItemsOrdered = attribute.Key("pack.items_ordered")
+	// CacheHit reports whether a calculation was answered from the result cache.
+	CacheHit = attribute.Key("pack.cache_hit")
+	// RequestID is the X-Request-ID of the request a span belongs to.
+	RequestID = attribute.Key("pack.request_id")
+)
+
+// Init exports spans to cfg.Endpoint over OTLP/HTTP and makes the exporting
+// provider and the W3C propagators global. The returned function flushes
+// the spans still buffered; call it at shutdown.
+func Init(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(version.Version),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator())
+	return provider.Shutdown, nil
+}
+
+// Propagator returns the W3C trace context and baggage propagator. Unlike
+// the global one, it reads traceparent headers before Init is called, so
+// requests keep their caller's trace ID even when spans are not exported.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Tracer returns the service's tracer. Until Init is called its spans are
+// not recorded.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
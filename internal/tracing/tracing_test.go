@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/guttosm/pack-service/config"
+)
+
+func TestInit(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	shutdown, err := Init(context.Background(), config.TracingConfig{
+		Endpoint:    "http://localhost:4318",
+		ServiceName: "pack-service-test",
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "test")
+	assert.True(t, span.IsRecording())
+	assert.True(t, span.SpanContext().IsSampled())
+	span.End()
+
+	carrier := propagation.MapCarrier{}
+	ctx, span := Tracer().Start(context.Background(), "outgoing")
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	span.End()
+	assert.Contains(t, carrier.Get("traceparent"), span.SpanContext().TraceID().String())
+
+	// Nothing listens on the endpoint: the flush fails, but shutting down
+	// must not hang
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_ = shutdown(ctx)
+}
+
+func TestInit_Unsampled(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+	shutdown, err := Init(context.Background(), config.TracingConfig{Endpoint: "http://localhost:4318", SampleRatio: 0})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	_, span := Tracer().Start(context.Background(), "test")
+	defer span.End()
+	assert.False(t, span.SpanContext().IsSampled())
+}
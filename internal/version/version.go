@@ -0,0 +1,44 @@
+// Package version exposes build information about the running binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the release version of the binary. It is set at build time with
+// -ldflags "-X github.com/guttosm/pack-service/internal/version.Version=<version>".
+var Version = "dev"
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"revision,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// Get returns build information, including VCS details when the binary was
+// built from a git checkout.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.BuildTime = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,21 @@
+//go:build !integration
+
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	original := Version
+	defer func() { Version = original }()
+
+	Version = "1.2.3"
+	info := Get()
+
+	assert.Equal(t, "1.2.3", info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
@@ -0,0 +1,217 @@
+// Package workerpool runs CPU-bound work on a fixed number of workers shared
+// by all requests. Each caller is capped to a share of the workers and waits
+// a bounded time for one, so a single large job queues behind its own cap
+// instead of starving everyone else.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/guttosm/pack-service/internal/metrics"
+)
+
+// ErrSaturated is returned for tasks that could not get a worker within the
+// queue timeout.
+var ErrSaturated = errors.New("worker pool saturated")
+
+// ErrTaskPanicked is passed to the fallback of a task that panicked, which
+// would otherwise crash the process: the request's recovery middleware does
+// not see panics on worker goroutines.
+var ErrTaskPanicked = errors.New("worker pool task panicked")
+
+// Config configures a Pool.
+type Config struct {
+	// Name labels the pool's metrics.
+	Name string
+	// Workers is the number of tasks that may run at once across all callers.
+	// Zero or less uses runtime.NumCPU().
+	Workers int
+	// MaxPerCaller caps the workers one Ordered call may use at once. Zero or
+	// less uses half the workers, at least one.
+	MaxPerCaller int
+	// QueueTimeout is how long a task waits for a free worker. Zero waits
+	// until the caller's context is done.
+	QueueTimeout time.Duration
+	// MaxQueued caps the tasks waiting for a free worker; tasks beyond it
+	// fail with ErrSaturated right away. Zero leaves the queue unbounded.
+	MaxQueued int
+}
+
+// Pool is a bounded set of workers.
+type Pool struct {
+	name         string
+	slots        chan struct{}
+	maxPerCaller int
+	queueTimeout time.Duration
+	maxQueued    int64
+	queued       atomic.Int64
+}
+
+// New creates a Pool.
+func New(cfg Config) *Pool {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	maxPerCaller := cfg.MaxPerCaller
+	if maxPerCaller <= 0 {
+		maxPerCaller = max(1, workers/2)
+	}
+	maxPerCaller = min(maxPerCaller, workers)
+
+	metrics.SetWorkerPoolSize(cfg.Name, workers)
+	return &Pool{
+		name:         cfg.Name,
+		slots:        make(chan struct{}, workers),
+		maxPerCaller: maxPerCaller,
+		queueTimeout: cfg.QueueTimeout,
+		maxQueued:    int64(cfg.MaxQueued),
+	}
+}
+
+// Size returns the number of workers.
+func (p *Pool) Size() int {
+	return cap(p.slots)
+}
+
+// MaxPerCaller returns the number of workers one caller may use at once.
+func (p *Pool) MaxPerCaller() int {
+	return p.maxPerCaller
+}
+
+// acquire waits for a free worker, up to the queue timeout, unless the
+// queue is full.
+func (p *Pool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		metrics.RecordWorkerPoolAcquire(p.name, 0)
+		return nil
+	default:
+	}
+
+	queued := p.queued.Add(1)
+	defer p.queued.Add(-1)
+	if p.maxQueued > 0 && queued > p.maxQueued {
+		metrics.RecordWorkerPoolRejection(p.name, "queue_full")
+		return ErrSaturated
+	}
+	metrics.AddWorkerPoolQueued(p.name, 1)
+	defer metrics.AddWorkerPoolQueued(p.name, -1)
+
+	var timeout <-chan time.Time
+	if p.queueTimeout > 0 {
+		timer := time.NewTimer(p.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	start := time.Now()
+	select {
+	case p.slots <- struct{}{}:
+		metrics.RecordWorkerPoolAcquire(p.name, time.Since(start))
+		return nil
+	case <-timeout:
+		metrics.RecordWorkerPoolRejection(p.name, "queue_timeout")
+		return ErrSaturated
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Acquire waits for a free worker like a task of Ordered, for callers that
+// run the work themselves, and returns the function that releases it. It
+// fails with ErrSaturated when no worker was free in time or the queue is
+// full, or with the context error once ctx is done.
+func (p *Pool) Acquire(ctx context.Context) (release func(), err error) {
+	if err := p.acquire(ctx); err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func() { once.Do(p.release) }, nil
+}
+
+func (p *Pool) release() {
+	<-p.slots
+	metrics.RecordWorkerPoolRelease(p.name)
+}
+
+// Ordered runs task for every index in [0, n) on the pool and passes the
+// results to emit in index order, as soon as each result and all before it
+// are ready. At most MaxPerCaller tasks of the call run at once.
+//
+// Indexes that do not run get their result from fallback instead: with
+// ErrSaturated when no worker was free within the queue timeout, or the
+// context error once ctx is done. After the first queue timeout the rest of
+// the call fails fast with ErrSaturated, so an overloaded pool sheds work
+// rather than holding a request for n timeouts.
+//
+// Ordered stops when emit returns false and waits for its running tasks
+// before returning.
+func Ordered[T any](ctx context.Context, p *Pool, n int, task func(int) T, fallback func(int, error) T, emit func(int, T) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]chan T, n)
+	for i := range results {
+		results[i] = make(chan T, 1)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		callerSlots := make(chan struct{}, p.maxPerCaller)
+		var failErr error
+		for i := 0; i < n; i++ {
+			if failErr == nil {
+				failErr = ctx.Err()
+			}
+			if failErr == nil {
+				select {
+				case callerSlots <- struct{}{}:
+					failErr = p.acquire(ctx)
+					if failErr != nil {
+						<-callerSlots
+					}
+				case <-ctx.Done():
+					failErr = ctx.Err()
+				}
+			} else if errors.Is(failErr, ErrSaturated) {
+				metrics.RecordWorkerPoolRejection(p.name, "saturated")
+			}
+			if failErr != nil {
+				results[i] <- fallback(i, failErr)
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer func() {
+					p.release()
+					<-callerSlots
+				}()
+				defer func() {
+					if r := recover(); r != nil {
+						results[i] <- fallback(i, fmt.Errorf("%w: %v", ErrTaskPanicked, r))
+					}
+				}()
+				results[i] <- task(i)
+			}(i)
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		if !emit(i, <-results[i]) {
+			break
+		}
+	}
+	cancel()
+	wg.Wait()
+}
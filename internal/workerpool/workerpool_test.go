@@ -0,0 +1,173 @@
+//go:build !integration
+
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outcome struct {
+	value int
+	err   error
+}
+
+func collect(ctx context.Context, p *Pool, n int, task func(int) int) []outcome {
+	var out []outcome
+	Ordered(ctx, p, n,
+		func(i int) outcome { return outcome{value: task(i)} },
+		func(i int, err error) outcome { return outcome{err: err} },
+		func(i int, o outcome) bool {
+			out = append(out, o)
+			return true
+		})
+	return out
+}
+
+func TestNew_Defaults(t *testing.T) {
+	p := New(Config{Name: "test_defaults"})
+	assert.Positive(t, p.Size())
+	assert.Equal(t, max(1, p.Size()/2), p.MaxPerCaller())
+
+	p = New(Config{Name: "test_defaults", Workers: 2, MaxPerCaller: 8})
+	assert.Equal(t, 2, p.Size())
+	assert.Equal(t, 2, p.MaxPerCaller())
+}
+
+func TestOrdered_EmitsInIndexOrder(t *testing.T) {
+	p := New(Config{Name: "test_order", Workers: 4, MaxPerCaller: 4})
+
+	out := collect(context.Background(), p, 20, func(i int) int {
+		// Later items finish first
+		time.Sleep(time.Duration(20-i) * time.Millisecond)
+		return i * i
+	})
+
+	require.Len(t, out, 20)
+	for i, o := range out {
+		require.NoError(t, o.err)
+		assert.Equal(t, i*i, o.value)
+	}
+}
+
+func TestOrdered_CapsConcurrencyPerCaller(t *testing.T) {
+	p := New(Config{Name: "test_cap", Workers: 8, MaxPerCaller: 2})
+
+	var running, peak atomic.Int32
+	collect(context.Background(), p, 20, func(i int) int {
+		n := running.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		running.Add(-1)
+		return i
+	})
+
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestOrdered_QueueTimeoutShedsRestOfCall(t *testing.T) {
+	p := New(Config{Name: "test_timeout", Workers: 1, QueueTimeout: 20 * time.Millisecond})
+
+	// Occupy the only worker
+	require.NoError(t, p.acquire(context.Background()))
+	defer p.release()
+
+	start := time.Now()
+	out := collect(context.Background(), p, 5, func(i int) int { return i })
+
+	require.Len(t, out, 5)
+	for _, o := range out {
+		assert.ErrorIs(t, o.err, ErrSaturated)
+	}
+	assert.Less(t, time.Since(start), time.Second, "only the first item should wait for the queue timeout")
+}
+
+func TestOrdered_StopsWhenEmitReturnsFalse(t *testing.T) {
+	p := New(Config{Name: "test_stop", Workers: 2})
+
+	var ran atomic.Int32
+	emitted := 0
+	Ordered(context.Background(), p, 1000,
+		func(i int) int {
+			ran.Add(1)
+			time.Sleep(time.Millisecond)
+			return i
+		},
+		func(i int, err error) int { return -1 },
+		func(i int, v int) bool {
+			emitted++
+			return emitted < 3
+		})
+
+	assert.Equal(t, 3, emitted)
+	assert.Less(t, ran.Load(), int32(1000))
+}
+
+func TestOrdered_CanceledContext(t *testing.T) {
+	p := New(Config{Name: "test_cancel", Workers: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := collect(ctx, p, 3, func(i int) int { return i })
+
+	require.Len(t, out, 3)
+	for _, o := range out {
+		assert.True(t, errors.Is(o.err, context.Canceled))
+	}
+}
+
+func TestOrdered_RecoversTaskPanics(t *testing.T) {
+	p := New(Config{Name: "test_panic", Workers: 2})
+
+	out := collect(context.Background(), p, 3, func(i int) int {
+		if i == 1 {
+			panic("boom")
+		}
+		return i
+	})
+
+	require.Len(t, out, 3)
+	assert.NoError(t, out[0].err)
+	assert.ErrorIs(t, out[1].err, ErrTaskPanicked)
+	assert.Equal(t, 2, out[2].value)
+}
+
+func TestAcquire_MaxQueuedRejectsAtOnce(t *testing.T) {
+	p := New(Config{Name: "test_queue", Workers: 1, MaxQueued: 1})
+
+	release, err := p.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// One caller waits in the queue
+	waiting := make(chan error, 1)
+	go func() {
+		release, err := p.Acquire(context.Background())
+		if err == nil {
+			release()
+		}
+		waiting <- err
+	}()
+	require.Eventually(t, func() bool { return p.queued.Load() == 1 }, time.Second, time.Millisecond)
+
+	start := time.Now()
+	_, err = p.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrSaturated)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "a full queue should not wait")
+
+	release()
+	release()
+	require.NoError(t, <-waiting, "the queued caller gets the worker")
+	_, err = p.Acquire(context.Background())
+	assert.NoError(t, err, "releasing twice frees the worker once")
+}